@@ -0,0 +1,30 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GPUUsageRecord is the GPU usage attributed to a cgroup (e.g., a pod)
+// on a GPU over an accounting window, to bill the tenants by.
+type GPUUsageRecord struct {
+	// Start is the start of the accounting window (inclusive).
+	Start metav1.Time `json:"start"`
+	// End is the end of the accounting window (exclusive).
+	End metav1.Time `json:"end"`
+
+	// GPUUUID is the UUID of the GPU used.
+	GPUUUID string `json:"gpuUUID"`
+
+	// PodUID is the UID of the Kubernetes pod, if the processes run in a pod.
+	PodUID string `json:"podUID,omitempty"`
+	// ContainerID is the ID of the container, if the processes run in a container.
+	ContainerID string `json:"containerID,omitempty"`
+	// Cgroup is the cgroup path of the processes.
+	Cgroup string `json:"cgroup,omitempty"`
+
+	// GPUSeconds is the GPU time used in seconds. A GPU shared by
+	// multiple cgroups is split evenly between them.
+	GPUSeconds float64 `json:"gpuSeconds"`
+	// EnergyJoules is the GPU energy used in joules, split the same way.
+	EnergyJoules float64 `json:"energyJoules"`
+}
@@ -0,0 +1,53 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ComponentCapabilityStatus is whether a component is running its checks, and if not, why.
+type ComponentCapabilityStatus string
+
+const (
+	// ComponentCapabilityStatusEnabled is the component running its checks.
+	ComponentCapabilityStatusEnabled ComponentCapabilityStatus = "Enabled"
+	// ComponentCapabilityStatusDegraded is the component running its checks
+	// with some features lost (e.g., gpud not running as root).
+	ComponentCapabilityStatusDegraded ComponentCapabilityStatus = "Degraded"
+	// ComponentCapabilityStatusMissingTool is the component running its checks,
+	// but skipping them for the tool not found (e.g., "ibstat").
+	ComponentCapabilityStatusMissingTool ComponentCapabilityStatus = "MissingTool"
+	// ComponentCapabilityStatusNotSupported is the component registered but
+	// not supported on the host (e.g., no NVIDIA GPU), so its checks are not run.
+	ComponentCapabilityStatusNotSupported ComponentCapabilityStatus = "NotSupported"
+	// ComponentCapabilityStatusDisabledByConfig is the component not registered
+	// for the enable or disable components configuration.
+	ComponentCapabilityStatusDisabledByConfig ComponentCapabilityStatus = "DisabledByConfig"
+	// ComponentCapabilityStatusDisabledByDevMode is the component not registered
+	// in the dev mode, for checking the host hardware directly rather than the fake backends.
+	ComponentCapabilityStatusDisabledByDevMode ComponentCapabilityStatus = "DisabledByDevMode"
+)
+
+// ComponentCapability is whether a component was enabled at the startup, and why.
+type ComponentCapability struct {
+	// Component is the name of the component.
+	Component string `json:"component"`
+	// Enabled is true if the component is running its checks.
+	Enabled bool `json:"enabled"`
+	// Status is the capability status of the component.
+	Status ComponentCapabilityStatus `json:"status"`
+	// Reason is the human-readable reason of the status, empty if enabled.
+	Reason string `json:"reason,omitempty"`
+}
+
+// ComponentCapabilityReport lists every component considered at the startup,
+// to tell why a component is not running its checks.
+type ComponentCapabilityReport struct {
+	// Time is when the report was generated.
+	Time metav1.Time `json:"time"`
+	// RunningAsRoot is true if gpud runs as root.
+	RunningAsRoot bool `json:"runningAsRoot"`
+	// NVMLExists is true if the NVIDIA Management Library is loaded.
+	NVMLExists bool `json:"nvmlExists"`
+	// Components are the capabilities sorted by the component name.
+	Components []ComponentCapability `json:"components"`
+}
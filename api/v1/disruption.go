@@ -0,0 +1,26 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DisruptionWindow is the window of the expected disruption of the components,
+// declared by the external scheduler (e.g., the infiniband fabric upgrade from 2am to 4am),
+// so that the findings of the components inside the window are tagged as expected
+// and not paged, while still recorded.
+type DisruptionWindow struct {
+	// ID is the unique identifier of the window, assigned by gpud if empty.
+	// Declaring the window with the existing id replaces the window.
+	ID string `json:"id"`
+	// Components are the glob patterns of the component names expected to be disrupted
+	// (e.g., "accelerator-nvidia-infiniband", "accelerator-nvidia-*").
+	Components []string `json:"components"`
+	// Start is the start time of the window.
+	Start metav1.Time `json:"start"`
+	// End is the end time of the window.
+	End metav1.Time `json:"end"`
+	// Reason is the reason of the disruption (e.g., "IB fabric upgrade").
+	Reason string `json:"reason,omitempty"`
+	// Requester is the scheduler or the operator that declared the window.
+	Requester string `json:"requester,omitempty"`
+}
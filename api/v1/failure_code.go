@@ -0,0 +1,106 @@
+package v1
+
+// FailureCode is the stable machine-readable code of the failure
+// detected by a component, attached to the unhealthy (or degraded)
+// health state along with the human-readable reason.
+// The automation should key off the failure codes instead of
+// parsing the reason, which may change across the gpud releases.
+//
+// The codes are never renamed or reused once released.
+// New codes may be added, so the consumers should handle
+// the unknown codes (e.g., fall back to the health state).
+type FailureCode string
+
+const (
+	// FailureCodeIBPortDown is the InfiniBand port not up (e.g., "Disabled", "Polling"),
+	// with fewer active ports than expected.
+	FailureCodeIBPortDown FailureCode = "IB_PORT_DOWN"
+	// FailureCodeIBPortRateDegraded is the InfiniBand port up but
+	// at the rate lower than expected.
+	FailureCodeIBPortRateDegraded FailureCode = "IB_PORT_RATE_DEGRADED"
+	// FailureCodeIBPortFlap is the InfiniBand port repeatedly going down
+	// and back up within the flap window.
+	FailureCodeIBPortFlap FailureCode = "IB_PORT_FLAP"
+	// FailureCodeIBPortErrors is the InfiniBand port error counters
+	// (e.g., symbol errors) increasing beyond the thresholds between the checks.
+	FailureCodeIBPortErrors FailureCode = "IB_PORT_ERRORS"
+
+	// FailureCodeXIDUncorrectableECC is the XID of the uncorrectable ECC error
+	// (e.g., Xid 48, 94, 95).
+	FailureCodeXIDUncorrectableECC FailureCode = "XID_UNCORRECTABLE_ECC"
+	// FailureCodeXIDRowRemapping is the XID of the row remapping event or failure
+	// (e.g., Xid 63, 64).
+	FailureCodeXIDRowRemapping FailureCode = "XID_ROW_REMAPPING"
+	// FailureCodeXIDNVLinkError is the XID of the NVLink error (e.g., Xid 74).
+	FailureCodeXIDNVLinkError FailureCode = "XID_NVLINK_ERROR"
+	// FailureCodeXIDGPUFallenOffBus is the XID of the GPU fallen off the bus (e.g., Xid 79).
+	FailureCodeXIDGPUFallenOffBus FailureCode = "XID_GPU_FALLEN_OFF_BUS"
+	// FailureCodeXIDGSPError is the XID of the GSP firmware error (e.g., Xid 119, 120).
+	FailureCodeXIDGSPError FailureCode = "XID_GSP_ERROR"
+	// FailureCodeXIDOther is the XID not classified by the other codes.
+	FailureCodeXIDOther FailureCode = "XID_OTHER"
+
+	// FailureCodeRowRemappingPending is the pending row remapping that requires the GPU reset.
+	FailureCodeRowRemappingPending FailureCode = "ROW_REMAPPING_PENDING"
+	// FailureCodeRowRemappingFailed is the failed row remapping that qualifies the GPU for RMA.
+	FailureCodeRowRemappingFailed FailureCode = "ROW_REMAPPING_FAILED"
+
+	// FailureCodeGPUFallenOffBus is the GPU fallen off the PCI bus.
+	FailureCodeGPUFallenOffBus FailureCode = "GPU_FALLEN_OFF_BUS"
+
+	// FailureCodeNVLinkInactive is the NVLink not active (e.g., the feature disabled),
+	// with fewer enabled links than expected.
+	FailureCodeNVLinkInactive FailureCode = "NVLINK_INACTIVE"
+
+	// FailureCodeFabricManagerInactive is the fabric manager service not active.
+	FailureCodeFabricManagerInactive FailureCode = "FABRIC_MANAGER_INACTIVE"
+)
+
+// AllFailureCodes returns all the failure codes, in the order of the definitions.
+func AllFailureCodes() []FailureCode {
+	return []FailureCode{
+		FailureCodeIBPortDown,
+		FailureCodeIBPortRateDegraded,
+		FailureCodeIBPortFlap,
+		FailureCodeIBPortErrors,
+		FailureCodeXIDUncorrectableECC,
+		FailureCodeXIDRowRemapping,
+		FailureCodeXIDNVLinkError,
+		FailureCodeXIDGPUFallenOffBus,
+		FailureCodeXIDGSPError,
+		FailureCodeXIDOther,
+		FailureCodeRowRemappingPending,
+		FailureCodeRowRemappingFailed,
+		FailureCodeGPUFallenOffBus,
+		FailureCodeNVLinkInactive,
+		FailureCodeFabricManagerInactive,
+	}
+}
+
+// FailureCodeForXID returns the failure code of the XID.
+func FailureCodeForXID(xid uint64) FailureCode {
+	switch xid {
+	case 48, 94, 95:
+		return FailureCodeXIDUncorrectableECC
+	case 63, 64:
+		return FailureCodeXIDRowRemapping
+	case 74:
+		return FailureCodeXIDNVLinkError
+	case 79:
+		return FailureCodeXIDGPUFallenOffBus
+	case 119, 120:
+		return FailureCodeXIDGSPError
+	default:
+		return FailureCodeXIDOther
+	}
+}
+
+// HasFailureCode returns true if the health state has the failure code.
+func (s HealthState) HasFailureCode(code FailureCode) bool {
+	for _, c := range s.FailureCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
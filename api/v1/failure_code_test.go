@@ -0,0 +1,43 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailureCodeForXID(t *testing.T) {
+	tests := []struct {
+		xid  uint64
+		want FailureCode
+	}{
+		{xid: 48, want: FailureCodeXIDUncorrectableECC},
+		{xid: 94, want: FailureCodeXIDUncorrectableECC},
+		{xid: 95, want: FailureCodeXIDUncorrectableECC},
+		{xid: 63, want: FailureCodeXIDRowRemapping},
+		{xid: 74, want: FailureCodeXIDNVLinkError},
+		{xid: 79, want: FailureCodeXIDGPUFallenOffBus},
+		{xid: 119, want: FailureCodeXIDGSPError},
+		{xid: 31, want: FailureCodeXIDOther},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, FailureCodeForXID(tt.xid), "xid %d", tt.xid)
+	}
+}
+
+func TestAllFailureCodesUnique(t *testing.T) {
+	seen := make(map[FailureCode]bool)
+	for _, code := range AllFailureCodes() {
+		assert.NotEmpty(t, code)
+		assert.False(t, seen[code], "duplicate failure code %q", code)
+		seen[code] = true
+	}
+	assert.True(t, seen[FailureCodeNVLinkInactive])
+}
+
+func TestHasFailureCode(t *testing.T) {
+	st := HealthState{FailureCodes: []FailureCode{FailureCodeIBPortDown}}
+	assert.True(t, st.HasFailureCode(FailureCodeIBPortDown))
+	assert.False(t, st.HasFailureCode(FailureCodeIBPortRateDegraded))
+	assert.False(t, HealthState{}.HasFailureCode(FailureCodeIBPortDown))
+}
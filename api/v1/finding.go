@@ -0,0 +1,67 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Finding is an unhealthy (or degraded) health state reported to the control plane,
+// tracked until the control plane acknowledges it or the state recovers.
+type Finding struct {
+	// ID is the stable identifier of the finding, derived from the component,
+	// the health state name, the health, and the failure codes
+	// (but not the reason, which may change across the checks).
+	ID string `json:"id"`
+
+	Component    string          `json:"component"`
+	Name         string          `json:"name"`
+	Health       HealthStateType `json:"health"`
+	Reason       string          `json:"reason,omitempty"`
+	FailureCodes []FailureCode   `json:"failure_codes,omitempty"`
+
+	// Severity is the severity of the reported state, escalated by the escalation ladders.
+	Severity EventType `json:"severity,omitempty"`
+	// EscalationLevel is the number of the escalation ladder steps reached by the reported state.
+	EscalationLevel int `json:"escalation_level,omitempty"`
+
+	// FirstReportedAt is when the finding was first reported to the control plane.
+	FirstReportedAt metav1.Time `json:"first_reported_at"`
+	// LastReportedAt is when the finding was last reported to the control plane.
+	LastReportedAt metav1.Time `json:"last_reported_at"`
+
+	// AckID is the acknowledgment id returned by the control plane,
+	// empty if not acknowledged yet.
+	AckID string `json:"ack_id,omitempty"`
+	// AcknowledgedAt is when the control plane acknowledged the finding.
+	AcknowledgedAt *metav1.Time `json:"acknowledged_at,omitempty"`
+
+	// Escalations is the number of the times the unacknowledged finding
+	// was re-escalated to the control plane (not the escalation ladder steps,
+	// see EscalationLevel).
+	Escalations int `json:"escalations,omitempty"`
+	// LastEscalatedAt is when the finding was last re-escalated.
+	LastEscalatedAt *metav1.Time `json:"last_escalated_at,omitempty"`
+
+	// ExpectedDisruption is the id of the declared disruption window
+	// the finding is inside of, empty if the finding is not expected.
+	// The expected findings are reported but not re-escalated.
+	ExpectedDisruption string `json:"expected_disruption,omitempty"`
+}
+
+// Acknowledged returns true if the control plane acknowledged the finding.
+func (f Finding) Acknowledged() bool {
+	return f.AckID != ""
+}
+
+// Expected returns true if the finding is inside the declared disruption window.
+func (f Finding) Expected() bool {
+	return f.ExpectedDisruption != ""
+}
+
+// FindingAck is the acknowledgment of a finding by the control plane.
+type FindingAck struct {
+	// FindingID is the id of the acknowledged finding.
+	FindingID string `json:"finding_id"`
+	// AckID is the acknowledgment id assigned by the control plane
+	// (e.g., the incident or ticket id).
+	AckID string `json:"ack_id"`
+}
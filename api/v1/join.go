@@ -6,6 +6,7 @@ type JoinRequest struct {
 	ClusterName        string `json:"cluster_name,omitempty"`
 	PublicIP           string `json:"public_ip,omitempty"`
 	PrivateIP          string `json:"private_ip,omitempty"`
+	PrivateIPv6        string `json:"private_ipv6,omitempty"`
 	Provider           string `json:"provider,omitempty"`
 	ProviderInstanceID string `json:"provider_instance_id,omitempty"`
 	ProviderGPUShape   string `json:"provider_gpu_shape,omitempty"`
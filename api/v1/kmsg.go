@@ -0,0 +1,32 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KernelMessage is a kernel message (e.g., "dmesg" line),
+// annotated with the gpud matcher that classifies it, if any.
+type KernelMessage struct {
+	// Time represents when the message was logged.
+	Time metav1.Time `json:"time"`
+	// Priority is the syslog priority of the message (e.g., 3 for "err").
+	Priority int `json:"priority"`
+	// Message is the kernel message.
+	Message string `json:"message"`
+
+	// Match is the gpud matcher that classifies the message,
+	// nil if the message is not matched by any matcher.
+	Match *KernelMessageMatch `json:"match,omitempty"`
+}
+
+// KernelMessageMatch is the gpud matcher classification of a kernel message.
+type KernelMessageMatch struct {
+	// Component is the name of the component that owns the matcher.
+	Component string `json:"component"`
+	// EventName is the name of the event the component creates for the message.
+	EventName string `json:"eventName"`
+	// EventType is the type of the event, if known.
+	EventType EventType `json:"eventType,omitempty"`
+	// Description is the human-readable description of the match.
+	Description string `json:"description,omitempty"`
+}
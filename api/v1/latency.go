@@ -0,0 +1,39 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ComponentCheckLatency is the duration and the CPU time spent
+// by the checks of a component, to make the monitoring overhead visible.
+type ComponentCheckLatency struct {
+	// Component is the name of the component.
+	Component string `json:"component"`
+
+	// Checks is the number of the checks recorded.
+	Checks int64 `json:"checks"`
+
+	// LastDuration is the wall-clock duration of the last check.
+	LastDuration metav1.Duration `json:"lastDuration"`
+	// AvgDuration is the average wall-clock duration of the checks.
+	AvgDuration metav1.Duration `json:"avgDuration"`
+	// MaxDuration is the maximum wall-clock duration of the checks.
+	MaxDuration metav1.Duration `json:"maxDuration"`
+
+	// LastCPUTime is the CPU time (user and system) of the last check,
+	// on the thread that ran the check (i.e., excluding the other goroutines).
+	// Zero if not supported on the platform.
+	LastCPUTime metav1.Duration `json:"lastCpuTime"`
+	// AvgCPUTime is the average CPU time of the checks.
+	AvgCPUTime metav1.Duration `json:"avgCpuTime"`
+
+	// Budget is the duration each check is expected to complete within.
+	Budget metav1.Duration `json:"budget"`
+	// OverBudget is the number of the consecutive checks that exceeded the budget.
+	OverBudget int64 `json:"overBudget"`
+	// LastCheckTime is when the last check completed.
+	LastCheckTime metav1.Time `json:"lastCheckTime"`
+}
+
+// ComponentCheckLatencies is the list of the check latencies, sorted by the component name.
+type ComponentCheckLatencies []ComponentCheckLatency
@@ -0,0 +1,550 @@
+package v1
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The protobuf encoding of the API responses, following the schema in "gpud.proto".
+// The messages are encoded with the low-level wire format helpers to avoid
+// the generated code, given the small number of messages.
+
+// MarshalProto encodes the health states in the protobuf wire format.
+func (s GPUdComponentHealthStates) MarshalProto() ([]byte, error) {
+	var b []byte
+	for _, cs := range s {
+		b = appendMessage(b, 1, marshalComponentHealthStates(cs))
+	}
+	return b, nil
+}
+
+// UnmarshalProto decodes the health states from the protobuf wire format.
+func (s *GPUdComponentHealthStates) UnmarshalProto(b []byte) error {
+	var out GPUdComponentHealthStates
+	err := consumeFields(b, func(f protoField) error {
+		if f.num != 1 {
+			return nil
+		}
+		cs, err := unmarshalComponentHealthStates(f.bytes)
+		if err != nil {
+			return err
+		}
+		out = append(out, cs)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	*s = out
+	return nil
+}
+
+// MarshalProto encodes the events in the protobuf wire format.
+func (e GPUdComponentEvents) MarshalProto() ([]byte, error) {
+	var b []byte
+	for _, ce := range e {
+		b = appendMessage(b, 1, marshalComponentEvents(ce))
+	}
+	return b, nil
+}
+
+// UnmarshalProto decodes the events from the protobuf wire format.
+func (e *GPUdComponentEvents) UnmarshalProto(b []byte) error {
+	var out GPUdComponentEvents
+	err := consumeFields(b, func(f protoField) error {
+		if f.num != 1 {
+			return nil
+		}
+		ce, err := unmarshalComponentEvents(f.bytes)
+		if err != nil {
+			return err
+		}
+		out = append(out, ce)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	*e = out
+	return nil
+}
+
+// MarshalProto encodes the metrics in the protobuf wire format.
+func (m GPUdComponentMetrics) MarshalProto() ([]byte, error) {
+	var b []byte
+	for _, cm := range m {
+		b = appendMessage(b, 1, marshalComponentMetrics(cm))
+	}
+	return b, nil
+}
+
+// UnmarshalProto decodes the metrics from the protobuf wire format.
+func (m *GPUdComponentMetrics) UnmarshalProto(b []byte) error {
+	var out GPUdComponentMetrics
+	err := consumeFields(b, func(f protoField) error {
+		if f.num != 1 {
+			return nil
+		}
+		cm, err := unmarshalComponentMetrics(f.bytes)
+		if err != nil {
+			return err
+		}
+		out = append(out, cm)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	*m = out
+	return nil
+}
+
+func marshalComponentHealthStates(cs ComponentHealthStates) []byte {
+	var b []byte
+	b = appendString(b, 1, cs.Component)
+	for _, st := range cs.States {
+		b = appendMessage(b, 2, marshalHealthState(st))
+	}
+	return b
+}
+
+func unmarshalComponentHealthStates(b []byte) (ComponentHealthStates, error) {
+	var cs ComponentHealthStates
+	err := consumeFields(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			cs.Component = string(f.bytes)
+		case 2:
+			st, err := unmarshalHealthState(f.bytes)
+			if err != nil {
+				return err
+			}
+			cs.States = append(cs.States, st)
+		}
+		return nil
+	})
+	return cs, err
+}
+
+func marshalHealthState(st HealthState) []byte {
+	var b []byte
+	b = appendTime(b, 1, st.Time.Time)
+	b = appendString(b, 2, st.Component)
+	b = appendString(b, 3, string(st.ComponentType))
+	b = appendString(b, 4, st.Name)
+	b = appendString(b, 5, string(st.RunMode))
+	b = appendString(b, 6, string(st.Health))
+	b = appendString(b, 7, st.Reason)
+	b = appendString(b, 8, st.Error)
+	if st.SuggestedActions != nil {
+		b = appendMessage(b, 9, marshalSuggestedActions(st.SuggestedActions))
+	}
+	b = appendStringMap(b, 10, st.ExtraInfo)
+	b = appendString(b, 11, st.RawOutput)
+	b = appendStringMap(b, 12, st.Labels)
+	b = appendString(b, 13, st.ComponentVersion)
+	b = appendString(b, 14, st.SchemaVersion)
+	for _, code := range st.FailureCodes {
+		b = appendString(b, 15, string(code))
+	}
+	b = appendString(b, 16, string(st.Severity))
+	b = appendInt64(b, 17, int64(st.EscalationLevel))
+	if st.UnhealthySince != nil {
+		b = appendTime(b, 18, st.UnhealthySince.Time)
+	}
+	return b
+}
+
+func unmarshalHealthState(b []byte) (HealthState, error) {
+	var st HealthState
+	err := consumeFields(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			st.Time = metav1.NewTime(timeFromUnixNano(f.varint))
+		case 2:
+			st.Component = string(f.bytes)
+		case 3:
+			st.ComponentType = ComponentType(f.bytes)
+		case 4:
+			st.Name = string(f.bytes)
+		case 5:
+			st.RunMode = RunModeType(f.bytes)
+		case 6:
+			st.Health = HealthStateType(f.bytes)
+		case 7:
+			st.Reason = string(f.bytes)
+		case 8:
+			st.Error = string(f.bytes)
+		case 9:
+			sa, err := unmarshalSuggestedActions(f.bytes)
+			if err != nil {
+				return err
+			}
+			st.SuggestedActions = sa
+		case 10:
+			if st.ExtraInfo == nil {
+				st.ExtraInfo = make(map[string]string)
+			}
+			return consumeMapEntry(f.bytes, st.ExtraInfo)
+		case 11:
+			st.RawOutput = string(f.bytes)
+		case 12:
+			if st.Labels == nil {
+				st.Labels = make(map[string]string)
+			}
+			return consumeMapEntry(f.bytes, st.Labels)
+		case 13:
+			st.ComponentVersion = string(f.bytes)
+		case 14:
+			st.SchemaVersion = string(f.bytes)
+		case 15:
+			st.FailureCodes = append(st.FailureCodes, FailureCode(f.bytes))
+		case 16:
+			st.Severity = EventType(f.bytes)
+		case 17:
+			st.EscalationLevel = int(f.varint)
+		case 18:
+			since := metav1.NewTime(timeFromUnixNano(f.varint))
+			st.UnhealthySince = &since
+		}
+		return nil
+	})
+	return st, err
+}
+
+func marshalComponentEvents(ce ComponentEvents) []byte {
+	var b []byte
+	b = appendString(b, 1, ce.Component)
+	b = appendTime(b, 2, ce.StartTime)
+	b = appendTime(b, 3, ce.EndTime)
+	for _, ev := range ce.Events {
+		b = appendMessage(b, 4, marshalEvent(ev))
+	}
+	b = appendInt64(b, 5, int64(ce.NextOffset))
+	return b
+}
+
+func unmarshalComponentEvents(b []byte) (ComponentEvents, error) {
+	var ce ComponentEvents
+	err := consumeFields(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			ce.Component = string(f.bytes)
+		case 2:
+			ce.StartTime = timeFromUnixNano(f.varint)
+		case 3:
+			ce.EndTime = timeFromUnixNano(f.varint)
+		case 4:
+			ev, err := unmarshalEvent(f.bytes)
+			if err != nil {
+				return err
+			}
+			ce.Events = append(ce.Events, ev)
+		case 5:
+			ce.NextOffset = int(int64(f.varint))
+		}
+		return nil
+	})
+	return ce, err
+}
+
+func marshalEvent(ev Event) []byte {
+	var b []byte
+	b = appendString(b, 1, ev.Component)
+	b = appendTime(b, 2, ev.Time.Time)
+	b = appendString(b, 3, ev.Name)
+	b = appendString(b, 4, string(ev.Type))
+	b = appendString(b, 5, ev.Message)
+	b = appendStringMap(b, 6, ev.Labels)
+	b = appendString(b, 7, ev.ComponentVersion)
+	b = appendString(b, 8, ev.SchemaVersion)
+	b = appendInt64(b, 9, ev.Seq)
+	for _, proc := range ev.AffectedProcesses {
+		b = appendMessage(b, 10, marshalAffectedProcess(proc))
+	}
+	if ev.SuggestedActions != nil {
+		b = appendMessage(b, 11, marshalSuggestedActions(ev.SuggestedActions))
+	}
+	return b
+}
+
+func unmarshalEvent(b []byte) (Event, error) {
+	var ev Event
+	err := consumeFields(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			ev.Component = string(f.bytes)
+		case 2:
+			ev.Time = metav1.NewTime(timeFromUnixNano(f.varint))
+		case 3:
+			ev.Name = string(f.bytes)
+		case 4:
+			ev.Type = EventType(f.bytes)
+		case 5:
+			ev.Message = string(f.bytes)
+		case 6:
+			if ev.Labels == nil {
+				ev.Labels = make(map[string]string)
+			}
+			return consumeMapEntry(f.bytes, ev.Labels)
+		case 7:
+			ev.ComponentVersion = string(f.bytes)
+		case 8:
+			ev.SchemaVersion = string(f.bytes)
+		case 9:
+			ev.Seq = int64(f.varint)
+		case 10:
+			proc, err := unmarshalAffectedProcess(f.bytes)
+			if err != nil {
+				return err
+			}
+			ev.AffectedProcesses = append(ev.AffectedProcesses, proc)
+		case 11:
+			sa, err := unmarshalSuggestedActions(f.bytes)
+			if err != nil {
+				return err
+			}
+			ev.SuggestedActions = sa
+		}
+		return nil
+	})
+	return ev, err
+}
+
+func marshalSuggestedActions(sa *SuggestedActions) []byte {
+	var b []byte
+	b = appendString(b, 1, sa.Description)
+	for _, act := range sa.RepairActions {
+		b = appendMessage(b, 2, []byte(act))
+	}
+	return b
+}
+
+func unmarshalSuggestedActions(b []byte) (*SuggestedActions, error) {
+	sa := &SuggestedActions{}
+	err := consumeFields(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			sa.Description = string(f.bytes)
+		case 2:
+			sa.RepairActions = append(sa.RepairActions, RepairActionType(f.bytes))
+		}
+		return nil
+	})
+	return sa, err
+}
+
+func marshalAffectedProcess(proc AffectedProcess) []byte {
+	var b []byte
+	b = appendString(b, 1, proc.GPUUUID)
+	b = appendInt64(b, 2, int64(proc.PID))
+	for _, arg := range proc.CmdArgs {
+		b = appendMessage(b, 3, []byte(arg))
+	}
+	b = appendInt64(b, 4, int64(proc.GPUUsedMemoryBytes))
+	b = appendString(b, 5, proc.Cgroup)
+	b = appendString(b, 6, proc.ContainerID)
+	b = appendString(b, 7, proc.PodUID)
+	return b
+}
+
+func unmarshalAffectedProcess(b []byte) (AffectedProcess, error) {
+	var proc AffectedProcess
+	err := consumeFields(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			proc.GPUUUID = string(f.bytes)
+		case 2:
+			proc.PID = uint32(f.varint)
+		case 3:
+			proc.CmdArgs = append(proc.CmdArgs, string(f.bytes))
+		case 4:
+			proc.GPUUsedMemoryBytes = f.varint
+		case 5:
+			proc.Cgroup = string(f.bytes)
+		case 6:
+			proc.ContainerID = string(f.bytes)
+		case 7:
+			proc.PodUID = string(f.bytes)
+		}
+		return nil
+	})
+	return proc, err
+}
+
+func marshalComponentMetrics(cm ComponentMetrics) []byte {
+	var b []byte
+	b = appendString(b, 1, cm.Component)
+	for _, m := range cm.Metrics {
+		b = appendMessage(b, 2, marshalMetric(m))
+	}
+	return b
+}
+
+func unmarshalComponentMetrics(b []byte) (ComponentMetrics, error) {
+	var cm ComponentMetrics
+	err := consumeFields(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			cm.Component = string(f.bytes)
+		case 2:
+			m, err := unmarshalMetric(f.bytes)
+			if err != nil {
+				return err
+			}
+			cm.Metrics = append(cm.Metrics, m)
+		}
+		return nil
+	})
+	return cm, err
+}
+
+func marshalMetric(m Metric) []byte {
+	var b []byte
+	b = appendInt64(b, 1, m.UnixSeconds)
+	b = appendString(b, 2, m.Name)
+	b = appendStringMap(b, 3, m.Labels)
+	if m.Value != 0 {
+		b = protowire.AppendTag(b, 4, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(m.Value))
+	}
+	return b
+}
+
+func unmarshalMetric(b []byte) (Metric, error) {
+	var m Metric
+	err := consumeFields(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			m.UnixSeconds = int64(f.varint)
+		case 2:
+			m.Name = string(f.bytes)
+		case 3:
+			if m.Labels == nil {
+				m.Labels = make(map[string]string)
+			}
+			return consumeMapEntry(f.bytes, m.Labels)
+		case 4:
+			m.Value = math.Float64frombits(f.varint)
+		}
+		return nil
+	})
+	return m, err
+}
+
+// protoField is a decoded field, where "varint" holds the value of
+// the varint and fixed-size types, and "bytes" holds the value of the length-delimited type.
+type protoField struct {
+	num    protowire.Number
+	varint uint64
+	bytes  []byte
+}
+
+// consumeFields decodes the fields of the message in order,
+// skipping the unknown wire types.
+func consumeFields(b []byte, fn func(f protoField) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return fmt.Errorf("failed to decode tag: %w", protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		f := protoField{num: num}
+		switch typ {
+		case protowire.VarintType:
+			f.varint, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			f.varint, n = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return fmt.Errorf("failed to decode field %d: %w", num, protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func consumeMapEntry(b []byte, m map[string]string) error {
+	var k, v string
+	if err := consumeFields(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			k = string(f.bytes)
+		case 2:
+			v = string(f.bytes)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	m[k] = v
+	return nil
+}
+
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendInt64(b []byte, num protowire.Number, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(v))
+}
+
+func appendTime(b []byte, num protowire.Number, t time.Time) []byte {
+	if t.IsZero() {
+		return b
+	}
+	return appendInt64(b, num, t.UnixNano())
+}
+
+// appendMessage appends the embedded message (or the repeated string element),
+// even if empty, so that the repeated fields preserve the number of elements.
+func appendMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+// appendStringMap appends the map entries sorted by the keys, for the deterministic output.
+func appendStringMap(b []byte, num protowire.Number, m map[string]string) []byte {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		var entry []byte
+		entry = appendString(entry, 1, k)
+		entry = appendString(entry, 2, m[k])
+		b = appendMessage(b, num, entry)
+	}
+	return b
+}
+
+func timeFromUnixNano(v uint64) time.Time {
+	if v == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(v)).UTC()
+}
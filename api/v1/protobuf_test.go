@@ -0,0 +1,153 @@
+package v1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHealthStatesProtoRoundTrip(t *testing.T) {
+	ts := time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC)
+	in := GPUdComponentHealthStates{
+		{
+			Component: "accelerator-nvidia-infiniband",
+			States: HealthStates{
+				{
+					Time:      metav1.NewTime(ts),
+					Component: "accelerator-nvidia-infiniband",
+					Name:      "accelerator-nvidia-infiniband",
+					Health:    HealthStateTypeUnhealthy,
+					Reason:    "only 7 ports are active",
+					Error:     "port down",
+					SuggestedActions: &SuggestedActions{
+						Description:   "check the cables",
+						RepairActions: []RepairActionType{RepairActionTypeHardwareInspection},
+					},
+					ExtraInfo:       map[string]string{"a": "1", "b": ""},
+					RawOutput:       "raw",
+					Labels:          map[string]string{"rack": "r1"},
+					FailureCodes:    []FailureCode{FailureCodeIBPortDown, FailureCodeIBPortRateDegraded},
+					Severity:        EventTypeCritical,
+					EscalationLevel: 2,
+					UnhealthySince:  &metav1.Time{Time: ts.Add(-time.Hour)},
+				},
+				{Name: "empty"},
+			},
+		},
+		{Component: "no-states"},
+	}
+
+	b, err := in.MarshalProto()
+	require.NoError(t, err)
+
+	var out GPUdComponentHealthStates
+	require.NoError(t, out.UnmarshalProto(b))
+	require.Len(t, out, 2)
+	assert.Equal(t, in[0].Component, out[0].Component)
+	require.Len(t, out[0].States, 2)
+	assert.True(t, ts.Equal(out[0].States[0].Time.Time))
+	assert.Equal(t, in[0].States[0].Reason, out[0].States[0].Reason)
+	assert.Equal(t, in[0].States[0].Error, out[0].States[0].Error)
+	assert.Equal(t, in[0].States[0].Health, out[0].States[0].Health)
+	assert.Equal(t, in[0].States[0].SuggestedActions, out[0].States[0].SuggestedActions)
+	assert.Equal(t, in[0].States[0].ExtraInfo, out[0].States[0].ExtraInfo)
+	assert.Equal(t, in[0].States[0].RawOutput, out[0].States[0].RawOutput)
+	assert.Equal(t, in[0].States[0].Labels, out[0].States[0].Labels)
+	assert.Equal(t, in[0].States[0].FailureCodes, out[0].States[0].FailureCodes)
+	assert.Equal(t, "empty", out[0].States[1].Name)
+	assert.Equal(t, in[0].States[0].Severity, out[0].States[0].Severity)
+	assert.Equal(t, in[0].States[0].EscalationLevel, out[0].States[0].EscalationLevel)
+	require.NotNil(t, out[0].States[0].UnhealthySince)
+	assert.True(t, ts.Add(-time.Hour).Equal(out[0].States[0].UnhealthySince.Time))
+	assert.Nil(t, out[0].States[1].FailureCodes)
+	assert.Nil(t, out[0].States[1].UnhealthySince)
+	assert.True(t, out[0].States[1].Time.IsZero())
+	assert.Equal(t, "no-states", out[1].Component)
+}
+
+func TestEventsProtoRoundTrip(t *testing.T) {
+	ts := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	in := GPUdComponentEvents{
+		{
+			Component:  "cpu",
+			StartTime:  ts.Add(-time.Hour),
+			EndTime:    ts,
+			NextOffset: 100,
+			Events: Events{
+				{Component: "cpu", Time: metav1.NewTime(ts), Name: "soft_lockup", Type: EventTypeWarning, Message: "lockup", Labels: map[string]string{"tenant": "a"}, Seq: 42,
+					SuggestedActions: &SuggestedActions{Description: "lockup", RepairActions: []RepairActionType{RepairActionTypeRebootSystem}}},
+				{Component: "cpu", Name: "no_seq"},
+				{
+					Component: "accelerator-nvidia-error-xid",
+					Name:      "error_xid",
+					AffectedProcesses: []AffectedProcess{
+						{
+							GPUUUID:            "GPU-1",
+							PID:                1234,
+							CmdArgs:            []string{"python", "", "train.py"},
+							GPUUsedMemoryBytes: 1 << 40,
+							Cgroup:             "/kubepods/pod-a/c1",
+							ContainerID:        "c1",
+							PodUID:             "a",
+						},
+						{GPUUUID: "GPU-1", PID: 5678},
+					},
+				},
+			},
+		},
+	}
+
+	b, err := in.MarshalProto()
+	require.NoError(t, err)
+
+	var out GPUdComponentEvents
+	require.NoError(t, out.UnmarshalProto(b))
+	require.Len(t, out, 1)
+	assert.True(t, in[0].StartTime.Equal(out[0].StartTime))
+	assert.True(t, in[0].EndTime.Equal(out[0].EndTime))
+	assert.Equal(t, 100, out[0].NextOffset)
+	require.Len(t, out[0].Events, 3)
+	assert.Equal(t, in[0].Events[0].Name, out[0].Events[0].Name)
+	assert.Equal(t, in[0].Events[0].Type, out[0].Events[0].Type)
+	assert.Equal(t, in[0].Events[0].Message, out[0].Events[0].Message)
+	assert.Equal(t, in[0].Events[0].Labels, out[0].Events[0].Labels)
+	assert.True(t, ts.Equal(out[0].Events[0].Time.Time))
+	assert.Equal(t, int64(42), out[0].Events[0].Seq)
+	assert.Zero(t, out[0].Events[1].Seq)
+	assert.Nil(t, out[0].Events[1].AffectedProcesses)
+	assert.Equal(t, in[0].Events[0].SuggestedActions, out[0].Events[0].SuggestedActions)
+	assert.Nil(t, out[0].Events[1].SuggestedActions)
+	assert.Equal(t, in[0].Events[2].AffectedProcesses, out[0].Events[2].AffectedProcesses)
+}
+
+func TestMetricsProtoRoundTrip(t *testing.T) {
+	in := GPUdComponentMetrics{
+		{
+			Component: "accelerator-nvidia-temperature",
+			Metrics: Metrics{
+				{UnixSeconds: 1700000000, Name: "temperature_current_celsius", Labels: map[string]string{"gpu_id": "GPU-0"}, Value: 65.5},
+				{UnixSeconds: 1700000060, Name: "temperature_current_celsius", Value: 0},
+				{UnixSeconds: 1700000120, Name: "negative", Value: -1.25},
+			},
+		},
+	}
+
+	b, err := in.MarshalProto()
+	require.NoError(t, err)
+
+	var out GPUdComponentMetrics
+	require.NoError(t, out.UnmarshalProto(b))
+	assert.Equal(t, in, out)
+}
+
+func TestUnmarshalProtoInvalid(t *testing.T) {
+	var states GPUdComponentHealthStates
+	require.Error(t, states.UnmarshalProto([]byte{0x0a, 0x10, 0x01}))
+
+	// empty input decodes into an empty list
+	require.NoError(t, states.UnmarshalProto(nil))
+	assert.Empty(t, states)
+}
@@ -0,0 +1,59 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GPUSample is a single high-frequency sample of a GPU.
+type GPUSample struct {
+	// Time represents when the sample was taken,
+	// with the microsecond precision for the sub-second intervals.
+	Time metav1.MicroTime `json:"time"`
+	// UUID represents the GPU UUID.
+	UUID string `json:"uuid"`
+
+	// GPUUsedPercent is the percent of time over the past sample period
+	// during which one or more kernels was executing on the GPU.
+	GPUUsedPercent uint32 `json:"gpuUsedPercent"`
+	// MemoryUsedPercent is the percent of time over the past sample period
+	// during which the device memory was being read or written.
+	MemoryUsedPercent uint32 `json:"memoryUsedPercent"`
+
+	// PowerUsageMilliWatts is the current power usage in milliwatts.
+	PowerUsageMilliWatts uint32 `json:"powerUsageMilliWatts"`
+
+	// GraphicsClockMHz is the current graphics clock speed in MHz.
+	GraphicsClockMHz uint32 `json:"graphicsClockMHz"`
+	// MemoryClockMHz is the current memory clock speed in MHz.
+	MemoryClockMHz uint32 `json:"memoryClockMHz"`
+}
+
+// GPUSamplingRequest is the request to start the high-frequency sampling.
+type GPUSamplingRequest struct {
+	// Interval is the sampling interval (e.g., "100ms").
+	Interval metav1.Duration `json:"interval"`
+	// Duration is how long to sample for, after which
+	// the sampling stops automatically (e.g., "5m").
+	Duration metav1.Duration `json:"duration"`
+}
+
+// GPUSamplingStatus is the status of the high-frequency sampling,
+// with the samples collected so far.
+type GPUSamplingStatus struct {
+	// Active is true if the sampling is in progress.
+	Active bool `json:"active"`
+
+	// Interval is the sampling interval of the last (or current) session.
+	Interval metav1.Duration `json:"interval,omitempty"`
+	// StartedAt represents when the last (or current) session was started.
+	StartedAt metav1.Time `json:"startedAt,omitempty"`
+	// ExpiresAt represents when the last (or current) session stops automatically.
+	ExpiresAt metav1.Time `json:"expiresAt,omitempty"`
+
+	// Dropped is the number of the oldest samples overwritten
+	// since the ring buffer was full.
+	Dropped int `json:"dropped,omitempty"`
+
+	// Samples are the samples in the ring buffer, in the order of time.
+	Samples []GPUSample `json:"samples,omitempty"`
+}
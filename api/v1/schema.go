@@ -0,0 +1,169 @@
+package v1
+
+import (
+	"fmt"
+)
+
+// The schema versions of the HealthState and Event formats,
+// bumped when a field is added, removed, or changes its meaning,
+// so that the downstream consumers can handle the format changes
+// across the gpud upgrades.
+const (
+	// SchemaVersion1 is the format before the version fields were added.
+	// The health states and events without the schema version are in this format.
+	SchemaVersion1 = "v1"
+	// SchemaVersion2 adds the component and schema versions.
+	SchemaVersion2 = "v2"
+	// SchemaVersion3 adds the failure codes to the health states.
+	SchemaVersion3 = "v3"
+	// SchemaVersion4 adds the severity and the escalation level to the health states.
+	SchemaVersion4 = "v4"
+	// SchemaVersion5 adds the suggested actions to the events.
+	SchemaVersion5 = "v5"
+
+	// CurrentSchemaVersion is the schema version of the health states and events
+	// produced by this gpud.
+	CurrentSchemaVersion = SchemaVersion5
+)
+
+// supportedSchemaVersions are the schema versions this gpud can convert to,
+// in the order of the releases.
+var supportedSchemaVersions = []string{SchemaVersion1, SchemaVersion2, SchemaVersion3, SchemaVersion4, SchemaVersion5}
+
+// IsSupportedSchemaVersion returns true if the health states and events
+// can be converted to the schema version.
+func IsSupportedSchemaVersion(schemaVersion string) bool {
+	for _, v := range supportedSchemaVersions {
+		if v == schemaVersion {
+			return true
+		}
+	}
+	return false
+}
+
+// StampHealthStates returns the copy of the health states with the component version
+// (if not already set by the component) and the current schema version.
+// The states are copied since they may be shared with the component cache.
+func StampHealthStates(states HealthStates, componentVersion string) HealthStates {
+	if len(states) == 0 {
+		return states
+	}
+	out := make(HealthStates, len(states))
+	for i, st := range states {
+		if st.ComponentVersion == "" {
+			st.ComponentVersion = componentVersion
+		}
+		st.SchemaVersion = CurrentSchemaVersion
+		out[i] = st
+	}
+	return out
+}
+
+// StampEvents returns the copy of the events with the component version
+// (if not already set) and the current schema version.
+func StampEvents(events Events, componentVersion string) Events {
+	if len(events) == 0 {
+		return events
+	}
+	out := make(Events, len(events))
+	for i, ev := range events {
+		if ev.ComponentVersion == "" {
+			ev.ComponentVersion = componentVersion
+		}
+		ev.SchemaVersion = CurrentSchemaVersion
+		out[i] = ev
+	}
+	return out
+}
+
+// NormalizeHealthStates returns the copy of the health states read from
+// any gpud version, with the empty schema version set to SchemaVersion1
+// (i.e., produced by the gpud before the version fields were added).
+func NormalizeHealthStates(states HealthStates) HealthStates {
+	out := make(HealthStates, len(states))
+	for i, st := range states {
+		if st.SchemaVersion == "" {
+			st.SchemaVersion = SchemaVersion1
+		}
+		out[i] = st
+	}
+	return out
+}
+
+// NormalizeEvents returns the copy of the events read from any gpud version,
+// with the empty schema version set to SchemaVersion1.
+func NormalizeEvents(events Events) Events {
+	out := make(Events, len(events))
+	for i, ev := range events {
+		if ev.SchemaVersion == "" {
+			ev.SchemaVersion = SchemaVersion1
+		}
+		out[i] = ev
+	}
+	return out
+}
+
+// ConvertHealthStates returns the copy of the health states converted to
+// the older schema version for the older consumers, dropping the fields
+// not in the schema. The empty schema version is the current schema version.
+func ConvertHealthStates(states HealthStates, schemaVersion string) (HealthStates, error) {
+	if schemaVersion == "" || schemaVersion == CurrentSchemaVersion {
+		return states, nil
+	}
+	if !IsSupportedSchemaVersion(schemaVersion) {
+		return nil, fmt.Errorf("unsupported schema version %q", schemaVersion)
+	}
+
+	out := make(HealthStates, len(states))
+	for i, st := range states {
+		switch schemaVersion {
+		case SchemaVersion1:
+			st.ComponentVersion = ""
+			st.SchemaVersion = ""
+			st.FailureCodes = nil
+		case SchemaVersion2:
+			st.SchemaVersion = SchemaVersion2
+			st.FailureCodes = nil
+		case SchemaVersion3:
+			st.SchemaVersion = SchemaVersion3
+		case SchemaVersion4:
+			// same health state format as the v5
+			st.SchemaVersion = SchemaVersion4
+			out[i] = st
+			continue
+		}
+		// the older schema versions are before the severity and escalation
+		st.Severity = ""
+		st.EscalationLevel = 0
+		st.UnhealthySince = nil
+		out[i] = st
+	}
+	return out, nil
+}
+
+// ConvertEvents returns the copy of the events converted to
+// the older schema version for the older consumers.
+func ConvertEvents(events Events, schemaVersion string) (Events, error) {
+	if schemaVersion == "" || schemaVersion == CurrentSchemaVersion {
+		return events, nil
+	}
+	if !IsSupportedSchemaVersion(schemaVersion) {
+		return nil, fmt.Errorf("unsupported schema version %q", schemaVersion)
+	}
+
+	out := make(Events, len(events))
+	for i, ev := range events {
+		switch schemaVersion {
+		case SchemaVersion1:
+			ev.ComponentVersion = ""
+			ev.SchemaVersion = ""
+		case SchemaVersion2, SchemaVersion3, SchemaVersion4:
+			// same event format as the v5, except the suggested actions
+			ev.SchemaVersion = schemaVersion
+		}
+		// all the older schema versions are before the event suggested actions
+		ev.SuggestedActions = nil
+		out[i] = ev
+	}
+	return out, nil
+}
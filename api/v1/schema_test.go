@@ -0,0 +1,133 @@
+package v1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsSupportedSchemaVersion(t *testing.T) {
+	assert.True(t, IsSupportedSchemaVersion(SchemaVersion1))
+	assert.True(t, IsSupportedSchemaVersion(SchemaVersion2))
+	assert.True(t, IsSupportedSchemaVersion(SchemaVersion3))
+	assert.True(t, IsSupportedSchemaVersion(CurrentSchemaVersion))
+	assert.False(t, IsSupportedSchemaVersion(""))
+	assert.False(t, IsSupportedSchemaVersion("v99"))
+}
+
+func TestStampHealthStates(t *testing.T) {
+	assert.Nil(t, StampHealthStates(nil, "v0.5.0"))
+
+	states := HealthStates{
+		{Name: "a", Health: HealthStateTypeHealthy},
+		{Name: "b", Health: HealthStateTypeHealthy, ComponentVersion: "v1.2.3"},
+	}
+	stamped := StampHealthStates(states, "v0.5.0")
+	require.Len(t, stamped, 2)
+	assert.Equal(t, "v0.5.0", stamped[0].ComponentVersion)
+	assert.Equal(t, CurrentSchemaVersion, stamped[0].SchemaVersion)
+	assert.Equal(t, "v1.2.3", stamped[1].ComponentVersion)
+	assert.Equal(t, CurrentSchemaVersion, stamped[1].SchemaVersion)
+
+	// the input is not modified
+	assert.Empty(t, states[0].ComponentVersion)
+	assert.Empty(t, states[0].SchemaVersion)
+}
+
+func TestStampEvents(t *testing.T) {
+	assert.Nil(t, StampEvents(nil, "v0.5.0"))
+
+	events := Events{{Name: "a"}, {Name: "b", ComponentVersion: "v1.2.3"}}
+	stamped := StampEvents(events, "v0.5.0")
+	require.Len(t, stamped, 2)
+	assert.Equal(t, "v0.5.0", stamped[0].ComponentVersion)
+	assert.Equal(t, "v1.2.3", stamped[1].ComponentVersion)
+	assert.Equal(t, CurrentSchemaVersion, stamped[1].SchemaVersion)
+	assert.Empty(t, events[0].SchemaVersion)
+}
+
+func TestNormalize(t *testing.T) {
+	states := NormalizeHealthStates(HealthStates{{Name: "old"}, {Name: "new", SchemaVersion: SchemaVersion2}})
+	assert.Equal(t, SchemaVersion1, states[0].SchemaVersion)
+	assert.Equal(t, SchemaVersion2, states[1].SchemaVersion)
+
+	events := NormalizeEvents(Events{{Name: "old"}, {Name: "new", SchemaVersion: SchemaVersion2}})
+	assert.Equal(t, SchemaVersion1, events[0].SchemaVersion)
+	assert.Equal(t, SchemaVersion2, events[1].SchemaVersion)
+}
+
+func TestConvertHealthStates(t *testing.T) {
+	since := metav1.NewTime(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+	states := StampHealthStates(HealthStates{{
+		Name:            "a",
+		Health:          HealthStateTypeUnhealthy,
+		FailureCodes:    []FailureCode{FailureCodeIBPortDown},
+		Severity:        EventTypeCritical,
+		EscalationLevel: 1,
+		UnhealthySince:  &since,
+	}}, "v0.5.0")
+
+	converted, err := ConvertHealthStates(states, "")
+	require.NoError(t, err)
+	assert.Equal(t, states, converted)
+
+	converted, err = ConvertHealthStates(states, CurrentSchemaVersion)
+	require.NoError(t, err)
+	assert.Equal(t, states, converted)
+
+	converted, err = ConvertHealthStates(states, SchemaVersion4)
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersion4, converted[0].SchemaVersion)
+	assert.Equal(t, EventTypeCritical, converted[0].Severity)
+
+	converted, err = ConvertHealthStates(states, SchemaVersion3)
+	require.NoError(t, err)
+	assert.Equal(t, HealthStates{{Name: "a", Health: HealthStateTypeUnhealthy, FailureCodes: []FailureCode{FailureCodeIBPortDown}, ComponentVersion: "v0.5.0", SchemaVersion: SchemaVersion3}}, converted)
+
+	converted, err = ConvertHealthStates(states, SchemaVersion2)
+	require.NoError(t, err)
+	assert.Equal(t, HealthStates{{Name: "a", Health: HealthStateTypeUnhealthy, ComponentVersion: "v0.5.0", SchemaVersion: SchemaVersion2}}, converted)
+
+	converted, err = ConvertHealthStates(states, SchemaVersion1)
+	require.NoError(t, err)
+	assert.Equal(t, HealthStates{{Name: "a", Health: HealthStateTypeUnhealthy}}, converted)
+	assert.Equal(t, []FailureCode{FailureCodeIBPortDown}, states[0].FailureCodes)
+	assert.Equal(t, "v0.5.0", states[0].ComponentVersion)
+	assert.Equal(t, EventTypeCritical, states[0].Severity)
+
+	_, err = ConvertHealthStates(states, "v99")
+	assert.Error(t, err)
+}
+
+func TestConvertEvents(t *testing.T) {
+	events := StampEvents(Events{{Name: "a", Message: "test", SuggestedActions: &SuggestedActions{RepairActions: []RepairActionType{RepairActionTypeRebootSystem}}}}, "v0.5.0")
+
+	converted, err := ConvertEvents(events, CurrentSchemaVersion)
+	require.NoError(t, err)
+	assert.Equal(t, events, converted)
+
+	converted, err = ConvertEvents(events, SchemaVersion4)
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersion4, converted[0].SchemaVersion)
+	assert.Nil(t, converted[0].SuggestedActions)
+	assert.NotNil(t, events[0].SuggestedActions)
+
+	converted, err = ConvertEvents(events, SchemaVersion3)
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersion3, converted[0].SchemaVersion)
+
+	converted, err = ConvertEvents(events, SchemaVersion2)
+	require.NoError(t, err)
+	assert.Equal(t, "v0.5.0", converted[0].ComponentVersion)
+	assert.Equal(t, SchemaVersion2, converted[0].SchemaVersion)
+
+	converted, err = ConvertEvents(events, SchemaVersion1)
+	require.NoError(t, err)
+	assert.Equal(t, Events{{Name: "a", Message: "test"}}, converted)
+
+	_, err = ConvertEvents(events, "v99")
+	assert.Error(t, err)
+}
@@ -0,0 +1,45 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// SyntheticMessagePrefix prefixes the message of the synthetic events
+	// and the reason of the synthetic health states.
+	SyntheticMessagePrefix = "[SYNTHETIC] "
+	// SyntheticExtraInfoKey is the extra info key set to "true"
+	// for the synthetic health states and events.
+	SyntheticExtraInfoKey = "synthetic"
+)
+
+// SimulationRequest is the request to inject the synthetic event and/or health state
+// into a component, through the normal pipeline (event store, notifications, control plane),
+// for testing the alert pipelines end-to-end.
+type SimulationRequest struct {
+	// Scenario is the name of the built-in scenario (e.g., "xid-63", "ib-port-down"),
+	// which fills in the component, event, and health state.
+	// The other fields override the scenario, if set.
+	Scenario string `json:"scenario,omitempty"`
+
+	// Component is the name of the component to inject into.
+	Component string `json:"component,omitempty"`
+
+	// Event is the synthetic event to insert, if any.
+	Event *Event `json:"event,omitempty"`
+	// HealthState is the synthetic health state to report, if any.
+	HealthState *HealthState `json:"health_state,omitempty"`
+
+	// Duration is how long the synthetic health state is reported,
+	// before the component reports its actual health states again.
+	Duration metav1.Duration `json:"duration,omitempty"`
+}
+
+// SimulationResponse is the synthetic event and health state injected.
+type SimulationResponse struct {
+	Component   string       `json:"component"`
+	Event       *Event       `json:"event,omitempty"`
+	HealthState *HealthState `json:"health_state,omitempty"`
+	// ExpiresAt is when the synthetic health state expires.
+	ExpiresAt *metav1.Time `json:"expires_at,omitempty"`
+}
@@ -0,0 +1,67 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RepairActionInvasiveness returns the invasiveness of the repair action,
+// the higher the more disruptive to the workloads on the node
+// (e.g., the hardware inspection takes the node out of service
+// longer than the reboot, and the cabling check only touches the links).
+// The unknown actions rank above the user application check,
+// and the ignore action ranks the lowest.
+func RepairActionInvasiveness(action RepairActionType) int {
+	switch action {
+	case RepairActionTypeHardwareInspection:
+		return 5
+	case RepairActionTypeRebootSystem:
+		return 4
+	case RepairActionTypeCheckCabling:
+		return 3
+	case RepairActionTypeCheckUserAppAndGPU:
+		return 1
+	case RepairActionTypeIgnoreNoActionRequired:
+		return 0
+	default:
+		return 2
+	}
+}
+
+// ActionPlanStep is a repair action suggested by one or more components,
+// deduplicated across the components.
+type ActionPlanStep struct {
+	// Action is the repair action.
+	Action RepairActionType `json:"action"`
+	// Components are the components suggesting the action.
+	Components []string `json:"components"`
+	// Descriptions are the unique descriptions of the suggested actions.
+	Descriptions []string `json:"descriptions,omitempty"`
+	// FailureCodes are the failure codes of the states suggesting the action.
+	FailureCodes []FailureCode `json:"failure_codes,omitempty"`
+}
+
+// ActionPlan is the node-level plan of the repair actions
+// suggested by all the components, ordered by the invasiveness.
+type ActionPlan struct {
+	// Action is the single action to take for the node first,
+	// the most invasive of the steps, empty if no action is required.
+	Action RepairActionType `json:"action,omitempty"`
+	// Steps are the deduplicated repair actions,
+	// in the order of the most invasive first.
+	Steps []ActionPlanStep `json:"steps,omitempty"`
+}
+
+// NodeSummary is the node-level summary of the component health states.
+type NodeSummary struct {
+	// Time is when the summary was generated.
+	Time metav1.Time `json:"time"`
+	// Health is the worst health state across the components.
+	Health HealthStateType `json:"health"`
+	// UnhealthyComponents are the components with any unhealthy state.
+	UnhealthyComponents []string `json:"unhealthy_components,omitempty"`
+	// DegradedComponents are the components with any degraded state,
+	// but no unhealthy state.
+	DegradedComponents []string `json:"degraded_components,omitempty"`
+	// ActionPlan is the prioritized plan of the suggested actions.
+	ActionPlan ActionPlan `json:"action_plan"`
+}
@@ -0,0 +1,68 @@
+package v1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TimelineEntryKind is the kind of the timeline entry.
+type TimelineEntryKind string
+
+const (
+	// TimelineEntryKindEvent represents an event emitted by a component.
+	TimelineEntryKindEvent TimelineEntryKind = "event"
+
+	// TimelineEntryKindHealthTransition represents a change of the health state of a component.
+	TimelineEntryKindHealthTransition TimelineEntryKind = "health_transition"
+
+	// TimelineEntryKindMetricBreach represents a key metric crossing its threshold.
+	TimelineEntryKindMetricBreach TimelineEntryKind = "metric_breach"
+)
+
+// TimelineEntry is a single entry in the cross-component timeline.
+type TimelineEntry struct {
+	// Time represents when the entry happened.
+	Time metav1.Time `json:"time"`
+
+	// Kind represents the kind of the entry.
+	Kind TimelineEntryKind `json:"kind"`
+
+	// Component represents which component the entry belongs to.
+	Component string `json:"component"`
+
+	// Name represents the event name, the health state name, or the metric name.
+	Name string `json:"name,omitempty"`
+
+	// EventType is only set for the events.
+	EventType EventType `json:"eventType,omitempty"`
+
+	// Health is the health state after the transition,
+	// only set for the health transitions.
+	Health HealthStateType `json:"health,omitempty"`
+	// PreviousHealth is the health state before the transition,
+	// only set for the health transitions, and empty if unknown.
+	PreviousHealth HealthStateType `json:"previousHealth,omitempty"`
+
+	// Value and Threshold are only set for the metric breaches.
+	Value     float64 `json:"value,omitempty"`
+	Threshold float64 `json:"threshold,omitempty"`
+
+	// Message describes the entry.
+	Message string `json:"message,omitempty"`
+
+	// Labels represents the metric labels or the node labels.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Timeline is the chronologically ordered view of the events, the health transitions,
+// and the key metric breaches from all the components in the time range.
+type Timeline struct {
+	From    time.Time       `json:"from"`
+	To      time.Time       `json:"to"`
+	Entries []TimelineEntry `json:"entries"`
+
+	// Truncated is true if the entries are truncated to the limit,
+	// in which case the latest entries are kept.
+	Truncated bool `json:"truncated,omitempty"`
+}
@@ -82,6 +82,22 @@ type HealthState struct {
 	// SuggestedActions represents the suggested actions to mitigate the issue.
 	SuggestedActions *SuggestedActions `json:"suggested_actions,omitempty"`
 
+	// FailureCodes represents the stable machine-readable codes of the failures
+	// detected, set only when the state is not healthy.
+	// The automation should key off the codes instead of parsing the reason.
+	FailureCodes []FailureCode `json:"failure_codes,omitempty"`
+
+	// Severity represents the severity of the state, set only when the state
+	// is not healthy. The unhealthy state starts at "Warning", and escalates
+	// (e.g., to "Critical") per the escalation ladder the longer it persists.
+	Severity EventType `json:"severity,omitempty"`
+	// EscalationLevel represents the number of the escalation ladder steps
+	// reached since the state became unhealthy, zero if not escalated.
+	EscalationLevel int `json:"escalation_level,omitempty"`
+	// UnhealthySince represents when the state became unhealthy,
+	// set only when the state is not healthy.
+	UnhealthySince *metav1.Time `json:"unhealthy_since,omitempty"`
+
 	// ExtraInfo represents the extra information of the state.
 	ExtraInfo map[string]string `json:"extra_info,omitempty"`
 
@@ -90,6 +106,18 @@ type HealthState struct {
 	// is the stdout/stderr of the script.
 	// The maximum length of the raw output is 4096 bytes.
 	RawOutput string `json:"raw_output,omitempty"`
+
+	// Labels represents the node labels attached to the state
+	// (e.g., rack, cluster, tenant), for the downstream aggregation.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// ComponentVersion represents the implementation version of the component
+	// that produced the state, to handle the component-specific format changes
+	// (e.g., the extra info keys) across the gpud upgrades.
+	ComponentVersion string `json:"component_version,omitempty"`
+	// SchemaVersion represents the schema version of the state format
+	// (e.g., "v2"), empty for the gpud before the version fields were added.
+	SchemaVersion string `json:"schema_version,omitempty"`
 }
 
 type HealthStates []HealthState
@@ -119,6 +147,48 @@ type Event struct {
 
 	// Message represents the detailed message of the event.
 	Message string `json:"message,omitempty"`
+
+	// Labels represents the node labels attached to the event
+	// (e.g., rack, cluster, tenant), for the downstream aggregation.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Seq is the monotonic sequence number of the event on the node,
+	// which orders the events reliably even when the wall clock jumps
+	// (e.g., NTP step). Zero if not assigned.
+	Seq int64 `json:"seq,omitempty"`
+
+	// AffectedProcesses are the processes that were running on the affected GPU
+	// when the event occurred (e.g., Xid errors), to identify the impacted jobs.
+	AffectedProcesses []AffectedProcess `json:"affected_processes,omitempty"`
+
+	// SuggestedActions represents the suggested actions for the event, if any
+	// (e.g., set by the user-supplied kernel message rules).
+	SuggestedActions *SuggestedActions `json:"suggested_actions,omitempty"`
+
+	// ComponentVersion represents the implementation version of the component
+	// serving the event.
+	ComponentVersion string `json:"component_version,omitempty"`
+	// SchemaVersion represents the schema version of the event format
+	// (e.g., "v2"), empty for the gpud before the version fields were added.
+	SchemaVersion string `json:"schema_version,omitempty"`
+}
+
+// AffectedProcess is a process running on the GPU affected by an event.
+type AffectedProcess struct {
+	// GPUUUID is the UUID of the GPU the process was running on.
+	GPUUUID string `json:"gpu_uuid"`
+	// PID is the process ID.
+	PID uint32 `json:"pid"`
+	// CmdArgs is the command line of the process.
+	CmdArgs []string `json:"cmd_args,omitempty"`
+	// GPUUsedMemoryBytes is the GPU memory used by the process.
+	GPUUsedMemoryBytes uint64 `json:"gpu_used_memory_bytes,omitempty"`
+	// Cgroup is the cgroup path of the process.
+	Cgroup string `json:"cgroup,omitempty"`
+	// ContainerID is the ID of the container the process runs in, if any.
+	ContainerID string `json:"container_id,omitempty"`
+	// PodUID is the UID of the Kubernetes pod the process runs in, if any.
+	PodUID string `json:"pod_uid,omitempty"`
 }
 
 type Events []Event
@@ -128,6 +198,10 @@ type ComponentEvents struct {
 	StartTime time.Time `json:"startTime"`
 	EndTime   time.Time `json:"endTime"`
 	Events    Events    `json:"events"`
+
+	// NextOffset is the offset to fetch the next page of the events,
+	// and is only set if there are more events than the requested limit.
+	NextOffset int `json:"nextOffset,omitempty"`
 }
 
 type GPUdComponentEvents []ComponentEvents
@@ -198,6 +272,11 @@ const (
 	// For instance, NVIDIA may report XID 45 as user app error, but the underlying GPU might have other issues
 	// thus requires further diagnosis of the application and the GPU.
 	RepairActionTypeCheckUserAppAndGPU RepairActionType = "CHECK_USER_APP_AND_GPU"
+
+	// RepairActionTypeCheckCabling represents a suggested action to check the cables and the transceivers
+	// (e.g., reseat or replace), and the switch ports they connect to. For instance, the InfiniBand port
+	// repeatedly going down and back up often comes from the loose or the faulty cable.
+	RepairActionTypeCheckCabling RepairActionType = "CHECK_CABLING"
 )
 
 // SuggestedActions represents a set of suggested actions to mitigate an issue.
@@ -319,6 +398,9 @@ func (i *MachineInfo) RenderTable(wr io.Writer) {
 		table.Append([]string{"GPU Manufacturer", i.GPUInfo.Manufacturer})
 		table.Append([]string{"GPU Architecture", i.GPUInfo.Architecture})
 		table.Append([]string{"GPU Memory", i.GPUInfo.Memory})
+		if i.GPUInfo.VirtualizationMode != "" {
+			table.Append([]string{"GPU Virtualization Mode", i.GPUInfo.VirtualizationMode})
+		}
 	}
 
 	if i.NICInfo != nil {
@@ -368,6 +450,10 @@ type MachineGPUInfo struct {
 
 	Memory string `json:"memory,omitempty"`
 
+	// VirtualizationMode is the virtualization mode of the GPUs
+	// (e.g., "none" for bare-metal, "passthrough", "vgpu" in the guest VM).
+	VirtualizationMode string `json:"virtualizationMode,omitempty"`
+
 	// GPUs is the GPU info of the machine.
 	GPUs []MachineGPUInstance `json:"gpus,omitempty"`
 }
@@ -377,6 +463,13 @@ type MachineGPUInstance struct {
 	SN      string `json:"sn,omitempty"`
 	MinorID string `json:"minorID,omitempty"`
 	BoardID uint32 `json:"boardID,omitempty"`
+
+	// VBIOSVersion is the VBIOS version of the GPU (e.g., "96.00.89.00.01").
+	VBIOSVersion string `json:"vbiosVersion,omitempty"`
+
+	// ActiveVGPUs is the number of the virtual GPUs running on the GPU,
+	// only set for the host GPU shared as the virtual GPUs.
+	ActiveVGPUs int `json:"activeVGPUs,omitempty"`
 }
 
 func (gi *MachineGPUInfo) RenderTable(wr io.Writer) {
@@ -450,14 +543,30 @@ type MachineNetwork struct {
 	PublicIP string `json:"publicIP,omitempty"`
 	// PrivateIP is the first private IP in IPv4 family,
 	// detected from the local host.
+	// Set to the first private IPv6 address on the IPv6-only hosts.
 	// May be overridden by the user with the private IP address.
 	PrivateIP string `json:"privateIP,omitempty"`
+	// PrivateIPv6 is the first private (unique local) IP in IPv6 family,
+	// detected from the local host, for the dual-stack networks.
+	PrivateIPv6 string `json:"privateIPv6,omitempty"`
 }
 
 // MachineNICInfo consists of the network info of the machine.
 type MachineNICInfo struct {
 	// PrivateIPInterfaces is the private network interface info of the machine.
 	PrivateIPInterfaces []MachineNetworkInterface `json:"privateIPInterfaces,omitempty"`
+
+	// InfinibandDevices is the infiniband device info of the machine.
+	InfinibandDevices []MachineInfinibandDevice `json:"infinibandDevices,omitempty"`
+}
+
+// MachineInfinibandDevice is the infiniband device info of the machine.
+type MachineInfinibandDevice struct {
+	// Device is the device name (e.g., "mlx5_0").
+	Device string `json:"device,omitempty"`
+
+	// FirmwareVersion is the firmware version of the device (e.g., "28.39.1002").
+	FirmwareVersion string `json:"firmwareVersion,omitempty"`
 }
 
 // MachineNetworkInterface is the network interface info of the machine.
@@ -0,0 +1,135 @@
+package v2
+
+const (
+	// PayloadTypeIBPorts is the payload type of the InfiniBand ports.
+	PayloadTypeIBPorts = "ib-ports"
+	// PayloadVersionIBPorts is the current version of the InfiniBand ports payload.
+	PayloadVersionIBPorts = "v1"
+
+	// PayloadTypeGPUECC is the payload type of the GPU ECC modes and errors.
+	PayloadTypeGPUECC = "gpu-ecc"
+	// PayloadVersionGPUECC is the current version of the GPU ECC payload.
+	PayloadVersionGPUECC = "v1"
+
+	// PayloadTypeExtraInfo is the payload type of the v1 extra info
+	// of the components without a typed payload.
+	PayloadTypeExtraInfo = "extra-info"
+	// PayloadVersionExtraInfo is the current version of the extra info payload.
+	PayloadVersionExtraInfo = "v1"
+)
+
+var _ Payload = &IBPortsPayload{}
+
+// IBPortsPayload is the payload of the InfiniBand component.
+type IBPortsPayload struct {
+	// Ports are the InfiniBand ports from "ibstat".
+	Ports []IBPort `json:"ports"`
+	// Peers are the last known peers (e.g., the switch ports) of the ports.
+	Peers []IBPeer `json:"peers,omitempty"`
+}
+
+func (p *IBPortsPayload) PayloadType() string    { return PayloadTypeIBPorts }
+func (p *IBPortsPayload) PayloadVersion() string { return PayloadVersionIBPorts }
+
+// IBPort is the state of an InfiniBand port.
+type IBPort struct {
+	// Device is the name of the InfiniBand device (e.g., "mlx5_0").
+	Device string `json:"device"`
+	// Port is the port number on the device.
+	Port int `json:"port"`
+	// State is the logical state of the port (e.g., "Active", "Down").
+	State string `json:"state"`
+	// PhysicalState is the physical state of the port (e.g., "LinkUp", "Disabled").
+	PhysicalState string `json:"physical_state"`
+	// RateGbPerSec is the link rate in Gb/sec.
+	RateGbPerSec int `json:"rate_gb_per_sec"`
+	// LinkLayer is the link layer of the port (e.g., "InfiniBand", "Ethernet").
+	LinkLayer string `json:"link_layer,omitempty"`
+	// FirmwareVersion is the firmware version of the device.
+	FirmwareVersion string `json:"firmware_version,omitempty"`
+}
+
+// IBPeer is the remote peer (e.g., the switch port) of an InfiniBand port.
+type IBPeer struct {
+	// Device is the name of the local InfiniBand device.
+	Device string `json:"device"`
+	// LID is the base LID of the local port.
+	LID int `json:"lid"`
+	// PeerLID is the LID of the remote node.
+	PeerLID int `json:"peer_lid"`
+	// PeerPort is the port number on the remote node.
+	PeerPort int `json:"peer_port"`
+	// PeerGUID is the node GUID of the remote node.
+	PeerGUID string `json:"peer_guid,omitempty"`
+	// PeerDescription is the node description of the remote node.
+	PeerDescription string `json:"peer_description,omitempty"`
+}
+
+var _ Payload = &GPUECCPayload{}
+
+// GPUECCPayload is the payload of the GPU ECC component.
+type GPUECCPayload struct {
+	// GPUs are the ECC modes and errors per GPU.
+	GPUs []GPUECC `json:"gpus"`
+}
+
+func (p *GPUECCPayload) PayloadType() string    { return PayloadTypeGPUECC }
+func (p *GPUECCPayload) PayloadVersion() string { return PayloadVersionGPUECC }
+
+// GPUECC is the ECC mode and errors of a GPU.
+type GPUECC struct {
+	// UUID is the GPU UUID.
+	UUID string `json:"uuid"`
+
+	// ModeSupported is true if the ECC mode is supported by the GPU.
+	ModeSupported bool `json:"mode_supported"`
+	// EnabledCurrent is true if the ECC mode is currently enabled.
+	EnabledCurrent bool `json:"enabled_current"`
+	// EnabledPending is true if the ECC mode is enabled after the next reboot.
+	EnabledPending bool `json:"enabled_pending"`
+
+	// ErrorsSupported is true if the ECC error counts are supported by the GPU.
+	ErrorsSupported bool `json:"errors_supported"`
+	// Aggregate are the error counts for the lifetime of the GPU.
+	Aggregate ECCErrorCounts `json:"aggregate"`
+	// Volatile are the error counts since the driver was loaded.
+	Volatile ECCErrorCounts `json:"volatile"`
+}
+
+// ECCErrorCounts are the total ECC error counts.
+type ECCErrorCounts struct {
+	// Corrected is the number of the corrected (single bit) errors.
+	Corrected uint64 `json:"corrected"`
+	// Uncorrected is the number of the uncorrected (double bit) errors.
+	Uncorrected uint64 `json:"uncorrected"`
+}
+
+var _ Payload = &ExtraInfoPayload{}
+
+// ExtraInfoPayload is the payload of the components without a typed payload,
+// carrying the v1 extra info of the health state as is.
+type ExtraInfoPayload struct {
+	// ExtraInfo is the extra info of the v1 health state.
+	ExtraInfo map[string]string `json:"extra_info"`
+}
+
+func (p *ExtraInfoPayload) PayloadType() string    { return PayloadTypeExtraInfo }
+func (p *ExtraInfoPayload) PayloadVersion() string { return PayloadVersionExtraInfo }
+
+// payloadFactories are the published payload types,
+// to generate the JSON schemas and to decode the payloads.
+var payloadFactories = map[string]func() Payload{
+	PayloadTypeIBPorts:   func() Payload { return &IBPortsPayload{} },
+	PayloadTypeGPUECC:    func() Payload { return &GPUECCPayload{} },
+	PayloadTypeExtraInfo: func() Payload { return &ExtraInfoPayload{} },
+}
+
+// NewPayload returns the empty payload of the payload type,
+// or false if the payload type is unknown.
+func NewPayload(payloadType string) (Payload, bool) {
+	f, ok := payloadFactories[payloadType]
+	if !ok {
+		return nil, false
+	}
+	return f(), true
+}
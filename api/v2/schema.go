@@ -0,0 +1,112 @@
+package v2
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// JSONSchemaDraft is the JSON schema draft of the published payload schemas.
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// PayloadSchema is the JSON schema of a payload type and version.
+type PayloadSchema struct {
+	// Type is the type name of the payload.
+	Type string `json:"type"`
+	// Version is the version of the payload type.
+	Version string `json:"version"`
+	// Schema is the JSON schema of the payload data.
+	Schema map[string]any `json:"schema"`
+}
+
+// PayloadSchemas returns the JSON schemas of all the published payload types,
+// sorted by the type name.
+func PayloadSchemas() []PayloadSchema {
+	types := make([]string, 0, len(payloadFactories))
+	for t := range payloadFactories {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	schemas := make([]PayloadSchema, 0, len(types))
+	for _, t := range types {
+		s, _ := GetPayloadSchema(t)
+		schemas = append(schemas, s)
+	}
+	return schemas
+}
+
+// GetPayloadSchema returns the JSON schema of the payload type,
+// or false if the payload type is unknown.
+func GetPayloadSchema(payloadType string) (PayloadSchema, bool) {
+	p, ok := NewPayload(payloadType)
+	if !ok {
+		return PayloadSchema{}, false
+	}
+
+	schema := jsonSchemaOf(reflect.TypeOf(p))
+	schema["$schema"] = JSONSchemaDraft
+	schema["title"] = p.PayloadType() + "/" + p.PayloadVersion()
+	return PayloadSchema{
+		Type:    p.PayloadType(),
+		Version: p.PayloadVersion(),
+		Schema:  schema,
+	}, true
+}
+
+// jsonSchemaOf returns the JSON schema of the Go type,
+// following the "encoding/json" field names and omitempty options.
+// Only the types used by the payloads are supported.
+func jsonSchemaOf(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": jsonSchemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": jsonSchemaOf(t.Elem())}
+	case reflect.Struct:
+		props := make(map[string]any)
+		required := []string{}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			props[name] = jsonSchemaOf(f.Type)
+			if !strings.Contains(opts, "omitempty") {
+				required = append(required, name)
+			}
+		}
+		schema := map[string]any{
+			"type":                 "object",
+			"properties":           props,
+			"additionalProperties": false,
+		}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	default:
+		return map[string]any{}
+	}
+}
@@ -0,0 +1,180 @@
+// Package v2 defines the gpud API types, where the component-specific data
+// is the typed and versioned payload per component (e.g., IBPortsPayload,
+// GPUECCPayload), instead of the JSON-encoded strings in the extra info map of v1.
+package v2
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+// Payload is the typed component-specific data of the health state.
+// Each payload type is versioned on its own, bumped when its fields change,
+// and its JSON schema is published via the schemas endpoint.
+type Payload interface {
+	// PayloadType returns the type name of the payload (e.g., "ib-ports").
+	PayloadType() string
+	// PayloadVersion returns the version of the payload type (e.g., "v1").
+	PayloadVersion() string
+}
+
+// TypedPayload is the payload encoded with its type and version,
+// so that the consumers can decode it without knowing the component.
+type TypedPayload struct {
+	// Type is the type name of the payload.
+	Type string `json:"type"`
+	// Version is the version of the payload type.
+	Version string `json:"version"`
+	// Data is the JSON-encoded payload, matching the JSON schema
+	// of the payload type and version.
+	Data json.RawMessage `json:"data"`
+}
+
+// ErrPayloadTypeMismatch is returned when decoding the payload
+// into the payload of a different type.
+var ErrPayloadTypeMismatch = errors.New("payload type mismatch")
+
+// NewTypedPayload encodes the payload with its type and version.
+func NewTypedPayload(p Payload) (*TypedPayload, error) {
+	if p == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return &TypedPayload{
+		Type:    p.PayloadType(),
+		Version: p.PayloadVersion(),
+		Data:    b,
+	}, nil
+}
+
+// Decode decodes the payload into "p", which must be of the same type.
+func (tp *TypedPayload) Decode(p Payload) error {
+	if tp == nil {
+		return errors.New("no payload")
+	}
+	if tp.Type != p.PayloadType() {
+		return fmt.Errorf("%w: expected %q, got %q", ErrPayloadTypeMismatch, p.PayloadType(), tp.Type)
+	}
+	return json.Unmarshal(tp.Data, p)
+}
+
+// HealthState is the v2 health state of a component,
+// same as the v1 health state except the extra info map
+// is replaced with the typed payload.
+type HealthState struct {
+	// Time represents when the event happened.
+	Time metav1.Time `json:"time"`
+
+	// Component represents the component name.
+	Component string `json:"component,omitempty"`
+	// ComponentType represents the type of the component.
+	ComponentType apiv1.ComponentType `json:"component_type,omitempty"`
+
+	// Name is the name of the state,
+	// can be different from the component name.
+	Name string `json:"name,omitempty"`
+
+	// RunMode is the run mode of the state.
+	RunMode apiv1.RunModeType `json:"run_mode,omitempty"`
+
+	// Health represents the health level of the state.
+	Health apiv1.HealthStateType `json:"health,omitempty"`
+
+	// Reason represents what happened or detected by GPUd if it isn't healthy.
+	Reason string `json:"reason,omitempty"`
+
+	// Error represents the detailed error information.
+	Error string `json:"error,omitempty"`
+
+	// SuggestedActions represents the suggested actions to mitigate the issue.
+	SuggestedActions *apiv1.SuggestedActions `json:"suggested_actions,omitempty"`
+
+	// FailureCodes represents the stable machine-readable codes of the failures detected.
+	FailureCodes []apiv1.FailureCode `json:"failure_codes,omitempty"`
+
+	// Severity represents the severity of the unhealthy state,
+	// escalated by the escalation ladders.
+	Severity apiv1.EventType `json:"severity,omitempty"`
+	// EscalationLevel represents the number of the escalation ladder steps reached.
+	EscalationLevel int `json:"escalation_level,omitempty"`
+	// UnhealthySince represents when the state became unhealthy.
+	UnhealthySince *metav1.Time `json:"unhealthy_since,omitempty"`
+
+	// Payload is the typed component-specific data of the state,
+	// nil if the component does not provide the typed payload (yet).
+	Payload *TypedPayload `json:"payload,omitempty"`
+
+	// RawOutput represents the raw output of the health checker
+	// (e.g., the stdout/stderr of the custom plugin).
+	RawOutput string `json:"raw_output,omitempty"`
+
+	// Labels represents the node labels attached to the state.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// ComponentVersion represents the implementation version of the component.
+	ComponentVersion string `json:"component_version,omitempty"`
+}
+
+type HealthStates []HealthState
+
+type ComponentHealthStates struct {
+	Component string       `json:"component"`
+	States    HealthStates `json:"states"`
+}
+
+type GPUdComponentHealthStates []ComponentHealthStates
+
+// FromV1HealthState converts the v1 health state to v2, with the typed payload
+// in place of the extra info. If the component has no typed payload (nil),
+// the extra info, if any, is carried as the "extra-info" payload instead,
+// since the payload is the only component-specific data contract in v2.
+func FromV1HealthState(st apiv1.HealthState, p Payload) (HealthState, error) {
+	if p == nil && len(st.ExtraInfo) > 0 {
+		p = &ExtraInfoPayload{ExtraInfo: st.ExtraInfo}
+	}
+	tp, err := NewTypedPayload(p)
+	if err != nil {
+		return HealthState{}, err
+	}
+	return HealthState{
+		Time:             st.Time,
+		Component:        st.Component,
+		ComponentType:    st.ComponentType,
+		Name:             st.Name,
+		RunMode:          st.RunMode,
+		Health:           st.Health,
+		Reason:           st.Reason,
+		Error:            st.Error,
+		SuggestedActions: st.SuggestedActions,
+		FailureCodes:     st.FailureCodes,
+		Severity:         st.Severity,
+		EscalationLevel:  st.EscalationLevel,
+		UnhealthySince:   st.UnhealthySince,
+		Payload:          tp,
+		RawOutput:        st.RawOutput,
+		Labels:           st.Labels,
+		ComponentVersion: st.ComponentVersion,
+	}, nil
+}
+
+// FromV1HealthStates converts the v1 health states of a component to v2,
+// attaching the payload to each state.
+func FromV1HealthStates(states apiv1.HealthStates, p Payload) (HealthStates, error) {
+	out := make(HealthStates, 0, len(states))
+	for _, st := range states {
+		v2st, err := FromV1HealthState(st, p)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v2st)
+	}
+	return out, nil
+}
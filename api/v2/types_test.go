@@ -0,0 +1,120 @@
+package v2
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestTypedPayload(t *testing.T) {
+	p := &IBPortsPayload{
+		Ports: []IBPort{{Device: "mlx5_0", Port: 1, State: "Active", PhysicalState: "LinkUp", RateGbPerSec: 400}},
+	}
+	tp, err := NewTypedPayload(p)
+	require.NoError(t, err)
+	assert.Equal(t, PayloadTypeIBPorts, tp.Type)
+	assert.Equal(t, PayloadVersionIBPorts, tp.Version)
+
+	decoded := &IBPortsPayload{}
+	require.NoError(t, tp.Decode(decoded))
+	assert.Equal(t, p, decoded)
+
+	err = tp.Decode(&GPUECCPayload{})
+	assert.True(t, errors.Is(err, ErrPayloadTypeMismatch))
+
+	tp, err = NewTypedPayload(nil)
+	require.NoError(t, err)
+	assert.Nil(t, tp)
+	assert.Error(t, tp.Decode(&IBPortsPayload{}))
+}
+
+func TestFromV1HealthStates(t *testing.T) {
+	now := metav1.Now()
+	states := apiv1.HealthStates{
+		{
+			Time:             now,
+			Component:        "accelerator-nvidia-ecc",
+			Name:             "accelerator-nvidia-ecc",
+			Health:           apiv1.HealthStateTypeUnhealthy,
+			Reason:           "uncorrected errors",
+			FailureCodes:     []apiv1.FailureCode{apiv1.FailureCodeXIDUncorrectableECC},
+			ExtraInfo:        map[string]string{"data": "{}"},
+			ComponentVersion: "v0.5.0",
+			SchemaVersion:    apiv1.SchemaVersion2,
+			Severity:         apiv1.EventTypeCritical,
+			EscalationLevel:  2,
+			UnhealthySince:   &now,
+		},
+	}
+	p := &GPUECCPayload{GPUs: []GPUECC{{UUID: "GPU-0", Volatile: ECCErrorCounts{Uncorrected: 1}}}}
+
+	converted, err := FromV1HealthStates(states, p)
+	require.NoError(t, err)
+	require.Len(t, converted, 1)
+	assert.Equal(t, now, converted[0].Time)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, converted[0].Health)
+	assert.Equal(t, "uncorrected errors", converted[0].Reason)
+	assert.Equal(t, []apiv1.FailureCode{apiv1.FailureCodeXIDUncorrectableECC}, converted[0].FailureCodes)
+	assert.Equal(t, "v0.5.0", converted[0].ComponentVersion)
+	assert.Equal(t, apiv1.EventTypeCritical, converted[0].Severity)
+	assert.Equal(t, 2, converted[0].EscalationLevel)
+	assert.Equal(t, &now, converted[0].UnhealthySince)
+	require.NotNil(t, converted[0].Payload)
+	assert.Equal(t, PayloadTypeGPUECC, converted[0].Payload.Type)
+
+	b, err := json.Marshal(converted)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "extra_info")
+
+	// no typed payload, the extra info is carried as is
+	converted, err = FromV1HealthStates(states, nil)
+	require.NoError(t, err)
+	require.NotNil(t, converted[0].Payload)
+	assert.Equal(t, PayloadTypeExtraInfo, converted[0].Payload.Type)
+	extra := &ExtraInfoPayload{}
+	require.NoError(t, converted[0].Payload.Decode(extra))
+	assert.Equal(t, map[string]string{"data": "{}"}, extra.ExtraInfo)
+
+	// no typed payload nor extra info
+	states[0].ExtraInfo = nil
+	converted, err = FromV1HealthStates(states, nil)
+	require.NoError(t, err)
+	assert.Nil(t, converted[0].Payload)
+}
+
+func TestPayloadSchemas(t *testing.T) {
+	schemas := PayloadSchemas()
+	require.Len(t, schemas, 3)
+	assert.Equal(t, PayloadTypeExtraInfo, schemas[0].Type)
+	assert.Equal(t, PayloadTypeGPUECC, schemas[1].Type)
+	assert.Equal(t, PayloadTypeIBPorts, schemas[2].Type)
+
+	s, ok := GetPayloadSchema(PayloadTypeIBPorts)
+	require.True(t, ok)
+	assert.Equal(t, PayloadVersionIBPorts, s.Version)
+	assert.Equal(t, JSONSchemaDraft, s.Schema["$schema"])
+	assert.Equal(t, "object", s.Schema["type"])
+	assert.Equal(t, []string{"ports"}, s.Schema["required"])
+
+	props := s.Schema["properties"].(map[string]any)
+	ports := props["ports"].(map[string]any)
+	assert.Equal(t, "array", ports["type"])
+	port := ports["items"].(map[string]any)
+	portProps := port["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "integer"}, portProps["rate_gb_per_sec"])
+	assert.Contains(t, port["required"], "physical_state")
+	assert.NotContains(t, port["required"], "link_layer")
+
+	// the schema is JSON-encodable
+	_, err := json.Marshal(s)
+	require.NoError(t, err)
+
+	_, ok = GetPayloadSchema("unknown")
+	assert.False(t, ok)
+}
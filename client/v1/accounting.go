@@ -0,0 +1,75 @@
+package v1
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/httputil"
+	"github.com/leptonai/gpud/pkg/server"
+)
+
+// GetGPUAccounting returns the GPU usage records attributed to each cgroup per GPU.
+// Use WithStartTime and WithEndTime to set the time range.
+func GetGPUAccounting(ctx context.Context, addr string, opts ...OpOption) ([]apiv1.GPUUsageRecord, error) {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return nil, err
+	}
+
+	reqURL, err := url.Parse(fmt.Sprintf("%s/v1%s", addr, server.URLPathGPUAccounting))
+	if err != nil {
+		return nil, err
+	}
+	q := reqURL.Query()
+	if !op.startTime.IsZero() {
+		q.Add("from", strconv.FormatInt(op.startTime.Unix(), 10))
+	}
+	if !op.endTime.IsZero() {
+		q.Add("to", strconv.FormatInt(op.endTime.Unix(), 10))
+	}
+	reqURL.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if op.requestAcceptEncoding != "" {
+		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
+	}
+
+	resp, err := op.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errors.New("gpu accounting not enabled")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("server not ready, response not 200")
+	}
+
+	var rd io.Reader = resp.Body
+	if op.requestAcceptEncoding == httputil.RequestHeaderEncodingGzip {
+		gr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		defer gr.Close()
+		rd = gr
+	}
+
+	var records []apiv1.GPUUsageRecord
+	if err := json.NewDecoder(rd).Decode(&records); err != nil {
+		return nil, fmt.Errorf("failed to decode json: %w", err)
+	}
+	return records, nil
+}
@@ -0,0 +1,64 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestGetGPUAccounting(t *testing.T) {
+	now := time.Unix(1700000000, 0).UTC()
+	testRecords := []apiv1.GPUUsageRecord{
+		{
+			Start:        metav1.NewTime(now.Add(-5 * time.Minute)),
+			End:          metav1.NewTime(now),
+			GPUUUID:      "GPU-0",
+			PodUID:       "pod-a",
+			Cgroup:       "/kubepods/podpod-a",
+			GPUSeconds:   300,
+			EnergyJoules: 90000,
+		},
+	}
+
+	tests := []struct {
+		name          string
+		statusCode    int
+		expectedError string
+	}{
+		{name: "successful JSON response", statusCode: http.StatusOK},
+		{name: "not enabled", statusCode: http.StatusNotFound, expectedError: "gpu accounting not enabled"},
+		{name: "server error", statusCode: http.StatusInternalServerError, expectedError: "server not ready, response not 200"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v1/accounting/gpu-usage", r.URL.Path)
+				assert.Equal(t, "1699996400", r.URL.Query().Get("from"))
+				assert.Equal(t, "1700000000", r.URL.Query().Get("to"))
+
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write(mustMarshalJSON(t, testRecords))
+			}))
+			defer srv.Close()
+
+			records, err := GetGPUAccounting(context.Background(), srv.URL, WithStartTime(now.Add(-time.Hour)), WithEndTime(now))
+			if tt.expectedError != "" {
+				require.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, records, 1)
+			assert.Equal(t, "pod-a", records[0].PodUID)
+			assert.Equal(t, 300.0, records[0].GPUSeconds)
+			assert.True(t, now.Equal(records[0].End.Time))
+		})
+	}
+}
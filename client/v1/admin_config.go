@@ -0,0 +1,59 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/leptonai/gpud/pkg/config"
+	"github.com/leptonai/gpud/pkg/httputil"
+)
+
+// GetConfig returns the configuration the server is running with.
+func GetConfig(ctx context.Context, addr string, opts ...OpOption) (*config.Config, error) {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/admin/config", addr), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if op.requestContentType != "" {
+		req.Header.Set(httputil.RequestHeaderContentType, op.requestContentType)
+	}
+
+	resp, err := op.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %v received", resp.StatusCode)
+	}
+
+	cfg := new(config.Config)
+	switch op.requestContentType {
+	case httputil.RequestHeaderJSON, "":
+		if err := json.NewDecoder(resp.Body).Decode(cfg); err != nil {
+			return nil, fmt.Errorf("failed to decode json: %w", err)
+		}
+	case httputil.RequestHeaderYAML:
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read yaml: %w", err)
+		}
+		if err := yaml.Unmarshal(b, cfg); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal yaml: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported content type: %s", op.requestContentType)
+	}
+	return cfg, nil
+}
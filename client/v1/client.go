@@ -0,0 +1,202 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/config"
+	pkgcustomplugins "github.com/leptonai/gpud/pkg/custom-plugins"
+	pkgfaultinjector "github.com/leptonai/gpud/pkg/fault-injector"
+	"github.com/leptonai/gpud/pkg/gpud-manager/packages"
+	pkgnccltest "github.com/leptonai/gpud/pkg/nccl-test"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	"github.com/leptonai/gpud/pkg/server"
+)
+
+// Client is the typed client for the gpud server, which wraps the
+// package-level functions with the server address and the default options
+// (e.g., TLS config, retries), so that the callers do not need to pass
+// them on every request.
+//
+// e.g.,
+//
+//	cli := v1.NewClient("https://localhost:15132", v1.WithRetries(3))
+//	states, err := cli.GetHealthStates(ctx, v1.WithComponent("accelerator-nvidia-infiniband"))
+type Client struct {
+	addr string
+	opts []OpOption
+}
+
+// NewClient creates a new client for the server at the address,
+// with the default options applied to all requests.
+func NewClient(addr string, opts ...OpOption) *Client {
+	return &Client{
+		addr: addr,
+		opts: opts,
+	}
+}
+
+// Addr returns the server address of the client.
+func (c *Client) Addr() string {
+	return c.addr
+}
+
+// withOpts returns the default options followed by the request options,
+// so that the request options take precedence.
+func (c *Client) withOpts(opts []OpOption) []OpOption {
+	merged := make([]OpOption, 0, len(c.opts)+len(opts))
+	merged = append(merged, c.opts...)
+	return append(merged, opts...)
+}
+
+// CheckHealthz checks if the server is healthy.
+func (c *Client) CheckHealthz(ctx context.Context, opts ...OpOption) error {
+	return CheckHealthz(ctx, c.addr, c.withOpts(opts)...)
+}
+
+// BlockUntilServerReady blocks until the server is ready or the context is done.
+func (c *Client) BlockUntilServerReady(ctx context.Context, opts ...OpOption) error {
+	return BlockUntilServerReady(ctx, c.addr, c.withOpts(opts)...)
+}
+
+// GetComponents returns the names of the components registered in the server.
+func (c *Client) GetComponents(ctx context.Context, opts ...OpOption) ([]string, error) {
+	return GetComponents(ctx, c.addr, c.withOpts(opts)...)
+}
+
+// DeregisterComponent deregisters the component from the server.
+func (c *Client) DeregisterComponent(ctx context.Context, componentName string, opts ...OpOption) error {
+	return DeregisterComponent(ctx, c.addr, componentName, c.withOpts(opts)...)
+}
+
+// GetHealthStates returns the current health states of the components.
+func (c *Client) GetHealthStates(ctx context.Context, opts ...OpOption) (apiv1.GPUdComponentHealthStates, error) {
+	return GetHealthStates(ctx, c.addr, c.withOpts(opts)...)
+}
+
+// GetSummary returns the node health summary with the prioritized action plan.
+func (c *Client) GetSummary(ctx context.Context, opts ...OpOption) (*apiv1.NodeSummary, error) {
+	return GetSummary(ctx, c.addr, c.withOpts(opts)...)
+}
+
+// GetFindings returns the findings reported to the control plane,
+// with their acknowledgment status.
+func (c *Client) GetFindings(ctx context.Context, opts ...OpOption) ([]apiv1.Finding, error) {
+	return GetFindings(ctx, c.addr, c.withOpts(opts)...)
+}
+
+// GetDisruptions returns the windows of the expected disruption not ended yet.
+func (c *Client) GetDisruptions(ctx context.Context, opts ...OpOption) ([]apiv1.DisruptionWindow, error) {
+	return GetDisruptions(ctx, c.addr, c.withOpts(opts)...)
+}
+
+// AddDisruption declares the window of the expected disruption of the components.
+func (c *Client) AddDisruption(ctx context.Context, window apiv1.DisruptionWindow, opts ...OpOption) (*apiv1.DisruptionWindow, error) {
+	return AddDisruption(ctx, c.addr, window, c.withOpts(opts)...)
+}
+
+// DeleteDisruption deletes the window of the expected disruption.
+func (c *Client) DeleteDisruption(ctx context.Context, id string, opts ...OpOption) error {
+	return DeleteDisruption(ctx, c.addr, id, c.withOpts(opts)...)
+}
+
+// GetEvents returns the events of the components.
+// Use WithStartTime and WithEndTime to set the time range.
+func (c *Client) GetEvents(ctx context.Context, opts ...OpOption) (apiv1.GPUdComponentEvents, error) {
+	return GetEvents(ctx, c.addr, c.withOpts(opts)...)
+}
+
+// GetMetrics returns the metrics of the components.
+// Use WithSince to set the lookback duration.
+func (c *Client) GetMetrics(ctx context.Context, opts ...OpOption) (apiv1.GPUdComponentMetrics, error) {
+	return GetMetrics(ctx, c.addr, c.withOpts(opts)...)
+}
+
+// GetTimeline returns the events, the health transitions, and the key metric
+// breaches of the components in a single chronologically ordered view.
+// Use WithStartTime and WithEndTime to set the time range.
+func (c *Client) GetTimeline(ctx context.Context, opts ...OpOption) (*apiv1.Timeline, error) {
+	return GetTimeline(ctx, c.addr, c.withOpts(opts)...)
+}
+
+// StartGPUSampling starts the temporary high-frequency GPU sampling.
+func (c *Client) StartGPUSampling(ctx context.Context, interval time.Duration, duration time.Duration, opts ...OpOption) (*apiv1.GPUSamplingStatus, error) {
+	return StartGPUSampling(ctx, c.addr, interval, duration, c.withOpts(opts)...)
+}
+
+// StopGPUSampling stops the current high-frequency GPU sampling session.
+func (c *Client) StopGPUSampling(ctx context.Context, opts ...OpOption) error {
+	return StopGPUSampling(ctx, c.addr, c.withOpts(opts)...)
+}
+
+// GetGPUSampling returns the status of the high-frequency GPU sampling with the samples.
+func (c *Client) GetGPUSampling(ctx context.Context, opts ...OpOption) (*apiv1.GPUSamplingStatus, error) {
+	return GetGPUSampling(ctx, c.addr, c.withOpts(opts)...)
+}
+
+// GetGPUSnapshot returns the comprehensive snapshot of each GPU (or the GPU of the uuid).
+func (c *Client) GetGPUSnapshot(ctx context.Context, uuid string, opts ...OpOption) ([]nvidianvml.Snapshot, error) {
+	return GetGPUSnapshot(ctx, c.addr, uuid, c.withOpts(opts)...)
+}
+
+// GetKmsg returns the recent kernel messages annotated with the gpud matcher classifications.
+func (c *Client) GetKmsg(ctx context.Context, opts ...OpOption) ([]apiv1.KernelMessage, error) {
+	return GetKmsg(ctx, c.addr, c.withOpts(opts)...)
+}
+
+// Simulate injects the synthetic event and/or health state into a component.
+func (c *Client) Simulate(ctx context.Context, request apiv1.SimulationRequest, opts ...OpOption) (*apiv1.SimulationResponse, error) {
+	return Simulate(ctx, c.addr, request, c.withOpts(opts)...)
+}
+
+// StartNCCLTest starts the inter-node NCCL bandwidth test with the server as the leader.
+func (c *Client) StartNCCLTest(ctx context.Context, request pkgnccltest.Request, opts ...OpOption) (*pkgnccltest.Result, error) {
+	return StartNCCLTest(ctx, c.addr, request, c.withOpts(opts)...)
+}
+
+// GetNCCLTest returns the result of the last (or the running) NCCL bandwidth test led by the server.
+func (c *Client) GetNCCLTest(ctx context.Context, opts ...OpOption) (*pkgnccltest.Result, error) {
+	return GetNCCLTest(ctx, c.addr, c.withOpts(opts)...)
+}
+
+// GetInfo returns the events, states, and metrics of the components.
+func (c *Client) GetInfo(ctx context.Context, opts ...OpOption) (apiv1.GPUdComponentInfos, error) {
+	return GetInfo(ctx, c.addr, c.withOpts(opts)...)
+}
+
+// GetPluginSpecs returns the custom plugins registered in the server.
+func (c *Client) GetPluginSpecs(ctx context.Context, opts ...OpOption) (pkgcustomplugins.Specs, error) {
+	return GetPluginSpecs(ctx, c.addr, c.withOpts(opts)...)
+}
+
+// TriggerComponent triggers the component check and returns the resulting health states.
+func (c *Client) TriggerComponent(ctx context.Context, componentName string, opts ...OpOption) (apiv1.GPUdComponentHealthStates, error) {
+	return TriggerComponent(ctx, c.addr, componentName, c.withOpts(opts)...)
+}
+
+// TriggerComponentCheckByTag triggers the checks of all components with the tag.
+func (c *Client) TriggerComponentCheckByTag(ctx context.Context, tagName string, opts ...OpOption) error {
+	return TriggerComponentCheckByTag(ctx, c.addr, tagName, c.withOpts(opts)...)
+}
+
+// GetMachineInfo returns the machine information of the server.
+func (c *Client) GetMachineInfo(ctx context.Context, opts ...OpOption) (*apiv1.MachineInfo, error) {
+	return GetMachineInfo(ctx, c.addr, c.withOpts(opts)...)
+}
+
+// GetConfig returns the configuration the server is running with.
+func (c *Client) GetConfig(ctx context.Context, opts ...OpOption) (*config.Config, error) {
+	return GetConfig(ctx, c.addr, c.withOpts(opts)...)
+}
+
+// GetPackageStatus returns the status of the packages managed by the server.
+func (c *Client) GetPackageStatus(ctx context.Context, opts ...OpOption) ([]packages.PackageStatus, error) {
+	return GetPackageStatus(ctx, fmt.Sprintf("%s%s", c.addr, server.URLPathAdminPackages), c.withOpts(opts)...)
+}
+
+// InjectFault injects the fault into the server, for testing purposes.
+func (c *Client) InjectFault(ctx context.Context, request *pkgfaultinjector.Request, opts ...OpOption) error {
+	return InjectFault(ctx, c.addr, request, c.withOpts(opts)...)
+}
@@ -0,0 +1,204 @@
+package v1
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/config"
+	"github.com/leptonai/gpud/pkg/errdefs"
+	pkgfaultinjector "github.com/leptonai/gpud/pkg/fault-injector"
+	"github.com/leptonai/gpud/pkg/httputil"
+	pkgkmsgwriter "github.com/leptonai/gpud/pkg/kmsg/writer"
+)
+
+func TestClientRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]string{"cpu"})
+	}))
+	defer srv.Close()
+
+	cli := NewClient(srv.URL, WithRetries(2), WithRetryInterval(time.Millisecond))
+	components, err := cli.GetComponents(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cpu"}, components)
+	assert.Equal(t, int32(3), calls.Load())
+
+	// no retry by default
+	calls.Store(0)
+	_, err = GetComponents(context.Background(), srv.URL)
+	require.Error(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestClientRetriesExhausted(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	cli := NewClient(srv.URL, WithRetries(1), WithRetryInterval(time.Millisecond))
+	_, err := cli.GetHealthStates(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestClientNoRetryOnClientError(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	cli := NewClient(srv.URL, WithRetries(3), WithRetryInterval(time.Millisecond))
+	_, err := cli.GetHealthStates(context.Background())
+	require.ErrorIs(t, err, errdefs.ErrNotFound)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestClientQueryParams(t *testing.T) {
+	var gotQuery atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery.Store(r.URL.Query())
+		_, _ = w.Write([]byte("[]"))
+	}))
+	defer srv.Close()
+
+	cli := NewClient(srv.URL, WithComponent("cpu"))
+
+	start := time.Unix(1700000000, 0)
+	end := time.Unix(1700000600, 0)
+	_, err := cli.GetEvents(context.Background(), WithStartTime(start), WithEndTime(end), WithEventTypes("Warning", "Critical"), WithLimit(10), WithOffset(20))
+	require.NoError(t, err)
+	q := gotQuery.Load().(url.Values)
+	assert.Equal(t, []string{"cpu"}, q["components"])
+	assert.Equal(t, []string{"1700000000"}, q["startTime"])
+	assert.Equal(t, []string{"1700000600"}, q["endTime"])
+	assert.Equal(t, []string{"Warning,Critical"}, q["eventTypes"])
+	assert.Equal(t, []string{"10"}, q["limit"])
+	assert.Equal(t, []string{"20"}, q["offset"])
+
+	_, err = cli.GetMetrics(context.Background(), WithSince(time.Hour), WithStep(time.Minute), WithMetricNames("a", "b"))
+	require.NoError(t, err)
+	q = gotQuery.Load().(url.Values)
+	assert.Equal(t, []string{"cpu"}, q["components"])
+	assert.Equal(t, []string{"1h0m0s"}, q["since"])
+	assert.Equal(t, []string{"1m0s"}, q["step"])
+	assert.Equal(t, []string{"a,b"}, q["name"])
+}
+
+func TestClientTLSConfig(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("[]"))
+	}))
+	defer srv.Close()
+
+	// strict verification fails against the self-signed certificate
+	_, err := NewClient(srv.URL, WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12})).GetComponents(context.Background())
+	require.Error(t, err)
+
+	// the default client skips the verification
+	_, err = NewClient(srv.URL).GetComponents(context.Background())
+	require.NoError(t, err)
+
+	// the user-provided client takes precedence
+	_, err = NewClient(srv.URL, WithHTTPClient(srv.Client())).GetComponents(context.Background())
+	require.NoError(t, err)
+}
+
+func TestClientGetConfig(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/admin/config", r.URL.Path)
+		_ = json.NewEncoder(w).Encode(config.Config{Address: "0.0.0.0:15132"})
+	}))
+	defer srv.Close()
+
+	cfg, err := NewClient(srv.URL).GetConfig(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "0.0.0.0:15132", cfg.Address)
+}
+
+func TestClientInjectFault(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.True(t, strings.Contains(string(b), "hello"), string(b))
+
+		// fail the first request to make sure the body is re-sent on retry
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"message":"fault injected"}`))
+	}))
+	defer srv.Close()
+
+	cli := NewClient(srv.URL, WithRetries(1), WithRetryInterval(time.Millisecond))
+	err := cli.InjectFault(context.Background(), &pkgfaultinjector.Request{
+		KernelMessage: &pkgkmsgwriter.KernelMessage{Priority: pkgkmsgwriter.KernelMessagePriorityInfo, Message: "hello"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+
+	require.Error(t, cli.InjectFault(context.Background(), nil))
+}
+
+func TestClientMergesOptions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(apiv1.GPUdComponentHealthStates{{Component: r.URL.Query().Get("components")}})
+	}))
+	defer srv.Close()
+
+	cli := NewClient(srv.URL)
+	assert.Equal(t, srv.URL, cli.Addr())
+
+	states, err := cli.GetHealthStates(context.Background(), WithComponent("disk"))
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	assert.Equal(t, "disk", states[0].Component)
+}
+
+func TestClientProtobufGzip(t *testing.T) {
+	want := apiv1.GPUdComponentHealthStates{
+		{Component: "cpu", States: apiv1.HealthStates{{Name: "cpu", Health: apiv1.HealthStateTypeHealthy}}},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, httputil.RequestHeaderProtobuf, r.Header.Get(httputil.RequestHeaderContentType))
+		assert.Equal(t, httputil.RequestHeaderEncodingGzip, r.Header.Get(httputil.RequestHeaderAcceptEncoding))
+
+		b, err := want.MarshalProto()
+		require.NoError(t, err)
+		w.Header().Set("Content-Encoding", "gzip")
+		_, _ = w.Write(gzipContent(t, b))
+	}))
+	defer srv.Close()
+
+	states, err := NewClient(srv.URL, WithRequestContentTypeProtobuf(), WithAcceptEncodingGzip()).GetHealthStates(context.Background())
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	assert.Equal(t, "cpu", states[0].Component)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, states[0].States[0].Health)
+}
@@ -0,0 +1,96 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/server"
+)
+
+// GetDisruptions returns the windows of the expected disruption not ended yet.
+func GetDisruptions(ctx context.Context, addr string, opts ...OpOption) ([]apiv1.DisruptionWindow, error) {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v1%s", addr, server.URLPathDisruptions), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := op.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := checkResponseStatus(resp); err != nil {
+		return nil, err
+	}
+
+	var windows []apiv1.DisruptionWindow
+	if err := json.NewDecoder(resp.Body).Decode(&windows); err != nil {
+		return nil, fmt.Errorf("failed to decode json: %w", err)
+	}
+	return windows, nil
+}
+
+// AddDisruption declares the window of the expected disruption of the components,
+// and returns the declared window with its id.
+func AddDisruption(ctx context.Context, addr string, window apiv1.DisruptionWindow, opts ...OpOption) (*apiv1.DisruptionWindow, error) {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(window)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/v1%s", addr, server.URLPathDisruptions), bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := op.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := checkResponseStatus(resp); err != nil {
+		return nil, err
+	}
+
+	var declared apiv1.DisruptionWindow
+	if err := json.NewDecoder(resp.Body).Decode(&declared); err != nil {
+		return nil, fmt.Errorf("failed to decode json: %w", err)
+	}
+	return &declared, nil
+}
+
+// DeleteDisruption deletes the window of the expected disruption.
+func DeleteDisruption(ctx context.Context, addr string, id string, opts ...OpOption) error {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, fmt.Sprintf("%s/v1%s/%s", addr, server.URLPathDisruptions, url.PathEscape(id)), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := op.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+	return checkResponseStatus(resp)
+}
@@ -0,0 +1,63 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/errdefs"
+)
+
+func TestDisruptions(t *testing.T) {
+	start := time.Date(2026, 1, 1, 2, 0, 0, 0, time.UTC)
+	window := apiv1.DisruptionWindow{
+		Components: []string{"accelerator-nvidia-infiniband"},
+		Start:      metav1.NewTime(start),
+		End:        metav1.NewTime(start.Add(2 * time.Hour)),
+		Reason:     "IB fabric upgrade",
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			assert.Equal(t, "/v1/disruptions", r.URL.Path)
+			var req apiv1.DisruptionWindow
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, window.Components, req.Components)
+			req.ID = "w1"
+			_, _ = w.Write(mustMarshalJSON(t, req))
+		case http.MethodGet:
+			assert.Equal(t, "/v1/disruptions", r.URL.Path)
+			w1 := window
+			w1.ID = "w1"
+			_, _ = w.Write(mustMarshalJSON(t, []apiv1.DisruptionWindow{w1}))
+		case http.MethodDelete:
+			if r.URL.Path != "/v1/disruptions/w1" {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}
+	}))
+	defer srv.Close()
+
+	cli := NewClient(srv.URL)
+
+	declared, err := cli.AddDisruption(context.Background(), window)
+	require.NoError(t, err)
+	assert.Equal(t, "w1", declared.ID)
+
+	windows, err := cli.GetDisruptions(context.Background())
+	require.NoError(t, err)
+	require.Len(t, windows, 1)
+	assert.True(t, start.Equal(windows[0].Start.Time))
+
+	require.NoError(t, cli.DeleteDisruption(context.Background(), "w1"))
+	assert.ErrorIs(t, cli.DeleteDisruption(context.Background(), "w2"), errdefs.ErrNotFound)
+}
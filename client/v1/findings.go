@@ -0,0 +1,56 @@
+package v1
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/httputil"
+	"github.com/leptonai/gpud/pkg/server"
+)
+
+// GetFindings returns the findings reported to the control plane,
+// with their acknowledgment status.
+func GetFindings(ctx context.Context, addr string, opts ...OpOption) ([]apiv1.Finding, error) {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v1%s", addr, server.URLPathFindings), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if op.requestAcceptEncoding != "" {
+		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
+	}
+
+	resp, err := op.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := checkResponseStatus(resp); err != nil {
+		return nil, err
+	}
+
+	var rd io.Reader = resp.Body
+	if op.requestAcceptEncoding == httputil.RequestHeaderEncodingGzip {
+		gr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		defer gr.Close()
+		rd = gr
+	}
+
+	var findings []apiv1.Finding
+	if err := json.NewDecoder(rd).Decode(&findings); err != nil {
+		return nil, fmt.Errorf("failed to decode json: %w", err)
+	}
+	return findings, nil
+}
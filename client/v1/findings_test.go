@@ -0,0 +1,38 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestGetFindings(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/findings", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(mustMarshalJSON(t, []apiv1.Finding{
+			{ID: "a", Component: "accelerator-nvidia-infiniband", Health: apiv1.HealthStateTypeUnhealthy, AckID: "INC-1"},
+			{ID: "b", Component: "accelerator-nvidia-ecc", Health: apiv1.HealthStateTypeDegraded},
+		}))
+	}))
+	defer srv.Close()
+
+	findings, err := NewClient(srv.URL).GetFindings(context.Background())
+	require.NoError(t, err)
+	require.Len(t, findings, 2)
+	assert.True(t, findings[0].Acknowledged())
+	assert.False(t, findings[1].Acknowledged())
+
+	srv404 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv404.Close()
+	_, err = GetFindings(context.Background(), srv404.URL)
+	assert.Error(t, err)
+}
@@ -31,7 +31,7 @@ func CheckHealthz(ctx context.Context, addr string, opts ...OpOption) error {
 		return fmt.Errorf("failed to marshal expected healthz response: %w", err)
 	}
 
-	return checkHealthz(createDefaultHTTPClient(), req, exp)
+	return checkHealthz(op.newHTTPClient(), req, exp)
 }
 
 func checkHealthz(cli *http.Client, req *http.Request, exp []byte) error {
@@ -73,7 +73,7 @@ func BlockUntilServerReady(ctx context.Context, addr string, opts ...OpOption) e
 		return fmt.Errorf("failed to marshal expected healthz response: %w", err)
 	}
 
-	httpClient := createDefaultHTTPClient()
+	httpClient := op.newHTTPClient()
 
 	ticker := time.NewTicker(time.Second)
 	defer ticker.Stop()
@@ -0,0 +1,57 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/leptonai/gpud/pkg/errdefs"
+	pkgfaultinjector "github.com/leptonai/gpud/pkg/fault-injector"
+	"github.com/leptonai/gpud/pkg/server"
+)
+
+// InjectFault injects the fault (e.g., kernel message) into the server, for testing purposes.
+func InjectFault(ctx context.Context, addr string, request *pkgfaultinjector.Request, opts ...OpOption) error {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return err
+	}
+
+	if request == nil {
+		return errors.New("fault injection request is required")
+	}
+	if err := request.Validate(); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s%s", addr, server.URLPathInjectFault), bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := op.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return errdefs.ErrNotFound
+	default:
+		rb, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("failed to inject fault (status code %d): %s", resp.StatusCode, string(rb))
+	}
+}
@@ -0,0 +1,72 @@
+package v1
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/httputil"
+	"github.com/leptonai/gpud/pkg/server"
+)
+
+// GetKmsg returns the recent kernel messages annotated with the gpud matcher classifications.
+// Use WithStartTime to only return the messages after the time,
+// and WithGrep to filter the messages by the regular expression.
+func GetKmsg(ctx context.Context, addr string, opts ...OpOption) ([]apiv1.KernelMessage, error) {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return nil, err
+	}
+
+	reqURL, err := url.Parse(fmt.Sprintf("%s/v1%s", addr, server.URLPathKmsg))
+	if err != nil {
+		return nil, err
+	}
+	q := reqURL.Query()
+	if !op.startTime.IsZero() {
+		q.Add("since", op.startTime.UTC().Format(time.RFC3339))
+	}
+	if op.grep != "" {
+		q.Add("grep", op.grep)
+	}
+	reqURL.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if op.requestAcceptEncoding != "" {
+		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
+	}
+
+	resp, err := op.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := checkResponseStatus(resp); err != nil {
+		return nil, err
+	}
+
+	var rd io.Reader = resp.Body
+	if op.requestAcceptEncoding == httputil.RequestHeaderEncodingGzip {
+		gr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		defer gr.Close()
+		rd = gr
+	}
+
+	var msgs []apiv1.KernelMessage
+	if err := json.NewDecoder(rd).Decode(&msgs); err != nil {
+		return nil, fmt.Errorf("failed to decode json: %w", err)
+	}
+	return msgs, nil
+}
@@ -0,0 +1,34 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestGetKmsg(t *testing.T) {
+	since := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/kmsg", r.URL.Path)
+		assert.Equal(t, "2025-01-02T15:04:05Z", r.URL.Query().Get("since"))
+		assert.Equal(t, "NVRM", r.URL.Query().Get("grep"))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(mustMarshalJSON(t, []apiv1.KernelMessage{
+			{Message: "NVRM: Xid (PCI:0000:01:00): 79", Match: &apiv1.KernelMessageMatch{Component: "accelerator-nvidia-error-xid", EventName: "error_xid"}},
+		}))
+	}))
+	defer srv.Close()
+
+	msgs, err := NewClient(srv.URL).GetKmsg(context.Background(), WithStartTime(since), WithGrep("NVRM"))
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	require.NotNil(t, msgs[0].Match)
+	assert.Equal(t, "error_xid", msgs[0].Match.EventName)
+}
@@ -21,7 +21,7 @@ func GetMachineInfo(ctx context.Context, addr string, opts ...OpOption) (*apiv1.
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	return getMachineInfo(createDefaultHTTPClient(), req)
+	return getMachineInfo(op.newHTTPClient(), req)
 }
 
 func getMachineInfo(cli *http.Client, req *http.Request) (*apiv1.MachineInfo, error) {
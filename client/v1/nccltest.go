@@ -0,0 +1,57 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	pkgnccltest "github.com/leptonai/gpud/pkg/nccl-test"
+	"github.com/leptonai/gpud/pkg/server"
+)
+
+// StartNCCLTest starts the inter-node NCCL bandwidth test with the server as the leader.
+func StartNCCLTest(ctx context.Context, addr string, request pkgnccltest.Request, opts ...OpOption) (*pkgnccltest.Result, error) {
+	b, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	return doNCCLTest(ctx, addr, http.MethodPost, b, opts...)
+}
+
+// GetNCCLTest returns the result of the last (or the running) NCCL bandwidth test led by the server.
+func GetNCCLTest(ctx context.Context, addr string, opts ...OpOption) (*pkgnccltest.Result, error) {
+	return doNCCLTest(ctx, addr, http.MethodGet, nil, opts...)
+}
+
+func doNCCLTest(ctx context.Context, addr string, method string, body []byte, opts ...OpOption) (*pkgnccltest.Result, error) {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s/v1%s", addr, server.URLPathNCCLTest), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := op.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+	// the test is started in the background
+	if resp.StatusCode != http.StatusAccepted {
+		if err := checkResponseStatus(resp); err != nil {
+			return nil, err
+		}
+	}
+
+	var ret pkgnccltest.Result
+	if err := json.NewDecoder(resp.Body).Decode(&ret); err != nil {
+		return nil, fmt.Errorf("failed to decode json: %w", err)
+	}
+	return &ret, nil
+}
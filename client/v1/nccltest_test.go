@@ -0,0 +1,56 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/errdefs"
+	pkgnccltest "github.com/leptonai/gpud/pkg/nccl-test"
+)
+
+func TestNCCLTest(t *testing.T) {
+	started := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/nccl-test", r.URL.Path)
+
+		switch r.Method {
+		case http.MethodPost:
+			var req pkgnccltest.Request
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, pkgnccltest.ModePairwise, req.Mode)
+			started = true
+
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write(mustMarshalJSON(t, pkgnccltest.Result{Request: req, PendingLinks: 1}))
+
+		case http.MethodGet:
+			if !started {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(mustMarshalJSON(t, pkgnccltest.Result{SuspectNodes: []string{"b"}}))
+		}
+	}))
+	defer srv.Close()
+
+	cli := NewClient(srv.URL)
+
+	_, err := cli.GetNCCLTest(context.Background())
+	assert.True(t, errors.Is(err, errdefs.ErrNotFound))
+
+	result, err := cli.StartNCCLTest(context.Background(), pkgnccltest.Request{Mode: pkgnccltest.ModePairwise})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.PendingLinks)
+
+	result, err = cli.GetNCCLTest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, result.SuspectNodes)
+}
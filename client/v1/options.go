@@ -1,12 +1,33 @@
 // Package v1 provides the gpud v1 client for the server.
 package v1
 
-import "github.com/leptonai/gpud/pkg/httputil"
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/httputil"
+)
 
 type Op struct {
 	requestContentType    string
 	requestAcceptEncoding string
 	components            map[string]any
+
+	startTime   time.Time
+	endTime     time.Time
+	since       time.Duration
+	step        time.Duration
+	metricNames []string
+	eventTypes  []string
+	grep        string
+	limit       int
+	offset      int
+
+	httpClient    *http.Client
+	tlsConfig     *tls.Config
+	retries       int
+	retryInterval time.Duration
 }
 
 type OpOption func(*Op)
@@ -16,9 +37,16 @@ func (op *Op) applyOpts(opts []OpOption) error {
 		opt(op)
 	}
 
+	if op.retryInterval == 0 {
+		op.retryInterval = DefaultRetryInterval
+	}
+
 	return nil
 }
 
+// DefaultRetryInterval is the default interval between the retries.
+const DefaultRetryInterval = time.Second
+
 // WithRequestContentTypeYAML sets the request content type to YAML.
 func WithRequestContentTypeYAML() OpOption {
 	return func(op *Op) {
@@ -33,6 +61,14 @@ func WithRequestContentTypeJSON() OpOption {
 	}
 }
 
+// WithRequestContentTypeProtobuf sets the request content type to protobuf,
+// only supported by the states, events, and metrics APIs.
+func WithRequestContentTypeProtobuf() OpOption {
+	return func(op *Op) {
+		op.requestContentType = httputil.RequestHeaderProtobuf
+	}
+}
+
 // WithAcceptEncodingGzip requests gzip encoding for the response.
 func WithAcceptEncodingGzip() OpOption {
 	return func(op *Op) {
@@ -40,6 +76,8 @@ func WithAcceptEncodingGzip() OpOption {
 	}
 }
 
+// WithComponent adds the component to query.
+// If not set, the server returns the data for all components.
 func WithComponent(component string) OpOption {
 	return func(op *Op) {
 		if op.components == nil {
@@ -48,3 +86,102 @@ func WithComponent(component string) OpOption {
 		op.components[component] = nil
 	}
 }
+
+// WithStartTime sets the start time of the events query.
+func WithStartTime(t time.Time) OpOption {
+	return func(op *Op) {
+		op.startTime = t
+	}
+}
+
+// WithEndTime sets the end time of the events query.
+func WithEndTime(t time.Time) OpOption {
+	return func(op *Op) {
+		op.endTime = t
+	}
+}
+
+// WithEventTypes filters the events by the types (e.g., "Warning", "Critical").
+func WithEventTypes(eventTypes ...string) OpOption {
+	return func(op *Op) {
+		op.eventTypes = append(op.eventTypes, eventTypes...)
+	}
+}
+
+// WithGrep filters the kernel messages by the regular expression.
+func WithGrep(pattern string) OpOption {
+	return func(op *Op) {
+		op.grep = pattern
+	}
+}
+
+// WithLimit sets the maximum number of events to return per component.
+func WithLimit(limit int) OpOption {
+	return func(op *Op) {
+		op.limit = limit
+	}
+}
+
+// WithOffset sets the number of events to skip per component,
+// to fetch the next page of the events (see "NextOffset" in the response).
+func WithOffset(offset int) OpOption {
+	return func(op *Op) {
+		op.offset = offset
+	}
+}
+
+// WithSince sets the lookback duration of the metrics query
+// (e.g., 30 minutes to read the metrics of the last 30 minutes).
+func WithSince(since time.Duration) OpOption {
+	return func(op *Op) {
+		op.since = since
+	}
+}
+
+// WithStep sets the step to downsample the metrics query, by averaging
+// the data points of each series in each step.
+func WithStep(step time.Duration) OpOption {
+	return func(op *Op) {
+		op.step = step
+	}
+}
+
+// WithMetricNames filters the metrics query by the metric names.
+func WithMetricNames(names ...string) OpOption {
+	return func(op *Op) {
+		op.metricNames = append(op.metricNames, names...)
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the requests.
+// If set, it takes precedence over the TLS config.
+func WithHTTPClient(cli *http.Client) OpOption {
+	return func(op *Op) {
+		op.httpClient = cli
+	}
+}
+
+// WithTLSConfig sets the TLS config of the default HTTP client
+// (e.g., to verify the server certificate, or to present a client certificate).
+// If not set, the server certificate is not verified, since gpud serves
+// a self-signed certificate by default.
+func WithTLSConfig(cfg *tls.Config) OpOption {
+	return func(op *Op) {
+		op.tlsConfig = cfg
+	}
+}
+
+// WithRetries sets the number of retries on the connection errors
+// and the server errors (5xx). Zero (default) disables the retries.
+func WithRetries(retries int) OpOption {
+	return func(op *Op) {
+		op.retries = retries
+	}
+}
+
+// WithRetryInterval sets the interval between the retries.
+func WithRetryInterval(interval time.Duration) OpOption {
+	return func(op *Op) {
+		op.retryInterval = interval
+	}
+}
@@ -22,7 +22,7 @@ func GetPackageStatus(ctx context.Context, url string, opts ...OpOption) ([]pack
 		return nil, err
 	}
 
-	resp, err := createDefaultHTTPClient().Do(req)
+	resp, err := op.do(req)
 	if err != nil {
 		return nil, err
 	}
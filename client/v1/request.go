@@ -0,0 +1,97 @@
+package v1
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// newHTTPClient returns the HTTP client for the requests,
+// in the order of the user-provided client, the client with the
+// user-provided TLS config, and the default client.
+func (op *Op) newHTTPClient() *http.Client {
+	if op.httpClient != nil {
+		return op.httpClient
+	}
+	if op.tlsConfig != nil {
+		return &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: op.tlsConfig,
+			},
+		}
+	}
+	return createDefaultHTTPClient()
+}
+
+// do sends the request and retries on the connection errors
+// and the server errors (5xx), up to the configured number of retries.
+// The request is only retried if its body can be re-read.
+func (op *Op) do(req *http.Request) (*http.Response, error) {
+	cli := op.newHTTPClient()
+
+	for attempt := 0; ; attempt++ {
+		resp, err := cli.Do(req)
+		if !shouldRetry(resp, err) || attempt >= op.retries || !rewindBody(req) {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		log.Logger.Debugw("retrying request", "url", req.URL.String(), "attempt", attempt+1, "status", statusCode(resp), "error", err)
+		select {
+		case <-req.Context().Done():
+			return nil, fmt.Errorf("context done while retrying: %w", req.Context().Err())
+		case <-time.After(op.retryInterval):
+		}
+	}
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode >= http.StatusInternalServerError
+}
+
+// rewindBody resets the request body for the retry, and returns false
+// if the body cannot be re-read.
+func rewindBody(req *http.Request) bool {
+	if req.Body == nil || req.Body == http.NoBody {
+		return true
+	}
+	if req.GetBody == nil {
+		return false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return false
+	}
+	req.Body = body
+	return true
+}
+
+func statusCode(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
+
+// checkResponseStatus returns errdefs.ErrNotFound for 404,
+// and the error with the response body for the other non-200 status codes.
+func checkResponseStatus(resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return errdefs.ErrNotFound
+	default:
+		rb, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(rb))
+	}
+}
@@ -0,0 +1,131 @@
+package v1
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/httputil"
+	"github.com/leptonai/gpud/pkg/server"
+)
+
+// StartGPUSampling starts the temporary high-frequency sampling of the GPU
+// utilization, power, and clocks, which stops automatically after the duration.
+// Zero duration uses the server default.
+func StartGPUSampling(ctx context.Context, addr string, interval time.Duration, duration time.Duration, opts ...OpOption) (*apiv1.GPUSamplingStatus, error) {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(apiv1.GPUSamplingRequest{
+		Interval: metav1.Duration{Duration: interval},
+		Duration: metav1.Duration{Duration: duration},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/v1%s", addr, server.URLPathGPUSampling), bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := op.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := checkResponseStatus(resp); err != nil {
+		return nil, err
+	}
+
+	var st apiv1.GPUSamplingStatus
+	if err := json.NewDecoder(resp.Body).Decode(&st); err != nil {
+		return nil, fmt.Errorf("failed to decode json: %w", err)
+	}
+	return &st, nil
+}
+
+// StopGPUSampling stops the current high-frequency sampling session, if any.
+func StopGPUSampling(ctx context.Context, addr string, opts ...OpOption) error {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, fmt.Sprintf("%s/v1%s", addr, server.URLPathGPUSampling), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := op.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+	return checkResponseStatus(resp)
+}
+
+// GetGPUSampling returns the status of the high-frequency sampling with the collected samples.
+// Use WithStartTime to only return the samples after the time
+// (e.g., the time of the last sample received).
+func GetGPUSampling(ctx context.Context, addr string, opts ...OpOption) (*apiv1.GPUSamplingStatus, error) {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return nil, err
+	}
+
+	reqURL, err := url.Parse(fmt.Sprintf("%s/v1%s", addr, server.URLPathGPUSampling))
+	if err != nil {
+		return nil, err
+	}
+	if !op.startTime.IsZero() {
+		q := reqURL.Query()
+		q.Add("since", op.startTime.UTC().Format(time.RFC3339Nano))
+		reqURL.RawQuery = q.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if op.requestAcceptEncoding != "" {
+		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
+	}
+
+	resp, err := op.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := checkResponseStatus(resp); err != nil {
+		return nil, err
+	}
+
+	var rd io.Reader = resp.Body
+	if op.requestAcceptEncoding == httputil.RequestHeaderEncodingGzip {
+		gr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		defer gr.Close()
+		rd = gr
+	}
+
+	var st apiv1.GPUSamplingStatus
+	if err := json.NewDecoder(rd).Decode(&st); err != nil {
+		return nil, fmt.Errorf("failed to decode json: %w", err)
+	}
+	return &st, nil
+}
@@ -0,0 +1,68 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/errdefs"
+)
+
+func TestGPUSampling(t *testing.T) {
+	now := time.Unix(1700000000, 500000000).UTC()
+	testStatus := apiv1.GPUSamplingStatus{
+		Active:   true,
+		Interval: metav1.Duration{Duration: 100 * time.Millisecond},
+		Samples: []apiv1.GPUSample{
+			{Time: metav1.NewMicroTime(now), UUID: "GPU-0", PowerUsageMilliWatts: 300000},
+		},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/gpu/sampling", r.URL.Path)
+		switch r.Method {
+		case http.MethodPost:
+			var req apiv1.GPUSamplingRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, 100*time.Millisecond, req.Interval.Duration)
+			assert.Equal(t, time.Minute, req.Duration.Duration)
+		case http.MethodGet:
+			assert.Equal(t, "2023-11-14T22:13:20.5Z", r.URL.Query().Get("since"))
+		case http.MethodDelete:
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(mustMarshalJSON(t, testStatus))
+	}))
+	defer srv.Close()
+
+	cli := NewClient(srv.URL)
+
+	st, err := cli.StartGPUSampling(context.Background(), 100*time.Millisecond, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, st.Active)
+
+	st, err = cli.GetGPUSampling(context.Background(), WithStartTime(now))
+	require.NoError(t, err)
+	require.Len(t, st.Samples, 1)
+	assert.True(t, now.Equal(st.Samples[0].Time.Time))
+
+	require.NoError(t, cli.StopGPUSampling(context.Background()))
+}
+
+func TestGPUSamplingNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	_, err := GetGPUSampling(context.Background(), srv.URL)
+	assert.ErrorIs(t, err, errdefs.ErrNotFound)
+}
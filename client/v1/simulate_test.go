@@ -0,0 +1,38 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestSimulate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/simulate", r.URL.Path)
+
+		var req apiv1.SimulationRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "xid-63", req.Scenario)
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(mustMarshalJSON(t, apiv1.SimulationResponse{
+			Component: "accelerator-nvidia-error-xid",
+			Event:     &apiv1.Event{Name: "error_xid", Message: "[SYNTHETIC] NVRM: Xid (PCI:0000:04:00): 63"},
+		}))
+	}))
+	defer srv.Close()
+
+	resp, err := NewClient(srv.URL).Simulate(context.Background(), apiv1.SimulationRequest{Scenario: "xid-63"})
+	require.NoError(t, err)
+	assert.Equal(t, "accelerator-nvidia-error-xid", resp.Component)
+	require.NotNil(t, resp.Event)
+	assert.Equal(t, "error_xid", resp.Event.Name)
+}
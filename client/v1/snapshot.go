@@ -0,0 +1,68 @@
+package v1
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/leptonai/gpud/pkg/httputil"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	"github.com/leptonai/gpud/pkg/server"
+)
+
+// GetGPUSnapshot returns the comprehensive snapshot of each GPU,
+// equivalent in coverage to "nvidia-smi -q".
+// Pass a non-empty uuid to only return the snapshot of the GPU.
+func GetGPUSnapshot(ctx context.Context, addr string, uuid string, opts ...OpOption) ([]nvidianvml.Snapshot, error) {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return nil, err
+	}
+
+	reqURL, err := url.Parse(fmt.Sprintf("%s/v1%s", addr, server.URLPathGPUSnapshot))
+	if err != nil {
+		return nil, err
+	}
+	if uuid != "" {
+		q := reqURL.Query()
+		q.Add("uuid", uuid)
+		reqURL.RawQuery = q.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if op.requestAcceptEncoding != "" {
+		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
+	}
+
+	resp, err := op.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := checkResponseStatus(resp); err != nil {
+		return nil, err
+	}
+
+	var rd io.Reader = resp.Body
+	if op.requestAcceptEncoding == httputil.RequestHeaderEncodingGzip {
+		gr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		defer gr.Close()
+		rd = gr
+	}
+
+	var snapshots []nvidianvml.Snapshot
+	if err := json.NewDecoder(rd).Decode(&snapshots); err != nil {
+		return nil, fmt.Errorf("failed to decode json: %w", err)
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,30 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+)
+
+func TestGetGPUSnapshot(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/gpu/snapshot", r.URL.Path)
+		assert.Equal(t, "GPU-1", r.URL.Query().Get("uuid"))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(mustMarshalJSON(t, []nvidianvml.Snapshot{
+			{UUID: "GPU-1", Fan: nvidianvml.Fan{UUID: "GPU-1", Supported: true, SpeedPercents: []uint32{40}}},
+		}))
+	}))
+	defer srv.Close()
+
+	snapshots, err := NewClient(srv.URL).GetGPUSnapshot(context.Background(), "GPU-1")
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, []uint32{40}, snapshots[0].Fan.SpeedPercents)
+}
@@ -0,0 +1,74 @@
+package v1
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/httputil"
+	"github.com/leptonai/gpud/pkg/server"
+)
+
+// GetSummary returns the node health summary with the prioritized action plan.
+// Use WithComponent to only summarize the specific components.
+func GetSummary(ctx context.Context, addr string, opts ...OpOption) (*apiv1.NodeSummary, error) {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return nil, err
+	}
+
+	reqURL, err := url.Parse(fmt.Sprintf("%s/v1%s", addr, server.URLPathSummary))
+	if err != nil {
+		return nil, err
+	}
+	q := reqURL.Query()
+	if len(op.components) > 0 {
+		components := make([]string, 0, len(op.components))
+		for component := range op.components {
+			components = append(components, component)
+		}
+		sort.Strings(components)
+		q.Add("components", strings.Join(components, ","))
+	}
+	reqURL.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if op.requestAcceptEncoding != "" {
+		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
+	}
+
+	resp, err := op.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := checkResponseStatus(resp); err != nil {
+		return nil, err
+	}
+
+	var rd io.Reader = resp.Body
+	if op.requestAcceptEncoding == httputil.RequestHeaderEncodingGzip {
+		gr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		defer gr.Close()
+		rd = gr
+	}
+
+	var summary apiv1.NodeSummary
+	if err := json.NewDecoder(rd).Decode(&summary); err != nil {
+		return nil, fmt.Errorf("failed to decode json: %w", err)
+	}
+	return &summary, nil
+}
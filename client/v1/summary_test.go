@@ -0,0 +1,38 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestGetSummary(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/summary", r.URL.Path)
+		assert.Equal(t, "a,b", r.URL.Query().Get("components"))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(mustMarshalJSON(t, apiv1.NodeSummary{
+			Health:     apiv1.HealthStateTypeUnhealthy,
+			ActionPlan: apiv1.ActionPlan{Action: apiv1.RepairActionTypeRebootSystem},
+		}))
+	}))
+	defer srv.Close()
+
+	summary, err := NewClient(srv.URL).GetSummary(context.Background(), WithComponent("b"), WithComponent("a"))
+	require.NoError(t, err)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, summary.Health)
+	assert.Equal(t, apiv1.RepairActionTypeRebootSystem, summary.ActionPlan.Action)
+
+	srv404 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv404.Close()
+	_, err = GetSummary(context.Background(), srv404.URL)
+	assert.Error(t, err)
+}
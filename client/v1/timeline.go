@@ -0,0 +1,85 @@
+package v1
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/httputil"
+	"github.com/leptonai/gpud/pkg/server"
+)
+
+// GetTimeline returns the events, the health transitions, and the key metric breaches
+// of the components in a single chronologically ordered view.
+// Use WithStartTime and WithEndTime to set the time range, and WithLimit to set
+// the maximum number of the entries.
+func GetTimeline(ctx context.Context, addr string, opts ...OpOption) (*apiv1.Timeline, error) {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return nil, err
+	}
+
+	reqURL, err := url.Parse(fmt.Sprintf("%s/v1%s", addr, server.URLPathTimeline))
+	if err != nil {
+		return nil, err
+	}
+	q := reqURL.Query()
+	if len(op.components) > 0 {
+		components := make([]string, 0, len(op.components))
+		for component := range op.components {
+			components = append(components, component)
+		}
+		q.Add("components", strings.Join(components, ","))
+	}
+	if !op.startTime.IsZero() {
+		q.Add("from", strconv.FormatInt(op.startTime.Unix(), 10))
+	}
+	if !op.endTime.IsZero() {
+		q.Add("to", strconv.FormatInt(op.endTime.Unix(), 10))
+	}
+	if op.limit > 0 {
+		q.Add("limit", strconv.Itoa(op.limit))
+	}
+	reqURL.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if op.requestAcceptEncoding != "" {
+		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
+	}
+
+	resp, err := op.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("server not ready, response not 200")
+	}
+
+	var rd io.Reader = resp.Body
+	if op.requestAcceptEncoding == httputil.RequestHeaderEncodingGzip {
+		gr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		defer gr.Close()
+		rd = gr
+	}
+
+	var tl apiv1.Timeline
+	if err := json.NewDecoder(rd).Decode(&tl); err != nil {
+		return nil, fmt.Errorf("failed to decode json: %w", err)
+	}
+	return &tl, nil
+}
@@ -0,0 +1,78 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/httputil"
+)
+
+func TestGetTimeline(t *testing.T) {
+	now := time.Unix(1700000000, 0).UTC()
+	testTimeline := apiv1.Timeline{
+		From: now.Add(-time.Hour),
+		To:   now,
+		Entries: []apiv1.TimelineEntry{
+			{Time: metav1.NewTime(now), Kind: apiv1.TimelineEntryKindEvent, Component: "comp1", Name: "xid"},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		statusCode     int
+		acceptEncoding string
+		expectedError  string
+	}{
+		{name: "successful JSON response", statusCode: http.StatusOK},
+		{name: "successful gzipped JSON response", statusCode: http.StatusOK, acceptEncoding: httputil.RequestHeaderEncodingGzip},
+		{name: "server error", statusCode: http.StatusInternalServerError, expectedError: "server not ready, response not 200"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v1/timeline", r.URL.Path)
+				assert.Equal(t, "comp1", r.URL.Query().Get("components"))
+				assert.Equal(t, "1699996400", r.URL.Query().Get("from"))
+				assert.Equal(t, "1700000000", r.URL.Query().Get("to"))
+				assert.Equal(t, "10", r.URL.Query().Get("limit"))
+
+				w.WriteHeader(tt.statusCode)
+				b := mustMarshalJSON(t, testTimeline)
+				if tt.acceptEncoding != "" {
+					b = gzipContent(t, b)
+				}
+				_, _ = w.Write(b)
+			}))
+			defer srv.Close()
+
+			opts := []OpOption{
+				WithComponent("comp1"),
+				WithStartTime(now.Add(-time.Hour)),
+				WithEndTime(now),
+				WithLimit(10),
+			}
+			if tt.acceptEncoding != "" {
+				opts = append(opts, WithAcceptEncodingGzip())
+			}
+
+			tl, err := GetTimeline(context.Background(), srv.URL, opts...)
+			if tt.expectedError != "" {
+				require.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, tl.Entries, 1)
+			assert.Equal(t, "xid", tl.Entries[0].Name)
+			assert.True(t, now.Equal(tl.Entries[0].Time.Time))
+			assert.True(t, testTimeline.From.Equal(tl.From))
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"sigs.k8s.io/yaml"
@@ -37,7 +38,7 @@ func GetComponents(ctx context.Context, addr string, opts ...OpOption) ([]string
 		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
 	}
 
-	resp, err := createDefaultHTTPClient().Do(req)
+	resp, err := op.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %w", err)
 	}
@@ -136,7 +137,7 @@ func DeregisterComponent(ctx context.Context, addr string, componentName string,
 		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
 	}
 
-	resp, err := createDefaultHTTPClient().Do(req)
+	resp, err := op.do(req)
 	if err != nil {
 		return fmt.Errorf("failed to make request: %w", err)
 	}
@@ -215,7 +216,7 @@ func GetInfo(ctx context.Context, addr string, opts ...OpOption) (v1.GPUdCompone
 		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
 	}
 
-	resp, err := createDefaultHTTPClient().Do(req)
+	resp, err := op.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %w", err)
 	}
@@ -338,7 +339,7 @@ func GetHealthStates(ctx context.Context, addr string, opts ...OpOption) (v1.GPU
 		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
 	}
 
-	resp, err := createDefaultHTTPClient().Do(req)
+	resp, err := op.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %w", err)
 	}
@@ -382,6 +383,14 @@ func ReadHealthStates(rd io.Reader, opts ...OpOption) (v1.GPUdComponentHealthSta
 			if err := yaml.Unmarshal(b, &states); err != nil {
 				return nil, fmt.Errorf("failed to unmarshal yaml: %w", err)
 			}
+		case httputil.RequestHeaderProtobuf:
+			b, err := io.ReadAll(gr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read protobuf: %w", err)
+			}
+			if err := states.UnmarshalProto(b); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal protobuf: %w", err)
+			}
 		default:
 			return nil, fmt.Errorf("unsupported content type: %s", op.requestContentType)
 		}
@@ -400,6 +409,14 @@ func ReadHealthStates(rd io.Reader, opts ...OpOption) (v1.GPUdComponentHealthSta
 			if err := yaml.Unmarshal(b, &states); err != nil {
 				return nil, fmt.Errorf("failed to unmarshal yaml: %w", err)
 			}
+		case httputil.RequestHeaderProtobuf:
+			b, err := io.ReadAll(rd)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read protobuf: %w", err)
+			}
+			if err := states.UnmarshalProto(b); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal protobuf: %w", err)
+			}
 		default:
 			return nil, fmt.Errorf("unsupported content type: %s", op.requestContentType)
 		}
@@ -414,7 +431,36 @@ func GetEvents(ctx context.Context, addr string, opts ...OpOption) (v1.GPUdCompo
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v1/events", addr), nil)
+	reqURL, err := url.Parse(fmt.Sprintf("%s/v1/events", addr))
+	if err != nil {
+		return nil, err
+	}
+	q := reqURL.Query()
+	if len(op.components) > 0 {
+		components := make([]string, 0, len(op.components))
+		for component := range op.components {
+			components = append(components, component)
+		}
+		q.Add("components", strings.Join(components, ","))
+	}
+	if !op.startTime.IsZero() {
+		q.Add("startTime", strconv.FormatInt(op.startTime.Unix(), 10))
+	}
+	if !op.endTime.IsZero() {
+		q.Add("endTime", strconv.FormatInt(op.endTime.Unix(), 10))
+	}
+	if len(op.eventTypes) > 0 {
+		q.Add("eventTypes", strings.Join(op.eventTypes, ","))
+	}
+	if op.limit > 0 {
+		q.Add("limit", strconv.Itoa(op.limit))
+	}
+	if op.offset > 0 {
+		q.Add("offset", strconv.Itoa(op.offset))
+	}
+	reqURL.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -425,7 +471,7 @@ func GetEvents(ctx context.Context, addr string, opts ...OpOption) (v1.GPUdCompo
 		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
 	}
 
-	resp, err := createDefaultHTTPClient().Do(req)
+	resp, err := op.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %w", err)
 	}
@@ -465,6 +511,14 @@ func ReadEvents(rd io.Reader, opts ...OpOption) (v1.GPUdComponentEvents, error)
 			if err := yaml.Unmarshal(b, &evs); err != nil {
 				return nil, fmt.Errorf("failed to unmarshal yaml: %w", err)
 			}
+		case httputil.RequestHeaderProtobuf:
+			b, err := io.ReadAll(gr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read protobuf: %w", err)
+			}
+			if err := evs.UnmarshalProto(b); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal protobuf: %w", err)
+			}
 		default:
 			return nil, fmt.Errorf("unsupported content type: %s", op.requestContentType)
 		}
@@ -483,6 +537,14 @@ func ReadEvents(rd io.Reader, opts ...OpOption) (v1.GPUdComponentEvents, error)
 			if err := yaml.Unmarshal(b, &evs); err != nil {
 				return nil, fmt.Errorf("failed to unmarshal yaml: %w", err)
 			}
+		case httputil.RequestHeaderProtobuf:
+			b, err := io.ReadAll(rd)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read protobuf: %w", err)
+			}
+			if err := evs.UnmarshalProto(b); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal protobuf: %w", err)
+			}
 		default:
 			return nil, fmt.Errorf("unsupported content type: %s", op.requestContentType)
 		}
@@ -497,7 +559,30 @@ func GetMetrics(ctx context.Context, addr string, opts ...OpOption) (v1.GPUdComp
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v1/metrics", addr), nil)
+	reqURL, err := url.Parse(fmt.Sprintf("%s/v1/metrics", addr))
+	if err != nil {
+		return nil, err
+	}
+	q := reqURL.Query()
+	if len(op.components) > 0 {
+		components := make([]string, 0, len(op.components))
+		for component := range op.components {
+			components = append(components, component)
+		}
+		q.Add("components", strings.Join(components, ","))
+	}
+	if op.since > 0 {
+		q.Add("since", op.since.String())
+	}
+	if op.step > 0 {
+		q.Add("step", op.step.String())
+	}
+	if len(op.metricNames) > 0 {
+		q.Add("name", strings.Join(op.metricNames, ","))
+	}
+	reqURL.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -508,7 +593,7 @@ func GetMetrics(ctx context.Context, addr string, opts ...OpOption) (v1.GPUdComp
 		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
 	}
 
-	resp, err := createDefaultHTTPClient().Do(req)
+	resp, err := op.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %w", err)
 	}
@@ -548,6 +633,14 @@ func ReadMetrics(rd io.Reader, opts ...OpOption) (v1.GPUdComponentMetrics, error
 			if err := yaml.Unmarshal(b, &metrics); err != nil {
 				return nil, fmt.Errorf("failed to unmarshal yaml: %w", err)
 			}
+		case httputil.RequestHeaderProtobuf:
+			b, err := io.ReadAll(gr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read protobuf: %w", err)
+			}
+			if err := metrics.UnmarshalProto(b); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal protobuf: %w", err)
+			}
 		default:
 			return nil, fmt.Errorf("unsupported content type: %s", op.requestContentType)
 		}
@@ -566,6 +659,14 @@ func ReadMetrics(rd io.Reader, opts ...OpOption) (v1.GPUdComponentMetrics, error
 			if err := yaml.Unmarshal(b, &metrics); err != nil {
 				return nil, fmt.Errorf("failed to unmarshal yaml: %w", err)
 			}
+		case httputil.RequestHeaderProtobuf:
+			b, err := io.ReadAll(rd)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read protobuf: %w", err)
+			}
+			if err := metrics.UnmarshalProto(b); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal protobuf: %w", err)
+			}
 		default:
 			return nil, fmt.Errorf("unsupported content type: %s", op.requestContentType)
 		}
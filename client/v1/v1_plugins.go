@@ -41,7 +41,7 @@ func GetPluginSpecs(ctx context.Context, addr string, opts ...OpOption) (pkgcust
 		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
 	}
 
-	resp, err := createDefaultHTTPClient().Do(req)
+	resp, err := op.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %w", err)
 	}
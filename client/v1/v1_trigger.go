@@ -45,7 +45,7 @@ func TriggerComponent(ctx context.Context, addr string, componentName string, op
 		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
 	}
 
-	resp, err := createDefaultHTTPClient().Do(req)
+	resp, err := op.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %w", err)
 	}
@@ -96,7 +96,7 @@ func TriggerComponentCheckByTag(ctx context.Context, addr string, tagName string
 		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
 	}
 
-	resp, err := createDefaultHTTPClient().Do(req)
+	resp, err := op.do(req)
 	if err != nil {
 		return fmt.Errorf("failed to make request: %w", err)
 	}
@@ -0,0 +1,168 @@
+// Package certify implements the "certify" command.
+package certify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/urfave/cli"
+
+	cmdcommon "github.com/leptonai/gpud/cmd/common"
+	pkgcertify "github.com/leptonai/gpud/pkg/certify"
+	pkglabels "github.com/leptonai/gpud/pkg/labels"
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/scan"
+	"github.com/leptonai/gpud/pkg/toolpath"
+	"github.com/leptonai/gpud/version"
+)
+
+// DefaultCertificatePath is the default path to write the certificate to,
+// with the signature written to the path with the ".sig" suffix.
+const DefaultCertificatePath = "gpud-certificate.json"
+
+func CommandRun(cliContext *cli.Context) error {
+	logLevel := cliContext.String("log-level")
+	zapLvl, err := log.ParseLogLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	log.Logger = log.CreateLogger(zapLvl, "")
+
+	log.Logger.Debugw("starting certify command")
+
+	// fail before running the checks on the invalid certification key
+	signPrivPath := cliContext.String("sign-priv-path")
+	if signPrivPath == "" {
+		return fmt.Errorf("--sign-priv-path is required")
+	}
+	signPrivRaw, err := os.ReadFile(signPrivPath)
+	if err != nil {
+		return err
+	}
+	signPrivKey, err := pkgcertify.ParseKey(signPrivRaw)
+	if err != nil {
+		return err
+	}
+
+	toolPaths, err := pkglabels.Parse(cliContext.String("tool-paths"))
+	if err != nil {
+		return err
+	}
+	if err := toolpath.SetOverrides(toolPaths); err != nil {
+		return err
+	}
+
+	certPath := cliContext.String("output-path")
+	if certPath == "" {
+		certPath = DefaultCertificatePath
+	}
+
+	// the deep scan enforces its own time budget
+	result, err := scan.Run(
+		context.Background(),
+		scan.WithProfile(scan.ProfileDeep),
+	)
+	if err != nil {
+		return err
+	}
+
+	cert, err := pkgcertify.New(result, time.Now(), version.Version)
+	if err != nil {
+		fmt.Printf("%s %v\n", cmdcommon.WarningSign, err)
+		return err
+	}
+	doc, err := cert.Marshal()
+	if err != nil {
+		return err
+	}
+	sig, err := pkgcertify.Sign(doc, signPrivKey)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(certPath, doc, 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(certPath+pkgcertify.SignatureSuffix, sig, 0644); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s node certified, wrote the certificate to %s (signature %s%s)\n", cmdcommon.CheckMark, certPath, certPath, pkgcertify.SignatureSuffix)
+	return nil
+}
+
+func CommandVerify(cliContext *cli.Context) error {
+	logLevel := cliContext.String("log-level")
+	zapLvl, err := log.ParseLogLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	log.Logger = log.CreateLogger(zapLvl, "")
+
+	log.Logger.Debugw("starting certify verify command")
+
+	signPubBundle, err := os.ReadFile(cliContext.String("sign-pub-path"))
+	if err != nil {
+		return err
+	}
+	certPath := cliContext.String("certificate-path")
+	if certPath == "" {
+		certPath = DefaultCertificatePath
+	}
+	doc, err := os.ReadFile(certPath)
+	if err != nil {
+		return err
+	}
+	sigPath := cliContext.String("sig-path")
+	if sigPath == "" {
+		sigPath = certPath + pkgcertify.SignatureSuffix
+	}
+	sig, err := os.ReadFile(sigPath)
+	if err != nil {
+		return err
+	}
+
+	cert, err := pkgcertify.Verify(doc, sig, signPubBundle)
+	if err != nil {
+		return err
+	}
+
+	var machineID string
+	if cert.MachineInfo != nil {
+		machineID = cert.MachineInfo.MachineID
+	}
+	fmt.Printf("%s certificate ok (machine %s, issued at %s by gpud %s, %d component(s) checked)\n", cmdcommon.CheckMark, machineID, cert.IssuedAt.Format(time.RFC3339), cert.GPUdVersion, len(cert.HealthStates))
+	return nil
+}
+
+func CommandGenKey(cliContext *cli.Context) error {
+	logLevel := cliContext.String("log-level")
+	zapLvl, err := log.ParseLogLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	log.Logger = log.CreateLogger(zapLvl, "")
+
+	log.Logger.Debugw("starting certify gen-key command")
+
+	priv, pub, err := pkgcertify.GenerateKey()
+	if err != nil {
+		return fmt.Errorf("failed to generate key pair: %w", err)
+	}
+
+	privPath := cliContext.String("priv-path")
+	if err := os.WriteFile(privPath, priv, 0400); err != nil {
+		return fmt.Errorf("failed writing private key: %w", err)
+	}
+	fmt.Println("wrote private key to", privPath)
+
+	pubPath := cliContext.String("pub-path")
+	if err := os.WriteFile(pubPath, pub, 0400); err != nil {
+		return fmt.Errorf("failed writing public key: %w", err)
+	}
+	fmt.Println("wrote public key to", pubPath)
+
+	return nil
+}
@@ -2,12 +2,18 @@ package command
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/urfave/cli"
 
+	cmdcertify "github.com/leptonai/gpud/cmd/gpud/certify"
 	cmdcompact "github.com/leptonai/gpud/cmd/gpud/compact"
 	cmdcustomplugins "github.com/leptonai/gpud/cmd/gpud/custom-plugins"
+	cmddiagnose "github.com/leptonai/gpud/cmd/gpud/diagnose"
+	cmddoctor "github.com/leptonai/gpud/cmd/gpud/doctor"
 	cmddown "github.com/leptonai/gpud/cmd/gpud/down"
+	cmddriver "github.com/leptonai/gpud/cmd/gpud/driver"
+	cmdexport "github.com/leptonai/gpud/cmd/gpud/export"
 	cmdinjectfault "github.com/leptonai/gpud/cmd/gpud/inject-fault"
 	cmdjoin "github.com/leptonai/gpud/cmd/gpud/join"
 	cmdlistplugins "github.com/leptonai/gpud/cmd/gpud/list-plugins"
@@ -15,16 +21,28 @@ import (
 	cmdlogout "github.com/leptonai/gpud/cmd/gpud/logout"
 	cmdmachineinfo "github.com/leptonai/gpud/cmd/gpud/machine-info"
 	cmdmetadata "github.com/leptonai/gpud/cmd/gpud/metadata"
+	cmdnccltest "github.com/leptonai/gpud/cmd/gpud/nccl-test"
 	cmdnotify "github.com/leptonai/gpud/cmd/gpud/notify"
 	cmdrelease "github.com/leptonai/gpud/cmd/gpud/release"
 	cmdrun "github.com/leptonai/gpud/cmd/gpud/run"
 	cmdrunplugingroup "github.com/leptonai/gpud/cmd/gpud/run-plugin-group"
 	cmdscan "github.com/leptonai/gpud/cmd/gpud/scan"
+	cmdsimulate "github.com/leptonai/gpud/cmd/gpud/simulate"
 	cmdstatus "github.com/leptonai/gpud/cmd/gpud/status"
 	cmdup "github.com/leptonai/gpud/cmd/gpud/up"
 	cmdupdate "github.com/leptonai/gpud/cmd/gpud/update"
+	"github.com/leptonai/gpud/components"
+	pkgbaseline "github.com/leptonai/gpud/pkg/baseline"
 	pkgconfig "github.com/leptonai/gpud/pkg/config"
 	pkgcustomplugins "github.com/leptonai/gpud/pkg/custom-plugins"
+	pkgdevmode "github.com/leptonai/gpud/pkg/devmode"
+	pkgdiagnose "github.com/leptonai/gpud/pkg/diagnose"
+	pkgmaintenance "github.com/leptonai/gpud/pkg/maintenance"
+	pkgnccltest "github.com/leptonai/gpud/pkg/nccl-test"
+	pkgoutput "github.com/leptonai/gpud/pkg/output"
+	pkgdistsign "github.com/leptonai/gpud/pkg/release/distsign"
+	pkgscan "github.com/leptonai/gpud/pkg/scan"
+	pkgsimulate "github.com/leptonai/gpud/pkg/simulate"
 	"github.com/leptonai/gpud/version"
 )
 
@@ -43,6 +61,52 @@ func App() *cli.App {
 	app.Version = version.Version
 	app.Usage = usage
 	app.Description = "GPU health checkers"
+	app.Flags = []cli.Flag{pkgoutput.Flag}
+	app.Before = pkgoutput.RequireSupported
+
+	driverFlags := []cli.Flag{
+		&cli.StringFlag{
+			Name:  "log-level,l",
+			Usage: "set the logging level [debug, info, warn, error, fatal, panic, dpanic]",
+		},
+		&cli.StringFlag{
+			Name:  "version",
+			Usage: "NVIDIA driver version to install (e.g., 550.90.07)",
+		},
+		&cli.StringFlag{
+			Name:  "method",
+			Usage: "driver install method [apt, dnf, runfile] (default: detected from /etc/os-release)",
+		},
+		&cli.StringFlag{
+			Name:  "runfile-url",
+			Usage: "URL to download the runfile installer from, only for the runfile method (default: NVIDIA download site)",
+		},
+		&cli.StringFlag{
+			Name:  "sha256",
+			Usage: "expected sha256 checksum of the runfile installer, required for the runfile method",
+		},
+		&cli.BoolFlag{
+			Name:  "drain",
+			Usage: "drain the GPU workloads before the install, by running the drain command and terminating the GPU processes",
+		},
+		&cli.StringFlag{
+			Name:  "drain-command",
+			Usage: "command to run to drain the node before the install (e.g., 'kubectl drain $(hostname) --ignore-daemonsets'), only with --drain",
+		},
+		&cli.BoolFlag{
+			Name:  "reboot",
+			Usage: "reboot the node after the successful install to load the driver",
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "print the install plan without running it",
+		},
+		&cli.DurationFlag{
+			Name:  "timeout",
+			Usage: "timeout of the driver operation",
+			Value: time.Hour,
+		},
+	}
 
 	app.Commands = []cli.Command{
 		{
@@ -51,6 +115,9 @@ func App() *cli.App {
 			UsageText: `# to start gpud as a systemd unit (recommended)
 sudo gpud up
 
+# to limit the resources of the systemd unit, without restarting the running gpud
+sudo gpud up --cpu-quota 50% --memory-max 2GB --nice 10 --no-restart
+
 # to enable machine monitoring powered by lepton.ai platform
 # sign up here: https://lepton.ai
 sudo gpud up --token <LEPTON_AI_TOKEN>
@@ -77,6 +144,10 @@ nohup sudo gpud run &>> <your log file path> &
 					Name:  "private-ip",
 					Usage: "(optional) can specify private ip for internal network",
 				},
+				cli.StringFlag{
+					Name:  "private-ipv6",
+					Usage: "(optional) can specify private ipv6 for internal dual-stack network",
+				},
 				cli.StringFlag{
 					Name:  "public-ip",
 					Usage: "(optional) can specify public ip for machine",
@@ -92,13 +163,43 @@ nohup sudo gpud run &>> <your log file path> &
 				},
 				cli.StringFlag{
 					Name:  "endpoint",
-					Usage: "(optional) endpoint for checking in",
+					Usage: "(optional) endpoint for checking in (comma-separated for the failover, the first being the primary)",
 					Value: "gpud-manager-prod01.dgxc-lepton.nvidia.com",
 				},
 				cli.StringFlag{
 					Name:  "gpu-count",
 					Usage: "(optional) specify count of gpu (leave empty to auto-detect)",
 				},
+
+				// optional, to customize the systemd unit
+				cli.StringFlag{
+					Name:  "cpu-quota",
+					Usage: "(optional) CPU quota of the systemd unit (e.g., '50%', '200%' for two cores)",
+				},
+				cli.StringFlag{
+					Name:  "memory-max",
+					Usage: "(optional) memory limit of the systemd unit (e.g., '2GB', 'infinity')",
+				},
+				cli.IntFlag{
+					Name:  "nice",
+					Usage: "(optional) nice level of the systemd unit, between -20 and 19 (default: 0 to not set)",
+				},
+				cli.StringSliceFlag{
+					Name:  "env",
+					Usage: "(optional) environment variable of the systemd unit in the KEY=VALUE format (repeat for multiple)",
+				},
+				cli.StringFlag{
+					Name:  "extra-flags",
+					Usage: "(optional) extra flags passed to 'gpud run' (e.g., '--web-ui --retention-period=72h')",
+				},
+				cli.BoolFlag{
+					Name:  "install-security-policy",
+					Usage: "(optional) install the SELinux/AppArmor policy allowing the denials affecting gpud (e.g., module loading, /dev/kmsg, NVML)",
+				},
+				cli.BoolFlag{
+					Name:  "no-restart",
+					Usage: "(optional) install/update the systemd unit without restarting the running gpud (applied on the next restart)",
+				},
 			},
 		},
 		{
@@ -140,13 +241,121 @@ sudo rm /etc/systemd/system/gpud.service
 				},
 				&cli.StringFlag{
 					Name:  "listen-address",
-					Usage: "set the listen address",
-					Value: fmt.Sprintf("0.0.0.0:%d", pkgconfig.DefaultGPUdPort),
+					Usage: "set the listen address (the default listens on all the IPv4 and IPv6 addresses, e.g., '0.0.0.0:15132' for IPv4 only, '[::1]:15132' for IPv6 loopback)",
+					Value: fmt.Sprintf(":%d", pkgconfig.DefaultGPUdPort),
 				},
 				&cli.BoolFlag{
 					Name:  "pprof",
 					Usage: "enable pprof (default: false)",
 				},
+				&cli.BoolFlag{
+					Name:  "enable-pci-rescan",
+					Usage: "remove and rescan the GPUs that fell off the PCI bus (e.g., Xid 79) to try recovering them before suggesting a reboot (default: false)",
+				},
+				&cli.StringFlag{
+					Name:  "latency-targets",
+					Usage: "(optional) comma-separated targets to measure the network latencies against in addition to the edge servers, in the format of '[<name>[@<region>]=]<host:port or URL>' (e.g., 'control-plane=https://cp.example.com,storage@us-east-1=10.0.0.5:2049')",
+				},
+				&cli.StringFlag{
+					Name:  "peer-mesh-peers",
+					Usage: "(optional) comma-separated peer gpud nodes to probe for the latencies and the packet loss, in the format of '[<id>[@<rack>]=]<host:port>' (e.g., 'node-2@rack-1=10.0.1.2:15132,node-3@rack-2=10.0.2.3:15132'), the control plane may override the peers",
+				},
+				&cli.BoolFlag{
+					Name:   "enable-component-fault-injection",
+					Usage:  "allow the inject-fault API to make the components return unhealthy results, time out, or panic on demand, for the chaos testing (NOT for production, default: false)",
+					Hidden: true,
+				},
+				&cli.BoolFlag{
+					Name:  "dev",
+					Usage: "run with the fake NVML and InfiniBand backends with the synthetic GPUs, to develop and test the integrations on the machines without any GPU (NOT for production, default: false)",
+				},
+				&cli.IntFlag{
+					Name:  "dev-gpus",
+					Usage: "number of the synthetic GPUs in the dev mode",
+					Value: pkgdevmode.DefaultGPUs,
+				},
+				&cli.StringFlag{
+					Name:  "dev-product-name",
+					Usage: "product name of the synthetic GPUs in the dev mode",
+					Value: pkgdevmode.DefaultProductName,
+				},
+				&cli.StringFlag{
+					Name:  "dev-scenarios",
+					Usage: fmt.Sprintf("(optional) comma-separated failure scenarios to inject in the dev mode (supported: %v)", pkgdevmode.AllScenarios()),
+				},
+				&cli.StringFlag{
+					Name:  "memory-ceiling",
+					Usage: "(optional) RSS ceiling of the gpud process (e.g., 2GiB), once exceeded gpud records an event and restarts itself (leave empty to disable)",
+				},
+				&cli.StringFlag{
+					Name:  "heap-ceiling",
+					Usage: "(optional) Go heap ceiling of the gpud process (e.g., 1GiB), once exceeded gpud records an event and restarts itself (leave empty to disable)",
+				},
+				&cli.BoolFlag{
+					Name:  "web-ui",
+					Usage: "serve the built-in web dashboard at the root path, e.g., https://localhost:15132 (default: false)",
+				},
+				&cli.StringFlag{
+					Name:  "metrics-archive-dir",
+					Usage: "(optional) directory to archive the metrics older than the retention period into, in parquet files (leave empty to delete the old metrics)",
+				},
+				&cli.StringFlag{
+					Name:  "metrics-archive-upload",
+					Usage: "(optional) destination to upload the archived metrics files to (e.g., s3://bucket/prefix, gs://bucket/prefix, azblob://account/container/prefix)",
+				},
+				&cli.StringFlag{
+					Name:  "annotations",
+					Usage: "(optional) node labels attached to every health state, event, and metric, either in JSON (e.g., '{\"rack\":\"r1\"}') or comma-separated key=value (e.g., 'rack=r1,tenant=a')",
+				},
+				&cli.StringFlag{
+					Name:  "tool-paths",
+					Usage: "(optional) paths of the external tools to run instead of auto-discovering them, either in JSON (e.g., '{\"ibstat\":\"/opt/ofed/bin/ibstat\"}') or comma-separated name=path (e.g., 'ibstat=/opt/ofed/bin/ibstat,lspci=/sbin/lspci')",
+				},
+				&cli.StringFlag{
+					Name:  "tool-resource-limits",
+					Usage: "(optional) comma-separated CPU and memory caps of the external tools run by each component (or '*' for all the components) in the format of '<component>=<cpus>:<memory max>' (e.g., 'accelerator-nvidia-infiniband=0.5:512MiB,*=1:1GiB', leave empty to not cap the tools)",
+				},
+				&cli.StringFlag{
+					Name:  "component-intervals",
+					Usage: "(optional) comma-separated intervals of the periodic checks per component in the format of '<component>=<duration>' (e.g., 'accelerator-nvidia-infiniband=30s,disk=5m', leave empty to check all the components every minute, failing the startup on the unknown component names)",
+				},
+				&cli.StringFlag{
+					Name:  "component-check-budgets",
+					Usage: "(optional) comma-separated durations each component check is expected to complete within in the format of '<component>=<duration>' (e.g., 'accelerator-nvidia-infiniband=30s', leave empty to warn on the checks consistently taking longer than 10s, failing the startup on the unknown component names)",
+				},
+				&cli.Float64Flag{
+					Name:  "defer-checks-cpu-percent",
+					Usage: "(optional) node CPU utilization in percent, at and above which the low priority checks (e.g., network probes) are deferred or downscoped to minimize the interference with the workloads (0 to ignore the CPU utilization)",
+				},
+				&cli.Float64Flag{
+					Name:  "defer-checks-gpu-percent",
+					Usage: "(optional) highest GPU utilization in percent, at and above which the low priority checks are deferred or downscoped (0 to ignore the GPU utilization)",
+				},
+				&cli.DurationFlag{
+					Name:  "defer-checks-max",
+					Usage: "(optional) longest time the low priority checks are deferred for under load",
+					Value: components.DefaultMaxDeferral,
+				},
+				&cli.DurationFlag{
+					Name:  "baseline-learning-period",
+					Usage: fmt.Sprintf("(optional) period after the node joins to learn its normal values (e.g., GPU count, infiniband ports and rate, nvlinks, mount points), proposed as the thresholds to accept at /v1/baseline/accept (e.g., %s, leave empty to disable)", pkgbaseline.DefaultLearningPeriod),
+				},
+				&cli.StringFlag{
+					Name:  "gds-probe-dir",
+					Usage: "(optional) directory on the GPUDirect Storage compatible filesystem to probe the GDS read throughput in with 'gdsio' every hour (leave empty to only validate the GDS prerequisites)",
+				},
+				&cli.IntFlag{
+					Name:  "gds-probe-gpu-index",
+					Usage: "(optional) GPU to read the GDS probe file into",
+				},
+				&cli.Float64Flag{
+					Name:  "gds-probe-min-throughput-gibps",
+					Usage: "(optional) GDS read throughput in GiB/s below which the GDS is degraded (e.g., fell back to the compatibility mode) (0 to only report the throughput)",
+				},
+				&cli.StringFlag{
+					Name:  "prediction-model",
+					Usage: "(optional) failure prediction model: 'baseline' for the built-in model, 'http(s)://...' for the scoring endpoint, or 'exec:<path>' for the local command reading the window in JSON from stdin (leave empty to disable)",
+				},
 				&cli.DurationFlag{
 					Name:  "retention-period",
 					Usage: "set the time period to retain metrics for (once elapsed, old records are compacted/purged)",
@@ -161,6 +370,77 @@ sudo rm /etc/systemd/system/gpud.service
 					Usage: "specifies the exit code to exit with when auto updating (default: -1 to disable exit code)",
 					Value: -1,
 				},
+				cli.StringFlag{
+					Name:  "event-sinks-file",
+					Usage: "sets the YAML file of the event notification sinks (e.g., webhooks) with the per-sink routing rules by component glob, minimum severity, and rate limit (leave empty to disable)",
+				},
+				cli.StringFlag{
+					Name:  "escalation-ladder-file",
+					Usage: "sets the YAML file of the escalation ladders that escalate the severity of the unhealthy states the longer they persist (e.g., warning to critical after 30m), with the additional sinks and actions per step (leave empty to disable)",
+				},
+				cli.StringFlag{
+					Name:  "hooks-file",
+					Usage: "sets the YAML file of the hooks that run the local commands on the matching health transitions (e.g., run /etc/gpud/hooks/ib-down.sh once the infiniband component is unhealthy), with the state in the environment variables and the output recorded as the component events (leave empty to disable)",
+				},
+				cli.StringFlag{
+					Name:  "remediation-policy-file",
+					Usage: "sets the YAML file of the policy mapping the component and failure class to the repair actions gpud may perform automatically and their cooldowns (leave empty to only suggest the actions)",
+				},
+				cli.StringFlag{
+					Name:  "error-budget-file",
+					Usage: "sets the YAML file of the budgets of the correctable hardware errors per period (e.g., 100 corrected ECC errors per 24h, counters: ecc_corrected, ib_symbol_error, pcie_replay), marking the node as degraded once any budget is exhausted (leave empty to disable)",
+				},
+				cli.StringFlag{
+					Name:  "artifact-sign-pub-path",
+					Usage: "(optional) sets the bundle of the public artifact keys (generated by 'gpud release gen-key --artifact') to verify the plugin specs file and the config files with their '.sig' signatures before applying, failing the startup and rejecting the plugin specs from the control plane if not verified (leave empty to disable)",
+				},
+				cli.BoolFlag{
+					Name:  "defer-maintenance-kubelet",
+					Usage: "(optional) defers the automatic remediations and the auto-updates while the pods requesting the GPUs are running on the node, listed from the authenticated kubelet API (see --defer-maintenance-kubelet-token-file and --defer-maintenance-kubelet-ca-file)",
+				},
+				cli.StringFlag{
+					Name:  "defer-maintenance-kubelet-token-file",
+					Usage: "(optional) bearer token file authorized to list the pods from the kubelet (e.g., the service account token with the 'nodes/proxy' get), read on every request",
+					Value: pkgmaintenance.DefaultKubeletTokenFile,
+				},
+				cli.StringFlag{
+					Name:  "defer-maintenance-kubelet-ca-file",
+					Usage: "(optional) CA file to verify the kubelet serving certificate",
+					Value: pkgmaintenance.DefaultKubeletCAFile,
+				},
+				cli.StringFlag{
+					Name:  "defer-maintenance-hook",
+					Usage: "(optional) command run with bash to tell whether the node is busy with the jobs (exit 0 if idle, 1 if busy with the output as the reason), to defer the automatic remediations and the auto-updates (leave empty to disable)",
+				},
+				&cli.DurationFlag{
+					Name:  "defer-maintenance-max",
+					Usage: "(optional) longest time the automatic remediations and the auto-updates are deferred for while the node is busy, before forcing them",
+					Value: pkgmaintenance.DefaultMaxDeferral,
+				},
+				cli.BoolFlag{
+					Name:  "enable-gpu-accounting",
+					Usage: "enables the GPU usage accounting that attributes the GPU-seconds and energy per cgroup/pod, served at /v1/accounting/gpu-usage in JSON or CSV",
+				},
+				cli.BoolFlag{
+					Name:  "enable-prometheus",
+					Usage: "exports the per-component health states, the check durations, and the infiniband port states on the /metrics endpoint for Prometheus to scrape, in addition to the GPU metrics",
+				},
+				cli.StringFlag{
+					Name:  "otlp-endpoint",
+					Usage: "(optional) sets the OpenTelemetry collector OTLP/HTTP endpoint (e.g., 'http://otel-collector:4318', or 'host:port' for HTTPS) to export the component check spans (latency, health, errors) and metrics to (leave empty to disable)",
+				},
+				cli.StringFlag{
+					Name:  "kmsg-matchers-file",
+					Usage: "sets the YAML file of the user-supplied kernel message matchers with the regex, owner component, event type, and suggested action (leave empty to use the built-in matchers only)",
+				},
+				cli.StringFlag{
+					Name:  "infiniband-evaluation-file",
+					Usage: "sets the YAML file of the infiniband port drop duration, flap window, minimum flap transitions, and error counter delta thresholds, reloaded whenever the file changes (leave empty to use the defaults)",
+				},
+				cli.StringFlag{
+					Name:  "lldp-cabling-map-file",
+					Usage: "sets the YAML file of the expected switch and port per interface (optionally per host) to validate the LLDP neighbors against, flagging the miscabled nodes (leave empty to only record the LLDP neighbors)",
+				},
 				cli.StringFlag{
 					Name:  "plugin-specs-file",
 					Usage: "sets the plugin specs file (leave empty for default) -- if the file does not exist, gpud does not install/run any plugin, and updated configuration requires an gpud restart)",
@@ -171,18 +451,6 @@ sudo rm /etc/systemd/system/gpud.service
 					Usage: "sets the components to enable (comma-separated, leave empty for default to enable all components, set 'none' or any other non-matching value to disable all components, prefix component name with '-' to disable it)",
 					Value: "",
 				},
-				cli.StringFlag{
-					Name:   "ibstat-command",
-					Usage:  "sets the ibstat command (leave empty for default, useful for testing)",
-					Value:  "ibstat",
-					Hidden: true, // only for testing
-				},
-				cli.StringFlag{
-					Name:   "ibstatus-command",
-					Usage:  "sets the ibstatus command (leave empty for default, useful for testing)",
-					Value:  "ibstatus",
-					Hidden: true, // only for testing
-				},
 			},
 		},
 		{
@@ -239,6 +507,10 @@ sudo rm /etc/systemd/system/gpud.service
 							Name:  "signing (default: false)",
 							Usage: "generate signing key",
 						},
+						cli.BoolFlag{
+							Name:  "artifact",
+							Usage: "generate artifact key to sign the plugin specs and the config files with",
+						},
 						cli.StringFlag{
 							Name:  "priv-path",
 							Usage: "path of private key",
@@ -272,6 +544,46 @@ sudo rm /etc/systemd/system/gpud.service
 						},
 					},
 				},
+				{
+					Name:  "rotate-key",
+					Usage: "Issue a new signing key signed with a root key, and the transition bundle accepting both the new and the current signing keys during the overlap",
+					UsageText: `# to rotate the signing key, publish the transition bundle as distsign.pub and its signature as distsign.pub.sig,
+# and sign the new packages with the new signing key (the current keys are accepted by 'gpud update' until the overlap ends)
+gpud release rotate-key --root-priv-path root.priv --sign-pub-path distsign.pub --overlap 720h --priv-path signing.new.priv --pub-path distsign.pub.new --sig-path distsign.pub.new.sig
+`,
+					Action: cmdrelease.CommandRotateKey,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "log-level,l",
+							Usage: "set the logging level [debug, info, warn, error, fatal, panic, dpanic]",
+						},
+						cli.StringFlag{
+							Name:  "root-priv-path",
+							Usage: "path of root private key",
+						},
+						cli.StringFlag{
+							Name:  "sign-pub-path",
+							Usage: "path of the current signing public keys' bundle",
+						},
+						cli.DurationFlag{
+							Name:  "overlap",
+							Usage: "window during which the current signing keys are still accepted after the rotation",
+							Value: pkgdistsign.DefaultRotationOverlap,
+						},
+						cli.StringFlag{
+							Name:  "priv-path",
+							Usage: "output path of the new signing private key",
+						},
+						cli.StringFlag{
+							Name:  "pub-path",
+							Usage: "output path of the transition bundle",
+						},
+						cli.StringFlag{
+							Name:  "sig-path",
+							Usage: "output path of the transition bundle signature",
+						},
+					},
+				},
 				{
 					Name:   "verify-key-signature",
 					Usage:  "Verify a root signture of the signing keys' bundle",
@@ -318,6 +630,33 @@ sudo rm /etc/systemd/system/gpud.service
 						},
 					},
 				},
+				{
+					Name:   "sign-artifact",
+					Usage:  "Sign an artifact (e.g., plugin specs, config files) as its type with an artifact key",
+					Action: cmdrelease.CommandSignArtifact,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "log-level,l",
+							Usage: "set the logging level [debug, info, warn, error, fatal, panic, dpanic]",
+						},
+						cli.StringFlag{
+							Name:  "type",
+							Usage: fmt.Sprintf("type of the artifact, covered by the signature (one of %q)", pkgdistsign.ArtifactTypes),
+						},
+						cli.StringFlag{
+							Name:  "artifact-path",
+							Usage: "path of artifact",
+						},
+						cli.StringFlag{
+							Name:  "sign-priv-path",
+							Usage: "path of artifact private key",
+						},
+						cli.StringFlag{
+							Name:  "sig-path",
+							Usage: "output path of signature (leave empty for the artifact path with the '.sig' suffix)",
+						},
+					},
+				},
 				{
 					Name:   "verify-package-signature",
 					Usage:  "Verify a package signture using a signing key",
@@ -378,6 +717,8 @@ sudo rm /etc/systemd/system/gpud.service
 			Usage:   "checks the status of gpud",
 			Action:  cmdstatus.Command,
 			Flags: []cli.Flag{
+				pkgoutput.Flag,
+				pkgoutput.JSONFlag,
 				&cli.StringFlag{
 					Name:  "log-level,l",
 					Usage: "set the logging level [debug, info, warn, error, fatal, panic, dpanic]",
@@ -386,6 +727,26 @@ sudo rm /etc/systemd/system/gpud.service
 					Name:  "watch,w",
 					Usage: "watch for package install status",
 				},
+				&cli.StringFlag{
+					Name:  "components",
+					Usage: "comma-separated list of components to print the health states of (e.g., 'cpu,disk'), skipping the other status checks",
+				},
+				&cli.StringFlag{
+					Name:  "fields",
+					Usage: "comma-separated list of health state fields to print as tab-separated values [component, name, health, reason, error, updated, suggested_actions] (default: component,health,reason)",
+				},
+				&cli.IntFlag{
+					Name:  "history",
+					Usage: "print the current health state and the last N health transitions of each component with the timestamps (e.g., --history 5), skipping the other status checks (default: 0 to disable)",
+				},
+				&cli.StringFlag{
+					Name:  "save-snapshot",
+					Usage: "save the current health states and inventory to the file (e.g., before the maintenance), to compare against later with --diff, skipping the other status checks",
+				},
+				&cli.StringFlag{
+					Name:  "diff",
+					Usage: "print what changed in the health states and inventory since the snapshot file saved with --save-snapshot, or since the time in the health history as a RFC3339 timestamp or a duration ago (e.g., --diff 24h), skipping the other status checks",
+				},
 			},
 		},
 		{
@@ -399,12 +760,97 @@ sudo rm /etc/systemd/system/gpud.service
 				},
 			},
 		},
+		{
+			Name:  "driver",
+			Usage: "install or upgrade the NVIDIA driver with the distro-appropriate method",
+			Subcommands: []cli.Command{
+				{
+					Name:  "install",
+					Usage: "install the NVIDIA driver on the node without the driver",
+					UsageText: `# to print the install plan
+sudo gpud driver install --version 550.90.07 --dry-run
+
+# to install the driver and reboot to load it
+sudo gpud driver install --version 550.90.07 --reboot
+
+# to install the driver with the runfile installer
+sudo gpud driver install --version 550.90.07 --method runfile --sha256 <SHA256>
+`,
+					Action: cmddriver.CommandInstall,
+					Flags:  driverFlags,
+				},
+				{
+					Name:  "upgrade",
+					Usage: "upgrade the installed NVIDIA driver to the newer version",
+					UsageText: `# to drain the node, upgrade the driver, and reboot to load it
+sudo gpud driver upgrade --version 570.86.15 --drain --drain-command "kubectl drain $(hostname) --ignore-daemonsets" --reboot
+`,
+					Action: cmddriver.CommandUpgrade,
+					Flags:  driverFlags,
+				},
+			},
+		},
+		{
+			Name:  "export",
+			Usage: "exports the events and the latest health check results from the running GPUd into a JSONL file",
+			UsageText: `# to export the events in the last 72 hours
+gpud export --since 72h --out events.jsonl
+`,
+			Action: cmdexport.Command,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "log-level,l",
+					Usage: "set the logging level [debug, info, warn, error, fatal, panic, dpanic]",
+				},
+				&cli.DurationFlag{
+					Name:  "since",
+					Usage: "set the time period to export the events for",
+					Value: 72 * time.Hour,
+				},
+				&cli.StringFlag{
+					Name:  "out,o",
+					Usage: "set the output file path (set empty or '-' to stdout)",
+				},
+				&cli.StringFlag{
+					Name:  "format",
+					Usage: "set the output format [jsonl]",
+					Value: "jsonl",
+				},
+				&cli.StringFlag{
+					Name:  "components",
+					Usage: "comma-separated list of components to export (leave empty to export all)",
+				},
+			},
+		},
+		{
+			Name:  "import",
+			Usage: "imports the events exported by 'gpud export' into the GPUd state file",
+			UsageText: `# to import the events exported from another machine
+gpud import --in events.jsonl
+`,
+			Action: cmdexport.ImportCommand,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "log-level,l",
+					Usage: "set the logging level [debug, info, warn, error, fatal, panic, dpanic]",
+				},
+				&cli.StringFlag{
+					Name:  "in,i",
+					Usage: "set the input file path (set empty or '-' to stdin)",
+				},
+				&cli.StringFlag{
+					Name:  "state-file",
+					Usage: "set the state file path to import into (leave empty for default)",
+				},
+			},
+		},
 		{
 			Name:    "scan",
 			Aliases: []string{"check", "s"},
 			Usage:   "quick scans the host for any major issues",
 			Action:  cmdscan.CreateCommand(),
 			Flags: []cli.Flag{
+				pkgoutput.Flag,
 				&cli.StringFlag{
 					Name:  "log-level,l",
 					Usage: "set the logging level [debug, info, warn, error, fatal, panic, dpanic]",
@@ -413,15 +859,137 @@ sudo rm /etc/systemd/system/gpud.service
 					Name:  "nfs-checker-configs",
 					Usage: "set the NFS checker group configs in JSON (leave empty for default, useful for testing)",
 				},
-				cli.StringFlag{
-					Name:   "ibstat-command",
-					Usage:  "sets the ibstat command (leave empty for default, useful for testing)",
-					Hidden: true, // only for testing
+				&cli.StringFlag{
+					Name:  "profile",
+					Usage: "set the scan profile [quick (NVML and in-memory checks only, 30s budget), standard (all components, 2m budget), deep (standard plus DCGM diag, nvbandwidth, and ibdiagnet if installed, 15m budget)]",
+					Value: string(pkgscan.DefaultProfile),
 				},
-				cli.StringFlag{
-					Name:   "ibstatus-command",
-					Usage:  "sets the ibstatus command (leave empty for default, useful for testing)",
-					Hidden: true, // only for testing
+				&cli.StringFlag{
+					Name:  "record",
+					Usage: "record the raw inputs of the scan (NVML calls, ibstat/ibstatus/nvidia-smi outputs, PCI sysfs snapshots) and the result into the directory, to replay the scan with",
+				},
+				&cli.StringFlag{
+					Name:  "replay",
+					Usage: "replay the scan from the recording directory on the recorded inputs, and fail if the health states differ from the recorded result",
+				},
+				&cli.StringFlag{
+					Name:  "upload",
+					Usage: "(optional) destination to upload the scan result in JSON to with the instance credentials (e.g., s3://bucket/prefix, gs://bucket/prefix, azblob://account/container/prefix)",
+				},
+				&cli.StringFlag{
+					Name:  "tool-resource-limits",
+					Usage: "(optional) comma-separated CPU and memory caps of the external tools run by each check (or '*' for all the checks) in the format of '<check>=<cpus>:<memory max>' (e.g., 'dcgm-diag=2:4GiB', leave empty to not cap the tools)",
+				},
+				&cli.StringFlag{
+					Name:  "tool-paths",
+					Usage: "(optional) paths of the external tools to run instead of auto-discovering them, either in JSON (e.g., '{\"ibstat\":\"/opt/ofed/bin/ibstat\"}') or comma-separated name=path (e.g., 'ibstat=/opt/ofed/bin/ibstat,dcgmi=/usr/local/dcgm/bin/dcgmi')",
+				},
+			},
+		},
+		{
+			Name:  "certify",
+			Usage: "certifies the node by running the deep-check suite, and issues the signed certificate on pass",
+			Subcommands: []cli.Command{
+				{
+					Name:   "gen-key",
+					Usage:  "generate the certification key pair to sign and verify the certificates with",
+					Action: cmdcertify.CommandGenKey,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "log-level,l",
+							Usage: "set the logging level [debug, info, warn, error, fatal, panic, dpanic]",
+						},
+						&cli.StringFlag{
+							Name:  "priv-path",
+							Usage: "path to write the private certification key to",
+							Value: "certification.priv",
+						},
+						&cli.StringFlag{
+							Name:  "pub-path",
+							Usage: "path to write the public certification key to",
+							Value: "certification.pub",
+						},
+					},
+				},
+				{
+					Name:  "run",
+					Usage: "run the deep-check suite, and on pass, write the certificate (hardware inventory, check results, timestamp) signed with the certification key",
+					UsageText: `# to certify the node
+sudo gpud certify run --sign-priv-path certification.priv --output-path gpud-certificate.json
+`,
+					Action: cmdcertify.CommandRun,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "log-level,l",
+							Usage: "set the logging level [debug, info, warn, error, fatal, panic, dpanic]",
+						},
+						&cli.StringFlag{
+							Name:  "sign-priv-path",
+							Usage: "path to the private certification key to sign the certificate with (generated by 'gpud certify gen-key')",
+						},
+						&cli.StringFlag{
+							Name:  "output-path",
+							Usage: "path to write the certificate to, with the signature written to the path with the '.sig' suffix",
+							Value: cmdcertify.DefaultCertificatePath,
+						},
+						&cli.StringFlag{
+							Name:  "tool-paths",
+							Usage: "(optional) paths of the external tools to run instead of auto-discovering them, either in JSON (e.g., '{\"ibstat\":\"/opt/ofed/bin/ibstat\"}') or comma-separated name=path (e.g., 'ibstat=/opt/ofed/bin/ibstat,dcgmi=/usr/local/dcgm/bin/dcgmi')",
+						},
+					},
+				},
+				{
+					Name:   "verify",
+					Usage:  "verify the signature of the certificate with the public certification keys",
+					Action: cmdcertify.CommandVerify,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "log-level,l",
+							Usage: "set the logging level [debug, info, warn, error, fatal, panic, dpanic]",
+						},
+						&cli.StringFlag{
+							Name:  "sign-pub-path",
+							Usage: "path to the bundle of the public certification keys (generated by 'gpud certify gen-key')",
+						},
+						&cli.StringFlag{
+							Name:  "certificate-path",
+							Usage: "path to the certificate",
+							Value: cmdcertify.DefaultCertificatePath,
+						},
+						&cli.StringFlag{
+							Name:  "sig-path",
+							Usage: "path to the signature of the certificate (leave empty for the certificate path with the '.sig' suffix)",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:  "verify-artifact",
+			Usage: "verify the signatures of the artifacts of the type (e.g., plugin specs, config files) with the public artifact keys before applying them",
+			UsageText: `# to sign the artifact as its type
+gpud release sign-artifact --type plugin-specs --artifact-path plugins.yaml --sign-priv-path artifact.priv
+
+# to verify the artifacts of the type with the signatures next to them
+gpud verify-artifact --type plugin-specs --sign-pub-path artifact.pub plugins.yaml
+`,
+			Action: cmdrelease.CommandVerifyArtifact,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "log-level,l",
+					Usage: "set the logging level [debug, info, warn, error, fatal, panic, dpanic]",
+				},
+				&cli.StringFlag{
+					Name:  "type",
+					Usage: fmt.Sprintf("type of the artifacts, covered by the signatures (one of %q)", pkgdistsign.ArtifactTypes),
+				},
+				&cli.StringFlag{
+					Name:  "sign-pub-path",
+					Usage: "path to the bundle of the public artifact keys (generated by 'gpud release gen-key --artifact')",
+				},
+				&cli.StringFlag{
+					Name:  "sig-path",
+					Usage: "path to the signature of the single artifact (leave empty for each artifact path with the '.sig' suffix)",
 				},
 			},
 		},
@@ -431,6 +999,7 @@ sudo rm /etc/systemd/system/gpud.service
 			Usage:   "list all registered custom plugins",
 			Action:  cmdlistplugins.Command,
 			Flags: []cli.Flag{
+				pkgoutput.Flag,
 				&cli.StringFlag{
 					Name:  "log-level,l",
 					Usage: "set the logging level [debug, info, warn, error, fatal, panic, dpanic]",
@@ -459,15 +1028,9 @@ sudo rm /etc/systemd/system/gpud.service
 					Name:  "fail-fast,f",
 					Usage: "fail fast, exit immediately if any plugin returns unhealthy state (default: true)",
 				},
-				cli.StringFlag{
-					Name:   "ibstat-command",
-					Usage:  "sets the ibstat command (leave empty for default, useful for testing)",
-					Hidden: true, // only for testing
-				},
-				cli.StringFlag{
-					Name:   "ibstatus-command",
-					Usage:  "sets the ibstatus command (leave empty for default, useful for testing)",
-					Hidden: true, // only for testing
+				&cli.StringFlag{
+					Name:  "tool-paths",
+					Usage: "(optional) paths of the external tools to run instead of auto-discovering them, either in JSON (e.g., '{\"ibstat\":\"/opt/ofed/bin/ibstat\"}') or comma-separated name=path (e.g., 'ibstat=/opt/ofed/bin/ibstat,dcgmi=/usr/local/dcgm/bin/dcgmi')",
 				},
 			},
 		},
@@ -487,18 +1050,177 @@ sudo rm /etc/systemd/system/gpud.service
 				},
 			},
 		},
+		{
+			Name:      "doctor",
+			Usage:     "validates the gpud installation (binary, systemd unit, state file, permissions, NVIDIA library, kernel modules, ports) with fix-it hints",
+			UsageText: "gpud doctor",
+			Action:    cmddoctor.Command,
+			Flags: []cli.Flag{
+				pkgoutput.Flag,
+				&cli.StringFlag{
+					Name:  "log-level,l",
+					Usage: "set the logging level [debug, info, warn, error, fatal, panic, dpanic]",
+				},
+			},
+		},
+		{
+			Name:  "diagnose",
+			Usage: "collects the support archive (events, health states, metrics, kernel messages, nvidia-smi/ibstat outputs, config, and gpud logs) into a single tar.gz with the tokens redacted",
+			UsageText: `# to collect the last 24 hours into gpud-diagnose-<timestamp>.tar.gz
+sudo gpud diagnose
+
+# to collect the last 6 hours into the file
+sudo gpud diagnose --since 6h --out /tmp/support.tar.gz
+`,
+			Action: cmddiagnose.Command,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "log-level,l",
+					Usage: "set the logging level [debug, info, warn, error, fatal, panic, dpanic]",
+				},
+				&cli.DurationFlag{
+					Name:  "since",
+					Usage: "time range of the events, metrics, kernel messages, and gpud logs to collect",
+					Value: pkgdiagnose.DefaultSince,
+				},
+				&cli.StringFlag{
+					Name:  "out,o",
+					Usage: "path of the support archive (leave empty for gpud-diagnose-<timestamp>.tar.gz in the current directory)",
+				},
+				&cli.StringFlag{
+					Name:  "log-file",
+					Usage: "gpud log file to collect the tail of",
+					Value: pkgdiagnose.DefaultLogFile,
+				},
+			},
+		},
 		{
 			Name:      "machine-info",
 			Usage:     "get machine info (useful for debugging)",
 			UsageText: "gpud machine-info",
 			Action:    cmdmachineinfo.Command,
 			Flags: []cli.Flag{
+				pkgoutput.Flag,
 				&cli.StringFlag{
 					Name:  "log-level,l",
 					Usage: "set the logging level [debug, info, warn, error, fatal, panic, dpanic]",
 				},
 			},
 		},
+		{
+			Name:  "nccl-test",
+			Usage: "runs the NCCL bandwidth tests between the peer nodes (pairwise or in a ring) with the gpud server as the leader, and localizes the fabric issues to the nodes or the links",
+			UsageText: `# to test the peer mesh peers in a ring, and wait for the result
+gpud nccl-test --wait
+
+# to test every pair of the nodes
+gpud nccl-test --mode pairwise --peers node-1=10.0.1.1:15132,node-2=10.0.1.2:15132,node-3=10.0.1.3:15132 --min-bus-bandwidth-gbps 150 --wait
+`,
+			Action: cmdnccltest.Command,
+			Flags: []cli.Flag{
+				pkgoutput.Flag,
+				&cli.StringFlag{
+					Name:  "log-level,l",
+					Usage: "set the logging level [debug, info, warn, error, fatal, panic, dpanic]",
+				},
+				&cli.StringFlag{
+					Name:  "server",
+					Usage: "gpud server address of the leader (leave empty to use the local server)",
+				},
+				&cli.StringFlag{
+					Name:  "mode",
+					Usage: "topology of the links to test [ring, pairwise]",
+					Value: string(pkgnccltest.ModeRing),
+				},
+				&cli.StringFlag{
+					Name:  "peers",
+					Usage: "comma-separated nodes to test in the format of '[<id>[@<rack>]=]<host:port>' (leave empty to use the peer mesh peers of the leader)",
+				},
+				&cli.IntFlag{
+					Name:  "gpus-per-node",
+					Usage: "number of the GPUs to test per node",
+					Value: pkgnccltest.DefaultGPUsPerNode,
+				},
+				&cli.StringFlag{
+					Name:  "message-size",
+					Usage: "message size of the all-reduce",
+					Value: pkgnccltest.DefaultMessageSize,
+				},
+				&cli.Float64Flag{
+					Name:  "min-bus-bandwidth-gbps",
+					Usage: "bus bandwidth in GB/s below which the link is degraded (0 to only consider the failed tests as degraded)",
+				},
+				&cli.DurationFlag{
+					Name:  "link-timeout",
+					Usage: "timeout to test each link",
+					Value: pkgnccltest.DefaultLinkTimeout,
+				},
+				&cli.BoolFlag{
+					Name:  "wait",
+					Usage: "wait for all the links to be tested",
+				},
+			},
+		},
+		{
+			Name:  "simulate",
+			Usage: "injects a synthetic event and/or health state (e.g., fake Xid 63, fake IB port down) through the normal pipeline (event store, notifications, control plane), for testing the alert pipelines end-to-end",
+			UsageText: `# to simulate the Xid 63
+gpud simulate --scenario xid-63
+
+# to simulate the unhealthy state of a component for 10 minutes
+gpud simulate --component disk --health Unhealthy --reason "disk full" --duration 10m
+`,
+			Action: cmdsimulate.Command,
+			Flags: []cli.Flag{
+				pkgoutput.Flag,
+				&cli.StringFlag{
+					Name:  "log-level,l",
+					Usage: "set the logging level [debug, info, warn, error, fatal, panic, dpanic]",
+				},
+				&cli.StringFlag{
+					Name:  "server",
+					Usage: "gpud server address (leave empty to use the local server)",
+				},
+				&cli.BoolFlag{
+					Name:  "list-scenarios",
+					Usage: "list the built-in scenarios",
+				},
+				&cli.StringFlag{
+					Name:  "scenario",
+					Usage: "built-in scenario to simulate (e.g., xid-63, ib-port-down)",
+				},
+				&cli.StringFlag{
+					Name:  "component",
+					Usage: "component to inject into (overrides the scenario)",
+				},
+				&cli.StringFlag{
+					Name:  "event-name",
+					Usage: "name of the synthetic event to insert (overrides the scenario)",
+				},
+				&cli.StringFlag{
+					Name:  "event-type",
+					Usage: "type of the synthetic event [Info, Warning, Critical, Fatal]",
+					Value: "Warning",
+				},
+				&cli.StringFlag{
+					Name:  "event-message",
+					Usage: "message of the synthetic event",
+				},
+				&cli.StringFlag{
+					Name:  "health",
+					Usage: "health of the synthetic health state to report [Healthy, Degraded, Unhealthy] (overrides the scenario)",
+				},
+				&cli.StringFlag{
+					Name:  "reason",
+					Usage: "reason of the synthetic health state",
+				},
+				&cli.DurationFlag{
+					Name:  "duration",
+					Usage: "duration to report the synthetic health state for",
+					Value: pkgsimulate.DefaultDuration,
+				},
+			},
+		},
 		{
 			Name:   "inject-fault",
 			Usage:  "injects a fault such as writing a kernel message to the kernel log",
@@ -524,6 +1246,7 @@ sudo rm /etc/systemd/system/gpud.service
 			Usage:  "inspects/updates metadata table",
 			Action: cmdmetadata.Command,
 			Flags: []cli.Flag{
+				pkgoutput.Flag,
 				&cli.StringFlag{
 					Name:  "log-level,l",
 					Usage: "set the logging level [debug, info, warn, error, fatal, panic, dpanic]",
@@ -569,7 +1292,7 @@ sudo rm /etc/systemd/system/gpud.service
 				},
 				cli.StringFlag{
 					Name:  "endpoint",
-					Usage: "endpoint for control plane",
+					Usage: "endpoint for control plane (comma-separated for the failover, the first being the primary)",
 					Value: "gpud-manager-prod01.dgxc-lepton.nvidia.com",
 				},
 				cli.StringFlag{
@@ -589,6 +1312,10 @@ sudo rm /etc/systemd/system/gpud.service
 					Name:  "private-ip",
 					Usage: "can specify private ip for internal network",
 				},
+				cli.StringFlag{
+					Name:  "private-ipv6",
+					Usage: "can specify private ipv6 for internal dual-stack network",
+				},
 				cli.StringFlag{
 					Name:  "public-ip",
 					Usage: "can specify public ip for machine",
@@ -631,6 +1358,10 @@ sudo rm /etc/systemd/system/gpud.service
 					Name:  "private-ip",
 					Usage: "can specify private ip for internal network",
 				},
+				cli.StringFlag{
+					Name:  "private-ipv6",
+					Usage: "can specify private ipv6 for internal dual-stack network",
+				},
 				cli.BoolTFlag{
 					Name:  "skip-interactive",
 					Usage: "use detected value instead of prompting for user input",
@@ -643,6 +1374,10 @@ sudo rm /etc/systemd/system/gpud.service
 					Name:  "region",
 					Usage: "specify the region of the machine",
 				},
+				cli.StringFlag{
+					Name:  "latency-targets",
+					Usage: "(optional) comma-separated targets with the region codes to measure the latencies against in addition to the edge servers, for the region detection, in the format of '<name>@<region>=<host:port or URL>' (e.g., 'storage@us-east-1=https://s3.us-east-1.amazonaws.com')",
+				},
 				cli.StringFlag{
 					Name:  "gpu-product",
 					Usage: "specify the GPU shape of the machine",
@@ -14,11 +14,12 @@ import (
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	cmdcommon "github.com/leptonai/gpud/cmd/common"
 	"github.com/leptonai/gpud/components"
-	nvidiacommon "github.com/leptonai/gpud/pkg/config/common"
 	customplugins "github.com/leptonai/gpud/pkg/custom-plugins"
 	custompluginstestdata "github.com/leptonai/gpud/pkg/custom-plugins/testdata"
+	pkglabels "github.com/leptonai/gpud/pkg/labels"
 	"github.com/leptonai/gpud/pkg/log"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	"github.com/leptonai/gpud/pkg/toolpath"
 )
 
 func Command(cliContext *cli.Context) error {
@@ -66,6 +67,14 @@ func Command(cliContext *cli.Context) error {
 		return nil
 	}
 
+	toolPaths, err := pkglabels.Parse(cliContext.String("tool-paths"))
+	if err != nil {
+		return err
+	}
+	if err := toolpath.SetOverrides(toolPaths); err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer cancel()
 
@@ -77,10 +86,6 @@ func Command(cliContext *cli.Context) error {
 	gpudInstance := &components.GPUdInstance{
 		RootCtx:      ctx,
 		NVMLInstance: nvmlInstance,
-		NVIDIAToolOverwrites: nvidiacommon.ToolOverwrites{
-			IbstatCommand:   cliContext.String("ibstat-command"),
-			IbstatusCommand: cliContext.String("ibstatus-command"),
-		},
 	}
 
 	customPluginsFailFast := cliContext.Bool("fail-fast")
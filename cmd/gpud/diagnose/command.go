@@ -0,0 +1,105 @@
+// Package diagnose implements the "diagnose" command.
+package diagnose
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/urfave/cli"
+
+	clientv1 "github.com/leptonai/gpud/client/v1"
+	cmdcommon "github.com/leptonai/gpud/cmd/common"
+	cmdexport "github.com/leptonai/gpud/cmd/gpud/export"
+	"github.com/leptonai/gpud/pkg/config"
+	pkgdiagnose "github.com/leptonai/gpud/pkg/diagnose"
+	"github.com/leptonai/gpud/pkg/gpud-manager/systemd"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// Command collects the support archive of the node into a single tar.gz file,
+// from the running GPUd server (if reachable), the tools, and the gpud logs.
+func Command(cliContext *cli.Context) error {
+	logLevel := cliContext.String("log-level")
+	zapLvl, err := log.ParseLogLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	log.Logger = log.CreateLogger(zapLvl, "")
+
+	log.Logger.Debugw("starting diagnose command")
+
+	since := cliContext.Duration("since")
+	if since <= 0 {
+		return fmt.Errorf("invalid since %s", since)
+	}
+
+	now := time.Now().UTC()
+	dir := "gpud-diagnose-" + now.Format("20060102-150405")
+	out := cliContext.String("out")
+	if out == "" {
+		out = dir + ".tar.gz"
+	}
+	// readable by the owner only, since the archive may have the secrets not redacted
+	f, err := os.OpenFile(out, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer f.Close()
+
+	rootCtx, rootCancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer rootCancel()
+
+	addr := fmt.Sprintf("https://localhost:%d", config.DefaultGPUdPort)
+	start := now.Add(-since)
+	sources := []pkgdiagnose.Source{
+		apiSource("machine-info.json", func(ctx context.Context) (any, error) {
+			return clientv1.GetMachineInfo(ctx, addr)
+		}),
+		apiSource("config.json", func(ctx context.Context) (any, error) {
+			return clientv1.GetConfig(ctx, addr)
+		}),
+		apiSource("health-states.json", func(ctx context.Context) (any, error) {
+			return clientv1.GetHealthStates(ctx, addr)
+		}),
+		apiSource("findings.json", func(ctx context.Context) (any, error) {
+			return clientv1.GetFindings(ctx, addr)
+		}),
+		apiSource("events.json", func(ctx context.Context) (any, error) {
+			return cmdexport.GetAllEvents(ctx, addr, nil, start, now)
+		}),
+		apiSource("metrics.json", func(ctx context.Context) (any, error) {
+			return clientv1.GetMetrics(ctx, addr, clientv1.WithSince(since))
+		}),
+		apiSource("kmsg.json", func(ctx context.Context) (any, error) {
+			return clientv1.GetKmsg(ctx, addr, clientv1.WithStartTime(start))
+		}),
+		pkgdiagnose.CommandSource("nvidia-smi.txt", time.Minute, "nvidia-smi", "-q"),
+		pkgdiagnose.CommandSource("nvidia-smi-topo.txt", time.Minute, "nvidia-smi", "topo", "-m"),
+		pkgdiagnose.CommandSource("ibstat.txt", time.Minute, "ibstat"),
+		pkgdiagnose.FileTailSource("gpud.env", systemd.DefaultEnvFile, pkgdiagnose.DefaultMaxLogBytes),
+		pkgdiagnose.LogSource("gpud.log", cliContext.String("log-file"), start, pkgdiagnose.DefaultMaxLogBytes),
+	}
+
+	n, err := pkgdiagnose.Collect(rootCtx, f, dir, now, sources)
+	if err != nil {
+		return fmt.Errorf("failed to write support archive: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s wrote %d file(s) since %s to %s (tokens redacted, attach it to the support ticket)\n", cmdcommon.CheckMark, n, start.Format(time.RFC3339), out)
+	return nil
+}
+
+// apiSource returns the source of the GPUd API response,
+// with the timeout for each request not to block on the unresponsive server.
+func apiSource(name string, get func(ctx context.Context) (any, error)) pkgdiagnose.Source {
+	return pkgdiagnose.JSONSource(name, func(ctx context.Context) (any, error) {
+		cctx, ccancel := context.WithTimeout(ctx, 30*time.Second)
+		defer ccancel()
+		return get(cctx)
+	})
+}
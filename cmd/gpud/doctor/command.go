@@ -0,0 +1,78 @@
+// Package doctor implements the "doctor" command.
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/urfave/cli"
+
+	cmdcommon "github.com/leptonai/gpud/cmd/common"
+	"github.com/leptonai/gpud/components/all"
+	pkgdoctor "github.com/leptonai/gpud/pkg/doctor"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgoutput "github.com/leptonai/gpud/pkg/output"
+)
+
+func Command(cliContext *cli.Context) error {
+	logLevel := cliContext.String("log-level")
+	zapLvl, err := log.ParseLogLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	log.Logger = log.CreateLogger(zapLvl, "")
+
+	log.Logger.Debugw("starting doctor command")
+
+	format, err := pkgoutput.FormatFromContext(cliContext)
+	if err != nil {
+		return err
+	}
+
+	checks, err := pkgdoctor.Checks(pkgdoctor.WithNonRootDegradations(all.NonRootDegradations()))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	results := pkgdoctor.Run(ctx, checks)
+	cancel()
+
+	if format.IsMachineReadable() {
+		if err := pkgoutput.Render(os.Stdout, format, results, nil); err != nil {
+			return err
+		}
+	} else {
+		printResults(os.Stdout, results)
+	}
+
+	if failed := pkgdoctor.Failed(results); failed > 0 {
+		return fmt.Errorf("%d of %d check(s) failed", failed, len(results))
+	}
+	if !format.IsMachineReadable() {
+		fmt.Printf("%s all checks passed\n", cmdcommon.CheckMark)
+	}
+	return nil
+}
+
+func printResults(w io.Writer, results []pkgdoctor.Result) {
+	for _, rs := range results {
+		mark := cmdcommon.CheckMark
+		switch rs.Status {
+		case pkgdoctor.StatusWarn:
+			mark = cmdcommon.InProgress
+		case pkgdoctor.StatusFail:
+			mark = cmdcommon.WarningSign
+		}
+		fmt.Fprintf(w, "%s [%s] %s\n", mark, rs.Name, rs.Message)
+		for _, d := range rs.Details {
+			fmt.Fprintf(w, "    - %s\n", d)
+		}
+		if rs.Status != pkgdoctor.StatusOK && rs.Hint != "" {
+			fmt.Fprintf(w, "    fix: %s\n", rs.Hint)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+// Package driver implements the "driver" command to install or upgrade the NVIDIA driver.
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cmdcommon "github.com/leptonai/gpud/cmd/common"
+	"github.com/leptonai/gpud/pkg/config"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	pkghost "github.com/leptonai/gpud/pkg/host"
+	"github.com/leptonai/gpud/pkg/log"
+	nvidiadriver "github.com/leptonai/gpud/pkg/nvidia-driver"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	"github.com/leptonai/gpud/pkg/osutil"
+	"github.com/leptonai/gpud/pkg/process"
+	"github.com/leptonai/gpud/pkg/remediation"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+// CommandInstall installs the NVIDIA driver on the node without the driver.
+func CommandInstall(cliContext *cli.Context) error {
+	return run(cliContext, nvidiadriver.OperationInstall)
+}
+
+// CommandUpgrade upgrades the installed NVIDIA driver to the newer version.
+func CommandUpgrade(cliContext *cli.Context) error {
+	return run(cliContext, nvidiadriver.OperationUpgrade)
+}
+
+func run(cliContext *cli.Context, operation nvidiadriver.Operation) error {
+	logLevel := cliContext.String("log-level")
+	zapLvl, err := log.ParseLogLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	log.Logger = log.CreateLogger(zapLvl, "")
+
+	log.Logger.Debugw("starting driver command", "operation", operation)
+
+	dryRun := cliContext.Bool("dry-run")
+	if !dryRun {
+		if err := osutil.RequireRoot(); err != nil {
+			return err
+		}
+	}
+
+	version := cliContext.String("version")
+	if version == "" {
+		return errors.New("--version is required")
+	}
+
+	method, err := nvidiadriver.ParseMethod(cliContext.String("method"))
+	if err != nil {
+		return err
+	}
+	if method == "" {
+		method, err = nvidiadriver.DetectMethod(nvidiadriver.DefaultOSReleaseFile)
+		if err != nil {
+			return fmt.Errorf("failed to detect driver install method: %w", err)
+		}
+	}
+
+	nvmlInstance, err := nvidianvml.New()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := nvmlInstance.Shutdown(); err != nil {
+			log.Logger.Debugw("failed to shutdown nvml", "error", err)
+		}
+	}()
+	currentVersion := nvmlInstance.DriverVersion()
+
+	plan, err := nvidiadriver.NewPlan(
+		operation,
+		version,
+		currentVersion,
+		method,
+		nvidiadriver.WithRunfileURL(cliContext.String("runfile-url")),
+		nvidiadriver.WithRunfileSHA256(cliContext.String("sha256")),
+	)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("driver %s plan:\n%s\n", operation, string(b))
+	if dryRun {
+		fmt.Printf("%s dry run, not running the plan\n", cmdcommon.CheckMark)
+		return nil
+	}
+
+	rootCtx, rootCancel := context.WithTimeout(context.Background(), cliContext.Duration("timeout"))
+	defer rootCancel()
+
+	rec := nvidiadriver.Record{
+		Plan:      *plan,
+		StartedAt: metav1.Time{Time: time.Now().UTC()},
+	}
+
+	err = drainAndInstall(rootCtx, cliContext, nvmlInstance, plan, &rec)
+	rec.FinishedAt = metav1.Time{Time: time.Now().UTC()}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+
+	reboot := err == nil && cliContext.Bool("reboot")
+	rec.RebootRequested = reboot
+	if serr := saveRecord(rootCtx, rec); serr != nil {
+		log.Logger.Warnw("failed to record driver operation", "error", serr)
+	}
+
+	if err != nil {
+		fmt.Printf("%s failed to %s driver %s: %v\n", cmdcommon.WarningSign, operation, version, err)
+		return err
+	}
+	fmt.Printf("%s successfully ran driver %s to %s\n", cmdcommon.CheckMark, operation, version)
+
+	if !reboot {
+		fmt.Printf("%s reboot the node to load the driver %s\n", cmdcommon.WarningSign, version)
+		return nil
+	}
+	fmt.Printf("rebooting the node to load the driver %s\n", version)
+	return pkghost.Reboot(rootCtx)
+}
+
+// drainAndInstall drains the GPU workloads if requested, and runs the plan.
+func drainAndInstall(ctx context.Context, cliContext *cli.Context, nvmlInstance nvidianvml.Instance, plan *nvidiadriver.Plan, rec *nvidiadriver.Record) error {
+	if cliContext.Bool("drain") {
+		if cmd := cliContext.String("drain-command"); cmd != "" {
+			log.Logger.Infow("running drain command", "command", cmd)
+			if err := runDrainCommand(ctx, cmd); err != nil {
+				return fmt.Errorf("failed to run drain command: %w", err)
+			}
+		}
+
+		drained, err := terminateGPUProcesses(ctx, nvmlInstance, fmt.Sprintf("driver %s to %s", plan.Operation, plan.Version))
+		rec.DrainedProcesses = drained
+		if err != nil {
+			return fmt.Errorf("failed to drain gpu processes: %w", err)
+		}
+	}
+
+	return nvidiadriver.Install(ctx, plan)
+}
+
+// terminateGPUProcesses terminates the processes on all the GPUs,
+// records them in the remediation event bucket of the state file,
+// and returns the number of the terminated processes.
+func terminateGPUProcesses(ctx context.Context, nvmlInstance nvidianvml.Instance, reason string) (int, error) {
+	if !nvmlInstance.NVMLExists() {
+		return 0, nil
+	}
+
+	stateFile, err := config.DefaultStateFile()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get state file: %w", err)
+	}
+	dbRW, err := sqlite.Open(stateFile)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open state file: %w", err)
+	}
+	defer dbRW.Close()
+	dbRO, err := sqlite.Open(stateFile, sqlite.WithReadOnly(true))
+	if err != nil {
+		return 0, fmt.Errorf("failed to open state file: %w", err)
+	}
+	defer dbRO.Close()
+
+	store, err := eventstore.New(dbRW, dbRO, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open event store: %w", err)
+	}
+	bucket, err := store.Bucket(remediation.BucketName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open remediation event bucket: %w", err)
+	}
+	defer bucket.Close()
+
+	recs, err := remediation.NewGPUProcessTerminator(nvmlInstance, bucket, nil).TerminateAll(ctx, reason)
+	drained := 0
+	for _, r := range recs {
+		drained += len(r.Terminated)
+	}
+	return drained, err
+}
+
+func runDrainCommand(ctx context.Context, cmd string) error {
+	p, err := process.New(
+		process.WithCommand(cmd),
+		process.WithRunAsBashScript(),
+	)
+	if err != nil {
+		return err
+	}
+	out, err := p.StartAndWaitForCombinedOutput(ctx)
+	if len(out) > 0 {
+		fmt.Println(string(out))
+	}
+	return err
+}
+
+func saveRecord(ctx context.Context, rec nvidiadriver.Record) error {
+	stateFile, err := config.DefaultStateFile()
+	if err != nil {
+		return fmt.Errorf("failed to get state file: %w", err)
+	}
+	dbRW, err := sqlite.Open(stateFile)
+	if err != nil {
+		return fmt.Errorf("failed to open state file: %w", err)
+	}
+	defer dbRW.Close()
+
+	return nvidiadriver.SaveRecord(ctx, dbRW, rec)
+}
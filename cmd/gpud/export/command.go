@@ -0,0 +1,137 @@
+// Package export implements the "export" and "import" commands.
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	clientv1 "github.com/leptonai/gpud/client/v1"
+	cmdcommon "github.com/leptonai/gpud/cmd/common"
+	"github.com/leptonai/gpud/pkg/config"
+	pkgexport "github.com/leptonai/gpud/pkg/export"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgserver "github.com/leptonai/gpud/pkg/server"
+)
+
+const formatJSONL = "jsonl"
+
+// Command exports the events and the latest component health check results
+// from the running GPUd server into a JSONL file.
+func Command(cliContext *cli.Context) error {
+	logLevel := cliContext.String("log-level")
+	zapLvl, err := log.ParseLogLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	log.Logger = log.CreateLogger(zapLvl, "")
+
+	log.Logger.Debugw("starting export command")
+
+	since := cliContext.Duration("since")
+	if since <= 0 {
+		return fmt.Errorf("invalid since %s", since)
+	}
+	if format := cliContext.String("format"); format != formatJSONL {
+		return fmt.Errorf("unsupported format %q (supported: %s)", format, formatJSONL)
+	}
+
+	var components []string
+	for _, c := range strings.Split(cliContext.String("components"), ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			components = append(components, c)
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if out := cliContext.String("out"); out != "" && out != "-" {
+		f, err := os.Create(out)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	rootCtx, rootCancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer rootCancel()
+
+	addr := fmt.Sprintf("https://localhost:%d", config.DefaultGPUdPort)
+	if err := clientv1.BlockUntilServerReady(rootCtx, addr); err != nil {
+		return err
+	}
+
+	end := time.Now()
+	start := end.Add(-since)
+	events, err := GetAllEvents(rootCtx, addr, components, start, end)
+	if err != nil {
+		return err
+	}
+
+	opts := make([]clientv1.OpOption, 0, len(components))
+	for _, c := range components {
+		opts = append(opts, clientv1.WithComponent(c))
+	}
+	states, err := clientv1.GetHealthStates(rootCtx, addr, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to get health states: %w", err)
+	}
+
+	ew := pkgexport.NewWriter(w)
+	if err := ew.WriteEvents(events); err != nil {
+		return fmt.Errorf("failed to write events: %w", err)
+	}
+	if err := ew.WriteHealthStates(states); err != nil {
+		return fmt.Errorf("failed to write health states: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "%s successfully exported %d records since %s\n", cmdcommon.CheckMark, ew.Count(), start.UTC().Format(time.RFC3339))
+	return nil
+}
+
+// GetAllEvents reads the events in the time range, following the pagination
+// of each component until all the events are read.
+func GetAllEvents(ctx context.Context, addr string, components []string, start time.Time, end time.Time) (apiv1.GPUdComponentEvents, error) {
+	baseOpts := []clientv1.OpOption{
+		clientv1.WithStartTime(start),
+		clientv1.WithEndTime(end),
+		clientv1.WithLimit(pkgserver.MaxEventsQueryLimit),
+		clientv1.WithAcceptEncodingGzip(),
+	}
+
+	opts := append([]clientv1.OpOption{}, baseOpts...)
+	for _, c := range components {
+		opts = append(opts, clientv1.WithComponent(c))
+	}
+	events, err := clientv1.GetEvents(ctx, addr, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events: %w", err)
+	}
+
+	for i := range events {
+		next := events[i].NextOffset
+		for next > 0 {
+			log.Logger.Debugw("reading next page of events", "component", events[i].Component, "offset", next)
+			page, err := clientv1.GetEvents(ctx, addr, append(baseOpts, clientv1.WithComponent(events[i].Component), clientv1.WithOffset(next))...)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get events: %w", err)
+			}
+
+			next = 0
+			for _, p := range page {
+				if p.Component == events[i].Component {
+					events[i].Events = append(events[i].Events, p.Events...)
+					next = p.NextOffset
+				}
+			}
+		}
+		events[i].NextOffset = 0
+	}
+	return events, nil
+}
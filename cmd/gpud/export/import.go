@@ -0,0 +1,131 @@
+package export
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/urfave/cli"
+
+	cmdcommon "github.com/leptonai/gpud/cmd/common"
+	"github.com/leptonai/gpud/pkg/config"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	pkgexport "github.com/leptonai/gpud/pkg/export"
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+// ImportCommand imports the events from the JSONL file exported by the "export" command
+// into the GPUd state file, skipping the events that already exist.
+// The health check results are not imported, since only the latest results
+// are kept in memory by the components.
+func ImportCommand(cliContext *cli.Context) error {
+	logLevel := cliContext.String("log-level")
+	zapLvl, err := log.ParseLogLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	log.Logger = log.CreateLogger(zapLvl, "")
+
+	log.Logger.Debugw("starting import command")
+
+	var r io.Reader = os.Stdin
+	if in := cliContext.String("in"); in != "" && in != "-" {
+		f, err := os.Open(in)
+		if err != nil {
+			return fmt.Errorf("failed to open input file: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	stateFile := cliContext.String("state-file")
+	if stateFile == "" {
+		stateFile, err = config.DefaultStateFile()
+		if err != nil {
+			return fmt.Errorf("failed to get state file: %w", err)
+		}
+	}
+
+	dbRW, err := sqlite.Open(stateFile)
+	if err != nil {
+		return fmt.Errorf("failed to open state file: %w", err)
+	}
+	defer dbRW.Close()
+
+	dbRO, err := sqlite.Open(stateFile, sqlite.WithReadOnly(true))
+	if err != nil {
+		return fmt.Errorf("failed to open state file: %w", err)
+	}
+	defer dbRO.Close()
+
+	rootCtx, rootCancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer rootCancel()
+
+	imported, skipped, err := importEvents(rootCtx, dbRW, dbRO, r)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s successfully imported %d events into %s (skipped %d records)\n", cmdcommon.CheckMark, imported, stateFile, skipped)
+	return nil
+}
+
+// importEvents inserts the event records into the event store buckets of their components,
+// and returns the number of the imported events and the skipped records.
+func importEvents(ctx context.Context, dbRW *sql.DB, dbRO *sql.DB, r io.Reader) (int, int, error) {
+	store, err := eventstore.New(dbRW, dbRO, 0)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to open event store: %w", err)
+	}
+
+	buckets := make(map[string]eventstore.Bucket)
+	defer func() {
+		for _, b := range buckets {
+			b.Close()
+		}
+	}()
+
+	imported, skipped := 0, 0
+	err = pkgexport.Read(r, func(rec pkgexport.Record) error {
+		if rec.Kind != pkgexport.KindEvent || rec.Event.Component == "" {
+			skipped++
+			return nil
+		}
+
+		bucket, ok := buckets[rec.Event.Component]
+		if !ok {
+			var err error
+			bucket, err = store.Bucket(rec.Event.Component, eventstore.WithDisablePurge())
+			if err != nil {
+				return fmt.Errorf("failed to open event bucket %q: %w", rec.Event.Component, err)
+			}
+			buckets[rec.Event.Component] = bucket
+		}
+
+		ev := eventstore.Event{
+			Component: rec.Event.Component,
+			Time:      rec.Event.Time.Time,
+			Name:      rec.Event.Name,
+			Type:      string(rec.Event.Type),
+			Message:   rec.Event.Message,
+		}
+		found, err := bucket.Find(ctx, ev)
+		if err != nil {
+			return fmt.Errorf("failed to find event: %w", err)
+		}
+		if found != nil {
+			skipped++
+			return nil
+		}
+		if err := bucket.Insert(ctx, ev); err != nil {
+			return fmt.Errorf("failed to insert event: %w", err)
+		}
+		imported++
+		return nil
+	})
+	return imported, skipped, err
+}
@@ -0,0 +1,44 @@
+package export
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestImportEvents(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	input := `{"kind":"event","event":{"component":"cpu","time":"2025-01-02T03:04:05Z","name":"soft_lockup","type":"Warning","message":"lockup"}}
+{"kind":"event","event":{"component":"cpu","time":"2025-01-02T03:04:05Z","name":"soft_lockup","type":"Warning","message":"lockup"}}
+{"kind":"event","event":{"time":"2025-01-02T03:04:05Z","name":"no_component"}}
+{"kind":"health_state","health_state":{"component":"cpu","time":"2025-01-02T03:04:05Z","health":"Healthy"}}
+`
+	imported, skipped, err := importEvents(ctx, dbRW, dbRO, strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, 1, imported)
+	assert.Equal(t, 3, skipped)
+
+	store, err := eventstore.New(dbRW, dbRO, 0)
+	require.NoError(t, err)
+	bucket, err := store.Bucket("cpu", eventstore.WithDisablePurge())
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	evs, err := bucket.Get(ctx, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, evs, 1)
+	assert.Equal(t, "soft_lockup", evs[0].Name)
+	assert.Equal(t, "lockup", evs[0].Message)
+}
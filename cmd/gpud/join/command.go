@@ -20,10 +20,12 @@ import (
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/pkg/config"
+	pkgendpoints "github.com/leptonai/gpud/pkg/endpoints"
 	"github.com/leptonai/gpud/pkg/log"
 	pkgmachineinfo "github.com/leptonai/gpud/pkg/machine-info"
 	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
 	latencyedge "github.com/leptonai/gpud/pkg/netutil/latency/edge"
+	latencytarget "github.com/leptonai/gpud/pkg/netutil/latency/target"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
 	"github.com/leptonai/gpud/pkg/osutil"
 	"github.com/leptonai/gpud/pkg/sqlite"
@@ -99,6 +101,17 @@ func Command(cliContext *cli.Context) (retErr error) {
 		log.Logger.Debugw("successfully read private IP from state file", "privateIP", privateIP)
 	}
 
+	// assume if not empty, it should have been persisted by the "gpud login" command
+	privateIPv6 := cliContext.String("private-ipv6")
+	if privateIPv6 == "" {
+		log.Logger.Debugw("reading private IPv6 from state file")
+		privateIPv6, err = pkgmetadata.ReadMetadata(rootCtx, dbRO, pkgmetadata.MetadataKeyPrivateIPv6)
+		if err != nil {
+			return fmt.Errorf("failed to read private IPv6: %w", err)
+		}
+		log.Logger.Debugw("successfully read private IPv6 from state file", "privateIPv6", privateIPv6)
+	}
+
 	// assume if not empty, it should have been persisted by the "gpud login" command
 	log.Logger.Debugw("reading public IP from state file")
 	publicIP := cliContext.String("public-ip")
@@ -142,6 +155,21 @@ func Command(cliContext *cli.Context) (retErr error) {
 	log.Logger.Debugw("measuring latencies to public tailscale DERP nodes to determine region")
 	region := "unknown"
 	latencies, _ := latencyedge.Measure(rootCtx)
+
+	// the user-provided targets with the region codes (e.g., the storage endpoints
+	// in the same region) are also candidates for the closest region
+	regionTargets, err := parseRegionTargets(cliContext.String("latency-targets"))
+	if err != nil {
+		return err
+	}
+	if len(regionTargets) > 0 {
+		targetLatencies, err := latencytarget.Measure(rootCtx, regionTargets)
+		if err != nil {
+			log.Logger.Warnw("failed to measure latencies to some targets", "error", err)
+		}
+		latencies = append(latencies, targetLatencies...)
+	}
+
 	if len(latencies) > 0 {
 		closest := latencies.Closest()
 		region = closest.RegionCode
@@ -247,6 +275,7 @@ func Command(cliContext *cli.Context) (retErr error) {
 		ExtraInfo:          extraInfo,
 		Region:             region,
 		PrivateIP:          privateIP,
+		PrivateIPv6:        privateIPv6,
 	}
 
 	rawPayload, _ := json.Marshal(&content)
@@ -266,7 +295,13 @@ func Command(cliContext *cli.Context) (retErr error) {
 
 	joinSentAt := time.Now()
 	log.Logger.Debugw("sending join request")
-	joinResp, err := http.Post(createJoinURL(endpoint), "application/json", bytes.NewBuffer(rawPayload))
+	// comma-separated for the failover, the first being the primary
+	var joinResp *http.Response
+	_, err = pkgendpoints.NewFailover(pkgendpoints.Parse(endpoint), 0).Do(rootCtx, func(_ context.Context, ep string) error {
+		var err error
+		joinResp, err = http.Post(createJoinURL(ep), "application/json", bytes.NewBuffer(rawPayload))
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -330,3 +365,19 @@ func createJoinURL(endpoint string) string {
 	}
 	return fmt.Sprintf("https://%s/api/v1/join", host)
 }
+
+// parseRegionTargets returns the latency targets with the region codes,
+// the ones without the region codes cannot determine the region.
+func parseRegionTargets(s string) ([]latencytarget.Target, error) {
+	targets, err := latencytarget.ParseTargets(s)
+	if err != nil {
+		return nil, err
+	}
+	regionTargets := make([]latencytarget.Target, 0, len(targets))
+	for _, t := range targets {
+		if t.RegionCode != "" {
+			regionTargets = append(regionTargets, t)
+		}
+	}
+	return regionTargets, nil
+}
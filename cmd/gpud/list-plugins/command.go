@@ -3,6 +3,7 @@ package listplugins
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/urfave/cli"
@@ -10,6 +11,7 @@ import (
 	clientv1 "github.com/leptonai/gpud/client/v1"
 	"github.com/leptonai/gpud/pkg/config"
 	"github.com/leptonai/gpud/pkg/log"
+	pkgoutput "github.com/leptonai/gpud/pkg/output"
 )
 
 // Command implements the list-plugins command
@@ -23,6 +25,11 @@ func Command(cliContext *cli.Context) error {
 
 	log.Logger.Debugw("starting list-plugins command")
 
+	format, err := pkgoutput.FormatFromContext(cliContext)
+	if err != nil {
+		return err
+	}
+
 	// Create a context with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer cancel()
@@ -40,16 +47,17 @@ func Command(cliContext *cli.Context) error {
 	}
 
 	// Print plugins
-	if len(plugins) == 0 {
+	if len(plugins) == 0 && !format.IsMachineReadable() {
 		fmt.Println("No custom plugins registered")
 		return nil
 	}
 
-	fmt.Println("Registered custom plugins:")
+	table := &pkgoutput.Table{
+		Header: []string{"Component", "Type", "Run Mode", "Timeout", "Interval"},
+		Wide:   2,
+	}
 	for _, spec := range plugins {
-		name := spec.ComponentName()
-		fmt.Printf("- %s (Type: %s, Run Mode: %s)\n", name, spec.PluginType, spec.RunMode)
+		table.AddRow(spec.ComponentName(), spec.PluginType, spec.RunMode, spec.Timeout.Duration.String(), spec.Interval.Duration.String())
 	}
-
-	return nil
+	return pkgoutput.Render(os.Stdout, format, plugins, table)
 }
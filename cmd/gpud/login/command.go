@@ -10,10 +10,12 @@ import (
 
 	cmdcommon "github.com/leptonai/gpud/cmd/common"
 	"github.com/leptonai/gpud/pkg/config"
+	pkgendpoints "github.com/leptonai/gpud/pkg/endpoints"
 	"github.com/leptonai/gpud/pkg/log"
 	"github.com/leptonai/gpud/pkg/login"
 	pkgmachineinfo "github.com/leptonai/gpud/pkg/machine-info"
 	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	pkgmigrations "github.com/leptonai/gpud/pkg/migrations"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
 	"github.com/leptonai/gpud/pkg/osutil"
 	"github.com/leptonai/gpud/pkg/server"
@@ -76,12 +78,12 @@ func Command(cliContext *cli.Context) error {
 	defer dbRO.Close()
 	log.Logger.Debugw("successfully opened state file for reading")
 
-	// in case the table has not been created
-	log.Logger.Debugw("creating metadata table")
-	if err := pkgmetadata.CreateTableMetadata(rootCtx, dbRW); err != nil {
-		return fmt.Errorf("failed to create metadata table: %w", err)
+	// in case the tables have not been created
+	log.Logger.Debugw("migrating state file")
+	if _, _, err := pkgmigrations.Apply(rootCtx, dbRW); err != nil {
+		return fmt.Errorf("failed to migrate state file: %w", err)
 	}
-	log.Logger.Debugw("successfully created metadata table")
+	log.Logger.Debugw("successfully migrated state file")
 
 	log.Logger.Debugw("reading machine ID with fallback")
 	prevMachineID, err := pkgmetadata.ReadMachineIDWithFallback(rootCtx, dbRW, dbRO)
@@ -136,12 +138,18 @@ func Command(cliContext *cli.Context) error {
 		req.Network.PrivateIP = privateIP
 	}
 
+	privateIPv6 := cliContext.String("private-ipv6")
+	if privateIPv6 != "" { // overwrite if not empty
+		req.Network.PrivateIPv6 = privateIPv6
+	}
+
 	// machine ID has not been assigned yet
 	// thus request one and blocks until the login request is processed
 	loginSentAt := time.Now()
 	log.Logger.Debugw("sending login request")
 	endpoint := cliContext.String("endpoint")
-	loginResp, err := login.SendRequest(rootCtx, endpoint, *req)
+	// comma-separated for the failover, the first being the primary
+	loginResp, err := login.SendRequestWithFailover(rootCtx, pkgendpoints.Parse(endpoint), *req)
 	if err != nil {
 		log.Logger.Debugw("failed to login", "error", err)
 		if loginResp != nil {
@@ -186,6 +194,12 @@ func Command(cliContext *cli.Context) error {
 	}
 	log.Logger.Debugw("successfully recorded private IP")
 
+	log.Logger.Debugw("recording private IPv6")
+	if err := pkgmetadata.SetMetadata(rootCtx, dbRW, pkgmetadata.MetadataKeyPrivateIPv6, req.Network.PrivateIPv6); err != nil {
+		return fmt.Errorf("failed to record private IPv6: %w", err)
+	}
+	log.Logger.Debugw("successfully recorded private IPv6")
+
 	log.Logger.Debugw("getting fifo file")
 	fifoFile, err := config.DefaultFifoFile()
 	if err != nil {
@@ -8,6 +8,7 @@ import (
 
 	"github.com/urfave/cli"
 
+	apiv1 "github.com/leptonai/gpud/api/v1"
 	cmdcommon "github.com/leptonai/gpud/cmd/common"
 	"github.com/leptonai/gpud/pkg/config"
 	"github.com/leptonai/gpud/pkg/log"
@@ -15,9 +16,20 @@ import (
 	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
 	"github.com/leptonai/gpud/pkg/netutil"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	pkgoutput "github.com/leptonai/gpud/pkg/output"
+	"github.com/leptonai/gpud/pkg/providers"
 	"github.com/leptonai/gpud/pkg/sqlite"
 )
 
+// machineInfoOutput is the machine-readable output of the machine-info command.
+type machineInfoOutput struct {
+	// MachineID is empty if gpud has not logged in.
+	MachineID   string             `json:"machine_id,omitempty"`
+	MachineInfo *apiv1.MachineInfo `json:"machine_info"`
+	// Provider is nil if the provider is not found.
+	Provider *providers.Info `json:"provider,omitempty"`
+}
+
 func Command(cliContext *cli.Context) error {
 	logLevel := cliContext.String("log-level")
 	zapLvl, err := log.ParseLogLevel(logLevel)
@@ -28,6 +40,12 @@ func Command(cliContext *cli.Context) error {
 
 	log.Logger.Debugw("starting machine-info command")
 
+	format, err := pkgoutput.FormatFromContext(cliContext)
+	if err != nil {
+		return err
+	}
+	out := machineInfoOutput{}
+
 	stateFile, err := config.DefaultStateFile()
 	if err != nil {
 		return fmt.Errorf("failed to get state file: %w", err)
@@ -54,7 +72,10 @@ func Command(cliContext *cli.Context) error {
 			return err
 		}
 
-		fmt.Printf("GPUd machine ID: %q\n\n", machineID)
+		out.MachineID = machineID
+		if !format.IsMachineReadable() {
+			fmt.Printf("GPUd machine ID: %q\n\n", machineID)
+		}
 	}
 
 	nvmlInstance, err := nvidianvml.New()
@@ -66,10 +87,17 @@ func Command(cliContext *cli.Context) error {
 	if err != nil {
 		return err
 	}
-	machineInfo.RenderTable(os.Stdout)
 
 	pubIP, _ := netutil.PublicIP()
 	providerInfo := pkgmachineinfo.GetProvider(pubIP)
+
+	if format.IsMachineReadable() {
+		out.MachineInfo = machineInfo
+		out.Provider = providerInfo
+		return pkgoutput.Render(os.Stdout, format, out, nil)
+	}
+
+	machineInfo.RenderTable(os.Stdout)
 	if providerInfo == nil {
 		fmt.Printf("%s failed to find provider (%v)\n", cmdcommon.WarningSign, err)
 	} else {
@@ -3,6 +3,8 @@ package metadata
 import (
 	"context"
 	"fmt"
+	"os"
+	"sort"
 	"time"
 
 	"github.com/urfave/cli"
@@ -12,6 +14,7 @@ import (
 	"github.com/leptonai/gpud/pkg/log"
 	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
 	"github.com/leptonai/gpud/pkg/osutil"
+	pkgoutput "github.com/leptonai/gpud/pkg/output"
 	"github.com/leptonai/gpud/pkg/sqlite"
 )
 
@@ -25,6 +28,11 @@ func Command(cliContext *cli.Context) error {
 
 	log.Logger.Debugw("starting metadata command")
 
+	format, err := pkgoutput.FormatFromContext(cliContext)
+	if err != nil {
+		return err
+	}
+
 	if err := osutil.RequireRoot(); err != nil {
 		return err
 	}
@@ -53,11 +61,20 @@ func Command(cliContext *cli.Context) error {
 	}
 	log.Logger.Debugw("successfully read metadata")
 
-	for k, v := range metadata {
-		if k == pkgmetadata.MetadataKeyToken {
-			v = pkgmetadata.MaskToken(v)
-		}
-		fmt.Printf("%s: %s\n", k, v)
+	if tok, ok := metadata[pkgmetadata.MetadataKeyToken]; ok {
+		metadata[pkgmetadata.MetadataKeyToken] = pkgmetadata.MaskToken(tok)
+	}
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	table := &pkgoutput.Table{Header: []string{"Key", "Value"}}
+	for _, k := range keys {
+		table.AddRow(k, metadata[k])
+	}
+	if err := pkgoutput.Render(os.Stdout, format, metadata, table); err != nil {
+		return err
 	}
 
 	setKey := cliContext.String("set-key")
@@ -80,6 +97,8 @@ func Command(cliContext *cli.Context) error {
 	}
 	log.Logger.Debugw("successfully updated metadata")
 
-	fmt.Printf("%s successfully updated metadata\n", cmdcommon.CheckMark)
+	if !format.IsMachineReadable() {
+		fmt.Printf("%s successfully updated metadata\n", cmdcommon.CheckMark)
+	}
 	return nil
 }
@@ -0,0 +1,86 @@
+// Package nccltest implements the "nccl-test" command.
+package nccltest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/urfave/cli"
+
+	clientv1 "github.com/leptonai/gpud/client/v1"
+	"github.com/leptonai/gpud/pkg/config"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgnccltest "github.com/leptonai/gpud/pkg/nccl-test"
+	pkgoutput "github.com/leptonai/gpud/pkg/output"
+	pkgpeermesh "github.com/leptonai/gpud/pkg/peer-mesh"
+)
+
+// pollInterval is the interval to poll the result while waiting for the test.
+const pollInterval = 5 * time.Second
+
+// Command starts the inter-node NCCL bandwidth test with the gpud server as the
+// designated leader, and prints the result (once finished if "--wait" is set).
+func Command(cliContext *cli.Context) error {
+	logLevel := cliContext.String("log-level")
+	zapLvl, err := log.ParseLogLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	log.Logger = log.CreateLogger(zapLvl, "")
+
+	log.Logger.Debugw("starting nccl-test command")
+
+	// prints the json result by default, as before the output flag
+	format, err := pkgoutput.FormatFromContextOr(cliContext, pkgoutput.FormatJSON)
+	if err != nil {
+		return err
+	}
+
+	var peers pkgpeermesh.Peers
+	if s := cliContext.String("peers"); s != "" {
+		peers, err = pkgpeermesh.ParsePeers(s)
+		if err != nil {
+			return err
+		}
+	}
+	req := pkgnccltest.Request{
+		Mode:                pkgnccltest.Mode(cliContext.String("mode")),
+		Peers:               peers,
+		GPUsPerNode:         cliContext.Int("gpus-per-node"),
+		MessageSize:         cliContext.String("message-size"),
+		MinBusBandwidthGBps: cliContext.Float64("min-bus-bandwidth-gbps"),
+		LinkTimeoutSeconds:  int(cliContext.Duration("link-timeout").Seconds()),
+	}
+
+	serverAddr := cliContext.String("server")
+	if serverAddr == "" {
+		serverAddr = fmt.Sprintf("https://localhost:%d", config.DefaultGPUdPort)
+	}
+	cli := clientv1.NewClient(serverAddr)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	result, err := cli.StartNCCLTest(ctx, req)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to start nccl test: %w", err)
+	}
+
+	if cliContext.Bool("wait") {
+		for result.FinishedAt == nil {
+			// to the stderr, not to mix with the rendered result
+			fmt.Fprintf(os.Stderr, "waiting for nccl test (%d link(s) tested, %d pending)\n", len(result.Links), result.PendingLinks)
+			time.Sleep(pollInterval)
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			result, err = cli.GetNCCLTest(ctx)
+			cancel()
+			if err != nil {
+				return fmt.Errorf("failed to get nccl test result: %w", err)
+			}
+		}
+	}
+
+	return pkgoutput.Render(os.Stdout, format, result, nil)
+}
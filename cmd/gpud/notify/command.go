@@ -15,6 +15,7 @@ import (
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/pkg/config"
+	pkgendpoints "github.com/leptonai/gpud/pkg/endpoints"
 	"github.com/leptonai/gpud/pkg/log"
 	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
 	"github.com/leptonai/gpud/pkg/sqlite"
@@ -68,7 +69,7 @@ func CommandStartup(cliContext *cli.Context) error {
 		Type: apiv1.NotificationTypeStartup,
 	}
 
-	return sendNotification(endpoint, req)
+	return sendNotificationWithFailover(rootCtx, endpoint, req)
 }
 
 func CommandShutdown(cliContext *cli.Context) error {
@@ -119,7 +120,16 @@ func CommandShutdown(cliContext *cli.Context) error {
 		Type: apiv1.NotificationTypeShutdown,
 	}
 
-	return sendNotification(endpoint, req)
+	return sendNotificationWithFailover(rootCtx, endpoint, req)
+}
+
+// sendNotificationWithFailover sends the notification to the comma-separated endpoints
+// in order until one succeeds, the first being the primary.
+func sendNotificationWithFailover(ctx context.Context, endpoint string, req apiv1.NotificationRequest) error {
+	_, err := pkgendpoints.NewFailover(pkgendpoints.Parse(endpoint), 0).Do(ctx, func(_ context.Context, ep string) error {
+		return sendNotification(ep, req)
+	})
+	return err
 }
 
 func sendNotification(endpoint string, req apiv1.NotificationRequest) error {
@@ -27,16 +27,25 @@ func CommandGenKey(cliContext *cli.Context) error {
 
 	root := cliContext.Bool("root")
 	signing := cliContext.Bool("signing")
+	artifact := cliContext.Bool("artifact")
+	set := 0
+	for _, b := range []bool{root, signing, artifact} {
+		if b {
+			set++
+		}
+	}
 	var pub, priv []byte
 	switch {
-	case root && signing:
-		return errors.New("only one of --root or --signing can be set")
-	case !root && !signing:
-		return errors.New("set either --root or --signing")
+	case set > 1:
+		return errors.New("only one of --root, --signing, or --artifact can be set")
+	case set == 0:
+		return errors.New("set either --root, --signing, or --artifact")
 	case root:
 		priv, pub, err = distsign.GenerateRootKey()
 	case signing:
 		priv, pub, err = distsign.GenerateSigningKey()
+	case artifact:
+		priv, pub, err = distsign.GenerateArtifactKey()
 	}
 	if err != nil {
 		fmt.Printf("failed to generate key pair: %v\n", err)
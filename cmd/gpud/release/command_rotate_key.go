@@ -0,0 +1,70 @@
+package release
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/urfave/cli"
+
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/release/distsign"
+)
+
+// CommandRotateKey issues a new signing key signed by the root key, and writes the
+// transition bundle accepting both the new and the current signing keys until the
+// current keys retire after the overlap, so that the signing keys are rotated
+// without breaking the updates of the nodes.
+func CommandRotateKey(cliContext *cli.Context) error {
+	logLevel := cliContext.String("log-level")
+	zapLvl, err := log.ParseLogLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	log.Logger = log.CreateLogger(zapLvl, "")
+
+	log.Logger.Debugw("starting rotate-key command")
+
+	rootPrivPath := cliContext.String("root-priv-path")
+	rkRaw, err := os.ReadFile(rootPrivPath)
+	if err != nil {
+		return err
+	}
+	rk, err := distsign.ParseRootKey(rkRaw)
+	if err != nil {
+		return err
+	}
+
+	signPubPath := cliContext.String("sign-pub-path")
+	currentBundle, err := os.ReadFile(signPubPath)
+	if err != nil {
+		return err
+	}
+
+	overlap := cliContext.Duration("overlap")
+	priv, bundle, sig, err := rk.RotateSigningKeys(currentBundle, time.Now(), overlap)
+	if err != nil {
+		return err
+	}
+
+	privPath := cliContext.String("priv-path")
+	if err := os.WriteFile(privPath, priv, 0400); err != nil {
+		return fmt.Errorf("failed writing private key: %w", err)
+	}
+	fmt.Println("wrote new signing private key to", privPath)
+
+	pubPath := cliContext.String("pub-path")
+	if err := os.WriteFile(pubPath, bundle, 0400); err != nil {
+		return fmt.Errorf("failed writing transition bundle: %w", err)
+	}
+	fmt.Println("wrote transition bundle to", pubPath)
+
+	sigPath := cliContext.String("sig-path")
+	if err := os.WriteFile(sigPath, sig, 0400); err != nil {
+		return fmt.Errorf("failed writing signature file: %w", err)
+	}
+	fmt.Println("wrote transition bundle signature to", sigPath)
+
+	fmt.Printf("current signing keys accepted until %s\n", time.Now().Add(overlap).UTC().Format(time.RFC3339))
+	return nil
+}
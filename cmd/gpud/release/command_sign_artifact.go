@@ -0,0 +1,55 @@
+package release
+
+import (
+	"os"
+
+	"github.com/urfave/cli"
+
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/release/distsign"
+)
+
+// CommandSignArtifact signs the artifact of the type (e.g., the plugin specs,
+// the config files) with the artifact key.
+func CommandSignArtifact(cliContext *cli.Context) error {
+	logLevel := cliContext.String("log-level")
+	zapLvl, err := log.ParseLogLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	log.Logger = log.CreateLogger(zapLvl, "")
+
+	log.Logger.Debugw("starting sign-artifact command")
+
+	typ, err := distsign.ParseArtifactType(cliContext.String("type"))
+	if err != nil {
+		return err
+	}
+
+	signPrivPath := cliContext.String("sign-priv-path")
+	signPrivRaw, err := os.ReadFile(signPrivPath)
+	if err != nil {
+		return err
+	}
+	signPrivKey, err := distsign.ParseArtifactKey(signPrivRaw)
+	if err != nil {
+		return err
+	}
+
+	artifactPath := cliContext.String("artifact-path")
+	data, err := os.ReadFile(artifactPath)
+	if err != nil {
+		return err
+	}
+
+	sig, err := distsign.SignArtifact(signPrivKey, typ, data)
+	if err != nil {
+		return err
+	}
+
+	sigPath := cliContext.String("sig-path")
+	if sigPath == "" {
+		sigPath = artifactPath + distsign.SignatureSuffix
+	}
+	return os.WriteFile(sigPath, sig, 0400)
+}
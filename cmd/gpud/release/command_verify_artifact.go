@@ -0,0 +1,68 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli"
+
+	cmdcommon "github.com/leptonai/gpud/cmd/common"
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/release/distsign"
+)
+
+// CommandVerifyArtifact verifies the signatures of the artifacts of the type
+// (e.g., the plugin specs, the config files) with the artifact keys,
+// before the artifacts are applied to the node.
+func CommandVerifyArtifact(cliContext *cli.Context) error {
+	logLevel := cliContext.String("log-level")
+	zapLvl, err := log.ParseLogLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	log.Logger = log.CreateLogger(zapLvl, "")
+
+	log.Logger.Debugw("starting verify-artifact command")
+
+	paths := cliContext.Args()
+	if len(paths) == 0 {
+		return errors.New("no artifact path specified")
+	}
+	sigPath := cliContext.String("sig-path")
+	if sigPath != "" && len(paths) > 1 {
+		return errors.New("sig-path can only be set with a single artifact")
+	}
+
+	typ, err := distsign.ParseArtifactType(cliContext.String("type"))
+	if err != nil {
+		return err
+	}
+
+	signPubPath := cliContext.String("sign-pub-path")
+	if signPubPath == "" {
+		return errors.New("sign-pub-path is required")
+	}
+	signPubBundle, err := os.ReadFile(signPubPath)
+	if err != nil {
+		return err
+	}
+	signPubs, err := distsign.ParseArtifactKeyBundle(signPubBundle)
+	if err != nil {
+		return fmt.Errorf("parsing %q: %w", signPubPath, err)
+	}
+
+	failed := 0
+	for _, p := range paths {
+		if _, err := distsign.VerifyArtifactFile(signPubs, typ, p, sigPath); err != nil {
+			fmt.Printf("%s %v\n", cmdcommon.WarningSign, err)
+			failed++
+			continue
+		}
+		fmt.Printf("%s %s signature ok\n", cmdcommon.CheckMark, p)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d artifact(s) failed verification", failed, len(paths))
+	}
+	return nil
+}
@@ -10,16 +10,25 @@ import (
 	"strings"
 	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/gin-gonic/gin"
 	"github.com/urfave/cli"
 	"go.uber.org/zap"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/leptonai/gpud/components"
 	"github.com/leptonai/gpud/pkg/config"
+	pkgdevmode "github.com/leptonai/gpud/pkg/devmode"
 	gpudmanager "github.com/leptonai/gpud/pkg/gpud-manager"
+	pkglabels "github.com/leptonai/gpud/pkg/labels"
 	"github.com/leptonai/gpud/pkg/log"
+	pkgmaintenance "github.com/leptonai/gpud/pkg/maintenance"
+	latencytarget "github.com/leptonai/gpud/pkg/netutil/latency/target"
+	querygds "github.com/leptonai/gpud/pkg/nvidia-query/gds"
+	pkgpeermesh "github.com/leptonai/gpud/pkg/peer-mesh"
 	gpudserver "github.com/leptonai/gpud/pkg/server"
 	pkgsystemd "github.com/leptonai/gpud/pkg/systemd"
+	"github.com/leptonai/gpud/pkg/toolexec"
 	"github.com/leptonai/gpud/version"
 )
 
@@ -47,21 +56,117 @@ func Command(cliContext *cli.Context) error {
 
 	listenAddress := cliContext.String("listen-address")
 	pprof := cliContext.Bool("pprof")
+	enableWebUI := cliContext.Bool("web-ui")
+	enablePCIRescan := cliContext.Bool("enable-pci-rescan")
+	latencyTargets, err := latencytarget.ParseTargets(cliContext.String("latency-targets"))
+	if err != nil {
+		return err
+	}
+	peerMeshPeers, err := pkgpeermesh.ParsePeers(cliContext.String("peer-mesh-peers"))
+	if err != nil {
+		return err
+	}
+	enableComponentFaultInjection := cliContext.Bool("enable-component-fault-injection")
+	annotations, err := pkglabels.Parse(cliContext.String("annotations"))
+	if err != nil {
+		return err
+	}
+	toolPaths, err := pkglabels.Parse(cliContext.String("tool-paths"))
+	if err != nil {
+		return err
+	}
+	toolResourceLimits, err := toolexec.ParseResourceLimits(cliContext.String("tool-resource-limits"))
+	if err != nil {
+		return err
+	}
+	checkIntervals, err := components.ParseCheckIntervals(cliContext.String("component-intervals"))
+	if err != nil {
+		return err
+	}
+	checkBudgets, err := components.ParseCheckBudgets(cliContext.String("component-check-budgets"))
+	if err != nil {
+		return err
+	}
+	var checkLoadPolicy *components.LoadPolicy
+	deferChecksCPUPercent := cliContext.Float64("defer-checks-cpu-percent")
+	deferChecksGPUPercent := cliContext.Float64("defer-checks-gpu-percent")
+	if deferChecksCPUPercent > 0 || deferChecksGPUPercent > 0 {
+		checkLoadPolicy = &components.LoadPolicy{
+			CPUThresholdPercent: deferChecksCPUPercent,
+			GPUThresholdPercent: deferChecksGPUPercent,
+			MaxDeferral:         metav1.Duration{Duration: cliContext.Duration("defer-checks-max")},
+		}
+	}
+	var memoryCeiling, heapCeiling uint64
+	if s := cliContext.String("memory-ceiling"); s != "" {
+		memoryCeiling, err = humanize.ParseBytes(s)
+		if err != nil {
+			return fmt.Errorf("failed to parse memory ceiling %q: %w", s, err)
+		}
+	}
+	if s := cliContext.String("heap-ceiling"); s != "" {
+		heapCeiling, err = humanize.ParseBytes(s)
+		if err != nil {
+			return fmt.Errorf("failed to parse heap ceiling %q: %w", s, err)
+		}
+	}
+	baselineLearningPeriod := cliContext.Duration("baseline-learning-period")
+	var gdsProbe *querygds.ProbeConfig
+	if dir := cliContext.String("gds-probe-dir"); dir != "" {
+		gdsProbe = &querygds.ProbeConfig{
+			Dir:                dir,
+			GPUIndex:           cliContext.Int("gds-probe-gpu-index"),
+			MinThroughputGiBps: cliContext.Float64("gds-probe-min-throughput-gibps"),
+		}
+	}
+	predictionModel := cliContext.String("prediction-model")
 	retentionPeriod := cliContext.Duration("retention-period")
+	metricsArchiveDir := cliContext.String("metrics-archive-dir")
+	metricsArchiveUpload := cliContext.String("metrics-archive-upload")
 	enableAutoUpdate := cliContext.Bool("enable-auto-update")
 	autoUpdateExitCode := cliContext.Int("auto-update-exit-code")
 	pluginSpecsFile := cliContext.String("plugin-specs-file")
-	ibstatCommand := cliContext.String("ibstat-command")
-	ibstatusCommand := cliContext.String("ibstatus-command")
+	eventSinksFile := cliContext.String("event-sinks-file")
+	escalationLadderFile := cliContext.String("escalation-ladder-file")
+	hooksFile := cliContext.String("hooks-file")
+	kmsgMatchersFile := cliContext.String("kmsg-matchers-file")
+	lldpCablingMapFile := cliContext.String("lldp-cabling-map-file")
+	infinibandEvaluationFile := cliContext.String("infiniband-evaluation-file")
+	remediationPolicyFile := cliContext.String("remediation-policy-file")
+	errorBudgetFile := cliContext.String("error-budget-file")
+	artifactSignPubPath := cliContext.String("artifact-sign-pub-path")
+	var maintenanceDeferral *pkgmaintenance.Policy
+	deferMaintenanceKubelet := cliContext.Bool("defer-maintenance-kubelet")
+	deferMaintenanceHook := cliContext.String("defer-maintenance-hook")
+	if deferMaintenanceKubelet || deferMaintenanceHook != "" {
+		maintenanceDeferral = &pkgmaintenance.Policy{
+			Kubelet:          deferMaintenanceKubelet,
+			KubeletTokenFile: cliContext.String("defer-maintenance-kubelet-token-file"),
+			KubeletCAFile:    cliContext.String("defer-maintenance-kubelet-ca-file"),
+			BusyHook:         deferMaintenanceHook,
+			MaxDeferral:      metav1.Duration{Duration: cliContext.Duration("defer-maintenance-max")},
+		}
+	}
+	enableGPUAccounting := cliContext.Bool("enable-gpu-accounting")
+	enablePrometheus := cliContext.Bool("enable-prometheus")
+	otlpEndpoint := cliContext.String("otlp-endpoint")
 	components := cliContext.String("components")
 
-	configOpts := []config.OpOption{
-		config.WithIbstatCommand(ibstatCommand),
-		config.WithIbstatusCommand(ibstatusCommand),
+	var devModeCfg *pkgdevmode.Config
+	if cliContext.Bool("dev") {
+		scenarios, err := pkgdevmode.ParseScenarios(cliContext.String("dev-scenarios"))
+		if err != nil {
+			return err
+		}
+		devModeCfg = &pkgdevmode.Config{
+			GPUs:        cliContext.Int("dev-gpus"),
+			ProductName: cliContext.String("dev-product-name"),
+			Scenarios:   scenarios,
+		}
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
-	cfg, err := config.DefaultConfig(ctx, configOpts...)
+	cfg, err := config.DefaultConfig(ctx, config.WithToolPaths(toolPaths))
 	cancel()
 	if err != nil {
 		return err
@@ -73,16 +178,72 @@ func Command(cliContext *cli.Context) error {
 	if pprof {
 		cfg.Pprof = true
 	}
+	if enableWebUI {
+		cfg.EnableWebUI = true
+	}
+	if enablePCIRescan {
+		cfg.EnablePCIRescan = true
+	}
+	if len(latencyTargets) > 0 {
+		cfg.LatencyTargets = latencyTargets
+	}
+	if len(peerMeshPeers) > 0 {
+		cfg.PeerMeshPeers = peerMeshPeers
+	}
+	if enableComponentFaultInjection {
+		cfg.EnableComponentFaultInjection = true
+	}
+	if len(annotations) > 0 {
+		cfg.Annotations = annotations
+	}
+	if len(toolResourceLimits) > 0 {
+		cfg.ToolResourceLimits = toolResourceLimits
+	}
+	if len(checkIntervals) > 0 {
+		cfg.ComponentIntervals = make(map[string]metav1.Duration, len(checkIntervals))
+		for name, d := range checkIntervals {
+			cfg.ComponentIntervals[name] = metav1.Duration{Duration: d}
+		}
+	}
+	if len(checkBudgets) > 0 {
+		cfg.ComponentCheckBudgets = make(map[string]metav1.Duration, len(checkBudgets))
+		for name, d := range checkBudgets {
+			cfg.ComponentCheckBudgets[name] = metav1.Duration{Duration: d}
+		}
+	}
+	cfg.CheckLoadPolicy = checkLoadPolicy
+	cfg.BaselineLearningPeriod = metav1.Duration{Duration: baselineLearningPeriod}
+	cfg.GDSProbe = gdsProbe
+	cfg.DevMode = devModeCfg
+	cfg.MemoryCeilingBytes = memoryCeiling
+	cfg.HeapCeilingBytes = heapCeiling
+	cfg.PredictionModel = predictionModel
 	if retentionPeriod > 0 {
 		cfg.RetentionPeriod = metav1.Duration{Duration: retentionPeriod}
 	}
 
+	cfg.MetricsArchiveDir = metricsArchiveDir
+	cfg.MetricsArchiveUploadDestination = metricsArchiveUpload
+
 	cfg.CompactPeriod = config.DefaultCompactPeriod
 
 	cfg.EnableAutoUpdate = enableAutoUpdate
 	cfg.AutoUpdateExitCode = autoUpdateExitCode
 
 	cfg.PluginSpecsFile = pluginSpecsFile
+	cfg.EventSinksFile = eventSinksFile
+	cfg.EscalationLadderFile = escalationLadderFile
+	cfg.HooksFile = hooksFile
+	cfg.KmsgMatchersFile = kmsgMatchersFile
+	cfg.LLDPCablingMapFile = lldpCablingMapFile
+	cfg.InfinibandEvaluationFile = infinibandEvaluationFile
+	cfg.RemediationPolicyFile = remediationPolicyFile
+	cfg.ErrorBudgetFile = errorBudgetFile
+	cfg.ArtifactSignPubPath = artifactSignPubPath
+	cfg.MaintenanceDeferral = maintenanceDeferral
+	cfg.EnableGPUAccounting = enableGPUAccounting
+	cfg.EnablePrometheus = enablePrometheus
+	cfg.OTLPEndpoint = otlpEndpoint
 
 	if components != "" {
 		cfg.Components = strings.Split(components, ",")
@@ -3,29 +3,48 @@ package scan
 import (
 	"context"
 	"encoding/json"
+	"os"
 	"time"
 
 	"github.com/urfave/cli"
 	"go.uber.org/zap"
 
 	componentsnfs "github.com/leptonai/gpud/components/nfs"
+	"github.com/leptonai/gpud/pkg/config"
+	pkghost "github.com/leptonai/gpud/pkg/host"
+	pkglabels "github.com/leptonai/gpud/pkg/labels"
 	"github.com/leptonai/gpud/pkg/log"
 	pkgnfschecker "github.com/leptonai/gpud/pkg/nfs-checker"
+	pkgoutput "github.com/leptonai/gpud/pkg/output"
+	pkgprobecache "github.com/leptonai/gpud/pkg/probecache"
 	"github.com/leptonai/gpud/pkg/scan"
+	"github.com/leptonai/gpud/pkg/sqlite"
+	"github.com/leptonai/gpud/pkg/toolexec"
+	"github.com/leptonai/gpud/pkg/toolpath"
+	"github.com/leptonai/gpud/pkg/upload"
 )
 
 func CreateCommand() func(*cli.Context) error {
 	return func(cliContext *cli.Context) error {
+		format, err := pkgoutput.FormatFromContext(cliContext)
+		if err != nil {
+			return err
+		}
 		return cmdScan(
 			cliContext.String("log-level"),
-			cliContext.String("ibstat-command"),
-			cliContext.String("ibstatus-command"),
+			cliContext.String("tool-paths"),
 			cliContext.String("nfs-checker-configs"),
+			cliContext.String("profile"),
+			cliContext.String("record"),
+			cliContext.String("replay"),
+			cliContext.String("upload"),
+			cliContext.String("tool-resource-limits"),
+			format,
 		)
 	}
 }
 
-func cmdScan(logLevel string, ibstatCommand string, ibstatusCommand string, nfsCheckerConfigs string) error {
+func cmdScan(logLevel string, toolPaths string, nfsCheckerConfigs string, profileName string, recordDir string, replayDir string, uploadDest string, toolResourceLimits string, format pkgoutput.Format) error {
 	zapLvl, err := log.ParseLogLevel(logLevel)
 	if err != nil {
 		return err
@@ -34,6 +53,36 @@ func cmdScan(logLevel string, ibstatCommand string, ibstatusCommand string, nfsC
 
 	log.Logger.Debugw("starting scan command")
 
+	profile, err := scan.ParseProfile(profileName)
+	if err != nil {
+		return err
+	}
+
+	// fail before scanning on the invalid destination
+	var uploader upload.Uploader
+	if uploadDest != "" {
+		uploader, err = upload.New(uploadDest)
+		if err != nil {
+			return err
+		}
+	}
+
+	overrides, err := pkglabels.Parse(toolPaths)
+	if err != nil {
+		return err
+	}
+	if err := toolpath.SetOverrides(overrides); err != nil {
+		return err
+	}
+
+	limits, err := toolexec.ParseResourceLimits(toolResourceLimits)
+	if err != nil {
+		return err
+	}
+	if err := toolexec.SetResourceLimits(limits); err != nil {
+		return err
+	}
+
 	if len(nfsCheckerConfigs) > 0 {
 		groupConfigs := make(pkgnfschecker.Configs, 0)
 		if err := json.Unmarshal([]byte(nfsCheckerConfigs), &groupConfigs); err != nil {
@@ -44,18 +93,68 @@ func cmdScan(logLevel string, ibstatCommand string, ibstatusCommand string, nfsC
 	}
 
 	opts := []scan.OpOption{
-		scan.WithIbstatCommand(ibstatCommand),
-		scan.WithIbstatusCommand(ibstatusCommand),
+		scan.WithProfile(profile),
+		scan.WithOutput(format),
+		scan.WithRecordDir(recordDir),
+		scan.WithReplayDir(replayDir),
+	}
+	if uploader != nil {
+		opts = append(opts, scan.WithUploader(uploader))
 	}
 	if zapLvl.Level() <= zap.DebugLevel { // e.g., info, warn, error
 		opts = append(opts, scan.WithDebug(true))
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
-	defer cancel()
-	if err = scan.Scan(ctx, opts...); err != nil {
+	if probeCache, closeFunc := openProbeCache(context.Background()); probeCache != nil {
+		defer closeFunc()
+		opts = append(opts, scan.WithProbeCache(probeCache))
+	}
+
+	// the scan enforces the time budget of the profile
+	if err = scan.Scan(context.Background(), opts...); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// openProbeCache opens the probe cache in the existing state file of gpud,
+// to skip the redundant static discoveries on the repeated scans.
+// Returns nil if the state file does not exist or is not accessible
+// (e.g., not running as root), in which case the scan probes everything.
+func openProbeCache(ctx context.Context) (*pkgprobecache.Cache, func()) {
+	stateFile, err := config.DefaultStateFile()
+	if err != nil {
+		return nil, nil
+	}
+	if _, err := os.Stat(stateFile); err != nil {
+		return nil, nil
+	}
+
+	dbRW, err := sqlite.Open(stateFile)
+	if err != nil {
+		log.Logger.Debugw("failed to open state file, skipping probe cache", "error", err)
+		return nil, nil
+	}
+	dbRO, err := sqlite.Open(stateFile, sqlite.WithReadOnly(true))
+	if err != nil {
+		_ = dbRW.Close()
+		log.Logger.Debugw("failed to open state file, skipping probe cache", "error", err)
+		return nil, nil
+	}
+	closeFunc := func() {
+		_ = dbRO.Close()
+		_ = dbRW.Close()
+	}
+
+	cctx, ccancel := context.WithTimeout(ctx, 10*time.Second)
+	c, err := pkgprobecache.New(cctx, dbRW, dbRO, pkghost.BootID())
+	ccancel()
+	if err != nil {
+		// e.g., the state file not migrated by the newer gpud yet
+		log.Logger.Debugw("failed to open probe cache, skipping", "error", err)
+		closeFunc()
+		return nil, nil
+	}
+	return c, closeFunc
+}
@@ -0,0 +1,83 @@
+// Package simulate implements the "simulate" command.
+package simulate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	clientv1 "github.com/leptonai/gpud/client/v1"
+	"github.com/leptonai/gpud/pkg/config"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgoutput "github.com/leptonai/gpud/pkg/output"
+	pkgsimulate "github.com/leptonai/gpud/pkg/simulate"
+)
+
+// Command injects the synthetic event and/or health state into the running gpud server.
+func Command(cliContext *cli.Context) error {
+	logLevel := cliContext.String("log-level")
+	zapLvl, err := log.ParseLogLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	log.Logger = log.CreateLogger(zapLvl, "")
+
+	log.Logger.Debugw("starting simulate command")
+
+	// prints the json response by default, as before the output flag
+	format, err := pkgoutput.FormatFromContextOr(cliContext, pkgoutput.FormatJSON)
+	if err != nil {
+		return err
+	}
+
+	if cliContext.Bool("list-scenarios") {
+		if cliContext.String(pkgoutput.FlagName) != "" || cliContext.GlobalString(pkgoutput.FlagName) != "" {
+			return pkgoutput.Render(os.Stdout, format, pkgsimulate.Scenarios(), nil)
+		}
+		fmt.Println(strings.Join(pkgsimulate.Scenarios(), "\n"))
+		return nil
+	}
+
+	req := apiv1.SimulationRequest{
+		Scenario:  cliContext.String("scenario"),
+		Component: cliContext.String("component"),
+		Duration:  metav1.Duration{Duration: cliContext.Duration("duration")},
+	}
+	if name := cliContext.String("event-name"); name != "" {
+		req.Event = &apiv1.Event{
+			Name:    name,
+			Type:    apiv1.EventType(cliContext.String("event-type")),
+			Message: cliContext.String("event-message"),
+		}
+	}
+	if health := cliContext.String("health"); health != "" {
+		req.HealthState = &apiv1.HealthState{
+			Health: apiv1.HealthStateType(health),
+			Reason: cliContext.String("reason"),
+		}
+	}
+	if req.Scenario == "" && req.Component == "" {
+		return fmt.Errorf("either --scenario or --component is required (available scenarios: %s)", strings.Join(pkgsimulate.Scenarios(), ", "))
+	}
+
+	serverAddr := cliContext.String("server")
+	if serverAddr == "" {
+		serverAddr = fmt.Sprintf("https://localhost:%d", config.DefaultGPUdPort)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	resp, err := clientv1.Simulate(ctx, serverAddr, req)
+	if err != nil {
+		return fmt.Errorf("failed to simulate: %w", err)
+	}
+
+	return pkgoutput.Render(os.Stdout, format, resp, nil)
+}
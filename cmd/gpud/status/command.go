@@ -3,17 +3,21 @@ package status
 import (
 	"context"
 	"fmt"
+	"os"
+	"sort"
 	"strconv"
 	"time"
 
 	"github.com/dustin/go-humanize"
 	"github.com/urfave/cli"
 
+	apiv1 "github.com/leptonai/gpud/api/v1"
 	clientv1 "github.com/leptonai/gpud/client/v1"
 	cmdcommon "github.com/leptonai/gpud/cmd/common"
 	"github.com/leptonai/gpud/pkg/config"
 	"github.com/leptonai/gpud/pkg/log"
 	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	pkgoutput "github.com/leptonai/gpud/pkg/output"
 	"github.com/leptonai/gpud/pkg/process"
 	"github.com/leptonai/gpud/pkg/server"
 	"github.com/leptonai/gpud/pkg/sqlite"
@@ -33,6 +37,28 @@ func Command(cliContext *cli.Context) error {
 	rootCtx, rootCancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer rootCancel()
 
+	// only print the selected health states, without the other status checks
+	// so that the scripts can parse the output reliably
+	selectedComponents := cliContext.String("components")
+	selectedFields := cliContext.String("fields")
+	format, err := pkgoutput.FormatFromContext(cliContext)
+	if err != nil {
+		return err
+	}
+	if file := cliContext.String("save-snapshot"); file != "" {
+		return saveSnapshot(rootCtx, fmt.Sprintf("https://localhost:%d", config.DefaultGPUdPort), file)
+	}
+	if against := cliContext.String("diff"); against != "" {
+		return printDiff(rootCtx, fmt.Sprintf("https://localhost:%d", config.DefaultGPUdPort), against, format)
+	}
+	if history := cliContext.Int("history"); history > 0 {
+		return printHealthHistory(rootCtx, fmt.Sprintf("https://localhost:%d", config.DefaultGPUdPort), selectedComponents, history, format)
+	}
+	// the machine-readable output only prints the health states
+	if selectedComponents != "" || selectedFields != "" || format.IsMachineReadable() {
+		return printSelectedHealthStates(rootCtx, fmt.Sprintf("https://localhost:%d", config.DefaultGPUdPort), selectedComponents, selectedFields, format)
+	}
+
 	log.Logger.Debugw("getting state file")
 	stateFile, err := config.DefaultStateFile()
 	if err != nil {
@@ -111,6 +137,15 @@ func Command(cliContext *cli.Context) error {
 	}
 	fmt.Printf("%s successfully checked gpud health\n", cmdcommon.CheckMark)
 
+	cctx, ccancel := context.WithTimeout(rootCtx, 15*time.Second)
+	findings, err := clientv1.GetFindings(cctx, fmt.Sprintf("https://localhost:%d", config.DefaultGPUdPort))
+	ccancel()
+	if err != nil {
+		fmt.Printf("%s failed to get reported findings: %v\n", cmdcommon.WarningSign, err)
+	} else {
+		writeFindings(os.Stdout, findings, time.Now().UTC())
+	}
+
 	statusWatch := cliContext.Bool("watch")
 
 	for {
@@ -148,3 +183,54 @@ func Command(cliContext *cli.Context) error {
 
 	return nil
 }
+
+func printSelectedHealthStates(ctx context.Context, addr string, selectedComponents string, selectedFields string, format pkgoutput.Format) error {
+	fields, err := parseFields(selectedFields)
+	if err != nil {
+		return err
+	}
+	if format == pkgoutput.FormatWide && selectedFields == "" {
+		fields = supportedFields
+	}
+
+	if err := clientv1.BlockUntilServerReady(ctx, addr); err != nil {
+		return err
+	}
+
+	components := parseList(selectedComponents)
+	opts := make([]clientv1.OpOption, 0, len(components))
+	for _, c := range components {
+		opts = append(opts, clientv1.WithComponent(c))
+	}
+
+	cctx, ccancel := context.WithTimeout(ctx, 15*time.Second)
+	states, err := clientv1.GetHealthStates(cctx, addr, opts...)
+	ccancel()
+	if err != nil {
+		return fmt.Errorf("failed to get health states: %w", err)
+	}
+
+	states = sortByComponents(states, components)
+	if format.IsMachineReadable() {
+		return pkgoutput.Render(os.Stdout, format, states, nil)
+	}
+	return writeSelectedFields(os.Stdout, states, fields)
+}
+
+// sortByComponents sorts the health states in the order of the requested components,
+// or by the component name if no component is requested.
+func sortByComponents(states apiv1.GPUdComponentHealthStates, components []string) apiv1.GPUdComponentHealthStates {
+	order := make(map[string]int, len(components))
+	for i, c := range components {
+		order[c] = i
+	}
+	sort.SliceStable(states, func(i, j int) bool {
+		oi, iok := order[states[i].Component]
+		oj, jok := order[states[j].Component]
+		if iok && jok {
+			return oi < oj
+		}
+		return states[i].Component < states[j].Component
+	})
+	return states
+}
@@ -0,0 +1,477 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	clientv1 "github.com/leptonai/gpud/client/v1"
+	cmdcommon "github.com/leptonai/gpud/cmd/common"
+	pkgoutput "github.com/leptonai/gpud/pkg/output"
+	"github.com/leptonai/gpud/pkg/server"
+)
+
+// snapshot is the health states and the inventory of the node at a point in time,
+// saved before the maintenance to compare against afterwards.
+type snapshot struct {
+	Time   time.Time                       `json:"time"`
+	States apiv1.GPUdComponentHealthStates `json:"states"`
+	// Machine is nil if the inventory is not known
+	// (e.g., the health states reconstructed from the history).
+	Machine *apiv1.MachineInfo `json:"machine,omitempty"`
+}
+
+// healthChange is the change of a health state since the snapshot.
+type healthChange struct {
+	Component string `json:"component"`
+	Name      string `json:"name,omitempty"`
+	// Before is empty if the health state did not exist in the snapshot.
+	Before apiv1.HealthStateType `json:"before,omitempty"`
+	// After is empty if the health state no longer exists.
+	After  apiv1.HealthStateType `json:"after,omitempty"`
+	Reason string                `json:"reason,omitempty"`
+	// NewFailureCodes are the failure codes not in the snapshot.
+	NewFailureCodes []apiv1.FailureCode `json:"new_failure_codes,omitempty"`
+}
+
+// inventoryChange is the change of an inventory field since the snapshot
+// (e.g., the driver version, a GPU gone).
+type inventoryChange struct {
+	Field string `json:"field"`
+	// Before is empty if newly added.
+	Before string `json:"before,omitempty"`
+	// After is empty if gone.
+	After string `json:"after,omitempty"`
+}
+
+// statusDiff is what changed since the snapshot.
+type statusDiff struct {
+	Since  time.Time      `json:"since"`
+	Health []healthChange `json:"health,omitempty"`
+	// Inventory is not compared if the snapshot has no inventory.
+	InventoryCompared bool              `json:"inventory_compared"`
+	Inventory         []inventoryChange `json:"inventory,omitempty"`
+}
+
+// saveSnapshot saves the current health states and inventory to the file.
+func saveSnapshot(ctx context.Context, addr string, file string) error {
+	cur, err := takeSnapshot(ctx, addr)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(cur, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, b, 0644); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	fmt.Printf("%s saved the snapshot of %d component(s) to %s\n", cmdcommon.CheckMark, len(cur.States), file)
+	return nil
+}
+
+// printDiff prints what changed since the saved snapshot file, or since the point in time
+// (RFC3339 timestamp or the duration ago) reconstructed from the health transitions.
+func printDiff(ctx context.Context, addr string, against string, format pkgoutput.Format) error {
+	cur, err := takeSnapshot(ctx, addr)
+	if err != nil {
+		return err
+	}
+
+	var before *snapshot
+	if _, statErr := os.Stat(against); statErr == nil {
+		before, err = loadSnapshot(against)
+		if err != nil {
+			return err
+		}
+	} else {
+		since, err := parseDiffTime(against, cur.Time)
+		if err != nil {
+			return fmt.Errorf("%q is neither a snapshot file nor a time: %w", against, err)
+		}
+
+		entries, err := getHealthTransitions(since, cur.Time, func(from, to time.Time) (*apiv1.Timeline, error) {
+			cctx, ccancel := context.WithTimeout(ctx, 15*time.Second)
+			defer ccancel()
+			return clientv1.GetTimeline(cctx, addr,
+				clientv1.WithStartTime(from),
+				clientv1.WithEndTime(to),
+				clientv1.WithLimit(server.MaxEventsQueryLimit),
+			)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get health transitions: %w", err)
+		}
+		before = snapshotAt(cur, entries, since)
+	}
+
+	d := diffSnapshots(before, cur)
+	if format.IsMachineReadable() {
+		return pkgoutput.Render(os.Stdout, format, d, nil)
+	}
+	return writeDiff(os.Stdout, d, cur.Time)
+}
+
+func takeSnapshot(ctx context.Context, addr string) (*snapshot, error) {
+	if err := clientv1.BlockUntilServerReady(ctx, addr); err != nil {
+		return nil, err
+	}
+
+	cctx, ccancel := context.WithTimeout(ctx, 15*time.Second)
+	states, err := clientv1.GetHealthStates(cctx, addr)
+	ccancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get health states: %w", err)
+	}
+
+	cctx, ccancel = context.WithTimeout(ctx, 15*time.Second)
+	machine, err := clientv1.GetMachineInfo(cctx, addr)
+	ccancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get machine info: %w", err)
+	}
+
+	return &snapshot{
+		Time:    time.Now().UTC(),
+		States:  sortByComponents(states, nil),
+		Machine: machine,
+	}, nil
+}
+
+func loadSnapshot(file string) (*snapshot, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var s snapshot
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %q: %w", file, err)
+	}
+	return &s, nil
+}
+
+// parseDiffTime parses the RFC3339 timestamp, or the duration ago (e.g., "24h").
+func parseDiffTime(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC(), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected a RFC3339 timestamp or a duration (e.g., 24h)")
+	}
+	if d <= 0 {
+		return time.Time{}, fmt.Errorf("duration must be positive, got %v", d)
+	}
+	return now.Add(-d), nil
+}
+
+// getHealthTransitions returns the timeline entries from the time until the end,
+// paging backwards while the timeline is truncated to the limit (the latest entries kept),
+// since the earliest health transitions are the ones to roll back to.
+func getHealthTransitions(since time.Time, until time.Time, getTimeline func(from, to time.Time) (*apiv1.Timeline, error)) ([]apiv1.TimelineEntry, error) {
+	var entries []apiv1.TimelineEntry
+	to := until
+	for {
+		timeline, err := getTimeline(since, to)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, timeline.Entries...)
+		if !timeline.Truncated || len(timeline.Entries) == 0 {
+			return entries, nil
+		}
+
+		// the timeline is queried in seconds, so the next page overlaps
+		// the oldest second of this page (the duplicates do not change the earliest transitions)
+		oldest := timeline.Entries[0].Time.Time
+		next := oldest.Truncate(time.Second).Add(time.Second)
+		if !next.Before(to) {
+			return nil, fmt.Errorf("more than %d timeline entries at %s, cannot reconstruct the health states", len(timeline.Entries), oldest.UTC().Format(time.RFC3339))
+		}
+		to = next
+	}
+}
+
+// snapshotAt reconstructs the health states at the time from the current health states,
+// rolling back the health transitions since the time. The inventory is not known.
+func snapshotAt(cur *snapshot, entries []apiv1.TimelineEntry, t time.Time) *snapshot {
+	// the earliest transition after the time has the health at the time
+	earliest := make(map[string]apiv1.TimelineEntry)
+	for _, e := range entries {
+		if e.Kind != apiv1.TimelineEntryKindHealthTransition || !e.Time.After(t) {
+			continue
+		}
+		key := e.Component + "/" + e.Name
+		if prev, ok := earliest[key]; !ok || e.Time.Before(&prev.Time) {
+			earliest[key] = e
+		}
+	}
+
+	states := make(apiv1.GPUdComponentHealthStates, 0, len(cur.States))
+	for _, cs := range cur.States {
+		past := apiv1.ComponentHealthStates{Component: cs.Component}
+		for _, st := range cs.States {
+			if e, ok := earliest[cs.Component+"/"+st.Name]; ok {
+				if e.PreviousHealth == "" {
+					// first observed unhealthy after the time
+					continue
+				}
+				st = apiv1.HealthState{Name: st.Name, Health: e.PreviousHealth}
+			}
+			past.States = append(past.States, st)
+		}
+		states = append(states, past)
+	}
+	return &snapshot{Time: t, States: states}
+}
+
+// diffSnapshots returns what changed from the before to the after snapshot.
+func diffSnapshots(before *snapshot, after *snapshot) statusDiff {
+	d := statusDiff{Since: before.Time}
+
+	prev := make(map[string]apiv1.HealthState)
+	for _, cs := range before.States {
+		for _, st := range cs.States {
+			prev[cs.Component+"/"+st.Name] = st
+		}
+	}
+
+	seen := make(map[string]struct{})
+	for _, cs := range after.States {
+		for _, st := range cs.States {
+			key := cs.Component + "/" + st.Name
+			seen[key] = struct{}{}
+
+			p, ok := prev[key]
+			if !ok {
+				d.Health = append(d.Health, healthChange{Component: cs.Component, Name: st.Name, After: st.Health, Reason: st.Reason, NewFailureCodes: st.FailureCodes})
+				continue
+			}
+
+			newCodes := newFailureCodes(p.FailureCodes, st.FailureCodes)
+			if p.Health != st.Health || len(newCodes) > 0 {
+				d.Health = append(d.Health, healthChange{Component: cs.Component, Name: st.Name, Before: p.Health, After: st.Health, Reason: st.Reason, NewFailureCodes: newCodes})
+			}
+		}
+	}
+	for _, cs := range before.States {
+		for _, st := range cs.States {
+			if _, ok := seen[cs.Component+"/"+st.Name]; !ok {
+				d.Health = append(d.Health, healthChange{Component: cs.Component, Name: st.Name, Before: st.Health})
+			}
+		}
+	}
+	sort.SliceStable(d.Health, func(i, j int) bool {
+		if d.Health[i].Component != d.Health[j].Component {
+			return d.Health[i].Component < d.Health[j].Component
+		}
+		return d.Health[i].Name < d.Health[j].Name
+	})
+
+	if before.Machine != nil && after.Machine != nil {
+		d.InventoryCompared = true
+		d.Inventory = diffMachineInfo(before.Machine, after.Machine)
+	}
+	return d
+}
+
+func newFailureCodes(before []apiv1.FailureCode, after []apiv1.FailureCode) []apiv1.FailureCode {
+	prev := make(map[apiv1.FailureCode]struct{}, len(before))
+	for _, c := range before {
+		prev[c] = struct{}{}
+	}
+	var codes []apiv1.FailureCode
+	for _, c := range after {
+		if _, ok := prev[c]; !ok {
+			codes = append(codes, c)
+		}
+	}
+	return codes
+}
+
+// diffMachineInfo returns the changed versions (including the GPU and infiniband firmware)
+// and the GPUs gone or added.
+func diffMachineInfo(before *apiv1.MachineInfo, after *apiv1.MachineInfo) []inventoryChange {
+	var changes []inventoryChange
+	for _, f := range []struct {
+		field         string
+		before, after string
+	}{
+		{"gpud_version", before.GPUdVersion, after.GPUdVersion},
+		{"gpu_driver_version", before.GPUDriverVersion, after.GPUDriverVersion},
+		{"cuda_version", before.CUDAVersion, after.CUDAVersion},
+		{"kernel_version", before.KernelVersion, after.KernelVersion},
+		{"os_image", before.OSImage, after.OSImage},
+		{"container_runtime_version", before.ContainerRuntimeVersion, after.ContainerRuntimeVersion},
+		{"boot_id", before.BootID, after.BootID},
+	} {
+		if f.before != f.after {
+			changes = append(changes, inventoryChange{Field: f.field, Before: f.before, After: f.after})
+		}
+	}
+
+	prev, cur := gpuIDs(before.GPUInfo), gpuIDs(after.GPUInfo)
+	for _, id := range sortedKeys(prev) {
+		if _, ok := cur[id]; !ok {
+			changes = append(changes, inventoryChange{Field: "gpu", Before: id})
+		}
+	}
+	for _, id := range sortedKeys(cur) {
+		if _, ok := prev[id]; !ok {
+			changes = append(changes, inventoryChange{Field: "gpu", After: id})
+		}
+	}
+
+	changes = append(changes, diffFirmwareVersions("gpu_vbios_version", gpuVBIOSVersions(before.GPUInfo), gpuVBIOSVersions(after.GPUInfo))...)
+	changes = append(changes, diffFirmwareVersions("infiniband_firmware_version", infinibandFirmwareVersions(before.NICInfo), infinibandFirmwareVersions(after.NICInfo))...)
+	return changes
+}
+
+// diffFirmwareVersions returns the changed firmware versions of the devices in both,
+// skipping the unknown versions (e.g., the snapshot taken before the versions were collected).
+func diffFirmwareVersions(field string, before map[string]string, after map[string]string) []inventoryChange {
+	ids := make(map[string]struct{}, len(after))
+	for id := range after {
+		ids[id] = struct{}{}
+	}
+
+	var changes []inventoryChange
+	for _, id := range sortedKeys(ids) {
+		prev, ok := before[id]
+		if !ok || prev == "" || after[id] == "" || prev == after[id] {
+			continue
+		}
+		changes = append(changes, inventoryChange{Field: field + " (" + id + ")", Before: prev, After: after[id]})
+	}
+	return changes
+}
+
+// gpuVBIOSVersions returns the VBIOS versions keyed by the GPU UUID.
+func gpuVBIOSVersions(info *apiv1.MachineGPUInfo) map[string]string {
+	versions := make(map[string]string)
+	if info == nil {
+		return versions
+	}
+	for _, g := range info.GPUs {
+		versions[g.UUID] = g.VBIOSVersion
+	}
+	return versions
+}
+
+// infinibandFirmwareVersions returns the firmware versions keyed by the infiniband device.
+func infinibandFirmwareVersions(info *apiv1.MachineNICInfo) map[string]string {
+	versions := make(map[string]string)
+	if info == nil {
+		return versions
+	}
+	for _, d := range info.InfinibandDevices {
+		versions[d.Device] = d.FirmwareVersion
+	}
+	return versions
+}
+
+// gpuIDs returns the GPUs keyed by the UUID (and the serial number if known),
+// to tell the replaced GPU.
+func gpuIDs(info *apiv1.MachineGPUInfo) map[string]struct{} {
+	gpus := make(map[string]struct{})
+	if info == nil {
+		return gpus
+	}
+	for _, g := range info.GPUs {
+		id := g.UUID
+		if g.SN != "" {
+			id += " (SN " + g.SN + ")"
+		}
+		gpus[id] = struct{}{}
+	}
+	return gpus
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writeDiff writes the health and inventory changes since the snapshot.
+func writeDiff(wr io.Writer, d statusDiff, now time.Time) error {
+	if _, err := fmt.Fprintf(wr, "changes since %s (%s)\n", d.Since.UTC().Format(time.RFC3339), humanize.RelTime(d.Since, now, "ago", "from now")); err != nil {
+		return err
+	}
+
+	if len(d.Health) == 0 {
+		if _, err := fmt.Fprintf(wr, "%s no health change\n", cmdcommon.CheckMark); err != nil {
+			return err
+		}
+	} else {
+		if _, err := fmt.Fprintf(wr, "%s %d health change(s)\n", cmdcommon.WarningSign, len(d.Health)); err != nil {
+			return err
+		}
+		for _, c := range d.Health {
+			line := "  " + c.Component
+			if c.Name != "" && c.Name != c.Component {
+				line += "/" + c.Name
+			}
+			switch {
+			case c.Before == "":
+				line += fmt.Sprintf(": new (%s)", c.After)
+			case c.After == "":
+				line += fmt.Sprintf(": gone (was %s)", c.Before)
+			default:
+				line += fmt.Sprintf(": %s -> %s", c.Before, c.After)
+			}
+			if len(c.NewFailureCodes) > 0 {
+				codes := make([]string, 0, len(c.NewFailureCodes))
+				for _, code := range c.NewFailureCodes {
+					codes = append(codes, string(code))
+				}
+				line += ", new failure codes " + strings.Join(codes, ",")
+			}
+			if c.Reason != "" && c.After != apiv1.HealthStateTypeHealthy {
+				line += ": " + fieldValue(c.Component, apiv1.HealthState{Reason: c.Reason}, fieldReason)
+			}
+			if _, err := fmt.Fprintln(wr, line); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !d.InventoryCompared {
+		_, err := fmt.Fprintln(wr, "inventory not compared (no inventory in the history)")
+		return err
+	}
+	if len(d.Inventory) == 0 {
+		_, err := fmt.Fprintf(wr, "%s no inventory change\n", cmdcommon.CheckMark)
+		return err
+	}
+	if _, err := fmt.Fprintf(wr, "%s %d inventory change(s)\n", cmdcommon.WarningSign, len(d.Inventory)); err != nil {
+		return err
+	}
+	for _, c := range d.Inventory {
+		var line string
+		switch {
+		case c.Before == "":
+			line = fmt.Sprintf("  %s: added %s", c.Field, c.After)
+		case c.After == "":
+			line = fmt.Sprintf("  %s: gone %s", c.Field, c.Before)
+		default:
+			line = fmt.Sprintf("  %s: %s -> %s", c.Field, c.Before, c.After)
+		}
+		if _, err := fmt.Fprintln(wr, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
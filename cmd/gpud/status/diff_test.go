@@ -0,0 +1,220 @@
+package status
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	now := time.Date(2025, 1, 2, 4, 0, 0, 0, time.UTC)
+	before := &snapshot{
+		Time: now.Add(-2 * time.Hour),
+		States: apiv1.GPUdComponentHealthStates{
+			{Component: "cpu", States: []apiv1.HealthState{{Name: "cpu", Health: apiv1.HealthStateTypeHealthy}}},
+			{Component: "disk", States: []apiv1.HealthState{{Name: "disk", Health: apiv1.HealthStateTypeHealthy}}},
+			{Component: "nfs", States: []apiv1.HealthState{{Name: "nfs", Health: apiv1.HealthStateTypeHealthy}}},
+		},
+		Machine: &apiv1.MachineInfo{
+			GPUDriverVersion: "535.161.08",
+			KernelVersion:    "5.15.0",
+			GPUInfo: &apiv1.MachineGPUInfo{GPUs: []apiv1.MachineGPUInstance{
+				{UUID: "GPU-0", SN: "1", VBIOSVersion: "96.00.89.00.01"},
+				{UUID: "GPU-1", SN: "2", VBIOSVersion: "96.00.89.00.01"},
+			}},
+			NICInfo: &apiv1.MachineNICInfo{InfinibandDevices: []apiv1.MachineInfinibandDevice{
+				{Device: "mlx5_0", FirmwareVersion: "28.39.1002"},
+				{Device: "mlx5_1", FirmwareVersion: "28.39.1002"},
+			}},
+		},
+	}
+	after := &snapshot{
+		Time: now,
+		States: apiv1.GPUdComponentHealthStates{
+			{Component: "cpu", States: []apiv1.HealthState{{Name: "cpu", Health: apiv1.HealthStateTypeHealthy}}},
+			{Component: "disk", States: []apiv1.HealthState{{Name: "disk", Health: apiv1.HealthStateTypeUnhealthy, Reason: "disk\nfull", FailureCodes: []apiv1.FailureCode{"disk_full"}}}},
+			{Component: "xid", States: []apiv1.HealthState{{Name: "xid", Health: apiv1.HealthStateTypeHealthy}}},
+		},
+		Machine: &apiv1.MachineInfo{
+			GPUDriverVersion: "550.54.15",
+			KernelVersion:    "5.15.0",
+			GPUInfo: &apiv1.MachineGPUInfo{GPUs: []apiv1.MachineGPUInstance{
+				{UUID: "GPU-0", SN: "1", VBIOSVersion: "96.00.9F.00.01"},
+			}},
+			NICInfo: &apiv1.MachineNICInfo{InfinibandDevices: []apiv1.MachineInfinibandDevice{
+				{Device: "mlx5_0", FirmwareVersion: "28.41.1000"},
+				// unknown version not compared
+				{Device: "mlx5_1"},
+			}},
+		},
+	}
+
+	d := diffSnapshots(before, after)
+	assert.Equal(t, before.Time, d.Since)
+	assert.Equal(t, []healthChange{
+		{Component: "disk", Name: "disk", Before: apiv1.HealthStateTypeHealthy, After: apiv1.HealthStateTypeUnhealthy, Reason: "disk\nfull", NewFailureCodes: []apiv1.FailureCode{"disk_full"}},
+		{Component: "nfs", Name: "nfs", Before: apiv1.HealthStateTypeHealthy},
+		{Component: "xid", Name: "xid", After: apiv1.HealthStateTypeHealthy},
+	}, d.Health)
+	assert.True(t, d.InventoryCompared)
+	assert.Equal(t, []inventoryChange{
+		{Field: "gpu_driver_version", Before: "535.161.08", After: "550.54.15"},
+		{Field: "gpu", Before: "GPU-1 (SN 2)"},
+		{Field: "gpu_vbios_version (GPU-0)", Before: "96.00.89.00.01", After: "96.00.9F.00.01"},
+		{Field: "infiniband_firmware_version (mlx5_0)", Before: "28.39.1002", After: "28.41.1000"},
+	}, d.Inventory)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeDiff(buf, d, now))
+	out := buf.String()
+	assert.Contains(t, out, "changes since 2025-01-02T02:00:00Z (2 hours ago)\n")
+	assert.Contains(t, out, "  disk: Healthy -> Unhealthy, new failure codes disk_full: disk full\n")
+	assert.Contains(t, out, "  nfs: gone (was Healthy)\n")
+	assert.Contains(t, out, "  xid: new (Healthy)\n")
+	assert.Contains(t, out, "  gpu_driver_version: 535.161.08 -> 550.54.15\n")
+	assert.Contains(t, out, "  gpu: gone GPU-1 (SN 2)\n")
+	assert.Contains(t, out, "  infiniband_firmware_version (mlx5_0): 28.39.1002 -> 28.41.1000\n")
+
+	// no change
+	d = diffSnapshots(after, after)
+	assert.Empty(t, d.Health)
+	assert.Empty(t, d.Inventory)
+	buf.Reset()
+	require.NoError(t, writeDiff(buf, d, now))
+	assert.Contains(t, buf.String(), "no health change\n")
+	assert.Contains(t, buf.String(), "no inventory change\n")
+}
+
+func TestSnapshotAt(t *testing.T) {
+	now := time.Date(2025, 1, 2, 4, 0, 0, 0, time.UTC)
+	cur := &snapshot{
+		Time: now,
+		States: apiv1.GPUdComponentHealthStates{
+			{Component: "cpu", States: []apiv1.HealthState{{Name: "cpu", Health: apiv1.HealthStateTypeHealthy}}},
+			{Component: "disk", States: []apiv1.HealthState{{Name: "disk", Health: apiv1.HealthStateTypeUnhealthy, Reason: "disk full"}}},
+			{Component: "xid", States: []apiv1.HealthState{{Name: "xid", Health: apiv1.HealthStateTypeUnhealthy}}},
+		},
+	}
+	entries := []apiv1.TimelineEntry{
+		// before the time, ignored
+		{Time: metav1.NewTime(now.Add(-3 * time.Hour)), Kind: apiv1.TimelineEntryKindHealthTransition, Component: "disk", Name: "disk", PreviousHealth: apiv1.HealthStateTypeHealthy, Health: apiv1.HealthStateTypeUnhealthy},
+		{Time: metav1.NewTime(now.Add(-90 * time.Minute)), Kind: apiv1.TimelineEntryKindHealthTransition, Component: "disk", Name: "disk", PreviousHealth: apiv1.HealthStateTypeDegraded, Health: apiv1.HealthStateTypeHealthy},
+		{Time: metav1.NewTime(now.Add(-time.Hour)), Kind: apiv1.TimelineEntryKindHealthTransition, Component: "disk", Name: "disk", PreviousHealth: apiv1.HealthStateTypeHealthy, Health: apiv1.HealthStateTypeUnhealthy},
+		{Time: metav1.NewTime(now.Add(-time.Hour)), Kind: apiv1.TimelineEntryKindEvent, Component: "cpu", Name: "cpu"},
+		// first observed after the time
+		{Time: metav1.NewTime(now.Add(-time.Hour)), Kind: apiv1.TimelineEntryKindHealthTransition, Component: "xid", Name: "xid", Health: apiv1.HealthStateTypeUnhealthy},
+	}
+
+	past := snapshotAt(cur, entries, now.Add(-2*time.Hour))
+	assert.Equal(t, now.Add(-2*time.Hour), past.Time)
+	assert.Nil(t, past.Machine)
+	assert.Equal(t, apiv1.GPUdComponentHealthStates{
+		{Component: "cpu", States: []apiv1.HealthState{{Name: "cpu", Health: apiv1.HealthStateTypeHealthy}}},
+		{Component: "disk", States: []apiv1.HealthState{{Name: "disk", Health: apiv1.HealthStateTypeDegraded}}},
+		{Component: "xid"},
+	}, past.States)
+
+	d := diffSnapshots(past, cur)
+	assert.False(t, d.InventoryCompared)
+	require.Len(t, d.Health, 2)
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, d.Health[0].Before)
+	assert.Equal(t, apiv1.HealthStateType(""), d.Health[1].Before)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeDiff(buf, d, now))
+	assert.Contains(t, buf.String(), "inventory not compared")
+}
+
+func TestGetHealthTransitions(t *testing.T) {
+	now := time.Date(2025, 1, 2, 4, 0, 0, 0, time.UTC)
+	since := now.Add(-2 * time.Hour)
+	all := []apiv1.TimelineEntry{
+		{Time: metav1.NewTime(now.Add(-90 * time.Minute)), Kind: apiv1.TimelineEntryKindHealthTransition, Component: "disk", Name: "disk", PreviousHealth: apiv1.HealthStateTypeDegraded},
+		{Time: metav1.NewTime(now.Add(-time.Hour)), Kind: apiv1.TimelineEntryKindEvent, Component: "cpu"},
+		{Time: metav1.NewTime(now.Add(-30 * time.Minute)), Kind: apiv1.TimelineEntryKindEvent, Component: "cpu"},
+		{Time: metav1.NewTime(now.Add(-10 * time.Minute)), Kind: apiv1.TimelineEntryKindHealthTransition, Component: "disk", Name: "disk", PreviousHealth: apiv1.HealthStateTypeHealthy},
+	}
+
+	// keeps the latest 2 entries, same as the server
+	calls := 0
+	getTimeline := func(from, to time.Time) (*apiv1.Timeline, error) {
+		calls++
+		tl := &apiv1.Timeline{From: from, To: to}
+		for _, e := range all {
+			if !e.Time.Time.Before(from) && !e.Time.Time.After(to) {
+				tl.Entries = append(tl.Entries, e)
+			}
+		}
+		if len(tl.Entries) > 2 {
+			tl.Entries = tl.Entries[len(tl.Entries)-2:]
+			tl.Truncated = true
+		}
+		return tl, nil
+	}
+
+	entries, err := getHealthTransitions(since, now, getTimeline)
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	// the earliest transition is not lost to the truncation
+	past := snapshotAt(&snapshot{Time: now, States: apiv1.GPUdComponentHealthStates{
+		{Component: "disk", States: []apiv1.HealthState{{Name: "disk", Health: apiv1.HealthStateTypeUnhealthy}}},
+	}}, entries, since)
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, past.States[0].States[0].Health)
+
+	// cannot page back past the entries at the same second
+	_, err = getHealthTransitions(since, now, func(from, to time.Time) (*apiv1.Timeline, error) {
+		return &apiv1.Timeline{Entries: []apiv1.TimelineEntry{{Time: metav1.NewTime(now)}}, Truncated: true}, nil
+	})
+	assert.Error(t, err)
+}
+
+func TestParseDiffTime(t *testing.T) {
+	now := time.Date(2025, 1, 2, 4, 0, 0, 0, time.UTC)
+
+	ts, err := parseDiffTime("2025-01-01T04:00:00Z", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-24*time.Hour), ts)
+
+	ts, err = parseDiffTime("24h", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-24*time.Hour), ts)
+
+	_, err = parseDiffTime("-1h", now)
+	assert.Error(t, err)
+	_, err = parseDiffTime("yesterday", now)
+	assert.Error(t, err)
+}
+
+func TestLoadSnapshot(t *testing.T) {
+	s := snapshot{
+		Time:    time.Date(2025, 1, 2, 4, 0, 0, 0, time.UTC),
+		States:  apiv1.GPUdComponentHealthStates{{Component: "cpu", States: []apiv1.HealthState{{Name: "cpu", Health: apiv1.HealthStateTypeHealthy}}}},
+		Machine: &apiv1.MachineInfo{GPUDriverVersion: "535.161.08"},
+	}
+	b, err := json.Marshal(s)
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "snapshot.json")
+	require.NoError(t, os.WriteFile(file, b, 0644))
+
+	loaded, err := loadSnapshot(file)
+	require.NoError(t, err)
+	assert.Equal(t, s.Time, loaded.Time)
+	assert.Equal(t, "535.161.08", loaded.Machine.GPUDriverVersion)
+	require.Len(t, loaded.States, 1)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, loaded.States[0].States[0].Health)
+
+	require.NoError(t, os.WriteFile(file, []byte("{"), 0644))
+	_, err = loadSnapshot(file)
+	assert.Error(t, err)
+}
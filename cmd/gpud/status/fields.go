@@ -0,0 +1,114 @@
+package status
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+const (
+	fieldComponent        = "component"
+	fieldName             = "name"
+	fieldHealth           = "health"
+	fieldReason           = "reason"
+	fieldError            = "error"
+	fieldUpdated          = "updated"
+	fieldSuggestedActions = "suggested_actions"
+)
+
+var (
+	supportedFields = []string{
+		fieldComponent,
+		fieldName,
+		fieldHealth,
+		fieldReason,
+		fieldError,
+		fieldUpdated,
+		fieldSuggestedActions,
+	}
+	defaultFields = []string{fieldComponent, fieldHealth, fieldReason}
+)
+
+// parseList splits the comma-separated list, trimming the spaces and dropping the empty entries.
+func parseList(s string) []string {
+	var ret []string
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
+
+// parseFields parses the comma-separated field selectors,
+// and returns the default fields if none is specified.
+func parseFields(s string) ([]string, error) {
+	fields := parseList(s)
+	if len(fields) == 0 {
+		return defaultFields, nil
+	}
+
+	supported := make(map[string]struct{}, len(supportedFields))
+	for _, f := range supportedFields {
+		supported[f] = struct{}{}
+	}
+	for i, f := range fields {
+		f = strings.ToLower(f)
+		if _, ok := supported[f]; !ok {
+			return nil, fmt.Errorf("unsupported field %q (supported: %s)", f, strings.Join(supportedFields, ", "))
+		}
+		fields[i] = f
+	}
+	return fields, nil
+}
+
+// fieldValue returns the value of the field for the health state,
+// with the tabs and newlines replaced so that each state stays in a single line.
+func fieldValue(component string, state apiv1.HealthState, field string) string {
+	v := ""
+	switch field {
+	case fieldComponent:
+		v = component
+	case fieldName:
+		v = state.Name
+	case fieldHealth:
+		v = string(state.Health)
+	case fieldReason:
+		v = state.Reason
+	case fieldError:
+		v = state.Error
+	case fieldUpdated:
+		if !state.Time.IsZero() {
+			v = state.Time.UTC().Format(time.RFC3339)
+		}
+	case fieldSuggestedActions:
+		if state.SuggestedActions != nil {
+			v = state.SuggestedActions.DescribeActions()
+		}
+	}
+	return strings.NewReplacer("\t", " ", "\n", " ").Replace(v)
+}
+
+// writeSelectedFields writes the selected fields of the health states
+// as tab-separated values with a header line, one line per health state.
+func writeSelectedFields(wr io.Writer, states apiv1.GPUdComponentHealthStates, fields []string) error {
+	if _, err := fmt.Fprintln(wr, strings.Join(fields, "\t")); err != nil {
+		return err
+	}
+	for _, cs := range states {
+		for _, s := range cs.States {
+			vals := make([]string, 0, len(fields))
+			for _, f := range fields {
+				vals = append(vals, fieldValue(cs.Component, s, f))
+			}
+			if _, err := fmt.Fprintln(wr, strings.Join(vals, "\t")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
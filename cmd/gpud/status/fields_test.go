@@ -0,0 +1,73 @@
+package status
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestParseFields(t *testing.T) {
+	fields, err := parseFields("")
+	require.NoError(t, err)
+	assert.Equal(t, defaultFields, fields)
+
+	fields, err = parseFields(" Health, reason,,updated ")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"health", "reason", "updated"}, fields)
+
+	_, err = parseFields("health,unknown")
+	require.Error(t, err)
+}
+
+func TestParseList(t *testing.T) {
+	assert.Nil(t, parseList(""))
+	assert.Equal(t, []string{"cpu", "disk"}, parseList("cpu, disk,"))
+}
+
+func TestWriteSelectedFields(t *testing.T) {
+	ts := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	states := apiv1.GPUdComponentHealthStates{
+		{
+			Component: "cpu",
+			States: []apiv1.HealthState{
+				{Time: metav1.NewTime(ts), Name: "cpu", Health: apiv1.HealthStateTypeHealthy, Reason: "ok"},
+			},
+		},
+		{
+			Component: "disk",
+			States: []apiv1.HealthState{
+				{Name: "disk", Health: apiv1.HealthStateTypeUnhealthy, Reason: "line1\nline2\tx"},
+			},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeSelectedFields(buf, states, []string{"component", "health", "reason", "updated"}))
+	assert.Equal(t, "component\thealth\treason\tupdated\n"+
+		"cpu\tHealthy\tok\t2025-01-02T03:04:05Z\n"+
+		"disk\tUnhealthy\tline1 line2 x\t\n", buf.String())
+}
+
+func TestSortByComponents(t *testing.T) {
+	states := apiv1.GPUdComponentHealthStates{
+		{Component: "a"},
+		{Component: "c"},
+		{Component: "b"},
+	}
+
+	sorted := sortByComponents(states, []string{"c", "a", "b"})
+	assert.Equal(t, "c", sorted[0].Component)
+	assert.Equal(t, "a", sorted[1].Component)
+	assert.Equal(t, "b", sorted[2].Component)
+
+	sorted = sortByComponents(states, nil)
+	assert.Equal(t, "a", sorted[0].Component)
+	assert.Equal(t, "b", sorted[1].Component)
+	assert.Equal(t, "c", sorted[2].Component)
+}
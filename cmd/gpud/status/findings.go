@@ -0,0 +1,41 @@
+package status
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	cmdcommon "github.com/leptonai/gpud/cmd/common"
+)
+
+// writeFindings writes whether the control plane acknowledged
+// the reported findings, listing the unacknowledged ones.
+func writeFindings(w io.Writer, findings []apiv1.Finding, now time.Time) {
+	if len(findings) == 0 {
+		fmt.Fprintf(w, "%s no unhealthy finding reported to the control plane\n", cmdcommon.CheckMark)
+		return
+	}
+
+	var pending []apiv1.Finding
+	for _, f := range findings {
+		if !f.Acknowledged() {
+			pending = append(pending, f)
+		}
+	}
+	if len(pending) == 0 {
+		fmt.Fprintf(w, "%s all %d reported finding(s) acknowledged by the control plane\n", cmdcommon.CheckMark, len(findings))
+		return
+	}
+
+	fmt.Fprintf(w, "%s %d of %d reported finding(s) not acknowledged by the control plane\n", cmdcommon.WarningSign, len(pending), len(findings))
+	for _, f := range pending {
+		line := fmt.Sprintf("  - %s/%s (%s) reported %s", f.Component, f.Name, f.Health, humanize.RelTime(f.FirstReportedAt.Time, now, "ago", "from now"))
+		if f.Escalations > 0 {
+			line += fmt.Sprintf(", re-escalated %d time(s)", f.Escalations)
+		}
+		fmt.Fprintln(w, line)
+	}
+}
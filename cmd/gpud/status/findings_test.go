@@ -0,0 +1,40 @@
+package status
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestWriteFindings(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 30, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	writeFindings(&buf, nil, now)
+	assert.Contains(t, buf.String(), "no unhealthy finding reported")
+
+	acked := apiv1.Finding{ID: "a", Component: "accelerator-nvidia-ecc", Name: "ecc", Health: apiv1.HealthStateTypeDegraded, AckID: "INC-1"}
+	buf.Reset()
+	writeFindings(&buf, []apiv1.Finding{acked}, now)
+	assert.Contains(t, buf.String(), "all 1 reported finding(s) acknowledged")
+
+	pending := apiv1.Finding{
+		ID:              "b",
+		Component:       "accelerator-nvidia-infiniband",
+		Name:            "accelerator-nvidia-infiniband",
+		Health:          apiv1.HealthStateTypeUnhealthy,
+		FirstReportedAt: metav1.NewTime(now.Add(-20 * time.Minute)),
+		Escalations:     1,
+	}
+	buf.Reset()
+	writeFindings(&buf, []apiv1.Finding{acked, pending}, now)
+	out := buf.String()
+	assert.Contains(t, out, "1 of 2 reported finding(s) not acknowledged")
+	assert.Contains(t, out, "accelerator-nvidia-infiniband/accelerator-nvidia-infiniband (Unhealthy) reported 20 minutes ago, re-escalated 1 time(s)")
+	assert.NotContains(t, out, "accelerator-nvidia-ecc/ecc")
+}
@@ -0,0 +1,138 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	clientv1 "github.com/leptonai/gpud/client/v1"
+	pkgoutput "github.com/leptonai/gpud/pkg/output"
+	"github.com/leptonai/gpud/pkg/server"
+)
+
+// componentHistory is the current health state of a component
+// with its last health transitions (the latest first).
+type componentHistory struct {
+	Component   string                `json:"component"`
+	Health      apiv1.HealthStateType `json:"health"`
+	Reason      string                `json:"reason,omitempty"`
+	Transitions []apiv1.TimelineEntry `json:"transitions"`
+}
+
+// printHealthHistory prints the current health state of each component,
+// followed by its last n health transitions.
+func printHealthHistory(ctx context.Context, addr string, selectedComponents string, n int, format pkgoutput.Format) error {
+	if err := clientv1.BlockUntilServerReady(ctx, addr); err != nil {
+		return err
+	}
+
+	components := parseList(selectedComponents)
+	opts := make([]clientv1.OpOption, 0, len(components))
+	for _, c := range components {
+		opts = append(opts, clientv1.WithComponent(c))
+	}
+
+	cctx, ccancel := context.WithTimeout(ctx, 15*time.Second)
+	states, err := clientv1.GetHealthStates(cctx, addr, opts...)
+	ccancel()
+	if err != nil {
+		return fmt.Errorf("failed to get health states: %w", err)
+	}
+
+	now := time.Now().UTC()
+	cctx, ccancel = context.WithTimeout(ctx, 15*time.Second)
+	timeline, err := clientv1.GetTimeline(cctx, addr, append(opts,
+		clientv1.WithStartTime(now.Add(-server.MaxEventsQueryRange)),
+		clientv1.WithEndTime(now),
+		clientv1.WithLimit(server.MaxEventsQueryLimit),
+	)...)
+	ccancel()
+	if err != nil {
+		return fmt.Errorf("failed to get health transitions: %w", err)
+	}
+
+	histories := buildHealthHistory(sortByComponents(states, components), timeline.Entries, n)
+	if format.IsMachineReadable() {
+		return pkgoutput.Render(os.Stdout, format, histories, nil)
+	}
+	return writeHealthHistory(os.Stdout, histories, now)
+}
+
+// buildHealthHistory returns the current health state of each component,
+// with its last n health transitions (the latest first).
+func buildHealthHistory(states apiv1.GPUdComponentHealthStates, entries []apiv1.TimelineEntry, n int) []componentHistory {
+	transitions := make(map[string][]apiv1.TimelineEntry)
+	for _, e := range entries {
+		if e.Kind != apiv1.TimelineEntryKindHealthTransition {
+			continue
+		}
+		transitions[e.Component] = append(transitions[e.Component], e)
+	}
+
+	histories := make([]componentHistory, 0, len(states))
+	for _, cs := range states {
+		h := componentHistory{
+			Component:   cs.Component,
+			Health:      apiv1.HealthStateTypeHealthy,
+			Transitions: make([]apiv1.TimelineEntry, 0),
+		}
+		for _, s := range cs.States {
+			if s.Health != apiv1.HealthStateTypeHealthy {
+				h.Health = s.Health
+				h.Reason = s.Reason
+				break
+			}
+			if h.Reason == "" {
+				h.Reason = s.Reason
+			}
+		}
+
+		ts := transitions[cs.Component]
+		for i := len(ts) - 1; i >= 0 && i >= len(ts)-n; i-- {
+			h.Transitions = append(h.Transitions, ts[i])
+		}
+		histories = append(histories, h)
+	}
+	return histories
+}
+
+// writeHealthHistory writes the current health state of each component,
+// followed by its last health transitions (the latest first).
+func writeHealthHistory(wr io.Writer, histories []componentHistory, now time.Time) error {
+	for _, h := range histories {
+		if _, err := fmt.Fprintf(wr, "%s: %s (%s)\n", h.Component, h.Health, fieldValue(h.Component, apiv1.HealthState{Reason: h.Reason}, fieldReason)); err != nil {
+			return err
+		}
+
+		if len(h.Transitions) == 0 {
+			if _, err := fmt.Fprintln(wr, "  no health transitions"); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, e := range h.Transitions {
+			prev := string(e.PreviousHealth)
+			if prev == "" {
+				prev = "?"
+			}
+			line := fmt.Sprintf("  %s (%s) %s -> %s",
+				e.Time.UTC().Format(time.RFC3339),
+				humanize.RelTime(e.Time.Time, now, "ago", "from now"),
+				prev,
+				e.Health,
+			)
+			if e.Message != "" {
+				line += ": " + fieldValue(h.Component, apiv1.HealthState{Reason: e.Message}, fieldReason)
+			}
+			if _, err := fmt.Fprintln(wr, line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
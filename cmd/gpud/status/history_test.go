@@ -0,0 +1,47 @@
+package status
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestWriteHealthHistory(t *testing.T) {
+	now := time.Date(2025, 1, 2, 4, 0, 0, 0, time.UTC)
+	states := apiv1.GPUdComponentHealthStates{
+		{
+			Component: "cpu",
+			States:    []apiv1.HealthState{{Health: apiv1.HealthStateTypeHealthy, Reason: "ok"}},
+		},
+		{
+			Component: "disk",
+			States:    []apiv1.HealthState{{Health: apiv1.HealthStateTypeUnhealthy, Reason: "disk\nfull"}},
+		},
+	}
+	entries := []apiv1.TimelineEntry{
+		{Time: metav1.NewTime(now.Add(-3 * time.Hour)), Kind: apiv1.TimelineEntryKindHealthTransition, Component: "disk", Health: apiv1.HealthStateTypeUnhealthy},
+		{Time: metav1.NewTime(now.Add(-2 * time.Hour)), Kind: apiv1.TimelineEntryKindEvent, Component: "disk", Name: "ignored"},
+		{Time: metav1.NewTime(now.Add(-2 * time.Hour)), Kind: apiv1.TimelineEntryKindHealthTransition, Component: "disk", PreviousHealth: apiv1.HealthStateTypeUnhealthy, Health: apiv1.HealthStateTypeHealthy},
+		{Time: metav1.NewTime(now.Add(-time.Hour)), Kind: apiv1.TimelineEntryKindHealthTransition, Component: "disk", PreviousHealth: apiv1.HealthStateTypeHealthy, Health: apiv1.HealthStateTypeUnhealthy, Message: "disk full"},
+	}
+
+	buf := &bytes.Buffer{}
+	histories := buildHealthHistory(states, entries, 2)
+	require.Len(t, histories, 2)
+	assert.Empty(t, histories[0].Transitions)
+	require.Len(t, histories[1].Transitions, 2)
+	assert.Equal(t, "disk full", histories[1].Transitions[0].Message)
+
+	require.NoError(t, writeHealthHistory(buf, histories, now))
+	assert.Equal(t, "cpu: Healthy (ok)\n"+
+		"  no health transitions\n"+
+		"disk: Unhealthy (disk full)\n"+
+		"  2025-01-02T03:00:00Z (1 hour ago) Healthy -> Unhealthy: disk full\n"+
+		"  2025-01-02T02:00:00Z (2 hours ago) Unhealthy -> Healthy\n", buf.String())
+}
@@ -2,14 +2,18 @@
 package up
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/urfave/cli"
 
+	cmdcommon "github.com/leptonai/gpud/cmd/common"
 	cmdlogin "github.com/leptonai/gpud/cmd/gpud/login"
 	"github.com/leptonai/gpud/pkg/gpud-manager/systemd"
 	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/lsm"
 	"github.com/leptonai/gpud/pkg/osutil"
 	pkdsystemd "github.com/leptonai/gpud/pkg/systemd"
 	pkgupdate "github.com/leptonai/gpud/pkg/update"
@@ -30,6 +34,17 @@ func Command(cliContext *cli.Context) (retErr error) {
 		return err
 	}
 
+	unitOpts := systemd.UnitOptions{
+		CPUQuota:    cliContext.String("cpu-quota"),
+		MemoryMax:   cliContext.String("memory-max"),
+		Nice:        cliContext.Int("nice"),
+		Environment: cliContext.StringSlice("env"),
+		ExtraFlags:  cliContext.String("extra-flags"),
+	}
+	if err := unitOpts.Validate(); err != nil {
+		return err
+	}
+
 	// step 1.
 	// perform "login" if and only if configured
 	if cliContext.String("token") != "" {
@@ -61,9 +76,13 @@ func Command(cliContext *cli.Context) (retErr error) {
 	}
 	log.Logger.Debugw("gpud binary exists")
 
+	if err := verifySecurityModules(cliContext.Bool("install-security-policy")); err != nil {
+		return err
+	}
+
 	log.Logger.Debugw("starting systemd init")
 	endpoint := cliContext.String("endpoint")
-	if err := systemdInit(endpoint); err != nil {
+	if err := systemdInit(endpoint, unitOpts); err != nil {
 		return err
 	}
 	log.Logger.Debugw("successfully started systemd init")
@@ -74,6 +93,16 @@ func Command(cliContext *cli.Context) (retErr error) {
 	}
 	log.Logger.Debugw("successfully enabled systemd unit")
 
+	// only starts the unit if not running, to apply the updated unit on the next restart
+	if cliContext.Bool("no-restart") {
+		log.Logger.Debugw("starting systemd unit without restart")
+		if err := pkgupdate.StartGPUdSystemdUnit(); err != nil {
+			return err
+		}
+		log.Logger.Debugw("successfully started systemd unit (the running daemon is not restarted)")
+		return nil
+	}
+
 	log.Logger.Debugw("restarting systemd unit")
 	if err := pkgupdate.RestartGPUdSystemdUnit(); err != nil {
 		return err
@@ -84,10 +113,61 @@ func Command(cliContext *cli.Context) (retErr error) {
 	return nil
 }
 
-func systemdInit(endpoint string) error {
+func systemdInit(endpoint string, unitOpts systemd.UnitOptions) error {
 	if err := systemd.CreateDefaultEnvFile(endpoint); err != nil {
 		return err
 	}
 	systemdUnitFileData := systemd.GPUdServiceUnitFileContents()
-	return os.WriteFile(systemd.DefaultUnitFile, []byte(systemdUnitFileData), 0644)
+	if err := os.WriteFile(systemd.DefaultUnitFile, []byte(systemdUnitFileData), 0644); err != nil {
+		return err
+	}
+	return systemd.WriteUnitDropIn(systemd.DefaultUnitDropInFile, unitOpts)
+}
+
+// verifySecurityModules finds the SELinux and AppArmor denials affecting gpud
+// (e.g., from the previous runs), and installs the policy allowing them if requested.
+func verifySecurityModules(installPolicy bool) error {
+	log.Logger.Debugw("scanning security module denials")
+	rs, err := lsm.Scan()
+	if err != nil {
+		// the scan is best-effort, not to block the install
+		log.Logger.Warnw("failed to scan security module denials", "error", err)
+		return nil
+	}
+	if len(rs.Denials) == 0 {
+		log.Logger.Debugw("no security module denial found", "selinuxEnforcing", rs.SELinuxEnforcing, "apparmorEnabled", rs.AppArmorEnabled)
+		return nil
+	}
+
+	for _, d := range rs.Denials {
+		fmt.Printf("%s [%s] %s\n", cmdcommon.WarningSign, d.Feature, d)
+	}
+	if !installPolicy {
+		fmt.Printf("%s found %d security module denial(s) affecting gpud (run 'gpud up --install-security-policy' to allow them)\n", cmdcommon.WarningSign, len(rs.Denials))
+		return nil
+	}
+
+	// only allows the accesses needed by the gpud features,
+	// not to widen the policy for the unrelated denials
+	var needed []lsm.Denial
+	for _, d := range rs.Denials {
+		if d.Feature != lsm.FeatureOther {
+			needed = append(needed, d)
+		}
+	}
+	if len(needed) == 0 {
+		fmt.Printf("%s no denial of the module loading, /dev/kmsg, or NVML found, skipped installing security policy\n", cmdcommon.CheckMark)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	written, err := lsm.InstallPolicy(ctx, needed)
+	if err != nil {
+		return fmt.Errorf("failed to install security policy: %w", err)
+	}
+	for _, f := range written {
+		fmt.Printf("%s installed security policy %s\n", cmdcommon.CheckMark, f)
+	}
+	return nil
 }
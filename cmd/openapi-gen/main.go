@@ -0,0 +1,42 @@
+// openapi-gen converts the Swagger 2.0 docs generated by swag into the OpenAPI 3 spec.
+//
+// e.g.,
+//
+//	go run ./cmd/openapi-gen -input ./docs/apis/swagger.json -output ./docs/apis/openapi.json
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/leptonai/gpud/pkg/openapi"
+)
+
+func main() {
+	input := flag.String("input", "./docs/apis/swagger.json", "path to the swagger 2.0 json file")
+	output := flag.String("output", "./docs/apis/openapi.json", "path to write the openapi 3 json file")
+	flag.Parse()
+
+	b, err := os.ReadFile(*input)
+	if err != nil {
+		log.Fatalf("failed to read %q: %v", *input, err)
+	}
+
+	converted, err := openapi.Convert(b)
+	if err != nil {
+		log.Fatalf("failed to convert %q: %v", *input, err)
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, converted, "", "  "); err != nil {
+		log.Fatalf("failed to indent: %v", err)
+	}
+	indented.WriteByte('\n')
+
+	if err := os.WriteFile(*output, indented.Bytes(), 0644); err != nil {
+		log.Fatalf("failed to write %q: %v", *output, err)
+	}
+}
@@ -28,6 +28,8 @@ type component struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	checkInterval time.Duration
+
 	nvmlInstance nvidianvml.Instance
 
 	// returns true if the specified environment variable is set
@@ -40,8 +42,11 @@ type component struct {
 func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
 	c := &component{
-		ctx:          cctx,
-		cancel:       ccancel,
+		ctx:    cctx,
+		cancel: ccancel,
+
+		checkInterval: gpudInstance.CheckInterval(Name),
+
 		nvmlInstance: gpudInstance.NVMLInstance,
 		checkEnvFunc: func(key string) bool {
 			return os.Getenv(key) == "1"
@@ -70,11 +75,11 @@ func (c *component) IsSupported() bool {
 
 func (c *component) Start() error {
 	go func() {
-		ticker := time.NewTicker(time.Minute)
+		ticker := components.NewCheckTicker(c.checkInterval)
 		defer ticker.Stop()
 
 		for {
-			_ = c.Check()
+			_ = components.SafeCheck(c)
 
 			select {
 			case <-c.ctx.Done():
@@ -183,6 +183,10 @@ func (m *mockNVMLInstance) FabricManagerSupported() bool {
 	return true
 }
 
+func (m *mockNVMLInstance) VirtualizationMode() nvidianvml.VirtualizationMode {
+	return nvidianvml.VirtualizationModeNone
+}
+
 func (m *mockNVMLInstance) GetMemoryErrorManagementCapabilities() nvidianvml.MemoryErrorManagementCapabilities {
 	return nvidianvml.MemoryErrorManagementCapabilities{}
 }
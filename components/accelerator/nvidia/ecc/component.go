@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
+	apiv2 "github.com/leptonai/gpud/api/v2"
 	"github.com/leptonai/gpud/components"
 	"github.com/leptonai/gpud/pkg/log"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
@@ -28,9 +30,16 @@ type component struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	checkInterval time.Duration
+
+	// triggerCh receives when the relevant kmsg events are matched,
+	// to re-check without waiting for the next tick
+	triggerCh <-chan struct{}
+
 	nvmlInstance          nvidianvml.Instance
 	getECCModeEnabledFunc func(uuid string, dev device.Device) (nvidianvml.ECCMode, error)
 	getECCErrorsFunc      func(uuid string, dev device.Device, eccModeEnabledCurrent bool) (nvidianvml.ECCErrors, error)
+	getThresholdsFunc     func() Thresholds
 
 	lastMu          sync.RWMutex
 	lastCheckResult *checkResult
@@ -39,11 +48,16 @@ type component struct {
 func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
 	c := &component{
-		ctx:                   cctx,
-		cancel:                ccancel,
+		ctx:    cctx,
+		cancel: ccancel,
+
+		checkInterval: gpudInstance.CheckInterval(Name),
+
+		triggerCh:             gpudInstance.TriggerBus.Subscribe(components.TriggerTopicNVIDIAXid),
 		nvmlInstance:          gpudInstance.NVMLInstance,
 		getECCModeEnabledFunc: nvidianvml.GetECCModeEnabled,
 		getECCErrorsFunc:      nvidianvml.GetECCErrors,
+		getThresholdsFunc:     GetDefaultThresholds,
 	}
 	return c, nil
 }
@@ -68,16 +82,18 @@ func (c *component) IsSupported() bool {
 
 func (c *component) Start() error {
 	go func() {
-		ticker := time.NewTicker(time.Minute)
+		ticker := components.NewCheckTicker(c.checkInterval)
 		defer ticker.Stop()
 
 		for {
-			_ = c.Check()
+			_ = components.SafeCheck(c)
 
 			select {
 			case <-c.ctx.Done():
 				return
 			case <-ticker.C:
+			case <-c.triggerCh:
+				log.Logger.Infow("re-checking on trigger", "component", Name)
 			}
 		}
 	}()
@@ -91,6 +107,15 @@ func (c *component) LastHealthStates() apiv1.HealthStates {
 	return lastCheckResult.HealthStates()
 }
 
+var _ components.TypedPayloader = &component{}
+
+func (c *component) LastHealthStatesWithPayload() (apiv1.HealthStates, apiv2.Payload) {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates(), lastCheckResult.Payload()
+}
+
 func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
 	return nil, nil
 }
@@ -131,6 +156,12 @@ func (c *component) Check() components.CheckResult {
 		return cr
 	}
 
+	var thresholds Thresholds
+	if c.getThresholdsFunc != nil {
+		thresholds = c.getThresholdsFunc()
+	}
+
+	var uncorrectedExceeded, correctedExceeded []string
 	devs := c.nvmlInstance.Devices()
 	for uuid, dev := range devs {
 		eccMode, err := c.getECCModeEnabledFunc(uuid, dev)
@@ -153,14 +184,27 @@ func (c *component) Check() components.CheckResult {
 		}
 		cr.ECCErrors = append(cr.ECCErrors, eccErrors)
 
+		uncorrected, corrected := thresholds.exceeded(eccErrors)
+		uncorrectedExceeded = append(uncorrectedExceeded, uncorrected...)
+		correctedExceeded = append(correctedExceeded, corrected...)
+
 		metricAggregateTotalCorrected.With(prometheus.Labels{"uuid": uuid}).Set(float64(eccErrors.Aggregate.Total.Corrected))
 		metricAggregateTotalUncorrected.With(prometheus.Labels{"uuid": uuid}).Set(float64(eccErrors.Aggregate.Total.Uncorrected))
 		metricVolatileTotalCorrected.With(prometheus.Labels{"uuid": uuid}).Set(float64(eccErrors.Volatile.Total.Corrected))
 		metricVolatileTotalUncorrected.With(prometheus.Labels{"uuid": uuid}).Set(float64(eccErrors.Volatile.Total.Uncorrected))
 	}
 
-	cr.health = apiv1.HealthStateTypeHealthy
-	cr.reason = fmt.Sprintf("all %d GPU(s) were checked, no ECC issue found", len(devs))
+	switch {
+	case len(uncorrectedExceeded) > 0:
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = fmt.Sprintf("exceeded ECC thresholds: %s", strings.Join(append(uncorrectedExceeded, correctedExceeded...), ", "))
+	case len(correctedExceeded) > 0:
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = fmt.Sprintf("exceeded ECC thresholds: %s", strings.Join(correctedExceeded, ", "))
+	default:
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = fmt.Sprintf("all %d GPU(s) were checked, no ECC issue found", len(devs))
+	}
 
 	return cr
 }
@@ -246,6 +290,36 @@ func (cr *checkResult) getError() string {
 	return cr.err.Error()
 }
 
+// Payload returns the typed payload of the check result,
+// or nil if no ECC data is available.
+func (cr *checkResult) Payload() apiv2.Payload {
+	if cr == nil || len(cr.ECCModes) == 0 {
+		return nil
+	}
+
+	errs := make(map[string]nvidianvml.ECCErrors, len(cr.ECCErrors))
+	for _, e := range cr.ECCErrors {
+		errs[e.UUID] = e
+	}
+
+	p := &apiv2.GPUECCPayload{GPUs: make([]apiv2.GPUECC, 0, len(cr.ECCModes))}
+	for _, m := range cr.ECCModes {
+		gpu := apiv2.GPUECC{
+			UUID:           m.UUID,
+			ModeSupported:  m.Supported,
+			EnabledCurrent: m.EnabledCurrent,
+			EnabledPending: m.EnabledPending,
+		}
+		if e, ok := errs[m.UUID]; ok {
+			gpu.ErrorsSupported = e.Supported
+			gpu.Aggregate = apiv2.ECCErrorCounts{Corrected: e.Aggregate.Total.Corrected, Uncorrected: e.Aggregate.Total.Uncorrected}
+			gpu.Volatile = apiv2.ECCErrorCounts{Corrected: e.Volatile.Total.Corrected, Uncorrected: e.Volatile.Total.Uncorrected}
+		}
+		p.GPUs = append(p.GPUs, gpu)
+	}
+	return p
+}
+
 func (cr *checkResult) HealthStates() apiv1.HealthStates {
 	if cr == nil {
 		return apiv1.HealthStates{
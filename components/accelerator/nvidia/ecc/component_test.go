@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
+	apiv2 "github.com/leptonai/gpud/api/v2"
 	"github.com/leptonai/gpud/components"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
 	"github.com/leptonai/gpud/pkg/nvidia-query/nvml/lib"
@@ -36,6 +37,10 @@ func (m *mockNVMLInstance) FabricManagerSupported() bool {
 	return true
 }
 
+func (m *mockNVMLInstance) VirtualizationMode() nvidianvml.VirtualizationMode {
+	return nvidianvml.VirtualizationModeNone
+}
+
 func (m *mockNVMLInstance) GetMemoryErrorManagementCapabilities() nvidianvml.MemoryErrorManagementCapabilities {
 	return nvidianvml.MemoryErrorManagementCapabilities{
 		ErrorContainment:     true,
@@ -226,6 +231,18 @@ func TestCheck_Success(t *testing.T) {
 	assert.Len(t, data.ECCErrors, 1)
 	assert.Equal(t, eccMode, data.ECCModes[0])
 	assert.Equal(t, eccErrors, data.ECCErrors[0])
+
+	component.getThresholdsFunc = func() Thresholds { return Thresholds{VolatileCorrectedMax: 2} }
+	data = component.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, data.health)
+	assert.Equal(t, "exceeded ECC thresholds: gpu-uuid-123 volatile corrected errors 3 exceeding 2", data.reason)
+
+	component.getThresholdsFunc = func() Thresholds {
+		return Thresholds{VolatileCorrectedMax: 2, AggregateUncorrectedMax: 1}
+	}
+	data = component.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, data.health)
+	assert.Equal(t, "exceeded ECC thresholds: gpu-uuid-123 aggregate uncorrected errors 2 exceeding 1, gpu-uuid-123 volatile corrected errors 3 exceeding 2", data.reason)
 }
 
 func TestCheck_ECCModeError(t *testing.T) {
@@ -643,6 +660,46 @@ func TestData_HealthState(t *testing.T) {
 	}
 }
 
+func TestData_Payload(t *testing.T) {
+	var cr *checkResult
+	assert.Nil(t, cr.Payload())
+	assert.Nil(t, (&checkResult{}).Payload())
+
+	cr = &checkResult{
+		ECCModes: []nvidianvml.ECCMode{
+			{UUID: "gpu-0", EnabledCurrent: true, EnabledPending: true, Supported: true},
+			{UUID: "gpu-1", Supported: false},
+		},
+		ECCErrors: []nvidianvml.ECCErrors{
+			{
+				UUID:      "gpu-0",
+				Aggregate: nvidianvml.AllECCErrorCounts{Total: nvidianvml.ECCErrorCounts{Corrected: 5, Uncorrected: 1}},
+				Volatile:  nvidianvml.AllECCErrorCounts{Total: nvidianvml.ECCErrorCounts{Corrected: 2}},
+				Supported: true,
+			},
+		},
+	}
+	p, ok := cr.Payload().(*apiv2.GPUECCPayload)
+	require.True(t, ok)
+	assert.Equal(t, []apiv2.GPUECC{
+		{
+			UUID:            "gpu-0",
+			ModeSupported:   true,
+			EnabledCurrent:  true,
+			EnabledPending:  true,
+			ErrorsSupported: true,
+			Aggregate:       apiv2.ECCErrorCounts{Corrected: 5, Uncorrected: 1},
+			Volatile:        apiv2.ECCErrorCounts{Corrected: 2},
+		},
+		{UUID: "gpu-1"},
+	}, p.GPUs)
+
+	c := &component{lastCheckResult: cr}
+	states, payload := c.LastHealthStatesWithPayload()
+	assert.Equal(t, cr.HealthStates(), states)
+	assert.Equal(t, p, payload)
+}
+
 func TestCheck_NilNvmlInstance(t *testing.T) {
 	ctx := context.Background()
 
@@ -0,0 +1,61 @@
+package ecc
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/leptonai/gpud/pkg/log"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+)
+
+// Thresholds are the limits of the ECC error counts per GPU.
+// Zero disables the limit.
+type Thresholds struct {
+	// VolatileCorrectedMax is the volatile (since the driver load) corrected error count,
+	// above which the GPU is degraded.
+	VolatileCorrectedMax uint64 `json:"volatile_corrected_max,omitempty"`
+	// VolatileUncorrectedMax is the volatile uncorrected error count,
+	// above which the GPU is unhealthy.
+	VolatileUncorrectedMax uint64 `json:"volatile_uncorrected_max,omitempty"`
+	// AggregateUncorrectedMax is the aggregate (lifetime) uncorrected error count,
+	// above which the GPU is unhealthy.
+	AggregateUncorrectedMax uint64 `json:"aggregate_uncorrected_max,omitempty"`
+}
+
+// exceeded returns the descriptions of the uncorrected and the corrected error counts
+// exceeding the thresholds.
+func (t Thresholds) exceeded(errs nvidianvml.ECCErrors) (uncorrected []string, corrected []string) {
+	if t.VolatileUncorrectedMax > 0 && errs.Volatile.Total.Uncorrected > t.VolatileUncorrectedMax {
+		uncorrected = append(uncorrected, fmt.Sprintf("%s volatile uncorrected errors %d exceeding %d", errs.UUID, errs.Volatile.Total.Uncorrected, t.VolatileUncorrectedMax))
+	}
+	if t.AggregateUncorrectedMax > 0 && errs.Aggregate.Total.Uncorrected > t.AggregateUncorrectedMax {
+		uncorrected = append(uncorrected, fmt.Sprintf("%s aggregate uncorrected errors %d exceeding %d", errs.UUID, errs.Aggregate.Total.Uncorrected, t.AggregateUncorrectedMax))
+	}
+	if t.VolatileCorrectedMax > 0 && errs.Volatile.Total.Corrected > t.VolatileCorrectedMax {
+		corrected = append(corrected, fmt.Sprintf("%s volatile corrected errors %d exceeding %d", errs.UUID, errs.Volatile.Total.Corrected, t.VolatileCorrectedMax))
+	}
+	return uncorrected, corrected
+}
+
+var (
+	defaultThresholdsMu sync.RWMutex
+	defaultThresholds   Thresholds
+)
+
+func GetDefaultThresholds() Thresholds {
+	defaultThresholdsMu.RLock()
+	defer defaultThresholdsMu.RUnlock()
+	return defaultThresholds
+}
+
+func SetDefaultThresholds(thresholds Thresholds) {
+	log.Logger.Infow("setting default ecc thresholds",
+		"volatile_corrected_max", thresholds.VolatileCorrectedMax,
+		"volatile_uncorrected_max", thresholds.VolatileUncorrectedMax,
+		"aggregate_uncorrected_max", thresholds.AggregateUncorrectedMax,
+	)
+
+	defaultThresholdsMu.Lock()
+	defer defaultThresholdsMu.Unlock()
+	defaultThresholds = thresholds
+}
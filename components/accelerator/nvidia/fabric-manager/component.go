@@ -5,6 +5,7 @@ package fabricmanager
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os/exec"
 	"sync"
 	"time"
@@ -18,6 +19,7 @@ import (
 	netutil "github.com/leptonai/gpud/pkg/netutil"
 	nvidiaquery "github.com/leptonai/gpud/pkg/nvidia-query"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	"github.com/leptonai/gpud/pkg/toolexec"
 )
 
 const Name = "accelerator-nvidia-fabric-manager"
@@ -28,6 +30,8 @@ type component struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	checkInterval time.Duration
+
 	nvmlInstance nvidianvml.Instance
 
 	checkNVSwitchExistsFunc func() bool
@@ -43,11 +47,13 @@ type component struct {
 }
 
 func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
-	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+	cctx, ccancel := context.WithCancel(toolexec.WithComponent(gpudInstance.RootCtx, Name))
 	c := &component{
 		ctx:    cctx,
 		cancel: ccancel,
 
+		checkInterval: gpudInstance.CheckInterval(Name),
+
 		nvmlInstance: gpudInstance.NVMLInstance,
 
 		checkNVSwitchExistsFunc: func() bool {
@@ -75,7 +81,8 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 
 	if gpudInstance.EventStore != nil {
 		var err error
-		c.eventBucket, err = gpudInstance.EventStore.Bucket(Name)
+		// buffers the inserts, to write the fabric manager log storms in batches
+		c.eventBucket, err = gpudInstance.EventStore.Bucket(Name, eventstore.WithBuffer(eventstore.DefaultBatchSize, eventstore.DefaultFlushInterval))
 		if err != nil {
 			ccancel()
 			return nil, err
@@ -112,13 +119,22 @@ func (c *component) IsSupported() bool {
 	return c.nvmlInstance.NVMLExists() && c.nvmlInstance.ProductName() != ""
 }
 
+// RequiresTools returns false on the GPUs without the fabric manager support
+// or without the NVSwitches, where the check never runs the fabric manager.
+func (c *component) RequiresTools() bool {
+	if !c.IsSupported() || !c.nvmlInstance.FabricManagerSupported() {
+		return false
+	}
+	return c.checkNVSwitchExistsFunc == nil || c.checkNVSwitchExistsFunc()
+}
+
 func (c *component) Start() error {
 	go func() {
-		ticker := time.NewTicker(time.Minute)
+		ticker := components.NewCheckTicker(c.checkInterval)
 		defer ticker.Stop()
 
 		for {
-			_ = c.Check()
+			_ = components.SafeCheck(c)
 
 			select {
 			case <-c.ctx.Done():
@@ -187,6 +203,12 @@ func (c *component) Check() components.CheckResult {
 		return cr
 	}
 
+	if mode := c.nvmlInstance.VirtualizationMode(); mode.HostManaged() {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = fmt.Sprintf("fabric manager is managed by the host (virtualization mode %q), skipping fabric manager check", mode)
+		return cr
+	}
+
 	if !c.nvmlInstance.FabricManagerSupported() {
 		cr.FabricManagerActive = false
 		cr.health = apiv1.HealthStateTypeHealthy
@@ -213,6 +235,7 @@ func (c *component) Check() components.CheckResult {
 
 		cr.health = apiv1.HealthStateTypeUnhealthy
 		cr.reason = "nv-fabricmanager found but fabric manager service is not active"
+		cr.failureCodes = []apiv1.FailureCode{apiv1.FailureCodeFabricManagerInactive}
 
 		return cr
 	}
@@ -259,6 +282,8 @@ type checkResult struct {
 	health apiv1.HealthStateType
 	// tracks the reason of the last check
 	reason string
+	// tracks the failure codes of the last check, if unhealthy
+	failureCodes []apiv1.FailureCode
 }
 
 func (cr *checkResult) ComponentName() string {
@@ -312,12 +337,13 @@ func (cr *checkResult) HealthStates() apiv1.HealthStates {
 	}
 
 	state := apiv1.HealthState{
-		Time:      metav1.NewTime(cr.ts),
-		Component: Name,
-		Name:      Name,
-		Reason:    cr.reason,
-		Error:     cr.getError(),
-		Health:    cr.health,
+		Time:         metav1.NewTime(cr.ts),
+		Component:    Name,
+		Name:         Name,
+		Reason:       cr.reason,
+		Error:        cr.getError(),
+		Health:       cr.health,
+		FailureCodes: cr.failureCodes,
 	}
 
 	b, _ := json.Marshal(cr)
@@ -337,6 +337,7 @@ func TestStatesWhenFabricManagerExistsButNotActive(t *testing.T) {
 	assert.Equal(t, Name, states[0].Name)
 	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, states[0].Health)
 	assert.Equal(t, "nv-fabricmanager found but fabric manager service is not active", states[0].Reason)
+	assert.Equal(t, []apiv1.FailureCode{apiv1.FailureCodeFabricManagerInactive}, states[0].FailureCodes)
 }
 
 func TestDataGetError(t *testing.T) {
@@ -559,6 +560,7 @@ type mockNVMLInstance struct {
 	supportsFM  bool
 	productName string
 	deviceCount int // Add device count field
+	virtMode    nvidianvml.VirtualizationMode
 }
 
 func (m *mockNVMLInstance) NVMLExists() bool {
@@ -610,6 +612,13 @@ func (m *mockNVMLInstance) FabricManagerSupported() bool {
 	return m.supportsFM
 }
 
+func (m *mockNVMLInstance) VirtualizationMode() nvidianvml.VirtualizationMode {
+	if m.virtMode == "" {
+		return nvidianvml.VirtualizationModeNone
+	}
+	return m.virtMode
+}
+
 func (m *mockNVMLInstance) GetMemoryErrorManagementCapabilities() nvidianvml.MemoryErrorManagementCapabilities {
 	return nvidianvml.MemoryErrorManagementCapabilities{}
 }
@@ -774,6 +783,38 @@ func TestCheck_FabricManagerNotSupported(t *testing.T) {
 	assert.Equal(t, "Test GPU does not support fabric manager", states[0].Reason)
 }
 
+func TestCheck_HostManagedVirtualization(t *testing.T) {
+	t.Parallel()
+
+	mockInstance := &mockNVMLInstance{
+		exists:      true,
+		supportsFM:  true,
+		productName: "Test GPU",
+		deviceCount: 2,
+		virtMode:    nvidianvml.VirtualizationModeVGPU,
+	}
+
+	comp := &component{
+		ctx:               context.Background(),
+		cancel:            func() {},
+		nvmlInstance:      mockInstance,
+		checkFMExistsFunc: func() bool { return false },
+		checkFMActiveFunc: func() bool { return false },
+	}
+
+	cr, ok := comp.Check().(*checkResult)
+	assert.True(t, ok, "Expected result to be of type *checkResult")
+	assert.False(t, cr.FabricManagerActive)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.health)
+	assert.Contains(t, cr.reason, "managed by the host")
+
+	// passthrough GPUs are owned by the guest, so the fabric manager is still checked
+	mockInstance.virtMode = nvidianvml.VirtualizationModePassthrough
+	cr, ok = comp.Check().(*checkResult)
+	assert.True(t, ok, "Expected result to be of type *checkResult")
+	assert.Equal(t, "nv-fabricmanager executable not found", cr.reason)
+}
+
 func TestCheckWithEmptyProductName(t *testing.T) {
 	// Create mock NVML instance with empty product name
 	mockNVML := &mockNVMLInstance{
@@ -1075,3 +1116,25 @@ func TestCheckNVSwitchFuncNil(t *testing.T) {
 	assert.Equal(t, "fabric manager found and active", checkResult.reason)
 	assert.True(t, checkResult.FabricManagerActive)
 }
+
+func TestRequiresTools(t *testing.T) {
+	t.Parallel()
+
+	comp := &component{
+		nvmlInstance: &mockNVMLInstance{exists: true, supportsFM: false, productName: "NVIDIA A10", deviceCount: 1},
+	}
+	assert.False(t, comp.RequiresTools())
+
+	nvSwitch := false
+	comp = &component{
+		nvmlInstance:            &mockNVMLInstance{exists: true, supportsFM: true, productName: "NVIDIA H100 80GB HBM3", deviceCount: 8},
+		checkNVSwitchExistsFunc: func() bool { return nvSwitch },
+	}
+	assert.False(t, comp.RequiresTools())
+
+	nvSwitch = true
+	assert.True(t, comp.RequiresTools())
+
+	comp = &component{nvmlInstance: nil}
+	assert.False(t, comp.RequiresTools())
+}
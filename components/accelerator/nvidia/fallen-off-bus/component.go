@@ -0,0 +1,695 @@
+// Package fallenoffbus tracks the NVIDIA GPUs missing from the PCI enumeration
+// (e.g., "Xid 79: GPU has fallen off the bus") compared to the GPUs found at startup,
+// with the optional PCI rescan remediation.
+package fallenoffbus
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	"github.com/leptonai/gpud/pkg/pci"
+	"github.com/leptonai/gpud/pkg/toolexec"
+)
+
+const Name = "accelerator-nvidia-fallen-off-bus"
+
+const (
+	// EventNameGPUMissingFromPCIBus is the GPU missing from the PCI enumeration,
+	// distinct from the "gpu_fallen_off_bus" event of the Xid 79 in the pci component.
+	EventNameGPUMissingFromPCIBus  = "gpu_missing_from_pci_bus"
+	EventNameGPURecoveredByRescan  = "gpu_recovered_by_pci_rescan"
+	EventNameGPUNotRecoveredRescan = "gpu_not_recovered_by_pci_rescan"
+	EventKeyBusID                  = "bus_id"
+	EventKeyDeviceUUID             = "device_uuid"
+	defaultRescanWait              = 5 * time.Second
+
+	// defaultRescanBackoff is the wait before the second pci rescan
+	// of the same fallen GPU, doubled on every rescan after.
+	defaultRescanBackoff = 10 * time.Minute
+	// defaultMaxRescans is the most pci rescans of the same fallen GPU,
+	// before giving up to the reboot.
+	defaultMaxRescans = 3
+)
+
+var _ components.Component = &component{}
+
+type component struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	checkInterval time.Duration
+
+	nvmlInstance nvidianvml.Instance
+	eventBucket  eventstore.Bucket
+	dbRW         *sql.DB
+	dbRO         *sql.DB
+
+	sysfsRoot           string
+	getRescanEnabled    func() bool
+	getThresholdsFunc   func() Thresholds
+	getDeviceStatusFunc func(sysfsRoot string, busID string) (pci.DeviceStatus, error)
+	removeDeviceFunc    func(sysfsRoot string, busID string) error
+	rescanFunc          func(sysfsRoot string) error
+	rescanWait          time.Duration
+
+	stateMu sync.Mutex
+	// baseline is the PCI bus ID of each GPU (keyed by the UUID),
+	// as enumerated by the NVML and persisted, so that the GPU
+	// already fallen off the bus at the restart is still tracked
+	baseline map[string]string
+	// lost is the GPUs missing from the pci enumeration (keyed by the UUID),
+	// persisted with the baseline
+	lost map[string]lostGPU
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	cctx, ccancel := context.WithCancel(toolexec.WithComponent(gpudInstance.RootCtx, Name))
+	c := &component{
+		ctx:    cctx,
+		cancel: ccancel,
+
+		checkInterval: gpudInstance.CheckInterval(Name),
+
+		nvmlInstance:        gpudInstance.NVMLInstance,
+		dbRW:                gpudInstance.DBRW,
+		dbRO:                gpudInstance.DBRO,
+		sysfsRoot:           getDefaultSysfsRoot(),
+		getRescanEnabled:    GetDefaultRescanEnabled,
+		getThresholdsFunc:   GetDefaultThresholds,
+		getDeviceStatusFunc: pci.GetDeviceStatus,
+		removeDeviceFunc:    pci.RemoveDevice,
+		rescanFunc:          pci.Rescan,
+		rescanWait:          defaultRescanWait,
+	}
+
+	if gpudInstance.EventStore != nil {
+		var err error
+		c.eventBucket, err = gpudInstance.EventStore.Bucket(Name)
+		if err != nil {
+			ccancel()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"accelerator",
+		"gpu",
+		"nvidia",
+		Name,
+	}
+}
+
+func (c *component) IsSupported() bool {
+	if c.nvmlInstance == nil {
+		return false
+	}
+	return c.nvmlInstance.NVMLExists() && c.nvmlInstance.ProductName() != ""
+}
+
+func (c *component) Start() error {
+	go func() {
+		ticker := components.NewCheckTicker(c.checkInterval)
+		defer ticker.Stop()
+
+		for {
+			_ = components.SafeCheck(c)
+
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	if c.eventBucket == nil {
+		return nil, nil
+	}
+	evs, err := c.eventBucket.Get(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	return evs.Events(), nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	if c.eventBucket != nil {
+		c.eventBucket.Close()
+	}
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking nvidia gpu pci enumeration")
+
+	cr := &checkResult{
+		ts: time.Now().UTC(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	if c.nvmlInstance == nil {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML instance is nil"
+		return cr
+	}
+	if !c.nvmlInstance.NVMLExists() {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML library is not loaded"
+		return cr
+	}
+	if c.nvmlInstance.ProductName() == "" {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML is loaded but GPU is not detected (missing product name)"
+		return cr
+	}
+
+	baseline := c.loadBaseline()
+	if c.getThresholdsFunc != nil {
+		if expected := c.getThresholdsFunc().ExpectedGPUs; expected > len(baseline) {
+			cr.health = apiv1.HealthStateTypeUnhealthy
+			cr.reason = fmt.Sprintf("found %d GPU(s) on the pci bus, expected %d", len(baseline), expected)
+			cr.failureCodes = []apiv1.FailureCode{apiv1.FailureCodeGPUFallenOffBus}
+			cr.suggestedActions = &apiv1.SuggestedActions{
+				RepairActions: []apiv1.RepairActionType{apiv1.RepairActionTypeRebootSystem},
+			}
+			return cr
+		}
+	}
+	if len(baseline) == 0 {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "no GPU pci bus id found (skipped)"
+		return cr
+	}
+
+	cr.GPUs, cr.err = c.getStatuses(baseline)
+	if cr.err != nil {
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = "error reading pci device status"
+		log.Logger.Errorw(cr.reason, "error", cr.err)
+		return cr
+	}
+
+	lost := cr.lostGPUs()
+	lostSince := c.trackLost(cr.ts, cr.GPUs)
+	if len(lost) == 0 {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = fmt.Sprintf("all %d GPU(s) are present on the pci bus", len(cr.GPUs))
+		return cr
+	}
+
+	// the event time is the time the GPU was first found missing,
+	// so that the same event is found and not inserted again
+	for _, gpu := range lost {
+		c.insertEvent(lostSince[gpu.UUID], EventNameGPUMissingFromPCIBus, apiv1.EventTypeCritical, gpu,
+			fmt.Sprintf("GPU %s (%s) is %s in the pci enumeration (fallen off the bus)", gpu.UUID, gpu.BusID, gpu.Status))
+	}
+
+	rescanned := false
+	if c.getRescanEnabled != nil && c.getRescanEnabled() && c.rescanDue(cr.ts, lost) {
+		c.rescan(cr, lost)
+		lost = cr.lostGPUs()
+		rescanned = true
+	}
+
+	if len(lost) == 0 {
+		// recovered devices still need the driver to re-initialize them
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = fmt.Sprintf("%d GPU(s) fell off the pci bus but recovered after pci rescan", len(cr.Rescanned))
+		return cr
+	}
+
+	uuids := make([]string, 0, len(lost))
+	for _, gpu := range lost {
+		uuids = append(uuids, gpu.UUID)
+	}
+	cr.health = apiv1.HealthStateTypeUnhealthy
+	cr.reason = fmt.Sprintf("%d GPU(s) fell off the pci bus: %s", len(lost), strings.Join(uuids, ", "))
+	cr.failureCodes = []apiv1.FailureCode{apiv1.FailureCodeGPUFallenOffBus}
+	if rescanned || c.rescanned(lost) {
+		cr.reason += " (not recovered by pci rescan)"
+	}
+	cr.suggestedActions = &apiv1.SuggestedActions{
+		RepairActions: []apiv1.RepairActionType{apiv1.RepairActionTypeRebootSystem},
+	}
+	return cr
+}
+
+// lostGPU is the GPU missing from the pci enumeration.
+type lostGPU struct {
+	// Since is the time the GPU was first found missing.
+	Since time.Time `json:"since"`
+	// Rescans is the number of the pci rescans attempted for the GPU.
+	Rescans int `json:"rescans,omitempty"`
+	// LastRescan is the time of the last pci rescan for the GPU.
+	LastRescan time.Time `json:"last_rescan,omitempty"`
+}
+
+// persistedState is the baseline and the lost GPUs persisted in the metadata table.
+type persistedState struct {
+	Baseline map[string]string  `json:"baseline"`
+	Lost     map[string]lostGPU `json:"lost,omitempty"`
+}
+
+// loadBaseline returns the PCI bus IDs of the GPUs found on the node,
+// merging the persisted baseline with the GPUs enumerated by the NVML.
+// The persisted GPU is replaced by the enumerated GPU on the same PCI bus ID
+// (e.g., the GPU replaced during the maintenance).
+func (c *component) loadBaseline() map[string]string {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	if c.baseline != nil {
+		return c.baseline
+	}
+
+	st := c.readStateLocked()
+	baseline := st.Baseline
+	if baseline == nil {
+		baseline = make(map[string]string)
+	}
+	c.lost = st.Lost
+	if c.lost == nil {
+		c.lost = make(map[string]lostGPU)
+	}
+
+	byBusID := make(map[string]string, len(baseline))
+	for uuid, busID := range baseline {
+		byBusID[busID] = uuid
+	}
+	for uuid, dev := range c.nvmlInstance.Devices() {
+		busID, err := dev.GetPCIBusID()
+		if err != nil {
+			log.Logger.Warnw("failed to get pci bus id", "uuid", uuid, "error", err)
+			continue
+		}
+		busID = pci.NormalizeBusID(busID)
+		if prev, ok := byBusID[busID]; ok && prev != uuid {
+			log.Logger.Infow("replacing the gpu in the pci baseline", "busID", busID, "previous", prev, "uuid", uuid)
+			delete(baseline, prev)
+			delete(c.lost, prev)
+		}
+		baseline[uuid] = busID
+		byBusID[busID] = uuid
+	}
+	if len(baseline) > 0 {
+		c.baseline = baseline
+		c.persistStateLocked()
+	}
+	return baseline
+}
+
+func (c *component) readStateLocked() persistedState {
+	var st persistedState
+	if c.dbRO == nil {
+		return st
+	}
+
+	cctx, ccancel := context.WithTimeout(c.ctx, 15*time.Second)
+	v, err := pkgmetadata.ReadMetadata(cctx, c.dbRO, pkgmetadata.MetadataKeyGPUPCIBaseline)
+	ccancel()
+	if err != nil {
+		log.Logger.Warnw("failed to read the persisted pci baseline", "error", err)
+		return st
+	}
+	if v == "" {
+		return st
+	}
+	if err := json.Unmarshal([]byte(v), &st); err != nil {
+		log.Logger.Warnw("failed to parse the persisted pci baseline", "error", err)
+		return persistedState{}
+	}
+	return st
+}
+
+func (c *component) persistStateLocked() {
+	if c.dbRW == nil {
+		return
+	}
+
+	b, err := json.Marshal(persistedState{Baseline: c.baseline, Lost: c.lost})
+	if err != nil {
+		log.Logger.Warnw("failed to marshal the pci baseline", "error", err)
+		return
+	}
+	cctx, ccancel := context.WithTimeout(c.ctx, 15*time.Second)
+	err = pkgmetadata.SetMetadata(cctx, c.dbRW, pkgmetadata.MetadataKeyGPUPCIBaseline, string(b))
+	ccancel()
+	if err != nil {
+		log.Logger.Warnw("failed to persist the pci baseline", "error", err)
+	}
+}
+
+// trackLost records the GPUs newly missing from the pci enumeration,
+// forgets the GPUs present again, and returns the time each GPU was
+// first found missing.
+func (c *component) trackLost(now time.Time, gpus []GPUStatus) map[string]time.Time {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	changed := false
+	since := make(map[string]time.Time)
+	for _, gpu := range gpus {
+		l, ok := c.lost[gpu.UUID]
+		if gpu.Status == pci.DeviceStatusPresent {
+			if ok {
+				delete(c.lost, gpu.UUID)
+				changed = true
+			}
+			continue
+		}
+		if !ok {
+			l = lostGPU{Since: now}
+			c.lost[gpu.UUID] = l
+			changed = true
+		}
+		since[gpu.UUID] = l.Since
+	}
+	if changed {
+		c.persistStateLocked()
+	}
+	return since
+}
+
+// rescanDue returns true if any of the lost GPUs is due for the pci rescan,
+// backing off exponentially from the last rescan, up to the max rescans.
+func (c *component) rescanDue(now time.Time, lost []GPUStatus) bool {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	for _, gpu := range lost {
+		l := c.lost[gpu.UUID]
+		if l.Rescans == 0 {
+			return true
+		}
+		if l.Rescans >= defaultMaxRescans {
+			continue
+		}
+		backoff := defaultRescanBackoff << (l.Rescans - 1)
+		if now.Sub(l.LastRescan) >= backoff {
+			return true
+		}
+	}
+	return false
+}
+
+// rescanned returns true if any of the lost GPUs has been rescanned.
+func (c *component) rescanned(lost []GPUStatus) bool {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	for _, gpu := range lost {
+		if c.lost[gpu.UUID].Rescans > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// recordRescan records the pci rescan of the GPUs still missing after it,
+// and forgets the recovered GPUs.
+func (c *component) recordRescan(now time.Time, gpus []GPUStatus) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	for _, gpu := range gpus {
+		l, ok := c.lost[gpu.UUID]
+		if !ok {
+			continue
+		}
+		if gpu.Status == pci.DeviceStatusPresent {
+			delete(c.lost, gpu.UUID)
+			continue
+		}
+		l.Rescans++
+		l.LastRescan = now
+		c.lost[gpu.UUID] = l
+	}
+	c.persistStateLocked()
+}
+
+func (c *component) getStatuses(baseline map[string]string) ([]GPUStatus, error) {
+	gpus := make([]GPUStatus, 0, len(baseline))
+	for uuid, busID := range baseline {
+		st, err := c.getDeviceStatusFunc(c.sysfsRoot, busID)
+		if err != nil {
+			return nil, err
+		}
+		gpus = append(gpus, GPUStatus{UUID: uuid, BusID: busID, Status: st})
+	}
+	sort.Slice(gpus, func(i, j int) bool {
+		return gpus[i].BusID < gpus[j].BusID
+	})
+	return gpus, nil
+}
+
+// rescan removes the unresponsive GPUs and rescans the pci bus,
+// then updates the statuses of the lost GPUs.
+func (c *component) rescan(cr *checkResult, lost []GPUStatus) {
+	// the failed rescan still counts, to back off from it
+	defer c.recordRescan(cr.ts, cr.GPUs)
+
+	for _, gpu := range lost {
+		if gpu.Status != pci.DeviceStatusUnresponsive {
+			continue
+		}
+		if err := c.removeDeviceFunc(c.sysfsRoot, gpu.BusID); err != nil {
+			log.Logger.Warnw("failed to remove pci device", "uuid", gpu.UUID, "busID", gpu.BusID, "error", err)
+		}
+	}
+	if err := c.rescanFunc(c.sysfsRoot); err != nil {
+		log.Logger.Warnw("failed to rescan pci bus", "error", err)
+		return
+	}
+
+	select {
+	case <-c.ctx.Done():
+		return
+	case <-time.After(c.rescanWait):
+	}
+
+	for i := range cr.GPUs {
+		gpu := &cr.GPUs[i]
+		if gpu.Status == pci.DeviceStatusPresent {
+			continue
+		}
+
+		st, err := c.getDeviceStatusFunc(c.sysfsRoot, gpu.BusID)
+		if err != nil {
+			log.Logger.Warnw("failed to read pci device status after rescan", "uuid", gpu.UUID, "error", err)
+			continue
+		}
+		gpu.Status = st
+		cr.Rescanned = append(cr.Rescanned, gpu.UUID)
+
+		if st == pci.DeviceStatusPresent {
+			log.Logger.Infow("gpu recovered after pci rescan", "uuid", gpu.UUID, "busID", gpu.BusID)
+			c.insertEvent(cr.ts, EventNameGPURecoveredByRescan, apiv1.EventTypeWarning, *gpu,
+				fmt.Sprintf("GPU %s (%s) recovered after pci rescan", gpu.UUID, gpu.BusID))
+		} else {
+			log.Logger.Warnw("gpu not recovered after pci rescan", "uuid", gpu.UUID, "busID", gpu.BusID, "status", st)
+			c.insertEvent(cr.ts, EventNameGPUNotRecoveredRescan, apiv1.EventTypeCritical, *gpu,
+				fmt.Sprintf("GPU %s (%s) is still %s after pci rescan (reboot required)", gpu.UUID, gpu.BusID, st))
+		}
+	}
+}
+
+func (c *component) insertEvent(ts time.Time, name string, eventType apiv1.EventType, gpu GPUStatus, msg string) {
+	if c.eventBucket == nil {
+		return
+	}
+
+	ev := eventstore.Event{
+		Time:    ts,
+		Name:    name,
+		Type:    string(eventType),
+		Message: msg,
+		ExtraInfo: map[string]string{
+			EventKeyDeviceUUID: gpu.UUID,
+			EventKeyBusID:      gpu.BusID,
+		},
+	}
+
+	cctx, ccancel := context.WithTimeout(c.ctx, 15*time.Second)
+	found, err := c.eventBucket.Find(cctx, ev)
+	ccancel()
+	if err != nil {
+		log.Logger.Errorw("error finding event", "error", err)
+		return
+	}
+	if found != nil {
+		return
+	}
+
+	cctx, ccancel = context.WithTimeout(c.ctx, 15*time.Second)
+	err = c.eventBucket.Insert(cctx, ev)
+	ccancel()
+	if err != nil {
+		log.Logger.Errorw("error inserting event", "error", err)
+	}
+}
+
+// GPUStatus is the pci enumeration status of the GPU.
+type GPUStatus struct {
+	UUID   string           `json:"uuid"`
+	BusID  string           `json:"bus_id"`
+	Status pci.DeviceStatus `json:"status"`
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	GPUs []GPUStatus `json:"gpus,omitempty"`
+	// Rescanned are the UUIDs of the GPUs re-checked after the pci rescan.
+	Rescanned []string `json:"rescanned,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the suggested actions for the last check
+	suggestedActions *apiv1.SuggestedActions
+	// tracks the reason of the last check
+	reason string
+	// tracks the failure codes of the last check, if unhealthy
+	failureCodes []apiv1.FailureCode
+}
+
+func (cr *checkResult) lostGPUs() []GPUStatus {
+	var lost []GPUStatus
+	for _, gpu := range cr.GPUs {
+		if gpu.Status != pci.DeviceStatusPresent {
+			lost = append(lost, gpu)
+		}
+	}
+	return lost
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if len(cr.GPUs) == 0 {
+		return "no data"
+	}
+
+	buf := bytes.NewBuffer(nil)
+	table := tablewriter.NewWriter(buf)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.SetHeader([]string{"UUID", "PCI Bus ID", "Status"})
+	for _, gpu := range cr.GPUs {
+		table.Append([]string{gpu.UUID, gpu.BusID, string(gpu.Status)})
+	}
+	table.Render()
+
+	return buf.String()
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getSuggestedActions() *apiv1.SuggestedActions {
+	if cr == nil {
+		return nil
+	}
+	return cr.suggestedActions
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:             metav1.NewTime(cr.ts),
+		Component:        Name,
+		Name:             Name,
+		Reason:           cr.reason,
+		SuggestedActions: cr.getSuggestedActions(),
+		FailureCodes:     cr.failureCodes,
+		Error:            cr.getError(),
+		Health:           cr.health,
+	}
+
+	if len(cr.GPUs) > 0 {
+		b, _ := json.Marshal(cr)
+		state.ExtraInfo = map[string]string{"data": string(b)}
+	}
+	return apiv1.HealthStates{state}
+}
@@ -0,0 +1,339 @@
+package fallenoffbus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/go-nvlib/pkg/nvlib/device"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	"github.com/leptonai/gpud/pkg/nvidia-query/nvml/lib"
+	"github.com/leptonai/gpud/pkg/nvidia-query/nvml/testutil"
+	"github.com/leptonai/gpud/pkg/pci"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+// mockNVMLInstance implements the nvml.InstanceV2 interface for testing
+type mockNVMLInstance struct {
+	devs map[string]device.Device
+}
+
+func (m *mockNVMLInstance) Devices() map[string]device.Device { return m.devs }
+func (m *mockNVMLInstance) GetMemoryErrorManagementCapabilities() nvidianvml.MemoryErrorManagementCapabilities {
+	return nvidianvml.MemoryErrorManagementCapabilities{}
+}
+func (m *mockNVMLInstance) ProductName() string          { return "NVIDIA Test GPU" }
+func (m *mockNVMLInstance) Architecture() string         { return "" }
+func (m *mockNVMLInstance) Brand() string                { return "" }
+func (m *mockNVMLInstance) DriverVersion() string        { return "" }
+func (m *mockNVMLInstance) DriverMajor() int             { return 0 }
+func (m *mockNVMLInstance) CUDAVersion() string          { return "" }
+func (m *mockNVMLInstance) FabricManagerSupported() bool { return true }
+func (m *mockNVMLInstance) NVMLExists() bool             { return true }
+func (m *mockNVMLInstance) Library() lib.Library         { return nil }
+func (m *mockNVMLInstance) Shutdown() error              { return nil }
+func (m *mockNVMLInstance) VirtualizationMode() nvidianvml.VirtualizationMode {
+	return nvidianvml.VirtualizationModeNone
+}
+
+func newTestNVMLInstance() *mockNVMLInstance {
+	return &mockNVMLInstance{
+		devs: map[string]device.Device{
+			"GPU-0": testutil.NewMockDevice(&mock.Device{}, "test-arch", "test-brand", "test-cuda", "00000000:18:00.0"),
+			"GPU-1": testutil.NewMockDevice(&mock.Device{}, "test-arch", "test-brand", "test-cuda", "00000000:2A:00.0"),
+		},
+	}
+}
+
+func newTestEventBucket(t *testing.T) (eventstore.Bucket, func()) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+	bucket, err := store.Bucket(Name)
+	require.NoError(t, err)
+	return bucket, cleanup
+}
+
+func presentUnless(statuses map[string]pci.DeviceStatus) func(string, string) (pci.DeviceStatus, error) {
+	return func(_ string, busID string) (pci.DeviceStatus, error) {
+		if st, ok := statuses[busID]; ok {
+			return st, nil
+		}
+		return pci.DeviceStatusPresent, nil
+	}
+}
+
+func TestCheckAllPresent(t *testing.T) {
+	bucket, cleanup := newTestEventBucket(t)
+	defer cleanup()
+
+	var calls []string
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &component{
+		ctx:                 ctx,
+		cancel:              cancel,
+		nvmlInstance:        newTestNVMLInstance(),
+		eventBucket:         bucket,
+		getRescanEnabled:    func() bool { return true },
+		getDeviceStatusFunc: presentUnless(nil),
+		removeDeviceFunc: func(_ string, busID string) error {
+			calls = append(calls, "remove "+busID)
+			return nil
+		},
+		rescanFunc: func(_ string) error {
+			calls = append(calls, "rescan")
+			return nil
+		},
+		rescanWait: time.Millisecond,
+	}
+	defer c.Close()
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "all 2 GPU(s) are present on the pci bus", cr.Summary())
+	require.Len(t, cr.GPUs, 2)
+	assert.Equal(t, "0000:18:00.0", cr.GPUs[0].BusID)
+	assert.Equal(t, "0000:2a:00.0", cr.GPUs[1].BusID)
+	assert.Empty(t, calls)
+
+	c.getThresholdsFunc = func() Thresholds { return Thresholds{ExpectedGPUs: 2} }
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+
+	// fewer GPUs than expected were found at startup
+	c.getThresholdsFunc = func() Thresholds { return Thresholds{ExpectedGPUs: 8} }
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	assert.Equal(t, "found 2 GPU(s) on the pci bus, expected 8", cr.Summary())
+	assert.Equal(t, []apiv1.FailureCode{apiv1.FailureCodeGPUFallenOffBus}, cr.failureCodes)
+}
+
+func TestCheckFallenOffBusNoRescan(t *testing.T) {
+	statuses := map[string]pci.DeviceStatus{"0000:2a:00.0": pci.DeviceStatusMissing}
+	bucket, cleanup := newTestEventBucket(t)
+	defer cleanup()
+
+	var calls []string
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &component{
+		ctx:                 ctx,
+		cancel:              cancel,
+		nvmlInstance:        newTestNVMLInstance(),
+		eventBucket:         bucket,
+		getRescanEnabled:    func() bool { return false },
+		getDeviceStatusFunc: presentUnless(statuses),
+		removeDeviceFunc: func(_ string, busID string) error {
+			calls = append(calls, "remove "+busID)
+			return nil
+		},
+		rescanFunc: func(_ string) error {
+			calls = append(calls, "rescan")
+			return nil
+		},
+		rescanWait: time.Millisecond,
+	}
+	defer c.Close()
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	assert.Equal(t, "1 GPU(s) fell off the pci bus: GPU-1", cr.Summary())
+	assert.Equal(t, []apiv1.FailureCode{apiv1.FailureCodeGPUFallenOffBus}, cr.HealthStates()[0].FailureCodes)
+	require.NotNil(t, cr.getSuggestedActions())
+	assert.Equal(t, []apiv1.RepairActionType{apiv1.RepairActionTypeRebootSystem}, cr.getSuggestedActions().RepairActions)
+	assert.Empty(t, calls)
+
+	// same event is not inserted twice
+	time.Sleep(10 * time.Millisecond)
+	_ = c.Check()
+	evs, err := c.Events(context.Background(), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, evs, 1)
+	assert.Equal(t, EventNameGPUMissingFromPCIBus, evs[0].Name)
+	assert.Equal(t, apiv1.EventTypeCritical, evs[0].Type)
+}
+
+func TestCheckRescanRecovered(t *testing.T) {
+	statuses := map[string]pci.DeviceStatus{"0000:18:00.0": pci.DeviceStatusUnresponsive}
+	bucket, cleanup := newTestEventBucket(t)
+	defer cleanup()
+
+	var calls []string
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &component{
+		ctx:                 ctx,
+		cancel:              cancel,
+		nvmlInstance:        newTestNVMLInstance(),
+		eventBucket:         bucket,
+		getRescanEnabled:    func() bool { return true },
+		getDeviceStatusFunc: presentUnless(statuses),
+		removeDeviceFunc: func(_ string, busID string) error {
+			calls = append(calls, "remove "+busID)
+			return nil
+		},
+		// recovers after the rescan
+		rescanFunc: func(_ string) error {
+			calls = append(calls, "rescan")
+			delete(statuses, "0000:18:00.0")
+			return nil
+		},
+		rescanWait: time.Millisecond,
+	}
+	defer c.Close()
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+	assert.Equal(t, []string{"remove 0000:18:00.0", "rescan"}, calls)
+	assert.Equal(t, []string{"GPU-0"}, cr.Rescanned)
+	assert.Nil(t, cr.getSuggestedActions())
+
+	evs, err := c.Events(context.Background(), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	names := make(map[string]bool)
+	for _, ev := range evs {
+		names[ev.Name] = true
+	}
+	assert.True(t, names[EventNameGPUMissingFromPCIBus])
+	assert.True(t, names[EventNameGPURecoveredByRescan])
+}
+
+func TestCheckRescanNotRecovered(t *testing.T) {
+	statuses := map[string]pci.DeviceStatus{"0000:2a:00.0": pci.DeviceStatusMissing}
+	bucket, cleanup := newTestEventBucket(t)
+	defer cleanup()
+
+	var calls []string
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &component{
+		ctx:                 ctx,
+		cancel:              cancel,
+		nvmlInstance:        newTestNVMLInstance(),
+		eventBucket:         bucket,
+		getRescanEnabled:    func() bool { return true },
+		getDeviceStatusFunc: presentUnless(statuses),
+		removeDeviceFunc: func(_ string, busID string) error {
+			calls = append(calls, "remove "+busID)
+			return nil
+		},
+		rescanFunc: func(_ string) error {
+			calls = append(calls, "rescan")
+			return nil
+		},
+		rescanWait: time.Millisecond,
+	}
+	defer c.Close()
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	// missing device is not removed, only rescanned
+	assert.Equal(t, []string{"rescan"}, calls)
+	assert.Contains(t, cr.Summary(), "not recovered by pci rescan")
+	assert.Equal(t, []apiv1.RepairActionType{apiv1.RepairActionTypeRebootSystem}, cr.getSuggestedActions().RepairActions)
+	assert.Contains(t, cr.String(), "missing")
+
+	// backs off from the rescan of the same fallen GPU
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, []string{"rescan"}, calls)
+	assert.Empty(t, cr.Rescanned)
+	assert.Contains(t, cr.Summary(), "not recovered by pci rescan")
+
+	c.stateMu.Lock()
+	l := c.lost["GPU-1"]
+	l.LastRescan = l.LastRescan.Add(-defaultRescanBackoff)
+	c.lost["GPU-1"] = l
+	c.stateMu.Unlock()
+	_ = c.Check()
+	assert.Equal(t, []string{"rescan", "rescan"}, calls)
+
+	// gives up after the max rescans
+	for i := 0; i < 5; i++ {
+		c.stateMu.Lock()
+		l := c.lost["GPU-1"]
+		l.LastRescan = l.LastRescan.Add(-24 * time.Hour)
+		c.lost["GPU-1"] = l
+		c.stateMu.Unlock()
+		_ = c.Check()
+	}
+	assert.Len(t, calls, defaultMaxRescans)
+}
+
+func TestCheckPersistedBaseline(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+	require.NoError(t, pkgmetadata.CreateTableMetadata(context.Background(), dbRW))
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+	bucket, err := store.Bucket(Name)
+	require.NoError(t, err)
+
+	newComponent := func(nvmlInstance *mockNVMLInstance, statuses map[string]pci.DeviceStatus) *component {
+		ctx, cancel := context.WithCancel(context.Background())
+		return &component{
+			ctx:                 ctx,
+			cancel:              cancel,
+			nvmlInstance:        nvmlInstance,
+			eventBucket:         bucket,
+			dbRW:                dbRW,
+			dbRO:                dbRO,
+			getDeviceStatusFunc: presentUnless(statuses),
+			rescanWait:          time.Millisecond,
+		}
+	}
+
+	c := newComponent(newTestNVMLInstance(), map[string]pci.DeviceStatus{"0000:2a:00.0": pci.DeviceStatusMissing})
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	since := c.lost["GPU-1"].Since
+	c.cancel()
+
+	// GPU-1 is no longer enumerated by the NVML after the restart,
+	// but still tracked from the persisted baseline, with the same event
+	nvmlInstance := newTestNVMLInstance()
+	delete(nvmlInstance.devs, "GPU-1")
+	c = newComponent(nvmlInstance, map[string]pci.DeviceStatus{"0000:2a:00.0": pci.DeviceStatusMissing})
+	defer c.Close()
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	assert.Equal(t, "1 GPU(s) fell off the pci bus: GPU-1", cr.Summary())
+	assert.True(t, since.Equal(c.lost["GPU-1"].Since))
+
+	evs, err := c.Events(context.Background(), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, evs, 1)
+
+	// the GPU replaced on the same pci bus id replaces the persisted one
+	c.cancel()
+	nvmlInstance = newTestNVMLInstance()
+	nvmlInstance.devs["GPU-2"] = nvmlInstance.devs["GPU-1"]
+	delete(nvmlInstance.devs, "GPU-1")
+	c = newComponent(nvmlInstance, nil)
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	require.Len(t, cr.GPUs, 2)
+	assert.Equal(t, "GPU-2", cr.GPUs[1].UUID)
+	assert.Empty(t, c.lost)
+}
+
+func TestCheckNilNVML(t *testing.T) {
+	c := &component{ctx: context.Background(), cancel: func() {}}
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "NVIDIA NVML instance is nil", cr.Summary())
+
+	var nilResult *checkResult
+	assert.Equal(t, "no data yet", nilResult.HealthStates()[0].Reason)
+}
+
+func TestDefaultRescanEnabled(t *testing.T) {
+	defer SetDefaultRescanEnabled(false)
+
+	assert.False(t, GetDefaultRescanEnabled())
+	SetDefaultRescanEnabled(true)
+	assert.True(t, GetDefaultRescanEnabled())
+}
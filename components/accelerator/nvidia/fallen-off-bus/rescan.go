@@ -0,0 +1,30 @@
+package fallenoffbus
+
+import (
+	"sync"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+var (
+	defaultRescanEnabledMu sync.RWMutex
+	defaultRescanEnabled   = false
+)
+
+// GetDefaultRescanEnabled returns true if the PCI rescan remediation is enabled.
+func GetDefaultRescanEnabled() bool {
+	defaultRescanEnabledMu.RLock()
+	defer defaultRescanEnabledMu.RUnlock()
+	return defaultRescanEnabled
+}
+
+// SetDefaultRescanEnabled enables or disables the PCI rescan remediation,
+// which removes the unresponsive GPUs from the PCI enumeration and rescans the bus
+// to recover them, before suggesting a reboot.
+func SetDefaultRescanEnabled(enabled bool) {
+	log.Logger.Infow("setting default pci rescan enabled", "enabled", enabled)
+
+	defaultRescanEnabledMu.Lock()
+	defer defaultRescanEnabledMu.Unlock()
+	defaultRescanEnabled = enabled
+}
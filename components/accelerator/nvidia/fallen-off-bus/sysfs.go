@@ -0,0 +1,30 @@
+package fallenoffbus
+
+import (
+	"sync"
+
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/pci"
+)
+
+var (
+	defaultSysfsRootMu sync.RWMutex
+	defaultSysfsRoot   = pci.DefaultSysfsRoot
+)
+
+// getDefaultSysfsRoot returns the sysfs directory of the PCI bus to check the GPUs in.
+func getDefaultSysfsRoot() string {
+	defaultSysfsRootMu.RLock()
+	defer defaultSysfsRootMu.RUnlock()
+	return defaultSysfsRoot
+}
+
+// SetDefaultSysfsRoot sets the sysfs directory of the PCI bus to check the GPUs in
+// (e.g., the recorded sysfs snapshot to replay the scan with).
+func SetDefaultSysfsRoot(root string) {
+	log.Logger.Infow("setting default pci sysfs root", "root", root)
+
+	defaultSysfsRootMu.Lock()
+	defer defaultSysfsRootMu.Unlock()
+	defaultSysfsRoot = root
+}
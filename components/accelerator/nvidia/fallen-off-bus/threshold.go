@@ -0,0 +1,34 @@
+package fallenoffbus
+
+import (
+	"sync"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// Thresholds are the expected GPUs of the node.
+type Thresholds struct {
+	// ExpectedGPUs is the number of the GPUs expected on the pci bus,
+	// to detect the GPUs already missing when gpud starts.
+	// Zero to only compare with the GPUs found at startup.
+	ExpectedGPUs int `json:"expected_gpus,omitempty"`
+}
+
+var (
+	defaultThresholdsMu sync.RWMutex
+	defaultThresholds   Thresholds
+)
+
+func GetDefaultThresholds() Thresholds {
+	defaultThresholdsMu.RLock()
+	defer defaultThresholdsMu.RUnlock()
+	return defaultThresholds
+}
+
+func SetDefaultThresholds(thresholds Thresholds) {
+	log.Logger.Infow("setting default gpu thresholds", "expected_gpus", thresholds.ExpectedGPUs)
+
+	defaultThresholdsMu.Lock()
+	defer defaultThresholdsMu.Unlock()
+	defaultThresholds = thresholds
+}
@@ -0,0 +1,348 @@
+// Package gdr validates the GPUDirect RDMA prerequisites (the peer-direct memory
+// client registered with the RDMA core, and the PCIe topology between the GPUs
+// and the HCAs), since the broken GPUDirect RDMA silently falls back to staging
+// the transfers through the host memory. The peer memory kernel module itself
+// is monitored by the "accelerator-nvidia-peermem" component.
+package gdr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/components/accelerator/nvidia/peermem"
+	pkghost "github.com/leptonai/gpud/pkg/host"
+	"github.com/leptonai/gpud/pkg/log"
+	querygdr "github.com/leptonai/gpud/pkg/nvidia-query/gdr"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	"github.com/leptonai/gpud/pkg/pci"
+	"github.com/leptonai/gpud/pkg/toolexec"
+)
+
+// Name is the ID of the GPUDirect RDMA component.
+const Name = "accelerator-nvidia-gdr"
+
+var _ components.Component = &component{}
+
+type component struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	checkInterval time.Duration
+
+	nvmlInstance nvidianvml.Instance
+
+	getHCAPathsFunc    func() (map[string]string, error)
+	getMemoryPeersFunc func() ([]querygdr.MemoryPeer, bool, error)
+	// getGPUPathsFunc returns the GPU sysfs device paths and the UUIDs, keyed by the bus ID
+	getGPUPathsFunc func() (map[string]string, map[string]string, error)
+	// isVMFunc returns true if the host is a VM, whose emulated PCIe host bridges
+	// do not reflect the physical topology
+	isVMFunc func() bool
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	cctx, ccancel := context.WithCancel(toolexec.WithComponent(gpudInstance.RootCtx, Name))
+	c := &component{
+		ctx:    cctx,
+		cancel: ccancel,
+
+		checkInterval: gpudInstance.CheckInterval(Name),
+
+		nvmlInstance: gpudInstance.NVMLInstance,
+
+		getHCAPathsFunc: func() (map[string]string, error) {
+			return querygdr.GetHCADevicePaths(querygdr.DefaultInfinibandClassDir)
+		},
+		getMemoryPeersFunc: func() ([]querygdr.MemoryPeer, bool, error) {
+			return querygdr.GetMemoryPeers(querygdr.DefaultMemoryPeersDir)
+		},
+		isVMFunc: func() bool {
+			vm := pkghost.VirtualizationEnv().VM
+			return vm != "" && vm != "none"
+		},
+	}
+	c.getGPUPathsFunc = c.getGPUPaths
+	return c, nil
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"accelerator",
+		"gpu",
+		"nvidia",
+		"network",
+		Name,
+	}
+}
+
+func (c *component) IsSupported() bool {
+	if c.nvmlInstance == nil {
+		return false
+	}
+	return c.nvmlInstance.NVMLExists() && c.nvmlInstance.ProductName() != ""
+}
+
+func (c *component) Start() error {
+	go func() {
+		ticker := components.NewCheckTicker(c.checkInterval)
+		defer ticker.Stop()
+
+		for {
+			_ = components.SafeCheck(c)
+
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	return nil, nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking nvidia gpudirect rdma")
+
+	cr := &checkResult{
+		ts: time.Now().UTC(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	if c.nvmlInstance == nil {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML instance is nil"
+		return cr
+	}
+	if !c.nvmlInstance.NVMLExists() {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML library is not loaded"
+		return cr
+	}
+
+	hcaPaths, err := c.getHCAPathsFunc()
+	if err != nil {
+		cr.err = err
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = "error listing RDMA devices"
+		log.Logger.Errorw(cr.reason, "error", cr.err)
+		return cr
+	}
+	if len(hcaPaths) == 0 {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "no RDMA device found (GPUDirect RDMA not applicable)"
+		return cr
+	}
+	cr.HCAs = len(hcaPaths)
+
+	cr.MemoryPeers, cr.PeerDirectSupported, err = c.getMemoryPeersFunc()
+	if err != nil {
+		log.Logger.Warnw("failed to get peer-direct memory clients", "error", err)
+	} else if cr.PeerDirectSupported && !hasMemoryPeer(cr.MemoryPeers, querygdr.MemoryPeerNVIDIA) {
+		// the inbox RDMA core without the peer-direct memory uses the dma-buf instead
+		cr.Issues = append(cr.Issues, fmt.Sprintf("peer-direct memory client %q not registered (peer memory module not loaded, see %s)", querygdr.MemoryPeerNVIDIA, peermem.Name))
+	}
+
+	gpuPaths, uuids, err := c.getGPUPathsFunc()
+	if err != nil {
+		log.Logger.Warnw("failed to get gpu device paths", "error", err)
+	} else if len(gpuPaths) > 0 {
+		cr.Topology, err = querygdr.GetTopology(gpuPaths, uuids, hcaPaths)
+		if err != nil {
+			log.Logger.Warnw("failed to get gpu to hca topology", "error", err)
+		}
+		if c.isVMFunc != nil {
+			cr.VM = c.isVMFunc()
+		}
+
+		// only the hosts with an HCA per GPU (e.g., the rail-optimized topology)
+		// expect every GPU to have a nearby HCA
+		if len(hcaPaths) >= len(gpuPaths) {
+			for _, t := range cr.Topology {
+				if !t.Level.FartherThan(querygdr.DefaultMaxLevel) {
+					continue
+				}
+				// the emulated host bridges of the VM do not reflect the physical topology
+				if cr.VM && t.Level.CrossesHostBridges() {
+					continue
+				}
+				cr.Issues = append(cr.Issues, fmt.Sprintf("GPU %s nearest HCA %s at %s (expected %s or nearer)", t.BusID, t.NearestHCA, t.Level, querygdr.DefaultMaxLevel))
+			}
+		}
+	}
+
+	if len(cr.Issues) > 0 {
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = "GPUDirect RDMA not ready: " + strings.Join(cr.Issues, "; ")
+		log.Logger.Warnw(cr.reason)
+		return cr
+	}
+
+	cr.health = apiv1.HealthStateTypeHealthy
+	cr.reason = fmt.Sprintf("GPUDirect RDMA ready (%d HCA(s), %d GPU(s) checked)", cr.HCAs, len(cr.Topology))
+	return cr
+}
+
+// getGPUPaths returns the sysfs device paths of the GPUs visible to NVML.
+func (c *component) getGPUPaths() (map[string]string, map[string]string, error) {
+	paths := make(map[string]string)
+	uuids := make(map[string]string)
+	for uuid, dev := range c.nvmlInstance.Devices() {
+		busID, err := dev.GetPCIBusID()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get pci bus id of %s: %w", uuid, err)
+		}
+		busID = pci.NormalizeBusID(busID)
+		p, err := querygdr.GetGPUDevicePath(pci.DefaultSysfsRoot, busID)
+		if err != nil {
+			return nil, nil, err
+		}
+		paths[busID] = p
+		uuids[busID] = uuid
+	}
+	return paths, uuids, nil
+}
+
+func hasMemoryPeer(peers []querygdr.MemoryPeer, name string) bool {
+	for _, p := range peers {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	// HCAs is the number of the RDMA devices used for the GPUDirect RDMA.
+	HCAs int `json:"hcas"`
+	// PeerDirectSupported is true if the RDMA core supports the peer-direct memory.
+	PeerDirectSupported bool `json:"peer_direct_supported"`
+	// MemoryPeers are the peer-direct memory clients registered with the RDMA core.
+	MemoryPeers []querygdr.MemoryPeer `json:"memory_peers,omitempty"`
+	// Topology is the nearest HCA of each GPU.
+	Topology []querygdr.GPUTopology `json:"topology,omitempty"`
+	// VM is true if the host is a VM, where the topology across
+	// the host bridges is not evaluated.
+	VM bool `json:"vm,omitempty"`
+	// Issues are the found misconfigurations.
+	Issues []string `json:"issues,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if cr.HCAs == 0 {
+		return "no RDMA device found"
+	}
+
+	out := fmt.Sprintf("peer-direct supported: %t, HCAs: %d", cr.PeerDirectSupported, cr.HCAs)
+	for _, t := range cr.Topology {
+		out += fmt.Sprintf("\nGPU %s: nearest HCA %s (%s)", t.BusID, t.NearestHCA, t.Level)
+	}
+	for _, issue := range cr.Issues {
+		out += "\n" + issue
+	}
+	return out
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:      metav1.NewTime(cr.ts),
+		Component: Name,
+		Name:      Name,
+		Reason:    cr.reason,
+		Error:     cr.getError(),
+		Health:    cr.health,
+	}
+
+	if cr.HCAs > 0 {
+		b, _ := json.Marshal(cr)
+		state.ExtraInfo = map[string]string{"data": string(b)}
+	}
+	return apiv1.HealthStates{state}
+}
@@ -0,0 +1,206 @@
+package gdr
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	querygdr "github.com/leptonai/gpud/pkg/nvidia-query/gdr"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+)
+
+type mockNVMLInstance struct {
+	nvidianvml.Instance
+	exists bool
+}
+
+func (m *mockNVMLInstance) NVMLExists() bool    { return m.exists }
+func (m *mockNVMLInstance) ProductName() string { return "H100" }
+
+func TestComponentBasics(t *testing.T) {
+	comp, err := New(&components.GPUdInstance{RootCtx: context.Background(), NVMLInstance: &mockNVMLInstance{exists: true}})
+	require.NoError(t, err)
+	defer comp.Close()
+
+	assert.Equal(t, Name, comp.Name())
+	assert.Contains(t, comp.Tags(), Name)
+	assert.True(t, comp.IsSupported())
+
+	events, err := comp.Events(context.Background(), time.Now())
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+
+	states := comp.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, "no data yet", states[0].Reason)
+}
+
+func TestCheck(t *testing.T) {
+	// GPU and HCA sysfs paths behind the same PCIe switch, or across the sockets
+	hcas := map[string]string{
+		"mlx5_0": "/sys/devices/pci0000:00/0000:00:01.0/0000:01:00.0/0000:02:01.0/0000:04:00.0",
+	}
+	nearGPU := map[string]string{
+		"0000:03:00.0": "/sys/devices/pci0000:00/0000:00:01.0/0000:01:00.0/0000:02:00.0/0000:03:00.0",
+	}
+	farGPU := map[string]string{
+		"0000:03:00.0": "/sys/devices/pci0000:00/0000:00:02.0/0000:03:00.0",
+	}
+	// emulated host bridge per device in VMs
+	otherBridgeGPU := map[string]string{
+		"0000:81:00.0": "/sys/devices/pci0000:80/0000:80:01.0/0000:81:00.0",
+	}
+	nvMem := []querygdr.MemoryPeer{{Name: querygdr.MemoryPeerNVIDIA}}
+
+	tests := []struct {
+		name          string
+		hcas          map[string]string
+		hcasErr       error
+		memoryPeers   []querygdr.MemoryPeer
+		peerDirect    bool
+		gpus          map[string]string
+		vm            bool
+		wantHealth    apiv1.HealthStateType
+		wantReason    string
+		wantIssues    int
+		wantExtraInfo bool
+	}{
+		{
+			name:       "no rdma device",
+			wantHealth: apiv1.HealthStateTypeHealthy,
+			wantReason: "no RDMA device found",
+		},
+		{
+			name:       "error listing rdma devices",
+			hcasErr:    errors.New("permission denied"),
+			wantHealth: apiv1.HealthStateTypeUnhealthy,
+			wantReason: "error listing RDMA devices",
+		},
+		{
+			name:          "ready",
+			hcas:          hcas,
+			memoryPeers:   nvMem,
+			peerDirect:    true,
+			gpus:          nearGPU,
+			wantHealth:    apiv1.HealthStateTypeHealthy,
+			wantReason:    "GPUDirect RDMA ready (1 HCA(s), 1 GPU(s) checked)",
+			wantExtraInfo: true,
+		},
+		{
+			name:          "ready with inbox rdma core",
+			hcas:          hcas,
+			gpus:          nearGPU,
+			wantHealth:    apiv1.HealthStateTypeHealthy,
+			wantReason:    "GPUDirect RDMA ready",
+			wantExtraInfo: true,
+		},
+		{
+			name:          "peer memory client not registered",
+			hcas:          hcas,
+			peerDirect:    true,
+			gpus:          nearGPU,
+			wantHealth:    apiv1.HealthStateTypeDegraded,
+			wantReason:    `peer-direct memory client "nv_mem" not registered (peer memory module not loaded, see accelerator-nvidia-peermem)`,
+			wantIssues:    1,
+			wantExtraInfo: true,
+		},
+		{
+			name:          "gpu far from hca",
+			hcas:          hcas,
+			memoryPeers:   nvMem,
+			peerDirect:    true,
+			gpus:          farGPU,
+			wantHealth:    apiv1.HealthStateTypeDegraded,
+			wantReason:    "GPU 0000:03:00.0 nearest HCA mlx5_0 at PHB (expected PXB or nearer)",
+			wantIssues:    1,
+			wantExtraInfo: true,
+		},
+		{
+			name:          "gpu across host bridges",
+			hcas:          hcas,
+			memoryPeers:   nvMem,
+			peerDirect:    true,
+			gpus:          otherBridgeGPU,
+			wantHealth:    apiv1.HealthStateTypeDegraded,
+			wantReason:    "GPU 0000:81:00.0 nearest HCA mlx5_0 at",
+			wantIssues:    1,
+			wantExtraInfo: true,
+		},
+		{
+			name:          "gpu across host bridges in vm",
+			hcas:          hcas,
+			memoryPeers:   nvMem,
+			peerDirect:    true,
+			gpus:          otherBridgeGPU,
+			vm:            true,
+			wantHealth:    apiv1.HealthStateTypeHealthy,
+			wantReason:    "GPUDirect RDMA ready (1 HCA(s), 1 GPU(s) checked)",
+			wantExtraInfo: true,
+		},
+		{
+			name:        "gpu far from hca with fewer hcas than gpus",
+			hcas:        hcas,
+			memoryPeers: nvMem,
+			peerDirect:  true,
+			gpus: map[string]string{
+				"0000:03:00.0": nearGPU["0000:03:00.0"],
+				"0000:05:00.0": "/sys/devices/pci0000:00/0000:00:02.0/0000:05:00.0",
+			},
+			wantHealth:    apiv1.HealthStateTypeHealthy,
+			wantReason:    "2 GPU(s) checked",
+			wantExtraInfo: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &component{
+				ctx:          context.Background(),
+				nvmlInstance: &mockNVMLInstance{exists: true},
+				getHCAPathsFunc: func() (map[string]string, error) {
+					return tt.hcas, tt.hcasErr
+				},
+				getMemoryPeersFunc: func() ([]querygdr.MemoryPeer, bool, error) {
+					return tt.memoryPeers, tt.peerDirect, nil
+				},
+				getGPUPathsFunc: func() (map[string]string, map[string]string, error) {
+					return tt.gpus, nil, nil
+				},
+				isVMFunc: func() bool {
+					return tt.vm
+				},
+			}
+
+			cr := c.Check().(*checkResult)
+			assert.Equal(t, tt.wantHealth, cr.HealthStateType())
+			assert.Contains(t, cr.Summary(), tt.wantReason)
+			assert.Len(t, cr.Issues, tt.wantIssues)
+
+			states := c.LastHealthStates()
+			require.Len(t, states, 1)
+			assert.Equal(t, tt.wantHealth, states[0].Health)
+			if tt.wantExtraInfo {
+				assert.Contains(t, states[0].ExtraInfo, "data")
+			} else {
+				assert.Empty(t, states[0].ExtraInfo)
+			}
+		})
+	}
+}
+
+func TestCheckNVMLNotLoaded(t *testing.T) {
+	c := &component{
+		ctx:          context.Background(),
+		nvmlInstance: &mockNVMLInstance{exists: false},
+	}
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "NVIDIA NVML library is not loaded", cr.Summary())
+	assert.Equal(t, "no RDMA device found", cr.String())
+}
@@ -0,0 +1,404 @@
+// Package gds validates the GPUDirect Storage (GDS) prerequisites (the nvidia-fs module,
+// the cuFile configuration, the GDS compatible filesystems), and optionally probes
+// the read throughput, since the misconfigured GDS silently falls back to the slow
+// compatibility mode (POSIX reads through the CPU bounce buffers).
+package gds
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/log"
+	querygds "github.com/leptonai/gpud/pkg/nvidia-query/gds"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	"github.com/leptonai/gpud/pkg/toolexec"
+)
+
+// Name is the ID of the GDS component.
+const Name = "accelerator-nvidia-gds"
+
+// DefaultProbeInterval is the interval to re-run the throughput probe,
+// since the probe reads gigabytes from the storage.
+const DefaultProbeInterval = time.Hour
+
+var _ components.Component = &component{}
+
+type component struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	checkInterval time.Duration
+
+	nvmlInstance nvidianvml.Instance
+
+	getModuleVersionFunc    func() (string, bool, error)
+	readCufileConfigFunc    func() (*querygds.CufileConfig, error)
+	getCompatibleMountsFunc func() ([]querygds.Mount, error)
+
+	getProbeConfigFunc func() querygds.ProbeConfig
+	runProbeFunc       func(ctx context.Context, cfg querygds.ProbeConfig) (*querygds.ProbeResult, error)
+	probeInterval      time.Duration
+	// getNvfsReadOpsFunc returns the number of the reads served by nvidia-fs,
+	// to detect the probe falling back to the compatibility mode
+	getNvfsReadOpsFunc func() (uint64, bool, error)
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+
+	// lastProbe is the last throughput probe, re-run every probe interval
+	lastProbe   *probe
+	lastProbeAt time.Time
+}
+
+type probe struct {
+	config querygds.ProbeConfig
+	result *querygds.ProbeResult
+	err    error
+	// compatMode is true if the probe reads fell back to the compatibility mode
+	compatMode bool
+}
+
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	cctx, ccancel := context.WithCancel(toolexec.WithComponent(gpudInstance.RootCtx, Name))
+	return &component{
+		ctx:    cctx,
+		cancel: ccancel,
+
+		checkInterval: gpudInstance.CheckInterval(Name),
+
+		nvmlInstance: gpudInstance.NVMLInstance,
+
+		getModuleVersionFunc: func() (string, bool, error) {
+			return querygds.GetLoadedModuleVersion(querygds.DefaultSysModuleDir)
+		},
+		readCufileConfigFunc: func() (*querygds.CufileConfig, error) {
+			return querygds.ReadCufileConfig(querygds.CufileConfigPath())
+		},
+		getCompatibleMountsFunc: func() ([]querygds.Mount, error) {
+			return querygds.GetCompatibleMounts(querygds.DefaultProcMountsPath)
+		},
+
+		getProbeConfigFunc: GetDefaultProbeConfig,
+		runProbeFunc:       querygds.RunProbe,
+		probeInterval:      DefaultProbeInterval,
+		getNvfsReadOpsFunc: func() (uint64, bool, error) {
+			return querygds.GetNvfsReadOps(querygds.DefaultNvfsStatsPath)
+		},
+	}, nil
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"accelerator",
+		"gpu",
+		"nvidia",
+		"disk",
+		Name,
+	}
+}
+
+func (c *component) IsSupported() bool {
+	if c.nvmlInstance == nil {
+		return false
+	}
+	return c.nvmlInstance.NVMLExists() && c.nvmlInstance.ProductName() != ""
+}
+
+// Priority returns the low priority, since the throughput probe
+// competes with the workloads for the storage bandwidth.
+func (c *component) Priority() components.Priority {
+	return components.PriorityLow
+}
+
+func (c *component) Start() error {
+	go func() {
+		ticker := components.NewCheckTicker(c.checkInterval)
+		defer ticker.Stop()
+
+		for {
+			_ = components.SafeCheck(c)
+
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	return nil, nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking nvidia gds")
+
+	cr := &checkResult{
+		ts: time.Now().UTC(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	if c.nvmlInstance == nil {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML instance is nil"
+		return cr
+	}
+	if !c.nvmlInstance.NVMLExists() {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML library is not loaded"
+		return cr
+	}
+
+	cr.CufileConfig, cr.err = c.readCufileConfigFunc()
+	if cr.err != nil {
+		if errors.Is(cr.err, querygds.ErrNoCufileConfig) {
+			cr.err = nil
+			cr.health = apiv1.HealthStateTypeHealthy
+			cr.reason = "GDS not installed"
+			return cr
+		}
+
+		// the cuFile library fails to initialize with the invalid config
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = "error reading cufile config"
+		log.Logger.Errorw(cr.reason, "error", cr.err)
+		return cr
+	}
+
+	moduleVersion, loaded, err := c.getModuleVersionFunc()
+	if err != nil {
+		log.Logger.Warnw("failed to get loaded nvidia-fs module version", "error", err)
+	}
+	cr.ModuleLoaded = loaded
+	cr.ModuleVersion = moduleVersion
+	if err == nil && !loaded {
+		if cr.CufileConfig.AllowCompatMode {
+			cr.Issues = append(cr.Issues, "nvidia-fs module not loaded (cuFile silently falls back to the compatibility mode)")
+		} else {
+			cr.Issues = append(cr.Issues, "nvidia-fs module not loaded (cuFile fails with the compatibility mode disallowed)")
+		}
+	}
+
+	cr.Mounts, err = c.getCompatibleMountsFunc()
+	if err != nil {
+		log.Logger.Warnw("failed to get gds compatible mounts", "error", err)
+	} else if len(cr.Mounts) == 0 {
+		cr.Issues = append(cr.Issues, "no GDS compatible filesystem mounted (ext4, xfs, NFS over RDMA, or the supported distributed filesystems)")
+	}
+
+	if p := c.runProbe(); p != nil {
+		cr.ProbeConfig = &p.config
+		cr.Probe = p.result
+		cr.ProbeCompatMode = p.compatMode
+		switch {
+		case p.err != nil:
+			cr.Issues = append(cr.Issues, fmt.Sprintf("throughput probe in %s failed: %v", p.config.Dir, p.err))
+		case p.compatMode:
+			cr.Issues = append(cr.Issues, fmt.Sprintf("throughput probe in %s fell back to the compatibility mode (%.2f GiB/s, xfer type %s)", p.config.Dir, p.result.ThroughputGiBps, p.result.XferType))
+		case p.config.MinThroughputGiBps > 0 && p.result.ThroughputGiBps < p.config.MinThroughputGiBps:
+			cr.Issues = append(cr.Issues, fmt.Sprintf("read throughput %.2f GiB/s in %s below %.2f GiB/s (xfer type %s)", p.result.ThroughputGiBps, p.config.Dir, p.config.MinThroughputGiBps, p.result.XferType))
+		}
+	}
+
+	if len(cr.Issues) > 0 {
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = "GDS misconfigured: " + strings.Join(cr.Issues, "; ")
+		log.Logger.Warnw(cr.reason)
+		return cr
+	}
+
+	cr.health = apiv1.HealthStateTypeHealthy
+	cr.reason = fmt.Sprintf("GDS ready (nvidia-fs %s, %d compatible filesystem(s))", cr.ModuleVersion, len(cr.Mounts))
+	if cr.Probe != nil {
+		cr.reason += fmt.Sprintf(", read throughput %.2f GiB/s", cr.Probe.ThroughputGiBps)
+	}
+	return cr
+}
+
+// runProbe returns the last throughput probe, re-run if the probe interval has elapsed
+// or the probe config has changed, or nil if the probe is disabled.
+func (c *component) runProbe() *probe {
+	cfg := c.getProbeConfigFunc()
+	if cfg.Dir == "" {
+		return nil
+	}
+
+	c.lastMu.RLock()
+	last, lastAt := c.lastProbe, c.lastProbeAt
+	c.lastMu.RUnlock()
+	if last != nil && last.config == cfg && time.Since(lastAt) < c.probeInterval {
+		return last
+	}
+
+	readsBefore, statsBefore := c.getNvfsReadOps()
+
+	cctx, ccancel := context.WithTimeout(c.ctx, querygds.DefaultProbeTimeout)
+	result, err := c.runProbeFunc(cctx, cfg)
+	ccancel()
+
+	p := &probe{config: cfg, result: result, err: err}
+	if err == nil {
+		switch {
+		case result.XferType != "" && result.XferType != querygds.XferTypeGPUDirect:
+			p.compatMode = true
+		case statsBefore:
+			// the GPUDirect reads go through nvidia-fs, the compatibility mode reads do not
+			readsAfter, statsAfter := c.getNvfsReadOps()
+			p.compatMode = statsAfter && readsAfter == readsBefore
+		}
+	}
+	c.lastMu.Lock()
+	c.lastProbe = p
+	c.lastProbeAt = time.Now()
+	c.lastMu.Unlock()
+	return p
+}
+
+// getNvfsReadOps returns the number of the reads served by nvidia-fs,
+// and false if unknown (e.g., the nvidia-fs module not loaded).
+func (c *component) getNvfsReadOps() (uint64, bool) {
+	if c.getNvfsReadOpsFunc == nil {
+		return 0, false
+	}
+	n, ok, err := c.getNvfsReadOpsFunc()
+	if err != nil {
+		log.Logger.Warnw("failed to read nvidia-fs stats", "error", err)
+		return 0, false
+	}
+	return n, ok
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	// CufileConfig is the cuFile configuration.
+	CufileConfig *querygds.CufileConfig `json:"cufile_config,omitempty"`
+	// ModuleLoaded is true if the nvidia-fs module is loaded.
+	ModuleLoaded bool `json:"module_loaded"`
+	// ModuleVersion is the loaded nvidia-fs module version.
+	ModuleVersion string `json:"module_version,omitempty"`
+	// Mounts are the mounted GDS compatible filesystems.
+	Mounts []querygds.Mount `json:"mounts,omitempty"`
+	// ProbeConfig is the throughput probe config, nil if the probe is disabled.
+	ProbeConfig *querygds.ProbeConfig `json:"probe_config,omitempty"`
+	// Probe is the last throughput probe result.
+	Probe *querygds.ProbeResult `json:"probe,omitempty"`
+	// ProbeCompatMode is true if the last throughput probe fell back to the compatibility mode.
+	ProbeCompatMode bool `json:"probe_compat_mode,omitempty"`
+	// Issues are the found misconfigurations.
+	Issues []string `json:"issues,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if cr.CufileConfig == nil {
+		return "no GDS found"
+	}
+
+	out := fmt.Sprintf("nvidia-fs loaded: %t (version %q), compat mode allowed: %t, compatible filesystems: %d", cr.ModuleLoaded, cr.ModuleVersion, cr.CufileConfig.AllowCompatMode, len(cr.Mounts))
+	if cr.Probe != nil {
+		out += fmt.Sprintf("\nread throughput: %.2f GiB/s (xfer type %s, compat mode: %t)", cr.Probe.ThroughputGiBps, cr.Probe.XferType, cr.ProbeCompatMode)
+	}
+	for _, issue := range cr.Issues {
+		out += "\n" + issue
+	}
+	return out
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:      metav1.NewTime(cr.ts),
+		Component: Name,
+		Name:      Name,
+		Reason:    cr.reason,
+		Error:     cr.getError(),
+		Health:    cr.health,
+	}
+
+	if cr.CufileConfig != nil {
+		b, _ := json.Marshal(cr)
+		state.ExtraInfo = map[string]string{"data": string(b)}
+	}
+	return apiv1.HealthStates{state}
+}
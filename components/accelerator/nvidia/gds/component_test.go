@@ -0,0 +1,257 @@
+package gds
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	querygds "github.com/leptonai/gpud/pkg/nvidia-query/gds"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+)
+
+type mockNVMLInstance struct {
+	nvidianvml.Instance
+	exists bool
+}
+
+func (m *mockNVMLInstance) NVMLExists() bool    { return m.exists }
+func (m *mockNVMLInstance) ProductName() string { return "H100" }
+
+func TestComponentBasics(t *testing.T) {
+	comp, err := New(&components.GPUdInstance{RootCtx: context.Background(), NVMLInstance: &mockNVMLInstance{exists: true}})
+	require.NoError(t, err)
+	defer comp.Close()
+
+	assert.Equal(t, Name, comp.Name())
+	assert.Contains(t, comp.Tags(), Name)
+	assert.True(t, comp.IsSupported())
+	assert.Equal(t, components.PriorityLow, components.PriorityOf(comp))
+
+	events, err := comp.Events(context.Background(), time.Now())
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+
+	states := comp.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, "no data yet", states[0].Reason)
+}
+
+func TestCheck(t *testing.T) {
+	ext4 := []querygds.Mount{{Device: "/dev/md0", MountPoint: "/data", FsType: "ext4"}}
+
+	tests := []struct {
+		name         string
+		cufileConfig *querygds.CufileConfig
+		cufileErr    error
+		moduleLoaded bool
+		mounts       []querygds.Mount
+		probeConfig  querygds.ProbeConfig
+		probeResult  *querygds.ProbeResult
+		probeErr     error
+		// nvfsReads are the nvidia-fs read ops before and after the probe, nil if unavailable
+		nvfsReads  []uint64
+		wantHealth apiv1.HealthStateType
+		wantReason string
+		wantIssues int
+	}{
+		{
+			name:       "not installed",
+			cufileErr:  querygds.ErrNoCufileConfig,
+			wantHealth: apiv1.HealthStateTypeHealthy,
+			wantReason: "GDS not installed",
+		},
+		{
+			name:       "invalid cufile config",
+			cufileErr:  errors.New("failed to parse cufile config"),
+			wantHealth: apiv1.HealthStateTypeUnhealthy,
+			wantReason: "error reading cufile config",
+		},
+		{
+			name:         "ready",
+			cufileConfig: &querygds.CufileConfig{AllowCompatMode: true},
+			moduleLoaded: true,
+			mounts:       ext4,
+			wantHealth:   apiv1.HealthStateTypeHealthy,
+			wantReason:   "GDS ready (nvidia-fs 2.20.5, 1 compatible filesystem(s))",
+		},
+		{
+			name:         "module not loaded with compat mode",
+			cufileConfig: &querygds.CufileConfig{AllowCompatMode: true},
+			mounts:       ext4,
+			wantHealth:   apiv1.HealthStateTypeDegraded,
+			wantReason:   "silently falls back to the compatibility mode",
+			wantIssues:   1,
+		},
+		{
+			name:         "module not loaded and no compatible filesystem",
+			cufileConfig: &querygds.CufileConfig{AllowCompatMode: false},
+			wantHealth:   apiv1.HealthStateTypeDegraded,
+			wantReason:   "compatibility mode disallowed",
+			wantIssues:   2,
+		},
+		{
+			name:         "probe above threshold",
+			cufileConfig: &querygds.CufileConfig{AllowCompatMode: true},
+			moduleLoaded: true,
+			mounts:       ext4,
+			probeConfig:  querygds.ProbeConfig{Dir: "/data", MinThroughputGiBps: 2},
+			probeResult:  &querygds.ProbeResult{XferType: "GPUD", ThroughputGiBps: 3.2},
+			wantHealth:   apiv1.HealthStateTypeHealthy,
+			wantReason:   "read throughput 3.20 GiB/s",
+		},
+		{
+			name:         "probe below threshold",
+			cufileConfig: &querygds.CufileConfig{AllowCompatMode: true},
+			moduleLoaded: true,
+			mounts:       ext4,
+			probeConfig:  querygds.ProbeConfig{Dir: "/data", MinThroughputGiBps: 2},
+			probeResult:  &querygds.ProbeResult{XferType: "GPUD", ThroughputGiBps: 0.8},
+			wantHealth:   apiv1.HealthStateTypeDegraded,
+			wantReason:   "read throughput 0.80 GiB/s in /data below 2.00 GiB/s",
+			wantIssues:   1,
+		},
+		{
+			name:         "probe in compat mode without threshold",
+			cufileConfig: &querygds.CufileConfig{AllowCompatMode: true},
+			moduleLoaded: true,
+			mounts:       ext4,
+			probeConfig:  querygds.ProbeConfig{Dir: "/data"},
+			probeResult:  &querygds.ProbeResult{XferType: "CPUONLY", ThroughputGiBps: 2.5},
+			wantHealth:   apiv1.HealthStateTypeDegraded,
+			wantReason:   "throughput probe in /data fell back to the compatibility mode",
+			wantIssues:   1,
+		},
+		{
+			name:         "probe bypassing nvidia-fs",
+			cufileConfig: &querygds.CufileConfig{AllowCompatMode: true},
+			moduleLoaded: true,
+			mounts:       ext4,
+			probeConfig:  querygds.ProbeConfig{Dir: "/data"},
+			probeResult:  &querygds.ProbeResult{XferType: "GPUD", ThroughputGiBps: 2.5},
+			nvfsReads:    []uint64{100, 100},
+			wantHealth:   apiv1.HealthStateTypeDegraded,
+			wantReason:   "fell back to the compatibility mode",
+			wantIssues:   1,
+		},
+		{
+			name:         "probe through nvidia-fs",
+			cufileConfig: &querygds.CufileConfig{AllowCompatMode: true},
+			moduleLoaded: true,
+			mounts:       ext4,
+			probeConfig:  querygds.ProbeConfig{Dir: "/data"},
+			probeResult:  &querygds.ProbeResult{XferType: "GPUD", ThroughputGiBps: 2.5},
+			nvfsReads:    []uint64{100, 4196},
+			wantHealth:   apiv1.HealthStateTypeHealthy,
+			wantReason:   "read throughput 2.50 GiB/s",
+		},
+		{
+			name:         "probe failed",
+			cufileConfig: &querygds.CufileConfig{AllowCompatMode: true},
+			moduleLoaded: true,
+			mounts:       ext4,
+			probeConfig:  querygds.ProbeConfig{Dir: "/data"},
+			probeErr:     querygds.ErrNoThroughput,
+			wantHealth:   apiv1.HealthStateTypeDegraded,
+			wantReason:   "throughput probe in /data failed",
+			wantIssues:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &component{
+				ctx:          context.Background(),
+				nvmlInstance: &mockNVMLInstance{exists: true},
+				getModuleVersionFunc: func() (string, bool, error) {
+					if !tt.moduleLoaded {
+						return "", false, nil
+					}
+					return "2.20.5", true, nil
+				},
+				readCufileConfigFunc: func() (*querygds.CufileConfig, error) {
+					return tt.cufileConfig, tt.cufileErr
+				},
+				getCompatibleMountsFunc: func() ([]querygds.Mount, error) {
+					return tt.mounts, nil
+				},
+				getProbeConfigFunc: func() querygds.ProbeConfig {
+					return tt.probeConfig
+				},
+				runProbeFunc: func(ctx context.Context, cfg querygds.ProbeConfig) (*querygds.ProbeResult, error) {
+					return tt.probeResult, tt.probeErr
+				},
+				probeInterval: DefaultProbeInterval,
+				getNvfsReadOpsFunc: func() (uint64, bool, error) {
+					if len(tt.nvfsReads) == 0 {
+						return 0, false, nil
+					}
+					n := tt.nvfsReads[0]
+					tt.nvfsReads = tt.nvfsReads[1:]
+					return n, true, nil
+				},
+			}
+
+			cr := c.Check().(*checkResult)
+			assert.Equal(t, tt.wantHealth, cr.HealthStateType())
+			assert.Contains(t, cr.Summary(), tt.wantReason)
+			assert.Len(t, cr.Issues, tt.wantIssues)
+
+			states := c.LastHealthStates()
+			require.Len(t, states, 1)
+			assert.Equal(t, tt.wantHealth, states[0].Health)
+		})
+	}
+}
+
+func TestCheckProbeInterval(t *testing.T) {
+	probes := 0
+	cfg := querygds.ProbeConfig{Dir: "/data"}
+	c := &component{
+		ctx:                  context.Background(),
+		nvmlInstance:         &mockNVMLInstance{exists: true},
+		getModuleVersionFunc: func() (string, bool, error) { return "2.20.5", true, nil },
+		readCufileConfigFunc: func() (*querygds.CufileConfig, error) {
+			return &querygds.CufileConfig{AllowCompatMode: true}, nil
+		},
+		getCompatibleMountsFunc: func() ([]querygds.Mount, error) {
+			return []querygds.Mount{{MountPoint: "/data", FsType: "xfs"}}, nil
+		},
+		getProbeConfigFunc: func() querygds.ProbeConfig { return cfg },
+		runProbeFunc: func(ctx context.Context, cfg querygds.ProbeConfig) (*querygds.ProbeResult, error) {
+			probes++
+			return &querygds.ProbeResult{XferType: "GPUD", ThroughputGiBps: 3}, nil
+		},
+		probeInterval: time.Hour,
+	}
+
+	c.Check()
+	c.Check()
+	assert.Equal(t, 1, probes)
+
+	// re-run once the config changes
+	cfg.MinThroughputGiBps = 1
+	c.Check()
+	assert.Equal(t, 2, probes)
+
+	// the probe disabled
+	cfg.Dir = ""
+	cr := c.Check().(*checkResult)
+	assert.Nil(t, cr.Probe)
+	assert.Equal(t, 2, probes)
+}
+
+func TestCheckNVMLNotLoaded(t *testing.T) {
+	c := &component{
+		ctx:          context.Background(),
+		nvmlInstance: &mockNVMLInstance{exists: false},
+	}
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "NVIDIA NVML library is not loaded", cr.Summary())
+}
@@ -0,0 +1,31 @@
+package gds
+
+import (
+	"sync"
+
+	"github.com/leptonai/gpud/pkg/log"
+	querygds "github.com/leptonai/gpud/pkg/nvidia-query/gds"
+)
+
+var (
+	defaultProbeConfigMu sync.RWMutex
+	defaultProbeConfig   querygds.ProbeConfig
+)
+
+// GetDefaultProbeConfig returns the throughput probe config,
+// with the empty directory if the probe is disabled.
+func GetDefaultProbeConfig() querygds.ProbeConfig {
+	defaultProbeConfigMu.RLock()
+	defer defaultProbeConfigMu.RUnlock()
+
+	return defaultProbeConfig
+}
+
+// SetDefaultProbeConfig sets the throughput probe config.
+func SetDefaultProbeConfig(cfg querygds.ProbeConfig) {
+	log.Logger.Infow("setting default gds probe config", "dir", cfg.Dir, "gpu_index", cfg.GPUIndex, "min_throughput_gibps", cfg.MinThroughputGiBps)
+
+	defaultProbeConfigMu.Lock()
+	defer defaultProbeConfigMu.Unlock()
+	defaultProbeConfig = cfg
+}
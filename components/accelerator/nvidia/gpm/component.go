@@ -65,6 +65,8 @@ type component struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	checkInterval time.Duration
+
 	nvmlInstance        nvidianvml.Instance
 	getGPMSupportedFunc func(dev device.Device) (bool, error)
 	getGPMMetricsFunc   func(ctx context.Context, dev device.Device) (map[gonvml.GpmMetricId]float64, error)
@@ -76,8 +78,11 @@ type component struct {
 func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
 	c := &component{
-		ctx:                 cctx,
-		cancel:              ccancel,
+		ctx:    cctx,
+		cancel: ccancel,
+
+		checkInterval: gpudInstance.CheckInterval(Name),
+
 		nvmlInstance:        gpudInstance.NVMLInstance,
 		getGPMSupportedFunc: nvidianvml.GPMSupportedByDevice,
 		getGPMMetricsFunc: func(ctx2 context.Context, dev device.Device) (map[gonvml.GpmMetricId]float64, error) {
@@ -112,11 +117,11 @@ func (c *component) IsSupported() bool {
 
 func (c *component) Start() error {
 	go func() {
-		ticker := time.NewTicker(time.Minute)
+		ticker := components.NewCheckTicker(c.checkInterval)
 		defer ticker.Stop()
 
 		for {
-			_ = c.Check()
+			_ = components.SafeCheck(c)
 
 			select {
 			case <-c.ctx.Done():
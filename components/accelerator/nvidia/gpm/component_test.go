@@ -38,6 +38,10 @@ func (m *mockNVMLInstance) FabricManagerSupported() bool {
 	return true
 }
 
+func (m *mockNVMLInstance) VirtualizationMode() nvidianvml.VirtualizationMode {
+	return nvidianvml.VirtualizationModeNone
+}
+
 func (m *mockNVMLInstance) GetMemoryErrorManagementCapabilities() nvidianvml.MemoryErrorManagementCapabilities {
 	return nvidianvml.MemoryErrorManagementCapabilities{}
 }
@@ -92,6 +96,10 @@ func (m *customMockNVMLInstance) FabricManagerSupported() bool {
 	return true
 }
 
+func (m *customMockNVMLInstance) VirtualizationMode() nvidianvml.VirtualizationMode {
+	return nvidianvml.VirtualizationModeNone
+}
+
 func (m *customMockNVMLInstance) GetMemoryErrorManagementCapabilities() nvidianvml.MemoryErrorManagementCapabilities {
 	return nvidianvml.MemoryErrorManagementCapabilities{}
 }
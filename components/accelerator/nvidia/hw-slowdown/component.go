@@ -40,6 +40,12 @@ type component struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	checkInterval time.Duration
+
+	// triggerCh receives when the relevant kmsg events are matched,
+	// to re-check without waiting for the next tick
+	triggerCh <-chan struct{}
+
 	nvmlInstance                  nvidianvml.Instance
 	getClockEventsSupportedFunc   func(dev device.Device) (bool, error)
 	getClockEventsFunc            func(uuid string, dev device.Device) (nvidianvml.ClockEvents, error)
@@ -62,6 +68,10 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		ctx:    cctx,
 		cancel: ccancel,
 
+		checkInterval: gpudInstance.CheckInterval(Name),
+
+		triggerCh: gpudInstance.TriggerBus.Subscribe(components.TriggerTopicNVIDIAXid),
+
 		nvmlInstance:                gpudInstance.NVMLInstance,
 		getClockEventsSupportedFunc: nvidianvml.ClockEventsSupportedByDevice,
 		getClockEventsFunc:          nvidianvml.GetClockEvents,
@@ -110,16 +120,18 @@ func (c *component) IsSupported() bool {
 
 func (c *component) Start() error {
 	go func() {
-		ticker := time.NewTicker(time.Minute)
+		ticker := components.NewCheckTicker(c.checkInterval)
 		defer ticker.Stop()
 
 		for {
-			_ = c.Check()
+			_ = components.SafeCheck(c)
 
 			select {
 			case <-c.ctx.Done():
 				return
 			case <-ticker.C:
+			case <-c.triggerCh:
+				log.Logger.Infow("re-checking on trigger", "component", Name)
 			}
 		}
 	}()
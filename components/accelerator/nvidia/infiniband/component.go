@@ -9,12 +9,15 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
+	apiv2 "github.com/leptonai/gpud/api/v2"
 	"github.com/leptonai/gpud/components"
 	nvidia_common "github.com/leptonai/gpud/pkg/config/common"
 	"github.com/leptonai/gpud/pkg/eventstore"
@@ -22,10 +25,18 @@ import (
 	"github.com/leptonai/gpud/pkg/log"
 	"github.com/leptonai/gpud/pkg/nvidia-query/infiniband"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	"github.com/leptonai/gpud/pkg/toolexec"
 	"github.com/olekukonko/tablewriter"
 )
 
-const Name = "accelerator-nvidia-infiniband"
+const (
+	Name = "accelerator-nvidia-infiniband"
+
+	EventNamePortDrop     = "ib_port_drop"
+	EventNamePortFlap     = "ib_port_flap"
+	EventNamePortErrors   = "ib_port_errors"
+	EventNamePortMismatch = "ib_port_mismatch"
+)
 
 var _ components.Component = &component{}
 
@@ -33,30 +44,70 @@ type component struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	checkInterval time.Duration
+
 	nvmlInstance   nvidianvml.Instance
 	toolOverwrites nvidia_common.ToolOverwrites
 
 	eventBucket eventstore.Bucket
 	kmsgSyncer  *kmsg.Syncer
 
+	// getSysfsOutputFunc reads the port states from sysfs,
+	// without the "ibstat" and "ibstatus" commands
+	getSysfsOutputFunc    func() (*infiniband.IbstatOutput, error)
 	getIbstatOutputFunc   func(ctx context.Context, ibstatCommands []string) (*infiniband.IbstatOutput, error)
 	getIbstatusOutputFunc func(ctx context.Context, ibstatusCommands []string) (*infiniband.IbstatusOutput, error)
 	getThresholdsFunc     func() infiniband.ExpectedPortStates
+	getPeersFunc          func(ctx context.Context, saqueryCommand string, cards infiniband.IBStatCards) ([]infiniband.IBPeer, error)
+
+	// peersMu protects the last known peer of each port device,
+	// kept while the port is up, since the subnet administrator
+	// no longer has the link record once the port goes down
+	peersMu sync.Mutex
+	peers   map[string]infiniband.IBPeer
+	// peersUpdatedAt is the last time the peers were queried,
+	// to not query the whole fabric on every check
+	peersUpdatedAt time.Time
+
+	getEvaluationConfigFunc func() infiniband.EvaluationConfig
+	// portHistory tracks the port state transitions across the checks
+	portHistory portHistory
+
+	getPortCountersFunc func() ([]infiniband.PortCounters, error)
+	// portCounters tracks the port error counters across the checks
+	portCounters portCounters
+
+	nowFunc func() time.Time
 
 	lastMu          sync.RWMutex
 	lastCheckResult *checkResult
 }
 
 func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
-	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+	// the tools (e.g., "ibstat") run with the resource limits of the component
+	cctx, ccancel := context.WithCancel(toolexec.WithComponent(gpudInstance.RootCtx, Name))
+	classDir := getDefaultClassDir()
 	c := &component{
-		ctx:                   cctx,
-		cancel:                ccancel,
+		ctx:    cctx,
+		cancel: ccancel,
+
+		checkInterval: gpudInstance.CheckInterval(Name),
+
 		nvmlInstance:          gpudInstance.NVMLInstance,
-		toolOverwrites:        gpudInstance.NVIDIAToolOverwrites,
+		toolOverwrites:        gpudInstance.NVIDIAToolOverwrites.WithDefaults(),
 		getIbstatOutputFunc:   infiniband.GetIbstatOutput,
 		getIbstatusOutputFunc: infiniband.GetIbstatusOutput,
 		getThresholdsFunc:     GetDefaultExpectedPortStates,
+		getPeersFunc:          infiniband.GetPeers,
+		peers:                 make(map[string]infiniband.IBPeer),
+
+		getEvaluationConfigFunc: GetDefaultEvaluationConfig,
+		getPortCountersFunc: func() ([]infiniband.PortCounters, error) {
+			return infiniband.GetPortCounters(classDir)
+		},
+		getSysfsOutputFunc: func() (*infiniband.IbstatOutput, error) {
+			return infiniband.GetSysfsOutput(classDir)
+		},
 	}
 
 	if gpudInstance.EventStore != nil {
@@ -68,7 +119,7 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		}
 
 		if os.Geteuid() == 0 {
-			c.kmsgSyncer, err = kmsg.NewSyncer(cctx, Match, c.eventBucket)
+			c.kmsgSyncer, err = kmsg.NewSyncer(cctx, Match, c.eventBucket, kmsg.WithComponent(Name))
 			if err != nil {
 				ccancel()
 				return nil, err
@@ -99,11 +150,11 @@ func (c *component) IsSupported() bool {
 
 func (c *component) Start() error {
 	go func() {
-		ticker := time.NewTicker(time.Minute)
+		ticker := components.NewCheckTicker(c.checkInterval)
 		defer ticker.Stop()
 
 		for {
-			_ = c.Check()
+			_ = components.SafeCheck(c)
 
 			select {
 			case <-c.ctx.Done():
@@ -122,6 +173,15 @@ func (c *component) LastHealthStates() apiv1.HealthStates {
 	return lastCheckResult.HealthStates()
 }
 
+var _ components.TypedPayloader = &component{}
+
+func (c *component) LastHealthStatesWithPayload() (apiv1.HealthStates, apiv2.Payload) {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates(), lastCheckResult.Payload()
+}
+
 func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
 	if c.eventBucket == nil {
 		return nil, nil
@@ -151,8 +211,12 @@ func (c *component) Close() error {
 func (c *component) Check() components.CheckResult {
 	log.Logger.Infow("checking nvidia gpu infiniband")
 
+	now := time.Now
+	if c.nowFunc != nil {
+		now = c.nowFunc
+	}
 	cr := &checkResult{
-		ts: time.Now().UTC(),
+		ts: now().UTC(),
 	}
 	defer func() {
 		c.lastMu.Lock()
@@ -190,32 +254,41 @@ func (c *component) Check() components.CheckResult {
 		return cr
 	}
 
-	cctx, ccancel := context.WithTimeout(c.ctx, 15*time.Second)
-	cr.IbstatusOutput, cr.errIbstatus = c.getIbstatusOutputFunc(cctx, []string{c.toolOverwrites.IbstatusCommand})
-	ccancel()
-	if cr.errIbstatus != nil {
-		// this fallback is only used when the "ibstat" command fails
-		// then we don't care if this fallback "ibstatus" command fails
-		// as long as the following "ibstat" command succeeds
-		log.Logger.Warnw("ibstatus command failed", "error", cr.errIbstatus)
-	}
-
-	// "ibstat" may fail if there's a port device that is wrongly mapped (e.g., exit 255)
-	// but can still return the partial output with the correct data
-	// if there's any partial data, we should use it
-	// and only fallback to "ibstatus" if there's no data from "ibstat"
-	cctx, ccancel = context.WithTimeout(c.ctx, 15*time.Second)
-	cr.IbstatOutput, cr.err = c.getIbstatOutputFunc(cctx, []string{c.toolOverwrites.IbstatCommand})
-	ccancel()
+	// read the port states directly from sysfs, so that the hosts without
+	// the MOFED user-space tools are still checked, and only fall back to
+	// the "ibstat" and "ibstatus" commands if sysfs is unavailable
+	c.readSysfs(cr)
+
+	var cctx context.Context
+	var ccancel context.CancelFunc
+	if cr.Source != sourceSysfs {
+		cctx, ccancel = context.WithTimeout(c.ctx, 15*time.Second)
+		cr.IbstatusOutput, cr.errIbstatus = c.getIbstatusOutputFunc(cctx, []string{c.toolOverwrites.IbstatusCommand})
+		ccancel()
+		if cr.errIbstatus != nil {
+			// this fallback is only used when the "ibstat" command fails
+			// then we don't care if this fallback "ibstatus" command fails
+			// as long as the following "ibstat" command succeeds
+			log.Logger.Warnw("ibstatus command failed", "error", cr.errIbstatus)
+		}
 
-	if cr.err != nil {
-		if errors.Is(cr.err, infiniband.ErrNoIbstatCommand) {
-			cr.health = apiv1.HealthStateTypeHealthy
-			cr.reason = "ibstat command not found"
-		} else {
-			cr.health = apiv1.HealthStateTypeUnhealthy
-			cr.reason = "ibstat command failed"
-			log.Logger.Errorw(cr.reason, "error", cr.err)
+		// "ibstat" may fail if there's a port device that is wrongly mapped (e.g., exit 255)
+		// but can still return the partial output with the correct data
+		// if there's any partial data, we should use it
+		// and only fallback to "ibstatus" if there's no data from "ibstat"
+		cctx, ccancel = context.WithTimeout(c.ctx, 15*time.Second)
+		cr.IbstatOutput, cr.err = c.getIbstatOutputFunc(cctx, []string{c.toolOverwrites.IbstatCommand})
+		ccancel()
+
+		if cr.err != nil {
+			if errors.Is(cr.err, infiniband.ErrNoIbstatCommand) {
+				cr.health = apiv1.HealthStateTypeHealthy
+				cr.reason = "ibstat command not found"
+			} else {
+				cr.health = apiv1.HealthStateTypeUnhealthy
+				cr.reason = "ibstat command failed"
+				log.Logger.Errorw(cr.reason, "error", cr.err)
+			}
 		}
 	}
 
@@ -246,6 +319,11 @@ func (c *component) Check() components.CheckResult {
 		// which means we may overwrite the error above
 		// (e.g., "ibstat" command exited 255 but still meets the thresholds)
 		cr.health, cr.suggestedActions, cr.reason = evaluateIbstatOutputAgainstThresholds(cr.IbstatOutput, thresholds)
+		if cr.health != apiv1.HealthStateTypeHealthy {
+			cr.failureCodes = portFailureCodes(cr.IbstatOutput.Parsed.IBPorts(), thresholds)
+		} else if cr.Source == sourceSysfs && cr.reason == reasonNoIbIssueFoundFromIbstat {
+			cr.reason = reasonNoIbIssueFoundFromSysfs
+		}
 
 		// partial output from "ibstat" command worked
 		if cr.err != nil && cr.health == apiv1.HealthStateTypeHealthy {
@@ -257,6 +335,54 @@ func (c *component) Check() components.CheckResult {
 		// ibstat command failed and no output
 		// then we need fallback to the second data source "ibstatus"
 		cr.health, cr.suggestedActions, cr.reason = evaluateIbstatusOutputAgainstThresholds(cr.IbstatusOutput, thresholds)
+		if cr.health != apiv1.HealthStateTypeHealthy {
+			cr.failureCodes = portFailureCodes(cr.IbstatusOutput.Parsed.IBPorts(), thresholds)
+		}
+	}
+
+	// the per-device expectations pinpoint the specific mismatched ports,
+	// even when the aggregate number of ports and rate are met,
+	// and are reported on top of the aggregate issue otherwise
+	if len(thresholds.Devices) > 0 {
+		var ports []infiniband.IBPort
+		if cr.IbstatOutput != nil {
+			ports = cr.IbstatOutput.Parsed.IBPorts()
+		} else if cr.IbstatusOutput != nil {
+			ports = cr.IbstatusOutput.Parsed.IBPorts()
+		}
+		cr.DeviceMismatches = thresholds.CheckDevices(ports)
+		if len(cr.DeviceMismatches) > 0 {
+			if cr.health == apiv1.HealthStateTypeHealthy {
+				cr.setPortIssue(apiv1.HealthStateTypeUnhealthy, reasonDevicesMismatched, describePorts(cr.DeviceMismatches), mismatchFailureCode(cr.DeviceMismatches), EventNamePortMismatch, suggestedActionsForPortMismatch)
+			} else {
+				cr.addPortIssue(reasonDevicesMismatched, describePorts(cr.DeviceMismatches), mismatchFailureCode(cr.DeviceMismatches))
+			}
+		}
+	}
+
+	if cr.IbstatOutput != nil {
+		c.updatePeers(cr.IbstatOutput.Parsed)
+		cr.Peers = c.getPeers(cr.IbstatOutput.Parsed)
+	}
+
+	// the port states take precedence over the port history,
+	// which flags the ports dropped (down for too long) or flapping (repeatedly down)
+	// even when the current snapshot meets the thresholds
+	if cr.IbstatOutput != nil {
+		cr.DroppedPorts, cr.FlappingPorts = c.checkPortHistory(cr.IbstatOutput.Parsed, cr.ts)
+		if len(cr.DroppedPorts) > 0 && cr.health == apiv1.HealthStateTypeHealthy {
+			cr.setPortIssue(apiv1.HealthStateTypeUnhealthy, reasonPortsDropped, describePorts(cr.DroppedPorts), apiv1.FailureCodeIBPortDown, EventNamePortDrop, suggestedActionsForPortDrop)
+		}
+		if len(cr.FlappingPorts) > 0 && cr.health == apiv1.HealthStateTypeHealthy {
+			cr.setPortIssue(apiv1.HealthStateTypeDegraded, reasonPortsFlapping, describePorts(cr.FlappingPorts), apiv1.FailureCodeIBPortFlap, EventNamePortFlap, suggestedActionsForPortFlap)
+		}
+	}
+
+	// the error counters increasing across the checks flag the slowly degrading links,
+	// while the ports are still up and meet the thresholds
+	cr.Counters, cr.CounterDeltas = c.checkPortCounters(cr.ts)
+	if len(cr.CounterDeltas) > 0 && cr.health == apiv1.HealthStateTypeHealthy {
+		cr.setPortIssue(apiv1.HealthStateTypeDegraded, reasonPortErrorsIncreasing, describePorts(cr.CounterDeltas), apiv1.FailureCodeIBPortErrors, EventNamePortErrors, suggestedActionsForPortErrors)
 	}
 
 	// we only care about unhealthy events, no need to persist healthy events
@@ -275,6 +401,17 @@ func (c *component) Check() components.CheckResult {
 		Type:    string(apiv1.EventTypeWarning),
 		Message: cr.reason,
 	}
+	if cr.eventName != "" {
+		ev.Name = cr.eventName
+	}
+	if cr.IbstatOutput != nil {
+		// include the last known peers of the ports that are not up,
+		// to trace them to the specific switch ports or cables
+		if peers := c.getPeers(downPorts(cr.IbstatOutput.Parsed)); len(peers) > 0 {
+			b, _ := json.Marshal(peers)
+			ev.ExtraInfo = map[string]string{EventKeyPeers: string(b)}
+		}
+	}
 
 	// lookup to prevent duplicate event insertions
 	cctx, ccancel = context.WithTimeout(c.ctx, 15*time.Second)
@@ -307,6 +444,141 @@ func (c *component) Check() components.CheckResult {
 	return cr
 }
 
+// readSysfs reads the port states from sysfs, unless the "ibstat" command is
+// overwritten (e.g., the mocked "ibstat" for testing) or no device is found in sysfs.
+func (c *component) readSysfs(cr *checkResult) {
+	if c.getSysfsOutputFunc == nil {
+		return
+	}
+	if cmd := c.toolOverwrites.IbstatCommand; cmd != "" && cmd != "ibstat" {
+		return
+	}
+
+	out, err := c.getSysfsOutputFunc()
+	if err != nil {
+		if !errors.Is(err, infiniband.ErrNoSysfsDevice) {
+			log.Logger.Warnw("failed to read infiniband ports from sysfs -- falling back to ibstat", "error", err)
+		}
+		return
+	}
+	cr.IbstatOutput = out
+	cr.Source = sourceSysfs
+}
+
+// checkPortHistory records the port states, and returns the dropped and the flapping ports.
+func (c *component) checkPortHistory(cards infiniband.IBStatCards, now time.Time) ([]PortDrop, []PortFlap) {
+	cfg := infiniband.EvaluationConfig{}.WithDefaults()
+	if c.getEvaluationConfigFunc != nil {
+		cfg = c.getEvaluationConfigFunc().WithDefaults()
+	}
+	c.portHistory.observe(cards, now, cfg.FlapWindow.Duration)
+	return c.portHistory.evaluate(now, cfg)
+}
+
+// checkPortCounters reads the port error counters, and returns the counters
+// and the counter increases at or beyond the thresholds within the counter window.
+func (c *component) checkPortCounters(now time.Time) ([]infiniband.PortCounters, []infiniband.CounterDelta) {
+	if c.getPortCountersFunc == nil {
+		return nil, nil
+	}
+	counters, err := c.getPortCountersFunc()
+	if err != nil {
+		log.Logger.Warnw("failed to read infiniband port counters", "error", err)
+		return nil, nil
+	}
+
+	cfg := infiniband.EvaluationConfig{}.WithDefaults()
+	if c.getEvaluationConfigFunc != nil {
+		cfg = c.getEvaluationConfigFunc().WithDefaults()
+	}
+	return counters, c.portCounters.observe(now, counters, cfg.CounterWindow.Duration, cfg.CounterDeltas)
+}
+
+// EventKeyPeers is the event extra info key for the
+// JSON-encoded peers (infiniband.IBPeer) of the down ports.
+const EventKeyPeers = "peers"
+
+// DefaultPeersRefreshInterval is the interval to query the peers from the subnet administrator,
+// since the link and node records of the whole fabric are queried at once.
+const DefaultPeersRefreshInterval = 10 * time.Minute
+
+// updatePeers refreshes the last known peers with the subnet administrator records,
+// at most every DefaultPeersRefreshInterval unless an up port has no known peer yet.
+// The peers of the ports without the link record (e.g., down ports) are kept.
+func (c *component) updatePeers(cards infiniband.IBStatCards) {
+	if c.getPeersFunc == nil || len(cards) == 0 {
+		return
+	}
+	if !c.shouldRefreshPeers(cards, time.Now()) {
+		return
+	}
+
+	cctx, ccancel := context.WithTimeout(c.ctx, 15*time.Second)
+	peers, err := c.getPeersFunc(cctx, c.toolOverwrites.SaqueryCommand, cards)
+	ccancel()
+	if err != nil {
+		if !errors.Is(err, infiniband.ErrNoSaqueryCommand) {
+			log.Logger.Warnw("failed to get infiniband port peers", "error", err)
+		}
+		return
+	}
+
+	c.peersMu.Lock()
+	defer c.peersMu.Unlock()
+	if c.peers == nil {
+		c.peers = make(map[string]infiniband.IBPeer)
+	}
+	for _, peer := range peers {
+		c.peers[peer.Device] = peer
+	}
+	c.peersUpdatedAt = time.Now()
+}
+
+// shouldRefreshPeers returns true if the peers were not queried within the refresh interval,
+// or if any up port has no known peer (e.g., a new port, the first check).
+func (c *component) shouldRefreshPeers(cards infiniband.IBStatCards, now time.Time) bool {
+	c.peersMu.Lock()
+	defer c.peersMu.Unlock()
+
+	if c.peersUpdatedAt.IsZero() || now.Sub(c.peersUpdatedAt) >= DefaultPeersRefreshInterval {
+		return true
+	}
+	for _, card := range cards {
+		if card.Port1.PhysicalState != "LinkUp" || card.Port1.BaseLid == 0 {
+			continue
+		}
+		if _, ok := c.peers[card.Device]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// getPeers returns the last known peers of the port devices, if any.
+func (c *component) getPeers(cards infiniband.IBStatCards) []infiniband.IBPeer {
+	c.peersMu.Lock()
+	defer c.peersMu.Unlock()
+
+	var peers []infiniband.IBPeer
+	for _, card := range cards {
+		if peer, ok := c.peers[card.Device]; ok {
+			peers = append(peers, peer)
+		}
+	}
+	return peers
+}
+
+// downPorts returns the cards whose port is not in the "LinkUp" physical state.
+func downPorts(cards infiniband.IBStatCards) infiniband.IBStatCards {
+	var down infiniband.IBStatCards
+	for _, card := range cards {
+		if card.Port1.PhysicalState != "LinkUp" {
+			down = append(down, card)
+		}
+	}
+	return down
+}
+
 var (
 	// nothing specified for this machine, gpud MUST skip the ib check
 	reasonThresholdNotSetSkipped = "ports or rate threshold not set, skipping"
@@ -315,8 +587,34 @@ var (
 	reasonMissingEventBucket          = "missing event storage (skipped evaluation)"
 	reasonNoIbIssueFoundFromIbstat    = "no infiniband issue found (in ibstat)"
 	reasonNoIbIssueFoundFromIbstatus  = "no infiniband issue found (in ibstatus)"
+	reasonNoIbIssueFoundFromSysfs     = "no infiniband issue found (in sysfs)"
+	reasonPortsDropped                = "infiniband port(s) dropped"
+	reasonPortsFlapping               = "infiniband port(s) flapping"
+	reasonPortErrorsIncreasing        = "infiniband port error counter(s) increasing"
+	reasonDevicesMismatched           = "infiniband port(s) not in the expected state"
 )
 
+// mismatchFailureCode returns the rate degraded if only the rates are mismatched,
+// otherwise the port down (e.g., the device not found, the port not active).
+func mismatchFailureCode(mismatches []infiniband.DeviceMismatch) apiv1.FailureCode {
+	for _, m := range mismatches {
+		if m.Field != infiniband.MismatchFieldRate {
+			return apiv1.FailureCodeIBPortDown
+		}
+	}
+	return apiv1.FailureCodeIBPortRateDegraded
+}
+
+// portFailureCodes returns the failure code of the ports not meeting the thresholds,
+// the rate degraded if enough ports are up but at the lower rate, otherwise the port down.
+func portFailureCodes(ports []infiniband.IBPort, thresholds infiniband.ExpectedPortStates) []apiv1.FailureCode {
+	_, up := infiniband.CheckPortsAndRate(ports, []string{"LinkUp"}, "", 0)
+	if thresholds.AtLeastRate > 0 && len(up) >= thresholds.AtLeastPorts {
+		return []apiv1.FailureCode{apiv1.FailureCodeIBPortRateDegraded}
+	}
+	return []apiv1.FailureCode{apiv1.FailureCodeIBPortDown}
+}
+
 // Returns the output evaluation reason and its health state.
 // We DO NOT auto-detect infiniband devices/PCI buses, strictly rely on the user-specified config.
 func evaluateIbstatOutputAgainstThresholds(ibstatOut *infiniband.IbstatOutput, thresholds infiniband.ExpectedPortStates) (apiv1.HealthStateType, *apiv1.SuggestedActions, string) {
@@ -361,9 +659,27 @@ func evaluateIbstatusOutputAgainstThresholds(ibstatusOut *infiniband.IbstatusOut
 
 var _ components.CheckResult = &checkResult{}
 
+// sourceSysfs is the source of the port states read from sysfs.
+const sourceSysfs = "sysfs"
+
 type checkResult struct {
 	IbstatOutput   *infiniband.IbstatOutput   `json:"ibstat_output"`
 	IbstatusOutput *infiniband.IbstatusOutput `json:"ibstatus_output"`
+	// Source is "sysfs" if the port states in the ibstat output are read
+	// from sysfs, empty if from the "ibstat" or "ibstatus" commands.
+	Source string `json:"source,omitempty"`
+	// DroppedPorts are the ports down for the drop duration or longer.
+	DroppedPorts []PortDrop `json:"dropped_ports,omitempty"`
+	// FlappingPorts are the ports repeatedly going down and back up within the flap window.
+	FlappingPorts []PortFlap `json:"flapping_ports,omitempty"`
+	// DeviceMismatches are the ports of the expected devices not in the expected states.
+	DeviceMismatches []infiniband.DeviceMismatch `json:"device_mismatches,omitempty"`
+	// Peers are the last known peers (e.g., the switch ports) of the IB ports.
+	Peers []infiniband.IBPeer `json:"peers,omitempty"`
+	// Counters are the error counters of the IB ports.
+	Counters []infiniband.PortCounters `json:"counters,omitempty"`
+	// CounterDeltas are the error counters increased at or beyond the thresholds within the counter window.
+	CounterDeltas []infiniband.CounterDelta `json:"counter_deltas,omitempty"`
 
 	// timestamp of the last check
 	ts time.Time
@@ -376,8 +692,64 @@ type checkResult struct {
 	health apiv1.HealthStateType
 	// tracks the suggested actions for the last check
 	suggestedActions *apiv1.SuggestedActions
+	// tracks the failure codes of the last check, if unhealthy
+	failureCodes []apiv1.FailureCode
 	// tracks the reason of the last check
 	reason string
+	// tracks the event name of the last check, if not the default "ibstat"
+	eventName string
+}
+
+var (
+	// the port down for too long is likely the failed adapter or switch port
+	suggestedActionsForPortDrop = &apiv1.SuggestedActions{
+		Description: "inspect the infiniband adapter and the switch port of the dropped port",
+		RepairActions: []apiv1.RepairActionType{
+			apiv1.RepairActionTypeHardwareInspection,
+		},
+	}
+	// the port repeatedly going down and back up is likely the loose or faulty cable
+	suggestedActionsForPortFlap = &apiv1.SuggestedActions{
+		Description: "check the cable and the transceiver of the flapping port",
+		RepairActions: []apiv1.RepairActionType{
+			apiv1.RepairActionTypeCheckCabling,
+		},
+	}
+	// the port not in the expected state of the device is likely the failed adapter,
+	// or the cable or the switch port negotiating the lower rate
+	suggestedActionsForPortMismatch = &apiv1.SuggestedActions{
+		Description: "inspect the infiniband adapter, the cable, and the switch port of the mismatched device",
+		RepairActions: []apiv1.RepairActionType{
+			apiv1.RepairActionTypeHardwareInspection,
+		},
+	}
+	// the increasing error counters are likely the dirty or degrading cable or transceiver
+	suggestedActionsForPortErrors = &apiv1.SuggestedActions{
+		Description: "clean or replace the cable and the transceiver of the port with the increasing error counters",
+		RepairActions: []apiv1.RepairActionType{
+			apiv1.RepairActionTypeCheckCabling,
+		},
+	}
+)
+
+// setPortIssue sets the health state of the port issues found beyond the port states.
+func (cr *checkResult) setPortIssue(health apiv1.HealthStateType, reason string, issues []string, code apiv1.FailureCode, eventName string, actions *apiv1.SuggestedActions) {
+	cr.health = health
+	cr.reason = reason + ": " + strings.Join(issues, "; ")
+	cr.failureCodes = []apiv1.FailureCode{code}
+	cr.suggestedActions = actions
+	cr.eventName = eventName
+	log.Logger.Warnw(cr.reason)
+}
+
+// addPortIssue appends the issue to the reason and the failure codes of the
+// already unhealthy result, keeping its health, event, and suggested actions.
+func (cr *checkResult) addPortIssue(reason string, issues []string, code apiv1.FailureCode) {
+	cr.reason += "; " + reason + ": " + strings.Join(issues, "; ")
+	if !slices.Contains(cr.failureCodes, code) {
+		cr.failureCodes = append(cr.failureCodes, code)
+	}
+	log.Logger.Warnw(cr.reason)
 }
 
 func (cr *checkResult) ComponentName() string {
@@ -412,6 +784,46 @@ func (cr *checkResult) String() string {
 		out += buf.String() + "\n\n"
 	}
 
+	if len(cr.Peers) > 0 {
+		buf := bytes.NewBuffer(nil)
+		table := tablewriter.NewWriter(buf)
+		table.SetAlignment(tablewriter.ALIGN_CENTER)
+		table.SetHeader([]string{"Port Device Name", "LID", "Peer LID", "Peer Port", "Peer GUID", "Peer Description"})
+		for _, peer := range cr.Peers {
+			table.Append([]string{
+				peer.Device,
+				fmt.Sprintf("%d", peer.LID),
+				fmt.Sprintf("%d", peer.PeerLID),
+				fmt.Sprintf("%d", peer.PeerPort),
+				peer.PeerGUID,
+				peer.PeerDescription,
+			})
+		}
+		table.Render()
+
+		out += buf.String() + "\n\n"
+	}
+
+	if len(cr.Counters) > 0 {
+		buf := bytes.NewBuffer(nil)
+		table := tablewriter.NewWriter(buf)
+		table.SetAlignment(tablewriter.ALIGN_CENTER)
+		table.SetHeader([]string{"Port Device Name", "Port", "Symbol Errors", "Link Downed", "Port Rcv Errors", "Excessive Buffer Overruns"})
+		for _, pc := range cr.Counters {
+			table.Append([]string{
+				pc.Device,
+				fmt.Sprintf("%d", pc.Port),
+				fmt.Sprintf("%d", pc.SymbolError),
+				fmt.Sprintf("%d", pc.LinkDowned),
+				fmt.Sprintf("%d", pc.PortRcvErrors),
+				fmt.Sprintf("%d", pc.ExcessiveBufferOverrunErrors),
+			})
+		}
+		table.Render()
+
+		out += buf.String() + "\n\n"
+	}
+
 	if cr.IbstatusOutput != nil {
 		buf := bytes.NewBuffer(nil)
 		table := tablewriter.NewWriter(buf)
@@ -469,6 +881,38 @@ func (cr *checkResult) getError() string {
 	return ""
 }
 
+// Payload returns the typed payload of the check result,
+// or nil if no "ibstat" output is available.
+func (cr *checkResult) Payload() apiv2.Payload {
+	if cr == nil || cr.IbstatOutput == nil {
+		return nil
+	}
+
+	p := &apiv2.IBPortsPayload{Ports: make([]apiv2.IBPort, 0, len(cr.IbstatOutput.Parsed))}
+	for _, card := range cr.IbstatOutput.Parsed {
+		p.Ports = append(p.Ports, apiv2.IBPort{
+			Device:          card.Device,
+			Port:            1,
+			State:           card.Port1.State,
+			PhysicalState:   card.Port1.PhysicalState,
+			RateGbPerSec:    card.Port1.Rate,
+			LinkLayer:       card.Port1.LinkLayer,
+			FirmwareVersion: card.FirmwareVersion,
+		})
+	}
+	for _, peer := range cr.Peers {
+		p.Peers = append(p.Peers, apiv2.IBPeer{
+			Device:          peer.Device,
+			LID:             peer.LID,
+			PeerLID:         peer.PeerLID,
+			PeerPort:        peer.PeerPort,
+			PeerGUID:        peer.PeerGUID,
+			PeerDescription: peer.PeerDescription,
+		})
+	}
+	return p
+}
+
 func (cr *checkResult) HealthStates() apiv1.HealthStates {
 	if cr == nil {
 		return apiv1.HealthStates{
@@ -488,6 +932,7 @@ func (cr *checkResult) HealthStates() apiv1.HealthStates {
 		Name:             Name,
 		Reason:           cr.reason,
 		SuggestedActions: cr.getSuggestedActions(),
+		FailureCodes:     cr.failureCodes,
 		Error:            cr.getError(),
 		Health:           cr.health,
 	}
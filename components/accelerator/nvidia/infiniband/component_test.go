@@ -2,6 +2,7 @@ package infiniband
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -16,6 +17,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
+	apiv2 "github.com/leptonai/gpud/api/v2"
 	"github.com/leptonai/gpud/components"
 	nvidia_common "github.com/leptonai/gpud/pkg/config/common"
 	"github.com/leptonai/gpud/pkg/eventstore"
@@ -359,6 +361,26 @@ func TestDefaultExpectedPortStates(t *testing.T) {
 	assert.Equal(t, newStates.AtLeastRate, updated.AtLeastRate)
 }
 
+func TestPortFailureCodes(t *testing.T) {
+	ports := []infiniband.IBPort{
+		{Device: "mlx5_0", State: "Active", PhysicalState: "LinkUp", Rate: 200},
+		{Device: "mlx5_1", State: "Active", PhysicalState: "LinkUp", Rate: 200},
+		{Device: "mlx5_2", State: "Down", PhysicalState: "Disabled", Rate: 400},
+	}
+
+	// not enough ports up
+	assert.Equal(t,
+		[]apiv1.FailureCode{apiv1.FailureCodeIBPortDown},
+		portFailureCodes(ports, infiniband.ExpectedPortStates{AtLeastPorts: 3, AtLeastRate: 200}),
+	)
+
+	// enough ports up, but at the lower rate
+	assert.Equal(t,
+		[]apiv1.FailureCode{apiv1.FailureCodeIBPortRateDegraded},
+		portFailureCodes(ports, infiniband.ExpectedPortStates{AtLeastPorts: 2, AtLeastRate: 400}),
+	)
+}
+
 func TestEvaluateWithTestData(t *testing.T) {
 	// Read the test data file
 	testDataPath := filepath.Join("testdata", "ibstat.47.0.h100.all.active.1")
@@ -793,6 +815,10 @@ func (m *mockNVMLInstance) FabricManagerSupported() bool {
 	return true
 }
 
+func (m *mockNVMLInstance) VirtualizationMode() nvidianvml.VirtualizationMode {
+	return nvidianvml.VirtualizationModeNone
+}
+
 func (m *mockNVMLInstance) GetMemoryErrorManagementCapabilities() nvidianvml.MemoryErrorManagementCapabilities {
 	return nvidianvml.MemoryErrorManagementCapabilities{}
 }
@@ -1543,6 +1569,47 @@ func TestCheckResultWithIbstatusOutput(t *testing.T) {
 	assert.Contains(t, result, "LINK LAYER")
 }
 
+func TestCheckResultPayload(t *testing.T) {
+	t.Parallel()
+
+	var cr *checkResult
+	assert.Nil(t, cr.Payload())
+	assert.Nil(t, (&checkResult{}).Payload())
+
+	cr = &checkResult{
+		IbstatOutput: &infiniband.IbstatOutput{
+			Parsed: infiniband.IBStatCards{
+				{
+					Device:          "mlx5_0",
+					FirmwareVersion: "28.39.1002",
+					Port1: infiniband.IBStatPort{
+						State:         "Down",
+						PhysicalState: "Disabled",
+						Rate:          400,
+						LinkLayer:     "InfiniBand",
+					},
+				},
+			},
+		},
+		Peers: []infiniband.IBPeer{
+			{Device: "mlx5_0", LID: 10, PeerLID: 1, PeerPort: 7, PeerDescription: "ib-switch-01"},
+		},
+	}
+	p, ok := cr.Payload().(*apiv2.IBPortsPayload)
+	require.True(t, ok)
+	assert.Equal(t, []apiv2.IBPort{
+		{Device: "mlx5_0", Port: 1, State: "Down", PhysicalState: "Disabled", RateGbPerSec: 400, LinkLayer: "InfiniBand", FirmwareVersion: "28.39.1002"},
+	}, p.Ports)
+	assert.Equal(t, []apiv2.IBPeer{
+		{Device: "mlx5_0", LID: 10, PeerLID: 1, PeerPort: 7, PeerDescription: "ib-switch-01"},
+	}, p.Peers)
+
+	c := &component{lastCheckResult: cr}
+	states, payload := c.LastHealthStatesWithPayload()
+	assert.Equal(t, cr.HealthStates(), states)
+	assert.Equal(t, p, payload)
+}
+
 // Test checkResult methods directly to increase method-level coverage
 func TestCheckResultMethodsDirectCoverage(t *testing.T) {
 	t.Parallel()
@@ -2285,3 +2352,210 @@ func TestCheckWithPartialIbstatOutput(t *testing.T) {
 	assert.Equal(t, apiv1.HealthStateTypeHealthy, result.health)
 	assert.Equal(t, reasonNoIbIssueFoundFromIbstat, result.reason)
 }
+
+func TestCheckUnhealthyEventWithLastKnownPeers(t *testing.T) {
+	t.Parallel()
+
+	cctx, ccancel := context.WithCancel(context.Background())
+	defer ccancel()
+
+	mockBucket := createMockEventBucket()
+
+	peer := infiniband.IBPeer{
+		Device:          "mlx5_0",
+		LID:             1369,
+		PeerLID:         83,
+		PeerPort:        17,
+		PeerGUID:        "0xfc6a1c0300a1b2c3",
+		PeerDescription: "MF0;ib-leaf-01:MQM9700/U1",
+	}
+
+	var mu sync.Mutex
+	portUp := true
+	c := &component{
+		ctx:         cctx,
+		cancel:      ccancel,
+		eventBucket: mockBucket,
+		nvmlInstance: &mockNVMLInstance{
+			exists:      true,
+			productName: "Tesla V100",
+		},
+		getIbstatOutputFunc: func(ctx context.Context, ibstatCommands []string) (*infiniband.IbstatOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			port := infiniband.IBStatPort{State: "Active", PhysicalState: "LinkUp", Rate: 400, BaseLid: 1369}
+			if !portUp {
+				port = infiniband.IBStatPort{State: "Down", PhysicalState: "Disabled", Rate: 400}
+			}
+			return &infiniband.IbstatOutput{
+				Parsed: infiniband.IBStatCards{{Device: "mlx5_0", Port1: port}},
+			}, nil
+		},
+		getIbstatusOutputFunc: mockGetIbstatusOutput,
+		getThresholdsFunc: func() infiniband.ExpectedPortStates {
+			return infiniband.ExpectedPortStates{AtLeastPorts: 1, AtLeastRate: 400}
+		},
+		getPeersFunc: func(ctx context.Context, saqueryCommand string, cards infiniband.IBStatCards) ([]infiniband.IBPeer, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if !portUp {
+				// no link record once the port is down
+				return nil, nil
+			}
+			return []infiniband.IBPeer{peer}, nil
+		},
+	}
+
+	data := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, data.health)
+	assert.Equal(t, []infiniband.IBPeer{peer}, data.Peers)
+	assert.Contains(t, data.String(), "MF0;ib-leaf-01:MQM9700/U1")
+
+	mu.Lock()
+	portUp = false
+	mu.Unlock()
+
+	data = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, data.health)
+	assert.Equal(t, []infiniband.IBPeer{peer}, data.Peers)
+
+	require.Len(t, mockBucket.events, 1)
+	var peers []infiniband.IBPeer
+	require.NoError(t, json.Unmarshal([]byte(mockBucket.events[0].ExtraInfo[EventKeyPeers]), &peers))
+	assert.Equal(t, []infiniband.IBPeer{peer}, peers)
+}
+
+func TestShouldRefreshPeers(t *testing.T) {
+	up := infiniband.IBStatCards{{Device: "mlx5_0", Port1: infiniband.IBStatPort{PhysicalState: "LinkUp", BaseLid: 1}}}
+	down := infiniband.IBStatCards{{Device: "mlx5_1", Port1: infiniband.IBStatPort{PhysicalState: "Disabled"}}}
+	now := time.Now()
+
+	c := &component{peers: make(map[string]infiniband.IBPeer)}
+	assert.True(t, c.shouldRefreshPeers(up, now), "never queried")
+
+	c.peersUpdatedAt = now
+	assert.True(t, c.shouldRefreshPeers(up, now), "up port without the peer")
+	assert.False(t, c.shouldRefreshPeers(down, now), "down port without the peer")
+
+	c.peers["mlx5_0"] = infiniband.IBPeer{Device: "mlx5_0"}
+	assert.False(t, c.shouldRefreshPeers(up, now.Add(time.Minute)))
+	assert.True(t, c.shouldRefreshPeers(up, now.Add(DefaultPeersRefreshInterval)))
+}
+
+func TestCheckDeviceMismatches(t *testing.T) {
+	t.Parallel()
+
+	cctx, ccancel := context.WithCancel(context.Background())
+	defer ccancel()
+
+	mockBucket := createMockEventBucket()
+
+	expected := map[string]infiniband.ExpectedDeviceState{
+		"mlx5_0": {State: "Active", PhysicalState: "LinkUp", Rate: 400},
+	}
+	c := &component{
+		ctx:         cctx,
+		cancel:      ccancel,
+		eventBucket: mockBucket,
+		nvmlInstance: &mockNVMLInstance{
+			exists:      true,
+			productName: "H100",
+		},
+		// the port is up at 200 Gb/s, which meets the aggregate thresholds
+		getIbstatOutputFunc:   mockGetIbstatOutput,
+		getIbstatusOutputFunc: mockGetIbstatusOutput,
+		getThresholdsFunc: func() infiniband.ExpectedPortStates {
+			return infiniband.ExpectedPortStates{AtLeastPorts: 1, AtLeastRate: 100, Devices: expected}
+		},
+	}
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.health)
+	assert.Equal(t, "infiniband port(s) not in the expected state: mlx5_0 port 1 rate 200 (expected 400)", cr.reason)
+	assert.Equal(t, []apiv1.FailureCode{apiv1.FailureCodeIBPortRateDegraded}, cr.HealthStates()[0].FailureCodes)
+	assert.Contains(t, cr.HealthStates()[0].ExtraInfo["data"], `"device_mismatches":[{"device":"mlx5_0","port":1,"field":"rate","expected":"400","actual":"200"}]`)
+
+	events := mockBucket.GetAPIEvents()
+	require.Len(t, events, 1)
+	assert.Equal(t, EventNamePortMismatch, events[0].Name)
+
+	// the expected device not found
+	expected["mlx5_1"] = infiniband.ExpectedDeviceState{State: "Active"}
+	expected["mlx5_0"] = infiniband.ExpectedDeviceState{State: "Active", Rate: 200}
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.health)
+	assert.Equal(t, "infiniband port(s) not in the expected state: mlx5_1 not found", cr.reason)
+	assert.Equal(t, []apiv1.FailureCode{apiv1.FailureCodeIBPortDown}, cr.HealthStates()[0].FailureCodes)
+
+	delete(expected, "mlx5_1")
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.health)
+	assert.Empty(t, cr.DeviceMismatches)
+
+	// the mismatches are reported on top of the aggregate issue
+	expected["mlx5_0"] = infiniband.ExpectedDeviceState{Rate: 400}
+	c.getThresholdsFunc = func() infiniband.ExpectedPortStates {
+		return infiniband.ExpectedPortStates{AtLeastPorts: 2, AtLeastRate: 100, Devices: expected}
+	}
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.health)
+	require.Len(t, cr.DeviceMismatches, 1)
+	assert.Contains(t, cr.reason, "; infiniband port(s) not in the expected state: mlx5_0 port 1 rate 200 (expected 400)")
+	assert.Contains(t, cr.HealthStates()[0].FailureCodes, apiv1.FailureCodeIBPortRateDegraded)
+}
+
+func TestCheckSysfsSource(t *testing.T) {
+	t.Parallel()
+
+	cctx, ccancel := context.WithCancel(context.Background())
+	defer ccancel()
+
+	var ibstatCalls int
+	c := &component{
+		ctx:         cctx,
+		cancel:      ccancel,
+		eventBucket: createMockEventBucket(),
+		nvmlInstance: &mockNVMLInstance{
+			exists:      true,
+			productName: "H100",
+		},
+		getSysfsOutputFunc: func() (*infiniband.IbstatOutput, error) {
+			return &infiniband.IbstatOutput{Parsed: infiniband.IBStatCards{
+				{Device: "mlx5_0", Port1: infiniband.IBStatPort{State: "Active", PhysicalState: "LinkUp", Rate: 400}},
+			}}, nil
+		},
+		getIbstatOutputFunc: func(ctx context.Context, ibstatCommands []string) (*infiniband.IbstatOutput, error) {
+			ibstatCalls++
+			return mockGetIbstatOutput(ctx, ibstatCommands)
+		},
+		getIbstatusOutputFunc: mockGetIbstatusOutput,
+		getThresholdsFunc: func() infiniband.ExpectedPortStates {
+			return infiniband.ExpectedPortStates{AtLeastPorts: 1, AtLeastRate: 400}
+		},
+	}
+
+	// sysfs is preferred over the ibstat command
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.health)
+	assert.Equal(t, reasonNoIbIssueFoundFromSysfs, cr.reason)
+	assert.Equal(t, sourceSysfs, cr.Source)
+	assert.Zero(t, ibstatCalls)
+
+	// no device in sysfs, falls back to the ibstat command
+	c.getSysfsOutputFunc = func() (*infiniband.IbstatOutput, error) {
+		return nil, infiniband.ErrNoSysfsDevice
+	}
+	cr = c.Check().(*checkResult)
+	assert.Empty(t, cr.Source)
+	assert.Equal(t, 1, ibstatCalls)
+
+	// the overwritten ibstat command (e.g., mocked) is not replaced by sysfs
+	c.getSysfsOutputFunc = func() (*infiniband.IbstatOutput, error) {
+		t.Fatal("sysfs must not be read with the overwritten ibstat command")
+		return nil, nil
+	}
+	c.toolOverwrites.IbstatCommand = "cat ibstat.txt"
+	cr = c.Check().(*checkResult)
+	assert.Empty(t, cr.Source)
+	assert.Equal(t, 2, ibstatCalls)
+}
@@ -0,0 +1,74 @@
+package infiniband
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/nvidia-query/infiniband"
+)
+
+// DefaultEvaluationConfigReloadInterval is the default interval
+// to check the evaluation config file for the changes.
+const DefaultEvaluationConfigReloadInterval = 30 * time.Second
+
+// LoadEvaluationConfigFile loads the evaluation config file with the read function
+// (e.g., os.ReadFile, or the one verifying the file signature),
+// and sets it as the evaluation config of the file source,
+// which overrides the fields set by the control plane and the thresholds API.
+func LoadEvaluationConfigFile(file string, readFile func(string) ([]byte, error)) error {
+	b, err := readFile(file)
+	if err != nil {
+		return err
+	}
+	cfg, err := infiniband.ParseEvaluationConfig(b)
+	if err != nil {
+		return err
+	}
+	SetDefaultEvaluationConfig(EvaluationConfigSourceFile, cfg)
+	return nil
+}
+
+// WatchEvaluationConfigFile reloads the evaluation config file in the background
+// whenever its modification time changes, until the context is canceled.
+// Every reload goes through the read function, as the initial load.
+// The invalid config is logged and skipped, keeping the last valid one,
+// and retried until loaded (e.g., the signature file updated after the config file).
+func WatchEvaluationConfigFile(ctx context.Context, file string, readFile func(string) ([]byte, error), interval time.Duration) {
+	lastModTime := modTime(file)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			mt := modTime(file)
+			if mt.IsZero() || mt.Equal(lastModTime) {
+				continue
+			}
+
+			if err := LoadEvaluationConfigFile(file, readFile); err != nil {
+				log.Logger.Warnw("failed to reload infiniband evaluation config -- keeping the last valid config", "file", file, "error", err)
+				continue
+			}
+			lastModTime = mt
+			log.Logger.Infow("reloaded infiniband evaluation config", "file", file)
+		}
+	}()
+}
+
+// modTime returns the modification time of the file, or zero if not found.
+func modTime(file string) time.Time {
+	fi, err := os.Stat(file)
+	if err != nil {
+		return time.Time{}
+	}
+	return fi.ModTime()
+}
@@ -0,0 +1,81 @@
+package infiniband
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/leptonai/gpud/pkg/nvidia-query/infiniband"
+)
+
+func TestWatchEvaluationConfigFile(t *testing.T) {
+	defer SetDefaultEvaluationConfig(EvaluationConfigSourceFile, infiniband.EvaluationConfig{})
+
+	file := filepath.Join(t.TempDir(), "evaluation.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("drop_duration: 10m\n"), 0644))
+	require.NoError(t, LoadEvaluationConfigFile(file, os.ReadFile))
+	assert.Equal(t, 10*time.Minute, GetDefaultEvaluationConfig().DropDuration.Duration)
+	assert.Equal(t, infiniband.DefaultFlapWindow, GetDefaultEvaluationConfig().FlapWindow.Duration)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	WatchEvaluationConfigFile(ctx, file, os.ReadFile, 10*time.Millisecond)
+
+	// the invalid config is skipped
+	require.NoError(t, os.WriteFile(file, []byte("flap_min_transitions: -1\n"), 0644))
+	require.NoError(t, os.Chtimes(file, time.Now(), time.Now().Add(time.Second)))
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 10*time.Minute, GetDefaultEvaluationConfig().DropDuration.Duration)
+
+	require.NoError(t, os.WriteFile(file, []byte("drop_duration: 2m\nflap_min_transitions: 5\n"), 0644))
+	require.NoError(t, os.Chtimes(file, time.Now(), time.Now().Add(2*time.Second)))
+	assert.Eventually(t, func() bool {
+		cfg := GetDefaultEvaluationConfig()
+		return cfg.DropDuration.Duration == 2*time.Minute && cfg.FlapMinTransitions == 5
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Error(t, LoadEvaluationConfigFile(filepath.Join(t.TempDir(), "not-found.yaml"), os.ReadFile))
+}
+
+func TestEvaluationConfigSources(t *testing.T) {
+	defer func() {
+		SetDefaultEvaluationConfig(EvaluationConfigSourceThresholds, infiniband.EvaluationConfig{})
+		SetDefaultEvaluationConfig(EvaluationConfigSourceControlPlane, infiniband.EvaluationConfig{})
+		SetDefaultEvaluationConfig(EvaluationConfigSourceFile, infiniband.EvaluationConfig{})
+	}()
+
+	SetDefaultEvaluationConfig(EvaluationConfigSourceFile, infiniband.EvaluationConfig{
+		DropDuration: metav1.Duration{Duration: 10 * time.Minute},
+	})
+	SetDefaultEvaluationConfig(EvaluationConfigSourceControlPlane, infiniband.EvaluationConfig{
+		DropDuration:       metav1.Duration{Duration: 2 * time.Minute},
+		FlapMinTransitions: 5,
+	})
+	SetDefaultEvaluationConfig(EvaluationConfigSourceThresholds, infiniband.EvaluationConfig{
+		FlapMinTransitions: 7,
+		CounterDeltas:      infiniband.CounterDeltaThresholds{SymbolError: 100},
+	})
+
+	// the file overrides the control plane, which overrides the thresholds API,
+	// and the fields not set by a higher source are kept
+	cfg := GetDefaultEvaluationConfig()
+	assert.Equal(t, 10*time.Minute, cfg.DropDuration.Duration)
+	assert.Equal(t, 5, cfg.FlapMinTransitions)
+	assert.Equal(t, uint64(100), cfg.CounterDeltas.SymbolError)
+	assert.Equal(t, infiniband.DefaultFlapWindow, cfg.FlapWindow.Duration)
+
+	// a later thresholds API update does not override the higher sources
+	SetDefaultEvaluationConfig(EvaluationConfigSourceThresholds, infiniband.EvaluationConfig{
+		DropDuration: metav1.Duration{Duration: time.Minute},
+	})
+	cfg = GetDefaultEvaluationConfig()
+	assert.Equal(t, 10*time.Minute, cfg.DropDuration.Duration)
+	assert.Equal(t, 5, cfg.FlapMinTransitions)
+	assert.Equal(t, infiniband.DefaultCounterDeltaThresholds.SymbolError, cfg.CounterDeltas.SymbolError)
+}
@@ -0,0 +1,62 @@
+package infiniband
+
+import (
+	"sync"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/nvidia-query/infiniband"
+)
+
+// portCounters tracks the port error counters across the checks,
+// to evaluate the counter increases within a time window, which flag
+// the slowly degrading links (e.g., the dirty transceivers) before they drop.
+// The window keeps the evaluation independent of the check interval.
+type portCounters struct {
+	mu sync.Mutex
+	// samples are the read counters of each port within the window (oldest first),
+	// keyed by the port key
+	samples map[string][]counterSample
+}
+
+type counterSample struct {
+	time     time.Time
+	counters infiniband.PortCounters
+}
+
+// observe records the current counters, and returns the counters that increased
+// at or beyond the thresholds within the window, sorted by the port.
+// The ports first seen in this check are not evaluated, and the ports
+// no longer read are forgotten.
+func (h *portCounters) observe(now time.Time, cur []infiniband.PortCounters, window time.Duration, thresholds infiniband.CounterDeltaThresholds) []infiniband.CounterDelta {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	prev := h.samples
+	h.samples = make(map[string][]counterSample, len(cur))
+
+	var exceeded []infiniband.CounterDelta
+	for _, pc := range cur {
+		samples := trimCounterSamples(append(prev[pc.Key()], counterSample{time: now, counters: pc}), now.Add(-window))
+		h.samples[pc.Key()] = samples
+
+		readings := make([]infiniband.PortCounters, 0, len(samples))
+		for _, s := range samples {
+			readings = append(readings, s.counters)
+		}
+		exceeded = append(exceeded, thresholds.ExceededDeltas(readings...)...)
+	}
+	return exceeded
+}
+
+// trimCounterSamples drops the samples before the window start, but keeps
+// the latest one at or before the start as the baseline of the window.
+func trimCounterSamples(samples []counterSample, start time.Time) []counterSample {
+	first := 0
+	for i, s := range samples {
+		if s.time.After(start) {
+			break
+		}
+		first = i
+	}
+	return samples[first:]
+}
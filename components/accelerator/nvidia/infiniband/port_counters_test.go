@@ -0,0 +1,120 @@
+package infiniband
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/nvidia-query/infiniband"
+)
+
+func TestPortCountersObserve(t *testing.T) {
+	thresholds := infiniband.CounterDeltaThresholds{SymbolError: 100, LinkDowned: 1}
+	window := 10 * time.Minute
+	now := time.Now()
+
+	var h portCounters
+	// first seen, no previous counters to compare
+	assert.Empty(t, h.observe(now, []infiniband.PortCounters{{Device: "mlx5_0", Port: 1, SymbolError: 5000}}, window, thresholds))
+
+	now = now.Add(time.Minute)
+	assert.Empty(t, h.observe(now, []infiniband.PortCounters{{Device: "mlx5_0", Port: 1, SymbolError: 5050}}, window, thresholds))
+
+	// the increases within the window are summed, regardless of the check interval
+	now = now.Add(time.Minute)
+	exceeded := h.observe(now, []infiniband.PortCounters{
+		{Device: "mlx5_0", Port: 1, SymbolError: 5120, LinkDowned: 1},
+		{Device: "mlx5_1", Port: 1, SymbolError: 1000},
+	}, window, thresholds)
+	assert.Equal(t, []infiniband.CounterDelta{
+		{Device: "mlx5_0", Port: 1, Counter: infiniband.CounterSymbolError, Delta: 120, Threshold: 100},
+		{Device: "mlx5_0", Port: 1, Counter: infiniband.CounterLinkDowned, Delta: 1, Threshold: 1},
+	}, exceeded)
+
+	// the increases before the window no longer count
+	now = now.Add(window)
+	assert.Empty(t, h.observe(now, []infiniband.PortCounters{{Device: "mlx5_0", Port: 1, SymbolError: 5150, LinkDowned: 1}}, window, thresholds))
+
+	// the ports no longer read are forgotten
+	assert.NotContains(t, h.samples, "mlx5_1/1")
+
+	// no thresholds
+	now = now.Add(time.Minute)
+	assert.Empty(t, h.observe(now, []infiniband.PortCounters{{Device: "mlx5_0", Port: 1, SymbolError: 9000}}, window, infiniband.CounterDeltaThresholds{}))
+}
+
+func TestCheckPortErrors(t *testing.T) {
+	t.Parallel()
+
+	cctx, ccancel := context.WithCancel(context.Background())
+	defer ccancel()
+
+	mockBucket := createMockEventBucket()
+
+	now := time.Now()
+	symbolErrors := uint64(10)
+	var countersErr error
+	c := &component{
+		nowFunc:     func() time.Time { return now },
+		ctx:         cctx,
+		cancel:      ccancel,
+		eventBucket: mockBucket,
+		nvmlInstance: &mockNVMLInstance{
+			exists:      true,
+			productName: "H100",
+		},
+		getIbstatOutputFunc: func(ctx context.Context, ibstatCommands []string) (*infiniband.IbstatOutput, error) {
+			return &infiniband.IbstatOutput{Parsed: testCards(map[string]string{"mlx5_0": "LinkUp"})}, nil
+		},
+		getIbstatusOutputFunc: mockGetIbstatusOutput,
+		getThresholdsFunc: func() infiniband.ExpectedPortStates {
+			return infiniband.ExpectedPortStates{AtLeastPorts: 1, AtLeastRate: 400}
+		},
+		getEvaluationConfigFunc: func() infiniband.EvaluationConfig {
+			return infiniband.EvaluationConfig{CounterDeltas: infiniband.CounterDeltaThresholds{SymbolError: 100}}
+		},
+		getPortCountersFunc: func() ([]infiniband.PortCounters, error) {
+			return []infiniband.PortCounters{{Device: "mlx5_0", Port: 1, SymbolError: symbolErrors}}, countersErr
+		},
+	}
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.health)
+	assert.Len(t, cr.Counters, 1)
+	assert.Contains(t, cr.String(), "SYMBOL ERRORS")
+
+	now = now.Add(time.Minute)
+	symbolErrors = 60
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.health)
+
+	now = now.Add(time.Minute)
+	symbolErrors = 260
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.health)
+	assert.Equal(t, "infiniband port error counter(s) increasing: mlx5_0 port 1 symbol_error increased by 250 (threshold 100)", cr.reason)
+	require.NotNil(t, cr.suggestedActions)
+	assert.Equal(t, []apiv1.RepairActionType{apiv1.RepairActionTypeCheckCabling}, cr.suggestedActions.RepairActions)
+	assert.Equal(t, []apiv1.FailureCode{apiv1.FailureCodeIBPortErrors}, cr.HealthStates()[0].FailureCodes)
+	assert.Contains(t, cr.HealthStates()[0].ExtraInfo["data"], `"counter_deltas":[{"device":"mlx5_0","port":1,"counter":"symbol_error","delta":250,"threshold":100}]`)
+
+	events := mockBucket.GetAPIEvents()
+	require.Len(t, events, 1)
+	assert.Equal(t, EventNamePortErrors, events[0].Name)
+
+	// the counters stopped increasing, and the increases left the window
+	now = now.Add(infiniband.DefaultCounterWindow)
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.health)
+
+	// the counters not readable, no evaluation
+	countersErr = errors.New("permission denied")
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.health)
+	assert.Empty(t, cr.Counters)
+}
@@ -0,0 +1,161 @@
+package infiniband
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/leptonai/gpud/pkg/nvidia-query/infiniband"
+)
+
+// PortDrop is the port down for the drop duration or longer.
+type PortDrop struct {
+	// Device is the port device name (e.g., "mlx5_0").
+	Device string `json:"device"`
+	// DownSince is the time the port was first observed down.
+	DownSince time.Time `json:"down_since"`
+	// Duration is how long the port has been down, rounded to the second.
+	Duration metav1.Duration `json:"duration"`
+}
+
+func (d PortDrop) String() string {
+	return fmt.Sprintf("%s down for %s", d.Device, d.Duration.Duration)
+}
+
+// PortFlap is the port repeatedly going down and back up within the flap window.
+type PortFlap struct {
+	// Device is the port device name (e.g., "mlx5_0").
+	Device string `json:"device"`
+	// Transitions is the number of the down transitions within the window.
+	Transitions int `json:"transitions"`
+	// Window is the flap window the transitions are counted in.
+	Window metav1.Duration `json:"window"`
+}
+
+func (f PortFlap) String() string {
+	return fmt.Sprintf("%s went down %d time(s) in %s", f.Device, f.Transitions, f.Window.Duration)
+}
+
+// portHistory tracks the physical state transitions of the ports across the checks,
+// to detect the ports down for too long (dropped) and the ports repeatedly
+// going down and back up (flapping), which a single snapshot cannot tell.
+type portHistory struct {
+	mu sync.Mutex
+	// up is the last observed state of each port device
+	up map[string]bool
+	// downSince is the time each port device was first observed down, removed once up
+	downSince map[string]time.Time
+	// downTransitions are the times each port device went down from up,
+	// within the flap window
+	downTransitions map[string][]time.Time
+}
+
+// observe records the port states at the time, and prunes the down transitions
+// older than the flap window.
+func (h *portHistory) observe(cards infiniband.IBStatCards, now time.Time, flapWindow time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.up == nil {
+		h.up = make(map[string]bool)
+		h.downSince = make(map[string]time.Time)
+		h.downTransitions = make(map[string][]time.Time)
+	}
+
+	for _, card := range cards {
+		isUp := card.Port1.PhysicalState == "LinkUp"
+		wasUp, seen := h.up[card.Device]
+		h.up[card.Device] = isUp
+
+		if isUp {
+			delete(h.downSince, card.Device)
+			continue
+		}
+		if _, ok := h.downSince[card.Device]; !ok {
+			h.downSince[card.Device] = now
+		}
+		if seen && wasUp {
+			h.downTransitions[card.Device] = append(h.downTransitions[card.Device], now)
+		}
+	}
+
+	for dev, ts := range h.downTransitions {
+		kept := ts[:0]
+		for _, t := range ts {
+			if now.Sub(t) < flapWindow {
+				kept = append(kept, t)
+			}
+		}
+		if len(kept) == 0 {
+			delete(h.downTransitions, dev)
+			continue
+		}
+		h.downTransitions[dev] = kept
+	}
+}
+
+// evaluate returns the dropped and the flapping ports, sorted by the device.
+func (h *portHistory) evaluate(now time.Time, cfg infiniband.EvaluationConfig) ([]PortDrop, []PortFlap) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return evaluateIbPortDrop(h.downSince, now, cfg), evaluateIbPortFlap(h.downTransitions, now, cfg)
+}
+
+// evaluateIbPortDrop returns the ports down for the drop duration or longer.
+func evaluateIbPortDrop(downSince map[string]time.Time, now time.Time, cfg infiniband.EvaluationConfig) []PortDrop {
+	var dropped []PortDrop
+	for _, dev := range sortedKeys(downSince) {
+		if d := now.Sub(downSince[dev]); d >= cfg.DropDuration.Duration {
+			dropped = append(dropped, PortDrop{
+				Device:    dev,
+				DownSince: downSince[dev],
+				Duration:  metav1.Duration{Duration: d.Round(time.Second)},
+			})
+		}
+	}
+	return dropped
+}
+
+// evaluateIbPortFlap returns the ports that went down the minimum transitions
+// or more within the flap window.
+func evaluateIbPortFlap(downTransitions map[string][]time.Time, now time.Time, cfg infiniband.EvaluationConfig) []PortFlap {
+	var flapping []PortFlap
+	for _, dev := range sortedKeys(downTransitions) {
+		n := 0
+		for _, t := range downTransitions[dev] {
+			if now.Sub(t) < cfg.FlapWindow.Duration {
+				n++
+			}
+		}
+		if n >= cfg.FlapMinTransitions {
+			flapping = append(flapping, PortFlap{
+				Device:      dev,
+				Transitions: n,
+				Window:      cfg.FlapWindow,
+			})
+		}
+	}
+	return flapping
+}
+
+// describePorts returns the descriptions of the port issues.
+func describePorts[T fmt.Stringer](ports []T) []string {
+	descs := make([]string, 0, len(ports))
+	for _, p := range ports {
+		descs = append(descs, p.String())
+	}
+	return descs
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
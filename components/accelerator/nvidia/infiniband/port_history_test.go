@@ -0,0 +1,190 @@
+package infiniband
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/nvidia-query/infiniband"
+)
+
+func testCards(states map[string]string) infiniband.IBStatCards {
+	var cards infiniband.IBStatCards
+	for _, dev := range sortedKeys(states) {
+		state := "Active"
+		if states[dev] != "LinkUp" {
+			state = "Down"
+		}
+		cards = append(cards, infiniband.IBStatCard{
+			Device: dev,
+			Port1:  infiniband.IBStatPort{State: state, PhysicalState: states[dev], Rate: 400},
+		})
+	}
+	return cards
+}
+
+func TestEvaluateIbPortDrop(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	cfg := infiniband.EvaluationConfig{DropDuration: metav1.Duration{Duration: 4 * time.Minute}}
+
+	downSince := map[string]time.Time{
+		"mlx5_1": now.Add(-5 * time.Minute),
+		"mlx5_0": now.Add(-4 * time.Minute),
+		"mlx5_2": now.Add(-time.Minute),
+	}
+	assert.Equal(t, []PortDrop{
+		{Device: "mlx5_0", DownSince: now.Add(-4 * time.Minute), Duration: metav1.Duration{Duration: 4 * time.Minute}},
+		{Device: "mlx5_1", DownSince: now.Add(-5 * time.Minute), Duration: metav1.Duration{Duration: 5 * time.Minute}},
+	}, evaluateIbPortDrop(downSince, now, cfg))
+
+	cfg.DropDuration.Duration = 10 * time.Minute
+	assert.Empty(t, evaluateIbPortDrop(downSince, now, cfg))
+}
+
+func TestEvaluateIbPortFlap(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	cfg := infiniband.EvaluationConfig{FlapWindow: metav1.Duration{Duration: 10 * time.Minute}, FlapMinTransitions: 3}
+
+	transitions := map[string][]time.Time{
+		"mlx5_0": {now.Add(-9 * time.Minute), now.Add(-5 * time.Minute), now.Add(-time.Minute)},
+		"mlx5_1": {now.Add(-20 * time.Minute), now.Add(-5 * time.Minute), now.Add(-time.Minute)},
+	}
+	assert.Equal(t, []PortFlap{
+		{Device: "mlx5_0", Transitions: 3, Window: metav1.Duration{Duration: 10 * time.Minute}},
+	}, evaluateIbPortFlap(transitions, now, cfg))
+
+	cfg.FlapWindow.Duration = 30 * time.Minute
+	assert.Len(t, evaluateIbPortFlap(transitions, now, cfg), 2)
+}
+
+func TestPortHistory(t *testing.T) {
+	cfg := infiniband.EvaluationConfig{}.WithDefaults()
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var h portHistory
+
+	// mlx5_1 flaps every minute, mlx5_2 stays down
+	for i := 0; i < 6; i++ {
+		state := "LinkUp"
+		if i%2 == 1 {
+			state = "Polling"
+		}
+		h.observe(testCards(map[string]string{"mlx5_0": "LinkUp", "mlx5_1": state, "mlx5_2": "Disabled"}), now, cfg.FlapWindow.Duration)
+		now = now.Add(time.Minute)
+	}
+
+	dropped, flapping := h.evaluate(now, cfg)
+	assert.Equal(t, []string{"mlx5_2 down for 6m0s"}, describePorts(dropped))
+	assert.Equal(t, []string{"mlx5_1 went down 3 time(s) in 10m0s"}, describePorts(flapping))
+
+	// the down transitions age out of the flap window, and the recovered port is no longer dropped
+	now = now.Add(cfg.FlapWindow.Duration)
+	h.observe(testCards(map[string]string{"mlx5_0": "LinkUp", "mlx5_1": "LinkUp", "mlx5_2": "LinkUp"}), now, cfg.FlapWindow.Duration)
+	dropped, flapping = h.evaluate(now, cfg)
+	assert.Empty(t, dropped)
+	assert.Empty(t, flapping)
+}
+
+func TestCheckPortFlap(t *testing.T) {
+	t.Parallel()
+
+	cctx, ccancel := context.WithCancel(context.Background())
+	defer ccancel()
+
+	mockBucket := createMockEventBucket()
+
+	states := map[string]string{"mlx5_0": "LinkUp", "mlx5_1": "LinkUp"}
+	c := &component{
+		ctx:         cctx,
+		cancel:      ccancel,
+		eventBucket: mockBucket,
+		nvmlInstance: &mockNVMLInstance{
+			exists:      true,
+			productName: "H100",
+		},
+		getIbstatOutputFunc: func(ctx context.Context, ibstatCommands []string) (*infiniband.IbstatOutput, error) {
+			return &infiniband.IbstatOutput{Parsed: testCards(states)}, nil
+		},
+		getIbstatusOutputFunc: mockGetIbstatusOutput,
+		getThresholdsFunc: func() infiniband.ExpectedPortStates {
+			return infiniband.ExpectedPortStates{AtLeastPorts: 1, AtLeastRate: 400}
+		},
+		getEvaluationConfigFunc: func() infiniband.EvaluationConfig {
+			return infiniband.EvaluationConfig{FlapMinTransitions: 2}
+		},
+	}
+
+	for _, state := range []string{"LinkUp", "Polling", "LinkUp", "Polling"} {
+		states["mlx5_1"] = state
+		_ = c.Check()
+	}
+	states["mlx5_1"] = "LinkUp"
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.health)
+	assert.Equal(t, "infiniband port(s) flapping: mlx5_1 went down 2 time(s) in 10m0s", cr.reason)
+	assert.Equal(t, []PortFlap{
+		{Device: "mlx5_1", Transitions: 2, Window: metav1.Duration{Duration: 10 * time.Minute}},
+	}, cr.FlappingPorts)
+	assert.Empty(t, cr.DroppedPorts)
+	require.NotNil(t, cr.suggestedActions)
+	assert.Equal(t, []apiv1.RepairActionType{apiv1.RepairActionTypeCheckCabling}, cr.suggestedActions.RepairActions)
+	assert.Equal(t, []apiv1.FailureCode{apiv1.FailureCodeIBPortFlap}, cr.HealthStates()[0].FailureCodes)
+	assert.Contains(t, cr.HealthStates()[0].ExtraInfo["data"], `"flapping_ports":[{"device":"mlx5_1","transitions":2,"window":"10m0s"}]`)
+
+	events := mockBucket.GetAPIEvents()
+	require.Len(t, events, 1)
+	assert.Equal(t, EventNamePortFlap, events[0].Name)
+}
+
+func TestCheckPortDrop(t *testing.T) {
+	t.Parallel()
+
+	cctx, ccancel := context.WithCancel(context.Background())
+	defer ccancel()
+
+	mockBucket := createMockEventBucket()
+
+	states := map[string]string{"mlx5_0": "LinkUp", "mlx5_1": "Disabled"}
+	c := &component{
+		ctx:         cctx,
+		cancel:      ccancel,
+		eventBucket: mockBucket,
+		nvmlInstance: &mockNVMLInstance{
+			exists:      true,
+			productName: "H100",
+		},
+		getIbstatOutputFunc: func(ctx context.Context, ibstatCommands []string) (*infiniband.IbstatOutput, error) {
+			return &infiniband.IbstatOutput{Parsed: testCards(states)}, nil
+		},
+		getIbstatusOutputFunc: mockGetIbstatusOutput,
+		getThresholdsFunc: func() infiniband.ExpectedPortStates {
+			return infiniband.ExpectedPortStates{AtLeastPorts: 1, AtLeastRate: 400}
+		},
+		getEvaluationConfigFunc: func() infiniband.EvaluationConfig {
+			return infiniband.EvaluationConfig{DropDuration: metav1.Duration{Duration: time.Nanosecond}}
+		},
+	}
+
+	_ = c.Check()
+	time.Sleep(time.Millisecond)
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.health)
+	require.Len(t, cr.DroppedPorts, 1)
+	assert.Equal(t, "mlx5_1", cr.DroppedPorts[0].Device)
+	assert.False(t, cr.DroppedPorts[0].DownSince.IsZero())
+	assert.Contains(t, cr.reason, "infiniband port(s) dropped: mlx5_1 down for ")
+	assert.Empty(t, cr.FlappingPorts)
+	require.NotNil(t, cr.suggestedActions)
+	assert.Equal(t, []apiv1.RepairActionType{apiv1.RepairActionTypeHardwareInspection}, cr.suggestedActions.RepairActions)
+	assert.Equal(t, []apiv1.FailureCode{apiv1.FailureCodeIBPortDown}, cr.HealthStates()[0].FailureCodes)
+	assert.Contains(t, cr.HealthStates()[0].ExtraInfo["data"], `"dropped_ports":[{"device":"mlx5_1","down_since":`)
+
+	events := mockBucket.GetAPIEvents()
+	require.Len(t, events, 1)
+	assert.Equal(t, EventNamePortDrop, events[0].Name)
+}
@@ -0,0 +1,30 @@
+package infiniband
+
+import (
+	"sync"
+
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/nvidia-query/infiniband"
+)
+
+var (
+	defaultClassDirMu sync.RWMutex
+	defaultClassDir   = infiniband.DefaultClassDir
+)
+
+// getDefaultClassDir returns the sysfs class directory to read the infiniband ports from.
+func getDefaultClassDir() string {
+	defaultClassDirMu.RLock()
+	defer defaultClassDirMu.RUnlock()
+	return defaultClassDir
+}
+
+// SetDefaultClassDir sets the sysfs class directory to read the infiniband ports from
+// (e.g., the recorded sysfs snapshot to replay the scan with).
+func SetDefaultClassDir(dir string) {
+	log.Logger.Infow("setting default infiniband class dir", "dir", dir)
+
+	defaultClassDirMu.Lock()
+	defer defaultClassDirMu.Unlock()
+	defaultClassDir = dir
+}
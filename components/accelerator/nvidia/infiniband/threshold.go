@@ -22,9 +22,114 @@ func GetDefaultExpectedPortStates() infiniband.ExpectedPortStates {
 }
 
 func SetDefaultExpectedPortStates(states infiniband.ExpectedPortStates) {
-	log.Logger.Infow("setting default expected port states", "at_least_ports", states.AtLeastPorts, "at_least_rate", states.AtLeastRate)
+	log.Logger.Infow("setting default expected port states", "at_least_ports", states.AtLeastPorts, "at_least_rate", states.AtLeastRate, "devices", len(states.Devices))
 
 	defaultExpectedPortStatesMu.Lock()
 	defer defaultExpectedPortStatesMu.Unlock()
 	defaultExpectedPortStates = states
 }
+
+// EvaluationConfigSource is a writer of the evaluation config.
+// Each source keeps its own config, and the non-zero fields of the sources
+// are merged in the order of precedence (the highest last), so a source
+// never discards the fields set by the other sources.
+type EvaluationConfigSource int
+
+const (
+	// EvaluationConfigSourceThresholds is the thresholds API (persisted across the restarts),
+	// the lowest precedence.
+	EvaluationConfigSourceThresholds EvaluationConfigSource = iota
+	// EvaluationConfigSourceControlPlane is the update config request from the control plane.
+	EvaluationConfigSourceControlPlane
+	// EvaluationConfigSourceFile is the signed evaluation config file on the node,
+	// the highest precedence, as pinned by the operator.
+	EvaluationConfigSourceFile
+)
+
+func (src EvaluationConfigSource) String() string {
+	switch src {
+	case EvaluationConfigSourceThresholds:
+		return "thresholds"
+	case EvaluationConfigSourceControlPlane:
+		return "control-plane"
+	case EvaluationConfigSourceFile:
+		return "file"
+	default:
+		return "unknown"
+	}
+}
+
+var (
+	defaultEvaluationConfigMu sync.RWMutex
+	// evaluationConfigs are the configs set by each source
+	evaluationConfigs = make(map[EvaluationConfigSource]infiniband.EvaluationConfig)
+)
+
+// GetDefaultEvaluationConfig returns the port drop, flap, and error counter detection config,
+// merged from all the sources, with the zero fields set to the defaults.
+func GetDefaultEvaluationConfig() infiniband.EvaluationConfig {
+	defaultEvaluationConfigMu.RLock()
+	defer defaultEvaluationConfigMu.RUnlock()
+
+	var merged infiniband.EvaluationConfig
+	for _, src := range []EvaluationConfigSource{
+		EvaluationConfigSourceThresholds,
+		EvaluationConfigSourceControlPlane,
+		EvaluationConfigSourceFile,
+	} {
+		if cfg, ok := evaluationConfigs[src]; ok {
+			merged = mergeEvaluationConfig(merged, cfg)
+		}
+	}
+	return merged.WithDefaults()
+}
+
+// SetDefaultEvaluationConfig sets the port drop, flap, and error counter detection config
+// of the source, replacing the previous config of the same source only.
+func SetDefaultEvaluationConfig(src EvaluationConfigSource, cfg infiniband.EvaluationConfig) {
+	log.Logger.Infow("setting default evaluation config",
+		"source", src.String(),
+		"drop_duration", cfg.DropDuration.Duration,
+		"flap_window", cfg.FlapWindow.Duration,
+		"flap_min_transitions", cfg.FlapMinTransitions,
+		"counter_window", cfg.CounterWindow.Duration,
+		"counter_deltas", cfg.CounterDeltas,
+		"disable_counter_deltas", cfg.DisableCounterDeltas,
+	)
+
+	defaultEvaluationConfigMu.Lock()
+	defer defaultEvaluationConfigMu.Unlock()
+	evaluationConfigs[src] = cfg
+}
+
+// mergeEvaluationConfig returns the base config overridden by the non-zero fields of the override.
+func mergeEvaluationConfig(base infiniband.EvaluationConfig, override infiniband.EvaluationConfig) infiniband.EvaluationConfig {
+	if override.DropDuration.Duration != 0 {
+		base.DropDuration = override.DropDuration
+	}
+	if override.FlapWindow.Duration != 0 {
+		base.FlapWindow = override.FlapWindow
+	}
+	if override.FlapMinTransitions != 0 {
+		base.FlapMinTransitions = override.FlapMinTransitions
+	}
+	if override.CounterWindow.Duration != 0 {
+		base.CounterWindow = override.CounterWindow
+	}
+	if override.DisableCounterDeltas {
+		base.DisableCounterDeltas = true
+	}
+	if override.CounterDeltas.SymbolError != 0 {
+		base.CounterDeltas.SymbolError = override.CounterDeltas.SymbolError
+	}
+	if override.CounterDeltas.LinkDowned != 0 {
+		base.CounterDeltas.LinkDowned = override.CounterDeltas.LinkDowned
+	}
+	if override.CounterDeltas.PortRcvErrors != 0 {
+		base.CounterDeltas.PortRcvErrors = override.CounterDeltas.PortRcvErrors
+	}
+	if override.CounterDeltas.ExcessiveBufferOverrunErrors != 0 {
+		base.CounterDeltas.ExcessiveBufferOverrunErrors = override.CounterDeltas.ExcessiveBufferOverrunErrors
+	}
+	return base
+}
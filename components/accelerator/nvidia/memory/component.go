@@ -6,6 +6,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -16,6 +18,7 @@ import (
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/eventstore"
 	"github.com/leptonai/gpud/pkg/log"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
 )
@@ -28,9 +31,18 @@ type component struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	checkInterval time.Duration
+
 	nvmlInstance  nvidianvml.Instance
 	getMemoryFunc func(uuid string, dev device.Device) (nvidianvml.Memory, error)
 
+	// processesCache is used to find the top memory-consuming processes
+	// on the GPUs under memory pressure, nil to not include them
+	processesCache *nvidianvml.ProcessesCache
+	pressure       *pressureTracker
+
+	eventBucket eventstore.Bucket
+
 	lastMu          sync.RWMutex
 	lastCheckResult *checkResult
 }
@@ -38,11 +50,26 @@ type component struct {
 func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
 	c := &component{
-		ctx:           cctx,
-		cancel:        ccancel,
-		nvmlInstance:  gpudInstance.NVMLInstance,
-		getMemoryFunc: nvidianvml.GetMemory,
+		ctx:    cctx,
+		cancel: ccancel,
+
+		checkInterval: gpudInstance.CheckInterval(Name),
+
+		nvmlInstance:   gpudInstance.NVMLInstance,
+		getMemoryFunc:  nvidianvml.GetMemory,
+		processesCache: gpudInstance.ProcessesCache,
+		pressure:       newPressureTracker(),
+	}
+
+	if gpudInstance.EventStore != nil {
+		var err error
+		c.eventBucket, err = gpudInstance.EventStore.Bucket(Name)
+		if err != nil {
+			ccancel()
+			return nil, err
+		}
 	}
+
 	return c, nil
 }
 
@@ -66,11 +93,11 @@ func (c *component) IsSupported() bool {
 
 func (c *component) Start() error {
 	go func() {
-		ticker := time.NewTicker(time.Minute)
+		ticker := components.NewCheckTicker(c.checkInterval)
 		defer ticker.Stop()
 
 		for {
-			_ = c.Check()
+			_ = components.SafeCheck(c)
 
 			select {
 			case <-c.ctx.Done():
@@ -90,7 +117,14 @@ func (c *component) LastHealthStates() apiv1.HealthStates {
 }
 
 func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
-	return nil, nil
+	if c.eventBucket == nil {
+		return nil, nil
+	}
+	evs, err := c.eventBucket.Get(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	return evs.Events(), nil
 }
 
 func (c *component) Close() error {
@@ -98,6 +132,10 @@ func (c *component) Close() error {
 
 	c.cancel()
 
+	if c.eventBucket != nil {
+		c.eventBucket.Close()
+	}
+
 	return nil
 }
 
@@ -155,18 +193,81 @@ func (c *component) Check() components.CheckResult {
 			return cr
 		}
 		metricUsedPercent.With(prometheus.Labels{"uuid": uuid}).Set(usedPct)
+
+		c.checkPressure(cr, mem)
 	}
 
 	cr.health = apiv1.HealthStateTypeHealthy
-	cr.reason = fmt.Sprintf("all %d GPU(s) were checked, no memory issue found", len(devs))
+	if len(cr.Pressures) == 0 {
+		cr.reason = fmt.Sprintf("all %d GPU(s) were checked, no memory issue found", len(devs))
+		return cr
+	}
+
+	uuids := make([]string, 0, len(cr.Pressures))
+	for _, p := range cr.Pressures {
+		uuids = append(uuids, p.UUID)
+	}
+	sort.Strings(uuids)
+	cr.reason = fmt.Sprintf("all %d GPU(s) were checked, %d GPU(s) under memory pressure (%s)", len(devs), len(uuids), strings.Join(uuids, ", "))
 
 	return cr
 }
 
+// checkPressure tracks the memory pressure of the GPU,
+// and emits the warning event when the GPU comes under pressure.
+func (c *component) checkPressure(cr *checkResult, mem nvidianvml.Memory) {
+	if c.pressure == nil {
+		return
+	}
+
+	p, warn := c.pressure.observe(cr.ts, mem)
+	growth := float64(0)
+	if p != nil {
+		growth = p.GrowthBytesPerSecond
+	}
+	metricGrowthBytesPerSecond.With(prometheus.Labels{"uuid": mem.UUID}).Set(growth)
+	if p == nil {
+		return
+	}
+
+	if procs, _, ok := c.processesCache.Get(mem.UUID); ok {
+		p.TopProcesses = topProcesses(procs.RunningProcesses, DefaultPressureTopProcesses)
+	}
+	cr.Pressures = append(cr.Pressures, *p)
+
+	if !warn {
+		return
+	}
+	log.Logger.Warnw("gpu memory pressure", "uuid", p.UUID, "usedPercent", p.UsedPercent, "secondsToFull", p.SecondsToFull)
+	if c.eventBucket == nil {
+		return
+	}
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		log.Logger.Errorw("failed to marshal memory pressure", "error", err)
+		return
+	}
+	ev := eventstore.Event{
+		Time:    cr.ts,
+		Name:    EventNameMemoryPressure,
+		Type:    string(apiv1.EventTypeWarning),
+		Message: p.Message(),
+		ExtraInfo: map[string]string{
+			"data": string(b),
+		},
+	}
+	if err := c.eventBucket.Insert(c.ctx, ev); err != nil {
+		log.Logger.Errorw("failed to insert memory pressure event", "uuid", p.UUID, "error", err)
+	}
+}
+
 var _ components.CheckResult = &checkResult{}
 
 type checkResult struct {
 	Memories []nvidianvml.Memory `json:"memories,omitempty"`
+	// Pressures are the GPUs under memory pressure.
+	Pressures []MemoryPressure `json:"pressures,omitempty"`
 
 	// timestamp of the last check
 	ts time.Time
@@ -37,6 +37,10 @@ func (m *MockNvmlInstance) FabricManagerSupported() bool {
 	return true
 }
 
+func (m *MockNvmlInstance) VirtualizationMode() nvidianvml.VirtualizationMode {
+	return nvidianvml.VirtualizationModeNone
+}
+
 func (m *MockNvmlInstance) GetMemoryErrorManagementCapabilities() nvidianvml.MemoryErrorManagementCapabilities {
 	return nvidianvml.MemoryErrorManagementCapabilities{}
 }
@@ -62,6 +62,16 @@ var (
 		},
 		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
 	).MustCurryWith(componentLabel)
+
+	metricGrowthBytesPerSecond = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "growth_bytes_per_second",
+			Help:      "tracks the growth rate of the used memory of the GPUs under memory pressure (zero otherwise)",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+	).MustCurryWith(componentLabel)
 )
 
 func init() {
@@ -71,5 +81,6 @@ func init() {
 		metricUsedBytes,
 		metricFreeBytes,
 		metricUsedPercent,
+		metricGrowthBytesPerSecond,
 	)
 }
@@ -0,0 +1,160 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+)
+
+const (
+	// EventNameMemoryPressure is the warning event emitted when a GPU
+	// is about to run out of memory.
+	EventNameMemoryPressure = "gpu_memory_pressure"
+
+	// DefaultPressureUsedPercent is the used (including reserved) memory percentage
+	// at which the GPU is under memory pressure, regardless of the growth.
+	DefaultPressureUsedPercent = float64(95)
+	// DefaultPressureForecastUsedPercent is the used memory percentage above which
+	// the GPU is under memory pressure if the memory is forecast to run out
+	// within the forecast window at the current growth rate.
+	// The GPU is no longer under pressure once the usage drops below it.
+	DefaultPressureForecastUsedPercent = float64(80)
+	// DefaultPressureForecastWindow is the time window to forecast the memory exhaustion.
+	DefaultPressureForecastWindow = 10 * time.Minute
+	// DefaultPressureTopProcesses is the number of the top memory-consuming processes
+	// included in the memory pressure warning.
+	DefaultPressureTopProcesses = 5
+)
+
+// MemoryPressure is the memory pressure of a GPU.
+type MemoryPressure struct {
+	UUID string `json:"uuid"`
+
+	// UsedBytes is the used memory including the reserved memory.
+	UsedBytes   uint64  `json:"used_bytes"`
+	TotalBytes  uint64  `json:"total_bytes"`
+	UsedPercent float64 `json:"used_percent"`
+
+	// GrowthBytesPerSecond is the growth rate of the used memory since the last check,
+	// zero if not growing or unknown.
+	GrowthBytesPerSecond float64 `json:"growth_bytes_per_second"`
+	// SecondsToFull is the forecast time until the memory runs out at the growth rate,
+	// zero if not growing.
+	SecondsToFull float64 `json:"seconds_to_full,omitempty"`
+
+	// TopProcesses are the top memory-consuming processes on the GPU,
+	// from the latest cached process list.
+	TopProcesses []nvidianvml.Process `json:"top_processes,omitempty"`
+}
+
+// Message returns the human-readable description of the memory pressure.
+func (p MemoryPressure) Message() string {
+	msg := fmt.Sprintf("%s memory %.2f%% used (%s / %s)", p.UUID, p.UsedPercent, humanize.Bytes(p.UsedBytes), humanize.Bytes(p.TotalBytes))
+	if p.SecondsToFull > 0 {
+		msg += fmt.Sprintf(", growing %s/min, forecast to run out in %s",
+			humanize.Bytes(uint64(p.GrowthBytesPerSecond*60)),
+			(time.Duration(p.SecondsToFull) * time.Second).String(),
+		)
+	}
+	if len(p.TopProcesses) > 0 {
+		procs := make([]string, 0, len(p.TopProcesses))
+		for _, proc := range p.TopProcesses {
+			procs = append(procs, fmt.Sprintf("pid %d (%s)", proc.PID, humanize.Bytes(proc.GPUUsedMemoryBytes)))
+		}
+		msg += ", top processes: " + strings.Join(procs, ", ")
+	}
+	return msg
+}
+
+type memorySample struct {
+	ts        time.Time
+	usedBytes uint64
+}
+
+// pressureTracker tracks the per-GPU memory usage over the checks,
+// to detect the memory pressure before the allocations fail.
+// Not safe for concurrent use, only used by the check.
+type pressureTracker struct {
+	usedPercent         float64
+	forecastUsedPercent float64
+	forecastWindow      time.Duration
+
+	last map[string]memorySample
+	// pressured tracks the GPUs under memory pressure,
+	// to only warn once until the pressure is relieved
+	pressured map[string]bool
+}
+
+func newPressureTracker() *pressureTracker {
+	return &pressureTracker{
+		usedPercent:         DefaultPressureUsedPercent,
+		forecastUsedPercent: DefaultPressureForecastUsedPercent,
+		forecastWindow:      DefaultPressureForecastWindow,
+		last:                make(map[string]memorySample),
+		pressured:           make(map[string]bool),
+	}
+}
+
+// observe records the memory usage, and returns the memory pressure if the GPU
+// is under pressure, and true if the GPU newly came under pressure (to warn).
+func (t *pressureTracker) observe(ts time.Time, mem nvidianvml.Memory) (*MemoryPressure, bool) {
+	if !mem.Supported || mem.TotalBytes == 0 {
+		return nil, false
+	}
+
+	used := mem.UsedBytes + mem.ReservedBytes
+	if used > mem.TotalBytes {
+		used = mem.TotalBytes
+	}
+	p := MemoryPressure{
+		UUID:        mem.UUID,
+		UsedBytes:   used,
+		TotalBytes:  mem.TotalBytes,
+		UsedPercent: float64(used) / float64(mem.TotalBytes) * 100,
+	}
+
+	prev, ok := t.last[mem.UUID]
+	t.last[mem.UUID] = memorySample{ts: ts, usedBytes: used}
+	if ok && used > prev.usedBytes && ts.After(prev.ts) {
+		p.GrowthBytesPerSecond = float64(used-prev.usedBytes) / ts.Sub(prev.ts).Seconds()
+		p.SecondsToFull = float64(mem.TotalBytes-used) / p.GrowthBytesPerSecond
+	}
+
+	if p.UsedPercent < t.forecastUsedPercent {
+		delete(t.pressured, mem.UUID)
+		return nil, false
+	}
+
+	forecastFull := p.SecondsToFull > 0 && p.SecondsToFull <= t.forecastWindow.Seconds()
+	underPressure := p.UsedPercent >= t.usedPercent || forecastFull
+	if !underPressure {
+		// still above the forecast threshold, stays under pressure
+		// if already warned, to not warn again on the fluctuations
+		if !t.pressured[mem.UUID] {
+			return nil, false
+		}
+		return &p, false
+	}
+
+	warn := !t.pressured[mem.UUID]
+	t.pressured[mem.UUID] = true
+	return &p, warn
+}
+
+// topProcesses returns the top n memory-consuming processes.
+func topProcesses(procs []nvidianvml.Process, n int) []nvidianvml.Process {
+	sorted := make([]nvidianvml.Process, len(procs))
+	copy(sorted, procs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].GPUUsedMemoryBytes > sorted[j].GPUUsedMemoryBytes
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
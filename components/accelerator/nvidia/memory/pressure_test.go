@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/go-nvlib/pkg/nvlib/device"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+const gib = uint64(1 << 30)
+
+func testMemory(used uint64) nvidianvml.Memory {
+	return nvidianvml.Memory{
+		UUID:          "GPU-0",
+		TotalBytes:    80 * gib,
+		ReservedBytes: gib,
+		UsedBytes:     used - gib,
+		Supported:     true,
+	}
+}
+
+func TestPressureTracker(t *testing.T) {
+	tr := newPressureTracker()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// below the forecast threshold
+	p, warn := tr.observe(base, testMemory(40*gib))
+	assert.Nil(t, p)
+	assert.False(t, warn)
+
+	// fast growth, but below the forecast threshold (e.g., model loading)
+	p, warn = tr.observe(base.Add(time.Minute), testMemory(60*gib))
+	assert.Nil(t, p)
+	assert.False(t, warn)
+
+	// above the forecast threshold, forecast to run out in 5 minutes
+	p, warn = tr.observe(base.Add(2*time.Minute), testMemory(66*gib))
+	require.NotNil(t, p)
+	assert.True(t, warn)
+	assert.Equal(t, 66*gib, p.UsedBytes)
+	assert.InDelta(t, 82.5, p.UsedPercent, 0.01)
+	assert.InDelta(t, float64(6*gib)/60, p.GrowthBytesPerSecond, 1)
+	assert.InDelta(t, 140, p.SecondsToFull, 0.1)
+	assert.Contains(t, p.Message(), "GPU-0 memory 82.50% used")
+	assert.Contains(t, p.Message(), "forecast to run out in 2m20s")
+
+	// stays under pressure without growth, but does not warn again
+	p, warn = tr.observe(base.Add(3*time.Minute), testMemory(66*gib))
+	require.NotNil(t, p)
+	assert.False(t, warn)
+	assert.Zero(t, p.SecondsToFull)
+
+	// relieved
+	p, warn = tr.observe(base.Add(4*time.Minute), testMemory(30*gib))
+	assert.Nil(t, p)
+	assert.False(t, warn)
+
+	// above the forecast threshold, but growing slowly, not under pressure
+	p, warn = tr.observe(base.Add(time.Hour), testMemory(70*gib))
+	assert.Nil(t, p)
+	assert.False(t, warn)
+	p, warn = tr.observe(base.Add(2*time.Hour), testMemory(70*gib))
+	assert.Nil(t, p)
+	assert.False(t, warn)
+
+	// above the pressure threshold regardless of the growth
+	p, warn = tr.observe(base.Add(3*time.Hour), testMemory(77*gib))
+	require.NotNil(t, p)
+	assert.True(t, warn)
+
+	// unsupported
+	p, warn = tr.observe(base, nvidianvml.Memory{UUID: "GPU-1"})
+	assert.Nil(t, p)
+	assert.False(t, warn)
+}
+
+func TestTopProcesses(t *testing.T) {
+	procs := []nvidianvml.Process{
+		{PID: 1, GPUUsedMemoryBytes: 10},
+		{PID: 2, GPUUsedMemoryBytes: 30},
+		{PID: 3, GPUUsedMemoryBytes: 20},
+	}
+	top := topProcesses(procs, 2)
+	require.Len(t, top, 2)
+	assert.Equal(t, uint32(2), top[0].PID)
+	assert.Equal(t, uint32(3), top[1].PID)
+	assert.Equal(t, uint32(1), procs[0].PID, "input not mutated")
+}
+
+func TestCheckMemoryPressureEvent(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+
+	cache := nvidianvml.NewProcessesCache()
+	cache.Set(time.Now(), []nvidianvml.Processes{
+		{UUID: "GPU-0", RunningProcesses: []nvidianvml.Process{
+			{PID: 100, GPUUsedMemoryBytes: 10 * gib},
+			{PID: 200, GPUUsedMemoryBytes: 60 * gib},
+		}},
+	})
+
+	comp, err := New(&components.GPUdInstance{
+		RootCtx: ctx,
+		NVMLInstance: &MockNvmlInstance{
+			nvmlExists: true,
+			DevicesFunc: func() map[string]device.Device {
+				return map[string]device.Device{"GPU-0": nil}
+			},
+		},
+		ProcessesCache: cache,
+		EventStore:     store,
+	})
+	require.NoError(t, err)
+	defer comp.Close()
+
+	c := comp.(*component)
+	c.getMemoryFunc = func(uuid string, dev device.Device) (nvidianvml.Memory, error) {
+		mem := testMemory(78 * gib)
+		mem.UsedPercent = "97.50"
+		return mem, nil
+	}
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Contains(t, cr.Summary(), "1 GPU(s) under memory pressure (GPU-0)")
+	require.Len(t, cr.Pressures, 1)
+	require.Len(t, cr.Pressures[0].TopProcesses, 2)
+	assert.Equal(t, uint32(200), cr.Pressures[0].TopProcesses[0].PID)
+
+	// warns only once
+	_ = c.Check()
+
+	evs, err := comp.Events(ctx, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	require.Len(t, evs, 1)
+	assert.Equal(t, EventNameMemoryPressure, evs[0].Name)
+	assert.Equal(t, apiv1.EventTypeWarning, evs[0].Type)
+	assert.Contains(t, evs[0].Message, "top processes: pid 200 (64 GB), pid 100 (11 GB)")
+}
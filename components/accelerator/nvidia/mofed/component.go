@@ -0,0 +1,290 @@
+// Package mofed tracks the installed MLNX_OFED/DOCA-OFED version, and warns
+// if it is not compatible with the running kernel or the NVIDIA driver,
+// or if the loaded mlx5_core module is not built from it,
+// before the mismatches cause the RDMA failures.
+package mofed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	pkghost "github.com/leptonai/gpud/pkg/host"
+	"github.com/leptonai/gpud/pkg/log"
+	querymofed "github.com/leptonai/gpud/pkg/nvidia-query/mofed"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	"github.com/leptonai/gpud/pkg/toolexec"
+)
+
+// Name is the ID of the MOFED/DOCA component.
+const Name = "accelerator-nvidia-mofed"
+
+var _ components.Component = &component{}
+
+type component struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	checkInterval time.Duration
+
+	nvmlInstance nvidianvml.Instance
+	matrix       querymofed.Matrix
+
+	getVersionFunc             func(ctx context.Context) (*querymofed.Version, error)
+	getLoadedModuleVersionFunc func() (string, bool, error)
+	getKernelVersionFunc       func() string
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	cctx, ccancel := context.WithCancel(toolexec.WithComponent(gpudInstance.RootCtx, Name))
+	return &component{
+		ctx:    cctx,
+		cancel: ccancel,
+
+		checkInterval: gpudInstance.CheckInterval(Name),
+
+		nvmlInstance: gpudInstance.NVMLInstance,
+		matrix:       querymofed.DefaultMatrix,
+
+		getVersionFunc: querymofed.GetVersion,
+		getLoadedModuleVersionFunc: func() (string, bool, error) {
+			return querymofed.GetLoadedModuleVersion(querymofed.DefaultSysModuleDir)
+		},
+		getKernelVersionFunc: pkghost.KernelVersion,
+	}, nil
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"accelerator",
+		"gpu",
+		"nvidia",
+		"network",
+		Name,
+	}
+}
+
+func (c *component) IsSupported() bool {
+	return true
+}
+
+func (c *component) Start() error {
+	go func() {
+		ticker := components.NewCheckTicker(c.checkInterval)
+		defer ticker.Stop()
+
+		for {
+			_ = components.SafeCheck(c)
+
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	return nil, nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking mofed/doca version")
+
+	cr := &checkResult{
+		ts: time.Now().UTC(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	cctx, ccancel := context.WithTimeout(c.ctx, 15*time.Second)
+	cr.Version, cr.err = c.getVersionFunc(cctx)
+	ccancel()
+	if cr.err != nil {
+		if errors.Is(cr.err, querymofed.ErrNoOFEDInfo) {
+			cr.err = nil
+			cr.health = apiv1.HealthStateTypeHealthy
+			cr.reason = "MOFED/DOCA not installed"
+			return cr
+		}
+
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = "error getting MOFED/DOCA version"
+		log.Logger.Errorw(cr.reason, "error", cr.err)
+		return cr
+	}
+
+	cr.KernelVersion = c.getKernelVersionFunc()
+	if c.nvmlInstance != nil && c.nvmlInstance.NVMLExists() {
+		cr.DriverVersion = c.nvmlInstance.DriverVersion()
+	}
+
+	moduleVersion, loaded, err := c.getLoadedModuleVersionFunc()
+	if err != nil {
+		log.Logger.Warnw("failed to get loaded mlx5_core module version", "error", err)
+	} else if loaded {
+		cr.ModuleVersion = moduleVersion
+		if moduleVersion == "" {
+			cr.Issues = append(cr.Issues, fmt.Sprintf("inbox mlx5_core loaded instead of MOFED/DOCA %s (module not built for kernel %s?)", cr.Version.Version, cr.KernelVersion))
+		} else if !cr.Version.MatchesModule(moduleVersion) {
+			cr.Issues = append(cr.Issues, fmt.Sprintf("loaded mlx5_core %s does not match installed MOFED/DOCA %s", moduleVersion, cr.Version.Version))
+		}
+	}
+
+	compat := c.matrix.Find(cr.Version.Release)
+	if compat == nil {
+		log.Logger.Debugw("mofed/doca release not in compatibility matrix", "release", cr.Version.Release)
+	} else {
+		issues, err := compat.Validate(cr.KernelVersion, cr.DriverVersion)
+		if err != nil {
+			log.Logger.Warnw("failed to validate mofed/doca compatibility", "release", cr.Version.Release, "error", err)
+		}
+		cr.Issues = append(cr.Issues, issues...)
+	}
+
+	if len(cr.Issues) > 0 {
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = fmt.Sprintf("MOFED/DOCA %s incompatible: %s", cr.Version.Version, strings.Join(cr.Issues, "; "))
+		log.Logger.Warnw(cr.reason)
+		return cr
+	}
+
+	cr.health = apiv1.HealthStateTypeHealthy
+	if compat == nil {
+		cr.reason = fmt.Sprintf("MOFED/DOCA %s not in compatibility matrix, skipped validation", cr.Version.Version)
+	} else {
+		cr.reason = fmt.Sprintf("MOFED/DOCA %s compatible with kernel %s", cr.Version.Version, cr.KernelVersion)
+		if cr.DriverVersion != "" {
+			cr.reason += fmt.Sprintf(" and nvidia driver %s", cr.DriverVersion)
+		}
+	}
+	return cr
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	// Version is the installed MOFED/DOCA version.
+	Version *querymofed.Version `json:"version,omitempty"`
+	// ModuleVersion is the loaded mlx5_core module version,
+	// empty if not loaded or the inbox module is loaded.
+	ModuleVersion string `json:"module_version,omitempty"`
+	// KernelVersion is the running kernel version.
+	KernelVersion string `json:"kernel_version,omitempty"`
+	// DriverVersion is the NVIDIA driver version.
+	DriverVersion string `json:"driver_version,omitempty"`
+	// Issues are the found incompatibilities.
+	Issues []string `json:"issues,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if cr.Version == nil {
+		return "no MOFED/DOCA found"
+	}
+
+	out := fmt.Sprintf("MOFED/DOCA %s (%s)", cr.Version.Version, cr.Version.Flavor)
+	for _, issue := range cr.Issues {
+		out += "\n" + issue
+	}
+	return out
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:      metav1.NewTime(cr.ts),
+		Component: Name,
+		Name:      Name,
+		Reason:    cr.reason,
+		Error:     cr.getError(),
+		Health:    cr.health,
+	}
+
+	if cr.Version != nil {
+		b, _ := json.Marshal(cr)
+		state.ExtraInfo = map[string]string{"data": string(b)}
+	}
+	return apiv1.HealthStates{state}
+}
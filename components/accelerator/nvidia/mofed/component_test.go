@@ -0,0 +1,154 @@
+package mofed
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	querymofed "github.com/leptonai/gpud/pkg/nvidia-query/mofed"
+)
+
+func TestComponentBasics(t *testing.T) {
+	comp, err := New(&components.GPUdInstance{RootCtx: context.Background()})
+	require.NoError(t, err)
+	defer comp.Close()
+
+	assert.Equal(t, Name, comp.Name())
+	assert.Contains(t, comp.Tags(), Name)
+	assert.True(t, comp.IsSupported())
+
+	events, err := comp.Events(context.Background(), time.Now())
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+
+	states := comp.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, "no data yet", states[0].Reason)
+}
+
+func TestCheck(t *testing.T) {
+	doca := &querymofed.Version{Flavor: querymofed.FlavorDOCA, Version: "24.10-1.1.4", Release: "24.10"}
+
+	tests := []struct {
+		name          string
+		version       *querymofed.Version
+		err           error
+		moduleVersion string
+		moduleLoaded  bool
+		kernelVersion string
+		wantHealth    apiv1.HealthStateType
+		wantReason    string
+		wantErr       bool
+		wantIssues    int
+	}{
+		{
+			name:       "not installed",
+			err:        querymofed.ErrNoOFEDInfo,
+			wantHealth: apiv1.HealthStateTypeHealthy,
+			wantReason: "MOFED/DOCA not installed",
+		},
+		{
+			name:       "ofed_info failed",
+			err:        errors.New("exit status 1"),
+			wantHealth: apiv1.HealthStateTypeUnhealthy,
+			wantReason: "error getting MOFED/DOCA version",
+			wantErr:    true,
+		},
+		{
+			name:          "compatible",
+			version:       doca,
+			moduleVersion: "24.10-1.1.4",
+			moduleLoaded:  true,
+			kernelVersion: "5.15.0-105-generic",
+			wantHealth:    apiv1.HealthStateTypeHealthy,
+			wantReason:    "MOFED/DOCA 24.10-1.1.4 compatible with kernel 5.15.0-105-generic",
+		},
+		{
+			name:          "not in matrix",
+			version:       &querymofed.Version{Flavor: querymofed.FlavorMLNXOFED, Version: "5.8-3.0.7.0", Release: "5.8"},
+			kernelVersion: "5.15.0-105-generic",
+			wantHealth:    apiv1.HealthStateTypeHealthy,
+			wantReason:    "not in compatibility matrix",
+		},
+		{
+			name:          "kernel too new",
+			version:       doca,
+			moduleVersion: "24.10-1.1.4",
+			moduleLoaded:  true,
+			kernelVersion: "6.14.0-1-generic",
+			wantHealth:    apiv1.HealthStateTypeDegraded,
+			wantReason:    "kernel 6.14.0-1-generic is newer than 6.11",
+			wantIssues:    1,
+		},
+		{
+			name:          "inbox module loaded",
+			version:       doca,
+			moduleLoaded:  true,
+			kernelVersion: "6.8.0-45-generic",
+			wantHealth:    apiv1.HealthStateTypeDegraded,
+			wantReason:    "inbox mlx5_core loaded",
+			wantIssues:    1,
+		},
+		{
+			name:          "module version mismatch",
+			version:       doca,
+			moduleVersion: "24.07-0.6.1",
+			moduleLoaded:  true,
+			kernelVersion: "6.8.0-45-generic",
+			wantHealth:    apiv1.HealthStateTypeDegraded,
+			wantReason:    "loaded mlx5_core 24.07-0.6.1 does not match",
+			wantIssues:    1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			c := &component{
+				ctx:    ctx,
+				cancel: cancel,
+				matrix: querymofed.Matrix{
+					{Release: "24.10", MinKernel: "4.18", MaxKernel: "6.11", MinDriverMajor: 535},
+				},
+				getVersionFunc: func(context.Context) (*querymofed.Version, error) {
+					return tt.version, tt.err
+				},
+				getLoadedModuleVersionFunc: func() (string, bool, error) {
+					return tt.moduleVersion, tt.moduleLoaded, nil
+				},
+				getKernelVersionFunc: func() string { return tt.kernelVersion },
+			}
+			defer c.Close()
+
+			cr := c.Check().(*checkResult)
+			assert.Equal(t, tt.wantHealth, cr.HealthStateType())
+			assert.Contains(t, cr.Summary(), tt.wantReason)
+			assert.Len(t, cr.Issues, tt.wantIssues)
+
+			states := c.LastHealthStates()
+			require.Len(t, states, 1)
+			assert.Equal(t, tt.wantHealth, states[0].Health)
+			if tt.wantErr {
+				assert.NotEmpty(t, states[0].Error)
+			} else {
+				assert.Empty(t, states[0].Error)
+			}
+			if tt.version != nil {
+				assert.Contains(t, states[0].ExtraInfo["data"], tt.version.Version)
+			}
+		})
+	}
+}
+
+func TestCheckResultNil(t *testing.T) {
+	var cr *checkResult
+	assert.Equal(t, "", cr.String())
+	assert.Equal(t, "", cr.Summary())
+	assert.Equal(t, apiv1.HealthStateType(""), cr.HealthStateType())
+	assert.Equal(t, "", cr.getError())
+}
@@ -34,6 +34,9 @@ type component struct {
 	kmsgSyncer  *kmsg.Syncer
 
 	readAllKmsg func(context.Context) ([]kmsg.Message, error)
+	// nonRoot is true if gpud runs as a non-root user,
+	// in which case the check self-disables
+	nonRoot bool
 
 	lastMu          sync.RWMutex
 	lastCheckResult *checkResult
@@ -56,7 +59,7 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		}
 
 		if os.Geteuid() == 0 {
-			c.kmsgSyncer, err = kmsg.NewSyncer(cctx, Match, c.eventBucket)
+			c.kmsgSyncer, err = kmsg.NewSyncer(cctx, Match, c.eventBucket, kmsg.WithComponent(Name))
 			if err != nil {
 				ccancel()
 				return nil, err
@@ -64,6 +67,7 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		}
 	}
 
+	c.nonRoot = !components.RunningAsRoot()
 	if runtime.GOOS == "linux" && os.Geteuid() == 0 {
 		c.readAllKmsg = kmsg.ReadAll
 	}
@@ -156,6 +160,12 @@ func (c *component) Check() components.CheckResult {
 		return cr
 	}
 
+	if c.nonRoot {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = components.RequiresRootReason("read the kernel messages")
+		return cr
+	}
+
 	if c.readAllKmsg == nil {
 		cr.reason = "kmsg reader is not set"
 		cr.health = apiv1.HealthStateTypeHealthy
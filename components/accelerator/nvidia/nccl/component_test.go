@@ -137,6 +137,10 @@ func (m *mockNVMLInstance) FabricManagerSupported() bool {
 	return args.Bool(0)
 }
 
+func (m *mockNVMLInstance) VirtualizationMode() nvidianvml.VirtualizationMode {
+	return nvidianvml.VirtualizationModeNone
+}
+
 func (m *mockNVMLInstance) GetMemoryErrorManagementCapabilities() nvidianvml.MemoryErrorManagementCapabilities {
 	args := m.Called()
 	return args.Get(0).(nvidianvml.MemoryErrorManagementCapabilities)
@@ -205,6 +209,21 @@ func TestCheck(t *testing.T) {
 		assert.Contains(t, result.Summary(), "kmsg reader is not set")
 	})
 
+	t.Run("non-root", func(t *testing.T) {
+		mockNvml := new(mockNVMLInstance)
+		mockNvml.On("NVMLExists").Return(true)
+		mockNvml.On("ProductName").Return("Test GPU")
+
+		comp := &component{
+			nvmlInstance: mockNvml,
+			nonRoot:      true,
+		}
+		result := comp.Check()
+		assert.NotNil(t, result)
+		assert.Equal(t, apiv1.HealthStateTypeHealthy, result.HealthStateType())
+		assert.Contains(t, result.Summary(), "requires root")
+	})
+
 	t.Run("readAllKmsg returns error", func(t *testing.T) {
 		mockNvml := new(mockNVMLInstance)
 		mockNvml.On("NVMLExists").Return(true)
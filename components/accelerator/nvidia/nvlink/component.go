@@ -6,6 +6,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -28,9 +30,17 @@ type component struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	checkInterval time.Duration
+
+	// triggerCh receives when the relevant kmsg events are matched,
+	// to re-check without waiting for the next tick
+	triggerCh <-chan struct{}
+
 	nvmlInstance  nvidianvml.Instance
 	getNVLinkFunc func(uuid string, dev device.Device) (nvidianvml.NVLink, error)
 
+	getThresholdsFunc func() Thresholds
+
 	lastMu          sync.RWMutex
 	lastCheckResult *checkResult
 }
@@ -38,10 +48,16 @@ type component struct {
 func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
 	c := &component{
-		ctx:           cctx,
-		cancel:        ccancel,
+		ctx:    cctx,
+		cancel: ccancel,
+
+		checkInterval: gpudInstance.CheckInterval(Name),
+
+		triggerCh:     gpudInstance.TriggerBus.Subscribe(components.TriggerTopicNVIDIAXid, components.TriggerTopicNVIDIASXid),
 		nvmlInstance:  gpudInstance.NVMLInstance,
 		getNVLinkFunc: nvidianvml.GetNVLink,
+
+		getThresholdsFunc: GetDefaultThresholds,
 	}
 	return c, nil
 }
@@ -66,16 +82,18 @@ func (c *component) IsSupported() bool {
 
 func (c *component) Start() error {
 	go func() {
-		ticker := time.NewTicker(time.Minute)
+		ticker := components.NewCheckTicker(c.checkInterval)
 		defer ticker.Stop()
 
 		for {
-			_ = c.Check()
+			_ = components.SafeCheck(c)
 
 			select {
 			case <-c.ctx.Done():
 				return
 			case <-ticker.C:
+			case <-c.triggerCh:
+				log.Logger.Infow("re-checking on trigger", "component", Name)
 			}
 		}
 	}()
@@ -129,6 +147,18 @@ func (c *component) Check() components.CheckResult {
 		return cr
 	}
 
+	if mode := c.nvmlInstance.VirtualizationMode(); mode.HostManaged() {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = fmt.Sprintf("nvlink is managed by the host (virtualization mode %q), skipping nvlink check", mode)
+		return cr
+	}
+
+	var thresholds Thresholds
+	if c.getThresholdsFunc != nil {
+		thresholds = c.getThresholdsFunc()
+	}
+
+	var belowThresholds []string
 	devs := c.nvmlInstance.Devices()
 	for uuid, dev := range devs {
 		nvLink, err := c.getNVLinkFunc(uuid, dev)
@@ -141,6 +171,9 @@ func (c *component) Check() components.CheckResult {
 		}
 
 		cr.NVLinks = append(cr.NVLinks, nvLink)
+		if msg := thresholds.below(nvLink); msg != "" {
+			belowThresholds = append(belowThresholds, msg)
+		}
 
 		if nvLink.States.AllFeatureEnabled() {
 			metricFeatureEnabled.With(prometheus.Labels{"uuid": uuid}).Set(float64(1.0))
@@ -152,6 +185,14 @@ func (c *component) Check() components.CheckResult {
 		metricCRCErrors.With(prometheus.Labels{"uuid": uuid}).Set(float64(nvLink.States.TotalCRCErrors()))
 	}
 
+	if len(belowThresholds) > 0 {
+		sort.Strings(belowThresholds)
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = "fewer nvlinks than expected: " + strings.Join(belowThresholds, ", ")
+		cr.failureCodes = []apiv1.FailureCode{apiv1.FailureCodeNVLinkInactive}
+		return cr
+	}
+
 	cr.health = apiv1.HealthStateTypeHealthy
 	cr.reason = fmt.Sprintf("all %d GPU(s) were checked, no nvlink issue found", len(devs))
 
@@ -172,6 +213,8 @@ type checkResult struct {
 	health apiv1.HealthStateType
 	// tracks the reason of the last check
 	reason string
+	// tracks the failure codes of the last check, if unhealthy
+	failureCodes []apiv1.FailureCode
 }
 
 func (cr *checkResult) ComponentName() string {
@@ -239,6 +282,8 @@ func (cr *checkResult) HealthStates() apiv1.HealthStates {
 		Reason:    cr.reason,
 		Error:     cr.getError(),
 		Health:    cr.health,
+
+		FailureCodes: cr.failureCodes,
 	}
 
 	if len(cr.NVLinks) > 0 {
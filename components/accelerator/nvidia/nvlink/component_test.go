@@ -23,6 +23,7 @@ import (
 // mockNVMLInstance implements the nvml.InstanceV2 interface for testing
 type mockNVMLInstance struct {
 	devicesFunc func() map[string]device.Device
+	virtMode    nvidianvml.VirtualizationMode
 }
 
 func (m *mockNVMLInstance) Devices() map[string]device.Device {
@@ -36,6 +37,13 @@ func (m *mockNVMLInstance) FabricManagerSupported() bool {
 	return true
 }
 
+func (m *mockNVMLInstance) VirtualizationMode() nvidianvml.VirtualizationMode {
+	if m.virtMode == "" {
+		return nvidianvml.VirtualizationModeNone
+	}
+	return m.virtMode
+}
+
 func (m *mockNVMLInstance) GetMemoryErrorManagementCapabilities() nvidianvml.MemoryErrorManagementCapabilities {
 	return nvidianvml.MemoryErrorManagementCapabilities{}
 }
@@ -206,6 +214,42 @@ func TestCheckOnce_Success(t *testing.T) {
 	assert.Equal(t, nvLink, lastCheckResult.NVLinks[0])
 }
 
+func TestCheckOnce_BelowThresholds(t *testing.T) {
+	ctx := context.Background()
+
+	uuid := "gpu-uuid-123"
+	devs := map[string]device.Device{
+		uuid: testutil.NewMockDevice(&mock.Device{}, "test-arch", "test-brand", "test-cuda", "test-pci"),
+	}
+	getDevicesFunc := func() map[string]device.Device {
+		return devs
+	}
+
+	nvLink := nvidianvml.NVLink{
+		UUID: uuid,
+		States: []nvidianvml.NVLinkState{
+			{Link: 0, FeatureEnabled: true},
+			{Link: 1, FeatureEnabled: false},
+		},
+		Supported: true,
+	}
+	getNVLinkFunc := func(uuid string, dev device.Device) (nvidianvml.NVLink, error) {
+		return nvLink, nil
+	}
+
+	component := MockNVLinkComponent(ctx, getDevicesFunc, getNVLinkFunc).(*component)
+
+	component.getThresholdsFunc = func() Thresholds { return Thresholds{AtLeastEnabledLinks: 1} }
+	cr := component.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.health)
+
+	component.getThresholdsFunc = func() Thresholds { return Thresholds{AtLeastEnabledLinks: 2} }
+	cr = component.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.health)
+	assert.Equal(t, "fewer nvlinks than expected: gpu-uuid-123 has 1 enabled nvlink(s), expected at least 2", cr.reason)
+	assert.Equal(t, []apiv1.FailureCode{apiv1.FailureCodeNVLinkInactive}, cr.HealthStates()[0].FailureCodes)
+}
+
 func TestCheckOnce_NVLinkError(t *testing.T) {
 	ctx := context.Background()
 
@@ -765,3 +809,21 @@ func TestCheck_GPULostError(t *testing.T) {
 	assert.Equal(t, "error getting nvlink", data.reason,
 		"reason should have '(GPU is lost)' suffix")
 }
+
+func TestCheckSkipsHostManagedVirtualization(t *testing.T) {
+	getDevicesFunc := func() map[string]device.Device {
+		return map[string]device.Device{"gpu-uuid-123": nil}
+	}
+	getNVLinkFunc := func(uuid string, dev device.Device) (nvidianvml.NVLink, error) {
+		t.Fatal("nvlink should not be queried for the host managed gpu")
+		return nvidianvml.NVLink{}, nil
+	}
+
+	component := MockNVLinkComponent(context.Background(), getDevicesFunc, getNVLinkFunc).(*component)
+	component.nvmlInstance.(*mockNVMLInstance).virtMode = nvidianvml.VirtualizationModeVGPU
+	component.getThresholdsFunc = func() Thresholds { return Thresholds{AtLeastEnabledLinks: 18} }
+
+	cr := component.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.health)
+	assert.Contains(t, cr.reason, "managed by the host")
+}
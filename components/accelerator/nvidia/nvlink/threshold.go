@@ -0,0 +1,54 @@
+package nvlink
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/leptonai/gpud/pkg/log"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+)
+
+// Thresholds are the expected nvlink topology of each GPU.
+type Thresholds struct {
+	// AtLeastEnabledLinks is the number of the nvlinks with the feature enabled per GPU,
+	// below which the GPU is unhealthy.
+	// Zero disables the check.
+	AtLeastEnabledLinks int `json:"at_least_enabled_links,omitempty"`
+}
+
+// below returns the description of the GPU with fewer enabled links than the threshold,
+// or empty if within the threshold.
+func (t Thresholds) below(nvLink nvidianvml.NVLink) string {
+	if t.AtLeastEnabledLinks <= 0 || !nvLink.Supported {
+		return ""
+	}
+	enabled := 0
+	for _, state := range nvLink.States {
+		if state.FeatureEnabled {
+			enabled++
+		}
+	}
+	if enabled >= t.AtLeastEnabledLinks {
+		return ""
+	}
+	return fmt.Sprintf("%s has %d enabled nvlink(s), expected at least %d", nvLink.UUID, enabled, t.AtLeastEnabledLinks)
+}
+
+var (
+	defaultThresholdsMu sync.RWMutex
+	defaultThresholds   Thresholds
+)
+
+func GetDefaultThresholds() Thresholds {
+	defaultThresholdsMu.RLock()
+	defer defaultThresholdsMu.RUnlock()
+	return defaultThresholds
+}
+
+func SetDefaultThresholds(thresholds Thresholds) {
+	log.Logger.Infow("setting default nvlink thresholds", "at_least_enabled_links", thresholds.AtLeastEnabledLinks)
+
+	defaultThresholdsMu.Lock()
+	defer defaultThresholdsMu.Unlock()
+	defaultThresholds = thresholds
+}
@@ -0,0 +1,425 @@
+// Package passthrough validates the NVIDIA GPUs passed through to the guest VM,
+// catching the common PCI passthrough misconfigurations (e.g., the GPUs bound to
+// vfio-pci in the guest, or the MSI-X interrupts not delivered to the guest).
+package passthrough
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	pkghost "github.com/leptonai/gpud/pkg/host"
+	"github.com/leptonai/gpud/pkg/log"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	"github.com/leptonai/gpud/pkg/pci"
+	"github.com/leptonai/gpud/pkg/toolexec"
+)
+
+const Name = "accelerator-nvidia-passthrough"
+
+const (
+	// nvidiaVendorID is the PCI vendor ID of NVIDIA.
+	nvidiaVendorID = "0x10de"
+	// nvidiaDriver is the kernel driver the GPUs must be bound to in the guest.
+	nvidiaDriver = "nvidia"
+)
+
+var _ components.Component = &component{}
+
+type component struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	checkInterval time.Duration
+
+	nvmlInstance nvidianvml.Instance
+	// getVMFunc returns the VM type of the host ("none" or empty if not a VM),
+	// used when NVML cannot tell (e.g., the GPUs are bound to vfio-pci in the guest)
+	getVMFunc func() string
+
+	sysfsRoot               string
+	procInterrupts          string
+	listDevicesFunc         func(sysfsRoot string, vendor string) ([]pci.SysfsDevice, error)
+	readInterruptCountsFunc func(file string) (map[int]uint64, error)
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	cctx, ccancel := context.WithCancel(toolexec.WithComponent(gpudInstance.RootCtx, Name))
+	sysfsRoot, procInterrupts := getDefaultSysfs()
+	c := &component{
+		ctx:    cctx,
+		cancel: ccancel,
+
+		checkInterval: gpudInstance.CheckInterval(Name),
+
+		nvmlInstance:            gpudInstance.NVMLInstance,
+		getVMFunc:               func() string { return pkghost.VirtualizationEnv().VM },
+		sysfsRoot:               sysfsRoot,
+		procInterrupts:          procInterrupts,
+		listDevicesFunc:         pci.ListSysfsDevices,
+		readInterruptCountsFunc: pci.ReadInterruptCounts,
+	}
+	return c, nil
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"accelerator",
+		"gpu",
+		"nvidia",
+		Name,
+	}
+}
+
+// IsSupported returns true in the VM guest, even if NVML does not see the GPUs
+// (e.g., the passed-through GPUs are bound to vfio-pci in the guest).
+func (c *component) IsSupported() bool {
+	if c.nvmlReady() && c.nvmlInstance.VirtualizationMode() == nvidianvml.VirtualizationModePassthrough {
+		return true
+	}
+	return c.isVMGuest()
+}
+
+// nvmlReady returns true if NVML is loaded and detects the GPUs.
+func (c *component) nvmlReady() bool {
+	return c.nvmlInstance != nil && c.nvmlInstance.NVMLExists() && c.nvmlInstance.ProductName() != ""
+}
+
+func (c *component) isVMGuest() bool {
+	if c.getVMFunc == nil {
+		return false
+	}
+	vm := c.getVMFunc()
+	return vm != "" && vm != "none"
+}
+
+func (c *component) Start() error {
+	go func() {
+		ticker := components.NewCheckTicker(c.checkInterval)
+		defer ticker.Stop()
+
+		for {
+			_ = components.SafeCheck(c)
+
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	return nil, nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking nvidia gpu passthrough")
+
+	cr := &checkResult{
+		ts: time.Now().UTC(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	if c.nvmlReady() {
+		if mode := c.nvmlInstance.VirtualizationMode(); mode != nvidianvml.VirtualizationModePassthrough {
+			cr.health = apiv1.HealthStateTypeHealthy
+			cr.reason = fmt.Sprintf("not a passthrough guest (virtualization mode %q), skipped", mode)
+			return cr
+		}
+	} else if !c.isVMGuest() {
+		// NVML cannot detect the GPUs, and not a VM guest to validate the passthrough
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML does not detect the GPUs and not a VM guest, skipped"
+		return cr
+	}
+
+	devs, err := c.listDevicesFunc(c.sysfsRoot, nvidiaVendorID)
+	if err != nil {
+		cr.err = err
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = "error listing pci devices"
+		log.Logger.Errorw(cr.reason, "error", cr.err)
+		return cr
+	}
+
+	// best effort, the interrupts are only checked if readable
+	irqCounts, err := c.readInterruptCountsFunc(c.procInterrupts)
+	if err != nil {
+		log.Logger.Warnw("failed to read interrupt counts", "error", err)
+		irqCounts = nil
+	}
+
+	cr.GPUs = validateGPUs(devs, c.nvmlBusIDs(), irqCounts)
+	if len(cr.GPUs) == 0 {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "no NVIDIA GPU found on the pci bus"
+		return cr
+	}
+
+	var issues []string
+	for _, gpu := range cr.GPUs {
+		if len(gpu.Issues) > 0 {
+			issues = append(issues, fmt.Sprintf("%s (%s)", gpu.BusID, strings.Join(gpu.Issues, ", ")))
+		}
+	}
+	if len(issues) == 0 {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = fmt.Sprintf("all %d passed-through GPU(s) are bound to the %s driver with working interrupts", len(cr.GPUs), nvidiaDriver)
+		return cr
+	}
+
+	cr.health = apiv1.HealthStateTypeUnhealthy
+	cr.reason = fmt.Sprintf("%d of %d passed-through GPU(s) misconfigured: %s", len(issues), len(cr.GPUs), strings.Join(issues, "; "))
+	cr.suggestedActions = &apiv1.SuggestedActions{
+		RepairActions: []apiv1.RepairActionType{apiv1.RepairActionTypeHardwareInspection},
+	}
+	return cr
+}
+
+// nvmlBusIDs returns the normalized PCI bus IDs of the GPUs visible to NVML.
+func (c *component) nvmlBusIDs() map[string]string {
+	busIDs := make(map[string]string)
+	if !c.nvmlReady() {
+		// e.g., the GPUs are bound to vfio-pci in the guest
+		return busIDs
+	}
+	for uuid, dev := range c.nvmlInstance.Devices() {
+		busID, err := dev.GetPCIBusID()
+		if err != nil {
+			log.Logger.Warnw("failed to get pci bus id", "uuid", uuid, "error", err)
+			continue
+		}
+		busIDs[pci.NormalizeBusID(busID)] = uuid
+	}
+	return busIDs
+}
+
+// validateGPUs validates the NVIDIA GPUs on the pci bus of the guest,
+// against the GPUs visible to NVML (keyed by the normalized pci bus ID),
+// and the interrupt counts (nil to skip the interrupt delivery check).
+func validateGPUs(devs []pci.SysfsDevice, nvmlBusIDs map[string]string, irqCounts map[int]uint64) []GPUStatus {
+	var gpus []GPUStatus
+	for _, dev := range devs {
+		if !isGPUClass(dev.Class) {
+			// e.g., the audio function or NVSwitch
+			continue
+		}
+
+		gpu := GPUStatus{
+			BusID:   dev.BusID,
+			UUID:    nvmlBusIDs[pci.NormalizeBusID(dev.BusID)],
+			Driver:  dev.Driver,
+			MSIIRQs: len(dev.MSIIRQs),
+		}
+
+		switch {
+		case dev.Driver == "":
+			gpu.Issues = append(gpu.Issues, "no driver bound")
+		case strings.HasPrefix(dev.Driver, "vfio"):
+			gpu.Issues = append(gpu.Issues, "bound to "+dev.Driver+" in the guest")
+		case dev.Driver != nvidiaDriver:
+			gpu.Issues = append(gpu.Issues, "bound to unexpected driver "+dev.Driver)
+		}
+
+		if gpu.UUID == "" {
+			gpu.Issues = append(gpu.Issues, "not visible to NVML")
+		}
+
+		if dev.Driver == nvidiaDriver {
+			if len(dev.MSIIRQs) == 0 {
+				gpu.Issues = append(gpu.Issues, "no MSI/MSI-X interrupts allocated")
+			} else if irqCounts != nil {
+				var total uint64
+				for _, irq := range dev.MSIIRQs {
+					total += irqCounts[irq]
+				}
+				gpu.Interrupts = &total
+				if total == 0 {
+					gpu.Issues = append(gpu.Issues, "no MSI/MSI-X interrupts received")
+				}
+			}
+		}
+
+		gpus = append(gpus, gpu)
+	}
+
+	// GPUs visible to NVML but not found on the pci bus
+	for busID, uuid := range nvmlBusIDs {
+		found := false
+		for _, gpu := range gpus {
+			if pci.NormalizeBusID(gpu.BusID) == busID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			gpus = append(gpus, GPUStatus{BusID: busID, UUID: uuid, Issues: []string{"missing on the pci bus"}})
+		}
+	}
+
+	sort.Slice(gpus, func(i, j int) bool {
+		return gpus[i].BusID < gpus[j].BusID
+	})
+	return gpus
+}
+
+// isGPUClass returns true for the VGA and 3D controller class codes.
+func isGPUClass(class string) bool {
+	return strings.HasPrefix(class, "0x0300") || strings.HasPrefix(class, "0x0302")
+}
+
+// GPUStatus is the passthrough status of the GPU.
+type GPUStatus struct {
+	BusID string `json:"bus_id"`
+	// UUID is empty if the GPU is not visible to NVML.
+	UUID   string `json:"uuid,omitempty"`
+	Driver string `json:"driver,omitempty"`
+	// MSIIRQs is the number of the allocated MSI/MSI-X interrupts.
+	MSIIRQs int `json:"msi_irqs"`
+	// Interrupts is the total number of the received MSI/MSI-X interrupts,
+	// nil if not read.
+	Interrupts *uint64  `json:"interrupts,omitempty"`
+	Issues     []string `json:"issues,omitempty"`
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	GPUs []GPUStatus `json:"gpus,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the suggested actions for the last check
+	suggestedActions *apiv1.SuggestedActions
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if len(cr.GPUs) == 0 {
+		return "no data"
+	}
+
+	buf := bytes.NewBuffer(nil)
+	table := tablewriter.NewWriter(buf)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.SetHeader([]string{"PCI Bus ID", "UUID", "Driver", "MSI IRQs", "Interrupts", "Issues"})
+	for _, gpu := range cr.GPUs {
+		interrupts := ""
+		if gpu.Interrupts != nil {
+			interrupts = strconv.FormatUint(*gpu.Interrupts, 10)
+		}
+		table.Append([]string{gpu.BusID, gpu.UUID, gpu.Driver, strconv.Itoa(gpu.MSIIRQs), interrupts, strings.Join(gpu.Issues, ", ")})
+	}
+	table.Render()
+
+	return buf.String()
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getSuggestedActions() *apiv1.SuggestedActions {
+	if cr == nil {
+		return nil
+	}
+	return cr.suggestedActions
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:             metav1.NewTime(cr.ts),
+		Component:        Name,
+		Name:             Name,
+		Reason:           cr.reason,
+		SuggestedActions: cr.getSuggestedActions(),
+		Error:            cr.getError(),
+		Health:           cr.health,
+	}
+
+	if len(cr.GPUs) > 0 {
+		b, _ := json.Marshal(cr)
+		state.ExtraInfo = map[string]string{"data": string(b)}
+	}
+	return apiv1.HealthStates{state}
+}
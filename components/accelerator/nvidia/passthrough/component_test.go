@@ -0,0 +1,245 @@
+package passthrough
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/NVIDIA/go-nvlib/pkg/nvlib/device"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	"github.com/leptonai/gpud/pkg/nvidia-query/nvml/lib"
+	"github.com/leptonai/gpud/pkg/nvidia-query/nvml/testutil"
+	"github.com/leptonai/gpud/pkg/pci"
+)
+
+type mockNVMLInstance struct {
+	devs        map[string]device.Device
+	productName string
+	virtMode    nvidianvml.VirtualizationMode
+}
+
+func (m *mockNVMLInstance) Devices() map[string]device.Device { return m.devs }
+func (m *mockNVMLInstance) GetMemoryErrorManagementCapabilities() nvidianvml.MemoryErrorManagementCapabilities {
+	return nvidianvml.MemoryErrorManagementCapabilities{}
+}
+func (m *mockNVMLInstance) ProductName() string          { return m.productName }
+func (m *mockNVMLInstance) Architecture() string         { return "" }
+func (m *mockNVMLInstance) Brand() string                { return "" }
+func (m *mockNVMLInstance) DriverVersion() string        { return "" }
+func (m *mockNVMLInstance) DriverMajor() int             { return 0 }
+func (m *mockNVMLInstance) CUDAVersion() string          { return "" }
+func (m *mockNVMLInstance) FabricManagerSupported() bool { return true }
+func (m *mockNVMLInstance) NVMLExists() bool             { return true }
+func (m *mockNVMLInstance) Library() lib.Library         { return nil }
+func (m *mockNVMLInstance) Shutdown() error              { return nil }
+func (m *mockNVMLInstance) VirtualizationMode() nvidianvml.VirtualizationMode {
+	return m.virtMode
+}
+
+func newPassthroughNVMLInstance() *mockNVMLInstance {
+	return &mockNVMLInstance{
+		devs: map[string]device.Device{
+			"GPU-0": testutil.NewMockDevice(&mock.Device{}, "test-arch", "test-brand", "test-cuda", "00000000:18:00.0"),
+			"GPU-1": testutil.NewMockDevice(&mock.Device{}, "test-arch", "test-brand", "test-cuda", "00000000:2A:00.0"),
+		},
+		productName: "NVIDIA Test GPU",
+		virtMode:    nvidianvml.VirtualizationModePassthrough,
+	}
+}
+
+func TestCheckHealthy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &component{
+		ctx:          ctx,
+		cancel:       cancel,
+		nvmlInstance: newPassthroughNVMLInstance(),
+		listDevicesFunc: func(string, string) ([]pci.SysfsDevice, error) {
+			return []pci.SysfsDevice{
+				{BusID: "0000:18:00.0", Vendor: "0x10de", Class: "0x030200", Driver: "nvidia", MSIIRQs: []int{98}},
+				{BusID: "0000:18:00.1", Vendor: "0x10de", Class: "0x040300", Driver: "snd_hda_intel"},
+				{BusID: "0000:2a:00.0", Vendor: "0x10de", Class: "0x030200", Driver: "nvidia", MSIIRQs: []int{99, 100}},
+			}, nil
+		},
+		readInterruptCountsFunc: func(string) (map[int]uint64, error) {
+			return map[int]uint64{98: 10, 99: 0, 100: 5}, nil
+		},
+	}
+	defer c.Close()
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.health, cr.reason)
+	require.Len(t, cr.GPUs, 2)
+	assert.Equal(t, "GPU-0", cr.GPUs[0].UUID)
+	require.NotNil(t, cr.GPUs[1].Interrupts)
+	assert.Equal(t, uint64(5), *cr.GPUs[1].Interrupts)
+	assert.Nil(t, cr.suggestedActions)
+}
+
+func TestCheckMisconfigured(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &component{
+		ctx:          ctx,
+		cancel:       cancel,
+		nvmlInstance: newPassthroughNVMLInstance(),
+		listDevicesFunc: func(string, string) ([]pci.SysfsDevice, error) {
+			return []pci.SysfsDevice{
+				{BusID: "0000:18:00.0", Vendor: "0x10de", Class: "0x030200", Driver: "nvidia", MSIIRQs: []int{98}},
+				{BusID: "0000:3a:00.0", Vendor: "0x10de", Class: "0x030200", Driver: "vfio-pci"},
+				{BusID: "0000:5d:00.0", Vendor: "0x10de", Class: "0x030200"},
+			}, nil
+		},
+		readInterruptCountsFunc: func(string) (map[int]uint64, error) {
+			return map[int]uint64{98: 0}, nil
+		},
+	}
+	defer c.Close()
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.health)
+	assert.Contains(t, cr.reason, "4 of 4 passed-through GPU(s) misconfigured")
+	require.NotNil(t, cr.suggestedActions)
+	assert.Equal(t, []apiv1.RepairActionType{apiv1.RepairActionTypeHardwareInspection}, cr.suggestedActions.RepairActions)
+
+	require.Len(t, cr.GPUs, 4)
+	assert.Equal(t, []string{"no MSI/MSI-X interrupts received"}, cr.GPUs[0].Issues)
+	assert.Equal(t, "0000:2a:00.0", cr.GPUs[1].BusID)
+	assert.Equal(t, []string{"missing on the pci bus"}, cr.GPUs[1].Issues)
+	assert.Equal(t, []string{"bound to vfio-pci in the guest", "not visible to NVML"}, cr.GPUs[2].Issues)
+	assert.Equal(t, []string{"no driver bound", "not visible to NVML"}, cr.GPUs[3].Issues)
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Contains(t, states[0].ExtraInfo["data"], "vfio-pci")
+}
+
+func TestCheckInterruptsNotReadable(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &component{
+		ctx:          ctx,
+		cancel:       cancel,
+		nvmlInstance: newPassthroughNVMLInstance(),
+		listDevicesFunc: func(string, string) ([]pci.SysfsDevice, error) {
+			return []pci.SysfsDevice{
+				{BusID: "0000:18:00.0", Vendor: "0x10de", Class: "0x030200", Driver: "nvidia", MSIIRQs: []int{98}},
+				{BusID: "0000:2a:00.0", Vendor: "0x10de", Class: "0x030200", Driver: "nvidia"},
+			}, nil
+		},
+		readInterruptCountsFunc: func(string) (map[int]uint64, error) {
+			return nil, errors.New("not readable")
+		},
+	}
+	defer c.Close()
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.health)
+	assert.Nil(t, cr.GPUs[0].Interrupts)
+	assert.Empty(t, cr.GPUs[0].Issues)
+	assert.Equal(t, []string{"no MSI/MSI-X interrupts allocated"}, cr.GPUs[1].Issues)
+}
+
+func TestCheckSkipsNonPassthrough(t *testing.T) {
+	nvmlInstance := newPassthroughNVMLInstance()
+	nvmlInstance.virtMode = nvidianvml.VirtualizationModeNone
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &component{
+		ctx:          ctx,
+		cancel:       cancel,
+		nvmlInstance: nvmlInstance,
+		listDevicesFunc: func(string, string) ([]pci.SysfsDevice, error) {
+			t.Fatal("pci devices should not be listed for bare-metal")
+			return nil, nil
+		},
+	}
+	defer c.Close()
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.health)
+	assert.Contains(t, cr.reason, "not a passthrough guest")
+}
+
+func TestCheckListError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &component{
+		ctx:             ctx,
+		cancel:          cancel,
+		nvmlInstance:    newPassthroughNVMLInstance(),
+		listDevicesFunc: func(string, string) ([]pci.SysfsDevice, error) { return nil, errors.New("permission denied") },
+	}
+	defer c.Close()
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.health)
+	assert.Equal(t, "permission denied", cr.getError())
+}
+
+func TestCheckVFIOBoundWithoutNVML(t *testing.T) {
+	// the guest NVML sees no GPU when all the GPUs are bound to vfio-pci
+	nvmlInstance := &mockNVMLInstance{virtMode: nvidianvml.VirtualizationModeNone}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &component{
+		ctx:          ctx,
+		cancel:       cancel,
+		nvmlInstance: nvmlInstance,
+		getVMFunc:    func() string { return "kvm" },
+		listDevicesFunc: func(string, string) ([]pci.SysfsDevice, error) {
+			return []pci.SysfsDevice{
+				{BusID: "0000:18:00.0", Vendor: "0x10de", Class: "0x030200", Driver: "vfio-pci"},
+				{BusID: "0000:2a:00.0", Vendor: "0x10de", Class: "0x030200", Driver: "vfio-pci"},
+			}, nil
+		},
+		readInterruptCountsFunc: func(string) (map[int]uint64, error) { return nil, nil },
+	}
+	defer c.Close()
+
+	assert.True(t, c.IsSupported())
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.health)
+	assert.Contains(t, cr.reason, "2 of 2 passed-through GPU(s) misconfigured")
+	require.Len(t, cr.GPUs, 2)
+	assert.Equal(t, []string{"bound to vfio-pci in the guest", "not visible to NVML"}, cr.GPUs[0].Issues)
+}
+
+func TestCheckWithoutNVMLNotGuest(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &component{
+		ctx:          ctx,
+		cancel:       cancel,
+		nvmlInstance: &mockNVMLInstance{},
+		getVMFunc:    func() string { return "none" },
+		listDevicesFunc: func(string, string) ([]pci.SysfsDevice, error) {
+			t.Fatal("pci devices should not be listed outside the guest")
+			return nil, nil
+		},
+	}
+	defer c.Close()
+
+	assert.False(t, c.IsSupported())
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.health)
+	assert.Contains(t, cr.reason, "not a VM guest")
+}
+
+func TestCheckGuestWithoutGPUs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &component{
+		ctx:                     ctx,
+		cancel:                  cancel,
+		getVMFunc:               func() string { return "kvm" },
+		listDevicesFunc:         func(string, string) ([]pci.SysfsDevice, error) { return nil, nil },
+		readInterruptCountsFunc: func(string) (map[int]uint64, error) { return nil, nil },
+	}
+	defer c.Close()
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.health)
+	assert.Equal(t, "no NVIDIA GPU found on the pci bus", cr.reason)
+}
@@ -0,0 +1,33 @@
+package passthrough
+
+import (
+	"sync"
+
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/pci"
+)
+
+var (
+	defaultSysfsMu        sync.RWMutex
+	defaultSysfsRoot      = pci.DefaultSysfsRoot
+	defaultProcInterrupts = pci.DefaultProcInterrupts
+)
+
+// getDefaultSysfs returns the sysfs directory of the PCI bus to list the GPUs in,
+// and the file of the interrupt counts.
+func getDefaultSysfs() (string, string) {
+	defaultSysfsMu.RLock()
+	defer defaultSysfsMu.RUnlock()
+	return defaultSysfsRoot, defaultProcInterrupts
+}
+
+// SetDefaultSysfs sets the sysfs directory of the PCI bus to list the GPUs in,
+// and the file of the interrupt counts (e.g., the recorded snapshots to replay the scan with).
+func SetDefaultSysfs(sysfsRoot string, procInterrupts string) {
+	log.Logger.Infow("setting default pci sysfs root", "root", sysfsRoot, "interrupts", procInterrupts)
+
+	defaultSysfsMu.Lock()
+	defer defaultSysfsMu.Unlock()
+	defaultSysfsRoot = sysfsRoot
+	defaultProcInterrupts = procInterrupts
+}
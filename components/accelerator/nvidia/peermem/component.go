@@ -19,6 +19,7 @@ import (
 	"github.com/leptonai/gpud/pkg/log"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
 	querypeermem "github.com/leptonai/gpud/pkg/nvidia-query/peermem"
+	"github.com/leptonai/gpud/pkg/toolexec"
 )
 
 const Name = "accelerator-nvidia-peermem"
@@ -29,6 +30,8 @@ type component struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	checkInterval time.Duration
+
 	nvmlInstance nvidianvml.Instance
 
 	eventBucket eventstore.Bucket
@@ -36,19 +39,26 @@ type component struct {
 
 	checkLsmodPeermemModuleFunc func(ctx context.Context) (*querypeermem.LsmodPeermemModuleOutput, error)
 
+	// nonRoot is true if gpud runs as a non-root user,
+	// in which case the check self-disables
+	nonRoot bool
+
 	lastMu          sync.RWMutex
 	lastCheckResult *checkResult
 }
 
 func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
-	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+	cctx, ccancel := context.WithCancel(toolexec.WithComponent(gpudInstance.RootCtx, Name))
 	c := &component{
 		ctx:    cctx,
 		cancel: ccancel,
 
+		checkInterval: gpudInstance.CheckInterval(Name),
+
 		nvmlInstance: gpudInstance.NVMLInstance,
 
 		checkLsmodPeermemModuleFunc: querypeermem.CheckLsmodPeermemModule,
+		nonRoot:                     !components.RunningAsRoot(),
 	}
 
 	if gpudInstance.EventStore != nil {
@@ -60,7 +70,7 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		}
 
 		if os.Geteuid() == 0 {
-			c.kmsgSyncer, err = kmsg.NewSyncer(cctx, Match, c.eventBucket)
+			c.kmsgSyncer, err = kmsg.NewSyncer(cctx, Match, c.eventBucket, kmsg.WithComponent(Name))
 			if err != nil {
 				ccancel()
 				return nil, err
@@ -91,11 +101,11 @@ func (c *component) IsSupported() bool {
 
 func (c *component) Start() error {
 	go func() {
-		ticker := time.NewTicker(time.Minute)
+		ticker := components.NewCheckTicker(c.checkInterval)
 		defer ticker.Stop()
 
 		for {
-			_ = c.Check()
+			_ = components.SafeCheck(c)
 
 			select {
 			case <-c.ctx.Done():
@@ -166,6 +176,12 @@ func (c *component) Check() components.CheckResult {
 		return cr
 	}
 
+	if c.nonRoot {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = components.RequiresRootReason("check the peermem kernel module")
+		return cr
+	}
+
 	cctx, ccancel := context.WithTimeout(c.ctx, 30*time.Second)
 	cr.PeerMemModuleOutput, cr.err = c.checkLsmodPeermemModuleFunc(cctx)
 	ccancel()
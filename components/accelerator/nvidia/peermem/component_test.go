@@ -65,6 +65,10 @@ func (m *mockNVMLInstance) FabricManagerSupported() bool {
 	return true
 }
 
+func (m *mockNVMLInstance) VirtualizationMode() nvidianvml.VirtualizationMode {
+	return nvidianvml.VirtualizationModeNone
+}
+
 func (m *mockNVMLInstance) GetMemoryErrorManagementCapabilities() nvidianvml.MemoryErrorManagementCapabilities {
 	return nvidianvml.MemoryErrorManagementCapabilities{}
 }
@@ -326,6 +330,22 @@ func TestCheckWithNVML(t *testing.T) {
 	assert.Contains(t, result.Summary(), "error checking peermem")
 }
 
+func TestCheckNonRoot(t *testing.T) {
+	mockChecker := &mockPeermemChecker{err: errors.New("requires sudo/root access")}
+
+	c := &component{
+		ctx:                         context.Background(),
+		cancel:                      func() {},
+		nvmlInstance:                &mockNVMLInstance{exists: true},
+		checkLsmodPeermemModuleFunc: mockChecker.Check,
+		nonRoot:                     true,
+	}
+
+	result := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, result.HealthStateType())
+	assert.Contains(t, result.Summary(), "requires root")
+}
+
 func TestLastHealthStates(t *testing.T) {
 	c := &component{
 		ctx:    context.Background(),
@@ -28,6 +28,8 @@ type component struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	checkInterval time.Duration
+
 	nvmlInstance           nvidianvml.Instance
 	getPersistenceModeFunc func(uuid string, dev device.Device) (nvidianvml.PersistenceMode, error)
 
@@ -38,8 +40,11 @@ type component struct {
 func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
 	c := &component{
-		ctx:                    cctx,
-		cancel:                 ccancel,
+		ctx:    cctx,
+		cancel: ccancel,
+
+		checkInterval: gpudInstance.CheckInterval(Name),
+
 		nvmlInstance:           gpudInstance.NVMLInstance,
 		getPersistenceModeFunc: nvidianvml.GetPersistenceMode,
 	}
@@ -66,11 +71,11 @@ func (c *component) IsSupported() bool {
 
 func (c *component) Start() error {
 	go func() {
-		ticker := time.NewTicker(time.Minute)
+		ticker := components.NewCheckTicker(c.checkInterval)
 		defer ticker.Stop()
 
 		for {
-			_ = c.Check()
+			_ = components.SafeCheck(c)
 
 			select {
 			case <-c.ctx.Done():
@@ -129,6 +134,12 @@ func (c *component) Check() components.CheckResult {
 		return cr
 	}
 
+	if mode := c.nvmlInstance.VirtualizationMode(); mode.HostManaged() {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = fmt.Sprintf("persistence mode is managed by the host (virtualization mode %q), skipping persistence mode check", mode)
+		return cr
+	}
+
 	devs := c.nvmlInstance.Devices()
 	for uuid, dev := range devs {
 		persistenceMode, err := c.getPersistenceModeFunc(uuid, dev)
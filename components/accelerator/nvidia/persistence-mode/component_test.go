@@ -25,6 +25,7 @@ type mockNVMLInstance struct {
 	devicesFunc      func() map[string]device.Device
 	nvmlExists       bool
 	emptyProductName bool
+	virtMode         nvidianvml.VirtualizationMode
 }
 
 func (m *mockNVMLInstance) Devices() map[string]device.Device {
@@ -73,6 +74,13 @@ func (m *mockNVMLInstance) FabricManagerSupported() bool {
 	return true
 }
 
+func (m *mockNVMLInstance) VirtualizationMode() nvidianvml.VirtualizationMode {
+	if m.virtMode == "" {
+		return nvidianvml.VirtualizationModeNone
+	}
+	return m.virtMode
+}
+
 func (m *mockNVMLInstance) NVMLExists() bool {
 	return m.nvmlExists
 }
@@ -841,3 +849,21 @@ func TestCheck_GPULostError(t *testing.T) {
 	assert.Equal(t, "error getting persistence mode", data.reason,
 		"reason should have '(GPU is lost)' suffix")
 }
+
+func TestCheck_SkipsHostManagedVirtualization(t *testing.T) {
+	getDevicesFunc := func() map[string]device.Device {
+		return map[string]device.Device{"gpu-uuid-123": nil}
+	}
+	getPersistenceModeFunc := func(uuid string, dev device.Device) (nvidianvml.PersistenceMode, error) {
+		t.Fatal("persistence mode should not be queried for the host managed gpu")
+		return nvidianvml.PersistenceMode{}, nil
+	}
+
+	component := mockComponent(context.Background(), getDevicesFunc, getPersistenceModeFunc).(*component)
+	component.nvmlInstance.(*mockNVMLInstance).virtMode = nvidianvml.VirtualizationModeSRIOVVF
+
+	data, ok := component.Check().(*checkResult)
+	require.True(t, ok)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, data.health)
+	assert.Contains(t, data.reason, "managed by the host")
+}
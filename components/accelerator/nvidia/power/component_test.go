@@ -81,6 +81,10 @@ func (m *mockNVMLInstance) FabricManagerSupported() bool {
 	return true
 }
 
+func (m *mockNVMLInstance) VirtualizationMode() nvidianvml.VirtualizationMode {
+	return nvidianvml.VirtualizationModeNone
+}
+
 func (m *mockNVMLInstance) GetMemoryErrorManagementCapabilities() nvidianvml.MemoryErrorManagementCapabilities {
 	return nvidianvml.MemoryErrorManagementCapabilities{}
 }
@@ -397,6 +401,37 @@ func TestStart(t *testing.T) {
 	assert.GreaterOrEqual(t, callCount.Load(), int32(1), "Check should have been called at least once")
 }
 
+func TestStartTriggered(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	callCount := &atomic.Int32{}
+	mockNvml := &mockNVMLInstance{
+		devices: map[string]device.Device{
+			"gpu-uuid-123": testutil.NewMockDevice(nil, "test-arch", "test-brand", "test-cuda", "test-pci"),
+		},
+	}
+
+	bus := components.NewTriggerBus()
+	c, err := New(&components.GPUdInstance{
+		RootCtx:      ctx,
+		NVMLInstance: mockNvml,
+		TriggerBus:   bus,
+	})
+	require.NoError(t, err)
+	c.(*component).getPowerFunc = func(uuid string, dev device.Device) (nvidianvml.Power, error) {
+		callCount.Add(1)
+		return nvidianvml.Power{}, nil
+	}
+
+	require.NoError(t, c.Start())
+	require.Eventually(t, func() bool { return callCount.Load() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// re-checks right away rather than waiting for the next tick
+	assert.Equal(t, 1, bus.Publish(components.TriggerTopicNVIDIAXid))
+	require.Eventually(t, func() bool { return callCount.Load() == 2 }, 5*time.Second, 10*time.Millisecond)
+}
+
 func TestClose(t *testing.T) {
 	ctx := context.Background()
 	component := MockPowerComponent(ctx, nil, nil).(*component)
@@ -28,9 +28,14 @@ type component struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	checkInterval time.Duration
+
 	nvmlInstance     nvidianvml.Instance
 	getProcessesFunc func(uuid string, dev device.Device) (nvidianvml.Processes, error)
 
+	// processesCache is updated with the processes of every successful check
+	processesCache *nvidianvml.ProcessesCache
+
 	lastMu          sync.RWMutex
 	lastCheckResult *checkResult
 }
@@ -38,10 +43,14 @@ type component struct {
 func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
 	c := &component{
-		ctx:              cctx,
-		cancel:           ccancel,
+		ctx:    cctx,
+		cancel: ccancel,
+
+		checkInterval: gpudInstance.CheckInterval(Name),
+
 		nvmlInstance:     gpudInstance.NVMLInstance,
 		getProcessesFunc: nvidianvml.GetProcesses,
+		processesCache:   gpudInstance.ProcessesCache,
 	}
 	return c, nil
 }
@@ -66,11 +75,11 @@ func (c *component) IsSupported() bool {
 
 func (c *component) Start() error {
 	go func() {
-		ticker := time.NewTicker(time.Minute)
+		ticker := components.NewCheckTicker(c.checkInterval)
 		defer ticker.Stop()
 
 		for {
-			_ = c.Check()
+			_ = components.SafeCheck(c)
 
 			select {
 			case <-c.ctx.Done():
@@ -145,6 +154,8 @@ func (c *component) Check() components.CheckResult {
 		metricRunningProcesses.With(prometheus.Labels{"uuid": uuid}).Set(float64(len(procs.RunningProcesses)))
 	}
 
+	c.processesCache.Set(cr.ts, cr.Processes)
+
 	cr.health = apiv1.HealthStateTypeHealthy
 	cr.reason = fmt.Sprintf("all %d GPU(s) were checked, no process issue found", len(devs))
 
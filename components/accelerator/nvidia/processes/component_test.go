@@ -46,6 +46,9 @@ func (m *mockNVMLInstance) DriverVersion() string        { return "1.0" }
 func (m *mockNVMLInstance) DriverMajor() int             { return 1 }
 func (m *mockNVMLInstance) CUDAVersion() string          { return "1.0" }
 func (m *mockNVMLInstance) FabricManagerSupported() bool { return true }
+func (m *mockNVMLInstance) VirtualizationMode() nvidianvml.VirtualizationMode {
+	return nvidianvml.VirtualizationModeNone
+}
 func (m *mockNVMLInstance) GetMemoryErrorManagementCapabilities() nvidianvml.MemoryErrorManagementCapabilities {
 	return nvidianvml.MemoryErrorManagementCapabilities{}
 }
@@ -195,8 +198,9 @@ func TestCheck(t *testing.T) {
 
 	// Create a mock GPUdInstance with the required fields
 	gpudInstance := &components.GPUdInstance{
-		RootCtx:      ctx,
-		NVMLInstance: mockInstance,
+		RootCtx:        ctx,
+		NVMLInstance:   mockInstance,
+		ProcessesCache: nvidianvml.NewProcessesCache(),
 	}
 
 	comp, err := New(gpudInstance)
@@ -226,6 +230,13 @@ func TestCheck(t *testing.T) {
 	require.True(t, ok)
 	assert.Equal(t, apiv1.HealthStateTypeHealthy, data.health)
 	assert.Equal(t, 1, len(data.Processes))
+
+	// Verify the processes are cached for the other components
+	procs, ts, ok := gpudInstance.ProcessesCache.Get("gpu-uuid-1")
+	require.True(t, ok)
+	assert.Equal(t, data.ts, ts)
+	require.Len(t, procs.RunningProcesses, 1)
+	assert.Equal(t, uint32(1234), procs.RunningProcesses[0].PID)
 }
 
 func TestCheckError(t *testing.T) {
@@ -32,6 +32,12 @@ type component struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	checkInterval time.Duration
+
+	// triggerCh receives when the relevant kmsg events are matched,
+	// to re-check without waiting for the next tick
+	triggerCh <-chan struct{}
+
 	nvmlInstance        nvidianvml.Instance
 	getRemappedRowsFunc func(uuid string, dev device.Device) (nvidianvml.RemappedRows, error)
 
@@ -44,8 +50,12 @@ type component struct {
 func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
 	c := &component{
-		ctx:                 cctx,
-		cancel:              ccancel,
+		ctx:    cctx,
+		cancel: ccancel,
+
+		checkInterval: gpudInstance.CheckInterval(Name),
+
+		triggerCh:           gpudInstance.TriggerBus.Subscribe(components.TriggerTopicNVIDIAXid),
 		nvmlInstance:        gpudInstance.NVMLInstance,
 		getRemappedRowsFunc: nvml.GetRemappedRows,
 	}
@@ -82,16 +92,18 @@ func (c *component) IsSupported() bool {
 
 func (c *component) Start() error {
 	go func() {
-		ticker := time.NewTicker(time.Minute)
+		ticker := components.NewCheckTicker(c.checkInterval)
 		defer ticker.Stop()
 
 		for {
-			_ = c.Check()
+			_ = components.SafeCheck(c)
 
 			select {
 			case <-c.ctx.Done():
 				return
 			case <-ticker.C:
+			case <-c.triggerCh:
+				log.Logger.Infow("re-checking on trigger", "component", Name)
 			}
 		}
 	}()
@@ -156,6 +168,12 @@ func (c *component) Check() components.CheckResult {
 		return cr
 	}
 
+	if mode := c.nvmlInstance.VirtualizationMode(); mode.HostManaged() {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = fmt.Sprintf("row remapping is managed by the host (virtualization mode %q), skipping remapped rows check", mode)
+		return cr
+	}
+
 	cr.ProductName = c.nvmlInstance.ProductName()
 	cr.MemoryErrorManagementCapabilities = c.nvmlInstance.GetMemoryErrorManagementCapabilities()
 
@@ -249,9 +267,11 @@ func (c *component) Check() components.CheckResult {
 
 		if remappedRows.QualifiesForRMA() {
 			issues = append(issues, fmt.Sprintf("%s qualifies for RMA (row remapping failed, remapped due to %d uncorrectable error(s))", uuid, remappedRows.RemappedDueToUncorrectableErrors))
+			cr.addFailureCode(apiv1.FailureCodeRowRemappingFailed)
 		}
 		if remappedRows.RequiresReset() {
 			issues = append(issues, fmt.Sprintf("%s needs reset (detected pending row remapping)", uuid))
+			cr.addFailureCode(apiv1.FailureCodeRowRemappingPending)
 		}
 	}
 
@@ -288,12 +308,24 @@ type checkResult struct {
 
 	// suggested actions
 	suggestedActions *apiv1.SuggestedActions
+	// tracks the failure codes of the last check, if unhealthy
+	failureCodes []apiv1.FailureCode
 }
 
 func (cr *checkResult) ComponentName() string {
 	return Name
 }
 
+// addFailureCode adds the failure code once, across the GPUs.
+func (cr *checkResult) addFailureCode(code apiv1.FailureCode) {
+	for _, c := range cr.failureCodes {
+		if c == code {
+			return
+		}
+	}
+	cr.failureCodes = append(cr.failureCodes, code)
+}
+
 func (cr *checkResult) String() string {
 	if cr == nil {
 		return ""
@@ -362,6 +394,7 @@ func (cr *checkResult) HealthStates() apiv1.HealthStates {
 		Error:            cr.getError(),
 		Health:           cr.health,
 		SuggestedActions: cr.suggestedActions,
+		FailureCodes:     cr.failureCodes,
 	}
 
 	if len(cr.RemappedRows) > 0 {
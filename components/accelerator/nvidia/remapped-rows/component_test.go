@@ -110,6 +110,7 @@ type mockNVMLInstance struct {
 	getDevicesFunc                           func() map[string]device.Device
 	getProductNameFunc                       func() string
 	getMemoryErrorManagementCapabilitiesFunc func() nvml.MemoryErrorManagementCapabilities
+	virtMode                                 nvml.VirtualizationMode
 }
 
 func (m *mockNVMLInstance) Devices() map[string]device.Device {
@@ -144,6 +145,13 @@ func (m *mockNVMLInstance) FabricManagerSupported() bool {
 	return true
 }
 
+func (m *mockNVMLInstance) VirtualizationMode() nvml.VirtualizationMode {
+	if m.virtMode == "" {
+		return nvml.VirtualizationModeNone
+	}
+	return m.virtMode
+}
+
 func (m *mockNVMLInstance) GetMemoryErrorManagementCapabilities() nvml.MemoryErrorManagementCapabilities {
 	return m.getMemoryErrorManagementCapabilitiesFunc()
 }
@@ -1125,6 +1133,7 @@ func TestStateTransitions(t *testing.T) {
 	assert.Equal(t, apiv1.HealthStateTypeHealthy, states[0].Health)
 	assert.Nil(t, c.lastCheckResult.suggestedActions, "Healthy state should have nil suggestedActions")
 	assert.Nil(t, states[0].SuggestedActions, "Healthy state should have nil SuggestedActions in HealthState")
+	assert.Empty(t, states[0].FailureCodes)
 
 	// Perform second check cycle - should transition to unhealthy (pending)
 	c.Check()
@@ -1132,6 +1141,7 @@ func TestStateTransitions(t *testing.T) {
 	require.Len(t, states, 1)
 	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, states[0].Health)
 	assert.Contains(t, states[0].Reason, "needs reset")
+	assert.Equal(t, []apiv1.FailureCode{apiv1.FailureCodeRowRemappingPending}, states[0].FailureCodes)
 	require.NotNil(t, c.lastCheckResult.suggestedActions, "Pending state should have suggestedActions")
 	assert.Equal(t, "row remapping pending requires GPU reset or system reboot", c.lastCheckResult.suggestedActions.Description)
 	require.Len(t, c.lastCheckResult.suggestedActions.RepairActions, 1)
@@ -1157,6 +1167,7 @@ func TestStateTransitions(t *testing.T) {
 	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, states[0].Health)
 	assert.Contains(t, states[0].Reason, "qualifies for RMA") // RMA message due to failed
 	assert.Contains(t, states[0].Reason, "needs reset")       // Reset message due to pending
+	assert.ElementsMatch(t, []apiv1.FailureCode{apiv1.FailureCodeRowRemappingFailed, apiv1.FailureCodeRowRemappingPending}, states[0].FailureCodes)
 	require.NotNil(t, c.lastCheckResult.suggestedActions, "Pending and Failed state should have suggestedActions")
 	assert.Equal(t, "row remapping failure requires hardware inspection", c.lastCheckResult.suggestedActions.Description, "Failed suggestion should take precedence")
 	require.Len(t, c.lastCheckResult.suggestedActions.RepairActions, 1)
@@ -1691,3 +1702,31 @@ func TestCheckSuggestedActionsWithNilEventBucket(t *testing.T) {
 	assert.NoError(t, errClient, "Events() should not error with nil eventBucket in TestCheckSuggestedActionsWithNilEventBucket")
 	assert.Nil(t, eventsClient, "Events() should return nil events with nil eventBucket in TestCheckSuggestedActionsWithNilEventBucket")
 }
+
+func TestCheckSkipsHostManagedVirtualization(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	nvmlInstance := &mockNVMLInstance{
+		getDevicesFunc:     func() map[string]device.Device { return map[string]device.Device{"GPU1": nil} },
+		getProductNameFunc: func() string { return "NVIDIA Test GPU" },
+		getMemoryErrorManagementCapabilitiesFunc: func() nvml.MemoryErrorManagementCapabilities {
+			return nvml.MemoryErrorManagementCapabilities{RowRemapping: true}
+		},
+		virtMode: nvml.VirtualizationModeSRIOVVF,
+	}
+
+	comp, err := New(&components.GPUdInstance{RootCtx: ctx, NVMLInstance: nvmlInstance})
+	require.NoError(t, err)
+
+	c := comp.(*component)
+	c.getRemappedRowsFunc = func(uuid string, dev device.Device) (nvml.RemappedRows, error) {
+		t.Fatal("remapped rows should not be queried for the host managed gpu")
+		return nvml.RemappedRows{}, nil
+	}
+
+	data, ok := c.Check().(*checkResult)
+	require.True(t, ok)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, data.health)
+	assert.Contains(t, data.reason, "managed by the host")
+}
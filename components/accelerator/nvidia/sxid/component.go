@@ -21,6 +21,7 @@ import (
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/eventbus"
 	"github.com/leptonai/gpud/pkg/eventstore"
 	pkghost "github.com/leptonai/gpud/pkg/host"
 	"github.com/leptonai/gpud/pkg/kmsg"
@@ -54,9 +55,19 @@ type component struct {
 	eventBucket      eventstore.Bucket
 	kmsgWatcher      kmsg.Watcher
 
+	// triggerBus notifies the other GPU components to re-check
+	// on the new matched events
+	triggerBus *components.TriggerBus
+	// eventBus delivers the new matched events to the other components
+	eventBus *eventbus.Bus
+
 	readAllKmsg  func(context.Context) ([]kmsg.Message, error)
 	extraEventCh chan *eventstore.Event
 
+	// nonRoot is true if gpud runs as a non-root user,
+	// in which case the check self-disables
+	nonRoot bool
+
 	lastMu          sync.RWMutex
 	lastCheckResult *checkResult
 
@@ -71,13 +82,16 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		cancel:           ccancel,
 		nvmlInstance:     gpudInstance.NVMLInstance,
 		rebootEventStore: gpudInstance.RebootEventStore,
+		triggerBus:       gpudInstance.TriggerBus,
+		eventBus:         gpudInstance.EventBus,
 
 		extraEventCh: make(chan *eventstore.Event, 256),
 	}
 
 	if gpudInstance.EventStore != nil {
 		var err error
-		c.eventBucket, err = gpudInstance.EventStore.Bucket(Name)
+		// buffers the inserts, to write the SXID storms in batches
+		c.eventBucket, err = gpudInstance.EventStore.Bucket(Name, eventstore.WithBuffer(eventstore.DefaultBatchSize, eventstore.DefaultFlushInterval))
 		if err != nil {
 			ccancel()
 			return nil, err
@@ -92,6 +106,7 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		}
 	}
 
+	c.nonRoot = !components.RunningAsRoot()
 	if runtime.GOOS == "linux" && os.Geteuid() == 0 {
 		c.readAllKmsg = kmsg.ReadAll
 	}
@@ -220,6 +235,12 @@ func (c *component) Check() components.CheckResult {
 		return cr
 	}
 
+	if c.nonRoot {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = components.RequiresRootReason("read the kernel messages")
+		return cr
+	}
+
 	if c.readAllKmsg == nil {
 		cr.reason = "kmsg reader is not set"
 		cr.health = apiv1.HealthStateTypeHealthy
@@ -428,6 +449,11 @@ func (c *component) start(kmsgCh <-chan kmsg.Message, updatePeriod time.Duration
 				continue
 			}
 			logger.Infow("inserted the event successfully")
+			if n := c.triggerBus.Publish(components.TriggerTopicNVIDIASXid); n > 0 {
+				logger.Infow("triggered re-checks", "subscribers", n)
+			}
+			event.Component = Name
+			c.eventBus.Publish(event)
 			if err = c.updateCurrentState(); err != nil {
 				logger.Errorw("failed to update current state", "error", err)
 				continue
@@ -473,6 +499,10 @@ func (c *component) updateCurrentState() error {
 
 	c.mu.Lock()
 	c.currState = evolveHealthyState(events)
+	if c.nonRoot && c.currState.Health == apiv1.HealthStateTypeHealthy {
+		// no new event is watched without root
+		c.currState.Reason = components.RequiresRootReason("read the kernel messages")
+	}
 	if rebootErr != "" {
 		c.currState.Error = fmt.Sprintf("%s\n%s", rebootErr, c.currState.Error)
 	}
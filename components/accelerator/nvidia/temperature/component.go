@@ -29,8 +29,24 @@ type component struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	checkInterval time.Duration
+
+	// triggerCh receives when the relevant kmsg events are matched,
+	// to re-check without waiting for the next tick
+	triggerCh <-chan struct{}
+
 	nvmlInstance       nvidianvml.Instance
 	getTemperatureFunc func(uuid string, dev device.Device) (nvidianvml.Temperature, error)
+	getThresholdsFunc  func() Thresholds
+	getUtilizationFunc func(uuid string, dev device.Device) (nvidianvml.Utilization, error)
+	getBaseboardFunc   func(uuid string, dev device.Device) (string, error)
+
+	nowFunc func() time.Time
+
+	// peerOutlierSince tracks since when each GPU has been hotter
+	// than its peers in all the checks, keyed by the UUID
+	peerMu           sync.Mutex
+	peerOutlierSince map[string]time.Time
 
 	lastMu          sync.RWMutex
 	lastCheckResult *checkResult
@@ -39,10 +55,17 @@ type component struct {
 func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
 	c := &component{
-		ctx:                cctx,
-		cancel:             ccancel,
+		ctx:    cctx,
+		cancel: ccancel,
+
+		checkInterval: gpudInstance.CheckInterval(Name),
+
+		triggerCh:          gpudInstance.TriggerBus.Subscribe(components.TriggerTopicNVIDIAXid),
 		nvmlInstance:       gpudInstance.NVMLInstance,
 		getTemperatureFunc: nvidianvml.GetTemperature,
+		getThresholdsFunc:  GetDefaultThresholds,
+		getUtilizationFunc: nvidianvml.GetUtilization,
+		getBaseboardFunc:   getBaseboard,
 	}
 	return c, nil
 }
@@ -67,16 +90,18 @@ func (c *component) IsSupported() bool {
 
 func (c *component) Start() error {
 	go func() {
-		ticker := time.NewTicker(time.Minute)
+		ticker := components.NewCheckTicker(c.checkInterval)
 		defer ticker.Stop()
 
 		for {
-			_ = c.Check()
+			_ = components.SafeCheck(c)
 
 			select {
 			case <-c.ctx.Done():
 				return
 			case <-ticker.C:
+			case <-c.triggerCh:
+				log.Logger.Infow("re-checking on trigger", "component", Name)
 			}
 		}
 	}()
@@ -105,8 +130,12 @@ func (c *component) Close() error {
 func (c *component) Check() components.CheckResult {
 	log.Logger.Infow("checking nvidia gpu temperature")
 
+	now := time.Now
+	if c.nowFunc != nil {
+		now = c.nowFunc
+	}
 	cr := &checkResult{
-		ts: time.Now().UTC(),
+		ts: now().UTC(),
 	}
 	defer func() {
 		c.lastMu.Lock()
@@ -130,7 +159,12 @@ func (c *component) Check() components.CheckResult {
 		return cr
 	}
 
+	var thresholds Thresholds
+	if c.getThresholdsFunc != nil {
+		thresholds = c.getThresholdsFunc()
+	}
 	tempThresholdExceeded := make([]string, 0)
+	samples := make([]gpuSample, 0)
 	devs := c.nvmlInstance.Devices()
 	for uuid, dev := range devs {
 		temp, err := c.getTemperatureFunc(uuid, dev)
@@ -154,6 +188,15 @@ func (c *component) Check() components.CheckResult {
 				),
 			)
 		}
+		if thresholds.GPUCoreMaxCelsius > 0 && temp.CurrentCelsiusGPUCore > thresholds.GPUCoreMaxCelsius {
+			tempThresholdExceeded = append(tempThresholdExceeded,
+				fmt.Sprintf("%s current temperature is %d °C exceeding the GPU core temperature threshold %d °C",
+					uuid,
+					temp.CurrentCelsiusGPUCore,
+					thresholds.GPUCoreMaxCelsius,
+				),
+			)
+		}
 
 		metricCurrentCelsius.With(prometheus.Labels{"uuid": uuid}).Set(float64(temp.CurrentCelsiusGPUCore))
 		metricThresholdSlowdownCelsius.With(prometheus.Labels{"uuid": uuid}).Set(float64(temp.ThresholdCelsiusSlowdown))
@@ -167,23 +210,78 @@ func (c *component) Check() components.CheckResult {
 			return cr
 		}
 		metricSlowdownUsedPercent.With(prometheus.Labels{"uuid": uuid}).Set(slowdownPct)
+
+		sample := gpuSample{uuid: uuid, celsius: temp.CurrentCelsiusGPUCore}
+		if c.getUtilizationFunc != nil {
+			util, err := c.getUtilizationFunc(uuid, dev)
+			if err != nil {
+				log.Logger.Warnw("error getting utilization, comparing temperature with all peers", "uuid", uuid, "error", err)
+			} else {
+				sample.utilPercent = util.GPUUsedPercent
+				sample.utilSupported = util.Supported
+			}
+		}
+		if c.getBaseboardFunc != nil {
+			baseboard, err := c.getBaseboardFunc(uuid, dev)
+			if err != nil {
+				log.Logger.Warnw("error getting baseboard, comparing temperature with all peers", "uuid", uuid, "error", err)
+			} else {
+				sample.baseboard = baseboard
+			}
+		}
+		samples = append(samples, sample)
 	}
 
-	if len(tempThresholdExceeded) == 0 {
+	cr.PeerOutliers = c.updatePeerOutliers(cr.ts, findPeerOutliers(samples, thresholds.peerDeltaCelsius()))
+
+	if len(tempThresholdExceeded) > 0 {
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = fmt.Sprintf("exceeded temperature thresholds: %s", strings.Join(tempThresholdExceeded, ", "))
+	} else if len(cr.PeerOutliers) > 0 {
+		hotter := make([]string, 0, len(cr.PeerOutliers))
+		for _, o := range cr.PeerOutliers {
+			hotter = append(hotter, o.String())
+		}
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = fmt.Sprintf("GPU(s) consistently hotter than peers (possible thermal contact issue): %s", strings.Join(hotter, ", "))
+	} else {
 		cr.health = apiv1.HealthStateTypeHealthy
 		cr.reason = fmt.Sprintf("all %d GPU(s) were checked, no temperature issue found", len(devs))
-	} else {
-		cr.health = apiv1.HealthStateTypeUnhealthy
-		cr.reason = fmt.Sprintf("exceeded HBM temperature thresholds: %s", strings.Join(tempThresholdExceeded, ", "))
 	}
 
 	return cr
 }
 
+// updatePeerOutliers records the outliers of the current check, and returns the ones
+// that have been outliers in all the checks for at least the DefaultPeerDeltaDuration.
+func (c *component) updatePeerOutliers(now time.Time, outliers []PeerOutlier) []PeerOutlier {
+	c.peerMu.Lock()
+	defer c.peerMu.Unlock()
+
+	prev := c.peerOutlierSince
+	c.peerOutlierSince = make(map[string]time.Time, len(outliers))
+
+	var consistent []PeerOutlier
+	for _, o := range outliers {
+		since, ok := prev[o.UUID]
+		if !ok {
+			since = now
+		}
+		c.peerOutlierSince[o.UUID] = since
+		o.Since = metav1.NewTime(since)
+		if now.Sub(since) >= DefaultPeerDeltaDuration {
+			consistent = append(consistent, o)
+		}
+	}
+	return consistent
+}
+
 var _ components.CheckResult = &checkResult{}
 
 type checkResult struct {
 	Temperatures []nvidianvml.Temperature `json:"temperatures,omitempty"`
+	// PeerOutliers are the GPUs consistently hotter than their peers under the similar load.
+	PeerOutliers []PeerOutlier `json:"peer_outliers,omitempty"`
 
 	// timestamp of the last check
 	ts time.Time
@@ -78,6 +78,10 @@ func (m *mockNVMLInstance) FabricManagerSupported() bool {
 	return true
 }
 
+func (m *mockNVMLInstance) VirtualizationMode() nvidianvml.VirtualizationMode {
+	return nvidianvml.VirtualizationModeNone
+}
+
 func (m *mockNVMLInstance) GetMemoryErrorManagementCapabilities() nvidianvml.MemoryErrorManagementCapabilities {
 	return nvidianvml.MemoryErrorManagementCapabilities{}
 }
@@ -219,6 +223,12 @@ func TestCheck_Success(t *testing.T) {
 	assert.Contains(t, data.reason, "no temperature issue found")
 	assert.Len(t, data.Temperatures, 1)
 	assert.Equal(t, temperature, data.Temperatures[0])
+
+	// exceeds the user-defined GPU core threshold
+	component.getThresholdsFunc = func() Thresholds { return Thresholds{GPUCoreMaxCelsius: 70} }
+	data = component.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, data.health)
+	assert.Contains(t, data.reason, "exceeding the GPU core temperature threshold 70 °C")
 }
 
 func TestCheck_TemperatureError(t *testing.T) {
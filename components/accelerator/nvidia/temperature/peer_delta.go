@@ -0,0 +1,146 @@
+package temperature
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/NVIDIA/go-nvlib/pkg/nvlib/device"
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultPeerDeltaCelsius is the default GPU core temperature delta above
+	// the median of the peer GPUs to flag the GPU as an outlier.
+	DefaultPeerDeltaCelsius = 15
+	// DefaultPeerDeltaDuration is how long the GPU must be an outlier for
+	// in all the checks, before marking it as degraded, to ignore the short
+	// spikes (e.g., a kernel launched on one GPU first), regardless of
+	// the check interval of the component.
+	DefaultPeerDeltaDuration = 5 * time.Minute
+
+	// peerUtilizationTolerancePercent is the GPU utilization difference
+	// within which the GPUs are considered to be under the similar load.
+	peerUtilizationTolerancePercent = 20
+	// minPeers is the minimum number of the peer GPUs under the similar load
+	// to compare against, since the hotter of two GPUs is not necessarily the faulty one.
+	minPeers = 2
+)
+
+// PeerOutlier is a GPU consistently hotter than its peers under the similar load.
+type PeerOutlier struct {
+	UUID string `json:"uuid"`
+	// CurrentCelsius is the current GPU core temperature.
+	CurrentCelsius uint32 `json:"current_celsius"`
+	// PeerMedianCelsius is the median GPU core temperature of the peers.
+	PeerMedianCelsius uint32 `json:"peer_median_celsius"`
+	// Peers is the number of the peer GPUs under the similar load.
+	Peers int `json:"peers"`
+	// Since is when the GPU became an outlier, in all the checks since.
+	Since metav1.Time `json:"since"`
+}
+
+func (o PeerOutlier) String() string {
+	return fmt.Sprintf("%s current temperature is %d °C, %d °C above the median %d °C of %d peer GPU(s) under similar load",
+		o.UUID,
+		o.CurrentCelsius,
+		o.CurrentCelsius-o.PeerMedianCelsius,
+		o.PeerMedianCelsius,
+		o.Peers,
+	)
+}
+
+// gpuSample is the temperature and the utilization of a GPU in a check.
+type gpuSample struct {
+	uuid    string
+	celsius uint32
+	// baseboard is the baseboard the GPU is on,
+	// empty to compare with all the GPUs of unknown baseboards
+	baseboard string
+	// utilPercent is the GPU utilization,
+	// ignored when comparing the load if utilSupported is false
+	utilPercent   uint32
+	utilSupported bool
+}
+
+func (s gpuSample) similarLoad(other gpuSample) bool {
+	if !s.utilSupported || !other.utilSupported {
+		return true
+	}
+	diff := int64(s.utilPercent) - int64(other.utilPercent)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= peerUtilizationTolerancePercent
+}
+
+// findPeerOutliers returns the GPUs whose temperatures are at least the delta
+// above the median of the other GPUs on the same baseboard under the similar load,
+// sorted by the UUID.
+func findPeerOutliers(samples []gpuSample, deltaCelsius uint32) []PeerOutlier {
+	if deltaCelsius == 0 {
+		return nil
+	}
+
+	var outliers []PeerOutlier
+	for i, s := range samples {
+		peers := make([]uint32, 0, len(samples))
+		for j, other := range samples {
+			if i == j || s.baseboard != other.baseboard || !s.similarLoad(other) {
+				continue
+			}
+			peers = append(peers, other.celsius)
+		}
+		if len(peers) < minPeers {
+			continue
+		}
+
+		median := medianCelsius(peers)
+		if s.celsius >= median+deltaCelsius {
+			outliers = append(outliers, PeerOutlier{
+				UUID:              s.uuid,
+				CurrentCelsius:    s.celsius,
+				PeerMedianCelsius: median,
+				Peers:             len(peers),
+			})
+		}
+	}
+	sort.Slice(outliers, func(i, j int) bool {
+		return outliers[i].UUID < outliers[j].UUID
+	})
+	return outliers
+}
+
+// getBaseboard returns the baseboard the GPU is on, to only compare the GPUs
+// cooled the same way. The GPUs on a multi-GPU board share the board ID.
+// The NVML has no baseboard ID for the SXM modules (e.g., HGX), which share
+// the baseboard with the other GPUs of the same product on the host.
+func getBaseboard(uuid string, dev device.Device) (string, error) {
+	multi, ret := dev.GetMultiGpuBoard()
+	if ret == nvml.SUCCESS && multi != 0 {
+		boardID, ret := dev.GetBoardId()
+		if ret != nvml.SUCCESS {
+			return "", fmt.Errorf("failed to get board id: %v", nvml.ErrorString(ret))
+		}
+		return fmt.Sprintf("board-%d", boardID), nil
+	}
+
+	name, ret := dev.GetName()
+	if ret != nvml.SUCCESS {
+		return "", fmt.Errorf("failed to get product name: %v", nvml.ErrorString(ret))
+	}
+	return name, nil
+}
+
+func medianCelsius(vs []uint32) uint32 {
+	sorted := make([]uint32, len(vs))
+	copy(sorted, vs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,161 @@
+package temperature
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/go-nvlib/pkg/nvlib/device"
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	"github.com/leptonai/gpud/pkg/nvidia-query/nvml/testutil"
+)
+
+func TestFindPeerOutliers(t *testing.T) {
+	samples := []gpuSample{
+		{uuid: "gpu-0", celsius: 60, utilPercent: 95, utilSupported: true},
+		{uuid: "gpu-1", celsius: 62, utilPercent: 90, utilSupported: true},
+		{uuid: "gpu-2", celsius: 80, utilPercent: 92, utilSupported: true},
+		{uuid: "gpu-3", celsius: 61, utilPercent: 99, utilSupported: true},
+	}
+	outliers := findPeerOutliers(samples, 15)
+	require.Len(t, outliers, 1)
+	assert.Equal(t, PeerOutlier{UUID: "gpu-2", CurrentCelsius: 80, PeerMedianCelsius: 61, Peers: 3}, outliers[0])
+	assert.Equal(t, "gpu-2 current temperature is 80 °C, 19 °C above the median 61 °C of 3 peer GPU(s) under similar load", outliers[0].String())
+
+	// below the delta
+	assert.Empty(t, findPeerOutliers(samples, 20))
+	// disabled
+	assert.Empty(t, findPeerOutliers(samples, 0))
+
+	// the hot GPU is the only one under load
+	samples[0].utilPercent = 0
+	samples[1].utilPercent = 5
+	samples[3].utilPercent = 0
+	assert.Empty(t, findPeerOutliers(samples, 15))
+
+	// not enough peers to compare against
+	assert.Empty(t, findPeerOutliers([]gpuSample{
+		{uuid: "gpu-0", celsius: 60},
+		{uuid: "gpu-1", celsius: 90},
+	}, 15))
+
+	// the utilization is ignored if not supported
+	outliers = findPeerOutliers([]gpuSample{
+		{uuid: "gpu-0", celsius: 60},
+		{uuid: "gpu-1", celsius: 64},
+		{uuid: "gpu-2", celsius: 30, utilPercent: 0, utilSupported: true},
+		{uuid: "gpu-3", celsius: 80, utilPercent: 100, utilSupported: true},
+	}, 15)
+	require.Len(t, outliers, 1)
+	assert.Equal(t, "gpu-3", outliers[0].UUID)
+	assert.Equal(t, uint32(62), outliers[0].PeerMedianCelsius)
+
+	// only compared with the GPUs on the same baseboard
+	assert.Empty(t, findPeerOutliers([]gpuSample{
+		{uuid: "gpu-0", celsius: 60, baseboard: "board-0"},
+		{uuid: "gpu-1", celsius: 61, baseboard: "board-0"},
+		{uuid: "gpu-2", celsius: 80, baseboard: "board-1"},
+		{uuid: "gpu-3", celsius: 81, baseboard: "board-1"},
+		{uuid: "gpu-4", celsius: 79, baseboard: "board-1"},
+	}, 15))
+}
+
+func TestThresholdsPeerDeltaCelsius(t *testing.T) {
+	// zero defaults, e.g., the thresholds updates without the field
+	assert.Equal(t, uint32(DefaultPeerDeltaCelsius), Thresholds{}.peerDeltaCelsius())
+	assert.Equal(t, uint32(10), Thresholds{PeerDeltaCelsius: 10}.peerDeltaCelsius())
+	assert.Zero(t, Thresholds{PeerDeltaCelsius: 10, DisablePeerComparison: true}.peerDeltaCelsius())
+}
+
+func TestGetBaseboard(t *testing.T) {
+	dev := testutil.NewMockDeviceWithIDs(&mock.Device{
+		GetMultiGpuBoardFunc: func() (int, nvml.Return) { return 0, nvml.SUCCESS },
+		GetNameFunc:          func() (string, nvml.Return) { return "NVIDIA H100 80GB HBM3", nvml.SUCCESS },
+	}, "test-arch", "test-brand", "test-cuda", "test-pci", "test-serial", 0, 0x1800)
+	baseboard, err := getBaseboard("gpu-0", dev)
+	require.NoError(t, err)
+	assert.Equal(t, "NVIDIA H100 80GB HBM3", baseboard)
+
+	dev = testutil.NewMockDeviceWithIDs(&mock.Device{
+		GetMultiGpuBoardFunc: func() (int, nvml.Return) { return 1, nvml.SUCCESS },
+	}, "test-arch", "test-brand", "test-cuda", "test-pci", "test-serial", 0, 0x1800)
+	baseboard, err = getBaseboard("gpu-0", dev)
+	require.NoError(t, err)
+	assert.Equal(t, "board-6144", baseboard)
+
+	dev = testutil.NewMockDeviceWithIDs(&mock.Device{
+		GetMultiGpuBoardFunc: func() (int, nvml.Return) { return 0, nvml.ERROR_NOT_SUPPORTED },
+		GetNameFunc:          func() (string, nvml.Return) { return "", nvml.ERROR_UNKNOWN },
+	}, "test-arch", "test-brand", "test-cuda", "test-pci", "test-serial", 0, 0)
+	_, err = getBaseboard("gpu-0", dev)
+	assert.Error(t, err)
+}
+
+func TestMedianCelsius(t *testing.T) {
+	assert.Equal(t, uint32(61), medianCelsius([]uint32{62, 60, 61}))
+	assert.Equal(t, uint32(61), medianCelsius([]uint32{60, 62}))
+}
+
+func TestCheckPeerOutlier(t *testing.T) {
+	temps := map[string]uint32{
+		"gpu-0": 60,
+		"gpu-1": 61,
+		"gpu-2": 62,
+		"gpu-3": 80,
+	}
+	devs := make(map[string]device.Device, len(temps))
+	for uuid := range temps {
+		uuid := uuid
+		devs[uuid] = testutil.NewMockDevice(&mock.Device{
+			GetUUIDFunc: func() (string, nvml.Return) { return uuid, nvml.SUCCESS },
+		}, "test-arch", "test-brand", "test-cuda", "test-pci")
+	}
+
+	c := MockTemperatureComponent(context.Background(), NewMockNVMLInstance(devs), func(uuid string, dev device.Device) (nvidianvml.Temperature, error) {
+		return nvidianvml.Temperature{
+			UUID:                  uuid,
+			CurrentCelsiusGPUCore: temps[uuid],
+			UsedPercentSlowdown:   "50.00",
+		}, nil
+	}).(*component)
+	defer c.Close()
+	c.getThresholdsFunc = func() Thresholds { return Thresholds{} }
+	c.getUtilizationFunc = func(uuid string, dev device.Device) (nvidianvml.Utilization, error) {
+		return nvidianvml.Utilization{UUID: uuid, GPUUsedPercent: 90, Supported: true}, nil
+	}
+
+	start := time.Unix(1700000000, 0).UTC()
+	now := start
+	c.nowFunc = func() time.Time { return now }
+
+	// not consistent yet, regardless of the number of the checks
+	for i := 0; i < 10; i++ {
+		cr := c.Check().(*checkResult)
+		assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.health, fmt.Sprintf("check %d", i))
+		assert.Empty(t, cr.PeerOutliers)
+		now = now.Add(10 * time.Second)
+	}
+
+	now = start.Add(DefaultPeerDeltaDuration)
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.health)
+	assert.Contains(t, cr.reason, "gpu-3 current temperature is 80 °C, 19 °C above the median 61 °C of 3 peer GPU(s)")
+	require.Len(t, cr.PeerOutliers, 1)
+	assert.True(t, start.Equal(cr.PeerOutliers[0].Since.Time))
+
+	// resets once the GPU cools down
+	temps["gpu-3"] = 65
+	now = now.Add(time.Minute)
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.health)
+	temps["gpu-3"] = 80
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.health)
+}
@@ -0,0 +1,66 @@
+package temperature
+
+import (
+	"sync"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// Thresholds are the user-defined temperature thresholds,
+// in addition to the thresholds reported by the GPUs.
+type Thresholds struct {
+	// GPUCoreMaxCelsius is the GPU core temperature in celsius,
+	// above which the GPU is unhealthy.
+	// Zero to only use the HBM temperature threshold reported by the GPU.
+	GPUCoreMaxCelsius uint32 `json:"gpu_core_max_celsius,omitempty"`
+	// PeerDeltaCelsius is the GPU core temperature delta in celsius above the median
+	// of the peer GPUs under the similar load, above which the GPU is degraded once
+	// consistently observed (e.g., the failed thermal paste or heatsink contact).
+	// Zero defaults to the DefaultPeerDeltaCelsius, so the thresholds updates
+	// and the persisted thresholds without the field keep the peer comparison.
+	PeerDeltaCelsius uint32 `json:"peer_delta_celsius,omitempty"`
+	// DisablePeerComparison disables the peer comparison.
+	DisablePeerComparison bool `json:"disable_peer_comparison,omitempty"`
+}
+
+// peerDeltaCelsius returns the peer delta to evaluate, or zero if disabled.
+func (t Thresholds) peerDeltaCelsius() uint32 {
+	if t.DisablePeerComparison {
+		return 0
+	}
+	if t.PeerDeltaCelsius == 0 {
+		return DefaultPeerDeltaCelsius
+	}
+	return t.PeerDeltaCelsius
+}
+
+var (
+	defaultThresholdsMu sync.RWMutex
+	defaultThresholds   = Thresholds{
+		PeerDeltaCelsius: DefaultPeerDeltaCelsius,
+	}
+)
+
+// GetDefaultThresholds returns the thresholds,
+// with the zero peer delta set to the one evaluated.
+func GetDefaultThresholds() Thresholds {
+	defaultThresholdsMu.RLock()
+	defer defaultThresholdsMu.RUnlock()
+	t := defaultThresholds
+	if !t.DisablePeerComparison {
+		t.PeerDeltaCelsius = t.peerDeltaCelsius()
+	}
+	return t
+}
+
+func SetDefaultThresholds(thresholds Thresholds) {
+	log.Logger.Infow("setting default temperature thresholds",
+		"gpu_core_max_celsius", thresholds.GPUCoreMaxCelsius,
+		"peer_delta_celsius", thresholds.PeerDeltaCelsius,
+		"disable_peer_comparison", thresholds.DisablePeerComparison,
+	)
+
+	defaultThresholdsMu.Lock()
+	defer defaultThresholdsMu.Unlock()
+	defaultThresholds = thresholds
+}
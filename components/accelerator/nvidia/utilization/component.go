@@ -1,4 +1,5 @@
-// Package utilization tracks the NVIDIA per-GPU utilization.
+// Package utilization tracks the NVIDIA per-GPU utilization,
+// and the virtual GPUs on the physical GPUs in the host vGPU mode.
 package utilization
 
 import (
@@ -6,10 +7,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/NVIDIA/go-nvlib/pkg/nvlib/device"
+	"github.com/dustin/go-humanize"
 	"github.com/olekukonko/tablewriter"
 	"github.com/prometheus/client_golang/prometheus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -28,8 +31,11 @@ type component struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	checkInterval time.Duration
+
 	nvmlInstance       nvidianvml.Instance
 	getUtilizationFunc func(uuid string, dev device.Device) (nvidianvml.Utilization, error)
+	getVGPUsFunc       func(uuid string, dev device.Device) ([]nvidianvml.VGPU, error)
 
 	lastMu          sync.RWMutex
 	lastCheckResult *checkResult
@@ -38,10 +44,14 @@ type component struct {
 func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
 	c := &component{
-		ctx:                cctx,
-		cancel:             ccancel,
+		ctx:    cctx,
+		cancel: ccancel,
+
+		checkInterval: gpudInstance.CheckInterval(Name),
+
 		nvmlInstance:       gpudInstance.NVMLInstance,
 		getUtilizationFunc: nvidianvml.GetUtilization,
+		getVGPUsFunc:       nvidianvml.GetVGPUs,
 	}
 	return c, nil
 }
@@ -66,11 +76,11 @@ func (c *component) IsSupported() bool {
 
 func (c *component) Start() error {
 	go func() {
-		ticker := time.NewTicker(time.Minute)
+		ticker := components.NewCheckTicker(c.checkInterval)
 		defer ticker.Stop()
 
 		for {
-			_ = c.Check()
+			_ = components.SafeCheck(c)
 
 			select {
 			case <-c.ctx.Done():
@@ -145,6 +155,34 @@ func (c *component) Check() components.CheckResult {
 		metricMemoryUtilPercent.With(prometheus.Labels{"uuid": uuid}).Set(float64(util.MemoryUsedPercent))
 	}
 
+	if c.nvmlInstance.VirtualizationMode() == nvidianvml.VirtualizationModeHostVGPU && c.getVGPUsFunc != nil {
+		// the virtual GPUs come and go with the guest VMs,
+		// so the metrics of the stopped ones must not linger
+		metricVGPUFramebufferUsedBytes.Reset()
+		for uuid, dev := range devs {
+			vgpus, err := c.getVGPUsFunc(uuid, dev)
+			if err != nil {
+				cr.err = err
+				cr.health = apiv1.HealthStateTypeUnhealthy
+				cr.reason = "error getting vgpus"
+				log.Logger.Errorw(cr.reason, "uuid", uuid, "error", err)
+				return cr
+			}
+			cr.VGPUs = append(cr.VGPUs, vgpus...)
+
+			metricActiveVGPUs.With(prometheus.Labels{"uuid": uuid}).Set(float64(len(vgpus)))
+			for _, v := range vgpus {
+				metricVGPUFramebufferUsedBytes.With(prometheus.Labels{"uuid": uuid, "vgpu_uuid": v.UUID}).Set(float64(v.FramebufferUsedBytes))
+			}
+		}
+		sort.Slice(cr.VGPUs, func(i, j int) bool {
+			if cr.VGPUs[i].GPUUUID != cr.VGPUs[j].GPUUUID {
+				return cr.VGPUs[i].GPUUUID < cr.VGPUs[j].GPUUUID
+			}
+			return cr.VGPUs[i].UUID < cr.VGPUs[j].UUID
+		})
+	}
+
 	cr.health = apiv1.HealthStateTypeHealthy
 	cr.reason = fmt.Sprintf("all %d GPU(s) were checked, no utilization issue found", len(devs))
 
@@ -155,6 +193,9 @@ var _ components.CheckResult = &checkResult{}
 
 type checkResult struct {
 	Utilizations []nvidianvml.Utilization `json:"utilizations,omitempty"`
+	// VGPUs are the virtual GPUs running on the physical GPUs,
+	// only set in the host vGPU mode.
+	VGPUs []nvidianvml.VGPU `json:"vgpus,omitempty"`
 
 	// timestamp of the last check
 	ts time.Time
@@ -193,6 +234,22 @@ func (cr *checkResult) String() string {
 	}
 	table.Render()
 
+	if len(cr.VGPUs) > 0 {
+		buf.WriteString("\n")
+		table = tablewriter.NewWriter(buf)
+		table.SetAlignment(tablewriter.ALIGN_CENTER)
+		table.SetHeader([]string{"GPU", "vGPU", "VM", "Framebuffer Used"})
+		for _, v := range cr.VGPUs {
+			table.Append([]string{
+				v.GPUUUID,
+				v.UUID,
+				v.VMID,
+				humanize.IBytes(v.FramebufferUsedBytes),
+			})
+		}
+		table.Render()
+	}
+
 	return buf.String()
 }
 
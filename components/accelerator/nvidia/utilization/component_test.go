@@ -22,7 +22,8 @@ import (
 
 // mockInstance implements the nvidianvml.Instance interface for testing
 type mockInstance struct {
-	devices map[string]device.Device
+	devices  map[string]device.Device
+	virtMode nvidianvml.VirtualizationMode
 }
 
 func (m *mockInstance) NVMLExists() bool {
@@ -65,6 +66,13 @@ func (m *mockInstance) FabricManagerSupported() bool {
 	return true
 }
 
+func (m *mockInstance) VirtualizationMode() nvidianvml.VirtualizationMode {
+	if m.virtMode == "" {
+		return nvidianvml.VirtualizationModeNone
+	}
+	return m.virtMode
+}
+
 func (m *mockInstance) GetMemoryErrorManagementCapabilities() nvidianvml.MemoryErrorManagementCapabilities {
 	return nvidianvml.MemoryErrorManagementCapabilities{}
 }
@@ -704,3 +712,44 @@ func TestCheck_GPULostError(t *testing.T) {
 	assert.Equal(t, "error getting utilization", data.reason,
 		"reason should have '(GPU is lost)' suffix")
 }
+
+func TestCheck_HostVGPU(t *testing.T) {
+	ctx := context.Background()
+
+	uuid := "gpu-uuid-123"
+	getDevicesFunc := func() map[string]device.Device {
+		return map[string]device.Device{uuid: nil}
+	}
+	getUtilizationFunc := func(uuid string, dev device.Device) (nvidianvml.Utilization, error) {
+		return nvidianvml.Utilization{UUID: uuid, Supported: true}, nil
+	}
+
+	component := MockUtilizationComponent(ctx, getDevicesFunc, getUtilizationFunc).(*component)
+	component.getVGPUsFunc = func(uuid string, dev device.Device) ([]nvidianvml.VGPU, error) {
+		return []nvidianvml.VGPU{
+			{GPUUUID: uuid, UUID: "vgpu-1", VMID: "vm-1", FramebufferUsedBytes: 2048},
+			{GPUUUID: uuid, UUID: "vgpu-0", VMID: "vm-0", FramebufferUsedBytes: 1024},
+		}, nil
+	}
+
+	// not queried on the bare-metal gpus
+	data := component.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, data.health)
+	assert.Empty(t, data.VGPUs)
+
+	component.nvmlInstance.(*mockInstance).virtMode = nvidianvml.VirtualizationModeHostVGPU
+	data = component.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, data.health)
+	require.Len(t, data.VGPUs, 2)
+	assert.Equal(t, "vgpu-0", data.VGPUs[0].UUID)
+	assert.Equal(t, "vgpu-1", data.VGPUs[1].UUID)
+	assert.Contains(t, data.String(), "vm-1")
+
+	component.getVGPUsFunc = func(uuid string, dev device.Device) ([]nvidianvml.VGPU, error) {
+		return nil, nvidianvml.ErrGPULost
+	}
+	data = component.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, data.health)
+	assert.Equal(t, "error getting vgpus", data.reason)
+	assert.ErrorIs(t, data.err, nvidianvml.ErrGPULost)
+}
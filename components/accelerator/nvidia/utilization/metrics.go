@@ -32,11 +32,33 @@ var (
 		},
 		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
 	).MustCurryWith(componentLabel)
+
+	metricActiveVGPUs = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "active_vgpus",
+			Help:      "tracks the number of the virtual GPUs running on the physical GPU (host vGPU mode only)",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+	).MustCurryWith(componentLabel)
+
+	metricVGPUFramebufferUsedBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "vgpu_framebuffer_used_bytes",
+			Help:      "tracks the framebuffer memory used by the virtual GPU in bytes (host vGPU mode only)",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "uuid", "vgpu_uuid"}, // label is GPU ID and vGPU ID
+	).MustCurryWith(componentLabel)
 )
 
 func init() {
 	pkgmetrics.MustRegister(
 		metricGPUUtilPercent,
 		metricMemoryUtilPercent,
+		metricActiveVGPUs,
+		metricVGPUFramebufferUsedBytes,
 	)
 }
@@ -21,6 +21,8 @@ import (
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/cgroup"
+	"github.com/leptonai/gpud/pkg/eventbus"
 	"github.com/leptonai/gpud/pkg/eventstore"
 	pkghost "github.com/leptonai/gpud/pkg/host"
 	"github.com/leptonai/gpud/pkg/kmsg"
@@ -31,6 +33,11 @@ import (
 // Name is the name of the XID component.
 const Name = "accelerator-nvidia-error-xid"
 
+// Version is the implementation version of the XID component, bumped when
+// the format of its health states or events changes. Version "2" attaches
+// the affected processes to the Xid events.
+const Version = "2"
+
 const (
 	StateNameErrorXid = "error_xid"
 
@@ -42,7 +49,10 @@ const (
 	DefaultStateUpdatePeriod = 30 * time.Second
 )
 
-var _ components.Component = &component{}
+var (
+	_ components.Component = &component{}
+	_ components.Versioned = &component{}
+)
 
 type component struct {
 	ctx    context.Context
@@ -50,13 +60,28 @@ type component struct {
 
 	nvmlInstance nvidianvml.Instance
 
+	// processesCache is used to attach the processes running on the GPU
+	// to the Xid events, nil to not attach
+	processesCache *nvidianvml.ProcessesCache
+	readCgroup     func(pid int32) (cgroup.Process, error)
+
 	rebootEventStore pkghost.RebootEventStore
 	eventBucket      eventstore.Bucket
 	kmsgWatcher      kmsg.Watcher
 
+	// triggerBus notifies the other GPU components to re-check
+	// on the new matched events
+	triggerBus *components.TriggerBus
+	// eventBus delivers the new matched events to the other components
+	eventBus *eventbus.Bus
+
 	readAllKmsg  func(context.Context) ([]kmsg.Message, error)
 	extraEventCh chan *eventstore.Event
 
+	// nonRoot is true if gpud runs as a non-root user,
+	// in which case the check self-disables
+	nonRoot bool
+
 	lastMu          sync.RWMutex
 	lastCheckResult *checkResult
 
@@ -70,14 +95,19 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		ctx:              cctx,
 		cancel:           ccancel,
 		nvmlInstance:     gpudInstance.NVMLInstance,
+		processesCache:   gpudInstance.ProcessesCache,
+		readCgroup:       defaultReadCgroup,
 		rebootEventStore: gpudInstance.RebootEventStore,
+		triggerBus:       gpudInstance.TriggerBus,
+		eventBus:         gpudInstance.EventBus,
 
 		extraEventCh: make(chan *eventstore.Event, 256),
 	}
 
 	if gpudInstance.EventStore != nil {
 		var err error
-		c.eventBucket, err = gpudInstance.EventStore.Bucket(Name)
+		// buffers the inserts, to write the XID storms in batches
+		c.eventBucket, err = gpudInstance.EventStore.Bucket(Name, eventstore.WithBuffer(eventstore.DefaultBatchSize, eventstore.DefaultFlushInterval))
 		if err != nil {
 			ccancel()
 			return nil, err
@@ -92,6 +122,7 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		}
 	}
 
+	c.nonRoot = !components.RunningAsRoot()
 	if runtime.GOOS == "linux" && os.Geteuid() == 0 {
 		c.readAllKmsg = kmsg.ReadAll
 	}
@@ -101,6 +132,8 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 
 func (c *component) Name() string { return Name }
 
+func (c *component) Version() string { return Version }
+
 func (c *component) Tags() []string {
 	return []string{
 		"accelerator",
@@ -164,12 +197,7 @@ func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events,
 		return nil, err
 	}
 
-	var ret apiv1.Events
-	for _, event := range events {
-		ev := resolveXIDEvent(event)
-		ret = append(ret, ev.ToEvent())
-	}
-	return ret, nil
+	return attachAffectedProcesses(events), nil
 }
 
 func (c *component) Close() error {
@@ -220,6 +248,12 @@ func (c *component) Check() components.CheckResult {
 		return cr
 	}
 
+	if c.nonRoot {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = components.RequiresRootReason("read the kernel messages")
+		return cr
+	}
+
 	if c.readAllKmsg == nil {
 		cr.reason = "kmsg reader is not set"
 		cr.health = apiv1.HealthStateTypeHealthy
@@ -437,6 +471,31 @@ func (c *component) start(kmsgCh <-chan kmsg.Message, updatePeriod time.Duration
 				continue
 			}
 			logger.Infow("inserted the event successfully")
+
+			procs := c.findAffectedProcesses(xidErr.DeviceUUID)
+			if len(procs) > 0 {
+				procsEvent, err := newAffectedProcessesEvent(event, procs)
+				if err == nil {
+					err = c.eventBucket.Insert(c.ctx, procsEvent)
+				}
+				if err != nil {
+					logger.Errorw("failed to record affected processes", "error", err)
+				} else {
+					// copy not to mutate the inserted event
+					extraInfo := make(map[string]string, len(event.ExtraInfo)+1)
+					for k, v := range event.ExtraInfo {
+						extraInfo[k] = v
+					}
+					extraInfo[EventKeyProcesses] = procsEvent.ExtraInfo[EventKeyProcesses]
+					event.ExtraInfo = extraInfo
+				}
+			}
+
+			if n := c.triggerBus.Publish(components.TriggerTopicNVIDIAXid); n > 0 {
+				logger.Infow("triggered re-checks", "subscribers", n)
+			}
+			event.Component = Name
+			c.eventBus.Publish(event)
 			if err = c.updateCurrentState(); err != nil {
 				logger.Errorw("failed to update current state", "error", err)
 				continue
@@ -482,6 +541,10 @@ func (c *component) updateCurrentState() error {
 
 	c.mu.Lock()
 	c.currState = evolveHealthyState(events)
+	if c.nonRoot && c.currState.Health == apiv1.HealthStateTypeHealthy {
+		// no new event is watched without root
+		c.currState.Reason = components.RequiresRootReason("read the kernel messages")
+	}
 	if rebootErr != "" {
 		c.currState.Error = fmt.Sprintf("%s\n%s", rebootErr, c.currState.Error)
 	}
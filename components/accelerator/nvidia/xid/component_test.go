@@ -32,6 +32,7 @@ func TestComponentNameSimple(t *testing.T) {
 	comp, err := New(gpudInstance)
 	assert.NoError(t, err)
 	assert.Equal(t, Name, comp.Name())
+	assert.Equal(t, Version, components.VersionOf(comp))
 }
 
 func TestTags(t *testing.T) {
@@ -449,12 +450,31 @@ func TestCheck(t *testing.T) {
 
 		c := comp.(*component)
 		c.readAllKmsg = nil
+		c.nonRoot = false
 
 		result := comp.Check()
 		assert.Equal(t, apiv1.HealthStateTypeHealthy, result.HealthStateType())
 		assert.Contains(t, result.Summary(), "kmsg reader is not set")
 	})
 
+	t.Run("non-root", func(t *testing.T) {
+		mockedNVML := createMockNVMLInstance()
+		gpudInstance := &components.GPUdInstance{
+			RootCtx:      ctx,
+			NVMLInstance: mockedNVML,
+		}
+
+		comp, err := New(gpudInstance)
+		assert.NoError(t, err)
+
+		c := comp.(*component)
+		c.nonRoot = true
+
+		result := comp.Check()
+		assert.Equal(t, apiv1.HealthStateTypeHealthy, result.HealthStateType())
+		assert.Contains(t, result.Summary(), "requires root")
+	})
+
 	t.Run("with kmsg reader error", func(t *testing.T) {
 		// Using a properly implemented mock
 		mockedNVML := createMockNVMLInstance()
@@ -738,6 +758,10 @@ func (m *mockNVMLInstance) FabricManagerSupported() bool {
 	return true
 }
 
+func (m *mockNVMLInstance) VirtualizationMode() nvml.VirtualizationMode {
+	return nvml.VirtualizationModeNone
+}
+
 func (m *mockNVMLInstance) GetMemoryErrorManagementCapabilities() nvml.MemoryErrorManagementCapabilities {
 	return nvml.MemoryErrorManagementCapabilities{
 		ErrorContainment:     false,
@@ -91,11 +91,16 @@ func evolveHealthyState(events eventstore.Events) (ret apiv1.HealthState) {
 			reason = fmt.Sprintf("XID %d detected on %s", lastXidErr.Xid, lastXidErr.DeviceUUID)
 		}
 	}
+	var failureCodes []apiv1.FailureCode
+	if lastXidErr != nil && lastHealth != StateHealthy {
+		failureCodes = []apiv1.FailureCode{apiv1.FailureCodeForXID(lastXidErr.Xid)}
+	}
 	return apiv1.HealthState{
 		Name:             StateNameErrorXid,
 		Health:           translateToStateHealth(lastHealth),
 		Reason:           reason,
 		SuggestedActions: lastSuggestedAction,
+		FailureCodes:     failureCodes,
 	}
 }
 
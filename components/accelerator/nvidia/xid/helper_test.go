@@ -38,6 +38,7 @@ func TestStateUpdateBasedOnEvents(t *testing.T) {
 		state := evolveHealthyState(eventstore.Events{})
 		assert.Equal(t, apiv1.HealthStateTypeHealthy, state.Health)
 		assert.Equal(t, "XIDComponent is healthy", state.Reason)
+		assert.Empty(t, state.FailureCodes)
 	})
 
 	t.Run("critical xid", func(t *testing.T) {
@@ -56,6 +57,7 @@ func TestStateUpdateBasedOnEvents(t *testing.T) {
 		state := evolveHealthyState(events)
 		assert.Equal(t, apiv1.HealthStateTypeUnhealthy, state.Health)
 		assert.Equal(t, "XID 456 detected on PCI:0000:9b:00", state.Reason)
+		assert.Equal(t, []apiv1.FailureCode{apiv1.FailureCodeXIDOther}, state.FailureCodes)
 	})
 
 	t.Run("reboot recover", func(t *testing.T) {
@@ -79,6 +81,7 @@ func TestStateUpdateBasedOnEvents(t *testing.T) {
 		state := evolveHealthyState(events)
 		assert.Equal(t, apiv1.HealthStateTypeDegraded, state.Health)
 		assert.Equal(t, apiv1.RepairActionTypeHardwareInspection, state.SuggestedActions.RepairActions[0])
+		assert.Equal(t, []apiv1.FailureCode{apiv1.FailureCodeXIDUncorrectableECC}, state.FailureCodes)
 	})
 
 	t.Run("SetHealthy", func(t *testing.T) {
@@ -89,6 +92,7 @@ func TestStateUpdateBasedOnEvents(t *testing.T) {
 		state := evolveHealthyState(events)
 		assert.Equal(t, apiv1.HealthStateTypeHealthy, state.Health)
 		assert.Nil(t, state.SuggestedActions)
+		assert.Empty(t, state.FailureCodes)
 	})
 
 	t.Run("invalid xid", func(t *testing.T) {
@@ -0,0 +1,180 @@
+package xid
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/NVIDIA/go-nvlib/pkg/nvlib/device"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/cgroup"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/pci"
+)
+
+const (
+	// EventNameAffectedProcesses is the event recorded along with the Xid event,
+	// at the same time, with the processes running on the affected GPU.
+	// It is kept separate from the Xid event so that the Xid event deduplication
+	// does not depend on the processes.
+	EventNameAffectedProcesses = "error_xid_affected_processes"
+
+	// EventKeyProcesses is the JSON-encoded list of the affected processes.
+	EventKeyProcesses = "processes"
+)
+
+// findAffectedProcesses returns the cached processes running on the GPU of the Xid error,
+// resolved to their cgroups, or nil if the GPU or its processes are not found.
+func (c *component) findAffectedProcesses(xidDevice string) []apiv1.AffectedProcess {
+	if c.nvmlInstance == nil || c.processesCache == nil {
+		return nil
+	}
+
+	uuid := resolveGPUUUID(c.nvmlInstance.Devices(), xidDevice)
+	if uuid == "" {
+		log.Logger.Debugw("no gpu found for the xid device", "device", xidDevice)
+		return nil
+	}
+	procs, ts, ok := c.processesCache.Get(uuid)
+	if !ok {
+		return nil
+	}
+
+	affected := make([]apiv1.AffectedProcess, 0, len(procs.RunningProcesses))
+	for _, p := range procs.RunningProcesses {
+		ap := apiv1.AffectedProcess{
+			GPUUUID:            uuid,
+			PID:                p.PID,
+			CmdArgs:            p.CmdArgs,
+			GPUUsedMemoryBytes: p.GPUUsedMemoryBytes,
+		}
+
+		// the process may have exited after the processes were cached
+		cg, err := c.readCgroup(int32(p.PID))
+		if err != nil {
+			log.Logger.Debugw("failed to read cgroup of the affected process", "pid", p.PID, "error", err)
+		} else {
+			ap.Cgroup = cg.Path
+			ap.ContainerID = cg.ContainerID
+			ap.PodUID = cg.PodUID
+		}
+		affected = append(affected, ap)
+	}
+	log.Logger.Infow("found processes affected by xid", "uuid", uuid, "processes", len(affected), "cachedAt", ts)
+	return affected
+}
+
+// resolveGPUUUID returns the UUID of the GPU with the Xid device ID
+// (e.g., "PCI:0000:01:00"), or empty if not found.
+func resolveGPUUUID(devs map[string]device.Device, xidDevice string) string {
+	if _, ok := devs[xidDevice]; ok {
+		return xidDevice
+	}
+
+	// the Xid message omits the PCI function (e.g., ".0")
+	target := pciBusIDWithoutFunction(strings.TrimPrefix(xidDevice, "PCI:"))
+	for uuid, dev := range devs {
+		busID, err := dev.GetPCIBusID()
+		if err != nil {
+			continue
+		}
+		if pciBusIDWithoutFunction(busID) == target {
+			return uuid
+		}
+	}
+	return ""
+}
+
+func pciBusIDWithoutFunction(busID string) string {
+	id, _, _ := strings.Cut(pci.NormalizeBusID(busID), ".")
+	return id
+}
+
+// newAffectedProcessesEvent returns the event that records the affected processes
+// of the Xid event.
+func newAffectedProcessesEvent(xidEvent eventstore.Event, procs []apiv1.AffectedProcess) (eventstore.Event, error) {
+	b, err := json.Marshal(procs)
+	if err != nil {
+		return eventstore.Event{}, err
+	}
+	return eventstore.Event{
+		Time: xidEvent.Time,
+		Name: EventNameAffectedProcesses,
+		ExtraInfo: map[string]string{
+			EventKeyErrorXidData: xidEvent.ExtraInfo[EventKeyErrorXidData],
+			EventKeyDeviceUUID:   xidEvent.ExtraInfo[EventKeyDeviceUUID],
+			EventKeyProcesses:    string(b),
+		},
+	}, nil
+}
+
+// affectedProcessesKey returns the key to match the Xid event
+// with its affected processes event.
+func affectedProcessesKey(ev eventstore.Event) string {
+	return fmt.Sprintf("%d/%s/%s", ev.Time.Unix(), ev.ExtraInfo[EventKeyErrorXidData], ev.ExtraInfo[EventKeyDeviceUUID])
+}
+
+// attachAffectedProcesses converts the events to the API events, attaching the
+// affected processes to their Xid events, and drops the affected processes events.
+func attachAffectedProcesses(events eventstore.Events) apiv1.Events {
+	affected := make(map[string][]apiv1.AffectedProcess)
+	for _, event := range events {
+		if event.Name != EventNameAffectedProcesses {
+			continue
+		}
+		var procs []apiv1.AffectedProcess
+		if err := json.Unmarshal([]byte(event.ExtraInfo[EventKeyProcesses]), &procs); err != nil {
+			log.Logger.Warnw("failed to unmarshal affected processes", "error", err)
+			continue
+		}
+		affected[affectedProcessesKey(event)] = procs
+	}
+
+	var ret apiv1.Events
+	for _, event := range events {
+		if event.Name == EventNameAffectedProcesses {
+			continue
+		}
+
+		var procs []apiv1.AffectedProcess
+		if event.Name == EventNameErrorXid {
+			procs = affected[affectedProcessesKey(event)]
+		}
+
+		ev := resolveXIDEvent(event)
+		apiEv := ev.ToEvent()
+		if len(procs) > 0 {
+			apiEv.AffectedProcesses = procs
+			apiEv.Message += affectedProcessesSummary(procs)
+		}
+		ret = append(ret, apiEv)
+	}
+	return ret
+}
+
+// affectedProcessesSummary returns the message suffix that lists the affected
+// pods, or the process IDs of the processes not in a pod.
+func affectedProcessesSummary(procs []apiv1.AffectedProcess) string {
+	seen := make(map[string]struct{}, len(procs))
+	ids := make([]string, 0, len(procs))
+	for _, p := range procs {
+		id := "pid " + strconv.FormatUint(uint64(p.PID), 10)
+		if p.PodUID != "" {
+			id = "pod " + p.PodUID
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	return fmt.Sprintf(" (affected %d process(es): %s)", len(procs), strings.Join(ids, ", "))
+}
+
+// defaultReadCgroup reads the cgroup of the process from the host proc filesystem.
+func defaultReadCgroup(pid int32) (cgroup.Process, error) {
+	return cgroup.ReadProcess(cgroup.DefaultProcRoot, pid)
+}
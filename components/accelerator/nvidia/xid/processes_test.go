@@ -0,0 +1,145 @@
+package xid
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/go-nvlib/pkg/nvlib/device"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/cgroup"
+	"github.com/leptonai/gpud/pkg/eventbus"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/kmsg"
+	nvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	"github.com/leptonai/gpud/pkg/nvidia-query/nvml/testutil"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestResolveGPUUUID(t *testing.T) {
+	devs := map[string]device.Device{
+		"GPU-0": testutil.NewMockDevice(&mock.Device{}, "", "", "", "00000000:01:00.0"),
+		"GPU-1": testutil.NewMockDevice(&mock.Device{}, "", "", "", "00000000:1A:00.0"),
+	}
+
+	assert.Equal(t, "GPU-0", resolveGPUUUID(devs, "PCI:0000:01:00"))
+	assert.Equal(t, "GPU-1", resolveGPUUUID(devs, "PCI:0000:1a:00"))
+	assert.Equal(t, "GPU-1", resolveGPUUUID(devs, "0000:1A:00"))
+	assert.Equal(t, "GPU-1", resolveGPUUUID(devs, "GPU-1"))
+	assert.Equal(t, "", resolveGPUUUID(devs, "PCI:0000:02:00"))
+	assert.Equal(t, "", resolveGPUUUID(nil, "PCI:0000:01:00"))
+}
+
+func TestAffectedProcessesSummary(t *testing.T) {
+	assert.Equal(t, " (affected 3 process(es): pod a, pid 300)", affectedProcessesSummary([]apiv1.AffectedProcess{
+		{PID: 100, PodUID: "a"},
+		{PID: 200, PodUID: "a"},
+		{PID: 300},
+	}))
+}
+
+func TestXidAffectedProcesses(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+	store, err := eventstore.New(dbRW, dbRO, DefaultRetentionPeriod)
+	require.NoError(t, err)
+
+	nvmlInstance := createMockNVMLInstance()
+	nvmlInstance.devices["GPU-0"] = testutil.NewMockDevice(&mock.Device{}, "", "", "", "00000000:01:00.0")
+
+	cache := nvml.NewProcessesCache()
+	cache.Set(time.Now(), []nvml.Processes{
+		{UUID: "GPU-0", RunningProcesses: []nvml.Process{
+			{PID: 100, CmdArgs: []string{"python", "train.py"}, GPUUsedMemoryBytes: 1024},
+			{PID: 200},
+		}},
+	})
+
+	bus := eventbus.New()
+	sub := bus.Subscribe(eventbus.WithComponents(Name))
+	defer bus.Unsubscribe(sub)
+
+	comp, err := New(&components.GPUdInstance{
+		RootCtx:        ctx,
+		NVMLInstance:   nvmlInstance,
+		ProcessesCache: cache,
+		EventStore:     store,
+		EventBus:       bus,
+	})
+	require.NoError(t, err)
+	defer comp.Close()
+
+	c := comp.(*component)
+	c.readCgroup = func(pid int32) (cgroup.Process, error) {
+		if pid == 100 {
+			return cgroup.Process{Path: "/kubepods/poda/abc", ContainerID: "abc", PodUID: "a"}, nil
+		}
+		return cgroup.Process{}, errors.New("exited")
+	}
+
+	kmsgCh := make(chan kmsg.Message, 10)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.start(kmsgCh, time.Minute)
+	}()
+
+	ts := time.Now().Truncate(time.Second)
+	msg := kmsg.Message{
+		Timestamp: metav1.NewTime(ts),
+		Message:   "NVRM: Xid (PCI:0000:01:00): 79, pid=100, GPU has fallen off the bus.",
+	}
+	kmsgCh <- msg
+
+	select {
+	case ev := <-sub.C():
+		assert.Equal(t, EventNameErrorXid, ev.Name)
+		assert.Contains(t, ev.ExtraInfo[EventKeyProcesses], `"pod_uid":"a"`)
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the xid event")
+	}
+
+	// the same kmsg is deduplicated regardless of the processes
+	kmsgCh <- msg
+
+	var events apiv1.Events
+	require.Eventually(t, func() bool {
+		events, err = comp.Events(ctx, ts.Add(-time.Minute))
+		return err == nil && len(events) == 1
+	}, 5*time.Second, 50*time.Millisecond)
+
+	ev := events[0]
+	assert.Equal(t, EventNameErrorXid, ev.Name)
+	assert.Contains(t, ev.Message, "affected 2 process(es): pod a, pid 200")
+	require.Len(t, ev.AffectedProcesses, 2)
+	assert.Equal(t, apiv1.AffectedProcess{
+		GPUUUID:            "GPU-0",
+		PID:                100,
+		CmdArgs:            []string{"python", "train.py"},
+		GPUUsedMemoryBytes: 1024,
+		Cgroup:             "/kubepods/poda/abc",
+		ContainerID:        "abc",
+		PodUID:             "a",
+	}, ev.AffectedProcesses[0])
+	assert.Equal(t, apiv1.AffectedProcess{GPUUUID: "GPU-0", PID: 200}, ev.AffectedProcesses[1])
+
+	raw, err := c.eventBucket.Get(ctx, ts.Add(-time.Minute))
+	require.NoError(t, err)
+	// the xid event and its affected processes event
+	assert.Len(t, raw, 2)
+
+	cancel()
+	wg.Wait()
+}
@@ -8,13 +8,18 @@ import (
 	componentsacceleratornvidiaclockspeed "github.com/leptonai/gpud/components/accelerator/nvidia/clock-speed"
 	componentsacceleratornvidiaecc "github.com/leptonai/gpud/components/accelerator/nvidia/ecc"
 	componentsacceleratornvidiafabricmanager "github.com/leptonai/gpud/components/accelerator/nvidia/fabric-manager"
+	componentsacceleratornvidiafallenoffbus "github.com/leptonai/gpud/components/accelerator/nvidia/fallen-off-bus"
+	componentsacceleratornvidiagdr "github.com/leptonai/gpud/components/accelerator/nvidia/gdr"
+	componentsacceleratornvidiagds "github.com/leptonai/gpud/components/accelerator/nvidia/gds"
 	componentsacceleratornvidiagpm "github.com/leptonai/gpud/components/accelerator/nvidia/gpm"
 	componentsacceleratornvidiagspfirmwaremode "github.com/leptonai/gpud/components/accelerator/nvidia/gsp-firmware-mode"
 	componentsacceleratornvidiahwslowdown "github.com/leptonai/gpud/components/accelerator/nvidia/hw-slowdown"
 	componentsacceleratornvidiainfiniband "github.com/leptonai/gpud/components/accelerator/nvidia/infiniband"
 	componentsacceleratornvidiamemory "github.com/leptonai/gpud/components/accelerator/nvidia/memory"
+	componentsacceleratornvidiamofed "github.com/leptonai/gpud/components/accelerator/nvidia/mofed"
 	componentsacceleratornvidianccl "github.com/leptonai/gpud/components/accelerator/nvidia/nccl"
 	componentsacceleratornvidianvlink "github.com/leptonai/gpud/components/accelerator/nvidia/nvlink"
+	componentsacceleratornvidiapassthrough "github.com/leptonai/gpud/components/accelerator/nvidia/passthrough"
 	componentsacceleratornvidiapeermem "github.com/leptonai/gpud/components/accelerator/nvidia/peermem"
 	componentsacceleratornvidiapersistencemode "github.com/leptonai/gpud/components/accelerator/nvidia/persistence-mode"
 	componentsacceleratornvidiapower "github.com/leptonai/gpud/components/accelerator/nvidia/power"
@@ -28,12 +33,15 @@ import (
 	componentscpu "github.com/leptonai/gpud/components/cpu"
 	componentsdisk "github.com/leptonai/gpud/components/disk"
 	componentsdocker "github.com/leptonai/gpud/components/docker"
+	componentsfan "github.com/leptonai/gpud/components/fan"
 	componentsfuse "github.com/leptonai/gpud/components/fuse"
 	componentskernelmodule "github.com/leptonai/gpud/components/kernel-module"
 	componentskubelet "github.com/leptonai/gpud/components/kubelet"
 	componentslibrary "github.com/leptonai/gpud/components/library"
 	componentsmemory "github.com/leptonai/gpud/components/memory"
 	componentsnetworklatency "github.com/leptonai/gpud/components/network/latency"
+	componentsnetworklldp "github.com/leptonai/gpud/components/network/lldp"
+	componentsnetworkpeermesh "github.com/leptonai/gpud/components/network/peer-mesh"
 	componentsnfs "github.com/leptonai/gpud/components/nfs"
 	componentsos "github.com/leptonai/gpud/components/os"
 	componentspci "github.com/leptonai/gpud/components/pci"
@@ -43,45 +51,113 @@ import (
 type Component struct {
 	Name     string
 	InitFunc components.InitFunc
+
+	// Dependencies are the names of the components or the resources
+	// (e.g., components.DependencyNVML) that must be ready before this component starts.
+	Dependencies []string
+
+	// NonRootDegradation describes the features lost when gpud runs
+	// as a non-root user (e.g., reading the kernel messages).
+	// Empty if the component is fully functional without root.
+	NonRootDegradation string
+
+	// RequiredExecutables are the tools the component checks run
+	// (e.g., "ibstat"), skipped if none of them is found.
+	RequiredExecutables []string
+
+	// RequiresGPU is true if the component only checks the GPUs,
+	// thus skipped on the hosts without the GPUs (e.g., the CPU-only head nodes).
+	// False for the accelerator components checking the host NIC software stack
+	// (e.g., "ofed_info").
+	RequiresGPU bool
 }
 
+// Dependencies returns the declared dependencies of all the components.
+func Dependencies() components.Dependencies {
+	deps := make(components.Dependencies)
+	for _, c := range componentInits {
+		if len(c.Dependencies) > 0 {
+			deps[c.Name] = c.Dependencies
+		}
+	}
+	return deps
+}
+
+// RequiredExecutables returns the tools the checks run per component.
+func RequiredExecutables() map[string][]string {
+	m := make(map[string][]string)
+	for _, c := range componentInits {
+		if len(c.RequiredExecutables) > 0 {
+			m[c.Name] = c.RequiredExecutables
+		}
+	}
+	return m
+}
+
+// NonRootDegradations returns the features lost per component
+// when gpud runs as a non-root user.
+func NonRootDegradations() map[string]string {
+	m := make(map[string]string)
+	for _, c := range componentInits {
+		if c.NonRootDegradation != "" {
+			m[c.Name] = c.NonRootDegradation
+		}
+	}
+	return m
+}
+
+var nvmlDependencies = []string{components.DependencyNVML}
+
+const (
+	kmsgEventsLost = "kernel message events are not watched"
+	kmsgChecksLost = "disabled, kernel messages are not readable"
+)
+
 func All() []Component {
 	return componentInits
 }
 
 var componentInits = []Component{
-	{Name: componentscpu.Name, InitFunc: componentscpu.New},
+	{Name: componentscpu.Name, InitFunc: componentscpu.New, NonRootDegradation: kmsgEventsLost + " (e.g., CPU soft lockups)"},
 	{Name: componentscontainerd.Name, InitFunc: componentscontainerd.New},
-	{Name: componentsdisk.Name, InitFunc: componentsdisk.New},
+	{Name: componentsdisk.Name, InitFunc: componentsdisk.New, NonRootDegradation: kmsgEventsLost + " (e.g., no space left on device)"},
 	{Name: componentsdocker.Name, InitFunc: componentsdocker.New},
+	{Name: componentsfan.Name, InitFunc: componentsfan.New, Dependencies: nvmlDependencies, NonRootDegradation: "chassis fans are not read (ipmitool requires root)"},
 	{Name: componentsfuse.Name, InitFunc: componentsfuse.New},
 	{Name: componentskernelmodule.Name, InitFunc: componentskernelmodule.New},
 	{Name: componentskubelet.Name, InitFunc: componentskubelet.New},
 	{Name: componentslibrary.Name, InitFunc: componentslibrary.New},
-	{Name: componentsmemory.Name, InitFunc: componentsmemory.New},
+	{Name: componentsmemory.Name, InitFunc: componentsmemory.New, NonRootDegradation: kmsgEventsLost + " (e.g., OOM kills, EDAC errors), and BPF JIT buffer metrics are not collected"},
 	{Name: componentsnetworklatency.Name, InitFunc: componentsnetworklatency.New},
+	{Name: componentsnetworklldp.Name, InitFunc: componentsnetworklldp.New, RequiredExecutables: []string{"lldpctl"}},
+	{Name: componentsnetworkpeermesh.Name, InitFunc: componentsnetworkpeermesh.New},
 	{Name: componentsnfs.Name, InitFunc: componentsnfs.New},
-	{Name: componentsos.Name, InitFunc: componentsos.New},
-	{Name: componentspci.Name, InitFunc: componentspci.New},
+	{Name: componentsos.Name, InitFunc: componentsos.New, NonRootDegradation: kmsgEventsLost + " (e.g., VFS file-max limit reached)"},
+	{Name: componentspci.Name, InitFunc: componentspci.New, Dependencies: []string{componentsacceleratornvidiaxid.Name}},
 	{Name: componentstailscale.Name, InitFunc: componentstailscale.New},
-	{Name: componentsacceleratornvidiabadenvs.Name, InitFunc: componentsacceleratornvidiabadenvs.New},
-	{Name: componentsacceleratornvidiaclockspeed.Name, InitFunc: componentsacceleratornvidiaclockspeed.New},
-	{Name: componentsacceleratornvidiaecc.Name, InitFunc: componentsacceleratornvidiaecc.New},
-	{Name: componentsacceleratornvidiafabricmanager.Name, InitFunc: componentsacceleratornvidiafabricmanager.New},
-	{Name: componentsacceleratornvidiagpm.Name, InitFunc: componentsacceleratornvidiagpm.New},
-	{Name: componentsacceleratornvidiagspfirmwaremode.Name, InitFunc: componentsacceleratornvidiagspfirmwaremode.New},
-	{Name: componentsacceleratornvidiahwslowdown.Name, InitFunc: componentsacceleratornvidiahwslowdown.New},
-	{Name: componentsacceleratornvidiainfiniband.Name, InitFunc: componentsacceleratornvidiainfiniband.New},
-	{Name: componentsacceleratornvidiamemory.Name, InitFunc: componentsacceleratornvidiamemory.New},
-	{Name: componentsacceleratornvidianccl.Name, InitFunc: componentsacceleratornvidianccl.New},
-	{Name: componentsacceleratornvidianvlink.Name, InitFunc: componentsacceleratornvidianvlink.New},
-	{Name: componentsacceleratornvidiapeermem.Name, InitFunc: componentsacceleratornvidiapeermem.New},
-	{Name: componentsacceleratornvidiapersistencemode.Name, InitFunc: componentsacceleratornvidiapersistencemode.New},
-	{Name: componentsacceleratornvidiapower.Name, InitFunc: componentsacceleratornvidiapower.New},
-	{Name: componentsacceleratornvidiaprocesses.Name, InitFunc: componentsacceleratornvidiaprocesses.New},
-	{Name: componentsacceleratornvidiaremappedrows.Name, InitFunc: componentsacceleratornvidiaremappedrows.New},
-	{Name: componentsacceleratornvidiasxid.Name, InitFunc: componentsacceleratornvidiasxid.New},
-	{Name: componentsacceleratornvidiatemperature.Name, InitFunc: componentsacceleratornvidiatemperature.New},
-	{Name: componentsacceleratornvidiautilization.Name, InitFunc: componentsacceleratornvidiautilization.New},
-	{Name: componentsacceleratornvidiaxid.Name, InitFunc: componentsacceleratornvidiaxid.New},
+	{Name: componentsacceleratornvidiabadenvs.Name, InitFunc: componentsacceleratornvidiabadenvs.New, Dependencies: nvmlDependencies, RequiresGPU: true},
+	{Name: componentsacceleratornvidiaclockspeed.Name, InitFunc: componentsacceleratornvidiaclockspeed.New, Dependencies: nvmlDependencies, RequiresGPU: true},
+	{Name: componentsacceleratornvidiaecc.Name, InitFunc: componentsacceleratornvidiaecc.New, Dependencies: nvmlDependencies, RequiresGPU: true},
+	{Name: componentsacceleratornvidiafabricmanager.Name, InitFunc: componentsacceleratornvidiafabricmanager.New, Dependencies: nvmlDependencies, RequiredExecutables: []string{"nv-fabricmanager"}, RequiresGPU: true},
+	{Name: componentsacceleratornvidiafallenoffbus.Name, InitFunc: componentsacceleratornvidiafallenoffbus.New, Dependencies: nvmlDependencies, RequiresGPU: true},
+	{Name: componentsacceleratornvidiagdr.Name, InitFunc: componentsacceleratornvidiagdr.New, Dependencies: nvmlDependencies, RequiresGPU: true},
+	{Name: componentsacceleratornvidiagds.Name, InitFunc: componentsacceleratornvidiagds.New, Dependencies: nvmlDependencies, RequiresGPU: true},
+	{Name: componentsacceleratornvidiagpm.Name, InitFunc: componentsacceleratornvidiagpm.New, Dependencies: nvmlDependencies, RequiresGPU: true},
+	{Name: componentsacceleratornvidiagspfirmwaremode.Name, InitFunc: componentsacceleratornvidiagspfirmwaremode.New, Dependencies: nvmlDependencies, RequiresGPU: true},
+	{Name: componentsacceleratornvidiahwslowdown.Name, InitFunc: componentsacceleratornvidiahwslowdown.New, Dependencies: nvmlDependencies, RequiresGPU: true},
+	{Name: componentsacceleratornvidiainfiniband.Name, InitFunc: componentsacceleratornvidiainfiniband.New, Dependencies: nvmlDependencies, NonRootDegradation: kmsgEventsLost + " (e.g., insufficient PCI power, high port module temperature)", RequiredExecutables: []string{"ibstat"}, RequiresGPU: true},
+	{Name: componentsacceleratornvidiamemory.Name, InitFunc: componentsacceleratornvidiamemory.New, Dependencies: nvmlDependencies, RequiresGPU: true},
+	{Name: componentsacceleratornvidiamofed.Name, InitFunc: componentsacceleratornvidiamofed.New, Dependencies: nvmlDependencies, RequiredExecutables: []string{"ofed_info"}},
+	{Name: componentsacceleratornvidianccl.Name, InitFunc: componentsacceleratornvidianccl.New, Dependencies: nvmlDependencies, NonRootDegradation: kmsgChecksLost + " (NCCL segfaults)", RequiresGPU: true},
+	{Name: componentsacceleratornvidianvlink.Name, InitFunc: componentsacceleratornvidianvlink.New, Dependencies: nvmlDependencies, RequiresGPU: true},
+	{Name: componentsacceleratornvidiapassthrough.Name, InitFunc: componentsacceleratornvidiapassthrough.New, Dependencies: nvmlDependencies, RequiresGPU: true},
+	{Name: componentsacceleratornvidiapeermem.Name, InitFunc: componentsacceleratornvidiapeermem.New, Dependencies: nvmlDependencies, NonRootDegradation: "disabled, the peermem kernel module is not checked, and kernel message events are not watched", RequiresGPU: true},
+	{Name: componentsacceleratornvidiapersistencemode.Name, InitFunc: componentsacceleratornvidiapersistencemode.New, Dependencies: nvmlDependencies, RequiresGPU: true},
+	{Name: componentsacceleratornvidiapower.Name, InitFunc: componentsacceleratornvidiapower.New, Dependencies: nvmlDependencies, RequiresGPU: true},
+	{Name: componentsacceleratornvidiaprocesses.Name, InitFunc: componentsacceleratornvidiaprocesses.New, Dependencies: nvmlDependencies, RequiresGPU: true},
+	{Name: componentsacceleratornvidiaremappedrows.Name, InitFunc: componentsacceleratornvidiaremappedrows.New, Dependencies: nvmlDependencies, RequiresGPU: true},
+	{Name: componentsacceleratornvidiasxid.Name, InitFunc: componentsacceleratornvidiasxid.New, Dependencies: nvmlDependencies, NonRootDegradation: kmsgChecksLost + " (NVSwitch SXid errors)", RequiresGPU: true},
+	{Name: componentsacceleratornvidiatemperature.Name, InitFunc: componentsacceleratornvidiatemperature.New, Dependencies: nvmlDependencies, RequiresGPU: true},
+	{Name: componentsacceleratornvidiautilization.Name, InitFunc: componentsacceleratornvidiautilization.New, Dependencies: nvmlDependencies, RequiresGPU: true},
+	{Name: componentsacceleratornvidiaxid.Name, InitFunc: componentsacceleratornvidiaxid.New, Dependencies: nvmlDependencies, NonRootDegradation: kmsgChecksLost + " (GPU Xid errors)", RequiresGPU: true},
 }
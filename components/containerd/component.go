@@ -16,6 +16,7 @@ import (
 	pkgcontainerd "github.com/leptonai/gpud/pkg/containerd"
 	"github.com/leptonai/gpud/pkg/log"
 	"github.com/leptonai/gpud/pkg/systemd"
+	"github.com/leptonai/gpud/pkg/toolexec"
 )
 
 // Name is the ID of the containerd component.
@@ -27,6 +28,8 @@ type component struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	checkInterval time.Duration
+
 	checkDependencyInstalledFunc func() bool
 	checkSocketExistsFunc        func() bool
 	checkServiceActiveFunc       func(context.Context) (bool, error)
@@ -40,11 +43,13 @@ type component struct {
 }
 
 func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
-	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+	cctx, ccancel := context.WithCancel(toolexec.WithComponent(gpudInstance.RootCtx, Name))
 	c := &component{
 		ctx:    cctx,
 		cancel: ccancel,
 
+		checkInterval: gpudInstance.CheckInterval(Name),
+
 		checkDependencyInstalledFunc: pkgcontainerd.CheckContainerdInstalled,
 		checkSocketExistsFunc:        pkgcontainerd.CheckSocketExists,
 		checkServiceActiveFunc: func(ctx context.Context) (bool, error) {
@@ -74,11 +79,11 @@ func (c *component) IsSupported() bool {
 
 func (c *component) Start() error {
 	go func() {
-		ticker := time.NewTicker(time.Minute)
+		ticker := components.NewCheckTicker(c.checkInterval)
 		defer ticker.Stop()
 
 		for {
-			_ = c.Check()
+			_ = components.SafeCheck(c)
 
 			select {
 			case <-c.ctx.Done():
@@ -33,6 +33,8 @@ type component struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	checkInterval time.Duration
+
 	getTimeStatFunc    func(ctx context.Context) (cpu.TimesStat, error)
 	getUsedPctFunc     func(ctx context.Context) (float64, error)
 	getLoadAvgStatFunc func(ctx context.Context) (*load.AvgStat, error)
@@ -53,6 +55,8 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		ctx:    cctx,
 		cancel: ccancel,
 
+		checkInterval: gpudInstance.CheckInterval(Name),
+
 		getTimeStatFunc:    getTimeStatForAllCPUs,
 		getUsedPctFunc:     getUsedPercentForAllCPUs,
 		getLoadAvgStatFunc: load.AvgWithContext,
@@ -70,7 +74,7 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		}
 
 		if os.Geteuid() == 0 {
-			c.kmsgSyncer, err = kmsg.NewSyncer(cctx, Match, c.eventBucket)
+			c.kmsgSyncer, err = kmsg.NewSyncer(cctx, Match, c.eventBucket, kmsg.WithComponent(Name))
 			if err != nil {
 				ccancel()
 				return nil, err
@@ -95,11 +99,11 @@ func (c *component) IsSupported() bool {
 
 func (c *component) Start() error {
 	go func() {
-		ticker := time.NewTicker(time.Minute)
+		ticker := components.NewCheckTicker(c.checkInterval)
 		defer ticker.Stop()
 
 		for {
-			_ = c.Check()
+			_ = components.SafeCheck(c)
 
 			select {
 			case <-c.ctx.Done():
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package components
+
+import "time"
+
+// threadCPUTime is not supported on the platform.
+func threadCPUTime() time.Duration {
+	return 0
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/leptonai/gpud/pkg/eventstore"
 	"github.com/leptonai/gpud/pkg/kmsg"
 	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/toolexec"
 )
 
 // Name is the ID of the disk component.
@@ -32,6 +33,8 @@ type component struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	checkInterval time.Duration
+
 	retryInterval time.Duration
 
 	getBlockDevicesFunc func(ctx context.Context) (disk.BlockDevices, error)
@@ -42,6 +45,7 @@ type component struct {
 	findMntFunc func(ctx context.Context, target string) (*disk.FindMntOutput, error)
 
 	mountPointsToTrackUsage map[string]struct{}
+	getThresholdsFunc       func() Thresholds
 
 	eventBucket eventstore.Bucket
 	kmsgSyncer  *kmsg.Syncer
@@ -53,11 +57,13 @@ type component struct {
 const defaultRetryInterval = 5 * time.Second
 
 func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
-	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+	cctx, ccancel := context.WithCancel(toolexec.WithComponent(gpudInstance.RootCtx, Name))
 	c := &component{
 		ctx:    cctx,
 		cancel: ccancel,
 
+		checkInterval: gpudInstance.CheckInterval(Name),
+
 		retryInterval: defaultRetryInterval,
 
 		getExt4PartitionsFunc: func(ctx context.Context) (disk.Partitions, error) {
@@ -69,7 +75,8 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 			return disk.GetPartitions(ctx, disk.WithFstype(disk.DefaultNFSFsTypeFunc), disk.WithSkipUsage())
 		},
 
-		findMntFunc: disk.FindMnt,
+		findMntFunc:       disk.FindMnt,
+		getThresholdsFunc: GetDefaultThresholds,
 	}
 
 	if runtime.GOOS == "linux" {
@@ -101,7 +108,7 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		}
 
 		if os.Geteuid() == 0 {
-			c.kmsgSyncer, err = kmsg.NewSyncer(cctx, Match, c.eventBucket)
+			c.kmsgSyncer, err = kmsg.NewSyncer(cctx, Match, c.eventBucket, kmsg.WithComponent(Name))
 			if err != nil {
 				ccancel()
 				return nil, err
@@ -126,11 +133,11 @@ func (c *component) IsSupported() bool {
 
 func (c *component) Start() error {
 	go func() {
-		ticker := time.NewTicker(time.Minute)
+		ticker := components.NewCheckTicker(c.checkInterval)
 		defer ticker.Stop()
 
 		for {
-			_ = c.Check()
+			_ = components.SafeCheck(c)
 
 			select {
 			case <-c.ctx.Done():
@@ -199,7 +206,18 @@ func (c *component) Check() components.CheckResult {
 		return cr
 	}
 
+	var thresholds Thresholds
+	if c.getThresholdsFunc != nil {
+		thresholds = c.getThresholdsFunc()
+	}
+	exceeded := thresholds.missing(cr.ExtPartitions, cr.NFSPartitions)
+
 	if len(cr.NFSPartitions) == 0 && len(cr.ExtPartitions) == 0 {
+		if len(exceeded) > 0 {
+			cr.health = apiv1.HealthStateTypeDegraded
+			cr.reason = "exceeded disk thresholds: " + strings.Join(exceeded, ", ")
+			return cr
+		}
 		cr.health = apiv1.HealthStateTypeHealthy
 		cr.reason = "no ext4/nfs partition found"
 		return cr
@@ -222,6 +240,10 @@ func (c *component) Check() components.CheckResult {
 		metricTotalBytes.With(prometheus.Labels{"mount_point": p.MountPoint}).Set(float64(usage.TotalBytes))
 		metricFreeBytes.With(prometheus.Labels{"mount_point": p.MountPoint}).Set(float64(usage.FreeBytes))
 		metricUsedBytes.With(prometheus.Labels{"mount_point": p.MountPoint}).Set(float64(usage.UsedBytes))
+
+		if msg := thresholds.exceeded(p.MountPoint, *usage); msg != "" {
+			exceeded = append(exceeded, msg)
+		}
 	}
 
 	for _, p := range cr.NFSPartitions {
@@ -240,6 +262,10 @@ func (c *component) Check() components.CheckResult {
 		metricTotalBytes.With(prometheus.Labels{"mount_point": p.MountPoint}).Set(float64(usage.TotalBytes))
 		metricFreeBytes.With(prometheus.Labels{"mount_point": p.MountPoint}).Set(float64(usage.FreeBytes))
 		metricUsedBytes.With(prometheus.Labels{"mount_point": p.MountPoint}).Set(float64(usage.UsedBytes))
+
+		if msg := thresholds.exceeded(p.MountPoint, *usage); msg != "" {
+			exceeded = append(exceeded, msg)
+		}
 	}
 
 	for target := range c.mountPointsToTrackUsage {
@@ -311,6 +337,13 @@ func (c *component) Check() components.CheckResult {
 		}
 	}
 
+	if len(exceeded) > 0 {
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = "exceeded disk thresholds: " + strings.Join(exceeded, ", ")
+		log.Logger.Warnw(cr.reason)
+		return cr
+	}
+
 	cr.health = apiv1.HealthStateTypeHealthy
 	cr.reason = "ok"
 	log.Logger.Debugw(cr.reason, "extPartitions", len(cr.ExtPartitions), "nfsPartitions", len(cr.NFSPartitions), "blockDevices", len(cr.BlockDevices))
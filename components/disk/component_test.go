@@ -297,6 +297,50 @@ func TestCheckOnce(t *testing.T) {
 		assert.Len(t, lastCheckResult.ExtPartitions, 1)
 	})
 
+	t.Run("exceeded usage threshold", func(t *testing.T) {
+		c := createTestComponent(ctx, []string{"/mnt/data1"}, []string{})
+		defer c.Close()
+
+		c.getBlockDevicesFunc = func(ctx context.Context) (disk.BlockDevices, error) {
+			return disk.BlockDevices{mockDevice}, nil
+		}
+		c.getExt4PartitionsFunc = func(ctx context.Context) (disk.Partitions, error) {
+			return disk.Partitions{mockPartition}, nil
+		}
+		c.getThresholdsFunc = func() Thresholds { return Thresholds{MaxUsedPercent: 40} }
+
+		c.Check()
+
+		c.lastMu.RLock()
+		lastCheckResult := c.lastCheckResult
+		c.lastMu.RUnlock()
+
+		assert.NotNil(t, lastCheckResult)
+		assert.Equal(t, apiv1.HealthStateTypeDegraded, lastCheckResult.health)
+		assert.Equal(t, "exceeded disk thresholds: /mnt/data1 used 50.00% exceeding 40.00%", lastCheckResult.reason)
+
+		c.getThresholdsFunc = func() Thresholds {
+			return Thresholds{ExpectedMountPoints: []string{"/mnt/data1", "/mnt/data2"}}
+		}
+		c.Check()
+
+		c.lastMu.RLock()
+		lastCheckResult = c.lastCheckResult
+		c.lastMu.RUnlock()
+		assert.Equal(t, apiv1.HealthStateTypeDegraded, lastCheckResult.health)
+		assert.Equal(t, "exceeded disk thresholds: /mnt/data2 not mounted", lastCheckResult.reason)
+
+		// the usage of the mount points not tracked is not checked
+		c.getThresholdsFunc = func() Thresholds { return Thresholds{MaxUsedPercent: 40} }
+		c.mountPointsToTrackUsage = map[string]struct{}{}
+		c.Check()
+
+		c.lastMu.RLock()
+		lastCheckResult = c.lastCheckResult
+		c.lastMu.RUnlock()
+		assert.Equal(t, apiv1.HealthStateTypeHealthy, lastCheckResult.health)
+	})
+
 	t.Run("no block devices", func(t *testing.T) {
 		c := createTestComponent(ctx, []string{}, []string{})
 		defer c.Close()
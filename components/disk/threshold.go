@@ -0,0 +1,77 @@
+package disk
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/leptonai/gpud/pkg/disk"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// Thresholds are the limits of the disk usage of the tracked mount points.
+type Thresholds struct {
+	// MaxUsedPercent is the used percentage of a tracked mount point,
+	// above which the disk is degraded (e.g., 90 for 90%).
+	// Zero disables the limit.
+	MaxUsedPercent float64 `json:"max_used_percent,omitempty"`
+	// ExpectedMountPoints are the ext4/nfs mount points expected on the node,
+	// any of which not mounted degrades the disk.
+	ExpectedMountPoints []string `json:"expected_mount_points,omitempty"`
+}
+
+func (t Thresholds) Validate() error {
+	if t.MaxUsedPercent < 0 || t.MaxUsedPercent > 100 {
+		return fmt.Errorf("max used percent %v out of range [0, 100]", t.MaxUsedPercent)
+	}
+	return nil
+}
+
+// exceeded returns the description of the usage exceeding the threshold,
+// or empty if within the threshold.
+func (t Thresholds) exceeded(mountPoint string, usage disk.Usage) string {
+	if t.MaxUsedPercent <= 0 || usage.TotalBytes == 0 {
+		return ""
+	}
+	usedPercent := float64(usage.UsedBytes) / float64(usage.TotalBytes) * 100
+	if usedPercent <= t.MaxUsedPercent {
+		return ""
+	}
+	return fmt.Sprintf("%s used %.2f%% exceeding %.2f%%", mountPoint, usedPercent, t.MaxUsedPercent)
+}
+
+// missing returns the descriptions of the expected mount points not in the partitions.
+func (t Thresholds) missing(partitions ...disk.Partitions) []string {
+	mounted := make(map[string]struct{})
+	for _, parts := range partitions {
+		for _, p := range parts {
+			mounted[p.MountPoint] = struct{}{}
+		}
+	}
+
+	var msgs []string
+	for _, mp := range t.ExpectedMountPoints {
+		if _, ok := mounted[mp]; !ok {
+			msgs = append(msgs, fmt.Sprintf("%s not mounted", mp))
+		}
+	}
+	return msgs
+}
+
+var (
+	defaultThresholdsMu sync.RWMutex
+	defaultThresholds   Thresholds
+)
+
+func GetDefaultThresholds() Thresholds {
+	defaultThresholdsMu.RLock()
+	defer defaultThresholdsMu.RUnlock()
+	return defaultThresholds
+}
+
+func SetDefaultThresholds(thresholds Thresholds) {
+	log.Logger.Infow("setting default disk thresholds", "max_used_percent", thresholds.MaxUsedPercent, "expected_mount_points", thresholds.ExpectedMountPoints)
+
+	defaultThresholdsMu.Lock()
+	defer defaultThresholdsMu.Unlock()
+	defaultThresholds = thresholds
+}
@@ -28,6 +28,8 @@ type component struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	checkInterval time.Duration
+
 	checkDependencyInstalledFunc func() bool
 	checkServiceActiveFunc       func() (bool, error)
 	checkDockerRunningFunc       func(context.Context) bool
@@ -47,6 +49,8 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		ctx:    cctx,
 		cancel: ccancel,
 
+		checkInterval: gpudInstance.CheckInterval(Name),
+
 		checkDependencyInstalledFunc: pkgdocker.CheckDockerInstalled,
 		checkServiceActiveFunc: func() (bool, error) {
 			return systemd.IsActive("docker")
@@ -75,11 +79,11 @@ func (c *component) IsSupported() bool {
 
 func (c *component) Start() error {
 	go func() {
-		ticker := time.NewTicker(time.Minute)
+		ticker := components.NewCheckTicker(c.checkInterval)
 		defer ticker.Stop()
 
 		for {
-			_ = c.Check()
+			_ = components.SafeCheck(c)
 
 			select {
 			case <-c.ctx.Done():
@@ -0,0 +1,322 @@
+// Package errorbudget provides a component that tracks the cumulative correctable
+// hardware errors (the corrected ECC errors, the infiniband symbol errors, and the
+// PCIe replays) against the configured budgets per period, and marks the node as
+// degraded once any budget is exhausted, even if no single event crossed the
+// instantaneous threshold.
+package errorbudget
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	pkgerrorbudget "github.com/leptonai/gpud/pkg/errorbudget"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	"github.com/leptonai/gpud/pkg/nvidia-query/infiniband"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+)
+
+// Name is the name of the error budget component.
+const Name = "error-budget"
+
+var _ components.Component = &component{}
+
+type component struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	checkInterval time.Duration
+
+	budgets      []pkgerrorbudget.Budget
+	metricsStore pkgmetrics.Store
+
+	// getCountersFunc returns the current cumulative values of the counter, keyed by the source
+	getCountersFunc func(counter string) (map[string]uint64, error)
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+// New returns the init function of the error budget component,
+// reading the counter history from the metrics store.
+func New(budgets []pkgerrorbudget.Budget, metricsStore pkgmetrics.Store) components.InitFunc {
+	return func(gpudInstance *components.GPUdInstance) (components.Component, error) {
+		cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+		c := &component{
+			ctx:    cctx,
+			cancel: ccancel,
+
+			checkInterval: gpudInstance.CheckInterval(Name),
+
+			budgets:      budgets,
+			metricsStore: metricsStore,
+		}
+		c.getCountersFunc = func(counter string) (map[string]uint64, error) {
+			return getCounters(gpudInstance.NVMLInstance, counter)
+		}
+		return c, nil
+	}
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"accelerator",
+		"gpu",
+		"network",
+		Name,
+	}
+}
+
+func (c *component) IsSupported() bool {
+	return true
+}
+
+func (c *component) Start() error {
+	go func() {
+		ticker := components.NewCheckTicker(c.checkInterval)
+		defer ticker.Stop()
+
+		for {
+			_ = components.SafeCheck(c)
+
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	return nil, nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking error budgets")
+
+	now := time.Now().UTC()
+	cr := &checkResult{
+		ts: now,
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	// the current values are recorded as the metrics,
+	// to evaluate the budgets across the restarts
+	var current pkgmetrics.Metrics
+	var maxPeriod time.Duration
+	sampled := make(map[string]struct{})
+	for _, b := range c.budgets {
+		if p := b.GetPeriod(); p > maxPeriod {
+			maxPeriod = p
+		}
+		if _, ok := sampled[b.Counter]; ok {
+			continue
+		}
+		sampled[b.Counter] = struct{}{}
+
+		values, err := c.getCountersFunc(b.Counter)
+		if err != nil {
+			cr.err = err
+			cr.health = apiv1.HealthStateTypeUnhealthy
+			cr.reason = fmt.Sprintf("error reading %s counters", b.Counter)
+			log.Logger.Errorw(cr.reason, "error", cr.err)
+			return cr
+		}
+		for src, v := range values {
+			metricCounter.With(map[string]string{pkgerrorbudget.LabelCounter: b.Counter, pkgerrorbudget.LabelSource: src}).Set(float64(v))
+			current = append(current, pkgmetrics.Metric{
+				UnixMilliseconds: now.UnixMilli(),
+				Component:        Name,
+				Name:             metricCounterName,
+				Value:            float64(v),
+				Labels:           map[string]string{pkgerrorbudget.LabelCounter: b.Counter, pkgerrorbudget.LabelSource: src},
+			})
+		}
+	}
+
+	var history pkgmetrics.Metrics
+	if c.metricsStore != nil {
+		var err error
+		history, err = c.metricsStore.Read(
+			c.ctx,
+			pkgmetrics.WithSince(now.Add(-maxPeriod)),
+			pkgmetrics.WithComponents(Name),
+			pkgmetrics.WithMetricNames(metricCounterName),
+		)
+		if err != nil {
+			log.Logger.Warnw("failed to read error budget counter history", "error", err)
+		}
+	}
+
+	cr.Usages = pkgerrorbudget.Evaluate(c.budgets, append(history, current...), now)
+
+	var exhausted []string
+	for _, u := range cr.Usages {
+		if u.Exhausted {
+			exhausted = append(exhausted, u.String())
+		}
+	}
+	if len(exhausted) > 0 {
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = "error budget exhausted: " + strings.Join(exhausted, ", ")
+		log.Logger.Warnw(cr.reason)
+		return cr
+	}
+
+	cr.health = apiv1.HealthStateTypeHealthy
+	cr.reason = fmt.Sprintf("%d error budget(s) within the limits", len(cr.Usages))
+	return cr
+}
+
+// getCounters returns the current cumulative values of the counter, keyed by the source.
+func getCounters(nvmlInstance nvidianvml.Instance, counter string) (map[string]uint64, error) {
+	values := make(map[string]uint64)
+	switch counter {
+	case pkgerrorbudget.CounterIBSymbolError:
+		pcs, err := infiniband.GetPortCounters(infiniband.DefaultClassDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, pc := range pcs {
+			values[pc.Key()] = pc.SymbolError
+		}
+
+	case pkgerrorbudget.CounterECCCorrected, pkgerrorbudget.CounterPCIeReplay:
+		if nvmlInstance == nil || !nvmlInstance.NVMLExists() {
+			return values, nil
+		}
+		for uuid, dev := range nvmlInstance.Devices() {
+			var v uint64
+			var ret nvml.Return
+			if counter == pkgerrorbudget.CounterECCCorrected {
+				v, ret = dev.GetTotalEccErrors(nvml.MEMORY_ERROR_TYPE_CORRECTED, nvml.AGGREGATE_ECC)
+			} else {
+				var replays int
+				replays, ret = dev.GetPcieReplayCounter()
+				v = uint64(replays)
+			}
+			if nvidianvml.IsNotSupportError(ret) {
+				continue
+			}
+			if ret != nvml.SUCCESS {
+				return nil, fmt.Errorf("failed to get %s counter of %s: %v", counter, uuid, nvml.ErrorString(ret))
+			}
+			values[uuid] = v
+		}
+	}
+	return values, nil
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	Usages []pkgerrorbudget.Usage `json:"usages"`
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+
+	b, err := yaml.Marshal(cr)
+	if err != nil {
+		return fmt.Sprintf("error marshaling data: %v", err)
+	}
+	return string(b)
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:      metav1.NewTime(cr.ts),
+		Component: Name,
+		Name:      Name,
+		Reason:    cr.reason,
+		Error:     cr.getError(),
+		Health:    cr.health,
+	}
+	if len(cr.Usages) > 0 {
+		b, _ := json.Marshal(cr)
+		state.ExtraInfo = map[string]string{"data": string(b)}
+	}
+	return apiv1.HealthStates{state}
+}
@@ -0,0 +1,129 @@
+package errorbudget
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgerrorbudget "github.com/leptonai/gpud/pkg/errorbudget"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+type mockStore struct {
+	pkgmetrics.Store
+
+	ms pkgmetrics.Metrics
+}
+
+func (s *mockStore) Read(ctx context.Context, opts ...pkgmetrics.OpOption) (pkgmetrics.Metrics, error) {
+	return s.ms, nil
+}
+
+func newTestComponent(budgets []pkgerrorbudget.Budget, store pkgmetrics.Store, counters map[string]map[string]uint64, err error) *component {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &component{
+		ctx:          ctx,
+		cancel:       cancel,
+		budgets:      budgets,
+		metricsStore: store,
+		getCountersFunc: func(counter string) (map[string]uint64, error) {
+			return counters[counter], err
+		},
+	}
+}
+
+func TestComponentName(t *testing.T) {
+	c := newTestComponent(nil, nil, nil, nil)
+	defer c.Close()
+	assert.Equal(t, Name, c.Name())
+	assert.True(t, c.IsSupported())
+}
+
+func TestCheckNoData(t *testing.T) {
+	c := newTestComponent(nil, nil, nil, nil)
+	defer c.Close()
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, states[0].Health)
+	assert.Equal(t, "no data yet", states[0].Reason)
+}
+
+func TestCheckWithinBudget(t *testing.T) {
+	budgets := []pkgerrorbudget.Budget{
+		{Counter: pkgerrorbudget.CounterECCCorrected, Limit: 100},
+	}
+	c := newTestComponent(budgets, nil, map[string]map[string]uint64{
+		pkgerrorbudget.CounterECCCorrected: {"gpu-0": 1000},
+	}, nil)
+	defer c.Close()
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "1 error budget(s) within the limits", cr.Summary())
+}
+
+func TestCheckExhausted(t *testing.T) {
+	now := time.Now()
+	budgets := []pkgerrorbudget.Budget{
+		{Counter: pkgerrorbudget.CounterECCCorrected, Limit: 100},
+		{Counter: pkgerrorbudget.CounterIBSymbolError, Limit: 10},
+	}
+	store := &mockStore{
+		ms: pkgmetrics.Metrics{
+			{
+				UnixMilliseconds: now.Add(-time.Hour).UnixMilli(),
+				Component:        Name,
+				Name:             metricCounterName,
+				Value:            900,
+				Labels:           map[string]string{pkgerrorbudget.LabelCounter: pkgerrorbudget.CounterECCCorrected, pkgerrorbudget.LabelSource: "gpu-0"},
+			},
+			{
+				UnixMilliseconds: now.Add(-time.Hour).UnixMilli(),
+				Component:        Name,
+				Name:             metricCounterName,
+				Value:            5,
+				Labels:           map[string]string{pkgerrorbudget.LabelCounter: pkgerrorbudget.CounterIBSymbolError, pkgerrorbudget.LabelSource: "mlx5_0/1"},
+			},
+		},
+	}
+	c := newTestComponent(budgets, store, map[string]map[string]uint64{
+		pkgerrorbudget.CounterECCCorrected:  {"gpu-0": 1000},
+		pkgerrorbudget.CounterIBSymbolError: {"mlx5_0/1": 6},
+	}, nil)
+	defer c.Close()
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+	assert.Equal(t, "error budget exhausted: ecc_corrected 100/100 in 24h0m0s", cr.Summary())
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	var data checkResult
+	require.NoError(t, json.Unmarshal([]byte(states[0].ExtraInfo["data"]), &data))
+	require.Len(t, data.Usages, 2)
+	assert.Equal(t, uint64(1), data.Usages[1].Used)
+	assert.False(t, data.Usages[1].Exhausted)
+}
+
+func TestCheckCounterError(t *testing.T) {
+	budgets := []pkgerrorbudget.Budget{
+		{Counter: pkgerrorbudget.CounterPCIeReplay, Limit: 10},
+	}
+	c := newTestComponent(budgets, nil, nil, errors.New("nvml error"))
+	defer c.Close()
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	assert.Equal(t, "error reading pcie_replay counters", cr.Summary())
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, "nvml error", states[0].Error)
+}
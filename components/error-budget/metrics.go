@@ -0,0 +1,35 @@
+package errorbudget
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	pkgerrorbudget "github.com/leptonai/gpud/pkg/errorbudget"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+const SubSystem = "error_budget"
+
+// metricCounterName is the name of the counter metric in the metrics store.
+const metricCounterName = SubSystem + "_counter"
+
+var (
+	componentLabel = prometheus.Labels{
+		pkgmetrics.MetricComponentLabelKey: Name,
+	}
+
+	metricCounter = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "counter",
+			Help:      "tracks the cumulative correctable errors of the counters with the error budgets",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, pkgerrorbudget.LabelCounter, pkgerrorbudget.LabelSource}, // label is the counter and its source (e.g., GPU ID, infiniband port)
+	).MustCurryWith(componentLabel)
+)
+
+func init() {
+	pkgmetrics.MustRegister(
+		metricCounter,
+	)
+}
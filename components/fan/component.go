@@ -0,0 +1,421 @@
+// Package fan tracks the GPU fan speeds (NVML) and the chassis fan speeds (IPMI),
+// and flags the fans stuck at zero while the temperatures climb, the fans stuck
+// at the maximum speed without cooling, and the chassis fans reported failed
+// by the BMC, suggesting the hardware inspection of the specific fan.
+package fan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NVIDIA/go-nvlib/pkg/nvlib/device"
+	"github.com/olekukonko/tablewriter"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	pkgipmi "github.com/leptonai/gpud/pkg/ipmi"
+	"github.com/leptonai/gpud/pkg/log"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	"github.com/leptonai/gpud/pkg/toolexec"
+)
+
+// Name is the ID of the fan component.
+const Name = "fan"
+
+var _ components.Component = &component{}
+
+type component struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	checkInterval time.Duration
+
+	nvmlInstance       nvidianvml.Instance
+	getFanFunc         func(uuid string, dev device.Device) (nvidianvml.Fan, error)
+	getTemperatureFunc func(uuid string, dev device.Device) (nvidianvml.Temperature, error)
+
+	ipmitool           string
+	getIPMISensorsFunc func(ctx context.Context, ipmitool string) ([]pkgipmi.Sensor, error)
+
+	nowFunc    func() time.Time
+	thresholds Thresholds
+
+	// windows are the readings of the last checks per fan, oldest first
+	windowsMu sync.Mutex
+	windows   map[string][]Reading
+	// seenChassisFans are the chassis fans seen with a reading,
+	// not to report the fan slots never populated as failed
+	seenChassisFans map[string]struct{}
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	cctx, ccancel := context.WithCancel(toolexec.WithComponent(gpudInstance.RootCtx, Name))
+	return &component{
+		ctx:    cctx,
+		cancel: ccancel,
+
+		checkInterval: gpudInstance.CheckInterval(Name),
+
+		nvmlInstance:       gpudInstance.NVMLInstance,
+		getFanFunc:         nvidianvml.GetFan,
+		getTemperatureFunc: nvidianvml.GetTemperature,
+
+		ipmitool:           pkgipmi.DefaultIPMITool,
+		getIPMISensorsFunc: pkgipmi.GetSensors,
+
+		nowFunc:    time.Now,
+		thresholds: DefaultThresholds,
+
+		windows:         make(map[string][]Reading),
+		seenChassisFans: make(map[string]struct{}),
+	}, nil
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"accelerator",
+		"gpu",
+		"hardware",
+		Name,
+	}
+}
+
+func (c *component) IsSupported() bool {
+	return true
+}
+
+func (c *component) Start() error {
+	go func() {
+		ticker := components.NewCheckTicker(c.checkInterval)
+		defer ticker.Stop()
+
+		for {
+			_ = components.SafeCheck(c)
+
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	return nil, nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking fans")
+
+	cr := &checkResult{
+		ts: c.nowFunc().UTC(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	// the hottest GPU, to compare against the chassis fans
+	// cooling the passively cooled GPUs (e.g., HGX)
+	var hottestGPUCelsius float64
+	if c.nvmlInstance != nil && c.nvmlInstance.NVMLExists() {
+		for uuid, dev := range c.nvmlInstance.Devices() {
+			temp, err := c.getTemperatureFunc(uuid, dev)
+			if err != nil {
+				cr.err = err
+				cr.health = apiv1.HealthStateTypeUnhealthy
+				cr.reason = "error getting temperature"
+				log.Logger.Errorw(cr.reason, "uuid", uuid, "error", cr.err)
+				return cr
+			}
+			celsius := float64(temp.CurrentCelsiusGPUCore)
+			if celsius > hottestGPUCelsius {
+				hottestGPUCelsius = celsius
+			}
+
+			fan, err := c.getFanFunc(uuid, dev)
+			if err != nil {
+				cr.err = err
+				cr.health = apiv1.HealthStateTypeUnhealthy
+				cr.reason = "error getting fan speed"
+				log.Logger.Errorw(cr.reason, "uuid", uuid, "error", cr.err)
+				return cr
+			}
+			if !fan.Supported {
+				continue
+			}
+			for i, speed := range fan.SpeedPercents {
+				cr.Readings = append(cr.Readings, Reading{
+					Time:               cr.ts,
+					ID:                 fmt.Sprintf("%s fan %d", uuid, i),
+					Source:             SourceGPU,
+					Speed:              float64(speed),
+					Unit:               "%",
+					AtMax:              speed >= 100,
+					TemperatureCelsius: celsius,
+				})
+			}
+		}
+	}
+
+	if c.getIPMISensorsFunc != nil {
+		cctx, ccancel := context.WithTimeout(c.ctx, 30*time.Second)
+		sensors, err := c.getIPMISensorsFunc(cctx, c.ipmitool)
+		ccancel()
+		if err != nil {
+			// the chassis fans are best-effort (e.g., no BMC on the VMs)
+			if errors.Is(err, pkgipmi.ErrNoIPMITool) {
+				log.Logger.Debugw("ipmitool not found, skipping chassis fans")
+			} else {
+				log.Logger.Warnw("failed to read ipmi sensors, skipping chassis fans", "error", err)
+			}
+		}
+		readings, failed := c.chassisReadings(cr.ts, sensors, hottestGPUCelsius)
+		cr.Readings = append(cr.Readings, readings...)
+		cr.FailedFans = failed
+	}
+
+	for _, r := range cr.Readings {
+		metricSpeed.With(prometheus.Labels{"fan": r.ID, "unit": r.Unit}).Set(r.Speed)
+	}
+
+	cr.StuckFans = c.updateWindows(cr.Readings)
+	if len(cr.StuckFans) > 0 || len(cr.FailedFans) > 0 {
+		var problems []string
+		for _, f := range cr.FailedFans {
+			problems = append(problems, f.String())
+		}
+		for _, f := range cr.StuckFans {
+			problems = append(problems, f.String())
+		}
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = fmt.Sprintf("%d fan(s) failed or stuck: %s", len(problems), strings.Join(problems, ", "))
+		cr.suggestedActions = &apiv1.SuggestedActions{
+			Description:   fmt.Sprintf("inspect the fan(s) and the air path: %s", strings.Join(problems, ", ")),
+			RepairActions: []apiv1.RepairActionType{apiv1.RepairActionTypeHardwareInspection},
+		}
+		log.Logger.Warnw(cr.reason)
+		return cr
+	}
+
+	cr.health = apiv1.HealthStateTypeHealthy
+	if len(cr.Readings) == 0 {
+		cr.reason = "no fan found"
+	} else {
+		cr.reason = fmt.Sprintf("%d fan(s) were checked, no failed or stuck fan found", len(cr.Readings))
+	}
+	return cr
+}
+
+// chassisReadings returns the readings of the chassis fans, with the temperature
+// of the hottest IPMI temperature sensor or the GPU, and the chassis fans failed:
+// beyond the critical thresholds, or without a reading once seen with a reading.
+func (c *component) chassisReadings(ts time.Time, sensors []pkgipmi.Sensor, hottestGPUCelsius float64) ([]Reading, []FailedFan) {
+	hottest := hottestGPUCelsius
+	for _, s := range sensors {
+		if s.Unit == pkgipmi.UnitDegreesC && !s.NoReading && s.Value > hottest {
+			hottest = s.Value
+		}
+	}
+
+	c.windowsMu.Lock()
+	defer c.windowsMu.Unlock()
+
+	var (
+		readings []Reading
+		failed   []FailedFan
+	)
+	for _, s := range sensors {
+		if s.Unit != pkgipmi.UnitRPM {
+			continue
+		}
+		id := "chassis " + s.Name
+
+		if s.NoReading {
+			if _, seen := c.seenChassisFans[id]; seen {
+				failed = append(failed, FailedFan{ID: id, Source: SourceChassis, Reason: "no reading"})
+			}
+			continue
+		}
+		c.seenChassisFans[id] = struct{}{}
+		if s.Critical() {
+			failed = append(failed, FailedFan{ID: id, Source: SourceChassis, Reason: fmt.Sprintf("status %q at %.0f %s", s.Status, s.Value, pkgipmi.UnitRPM)})
+		}
+
+		readings = append(readings, Reading{
+			Time:               ts,
+			ID:                 id,
+			Source:             SourceChassis,
+			Speed:              s.Value,
+			Unit:               pkgipmi.UnitRPM,
+			AtMax:              s.UpperNonCritical > 0 && s.Value >= s.UpperNonCritical,
+			TemperatureCelsius: hottest,
+		})
+	}
+	return readings, failed
+}
+
+// updateWindows appends the readings to the windows of the fans,
+// drops the windows of the fans no longer found, and returns the stuck fans
+// sorted by the ID.
+func (c *component) updateWindows(readings []Reading) []StuckFan {
+	c.windowsMu.Lock()
+	defer c.windowsMu.Unlock()
+
+	prev := c.windows
+	c.windows = make(map[string][]Reading, len(readings))
+
+	var stuck []StuckFan
+	for _, r := range readings {
+		window := trimWindow(prev[r.ID], r, c.thresholds.Window)
+		c.windows[r.ID] = window
+
+		if f := evaluateStuck(window, c.thresholds); f != nil {
+			stuck = append(stuck, *f)
+		}
+	}
+	sort.Slice(stuck, func(i, j int) bool {
+		return stuck[i].ID < stuck[j].ID
+	})
+	return stuck
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	Readings []Reading `json:"readings,omitempty"`
+	// StuckFans are the fans stuck at zero or at max without cooling.
+	StuckFans []StuckFan `json:"stuck_fans,omitempty"`
+	// FailedFans are the chassis fans reported failed by the BMC.
+	FailedFans []FailedFan `json:"failed_fans,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the reason of the last check
+	reason string
+	// tracks the suggested actions of the last check
+	suggestedActions *apiv1.SuggestedActions
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if len(cr.Readings) == 0 {
+		return "no data"
+	}
+
+	buf := bytes.NewBuffer(nil)
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader([]string{"Fan", "Speed", "At max", "Temperature"})
+	for _, r := range cr.Readings {
+		table.Append([]string{
+			r.ID,
+			fmt.Sprintf("%.0f %s", r.Speed, r.Unit),
+			fmt.Sprintf("%v", r.AtMax),
+			fmt.Sprintf("%.0f °C", r.TemperatureCelsius),
+		})
+	}
+	table.Render()
+
+	return buf.String()
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getSuggestedActions() *apiv1.SuggestedActions {
+	if cr == nil {
+		return nil
+	}
+	return cr.suggestedActions
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:             metav1.NewTime(cr.ts),
+		Component:        Name,
+		Name:             Name,
+		Reason:           cr.reason,
+		SuggestedActions: cr.getSuggestedActions(),
+		Error:            cr.getError(),
+		Health:           cr.health,
+	}
+
+	if len(cr.Readings) > 0 {
+		b, _ := json.Marshal(cr)
+		state.ExtraInfo = map[string]string{"data": string(b)}
+	}
+	return apiv1.HealthStates{state}
+}
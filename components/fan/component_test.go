@@ -0,0 +1,171 @@
+package fan
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/go-nvlib/pkg/nvlib/device"
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgipmi "github.com/leptonai/gpud/pkg/ipmi"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	"github.com/leptonai/gpud/pkg/nvidia-query/nvml/testutil"
+)
+
+type mockNVMLInstance struct {
+	nvidianvml.Instance
+
+	devices map[string]device.Device
+}
+
+func (m *mockNVMLInstance) NVMLExists() bool { return true }
+
+func (m *mockNVMLInstance) Devices() map[string]device.Device { return m.devices }
+
+func newTestComponent(nvmlInstance nvidianvml.Instance) *component {
+	ctx, cancel := context.WithCancel(context.Background())
+	now := time.Unix(1700000000, 0)
+	return &component{
+		ctx:          ctx,
+		cancel:       cancel,
+		nvmlInstance: nvmlInstance,
+		// advances a minute per check
+		nowFunc: func() time.Time {
+			now = now.Add(time.Minute)
+			return now
+		},
+		thresholds:      DefaultThresholds,
+		windows:         make(map[string][]Reading),
+		seenChassisFans: make(map[string]struct{}),
+	}
+}
+
+func TestComponentName(t *testing.T) {
+	c := newTestComponent(nil)
+	defer c.Close()
+	assert.Equal(t, Name, c.Name())
+	assert.True(t, c.IsSupported())
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, "no data yet", states[0].Reason)
+}
+
+func TestCheckNoFan(t *testing.T) {
+	c := newTestComponent(nil)
+	defer c.Close()
+	c.getIPMISensorsFunc = func(ctx context.Context, ipmitool string) ([]pkgipmi.Sensor, error) {
+		return nil, pkgipmi.ErrNoIPMITool
+	}
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "no fan found", cr.Summary())
+}
+
+func TestCheckChassisFanStuckAtZero(t *testing.T) {
+	c := newTestComponent(nil)
+	defer c.Close()
+
+	temp := 60.0
+	c.getIPMISensorsFunc = func(ctx context.Context, ipmitool string) ([]pkgipmi.Sensor, error) {
+		return []pkgipmi.Sensor{
+			{Name: "Inlet Temp", Value: temp, Unit: pkgipmi.UnitDegreesC},
+			{Name: "FAN1", Value: 0, Unit: pkgipmi.UnitRPM, UpperNonCritical: 25300},
+			{Name: "FAN2", Value: 5400, Unit: pkgipmi.UnitRPM, UpperNonCritical: 25300},
+		}, nil
+	}
+
+	// the window spans 5 minutes after 6 checks a minute apart
+	for i := 0; i < 5; i++ {
+		cr := c.Check()
+		assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+		assert.Equal(t, "2 fan(s) were checked, no failed or stuck fan found", cr.Summary())
+		temp += 2
+	}
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.health)
+	assert.Equal(t, "1 fan(s) failed or stuck: chassis FAN1 stuck at zero while temperature climbed from 60 °C to 70 °C", cr.reason)
+	require.Len(t, cr.StuckFans, 1)
+	assert.Equal(t, SourceChassis, cr.StuckFans[0].Source)
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	require.NotNil(t, states[0].SuggestedActions)
+	assert.Equal(t, []apiv1.RepairActionType{apiv1.RepairActionTypeHardwareInspection}, states[0].SuggestedActions.RepairActions)
+	assert.Contains(t, states[0].SuggestedActions.Description, "chassis FAN1")
+}
+
+func TestCheckGPUFanStuckAtMax(t *testing.T) {
+	uuid := "GPU-0"
+	devs := map[string]device.Device{
+		uuid: testutil.NewMockDevice(&mock.Device{
+			GetUUIDFunc: func() (string, nvml.Return) { return uuid, nvml.SUCCESS },
+		}, "test-arch", "test-brand", "test-cuda", "test-pci"),
+	}
+	c := newTestComponent(&mockNVMLInstance{devices: devs})
+	defer c.Close()
+
+	var celsius uint32 = 70
+	c.getTemperatureFunc = func(uuid string, dev device.Device) (nvidianvml.Temperature, error) {
+		return nvidianvml.Temperature{UUID: uuid, CurrentCelsiusGPUCore: celsius}, nil
+	}
+	c.getFanFunc = func(uuid string, dev device.Device) (nvidianvml.Fan, error) {
+		return nvidianvml.Fan{UUID: uuid, SpeedPercents: []uint32{100, 40}, Supported: true}, nil
+	}
+
+	var cr *checkResult
+	for i := 0; i < 6; i++ {
+		cr = c.Check().(*checkResult)
+		celsius += 3
+	}
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.health)
+	require.Len(t, cr.StuckFans, 1)
+	assert.Equal(t, StuckFan{ID: "GPU-0 fan 0", Source: SourceGPU, Stuck: StuckAtMax, FromCelsius: 70, ToCelsius: 85}, cr.StuckFans[0])
+
+	c.getFanFunc = func(uuid string, dev device.Device) (nvidianvml.Fan, error) {
+		return nvidianvml.Fan{}, errors.New("nvml error")
+	}
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.health)
+	assert.Equal(t, "error getting fan speed", cr.reason)
+}
+
+func TestCheckChassisFanFailed(t *testing.T) {
+	c := newTestComponent(nil)
+	defer c.Close()
+
+	sensors := []pkgipmi.Sensor{
+		{Name: "FAN1", Value: 5400, Unit: pkgipmi.UnitRPM, Status: "ok"},
+		{Name: "FAN2", Value: 5400, Unit: pkgipmi.UnitRPM, Status: "ok"},
+		// the fan slot never populated
+		{Name: "FAN8", Unit: pkgipmi.UnitRPM, Status: "na", NoReading: true},
+	}
+	c.getIPMISensorsFunc = func(ctx context.Context, ipmitool string) ([]pkgipmi.Sensor, error) {
+		return sensors, nil
+	}
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.health)
+	assert.Empty(t, cr.FailedFans)
+
+	// below the lower critical RPM, and no reading once seen with a reading
+	sensors[0] = pkgipmi.Sensor{Name: "FAN1", Value: 200, Unit: pkgipmi.UnitRPM, Status: "cr"}
+	sensors[1] = pkgipmi.Sensor{Name: "FAN2", Unit: pkgipmi.UnitRPM, Status: "na", NoReading: true}
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.health)
+	assert.Equal(t, []FailedFan{
+		{ID: "chassis FAN1", Source: SourceChassis, Reason: `status "cr" at 200 RPM`},
+		{ID: "chassis FAN2", Source: SourceChassis, Reason: "no reading"},
+	}, cr.FailedFans)
+	assert.Equal(t, `2 fan(s) failed or stuck: chassis FAN1 failed (status "cr" at 200 RPM), chassis FAN2 failed (no reading)`, cr.reason)
+	require.NotNil(t, cr.suggestedActions)
+	assert.Equal(t, []apiv1.RepairActionType{apiv1.RepairActionTypeHardwareInspection}, cr.suggestedActions.RepairActions)
+}
@@ -0,0 +1,31 @@
+package fan
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+const SubSystem = "fan"
+
+var (
+	componentLabel = prometheus.Labels{
+		pkgmetrics.MetricComponentLabelKey: Name,
+	}
+
+	metricSpeed = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "speed",
+			Help:      "tracks the fan speed in percent (GPU fans) or RPM (chassis fans)",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "fan", "unit"}, // label is the fan ID and the unit of the speed
+	).MustCurryWith(componentLabel)
+)
+
+func init() {
+	pkgmetrics.MustRegister(
+		metricSpeed,
+	)
+}
@@ -0,0 +1,186 @@
+package fan
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultWindow is the duration to evaluate the fan speed and the temperature over,
+	// regardless of the check interval.
+	DefaultWindow = 5 * time.Minute
+	// DefaultTemperatureRiseCelsius is the temperature rise over the window
+	// above which the temperature is considered climbing.
+	DefaultTemperatureRiseCelsius = 5
+	// DefaultStuckAtMaxCelsius is the temperature at or above which the fan
+	// at the maximum speed for the whole window is not cooling enough.
+	// Below it, the fan at max is the normal fan curve under the load,
+	// unless the temperature is still climbing after the fan reached max.
+	DefaultStuckAtMaxCelsius = 85
+)
+
+const (
+	// SourceGPU is the fan of the GPU board, read from NVML.
+	SourceGPU = "gpu"
+	// SourceChassis is the chassis fan, read from IPMI.
+	SourceChassis = "chassis"
+)
+
+// Stuck is the stuck condition of a fan.
+type Stuck string
+
+const (
+	// StuckAtZero is the fan not spinning, e.g., the failed fan or the fan controller.
+	StuckAtZero Stuck = "stuck at zero"
+	// StuckAtMax is the fan spinning at the maximum speed without cooling,
+	// e.g., the clogged air path or the failed fan in the same zone.
+	StuckAtMax Stuck = "stuck at max"
+)
+
+// Thresholds are the thresholds to evaluate the stuck fans.
+type Thresholds struct {
+	// Window is the duration to evaluate the readings over.
+	Window time.Duration
+	// RiseCelsius is the temperature rise above which the temperature is considered climbing.
+	RiseCelsius float64
+	// StuckAtMaxCelsius is the temperature at or above which the fan at max is not cooling enough.
+	StuckAtMaxCelsius float64
+}
+
+// DefaultThresholds are the default thresholds to evaluate the stuck fans.
+var DefaultThresholds = Thresholds{
+	Window:            DefaultWindow,
+	RiseCelsius:       DefaultTemperatureRiseCelsius,
+	StuckAtMaxCelsius: DefaultStuckAtMaxCelsius,
+}
+
+// Reading is the fan speed and the temperature of the part the fan cools, in a check.
+type Reading struct {
+	// Time is the time of the check.
+	Time time.Time `json:"time"`
+	// ID identifies the fan (e.g., "GPU-xxx fan 0", "chassis FAN1").
+	ID string `json:"id"`
+	// Source is the source of the fan (e.g., "gpu", "chassis").
+	Source string `json:"source"`
+
+	// Speed is the fan speed in the unit.
+	Speed float64 `json:"speed"`
+	// Unit is the unit of the speed (e.g., "%", "RPM").
+	Unit string `json:"unit"`
+	// AtMax is true if the fan runs at its maximum speed
+	// (e.g., 100%, above the upper non-critical threshold of the RPM).
+	AtMax bool `json:"at_max"`
+
+	// TemperatureCelsius is the temperature of the part the fan cools
+	// (e.g., the GPU for the GPU fans, the hottest sensor for the chassis fans).
+	TemperatureCelsius float64 `json:"temperature_celsius"`
+}
+
+// StuckFan is a fan stuck at zero while the temperature climbs,
+// or at max while the temperature is too high or still climbing.
+type StuckFan struct {
+	ID     string `json:"id"`
+	Source string `json:"source"`
+	Stuck  Stuck  `json:"stuck"`
+
+	// FromCelsius and ToCelsius are the temperatures at the start and the end of the window.
+	FromCelsius float64 `json:"from_celsius"`
+	ToCelsius   float64 `json:"to_celsius"`
+}
+
+func (f StuckFan) String() string {
+	if f.ToCelsius > f.FromCelsius {
+		return fmt.Sprintf("%s %s while temperature climbed from %.0f °C to %.0f °C", f.ID, f.Stuck, f.FromCelsius, f.ToCelsius)
+	}
+	return fmt.Sprintf("%s %s while temperature at %.0f °C", f.ID, f.Stuck, f.ToCelsius)
+}
+
+// FailedFan is a fan reported failed by the BMC
+// (e.g., below the lower critical RPM, or no reading).
+type FailedFan struct {
+	ID     string `json:"id"`
+	Source string `json:"source"`
+	Reason string `json:"reason"`
+}
+
+func (f FailedFan) String() string {
+	return fmt.Sprintf("%s failed (%s)", f.ID, f.Reason)
+}
+
+// trimWindow returns the window (oldest first) with the reading appended,
+// keeping the latest reading at or before the window start as the oldest one,
+// so that the window spans the whole duration once enough readings are in.
+func trimWindow(window []Reading, r Reading, d time.Duration) []Reading {
+	window = append(window, r)
+	start := r.Time.Add(-d)
+	for len(window) > 1 && !window[1].Time.After(start) {
+		window = window[1:]
+	}
+	return window
+}
+
+// evaluateStuck returns the stuck fan over the readings in the window (oldest first):
+//   - stuck at zero if the fan stayed at zero while the temperature rose by at least the rise
+//   - stuck at max if the fan stayed at max while the temperature is at or above the limit,
+//     or still rose by at least the rise in the second half of the window,
+//     after the fan had been at max for the first half
+//
+// Returns nil if the window does not span the whole duration yet.
+func evaluateStuck(window []Reading, th Thresholds) *StuckFan {
+	if len(window) < 2 {
+		return nil
+	}
+	first, last := window[0], window[len(window)-1]
+	if last.Time.Sub(first.Time) < th.Window {
+		return nil
+	}
+
+	atZero, atMax := true, true
+	for _, r := range window {
+		if r.Speed != 0 {
+			atZero = false
+		}
+		if !r.AtMax {
+			atMax = false
+		}
+	}
+
+	var stuck Stuck
+	switch {
+	case atZero:
+		if last.TemperatureCelsius-first.TemperatureCelsius < th.RiseCelsius {
+			return nil
+		}
+		stuck = StuckAtZero
+
+	case atMax:
+		if last.TemperatureCelsius < th.StuckAtMaxCelsius && last.TemperatureCelsius-midOf(window).TemperatureCelsius < th.RiseCelsius {
+			return nil
+		}
+		stuck = StuckAtMax
+
+	default:
+		return nil
+	}
+	return &StuckFan{
+		ID:          last.ID,
+		Source:      last.Source,
+		Stuck:       stuck,
+		FromCelsius: first.TemperatureCelsius,
+		ToCelsius:   last.TemperatureCelsius,
+	}
+}
+
+// midOf returns the latest reading at or before the middle of the window.
+func midOf(window []Reading) Reading {
+	first, last := window[0], window[len(window)-1]
+	mid := first.Time.Add(last.Time.Sub(first.Time) / 2)
+	m := first
+	for _, r := range window {
+		if r.Time.After(mid) {
+			break
+		}
+		m = r
+	}
+	return m
+}
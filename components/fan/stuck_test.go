@@ -0,0 +1,70 @@
+package fan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newWindow returns the readings one minute apart.
+func newWindow(speeds []float64, atMax bool, temps []float64) []Reading {
+	start := time.Unix(1700000000, 0)
+	var window []Reading
+	for i := range speeds {
+		window = trimWindow(window, Reading{
+			Time:               start.Add(time.Duration(i) * time.Minute),
+			ID:                 "chassis FAN1",
+			Source:             SourceChassis,
+			Speed:              speeds[i],
+			Unit:               "RPM",
+			AtMax:              atMax,
+			TemperatureCelsius: temps[i],
+		}, DefaultWindow)
+	}
+	return window
+}
+
+func TestEvaluateStuck(t *testing.T) {
+	climbing := []float64{60, 62, 64, 66, 68, 70}
+	zeros := []float64{0, 0, 0, 0, 0, 0}
+	maxes := []float64{25400, 25400, 25400, 25400, 25400, 25400}
+
+	f := evaluateStuck(newWindow(zeros, false, climbing), DefaultThresholds)
+	require.NotNil(t, f)
+	assert.Equal(t, StuckAtZero, f.Stuck)
+	assert.Equal(t, "chassis FAN1 stuck at zero while temperature climbed from 60 °C to 70 °C", f.String())
+
+	// at max while still climbing after the first half of the window
+	f = evaluateStuck(newWindow(maxes, true, []float64{60, 62, 64, 67, 70, 73}), DefaultThresholds)
+	require.NotNil(t, f)
+	assert.Equal(t, StuckAtMax, f.Stuck)
+
+	// at max while too hot
+	f = evaluateStuck(newWindow(maxes, true, []float64{86, 86, 86, 86, 86, 86}), DefaultThresholds)
+	require.NotNil(t, f)
+	assert.Equal(t, StuckAtMax, f.Stuck)
+	assert.Equal(t, "chassis FAN1 stuck at max while temperature at 86 °C", f.String())
+
+	// at max on the normal fan curve, the temperature plateaued under the limit
+	assert.Nil(t, evaluateStuck(newWindow(maxes, true, []float64{60, 66, 70, 71, 71, 71}), DefaultThresholds))
+	// the fan responds to the temperature
+	assert.Nil(t, evaluateStuck(newWindow([]float64{0, 0, 0, 3000, 6000, 6000}, false, climbing), DefaultThresholds))
+	// the temperature is stable
+	assert.Nil(t, evaluateStuck(newWindow(zeros, false, []float64{60, 61, 60, 61, 62, 62}), DefaultThresholds))
+	// the window does not span the duration yet
+	assert.Nil(t, evaluateStuck(newWindow([]float64{0, 0, 0}, false, []float64{60, 65, 70}), DefaultThresholds))
+	assert.Nil(t, evaluateStuck(nil, DefaultThresholds))
+}
+
+func TestTrimWindow(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	var window []Reading
+	// checked every 10 seconds, the window keeps the readings of the duration, not of the count
+	for i := 0; i < 60; i++ {
+		window = trimWindow(window, Reading{Time: start.Add(time.Duration(i) * 10 * time.Second)}, time.Minute)
+	}
+	require.Len(t, window, 7)
+	assert.Equal(t, time.Minute, window[len(window)-1].Time.Sub(window[0].Time))
+}
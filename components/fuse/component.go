@@ -39,6 +39,8 @@ type component struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	checkInterval time.Duration
+
 	// congestedPercentAgainstThreshold is the percentage of the FUSE connections waiting
 	// at which we consider the system to be congested.
 	congestedPercentAgainstThreshold float64
@@ -60,6 +62,8 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		ctx:    cctx,
 		cancel: ccancel,
 
+		checkInterval: gpudInstance.CheckInterval(Name),
+
 		congestedPercentAgainstThreshold:     DefaultCongestedPercentAgainstThreshold,
 		maxBackgroundPercentAgainstThreshold: DefaultMaxBackgroundPercentAgainstThreshold,
 
@@ -92,11 +96,11 @@ func (c *component) IsSupported() bool {
 
 func (c *component) Start() error {
 	go func() {
-		ticker := time.NewTicker(time.Minute)
+		ticker := components.NewCheckTicker(c.checkInterval)
 		defer ticker.Stop()
 
 		for {
-			_ = c.Check()
+			_ = components.SafeCheck(c)
 
 			select {
 			case <-c.ctx.Done():
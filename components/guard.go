@@ -0,0 +1,423 @@
+package components
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	apiv2 "github.com/leptonai/gpud/api/v2"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// DefaultQuarantineThreshold is the default number of the consecutive panics
+// in the component check, after which the component is quarantined.
+const DefaultQuarantineThreshold = 3
+
+// EventNameComponentQuarantined is the name of the event emitted
+// when a component is quarantined for repeatedly panicking.
+const EventNameComponentQuarantined = "component_quarantined"
+
+// CheckGuard recovers the panics in the component checks, and converts them
+// into the unhealthy check results, so that a buggy component
+// does not crash the whole daemon.
+//
+// A component that panics on the consecutive checks is quarantined:
+// its checks are no longer run (i.e., its poller keeps ticking but
+// does nothing) until released (e.g., via SetHealthy).
+type CheckGuard struct {
+	threshold int
+	stats     *CheckStats
+
+	mu           sync.RWMutex
+	states       map[string]*guardState
+	onQuarantine func(name string, err error)
+
+	// loadPolicy and loadFunc defer the low priority checks under load
+	loadPolicy LoadPolicy
+	loadFunc   func() Load
+	// lastRuns is the last time the check of each low priority component ran
+	lastRuns map[string]time.Time
+
+	// faultInjector injects the faults into the checks, nil to never inject
+	faultInjector CheckFaultInjector
+}
+
+// CheckFaultInjector injects the faults into the component checks run via the check guard,
+// so that the faults apply to the checks of the component pollers (which call the
+// unwrapped component) as well as the triggered checks.
+type CheckFaultInjector interface {
+	// InjectCheckFault returns the check result to return instead of running the check,
+	// and false if no fault is armed for the component.
+	// It may block (e.g., the check timeout) or panic, as the faulty check would.
+	InjectCheckFault(name string) (CheckResult, bool)
+	// ClearCheckFault clears the fault armed for the component, if any.
+	ClearCheckFault(name string)
+}
+
+type guardState struct {
+	panics      int
+	quarantined bool
+	lastStates  apiv1.HealthStates
+}
+
+// NewCheckGuard creates a new check guard that quarantines the component
+// after the threshold number of the consecutive panics.
+// Zero or negative threshold uses the default.
+func NewCheckGuard(threshold int) *CheckGuard {
+	if threshold <= 0 {
+		threshold = DefaultQuarantineThreshold
+	}
+	return &CheckGuard{
+		threshold: threshold,
+		stats:     NewCheckStats(),
+		states:    make(map[string]*guardState),
+		lastRuns:  make(map[string]time.Time),
+	}
+}
+
+// Stats returns the duration and the CPU time of the checks run via the guard.
+func (g *CheckGuard) Stats() *CheckStats {
+	return g.stats
+}
+
+var defaultCheckGuard = NewCheckGuard(DefaultQuarantineThreshold)
+
+// DefaultCheckGuard returns the check guard shared by all the component pollers.
+func DefaultCheckGuard() *CheckGuard {
+	return defaultCheckGuard
+}
+
+// SafeCheck runs the component check with the default check guard.
+// The component pollers must call this instead of calling "Check" directly.
+func SafeCheck(c Component) CheckResult {
+	return defaultCheckGuard.Check(c)
+}
+
+// SetOnQuarantine sets the function called (once) when a component is quarantined.
+func (g *CheckGuard) SetOnQuarantine(f func(name string, err error)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onQuarantine = f
+}
+
+// SetLoadPolicy sets the policy to defer the low priority checks
+// while the node is under load, as sampled by the load function.
+// Nil load function disables the deferral.
+func (g *CheckGuard) SetLoadPolicy(policy LoadPolicy, loadFunc func() Load) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.loadPolicy = policy
+	g.loadFunc = loadFunc
+}
+
+// SetFaultInjector sets the injector of the faults into the checks.
+// Nil disables the fault injection.
+func (g *CheckGuard) SetFaultInjector(fi CheckFaultInjector) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.faultInjector = fi
+}
+
+// Check runs the periodic component check, recovering the panic if any.
+// Returns the unhealthy check result without running the check
+// if the component is quarantined.
+// If the component is of the low priority and the node is under load,
+// runs the downscoped check if the component is a Downscoper,
+// and returns the health states of the last check without running the check otherwise.
+func (g *CheckGuard) Check(c Component) CheckResult {
+	return g.check(c, true)
+}
+
+// CheckNow runs the explicitly requested component check (e.g., triggered via the API),
+// recovering the panic if any. Unlike Check, the check is never deferred nor downscoped
+// under load, since the caller is waiting for the fresh result.
+func (g *CheckGuard) CheckNow(c Component) CheckResult {
+	return g.check(c, false)
+}
+
+func (g *CheckGuard) check(c Component, loadAware bool) (rs CheckResult) {
+	name := c.Name()
+
+	g.mu.RLock()
+	st, ok := g.states[name]
+	quarantined := ok && st.quarantined
+	var lastStates apiv1.HealthStates
+	if quarantined {
+		lastStates = st.lastStates
+	}
+	g.mu.RUnlock()
+	if quarantined {
+		return &guardCheckResult{name: name, states: lastStates}
+	}
+
+	runCheck := c.Check
+	if PriorityOf(c) == PriorityLow {
+		if load, underLoad := g.deferCheck(name, time.Now(), loadAware); underLoad {
+			d, ok := c.(Downscoper)
+			if !ok {
+				log.Logger.Debugw("deferred low priority check under load", "component", name, "cpuPercent", load.CPUPercent, "gpuPercent", load.GPUPercent)
+				return &deferredCheckResult{name: name, load: load, states: c.LastHealthStates()}
+			}
+			log.Logger.Debugw("downscoped low priority check under load", "component", name, "cpuPercent", load.CPUPercent, "gpuPercent", load.GPUPercent)
+			runCheck = d.CheckDownscoped
+		}
+	}
+
+	g.mu.RLock()
+	faultInjector := g.faultInjector
+	g.mu.RUnlock()
+
+	// pins the check to the thread to measure its CPU time
+	// (the goroutines spawned by the check are not accounted)
+	injected := false
+	runtime.LockOSThread()
+	span := startCheckSpan(name)
+	start := time.Now()
+	startCPU := threadCPUTime()
+
+	defer func() {
+		r := recover()
+
+		took := time.Since(start)
+		g.stats.Record(name, took, threadCPUTime()-startCPU)
+		runtime.UnlockOSThread()
+
+		if r == nil {
+			if injected {
+				// the injected states are served until the next check without the fault
+				g.setStates(name, rs.HealthStates())
+			} else {
+				g.reset(name)
+			}
+			endCheckSpan(span, name, took, rs, nil)
+			return
+		}
+
+		err := fmt.Errorf("component check panicked: %v", r)
+		log.Logger.Errorw("recovered panic in component check", "component", name, "error", err, "stack", string(debug.Stack()))
+		rs = g.recordPanic(name, err)
+		endCheckSpan(span, name, took, rs, err)
+	}()
+
+	if faultInjector != nil {
+		if rs, injected = faultInjector.InjectCheckFault(name); injected {
+			return rs
+		}
+	}
+	return runCheck()
+}
+
+// HealthStates returns the health states of the component
+// if its last check panicked or had the injected fault, or it is quarantined.
+// Returns false if the last check ran normally.
+func (g *CheckGuard) HealthStates(name string) (apiv1.HealthStates, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	st, ok := g.states[name]
+	if !ok {
+		return nil, false
+	}
+	return st.lastStates, true
+}
+
+// Quarantined returns true if the component is quarantined.
+func (g *CheckGuard) Quarantined(name string) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	st, ok := g.states[name]
+	return ok && st.quarantined
+}
+
+// Release releases the component from the quarantine, and clears the injected fault,
+// so that its checks are run again.
+func (g *CheckGuard) Release(name string) {
+	g.mu.RLock()
+	faultInjector := g.faultInjector
+	g.mu.RUnlock()
+	if faultInjector != nil {
+		faultInjector.ClearCheckFault(name)
+	}
+	g.reset(name)
+}
+
+// deferCheck returns true if the check of the low priority component
+// should be deferred (or downscoped), and records the full check run otherwise.
+// The check is never deferred if not load aware (i.e., explicitly requested).
+func (g *CheckGuard) deferCheck(name string, now time.Time, loadAware bool) (Load, bool) {
+	g.mu.RLock()
+	policy, loadFunc := g.loadPolicy, g.loadFunc
+	lastRun, ran := g.lastRuns[name]
+	g.mu.RUnlock()
+
+	var load Load
+	if loadAware && loadFunc != nil && ran && now.Sub(lastRun) < policy.maxDeferral() {
+		load = loadFunc()
+		if policy.UnderLoad(load) {
+			return load, true
+		}
+	}
+
+	g.mu.Lock()
+	g.lastRuns[name] = now
+	g.mu.Unlock()
+	return load, false
+}
+
+func (g *CheckGuard) reset(name string) {
+	g.mu.Lock()
+	delete(g.states, name)
+	g.mu.Unlock()
+}
+
+func (g *CheckGuard) setStates(name string, states apiv1.HealthStates) {
+	g.mu.Lock()
+	g.states[name] = &guardState{lastStates: states}
+	g.mu.Unlock()
+}
+
+func (g *CheckGuard) recordPanic(name string, err error) CheckResult {
+	g.mu.Lock()
+	st, ok := g.states[name]
+	if !ok {
+		st = &guardState{}
+		g.states[name] = st
+	}
+	st.panics++
+
+	reason := err.Error()
+	quarantine := !st.quarantined && st.panics >= g.threshold
+	if quarantine {
+		st.quarantined = true
+		reason = fmt.Sprintf("component quarantined after %d consecutive panics", st.panics)
+	}
+	st.lastStates = apiv1.HealthStates{
+		{
+			Time:      metav1.NewTime(time.Now().UTC()),
+			Component: name,
+			Name:      name,
+			Health:    apiv1.HealthStateTypeUnhealthy,
+			Reason:    reason,
+			Error:     err.Error(),
+		},
+	}
+	lastStates := st.lastStates
+	onQuarantine := g.onQuarantine
+	g.mu.Unlock()
+
+	if quarantine {
+		log.Logger.Errorw("quarantined component", "component", name, "panics", g.threshold)
+		if onQuarantine != nil {
+			onQuarantine(name, err)
+		}
+	}
+	return &guardCheckResult{name: name, states: lastStates}
+}
+
+// WrapInitFunc wraps the component init function, so that the component checks
+// and health states by the other callers (e.g., the API handlers)
+// go through the check guard.
+func WrapInitFunc(initFunc InitFunc, guard *CheckGuard) InitFunc {
+	return func(gpudInstance *GPUdInstance) (Component, error) {
+		c, err := initFunc(gpudInstance)
+		if err != nil {
+			return nil, err
+		}
+		return &guardedComponent{Component: c, guard: guard}, nil
+	}
+}
+
+var (
+	_ Component      = &guardedComponent{}
+	_ HealthSettable = &guardedComponent{}
+	_ Deregisterable = &guardedComponent{}
+	_ Prioritized    = &guardedComponent{}
+	_ ToolRequirer   = &guardedComponent{}
+	_ Versioned      = &guardedComponent{}
+	_ TypedPayloader = &guardedComponent{}
+)
+
+type guardedComponent struct {
+	Component
+	guard *CheckGuard
+}
+
+// Check runs the check requested by the other callers (e.g., the API handlers)
+// than the component poller, thus never deferred nor downscoped under load.
+func (c *guardedComponent) Check() CheckResult {
+	return c.guard.CheckNow(c.Component)
+}
+
+func (c *guardedComponent) LastHealthStates() apiv1.HealthStates {
+	if states, ok := c.guard.HealthStates(c.Name()); ok {
+		return states
+	}
+	return c.Component.LastHealthStates()
+}
+
+// LastHealthStatesWithPayload returns the health states of the guard without a payload,
+// if the component is quarantined or its last check failed in the guard.
+func (c *guardedComponent) LastHealthStatesWithPayload() (apiv1.HealthStates, apiv2.Payload) {
+	if states, ok := c.guard.HealthStates(c.Name()); ok {
+		return states, nil
+	}
+	return LastHealthStatesWithPayload(c.Component)
+}
+
+func (c *guardedComponent) Priority() Priority {
+	return PriorityOf(c.Component)
+}
+
+func (c *guardedComponent) Version() string {
+	return VersionOf(c.Component)
+}
+
+func (c *guardedComponent) RequiresTools() bool {
+	t, ok := c.Component.(ToolRequirer)
+	return !ok || t.RequiresTools()
+}
+
+func (c *guardedComponent) CanDeregister() bool {
+	d, ok := c.Component.(Deregisterable)
+	return ok && d.CanDeregister()
+}
+
+// SetHealthy releases the component from the quarantine, if any,
+// and delegates to the underlying component if it supports.
+func (c *guardedComponent) SetHealthy() error {
+	c.guard.Release(c.Name())
+	if hs, ok := c.Component.(HealthSettable); ok {
+		return hs.SetHealthy()
+	}
+	return nil
+}
+
+var _ CheckResult = &guardCheckResult{}
+
+type guardCheckResult struct {
+	name   string
+	states apiv1.HealthStates
+}
+
+func (cr *guardCheckResult) ComponentName() string { return cr.name }
+
+func (cr *guardCheckResult) String() string { return cr.Summary() }
+
+func (cr *guardCheckResult) Summary() string {
+	if len(cr.states) == 0 {
+		return ""
+	}
+	return cr.states[0].Reason
+}
+
+func (cr *guardCheckResult) HealthStateType() apiv1.HealthStateType {
+	return apiv1.HealthStateTypeUnhealthy
+}
+
+func (cr *guardCheckResult) HealthStates() apiv1.HealthStates { return cr.states }
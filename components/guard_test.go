@@ -0,0 +1,178 @@
+package components
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	apiv2 "github.com/leptonai/gpud/api/v2"
+)
+
+type panickingComponent struct {
+	mockComponent
+	panic  bool
+	checks int
+}
+
+func (p *panickingComponent) Check() CheckResult {
+	p.checks++
+	if p.panic {
+		panic("boom")
+	}
+	return &mockCheckResult{}
+}
+
+func TestCheckGuard(t *testing.T) {
+	guard := NewCheckGuard(2)
+
+	quarantined := ""
+	guard.SetOnQuarantine(func(name string, err error) {
+		quarantined = name
+	})
+
+	inner := &panickingComponent{mockComponent: mockComponent{name: "test"}, panic: true}
+	c, err := WrapInitFunc(func(*GPUdInstance) (Component, error) { return inner, nil }, guard)(nil)
+	require.NoError(t, err)
+
+	rs := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, rs.HealthStateType())
+	assert.Contains(t, rs.Summary(), "boom")
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, c.LastHealthStates()[0].Health)
+	assert.False(t, guard.Quarantined("test"))
+
+	// panics again, quarantined
+	rs = guard.Check(inner)
+	assert.Contains(t, rs.Summary(), "quarantined")
+	assert.True(t, guard.Quarantined("test"))
+	assert.Equal(t, "test", quarantined)
+	assert.Equal(t, 2, inner.checks)
+
+	// no longer checked
+	inner.panic = false
+	rs = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, rs.HealthStateType())
+	assert.Equal(t, 2, inner.checks)
+
+	// released
+	require.NoError(t, c.(HealthSettable).SetHealthy())
+	assert.False(t, guard.Quarantined("test"))
+	rs = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, rs.HealthStateType())
+	assert.Equal(t, 3, inner.checks)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, c.LastHealthStates()[0].Health)
+	assert.False(t, c.(Deregisterable).CanDeregister())
+}
+
+func TestCheckGuardResetOnSuccess(t *testing.T) {
+	guard := NewCheckGuard(2)
+	inner := &panickingComponent{mockComponent: mockComponent{name: "test"}, panic: true}
+
+	_ = guard.Check(inner)
+	inner.panic = false
+	_ = guard.Check(inner)
+	_, ok := guard.HealthStates("test")
+	assert.False(t, ok)
+
+	inner.panic = true
+	_ = guard.Check(inner)
+	assert.False(t, guard.Quarantined("test"))
+}
+
+type mockCheckFaultInjector struct {
+	armed   map[string]bool
+	cleared []string
+}
+
+func (f *mockCheckFaultInjector) InjectCheckFault(name string) (CheckResult, bool) {
+	if !f.armed[name] {
+		return nil, false
+	}
+	return &guardCheckResult{name: name, states: apiv1.HealthStates{{Name: name, Health: apiv1.HealthStateTypeUnhealthy, Reason: "injected"}}}, true
+}
+
+func (f *mockCheckFaultInjector) ClearCheckFault(name string) {
+	delete(f.armed, name)
+	f.cleared = append(f.cleared, name)
+}
+
+func TestCheckGuardFaultInjector(t *testing.T) {
+	guard := NewCheckGuard(2)
+	fi := &mockCheckFaultInjector{armed: map[string]bool{"test": true}}
+	guard.SetFaultInjector(fi)
+
+	inner := &panickingComponent{mockComponent: mockComponent{name: "test"}}
+
+	rs := guard.Check(inner)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, rs.HealthStateType())
+	assert.Equal(t, "injected", rs.Summary())
+	assert.Equal(t, 0, inner.checks)
+
+	states, ok := guard.HealthStates("test")
+	require.True(t, ok)
+	assert.Equal(t, "injected", states[0].Reason)
+	assert.False(t, guard.Quarantined("test"))
+
+	guard.Release("test")
+	assert.Equal(t, []string{"test"}, fi.cleared)
+
+	rs = guard.Check(inner)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, rs.HealthStateType())
+	assert.Equal(t, 1, inner.checks)
+	_, ok = guard.HealthStates("test")
+	assert.False(t, ok)
+}
+
+type payloadComponent struct {
+	panickingComponent
+	payload apiv2.Payload
+}
+
+func (p *payloadComponent) LastHealthStatesWithPayload() (apiv1.HealthStates, apiv2.Payload) {
+	return apiv1.HealthStates{{Name: p.name, Health: apiv1.HealthStateTypeHealthy}}, p.payload
+}
+
+func TestGuardedComponentPayload(t *testing.T) {
+	guard := NewCheckGuard(2)
+	inner := &payloadComponent{
+		panickingComponent: panickingComponent{mockComponent: mockComponent{name: "test"}},
+		payload:            &apiv2.IBPortsPayload{Ports: []apiv2.IBPort{{Device: "mlx5_0", Port: 1}}},
+	}
+	c, err := WrapInitFunc(func(*GPUdInstance) (Component, error) { return inner, nil }, guard)(nil)
+	require.NoError(t, err)
+
+	// the payload of the wrapped component is not hidden by the guard
+	states, payload := LastHealthStatesWithPayload(c)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, states[0].Health)
+	assert.Equal(t, inner.payload, payload)
+
+	// the guard states have no payload
+	inner.panic = true
+	_ = c.Check()
+	states, payload = LastHealthStatesWithPayload(c)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, states[0].Health)
+	assert.Nil(t, payload)
+
+	// no typed payload
+	states, payload = LastHealthStatesWithPayload(&mockComponent{name: "untyped"})
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, states[0].Health)
+	assert.Nil(t, payload)
+}
+
+type versionedComponent struct {
+	mockComponent
+}
+
+func (v *versionedComponent) Version() string { return "2" }
+
+func TestGuardedComponentVersion(t *testing.T) {
+	guard := NewCheckGuard(2)
+	c, err := WrapInitFunc(func(*GPUdInstance) (Component, error) {
+		return &versionedComponent{mockComponent: mockComponent{name: "test"}}, nil
+	}, guard)(nil)
+	require.NoError(t, err)
+
+	// the version of the wrapped component is not hidden by the guard
+	assert.Equal(t, "2", VersionOf(c))
+}
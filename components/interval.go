@@ -0,0 +1,125 @@
+package components
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultCheckInterval is the interval of the periodic component checks,
+	// unless overridden per component (e.g., "gpud run --component-intervals").
+	DefaultCheckInterval = time.Minute
+
+	// MinCheckInterval is the shortest configurable check interval,
+	// to not overload the node with the checks and the tools they run.
+	MinCheckInterval = time.Second
+)
+
+var (
+	ErrInvalidCheckInterval = errors.New("invalid check interval")
+	ErrUnknownComponent     = errors.New("unknown component")
+)
+
+// CheckIntervals maps the component names to their check intervals,
+// overriding the DefaultCheckInterval (e.g., infiniband every 30s, disk every 5m).
+type CheckIntervals map[string]time.Duration
+
+// Get returns the check interval of the component,
+// or DefaultCheckInterval if not configured.
+func (ci CheckIntervals) Get(componentName string) time.Duration {
+	if d, ok := ci[componentName]; ok && d > 0 {
+		return d
+	}
+	return DefaultCheckInterval
+}
+
+// Validate returns an error if any interval is shorter than MinCheckInterval.
+func (ci CheckIntervals) Validate() error {
+	for name, d := range ci {
+		if d < MinCheckInterval {
+			return fmt.Errorf("%w: %s of component %q (must be at least %s)", ErrInvalidCheckInterval, d, name, MinCheckInterval)
+		}
+	}
+	return nil
+}
+
+// ValidateComponents returns an error if any interval is set for the component
+// not in the component names (e.g., the typo of the component name).
+func (ci CheckIntervals) ValidateComponents(componentNames []string) error {
+	return validateComponentNames(ci, componentNames, "check intervals")
+}
+
+// validateComponentNames returns an error if any component configured
+// in the per-component settings is not in the known component names.
+func validateComponentNames(configured map[string]time.Duration, componentNames []string, setting string) error {
+	known := make(map[string]struct{}, len(componentNames))
+	for _, name := range componentNames {
+		known[name] = struct{}{}
+	}
+
+	names := make([]string, 0, len(configured))
+	for name := range configured {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, ok := known[name]; !ok {
+			return fmt.Errorf("%w %q in %s", ErrUnknownComponent, name, setting)
+		}
+	}
+	return nil
+}
+
+// NewCheckTicker returns the ticker of the periodic component checks at the interval,
+// or at the DefaultCheckInterval if the interval is not set
+// (e.g., the component not created with the GPUdInstance).
+func NewCheckTicker(interval time.Duration) *time.Ticker {
+	if interval <= 0 {
+		interval = DefaultCheckInterval
+	}
+	return time.NewTicker(interval)
+}
+
+// ParseCheckIntervals parses the comma-separated check intervals per component,
+// in the format of "<component>=<duration>" (e.g., "accelerator-nvidia-infiniband=30s,disk=5m").
+// The component names are validated with ValidateComponents, once all the components
+// (including the plugins) are known.
+func ParseCheckIntervals(s string) (CheckIntervals, error) {
+	m, err := parseComponentDurations(s, ErrInvalidCheckInterval)
+	if err != nil {
+		return nil, err
+	}
+	ci := CheckIntervals(m)
+	if err := ci.Validate(); err != nil {
+		return nil, err
+	}
+	return ci, nil
+}
+
+// parseComponentDurations parses the comma-separated "<component>=<duration>" pairs,
+// wrapping the parse errors with errInvalid.
+func parseComponentDurations(s string, errInvalid error) (map[string]time.Duration, error) {
+	m := make(map[string]time.Duration)
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		name, v, ok := strings.Cut(field, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%w: %q (expected <component>=<duration>)", errInvalid, field)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", errInvalid, field, err)
+		}
+		m[name] = d
+	}
+	return m, nil
+}
@@ -0,0 +1,88 @@
+package components
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckIntervalsGet(t *testing.T) {
+	ci := CheckIntervals{"disk": 5 * time.Minute, "cpu": 0}
+	assert.Equal(t, 5*time.Minute, ci.Get("disk"))
+	assert.Equal(t, DefaultCheckInterval, ci.Get("cpu"))
+	assert.Equal(t, DefaultCheckInterval, ci.Get("memory"))
+
+	var nilIntervals CheckIntervals
+	assert.Equal(t, DefaultCheckInterval, nilIntervals.Get("disk"))
+}
+
+func TestGPUdInstanceCheckInterval(t *testing.T) {
+	var nilInstance *GPUdInstance
+	assert.Equal(t, DefaultCheckInterval, nilInstance.CheckInterval("disk"))
+
+	assert.Equal(t, DefaultCheckInterval, (&GPUdInstance{}).CheckInterval("disk"))
+
+	g := &GPUdInstance{CheckIntervals: CheckIntervals{"accelerator-nvidia-infiniband": 30 * time.Second}}
+	assert.Equal(t, 30*time.Second, g.CheckInterval("accelerator-nvidia-infiniband"))
+	assert.Equal(t, DefaultCheckInterval, g.CheckInterval("disk"))
+}
+
+func TestParseCheckIntervals(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    CheckIntervals
+		wantErr bool
+	}{
+		{name: "empty", input: "", want: CheckIntervals{}},
+		{
+			name:  "multiple",
+			input: "accelerator-nvidia-infiniband=30s, disk=5m",
+			want:  CheckIntervals{"accelerator-nvidia-infiniband": 30 * time.Second, "disk": 5 * time.Minute},
+		},
+		{name: "trailing comma", input: "disk=5m,", want: CheckIntervals{"disk": 5 * time.Minute}},
+		{name: "missing duration", input: "disk", wantErr: true},
+		{name: "missing component", input: "=5m", wantErr: true},
+		{name: "invalid duration", input: "disk=5x", wantErr: true},
+		{name: "too short", input: "disk=100ms", wantErr: true},
+		{name: "negative", input: "disk=-1m", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCheckIntervals(tt.input)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidCheckInterval)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCheckIntervalsValidateComponents(t *testing.T) {
+	ci := CheckIntervals{"disk": 5 * time.Minute, "accelerator-nvidia-infiniband": 30 * time.Second}
+	assert.NoError(t, ci.ValidateComponents([]string{"accelerator-nvidia-infiniband", "cpu", "disk"}))
+
+	err := ci.ValidateComponents([]string{"cpu", "disk"})
+	require.ErrorIs(t, err, ErrUnknownComponent)
+	assert.Contains(t, err.Error(), `"accelerator-nvidia-infiniband"`)
+
+	assert.NoError(t, CheckIntervals(nil).ValidateComponents(nil))
+}
+
+func TestNewCheckTicker(t *testing.T) {
+	// the zero interval falls back to the default, rather than panicking
+	ticker := NewCheckTicker(0)
+	ticker.Stop()
+
+	ticker = NewCheckTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	select {
+	case <-ticker.C:
+	case <-time.After(5 * time.Second):
+		t.Fatal("ticker did not fire")
+	}
+}
@@ -32,6 +32,8 @@ type component struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	checkInterval time.Duration
+
 	checkDependencyInstalled func() bool
 	checkKubeletRunning      func() bool
 	kubeletReadOnlyPort      int
@@ -49,6 +51,8 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		ctx:    cctx,
 		cancel: ccancel,
 
+		checkInterval: gpudInstance.CheckInterval(Name),
+
 		checkDependencyInstalled: checkKubeletInstalled,
 		checkKubeletRunning: func() bool {
 			return netutil.IsPortOpen(defaultKubeletReadOnlyPort)
@@ -76,11 +80,11 @@ func (c *component) IsSupported() bool {
 
 func (c *component) Start() error {
 	go func() {
-		ticker := time.NewTicker(time.Minute)
+		ticker := components.NewCheckTicker(c.checkInterval)
 		defer ticker.Stop()
 
 		for {
-			_ = c.Check()
+			_ = components.SafeCheck(c)
 
 			select {
 			case <-c.ctx.Done():
@@ -11,14 +11,14 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	pkg_file "github.com/leptonai/gpud/pkg/file"
 	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/toolpath"
 )
 
 const DefaultKubeletReadOnlyPort = 10255
 
 func checkKubeletInstalled() bool {
-	p, err := pkg_file.LocateExecutable("kubelet")
+	p, err := toolpath.Locate("kubelet")
 	if err == nil {
 		log.Logger.Debugw("kubelet found in PATH", "path", p)
 		return true
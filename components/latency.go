@@ -0,0 +1,181 @@
+package components
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// DefaultCheckBudget is the default duration each component check
+	// is expected to complete within.
+	DefaultCheckBudget = 10 * time.Second
+
+	// DefaultOverBudgetWarnThreshold is the number of the consecutive checks
+	// over the budget, after which the component is warned about.
+	DefaultOverBudgetWarnThreshold = 3
+)
+
+// ErrInvalidCheckBudget is returned for the non-positive or malformed check budgets.
+var ErrInvalidCheckBudget = errors.New("invalid check budget")
+
+// CheckBudgets maps the component names to their check budgets,
+// overriding the DefaultCheckBudget (e.g., 1m for the slow nccl or infiniband checks).
+type CheckBudgets map[string]time.Duration
+
+// Validate returns an error if any budget is not positive.
+func (cb CheckBudgets) Validate() error {
+	for name, d := range cb {
+		if d <= 0 {
+			return fmt.Errorf("%w: %s of component %q (must be positive)", ErrInvalidCheckBudget, d, name)
+		}
+	}
+	return nil
+}
+
+// ValidateComponents returns an error if any budget is set for the component
+// not in the component names (e.g., the typo of the component name).
+func (cb CheckBudgets) ValidateComponents(componentNames []string) error {
+	return validateComponentNames(cb, componentNames, "check budgets")
+}
+
+// ParseCheckBudgets parses the comma-separated check budgets per component,
+// in the format of "<component>=<duration>" (e.g., "accelerator-nvidia-infiniband=30s").
+func ParseCheckBudgets(s string) (CheckBudgets, error) {
+	m, err := parseComponentDurations(s, ErrInvalidCheckBudget)
+	if err != nil {
+		return nil, err
+	}
+	cb := CheckBudgets(m)
+	if err := cb.Validate(); err != nil {
+		return nil, err
+	}
+	return cb, nil
+}
+
+// CheckStats tracks the duration and the CPU time of the component checks,
+// and warns on the components whose checks consistently exceed the budget.
+type CheckStats struct {
+	mu      sync.RWMutex
+	budgets map[string]time.Duration
+	stats   map[string]*checkStat
+}
+
+type checkStat struct {
+	checks        int64
+	lastDuration  time.Duration
+	totalDuration time.Duration
+	maxDuration   time.Duration
+	lastCPUTime   time.Duration
+	totalCPUTime  time.Duration
+	overBudget    int64
+	lastCheckTime time.Time
+}
+
+// NewCheckStats creates a new check stats tracker.
+func NewCheckStats() *CheckStats {
+	return &CheckStats{
+		budgets: make(map[string]time.Duration),
+		stats:   make(map[string]*checkStat),
+	}
+}
+
+// SetBudget overrides the check budget of the component.
+// Zero or negative budget resets to the default.
+func (s *CheckStats) SetBudget(name string, budget time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if budget <= 0 {
+		delete(s.budgets, name)
+		return
+	}
+	s.budgets[name] = budget
+}
+
+// SetBudgets overrides the check budgets of the components.
+func (s *CheckStats) SetBudgets(cb CheckBudgets) {
+	for name, budget := range cb {
+		s.SetBudget(name, budget)
+	}
+}
+
+func (s *CheckStats) budget(name string) time.Duration {
+	if b, ok := s.budgets[name]; ok {
+		return b
+	}
+	return DefaultCheckBudget
+}
+
+// Record records the duration and the CPU time of a check of the component.
+func (s *CheckStats) Record(name string, took time.Duration, cpuTime time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, ok := s.stats[name]
+	if !ok {
+		st = &checkStat{}
+		s.stats[name] = st
+	}
+	st.checks++
+	st.lastDuration = took
+	st.totalDuration += took
+	if took > st.maxDuration {
+		st.maxDuration = took
+	}
+	st.lastCPUTime = cpuTime
+	st.totalCPUTime += cpuTime
+	st.lastCheckTime = time.Now().UTC()
+
+	budget := s.budget(name)
+	if took <= budget {
+		if st.overBudget >= DefaultOverBudgetWarnThreshold {
+			log.Logger.Infow("component check back within budget", "component", name, "took", took, "budget", budget)
+		}
+		st.overBudget = 0
+		return
+	}
+
+	st.overBudget++
+	if st.overBudget == DefaultOverBudgetWarnThreshold {
+		log.Logger.Warnw("component check consistently exceeds budget",
+			"component", name,
+			"took", took,
+			"budget", budget,
+			"consecutive", st.overBudget,
+		)
+	}
+}
+
+// Get returns the check latencies of all the components, sorted by the component name.
+func (s *CheckStats) Get() apiv1.ComponentCheckLatencies {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rs := make(apiv1.ComponentCheckLatencies, 0, len(s.stats))
+	for name, st := range s.stats {
+		rs = append(rs, apiv1.ComponentCheckLatency{
+			Component:     name,
+			Checks:        st.checks,
+			LastDuration:  metav1.Duration{Duration: st.lastDuration},
+			AvgDuration:   metav1.Duration{Duration: st.totalDuration / time.Duration(st.checks)},
+			MaxDuration:   metav1.Duration{Duration: st.maxDuration},
+			LastCPUTime:   metav1.Duration{Duration: st.lastCPUTime},
+			AvgCPUTime:    metav1.Duration{Duration: st.totalCPUTime / time.Duration(st.checks)},
+			Budget:        metav1.Duration{Duration: s.budget(name)},
+			OverBudget:    st.overBudget,
+			LastCheckTime: metav1.NewTime(st.lastCheckTime),
+		})
+	}
+	sort.Slice(rs, func(i, j int) bool {
+		return rs[i].Component < rs[j].Component
+	})
+	return rs
+}
@@ -0,0 +1,81 @@
+package components
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckStats(t *testing.T) {
+	s := NewCheckStats()
+	s.SetBudget("slow", time.Second)
+
+	s.Record("slow", 2*time.Second, 100*time.Millisecond)
+	s.Record("slow", 4*time.Second, 300*time.Millisecond)
+	s.Record("fast", time.Millisecond, 0)
+
+	rs := s.Get()
+	require.Len(t, rs, 2)
+	assert.Equal(t, "fast", rs[0].Component)
+	assert.Equal(t, DefaultCheckBudget, rs[0].Budget.Duration)
+	assert.Equal(t, int64(0), rs[0].OverBudget)
+
+	assert.Equal(t, "slow", rs[1].Component)
+	assert.Equal(t, int64(2), rs[1].Checks)
+	assert.Equal(t, 4*time.Second, rs[1].LastDuration.Duration)
+	assert.Equal(t, 3*time.Second, rs[1].AvgDuration.Duration)
+	assert.Equal(t, 4*time.Second, rs[1].MaxDuration.Duration)
+	assert.Equal(t, 300*time.Millisecond, rs[1].LastCPUTime.Duration)
+	assert.Equal(t, 200*time.Millisecond, rs[1].AvgCPUTime.Duration)
+	assert.Equal(t, time.Second, rs[1].Budget.Duration)
+	assert.Equal(t, int64(2), rs[1].OverBudget)
+
+	// back within the budget resets the consecutive count
+	s.Record("slow", 500*time.Millisecond, 0)
+	assert.Equal(t, int64(0), s.Get()[1].OverBudget)
+
+	// resets to the default budget
+	s.SetBudget("slow", 0)
+	assert.Equal(t, DefaultCheckBudget, s.Get()[1].Budget.Duration)
+}
+
+func TestParseCheckBudgets(t *testing.T) {
+	cb, err := ParseCheckBudgets("accelerator-nvidia-infiniband=30s, disk=1m")
+	require.NoError(t, err)
+	assert.Equal(t, CheckBudgets{"accelerator-nvidia-infiniband": 30 * time.Second, "disk": time.Minute}, cb)
+
+	for _, input := range []string{"disk", "disk=5x", "disk=0s", "disk=-1m"} {
+		_, err := ParseCheckBudgets(input)
+		require.ErrorIs(t, err, ErrInvalidCheckBudget, input)
+	}
+
+	require.NoError(t, cb.ValidateComponents([]string{"accelerator-nvidia-infiniband", "disk"}))
+	err = cb.ValidateComponents([]string{"disk"})
+	require.ErrorIs(t, err, ErrUnknownComponent)
+	assert.Contains(t, err.Error(), "check budgets")
+
+	s := NewCheckStats()
+	s.SetBudgets(cb)
+	s.Record("disk", 30*time.Second, 0)
+	rs := s.Get()
+	require.Len(t, rs, 1)
+	assert.Equal(t, time.Minute, rs[0].Budget.Duration)
+	assert.Equal(t, int64(0), rs[0].OverBudget)
+}
+
+func TestCheckGuardRecordsStats(t *testing.T) {
+	guard := NewCheckGuard(2)
+	c := &panickingComponent{mockComponent: mockComponent{name: "test"}}
+
+	guard.Check(c)
+	c.panic = true
+	guard.Check(c)
+
+	rs := guard.Stats().Get()
+	require.Len(t, rs, 1)
+	assert.Equal(t, "test", rs[0].Component)
+	assert.Equal(t, int64(2), rs[0].Checks)
+	assert.False(t, rs[0].LastCheckTime.IsZero())
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/leptonai/gpud/pkg/log"
 	"github.com/leptonai/gpud/pkg/netutil/latency"
 	latencyedge "github.com/leptonai/gpud/pkg/netutil/latency/edge"
+	latencytarget "github.com/leptonai/gpud/pkg/netutil/latency/target"
 )
 
 // Name is the ID of the network latency component.
@@ -29,20 +30,37 @@ const (
 	MinGlobalMillisecondThreshold = 1000
 	// 7 seconds by default to reach any of the DERP servers.
 	DefaultGlobalMillisecondThreshold = 7000
+	// 1 second by default to connect to each user-provided target
+	// (e.g., the control plane, storage endpoints, peer nodes),
+	// which are expected to be much closer than the DERP servers.
+	DefaultTargetMillisecondThreshold = 1000
 )
 
-var _ components.Component = &component{}
+var (
+	_ components.Component  = &component{}
+	_ components.Downscoper = &component{}
+)
 
 type component struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	checkInterval time.Duration
+
 	getEgressLatenciesFunc func(context.Context, ...latencyedge.OpOption) (latency.Latencies, error)
 
+	// getTargetsFunc returns the user-provided targets (e.g., the control plane,
+	// storage endpoints, peer nodes) to measure the latencies against.
+	getTargetsFunc         func() []latencytarget.Target
+	getTargetLatenciesFunc func(context.Context, []latencytarget.Target, ...latencytarget.OpOption) (latency.Latencies, error)
+
 	// GlobalMillisecondThreshold is the global threshold in milliseconds for the DERP latency.
 	// If all DERP latencies are greater than this threshold, the component will be marked as failed.
 	// If at least one DERP latency is less than this threshold, the component will be marked as healthy.
 	globalMillisecondThreshold int64
+	// targetMillisecondThreshold is the threshold in milliseconds for the user-provided targets.
+	// Any target exceeding this threshold marks the component as failed.
+	targetMillisecondThreshold int64
 
 	lastMu          sync.RWMutex
 	lastCheckResult *checkResult
@@ -51,10 +69,16 @@ type component struct {
 func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
 	return &component{
-		ctx:                        cctx,
-		cancel:                     ccancel,
+		ctx:    cctx,
+		cancel: ccancel,
+
+		checkInterval: gpudInstance.CheckInterval(Name),
+
 		getEgressLatenciesFunc:     latencyedge.Measure,
+		getTargetsFunc:             GetDefaultTargets,
+		getTargetLatenciesFunc:     latencytarget.Measure,
 		globalMillisecondThreshold: DefaultGlobalMillisecondThreshold,
+		targetMillisecondThreshold: DefaultTargetMillisecondThreshold,
 	}, nil
 }
 
@@ -67,17 +91,22 @@ func (c *component) Tags() []string {
 	}
 }
 
+// Priority downscopes the latency probes while the node is under load.
+func (c *component) Priority() components.Priority {
+	return components.PriorityLow
+}
+
 func (c *component) IsSupported() bool {
 	return true
 }
 
 func (c *component) Start() error {
 	go func() {
-		ticker := time.NewTicker(time.Minute)
+		ticker := components.NewCheckTicker(c.checkInterval)
 		defer ticker.Stop()
 
 		for {
-			_ = c.Check()
+			_ = components.SafeCheck(c)
 
 			select {
 			case <-c.ctx.Done():
@@ -109,26 +138,44 @@ func (c *component) Close() error {
 }
 
 func (c *component) Check() components.CheckResult {
-	log.Logger.Infow("checking network egress latency")
+	return c.check(false)
+}
+
+// CheckDownscoped only probes the user-provided targets while the node is under load,
+// reusing the last egress latencies instead of probing the edge servers in all the regions.
+func (c *component) CheckDownscoped() components.CheckResult {
+	return c.check(true)
+}
+
+func (c *component) check(downscoped bool) components.CheckResult {
+	log.Logger.Infow("checking network egress latency", "downscoped", downscoped)
 
 	cr := &checkResult{
 		ts: time.Now().UTC(),
 	}
 
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+
 	defer func() {
 		c.lastMu.Lock()
 		c.lastCheckResult = cr
 		c.lastMu.Unlock()
 	}()
 
-	cctx, ccancel := context.WithTimeout(c.ctx, 15*time.Second)
-	cr.EgressLatencies, cr.err = c.getEgressLatenciesFunc(cctx)
-	ccancel()
-	if cr.err != nil {
-		cr.health = apiv1.HealthStateTypeUnhealthy
-		cr.reason = "error measuring egress latencies"
-		log.Logger.Errorw(cr.reason, "error", cr.err)
-		return cr
+	if downscoped && lastCheckResult != nil {
+		cr.EgressLatencies = lastCheckResult.EgressLatencies
+	} else {
+		cctx, ccancel := context.WithTimeout(c.ctx, 15*time.Second)
+		cr.EgressLatencies, cr.err = c.getEgressLatenciesFunc(cctx)
+		ccancel()
+		if cr.err != nil {
+			cr.health = apiv1.HealthStateTypeUnhealthy
+			cr.reason = "error measuring egress latencies"
+			log.Logger.Errorw(cr.reason, "error", cr.err)
+			return cr
+		}
 	}
 
 	exceededMsgs := []string{}
@@ -143,10 +190,37 @@ func (c *component) Check() components.CheckResult {
 		}
 	}
 
+	var targets []latencytarget.Target
+	if c.getTargetsFunc != nil {
+		targets = c.getTargetsFunc()
+	}
+	if len(targets) > 0 && c.getTargetLatenciesFunc != nil {
+		cctx, ccancel := context.WithTimeout(c.ctx, 15*time.Second)
+		var err error
+		cr.TargetLatencies, err = c.getTargetLatenciesFunc(cctx, targets)
+		ccancel()
+		if err != nil {
+			// the unreachable targets are reported along with the reachable ones
+			cr.err = err
+			exceededMsgs = append(exceededMsgs, fmt.Sprintf("failed to reach %d of %d latency target(s)", len(targets)-len(cr.TargetLatencies), len(targets)))
+			log.Logger.Warnw("failed to measure target latencies", "error", err)
+		}
+
+		for _, lat := range cr.TargetLatencies {
+			metricTargetInMilliseconds.With(prometheus.Labels{
+				"target": lat.RegionName,
+			}).Set(float64(lat.LatencyMilliseconds))
+
+			if c.targetMillisecondThreshold > 0 && lat.LatencyMilliseconds > c.targetMillisecondThreshold {
+				exceededMsgs = append(exceededMsgs, fmt.Sprintf("latency to target %s is %s (exceeded threshold %dms)", lat.RegionName, lat.Latency, c.targetMillisecondThreshold))
+			}
+		}
+	}
+
 	if len(exceededMsgs) == 0 {
 		cr.health = apiv1.HealthStateTypeHealthy
 		cr.reason = "checked egress latencies and no issue found"
-		log.Logger.Debugw(cr.reason, "servers", len(cr.EgressLatencies))
+		log.Logger.Debugw(cr.reason, "servers", len(cr.EgressLatencies), "targets", len(cr.TargetLatencies))
 	} else {
 		cr.health = apiv1.HealthStateTypeUnhealthy
 		cr.reason = strings.Join(exceededMsgs, "; ")
@@ -160,6 +234,8 @@ var _ components.CheckResult = &checkResult{}
 type checkResult struct {
 	// EgressLatencies is the list of egress latencies to global edge servers.
 	EgressLatencies latency.Latencies `json:"egress_latencies"`
+	// TargetLatencies is the list of latencies to the user-provided targets.
+	TargetLatencies latency.Latencies `json:"target_latencies,omitempty"`
 
 	// timestamp of the last check
 	ts time.Time
@@ -192,6 +268,12 @@ func (cr *checkResult) String() string {
 			lat.Latency.Duration.String(),
 		})
 	}
+	for _, lat := range cr.TargetLatencies {
+		table.Append([]string{
+			fmt.Sprintf("%s (%s)", lat.RegionName, lat.Provider),
+			lat.Latency.Duration.String(),
+		})
+	}
 
 	table.Render()
 
@@ -241,7 +323,7 @@ func (cr *checkResult) HealthStates() apiv1.HealthStates {
 		Health:    cr.health,
 	}
 
-	if len(cr.EgressLatencies) > 0 {
+	if len(cr.EgressLatencies) > 0 || len(cr.TargetLatencies) > 0 {
 		b, _ := json.Marshal(cr)
 		state.ExtraInfo = map[string]string{"data": string(b)}
 	}
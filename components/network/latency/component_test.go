@@ -17,6 +17,7 @@ import (
 	"github.com/leptonai/gpud/components"
 	"github.com/leptonai/gpud/pkg/netutil/latency"
 	latencyedge "github.com/leptonai/gpud/pkg/netutil/latency/edge"
+	latencytarget "github.com/leptonai/gpud/pkg/netutil/latency/target"
 )
 
 func TestDataGetError(t *testing.T) {
@@ -389,3 +390,137 @@ func TestComponentCheckWithDisabledThreshold(t *testing.T) {
 	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.health)
 	assert.Contains(t, cr.reason, "no issue found")
 }
+
+func TestComponentCheckWithTargets(t *testing.T) {
+	t.Parallel()
+
+	mockEdgeLatencies := []latency.Latency{
+		{
+			RegionName:          "us-west-2",
+			Provider:            "aws",
+			Latency:             metav1.Duration{Duration: 50 * time.Millisecond},
+			LatencyMilliseconds: 50,
+		},
+	}
+	targets := []latencytarget.Target{
+		{Name: "control-plane", Address: "cp.example.com:443"},
+		{Name: "storage", Address: "storage.example.com:443"},
+	}
+
+	tests := []struct {
+		name            string
+		targetLatencies latency.Latencies
+		targetErr       error
+		wantHealth      apiv1.HealthStateType
+		wantReason      string
+	}{
+		{
+			name: "all targets reachable",
+			targetLatencies: latency.Latencies{
+				{Provider: latencytarget.ProviderTarget, RegionName: "control-plane", Latency: metav1.Duration{Duration: 10 * time.Millisecond}, LatencyMilliseconds: 10},
+				{Provider: latencytarget.ProviderTarget, RegionName: "storage", Latency: metav1.Duration{Duration: 20 * time.Millisecond}, LatencyMilliseconds: 20},
+			},
+			wantHealth: apiv1.HealthStateTypeHealthy,
+			wantReason: "no issue found",
+		},
+		{
+			name: "target exceeds threshold",
+			targetLatencies: latency.Latencies{
+				{Provider: latencytarget.ProviderTarget, RegionName: "control-plane", Latency: metav1.Duration{Duration: 10 * time.Millisecond}, LatencyMilliseconds: 10},
+				{Provider: latencytarget.ProviderTarget, RegionName: "storage", Latency: metav1.Duration{Duration: 8 * time.Second}, LatencyMilliseconds: 8000},
+			},
+			wantHealth: apiv1.HealthStateTypeUnhealthy,
+			wantReason: "latency to target storage",
+		},
+		{
+			// below the DERP threshold, but too slow for the nearby targets
+			name: "target exceeds target threshold",
+			targetLatencies: latency.Latencies{
+				{Provider: latencytarget.ProviderTarget, RegionName: "control-plane", Latency: metav1.Duration{Duration: 10 * time.Millisecond}, LatencyMilliseconds: 10},
+				{Provider: latencytarget.ProviderTarget, RegionName: "storage", Latency: metav1.Duration{Duration: 2 * time.Second}, LatencyMilliseconds: 2000},
+			},
+			wantHealth: apiv1.HealthStateTypeUnhealthy,
+			wantReason: "exceeded threshold 1000ms",
+		},
+		{
+			name: "target unreachable",
+			targetLatencies: latency.Latencies{
+				{Provider: latencytarget.ProviderTarget, RegionName: "control-plane", Latency: metav1.Duration{Duration: 10 * time.Millisecond}, LatencyMilliseconds: 10},
+			},
+			targetErr:  errors.New("storage: connection refused"),
+			wantHealth: apiv1.HealthStateTypeUnhealthy,
+			wantReason: "failed to reach 1 of 2 latency target(s)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			comp := &component{
+				ctx:    ctx,
+				cancel: cancel,
+				getEgressLatenciesFunc: func(_ context.Context, _ ...latencyedge.OpOption) (latency.Latencies, error) {
+					return mockEdgeLatencies, nil
+				},
+				getTargetsFunc: func() []latencytarget.Target { return targets },
+				getTargetLatenciesFunc: func(_ context.Context, got []latencytarget.Target, _ ...latencytarget.OpOption) (latency.Latencies, error) {
+					assert.Equal(t, targets, got)
+					return tt.targetLatencies, tt.targetErr
+				},
+				globalMillisecondThreshold: DefaultGlobalMillisecondThreshold,
+				targetMillisecondThreshold: DefaultTargetMillisecondThreshold,
+			}
+
+			result := comp.Check()
+			cr, ok := result.(*checkResult)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantHealth, cr.health)
+			assert.Contains(t, cr.reason, tt.wantReason)
+			assert.Equal(t, tt.targetLatencies, cr.TargetLatencies)
+			assert.Contains(t, cr.String(), "control-plane (target)")
+
+			states := cr.HealthStates()
+			require.Len(t, states, 1)
+			assert.Contains(t, states[0].ExtraInfo["data"], "target_latencies")
+		})
+	}
+}
+
+func TestComponentCheckDownscoped(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	edgeCalls := 0
+	targets := []latencytarget.Target{{Name: "control-plane", Address: "cp.example.com:443"}}
+	comp := &component{
+		ctx:    ctx,
+		cancel: cancel,
+		getEgressLatenciesFunc: func(_ context.Context, _ ...latencyedge.OpOption) (latency.Latencies, error) {
+			edgeCalls++
+			return latency.Latencies{{RegionName: "us-west-2", Provider: "aws", LatencyMilliseconds: 50}}, nil
+		},
+		getTargetsFunc: func() []latencytarget.Target { return targets },
+		getTargetLatenciesFunc: func(_ context.Context, _ []latencytarget.Target, _ ...latencytarget.OpOption) (latency.Latencies, error) {
+			return latency.Latencies{{Provider: latencytarget.ProviderTarget, RegionName: "control-plane", LatencyMilliseconds: 2000}}, nil
+		},
+		globalMillisecondThreshold: DefaultGlobalMillisecondThreshold,
+		targetMillisecondThreshold: DefaultTargetMillisecondThreshold,
+	}
+
+	// probes the edge servers without the last result
+	cr := comp.CheckDownscoped()
+	assert.Equal(t, 1, edgeCalls)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+
+	// reuses the last edge latencies, and still probes the targets
+	cr = comp.CheckDownscoped()
+	assert.Equal(t, 1, edgeCalls)
+	assert.Contains(t, cr.Summary(), "latency to target control-plane")
+	require.Len(t, cr.(*checkResult).EgressLatencies, 1)
+
+	_ = comp.Check()
+	assert.Equal(t, 2, edgeCalls)
+}
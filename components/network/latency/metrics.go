@@ -22,8 +22,21 @@ var (
 		},
 		[]string{pkgmetrics.MetricComponentLabelKey, "region"}, // label is provider region
 	).MustCurryWith(componentLabel)
+
+	metricTargetInMilliseconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "target_in_milliseconds",
+			Help:      "tracks the latency to the user-provided target in milliseconds",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "target"}, // label is target name
+	).MustCurryWith(componentLabel)
 )
 
 func init() {
-	pkgmetrics.MustRegister(metricEdgeInMilliseconds)
+	pkgmetrics.MustRegister(
+		metricEdgeInMilliseconds,
+		metricTargetInMilliseconds,
+	)
 }
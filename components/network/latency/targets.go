@@ -0,0 +1,31 @@
+package latency
+
+import (
+	"sync"
+
+	"github.com/leptonai/gpud/pkg/log"
+	latencytarget "github.com/leptonai/gpud/pkg/netutil/latency/target"
+)
+
+var (
+	defaultTargetsMu sync.RWMutex
+	defaultTargets   []latencytarget.Target
+)
+
+// GetDefaultTargets returns the user-provided targets to measure the latencies against,
+// in addition to the global edge servers.
+func GetDefaultTargets() []latencytarget.Target {
+	defaultTargetsMu.RLock()
+	defer defaultTargetsMu.RUnlock()
+	return defaultTargets
+}
+
+// SetDefaultTargets sets the user-provided targets (e.g., the control plane,
+// storage endpoints, peer nodes) to measure the latencies against.
+func SetDefaultTargets(targets []latencytarget.Target) {
+	log.Logger.Infow("setting default latency targets", "targets", len(targets))
+
+	defaultTargetsMu.Lock()
+	defer defaultTargetsMu.Unlock()
+	defaultTargets = targets
+}
@@ -0,0 +1,296 @@
+// Package lldp records the Ethernet switch ports the NICs are connected to,
+// from the LLDP neighbors, and validates them against the expected cabling map
+// to flag the miscabled nodes (e.g., during the bring-up).
+package lldp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	pkglldp "github.com/leptonai/gpud/pkg/lldp"
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/toolexec"
+)
+
+// Name is the ID of the LLDP component.
+const Name = "network-lldp"
+
+var _ components.Component = &component{}
+
+type component struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	checkInterval time.Duration
+
+	hostname string
+
+	lldpctl           string
+	getNeighborsFunc  func(ctx context.Context, lldpctl string) ([]pkglldp.Neighbor, error)
+	getCablingMapFunc func() pkglldp.CablingMap
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
+	cctx, ccancel := context.WithCancel(toolexec.WithComponent(gpudInstance.RootCtx, Name))
+	return &component{
+		ctx:    cctx,
+		cancel: ccancel,
+
+		checkInterval: gpudInstance.CheckInterval(Name),
+
+		hostname: hostname,
+
+		lldpctl:           pkglldp.DefaultLLDPCtl,
+		getNeighborsFunc:  pkglldp.GetNeighbors,
+		getCablingMapFunc: GetDefaultCablingMap,
+	}, nil
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"network",
+		Name,
+	}
+}
+
+func (c *component) IsSupported() bool {
+	return true
+}
+
+func (c *component) Start() error {
+	go func() {
+		ticker := components.NewCheckTicker(c.checkInterval)
+		defer ticker.Stop()
+
+		for {
+			_ = components.SafeCheck(c)
+
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	return nil, nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking lldp neighbors")
+
+	cr := &checkResult{
+		ts: time.Now().UTC(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	expected := c.getCablingMapFunc().ForHost(c.hostname)
+
+	cctx, ccancel := context.WithTimeout(c.ctx, 15*time.Second)
+	cr.Neighbors, cr.err = c.getNeighborsFunc(cctx, c.lldpctl)
+	ccancel()
+	if cr.err != nil {
+		if errors.Is(cr.err, pkglldp.ErrNoLLDPCtlCommand) || errors.Is(cr.err, pkglldp.ErrLLDPDNotRunning) {
+			cause := "lldpctl not found"
+			if errors.Is(cr.err, pkglldp.ErrLLDPDNotRunning) {
+				cause = "lldpd not running"
+			}
+			if len(expected) == 0 {
+				// lldp is optional without the cabling map to validate against
+				cr.err = nil
+				cr.health = apiv1.HealthStateTypeHealthy
+				cr.reason = cause + ", skipping lldp neighbor discovery"
+				return cr
+			}
+
+			cr.health = apiv1.HealthStateTypeUnhealthy
+			cr.reason = fmt.Sprintf("%s, cannot validate %d interface(s) against the cabling map (install and start lldpd)", cause, len(expected))
+			log.Logger.Warnw(cr.reason, "error", cr.err)
+			return cr
+		}
+
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = "error getting lldp neighbors"
+		log.Logger.Errorw(cr.reason, "error", cr.err)
+		return cr
+	}
+
+	if len(expected) == 0 {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = fmt.Sprintf("found %d lldp neighbor(s), no cabling map to validate against", len(cr.Neighbors))
+		return cr
+	}
+
+	cr.Mismatches = pkglldp.FindMismatches(expected, cr.Neighbors)
+	if len(cr.Mismatches) == 0 {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = fmt.Sprintf("%d interface(s) cabled as expected", len(expected))
+		return cr
+	}
+
+	msgs := make([]string, 0, len(cr.Mismatches))
+	for _, m := range cr.Mismatches {
+		msgs = append(msgs, m.Reason)
+	}
+	cr.health = apiv1.HealthStateTypeUnhealthy
+	cr.reason = fmt.Sprintf("%d of %d interface(s) miscabled: %s", len(cr.Mismatches), len(expected), strings.Join(msgs, "; "))
+	cr.suggestedActions = &apiv1.SuggestedActions{
+		RepairActions: []apiv1.RepairActionType{apiv1.RepairActionTypeHardwareInspection},
+	}
+	log.Logger.Warnw(cr.reason)
+	return cr
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	// Neighbors is the list of the discovered LLDP neighbors per interface.
+	Neighbors []pkglldp.Neighbor `json:"neighbors,omitempty"`
+	// Mismatches is the list of the interfaces not cabled as expected.
+	Mismatches []pkglldp.Mismatch `json:"mismatches,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the suggested actions of the last check
+	suggestedActions *apiv1.SuggestedActions
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if len(cr.Neighbors) == 0 {
+		return "no lldp neighbor found"
+	}
+
+	buf := bytes.NewBuffer(nil)
+	table := tablewriter.NewWriter(buf)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+
+	table.SetHeader([]string{"Interface", "Switch", "Chassis ID", "Port"})
+	for _, n := range cr.Neighbors {
+		table.Append([]string{
+			n.Interface,
+			n.SystemName,
+			n.ChassisID,
+			n.PortID,
+		})
+	}
+
+	table.Render()
+
+	return buf.String()
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getSuggestedActions() *apiv1.SuggestedActions {
+	if cr == nil {
+		return nil
+	}
+	return cr.suggestedActions
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:             metav1.NewTime(cr.ts),
+		Component:        Name,
+		Name:             Name,
+		Reason:           cr.reason,
+		SuggestedActions: cr.getSuggestedActions(),
+		Error:            cr.getError(),
+		Health:           cr.health,
+	}
+
+	if len(cr.Neighbors) > 0 || len(cr.Mismatches) > 0 {
+		b, _ := json.Marshal(cr)
+		state.ExtraInfo = map[string]string{"data": string(b)}
+	}
+	return apiv1.HealthStates{state}
+}
@@ -0,0 +1,178 @@
+package lldp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	pkglldp "github.com/leptonai/gpud/pkg/lldp"
+)
+
+func TestComponentBasics(t *testing.T) {
+	comp, err := New(&components.GPUdInstance{RootCtx: context.Background()})
+	require.NoError(t, err)
+	defer comp.Close()
+
+	assert.Equal(t, Name, comp.Name())
+	assert.Equal(t, []string{"network", Name}, comp.Tags())
+	assert.True(t, comp.IsSupported())
+
+	events, err := comp.Events(context.Background(), time.Now())
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+
+	states := comp.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, "no data yet", states[0].Reason)
+}
+
+func TestCheck(t *testing.T) {
+	neighbors := []pkglldp.Neighbor{
+		{Interface: "eth0", SystemName: "leaf-01", ChassisID: "0c:42:a1:00:00:01", PortID: "swp11"},
+		{Interface: "eth1", SystemName: "leaf-02", ChassisID: "0c:42:a1:00:00:02", PortID: "swp12"},
+	}
+
+	tests := []struct {
+		name          string
+		neighbors     []pkglldp.Neighbor
+		err           error
+		cablingMap    pkglldp.CablingMap
+		wantHealth    apiv1.HealthStateType
+		wantReason    string
+		wantErr       bool
+		wantAction    bool
+		wantMismatchN int
+	}{
+		{
+			name:       "lldpctl not found",
+			err:        pkglldp.ErrNoLLDPCtlCommand,
+			wantHealth: apiv1.HealthStateTypeHealthy,
+			wantReason: "lldpctl not found",
+		},
+		{
+			name:       "lldpd not running",
+			err:        pkglldp.ErrLLDPDNotRunning,
+			wantHealth: apiv1.HealthStateTypeHealthy,
+			wantReason: "lldpd not running",
+		},
+		{
+			name: "lldpctl not found with cabling map",
+			err:  pkglldp.ErrNoLLDPCtlCommand,
+			cablingMap: pkglldp.CablingMap{
+				{Interface: "eth0", Switch: "leaf-01", Port: "swp11"},
+			},
+			wantHealth: apiv1.HealthStateTypeUnhealthy,
+			wantReason: "lldpctl not found, cannot validate 1 interface(s) against the cabling map",
+			wantErr:    true,
+		},
+		{
+			name: "lldpd not running with cabling map",
+			err:  pkglldp.ErrLLDPDNotRunning,
+			cablingMap: pkglldp.CablingMap{
+				{Interface: "eth0", Switch: "leaf-01", Port: "swp11"},
+			},
+			wantHealth: apiv1.HealthStateTypeUnhealthy,
+			wantReason: "lldpd not running, cannot validate 1 interface(s) against the cabling map",
+			wantErr:    true,
+		},
+		{
+			name:       "lldpctl failed",
+			err:        errors.New("exit status 1"),
+			wantHealth: apiv1.HealthStateTypeUnhealthy,
+			wantReason: "error getting lldp neighbors",
+			wantErr:    true,
+		},
+		{
+			name:       "no cabling map",
+			neighbors:  neighbors,
+			wantHealth: apiv1.HealthStateTypeHealthy,
+			wantReason: "found 2 lldp neighbor(s), no cabling map to validate against",
+		},
+		{
+			name:      "cabling map for the other host",
+			neighbors: neighbors,
+			cablingMap: pkglldp.CablingMap{
+				{Host: "node-b", Interface: "eth0", Switch: "leaf-09"},
+			},
+			wantHealth: apiv1.HealthStateTypeHealthy,
+			wantReason: "no cabling map to validate against",
+		},
+		{
+			name:      "cabled as expected",
+			neighbors: neighbors,
+			cablingMap: pkglldp.CablingMap{
+				{Host: "node-a", Interface: "eth0", Switch: "leaf-01", Port: "swp11"},
+				{Host: "node-a", Interface: "eth1", Switch: "leaf-02", Port: "swp12"},
+			},
+			wantHealth: apiv1.HealthStateTypeHealthy,
+			wantReason: "2 interface(s) cabled as expected",
+		},
+		{
+			name:      "swapped cables",
+			neighbors: neighbors,
+			cablingMap: pkglldp.CablingMap{
+				{Interface: "eth0", Switch: "leaf-02", Port: "swp12"},
+				{Interface: "eth1", Switch: "leaf-01", Port: "swp11"},
+			},
+			wantHealth:    apiv1.HealthStateTypeUnhealthy,
+			wantReason:    "2 of 2 interface(s) miscabled: eth0 connected to leaf-01 swp11 (expected leaf-02 swp12)",
+			wantAction:    true,
+			wantMismatchN: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			c := &component{
+				ctx:      ctx,
+				cancel:   cancel,
+				hostname: "node-a",
+				getNeighborsFunc: func(context.Context, string) ([]pkglldp.Neighbor, error) {
+					return tt.neighbors, tt.err
+				},
+				getCablingMapFunc: func() pkglldp.CablingMap { return tt.cablingMap },
+			}
+			defer c.Close()
+
+			cr, ok := c.Check().(*checkResult)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantHealth, cr.HealthStateType())
+			assert.Contains(t, cr.Summary(), tt.wantReason)
+			assert.Len(t, cr.Mismatches, tt.wantMismatchN)
+
+			states := c.LastHealthStates()
+			require.Len(t, states, 1)
+			assert.Equal(t, tt.wantHealth, states[0].Health)
+			if tt.wantErr {
+				assert.NotEmpty(t, states[0].Error)
+			} else {
+				assert.Empty(t, states[0].Error)
+			}
+			if tt.wantAction {
+				require.NotNil(t, states[0].SuggestedActions)
+				assert.Equal(t, []apiv1.RepairActionType{apiv1.RepairActionTypeHardwareInspection}, states[0].SuggestedActions.RepairActions)
+			} else {
+				assert.Nil(t, states[0].SuggestedActions)
+			}
+			if len(tt.neighbors) > 0 {
+				assert.Contains(t, states[0].ExtraInfo["data"], "leaf-01")
+				assert.Contains(t, cr.String(), "swp11")
+			}
+		})
+	}
+}
+
+func TestCheckResultNil(t *testing.T) {
+	var cr *checkResult
+	assert.Equal(t, "", cr.String())
+	assert.Equal(t, "", cr.Summary())
+	assert.Equal(t, apiv1.HealthStateType(""), cr.HealthStateType())
+	assert.Equal(t, "", cr.getError())
+	assert.Nil(t, cr.getSuggestedActions())
+}
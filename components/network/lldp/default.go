@@ -0,0 +1,31 @@
+package lldp
+
+import (
+	"sync"
+
+	pkglldp "github.com/leptonai/gpud/pkg/lldp"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+var (
+	defaultCablingMapMu sync.RWMutex
+	defaultCablingMap   = make(pkglldp.CablingMap, 0)
+)
+
+// GetDefaultCablingMap returns the expected cabling map to validate the LLDP neighbors against.
+func GetDefaultCablingMap() pkglldp.CablingMap {
+	defaultCablingMapMu.RLock()
+	defer defaultCablingMapMu.RUnlock()
+
+	return defaultCablingMap
+}
+
+// SetDefaultCablingMap sets the expected cabling map, either from the static file
+// or pushed from the control plane.
+func SetDefaultCablingMap(m pkglldp.CablingMap) {
+	log.Logger.Infow("setting default lldp cabling map", "links", len(m))
+
+	defaultCablingMapMu.Lock()
+	defer defaultCablingMapMu.Unlock()
+	defaultCablingMap = m
+}
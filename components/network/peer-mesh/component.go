@@ -0,0 +1,358 @@
+// Package peermesh tracks the latencies and the packet loss to the peer gpud nodes,
+// to spot the rack-level network issues that the single-node checks miss.
+package peermesh
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/log"
+	latencytarget "github.com/leptonai/gpud/pkg/netutil/latency/target"
+	pkgpeermesh "github.com/leptonai/gpud/pkg/peer-mesh"
+)
+
+// Name is the ID of the peer mesh component.
+const Name = "network-peer-mesh"
+
+const (
+	// DefaultPacketLossThresholdPercent is the packet loss percentage
+	// above which the peer is degraded.
+	DefaultPacketLossThresholdPercent = float64(10)
+	// DefaultLatencyThresholdMilliseconds is the average latency
+	// above which the peer is degraded, in the same cluster network.
+	DefaultLatencyThresholdMilliseconds = int64(50)
+	// DefaultDownscopedPeers is the number of the peers probed per check
+	// while the node is under load, rotating through all the peers.
+	DefaultDownscopedPeers = 8
+)
+
+var (
+	_ components.Component  = &component{}
+	_ components.Downscoper = &component{}
+)
+
+type component struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	checkInterval time.Duration
+
+	getPeersFunc   func() pkgpeermesh.Peers
+	probePeersFunc func(context.Context, pkgpeermesh.Peers, ...latencytarget.OpOption) []pkgpeermesh.PeerStatus
+
+	thresholds pkgpeermesh.Thresholds
+
+	// downscopedOffset is the index of the first peer probed by the next downscoped check
+	downscopedOffset int
+	// metricLabels are the labels of the peer metrics set by the previous checks,
+	// to delete the metrics of the peers no longer configured
+	metricLabels map[string]prometheus.Labels
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+	return &component{
+		ctx:    cctx,
+		cancel: ccancel,
+
+		checkInterval: gpudInstance.CheckInterval(Name),
+
+		getPeersFunc:   GetDefaultPeers,
+		probePeersFunc: pkgpeermesh.ProbePeers,
+		thresholds: pkgpeermesh.Thresholds{
+			PacketLossPercent:   DefaultPacketLossThresholdPercent,
+			LatencyMilliseconds: DefaultLatencyThresholdMilliseconds,
+		},
+	}, nil
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"network",
+		Name,
+	}
+}
+
+// Priority downscopes the peer probes while the node is under load.
+func (c *component) Priority() components.Priority {
+	return components.PriorityLow
+}
+
+func (c *component) IsSupported() bool {
+	return true
+}
+
+func (c *component) Start() error {
+	go func() {
+		ticker := components.NewCheckTicker(c.checkInterval)
+		defer ticker.Stop()
+
+		for {
+			_ = components.SafeCheck(c)
+
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// rotatePeers returns up to n peers from the offset (wrapping around),
+// and the offset of the next rotation.
+func rotatePeers(peers pkgpeermesh.Peers, offset int, n int) (pkgpeermesh.Peers, int) {
+	if len(peers) <= n {
+		return peers, 0
+	}
+	offset %= len(peers)
+	rotated := make(pkgpeermesh.Peers, 0, n)
+	for i := 0; i < n; i++ {
+		rotated = append(rotated, peers[(offset+i)%len(peers)])
+	}
+	return rotated, (offset + n) % len(peers)
+}
+
+func peerMetricLabels(p pkgpeermesh.Peer) prometheus.Labels {
+	return prometheus.Labels{"peer": p.ID, "rack": p.Rack}
+}
+
+func peerMetricKey(labels prometheus.Labels) string {
+	return labels["rack"] + "/" + labels["peer"]
+}
+
+// deleteStaleMetrics deletes the metrics of the peers removed from
+// (or moved to another rack in) the configured peers, so that the removed
+// peers are not reported with their last latency and packet loss forever.
+// The peers skipped by the downscoped checks are still configured,
+// thus their metrics are kept.
+func (c *component) deleteStaleMetrics(peers pkgpeermesh.Peers) {
+	configured := make(map[string]struct{}, len(peers))
+	for _, p := range peers {
+		configured[peerMetricKey(peerMetricLabels(p))] = struct{}{}
+	}
+
+	c.lastMu.Lock()
+	defer c.lastMu.Unlock()
+	for k, labels := range c.metricLabels {
+		if _, ok := configured[k]; ok {
+			continue
+		}
+		metricLatencyInMilliseconds.Delete(labels)
+		metricPacketLossPercent.Delete(labels)
+		delete(c.metricLabels, k)
+	}
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	return nil, nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	return c.check(false)
+}
+
+// CheckDownscoped only probes up to DefaultDownscopedPeers peers while the node is under load,
+// rotating through all the peers across the checks.
+func (c *component) CheckDownscoped() components.CheckResult {
+	return c.check(true)
+}
+
+func (c *component) check(downscoped bool) components.CheckResult {
+	log.Logger.Infow("checking peer mesh", "downscoped", downscoped)
+
+	cr := &checkResult{
+		ts: time.Now().UTC(),
+	}
+
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	peers := c.getPeersFunc()
+	c.deleteStaleMetrics(peers)
+	if len(peers) == 0 {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "no peer configured"
+		return cr
+	}
+	if downscoped {
+		c.lastMu.Lock()
+		peers, c.downscopedOffset = rotatePeers(peers, c.downscopedOffset, DefaultDownscopedPeers)
+		c.lastMu.Unlock()
+	}
+
+	cctx, ccancel := context.WithTimeout(c.ctx, 30*time.Second)
+	cr.Peers = c.probePeersFunc(cctx, peers)
+	ccancel()
+
+	var degraded []string
+	for _, p := range cr.Peers {
+		labels := peerMetricLabels(p.Peer)
+		metricLatencyInMilliseconds.With(labels).Set(float64(p.LatencyMilliseconds))
+		metricPacketLossPercent.With(labels).Set(p.PacketLossPercent)
+		c.lastMu.Lock()
+		if c.metricLabels == nil {
+			c.metricLabels = make(map[string]prometheus.Labels)
+		}
+		c.metricLabels[peerMetricKey(labels)] = labels
+		c.lastMu.Unlock()
+
+		if c.thresholds.Degraded(p) {
+			degraded = append(degraded, p.ID)
+		}
+	}
+	cr.Racks = pkgpeermesh.SummarizeRacks(cr.Peers, c.thresholds)
+
+	if len(degraded) == 0 {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = fmt.Sprintf("probed %d peer(s) and no issue found", len(cr.Peers))
+		return cr
+	}
+
+	// every peer is degraded, regardless of the rack,
+	// thus the issue is likely on this node (e.g., NIC, cable, local switch port)
+	if len(degraded) == len(cr.Peers) {
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = fmt.Sprintf("all %d peer(s) degraded (local network issue suspected)", len(cr.Peers))
+		log.Logger.Warnw(cr.reason, "peers", degraded)
+		return cr
+	}
+
+	msgs := []string{}
+	for _, r := range cr.Racks {
+		if r.Rack != "" && r.AllDegraded() {
+			msgs = append(msgs, fmt.Sprintf("all %d peer(s) in rack %s degraded (rack-level network issue suspected)", r.Peers, r.Rack))
+		}
+	}
+	msgs = append(msgs, fmt.Sprintf("%d of %d peer(s) degraded: %s", len(degraded), len(cr.Peers), strings.Join(degraded, ", ")))
+
+	cr.health = apiv1.HealthStateTypeDegraded
+	cr.reason = strings.Join(msgs, "; ")
+	log.Logger.Warnw(cr.reason, "peers", degraded)
+	return cr
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	// Peers is the list of the probe results per peer.
+	Peers []pkgpeermesh.PeerStatus `json:"peers,omitempty"`
+	// Racks is the summary of the probe results per rack.
+	Racks []pkgpeermesh.RackStatus `json:"racks,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if len(cr.Peers) == 0 {
+		return "no peer probed"
+	}
+
+	buf := bytes.NewBuffer(nil)
+	table := tablewriter.NewWriter(buf)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+
+	table.SetHeader([]string{"Peer", "Rack", "Latency", "Packet Loss"})
+	for _, p := range cr.Peers {
+		table.Append([]string{
+			p.ID,
+			p.Rack,
+			p.Latency.Duration.String(),
+			fmt.Sprintf("%.1f%%", p.PacketLossPercent),
+		})
+	}
+
+	table.Render()
+
+	return buf.String()
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:      metav1.NewTime(cr.ts),
+		Component: Name,
+		Name:      Name,
+		Reason:    cr.reason,
+		Health:    cr.health,
+	}
+
+	if len(cr.Peers) > 0 {
+		b, _ := json.Marshal(cr)
+		state.ExtraInfo = map[string]string{"data": string(b)}
+	}
+	return apiv1.HealthStates{state}
+}
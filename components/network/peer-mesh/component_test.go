@@ -0,0 +1,266 @@
+package peermesh
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	latencytarget "github.com/leptonai/gpud/pkg/netutil/latency/target"
+	pkgpeermesh "github.com/leptonai/gpud/pkg/peer-mesh"
+)
+
+func healthyPeer(id, rack string) pkgpeermesh.PeerStatus {
+	return pkgpeermesh.PeerStatus{
+		Peer:                pkgpeermesh.Peer{ID: id, Address: id + ":15132", Rack: rack},
+		Sent:                10,
+		Received:            10,
+		Latency:             metav1.Duration{Duration: time.Millisecond},
+		LatencyMilliseconds: 1,
+	}
+}
+
+func lostPeer(id, rack string) pkgpeermesh.PeerStatus {
+	return pkgpeermesh.PeerStatus{
+		Peer:              pkgpeermesh.Peer{ID: id, Address: id + ":15132", Rack: rack},
+		Sent:              10,
+		PacketLossPercent: 100,
+		Error:             "i/o timeout",
+	}
+}
+
+func TestComponentBasics(t *testing.T) {
+	comp, err := New(&components.GPUdInstance{RootCtx: context.Background()})
+	require.NoError(t, err)
+	defer comp.Close()
+
+	assert.Equal(t, Name, comp.Name())
+	assert.Equal(t, []string{"network", Name}, comp.Tags())
+	assert.True(t, comp.IsSupported())
+
+	events, err := comp.Events(context.Background(), time.Now())
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+
+	states := comp.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, states[0].Health)
+	assert.Equal(t, "no data yet", states[0].Reason)
+}
+
+func TestCheck(t *testing.T) {
+	peers := pkgpeermesh.Peers{{ID: "placeholder", Address: "10.0.0.1:15132"}}
+
+	tests := []struct {
+		name       string
+		peers      pkgpeermesh.Peers
+		statuses   []pkgpeermesh.PeerStatus
+		wantHealth apiv1.HealthStateType
+		wantReason []string
+	}{
+		{
+			name:       "no peer configured",
+			wantHealth: apiv1.HealthStateTypeHealthy,
+			wantReason: []string{"no peer configured"},
+		},
+		{
+			name:  "all peers healthy",
+			peers: peers,
+			statuses: []pkgpeermesh.PeerStatus{
+				healthyPeer("node-1", "rack-1"),
+				healthyPeer("node-2", "rack-2"),
+			},
+			wantHealth: apiv1.HealthStateTypeHealthy,
+			wantReason: []string{"probed 2 peer(s) and no issue found"},
+		},
+		{
+			name:  "all peers degraded",
+			peers: peers,
+			statuses: []pkgpeermesh.PeerStatus{
+				lostPeer("node-1", "rack-1"),
+				lostPeer("node-2", "rack-2"),
+			},
+			wantHealth: apiv1.HealthStateTypeUnhealthy,
+			wantReason: []string{"all 2 peer(s) degraded (local network issue suspected)"},
+		},
+		{
+			name:  "rack-level issue",
+			peers: peers,
+			statuses: []pkgpeermesh.PeerStatus{
+				healthyPeer("node-1", "rack-1"),
+				lostPeer("node-2", "rack-2"),
+				lostPeer("node-3", "rack-2"),
+			},
+			wantHealth: apiv1.HealthStateTypeDegraded,
+			wantReason: []string{
+				"all 2 peer(s) in rack rack-2 degraded (rack-level network issue suspected)",
+				"2 of 3 peer(s) degraded: node-2, node-3",
+			},
+		},
+		{
+			name:  "single peer slow",
+			peers: peers,
+			statuses: []pkgpeermesh.PeerStatus{
+				healthyPeer("node-1", "rack-1"),
+				{
+					Peer:                pkgpeermesh.Peer{ID: "node-2", Rack: "rack-1"},
+					Sent:                10,
+					Received:            10,
+					Latency:             metav1.Duration{Duration: 200 * time.Millisecond},
+					LatencyMilliseconds: 200,
+				},
+			},
+			wantHealth: apiv1.HealthStateTypeDegraded,
+			wantReason: []string{"1 of 2 peer(s) degraded: node-2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			c := &component{
+				ctx:          ctx,
+				cancel:       cancel,
+				getPeersFunc: func() pkgpeermesh.Peers { return tt.peers },
+				probePeersFunc: func(context.Context, pkgpeermesh.Peers, ...latencytarget.OpOption) []pkgpeermesh.PeerStatus {
+					return tt.statuses
+				},
+				thresholds: pkgpeermesh.Thresholds{
+					PacketLossPercent:   DefaultPacketLossThresholdPercent,
+					LatencyMilliseconds: DefaultLatencyThresholdMilliseconds,
+				},
+			}
+			defer c.Close()
+
+			cr, ok := c.Check().(*checkResult)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantHealth, cr.HealthStateType())
+			for _, r := range tt.wantReason {
+				assert.Contains(t, cr.Summary(), r)
+			}
+			if tt.name == "rack-level issue" {
+				assert.NotContains(t, cr.Summary(), "rack rack-1")
+			}
+
+			states := c.LastHealthStates()
+			require.Len(t, states, 1)
+			assert.Equal(t, tt.wantHealth, states[0].Health)
+			if len(tt.statuses) > 0 {
+				assert.Contains(t, states[0].ExtraInfo["data"], `"racks"`)
+				assert.Contains(t, cr.String(), "node-1")
+			} else {
+				assert.Nil(t, states[0].ExtraInfo)
+			}
+		})
+	}
+}
+
+func TestCheckDownscoped(t *testing.T) {
+	peers := pkgpeermesh.Peers{}
+	for i := 0; i < 10; i++ {
+		peers = append(peers, pkgpeermesh.Peer{ID: fmt.Sprintf("node-%d", i), Address: fmt.Sprintf("10.0.0.%d:15132", i)})
+	}
+
+	var probed []pkgpeermesh.Peers
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &component{
+		ctx:          ctx,
+		cancel:       cancel,
+		getPeersFunc: func() pkgpeermesh.Peers { return peers },
+		probePeersFunc: func(_ context.Context, ps pkgpeermesh.Peers, _ ...latencytarget.OpOption) []pkgpeermesh.PeerStatus {
+			probed = append(probed, ps)
+			statuses := make([]pkgpeermesh.PeerStatus, 0, len(ps))
+			for _, p := range ps {
+				statuses = append(statuses, healthyPeer(p.ID, ""))
+			}
+			return statuses
+		},
+	}
+	defer c.Close()
+
+	cr := c.CheckDownscoped()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Contains(t, cr.Summary(), "probed 8 peer(s)")
+
+	// rotates through all the peers
+	_ = c.CheckDownscoped()
+	require.Len(t, probed, 2)
+	assert.Equal(t, peers[:8], probed[0])
+	assert.Equal(t, pkgpeermesh.Peers{peers[8], peers[9], peers[0], peers[1], peers[2], peers[3], peers[4], peers[5]}, probed[1])
+
+	// the full check probes all the peers
+	_ = c.Check()
+	assert.Equal(t, peers, probed[2])
+}
+
+func TestCheckDeletesStaleMetrics(t *testing.T) {
+	peers := pkgpeermesh.Peers{
+		{ID: "stale-node-1", Address: "10.0.0.1:15132", Rack: "rack-1"},
+		{ID: "stale-node-2", Address: "10.0.0.2:15132", Rack: "rack-1"},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &component{
+		ctx:          ctx,
+		cancel:       cancel,
+		getPeersFunc: func() pkgpeermesh.Peers { return peers },
+		probePeersFunc: func(_ context.Context, ps pkgpeermesh.Peers, _ ...latencytarget.OpOption) []pkgpeermesh.PeerStatus {
+			statuses := make([]pkgpeermesh.PeerStatus, 0, len(ps))
+			for _, p := range ps {
+				statuses = append(statuses, healthyPeer(p.ID, p.Rack))
+			}
+			return statuses
+		},
+	}
+	defer c.Close()
+
+	_ = c.Check()
+	assert.Len(t, c.metricLabels, 2)
+
+	// node-2 is removed, node-1 moves to another rack
+	peers = pkgpeermesh.Peers{{ID: "stale-node-1", Address: "10.0.0.1:15132", Rack: "rack-2"}}
+	_ = c.Check()
+	assert.Len(t, c.metricLabels, 1)
+	assert.Contains(t, c.metricLabels, "rack-2/stale-node-1")
+
+	// already deleted by the check
+	assert.False(t, metricLatencyInMilliseconds.Delete(prometheus.Labels{"peer": "stale-node-2", "rack": "rack-1"}))
+	assert.False(t, metricPacketLossPercent.Delete(prometheus.Labels{"peer": "stale-node-1", "rack": "rack-1"}))
+	assert.True(t, metricLatencyInMilliseconds.Delete(prometheus.Labels{"peer": "stale-node-1", "rack": "rack-2"}))
+
+	// no peer configured
+	peers = nil
+	_ = c.Check()
+	assert.Empty(t, c.metricLabels)
+	assert.False(t, metricPacketLossPercent.Delete(prometheus.Labels{"peer": "stale-node-1", "rack": "rack-2"}))
+}
+
+func TestRotatePeers(t *testing.T) {
+	peers := pkgpeermesh.Peers{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+
+	rotated, next := rotatePeers(peers, 0, 3)
+	assert.Equal(t, peers, rotated)
+	assert.Equal(t, 0, next)
+
+	rotated, next = rotatePeers(peers, 2, 2)
+	assert.Equal(t, pkgpeermesh.Peers{{ID: "c"}, {ID: "a"}}, rotated)
+	assert.Equal(t, 1, next)
+
+	// the peers may have been removed since the last rotation
+	rotated, next = rotatePeers(peers, 7, 1)
+	assert.Equal(t, pkgpeermesh.Peers{{ID: "b"}}, rotated)
+	assert.Equal(t, 2, next)
+}
+
+func TestCheckResultNil(t *testing.T) {
+	var cr *checkResult
+	assert.Equal(t, "", cr.String())
+	assert.Equal(t, "", cr.Summary())
+	assert.Equal(t, apiv1.HealthStateType(""), cr.HealthStateType())
+}
@@ -0,0 +1,31 @@
+package peermesh
+
+import (
+	"sync"
+
+	"github.com/leptonai/gpud/pkg/log"
+	pkgpeermesh "github.com/leptonai/gpud/pkg/peer-mesh"
+)
+
+var (
+	defaultPeersMu sync.RWMutex
+	defaultPeers   = make(pkgpeermesh.Peers, 0)
+)
+
+// GetDefaultPeers returns the peer nodes to probe.
+func GetDefaultPeers() pkgpeermesh.Peers {
+	defaultPeersMu.RLock()
+	defer defaultPeersMu.RUnlock()
+
+	return defaultPeers
+}
+
+// SetDefaultPeers sets the peer nodes to probe, either from the static config
+// or pulled from the control plane.
+func SetDefaultPeers(peers pkgpeermesh.Peers) {
+	log.Logger.Infow("setting default peer mesh peers", "count", len(peers))
+
+	defaultPeersMu.Lock()
+	defer defaultPeersMu.Unlock()
+	defaultPeers = peers
+}
@@ -0,0 +1,42 @@
+package peermesh
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+const SubSystem = "network_peer_mesh"
+
+var (
+	componentLabel = prometheus.Labels{
+		pkgmetrics.MetricComponentLabelKey: Name,
+	}
+
+	metricLatencyInMilliseconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "latency_in_milliseconds",
+			Help:      "tracks the average latency to the peer node in milliseconds",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "peer", "rack"}, // label is peer ID and rack
+	).MustCurryWith(componentLabel)
+
+	metricPacketLossPercent = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "packet_loss_percent",
+			Help:      "tracks the percentage of the lost probes to the peer node",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "peer", "rack"}, // label is peer ID and rack
+	).MustCurryWith(componentLabel)
+)
+
+func init() {
+	pkgmetrics.MustRegister(
+		metricLatencyInMilliseconds,
+		metricPacketLossPercent,
+	)
+}
@@ -27,6 +27,8 @@ type component struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	checkInterval time.Duration
+
 	machineID string
 
 	getGroupConfigsFunc func() pkgnfschecker.Configs
@@ -41,6 +43,8 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		ctx:    cctx,
 		cancel: ccancel,
 
+		checkInterval: gpudInstance.CheckInterval(Name),
+
 		machineID:           gpudInstance.MachineID,
 		getGroupConfigsFunc: GetDefaultConfigs,
 	}
@@ -62,7 +66,7 @@ func (c *component) IsSupported() bool {
 
 func (c *component) Start() error {
 	go func() {
-		ticker := time.NewTicker(time.Minute)
+		ticker := components.NewCheckTicker(c.checkInterval)
 		defer ticker.Stop()
 
 		for {
@@ -72,7 +76,7 @@ func (c *component) Start() error {
 			case <-ticker.C:
 			}
 
-			_ = c.Check()
+			_ = components.SafeCheck(c)
 		}
 	}()
 	return nil
@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -16,37 +17,55 @@ import (
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/components/accelerator/nvidia/xid"
+	"github.com/leptonai/gpud/pkg/eventbus"
 	"github.com/leptonai/gpud/pkg/eventstore"
 	pkghost "github.com/leptonai/gpud/pkg/host"
 	"github.com/leptonai/gpud/pkg/log"
 	"github.com/leptonai/gpud/pkg/pci"
+	"github.com/leptonai/gpud/pkg/toolexec"
 )
 
 // Name is the name of the PCI ID component.
 const Name = "pci"
 
+const (
+	EventNameACSEnabled      = "acs_enabled"
+	EventNameGPUFallenOffBus = "gpu_fallen_off_bus"
+
+	// xidGPUFallenOffBus is the Xid for the GPU that has fallen off the bus.
+	// ref. https://docs.nvidia.com/deploy/xid-errors/index.html
+	xidGPUFallenOffBus = 79
+)
+
 var _ components.Component = &component{}
 
 type component struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	checkInterval time.Duration
+
 	currentVirtEnv                pkghost.VirtualizationEnvironment
 	getPCIDevicesFunc             func(ctx context.Context) (pci.Devices, error)
 	findACSEnabledDeviceUUIDsFunc func(devs []pci.Device) []string
 
 	eventBucket eventstore.Bucket
+	// xidSub receives the Xid events from the Xid component
+	xidSub *eventbus.Subscriber
 
 	lastMu          sync.RWMutex
 	lastCheckResult *checkResult
 }
 
 func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
-	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+	cctx, ccancel := context.WithCancel(toolexec.WithComponent(gpudInstance.RootCtx, Name))
 	c := &component{
 		ctx:    cctx,
 		cancel: ccancel,
 
+		checkInterval: gpudInstance.CheckInterval(Name),
+
 		currentVirtEnv:                pkghost.VirtualizationEnv(),
 		getPCIDevicesFunc:             pci.List,
 		findACSEnabledDeviceUUIDsFunc: findACSEnabledDeviceUUIDs,
@@ -61,6 +80,8 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		}
 	}
 
+	c.xidSub = gpudInstance.EventBus.Subscribe(eventbus.WithComponents(xid.Name))
+
 	return c, nil
 }
 
@@ -77,8 +98,10 @@ func (c *component) IsSupported() bool {
 }
 
 func (c *component) Start() error {
+	go c.consumeXidEvents()
+
 	go func() {
-		ticker := time.NewTicker(time.Minute)
+		ticker := components.NewCheckTicker(c.checkInterval)
 		defer ticker.Stop()
 
 		for {
@@ -96,18 +119,60 @@ func (c *component) Start() error {
 				}
 
 				nowUTC := time.Now().UTC()
-				if lastEvent != nil && nowUTC.Sub(lastEvent.Time) < 24*time.Hour {
+				if lastEvent != nil && lastEvent.Name == EventNameACSEnabled && nowUTC.Sub(lastEvent.Time) < 24*time.Hour {
 					log.Logger.Debugw("found events thus skipping to not overwrite latest data -- we only check once per day", "since", humanize.Time(nowUTC))
 					continue
 				}
 			}
 
-			_ = c.Check()
+			_ = components.SafeCheck(c)
 		}
 	}()
 	return nil
 }
 
+// consumeXidEvents records the GPUs that have fallen off the bus as reported
+// by the Xid component, and re-lists the PCI devices right away.
+func (c *component) consumeXidEvents() {
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case ev := <-c.xidSub.C():
+			c.processXidEvent(ev)
+		}
+	}
+}
+
+func (c *component) processXidEvent(ev eventstore.Event) {
+	id, err := strconv.Atoi(ev.ExtraInfo[xid.EventKeyErrorXidData])
+	if err != nil || id != xidGPUFallenOffBus {
+		return
+	}
+
+	deviceUUID := ev.ExtraInfo[xid.EventKeyDeviceUUID]
+	log.Logger.Warnw("gpu has fallen off the bus", "xid", id, "deviceUUID", deviceUUID)
+
+	if c.eventBucket != nil {
+		cctx, cancel := context.WithTimeout(c.ctx, 15*time.Second)
+		err = c.eventBucket.Insert(cctx, eventstore.Event{
+			Time:    ev.Time,
+			Name:    EventNameGPUFallenOffBus,
+			Type:    string(apiv1.EventTypeCritical),
+			Message: fmt.Sprintf("GPU %s has fallen off the bus (Xid %d)", deviceUUID, id),
+			ExtraInfo: map[string]string{
+				xid.EventKeyDeviceUUID: deviceUUID,
+			},
+		})
+		cancel()
+		if err != nil {
+			log.Logger.Errorw("error creating event", "error", err)
+		}
+	}
+
+	_ = components.SafeCheck(c)
+}
+
 func (c *component) LastHealthStates() apiv1.HealthStates {
 	c.lastMu.RLock()
 	lastCheckResult := c.lastCheckResult
@@ -201,7 +266,7 @@ func (c *component) Check() components.CheckResult {
 		cctx, cancel = context.WithTimeout(c.ctx, 15*time.Second)
 		cr.err = c.eventBucket.Insert(cctx, eventstore.Event{
 			Time:    time.Now().UTC(),
-			Name:    "acs_enabled",
+			Name:    EventNameACSEnabled,
 			Type:    string(apiv1.EventTypeWarning),
 			Message: fmt.Sprintf("host virt env is %q, ACS is enabled on the following PCI devices: %s (needs to be disabled)", c.currentVirtEnv.Type, strings.Join(acsEnabledDevices, ", ")),
 		})
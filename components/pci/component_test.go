@@ -16,6 +16,8 @@ import (
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/components/accelerator/nvidia/xid"
+	"github.com/leptonai/gpud/pkg/eventbus"
 	"github.com/leptonai/gpud/pkg/eventstore"
 	"github.com/leptonai/gpud/pkg/host"
 	"github.com/leptonai/gpud/pkg/pci"
@@ -1630,3 +1632,60 @@ func TestEventsWithMock(t *testing.T) {
 	assert.Equal(t, mockErr, err)
 	assert.Nil(t, events)
 }
+
+func TestConsumeXidEvents(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	bus := eventbus.New()
+	comp, err := New(&components.GPUdInstance{
+		RootCtx:    ctx,
+		EventStore: store,
+		EventBus:   bus,
+	})
+	require.NoError(t, err)
+	defer comp.Close()
+
+	c := comp.(*component)
+	c.currentVirtEnv = host.VirtualizationEnvironment{Type: "baremetal"}
+	c.getPCIDevicesFunc = func(ctx context.Context) (pci.Devices, error) {
+		return nil, nil
+	}
+	require.NoError(t, comp.Start())
+
+	// other xids and components are ignored
+	assert.Equal(t, 1, bus.Publish(eventstore.Event{
+		Component: xid.Name,
+		Time:      time.Now().UTC(),
+		Name:      xid.EventNameErrorXid,
+		ExtraInfo: map[string]string{xid.EventKeyErrorXidData: "31", xid.EventKeyDeviceUUID: "GPU-0"},
+	}))
+	assert.Equal(t, 0, bus.Publish(eventstore.Event{Component: "other"}))
+
+	assert.Equal(t, 1, bus.Publish(eventstore.Event{
+		Component: xid.Name,
+		Time:      time.Now().UTC(),
+		Name:      xid.EventNameErrorXid,
+		ExtraInfo: map[string]string{xid.EventKeyErrorXidData: "79", xid.EventKeyDeviceUUID: "GPU-1"},
+	}))
+
+	var evs apiv1.Events
+	require.Eventually(t, func() bool {
+		evs, err = comp.Events(ctx, time.Now().Add(-time.Hour))
+		return err == nil && len(evs) > 0
+	}, 5*time.Second, 50*time.Millisecond)
+	require.Len(t, evs, 1)
+	assert.Equal(t, EventNameGPUFallenOffBus, evs[0].Name)
+	assert.Equal(t, apiv1.EventTypeCritical, evs[0].Type)
+	assert.Contains(t, evs[0].Message, "GPU-1")
+
+	require.Eventually(t, func() bool {
+		return comp.LastHealthStates()[0].Reason != "no data yet"
+	}, 5*time.Second, 50*time.Millisecond)
+}
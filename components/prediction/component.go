@@ -0,0 +1,322 @@
+// Package prediction provides a component that surfaces the predicted failure risks
+// per node and per GPU, by feeding the recent window of the events and the metrics
+// from all the other components to the configured prediction model.
+package prediction
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	pkgprediction "github.com/leptonai/gpud/pkg/prediction"
+	"github.com/leptonai/gpud/pkg/toolexec"
+)
+
+// Name is the name of the prediction component.
+const Name = "failure-prediction"
+
+const (
+	// DefaultWindow is the default window of the events and the metrics fed to the model.
+	DefaultWindow = time.Hour
+
+	// DefaultRiskThreshold is the default risk at or above which the target is marked as degraded.
+	DefaultRiskThreshold = 0.7
+)
+
+var _ components.Component = &component{}
+
+type component struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	checkInterval time.Duration
+
+	model         pkgprediction.Model
+	registry      components.Registry
+	metricsStore  pkgmetrics.Store
+	window        time.Duration
+	riskThreshold float64
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+// New returns the init function of the prediction component,
+// reading the events from the components in the registry
+// and the metrics from the metrics store.
+func New(model pkgprediction.Model, registry components.Registry, metricsStore pkgmetrics.Store) components.InitFunc {
+	return func(gpudInstance *components.GPUdInstance) (components.Component, error) {
+		cctx, ccancel := context.WithCancel(toolexec.WithComponent(gpudInstance.RootCtx, Name))
+		c := &component{
+			ctx:    cctx,
+			cancel: ccancel,
+
+			checkInterval: gpudInstance.CheckInterval(Name),
+
+			model:         model,
+			registry:      registry,
+			metricsStore:  metricsStore,
+			window:        DefaultWindow,
+			riskThreshold: DefaultRiskThreshold,
+		}
+		return c, nil
+	}
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		Name,
+	}
+}
+
+func (c *component) IsSupported() bool {
+	return true
+}
+
+func (c *component) Start() error {
+	go func() {
+		ticker := components.NewCheckTicker(c.checkInterval)
+		defer ticker.Stop()
+
+		for {
+			_ = components.SafeCheck(c)
+
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	return nil, nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("predicting failure risks", "model", c.model.Name())
+
+	now := time.Now().UTC()
+	cr := &checkResult{
+		Model:         c.model.Name(),
+		riskThreshold: c.riskThreshold,
+		ts:            now,
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	w := c.readWindow(now)
+
+	ctx, cancel := context.WithTimeout(c.ctx, 30*time.Second)
+	cr.Predictions, cr.err = c.model.Predict(ctx, w)
+	cancel()
+	if cr.err != nil {
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = "error predicting failure risks"
+		log.Logger.Errorw(cr.reason, "model", c.model.Name(), "error", cr.err)
+		return cr
+	}
+
+	sort.Slice(cr.Predictions, func(i, j int) bool {
+		return cr.Predictions[i].Target < cr.Predictions[j].Target
+	})
+
+	cr.health = apiv1.HealthStateTypeHealthy
+	cr.reason = fmt.Sprintf("no target at risk (%d target(s) predicted)", len(cr.Predictions))
+	atRisk := 0
+	for _, p := range cr.Predictions {
+		if p.Risk >= c.riskThreshold {
+			atRisk++
+		}
+	}
+	if atRisk > 0 {
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = fmt.Sprintf("%d target(s) at risk of failure", atRisk)
+	}
+	return cr
+}
+
+// readWindow reads the events and the metrics in the window ending at the time,
+// skipping the components that fail to return the events.
+func (c *component) readWindow(end time.Time) pkgprediction.Window {
+	w := pkgprediction.Window{
+		Start: end.Add(-c.window),
+		End:   end,
+	}
+
+	if c.registry != nil {
+		for _, comp := range c.registry.All() {
+			if comp.Name() == Name {
+				continue
+			}
+			evs, err := comp.Events(c.ctx, w.Start)
+			if err != nil {
+				log.Logger.Warnw("failed to get events for prediction", "component", comp.Name(), "error", err)
+				continue
+			}
+			for _, ev := range evs {
+				if ev.Component == "" {
+					ev.Component = comp.Name()
+				}
+				w.Events = append(w.Events, ev)
+			}
+		}
+	}
+	sort.Slice(w.Events, func(i, j int) bool {
+		return w.Events[i].Time.Before(&w.Events[j].Time)
+	})
+
+	if c.metricsStore != nil {
+		ms, err := c.metricsStore.Read(c.ctx, pkgmetrics.WithSince(w.Start))
+		if err != nil {
+			log.Logger.Warnw("failed to read metrics for prediction", "error", err)
+		} else {
+			w.Metrics = ms
+		}
+	}
+	return w
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	Model       string                     `json:"model"`
+	Predictions []pkgprediction.Prediction `json:"predictions"`
+
+	riskThreshold float64
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+
+	b, err := yaml.Marshal(cr)
+	if err != nil {
+		return fmt.Sprintf("error marshaling data: %v", err)
+	}
+	return string(b)
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+// HealthStates returns one health state per target (the node and the GPUs),
+// with the predicted risk in the extra info.
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	if cr.err != nil || len(cr.Predictions) == 0 {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(cr.ts),
+				Component: Name,
+				Name:      Name,
+				Reason:    cr.reason,
+				Error:     cr.getError(),
+				Health:    cr.health,
+			},
+		}
+	}
+
+	states := make(apiv1.HealthStates, 0, len(cr.Predictions))
+	for _, p := range cr.Predictions {
+		state := apiv1.HealthState{
+			Time:      metav1.NewTime(cr.ts),
+			Component: Name,
+			Name:      p.Target,
+			Health:    apiv1.HealthStateTypeHealthy,
+			Reason:    fmt.Sprintf("predicted failure risk %.2f", p.Risk),
+		}
+		if p.Risk >= cr.riskThreshold {
+			state.Health = apiv1.HealthStateTypeDegraded
+		}
+		if p.Reason != "" {
+			state.Reason += ": " + p.Reason
+		}
+
+		b, _ := json.Marshal(p)
+		state.ExtraInfo = map[string]string{
+			"model": cr.Model,
+			"risk":  strconv.FormatFloat(p.Risk, 'f', 4, 64),
+			"data":  string(b),
+		}
+		states = append(states, state)
+	}
+	return states
+}
@@ -0,0 +1,168 @@
+package prediction
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	pkgprediction "github.com/leptonai/gpud/pkg/prediction"
+)
+
+type mockModel struct {
+	preds []pkgprediction.Prediction
+	err   error
+
+	lastWindow pkgprediction.Window
+}
+
+func (m *mockModel) Name() string { return "mock" }
+
+func (m *mockModel) Predict(ctx context.Context, w pkgprediction.Window) ([]pkgprediction.Prediction, error) {
+	m.lastWindow = w
+	return m.preds, m.err
+}
+
+type mockComponent struct {
+	components.Component
+
+	name   string
+	events apiv1.Events
+	err    error
+}
+
+func (c *mockComponent) Name() string { return c.name }
+
+func (c *mockComponent) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	return c.events, c.err
+}
+
+func TestComponentName(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &component{
+		ctx:           ctx,
+		cancel:        cancel,
+		model:         &mockModel{},
+		window:        DefaultWindow,
+		riskThreshold: DefaultRiskThreshold,
+	}
+	defer c.Close()
+	assert.Equal(t, Name, c.Name())
+	assert.Equal(t, []string{Name}, c.Tags())
+	assert.True(t, c.IsSupported())
+}
+
+func TestLastHealthStatesNoData(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &component{
+		ctx:           ctx,
+		cancel:        cancel,
+		model:         &mockModel{},
+		window:        DefaultWindow,
+		riskThreshold: DefaultRiskThreshold,
+	}
+	defer c.Close()
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, states[0].Health)
+	assert.Equal(t, "no data yet", states[0].Reason)
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Now()
+	registry := components.NewRegistry(&components.GPUdInstance{})
+	_, err := registry.Register(func(*components.GPUdInstance) (components.Component, error) {
+		return &mockComponent{name: "b", events: apiv1.Events{{Time: metav1.NewTime(now), Name: "later"}}}, nil
+	})
+	require.NoError(t, err)
+	_, err = registry.Register(func(*components.GPUdInstance) (components.Component, error) {
+		return &mockComponent{name: "a", events: apiv1.Events{{Time: metav1.NewTime(now.Add(-time.Minute)), Name: "earlier"}}}, nil
+	})
+	require.NoError(t, err)
+	_, err = registry.Register(func(*components.GPUdInstance) (components.Component, error) {
+		return &mockComponent{name: "c", err: errors.New("failed")}, nil
+	})
+	require.NoError(t, err)
+
+	model := &mockModel{
+		preds: []pkgprediction.Prediction{
+			{Target: "GPU-a", Risk: 0.9, Reason: "xid storm"},
+			{Target: pkgprediction.TargetNode, Risk: 0.1},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &component{
+		ctx:           ctx,
+		cancel:        cancel,
+		model:         model,
+		registry:      registry,
+		window:        DefaultWindow,
+		riskThreshold: DefaultRiskThreshold,
+	}
+	defer c.Close()
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+	assert.Equal(t, "1 target(s) at risk of failure", cr.Summary())
+
+	require.Len(t, model.lastWindow.Events, 2)
+	assert.Equal(t, "earlier", model.lastWindow.Events[0].Name)
+	assert.Equal(t, "a", model.lastWindow.Events[0].Component)
+	assert.Equal(t, "later", model.lastWindow.Events[1].Name)
+	assert.Equal(t, DefaultWindow, model.lastWindow.End.Sub(model.lastWindow.Start))
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 2)
+	assert.Equal(t, "GPU-a", states[0].Name)
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, states[0].Health)
+	assert.Equal(t, "predicted failure risk 0.90: xid storm", states[0].Reason)
+	assert.Equal(t, "0.9000", states[0].ExtraInfo["risk"])
+	assert.Equal(t, "mock", states[0].ExtraInfo["model"])
+	assert.Equal(t, pkgprediction.TargetNode, states[1].Name)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, states[1].Health)
+}
+
+func TestCheckModelError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &component{
+		ctx:           ctx,
+		cancel:        cancel,
+		model:         &mockModel{err: errors.New("endpoint down")},
+		window:        DefaultWindow,
+		riskThreshold: DefaultRiskThreshold,
+	}
+	defer c.Close()
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, "endpoint down", states[0].Error)
+}
+
+func TestCheckBaseline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &component{
+		ctx:           ctx,
+		cancel:        cancel,
+		model:         pkgprediction.NewBaselineModel(),
+		window:        DefaultWindow,
+		riskThreshold: DefaultRiskThreshold,
+	}
+	defer c.Close()
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, pkgprediction.TargetNode, states[0].Name)
+	assert.Equal(t, "0.0000", states[0].ExtraInfo["risk"])
+}
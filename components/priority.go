@@ -0,0 +1,111 @@
+package components
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+// Priority is the priority class of the component checks,
+// deciding whether the checks keep running while the node is under load.
+type Priority string
+
+const (
+	// PriorityNormal checks (e.g., XID, ECC) keep running regardless of the load.
+	PriorityNormal Priority = "normal"
+	// PriorityLow checks (e.g., the expensive network probes) are deferred
+	// while the node is under load, to minimize the interference with the workloads.
+	PriorityLow Priority = "low"
+)
+
+// PriorityOf returns the priority class of the component.
+func PriorityOf(c Component) Priority {
+	if p, ok := c.(Prioritized); ok && p.Priority() != "" {
+		return p.Priority()
+	}
+	return PriorityNormal
+}
+
+// DefaultMaxDeferral is the default longest time a low priority check is deferred for,
+// so that the check still runs on the node that is always busy.
+const DefaultMaxDeferral = 30 * time.Minute
+
+// Load is the utilization of the node.
+type Load struct {
+	// CPUPercent is the CPU utilization of the node in percent.
+	CPUPercent float64 `json:"cpu_percent"`
+	// GPUPercent is the highest utilization of the GPUs in percent,
+	// zero if the node has no GPU.
+	GPUPercent float64 `json:"gpu_percent"`
+}
+
+// LoadPolicy defers the low priority checks while the node is under load.
+type LoadPolicy struct {
+	// CPUThresholdPercent is the CPU utilization in percent, at and above which
+	// the node is under load. Zero to ignore the CPU utilization.
+	CPUThresholdPercent float64 `json:"cpu_threshold_percent,omitempty"`
+	// GPUThresholdPercent is the GPU utilization in percent, at and above which
+	// the node is under load. Zero to ignore the GPU utilization.
+	GPUThresholdPercent float64 `json:"gpu_threshold_percent,omitempty"`
+	// MaxDeferral is the longest time a low priority check is deferred for.
+	// Zero to use the default.
+	MaxDeferral metav1.Duration `json:"max_deferral,omitempty"`
+}
+
+func (p LoadPolicy) Validate() error {
+	if p.CPUThresholdPercent < 0 || p.CPUThresholdPercent > 100 {
+		return fmt.Errorf("cpu threshold percent must be between 0 and 100, got %v", p.CPUThresholdPercent)
+	}
+	if p.GPUThresholdPercent < 0 || p.GPUThresholdPercent > 100 {
+		return fmt.Errorf("gpu threshold percent must be between 0 and 100, got %v", p.GPUThresholdPercent)
+	}
+	if p.MaxDeferral.Duration < 0 {
+		return fmt.Errorf("max deferral must be non-negative, got %v", p.MaxDeferral)
+	}
+	return nil
+}
+
+// UnderLoad returns true if the load is at or above any of the thresholds.
+func (p LoadPolicy) UnderLoad(l Load) bool {
+	if p.CPUThresholdPercent > 0 && l.CPUPercent >= p.CPUThresholdPercent {
+		return true
+	}
+	return p.GPUThresholdPercent > 0 && l.GPUPercent >= p.GPUThresholdPercent
+}
+
+func (p LoadPolicy) maxDeferral() time.Duration {
+	if p.MaxDeferral.Duration > 0 {
+		return p.MaxDeferral.Duration
+	}
+	return DefaultMaxDeferral
+}
+
+var _ CheckResult = &deferredCheckResult{}
+
+// deferredCheckResult is the result of the check deferred under load,
+// with the health states of the last check run.
+type deferredCheckResult struct {
+	name   string
+	load   Load
+	states apiv1.HealthStates
+}
+
+func (cr *deferredCheckResult) ComponentName() string { return cr.name }
+
+func (cr *deferredCheckResult) String() string { return cr.Summary() }
+
+func (cr *deferredCheckResult) Summary() string {
+	return fmt.Sprintf("check deferred under load (cpu %.0f%%, gpu %.0f%%)", cr.load.CPUPercent, cr.load.GPUPercent)
+}
+
+func (cr *deferredCheckResult) HealthStateType() apiv1.HealthStateType {
+	if len(cr.states) == 0 {
+		return apiv1.HealthStateTypeHealthy
+	}
+	return cr.states[0].Health
+}
+
+func (cr *deferredCheckResult) HealthStates() apiv1.HealthStates { return cr.states }
@@ -0,0 +1,130 @@
+package components
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+type lowPriorityComponent struct {
+	panickingComponent
+}
+
+func (c *lowPriorityComponent) Priority() Priority { return PriorityLow }
+
+func TestLoadPolicy(t *testing.T) {
+	p := LoadPolicy{CPUThresholdPercent: 80, GPUThresholdPercent: 90}
+	require.NoError(t, p.Validate())
+	assert.False(t, p.UnderLoad(Load{CPUPercent: 79, GPUPercent: 89}))
+	assert.True(t, p.UnderLoad(Load{CPUPercent: 80}))
+	assert.True(t, p.UnderLoad(Load{GPUPercent: 95}))
+	assert.Equal(t, DefaultMaxDeferral, p.maxDeferral())
+
+	// zero thresholds are ignored
+	assert.False(t, LoadPolicy{}.UnderLoad(Load{CPUPercent: 100, GPUPercent: 100}))
+
+	assert.Error(t, LoadPolicy{CPUThresholdPercent: 101}.Validate())
+	assert.Error(t, LoadPolicy{GPUThresholdPercent: -1}.Validate())
+	assert.Error(t, LoadPolicy{MaxDeferral: metav1.Duration{Duration: -time.Second}}.Validate())
+}
+
+func TestPriorityOf(t *testing.T) {
+	assert.Equal(t, PriorityNormal, PriorityOf(&mockComponent{name: "test"}))
+	assert.Equal(t, PriorityLow, PriorityOf(&lowPriorityComponent{}))
+
+	c, err := WrapInitFunc(func(*GPUdInstance) (Component, error) { return &lowPriorityComponent{}, nil }, NewCheckGuard(0))(nil)
+	require.NoError(t, err)
+	assert.Equal(t, PriorityLow, PriorityOf(c))
+}
+
+func TestCheckGuardDefersUnderLoad(t *testing.T) {
+	guard := NewCheckGuard(0)
+
+	load := Load{CPUPercent: 95}
+	guard.SetLoadPolicy(LoadPolicy{CPUThresholdPercent: 80, MaxDeferral: metav1.Duration{Duration: time.Hour}}, func() Load { return load })
+
+	low := &lowPriorityComponent{panickingComponent{mockComponent: mockComponent{name: "low"}}}
+	normal := &panickingComponent{mockComponent: mockComponent{name: "normal"}}
+
+	// the first check always runs
+	_ = guard.Check(low)
+	assert.Equal(t, 1, low.checks)
+
+	rs := guard.Check(low)
+	assert.Equal(t, 1, low.checks)
+	assert.Contains(t, rs.Summary(), "deferred under load")
+	assert.Equal(t, "low", rs.ComponentName())
+
+	// the normal priority checks keep running
+	_ = guard.Check(normal)
+	_ = guard.Check(normal)
+	assert.Equal(t, 2, normal.checks)
+
+	load = Load{CPUPercent: 10}
+	_ = guard.Check(low)
+	assert.Equal(t, 2, low.checks)
+
+	// runs after the max deferral even under load
+	load = Load{CPUPercent: 95}
+	deferred, ok := guard.deferCheck("low", time.Now().Add(30*time.Minute), true)
+	assert.True(t, ok)
+	assert.Equal(t, load, deferred)
+	_, ok = guard.deferCheck("low", time.Now().Add(2*time.Hour), true)
+	assert.False(t, ok)
+}
+
+type downscopedComponent struct {
+	lowPriorityComponent
+	downscopedChecks int
+}
+
+func (c *downscopedComponent) CheckDownscoped() CheckResult {
+	c.downscopedChecks++
+	return &mockCheckResult{}
+}
+
+func TestCheckGuardDownscopesUnderLoad(t *testing.T) {
+	guard := NewCheckGuard(0)
+	guard.SetLoadPolicy(LoadPolicy{CPUThresholdPercent: 80}, func() Load { return Load{CPUPercent: 95} })
+
+	c := &downscopedComponent{lowPriorityComponent: lowPriorityComponent{panickingComponent{mockComponent: mockComponent{name: "low"}}}}
+
+	_ = guard.Check(c)
+	assert.Equal(t, 1, c.checks)
+
+	// runs the downscoped check in place of deferring
+	rs := guard.Check(c)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, rs.HealthStateType())
+	assert.Equal(t, 1, c.checks)
+	assert.Equal(t, 1, c.downscopedChecks)
+}
+
+func TestCheckGuardCheckNowUnderLoad(t *testing.T) {
+	guard := NewCheckGuard(0)
+	guard.SetLoadPolicy(LoadPolicy{CPUThresholdPercent: 80}, func() Load { return Load{CPUPercent: 95} })
+
+	low := &lowPriorityComponent{panickingComponent{mockComponent: mockComponent{name: "low"}}}
+	wrapped, err := WrapInitFunc(func(*GPUdInstance) (Component, error) { return low, nil }, guard)(nil)
+	require.NoError(t, err)
+
+	_ = guard.Check(low)
+	assert.Equal(t, 1, low.checks)
+
+	// the explicitly requested checks are never deferred
+	rs := wrapped.Check()
+	assert.NotContains(t, rs.Summary(), "deferred")
+	assert.Equal(t, 2, low.checks)
+	rs = guard.CheckNow(low)
+	assert.NotContains(t, rs.Summary(), "deferred")
+	assert.Equal(t, 3, low.checks)
+
+	// the periodic checks are still deferred
+	rs = guard.Check(low)
+	assert.Contains(t, rs.Summary(), "deferred under load")
+	assert.Equal(t, 3, low.checks)
+}
@@ -0,0 +1,19 @@
+package components
+
+import (
+	"fmt"
+	"os"
+)
+
+// RunningAsRoot returns true if gpud runs as root.
+// In the non-root mode, the components that require root
+// (e.g., reading the kernel messages) self-disable.
+func RunningAsRoot() bool {
+	return os.Geteuid() == 0
+}
+
+// RequiresRootReason returns the health state reason of the component
+// self-disabled in the non-root mode, for the feature that requires root.
+func RequiresRootReason(feature string) string {
+	return fmt.Sprintf("requires root to %s (disabled in the non-root mode)", feature)
+}
@@ -7,8 +7,10 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+	"time"
 
 	nvidiacommon "github.com/leptonai/gpud/pkg/config/common"
+	"github.com/leptonai/gpud/pkg/eventbus"
 	"github.com/leptonai/gpud/pkg/eventstore"
 	pkghost "github.com/leptonai/gpud/pkg/host"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
@@ -30,16 +32,48 @@ type GPUdInstance struct {
 
 	KernelModulesToCheck []string
 
-	NVMLInstance         nvidianvml.Instance
+	NVMLInstance nvidianvml.Instance
+	// NVIDIAToolOverwrites replaces the NVIDIA tools with the mocked commands,
+	// leave empty to run the tools located via "pkg/toolpath".
 	NVIDIAToolOverwrites nvidiacommon.ToolOverwrites
 
+	// ProcessesCache is the latest per-GPU processes, updated by the
+	// processes component and read by the others (e.g., on Xid errors).
+	// Nil disables the process lookups.
+	ProcessesCache *nvidianvml.ProcessesCache
+
 	DBRO *sql.DB
+	// DBRW is the read-write database for the components persisting
+	// their state across the restarts (e.g., the PCI baseline of the GPUs).
+	DBRW *sql.DB
 
 	EventStore       eventstore.Store
 	RebootEventStore pkghost.RebootEventStore
 
+	// TriggerBus notifies the components to re-check immediately
+	// on the relevant events matched by the other components.
+	// Nil disables the event-driven checks.
+	TriggerBus *TriggerBus
+
+	// EventBus delivers the events found by the components
+	// to the other components subscribed to them.
+	// Nil disables the cross-component event consumers.
+	EventBus *eventbus.Bus
+
 	MountPoints  []string
 	MountTargets []string
+
+	// CheckIntervals overrides the intervals of the periodic checks per component.
+	// Nil runs all the components at the DefaultCheckInterval.
+	CheckIntervals CheckIntervals
+}
+
+// CheckInterval returns the interval of the periodic checks of the component.
+func (g *GPUdInstance) CheckInterval(componentName string) time.Duration {
+	if g == nil {
+		return DefaultCheckInterval
+	}
+	return g.CheckIntervals.Get(componentName)
 }
 
 // InitFunc is the function that initializes a component.
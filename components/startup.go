@@ -0,0 +1,236 @@
+package components
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// DependencyNVML is the dependency on the validated NVML instance,
+	// for the components that query the GPUs via NVML.
+	DependencyNVML = "@nvml"
+	// DependencyEventStore is the dependency on the event store,
+	// for the components that evaluate the events of the other components.
+	DependencyEventStore = "@event-store"
+
+	// DefaultStartupStageTimeout is the default timeout for each startup stage
+	// to validate its resources and start its components.
+	DefaultStartupStageTimeout = 2 * time.Minute
+)
+
+var (
+	// ErrDependencyCycle is the error returned when the component dependencies have a cycle.
+	ErrDependencyCycle = errors.New("component dependency cycle")
+)
+
+// Dependencies maps the component name to the names of the components
+// or the resources (e.g., DependencyNVML) that must be ready before the component starts.
+// The dependencies that are neither registered nor provided are ignored
+// (e.g., a disabled component).
+type Dependencies map[string][]string
+
+// ReadyFunc validates that the resource is ready for its dependents.
+type ReadyFunc func(ctx context.Context) error
+
+// StartupStages returns the names of the components and the resources
+// grouped into the stages in the topological order, where each stage
+// only depends on the previous stages.
+// The names in each stage are sorted, for deterministic startup logs.
+// Returns ErrDependencyCycle if the dependencies have a cycle.
+func StartupStages(names []string, deps Dependencies) ([][]string, error) {
+	nodes := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		nodes[name] = struct{}{}
+	}
+
+	inDegrees := make(map[string]int, len(nodes))
+	dependents := make(map[string][]string)
+	for name := range nodes {
+		inDegrees[name] += 0
+		for _, dep := range deps[name] {
+			if _, ok := nodes[dep]; !ok || dep == name {
+				continue
+			}
+			inDegrees[name]++
+			dependents[dep] = append(dependents[dep], name)
+		}
+	}
+
+	var stages [][]string
+	for len(inDegrees) > 0 {
+		var stage []string
+		for name, d := range inDegrees {
+			if d == 0 {
+				stage = append(stage, name)
+			}
+		}
+		if len(stage) == 0 {
+			remaining := make([]string, 0, len(inDegrees))
+			for name := range inDegrees {
+				remaining = append(remaining, name)
+			}
+			sort.Strings(remaining)
+			return nil, fmt.Errorf("%w among %s", ErrDependencyCycle, strings.Join(remaining, ", "))
+		}
+		sort.Strings(stage)
+
+		for _, name := range stage {
+			delete(inDegrees, name)
+		}
+		for _, name := range stage {
+			for _, dependent := range dependents[name] {
+				inDegrees[dependent]--
+			}
+		}
+		stages = append(stages, stage)
+	}
+	return stages, nil
+}
+
+// StartInOrder starts the registered components stage by stage (see StartupStages),
+// after validating the resources each stage depends on.
+//
+// A resource that fails to validate is logged, and its dependents are
+// still started so that they can report the failure in their health states.
+// A stage that does not complete within the timeout is logged, and
+// the next stage is started while the slow components keep starting
+// in the background.
+// Returns the error if any component fails to start.
+func StartInOrder(ctx context.Context, registry Registry, deps Dependencies, resources map[string]ReadyFunc, stageTimeout time.Duration) error {
+	if stageTimeout <= 0 {
+		stageTimeout = DefaultStartupStageTimeout
+	}
+
+	comps := make(map[string]Component)
+	names := make([]string, 0, len(resources))
+	for _, c := range registry.All() {
+		comps[c.Name()] = c
+		names = append(names, c.Name())
+	}
+	for name := range resources {
+		names = append(names, name)
+	}
+
+	stages, err := StartupStages(names, deps)
+	if err != nil {
+		return err
+	}
+
+	for i, stage := range stages {
+		log.Logger.Infow("starting components", "stage", i, "names", stage)
+
+		cctx, ccancel := context.WithTimeout(ctx, stageTimeout)
+		errc := make(chan error, len(stage))
+		var wg sync.WaitGroup
+		for _, name := range stage {
+			wg.Add(1)
+			go func(name string) {
+				defer wg.Done()
+
+				if ready, ok := resources[name]; ok {
+					if err := ready(cctx); err != nil {
+						log.Logger.Errorw("failed to validate resource, starting its dependents anyway", "resource", name, "error", err)
+					}
+					return
+				}
+				if err := comps[name].Start(); err != nil {
+					errc <- fmt.Errorf("failed to start component %s: %w", name, err)
+				}
+			}(name)
+		}
+
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-cctx.Done():
+			log.Logger.Warnw("startup stage timed out, starting the next stage", "stage", i, "names", stage, "timeout", stageTimeout)
+		}
+		ccancel()
+
+		select {
+		case err := <-errc:
+			return err
+		default:
+		}
+	}
+	return nil
+}
+
+// CloseInOrder closes the registered components stage by stage
+// in the reverse order of StartInOrder, so that each component is closed
+// before the components it depends on.
+// The components in the same stage are closed concurrently.
+// A stage that does not complete within the timeout is logged, and
+// the previous stage is closed while the slow components keep closing
+// in the background.
+// Falls back to closing all the components at once if the dependencies have a cycle.
+// Returns the joined errors of the components that fail to close.
+func CloseInOrder(registry Registry, deps Dependencies, stageTimeout time.Duration) error {
+	if stageTimeout <= 0 {
+		stageTimeout = DefaultStartupStageTimeout
+	}
+
+	comps := make(map[string]Component)
+	names := make([]string, 0)
+	for _, c := range registry.All() {
+		comps[c.Name()] = c
+		names = append(names, c.Name())
+	}
+
+	stages, err := StartupStages(names, deps)
+	if err != nil {
+		log.Logger.Warnw("failed to order components, closing all at once", "error", err)
+		sort.Strings(names)
+		stages = [][]string{names}
+	}
+
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	for i := len(stages) - 1; i >= 0; i-- {
+		stage := stages[i]
+		log.Logger.Infow("closing components", "stage", i, "names", stage)
+
+		var wg sync.WaitGroup
+		for _, name := range stage {
+			wg.Add(1)
+			go func(name string) {
+				defer wg.Done()
+
+				if err := comps[name].Close(); err != nil {
+					log.Logger.Errorw("failed to close component", "component", name, "error", err)
+					mu.Lock()
+					errs = append(errs, fmt.Errorf("failed to close component %s: %w", name, err))
+					mu.Unlock()
+				}
+			}(name)
+		}
+
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(stageTimeout):
+			log.Logger.Warnw("closing stage timed out, closing the previous stage", "stage", i, "names", stage, "timeout", stageTimeout)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	return errors.Join(errs...)
+}
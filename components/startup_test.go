@@ -0,0 +1,142 @@
+package components
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartupStages(t *testing.T) {
+	stages, err := StartupStages(
+		[]string{"pci", "xid", "cpu", "power", DependencyNVML},
+		Dependencies{
+			"xid":   {DependencyNVML},
+			"power": {DependencyNVML},
+			"pci":   {"xid", "disabled"},
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{DependencyNVML, "cpu"},
+		{"power", "xid"},
+		{"pci"},
+	}, stages)
+
+	_, err = StartupStages([]string{"a", "b", "c"}, Dependencies{"a": {"b"}, "b": {"a"}})
+	assert.ErrorIs(t, err, ErrDependencyCycle)
+}
+
+type startRecorder struct {
+	mockComponent
+	mu    *sync.Mutex
+	order *[]string
+	err   error
+	block time.Duration
+}
+
+func (s *startRecorder) Start() error {
+	time.Sleep(s.block)
+	s.mu.Lock()
+	*s.order = append(*s.order, s.name)
+	s.mu.Unlock()
+	return s.err
+}
+
+func TestStartInOrder(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	newInit := func(name string, block time.Duration, err error) InitFunc {
+		return func(*GPUdInstance) (Component, error) {
+			return &startRecorder{mockComponent: mockComponent{name: name}, mu: &mu, order: &order, err: err, block: block}, nil
+		}
+	}
+
+	r := NewRegistry(nil)
+	r.MustRegister(newInit("pci", 0, nil))
+	r.MustRegister(newInit("xid", 50*time.Millisecond, nil))
+
+	resources := map[string]ReadyFunc{
+		DependencyNVML: func(ctx context.Context) error {
+			mu.Lock()
+			order = append(order, DependencyNVML)
+			mu.Unlock()
+			return errors.New("nvml not ready")
+		},
+	}
+	deps := Dependencies{"xid": {DependencyNVML}, "pci": {"xid"}}
+	require.NoError(t, StartInOrder(context.Background(), r, deps, resources, time.Second))
+	assert.Equal(t, []string{DependencyNVML, "xid", "pci"}, order)
+
+	// failed component start
+	r.MustRegister(newInit("bad", 0, errors.New("start failed")))
+	assert.ErrorContains(t, StartInOrder(context.Background(), r, nil, nil, time.Second), "bad")
+}
+
+func TestStartInOrderStageTimeout(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+
+	r := NewRegistry(nil)
+	r.MustRegister(func(*GPUdInstance) (Component, error) {
+		return &startRecorder{mockComponent: mockComponent{name: "slow"}, mu: &mu, order: &order, block: time.Second}, nil
+	})
+	r.MustRegister(func(*GPUdInstance) (Component, error) {
+		return &startRecorder{mockComponent: mockComponent{name: "next"}, mu: &mu, order: &order}, nil
+	})
+
+	require.NoError(t, StartInOrder(context.Background(), r, Dependencies{"next": {"slow"}}, nil, 50*time.Millisecond))
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"next"}, order)
+}
+
+type closeRecorder struct {
+	mockComponent
+	mu    *sync.Mutex
+	order *[]string
+	err   error
+}
+
+func (c *closeRecorder) Close() error {
+	c.mu.Lock()
+	*c.order = append(*c.order, c.name)
+	c.mu.Unlock()
+	return c.err
+}
+
+func TestCloseInOrder(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	newInit := func(name string, err error) InitFunc {
+		return func(*GPUdInstance) (Component, error) {
+			return &closeRecorder{mockComponent: mockComponent{name: name}, mu: &mu, order: &order, err: err}, nil
+		}
+	}
+
+	r := NewRegistry(nil)
+	r.MustRegister(newInit("pci", nil))
+	r.MustRegister(newInit("xid", nil))
+	r.MustRegister(newInit("nccl", nil))
+
+	// the dependents are closed first, the resources are ignored
+	deps := Dependencies{"xid": {DependencyNVML}, "pci": {"xid"}, "nccl": {"pci"}}
+	require.NoError(t, CloseInOrder(r, deps, time.Second))
+	assert.Equal(t, []string{"nccl", "pci", "xid"}, order)
+
+	// the cycle falls back to closing all
+	order = nil
+	require.NoError(t, CloseInOrder(r, Dependencies{"xid": {"pci"}, "pci": {"xid"}}, time.Second))
+	assert.ElementsMatch(t, []string{"nccl", "pci", "xid"}, order)
+
+	// failed component close does not stop closing the others
+	order = nil
+	r.MustRegister(newInit("bad", errors.New("close failed")))
+	err := CloseInOrder(r, deps, time.Second)
+	assert.ErrorContains(t, err, "bad")
+	assert.Len(t, order, 4)
+}
@@ -24,6 +24,8 @@ type component struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	checkInterval time.Duration
+
 	checkDependencyInstalled func() bool
 	checkServiceActiveFunc   func() (bool, error)
 
@@ -37,6 +39,8 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		ctx:    cctx,
 		cancel: ccancel,
 
+		checkInterval: gpudInstance.CheckInterval(Name),
+
 		checkDependencyInstalled: checkTailscaledInstalled,
 		checkServiceActiveFunc: func() (bool, error) {
 			return systemd.IsActive("tailscaled")
@@ -59,11 +63,11 @@ func (c *component) IsSupported() bool {
 
 func (c *component) Start() error {
 	go func() {
-		ticker := time.NewTicker(time.Minute)
+		ticker := components.NewCheckTicker(c.checkInterval)
 		defer ticker.Stop()
 
 		for {
-			_ = c.Check()
+			_ = components.SafeCheck(c)
 
 			select {
 			case <-c.ctx.Done():
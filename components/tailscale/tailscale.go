@@ -1,12 +1,12 @@
 package tailscale
 
 import (
-	pkg_file "github.com/leptonai/gpud/pkg/file"
 	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/toolpath"
 )
 
 func checkTailscaledInstalled() bool {
-	p, err := pkg_file.LocateExecutable("tailscaled")
+	p, err := toolpath.Locate("tailscaled")
 	if err == nil {
 		log.Logger.Debugw("tailscaled found in PATH", "path", p)
 		return true
@@ -0,0 +1,87 @@
+package components
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+// instrumentationName is the OpenTelemetry instrumentation scope of the component checks.
+// The spans and the metrics are no-op unless the OpenTelemetry providers are set
+// (e.g., "gpud run --otlp-endpoint").
+const instrumentationName = "github.com/leptonai/gpud/components"
+
+const (
+	// AttributeComponent is the span and metric attribute of the component name.
+	AttributeComponent = attribute.Key("gpud.component")
+	// AttributeHealth is the span and metric attribute of the check result health.
+	AttributeHealth = attribute.Key("gpud.health")
+)
+
+var checkDuration metric.Float64Histogram
+
+func init() {
+	var err error
+	checkDuration, err = otel.Meter(instrumentationName).Float64Histogram(
+		"gpud.component.check.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("wall-clock duration of the component checks"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+}
+
+// startCheckSpan starts the span of the component check.
+func startCheckSpan(name string) trace.Span {
+	_, span := otel.Tracer(instrumentationName).Start(
+		context.Background(),
+		"component.check",
+		trace.WithAttributes(AttributeComponent.String(name)),
+	)
+	return span
+}
+
+// endCheckSpan records the health and the errors of the check result
+// (or the panic) on the span and the check duration metric, and ends the span.
+func endCheckSpan(span trace.Span, name string, took time.Duration, rs CheckResult, panicErr error) {
+	defer span.End()
+
+	var health apiv1.HealthStateType
+	if rs != nil {
+		health = rs.HealthStateType()
+	}
+	attrs := []attribute.KeyValue{
+		AttributeComponent.String(name),
+		AttributeHealth.String(string(health)),
+	}
+	checkDuration.Record(context.Background(), took.Seconds(), metric.WithAttributes(attrs...))
+
+	span.SetAttributes(AttributeHealth.String(string(health)))
+	if panicErr != nil {
+		span.RecordError(panicErr)
+		span.SetStatus(codes.Error, panicErr.Error())
+		return
+	}
+	if rs == nil {
+		return
+	}
+
+	span.SetAttributes(attribute.String("gpud.summary", rs.Summary()))
+	for _, st := range rs.HealthStates() {
+		if st.Error != "" {
+			span.RecordError(errors.New(st.Error))
+		}
+	}
+	if health == apiv1.HealthStateTypeUnhealthy {
+		span.SetStatus(codes.Error, rs.Summary())
+	}
+}
@@ -0,0 +1,41 @@
+package components
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestCheckGuardSpans(t *testing.T) {
+	orig := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(orig) })
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	guard := NewCheckGuard(3)
+	guard.Check(&panickingComponent{mockComponent: mockComponent{name: "ok"}})
+	guard.Check(&panickingComponent{mockComponent: mockComponent{name: "boom"}, panic: true})
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "component.check", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), AttributeComponent.String("ok"))
+	assert.Contains(t, spans[0].Attributes(), AttributeHealth.String("Healthy"))
+	assert.Contains(t, spans[0].Attributes(), attribute.String("gpud.summary", "mock summary"))
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	assert.Contains(t, spans[1].Attributes(), AttributeComponent.String("boom"))
+	assert.Contains(t, spans[1].Attributes(), AttributeHealth.String("Unhealthy"))
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Contains(t, spans[1].Status().Description, "boom")
+	require.Len(t, spans[1].Events(), 1)
+	assert.Equal(t, "exception", spans[1].Events()[0].Name)
+}
@@ -0,0 +1,71 @@
+package components
+
+import (
+	"sync"
+)
+
+const (
+	// TriggerTopicNVIDIAXid is published when an NVIDIA Xid error is matched from the kmsg.
+	TriggerTopicNVIDIAXid = "nvidia-xid"
+	// TriggerTopicNVIDIASXid is published when an NVIDIA NVSwitch SXid error is matched from the kmsg.
+	TriggerTopicNVIDIASXid = "nvidia-sxid"
+)
+
+// TriggerBus notifies the subscribed components to re-check immediately
+// when a relevant event (e.g., an Xid in the kmsg) is matched by another component,
+// rather than waiting up to their next periodic check.
+//
+// All the methods are safe to call on the nil bus, in which case
+// the subscribers are never notified.
+type TriggerBus struct {
+	mu   sync.RWMutex
+	subs map[string][]chan struct{}
+}
+
+// NewTriggerBus creates a new trigger bus.
+func NewTriggerBus() *TriggerBus {
+	return &TriggerBus{
+		subs: make(map[string][]chan struct{}),
+	}
+}
+
+// Subscribe returns the channel that receives on each publish to any of the topics.
+// The channel is buffered by one, so that a burst of publishes while the
+// subscriber is busy (e.g., a flood of Xids) coalesces into a single re-check.
+//
+// Returns the nil channel if the bus is nil, which blocks forever in select.
+func (b *TriggerBus) Subscribe(topics ...string) <-chan struct{} {
+	if b == nil {
+		return nil
+	}
+
+	ch := make(chan struct{}, 1)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, topic := range topics {
+		b.subs[topic] = append(b.subs[topic], ch)
+	}
+	return ch
+}
+
+// Publish notifies the subscribers of the topic without blocking,
+// and returns the number of the subscribers.
+func (b *TriggerBus) Publish(topic string) int {
+	if b == nil {
+		return 0
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	subs := b.subs[topic]
+	for _, ch := range subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// re-check already pending
+		}
+	}
+	return len(subs)
+}
@@ -0,0 +1,33 @@
+package components
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTriggerBus(t *testing.T) {
+	bus := NewTriggerBus()
+
+	xidCh := bus.Subscribe(TriggerTopicNVIDIAXid)
+	bothCh := bus.Subscribe(TriggerTopicNVIDIAXid, TriggerTopicNVIDIASXid)
+
+	assert.Equal(t, 2, bus.Publish(TriggerTopicNVIDIAXid))
+	// coalesced into the pending notification
+	assert.Equal(t, 2, bus.Publish(TriggerTopicNVIDIAXid))
+	assert.Equal(t, 1, bus.Publish(TriggerTopicNVIDIASXid))
+	assert.Equal(t, 0, bus.Publish("unknown"))
+
+	assert.Len(t, xidCh, 1)
+	assert.Len(t, bothCh, 1)
+	<-xidCh
+	<-bothCh
+	assert.Len(t, xidCh, 0)
+	assert.Len(t, bothCh, 0)
+}
+
+func TestTriggerBusNil(t *testing.T) {
+	var bus *TriggerBus
+	assert.Nil(t, bus.Subscribe(TriggerTopicNVIDIAXid))
+	assert.Equal(t, 0, bus.Publish(TriggerTopicNVIDIAXid))
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
+	apiv2 "github.com/leptonai/gpud/api/v2"
 )
 
 // Component represents an individual component of the system.
@@ -80,6 +81,57 @@ type HealthSettable interface {
 	SetHealthy() error
 }
 
+// Versioned is an optional interface that can be implemented by components
+// to report the implementation version, bumped when the component-specific
+// format of its health states or events changes (e.g., the extra info keys).
+type Versioned interface {
+	// Version returns the implementation version of the component.
+	Version() string
+}
+
+// Prioritized is an optional interface that can be implemented by components
+// to set the priority class of their checks while the node is under load.
+// The components not implementing this interface are of the normal priority.
+type Prioritized interface {
+	// Priority returns the priority class of the component checks.
+	Priority() Priority
+}
+
+// Downscoper is an optional interface that can be implemented by the low priority components
+// to run a cheaper check (e.g., probing fewer targets) while the node is under load,
+// in place of deferring the check.
+type Downscoper interface {
+	// CheckDownscoped runs the downscoped check, and returns its result.
+	CheckDownscoped() CheckResult
+}
+
+// ToolRequirer is an optional interface that can be implemented by components
+// requiring their tools only on some hosts (e.g., the fabric manager with the NVSwitches),
+// so that the tools missing on the other hosts are not reported.
+// The components not implementing this interface always require their tools.
+type ToolRequirer interface {
+	// RequiresTools returns true if the checks on this host run the required tools.
+	RequiresTools() bool
+}
+
+// TypedPayloader is an optional interface that can be implemented by components
+// to report the typed component-specific data in the v2 API,
+// in place of the JSON-encoded strings in the v1 extra info.
+type TypedPayloader interface {
+	// LastHealthStatesWithPayload returns the health states and the typed payload
+	// of the same last check, or a nil payload if no check has been performed.
+	LastHealthStatesWithPayload() (apiv1.HealthStates, apiv2.Payload)
+}
+
+// LastHealthStatesWithPayload returns the last health states of the component,
+// with the typed payload of the same check if the component is a TypedPayloader.
+func LastHealthStatesWithPayload(c Component) (apiv1.HealthStates, apiv2.Payload) {
+	if p, ok := c.(TypedPayloader); ok {
+		return p.LastHealthStatesWithPayload()
+	}
+	return c.LastHealthStates(), nil
+}
+
 // CheckResult is the data type that represents the result of
 // a component health state check.
 type CheckResult interface {
@@ -0,0 +1,17 @@
+package components
+
+import (
+	"github.com/leptonai/gpud/version"
+)
+
+// VersionOf returns the implementation version of the component
+// (e.g., "2" for the XID component attaching the affected processes),
+// defaults to the gpud version if the component does not implement Versioned.
+func VersionOf(c Component) string {
+	if v, ok := c.(Versioned); ok {
+		if ver := v.Version(); ver != "" {
+			return ver
+		}
+	}
+	return version.Version
+}
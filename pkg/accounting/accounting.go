@@ -0,0 +1,378 @@
+// Package accounting attributes the GPU usage (GPU-seconds and energy)
+// to the cgroups and pods running on the GPUs over time, and persists
+// the periodic usage records to bill the internal tenants by.
+package accounting
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/cgroup"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/log"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+)
+
+const (
+	// BucketName is the name of the event store bucket for the usage records.
+	BucketName = "gpu-accounting"
+
+	// DefaultSampleInterval is the default interval to sample the GPU processes and power.
+	DefaultSampleInterval = 15 * time.Second
+	// DefaultWindow is the default accounting window of the usage records,
+	// aligned to the wall clock so that the records of the nodes line up.
+	DefaultWindow = 5 * time.Minute
+
+	eventNameGPUUsage = "gpu_usage"
+
+	extraInfoKeyGPUUUID      = "gpu_uuid"
+	extraInfoKeyPodUID       = "pod_uid"
+	extraInfoKeyContainerID  = "container_id"
+	extraInfoKeyCgroup       = "cgroup"
+	extraInfoKeyWindowStart  = "window_start"
+	extraInfoKeyGPUSeconds   = "gpu_seconds"
+	extraInfoKeyEnergyJoules = "energy_joules"
+)
+
+// GPUSample is the point-in-time usage of a GPU.
+type GPUSample struct {
+	UUID string
+	// PowerMilliWatts is the power usage of the GPU, zero if not supported.
+	PowerMilliWatts uint32
+	// PIDs are the processes running on the GPU.
+	PIDs []uint32
+}
+
+type usageKey struct {
+	gpuUUID string
+	cgroup.Process
+}
+
+type usage struct {
+	gpuSeconds   float64
+	energyJoules float64
+}
+
+// Accountant samples the GPUs periodically, attributes the elapsed GPU time
+// and energy to the cgroups of the processes running on each GPU, and persists
+// the accumulated usage per window.
+type Accountant struct {
+	bucket    eventstore.Bucket
+	window    time.Duration
+	retention time.Duration
+
+	sample  func(ctx context.Context) ([]GPUSample, error)
+	resolve func(pid int32) (cgroup.Process, error)
+
+	mu sync.Mutex
+	// maxGap caps the duration attributed by a single sample,
+	// not to over-attribute the usage across the gaps (e.g., suspended host)
+	maxGap      time.Duration
+	windowStart time.Time
+	lastSample  time.Time
+	usage       map[usageKey]*usage
+}
+
+// NewAccountant creates a new accountant that persists the usage records
+// per window in the bucket, purging the ones older than the retention.
+// Zero window uses the default, and zero retention disables the purge.
+func NewAccountant(nvmlInstance nvidianvml.Instance, bucket eventstore.Bucket, window time.Duration, retention time.Duration) *Accountant {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return &Accountant{
+		bucket:    bucket,
+		window:    window,
+		retention: retention,
+		sample: func(ctx context.Context) ([]GPUSample, error) {
+			return sampleGPUs(nvmlInstance)
+		},
+		resolve: func(pid int32) (cgroup.Process, error) {
+			return cgroup.ReadProcess(cgroup.DefaultProcRoot, pid)
+		},
+		maxGap: 2 * DefaultSampleInterval,
+		usage:  make(map[usageKey]*usage),
+	}
+}
+
+func sampleGPUs(nvmlInstance nvidianvml.Instance) ([]GPUSample, error) {
+	if nvmlInstance == nil || !nvmlInstance.NVMLExists() {
+		return nil, nil
+	}
+
+	var samples []GPUSample
+	for uuid, dev := range nvmlInstance.Devices() {
+		s := GPUSample{UUID: uuid}
+
+		power, ret := dev.GetPowerUsage()
+		if ret == nvml.SUCCESS {
+			s.PowerMilliWatts = power
+		} else if !nvidianvml.IsNotSupportError(ret) {
+			if nvidianvml.IsGPULostError(ret) {
+				return nil, nvidianvml.ErrGPULost
+			}
+			return nil, fmt.Errorf("failed to get device power usage: %v", nvml.ErrorString(ret))
+		}
+
+		procs, ret := dev.GetComputeRunningProcesses()
+		if ret != nvml.SUCCESS && !nvidianvml.IsNotSupportError(ret) {
+			if nvidianvml.IsGPULostError(ret) {
+				return nil, nvidianvml.ErrGPULost
+			}
+			return nil, fmt.Errorf("failed to get device compute processes: %v", nvml.ErrorString(ret))
+		}
+		for _, p := range procs {
+			s.PIDs = append(s.PIDs, p.Pid)
+		}
+
+		samples = append(samples, s)
+	}
+	return samples, nil
+}
+
+// Start samples the GPUs in the background until the context is canceled,
+// and persists the usage of the partial window on the cancellation.
+func (a *Accountant) Start(ctx context.Context, interval time.Duration) {
+	a.mu.Lock()
+	a.maxGap = 2 * interval
+	a.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				cctx, ccancel := context.WithTimeout(context.Background(), 10*time.Second)
+				if err := a.flush(cctx, time.Now().UTC()); err != nil {
+					log.Logger.Warnw("failed to flush gpu usage records", "error", err)
+				}
+				ccancel()
+				return
+			case <-ticker.C:
+			}
+
+			now := time.Now().UTC()
+			if err := a.observe(ctx, now); err != nil {
+				log.Logger.Warnw("failed to account gpu usage", "error", err)
+			}
+			if a.retention > 0 {
+				if _, err := a.bucket.Purge(ctx, now.Add(-a.retention).Unix()); err != nil {
+					log.Logger.Warnw("failed to purge gpu usage records", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// observe samples the GPUs, attributes the usage since the last sample,
+// and persists the accumulated usage once the window ends.
+func (a *Accountant) observe(ctx context.Context, now time.Time) error {
+	samples, err := a.sample(ctx)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.windowStart.IsZero() {
+		a.windowStart = now.Truncate(a.window)
+	}
+	if !a.lastSample.IsZero() {
+		elapsed := now.Sub(a.lastSample)
+		if elapsed > a.maxGap {
+			elapsed = a.maxGap
+		}
+		if elapsed > 0 {
+			a.attribute(samples, elapsed)
+		}
+	}
+	a.lastSample = now
+
+	if now.Sub(a.windowStart) < a.window {
+		return nil
+	}
+	return a.flushLocked(ctx, now)
+}
+
+// attribute splits the elapsed GPU time and energy of each GPU evenly
+// between the distinct cgroups of its processes.
+func (a *Accountant) attribute(samples []GPUSample, elapsed time.Duration) {
+	for _, s := range samples {
+		owners := make(map[cgroup.Process]struct{})
+		for _, pid := range s.PIDs {
+			p, err := a.resolve(int32(pid))
+			if err != nil {
+				// the process exited since sampled
+				continue
+			}
+			owners[p] = struct{}{}
+		}
+		if len(owners) == 0 {
+			continue
+		}
+
+		secs := elapsed.Seconds() / float64(len(owners))
+		joules := float64(s.PowerMilliWatts) / 1000 * elapsed.Seconds() / float64(len(owners))
+		for p := range owners {
+			k := usageKey{gpuUUID: s.UUID, Process: p}
+			u, ok := a.usage[k]
+			if !ok {
+				u = &usage{}
+				a.usage[k] = u
+			}
+			u.gpuSeconds += secs
+			u.energyJoules += joules
+		}
+	}
+}
+
+func (a *Accountant) flush(ctx context.Context, now time.Time) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.flushLocked(ctx, now)
+}
+
+// flushLocked persists the accumulated usage of the current window,
+// and starts the next window.
+func (a *Accountant) flushLocked(ctx context.Context, now time.Time) error {
+	start := a.windowStart
+	end := now.Truncate(a.window)
+	if !end.After(start) {
+		// partial window (e.g., on shutdown)
+		end = now
+	}
+
+	evs := make(eventstore.Events, 0, len(a.usage))
+	for k, u := range a.usage {
+		evs = append(evs, eventstore.Event{
+			Time: end,
+			Name: eventNameGPUUsage,
+			Type: string(apiv1.EventTypeInfo),
+			ExtraInfo: map[string]string{
+				extraInfoKeyGPUUUID:      k.gpuUUID,
+				extraInfoKeyPodUID:       k.PodUID,
+				extraInfoKeyContainerID:  k.ContainerID,
+				extraInfoKeyCgroup:       k.Path,
+				extraInfoKeyWindowStart:  start.Format(time.RFC3339),
+				extraInfoKeyGPUSeconds:   strconv.FormatFloat(u.gpuSeconds, 'f', 3, 64),
+				extraInfoKeyEnergyJoules: strconv.FormatFloat(u.energyJoules, 'f', 3, 64),
+			},
+		})
+	}
+	a.usage = make(map[usageKey]*usage)
+	a.windowStart = end
+
+	if len(evs) == 0 {
+		return nil
+	}
+	if bi, ok := a.bucket.(eventstore.BatchInserter); ok {
+		return bi.InsertBatch(ctx, evs)
+	}
+	for _, ev := range evs {
+		if err := a.bucket.Insert(ctx, ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Records returns the usage records of the windows ending in the time range (inclusive),
+// in the ascending order of the window end.
+func (a *Accountant) Records(ctx context.Context, from time.Time, to time.Time) ([]apiv1.GPUUsageRecord, error) {
+	if a == nil {
+		return nil, nil
+	}
+
+	// the event store query excludes the events at the time, in unix seconds
+	evs, err := a.bucket.Get(ctx, from.Add(-time.Second))
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]apiv1.GPUUsageRecord, 0, len(evs))
+	for _, ev := range evs {
+		if ev.Name != eventNameGPUUsage || ev.Time.Before(from) || ev.Time.After(to) {
+			continue
+		}
+		rec, err := parseRecord(ev)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		if !records[i].End.Equal(&records[j].End) {
+			return records[i].End.Before(&records[j].End)
+		}
+		if records[i].GPUUUID != records[j].GPUUUID {
+			return records[i].GPUUUID < records[j].GPUUUID
+		}
+		return records[i].Cgroup < records[j].Cgroup
+	})
+	return records, nil
+}
+
+func parseRecord(ev eventstore.Event) (apiv1.GPUUsageRecord, error) {
+	rec := apiv1.GPUUsageRecord{
+		End:         metav1.NewTime(ev.Time.UTC()),
+		GPUUUID:     ev.ExtraInfo[extraInfoKeyGPUUUID],
+		PodUID:      ev.ExtraInfo[extraInfoKeyPodUID],
+		ContainerID: ev.ExtraInfo[extraInfoKeyContainerID],
+		Cgroup:      ev.ExtraInfo[extraInfoKeyCgroup],
+	}
+
+	start, err := time.Parse(time.RFC3339, ev.ExtraInfo[extraInfoKeyWindowStart])
+	if err != nil {
+		return rec, fmt.Errorf("invalid window start: %w", err)
+	}
+	rec.Start = metav1.NewTime(start.UTC())
+
+	if rec.GPUSeconds, err = strconv.ParseFloat(ev.ExtraInfo[extraInfoKeyGPUSeconds], 64); err != nil {
+		return rec, fmt.Errorf("invalid gpu seconds: %w", err)
+	}
+	if rec.EnergyJoules, err = strconv.ParseFloat(ev.ExtraInfo[extraInfoKeyEnergyJoules], 64); err != nil {
+		return rec, fmt.Errorf("invalid energy joules: %w", err)
+	}
+	return rec, nil
+}
+
+// CSVHeader is the header of the usage records in CSV.
+var CSVHeader = []string{"start", "end", "gpu_uuid", "pod_uid", "container_id", "cgroup", "gpu_seconds", "energy_joules"}
+
+// WriteCSV writes the usage records in CSV, with the header.
+func WriteCSV(wr io.Writer, records []apiv1.GPUUsageRecord) error {
+	w := csv.NewWriter(wr)
+	if err := w.Write(CSVHeader); err != nil {
+		return err
+	}
+	for _, rec := range records {
+		if err := w.Write([]string{
+			rec.Start.UTC().Format(time.RFC3339),
+			rec.End.UTC().Format(time.RFC3339),
+			rec.GPUUUID,
+			rec.PodUID,
+			rec.ContainerID,
+			rec.Cgroup,
+			strconv.FormatFloat(rec.GPUSeconds, 'f', 3, 64),
+			strconv.FormatFloat(rec.EnergyJoules, 'f', 3, 64),
+		}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,133 @@
+package accounting
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/cgroup"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func newTestAccountant(t *testing.T, samples *[]GPUSample) *Accountant {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	t.Cleanup(cleanup)
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+	bucket, err := store.Bucket(BucketName, eventstore.WithDisablePurge())
+	require.NoError(t, err)
+	t.Cleanup(bucket.Close)
+
+	a := NewAccountant(nil, bucket, time.Minute, 0)
+	a.maxGap = time.Minute
+	a.sample = func(ctx context.Context) ([]GPUSample, error) {
+		return *samples, nil
+	}
+	a.resolve = func(pid int32) (cgroup.Process, error) {
+		switch pid {
+		case 100:
+			return cgroup.Process{Path: "/kubepods/poda", PodUID: "a"}, nil
+		case 101:
+			// same pod as 100
+			return cgroup.Process{Path: "/kubepods/poda", PodUID: "a"}, nil
+		case 200:
+			return cgroup.Process{Path: "/kubepods/podb", PodUID: "b"}, nil
+		}
+		return cgroup.Process{}, errors.New("not found")
+	}
+	return a
+}
+
+func TestAccountant(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	samples := []GPUSample{
+		{UUID: "GPU-0", PowerMilliWatts: 300000, PIDs: []uint32{100, 101}},
+		{UUID: "GPU-1", PowerMilliWatts: 200000, PIDs: []uint32{100, 200, 999}},
+		{UUID: "GPU-2", PowerMilliWatts: 50000},
+	}
+	a := newTestAccountant(t, &samples)
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, a.observe(ctx, base.Add(10*time.Second)))
+	require.NoError(t, a.observe(ctx, base.Add(20*time.Second)))
+	require.NoError(t, a.observe(ctx, base.Add(30*time.Second)))
+
+	// nothing persisted within the window
+	records, err := a.Records(ctx, base, base.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	// the window ends
+	require.NoError(t, a.observe(ctx, base.Add(60*time.Second)))
+
+	records, err = a.Records(ctx, base, base.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	// 50 seconds observed in the window
+	assert.Equal(t, "GPU-0", records[0].GPUUUID)
+	assert.Equal(t, "a", records[0].PodUID)
+	assert.InDelta(t, 50, records[0].GPUSeconds, 0.01)
+	assert.InDelta(t, 300*50, records[0].EnergyJoules, 0.01)
+	assert.True(t, records[0].Start.Time.Equal(base))
+	assert.True(t, records[0].End.Time.Equal(base.Add(time.Minute)))
+
+	// split evenly between the two pods
+	assert.Equal(t, "GPU-1", records[1].GPUUUID)
+	assert.Equal(t, "a", records[1].PodUID)
+	assert.InDelta(t, 25, records[1].GPUSeconds, 0.01)
+	assert.InDelta(t, 200*25, records[1].EnergyJoules, 0.01)
+	assert.Equal(t, "GPU-1", records[2].GPUUUID)
+	assert.Equal(t, "b", records[2].PodUID)
+	assert.InDelta(t, 25, records[2].GPUSeconds, 0.01)
+
+	// the gap is capped
+	require.NoError(t, a.observe(ctx, base.Add(10*time.Minute)))
+	records, err = a.Records(ctx, base.Add(2*time.Minute), base.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.InDelta(t, 60, records[0].GPUSeconds, 0.01)
+	assert.True(t, records[0].Start.Time.Equal(base.Add(time.Minute)))
+	assert.True(t, records[0].End.Time.Equal(base.Add(10*time.Minute)))
+
+	// partial window on shutdown
+	require.NoError(t, a.observe(ctx, base.Add(10*time.Minute+30*time.Second)))
+	require.NoError(t, a.flush(ctx, base.Add(10*time.Minute+30*time.Second)))
+	records, err = a.Records(ctx, base.Add(10*time.Minute+time.Second), base.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.InDelta(t, 30, records[0].GPUSeconds, 0.01)
+
+	var nilAccountant *Accountant
+	records, err = nilAccountant.Records(ctx, base, base)
+	require.NoError(t, err)
+	assert.Nil(t, records)
+}
+
+func TestWriteCSV(t *testing.T) {
+	samples := []GPUSample{{UUID: "GPU-0", PowerMilliWatts: 100000, PIDs: []uint32{200}}}
+	a := newTestAccountant(t, &samples)
+	ctx := context.Background()
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, a.observe(ctx, base))
+	require.NoError(t, a.observe(ctx, base.Add(time.Minute)))
+	records, err := a.Records(ctx, base, base.Add(time.Hour))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, records))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, strings.Join(CSVHeader, ","), lines[0])
+	assert.Equal(t, "2025-01-01T00:00:00Z,2025-01-01T00:01:00Z,GPU-0,b,,/kubepods/podb,60.000,6000.000", lines[1])
+}
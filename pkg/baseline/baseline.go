@@ -0,0 +1,378 @@
+// Package baseline learns the normal values of a new node (e.g., the GPU count,
+// the infiniband ports and rate, the nvlinks, the disk layout) during the learning
+// period after the node joins, and proposes them as the thresholds
+// the operator can accept at once, instead of hand-writing the expected states.
+// The learning is opt-in, disabled unless the learning period is set.
+package baseline
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	componentsfallenoffbus "github.com/leptonai/gpud/components/accelerator/nvidia/fallen-off-bus"
+	componentsnvlink "github.com/leptonai/gpud/components/accelerator/nvidia/nvlink"
+	componentsdisk "github.com/leptonai/gpud/components/disk"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	"github.com/leptonai/gpud/pkg/nvidia-query/infiniband"
+	pkgthresholds "github.com/leptonai/gpud/pkg/thresholds"
+)
+
+const (
+	// DefaultLearningPeriod is the recommended period after the join to learn the baseline.
+	DefaultLearningPeriod = 24 * time.Hour
+	// DefaultObserveInterval is the default interval to observe the node during the learning.
+	DefaultObserveInterval = 10 * time.Minute
+)
+
+var ErrNoObservation = errors.New("no observation to propose the baseline from")
+
+// Observation is the values observed on the node at a time.
+// Zero values are not observed (e.g., no infiniband port).
+type Observation struct {
+	// GPUs is the number of the GPUs.
+	GPUs int `json:"gpus"`
+	// NVLinkEnabledLinks is the fewest nvlinks with the feature enabled of any GPU.
+	NVLinkEnabledLinks int `json:"nvlink_enabled_links"`
+	// InfinibandPorts is the number of the infiniband ports, whether active or not,
+	// so that the ports down during the learning are not learned as the normal.
+	InfinibandPorts int `json:"infiniband_ports"`
+	// InfinibandRate is the lowest rate of the active infiniband ports in Gb/sec.
+	InfinibandRate int `json:"infiniband_rate"`
+	// MountPoints are the ext4/nfs mount points.
+	MountPoints []string `json:"mount_points,omitempty"`
+}
+
+// Proposal is the baseline proposed from the observations.
+type Proposal struct {
+	GPUs               int      `json:"gpus"`
+	NVLinkEnabledLinks int      `json:"nvlink_enabled_links"`
+	InfinibandPorts    int      `json:"infiniband_ports"`
+	InfinibandRate     int      `json:"infiniband_rate"`
+	MountPoints        []string `json:"mount_points,omitempty"`
+}
+
+// Thresholds converts the proposal into the thresholds on top of the current ones,
+// leaving the thresholds of the values not observed unchanged.
+func (p Proposal) Thresholds(current pkgthresholds.Thresholds) pkgthresholds.Thresholds {
+	var th pkgthresholds.Thresholds
+	if p.GPUs > 0 {
+		th.GPUs = &componentsfallenoffbus.Thresholds{ExpectedGPUs: p.GPUs}
+	}
+	if p.NVLinkEnabledLinks > 0 {
+		th.NVLink = &componentsnvlink.Thresholds{AtLeastEnabledLinks: p.NVLinkEnabledLinks}
+	}
+	if p.InfinibandPorts > 0 {
+		ib := pkgthresholds.Infiniband{}
+		if current.Infiniband != nil {
+			ib = *current.Infiniband
+		}
+		ib.ExpectedPortStates = infiniband.ExpectedPortStates{AtLeastPorts: p.InfinibandPorts, AtLeastRate: p.InfinibandRate}
+		th.Infiniband = &ib
+	}
+	if len(p.MountPoints) > 0 {
+		disk := componentsdisk.Thresholds{}
+		if current.Disk != nil {
+			disk = *current.Disk
+		}
+		disk.ExpectedMountPoints = p.MountPoints
+		th.Disk = &disk
+	}
+	return th
+}
+
+// State is the learning state, persisted across the restarts.
+type State struct {
+	// StartedAt is the time the learning started (i.e., the node joined),
+	// zero until the node joins.
+	StartedAt metav1.Time `json:"started_at"`
+	// Until is the time the learning ends.
+	Until metav1.Time `json:"until"`
+	// Learning is true if the learning period has not ended.
+	Learning bool `json:"learning"`
+	// Observations is the number of the observations so far.
+	Observations int `json:"observations"`
+	// Proposal is the baseline proposed from the observations so far,
+	// nil if not observed yet.
+	Proposal *Proposal `json:"proposal,omitempty"`
+	// AcceptedAt is the time the operator accepted the proposal,
+	// nil if not accepted yet.
+	AcceptedAt *metav1.Time `json:"accepted_at,omitempty"`
+
+	Tally Tally `json:"tally"`
+}
+
+// Tally counts the observed values, to propose the most frequent ones.
+type Tally struct {
+	GPUs               map[int]int    `json:"gpus,omitempty"`
+	NVLinkEnabledLinks map[int]int    `json:"nvlink_enabled_links,omitempty"`
+	InfinibandPorts    map[int]int    `json:"infiniband_ports,omitempty"`
+	InfinibandRate     map[int]int    `json:"infiniband_rate,omitempty"`
+	MountPoints        map[string]int `json:"mount_points,omitempty"`
+}
+
+func (t *Tally) add(o Observation) {
+	inc := func(m *map[int]int, v int) {
+		if *m == nil {
+			*m = make(map[int]int)
+		}
+		(*m)[v]++
+	}
+	inc(&t.GPUs, o.GPUs)
+	inc(&t.NVLinkEnabledLinks, o.NVLinkEnabledLinks)
+	inc(&t.InfinibandPorts, o.InfinibandPorts)
+	inc(&t.InfinibandRate, o.InfinibandRate)
+
+	if t.MountPoints == nil {
+		t.MountPoints = make(map[string]int)
+	}
+	for _, mp := range o.MountPoints {
+		t.MountPoints[mp]++
+	}
+}
+
+// mostFrequent returns the most frequently observed value,
+// the larger value if tied.
+func mostFrequent(m map[int]int) int {
+	best, bestCount := 0, 0
+	for v, count := range m {
+		if count > bestCount || (count == bestCount && v > best) {
+			best, bestCount = v, count
+		}
+	}
+	return best
+}
+
+// propose proposes the most frequently observed values,
+// and the mount points observed in every observation.
+func (t Tally) propose(observations int) Proposal {
+	p := Proposal{
+		GPUs:               mostFrequent(t.GPUs),
+		NVLinkEnabledLinks: mostFrequent(t.NVLinkEnabledLinks),
+		InfinibandPorts:    mostFrequent(t.InfinibandPorts),
+		InfinibandRate:     mostFrequent(t.InfinibandRate),
+	}
+	for mp, count := range t.MountPoints {
+		if count >= observations {
+			p.MountPoints = append(p.MountPoints, mp)
+		}
+	}
+	sort.Strings(p.MountPoints)
+	return p
+}
+
+// ObserveFunc observes the node.
+type ObserveFunc func(ctx context.Context) Observation
+
+// Learner learns the baseline during the learning period.
+type Learner struct {
+	dbRW *sql.DB
+	dbRO *sql.DB
+
+	period      time.Duration
+	observeFunc ObserveFunc
+
+	mu    sync.RWMutex
+	state State
+}
+
+// New creates a learner, resuming the learning persisted in the metadata table if any.
+// A new learning starts at the join (the control plane login),
+// and waits for the join if the node has not joined yet (see Waiting).
+func New(ctx context.Context, dbRW *sql.DB, dbRO *sql.DB, period time.Duration, observeFunc ObserveFunc) (*Learner, error) {
+	l := &Learner{
+		dbRW:        dbRW,
+		dbRO:        dbRO,
+		period:      period,
+		observeFunc: observeFunc,
+	}
+
+	st, err := Read(ctx, dbRO)
+	if err != nil {
+		return nil, err
+	}
+	if st == nil {
+		st = &State{}
+		if err := l.startAtJoin(ctx, st); err != nil {
+			log.Logger.Warnw("failed to read the join time, waiting for the join", "error", err)
+		}
+	}
+	l.state = *st
+	l.state.Learning = time.Now().Before(l.state.Until.Time)
+	return l, nil
+}
+
+// startAtJoin starts the learning period at the join time, if joined.
+func (l *Learner) startAtJoin(ctx context.Context, st *State) error {
+	joinedAt, err := readJoinedAt(ctx, l.dbRO)
+	if err != nil || joinedAt.IsZero() {
+		return err
+	}
+	st.StartedAt = metav1.NewTime(joinedAt)
+	st.Until = metav1.NewTime(joinedAt.Add(l.period))
+	return nil
+}
+
+// Waiting returns true if the learning has not started, waiting for the node to join.
+func (st State) Waiting() bool {
+	return st.StartedAt.IsZero()
+}
+
+func readJoinedAt(ctx context.Context, dbRO *sql.DB) (time.Time, error) {
+	raw, err := pkgmetadata.ReadMetadata(ctx, dbRO, pkgmetadata.MetadataKeyControlPlaneLoginSuccess)
+	if err != nil || raw == "" {
+		return time.Time{}, err
+	}
+	unix, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse login success time %q: %w", raw, err)
+	}
+	return time.Unix(unix, 0).UTC(), nil
+}
+
+// Start observes the node every interval until the learning period ends,
+// waiting for the node to join first if not joined yet.
+func (l *Learner) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if !l.observe(ctx, time.Now().UTC()) {
+				log.Logger.Infow("baseline learning period ended", "until", l.State().Until.Time)
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// observe records an observation, and returns false if the learning period has ended.
+// No observation is recorded until the node joins.
+func (l *Learner) observe(ctx context.Context, now time.Time) bool {
+	l.mu.RLock()
+	st := l.state
+	l.mu.RUnlock()
+
+	if st.Waiting() {
+		if err := l.startAtJoin(ctx, &st); err != nil {
+			log.Logger.Warnw("failed to read the join time", "error", err)
+			return true
+		}
+		if st.Waiting() {
+			return true
+		}
+
+		l.mu.Lock()
+		l.state.StartedAt = st.StartedAt
+		l.state.Until = st.Until
+		l.mu.Unlock()
+		log.Logger.Infow("node joined, started baseline learning", "until", st.Until.Time)
+	}
+
+	if !now.Before(st.Until.Time) {
+		l.mu.Lock()
+		l.state.Learning = false
+		l.mu.Unlock()
+		return false
+	}
+
+	o := l.observeFunc(ctx)
+
+	l.mu.Lock()
+	l.state.Learning = true
+	l.state.Observations++
+	l.state.Tally.add(o)
+	proposal := l.state.Tally.propose(l.state.Observations)
+	l.state.Proposal = &proposal
+	st = l.state
+	l.mu.Unlock()
+
+	if err := Save(ctx, l.dbRW, st); err != nil {
+		log.Logger.Warnw("failed to persist baseline learning state", "error", err)
+	}
+	return true
+}
+
+// State returns the learning state.
+func (l *Learner) State() State {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.state
+}
+
+// Accept applies the proposed baseline as the thresholds and persists them,
+// and returns the thresholds of all the components after the update.
+// The proposal may be accepted before the learning period ends.
+func (l *Learner) Accept(ctx context.Context) (pkgthresholds.Thresholds, error) {
+	l.mu.RLock()
+	proposal := l.state.Proposal
+	l.mu.RUnlock()
+	if proposal == nil {
+		return pkgthresholds.Thresholds{}, ErrNoObservation
+	}
+
+	th := proposal.Thresholds(pkgthresholds.Get())
+	if err := th.Validate(); err != nil {
+		return pkgthresholds.Thresholds{}, err
+	}
+	th.Apply()
+
+	updated := pkgthresholds.Get()
+	if err := pkgthresholds.Save(ctx, l.dbRW, updated); err != nil {
+		return pkgthresholds.Thresholds{}, err
+	}
+
+	now := metav1.NewTime(time.Now().UTC())
+	l.mu.Lock()
+	l.state.AcceptedAt = &now
+	st := l.state
+	l.mu.Unlock()
+
+	if err := Save(ctx, l.dbRW, st); err != nil {
+		return pkgthresholds.Thresholds{}, err
+	}
+	log.Logger.Infow("accepted baseline", "proposal", *proposal)
+	return updated, nil
+}
+
+// Read reads the learning state persisted in the metadata table.
+// It returns nil if no learning has started.
+func Read(ctx context.Context, dbRO *sql.DB) (*State, error) {
+	raw, err := pkgmetadata.ReadMetadata(ctx, dbRO, pkgmetadata.MetadataKeyBaseline)
+	if err != nil {
+		return nil, err
+	}
+	if raw == "" {
+		return nil, nil
+	}
+
+	var st State
+	if err := json.Unmarshal([]byte(raw), &st); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline learning state: %w", err)
+	}
+	return &st, nil
+}
+
+// Save persists the learning state to the metadata table.
+func Save(ctx context.Context, dbRW *sql.DB, st State) error {
+	b, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	return pkgmetadata.SetMetadata(ctx, dbRW, pkgmetadata.MetadataKeyBaseline, string(b))
+}
@@ -0,0 +1,166 @@
+package baseline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	"github.com/leptonai/gpud/pkg/sqlite"
+	pkgthresholds "github.com/leptonai/gpud/pkg/thresholds"
+)
+
+func TestTallyPropose(t *testing.T) {
+	var tally Tally
+	tally.add(Observation{GPUs: 8, InfinibandPorts: 8, InfinibandRate: 400, MountPoints: []string{"/", "/data"}})
+	tally.add(Observation{GPUs: 7, InfinibandPorts: 8, InfinibandRate: 400, MountPoints: []string{"/", "/data", "/mnt/tmp"}})
+	tally.add(Observation{GPUs: 8, InfinibandPorts: 7, InfinibandRate: 400, MountPoints: []string{"/", "/data"}})
+
+	assert.Equal(t, Proposal{
+		GPUs:            8,
+		InfinibandPorts: 8,
+		InfinibandRate:  400,
+		MountPoints:     []string{"/", "/data"},
+	}, tally.propose(3))
+
+	// the larger value if tied
+	assert.Equal(t, 8, mostFrequent(map[int]int{7: 1, 8: 1}))
+	assert.Equal(t, 0, mostFrequent(nil))
+}
+
+func TestProposalThresholds(t *testing.T) {
+	current := pkgthresholds.Get()
+
+	th := Proposal{GPUs: 8, InfinibandPorts: 8, InfinibandRate: 400}.Thresholds(current)
+	require.NotNil(t, th.GPUs)
+	assert.Equal(t, 8, th.GPUs.ExpectedGPUs)
+	require.NotNil(t, th.Infiniband)
+	assert.Equal(t, 8, th.Infiniband.ExpectedPortStates.AtLeastPorts)
+	assert.Equal(t, 400, th.Infiniband.ExpectedPortStates.AtLeastRate)
+	assert.Equal(t, current.Infiniband.Evaluation, th.Infiniband.Evaluation)
+
+	// the values not observed are left unchanged
+	assert.Nil(t, th.NVLink)
+	assert.Nil(t, th.Disk)
+	assert.Nil(t, th.Temperature)
+	assert.Nil(t, th.ECC)
+}
+
+func TestLearner(t *testing.T) {
+	orig := pkgthresholds.Get()
+	t.Cleanup(orig.Apply)
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	require.NoError(t, pkgmetadata.CreateTableMetadata(ctx, dbRW))
+
+	joinedAt := time.Now().Add(-time.Hour).Truncate(time.Second).UTC()
+	require.NoError(t, pkgmetadata.SetMetadata(ctx, dbRW, pkgmetadata.MetadataKeyControlPlaneLoginSuccess, fmt.Sprintf("%d", joinedAt.Unix())))
+
+	observeFunc := func(ctx context.Context) Observation {
+		return Observation{GPUs: 8, NVLinkEnabledLinks: 18, MountPoints: []string{"/data"}}
+	}
+	l, err := New(ctx, dbRW, dbRO, 24*time.Hour, observeFunc)
+	require.NoError(t, err)
+
+	st := l.State()
+	assert.True(t, st.StartedAt.Time.Equal(joinedAt))
+	assert.True(t, st.Learning)
+	assert.Nil(t, st.Proposal)
+
+	_, err = l.Accept(ctx)
+	assert.True(t, errors.Is(err, ErrNoObservation))
+
+	require.True(t, l.observe(ctx, time.Now()))
+	require.True(t, l.observe(ctx, time.Now()))
+	st = l.State()
+	assert.Equal(t, 2, st.Observations)
+	require.NotNil(t, st.Proposal)
+	assert.Equal(t, Proposal{GPUs: 8, NVLinkEnabledLinks: 18, MountPoints: []string{"/data"}}, *st.Proposal)
+
+	// resumes the learning after the restart
+	l, err = New(ctx, dbRW, dbRO, 24*time.Hour, observeFunc)
+	require.NoError(t, err)
+	assert.Equal(t, 2, l.State().Observations)
+
+	updated, err := l.Accept(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 8, updated.GPUs.ExpectedGPUs)
+	assert.Equal(t, 18, updated.NVLink.AtLeastEnabledLinks)
+	assert.Equal(t, []string{"/data"}, updated.Disk.ExpectedMountPoints)
+	assert.Equal(t, updated, pkgthresholds.Get())
+	assert.NotNil(t, l.State().AcceptedAt)
+
+	persisted, err := pkgthresholds.Read(ctx, dbRO)
+	require.NoError(t, err)
+	require.NotNil(t, persisted)
+	assert.Equal(t, updated, *persisted)
+
+	// no more observation once the learning period ended
+	assert.False(t, l.observe(ctx, joinedAt.Add(25*time.Hour)))
+	assert.False(t, l.State().Learning)
+	assert.Equal(t, 2, l.State().Observations)
+}
+
+func TestLearnerWaitsForJoin(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	require.NoError(t, pkgmetadata.CreateTableMetadata(ctx, dbRW))
+
+	observeFunc := func(ctx context.Context) Observation {
+		return Observation{GPUs: 8}
+	}
+	l, err := New(ctx, dbRW, dbRO, 24*time.Hour, observeFunc)
+	require.NoError(t, err)
+
+	// not learning until the node joins, rather than from the install
+	st := l.State()
+	assert.True(t, st.Waiting())
+	assert.False(t, st.Learning)
+	require.True(t, l.observe(ctx, time.Now()))
+	assert.Equal(t, 0, l.State().Observations)
+
+	joinedAt := time.Now().Truncate(time.Second).UTC()
+	require.NoError(t, pkgmetadata.SetMetadata(ctx, dbRW, pkgmetadata.MetadataKeyControlPlaneLoginSuccess, fmt.Sprintf("%d", joinedAt.Unix())))
+
+	require.True(t, l.observe(ctx, time.Now()))
+	st = l.State()
+	assert.False(t, st.Waiting())
+	assert.True(t, st.StartedAt.Time.Equal(joinedAt))
+	assert.True(t, st.Until.Time.Equal(joinedAt.Add(24*time.Hour)))
+	assert.True(t, st.Learning)
+	assert.Equal(t, 1, st.Observations)
+}
+
+func TestObserveInfiniband(t *testing.T) {
+	port := func(name string, state string, physicalState string, rate int, linkLayer string) string {
+		return fmt.Sprintf("CA '%s'\n\tCA type: MT4129\n\tNumber of ports: 1\n\tPort 1:\n\t\tState: %s\n\t\tPhysical state: %s\n\t\tRate: %d\n\t\tBase lid: 75\n\t\tSM lid: 334\n\t\tPort GUID: 0x946dae0300cde72c\n\t\tLink layer: %s\n", name, state, physicalState, rate, linkLayer)
+	}
+	out := port("mlx5_0", "Active", "LinkUp", 400, "InfiniBand") +
+		port("mlx5_1", "Down", "Disabled", 40, "InfiniBand") +
+		port("mlx5_2", "Active", "LinkUp", 100, "Ethernet")
+
+	f := filepath.Join(t.TempDir(), "ibstat.txt")
+	require.NoError(t, os.WriteFile(f, []byte(out), 0644))
+
+	// the port down is counted, while its rate and the Ethernet port are not
+	var o Observation
+	observeInfiniband(context.Background(), "cat "+f, &o)
+	assert.Equal(t, 2, o.InfinibandPorts)
+	assert.Equal(t, 400, o.InfinibandRate)
+}
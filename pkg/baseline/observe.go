@@ -0,0 +1,100 @@
+package baseline
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/leptonai/gpud/pkg/disk"
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/nvidia-query/infiniband"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+)
+
+// NewObserveFunc returns the function to observe the node
+// with the NVML instance (nil if not available), the "ibstat" command,
+// and the mounted partitions.
+func NewObserveFunc(nvmlInstance nvidianvml.Instance, ibstatCommand string) ObserveFunc {
+	if ibstatCommand == "" {
+		ibstatCommand = "ibstat"
+	}
+	return func(ctx context.Context) Observation {
+		var o Observation
+		if nvmlInstance != nil && nvmlInstance.NVMLExists() {
+			observeGPUs(nvmlInstance, &o)
+		}
+		observeInfiniband(ctx, ibstatCommand, &o)
+		observeMountPoints(ctx, &o)
+		return o
+	}
+}
+
+func observeGPUs(nvmlInstance nvidianvml.Instance, o *Observation) {
+	devs := nvmlInstance.Devices()
+	o.GPUs = len(devs)
+
+	fewest := -1
+	for uuid, dev := range devs {
+		nvLink, err := nvidianvml.GetNVLink(uuid, dev)
+		if err != nil {
+			log.Logger.Warnw("failed to get nvlink", "uuid", uuid, "error", err)
+			continue
+		}
+		if !nvLink.Supported {
+			continue
+		}
+		enabled := 0
+		for _, state := range nvLink.States {
+			if state.FeatureEnabled {
+				enabled++
+			}
+		}
+		if fewest < 0 || enabled < fewest {
+			fewest = enabled
+		}
+	}
+	if fewest > 0 {
+		o.NVLinkEnabledLinks = fewest
+	}
+}
+
+func observeInfiniband(ctx context.Context, ibstatCommand string, o *Observation) {
+	out, err := infiniband.GetIbstatOutput(ctx, []string{ibstatCommand})
+	if out == nil {
+		log.Logger.Debugw("no ibstat output", "error", err)
+		return
+	}
+
+	for _, card := range out.Parsed {
+		// the Ethernet (e.g., RoCE) ports are not checked as the infiniband ports
+		if ll := card.Port1.LinkLayer; ll != "" && !strings.EqualFold(ll, "InfiniBand") {
+			continue
+		}
+
+		// every port is counted, so that the ports down during the learning
+		// are reported once the baseline is accepted, rather than learned
+		o.InfinibandPorts++
+
+		// the rate of the ports not up is not the rate of the link
+		if card.Port1.State != "Active" || card.Port1.PhysicalState != "LinkUp" {
+			continue
+		}
+		if o.InfinibandRate == 0 || card.Port1.Rate < o.InfinibandRate {
+			o.InfinibandRate = card.Port1.Rate
+		}
+	}
+}
+
+func observeMountPoints(ctx context.Context, o *Observation) {
+	for _, fsTypeFunc := range []disk.MatchFunc{disk.DefaultExt4FsTypeFunc, disk.DefaultNFSFsTypeFunc} {
+		parts, err := disk.GetPartitions(ctx, disk.WithFstype(fsTypeFunc), disk.WithSkipUsage())
+		if err != nil {
+			log.Logger.Warnw("failed to get partitions", "error", err)
+			continue
+		}
+		for _, p := range parts {
+			o.MountPoints = append(o.MountPoints, p.MountPoint)
+		}
+	}
+	sort.Strings(o.MountPoints)
+}
@@ -0,0 +1,121 @@
+// Package certify issues the node certification document on passing the full
+// deep-check suite, signed with the dedicated certification key, so that the control
+// planes or the customers can verify the node passed the acceptance testing.
+package certify
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgscan "github.com/leptonai/gpud/pkg/scan"
+)
+
+// SchemaVersion is the version of the certification document format.
+const SchemaVersion = "v1"
+
+var (
+	ErrNotPassed        = errors.New("node did not pass the certification checks")
+	ErrInvalidSignature = errors.New("certification signature not valid")
+)
+
+// Certificate is the certification document of the node.
+type Certificate struct {
+	SchemaVersion string `json:"schema_version"`
+	// IssuedAt is the time the certification checks completed.
+	IssuedAt metav1.Time `json:"issued_at"`
+	// GPUdVersion is the version of gpud that ran the checks.
+	GPUdVersion string `json:"gpud_version"`
+	// Profile is the scan profile of the checks.
+	Profile pkgscan.Profile `json:"profile"`
+	// MachineInfo is the hardware inventory of the node
+	// (e.g., the machine ID, the GPUs, the NICs, the disks).
+	MachineInfo *apiv1.MachineInfo `json:"machine_info"`
+	// HealthStates are the results of the checks, all healthy.
+	HealthStates apiv1.GPUdComponentHealthStates `json:"health_states"`
+	// Took is the duration of the checks.
+	Took metav1.Duration `json:"took"`
+}
+
+// failedChecks returns the "<component>: <reason>" of the checks not healthy, sorted.
+func failedChecks(states apiv1.GPUdComponentHealthStates) []string {
+	var failed []string
+	for _, cs := range states {
+		for _, st := range cs.States {
+			if st.Health == apiv1.HealthStateTypeHealthy {
+				continue
+			}
+			failed = append(failed, fmt.Sprintf("%s: %s", cs.Component, st.Reason))
+		}
+	}
+	sort.Strings(failed)
+	return failed
+}
+
+// New creates the certificate from the scan result,
+// returning ErrNotPassed if any check is not healthy or not run.
+func New(result *pkgscan.Result, issuedAt time.Time, gpudVersion string) (*Certificate, error) {
+	if failed := failedChecks(result.HealthStates); len(failed) > 0 {
+		return nil, fmt.Errorf("%w (%d failed check(s): %s)", ErrNotPassed, len(failed), strings.Join(failed, "; "))
+	}
+	if len(result.NotRun) > 0 {
+		return nil, fmt.Errorf("%w (%d check(s) not run: %s)", ErrNotPassed, len(result.NotRun), strings.Join(result.NotRun, "; "))
+	}
+	return &Certificate{
+		SchemaVersion: SchemaVersion,
+		IssuedAt:      metav1.NewTime(issuedAt.UTC()),
+		GPUdVersion:   gpudVersion,
+		Profile:       result.Profile,
+		MachineInfo:   result.MachineInfo,
+		HealthStates:  result.HealthStates,
+		Took:          result.Took,
+	}, nil
+}
+
+// Marshal encodes the certificate in indented JSON, the exact bytes to sign.
+func (c *Certificate) Marshal() ([]byte, error) {
+	return json.MarshalIndent(c, "", "  ")
+}
+
+// Sign signs the certificate document with the certification key.
+func Sign(doc []byte, key *Key) ([]byte, error) {
+	if len(doc) == 0 {
+		return nil, errors.New("empty certificate")
+	}
+	return ed25519.Sign(key.k, signedMessage(doc)), nil
+}
+
+// Verify verifies the signature of the certificate document with any of the public
+// certification keys not yet retired in the bundle (see NotAfterHeader),
+// and returns the parsed certificate.
+func Verify(doc []byte, sig []byte, pubBundle []byte) (*Certificate, error) {
+	pubs, err := ParseKeyBundleAt(pubBundle, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certification key bundle: %w", err)
+	}
+
+	msg := signedMessage(doc)
+	verified := false
+	for _, pub := range pubs {
+		if ed25519.Verify(pub, msg, sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, ErrInvalidSignature
+	}
+
+	var c Certificate
+	if err := json.Unmarshal(doc, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	return &c, nil
+}
@@ -0,0 +1,103 @@
+package certify
+
+import (
+	"crypto/ed25519"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/release/distsign"
+	pkgscan "github.com/leptonai/gpud/pkg/scan"
+)
+
+func TestNew(t *testing.T) {
+	result := &pkgscan.Result{
+		Profile:     pkgscan.ProfileDeep,
+		MachineInfo: &apiv1.MachineInfo{GPUdVersion: "v0.5.0"},
+		HealthStates: apiv1.GPUdComponentHealthStates{
+			{Component: "cpu", States: apiv1.HealthStates{{Health: apiv1.HealthStateTypeHealthy, Reason: "ok"}}},
+			{Component: "dcgm-diag", States: apiv1.HealthStates{{Health: apiv1.HealthStateTypeHealthy, Reason: "passed"}}},
+		},
+	}
+
+	now := time.Now()
+	c, err := New(result, now, "v0.5.0")
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersion, c.SchemaVersion)
+	assert.Equal(t, pkgscan.ProfileDeep, c.Profile)
+	assert.True(t, c.IssuedAt.Time.Equal(now))
+	assert.Len(t, c.HealthStates, 2)
+
+	result.HealthStates = append(result.HealthStates, apiv1.ComponentHealthStates{
+		Component: "accelerator-nvidia-ecc",
+		States:    apiv1.HealthStates{{Health: apiv1.HealthStateTypeUnhealthy, Reason: "uncorrectable errors"}},
+	})
+	_, err = New(result, now, "v0.5.0")
+	require.True(t, errors.Is(err, ErrNotPassed))
+	assert.Contains(t, err.Error(), "accelerator-nvidia-ecc: uncorrectable errors")
+
+	// the deep checks not run (e.g., the tool not installed)
+	result.HealthStates = result.HealthStates[:2]
+	result.NotRun = []string{"nvbandwidth: nvbandwidth not found"}
+	_, err = New(result, now, "v0.5.0")
+	require.True(t, errors.Is(err, ErrNotPassed))
+	assert.Contains(t, err.Error(), "1 check(s) not run: nvbandwidth: nvbandwidth not found")
+}
+
+func TestSignVerify(t *testing.T) {
+	priv, pub, err := GenerateKey()
+	require.NoError(t, err)
+	key, err := ParseKey(priv)
+	require.NoError(t, err)
+
+	c := &Certificate{SchemaVersion: SchemaVersion, GPUdVersion: "v0.5.0", Profile: pkgscan.ProfileDeep}
+	doc, err := c.Marshal()
+	require.NoError(t, err)
+
+	sig, err := Sign(doc, key)
+	require.NoError(t, err)
+
+	verified, err := Verify(doc, sig, pub)
+	require.NoError(t, err)
+	assert.Equal(t, "v0.5.0", verified.GPUdVersion)
+
+	// tampered document
+	tampered := append([]byte{}, doc...)
+	tampered[len(tampered)-2] = ' '
+	_, err = Verify(tampered, sig, pub)
+	assert.True(t, errors.Is(err, ErrInvalidSignature))
+
+	// signed by another key
+	_, otherPub, err := GenerateKey()
+	require.NoError(t, err)
+	_, err = Verify(doc, sig, otherPub)
+	assert.True(t, errors.Is(err, ErrInvalidSignature))
+
+	// the plain signature of the document with the same key, as any other message
+	block, _ := pem.Decode(priv)
+	require.NotNil(t, block)
+	_, err = Verify(doc, ed25519.Sign(ed25519.PrivateKey(block.Bytes), doc), pub)
+	assert.True(t, errors.Is(err, ErrInvalidSignature))
+
+	// signed by the retired key
+	block, _ = pem.Decode(pub)
+	require.NotNil(t, block)
+	block.Headers = map[string]string{NotAfterHeader: time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)}
+	_, err = Verify(doc, sig, pem.EncodeToMemory(block))
+	assert.Error(t, err)
+
+	// the package signing keys are not certification keys
+	_, signingPub, err := distsign.GenerateSigningKey()
+	require.NoError(t, err)
+	_, err = Verify(doc, sig, signingPub)
+	assert.Error(t, err)
+	signingPriv, _, err := distsign.GenerateSigningKey()
+	require.NoError(t, err)
+	_, err = ParseKey(signingPriv)
+	assert.Error(t, err)
+}
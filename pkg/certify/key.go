@@ -0,0 +1,108 @@
+package certify
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// pemTypeKeyPrivate and pemTypeKeyPublic are the PEM types of the certification keys,
+	// distinct from the distsign package signing keys, so that the keys are never
+	// used for each other.
+	pemTypeKeyPrivate = "CERTIFICATION PRIVATE KEY"
+	pemTypeKeyPublic  = "CERTIFICATION PUBLIC KEY"
+
+	// signaturePrefix separates the certificate signatures from the signatures
+	// of any other message with the same key.
+	signaturePrefix = "gpud-node-certificate-v1\x00"
+)
+
+// NotAfterHeader is the PEM header of the public certification key in the bundle,
+// after which the key no longer verifies the certificates.
+const NotAfterHeader = "Not-After"
+
+// SignatureSuffix is the suffix of the detached signature file next to the certificate.
+const SignatureSuffix = ".sig"
+
+// Key is the private certification key to sign the certificates with.
+type Key struct {
+	k ed25519.PrivateKey
+}
+
+// GenerateKey generates a new certification key pair and encodes it as PEM.
+func GenerateKey() (priv, pub []byte, err error) {
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypeKeyPrivate, Bytes: privKey}),
+		pem.EncodeToMemory(&pem.Block{Type: pemTypeKeyPublic, Bytes: pubKey}),
+		nil
+}
+
+// ParseKey parses the PEM-encoded private certification key,
+// in the same format as returned by GenerateKey.
+func ParseKey(priv []byte) (*Key, error) {
+	b, rest := pem.Decode(priv)
+	if b == nil {
+		return nil, errors.New("failed to decode PEM data")
+	}
+	if len(rest) > 0 {
+		return nil, errors.New("trailing PEM data")
+	}
+	if b.Type != pemTypeKeyPrivate {
+		return nil, fmt.Errorf("PEM type is %q, want %q", b.Type, pemTypeKeyPrivate)
+	}
+	if len(b.Bytes) != ed25519.PrivateKeySize {
+		return nil, errors.New("private key has incorrect length for an Ed25519 private key")
+	}
+	return &Key{k: ed25519.PrivateKey(b.Bytes)}, nil
+}
+
+// ParseKeyBundleAt parses the bundle of PEM-encoded public certification keys,
+// excluding the keys retired at the time (see NotAfterHeader).
+func ParseKeyBundleAt(bundle []byte, now time.Time) ([]ed25519.PublicKey, error) {
+	var keys []ed25519.PublicKey
+	parsed := 0
+	for len(bundle) > 0 {
+		b, rest := pem.Decode(bundle)
+		if b == nil {
+			return nil, errors.New("failed to decode PEM data")
+		}
+		bundle = rest
+
+		if b.Type != pemTypeKeyPublic {
+			return nil, fmt.Errorf("PEM type is %q, want %q", b.Type, pemTypeKeyPublic)
+		}
+		if len(b.Bytes) != ed25519.PublicKeySize {
+			return nil, errors.New("public key has incorrect length for an Ed25519 public key")
+		}
+		parsed++
+
+		if v, ok := b.Headers[NotAfterHeader]; ok {
+			notAfter, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s header %q: %w", NotAfterHeader, v, err)
+			}
+			if !now.Before(notAfter) {
+				continue
+			}
+		}
+		keys = append(keys, ed25519.PublicKey(b.Bytes))
+	}
+	if parsed == 0 {
+		return nil, errors.New("no certification keys found in the bundle")
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("all certification keys in the bundle have been retired")
+	}
+	return keys, nil
+}
+
+func signedMessage(doc []byte) []byte {
+	return append([]byte(signaturePrefix), doc...)
+}
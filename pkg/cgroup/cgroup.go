@@ -0,0 +1,77 @@
+// Package cgroup resolves the processes to their cgroups,
+// and the cgroups to the containers and Kubernetes pods.
+package cgroup
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultProcRoot is the default proc filesystem root.
+const DefaultProcRoot = "/proc"
+
+// Process is the cgroup of a process, resolved to its container and pod.
+type Process struct {
+	// Path is the cgroup path of the process (e.g., "/kubepods.slice/...").
+	Path string `json:"cgroup,omitempty"`
+	// ContainerID is the ID of the container the process runs in, if any.
+	ContainerID string `json:"container_id,omitempty"`
+	// PodUID is the UID of the Kubernetes pod the process runs in, if any.
+	PodUID string `json:"pod_uid,omitempty"`
+}
+
+// ReadProcess reads the cgroup of the process from "<procRoot>/<pid>/cgroup",
+// and resolves its container and pod.
+func ReadProcess(procRoot string, pid int32) (Process, error) {
+	b, err := os.ReadFile(filepath.Join(procRoot, strconv.Itoa(int(pid)), "cgroup"))
+	if err != nil {
+		return Process{}, err
+	}
+
+	p := Process{Path: ParsePath(string(b))}
+	p.ContainerID, p.PodUID = ResolveContainer(p.Path)
+	return p, nil
+}
+
+// ParsePath returns the cgroup path from the "/proc/<pid>/cgroup" contents,
+// preferring the unified (v2) hierarchy, or the one with the container ID (v1).
+//
+// e.g.,
+// 0::/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod1b2c.slice/cri-containerd-<id>.scope
+// 12:memory:/kubepods/besteffort/pod1b2c.../<id>
+func ParsePath(contents string) string {
+	var fallback string
+	for _, line := range strings.Split(strings.TrimSpace(contents), "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[0] == "0" && parts[1] == "" {
+			return parts[2]
+		}
+		if fallback == "" || containerIDRegex.MatchString(parts[2]) && !containerIDRegex.MatchString(fallback) {
+			fallback = parts[2]
+		}
+	}
+	return fallback
+}
+
+var (
+	containerIDRegex = regexp.MustCompile(`[0-9a-f]{64}`)
+	podUIDRegex      = regexp.MustCompile(`pod([0-9a-f]{8}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{12})`)
+)
+
+// ResolveContainer returns the container ID and the pod UID in the cgroup path, if any.
+func ResolveContainer(path string) (containerID string, podUID string) {
+	if ids := containerIDRegex.FindAllString(path, -1); len(ids) > 0 {
+		containerID = ids[len(ids)-1]
+	}
+	if m := podUIDRegex.FindStringSubmatch(path); m != nil {
+		// the systemd cgroup driver escapes "-" in the pod UID to "_"
+		podUID = strings.ReplaceAll(m[1], "_", "-")
+	}
+	return containerID, podUID
+}
@@ -0,0 +1,54 @@
+package cgroup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testContainerID = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	testPodUID      = "1b2c3d4e-5f60-7182-93a4-b5c6d7e8f901"
+)
+
+func TestParsePath(t *testing.T) {
+	v2 := "0::/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod" +
+		"1b2c3d4e_5f60_7182_93a4_b5c6d7e8f901.slice/cri-containerd-" + testContainerID + ".scope\n"
+	containerID, podUID := ResolveContainer(ParsePath(v2))
+	assert.Equal(t, testContainerID, containerID)
+	assert.Equal(t, testPodUID, podUID)
+
+	v1 := "12:cpuset:/\n11:memory:/kubepods/besteffort/pod" + testPodUID + "/" + testContainerID + "\n"
+	path := ParsePath(v1)
+	assert.Equal(t, "/kubepods/besteffort/pod"+testPodUID+"/"+testContainerID, path)
+	containerID, podUID = ResolveContainer(path)
+	assert.Equal(t, testContainerID, containerID)
+	assert.Equal(t, testPodUID, podUID)
+
+	docker := "0::/system.slice/docker-" + testContainerID + ".scope\n"
+	containerID, podUID = ResolveContainer(ParsePath(docker))
+	assert.Equal(t, testContainerID, containerID)
+	assert.Empty(t, podUID)
+
+	containerID, podUID = ResolveContainer(ParsePath("0::/user.slice/user-1000.slice/session-1.scope\n"))
+	assert.Empty(t, containerID)
+	assert.Empty(t, podUID)
+}
+
+func TestReadProcess(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "100"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "100", "cgroup"), []byte("0::/kubepods/pod"+testPodUID+"/"+testContainerID+"\n"), 0644))
+
+	p, err := ReadProcess(root, 100)
+	require.NoError(t, err)
+	assert.Equal(t, "/kubepods/pod"+testPodUID+"/"+testContainerID, p.Path)
+	assert.Equal(t, testContainerID, p.ContainerID)
+	assert.Equal(t, testPodUID, p.PodUID)
+
+	_, err = ReadProcess(root, 101)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -1,6 +1,25 @@
 package common
 
+// ToolOverwrites replaces the NVIDIA tools with the commands printing
+// the canned outputs (e.g., the dev mode, the scan replay).
+// The user-configured tool paths are set via "pkg/toolpath" instead.
 type ToolOverwrites struct {
 	IbstatCommand   string `json:"ibstat_command"`
 	IbstatusCommand string `json:"ibstatus_command"`
+	SaqueryCommand  string `json:"saquery_command"`
+}
+
+// WithDefaults returns the overwrites with the empty commands
+// set to the tools themselves, located via "pkg/toolpath".
+func (o ToolOverwrites) WithDefaults() ToolOverwrites {
+	if o.IbstatCommand == "" {
+		o.IbstatCommand = "ibstat"
+	}
+	if o.IbstatusCommand == "" {
+		o.IbstatusCommand = "ibstatus"
+	}
+	if o.SaqueryCommand == "" {
+		o.SaqueryCommand = "saquery"
+	}
+	return o
 }
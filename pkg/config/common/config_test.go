@@ -0,0 +1,19 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToolOverwritesWithDefaults(t *testing.T) {
+	assert.Equal(t, ToolOverwrites{
+		IbstatCommand:   "ibstat",
+		IbstatusCommand: "ibstatus",
+		SaqueryCommand:  "saquery",
+	}, ToolOverwrites{}.WithDefaults())
+
+	o := ToolOverwrites{IbstatCommand: "cat ibstat.txt"}.WithDefaults()
+	assert.Equal(t, "cat ibstat.txt", o.IbstatCommand)
+	assert.Equal(t, "ibstatus", o.IbstatusCommand)
+}
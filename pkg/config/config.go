@@ -9,7 +9,14 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	nvidia_common "github.com/leptonai/gpud/pkg/config/common"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/devmode"
+	pkgmaintenance "github.com/leptonai/gpud/pkg/maintenance"
+	latencytarget "github.com/leptonai/gpud/pkg/netutil/latency/target"
+	querygds "github.com/leptonai/gpud/pkg/nvidia-query/gds"
+	pkgpeermesh "github.com/leptonai/gpud/pkg/peer-mesh"
+	"github.com/leptonai/gpud/pkg/toolexec"
+	"github.com/leptonai/gpud/pkg/toolpath"
 )
 
 // Config provides gpud configuration data for the server
@@ -30,9 +37,163 @@ type Config struct {
 	// Interval at which to compact the state database.
 	CompactPeriod metav1.Duration `json:"compact_period"`
 
+	// Directory to archive the metrics older than the retention period into,
+	// in the parquet files, instead of deleting them.
+	// Leave empty to disable the archival.
+	MetricsArchiveDir string `json:"metrics_archive_dir,omitempty"`
+
+	// Destination to upload the archived metrics files to
+	// (e.g., "s3://bucket/prefix", "gs://bucket/prefix").
+	// The files are removed from the archive directory once uploaded.
+	// Only valid when the metrics archive directory is set.
+	MetricsArchiveUploadDestination string `json:"metrics_archive_upload_destination,omitempty"`
+
 	// Set true to enable profiler.
 	Pprof bool `json:"pprof"`
 
+	// Set true to serve the built-in web dashboard at the root path.
+	EnableWebUI bool `json:"enable_web_ui"`
+
+	// Annotations are the node labels (e.g., rack, cluster, tenant) attached to
+	// every health state, event, and metric emitted by GPUd.
+	// The labels assigned by the control plane take precedence over the same keys.
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// PredictionModel is the failure prediction model to surface the
+	// predicted failure risks per node and per GPU with: "baseline" for the
+	// built-in model, "http(s)://..." for the scoring endpoint, or "exec:<path>"
+	// for the local command (e.g., running a local ONNX model).
+	// Leave empty to disable the failure prediction.
+	PredictionModel string `json:"prediction_model,omitempty"`
+
+	// EventSinksFile is the YAML file that defines the notification sinks
+	// (e.g., webhooks) and their routing rules by the component and severity.
+	// Leave empty to disable the event notifications.
+	EventSinksFile string `json:"event_sinks_file,omitempty"`
+
+	// EscalationLadderFile is the YAML file that defines the escalation ladders,
+	// escalating the severity of the unhealthy states the longer they persist,
+	// with the additional sinks and actions at each step.
+	// Leave empty to keep the unhealthy states at the base severity.
+	EscalationLadderFile string `json:"escalation_ladder_file,omitempty"`
+
+	// HooksFile is the YAML file that defines the local commands run on the
+	// health transitions of the components (e.g., run a script once the
+	// infiniband component is unhealthy), with their outputs recorded as events.
+	// Leave empty to disable the hooks.
+	HooksFile string `json:"hooks_file,omitempty"`
+
+	// KmsgMatchersFile is the YAML file that defines the user-supplied kernel message
+	// matchers (regex, owner component, event type, suggested action),
+	// in addition to the built-in matchers.
+	// Leave empty to use the built-in matchers only.
+	KmsgMatchersFile string `json:"kmsg_matchers_file,omitempty"`
+
+	// LLDPCablingMapFile is the YAML file that defines the expected switch
+	// and port of each interface, to validate the LLDP neighbors against.
+	// Leave empty to only record the LLDP neighbors.
+	LLDPCablingMapFile string `json:"lldp_cabling_map_file,omitempty"`
+
+	// InfinibandEvaluationFile is the YAML file that defines the infiniband
+	// port drop duration, flap window, minimum flap transitions, and error
+	// counter delta thresholds, reloaded whenever the file changes.
+	// The fields set in the file override the ones set by the control plane,
+	// which override the ones set by the thresholds API.
+	// Leave empty to use the defaults.
+	InfinibandEvaluationFile string `json:"infiniband_evaluation_file,omitempty"`
+
+	// RemediationPolicyFile is the YAML file that defines the policy mapping
+	// the component and failure class to the repair actions gpud may perform
+	// automatically, and their cooldowns.
+	// Leave empty to only suggest the repair actions.
+	RemediationPolicyFile string `json:"remediation_policy_file,omitempty"`
+
+	// ErrorBudgetFile is the YAML file that defines the budgets of the correctable
+	// hardware errors (e.g., 100 corrected ECC errors per 24h), marking the node
+	// as degraded once any budget is exhausted.
+	// Leave empty to disable the error budget tracking.
+	ErrorBudgetFile string `json:"error_budget_file,omitempty"`
+
+	// ArtifactSignPubPath is the bundle of the public artifact keys
+	// to verify the plugin specs file and the config files above with their
+	// detached signatures (the file path with the ".sig" suffix) before applying.
+	// Leave empty to apply the files without verification.
+	ArtifactSignPubPath string `json:"artifact_sign_pub_path,omitempty"`
+
+	// MaintenanceDeferral defers the automatic remediations and the auto-updates
+	// while the GPU jobs are running on the node (e.g., the pods requesting the GPUs,
+	// or the busy hook), up to the maximum deferral before forcing them.
+	// Leave nil to never defer.
+	MaintenanceDeferral *pkgmaintenance.Policy `json:"maintenance_deferral,omitempty"`
+
+	// Set true to attribute the GPU usage (GPU-seconds and energy) to the
+	// cgroups and pods running on the GPUs, and persist the usage records
+	// per window to bill the tenants by.
+	EnableGPUAccounting bool `json:"enable_gpu_accounting,omitempty"`
+
+	// Set true to export the component health states, the check durations,
+	// and the infiniband port states on the "/metrics" endpoint for Prometheus,
+	// in addition to the component metrics (e.g., GPU utilization, temperature).
+	EnablePrometheus bool `json:"enable_prometheus,omitempty"`
+
+	// OTLPEndpoint is the OpenTelemetry collector OTLP/HTTP endpoint
+	// (e.g., "http://otel-collector:4318") to export the component check
+	// traces and metrics to.
+	// Leave empty to disable the export.
+	OTLPEndpoint string `json:"otlp_endpoint,omitempty"`
+
+	// Set true to remove and rescan the GPUs that fell off the PCI bus,
+	// before suggesting a reboot.
+	EnablePCIRescan bool `json:"enable_pci_rescan"`
+
+	// LatencyTargets are the user-provided targets (e.g., the control plane,
+	// storage endpoints, peer nodes) to measure the network latencies against,
+	// in addition to the global edge servers.
+	LatencyTargets []latencytarget.Target `json:"latency_targets,omitempty"`
+
+	// PeerMeshPeers are the peer gpud nodes to probe for the latencies
+	// and the packet loss, to build the mesh health view.
+	// The control plane may override the peers via the config update.
+	PeerMeshPeers pkgpeermesh.Peers `json:"peer_mesh_peers,omitempty"`
+
+	// Set true to allow injecting the faults into the components
+	// (unhealthy results, timeouts, panics) via the inject-fault API,
+	// for the chaos testing in the integration tests and staging environments.
+	EnableComponentFaultInjection bool `json:"enable_component_fault_injection,omitempty"`
+
+	// MemoryCeilingBytes is the RSS ceiling of the gpud process in bytes.
+	// Once exceeded (e.g., leaking), gpud records an event and exits
+	// for the systemd to restart it.
+	// Zero disables the RSS ceiling.
+	MemoryCeilingBytes uint64 `json:"memory_ceiling_bytes,omitempty"`
+
+	// HeapCeilingBytes is the Go heap ceiling of the gpud process in bytes,
+	// with the same behavior as the MemoryCeilingBytes.
+	// Zero disables the heap ceiling.
+	HeapCeilingBytes uint64 `json:"heap_ceiling_bytes,omitempty"`
+
+	// DevMode fakes the NVML and InfiniBand backends with the synthetic GPUs
+	// and the failure scenarios, to run the full daemon without any GPU.
+	// Leave nil to use the real backends.
+	DevMode *devmode.Config `json:"dev_mode,omitempty"`
+
+	// CheckLoadPolicy defers or downscopes the low priority component checks (e.g., the network probes)
+	// while the node CPU or GPU utilization is above the thresholds,
+	// to minimize the interference with the workloads.
+	// Leave nil to always run all the checks.
+	CheckLoadPolicy *components.LoadPolicy `json:"check_load_policy,omitempty"`
+
+	// BaselineLearningPeriod is the period after the node joins to learn its normal values
+	// (e.g., the GPU count, the infiniband ports), proposed as the thresholds
+	// the operator can accept via the baseline API.
+	// Zero disables the baseline learning.
+	BaselineLearningPeriod metav1.Duration `json:"baseline_learning_period,omitempty"`
+
+	// GDSProbe probes the GPUDirect Storage read throughput in the directory
+	// on the GDS compatible filesystem, to tell the slow compatibility mode.
+	// Leave nil to only validate the GDS prerequisites.
+	GDSProbe *querygds.ProbeConfig `json:"gds_probe,omitempty"`
+
 	// Set false to disable auto update
 	EnableAutoUpdate bool `json:"enable_auto_update"`
 
@@ -41,8 +202,29 @@ type Config struct {
 	// Set -1 to disable the auto update by exit code.
 	AutoUpdateExitCode int `json:"auto_update_exit_code"`
 
-	// A list of nvidia tool command paths to overwrite the default paths.
-	NvidiaToolOverwrites nvidia_common.ToolOverwrites `json:"nvidia_tool_overwrites"`
+	// ToolPaths maps the external tool names (e.g., "ibstat", "lspci")
+	// to the paths to run instead of looking up the PATH and the well-known paths.
+	// Leave empty to auto-discover all the tools.
+	ToolPaths map[string]string `json:"tool_paths,omitempty"`
+
+	// ToolResourceLimits caps the CPU and the memory of the external tools
+	// run by each component (or "*" for all the components),
+	// in the transient cgroups in the delegated cgroup of gpud ("Delegate=yes"),
+	// or in the transient systemd scopes otherwise, so that a misbehaving diagnostic
+	// cannot starve the workloads on the node.
+	// Leave empty to not cap the tools.
+	ToolResourceLimits map[string]toolexec.ResourceLimits `json:"tool_resource_limits,omitempty"`
+
+	// ComponentIntervals overrides the intervals of the periodic checks
+	// per component name (e.g., {"accelerator-nvidia-infiniband": "30s", "disk": "5m"}).
+	// Leave empty to check all the components every minute.
+	ComponentIntervals map[string]metav1.Duration `json:"component_intervals,omitempty"`
+
+	// ComponentCheckBudgets overrides the durations each component check
+	// is expected to complete within, before warned as over budget
+	// (e.g., {"accelerator-nvidia-infiniband": "30s"}).
+	// Leave empty to use the 10-second default for all the components.
+	ComponentCheckBudgets map[string]metav1.Duration `json:"component_check_budgets,omitempty"`
 
 	// PluginSpecsFile is the file that contains the plugin specs.
 	PluginSpecsFile string `json:"plugin_specs_file"`
@@ -55,7 +237,10 @@ type Config struct {
 	disabledComponents map[string]any `json:"-"`
 }
 
-var ErrInvalidAutoUpdateExitCode = errors.New("auto_update_exit_code is only valid when auto_update is enabled")
+var (
+	ErrInvalidAutoUpdateExitCode      = errors.New("auto_update_exit_code is only valid when auto_update is enabled")
+	ErrMetricsArchiveUploadWithoutDir = errors.New("metrics_archive_upload_destination is only valid when metrics_archive_dir is set")
+)
 
 func (config *Config) Validate() error {
 	if config.Address == "" {
@@ -67,10 +252,76 @@ func (config *Config) Validate() error {
 	if !config.EnableAutoUpdate && config.AutoUpdateExitCode != -1 {
 		return ErrInvalidAutoUpdateExitCode
 	}
+	if config.MetricsArchiveUploadDestination != "" && config.MetricsArchiveDir == "" {
+		return ErrMetricsArchiveUploadWithoutDir
+	}
+	if config.DevMode != nil {
+		if err := config.DevMode.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := toolpath.Validate(config.ToolPaths); err != nil {
+		return err
+	}
+	if config.BaselineLearningPeriod.Duration < 0 {
+		return fmt.Errorf("baseline_learning_period must be non-negative, got %s", config.BaselineLearningPeriod.Duration)
+	}
+	if config.GDSProbe != nil {
+		if err := config.GDSProbe.Validate(); err != nil {
+			return fmt.Errorf("gds_probe: %w", err)
+		}
+	}
+	if config.CheckLoadPolicy != nil {
+		if err := config.CheckLoadPolicy.Validate(); err != nil {
+			return fmt.Errorf("check_load_policy: %w", err)
+		}
+	}
+	if config.MaintenanceDeferral != nil {
+		if err := config.MaintenanceDeferral.Validate(); err != nil {
+			return fmt.Errorf("maintenance_deferral: %w", err)
+		}
+	}
+	for component, l := range config.ToolResourceLimits {
+		if err := l.Validate(); err != nil {
+			return fmt.Errorf("tool_resource_limits of component %q: %w", component, err)
+		}
+	}
+	if err := config.CheckIntervals().Validate(); err != nil {
+		return fmt.Errorf("component_intervals: %w", err)
+	}
+	if err := config.CheckBudgets().Validate(); err != nil {
+		return fmt.Errorf("component_check_budgets: %w", err)
+	}
 
 	return nil
 }
 
+// CheckIntervals returns the check intervals per component,
+// or nil if not configured.
+func (config *Config) CheckIntervals() components.CheckIntervals {
+	if len(config.ComponentIntervals) == 0 {
+		return nil
+	}
+	ci := make(components.CheckIntervals, len(config.ComponentIntervals))
+	for name, d := range config.ComponentIntervals {
+		ci[name] = d.Duration
+	}
+	return ci
+}
+
+// CheckBudgets returns the check budgets per component,
+// or nil if not configured.
+func (config *Config) CheckBudgets() components.CheckBudgets {
+	if len(config.ComponentCheckBudgets) == 0 {
+		return nil
+	}
+	cb := make(components.CheckBudgets, len(config.ComponentCheckBudgets))
+	for name, d := range config.ComponentCheckBudgets {
+		cb[name] = d.Duration
+	}
+	return cb
+}
+
 // ShouldEnable returns true if the component should be enabled.
 // If the enable component sets are not specified, it will return true,
 // meaning it should be enabled by default.
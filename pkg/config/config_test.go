@@ -1,10 +1,17 @@
 package config
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/leptonai/gpud/components"
+	pkgmaintenance "github.com/leptonai/gpud/pkg/maintenance"
+	querygds "github.com/leptonai/gpud/pkg/nvidia-query/gds"
+	"github.com/leptonai/gpud/pkg/toolexec"
+	"github.com/leptonai/gpud/pkg/toolpath"
 )
 
 func TestConfigValidate_AutoUpdateExitCode(t *testing.T) {
@@ -57,6 +64,165 @@ func TestConfigValidate_AutoUpdateExitCode(t *testing.T) {
 	}
 }
 
+func TestConfigValidate_MetricsArchive(t *testing.T) {
+	cfg := &Config{
+		Address:                         "localhost:15132",
+		RetentionPeriod:                 metav1.Duration{Duration: time.Hour},
+		AutoUpdateExitCode:              -1,
+		MetricsArchiveUploadDestination: "s3://bucket/prefix",
+	}
+	if err := cfg.Validate(); err != ErrMetricsArchiveUploadWithoutDir {
+		t.Errorf("Config.Validate() error = %v, want %v", err, ErrMetricsArchiveUploadWithoutDir)
+	}
+
+	cfg.MetricsArchiveDir = "/var/lib/gpud/archive"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Config.Validate() error = %v, want nil", err)
+	}
+}
+
+func TestConfigValidate_ToolPaths(t *testing.T) {
+	cfg := &Config{
+		Address:            "localhost:15132",
+		RetentionPeriod:    metav1.Duration{Duration: time.Hour},
+		AutoUpdateExitCode: -1,
+		ToolPaths:          map[string]string{"unknown": "/opt/bin/unknown"},
+	}
+	if err := cfg.Validate(); !errors.Is(err, toolpath.ErrUnknownTool) {
+		t.Errorf("Config.Validate() error = %v, want %v", err, toolpath.ErrUnknownTool)
+	}
+
+	cfg.ToolPaths = map[string]string{"ibstat": "/opt/bin/ibstat"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Config.Validate() error = %v, want nil", err)
+	}
+}
+
+func TestConfigValidate_CheckLoadPolicy(t *testing.T) {
+	cfg := &Config{
+		Address:            "localhost:15132",
+		RetentionPeriod:    metav1.Duration{Duration: time.Hour},
+		AutoUpdateExitCode: -1,
+		CheckLoadPolicy:    &components.LoadPolicy{CPUThresholdPercent: 120},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Config.Validate() error = nil, want error")
+	}
+
+	cfg.CheckLoadPolicy = &components.LoadPolicy{CPUThresholdPercent: 80, GPUThresholdPercent: 90}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Config.Validate() error = %v, want nil", err)
+	}
+}
+
+func TestConfigValidate_BaselineLearningPeriod(t *testing.T) {
+	cfg := &Config{
+		Address:                "localhost:15132",
+		RetentionPeriod:        metav1.Duration{Duration: time.Hour},
+		AutoUpdateExitCode:     -1,
+		BaselineLearningPeriod: metav1.Duration{Duration: -time.Hour},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Config.Validate() error = nil, want error")
+	}
+
+	cfg.BaselineLearningPeriod = metav1.Duration{Duration: 24 * time.Hour}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Config.Validate() error = %v, want nil", err)
+	}
+}
+
+func TestConfigValidate_GDSProbe(t *testing.T) {
+	cfg := &Config{
+		Address:            "localhost:15132",
+		RetentionPeriod:    metav1.Duration{Duration: time.Hour},
+		AutoUpdateExitCode: -1,
+		GDSProbe:           &querygds.ProbeConfig{Dir: "/data", MinThroughputGiBps: -1},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Config.Validate() error = nil, want error")
+	}
+
+	cfg.GDSProbe.MinThroughputGiBps = 2
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Config.Validate() error = %v, want nil", err)
+	}
+}
+
+func TestConfigValidate_MaintenanceDeferral(t *testing.T) {
+	cfg := &Config{
+		Address:             "localhost:15132",
+		RetentionPeriod:     metav1.Duration{Duration: time.Hour},
+		AutoUpdateExitCode:  -1,
+		MaintenanceDeferral: &pkgmaintenance.Policy{},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Config.Validate() error = nil, want error")
+	}
+
+	cfg.MaintenanceDeferral.Kubelet = true
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Config.Validate() error = %v, want nil", err)
+	}
+}
+
+func TestConfigValidate_ToolResourceLimits(t *testing.T) {
+	cfg := &Config{
+		Address:            "localhost:15132",
+		RetentionPeriod:    metav1.Duration{Duration: time.Hour},
+		AutoUpdateExitCode: -1,
+		ToolResourceLimits: map[string]toolexec.ResourceLimits{"disk": {CPUs: -1}},
+	}
+	if err := cfg.Validate(); !errors.Is(err, toolexec.ErrInvalidResourceLimits) {
+		t.Errorf("Config.Validate() error = %v, want %v", err, toolexec.ErrInvalidResourceLimits)
+	}
+
+	cfg.ToolResourceLimits = map[string]toolexec.ResourceLimits{"disk": {CPUs: 0.5, MemoryMaxBytes: 512 * 1024 * 1024}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Config.Validate() error = %v, want nil", err)
+	}
+}
+
+func TestConfigValidate_ComponentIntervals(t *testing.T) {
+	cfg := &Config{
+		Address:            "localhost:15132",
+		RetentionPeriod:    metav1.Duration{Duration: time.Hour},
+		AutoUpdateExitCode: -1,
+		ComponentIntervals: map[string]metav1.Duration{"disk": {Duration: time.Millisecond}},
+	}
+	if err := cfg.Validate(); !errors.Is(err, components.ErrInvalidCheckInterval) {
+		t.Errorf("Config.Validate() error = %v, want %v", err, components.ErrInvalidCheckInterval)
+	}
+
+	cfg.ComponentIntervals = map[string]metav1.Duration{"disk": {Duration: 5 * time.Minute}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Config.Validate() error = %v, want nil", err)
+	}
+	if got := cfg.CheckIntervals().Get("disk"); got != 5*time.Minute {
+		t.Errorf("CheckIntervals().Get(disk) = %v, want %v", got, 5*time.Minute)
+	}
+}
+
+func TestConfigValidate_ComponentCheckBudgets(t *testing.T) {
+	cfg := &Config{
+		Address:               "localhost:15132",
+		RetentionPeriod:       metav1.Duration{Duration: time.Hour},
+		AutoUpdateExitCode:    -1,
+		ComponentCheckBudgets: map[string]metav1.Duration{"disk": {Duration: 0}},
+	}
+	if err := cfg.Validate(); !errors.Is(err, components.ErrInvalidCheckBudget) {
+		t.Errorf("Config.Validate() error = %v, want %v", err, components.ErrInvalidCheckBudget)
+	}
+
+	cfg.ComponentCheckBudgets = map[string]metav1.Duration{"disk": {Duration: time.Minute}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Config.Validate() error = %v, want nil", err)
+	}
+	if got := cfg.CheckBudgets()["disk"]; got != time.Minute {
+		t.Errorf("CheckBudgets()[disk] = %v, want %v", got, time.Minute)
+	}
+}
+
 func TestConfig_ShouldEnable(t *testing.T) {
 	tests := []struct {
 		name             string
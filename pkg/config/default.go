@@ -9,8 +9,6 @@ import (
 
 	"github.com/mitchellh/go-homedir"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-
-	nvidiacommon "github.com/leptonai/gpud/pkg/config/common"
 )
 
 const (
@@ -43,10 +41,7 @@ func DefaultConfig(ctx context.Context, opts ...OpOption) (*Config, error) {
 		CompactPeriod:    DefaultCompactPeriod,
 		Pprof:            false,
 		EnableAutoUpdate: true,
-		NvidiaToolOverwrites: nvidiacommon.ToolOverwrites{
-			IbstatCommand:   options.IbstatCommand,
-			IbstatusCommand: options.IbstatusCommand,
-		},
+		ToolPaths:        options.ToolPaths,
 	}
 
 	if cfg.State == "" {
@@ -98,3 +93,13 @@ func DefaultFifoFile() (string, error) {
 	}
 	return filepath.Join(f, "gpud.fifo"), nil
 }
+
+// DefaultKeyBundlePinFile is the file pinning the latest signing key bundle
+// seen by the package updates (see distsign.KeyBundlePin).
+func DefaultKeyBundlePinFile() (string, error) {
+	dir, err := setupDefaultDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "distsign.pub.pin"), nil
+}
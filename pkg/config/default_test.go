@@ -29,14 +29,12 @@ func TestDefaultConfig(t *testing.T) {
 	})
 
 	t.Run("with options", func(t *testing.T) {
-		ibstatCmd := "/custom/ibstat"
-		ibstatusCmd := "/custom/ibstatus"
+		toolPaths := map[string]string{"ibstat": "/custom/ibstat", "ibstatus": "/custom/ibstatus"}
 
-		cfg, err := DefaultConfig(ctx, WithIbstatCommand(ibstatCmd), WithIbstatusCommand(ibstatusCmd))
+		cfg, err := DefaultConfig(ctx, WithToolPaths(toolPaths))
 		require.NoError(t, err)
 
-		assert.Equal(t, ibstatCmd, cfg.NvidiaToolOverwrites.IbstatCommand)
-		assert.Equal(t, ibstatusCmd, cfg.NvidiaToolOverwrites.IbstatusCommand)
+		assert.Equal(t, toolPaths, cfg.ToolPaths)
 	})
 }
 
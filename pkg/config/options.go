@@ -1,11 +1,7 @@
 package config
 
-import (
-	nvidia_common "github.com/leptonai/gpud/pkg/config/common"
-)
-
 type Op struct {
-	nvidia_common.ToolOverwrites
+	ToolPaths map[string]string
 }
 
 type OpOption func(*Op)
@@ -14,27 +10,13 @@ func (op *Op) ApplyOpts(opts []OpOption) error {
 	for _, opt := range opts {
 		opt(op)
 	}
-
-	if op.IbstatCommand == "" {
-		op.IbstatCommand = "ibstat"
-	}
-	if op.IbstatusCommand == "" {
-		op.IbstatusCommand = "ibstatus"
-	}
-
 	return nil
 }
 
-// Specifies the ibstat binary path to overwrite the default path.
-func WithIbstatCommand(p string) OpOption {
-	return func(op *Op) {
-		op.IbstatCommand = p
-	}
-}
-
-// Specifies the ibstat binary path to overwrite the default path.
-func WithIbstatusCommand(p string) OpOption {
+// Specifies the paths of the external tools (e.g., "ibstat") to run
+// instead of auto-discovering them.
+func WithToolPaths(m map[string]string) OpOption {
 	return func(op *Op) {
-		op.IbstatusCommand = p
+		op.ToolPaths = m
 	}
 }
@@ -6,7 +6,6 @@ import (
 	"net"
 	"net/url"
 	"os"
-	"os/exec"
 	"regexp"
 	"sort"
 	"time"
@@ -19,8 +18,9 @@ import (
 	"google.golang.org/grpc/status"
 	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
 
-	pkgfile "github.com/leptonai/gpud/pkg/file"
 	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/toolexec"
+	"github.com/leptonai/gpud/pkg/toolpath"
 )
 
 const (
@@ -160,7 +160,7 @@ func createClient(ctx context.Context, conn *grpc.ClientConn) (runtimeapi.Runtim
 }
 
 func CheckContainerdInstalled() bool {
-	p, err := pkgfile.LocateExecutable("containerd")
+	p, err := toolpath.Locate("containerd")
 	if err == nil {
 		log.Logger.Debugw("containerd found in PATH", "path", p)
 		return true
@@ -230,16 +230,16 @@ func GetVersion(ctx context.Context, endpoint string) (string, error) {
 // e.g.,
 // "containerd containerd.io 1.7.25 bcc810d6b9066471b0b6fa75f557a15a1cbf31bb"
 func GetVersionFromCli(ctx context.Context) (string, error) {
-	containerdPath, err := pkgfile.LocateExecutable("containerd")
+	containerdPath, err := toolpath.Locate("containerd")
 	if err != nil {
 		return "", err
 	}
 
-	out, err := exec.CommandContext(ctx, containerdPath, "--version").Output()
+	res, err := toolexec.Run(ctx, []string{containerdPath, "--version"}, toolexec.WithSeparateStderr())
 	if err != nil {
 		return "", err
 	}
-	return parseContainerdVersion(string(out))
+	return parseContainerdVersion(string(res.Output))
 }
 
 // only matches "1.7.25" when "containerd containerd.io 1.7.25 bcc810d6b9066471b0b6fa75f557a15a1cbf31bb"
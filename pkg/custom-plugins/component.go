@@ -13,6 +13,7 @@ import (
 	"github.com/leptonai/gpud/components"
 	"github.com/leptonai/gpud/pkg/log"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	"github.com/leptonai/gpud/pkg/toolexec"
 )
 
 // NewInitFunc creates a new component initializer for the given plugin spec.
@@ -26,7 +27,8 @@ func (spec *Spec) NewInitFunc() components.InitFunc {
 			return nil, err
 		}
 
-		cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+		// caps the plugin scripts with the resource limits of the plugin component
+		cctx, ccancel := context.WithCancel(toolexec.WithComponent(gpudInstance.RootCtx, spec.ComponentName()))
 		c := &component{
 			ctx:               cctx,
 			cancel:            ccancel,
@@ -100,7 +102,7 @@ func (c *component) Start() error {
 	itv := c.spec.Interval.Duration
 	// either periodic check is disabled or interval is too short
 	if itv < time.Second {
-		_ = c.Check()
+		_ = components.SafeCheck(c)
 		return nil
 	}
 
@@ -109,7 +111,7 @@ func (c *component) Start() error {
 		defer ticker.Stop()
 
 		for {
-			_ = c.Check()
+			_ = components.SafeCheck(c)
 
 			select {
 			case <-c.ctx.Done():
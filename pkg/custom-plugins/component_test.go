@@ -17,6 +17,7 @@ import (
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/toolexec"
 )
 
 func TestNewInitFunc(t *testing.T) {
@@ -50,6 +51,9 @@ func TestNewInitFunc(t *testing.T) {
 
 	// Verify the component has the correct name
 	assert.Equal(t, ConvertToComponentName(spec.PluginName), comp.Name())
+
+	// the plugin scripts are capped by the resource limits of the plugin component
+	assert.Equal(t, comp.Name(), toolexec.ComponentFromContext(comp.(*component).ctx))
 }
 
 func TestComponent_Name(t *testing.T) {
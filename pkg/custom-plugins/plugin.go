@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/leptonai/gpud/pkg/process"
+	"github.com/leptonai/gpud/pkg/toolexec"
 )
 
 // Validate validates all the plugin steps.
@@ -22,7 +22,7 @@ func (p *Plugin) executeAllSteps(ctx context.Context) ([]byte, int32, error) {
 	// one shared runner for all the steps in this plugin
 	// run them in sequence, one by one
 	// this is to avoid running multiple commands in parallel
-	processRunner := process.NewExclusiveRunner()
+	processRunner := toolexec.NewExclusiveRunner()
 
 	var err error
 	output := make([]byte, 0)
@@ -6,7 +6,7 @@ import (
 	"testing"
 	"time"
 
-	"github.com/leptonai/gpud/pkg/process"
+	"github.com/leptonai/gpud/pkg/toolexec"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -156,7 +156,7 @@ func TestRunBashScript(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	out, exitCode, err := simpleScript.executeBash(ctx, process.NewExclusiveRunner())
+	out, exitCode, err := simpleScript.executeBash(ctx, toolexec.NewExclusiveRunner())
 	assert.NoError(t, err)
 	assert.Equal(t, "Hello, World!\n", string(out), "Script should output 'Hello, World!'")
 	assert.Equal(t, int32(0), exitCode, "Script should exit with code 0")
@@ -54,9 +54,13 @@ func LoadSpecs(path string) (Specs, error) {
 	if err != nil {
 		return nil, err
 	}
+	return ParseSpecs(yamlFile)
+}
 
+// ParseSpecs parses and validates the plugin specs from the YAML content.
+func ParseSpecs(b []byte) (Specs, error) {
 	var pluginSpecs Specs
-	if err := yaml.Unmarshal(yamlFile, &pluginSpecs); err != nil {
+	if err := yaml.Unmarshal(b, &pluginSpecs); err != nil {
 		return nil, err
 	}
 
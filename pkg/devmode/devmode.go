@@ -0,0 +1,127 @@
+// Package devmode defines the dev mode configuration of the synthetic GPUs
+// and the failure scenarios, so that the full daemon and its API can run on
+// the machines without any GPU (e.g., the local development, the CI of
+// the downstream integrations).
+// The fake backends are implemented in the "fake" sub-package, so that
+// the daemon config does not depend on the components and the NVML mocks.
+package devmode
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// DefaultGPUs is the default number of the synthetic GPUs.
+	DefaultGPUs = 8
+	// DefaultProductName is the default product name of the synthetic GPUs.
+	DefaultProductName = "NVIDIA H100 80GB HBM3"
+)
+
+// Scenario is the failure injected into the fake backends.
+// The GPU failures are injected into the first GPU (index 0),
+// and the InfiniBand failures into the first port ("mlx5_0"),
+// or the second port ("mlx5_1") for the rate degradation
+// if the first port is already down.
+type Scenario string
+
+const (
+	// ScenarioECCUncorrectable reports the volatile uncorrectable ECC errors.
+	ScenarioECCUncorrectable Scenario = "ecc-uncorrectable"
+	// ScenarioRemappedRowsPending reports the pending row remapping,
+	// which requires the GPU reset.
+	ScenarioRemappedRowsPending Scenario = "remapped-rows-pending"
+	// ScenarioRemappedRowsFailed reports the failed row remapping.
+	ScenarioRemappedRowsFailed Scenario = "remapped-rows-failed"
+	// ScenarioHWSlowdown reports the hardware slowdown clock events.
+	ScenarioHWSlowdown Scenario = "hw-slowdown"
+	// ScenarioGPULost fails the GPU queries with the "GPU is lost" error
+	// (e.g., the GPU fell off the bus).
+	ScenarioGPULost Scenario = "gpu-lost"
+	// ScenarioIBPortDown reports the InfiniBand port down.
+	ScenarioIBPortDown Scenario = "ib-port-down"
+	// ScenarioIBRateDegraded reports the InfiniBand port up but at the lower rate.
+	ScenarioIBRateDegraded Scenario = "ib-rate-degraded"
+)
+
+var allScenarios = map[Scenario]struct{}{
+	ScenarioECCUncorrectable:    {},
+	ScenarioRemappedRowsPending: {},
+	ScenarioRemappedRowsFailed:  {},
+	ScenarioHWSlowdown:          {},
+	ScenarioGPULost:             {},
+	ScenarioIBPortDown:          {},
+	ScenarioIBRateDegraded:      {},
+}
+
+// AllScenarios returns the supported scenarios, sorted by name.
+func AllScenarios() []Scenario {
+	ss := make([]Scenario, 0, len(allScenarios))
+	for s := range allScenarios {
+		ss = append(ss, s)
+	}
+	sort.Slice(ss, func(i, j int) bool { return ss[i] < ss[j] })
+	return ss
+}
+
+// ParseScenarios parses the comma-separated scenarios.
+func ParseScenarios(s string) ([]Scenario, error) {
+	var ss []Scenario
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		sc := Scenario(field)
+		if _, ok := allScenarios[sc]; !ok {
+			return nil, fmt.Errorf("unknown dev mode scenario %q (supported: %v)", field, AllScenarios())
+		}
+		ss = append(ss, sc)
+	}
+	return ss, nil
+}
+
+// Config is the configuration of the fake backends.
+type Config struct {
+	// GPUs is the number of the synthetic GPUs.
+	GPUs int `json:"gpus"`
+	// ProductName is the product name of the synthetic GPUs
+	// (e.g., "NVIDIA H100 80GB HBM3"), which decides the
+	// product-specific checks (e.g., the expected InfiniBand ports).
+	ProductName string `json:"product_name"`
+	// Scenarios are the failures to inject.
+	Scenarios []Scenario `json:"scenarios,omitempty"`
+}
+
+var ErrNoGPU = errors.New("dev mode requires at least one GPU")
+
+// Validate validates the config, and sets the defaults.
+func (c *Config) Validate() error {
+	if c.GPUs == 0 {
+		c.GPUs = DefaultGPUs
+	}
+	if c.GPUs < 0 {
+		return ErrNoGPU
+	}
+	if c.ProductName == "" {
+		c.ProductName = DefaultProductName
+	}
+	for _, s := range c.Scenarios {
+		if _, ok := allScenarios[s]; !ok {
+			return fmt.Errorf("unknown dev mode scenario %q", s)
+		}
+	}
+	return nil
+}
+
+// Has returns true if the scenario is injected.
+func (c *Config) Has(s Scenario) bool {
+	for _, sc := range c.Scenarios {
+		if sc == s {
+			return true
+		}
+	}
+	return false
+}
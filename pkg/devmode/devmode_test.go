@@ -0,0 +1,43 @@
+package devmode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScenarios(t *testing.T) {
+	ss, err := ParseScenarios("")
+	require.NoError(t, err)
+	assert.Empty(t, ss)
+
+	ss, err = ParseScenarios(" hw-slowdown, ib-port-down ,")
+	require.NoError(t, err)
+	assert.Equal(t, []Scenario{ScenarioHWSlowdown, ScenarioIBPortDown}, ss)
+
+	_, err = ParseScenarios("hw-slowdown,unknown")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown")
+}
+
+func TestAllScenariosSorted(t *testing.T) {
+	ss := AllScenarios()
+	require.Len(t, ss, len(allScenarios))
+	for i := 1; i < len(ss); i++ {
+		assert.Less(t, ss[i-1], ss[i])
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := Config{}
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, DefaultGPUs, cfg.GPUs)
+	assert.Equal(t, DefaultProductName, cfg.ProductName)
+
+	cfg = Config{GPUs: -1}
+	assert.ErrorIs(t, cfg.Validate(), ErrNoGPU)
+
+	cfg = Config{Scenarios: []Scenario{"unknown"}}
+	assert.Error(t, cfg.Validate())
+}
@@ -0,0 +1,39 @@
+// Package fake implements the fake NVML and InfiniBand backends
+// for the dev mode configuration.
+package fake
+
+import (
+	componentsnvidiafallenoffbus "github.com/leptonai/gpud/components/accelerator/nvidia/fallen-off-bus"
+	"github.com/leptonai/gpud/pkg/devmode"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	nvmllib "github.com/leptonai/gpud/pkg/nvidia-query/nvml/lib"
+	nvmllibmock "github.com/leptonai/gpud/pkg/nvidia-query/nvml/lib/mock"
+)
+
+// skippedComponents are the components that check the host hardware directly
+// (e.g., the PCI sysfs) rather than via the fake backends,
+// thus would report the synthetic GPUs as missing.
+var skippedComponents = map[string]struct{}{
+	componentsnvidiafallenoffbus.Name: {},
+}
+
+// SkipComponent returns true if the component should not run in the dev mode.
+func SkipComponent(name string) bool {
+	_, ok := skippedComponents[name]
+	return ok
+}
+
+// NewNVMLInstance creates the NVML instance backed by the synthetic GPUs.
+func NewNVMLInstance(cfg devmode.Config) (nvidianvml.Instance, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	lib, err := nvmllib.New(
+		nvmllib.WithNVML(newNVML(cfg)),
+		nvmllib.WithPropertyExtractor(nvmllibmock.HasNvmlPropertyExtractor),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return nvidianvml.NewWithLibrary(lib)
+}
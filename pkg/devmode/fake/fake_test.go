@@ -0,0 +1,179 @@
+package fake
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/devmode"
+	"github.com/leptonai/gpud/pkg/nvidia-query/infiniband"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+)
+
+func TestNewNVMLInstanceHealthy(t *testing.T) {
+	inst, err := NewNVMLInstance(devmode.Config{GPUs: 4})
+	require.NoError(t, err)
+
+	assert.True(t, inst.NVMLExists())
+	assert.Contains(t, inst.ProductName(), "H100")
+
+	devs := inst.Devices()
+	require.Len(t, devs, 4)
+	for i := 0; i < 4; i++ {
+		uuid := fakeUUID(i)
+		dev, ok := devs[uuid]
+		require.True(t, ok, "missing %s", uuid)
+
+		mem, err := nvidianvml.GetMemory(uuid, dev)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(fakeMemoryTotalBytes), mem.TotalBytes)
+
+		rows, err := nvidianvml.GetRemappedRows(uuid, dev)
+		require.NoError(t, err)
+		assert.False(t, rows.RemappingPending)
+		assert.False(t, rows.RemappingFailed)
+
+		eccErrs, err := nvidianvml.GetECCErrors(uuid, dev, true)
+		require.NoError(t, err)
+		assert.Zero(t, eccErrs.Volatile.Total.Uncorrected)
+
+		clockEvents, err := nvidianvml.GetClockEvents(uuid, dev)
+		require.NoError(t, err)
+		assert.Empty(t, clockEvents.HWSlowdownReasons)
+
+		nvlink, err := nvidianvml.GetNVLink(uuid, dev)
+		require.NoError(t, err)
+		assert.Len(t, nvlink.States, fakeNVLinks)
+
+		_, err = nvidianvml.GetTemperature(uuid, dev)
+		require.NoError(t, err)
+		_, err = nvidianvml.GetPower(uuid, dev)
+		require.NoError(t, err)
+		_, err = nvidianvml.GetUtilization(uuid, dev)
+		require.NoError(t, err)
+		_, err = nvidianvml.GetClockSpeed(uuid, dev)
+		require.NoError(t, err)
+		_, err = nvidianvml.GetProcesses(uuid, dev)
+		require.NoError(t, err)
+		_, err = nvidianvml.GetPersistenceMode(uuid, dev)
+		require.NoError(t, err)
+		_, err = nvidianvml.GetGSPFirmwareMode(uuid, dev)
+		require.NoError(t, err)
+		_, err = nvidianvml.GetFan(uuid, dev)
+		require.NoError(t, err)
+	}
+}
+
+func TestNewNVMLInstanceScenarios(t *testing.T) {
+	inst, err := NewNVMLInstance(devmode.Config{
+		GPUs:      2,
+		Scenarios: []devmode.Scenario{devmode.ScenarioECCUncorrectable, devmode.ScenarioRemappedRowsPending, devmode.ScenarioHWSlowdown},
+	})
+	require.NoError(t, err)
+
+	devs := inst.Devices()
+
+	// failures are injected into the first GPU only
+	uuid := fakeUUID(0)
+	dev := devs[uuid]
+
+	eccErrs, err := nvidianvml.GetECCErrors(uuid, dev, true)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(fakeUncorrectableECCErrors), eccErrs.Volatile.Total.Uncorrected)
+
+	rows, err := nvidianvml.GetRemappedRows(uuid, dev)
+	require.NoError(t, err)
+	assert.True(t, rows.RemappingPending)
+	assert.Equal(t, fakeRemappedRows, rows.RemappedDueToUncorrectableErrors)
+
+	clockEvents, err := nvidianvml.GetClockEvents(uuid, dev)
+	require.NoError(t, err)
+	assert.NotEmpty(t, clockEvents.HWSlowdownReasons)
+
+	uuid = fakeUUID(1)
+	rows, err = nvidianvml.GetRemappedRows(uuid, devs[uuid])
+	require.NoError(t, err)
+	assert.False(t, rows.RemappingPending)
+}
+
+func TestNewNVMLInstanceGPULost(t *testing.T) {
+	inst, err := NewNVMLInstance(devmode.Config{GPUs: 2, Scenarios: []devmode.Scenario{devmode.ScenarioGPULost}})
+	require.NoError(t, err)
+
+	devs := inst.Devices()
+	require.Len(t, devs, 2)
+
+	uuid := fakeUUID(0)
+	_, err = nvidianvml.GetMemory(uuid, devs[uuid])
+	assert.ErrorIs(t, err, nvidianvml.ErrGPULost)
+
+	uuid = fakeUUID(1)
+	_, err = nvidianvml.GetMemory(uuid, devs[uuid])
+	assert.NoError(t, err)
+}
+
+func TestInfinibandOutputs(t *testing.T) {
+	cards, err := infiniband.ParseIBStat(ibstatOutput(devmode.Config{ProductName: devmode.DefaultProductName}))
+	require.NoError(t, err)
+	require.Len(t, cards, fakeIBPorts)
+	for _, card := range cards {
+		assert.Equal(t, "Active", card.Port1.State)
+		assert.Equal(t, "LinkUp", card.Port1.PhysicalState)
+		assert.Equal(t, fakeIBRate, card.Port1.Rate)
+	}
+
+	cards, err = infiniband.ParseIBStat(ibstatOutput(devmode.Config{ProductName: devmode.DefaultProductName, Scenarios: []devmode.Scenario{devmode.ScenarioIBPortDown}}))
+	require.NoError(t, err)
+	assert.Equal(t, "Down", cards[0].Port1.State)
+	assert.Equal(t, "Active", cards[1].Port1.State)
+
+	cards, err = infiniband.ParseIBStat(ibstatOutput(devmode.Config{ProductName: devmode.DefaultProductName, Scenarios: []devmode.Scenario{devmode.ScenarioIBRateDegraded}}))
+	require.NoError(t, err)
+	assert.Equal(t, fakeIBDegradedRate, cards[0].Port1.Rate)
+
+	// both injected into the different ports
+	cards, err = infiniband.ParseIBStat(ibstatOutput(devmode.Config{ProductName: devmode.DefaultProductName, Scenarios: []devmode.Scenario{devmode.ScenarioIBRateDegraded, devmode.ScenarioIBPortDown}}))
+	require.NoError(t, err)
+	assert.Equal(t, "Down", cards[0].Port1.State)
+	assert.Equal(t, "Active", cards[1].Port1.State)
+	assert.Equal(t, fakeIBDegradedRate, cards[1].Port1.Rate)
+	assert.Equal(t, fakeIBRate, cards[2].Port1.Rate)
+
+	statuses, err := infiniband.ParseIBStatus(ibstatusOutput(devmode.Config{ProductName: devmode.DefaultProductName, Scenarios: []devmode.Scenario{devmode.ScenarioIBPortDown}}))
+	require.NoError(t, err)
+	require.Len(t, statuses, fakeIBPorts)
+	assert.Equal(t, "mlx5_0", statuses[0].Device)
+	assert.Contains(t, statuses[0].State, "DOWN")
+	assert.Contains(t, statuses[1].State, "ACTIVE")
+}
+
+func TestWriteInfinibandCommands(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "ib")
+	ibstatCmd, ibstatusCmd, err := WriteInfinibandCommands(dir, devmode.Config{})
+	require.NoError(t, err)
+
+	for _, cmd := range []string{ibstatCmd, ibstatusCmd} {
+		require.True(t, strings.HasPrefix(cmd, "cat "))
+		_, err := os.Stat(strings.TrimPrefix(cmd, "cat "))
+		require.NoError(t, err)
+	}
+}
+
+func TestSkipComponent(t *testing.T) {
+	assert.True(t, SkipComponent("accelerator-nvidia-fallen-off-bus"))
+	assert.False(t, SkipComponent("accelerator-nvidia-ecc"))
+}
+
+func TestExpectedInfinibandPortStates(t *testing.T) {
+	eps := ExpectedInfinibandPortStates(devmode.Config{ProductName: devmode.DefaultProductName})
+	assert.Equal(t, fakeIBPorts, eps.AtLeastPorts)
+	assert.Equal(t, fakeIBRate, eps.AtLeastRate)
+
+	// unknown products fall back to the defaults
+	eps = ExpectedInfinibandPortStates(devmode.Config{ProductName: "unknown"})
+	assert.Equal(t, fakeIBPorts, eps.AtLeastPorts)
+}
@@ -0,0 +1,130 @@
+package fake
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/leptonai/gpud/pkg/devmode"
+	"github.com/leptonai/gpud/pkg/nvidia-query/infiniband"
+)
+
+const (
+	fakeIBPorts = 8
+	fakeIBRate  = 400
+
+	fakeIBDegradedRate = 200
+)
+
+type fakeIBPort struct {
+	device        string
+	state         string
+	physicalState string
+	rate          int
+}
+
+// ExpectedInfinibandPortStates returns the expected InfiniBand ports and rate
+// of the synthetic GPUs, as the control plane would set for the product.
+func ExpectedInfinibandPortStates(cfg devmode.Config) infiniband.ExpectedPortStates {
+	if eps, err := infiniband.SupportsInfinibandPortRate(cfg.ProductName); err == nil {
+		return eps
+	}
+	return infiniband.ExpectedPortStates{AtLeastPorts: fakeIBPorts, AtLeastRate: fakeIBRate}
+}
+
+// fakeInfinibandPorts returns the synthetic InfiniBand ports, as expected for the product,
+// with the failures injected into the first port.
+// When both the port down and the rate degradation are injected,
+// the degraded rate goes to the next port, since a down port has no rate to degrade.
+func fakeInfinibandPorts(cfg devmode.Config) []fakeIBPort {
+	eps := ExpectedInfinibandPortStates(cfg)
+
+	ps := make([]fakeIBPort, eps.AtLeastPorts)
+	for i := range ps {
+		ps[i] = fakeIBPort{
+			device:        fmt.Sprintf("mlx5_%d", i),
+			state:         "Active",
+			physicalState: "LinkUp",
+			rate:          eps.AtLeastRate,
+		}
+	}
+	next := 0
+	if len(ps) > next && cfg.Has(devmode.ScenarioIBPortDown) {
+		ps[next].state = "Down"
+		ps[next].physicalState = "Disabled"
+		next++
+	}
+	if len(ps) > next && cfg.Has(devmode.ScenarioIBRateDegraded) {
+		ps[next].rate = fakeIBDegradedRate
+	}
+	return ps
+}
+
+// ibstatOutput returns the synthetic "ibstat" output.
+func ibstatOutput(cfg devmode.Config) string {
+	var sb strings.Builder
+	for i, p := range fakeInfinibandPorts(cfg) {
+		guid := fmt.Sprintf("0x946dae0300cd%04x", i)
+		fmt.Fprintf(&sb, "CA '%s'\n", p.device)
+		sb.WriteString("\tCA type: MT4129\n")
+		sb.WriteString("\tNumber of ports: 1\n")
+		sb.WriteString("\tFirmware version: 28.39.1002\n")
+		sb.WriteString("\tHardware version: 0\n")
+		fmt.Fprintf(&sb, "\tNode GUID: %s\n", guid)
+		fmt.Fprintf(&sb, "\tSystem image GUID: %s\n", guid)
+		sb.WriteString("\tPort 1:\n")
+		fmt.Fprintf(&sb, "\t\tState: %s\n", p.state)
+		fmt.Fprintf(&sb, "\t\tPhysical state: %s\n", p.physicalState)
+		fmt.Fprintf(&sb, "\t\tRate: %d\n", p.rate)
+		fmt.Fprintf(&sb, "\t\tBase lid: %d\n", 100+i)
+		sb.WriteString("\t\tLMC: 0\n")
+		sb.WriteString("\t\tSM lid: 1\n")
+		sb.WriteString("\t\tCapability mask: 0xa751e848\n")
+		fmt.Fprintf(&sb, "\t\tPort GUID: %s\n", guid)
+		sb.WriteString("\t\tLink layer: InfiniBand\n")
+	}
+	return sb.String()
+}
+
+// ibstatusOutput returns the synthetic "ibstatus" output.
+func ibstatusOutput(cfg devmode.Config) string {
+	var sb strings.Builder
+	for i, p := range fakeInfinibandPorts(cfg) {
+		state, physState := "4: ACTIVE", "5: LinkUp"
+		if p.state != "Active" {
+			state, physState = "1: DOWN", "3: Disabled"
+		}
+		fmt.Fprintf(&sb, "Infiniband device '%s' port 1 status:\n", p.device)
+		fmt.Fprintf(&sb, "        default gid:     fe80:0000:0000:0000:946d:ae03:00cd:%04x\n", i)
+		fmt.Fprintf(&sb, "        base lid:        0x%x\n", 100+i)
+		sb.WriteString("        sm lid:          0x1\n")
+		fmt.Fprintf(&sb, "        state:           %s\n", state)
+		fmt.Fprintf(&sb, "        phys state:      %s\n", physState)
+		fmt.Fprintf(&sb, "        rate:            %d Gb/sec (4X NDR)\n", p.rate)
+		sb.WriteString("        link_layer:      InfiniBand\n\n")
+	}
+	return sb.String()
+}
+
+// WriteInfinibandCommands writes the synthetic "ibstat" and "ibstatus" outputs
+// into the directory, and returns the commands to print them, to overwrite
+// the InfiniBand tool commands with.
+func WriteInfinibandCommands(dir string, cfg devmode.Config) (ibstatCommand string, ibstatusCommand string, err error) {
+	if err := cfg.Validate(); err != nil {
+		return "", "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", err
+	}
+
+	ibstatFile := filepath.Join(dir, "ibstat.txt")
+	if err := os.WriteFile(ibstatFile, []byte(ibstatOutput(cfg)), 0644); err != nil {
+		return "", "", err
+	}
+	ibstatusFile := filepath.Join(dir, "ibstatus.txt")
+	if err := os.WriteFile(ibstatusFile, []byte(ibstatusOutput(cfg)), 0644); err != nil {
+		return "", "", err
+	}
+	return "cat " + ibstatFile, "cat " + ibstatusFile, nil
+}
@@ -0,0 +1,194 @@
+package fake
+
+import (
+	"fmt"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	nvmlmock "github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+
+	"github.com/leptonai/gpud/pkg/devmode"
+)
+
+const (
+	fakeDriverVersion = "570.124.06"
+	fakeCUDAVersion   = 12080
+
+	fakeMemoryTotalBytes = 80 * 1024 * 1024 * 1024
+	fakeMemoryUsedBytes  = 1024 * 1024 * 1024
+
+	fakeNVLinks = 18
+
+	fakeUncorrectableECCErrors = 4
+	fakeRemappedRows           = 8
+)
+
+// newNVML creates the fake NVML library with the synthetic GPUs.
+func newNVML(cfg devmode.Config) nvml.Interface {
+	devs := make([]nvml.Device, cfg.GPUs)
+	for i := range devs {
+		devs[i] = newDevice(cfg, i)
+	}
+
+	return &nvmlmock.Interface{
+		InitFunc:     func() nvml.Return { return nvml.SUCCESS },
+		ShutdownFunc: func() nvml.Return { return nvml.SUCCESS },
+		ExtensionsFunc: func() nvml.ExtendedInterface {
+			return &nvmlmock.ExtendedInterface{
+				LookupSymbolFunc: func(symbol string) error { return nil },
+			}
+		},
+
+		SystemGetDriverVersionFunc:        func() (string, nvml.Return) { return fakeDriverVersion, nvml.SUCCESS },
+		SystemGetCudaDriverVersionFunc:    func() (int, nvml.Return) { return fakeCUDAVersion, nvml.SUCCESS },
+		SystemGetCudaDriverVersion_v2Func: func() (int, nvml.Return) { return fakeCUDAVersion, nvml.SUCCESS },
+
+		DeviceGetCountFunc: func() (int, nvml.Return) { return len(devs), nvml.SUCCESS },
+		DeviceGetHandleByIndexFunc: func(i int) (nvml.Device, nvml.Return) {
+			if i < 0 || i >= len(devs) {
+				return nil, nvml.ERROR_INVALID_ARGUMENT
+			}
+			return devs[i], nvml.SUCCESS
+		},
+	}
+}
+
+// fakeUUID returns the deterministic UUID of the synthetic GPU,
+// so that the GPUs are the same across the restarts.
+func fakeUUID(i int) string {
+	return fmt.Sprintf("GPU-00000000-0000-0000-0000-%012d", i)
+}
+
+// newDevice creates the synthetic GPU, with the failures injected into the first GPU.
+func newDevice(cfg devmode.Config, i int) nvml.Device {
+	faulty := i == 0
+	lost := faulty && cfg.Has(devmode.ScenarioGPULost)
+
+	// the identity queries succeed even if the GPU is lost,
+	// to load the NVML instance (as cached by the driver)
+	uuid := fakeUUID(i)
+	busID := fmt.Sprintf("0000:%02x:00.0", 0x18+i)
+	var pciInfo nvml.PciInfo
+	for j := 0; j < len(busID) && j < len(pciInfo.BusId)-1; j++ {
+		pciInfo.BusId[j] = int8(busID[j])
+	}
+
+	// lostOr returns the "GPU is lost" error if lost, otherwise success
+	lostOr := func() nvml.Return {
+		if lost {
+			return nvml.ERROR_GPU_IS_LOST
+		}
+		return nvml.SUCCESS
+	}
+
+	return &nvmlmock.Device{
+		GetNameFunc:        func() (string, nvml.Return) { return cfg.ProductName, nvml.SUCCESS },
+		GetUUIDFunc:        func() (string, nvml.Return) { return uuid, nvml.SUCCESS },
+		GetIndexFunc:       func() (int, nvml.Return) { return i, nvml.SUCCESS },
+		GetMinorNumberFunc: func() (int, nvml.Return) { return i, nvml.SUCCESS },
+		GetSerialFunc: func() (string, nvml.Return) {
+			return fmt.Sprintf("FAKE%08d", i), nvml.SUCCESS
+		},
+		GetBoardIdFunc:      func() (uint32, nvml.Return) { return uint32(0x100 + i), nvml.SUCCESS },
+		GetPciInfoFunc:      func() (nvml.PciInfo, nvml.Return) { return pciInfo, nvml.SUCCESS },
+		GetBrandFunc:        func() (nvml.BrandType, nvml.Return) { return nvml.BRAND_NVIDIA, nvml.SUCCESS },
+		GetArchitectureFunc: func() (nvml.DeviceArchitecture, nvml.Return) { return nvml.DEVICE_ARCH_HOPPER, nvml.SUCCESS },
+		GetCudaComputeCapabilityFunc: func() (int, int, nvml.Return) {
+			return 9, 0, nvml.SUCCESS
+		},
+		GetVirtualizationModeFunc: func() (nvml.GpuVirtualizationMode, nvml.Return) {
+			return nvml.GPU_VIRTUALIZATION_MODE_NONE, nvml.SUCCESS
+		},
+		GetActiveVgpusFunc: func() ([]nvml.VgpuInstance, nvml.Return) { return nil, nvml.ERROR_NOT_SUPPORTED },
+		GetMigModeFunc:     func() (int, int, nvml.Return) { return 0, 0, nvml.ERROR_NOT_SUPPORTED },
+		GpmQueryDeviceSupportFunc: func() (nvml.GpmSupport, nvml.Return) {
+			// not supported, since the GPM samples are queried from the real library
+			return nvml.GpmSupport{IsSupportedDevice: 0}, nvml.SUCCESS
+		},
+
+		GetPersistenceModeFunc: func() (nvml.EnableState, nvml.Return) { return nvml.FEATURE_ENABLED, lostOr() },
+		GetGspFirmwareModeFunc: func() (bool, bool, nvml.Return) { return true, true, lostOr() },
+		GetEccModeFunc: func() (nvml.EnableState, nvml.EnableState, nvml.Return) {
+			return nvml.FEATURE_ENABLED, nvml.FEATURE_ENABLED, lostOr()
+		},
+
+		GetMemoryInfoFunc: func() (nvml.Memory, nvml.Return) {
+			return nvml.Memory{Total: fakeMemoryTotalBytes, Used: fakeMemoryUsedBytes, Free: fakeMemoryTotalBytes - fakeMemoryUsedBytes}, lostOr()
+		},
+		GetMemoryInfo_v2Func: func() (nvml.Memory_v2, nvml.Return) {
+			return nvml.Memory_v2{Total: fakeMemoryTotalBytes, Used: fakeMemoryUsedBytes, Free: fakeMemoryTotalBytes - fakeMemoryUsedBytes}, lostOr()
+		},
+		GetUtilizationRatesFunc: func() (nvml.Utilization, nvml.Return) {
+			return nvml.Utilization{Gpu: 0, Memory: 0}, lostOr()
+		},
+		GetClockInfoFunc: func(clockType nvml.ClockType) (uint32, nvml.Return) {
+			if clockType == nvml.CLOCK_MEM {
+				return 2619, lostOr()
+			}
+			return 1980, lostOr()
+		},
+		GetCurrentClocksEventReasonsFunc: func() (uint64, nvml.Return) {
+			if faulty && cfg.Has(devmode.ScenarioHWSlowdown) {
+				return nvml.ClocksThrottleReasonHwSlowdown | nvml.ClocksThrottleReasonHwThermalSlowdown, lostOr()
+			}
+			return nvml.ClocksThrottleReasonGpuIdle, lostOr()
+		},
+
+		GetTemperatureFunc: func(sensor nvml.TemperatureSensors) (uint32, nvml.Return) { return 35, lostOr() },
+		GetTemperatureThresholdFunc: func(threshold nvml.TemperatureThresholds) (uint32, nvml.Return) {
+			switch threshold {
+			case nvml.TEMPERATURE_THRESHOLD_SHUTDOWN:
+				return 92, lostOr()
+			case nvml.TEMPERATURE_THRESHOLD_SLOWDOWN:
+				return 89, lostOr()
+			case nvml.TEMPERATURE_THRESHOLD_MEM_MAX:
+				return 95, lostOr()
+			default:
+				return 87, lostOr()
+			}
+		},
+		GetPowerUsageFunc:           func() (uint32, nvml.Return) { return 70000, lostOr() },
+		GetEnforcedPowerLimitFunc:   func() (uint32, nvml.Return) { return 700000, lostOr() },
+		GetPowerManagementLimitFunc: func() (uint32, nvml.Return) { return 700000, lostOr() },
+		GetNumFansFunc:              func() (int, nvml.Return) { return 0, nvml.ERROR_NOT_SUPPORTED },
+		GetFanSpeed_v2Func:          func(fan int) (uint32, nvml.Return) { return 0, nvml.ERROR_NOT_SUPPORTED },
+
+		GetTotalEccErrorsFunc: func(errType nvml.MemoryErrorType, counterType nvml.EccCounterType) (uint64, nvml.Return) {
+			if faulty && cfg.Has(devmode.ScenarioECCUncorrectable) && errType == nvml.MEMORY_ERROR_TYPE_UNCORRECTED {
+				return fakeUncorrectableECCErrors, lostOr()
+			}
+			return 0, lostOr()
+		},
+		GetPcieReplayCounterFunc: func() (int, nvml.Return) { return 0, lostOr() },
+		GetMemoryErrorCounterFunc: func(errType nvml.MemoryErrorType, counterType nvml.EccCounterType, location nvml.MemoryLocation) (uint64, nvml.Return) {
+			if faulty && cfg.Has(devmode.ScenarioECCUncorrectable) && errType == nvml.MEMORY_ERROR_TYPE_UNCORRECTED && location == nvml.MEMORY_LOCATION_DRAM {
+				return fakeUncorrectableECCErrors, lostOr()
+			}
+			return 0, lostOr()
+		},
+		GetRemappedRowsFunc: func() (int, int, bool, bool, nvml.Return) {
+			switch {
+			case faulty && cfg.Has(devmode.ScenarioRemappedRowsFailed):
+				return 0, fakeRemappedRows, false, true, lostOr()
+			case faulty && cfg.Has(devmode.ScenarioRemappedRowsPending):
+				return 0, fakeRemappedRows, true, false, lostOr()
+			default:
+				return 0, 0, false, false, lostOr()
+			}
+		},
+
+		GetNvLinkStateFunc: func(link int) (nvml.EnableState, nvml.Return) {
+			if link >= fakeNVLinks {
+				return nvml.FEATURE_DISABLED, nvml.ERROR_INVALID_ARGUMENT
+			}
+			return nvml.FEATURE_ENABLED, lostOr()
+		},
+		GetNvLinkErrorCounterFunc: func(link int, counter nvml.NvLinkErrorCounter) (uint64, nvml.Return) {
+			return 0, lostOr()
+		},
+
+		GetComputeRunningProcessesFunc: func() ([]nvml.ProcessInfo, nvml.Return) { return nil, lostOr() },
+		GetProcessUtilizationFunc: func(lastSeen uint64) ([]nvml.ProcessUtilizationSample, nvml.Return) {
+			return nil, lostOr()
+		},
+	}
+}
@@ -0,0 +1,152 @@
+// Package diagnose collects the support archive of the node (the events, health
+// states, metrics, kernel messages, tool outputs, config, and gpud logs) into a
+// single tar.gz file, with the tokens redacted, to attach to the support tickets.
+package diagnose
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/toolexec"
+)
+
+const (
+	// DefaultSince is the default time range of the collected events and metrics.
+	DefaultSince = 24 * time.Hour
+	// DefaultLogFile is the gpud log file of the systemd unit.
+	DefaultLogFile = "/var/log/gpud.log"
+	// DefaultMaxLogBytes is the default max size of the logs to collect.
+	DefaultMaxLogBytes = 16 * 1024 * 1024
+
+	// ErrorsFile is the file in the archive listing the sources
+	// that failed to collect.
+	ErrorsFile = "errors.txt"
+)
+
+// Source is a file of the support archive.
+type Source struct {
+	// Name is the file name in the archive (e.g., "events.json").
+	Name string
+	// Collect returns the file content. The partial content returned with
+	// the error (e.g., the output of the failed command) is still archived.
+	Collect func(ctx context.Context) ([]byte, error)
+}
+
+// Collect writes the support archive of the sources as tar.gz, with the files
+// under the directory of the archive name, and the tokens redacted.
+// The sources failed to collect are listed in ErrorsFile instead of failing
+// the archive. It returns the number of the archived files.
+func Collect(ctx context.Context, w io.Writer, dir string, now time.Time, sources []Source) (int, error) {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	add := func(name string, data []byte) error {
+		data = Redact(data)
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    path.Join(dir, name),
+			Mode:    0600,
+			Size:    int64(len(data)),
+			ModTime: now,
+		}); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	files := 0
+	var errs []string
+	for _, src := range sources {
+		log.Logger.Debugw("collecting", "file", src.Name)
+		data, err := src.Collect(ctx)
+		if err != nil {
+			log.Logger.Warnw("failed to collect", "file", src.Name, "error", err)
+			errs = append(errs, fmt.Sprintf("%s: %v", src.Name, err))
+		}
+		if len(data) == 0 {
+			continue
+		}
+		if err := add(src.Name, data); err != nil {
+			return files, fmt.Errorf("failed to write %q: %w", src.Name, err)
+		}
+		files++
+	}
+	if len(errs) > 0 {
+		if err := add(ErrorsFile, []byte(strings.Join(errs, "\n")+"\n")); err != nil {
+			return files, fmt.Errorf("failed to write %q: %w", ErrorsFile, err)
+		}
+		files++
+	}
+
+	if err := tw.Close(); err != nil {
+		return files, err
+	}
+	return files, gw.Close()
+}
+
+// JSONSource returns the source of the indented JSON of the value.
+func JSONSource(name string, get func(ctx context.Context) (any, error)) Source {
+	return Source{
+		Name: name,
+		Collect: func(ctx context.Context) ([]byte, error) {
+			v, err := get(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return json.MarshalIndent(v, "", "  ")
+		},
+	}
+}
+
+// CommandSource returns the source of the command output (e.g., "nvidia-smi -q").
+func CommandSource(name string, timeout time.Duration, command ...string) Source {
+	return Source{
+		Name: name,
+		Collect: func(ctx context.Context) ([]byte, error) {
+			rs, err := toolexec.Run(ctx, command, toolexec.WithTimeout(timeout))
+			if rs == nil {
+				return nil, err
+			}
+			return rs.Output, err
+		},
+	}
+}
+
+// FileTailSource returns the source of the last bytes of the file (e.g., the gpud log),
+// up to the max bytes.
+func FileTailSource(name string, file string, maxBytes int64) Source {
+	return Source{
+		Name: name,
+		Collect: func(ctx context.Context) ([]byte, error) {
+			return readTail(file, maxBytes)
+		},
+	}
+}
+
+func readTail(file string, maxBytes int64) ([]byte, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if fi.Size() > maxBytes {
+		if _, err := f.Seek(fi.Size()-maxBytes, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	return io.ReadAll(io.LimitReader(f, maxBytes))
+}
@@ -0,0 +1,73 @@
+package diagnose
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollect(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "gpud.log")
+	require.NoError(t, os.WriteFile(logFile, []byte("old line\nlatest line token=abc\n"), 0644))
+
+	sources := []Source{
+		JSONSource("states.json", func(ctx context.Context) (any, error) {
+			return map[string]string{"health": "Healthy"}, nil
+		}),
+		JSONSource("events.json", func(ctx context.Context) (any, error) {
+			return nil, errors.New("connection refused")
+		}),
+		{
+			Name: "partial.txt",
+			Collect: func(ctx context.Context) ([]byte, error) {
+				return []byte("partial output"), errors.New("exit status 1")
+			},
+		},
+		FileTailSource("gpud.log", logFile, 22),
+		FileTailSource("missing.log", filepath.Join(t.TempDir(), "missing.log"), DefaultMaxLogBytes),
+	}
+
+	buf := new(bytes.Buffer)
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	n, err := Collect(context.Background(), buf, "gpud-diagnose", now, sources)
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+
+	files := readArchive(t, buf)
+	assert.Len(t, files, 4)
+	assert.JSONEq(t, `{"health":"Healthy"}`, files["gpud-diagnose/states.json"])
+	assert.Equal(t, "partial output", files["gpud-diagnose/partial.txt"])
+	assert.Equal(t, "latest line token=REDACTED\n", files["gpud-diagnose/gpud.log"])
+	assert.Contains(t, files["gpud-diagnose/errors.txt"], "events.json: connection refused")
+	assert.Contains(t, files["gpud-diagnose/errors.txt"], "partial.txt: exit status 1")
+	assert.Contains(t, files["gpud-diagnose/errors.txt"], "missing.log: ")
+}
+
+func readArchive(t *testing.T, r io.Reader) map[string]string {
+	gr, err := gzip.NewReader(r)
+	require.NoError(t, err)
+	tr := tar.NewReader(gr)
+
+	files := make(map[string]string)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		b, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[hdr.Name] = string(b)
+	}
+	return files
+}
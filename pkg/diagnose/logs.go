@@ -0,0 +1,151 @@
+package diagnose
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LogSource returns the source of the gpud log lines since the start time,
+// from the rotated log files and then the current log file, up to the last max bytes.
+// The lines without the timestamp (e.g., the stack traces) follow the line before.
+func LogSource(name string, file string, start time.Time, maxBytes int64) Source {
+	return Source{
+		Name: name,
+		Collect: func(ctx context.Context) ([]byte, error) {
+			return readLogsSince(file, start, maxBytes)
+		},
+	}
+}
+
+func readLogsSince(file string, start time.Time, maxBytes int64) ([]byte, error) {
+	files, err := logFilesSince(file, start)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	var errs []error
+	for _, f := range files {
+		if err := filterLogSince(&buf, f, start); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	out := buf.Bytes()
+	if int64(len(out)) > maxBytes {
+		out = out[int64(len(out))-maxBytes:]
+		// not to start with the partial line
+		if i := bytes.IndexByte(out, '\n'); i >= 0 {
+			out = out[i+1:]
+		}
+	}
+	return out, errors.Join(errs...)
+}
+
+// logFilesSince returns the rotated log files (e.g., "gpud-2006-01-02T15-04-05.000.log.gz")
+// last written since the start time in the order of the rotation, and then the log file.
+func logFilesSince(file string, start time.Time) ([]string, error) {
+	if _, err := os.Stat(file); err != nil {
+		return nil, err
+	}
+
+	ext := filepath.Ext(file)
+	prefix := strings.TrimSuffix(filepath.Base(file), ext) + "-"
+	entries, err := os.ReadDir(filepath.Dir(file))
+	if err != nil {
+		return nil, err
+	}
+
+	type rotated struct {
+		path    string
+		modTime time.Time
+	}
+	var rs []rotated
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if !strings.HasSuffix(name, ext) && !strings.HasSuffix(name, ext+".gz") {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil || fi.ModTime().Before(start) {
+			continue
+		}
+		rs = append(rs, rotated{path: filepath.Join(filepath.Dir(file), name), modTime: fi.ModTime()})
+	}
+	sort.Slice(rs, func(i, j int) bool { return rs[i].modTime.Before(rs[j].modTime) })
+
+	files := make([]string, 0, len(rs)+1)
+	for _, r := range rs {
+		files = append(files, r.path)
+	}
+	return append(files, file), nil
+}
+
+// filterLogSince writes the log lines of the file since the start time.
+func filterLogSince(w io.Writer, file string, start time.Time) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(file, ".gz") {
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gr.Close()
+		r = gr
+	}
+
+	br := bufio.NewReader(r)
+	keep := false
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			if ts, ok := logLineTime(line); ok {
+				keep = !ts.Before(start)
+			}
+			if keep {
+				if _, werr := w.Write(line); werr != nil {
+					return werr
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// logLineTime returns the timestamp of the JSON log line (e.g., {"ts":"2006-01-02T15:04:05Z"}).
+func logLineTime(line []byte) (time.Time, bool) {
+	var entry struct {
+		TS string `json:"ts"`
+	}
+	if err := json.Unmarshal(line, &entry); err != nil || entry.TS == "" {
+		return time.Time{}, false
+	}
+	ts, err := time.Parse(time.RFC3339, entry.TS)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return ts, true
+}
@@ -0,0 +1,58 @@
+package diagnose
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadLogsSince(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	// rotated before the start, skipped
+	old := filepath.Join(dir, "gpud-2026-10-16T00-00-00.000.log.gz")
+	writeGzip(t, old, `{"level":"info","ts":"2026-10-16T00:00:00Z","msg":"too old"}`+"\n")
+	require.NoError(t, os.Chtimes(old, start.Add(-time.Hour), start.Add(-time.Hour)))
+
+	// rotated after the start, with the lines before and after the start
+	rotated := filepath.Join(dir, "gpud-2026-10-17T12-30-00.000.log.gz")
+	writeGzip(t, rotated, `{"level":"info","ts":"2026-10-17T11:59:00Z","msg":"before"}`+"\n"+
+		`{"level":"info","ts":"2026-10-17T12:10:00Z","msg":"rotated"}`+"\n")
+	require.NoError(t, os.Chtimes(rotated, start.Add(30*time.Minute), start.Add(30*time.Minute)))
+
+	file := filepath.Join(dir, "gpud.log")
+	require.NoError(t, os.WriteFile(file, []byte(
+		`{"level":"error","ts":"2026-10-17T12:40:00Z","msg":"current"}`+"\n"+
+			"goroutine 1 [running]:\n"), 0600))
+
+	out, err := readLogsSince(file, start, 1<<20)
+	require.NoError(t, err)
+	assert.Equal(t, `{"level":"info","ts":"2026-10-17T12:10:00Z","msg":"rotated"}`+"\n"+
+		`{"level":"error","ts":"2026-10-17T12:40:00Z","msg":"current"}`+"\n"+
+		"goroutine 1 [running]:\n", string(out))
+
+	// the last lines up to the max bytes
+	out, err = readLogsSince(file, start, 30)
+	require.NoError(t, err)
+	assert.Equal(t, "goroutine 1 [running]:\n", string(out))
+
+	_, err = readLogsSince(filepath.Join(dir, "missing.log"), start, 1<<20)
+	assert.Error(t, err)
+}
+
+func writeGzip(t *testing.T, file string, s string) {
+	f, err := os.Create(file)
+	require.NoError(t, err)
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	_, err = gw.Write([]byte(s))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+}
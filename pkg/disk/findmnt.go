@@ -7,52 +7,29 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/leptonai/gpud/pkg/file"
-	"github.com/leptonai/gpud/pkg/log"
-	"github.com/leptonai/gpud/pkg/process"
+	"github.com/leptonai/gpud/pkg/toolexec"
+	"github.com/leptonai/gpud/pkg/toolpath"
 
 	"github.com/dustin/go-humanize"
 )
 
 // Runs "findmnt --target [TARGET] --json --df" and parses the output.
 func FindMnt(ctx context.Context, target string) (*FindMntOutput, error) {
-	findmntPath, err := file.LocateExecutable("findmnt")
+	findmntPath, err := toolpath.Locate("findmnt")
 	if err != nil {
 		return nil, err
 	}
 
-	p, err := process.New(
-		process.WithCommand(fmt.Sprintf("%s --target %s --json --df", findmntPath, target)),
-		process.WithRunAsBashScript(),
-	)
+	res, err := toolexec.Run(ctx, []string{findmntPath, "--target", target, "--json", "--df"})
 	if err != nil {
-		return nil, err
-	}
-
-	if err := p.Start(ctx); err != nil {
-		return nil, err
-	}
-	defer func() {
-		if err := p.Close(ctx); err != nil {
-			log.Logger.Warnw("failed to abort command", "err", err)
+		var out string
+		if res != nil {
+			out = strings.TrimSpace(string(res.Output))
 		}
-	}()
-
-	lines := make([]string, 0)
-	if err := process.Read(
-		ctx,
-		p,
-		process.WithReadStdout(),
-		process.WithReadStderr(),
-		process.WithProcessLine(func(line string) {
-			lines = append(lines, line)
-		}),
-		process.WithWaitForCmd(),
-	); err != nil {
-		return nil, fmt.Errorf("failed to read findmnt output: %w (output: %s)", err, strings.Join(lines, "\n"))
+		return nil, fmt.Errorf("failed to read findmnt output: %w (output: %s)", err, out)
 	}
 
-	out, err := ParseFindMntOutput(strings.Join(lines, "\n"))
+	out, err := ParseFindMntOutput(string(res.Output))
 	if err != nil {
 		return nil, err
 	}
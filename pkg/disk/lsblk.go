@@ -32,9 +32,9 @@ import (
 	"github.com/dustin/go-humanize"
 	"github.com/olekukonko/tablewriter"
 
-	"github.com/leptonai/gpud/pkg/file"
 	"github.com/leptonai/gpud/pkg/log"
-	"github.com/leptonai/gpud/pkg/process"
+	"github.com/leptonai/gpud/pkg/toolexec"
+	"github.com/leptonai/gpud/pkg/toolpath"
 )
 
 type BlockDevices []BlockDevice
@@ -84,25 +84,12 @@ func getBlockDevicesWithLsblk(
 		flags, parseFunc = lsblkFlags+" "+lsblkJsonFlag, parseLsblkJSON
 	}
 
-	p, err := process.New(
-		process.WithCommand(lsblkBin+" "+flags),
-		process.WithRunAsBashScript(),
-	)
-	if err != nil {
-		return nil, err
-	}
-	defer func() {
-		if err := p.Close(ctx); err != nil {
-			log.Logger.Warnw("failed to abort command", "err", err)
-		}
-	}()
-
-	b, err := p.StartAndWaitForCombinedOutput(ctx)
+	res, err := toolexec.Run(ctx, append([]string{lsblkBin}, strings.Fields(flags)...))
 	if err != nil {
 		return nil, fmt.Errorf("failed to run lsblk command: %w", err)
 	}
 
-	return parseFunc(b, opts...)
+	return parseFunc(res.Output, opts...)
 }
 
 const (
@@ -140,43 +127,17 @@ func decideLsblkFlag(verOutput string) (string, func([]byte, ...OpOption) (Block
 
 // getLsblkBinPathAndVersion returns the "lsblk" executable path and the output of "lsblk --version".
 func getLsblkBinPathAndVersion(ctx context.Context) (string, string, error) {
-	lsblkBin, err := file.LocateExecutable("lsblk")
+	lsblkBin, err := toolpath.Locate("lsblk")
 	if err != nil {
 		return "", "", err
 	}
 
-	p, err := process.New(
-		process.WithCommand(lsblkBin+" "+lsblkVersionFlags),
-		process.WithRunAsBashScript(),
-	)
+	res, err := toolexec.Run(ctx, []string{lsblkBin, lsblkVersionFlags})
 	if err != nil {
-		return "", "", err
-	}
-
-	if err := p.Start(ctx); err != nil {
-		return "", "", err
-	}
-	defer func() {
-		if err := p.Close(ctx); err != nil {
-			log.Logger.Warnw("failed to abort command", "err", err)
-		}
-	}()
-
-	lines := make([]string, 0)
-	if err := process.Read(
-		ctx,
-		p,
-		process.WithReadStdout(),
-		process.WithReadStderr(),
-		process.WithProcessLine(func(line string) {
-			lines = append(lines, line)
-		}),
-		process.WithWaitForCmd(),
-	); err != nil {
 		return "", "", fmt.Errorf("failed to check lsblk version: %w", err)
 	}
 
-	line := strings.Join(lines, "\n")
+	line := string(res.Output)
 	line = strings.TrimSpace(line)
 
 	return lsblkBin, line, nil
@@ -0,0 +1,270 @@
+// Package disruption tracks the windows of the expected disruption declared by
+// the external schedulers (e.g., the infiniband fabric upgrade tonight from 2am
+// to 4am), so that the findings of the affected components inside the windows
+// are tagged as expected and not paged, while still recorded.
+package disruption
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+)
+
+// MaxWindowDuration is the longest window of the expected disruption,
+// not to silence the paging of the components indefinitely.
+const MaxWindowDuration = 7 * 24 * time.Hour
+
+var (
+	ErrInvalidWindow  = errors.New("invalid disruption window")
+	ErrWindowNotFound = errors.New("disruption window not found")
+)
+
+// ID returns the stable id of the window derived from its components and time range,
+// so that declaring the same window again does not duplicate it.
+func ID(w apiv1.DisruptionWindow) string {
+	h := sha256.New()
+	h.Write([]byte(strings.Join(w.Components, ",")))
+	h.Write([]byte{0})
+	h.Write([]byte(w.Start.UTC().Format(time.RFC3339)))
+	h.Write([]byte{0})
+	h.Write([]byte(w.End.UTC().Format(time.RFC3339)))
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// Validate validates the window declared at the time.
+func Validate(now time.Time, w apiv1.DisruptionWindow) error {
+	if len(w.Components) == 0 {
+		return fmt.Errorf("%w: no component", ErrInvalidWindow)
+	}
+	for _, pat := range w.Components {
+		if pat == "" {
+			return fmt.Errorf("%w: empty component pattern", ErrInvalidWindow)
+		}
+		if _, err := path.Match(pat, ""); err != nil {
+			return fmt.Errorf("%w: invalid component pattern %q", ErrInvalidWindow, pat)
+		}
+	}
+	if w.Start.IsZero() || w.End.IsZero() {
+		return fmt.Errorf("%w: start and end are required", ErrInvalidWindow)
+	}
+	if !w.End.After(w.Start.Time) {
+		return fmt.Errorf("%w: end %s not after start %s", ErrInvalidWindow, w.End.UTC().Format(time.RFC3339), w.Start.UTC().Format(time.RFC3339))
+	}
+	if d := w.End.Sub(w.Start.Time); d > MaxWindowDuration {
+		return fmt.Errorf("%w: duration %v exceeds %v", ErrInvalidWindow, d, MaxWindowDuration)
+	}
+	if !w.End.After(now) {
+		return fmt.Errorf("%w: already ended at %s", ErrInvalidWindow, w.End.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// Matches returns true if the component is expected to be disrupted at the time.
+func Matches(w apiv1.DisruptionWindow, component string, at time.Time) bool {
+	if at.Before(w.Start.Time) || !at.Before(w.End.Time) {
+		return false
+	}
+	for _, pat := range w.Components {
+		if ok, _ := path.Match(pat, component); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Windows tracks the declared windows until they end.
+// A nil *Windows is valid and expects no disruption.
+type Windows struct {
+	mu      sync.RWMutex
+	windows map[string]apiv1.DisruptionWindow
+}
+
+// New creates a new set of the windows.
+func New() *Windows {
+	return &Windows{windows: make(map[string]apiv1.DisruptionWindow)}
+}
+
+// PersistFunc persists the windows (e.g., to the metadata table) before they are applied.
+type PersistFunc func(windows []apiv1.DisruptionWindow) error
+
+// Add validates and declares the window, replacing the window of the same id,
+// and returns the declared window with its id.
+// If the persist function is not nil, the windows with the new window are
+// persisted first, and the window is declared only if persisted, so that the
+// in-memory windows never diverge from the persisted ones.
+func (ws *Windows) Add(now time.Time, w apiv1.DisruptionWindow, persist PersistFunc) (apiv1.DisruptionWindow, error) {
+	if err := Validate(now, w); err != nil {
+		return apiv1.DisruptionWindow{}, err
+	}
+	if w.ID == "" {
+		w.ID = ID(w)
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	ws.prune(now)
+	if persist != nil {
+		next := make(map[string]apiv1.DisruptionWindow, len(ws.windows)+1)
+		for id, existing := range ws.windows {
+			next[id] = existing
+		}
+		next[w.ID] = w
+		if err := persist(sorted(next)); err != nil {
+			return apiv1.DisruptionWindow{}, fmt.Errorf("failed to persist disruption windows: %w", err)
+		}
+	}
+	ws.windows[w.ID] = w
+	return w, nil
+}
+
+// Delete deletes the window, or returns ErrWindowNotFound.
+// If the persist function is not nil, the windows without the window are
+// persisted first, and the window is deleted only if persisted.
+func (ws *Windows) Delete(now time.Time, id string, persist PersistFunc) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	ws.prune(now)
+	if _, ok := ws.windows[id]; !ok {
+		return ErrWindowNotFound
+	}
+	if persist != nil {
+		next := make(map[string]apiv1.DisruptionWindow, len(ws.windows))
+		for wid, existing := range ws.windows {
+			if wid != id {
+				next[wid] = existing
+			}
+		}
+		if err := persist(sorted(next)); err != nil {
+			return fmt.Errorf("failed to persist disruption windows: %w", err)
+		}
+	}
+	delete(ws.windows, id)
+	return nil
+}
+
+// Set replaces all the windows (e.g., the windows persisted before the restart),
+// dropping the windows ended before the time.
+func (ws *Windows) Set(now time.Time, windows []apiv1.DisruptionWindow) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	ws.windows = make(map[string]apiv1.DisruptionWindow, len(windows))
+	for _, w := range windows {
+		if w.ID == "" {
+			w.ID = ID(w)
+		}
+		ws.windows[w.ID] = w
+	}
+	ws.prune(now)
+}
+
+// List returns the windows not ended before the time,
+// sorted by the start time and the id.
+func (ws *Windows) List(now time.Time) []apiv1.DisruptionWindow {
+	if ws == nil {
+		return nil
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	ws.prune(now)
+	return sorted(ws.windows)
+}
+
+// sorted returns the windows sorted by the start time and the id.
+func sorted(m map[string]apiv1.DisruptionWindow) []apiv1.DisruptionWindow {
+	windows := make([]apiv1.DisruptionWindow, 0, len(m))
+	for _, w := range m {
+		windows = append(windows, w)
+	}
+	sort.Slice(windows, func(i, j int) bool {
+		if !windows[i].Start.Equal(&windows[j].Start) {
+			return windows[i].Start.Before(&windows[j].Start)
+		}
+		return windows[i].ID < windows[j].ID
+	})
+	return windows
+}
+
+// Match returns the id of the window the component is expected
+// to be disrupted in at the time, empty if none.
+// The earliest started window is returned if multiple windows match.
+func (ws *Windows) Match(component string, at time.Time) string {
+	if ws == nil {
+		return ""
+	}
+
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	var matched *apiv1.DisruptionWindow
+	for _, w := range ws.windows {
+		if !Matches(w, component, at) {
+			continue
+		}
+		if matched == nil || w.Start.Before(&matched.Start) || (w.Start.Equal(&matched.Start) && w.ID < matched.ID) {
+			w := w
+			matched = &w
+		}
+	}
+	if matched == nil {
+		return ""
+	}
+	return matched.ID
+}
+
+// prune drops the windows ended before the time.
+// Must be called with the lock held.
+func (ws *Windows) prune(now time.Time) {
+	for id, w := range ws.windows {
+		if !w.End.After(now) {
+			delete(ws.windows, id)
+		}
+	}
+}
+
+// Read reads the windows persisted in the metadata table.
+// It returns nil if no window has been persisted.
+func Read(ctx context.Context, dbRO *sql.DB) ([]apiv1.DisruptionWindow, error) {
+	raw, err := pkgmetadata.ReadMetadata(ctx, dbRO, pkgmetadata.MetadataKeyDisruptionWindows)
+	if err != nil {
+		return nil, err
+	}
+	if raw == "" {
+		return nil, nil
+	}
+
+	var windows []apiv1.DisruptionWindow
+	if err := json.Unmarshal([]byte(raw), &windows); err != nil {
+		return nil, fmt.Errorf("failed to parse disruption windows: %w", err)
+	}
+	return windows, nil
+}
+
+// Save persists the windows to the metadata table,
+// so that the windows declared ahead survive the restarts.
+func Save(ctx context.Context, dbRW *sql.DB, windows []apiv1.DisruptionWindow) error {
+	if windows == nil {
+		windows = []apiv1.DisruptionWindow{}
+	}
+	b, err := json.Marshal(windows)
+	if err != nil {
+		return err
+	}
+	return pkgmetadata.SetMetadata(ctx, dbRW, pkgmetadata.MetadataKeyDisruptionWindows, string(b))
+}
@@ -0,0 +1,164 @@
+package disruption
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func window(components []string, start time.Time, d time.Duration) apiv1.DisruptionWindow {
+	return apiv1.DisruptionWindow{
+		Components: components,
+		Start:      metav1.NewTime(start),
+		End:        metav1.NewTime(start.Add(d)),
+		Reason:     "IB fabric upgrade",
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ib := []string{"accelerator-nvidia-infiniband"}
+
+	assert.NoError(t, Validate(now, window(ib, now.Add(2*time.Hour), 2*time.Hour)))
+	// the window in progress
+	assert.NoError(t, Validate(now, window(ib, now.Add(-time.Hour), 2*time.Hour)))
+
+	for _, w := range []apiv1.DisruptionWindow{
+		window(nil, now, time.Hour),
+		window([]string{""}, now, time.Hour),
+		window([]string{"["}, now, time.Hour),
+		window(ib, now, 0),
+		window(ib, now, -time.Hour),
+		window(ib, now, MaxWindowDuration+time.Hour),
+		window(ib, now.Add(-2*time.Hour), time.Hour),
+		{Components: ib},
+	} {
+		assert.ErrorIs(t, Validate(now, w), ErrInvalidWindow)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	start := time.Date(2026, 1, 1, 2, 0, 0, 0, time.UTC)
+	w := window([]string{"accelerator-nvidia-infiniband", "network-*"}, start, 2*time.Hour)
+
+	assert.True(t, Matches(w, "accelerator-nvidia-infiniband", start))
+	assert.True(t, Matches(w, "network-latency", start.Add(time.Hour)))
+	assert.False(t, Matches(w, "accelerator-nvidia-xid", start.Add(time.Hour)))
+	assert.False(t, Matches(w, "accelerator-nvidia-infiniband", start.Add(-time.Second)))
+	assert.False(t, Matches(w, "accelerator-nvidia-infiniband", start.Add(2*time.Hour)))
+}
+
+func TestWindows(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ws := New()
+
+	tonight, err := ws.Add(now, window([]string{"accelerator-nvidia-infiniband"}, now.Add(2*time.Hour), 2*time.Hour), nil)
+	require.NoError(t, err)
+	assert.Equal(t, ID(tonight), tonight.ID)
+
+	// declaring the same window again does not duplicate it
+	again, err := ws.Add(now, window([]string{"accelerator-nvidia-infiniband"}, now.Add(2*time.Hour), 2*time.Hour), nil)
+	require.NoError(t, err)
+	assert.Equal(t, tonight.ID, again.ID)
+
+	w := window([]string{"accelerator-nvidia-*"}, now.Add(3*time.Hour), 2*time.Hour)
+	w.ID = "driver-upgrade"
+	_, err = ws.Add(now, w, nil)
+	require.NoError(t, err)
+
+	_, err = ws.Add(now, window(nil, now, time.Hour), nil)
+	assert.ErrorIs(t, err, ErrInvalidWindow)
+
+	listed := ws.List(now)
+	require.Len(t, listed, 2)
+	assert.Equal(t, tonight.ID, listed[0].ID)
+	assert.Equal(t, "driver-upgrade", listed[1].ID)
+
+	assert.Empty(t, ws.Match("accelerator-nvidia-infiniband", now))
+	assert.Equal(t, tonight.ID, ws.Match("accelerator-nvidia-infiniband", now.Add(3*time.Hour)))
+	assert.Equal(t, "driver-upgrade", ws.Match("accelerator-nvidia-xid", now.Add(3*time.Hour)))
+	assert.Empty(t, ws.Match("disk", now.Add(3*time.Hour)))
+
+	// the ended windows are dropped
+	assert.Len(t, ws.List(now.Add(4*time.Hour)), 1)
+
+	require.NoError(t, ws.Delete(now.Add(4*time.Hour), "driver-upgrade", nil))
+	assert.ErrorIs(t, ws.Delete(now.Add(4*time.Hour), "driver-upgrade", nil), ErrWindowNotFound)
+	assert.Empty(t, ws.List(now.Add(4*time.Hour)))
+
+	ws.Set(now, []apiv1.DisruptionWindow{tonight, window([]string{"disk"}, now.Add(-2*time.Hour), time.Hour)})
+	assert.Equal(t, []apiv1.DisruptionWindow{tonight}, ws.List(now))
+
+	var nilWindows *Windows
+	assert.Nil(t, nilWindows.List(now))
+	assert.Empty(t, nilWindows.Match("accelerator-nvidia-infiniband", now))
+}
+
+func TestWindowsPersistFirst(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ws := New()
+
+	var persisted []apiv1.DisruptionWindow
+	persist := func(windows []apiv1.DisruptionWindow) error {
+		persisted = windows
+		return nil
+	}
+	errPersist := errors.New("disk full")
+	failPersist := func([]apiv1.DisruptionWindow) error { return errPersist }
+
+	w, err := ws.Add(now, window([]string{"accelerator-nvidia-infiniband"}, now.Add(time.Hour), time.Hour), persist)
+	require.NoError(t, err)
+	assert.Equal(t, []apiv1.DisruptionWindow{w}, persisted)
+
+	// the window not persisted is not declared
+	_, err = ws.Add(now, window([]string{"disk"}, now.Add(time.Hour), time.Hour), failPersist)
+	assert.ErrorIs(t, err, errPersist)
+	assert.Equal(t, []apiv1.DisruptionWindow{w}, ws.List(now))
+
+	// the window not persisted as deleted is not deleted
+	assert.ErrorIs(t, ws.Delete(now, w.ID, failPersist), errPersist)
+	assert.Equal(t, []apiv1.DisruptionWindow{w}, ws.List(now))
+
+	require.NoError(t, ws.Delete(now, w.ID, persist))
+	assert.Empty(t, persisted)
+	assert.Empty(t, ws.List(now))
+}
+
+func TestReadSave(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	require.NoError(t, pkgmetadata.CreateTableMetadata(ctx, dbRW))
+
+	windows, err := Read(ctx, dbRO)
+	require.NoError(t, err)
+	assert.Nil(t, windows)
+
+	start := time.Date(2026, 1, 1, 2, 0, 0, 0, time.UTC)
+	w := window([]string{"accelerator-nvidia-infiniband"}, start, 2*time.Hour)
+	w.ID = ID(w)
+	require.NoError(t, Save(ctx, dbRW, []apiv1.DisruptionWindow{w}))
+
+	windows, err = Read(ctx, dbRO)
+	require.NoError(t, err)
+	require.Len(t, windows, 1)
+	assert.Equal(t, w.ID, windows[0].ID)
+	assert.True(t, start.Equal(windows[0].Start.Time))
+
+	require.NoError(t, Save(ctx, dbRW, nil))
+	windows, err = Read(ctx, dbRO)
+	require.NoError(t, err)
+	assert.Empty(t, windows)
+}
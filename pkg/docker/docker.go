@@ -8,8 +8,8 @@ import (
 	dockerapitypescontainer "github.com/docker/docker/api/types/container"
 	dockerclient "github.com/docker/docker/client"
 
-	pkgfile "github.com/leptonai/gpud/pkg/file"
 	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/toolpath"
 )
 
 // ListContainers lists all containers from the docker daemon.
@@ -66,7 +66,7 @@ func convertToDockerContainer(resp dockerapitypes.Container) DockerContainer {
 }
 
 func CheckDockerInstalled() bool {
-	p, err := pkgfile.LocateExecutable("docker")
+	p, err := toolpath.Locate("docker")
 	if err == nil {
 		log.Logger.Debugw("docker found in PATH", "path", p)
 		return true
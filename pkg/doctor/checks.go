@@ -0,0 +1,379 @@
+package doctor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	procs "github.com/shirou/gopsutil/v4/process"
+	"golang.org/x/sys/unix"
+
+	"github.com/leptonai/gpud/pkg/file"
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/lsm"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	pkgmetricsstore "github.com/leptonai/gpud/pkg/metrics/store"
+	pkgmigrations "github.com/leptonai/gpud/pkg/migrations"
+	"github.com/leptonai/gpud/pkg/sqlite"
+	pkgsystemd "github.com/leptonai/gpud/pkg/systemd"
+)
+
+const (
+	CheckBinary        = "binary"
+	CheckSystemdUnit   = "systemd-unit"
+	CheckStateFile     = "state-file"
+	CheckPermissions   = "permissions"
+	CheckNVMLLibrary   = "nvml-library"
+	CheckKernelModules = "kernel-modules"
+	CheckPort          = "port"
+	CheckNonRoot       = "non-root"
+	CheckSecurity      = "security-modules"
+)
+
+const nvmlLibrary = "libnvidia-ml.so"
+
+// nvidiaKernelModule is the kernel module of the NVIDIA driver.
+const nvidiaKernelModule = "nvidia"
+
+func (op *Op) checkBinary(ctx context.Context) Result {
+	for i, p := range op.binPaths {
+		if _, err := os.Stat(p); err != nil {
+			continue
+		}
+		if i > 0 {
+			return warn(CheckBinary,
+				fmt.Sprintf("run 'gpud up' with the binary at %s to switch the systemd unit to the new path", op.binPaths[0]),
+				"gpud binary found at the deprecated path %s", p)
+		}
+		return ok(CheckBinary, "gpud binary found at %s", p)
+	}
+
+	hint := fmt.Sprintf("copy the gpud binary to %s", op.binPaths[0])
+	if exe, err := os.Executable(); err == nil {
+		hint = fmt.Sprintf("run 'cp %s %s'", exe, op.binPaths[0])
+	}
+	return fail(CheckBinary, hint, "gpud binary not found at %s", strings.Join(op.binPaths, ", "))
+}
+
+func (op *Op) hasSystemd() bool {
+	if op.systemdEnabled != nil {
+		return *op.systemdEnabled
+	}
+	return pkgsystemd.SystemctlExists()
+}
+
+func (op *Op) checkSystemdUnit(ctx context.Context) Result {
+	if !op.hasSystemd() {
+		return warn(CheckSystemdUnit, "run gpud with 'gpud run' under the process manager of the host", "systemd not found, skipped")
+	}
+
+	b, err := os.ReadFile(op.unitFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return fail(CheckSystemdUnit, "run 'gpud up' to install the systemd unit", "systemd unit not found at %s", op.unitFile)
+	}
+	if err != nil {
+		return fail(CheckSystemdUnit, "run 'gpud doctor' as root", "failed to read systemd unit %s: %v", op.unitFile, err)
+	}
+
+	if !bytes.Equal(bytes.TrimSpace(b), bytes.TrimSpace([]byte(op.unitContents))) {
+		return warn(CheckSystemdUnit,
+			"run 'gpud up' to rewrite the systemd unit, or ignore if the unit is customized on purpose",
+			"systemd unit %s differs from the unit shipped with this version", op.unitFile)
+	}
+	return ok(CheckSystemdUnit, "systemd unit %s is up to date", op.unitFile)
+}
+
+func (op *Op) checkStateFile(ctx context.Context) Result {
+	if _, err := os.Stat(op.stateFile); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fail(CheckStateFile, "start gpud with 'gpud up' or 'gpud run' to create the state file", "state file not found at %s", op.stateFile)
+		}
+		return fail(CheckStateFile, "run 'gpud doctor' as root", "failed to stat state file %s: %v", op.stateFile, err)
+	}
+
+	dbRO, err := sqlite.Open(op.stateFile, sqlite.WithReadOnly(true))
+	if err != nil {
+		return fail(CheckStateFile, "move the corrupted state file away and restart gpud to recreate it", "failed to open state file %s: %v", op.stateFile, err)
+	}
+	defer dbRO.Close()
+
+	cctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	version, err := sqlite.ReadSchemaVersion(cctx, dbRO)
+	if err != nil {
+		return fail(CheckStateFile, "move the corrupted state file away and restart gpud to recreate it", "failed to read schema version of %s: %v", op.stateFile, err)
+	}
+	latest := pkgmigrations.LatestVersion()
+	if version < latest {
+		return fail(CheckStateFile,
+			"restart gpud with this version to apply the schema migrations",
+			"state file %s has schema version %d (expected %d)", op.stateFile, version, latest)
+	}
+
+	if _, err := pkgmetadata.ReadMetadata(cctx, dbRO, pkgmetadata.MetadataKeyMachineID); err != nil {
+		return fail(CheckStateFile, "restart gpud to create the metadata table", "failed to read metadata from %s: %v", op.stateFile, err)
+	}
+
+	exists, err := sqlite.TableExists(cctx, dbRO, pkgmetricsstore.DefaultTableName)
+	if err != nil {
+		return fail(CheckStateFile, "move the corrupted state file away and restart gpud to recreate it", "failed to read schema of %s: %v", op.stateFile, err)
+	}
+	if !exists {
+		return fail(CheckStateFile,
+			"restart gpud with this version to create the tables (the state file was created by a different version)",
+			"table %s not found in %s", pkgmetricsstore.DefaultTableName, op.stateFile)
+	}
+	if version > latest {
+		return warn(CheckStateFile,
+			"upgrade gpud to the version that last migrated the state file, or ignore if rolled back on purpose",
+			"state file %s has schema version %d, newer than this version (%d)", op.stateFile, version, latest)
+	}
+	return ok(CheckStateFile, "state file %s has the expected schema version %d", op.stateFile, version)
+}
+
+func (op *Op) checkPermissions(ctx context.Context) Result {
+	dir := filepath.Dir(op.stateFile)
+	if err := unix.Access(dir, unix.W_OK); err != nil {
+		return fail(CheckPermissions, "run gpud as root, or fix the ownership of the directory", "state directory %s is not writable: %v", dir, err)
+	}
+	if _, err := os.Stat(op.stateFile); err == nil {
+		if err := unix.Access(op.stateFile, unix.R_OK|unix.W_OK); err != nil {
+			return fail(CheckPermissions, "run gpud as root, or fix the ownership of the state file", "state file %s is not readable and writable: %v", op.stateFile, err)
+		}
+	}
+	return ok(CheckPermissions, "state directory %s is writable", dir)
+}
+
+// daemonRoot returns whether the gpud daemon runs as root, and where it is
+// determined from: the running daemon process, the "User=" of the systemd unit
+// (including the drop-ins), or the current user if neither is found.
+func (op *Op) daemonRoot(ctx context.Context) (bool, string) {
+	if op.asRoot != nil {
+		return *op.asRoot, "override"
+	}
+
+	if pid, euid, err := op.findDaemonFunc(ctx); err != nil {
+		log.Logger.Warnw("failed to find running gpud daemon", "error", err)
+	} else if pid != 0 {
+		return euid == 0, fmt.Sprintf("running gpud daemon (pid %d, euid %d)", pid, euid)
+	}
+
+	if user, found := unitUser(op.unitFile); found {
+		return user == "" || user == "root" || user == "0", fmt.Sprintf("systemd unit %s (User=%s)", op.unitFile, user)
+	}
+
+	return os.Geteuid() == 0, "current user (gpud daemon not found)"
+}
+
+// unitUser returns the "User=" of the systemd unit, overridden by its drop-ins
+// (e.g., "gpud.service.d/*.conf"), and false if the unit file does not exist.
+// The empty user means root.
+func unitUser(unitFile string) (string, bool) {
+	if _, err := os.Stat(unitFile); err != nil {
+		return "", false
+	}
+
+	files := []string{unitFile}
+	dropIns, _ := filepath.Glob(unitFile + ".d/*.conf")
+	sort.Strings(dropIns)
+	files = append(files, dropIns...)
+
+	user := ""
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(b), "\n") {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(line), "User="); ok {
+				user = strings.TrimSpace(v)
+			}
+		}
+	}
+	return user, true
+}
+
+// findDaemon returns the pid and the effective user ID of the running
+// "gpud run" process, or zero pid if not running.
+func findDaemon(ctx context.Context) (int32, int, error) {
+	ps, err := procs.ProcessesWithContext(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	self := int32(os.Getpid())
+	for _, p := range ps {
+		if p.Pid == self {
+			continue
+		}
+		if name, err := p.NameWithContext(ctx); err != nil || name != "gpud" {
+			continue
+		}
+		args, err := p.CmdlineSliceWithContext(ctx)
+		if err != nil || len(args) < 2 || args[1] != "run" {
+			continue
+		}
+		uids, err := p.UidsWithContext(ctx)
+		if err != nil || len(uids) < 2 {
+			continue
+		}
+		// real, effective, saved, and filesystem user IDs
+		return p.Pid, int(uids[1]), nil
+	}
+	return 0, 0, nil
+}
+
+// checkNonRoot reports the features lost when the gpud daemon runs as
+// a non-root user, where the components that require root self-disable.
+func (op *Op) checkNonRoot(ctx context.Context) Result {
+	root, source := op.daemonRoot(ctx)
+	if root {
+		return ok(CheckNonRoot, "gpud runs as root per %s, all features are available", source)
+	}
+	if len(op.nonRootDegradations) == 0 {
+		return warn(CheckNonRoot, "run gpud as root for the full hardware access", "gpud does not run as root per %s", source)
+	}
+
+	names := make([]string, 0, len(op.nonRootDegradations))
+	for name := range op.nonRootDegradations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rs := warn(CheckNonRoot,
+		"run gpud as root (e.g., 'sudo gpud up') for the full hardware access, or ignore if non-root on purpose",
+		"gpud does not run as root per %s, %d component(s) degraded", source, len(names))
+	for _, name := range names {
+		rs.Details = append(rs.Details, fmt.Sprintf("%s: %s", name, op.nonRootDegradations[name]))
+	}
+	return rs
+}
+
+func (op *Op) checkNVMLLibrary(ctx context.Context) Result {
+	p, err := file.FindLibrary(nvmlLibrary,
+		file.WithSearchDirs(op.libSearchDirs...),
+		file.WithAlternativeLibraryName(nvmlLibrary+".1"),
+	)
+	if err != nil {
+		return warn(CheckNVMLLibrary, "install the NVIDIA driver, or ignore on the nodes without NVIDIA GPUs", "%s not found (%v)", nvmlLibrary, err)
+	}
+	return ok(CheckNVMLLibrary, "%s found at %s", nvmlLibrary, p)
+}
+
+func (op *Op) checkKernelModules(ctx context.Context) Result {
+	f, err := os.Open(op.procModules)
+	if err != nil {
+		return warn(CheckKernelModules, "", "failed to read loaded kernel modules from %s: %v", op.procModules, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 0 && fields[0] == nvidiaKernelModule {
+			return ok(CheckKernelModules, "kernel module %q is loaded", nvidiaKernelModule)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return warn(CheckKernelModules, "", "failed to read loaded kernel modules from %s: %v", op.procModules, err)
+	}
+	return warn(CheckKernelModules,
+		fmt.Sprintf("run 'modprobe %s', or ignore on the nodes without NVIDIA GPUs", nvidiaKernelModule),
+		"kernel module %q is not loaded", nvidiaKernelModule)
+}
+
+func (op *Op) checkPort(ctx context.Context) Result {
+	addr := fmt.Sprintf(":%d", op.port)
+	ln, err := net.Listen("tcp", addr)
+	if err == nil {
+		_ = ln.Close()
+		return ok(CheckPort, "port %d is free", op.port)
+	}
+
+	if op.isGPUdServing(ctx) {
+		return ok(CheckPort, "port %d is in use by the running gpud", op.port)
+	}
+	return fail(CheckPort,
+		fmt.Sprintf("stop the process listening on port %d (e.g., find it with 'ss -ltnp sport = :%d'), or run gpud with '--listen-address'", op.port, op.port),
+		"port %d is in use by another process", op.port)
+}
+
+// isGPUdServing returns true if the gpud health endpoint responds on the port.
+func (op *Op) isGPUdServing(ctx context.Context) bool {
+	cli := &http.Client{
+		Timeout: 3 * time.Second,
+		Transport: &http.Transport{
+			// gpud serves with the self-signed certificate
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("https://localhost:%d/healthz", op.port), nil)
+	if err != nil {
+		return false
+	}
+	resp, err := cli.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
+
+// checkSecurity finds the SELinux and AppArmor denials affecting gpud.
+func (op *Op) checkSecurity(ctx context.Context) Result {
+	rs, err := lsm.Scan(op.lsmOpts...)
+	if err != nil {
+		return warn(CheckSecurity, "run 'gpud doctor' as root", "failed to scan security module denials: %v", err)
+	}
+
+	var enforced []string
+	if rs.SELinuxEnforcing {
+		enforced = append(enforced, "SELinux")
+	}
+	if rs.AppArmorEnabled {
+		enforced = append(enforced, "AppArmor")
+	}
+	if len(enforced) == 0 {
+		return ok(CheckSecurity, "SELinux and AppArmor are not enforced")
+	}
+	if len(rs.Denials) == 0 {
+		return ok(CheckSecurity, "%s enforced, no denials affecting gpud", strings.Join(enforced, " and "))
+	}
+
+	features := make(map[lsm.Feature]struct{})
+	details := make([]string, 0, len(rs.Denials))
+	for _, d := range rs.Denials {
+		features[d.Feature] = struct{}{}
+		details = append(details, fmt.Sprintf("[%s] %s", d.Feature, d))
+	}
+	names := make([]string, 0, len(features))
+	for f := range features {
+		names = append(names, string(f))
+	}
+	sort.Strings(names)
+
+	hint := "run 'sudo gpud up --install-security-policy' to install the policy allowing the denials, or ignore if denied on purpose"
+	format := "%d %s denial(s) affecting gpud (%s)"
+	args := []any{len(rs.Denials), strings.Join(enforced, "/"), strings.Join(names, ", ")}
+
+	res := warn(CheckSecurity, hint, format, args...)
+	for f := range features {
+		// module loading, kmsg, or NVML denied
+		if f != lsm.FeatureOther {
+			res = fail(CheckSecurity, hint, format, args...)
+			break
+		}
+	}
+	res.Details = details
+	return res
+}
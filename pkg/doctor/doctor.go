@@ -0,0 +1,96 @@
+// Package doctor validates the gpud installation itself (e.g., binary, systemd unit,
+// state file and its schema version, permissions, NVIDIA libraries, kernel modules, ports,
+// the features lost in the non-root mode, and SELinux/AppArmor denials),
+// with the fix-it hints for the failed checks.
+package doctor
+
+import (
+	"context"
+	"fmt"
+)
+
+// Status is the status of a check.
+type Status string
+
+const (
+	StatusOK Status = "ok"
+	// StatusWarn is the status of the check that did not pass
+	// but may be expected (e.g., no NVIDIA driver on the CPU nodes).
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// Result is the result of a check.
+type Result struct {
+	// Name is the name of the check.
+	Name string `json:"name"`
+	// Status is the status of the check.
+	Status Status `json:"status"`
+	// Message describes the result of the check.
+	Message string `json:"message"`
+	// Hint describes how to fix the failed check.
+	Hint string `json:"hint,omitempty"`
+	// Details lists the itemized findings of the check, if any
+	// (e.g., the features lost in the non-root mode).
+	Details []string `json:"details,omitempty"`
+}
+
+// Check validates a part of the installation.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) Result
+}
+
+func ok(name string, format string, args ...any) Result {
+	return Result{Name: name, Status: StatusOK, Message: fmt.Sprintf(format, args...)}
+}
+
+func warn(name string, hint string, format string, args ...any) Result {
+	return Result{Name: name, Status: StatusWarn, Message: fmt.Sprintf(format, args...), Hint: hint}
+}
+
+func fail(name string, hint string, format string, args ...any) Result {
+	return Result{Name: name, Status: StatusFail, Message: fmt.Sprintf(format, args...), Hint: hint}
+}
+
+// Checks returns the default checks in the order to run.
+func Checks(opts ...OpOption) ([]Check, error) {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return nil, err
+	}
+
+	return []Check{
+		{Name: CheckBinary, Run: op.checkBinary},
+		{Name: CheckSystemdUnit, Run: op.checkSystemdUnit},
+		{Name: CheckStateFile, Run: op.checkStateFile},
+		{Name: CheckPermissions, Run: op.checkPermissions},
+		{Name: CheckNVMLLibrary, Run: op.checkNVMLLibrary},
+		{Name: CheckKernelModules, Run: op.checkKernelModules},
+		{Name: CheckPort, Run: op.checkPort},
+		{Name: CheckNonRoot, Run: op.checkNonRoot},
+		{Name: CheckSecurity, Run: op.checkSecurity},
+	}, nil
+}
+
+// Run runs the checks in order, and returns the results.
+func Run(ctx context.Context, checks []Check) []Result {
+	results := make([]Result, 0, len(checks))
+	for _, c := range checks {
+		rs := c.Run(ctx)
+		rs.Name = c.Name
+		results = append(results, rs)
+	}
+	return results
+}
+
+// Failed returns the number of the failed checks.
+func Failed(results []Result) int {
+	n := 0
+	for _, rs := range results {
+		if rs.Status == StatusFail {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,257 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/lsm"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	pkgmetricsstore "github.com/leptonai/gpud/pkg/metrics/store"
+	pkgmigrations "github.com/leptonai/gpud/pkg/migrations"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func newTestOp(t *testing.T, opts ...OpOption) *Op {
+	dir := t.TempDir()
+	op := &Op{}
+	require.NoError(t, op.applyOpts(append([]OpOption{
+		WithBinPaths(filepath.Join(dir, "bin", "gpud"), filepath.Join(dir, "sbin", "gpud")),
+		WithUnitFile(filepath.Join(dir, "gpud.service"), "[Unit]\nDescription=gpud\n"),
+		WithSystemd(true),
+		WithStateFile(filepath.Join(dir, "gpud.state")),
+		WithLibrarySearchDirs(filepath.Join(dir, "lib")),
+		WithProcModules(filepath.Join(dir, "modules")),
+		WithLSMOptions(
+			lsm.WithSELinuxEnforceFile(filepath.Join(dir, "selinux-enforce")),
+			lsm.WithAppArmorEnabledFile(filepath.Join(dir, "apparmor-enabled")),
+			lsm.WithLogFiles(filepath.Join(dir, "audit.log")),
+		),
+	}, opts...)))
+	return op
+}
+
+func TestCheckBinary(t *testing.T) {
+	op := newTestOp(t)
+	assert.Equal(t, StatusFail, op.checkBinary(context.Background()).Status)
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(op.binPaths[1]), 0755))
+	require.NoError(t, os.WriteFile(op.binPaths[1], nil, 0755))
+	assert.Equal(t, StatusWarn, op.checkBinary(context.Background()).Status)
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(op.binPaths[0]), 0755))
+	require.NoError(t, os.WriteFile(op.binPaths[0], nil, 0755))
+	assert.Equal(t, StatusOK, op.checkBinary(context.Background()).Status)
+}
+
+func TestCheckSystemdUnit(t *testing.T) {
+	op := newTestOp(t)
+	rs := op.checkSystemdUnit(context.Background())
+	assert.Equal(t, StatusFail, rs.Status)
+	assert.Contains(t, rs.Hint, "gpud up")
+
+	require.NoError(t, os.WriteFile(op.unitFile, []byte("[Unit]\nDescription=other\n"), 0644))
+	assert.Equal(t, StatusWarn, op.checkSystemdUnit(context.Background()).Status)
+
+	require.NoError(t, os.WriteFile(op.unitFile, []byte("[Unit]\nDescription=gpud\n\n"), 0644))
+	assert.Equal(t, StatusOK, op.checkSystemdUnit(context.Background()).Status)
+
+	op = newTestOp(t, WithSystemd(false))
+	assert.Equal(t, StatusWarn, op.checkSystemdUnit(context.Background()).Status)
+}
+
+func TestCheckStateFile(t *testing.T) {
+	op := newTestOp(t)
+	assert.Equal(t, StatusFail, op.checkStateFile(context.Background()).Status)
+
+	dbRW, err := sqlite.Open(op.stateFile)
+	require.NoError(t, err)
+	defer dbRW.Close()
+
+	// tables created without the migrations
+	require.NoError(t, pkgmetadata.CreateTableMetadata(context.Background(), dbRW))
+	rs := op.checkStateFile(context.Background())
+	assert.Equal(t, StatusFail, rs.Status)
+	assert.Contains(t, rs.Message, "schema version 0")
+
+	_, _, err = pkgmigrations.Apply(context.Background(), dbRW)
+	require.NoError(t, err)
+	rs = op.checkStateFile(context.Background())
+	assert.Equal(t, StatusOK, rs.Status)
+	assert.Contains(t, rs.Message, fmt.Sprintf("schema version %d", pkgmigrations.LatestVersion()))
+
+	// migrated table dropped by hand
+	_, err = dbRW.Exec("DROP TABLE " + pkgmetricsstore.DefaultTableName)
+	require.NoError(t, err)
+	rs = op.checkStateFile(context.Background())
+	assert.Equal(t, StatusFail, rs.Status)
+	assert.Contains(t, rs.Message, pkgmetricsstore.DefaultTableName)
+
+	// migrated by a newer version
+	require.NoError(t, pkgmetricsstore.CreateTable(context.Background(), dbRW, pkgmetricsstore.DefaultTableName))
+	_, err = dbRW.Exec(fmt.Sprintf("PRAGMA user_version = %d", pkgmigrations.LatestVersion()+1))
+	require.NoError(t, err)
+	assert.Equal(t, StatusWarn, op.checkStateFile(context.Background()).Status)
+}
+
+func TestCheckPermissions(t *testing.T) {
+	op := newTestOp(t)
+	rs := op.checkPermissions(context.Background())
+	assert.NotEqual(t, StatusFail, rs.Status)
+
+	op = newTestOp(t, WithStateFile(filepath.Join(t.TempDir(), "missing", "gpud.state")))
+	assert.Equal(t, StatusFail, op.checkPermissions(context.Background()).Status)
+}
+
+func TestCheckNonRoot(t *testing.T) {
+	op := newTestOp(t, WithRoot(true), WithNonRootDegradations(map[string]string{"xid": "disabled"}))
+	rs := op.checkNonRoot(context.Background())
+	assert.Equal(t, StatusOK, rs.Status)
+	assert.Empty(t, rs.Details)
+
+	op = newTestOp(t, WithRoot(false))
+	rs = op.checkNonRoot(context.Background())
+	assert.Equal(t, StatusWarn, rs.Status)
+	assert.Empty(t, rs.Details)
+
+	op = newTestOp(t, WithRoot(false), WithNonRootDegradations(map[string]string{
+		"xid": "disabled",
+		"cpu": "events not watched",
+	}))
+	rs = op.checkNonRoot(context.Background())
+	assert.Equal(t, StatusWarn, rs.Status)
+	assert.Contains(t, rs.Message, "2 component(s)")
+	assert.Equal(t, []string{"cpu: events not watched", "xid: disabled"}, rs.Details)
+}
+
+func TestCheckNonRootDaemonUser(t *testing.T) {
+	// the running daemon takes precedence over the unit
+	op := newTestOp(t)
+	op.findDaemonFunc = func(context.Context) (int32, int, error) { return 123, 1000, nil }
+	require.NoError(t, os.WriteFile(op.unitFile, []byte("[Service]\nUser=root\n"), 0644))
+	rs := op.checkNonRoot(context.Background())
+	assert.Equal(t, StatusWarn, rs.Status)
+	assert.Contains(t, rs.Message, "pid 123, euid 1000")
+
+	op.findDaemonFunc = func(context.Context) (int32, int, error) { return 123, 0, nil }
+	assert.Equal(t, StatusOK, op.checkNonRoot(context.Background()).Status)
+
+	// not running, the unit user overridden by the drop-in
+	op.findDaemonFunc = func(context.Context) (int32, int, error) { return 0, 0, nil }
+	assert.Equal(t, StatusOK, op.checkNonRoot(context.Background()).Status)
+
+	require.NoError(t, os.MkdirAll(op.unitFile+".d", 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(op.unitFile+".d", "user.conf"), []byte("[Service]\nUser=gpud\n"), 0644))
+	rs = op.checkNonRoot(context.Background())
+	assert.Equal(t, StatusWarn, rs.Status)
+	assert.Contains(t, rs.Message, "User=gpud")
+}
+
+func TestUnitUser(t *testing.T) {
+	unitFile := filepath.Join(t.TempDir(), "gpud.service")
+	_, found := unitUser(unitFile)
+	assert.False(t, found)
+
+	require.NoError(t, os.WriteFile(unitFile, []byte("[Service]\nExecStart=/usr/local/bin/gpud run\n"), 0644))
+	user, found := unitUser(unitFile)
+	assert.True(t, found)
+	assert.Empty(t, user)
+
+	require.NoError(t, os.MkdirAll(unitFile+".d", 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(unitFile+".d", "a.conf"), []byte("[Service]\nUser=a\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(unitFile+".d", "b.conf"), []byte("[Service]\nUser=b\n"), 0644))
+	user, _ = unitUser(unitFile)
+	assert.Equal(t, "b", user)
+}
+
+func TestCheckSecurity(t *testing.T) {
+	dir := t.TempDir()
+	enforce := filepath.Join(dir, "enforce")
+	auditLog := filepath.Join(dir, "audit.log")
+	op := newTestOp(t, WithLSMOptions(
+		lsm.WithSELinuxEnforceFile(enforce),
+		lsm.WithAppArmorEnabledFile(filepath.Join(dir, "enabled")),
+		lsm.WithLogFiles(auditLog),
+		lsm.WithSince(time.Unix(1600000000, 0)),
+	))
+
+	rs := op.checkSecurity(context.Background())
+	assert.Equal(t, StatusOK, rs.Status)
+	assert.Contains(t, rs.Message, "not enforced")
+
+	require.NoError(t, os.WriteFile(enforce, []byte("1"), 0644))
+	rs = op.checkSecurity(context.Background())
+	assert.Equal(t, StatusOK, rs.Status)
+	assert.Contains(t, rs.Message, "no denials")
+
+	other := `type=AVC msg=audit(1700000000.1:1): avc:  denied  { getattr } for  pid=1 comm="gpud" path="/etc/shadow" scontext=system_u:system_r:unconfined_service_t:s0 tcontext=system_u:object_r:shadow_t:s0 tclass=file permissive=0`
+	require.NoError(t, os.WriteFile(auditLog, []byte(other+"\n"), 0644))
+	rs = op.checkSecurity(context.Background())
+	assert.Equal(t, StatusWarn, rs.Status)
+	assert.Len(t, rs.Details, 1)
+
+	kmsg := `type=AVC msg=audit(1700000000.2:2): avc:  denied  { read } for  pid=1 comm="gpud" name="kmsg" scontext=system_u:system_r:unconfined_service_t:s0 tcontext=system_u:object_r:kmsg_device_t:s0 tclass=chr_file permissive=0`
+	require.NoError(t, os.WriteFile(auditLog, []byte(other+"\n"+kmsg+"\n"), 0644))
+	rs = op.checkSecurity(context.Background())
+	assert.Equal(t, StatusFail, rs.Status)
+	assert.Contains(t, rs.Message, "kmsg, other")
+	assert.Contains(t, rs.Hint, "--install-security-policy")
+	assert.Len(t, rs.Details, 2)
+}
+
+func TestCheckNVMLLibrary(t *testing.T) {
+	op := newTestOp(t)
+	assert.Equal(t, StatusWarn, op.checkNVMLLibrary(context.Background()).Status)
+
+	dir := op.libSearchDirs[0]
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "libnvidia-ml.so.1"), nil, 0644))
+	assert.Equal(t, StatusOK, op.checkNVMLLibrary(context.Background()).Status)
+}
+
+func TestCheckKernelModules(t *testing.T) {
+	op := newTestOp(t)
+	assert.Equal(t, StatusWarn, op.checkKernelModules(context.Background()).Status)
+
+	require.NoError(t, os.WriteFile(op.procModules, []byte("nvidia_uvm 1 0 - Live 0x0\n"), 0644))
+	assert.Equal(t, StatusWarn, op.checkKernelModules(context.Background()).Status)
+
+	require.NoError(t, os.WriteFile(op.procModules, []byte("nvidia_uvm 1 0 - Live 0x0\nnvidia 2 1 nvidia_uvm, Live 0x0\n"), 0644))
+	assert.Equal(t, StatusOK, op.checkKernelModules(context.Background()).Status)
+}
+
+func TestCheckPort(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	op := newTestOp(t, WithPort(port))
+	assert.Equal(t, StatusFail, op.checkPort(context.Background()).Status)
+
+	require.NoError(t, ln.Close())
+	assert.Equal(t, StatusOK, op.checkPort(context.Background()).Status)
+}
+
+func TestRun(t *testing.T) {
+	checks := []Check{
+		{Name: "a", Run: func(ctx context.Context) Result { return ok("", "fine") }},
+		{Name: "b", Run: func(ctx context.Context) Result { return fail("", "fix it", "broken") }},
+		{Name: "c", Run: func(ctx context.Context) Result { return warn("", "", "maybe") }},
+	}
+	results := Run(context.Background(), checks)
+	require.Len(t, results, 3)
+	assert.Equal(t, "a", results[0].Name)
+	assert.Equal(t, "fix it", results[1].Hint)
+	assert.Equal(t, 1, Failed(results))
+
+	defaults, err := Checks(WithStateFile(filepath.Join(t.TempDir(), "gpud.state")))
+	require.NoError(t, err)
+	assert.Len(t, defaults, 9)
+}
@@ -0,0 +1,144 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/leptonai/gpud/pkg/config"
+	"github.com/leptonai/gpud/pkg/gpud-manager/systemd"
+	"github.com/leptonai/gpud/pkg/lsm"
+	nvidiaquery "github.com/leptonai/gpud/pkg/nvidia-query"
+)
+
+const defaultProcModules = "/proc/modules"
+
+type Op struct {
+	binPaths       []string
+	unitFile       string
+	unitContents   string
+	stateFile      string
+	libSearchDirs  []string
+	procModules    string
+	port           int
+	systemdEnabled *bool
+
+	asRoot              *bool
+	nonRootDegradations map[string]string
+	// findDaemonFunc returns the pid and the effective user ID
+	// of the running gpud daemon, or zero pid if not running
+	findDaemonFunc func(ctx context.Context) (int32, int, error)
+
+	lsmOpts []lsm.OpOption
+}
+
+type OpOption func(*Op)
+
+func (op *Op) applyOpts(opts []OpOption) error {
+	for _, opt := range opts {
+		opt(op)
+	}
+
+	if len(op.binPaths) == 0 {
+		op.binPaths = []string{systemd.DefaultBinPath, systemd.DeprecatedDefaultBinPathSbin}
+	}
+	if op.unitFile == "" {
+		op.unitFile = systemd.DefaultUnitFile
+	}
+	if op.unitContents == "" {
+		op.unitContents = systemd.GPUdServiceUnitFileContents()
+	}
+	if op.stateFile == "" {
+		f, err := config.DefaultStateFile()
+		if err != nil {
+			return fmt.Errorf("failed to get state file: %w", err)
+		}
+		op.stateFile = f
+	}
+	if len(op.libSearchDirs) == 0 {
+		op.libSearchDirs = nvidiaquery.DefaultNVIDIALibrariesSearchDirs
+	}
+	if op.procModules == "" {
+		op.procModules = defaultProcModules
+	}
+	if op.port == 0 {
+		op.port = config.DefaultGPUdPort
+	}
+	if op.findDaemonFunc == nil {
+		op.findDaemonFunc = findDaemon
+	}
+
+	return nil
+}
+
+// WithBinPaths sets the expected paths of the gpud binary,
+// in the order of preference.
+func WithBinPaths(paths ...string) OpOption {
+	return func(op *Op) {
+		op.binPaths = paths
+	}
+}
+
+// WithUnitFile sets the systemd unit file path and its expected contents.
+func WithUnitFile(file string, contents string) OpOption {
+	return func(op *Op) {
+		op.unitFile = file
+		op.unitContents = contents
+	}
+}
+
+// WithSystemd overrides whether systemd is available on the host.
+func WithSystemd(enabled bool) OpOption {
+	return func(op *Op) {
+		op.systemdEnabled = &enabled
+	}
+}
+
+// WithStateFile sets the state file path.
+func WithStateFile(file string) OpOption {
+	return func(op *Op) {
+		op.stateFile = file
+	}
+}
+
+// WithLibrarySearchDirs sets the directories to search the NVIDIA libraries in.
+func WithLibrarySearchDirs(dirs ...string) OpOption {
+	return func(op *Op) {
+		op.libSearchDirs = dirs
+	}
+}
+
+// WithProcModules sets the path of the loaded kernel modules list.
+func WithProcModules(file string) OpOption {
+	return func(op *Op) {
+		op.procModules = file
+	}
+}
+
+// WithPort sets the gpud server port to check.
+func WithPort(port int) OpOption {
+	return func(op *Op) {
+		op.port = port
+	}
+}
+
+// WithRoot overrides whether the gpud daemon runs as root.
+func WithRoot(asRoot bool) OpOption {
+	return func(op *Op) {
+		op.asRoot = &asRoot
+	}
+}
+
+// WithNonRootDegradations sets the features lost per component
+// when gpud runs as a non-root user.
+func WithNonRootDegradations(m map[string]string) OpOption {
+	return func(op *Op) {
+		op.nonRootDegradations = m
+	}
+}
+
+// WithLSMOptions sets the options to scan the SELinux and AppArmor denials.
+func WithLSMOptions(opts ...lsm.OpOption) OpOption {
+	return func(op *Op) {
+		op.lsmOpts = opts
+	}
+}
@@ -0,0 +1,144 @@
+// Package endpoints provides the failover across multiple control plane endpoints.
+package endpoints
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// DefaultPrimaryRecheckInterval is the default interval to re-check the primary endpoint
+// while failed over to the secondary one.
+const DefaultPrimaryRecheckInterval = 5 * time.Minute
+
+var ErrNoEndpoint = errors.New("no endpoint")
+
+// Parse parses the comma-separated list of the endpoints
+// (e.g., "cp-us-east.example.com,cp-us-west.example.com"),
+// where the first one is the primary.
+func Parse(s string) []string {
+	var eps []string
+	for _, ep := range strings.Split(s, ",") {
+		ep = strings.TrimSpace(ep)
+		if ep == "" {
+			continue
+		}
+		eps = append(eps, ep)
+	}
+	return eps
+}
+
+// Failover selects the control plane endpoint to use among multiple ones.
+//
+// It sticks to the current endpoint as long as it works, and fails over to the
+// next endpoints in order when it does not. While failed over, the primary
+// (first) endpoint is re-tried at most once per the recheck interval, and the
+// failover sticks back to the primary once it works again.
+type Failover struct {
+	mu sync.Mutex
+
+	endpoints []string
+	current   int
+
+	primaryRecheckInterval time.Duration
+	lastPrimaryCheck       time.Time
+
+	nowFunc func() time.Time
+}
+
+// NewFailover creates a new failover across the endpoints, the first being the primary.
+// Zero recheck interval uses DefaultPrimaryRecheckInterval.
+func NewFailover(endpoints []string, primaryRecheckInterval time.Duration) *Failover {
+	if primaryRecheckInterval <= 0 {
+		primaryRecheckInterval = DefaultPrimaryRecheckInterval
+	}
+	return &Failover{
+		endpoints:              endpoints,
+		primaryRecheckInterval: primaryRecheckInterval,
+		nowFunc:                time.Now,
+	}
+}
+
+// Endpoints returns all the endpoints, the first being the primary.
+func (f *Failover) Endpoints() []string {
+	return f.endpoints
+}
+
+// Current returns the endpoint last used successfully (or the primary at first).
+// Returns an empty string if there is no endpoint.
+func (f *Failover) Current() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.endpoints) == 0 {
+		return ""
+	}
+	return f.endpoints[f.current]
+}
+
+// candidates returns the endpoint indices in the order to try.
+func (f *Failover) candidates() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	idxs := make([]int, 0, len(f.endpoints))
+
+	// fail back to the primary if due for a re-check
+	now := f.nowFunc()
+	if f.current != 0 && now.Sub(f.lastPrimaryCheck) >= f.primaryRecheckInterval {
+		f.lastPrimaryCheck = now
+		idxs = append(idxs, 0)
+	}
+
+	for i := 0; i < len(f.endpoints); i++ {
+		idx := (f.current + i) % len(f.endpoints)
+		if len(idxs) > 0 && idx == 0 && idxs[0] == 0 {
+			continue
+		}
+		idxs = append(idxs, idx)
+	}
+	return idxs
+}
+
+// Do calls the function with the endpoints in the failover order until one succeeds,
+// and returns the endpoint that succeeded. The succeeded endpoint is used first
+// in the next call. Returns all the errors joined if none succeeds.
+func (f *Failover) Do(ctx context.Context, fn func(ctx context.Context, endpoint string) error) (string, error) {
+	if len(f.endpoints) == 0 {
+		return "", ErrNoEndpoint
+	}
+
+	var errs []error
+	for _, idx := range f.candidates() {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		ep := f.endpoints[idx]
+		err := fn(ctx, ep)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ep, err))
+			continue
+		}
+
+		f.mu.Lock()
+		if f.current != idx {
+			log.Logger.Warnw("control plane endpoint switched", "from", f.endpoints[f.current], "to", ep)
+			if idx != 0 {
+				// start the recheck interval from the failover
+				f.lastPrimaryCheck = f.nowFunc()
+			}
+		}
+		f.current = idx
+		f.mu.Unlock()
+
+		return ep, nil
+	}
+	return "", errors.Join(errs...)
+}
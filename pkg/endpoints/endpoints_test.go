@@ -0,0 +1,107 @@
+package endpoints
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	assert.Nil(t, Parse(""))
+	assert.Equal(t, []string{"a.com"}, Parse("a.com"))
+	assert.Equal(t, []string{"a.com", "b.com"}, Parse(" a.com, ,b.com ,"))
+}
+
+func TestFailover(t *testing.T) {
+	now := time.Now()
+	f := NewFailover([]string{"a", "b", "c"}, time.Minute)
+	f.nowFunc = func() time.Time { return now }
+	assert.Equal(t, "a", f.Current())
+
+	down := map[string]bool{}
+	var tried []string
+	fn := func(_ context.Context, ep string) error {
+		tried = append(tried, ep)
+		if down[ep] {
+			return errors.New("down")
+		}
+		return nil
+	}
+
+	ep, err := f.Do(context.Background(), fn)
+	require.NoError(t, err)
+	assert.Equal(t, "a", ep)
+	assert.Equal(t, []string{"a"}, tried)
+
+	// primary down, fail over to the next
+	down["a"] = true
+	tried = nil
+	ep, err = f.Do(context.Background(), fn)
+	require.NoError(t, err)
+	assert.Equal(t, "b", ep)
+	assert.Equal(t, []string{"a", "b"}, tried)
+	assert.Equal(t, "b", f.Current())
+
+	// sticks to the secondary before the recheck interval, even if the primary is back
+	down["a"] = false
+	tried = nil
+	ep, err = f.Do(context.Background(), fn)
+	require.NoError(t, err)
+	assert.Equal(t, "b", ep)
+	assert.Equal(t, []string{"b"}, tried)
+
+	// fails back to the primary after the recheck interval
+	now = now.Add(time.Minute)
+	tried = nil
+	ep, err = f.Do(context.Background(), fn)
+	require.NoError(t, err)
+	assert.Equal(t, "a", ep)
+	assert.Equal(t, []string{"a"}, tried)
+
+	// all down
+	down["a"], down["b"], down["c"] = true, true, true
+	tried = nil
+	_, err = f.Do(context.Background(), fn)
+	require.Error(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, tried)
+	assert.Equal(t, "a", f.Current())
+}
+
+func TestFailoverSecondaryDown(t *testing.T) {
+	now := time.Now()
+	f := NewFailover([]string{"a", "b", "c"}, time.Minute)
+	f.nowFunc = func() time.Time { return now }
+
+	down := map[string]bool{"a": true, "b": true}
+	var tried []string
+	fn := func(_ context.Context, ep string) error {
+		tried = append(tried, ep)
+		if down[ep] {
+			return errors.New("down")
+		}
+		return nil
+	}
+
+	ep, err := f.Do(context.Background(), fn)
+	require.NoError(t, err)
+	assert.Equal(t, "c", ep)
+
+	// primary re-checked first after the interval, then the current, without duplicates
+	now = now.Add(time.Minute)
+	tried = nil
+	ep, err = f.Do(context.Background(), fn)
+	require.NoError(t, err)
+	assert.Equal(t, "c", ep)
+	assert.Equal(t, []string{"a", "c"}, tried)
+}
+
+func TestFailoverNoEndpoint(t *testing.T) {
+	f := NewFailover(nil, 0)
+	assert.Equal(t, "", f.Current())
+	_, err := f.Do(context.Background(), func(context.Context, string) error { return nil })
+	assert.ErrorIs(t, err, ErrNoEndpoint)
+}
@@ -0,0 +1,210 @@
+// Package errorbudget tracks the cumulative correctable hardware errors (e.g., the
+// corrected ECC errors, the infiniband symbol errors, the PCIe replays) against the
+// budgets per period, to flag the slowly degrading hardware even if no single event
+// crossed the instantaneous threshold.
+package errorbudget
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+const (
+	// CounterECCCorrected is the aggregate corrected ECC errors per GPU.
+	CounterECCCorrected = "ecc_corrected"
+	// CounterIBSymbolError is the symbol errors per infiniband port.
+	CounterIBSymbolError = "ib_symbol_error"
+	// CounterPCIeReplay is the PCIe replays per GPU.
+	CounterPCIeReplay = "pcie_replay"
+)
+
+// Counters are the supported counters.
+var Counters = []string{CounterECCCorrected, CounterIBSymbolError, CounterPCIeReplay}
+
+const (
+	// DefaultPeriod is the default period of the budget.
+	DefaultPeriod = 24 * time.Hour
+	// MaxPeriod is the longest period of the budget,
+	// same as the retention of the metrics the counters are read from.
+	MaxPeriod = 3 * 24 * time.Hour
+)
+
+const (
+	// LabelCounter is the metric label of the counter name.
+	LabelCounter = "counter"
+	// LabelSource is the metric label of the counter source
+	// (e.g., the GPU UUID, the infiniband port "mlx5_0/1").
+	LabelSource = "source"
+)
+
+var (
+	ErrUnknownCounter = errors.New("unknown error budget counter")
+	ErrInvalidLimit   = errors.New("error budget limit must be positive")
+	ErrInvalidPeriod  = errors.New("invalid error budget period")
+	ErrDuplicate      = errors.New("duplicate error budget")
+)
+
+// Budget is the number of the errors of the counter allowed per period,
+// summed across all the sources (e.g., all the GPUs) on the node.
+type Budget struct {
+	// Counter is the counter name (e.g., "ecc_corrected").
+	Counter string `json:"counter"`
+	// Limit is the number of the errors that exhausts the budget.
+	Limit uint64 `json:"limit"`
+	// Period is the sliding window of the budget
+	// (leave empty for DefaultPeriod).
+	Period metav1.Duration `json:"period,omitempty"`
+}
+
+// Validate validates the budget.
+func (b Budget) Validate() error {
+	known := false
+	for _, c := range Counters {
+		if b.Counter == c {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return fmt.Errorf("%w %q (supported: %s)", ErrUnknownCounter, b.Counter, strings.Join(Counters, ", "))
+	}
+	if b.Limit == 0 {
+		return fmt.Errorf("%w (counter %q)", ErrInvalidLimit, b.Counter)
+	}
+	if b.Period.Duration < 0 || b.Period.Duration > MaxPeriod {
+		return fmt.Errorf("%w %v, must be at most %v (counter %q)", ErrInvalidPeriod, b.Period.Duration, MaxPeriod, b.Counter)
+	}
+	return nil
+}
+
+// GetPeriod returns the period of the budget, or DefaultPeriod if not set.
+func (b Budget) GetPeriod() time.Duration {
+	if b.Period.Duration == 0 {
+		return DefaultPeriod
+	}
+	return b.Period.Duration
+}
+
+// LoadBudgets loads and validates the error budgets from the YAML file.
+func LoadBudgets(file string) ([]Budget, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParseBudgets(b)
+}
+
+// ParseBudgets parses and validates the error budgets from the YAML content.
+func ParseBudgets(b []byte) ([]Budget, error) {
+	var budgets []Budget
+	if err := yaml.Unmarshal(b, &budgets); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{}, len(budgets))
+	for _, budget := range budgets {
+		if err := budget.Validate(); err != nil {
+			return nil, err
+		}
+		key := budget.Counter + "/" + budget.GetPeriod().String()
+		if _, ok := seen[key]; ok {
+			return nil, fmt.Errorf("%w for counter %q and period %v", ErrDuplicate, budget.Counter, budget.GetPeriod())
+		}
+		seen[key] = struct{}{}
+	}
+	return budgets, nil
+}
+
+// Usage is the errors used out of the budget.
+type Usage struct {
+	Counter string `json:"counter"`
+	Limit   uint64 `json:"limit"`
+	Period  string `json:"period"`
+	// Used is the increase of the counter in the period, summed across the sources.
+	Used uint64 `json:"used"`
+	// Sources are the increases of the counter per source, only with the errors.
+	Sources map[string]uint64 `json:"sources,omitempty"`
+	// Exhausted is true if the used errors reached the limit.
+	Exhausted bool `json:"exhausted"`
+}
+
+// String returns the usage (e.g., "ecc_corrected 120/100 in 24h0m0s").
+func (u Usage) String() string {
+	return fmt.Sprintf("%s %d/%d in %s", u.Counter, u.Used, u.Limit, u.Period)
+}
+
+// Evaluate evaluates the budgets against the data points of the cumulative counters
+// (with the LabelCounter and LabelSource labels) at the time, sorted by the counter.
+func Evaluate(budgets []Budget, ms pkgmetrics.Metrics, now time.Time) []Usage {
+	usages := make([]Usage, 0, len(budgets))
+	for _, b := range budgets {
+		since := now.Add(-b.GetPeriod()).UnixMilli()
+
+		var inPeriod pkgmetrics.Metrics
+		for _, m := range ms {
+			if m.UnixMilliseconds >= since && m.Labels[LabelCounter] == b.Counter {
+				inPeriod = append(inPeriod, m)
+			}
+		}
+
+		u := Usage{
+			Counter: b.Counter,
+			Limit:   b.Limit,
+			Period:  b.GetPeriod().String(),
+		}
+		for src, inc := range Increase(inPeriod) {
+			if inc == 0 {
+				continue
+			}
+			if u.Sources == nil {
+				u.Sources = make(map[string]uint64)
+			}
+			u.Sources[src] = inc
+			u.Used += inc
+		}
+		u.Exhausted = u.Used >= u.Limit
+		usages = append(usages, u)
+	}
+	sort.SliceStable(usages, func(i, j int) bool {
+		return usages[i].Counter < usages[j].Counter
+	})
+	return usages
+}
+
+// Increase returns the increase of the cumulative counters per source (LabelSource)
+// over the data points, counting the value after a reset (e.g., the driver reload,
+// the port reset) as the increase since the reset.
+func Increase(ms pkgmetrics.Metrics) map[string]uint64 {
+	sorted := make(pkgmetrics.Metrics, len(ms))
+	copy(sorted, ms)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].UnixMilliseconds < sorted[j].UnixMilliseconds
+	})
+
+	last := make(map[string]float64)
+	increases := make(map[string]uint64)
+	for _, m := range sorted {
+		src := m.Labels[LabelSource]
+		prev, ok := last[src]
+		last[src] = m.Value
+		if !ok {
+			continue
+		}
+		if m.Value >= prev {
+			increases[src] += uint64(m.Value - prev)
+		} else {
+			// reset
+			increases[src] += uint64(m.Value)
+		}
+	}
+	return increases
+}
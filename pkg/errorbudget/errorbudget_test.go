@@ -0,0 +1,103 @@
+package errorbudget
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+func TestBudgetValidate(t *testing.T) {
+	assert.NoError(t, Budget{Counter: CounterECCCorrected, Limit: 100}.Validate())
+	assert.ErrorIs(t, Budget{Counter: "xid", Limit: 1}.Validate(), ErrUnknownCounter)
+	assert.ErrorIs(t, Budget{Counter: CounterPCIeReplay}.Validate(), ErrInvalidLimit)
+	assert.ErrorIs(t, Budget{Counter: CounterPCIeReplay, Limit: 1, Period: metav1.Duration{Duration: 7 * 24 * time.Hour}}.Validate(), ErrInvalidPeriod)
+
+	assert.Equal(t, DefaultPeriod, Budget{}.GetPeriod())
+	assert.Equal(t, time.Hour, Budget{Period: metav1.Duration{Duration: time.Hour}}.GetPeriod())
+}
+
+func TestLoadBudgets(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "error-budgets.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+- counter: ecc_corrected
+  limit: 100
+  period: 24h
+- counter: ib_symbol_error
+  limit: 1000
+`), 0644))
+
+	budgets, err := LoadBudgets(file)
+	require.NoError(t, err)
+	require.Len(t, budgets, 2)
+	assert.Equal(t, uint64(1000), budgets[1].Limit)
+	assert.Equal(t, DefaultPeriod, budgets[1].GetPeriod())
+
+	require.NoError(t, os.WriteFile(file, []byte(`
+- counter: ecc_corrected
+  limit: 100
+- counter: ecc_corrected
+  limit: 10
+  period: 24h
+`), 0644))
+	_, err = LoadBudgets(file)
+	assert.ErrorIs(t, err, ErrDuplicate)
+}
+
+func newMetric(at time.Time, counter string, source string, v float64) pkgmetrics.Metric {
+	return pkgmetrics.Metric{
+		UnixMilliseconds: at.UnixMilli(),
+		Value:            v,
+		Labels:           map[string]string{LabelCounter: counter, LabelSource: source},
+	}
+}
+
+func TestIncrease(t *testing.T) {
+	now := time.Now()
+	ms := pkgmetrics.Metrics{
+		newMetric(now.Add(-3*time.Minute), CounterPCIeReplay, "gpu-0", 10),
+		newMetric(now.Add(-time.Minute), CounterPCIeReplay, "gpu-0", 2), // reset
+		newMetric(now.Add(-2*time.Minute), CounterPCIeReplay, "gpu-0", 15),
+		newMetric(now, CounterPCIeReplay, "gpu-0", 4),
+		newMetric(now, CounterPCIeReplay, "gpu-1", 100),
+	}
+	assert.Equal(t, map[string]uint64{"gpu-0": 9}, Increase(ms))
+}
+
+func TestEvaluate(t *testing.T) {
+	now := time.Now()
+	budgets := []Budget{
+		{Counter: CounterPCIeReplay, Limit: 10, Period: metav1.Duration{Duration: time.Hour}},
+		{Counter: CounterECCCorrected, Limit: 100},
+	}
+	ms := pkgmetrics.Metrics{
+		// before the pcie replay budget period
+		newMetric(now.Add(-2*time.Hour), CounterPCIeReplay, "gpu-0", 0),
+		newMetric(now.Add(-30*time.Minute), CounterPCIeReplay, "gpu-0", 100),
+		newMetric(now, CounterPCIeReplay, "gpu-0", 106),
+		newMetric(now.Add(-30*time.Minute), CounterPCIeReplay, "gpu-1", 0),
+		newMetric(now, CounterPCIeReplay, "gpu-1", 5),
+
+		newMetric(now.Add(-2*time.Hour), CounterECCCorrected, "gpu-0", 0),
+		newMetric(now, CounterECCCorrected, "gpu-0", 50),
+	}
+
+	usages := Evaluate(budgets, ms, now)
+	require.Len(t, usages, 2)
+
+	assert.Equal(t, CounterECCCorrected, usages[0].Counter)
+	assert.Equal(t, uint64(50), usages[0].Used)
+	assert.False(t, usages[0].Exhausted)
+
+	assert.Equal(t, CounterPCIeReplay, usages[1].Counter)
+	assert.Equal(t, uint64(11), usages[1].Used)
+	assert.Equal(t, map[string]uint64{"gpu-0": 6, "gpu-1": 5}, usages[1].Sources)
+	assert.True(t, usages[1].Exhausted)
+	assert.Equal(t, "pcie_replay 11/10 in 1h0m0s", usages[1].String())
+}
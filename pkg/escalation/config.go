@@ -0,0 +1,130 @@
+package escalation
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/notifier"
+)
+
+var (
+	ErrLadderNameRequired  = errors.New("escalation ladder name is required")
+	ErrNoSteps             = errors.New("escalation ladder has no step")
+	ErrInvalidStepAfter    = errors.New("escalation step after must be positive and increasing")
+	ErrInvalidStepSeverity = errors.New("invalid escalation step severity")
+	ErrDuplicateLadderName = errors.New("duplicate escalation ladder name")
+	ErrInvalidComponentPat = errors.New("invalid escalation ladder component pattern")
+)
+
+// Ladder defines the steps to escalate the unhealthy states
+// of the matching components, the longer they persist.
+type Ladder struct {
+	// Name is the unique name of the ladder.
+	Name string `json:"name"`
+	// Components are the glob patterns of the component names to escalate
+	// (e.g., "accelerator-nvidia-*"). Leave empty to escalate all the components.
+	// The first ladder matching the component applies.
+	Components []string `json:"components,omitempty"`
+	// Steps are the escalation steps, in the increasing order of the after.
+	Steps []Step `json:"steps"`
+}
+
+// Step is an escalation step, reached once the state has been
+// unhealthy for the duration.
+type Step struct {
+	// After is how long the state has been unhealthy to reach the step.
+	After metav1.Duration `json:"after"`
+	// Severity is the severity of the state at the step
+	// (e.g., "Critical"), one of "Warning", "Critical", and "Fatal".
+	Severity apiv1.EventType `json:"severity"`
+	// Sinks are the additional sinks notified once the step is reached
+	// (e.g., the on-call paging webhook), in the event sink config format.
+	Sinks []notifier.SinkConfig `json:"sinks,omitempty"`
+	// Action is the command (run with bash) once the step is reached
+	// (e.g., cordon the node), with the escalated state in the environment
+	// variables (e.g., "GPUD_ESCALATION_COMPONENT").
+	Action string `json:"action,omitempty"`
+}
+
+// Validate validates the ladder.
+func (l Ladder) Validate() error {
+	if l.Name == "" {
+		return ErrLadderNameRequired
+	}
+	for _, pat := range l.Components {
+		if _, err := path.Match(pat, ""); err != nil {
+			return fmt.Errorf("%w %q (ladder %q)", ErrInvalidComponentPat, pat, l.Name)
+		}
+	}
+	if len(l.Steps) == 0 {
+		return fmt.Errorf("%w (ladder %q)", ErrNoSteps, l.Name)
+	}
+
+	var prev metav1.Duration
+	for i, step := range l.Steps {
+		if step.After.Duration <= prev.Duration {
+			return fmt.Errorf("%w, got %v at step %d (ladder %q)", ErrInvalidStepAfter, step.After.Duration, i+1, l.Name)
+		}
+		prev = step.After
+
+		switch step.Severity {
+		case apiv1.EventTypeWarning, apiv1.EventTypeCritical, apiv1.EventTypeFatal:
+		default:
+			return fmt.Errorf("%w %q at step %d (ladder %q)", ErrInvalidStepSeverity, step.Severity, i+1, l.Name)
+		}
+		for _, sink := range step.Sinks {
+			if err := sink.Validate(); err != nil {
+				return fmt.Errorf("%w at step %d (ladder %q)", err, i+1, l.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// Matches returns true if the ladder escalates the component.
+func (l Ladder) Matches(component string) bool {
+	if len(l.Components) == 0 {
+		return true
+	}
+	for _, pat := range l.Components {
+		if ok, _ := path.Match(pat, component); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadLadders loads and validates the escalation ladders from the YAML file.
+func LoadLadders(file string) ([]Ladder, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParseLadders(b)
+}
+
+// ParseLadders parses and validates the escalation ladders from the YAML content.
+func ParseLadders(b []byte) ([]Ladder, error) {
+	var ladders []Ladder
+	if err := yaml.Unmarshal(b, &ladders); err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]struct{}, len(ladders))
+	for _, l := range ladders {
+		if err := l.Validate(); err != nil {
+			return nil, err
+		}
+		if _, ok := names[l.Name]; ok {
+			return nil, fmt.Errorf("%w %q", ErrDuplicateLadderName, l.Name)
+		}
+		names[l.Name] = struct{}{}
+	}
+	return ladders, nil
+}
@@ -0,0 +1,104 @@
+package escalation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/notifier"
+)
+
+func TestLadderValidate(t *testing.T) {
+	step := func(after time.Duration, severity apiv1.EventType) Step {
+		return Step{After: metav1.Duration{Duration: after}, Severity: severity}
+	}
+
+	tests := []struct {
+		name    string
+		ladder  Ladder
+		wantErr error
+	}{
+		{name: "valid", ladder: Ladder{Name: "gpu", Components: []string{"accelerator-nvidia-*"}, Steps: []Step{step(10*time.Minute, apiv1.EventTypeCritical), step(time.Hour, apiv1.EventTypeFatal)}}},
+		{name: "missing name", ladder: Ladder{Steps: []Step{step(time.Minute, apiv1.EventTypeCritical)}}, wantErr: ErrLadderNameRequired},
+		{name: "invalid pattern", ladder: Ladder{Name: "x", Components: []string{"["}, Steps: []Step{step(time.Minute, apiv1.EventTypeCritical)}}, wantErr: ErrInvalidComponentPat},
+		{name: "no step", ladder: Ladder{Name: "x"}, wantErr: ErrNoSteps},
+		{name: "zero after", ladder: Ladder{Name: "x", Steps: []Step{step(0, apiv1.EventTypeCritical)}}, wantErr: ErrInvalidStepAfter},
+		{name: "decreasing after", ladder: Ladder{Name: "x", Steps: []Step{step(time.Hour, apiv1.EventTypeCritical), step(time.Minute, apiv1.EventTypeFatal)}}, wantErr: ErrInvalidStepAfter},
+		{name: "invalid severity", ladder: Ladder{Name: "x", Steps: []Step{step(time.Minute, apiv1.EventTypeInfo)}}, wantErr: ErrInvalidStepSeverity},
+		{name: "invalid sink", ladder: Ladder{Name: "x", Steps: []Step{{After: metav1.Duration{Duration: time.Minute}, Severity: apiv1.EventTypeCritical, Sinks: []notifier.SinkConfig{{Name: "pager", Type: notifier.SinkTypeWebhook}}}}}, wantErr: notifier.ErrSinkURLRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.ladder.Validate()
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLadderMatches(t *testing.T) {
+	l := Ladder{Name: "gpu", Components: []string{"accelerator-nvidia-*", "disk"}}
+	assert.True(t, l.Matches("accelerator-nvidia-infiniband"))
+	assert.True(t, l.Matches("disk"))
+	assert.False(t, l.Matches("memory"))
+	assert.True(t, Ladder{Name: "all"}.Matches("memory"))
+}
+
+func TestLoadLadders(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "escalation.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+- name: gpu
+  components:
+  - accelerator-nvidia-*
+  steps:
+  - after: 15m
+    severity: Critical
+    sinks:
+    - name: pager
+      type: webhook
+      url: https://example.com/hook
+  - after: 1h
+    severity: Fatal
+    action: /usr/local/bin/cordon.sh
+- name: all
+  steps:
+  - after: 1h
+    severity: Critical
+`), 0644))
+
+	ladders, err := LoadLadders(file)
+	require.NoError(t, err)
+	require.Len(t, ladders, 2)
+	require.Len(t, ladders[0].Steps, 2)
+	assert.Equal(t, 15*time.Minute, ladders[0].Steps[0].After.Duration)
+	assert.Equal(t, apiv1.EventTypeCritical, ladders[0].Steps[0].Severity)
+	assert.Equal(t, "https://example.com/hook", ladders[0].Steps[0].Sinks[0].URL)
+	assert.Equal(t, "/usr/local/bin/cordon.sh", ladders[0].Steps[1].Action)
+	assert.Empty(t, ladders[1].Components)
+
+	require.NoError(t, os.WriteFile(file, []byte(`
+- name: a
+  steps:
+  - after: 1h
+    severity: Critical
+- name: a
+  steps:
+  - after: 1h
+    severity: Critical
+`), 0644))
+	_, err = LoadLadders(file)
+	assert.ErrorIs(t, err, ErrDuplicateLadderName)
+
+	_, err = LoadLadders(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,329 @@
+// Package escalation escalates the severity of the unhealthy states the longer
+// they persist (e.g., from "Warning" to "Critical" after 30 minutes), per the
+// configured escalation ladders, notifying the additional sinks and running the
+// additional actions at each step, instead of the flat severity regardless of
+// how long the issue has been ongoing.
+package escalation
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	pkgdisruption "github.com/leptonai/gpud/pkg/disruption"
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/notifier"
+	"github.com/leptonai/gpud/pkg/toolexec"
+)
+
+const (
+	// DefaultPollInterval is the default interval to poll the health states of the components.
+	DefaultPollInterval = 10 * time.Second
+
+	// BaseSeverity is the severity of the unhealthy state before any escalation.
+	BaseSeverity = apiv1.EventTypeWarning
+
+	// EventNameEscalated is the name of the event sent to the step sinks.
+	EventNameEscalated = "health_state_escalated"
+
+	// defaultActionTimeout is the timeout of the step action.
+	defaultActionTimeout = time.Minute
+)
+
+// Tracker tracks how long each health state has been unhealthy,
+// and escalates it through the steps of the matching ladder.
+// A nil *Tracker is valid and escalates nothing.
+type Tracker struct {
+	registry components.Registry
+	ladders  []Ladder
+	// step sinks per ladder and step
+	sinks [][]*notifier.Notifier
+
+	nowFunc       func() time.Time
+	routeFunc     func(ctx context.Context, ladder int, step int, ev apiv1.Event)
+	runActionFunc func(ctx context.Context, action string, envs []string) error
+
+	mu     sync.Mutex
+	states map[stateKey]*state
+	// disruptions is nil if no disruption is expected
+	disruptions *pkgdisruption.Windows
+	// dbRW persists the states if not nil (see LoadStates)
+	dbRW *sql.DB
+}
+
+type stateKey struct {
+	component string
+	name      string
+}
+
+type state struct {
+	since time.Time
+	// level is the number of the ladder steps reached
+	level int
+}
+
+// NewTracker creates a new tracker with the escalation ladders.
+// With no ladder, the unhealthy states stay at the base severity.
+func NewTracker(registry components.Registry, ladders []Ladder) (*Tracker, error) {
+	t := &Tracker{
+		registry:      registry,
+		ladders:       ladders,
+		nowFunc:       time.Now,
+		runActionFunc: runAction,
+		states:        make(map[stateKey]*state),
+	}
+	for _, l := range ladders {
+		if err := l.Validate(); err != nil {
+			return nil, err
+		}
+		sinks := make([]*notifier.Notifier, 0, len(l.Steps))
+		for _, step := range l.Steps {
+			n, err := notifier.New(registry, step.Sinks)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, n)
+		}
+		t.sinks = append(t.sinks, sinks)
+	}
+	t.routeFunc = func(ctx context.Context, ladder int, step int, ev apiv1.Event) {
+		t.sinks[ladder][step].Route(ctx, ev)
+	}
+	return t, nil
+}
+
+// SetDisruptions holds the escalation of the components inside the windows of
+// the expected disruption, while still tracking how long they have been unhealthy,
+// so that the steps reached are run after the windows if still unhealthy.
+func (t *Tracker) SetDisruptions(ws *pkgdisruption.Windows) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.disruptions = ws
+}
+
+// Start polls the health states in the background until the context is canceled.
+func (t *Tracker) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			t.observe(ctx)
+		}
+	}()
+}
+
+// observe updates the unhealthy durations of the last health states of all
+// the components, and runs the steps newly reached since the previous poll.
+func (t *Tracker) observe(ctx context.Context) {
+	now := t.nowFunc()
+
+	type reached struct {
+		key    stateKey
+		st     apiv1.HealthState
+		ladder int
+		step   int
+		since  time.Time
+	}
+	var toRun []reached
+
+	t.mu.Lock()
+	changed := false
+	seen := make(map[stateKey]struct{})
+	for _, comp := range t.registry.All() {
+		for _, hs := range comp.LastHealthStates() {
+			key := keyOf(comp.Name(), hs)
+			// the synthetic states injected by the simulation
+			// are not escalated, not to run the step actions
+			if !isUnhealthy(hs.Health) || hs.ExtraInfo[apiv1.SyntheticExtraInfoKey] == "true" {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			cur, ok := t.states[key]
+			if !ok {
+				cur = &state{since: now}
+				t.states[key] = cur
+				changed = true
+			}
+
+			li := t.ladderOf(comp.Name())
+			if li < 0 {
+				continue
+			}
+			if id := t.disruptions.Match(comp.Name(), now); id != "" {
+				continue
+			}
+			level := reachedLevel(t.ladders[li], now.Sub(cur.since))
+			for step := cur.level; step < level; step++ {
+				toRun = append(toRun, reached{key: key, st: hs, ladder: li, step: step, since: cur.since})
+			}
+			if level != cur.level {
+				cur.level = level
+				changed = true
+			}
+		}
+	}
+	// the healthy (or removed) states are reset
+	for key := range t.states {
+		if _, ok := seen[key]; !ok {
+			delete(t.states, key)
+			changed = true
+		}
+	}
+	// persisted before running the steps, so that the steps
+	// already run are not run again after the restart
+	if changed {
+		if err := t.persistStatesLocked(ctx); err != nil {
+			log.Logger.Warnw("failed to persist escalation states", "error", err)
+		}
+	}
+	t.mu.Unlock()
+
+	for _, r := range toRun {
+		t.escalate(ctx, r.key, r.st, r.ladder, r.step, r.since, now)
+	}
+}
+
+// escalate notifies the step sinks and runs the step action.
+func (t *Tracker) escalate(ctx context.Context, key stateKey, hs apiv1.HealthState, ladder int, step int, since time.Time, now time.Time) {
+	l := t.ladders[ladder]
+	s := l.Steps[step]
+	level := step + 1
+	unhealthyFor := now.Sub(since).Round(time.Second)
+
+	log.Logger.Warnw("escalating unhealthy health state",
+		"ladder", l.Name,
+		"component", key.component,
+		"name", key.name,
+		"level", level,
+		"severity", s.Severity,
+		"unhealthyFor", unhealthyFor,
+	)
+
+	t.routeFunc(ctx, ladder, step, apiv1.Event{
+		Component: key.component,
+		Time:      metav1.NewTime(now),
+		Name:      EventNameEscalated,
+		Type:      s.Severity,
+		Message:   fmt.Sprintf("%s %s for %v, escalated to level %d (%s) by ladder %q: %s", key.name, hs.Health, unhealthyFor, level, s.Severity, l.Name, hs.Reason),
+	})
+
+	if s.Action == "" {
+		return
+	}
+	envs := []string{
+		"GPUD_ESCALATION_LADDER=" + l.Name,
+		"GPUD_ESCALATION_COMPONENT=" + key.component,
+		"GPUD_ESCALATION_NAME=" + key.name,
+		"GPUD_ESCALATION_LEVEL=" + strconv.Itoa(level),
+		"GPUD_ESCALATION_SEVERITY=" + string(s.Severity),
+		"GPUD_ESCALATION_REASON=" + hs.Reason,
+	}
+	if err := t.runActionFunc(ctx, s.Action, envs); err != nil {
+		log.Logger.Warnw("failed to run escalation action", "ladder", l.Name, "component", key.component, "name", key.name, "level", level, "error", err)
+	}
+}
+
+// Escalates returns true if the unhealthy states of the component
+// are escalated by any of the ladders.
+func (t *Tracker) Escalates(component string) bool {
+	if t == nil {
+		return false
+	}
+	return t.ladderOf(component) >= 0
+}
+
+// ApplyToHealthStates returns the copy of the health states of the component
+// with the severity, the escalation level, and the unhealthy since time.
+// The unhealthy state not yet polled is at the base severity since now.
+// The states are copied since they may be shared with the component cache.
+func (t *Tracker) ApplyToHealthStates(component string, states apiv1.HealthStates) apiv1.HealthStates {
+	if t == nil || len(states) == 0 {
+		return states
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.nowFunc()
+	li := t.ladderOf(component)
+
+	out := make(apiv1.HealthStates, len(states))
+	for i, hs := range states {
+		if isUnhealthy(hs.Health) {
+			since, level := now, 0
+			if cur, ok := t.states[keyOf(component, hs)]; ok {
+				since, level = cur.since, cur.level
+			}
+
+			hs.Severity = BaseSeverity
+			if li >= 0 && level > 0 {
+				hs.Severity = t.ladders[li].Steps[level-1].Severity
+			}
+			hs.EscalationLevel = level
+			hs.UnhealthySince = &metav1.Time{Time: since.UTC()}
+		}
+		out[i] = hs
+	}
+	return out
+}
+
+// ladderOf returns the index of the first ladder matching the component, -1 if none.
+func (t *Tracker) ladderOf(component string) int {
+	for i, l := range t.ladders {
+		if l.Matches(component) {
+			return i
+		}
+	}
+	return -1
+}
+
+// reachedLevel returns the number of the steps reached after being unhealthy for the duration.
+func reachedLevel(l Ladder, unhealthyFor time.Duration) int {
+	level := 0
+	for _, step := range l.Steps {
+		if unhealthyFor < step.After.Duration {
+			break
+		}
+		level++
+	}
+	return level
+}
+
+func keyOf(component string, hs apiv1.HealthState) stateKey {
+	if hs.Component != "" {
+		component = hs.Component
+	}
+	return stateKey{component: component, name: hs.Name}
+}
+
+func isUnhealthy(h apiv1.HealthStateType) bool {
+	return h == apiv1.HealthStateTypeUnhealthy || h == apiv1.HealthStateTypeDegraded
+}
+
+func runAction(ctx context.Context, action string, envs []string) error {
+	res, err := toolexec.Run(ctx, []string{"bash", "-c", action}, toolexec.WithTimeout(defaultActionTimeout), toolexec.WithEnvs(envs...))
+	if err != nil {
+		return err
+	}
+	if res.ExitCode != 0 {
+		return fmt.Errorf("escalation action exited %d: %s", res.ExitCode, string(res.Output))
+	}
+	return nil
+}
@@ -0,0 +1,314 @@
+package escalation
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	pkgdisruption "github.com/leptonai/gpud/pkg/disruption"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+type mockComponent struct {
+	components.Component
+
+	name string
+
+	mu     sync.Mutex
+	states apiv1.HealthStates
+}
+
+func (c *mockComponent) Name() string { return c.name }
+
+func (c *mockComponent) LastHealthStates() apiv1.HealthStates {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.states
+}
+
+func (c *mockComponent) set(states apiv1.HealthStates) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.states = states
+}
+
+type routed struct {
+	ladder int
+	step   int
+	ev     apiv1.Event
+}
+
+func TestTracker(t *testing.T) {
+	ctx := context.Background()
+
+	gpu := &mockComponent{name: "accelerator-nvidia-infiniband"}
+	mem := &mockComponent{name: "memory"}
+	registry := components.NewRegistry(&components.GPUdInstance{})
+	for _, c := range []*mockComponent{gpu, mem} {
+		comp := c
+		_, err := registry.Register(func(*components.GPUdInstance) (components.Component, error) { return comp, nil })
+		require.NoError(t, err)
+	}
+
+	tr, err := NewTracker(registry, []Ladder{{
+		Name:       "gpu",
+		Components: []string{"accelerator-nvidia-*"},
+		Steps: []Step{
+			{After: metav1.Duration{Duration: 10 * time.Minute}, Severity: apiv1.EventTypeCritical},
+			{After: metav1.Duration{Duration: time.Hour}, Severity: apiv1.EventTypeFatal, Action: "cordon"},
+		},
+	}})
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 0).UTC()
+	tr.nowFunc = func() time.Time { return now }
+
+	var sent []routed
+	tr.routeFunc = func(_ context.Context, ladder int, step int, ev apiv1.Event) {
+		sent = append(sent, routed{ladder: ladder, step: step, ev: ev})
+	}
+	var actions [][]string
+	tr.runActionFunc = func(_ context.Context, action string, envs []string) error {
+		actions = append(actions, append([]string{action}, envs...))
+		return nil
+	}
+
+	start := now
+	gpu.set(apiv1.HealthStates{{Name: "ib", Health: apiv1.HealthStateTypeUnhealthy, Reason: "port down"}})
+	mem.set(apiv1.HealthStates{{Name: "memory", Health: apiv1.HealthStateTypeDegraded}})
+	tr.observe(ctx)
+	assert.Empty(t, sent)
+
+	states := tr.ApplyToHealthStates(gpu.name, gpu.LastHealthStates())
+	require.Len(t, states, 1)
+	assert.Equal(t, BaseSeverity, states[0].Severity)
+	assert.Zero(t, states[0].EscalationLevel)
+	assert.True(t, start.Equal(states[0].UnhealthySince.Time))
+	// the component cache is not modified
+	assert.Empty(t, gpu.LastHealthStates()[0].Severity)
+
+	// escalated to critical
+	now = start.Add(15 * time.Minute)
+	tr.observe(ctx)
+	require.Len(t, sent, 1)
+	assert.Equal(t, 0, sent[0].step)
+	assert.Equal(t, gpu.name, sent[0].ev.Component)
+	assert.Equal(t, EventNameEscalated, sent[0].ev.Name)
+	assert.Equal(t, apiv1.EventTypeCritical, sent[0].ev.Type)
+	assert.Contains(t, sent[0].ev.Message, "port down")
+	assert.Empty(t, actions)
+
+	states = tr.ApplyToHealthStates(gpu.name, gpu.LastHealthStates())
+	assert.Equal(t, apiv1.EventTypeCritical, states[0].Severity)
+	assert.Equal(t, 1, states[0].EscalationLevel)
+	assert.True(t, start.Equal(states[0].UnhealthySince.Time))
+
+	// no ladder matches the memory component
+	states = tr.ApplyToHealthStates(mem.name, mem.LastHealthStates())
+	assert.Equal(t, BaseSeverity, states[0].Severity)
+	assert.Zero(t, states[0].EscalationLevel)
+
+	// not escalated again at the same level
+	now = start.Add(20 * time.Minute)
+	tr.observe(ctx)
+	require.Len(t, sent, 1)
+
+	// escalated to fatal with the action
+	now = start.Add(2 * time.Hour)
+	tr.observe(ctx)
+	require.Len(t, sent, 2)
+	assert.Equal(t, 1, sent[1].step)
+	assert.Equal(t, apiv1.EventTypeFatal, sent[1].ev.Type)
+	require.Len(t, actions, 1)
+	assert.Equal(t, "cordon", actions[0][0])
+	assert.Contains(t, actions[0], "GPUD_ESCALATION_LEVEL=2")
+	assert.Contains(t, actions[0], "GPUD_ESCALATION_COMPONENT=accelerator-nvidia-infiniband")
+
+	// healthy resets the escalation
+	gpu.set(apiv1.HealthStates{{Name: "ib", Health: apiv1.HealthStateTypeHealthy}})
+	tr.observe(ctx)
+	states = tr.ApplyToHealthStates(gpu.name, gpu.LastHealthStates())
+	assert.Empty(t, states[0].Severity)
+	assert.Zero(t, states[0].EscalationLevel)
+	assert.Nil(t, states[0].UnhealthySince)
+
+	// unhealthy again starts from the base severity
+	restart := start.Add(3 * time.Hour)
+	now = restart
+	gpu.set(apiv1.HealthStates{{Name: "ib", Health: apiv1.HealthStateTypeUnhealthy}})
+	tr.observe(ctx)
+	states = tr.ApplyToHealthStates(gpu.name, gpu.LastHealthStates())
+	assert.Equal(t, BaseSeverity, states[0].Severity)
+	assert.True(t, restart.Equal(states[0].UnhealthySince.Time))
+	require.Len(t, sent, 2)
+}
+
+func TestTrackerExpectedDisruption(t *testing.T) {
+	ctx := context.Background()
+
+	gpu := &mockComponent{name: "accelerator-nvidia-infiniband"}
+	registry := components.NewRegistry(&components.GPUdInstance{})
+	_, err := registry.Register(func(*components.GPUdInstance) (components.Component, error) { return gpu, nil })
+	require.NoError(t, err)
+
+	tr, err := NewTracker(registry, []Ladder{{
+		Name:       "gpu",
+		Components: []string{"accelerator-nvidia-*"},
+		Steps: []Step{
+			{After: metav1.Duration{Duration: 10 * time.Minute}, Severity: apiv1.EventTypeCritical, Action: "cordon"},
+		},
+	}})
+	require.NoError(t, err)
+
+	start := time.Unix(1700000000, 0).UTC()
+	now := start
+	tr.nowFunc = func() time.Time { return now }
+	var sent []routed
+	tr.routeFunc = func(_ context.Context, ladder int, step int, ev apiv1.Event) {
+		sent = append(sent, routed{ladder: ladder, step: step, ev: ev})
+	}
+	actions := 0
+	tr.runActionFunc = func(context.Context, string, []string) error {
+		actions++
+		return nil
+	}
+
+	ws := pkgdisruption.New()
+	_, err = ws.Add(now, apiv1.DisruptionWindow{
+		Components: []string{"accelerator-nvidia-infiniband"},
+		Start:      metav1.NewTime(start),
+		End:        metav1.NewTime(start.Add(time.Hour)),
+	}, nil)
+	require.NoError(t, err)
+	tr.SetDisruptions(ws)
+
+	gpu.set(apiv1.HealthStates{{Name: "ib", Health: apiv1.HealthStateTypeUnhealthy}})
+	tr.observe(ctx)
+
+	// not escalated inside the window
+	now = start.Add(30 * time.Minute)
+	tr.observe(ctx)
+	assert.Empty(t, sent)
+	assert.Zero(t, actions)
+
+	// escalated after the window, still unhealthy since the start
+	now = start.Add(2 * time.Hour)
+	tr.observe(ctx)
+	require.Len(t, sent, 1)
+	assert.Equal(t, 1, actions)
+	states := tr.ApplyToHealthStates(gpu.name, gpu.LastHealthStates())
+	assert.True(t, start.Equal(states[0].UnhealthySince.Time))
+}
+
+func TestTrackerSynthetic(t *testing.T) {
+	ctx := context.Background()
+
+	gpu := &mockComponent{name: "accelerator-nvidia-infiniband"}
+	registry := components.NewRegistry(&components.GPUdInstance{})
+	_, err := registry.Register(func(*components.GPUdInstance) (components.Component, error) { return gpu, nil })
+	require.NoError(t, err)
+
+	tr, err := NewTracker(registry, []Ladder{{
+		Name:       "gpu",
+		Components: []string{"accelerator-nvidia-*"},
+		Steps:      []Step{{After: metav1.Duration{Duration: 10 * time.Minute}, Severity: apiv1.EventTypeCritical, Action: "cordon"}},
+	}})
+	require.NoError(t, err)
+	assert.True(t, tr.Escalates(gpu.name))
+	assert.False(t, tr.Escalates("memory"))
+
+	now := time.Unix(1700000000, 0).UTC()
+	tr.nowFunc = func() time.Time { return now }
+	actions := 0
+	tr.runActionFunc = func(context.Context, string, []string) error {
+		actions++
+		return nil
+	}
+	tr.routeFunc = func(context.Context, int, int, apiv1.Event) {}
+
+	gpu.set(apiv1.HealthStates{{Name: "ib", Health: apiv1.HealthStateTypeUnhealthy, ExtraInfo: map[string]string{apiv1.SyntheticExtraInfoKey: "true"}}})
+	tr.observe(ctx)
+	now = now.Add(time.Hour)
+	tr.observe(ctx)
+	assert.Zero(t, actions)
+	assert.Empty(t, tr.states)
+}
+
+func TestTrackerLoadStates(t *testing.T) {
+	ctx := context.Background()
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+	require.NoError(t, pkgmetadata.CreateTableMetadata(ctx, dbRW))
+
+	gpu := &mockComponent{name: "accelerator-nvidia-infiniband"}
+	registry := components.NewRegistry(&components.GPUdInstance{})
+	_, err := registry.Register(func(*components.GPUdInstance) (components.Component, error) { return gpu, nil })
+	require.NoError(t, err)
+	gpu.set(apiv1.HealthStates{{Name: "ib", Health: apiv1.HealthStateTypeUnhealthy}})
+
+	start := time.Unix(1700000000, 0).UTC()
+	now := start
+	actions := 0
+	newTracker := func() *Tracker {
+		tr, err := NewTracker(registry, []Ladder{{
+			Name:       "gpu",
+			Components: []string{"accelerator-nvidia-*"},
+			Steps:      []Step{{After: metav1.Duration{Duration: 10 * time.Minute}, Severity: apiv1.EventTypeCritical, Action: "cordon"}},
+		}})
+		require.NoError(t, err)
+		require.NoError(t, tr.LoadStates(ctx, dbRW, dbRO))
+		tr.nowFunc = func() time.Time { return now }
+		tr.routeFunc = func(context.Context, int, int, apiv1.Event) {}
+		tr.runActionFunc = func(context.Context, string, []string) error {
+			actions++
+			return nil
+		}
+		return tr
+	}
+
+	tr := newTracker()
+	tr.observe(ctx)
+	now = start.Add(15 * time.Minute)
+	tr.observe(ctx)
+	assert.Equal(t, 1, actions)
+
+	// the unhealthy duration and the steps already run survive the restart
+	tr = newTracker()
+	now = start.Add(20 * time.Minute)
+	tr.observe(ctx)
+	assert.Equal(t, 1, actions)
+	states := tr.ApplyToHealthStates(gpu.name, gpu.LastHealthStates())
+	assert.True(t, start.Equal(states[0].UnhealthySince.Time))
+	assert.Equal(t, 1, states[0].EscalationLevel)
+
+	// the state healthy after the restart is reset
+	gpu.set(apiv1.HealthStates{{Name: "ib", Health: apiv1.HealthStateTypeHealthy}})
+	tr.observe(ctx)
+	tr = newTracker()
+	assert.Empty(t, tr.states)
+}
+
+func TestTrackerNil(t *testing.T) {
+	var tr *Tracker
+	states := apiv1.HealthStates{{Name: "a", Health: apiv1.HealthStateTypeUnhealthy}}
+	assert.Equal(t, states, tr.ApplyToHealthStates("a", states))
+}
+
+func TestReachedLevel(t *testing.T) {
+	l := Ladder{Steps: []Step{
+		{After: metav1.Duration{Duration: 10 * time.Minute}},
+		{After: metav1.Duration{Duration: time.Hour}},
+	}}
+	assert.Equal(t, 0, reachedLevel(l, 0))
+	assert.Equal(t, 0, reachedLevel(l, 9*time.Minute))
+	assert.Equal(t, 1, reachedLevel(l, 10*time.Minute))
+	assert.Equal(t, 2, reachedLevel(l, 2*time.Hour))
+}
@@ -0,0 +1,85 @@
+package escalation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+)
+
+// persistedState is the unhealthy state persisted in the metadata table.
+type persistedState struct {
+	Component string    `json:"component"`
+	Name      string    `json:"name"`
+	Since     time.Time `json:"since"`
+	Level     int       `json:"level,omitempty"`
+}
+
+// LoadStates loads the unhealthy states persisted in the metadata table,
+// and persists the states from now on whenever they change, so that
+// the unhealthy durations and the steps already run survive the restarts.
+// The loaded states healthy after the restart are reset on the next poll.
+func (t *Tracker) LoadStates(ctx context.Context, dbRW *sql.DB, dbRO *sql.DB) error {
+	if t == nil {
+		return nil
+	}
+
+	persisted, err := readStates(ctx, dbRO)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, ps := range persisted {
+		key := stateKey{component: ps.Component, name: ps.Name}
+		if _, ok := t.states[key]; ok {
+			continue
+		}
+		t.states[key] = &state{since: ps.Since, level: ps.Level}
+	}
+	t.dbRW = dbRW
+	return nil
+}
+
+// persistStatesLocked persists the states, if enabled.
+// The caller must hold the lock.
+func (t *Tracker) persistStatesLocked(ctx context.Context) error {
+	if t.dbRW == nil {
+		return nil
+	}
+
+	persisted := make([]persistedState, 0, len(t.states))
+	for key, st := range t.states {
+		persisted = append(persisted, persistedState{
+			Component: key.component,
+			Name:      key.name,
+			Since:     st.since,
+			Level:     st.level,
+		})
+	}
+	b, err := json.Marshal(persisted)
+	if err != nil {
+		return err
+	}
+	return pkgmetadata.SetMetadata(ctx, t.dbRW, pkgmetadata.MetadataKeyEscalationStates, string(b))
+}
+
+func readStates(ctx context.Context, dbRO *sql.DB) ([]persistedState, error) {
+	raw, err := pkgmetadata.ReadMetadata(ctx, dbRO, pkgmetadata.MetadataKeyEscalationStates)
+	if err != nil {
+		return nil, err
+	}
+	if raw == "" {
+		return nil, nil
+	}
+
+	var persisted []persistedState
+	if err := json.Unmarshal([]byte(raw), &persisted); err != nil {
+		return nil, fmt.Errorf("failed to parse escalation states: %w", err)
+	}
+	return persisted, nil
+}
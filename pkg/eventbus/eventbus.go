@@ -0,0 +1,132 @@
+// Package eventbus implements the in-process event bus between the components,
+// so that a component can consume the findings of the other components
+// (e.g., the PCI component consumes the Xid events) as they happen,
+// rather than periodically querying the other components' event buckets.
+package eventbus
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/leptonai/gpud/pkg/eventstore"
+)
+
+// DefaultBufferSize is the default number of the events buffered per subscriber.
+const DefaultBufferSize = 256
+
+// Bus fans out the published events to the subscribers.
+//
+// All the methods are safe to call on the nil bus, in which case
+// the events are discarded and the subscribers never receive.
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[*Subscriber]struct{}
+}
+
+// New creates a new event bus.
+func New() *Bus {
+	return &Bus{
+		subs: make(map[*Subscriber]struct{}),
+	}
+}
+
+// Subscriber receives the published events that match its filter.
+type Subscriber struct {
+	components map[string]struct{}
+	ch         chan eventstore.Event
+	dropped    atomic.Uint64
+}
+
+// C returns the channel that receives the events.
+// Returns the nil channel for the nil subscriber, which blocks forever in select.
+func (s *Subscriber) C() <-chan eventstore.Event {
+	if s == nil {
+		return nil
+	}
+	return s.ch
+}
+
+// Dropped returns the number of the events dropped
+// because the subscriber did not keep up.
+func (s *Subscriber) Dropped() uint64 {
+	if s == nil {
+		return 0
+	}
+	return s.dropped.Load()
+}
+
+func (s *Subscriber) matches(component string) bool {
+	if len(s.components) == 0 {
+		return true
+	}
+	_, ok := s.components[component]
+	return ok
+}
+
+// Subscribe registers a new subscriber.
+// Returns nil if the bus is nil.
+func (b *Bus) Subscribe(opts ...OpOption) *Subscriber {
+	if b == nil {
+		return nil
+	}
+
+	op := &Op{}
+	op.applyOpts(opts)
+
+	s := &Subscriber{
+		ch: make(chan eventstore.Event, op.bufferSize),
+	}
+	if len(op.components) > 0 {
+		s.components = make(map[string]struct{}, len(op.components))
+		for _, name := range op.components {
+			s.components[name] = struct{}{}
+		}
+	}
+
+	b.mu.Lock()
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+
+	return s
+}
+
+// Unsubscribe removes the subscriber from the bus.
+// The subscriber channel is not closed, so that the
+// concurrent readers do not receive the zero event.
+func (b *Bus) Unsubscribe(s *Subscriber) {
+	if b == nil || s == nil {
+		return
+	}
+
+	b.mu.Lock()
+	delete(b.subs, s)
+	b.mu.Unlock()
+}
+
+// Publish sends the event to the matching subscribers without blocking,
+// and returns the number of the subscribers that received the event.
+// The event is dropped for the subscribers whose buffer is full.
+//
+// The event component must be set, which the subscribers filter on.
+func (b *Bus) Publish(ev eventstore.Event) int {
+	if b == nil {
+		return 0
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	delivered := 0
+	for s := range b.subs {
+		if !s.matches(ev.Component) {
+			continue
+		}
+		select {
+		case s.ch <- ev:
+			delivered++
+		default:
+			s.dropped.Add(1)
+		}
+	}
+	return delivered
+}
@@ -0,0 +1,63 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/eventstore"
+)
+
+func TestBusPublishSubscribe(t *testing.T) {
+	b := New()
+
+	all := b.Subscribe()
+	xidOnly := b.Subscribe(WithComponents("xid"))
+
+	assert.Equal(t, 2, b.Publish(eventstore.Event{Component: "xid", Name: "error_xid"}))
+	assert.Equal(t, 1, b.Publish(eventstore.Event{Component: "sxid", Name: "error_sxid"}))
+
+	ev := <-all.C()
+	assert.Equal(t, "xid", ev.Component)
+	ev = <-all.C()
+	assert.Equal(t, "sxid", ev.Component)
+
+	ev = <-xidOnly.C()
+	assert.Equal(t, "error_xid", ev.Name)
+	select {
+	case ev = <-xidOnly.C():
+		t.Fatalf("unexpected event %+v", ev)
+	default:
+	}
+
+	b.Unsubscribe(all)
+	assert.Equal(t, 1, b.Publish(eventstore.Event{Component: "xid"}))
+}
+
+func TestBusDropsWhenFull(t *testing.T) {
+	b := New()
+	s := b.Subscribe(WithBufferSize(1))
+
+	assert.Equal(t, 1, b.Publish(eventstore.Event{Component: "a", Name: "first"}))
+	assert.Equal(t, 0, b.Publish(eventstore.Event{Component: "a", Name: "second"}))
+	assert.Equal(t, uint64(1), s.Dropped())
+
+	select {
+	case ev := <-s.C():
+		assert.Equal(t, "first", ev.Name)
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+}
+
+func TestBusNil(t *testing.T) {
+	var b *Bus
+	s := b.Subscribe()
+	require.Nil(t, s)
+	assert.Nil(t, s.C())
+	assert.Zero(t, s.Dropped())
+	assert.Equal(t, 0, b.Publish(eventstore.Event{Component: "xid"}))
+	b.Unsubscribe(s)
+}
@@ -0,0 +1,33 @@
+package eventbus
+
+type Op struct {
+	components []string
+	bufferSize int
+}
+
+type OpOption func(*Op)
+
+func (op *Op) applyOpts(opts []OpOption) {
+	for _, opt := range opts {
+		opt(op)
+	}
+
+	if op.bufferSize <= 0 {
+		op.bufferSize = DefaultBufferSize
+	}
+}
+
+// WithComponents only receives the events published by the components.
+// If not specified, the subscriber receives the events from all the components.
+func WithComponents(names ...string) OpOption {
+	return func(op *Op) {
+		op.components = append(op.components, names...)
+	}
+}
+
+// WithBufferSize sets the number of the events buffered for the subscriber.
+func WithBufferSize(n int) OpOption {
+	return func(op *Op) {
+		op.bufferSize = n
+	}
+}
@@ -0,0 +1,206 @@
+package eventstore
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// DefaultBatchSize is the default number of the buffered events
+	// that triggers the flush.
+	DefaultBatchSize = 100
+
+	// DefaultFlushInterval is the default interval to flush the buffered events.
+	DefaultFlushInterval = time.Second
+)
+
+var _ Bucket = &BufferedBucket{}
+
+// BufferedBucket buffers the inserted events in memory, and writes them
+// to the underlying bucket in a single transaction once the buffer is full
+// or the flush interval elapses, to reduce the write amplification and
+// the SQLite lock contention when many events are inserted at once
+// (e.g., kernel message storms).
+//
+// The buffered events are visible to "Find" (to dedup), and flushed
+// before the other reads, but not to the readers of the underlying bucket
+// until flushed.
+type BufferedBucket struct {
+	bucket        Bucket
+	batchSize     int
+	flushInterval time.Duration
+	// closes the underlying bucket on close (see WithBuffer)
+	closeBucket bool
+
+	rootCtx    context.Context
+	rootCancel context.CancelFunc
+	closeOnce  sync.Once
+	done       chan struct{}
+
+	// serializes the flushes to write the batches in the insertion order
+	flushMu sync.Mutex
+
+	mu      sync.Mutex
+	pending Events
+}
+
+// NewBufferedBucket creates a new buffered bucket that writes to the bucket.
+// Zero or negative batch size or flush interval uses the default.
+// Closing the buffered bucket flushes the pending events,
+// but does not close the underlying bucket.
+func NewBufferedBucket(bucket Bucket, batchSize int, flushInterval time.Duration) *BufferedBucket {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	if flushInterval <= 0 {
+		flushInterval = DefaultFlushInterval
+	}
+
+	rootCtx, rootCancel := context.WithCancel(context.Background())
+	b := &BufferedBucket{
+		bucket:        bucket,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		rootCtx:       rootCtx,
+		rootCancel:    rootCancel,
+		done:          make(chan struct{}),
+	}
+	go b.runFlush()
+	return b
+}
+
+func (b *BufferedBucket) runFlush() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.rootCtx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		cctx, ccancel := context.WithTimeout(b.rootCtx, 15*time.Second)
+		err := b.Flush(cctx)
+		ccancel()
+		if err != nil {
+			log.Logger.Errorw("failed to flush buffered events", "bucket", b.bucket.Name(), "error", err)
+		}
+	}
+}
+
+func (b *BufferedBucket) Name() string {
+	return b.bucket.Name()
+}
+
+// Insert buffers the event, and flushes the buffer if full.
+func (b *BufferedBucket) Insert(ctx context.Context, ev Event) error {
+	b.mu.Lock()
+	b.pending = append(b.pending, ev)
+	full := len(b.pending) >= b.batchSize
+	b.mu.Unlock()
+
+	if !full {
+		return nil
+	}
+	return b.Flush(ctx)
+}
+
+// Flush writes the buffered events to the underlying bucket.
+// The events are dropped if the write fails, to bound the memory usage.
+func (b *BufferedBucket) Flush(ctx context.Context) error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	b.mu.Lock()
+	evs := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+
+	if len(evs) == 0 {
+		return nil
+	}
+
+	if bi, ok := b.bucket.(BatchInserter); ok {
+		return bi.InsertBatch(ctx, evs)
+	}
+	for _, ev := range evs {
+		if err := b.bucket.Insert(ctx, ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Find returns the buffered event if any, otherwise queries the underlying bucket.
+func (b *BufferedBucket) Find(ctx context.Context, ev Event) (*Event, error) {
+	// waits for the in-flight flush, if any, not to miss its events
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	b.mu.Lock()
+	for _, pending := range b.pending {
+		if pending.Time.Unix() != ev.Time.Unix() || pending.Name != ev.Name || pending.Type != ev.Type {
+			continue
+		}
+		if ev.Message != "" && pending.Message != ev.Message {
+			continue
+		}
+		if compareEvent(pending, ev) {
+			found := pending
+			b.mu.Unlock()
+			return &found, nil
+		}
+	}
+	b.mu.Unlock()
+
+	return b.bucket.Find(ctx, ev)
+}
+
+// Get flushes the buffered events, and queries the underlying bucket.
+func (b *BufferedBucket) Get(ctx context.Context, since time.Time) (Events, error) {
+	if err := b.Flush(ctx); err != nil {
+		return nil, err
+	}
+	return b.bucket.Get(ctx, since)
+}
+
+// Latest flushes the buffered events, and queries the underlying bucket.
+func (b *BufferedBucket) Latest(ctx context.Context) (*Event, error) {
+	if err := b.Flush(ctx); err != nil {
+		return nil, err
+	}
+	return b.bucket.Latest(ctx)
+}
+
+// Purge flushes the buffered events, and purges the underlying bucket.
+func (b *BufferedBucket) Purge(ctx context.Context, beforeTimestamp int64) (int, error) {
+	if err := b.Flush(ctx); err != nil {
+		return 0, err
+	}
+	return b.bucket.Purge(ctx, beforeTimestamp)
+}
+
+// Close stops the periodic flush, and flushes the pending events.
+// The underlying bucket is closed only if opened with WithBuffer.
+func (b *BufferedBucket) Close() {
+	b.closeOnce.Do(func() {
+		b.rootCancel()
+		<-b.done
+
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		if err := b.Flush(ctx); err != nil {
+			log.Logger.Errorw("failed to flush buffered events on close", "bucket", b.bucket.Name(), "error", err)
+		}
+
+		if b.closeBucket {
+			b.bucket.Close()
+		}
+	})
+}
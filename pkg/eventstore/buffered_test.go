@@ -0,0 +1,175 @@
+package eventstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestInsertBatch(t *testing.T) {
+	t.Parallel()
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	store, err := New(dbRW, dbRO, 0)
+	require.NoError(t, err)
+	bucket, err := store.Bucket("test")
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	bi, ok := bucket.(BatchInserter)
+	require.True(t, ok)
+
+	baseTime := time.Now().UTC()
+	require.NoError(t, bi.InsertBatch(ctx, nil))
+	require.NoError(t, bi.InsertBatch(ctx, Events{
+		{Time: baseTime, Name: "first", Type: string(apiv1.EventTypeWarning), ExtraInfo: map[string]string{"a": "b"}},
+		{Time: baseTime, Name: "second", Type: string(apiv1.EventTypeWarning)},
+		{Time: baseTime, Name: "third", Type: string(apiv1.EventTypeWarning), Message: "msg"},
+	}))
+	require.NoError(t, bucket.Insert(ctx, Event{Time: baseTime, Name: "fourth", Type: string(apiv1.EventTypeWarning)}))
+
+	evs, err := bucket.Get(ctx, baseTime.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Len(t, evs, 4)
+
+	// consecutive sequence numbers in the batch order
+	assert.Equal(t, "fourth", evs[0].Name)
+	assert.Equal(t, "third", evs[1].Name)
+	assert.Equal(t, "msg", evs[1].Message)
+	assert.Equal(t, "second", evs[2].Name)
+	assert.Equal(t, "first", evs[3].Name)
+	assert.Equal(t, map[string]string{"a": "b"}, evs[3].ExtraInfo)
+	assert.Equal(t, evs[3].Seq+1, evs[2].Seq)
+	assert.Equal(t, evs[2].Seq+1, evs[1].Seq)
+	assert.Equal(t, evs[1].Seq+1, evs[0].Seq)
+}
+
+func TestBufferedBucket(t *testing.T) {
+	t.Parallel()
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	store, err := New(dbRW, dbRO, 0)
+	require.NoError(t, err)
+	bucket, err := store.Bucket("test")
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	// long interval to only flush on the batch size or the reads
+	buffered := NewBufferedBucket(bucket, 3, time.Hour)
+	assert.Equal(t, bucket.Name(), buffered.Name())
+
+	baseTime := time.Now().UTC()
+	ev1 := Event{Time: baseTime, Name: "first", Type: string(apiv1.EventTypeWarning), Message: "msg"}
+	ev2 := Event{Time: baseTime, Name: "second", Type: string(apiv1.EventTypeWarning)}
+	require.NoError(t, buffered.Insert(ctx, ev1))
+	require.NoError(t, buffered.Insert(ctx, ev2))
+
+	// not yet written to the underlying bucket
+	evs, err := bucket.Get(ctx, baseTime.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, evs)
+
+	// the buffered events are visible to find
+	found, err := buffered.Find(ctx, ev1)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "first", found.Name)
+	found, err = buffered.Find(ctx, Event{Time: baseTime, Name: "unknown", Type: string(apiv1.EventTypeWarning)})
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	// full batch flushes
+	require.NoError(t, buffered.Insert(ctx, Event{Time: baseTime, Name: "third", Type: string(apiv1.EventTypeWarning)}))
+	evs, err = bucket.Get(ctx, baseTime.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, evs, 3)
+
+	// reads flush
+	require.NoError(t, buffered.Insert(ctx, Event{Time: baseTime, Name: "fourth", Type: string(apiv1.EventTypeWarning)}))
+	latest, err := buffered.Latest(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.Equal(t, "fourth", latest.Name)
+
+	// close flushes
+	require.NoError(t, buffered.Insert(ctx, Event{Time: baseTime, Name: "fifth", Type: string(apiv1.EventTypeWarning)}))
+	buffered.Close()
+	buffered.Close()
+	evs, err = bucket.Get(ctx, baseTime.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Len(t, evs, 5)
+	assert.Equal(t, "fifth", evs[0].Name)
+}
+
+func TestBufferedBucketFlushInterval(t *testing.T) {
+	t.Parallel()
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	store, err := New(dbRW, dbRO, 0)
+	require.NoError(t, err)
+	bucket, err := store.Bucket("test")
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	buffered := NewBufferedBucket(bucket, 0, 50*time.Millisecond)
+	defer buffered.Close()
+
+	baseTime := time.Now().UTC()
+	require.NoError(t, buffered.Insert(ctx, Event{Time: baseTime, Name: "first", Type: string(apiv1.EventTypeWarning)}))
+
+	require.Eventually(t, func() bool {
+		evs, err := bucket.Get(ctx, baseTime.Add(-time.Minute))
+		return err == nil && len(evs) == 1
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+func TestBucketWithBuffer(t *testing.T) {
+	t.Parallel()
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	store, err := New(dbRW, dbRO, 0)
+	require.NoError(t, err)
+	buffered, err := store.Bucket("test", WithBuffer(10, time.Hour))
+	require.NoError(t, err)
+	require.IsType(t, &BufferedBucket{}, buffered)
+
+	baseTime := time.Now().UTC()
+	require.NoError(t, buffered.Insert(ctx, Event{Time: baseTime, Name: "first", Type: string(apiv1.EventTypeWarning)}))
+
+	// close flushes the pending events before closing the underlying bucket
+	buffered.Close()
+
+	bucket, err := store.Bucket("test")
+	require.NoError(t, err)
+	defer bucket.Close()
+	evs, err := bucket.Get(ctx, baseTime.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Len(t, evs, 1)
+	assert.Equal(t, "first", evs[0].Name)
+}
@@ -6,16 +6,18 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 
+	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/pkg/log"
 	pkgmetricsrecorder "github.com/leptonai/gpud/pkg/metrics/recorder"
 )
 
-// TODO: drop tables with "v0_4_0"
+// the tables with "v0_4_0" are dropped by the state file migrations (see "pkg/migrations")
 const schemaVersion = "v0_5_0"
 
 const (
@@ -40,11 +42,39 @@ const (
 	// event target: "gpu_id", "gpu_uuid".
 	// log detail: "oom_reaper: reaped process 345646 (vector), now anon-rss:0kB, file-rss:0kB, shmem-rss:0".
 	columnExtraInfo = "extra_info"
+
+	// columnSeq represents the monotonic sequence number of the event,
+	// shared by all the event tables, which orders the events by the insertion
+	// even when the wall clock jumps (e.g., NTP step, manual changes).
+	columnSeq = "seq"
+)
+
+// sequenceTableName is the table that tracks the last assigned event sequence number.
+const sequenceTableName = "gpud_events_sequence"
+
+const (
+	// PurgeAuditBucketName is the bucket that records what each purge deleted from the other buckets,
+	// so that the missing historical events can be distinguished from the never-recorded ones.
+	// Load with "WithDisablePurge" to read the audit events.
+	PurgeAuditBucketName = "eventstore-purges"
+
+	// PurgeAuditRetention is how long the purge audit events are kept for.
+	PurgeAuditRetention = 30 * 24 * time.Hour
+
+	// EventNamePurged is the name of the purge audit event.
+	EventNamePurged = "events_purged"
+
+	EventKeyPurgedBucket = "bucket"
+	EventKeyPurgedCount  = "count"
+	EventKeyPurgedOldest = "oldest"
+	EventKeyPurgedNewest = "newest"
+	EventKeyPurgedCutoff = "before"
 )
 
 var (
-	_ Store  = &database{}
-	_ Bucket = &table{}
+	_ Store         = &database{}
+	_ Bucket        = &table{}
+	_ BatchInserter = &table{}
 )
 
 type database struct {
@@ -89,8 +119,21 @@ func (d *database) Bucket(name string, opts ...OpOption) (Bucket, error) {
 		d.retention = 0
 		purgeInterval = 0
 	}
+	dbRO := d.dbRO
+	if op.dbRO != nil {
+		dbRO = op.dbRO
+	}
 
-	return newTable(d.dbRW, d.dbRO, name, d.retention, purgeInterval)
+	t, err := newTable(d.dbRW, dbRO, name, d.retention, purgeInterval)
+	if err != nil {
+		return nil, err
+	}
+	if op.buffered {
+		b := NewBufferedBucket(t, op.batchSize, op.flushInterval)
+		b.closeBucket = true
+		return b, nil
+	}
+	return t, nil
 }
 
 func (d *database) LoadBucketWithNoPurge(name string) (Bucket, error) {
@@ -123,7 +166,7 @@ func newTable(dbRW *sql.DB, dbRO *sql.DB, name string, retention time.Duration,
 }
 
 // defaultTableName creates the default table name for the component.
-// The table name is in the format of "components_{component_name}_events_v0_4_0".
+// The table name is in the format of "components_{component_name}_events_v0_5_0".
 // Suffix with the version, in case we change the table schema.
 func defaultTableName(componentName string) string {
 	c := strings.ReplaceAll(componentName, " ", "_")
@@ -168,23 +211,77 @@ func (t *table) Insert(ctx context.Context, ev Event) error {
 	return insertEvent(ctx, t.dbRW, t.table, ev)
 }
 
+// InsertBatch inserts the events in a single transaction.
+func (t *table) InsertBatch(ctx context.Context, evs Events) error {
+	return insertEvents(ctx, t.dbRW, t.table, evs)
+}
+
 // Find returns nil if the event is not found.
 func (t *table) Find(ctx context.Context, ev Event) (*Event, error) {
-	return findEvent(ctx, t.dbRO, t.table, ev)
+	return findEvent(ctx, readDB(ctx, t.dbRO), t.table, ev)
 }
 
 // Get queries the event in the descending order of timestamp (latest event first).
 func (t *table) Get(ctx context.Context, since time.Time) (Events, error) {
-	return getEvents(ctx, t.dbRO, t.table, since)
+	return getEvents(ctx, readDB(ctx, t.dbRO), t.table, since)
 }
 
 // Latest queries the latest event, returns nil if no event found.
 func (t *table) Latest(ctx context.Context) (*Event, error) {
-	return lastEvent(ctx, t.dbRO, t.table)
+	return lastEvent(ctx, readDB(ctx, t.dbRO), t.table)
 }
 
+// Purge deletes the events before the timestamp, and records the number of
+// deleted events and their time range in the purge audit bucket.
 func (t *table) Purge(ctx context.Context, beforeTimestamp int64) (int, error) {
-	return purgeEvents(ctx, t.dbRW, t.table, beforeTimestamp)
+	auditTable := defaultTableName(PurgeAuditBucketName)
+	if t.table == auditTable {
+		return purgeEvents(ctx, t.dbRW, t.table, beforeTimestamp)
+	}
+
+	purged, oldest, newest, err := purgeEventsWithRange(ctx, t.dbRW, t.table, beforeTimestamp)
+	if err != nil {
+		return 0, err
+	}
+	if purged == 0 {
+		return 0, nil
+	}
+
+	if err := createTable(ctx, t.dbRW, auditTable); err != nil {
+		return purged, fmt.Errorf("failed to create purge audit table: %w", err)
+	}
+	if err := insertEvent(ctx, t.dbRW, auditTable, newPurgeAuditEvent(t.table, purged, oldest, newest, beforeTimestamp)); err != nil {
+		return purged, fmt.Errorf("failed to record purge audit event: %w", err)
+	}
+
+	auditBefore := time.Now().UTC().Add(-PurgeAuditRetention).Unix()
+	if _, err := purgeEvents(ctx, t.dbRW, auditTable, auditBefore); err != nil {
+		log.Logger.Warnw("failed to purge old purge audit events", "error", err)
+	}
+
+	return purged, nil
+}
+
+func newPurgeAuditEvent(tableName string, purged int, oldest time.Time, newest time.Time, beforeTimestamp int64) Event {
+	return Event{
+		Component: PurgeAuditBucketName,
+		Time:      time.Now().UTC(),
+		Name:      EventNamePurged,
+		Type:      string(apiv1.EventTypeInfo),
+		Message: fmt.Sprintf("purged %d event(s) from %s between %s and %s",
+			purged,
+			tableName,
+			oldest.UTC().Format(time.RFC3339),
+			newest.UTC().Format(time.RFC3339),
+		),
+		ExtraInfo: map[string]string{
+			EventKeyPurgedBucket: tableName,
+			EventKeyPurgedCount:  strconv.Itoa(purged),
+			EventKeyPurgedOldest: oldest.UTC().Format(time.RFC3339),
+			EventKeyPurgedNewest: newest.UTC().Format(time.RFC3339),
+			EventKeyPurgedCutoff: time.Unix(beforeTimestamp, 0).UTC().Format(time.RFC3339),
+		},
+	}
 }
 
 func createTable(ctx context.Context, db *sql.DB, tableName string) error {
@@ -200,81 +297,187 @@ CREATE TABLE IF NOT EXISTS %s (
 	%s TEXT NOT NULL,
 	%s TEXT NOT NULL,
 	%s TEXT,
-	%s TEXT
+	%s TEXT,
+	%s INTEGER
 );`, tableName,
 		columnTimestamp,
 		columnName,
 		columnType,
 		columnMessage,
 		columnExtraInfo,
+		columnSeq,
 	))
 	if err != nil {
 		_ = tx.Rollback()
 		return err
 	}
 
-	_, err = tx.ExecContext(ctx, fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_%s_%s ON %s(%s);`,
-		tableName, columnTimestamp, tableName, columnTimestamp))
-	if err != nil {
+	if err = createSequenceTable(ctx, tx); err != nil {
 		_ = tx.Rollback()
 		return err
 	}
 
-	_, err = tx.ExecContext(ctx, fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_%s_%s ON %s(%s);`,
-		tableName, columnName, tableName, columnName))
-	if err != nil {
+	// the tables created before the sequence number was introduced
+	if err = addSeqColumnIfMissing(ctx, tx, tableName); err != nil {
 		_ = tx.Rollback()
 		return err
 	}
 
-	_, err = tx.ExecContext(ctx, fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_%s_%s ON %s(%s);`,
-		tableName, columnType, tableName, columnType))
+	for _, column := range []string{columnTimestamp, columnName, columnType, columnSeq} {
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_%s_%s ON %s(%s);`,
+			tableName, column, tableName, column))
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func createSequenceTable(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %s (
+	id INTEGER PRIMARY KEY,
+	value INTEGER NOT NULL
+);`, sequenceTableName))
 	if err != nil {
-		_ = tx.Rollback()
 		return err
 	}
 
-	return tx.Commit()
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`INSERT OR IGNORE INTO %s (id, value) VALUES (0, 0);`, sequenceTableName))
+	return err
+}
+
+// addSeqColumnIfMissing adds the sequence number column to the existing table,
+// and backfills the existing rows in the insertion order.
+func addSeqColumnIfMissing(ctx context.Context, tx *sql.Tx, tableName string) error {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`PRAGMA table_info(%s);`, tableName))
+	if err != nil {
+		return err
+	}
+	found := false
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		if name == columnSeq {
+			found = true
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	if found {
+		return nil
+	}
+
+	log.Logger.Infow("adding sequence number column", "table", tableName)
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s INTEGER;`, tableName, columnSeq)); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET %s = rowid + (SELECT value FROM %s WHERE id = 0);`,
+		tableName, columnSeq, sequenceTableName)); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET value = value + (SELECT COALESCE(MAX(rowid), 0) FROM %s) WHERE id = 0;`,
+		sequenceTableName, tableName))
+	return err
 }
 
 func insertEvent(ctx context.Context, db *sql.DB, tableName string, ev Event) error {
-	var extraInfoJSON []byte
-	if ev.ExtraInfo != nil {
-		var err error
-		extraInfoJSON, err = json.Marshal(ev.ExtraInfo)
+	return insertEvents(ctx, db, tableName, Events{ev})
+}
+
+// insertEvents inserts the events in a single transaction,
+// with the consecutive sequence numbers in the order of the slice.
+func insertEvents(ctx context.Context, db *sql.DB, tableName string, evs Events) error {
+	if len(evs) == 0 {
+		return nil
+	}
+
+	extraInfos := make([]string, len(evs))
+	for i, ev := range evs {
+		if ev.ExtraInfo == nil {
+			continue
+		}
+		b, err := json.Marshal(ev.ExtraInfo)
 		if err != nil {
 			return fmt.Errorf("failed to marshal extra info: %w", err)
 		}
+		extraInfos[i] = string(b)
 	}
 
 	start := time.Now()
-	_, err := db.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (%s, %s, %s, %s, %s) VALUES (?, ?, ?, NULLIF(?, ''), NULLIF(?, ''))",
+	defer func() {
+		pkgmetricsrecorder.RecordSQLiteInsertUpdate(time.Since(start).Seconds())
+	}()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	// reserves the sequence numbers for all the events at once
+	var lastSeq int64
+	err = tx.QueryRowContext(ctx, fmt.Sprintf(`UPDATE %s SET value = value + ? WHERE id = 0 RETURNING value`, sequenceTableName), len(evs)).Scan(&lastSeq)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to assign sequence number: %w", err)
+	}
+	seq := lastSeq - int64(len(evs)) + 1
+
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %s (%s, %s, %s, %s, %s, %s) VALUES (?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), ?)",
 		tableName,
 		columnTimestamp,
 		columnName,
 		columnType,
 		columnMessage,
 		columnExtraInfo,
-	),
-		ev.Time.Unix(),
-		ev.Name,
-		ev.Type,
-		ev.Message,
-		string(extraInfoJSON),
-	)
-	pkgmetricsrecorder.RecordSQLiteInsertUpdate(time.Since(start).Seconds())
+		columnSeq,
+	))
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for i, ev := range evs {
+		_, err = stmt.ExecContext(ctx,
+			ev.Time.Unix(),
+			ev.Name,
+			ev.Type,
+			ev.Message,
+			extraInfos[i],
+			seq+int64(i),
+		)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
 
-	return err
+	return tx.Commit()
 }
 
 func findEvent(ctx context.Context, db *sql.DB, tableName string, ev Event) (*Event, error) {
 	selectStatement := fmt.Sprintf(`
-SELECT %s, %s, %s, %s, %s FROM %s WHERE %s = ? AND %s = ? AND %s = ?`,
+SELECT %s, %s, %s, %s, %s, %s FROM %s WHERE %s = ? AND %s = ? AND %s = ?`,
 		columnTimestamp,
 		columnName,
 		columnType,
 		columnMessage,
 		columnExtraInfo,
+		columnSeq,
 		tableName,
 		columnTimestamp,
 		columnName,
@@ -315,14 +518,14 @@ SELECT %s, %s, %s, %s, %s FROM %s WHERE %s = ? AND %s = ? AND %s = ?`,
 
 // Returns the event in the descending order of timestamp (latest event first).
 func getEvents(ctx context.Context, db *sql.DB, tableName string, since time.Time) (Events, error) {
-	query := fmt.Sprintf(`SELECT %s, %s, %s, %s, %s
+	query := fmt.Sprintf(`SELECT %s, %s, %s, %s, %s, %s
 FROM %s
 WHERE %s > ?
-ORDER BY %s DESC`,
-		columnTimestamp, columnName, columnType, columnMessage, columnExtraInfo,
+ORDER BY %s DESC, %s DESC`,
+		columnTimestamp, columnName, columnType, columnMessage, columnExtraInfo, columnSeq,
 		tableName,
 		columnTimestamp,
-		columnTimestamp,
+		columnTimestamp, columnSeq,
 	)
 	params := []any{since.UTC().Unix()}
 
@@ -353,8 +556,8 @@ ORDER BY %s DESC`,
 }
 
 func lastEvent(ctx context.Context, db *sql.DB, tableName string) (*Event, error) {
-	query := fmt.Sprintf(`SELECT %s, %s, %s, %s, %s FROM %s ORDER BY %s DESC LIMIT 1`,
-		columnTimestamp, columnName, columnType, columnMessage, columnExtraInfo, tableName, columnTimestamp)
+	query := fmt.Sprintf(`SELECT %s, %s, %s, %s, %s, %s FROM %s ORDER BY %s DESC, %s DESC LIMIT 1`,
+		columnTimestamp, columnName, columnType, columnMessage, columnExtraInfo, columnSeq, tableName, columnTimestamp, columnSeq)
 
 	start := time.Now()
 	row := db.QueryRowContext(ctx, query)
@@ -376,18 +579,23 @@ func scanRow(row *sql.Row) (Event, error) {
 	var timestamp int64
 	var msg sql.NullString
 	var extraInfo sql.NullString
+	var seq sql.NullInt64
 	err := row.Scan(
 		&timestamp,
 		&event.Name,
 		&event.Type,
 		&msg,
 		&extraInfo,
+		&seq,
 	)
 	if err != nil {
 		return event, err
 	}
 
 	event.Time = time.Unix(timestamp, 0)
+	if seq.Valid {
+		event.Seq = seq.Int64
+	}
 	if msg.Valid {
 		event.Message = msg.String
 	}
@@ -404,18 +612,23 @@ func scanRows(rows *sql.Rows) (Event, error) {
 	var timestamp int64
 	var msg sql.NullString
 	var extraInfo sql.NullString
+	var seq sql.NullInt64
 	err := rows.Scan(
 		&timestamp,
 		&event.Name,
 		&event.Type,
 		&msg,
 		&extraInfo,
+		&seq,
 	)
 	if err != nil {
 		return event, err
 	}
 
 	event.Time = time.Unix(timestamp, 0)
+	if seq.Valid {
+		event.Seq = seq.Int64
+	}
 	if msg.Valid {
 		event.Message = msg.String
 	}
@@ -444,6 +657,53 @@ func purgeEvents(ctx context.Context, db *sql.DB, tableName string, beforeTimest
 	return int(affected), nil
 }
 
+// purgeEventsWithRange deletes the events before the timestamp,
+// and returns the number of deleted events and the time range of the deleted events
+// (zero times if none deleted).
+func purgeEventsWithRange(ctx context.Context, db *sql.DB, tableName string, beforeTimestamp int64) (int, time.Time, time.Time, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, time.Time{}, time.Time{}, err
+	}
+
+	selectStatement := fmt.Sprintf(`SELECT COUNT(*), MIN(%s), MAX(%s) FROM %s WHERE %s < ?`,
+		columnTimestamp, columnTimestamp, tableName, columnTimestamp)
+
+	var count int
+	var minTS, maxTS sql.NullInt64
+	start := time.Now()
+	err = tx.QueryRowContext(ctx, selectStatement, beforeTimestamp).Scan(&count, &minTS, &maxTS)
+	pkgmetricsrecorder.RecordSQLiteSelect(time.Since(start).Seconds())
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, time.Time{}, time.Time{}, err
+	}
+	if count == 0 {
+		_ = tx.Rollback()
+		return 0, time.Time{}, time.Time{}, nil
+	}
+
+	deleteStatement := fmt.Sprintf(`DELETE FROM %s WHERE %s < ?`, tableName, columnTimestamp)
+	start = time.Now()
+	rs, err := tx.ExecContext(ctx, deleteStatement, beforeTimestamp)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, time.Time{}, time.Time{}, err
+	}
+	pkgmetricsrecorder.RecordSQLiteDelete(time.Since(start).Seconds())
+
+	affected, err := rs.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, time.Time{}, time.Time{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, time.Time{}, time.Time{}, err
+	}
+
+	return int(affected), time.Unix(minTS.Int64, 0), time.Unix(maxTS.Int64, 0), nil
+}
+
 func compareEvent(eventA, eventB Event) bool {
 	if len(eventA.ExtraInfo) != len(eventB.ExtraInfo) {
 		return false
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/pkg/sqlite"
@@ -1519,3 +1520,230 @@ func TestUnmarshalIfValid(t *testing.T) {
 		})
 	}
 }
+
+func TestPurgeAudit(t *testing.T) {
+	t.Parallel()
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	store, err := New(dbRW, dbRO, 0)
+	assert.NoError(t, err)
+	bucket, err := store.Bucket("test_table")
+	assert.NoError(t, err)
+	defer bucket.Close()
+
+	baseTime := time.Now().UTC().Truncate(time.Second)
+	for i, d := range []time.Duration{-30 * time.Minute, -20 * time.Minute, 0} {
+		assert.NoError(t, bucket.Insert(ctx, Event{
+			Time:      baseTime.Add(d),
+			Name:      "test",
+			Type:      string(apiv1.EventTypeWarning),
+			ExtraInfo: map[string]string{"id": fmt.Sprintf("%d", i)},
+		}))
+	}
+
+	// nothing to purge, no audit event recorded
+	purged, err := bucket.Purge(ctx, baseTime.Add(-time.Hour).Unix())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, purged)
+
+	audit, err := store.Bucket(PurgeAuditBucketName, WithDisablePurge())
+	assert.NoError(t, err)
+	defer audit.Close()
+
+	evs, err := audit.Get(ctx, baseTime.Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, evs)
+
+	purged, err = bucket.Purge(ctx, baseTime.Add(-10*time.Minute).Unix())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, purged)
+
+	evs, err = audit.Get(ctx, baseTime.Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, evs, 1)
+	assert.Equal(t, EventNamePurged, evs[0].Name)
+	assert.Equal(t, string(apiv1.EventTypeInfo), evs[0].Type)
+	assert.Equal(t, bucket.Name(), evs[0].ExtraInfo[EventKeyPurgedBucket])
+	assert.Equal(t, "2", evs[0].ExtraInfo[EventKeyPurgedCount])
+	assert.Equal(t, baseTime.Add(-30*time.Minute).Format(time.RFC3339), evs[0].ExtraInfo[EventKeyPurgedOldest])
+	assert.Equal(t, baseTime.Add(-20*time.Minute).Format(time.RFC3339), evs[0].ExtraInfo[EventKeyPurgedNewest])
+
+	remaining, err := bucket.Get(ctx, baseTime.Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, remaining, 1)
+}
+
+func TestEventSequence(t *testing.T) {
+	t.Parallel()
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	store, err := New(dbRW, dbRO, 0)
+	require.NoError(t, err)
+	bucketA, err := store.Bucket("test_a")
+	require.NoError(t, err)
+	defer bucketA.Close()
+	bucketB, err := store.Bucket("test_b")
+	require.NoError(t, err)
+	defer bucketB.Close()
+
+	baseTime := time.Now().UTC()
+
+	// wall clock jumps backwards between the inserts
+	require.NoError(t, bucketA.Insert(ctx, Event{Time: baseTime, Name: "first", Type: string(apiv1.EventTypeInfo)}))
+	require.NoError(t, bucketB.Insert(ctx, Event{Time: baseTime.Add(-time.Hour), Name: "second", Type: string(apiv1.EventTypeInfo)}))
+	require.NoError(t, bucketA.Insert(ctx, Event{Time: baseTime, Name: "third", Type: string(apiv1.EventTypeInfo)}))
+
+	evsA, err := bucketA.Get(ctx, baseTime.Add(-2*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, evsA, 2)
+	evsB, err := bucketB.Get(ctx, baseTime.Add(-2*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, evsB, 1)
+
+	// same timestamp is ordered by the sequence number (latest first)
+	assert.Equal(t, "third", evsA[0].Name)
+	assert.Equal(t, "first", evsA[1].Name)
+
+	// sequence is shared by the buckets and follows the insertion order
+	assert.Less(t, evsA[1].Seq, evsB[0].Seq)
+	assert.Less(t, evsB[0].Seq, evsA[0].Seq)
+	assert.Equal(t, evsA[0].Seq, evsA.Events()[0].Seq)
+
+	latest, err := bucketA.Latest(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, evsA[0].Seq, latest.Seq)
+}
+
+func TestEventSequenceBackfill(t *testing.T) {
+	t.Parallel()
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	// table created before the sequence number column was added
+	tableName := defaultTableName("test_legacy")
+	_, err := dbRW.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE %s (timestamp INTEGER NOT NULL, name TEXT NOT NULL, type TEXT NOT NULL, message TEXT, extra_info TEXT);`, tableName))
+	require.NoError(t, err)
+	now := time.Now().UTC().Unix()
+	for _, name := range []string{"old1", "old2"} {
+		_, err = dbRW.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s (timestamp, name, type) VALUES (?, ?, ?);`, tableName), now, name, "Info")
+		require.NoError(t, err)
+	}
+
+	store, err := New(dbRW, dbRO, 0)
+	require.NoError(t, err)
+	bucket, err := store.Bucket("test_legacy")
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	require.NoError(t, bucket.Insert(ctx, Event{Time: time.Unix(now, 0), Name: "new", Type: "Info"}))
+
+	evs, err := bucket.Get(ctx, time.Unix(now-10, 0))
+	require.NoError(t, err)
+	require.Len(t, evs, 3)
+	assert.Equal(t, []string{"new", "old2", "old1"}, []string{evs[0].Name, evs[1].Name, evs[2].Name})
+	assert.Equal(t, []int64{3, 2, 1}, []int64{evs[0].Seq, evs[1].Seq, evs[2].Seq})
+}
+
+func TestBucketWithReadDB(t *testing.T) {
+	t.Parallel()
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+	otherRW, otherRO, otherCleanup := sqlite.OpenTestDB(t)
+	defer otherCleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	// creates the same table in the other database
+	otherStore, err := New(otherRW, otherRO, 0)
+	require.NoError(t, err)
+	otherBucket, err := otherStore.Bucket("test")
+	require.NoError(t, err)
+	defer otherBucket.Close()
+
+	store, err := New(dbRW, dbRO, 0)
+	require.NoError(t, err)
+	bucket, err := store.Bucket("test", WithReadDB(otherRO))
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	now := time.Now().UTC()
+	require.NoError(t, bucket.Insert(ctx, Event{Time: now, Name: "test", Type: string(apiv1.EventTypeInfo)}))
+
+	// reads from the other database
+	evs, err := bucket.Get(ctx, now.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, evs)
+
+	defaultBucket, err := store.Bucket("test")
+	require.NoError(t, err)
+	defer defaultBucket.Close()
+	evs, err = defaultBucket.Get(ctx, now.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, evs, 1)
+}
+
+func TestBucketWithReadDBContext(t *testing.T) {
+	t.Parallel()
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+	otherRW, otherRO, otherCleanup := sqlite.OpenTestDB(t)
+	defer otherCleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	// creates the same table in the other database
+	otherStore, err := New(otherRW, otherRO, 0)
+	require.NoError(t, err)
+	otherBucket, err := otherStore.Bucket("test")
+	require.NoError(t, err)
+	defer otherBucket.Close()
+
+	store, err := New(dbRW, dbRO, 0)
+	require.NoError(t, err)
+	bucket, err := store.Bucket("test")
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	now := time.Now().UTC()
+	ev := Event{Time: now, Name: "test", Type: string(apiv1.EventTypeInfo)}
+	require.NoError(t, bucket.Insert(ctx, ev))
+
+	evs, err := bucket.Get(ctx, now.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, evs, 1)
+
+	// reads from the database of the context
+	otherCtx := WithReadDBContext(ctx, otherRO)
+	evs, err = bucket.Get(otherCtx, now.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, evs)
+	latest, err := bucket.Latest(otherCtx)
+	require.NoError(t, err)
+	assert.Nil(t, latest)
+	found, err := bucket.Find(otherCtx, ev)
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	// nil keeps the database of the bucket
+	evs, err = bucket.Get(WithReadDBContext(ctx, nil), now.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, evs, 1)
+}
@@ -2,6 +2,7 @@ package eventstore
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -38,16 +39,32 @@ type Event struct {
 
 	// ExtraInfo represents the extra information of the event.
 	ExtraInfo map[string]string
+
+	// Seq is the monotonic sequence number assigned by the store on insert,
+	// shared by all the buckets. Ignored on insert.
+	Seq int64
 }
 
+// ExtraInfoKeySuggestedAction is the extra info key of the suggested repair action
+// of the event, converted to the suggested actions of the API event.
+const ExtraInfoKeySuggestedAction = "suggested_action"
+
 func (e *Event) ToEvent() apiv1.Event {
-	return apiv1.Event{
+	ev := apiv1.Event{
 		Component: e.Component,
 		Time:      metav1.Time{Time: e.Time},
 		Name:      e.Name,
 		Type:      apiv1.EventType(e.Type),
 		Message:   e.Message,
+		Seq:       e.Seq,
+	}
+	if action := e.ExtraInfo[ExtraInfoKeySuggestedAction]; action != "" {
+		ev.SuggestedActions = &apiv1.SuggestedActions{
+			Description:   e.Message,
+			RepairActions: []apiv1.RepairActionType{apiv1.RepairActionType(action)},
+		}
 	}
+	return ev
 }
 
 const DefaultRetention = 3 * 24 * time.Hour // 3 days
@@ -61,7 +78,8 @@ type Bucket interface {
 	Insert(ctx context.Context, ev Event) error
 	// Find returns nil if the event is not found.
 	Find(ctx context.Context, ev Event) (*Event, error)
-	// Get queries the event in the descending order of timestamp (latest event first).
+	// Get queries the event in the descending order of timestamp (latest event first),
+	// with the same timestamp ordered by the sequence number.
 	Get(ctx context.Context, since time.Time) (Events, error)
 	// Latest queries the latest event, returns nil if no event found.
 	Latest(ctx context.Context) (*Event, error)
@@ -69,8 +87,19 @@ type Bucket interface {
 	Close()
 }
 
+// BatchInserter is the optional interface of the bucket
+// that inserts multiple events in a single transaction.
+type BatchInserter interface {
+	InsertBatch(ctx context.Context, evs Events) error
+}
+
 type Op struct {
 	disablePurge bool
+	dbRO         *sql.DB
+
+	buffered      bool
+	batchSize     int
+	flushInterval time.Duration
 }
 
 type OpOption func(*Op)
@@ -90,3 +119,45 @@ func WithDisablePurge() OpOption {
 		op.disablePurge = true
 	}
 }
+
+// WithBuffer buffers the inserts of the bucket, and writes them in batches
+// of the batch size or every flush interval (see NewBufferedBucket),
+// for the components that insert many events at once (e.g., XID storms).
+// Zero or negative batch size or flush interval uses the default.
+// Closing the bucket flushes the pending events.
+func WithBuffer(batchSize int, flushInterval time.Duration) OpOption {
+	return func(op *Op) {
+		op.buffered = true
+		op.batchSize = batchSize
+		op.flushInterval = flushInterval
+	}
+}
+
+type readDBKey struct{}
+
+// WithReadDBContext returns the context to query the events from the read-only database,
+// instead of the one of the bucket (e.g., the dedicated pool for the API readers),
+// so that the API requests reading the events through the components do not
+// contend with the component checks for the shared read-only pool.
+func WithReadDBContext(ctx context.Context, db *sql.DB) context.Context {
+	if db == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, readDBKey{}, db)
+}
+
+// readDB returns the read-only database of the context, or the default if not set.
+func readDB(ctx context.Context, db *sql.DB) *sql.DB {
+	if ctxDB, ok := ctx.Value(readDBKey{}).(*sql.DB); ok {
+		return ctxDB
+	}
+	return db
+}
+
+// WithReadDB specifies the read-only database to query the events from,
+// instead of the one of the store (e.g., the dedicated pool for the API readers).
+func WithReadDB(db *sql.DB) OpOption {
+	return func(op *Op) {
+		op.dbRO = db
+	}
+}
@@ -0,0 +1,123 @@
+// Package export implements the JSONL serialization of the events and the
+// component health check results, for the offline analysis of node incidents
+// and the ingestion into data warehouses.
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+const (
+	// KindEvent is the record kind of an event.
+	KindEvent = "event"
+	// KindHealthState is the record kind of a component health check result.
+	KindHealthState = "health_state"
+)
+
+// maxLineSize is the maximum size of a single JSONL line,
+// large enough for the health states with the raw outputs.
+const maxLineSize = 4 * 1024 * 1024
+
+// ErrUnknownKind is returned when the record kind is not supported.
+var ErrUnknownKind = errors.New("unknown record kind")
+
+// Record is a single line in the exported JSONL file.
+type Record struct {
+	Kind        string             `json:"kind"`
+	Event       *apiv1.Event       `json:"event,omitempty"`
+	HealthState *apiv1.HealthState `json:"health_state,omitempty"`
+}
+
+// Writer writes the records in JSONL, one record per line.
+type Writer struct {
+	enc   *json.Encoder
+	count int
+}
+
+// NewWriter creates a new JSONL writer.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{enc: json.NewEncoder(w)}
+}
+
+// Count returns the number of the records written.
+func (w *Writer) Count() int {
+	return w.count
+}
+
+// WriteEvents writes the events, filling the component name
+// of each event from the component events if empty.
+func (w *Writer) WriteEvents(events apiv1.GPUdComponentEvents) error {
+	for _, ce := range events {
+		for _, ev := range ce.Events {
+			if ev.Component == "" {
+				ev.Component = ce.Component
+			}
+			if err := w.write(Record{Kind: KindEvent, Event: &ev}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteHealthStates writes the health states, filling the component name
+// of each state from the component health states if empty.
+func (w *Writer) WriteHealthStates(states apiv1.GPUdComponentHealthStates) error {
+	for _, cs := range states {
+		for _, st := range cs.States {
+			if st.Component == "" {
+				st.Component = cs.Component
+			}
+			if err := w.write(Record{Kind: KindHealthState, HealthState: &st}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (w *Writer) write(rec Record) error {
+	if err := w.enc.Encode(rec); err != nil {
+		return err
+	}
+	w.count++
+	return nil
+}
+
+// Read reads the JSONL records and calls the function for each record.
+// Empty lines are skipped.
+func Read(r io.Reader, fn func(Record) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		b := scanner.Bytes()
+		if len(b) == 0 {
+			continue
+		}
+
+		var rec Record
+		if err := json.Unmarshal(b, &rec); err != nil {
+			return fmt.Errorf("failed to parse line %d: %w", line, err)
+		}
+		switch {
+		case rec.Kind == KindEvent && rec.Event != nil:
+		case rec.Kind == KindHealthState && rec.HealthState != nil:
+		default:
+			return fmt.Errorf("line %d: %w %q", line, ErrUnknownKind, rec.Kind)
+		}
+
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
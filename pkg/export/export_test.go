@@ -0,0 +1,66 @@
+package export
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestWriteRead(t *testing.T) {
+	ts := metav1.NewTime(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	buf := new(bytes.Buffer)
+	w := NewWriter(buf)
+	require.NoError(t, w.WriteEvents(apiv1.GPUdComponentEvents{
+		{
+			Component: "cpu",
+			Events: apiv1.Events{
+				{Time: ts, Name: "soft_lockup", Type: apiv1.EventTypeWarning, Message: "lockup"},
+				{Component: "other", Time: ts, Name: "x"},
+			},
+		},
+	}))
+	require.NoError(t, w.WriteHealthStates(apiv1.GPUdComponentHealthStates{
+		{
+			Component: "disk",
+			States:    apiv1.HealthStates{{Time: ts, Health: apiv1.HealthStateTypeHealthy}},
+		},
+	}))
+	assert.Equal(t, 3, w.Count())
+	assert.Equal(t, 3, strings.Count(buf.String(), "\n"))
+
+	var recs []Record
+	require.NoError(t, Read(buf, func(rec Record) error {
+		recs = append(recs, rec)
+		return nil
+	}))
+	require.Len(t, recs, 3)
+	assert.Equal(t, KindEvent, recs[0].Kind)
+	assert.Equal(t, "cpu", recs[0].Event.Component)
+	assert.Equal(t, "lockup", recs[0].Event.Message)
+	assert.True(t, ts.Equal(&recs[0].Event.Time))
+	assert.Equal(t, "other", recs[1].Event.Component)
+	assert.Equal(t, KindHealthState, recs[2].Kind)
+	assert.Equal(t, "disk", recs[2].HealthState.Component)
+}
+
+func TestReadErrors(t *testing.T) {
+	err := Read(strings.NewReader("\n{\"kind\":\"unknown\"}\n"), func(Record) error { return nil })
+	require.ErrorIs(t, err, ErrUnknownKind)
+	assert.Contains(t, err.Error(), "line 2")
+
+	err = Read(strings.NewReader("{"), func(Record) error { return nil })
+	require.Error(t, err)
+
+	errStop := errors.New("stop")
+	err = Read(strings.NewReader(`{"kind":"event","event":{"name":"a"}}`), func(Record) error { return errStop })
+	require.ErrorIs(t, err, errStop)
+}
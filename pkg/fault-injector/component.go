@@ -0,0 +1,277 @@
+package faultinjector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	apiv2 "github.com/leptonai/gpud/api/v2"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// ComponentFaultType is the type of the fault to inject into a component.
+type ComponentFaultType string
+
+const (
+	// ComponentFaultTypeUnhealthy makes the component report the unhealthy results.
+	ComponentFaultTypeUnhealthy ComponentFaultType = "unhealthy"
+	// ComponentFaultTypeTimeout makes the component checks and event reads block
+	// for the timeout, before reporting the unhealthy results.
+	ComponentFaultTypeTimeout ComponentFaultType = "timeout"
+	// ComponentFaultTypePanic makes the component checks panic.
+	ComponentFaultTypePanic ComponentFaultType = "panic"
+)
+
+const (
+	// DefaultComponentFaultDuration is the default duration the component fault stays armed.
+	DefaultComponentFaultDuration = 5 * time.Minute
+	// DefaultComponentFaultTimeout is the default duration the "timeout" fault blocks for.
+	DefaultComponentFaultTimeout = time.Minute
+)
+
+var (
+	ErrComponentFaultInjectionDisabled = errors.New("component fault injection is disabled")
+	ErrComponentRequired               = errors.New("component fault component is required")
+	ErrInvalidComponentFaultType       = errors.New("invalid component fault type")
+)
+
+// ComponentFault is the fault to inject into a component.
+type ComponentFault struct {
+	// Component is the name of the component.
+	Component string `json:"component"`
+	// Type is the type of the fault.
+	Type ComponentFaultType `json:"type"`
+	// Duration is how long the fault stays armed.
+	// Defaults to DefaultComponentFaultDuration if zero.
+	Duration metav1.Duration `json:"duration,omitempty"`
+	// Timeout is how long the "timeout" fault blocks for.
+	// Defaults to DefaultComponentFaultTimeout if zero.
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+}
+
+// Validate validates the component fault and sets the defaults.
+func (f *ComponentFault) Validate() error {
+	if f.Component == "" {
+		return ErrComponentRequired
+	}
+	switch f.Type {
+	case ComponentFaultTypeUnhealthy, ComponentFaultTypeTimeout, ComponentFaultTypePanic:
+	default:
+		return fmt.Errorf("%w %q", ErrInvalidComponentFaultType, f.Type)
+	}
+	if f.Duration.Duration <= 0 {
+		f.Duration.Duration = DefaultComponentFaultDuration
+	}
+	if f.Timeout.Duration <= 0 {
+		f.Timeout.Duration = DefaultComponentFaultTimeout
+	}
+	return nil
+}
+
+// ComponentFaults is the set of the component faults currently armed,
+// consulted by the check guard and the components wrapped with WrapInitFunc.
+type ComponentFaults struct {
+	mu     sync.RWMutex
+	faults map[string]ComponentFault
+	until  map[string]time.Time
+
+	nowFunc func() time.Time
+}
+
+// NewComponentFaults creates a new empty set of the component faults.
+func NewComponentFaults() *ComponentFaults {
+	return &ComponentFaults{
+		faults:  make(map[string]ComponentFault),
+		until:   make(map[string]time.Time),
+		nowFunc: time.Now,
+	}
+}
+
+// Arm validates and arms the component fault for its duration,
+// replacing the existing fault of the component, if any.
+// Returns when the fault expires.
+func (cf *ComponentFaults) Arm(f ComponentFault) (time.Time, error) {
+	if err := f.Validate(); err != nil {
+		return time.Time{}, err
+	}
+
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	until := cf.nowFunc().Add(f.Duration.Duration)
+	cf.faults[f.Component] = f
+	cf.until[f.Component] = until
+
+	log.Logger.Warnw("armed component fault", "component", f.Component, "type", f.Type, "until", until)
+	return until, nil
+}
+
+// Disarm disarms the fault of the component, if any.
+func (cf *ComponentFaults) Disarm(component string) {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	delete(cf.faults, component)
+	delete(cf.until, component)
+}
+
+// Get returns the fault of the component, if armed and not expired.
+func (cf *ComponentFaults) Get(component string) (ComponentFault, bool) {
+	if cf == nil {
+		return ComponentFault{}, false
+	}
+
+	cf.mu.RLock()
+	defer cf.mu.RUnlock()
+
+	f, ok := cf.faults[component]
+	if !ok || !cf.nowFunc().Before(cf.until[component]) {
+		return ComponentFault{}, false
+	}
+	return f, true
+}
+
+var _ components.CheckFaultInjector = &ComponentFaults{}
+
+// InjectCheckFault implements components.CheckFaultInjector,
+// so that the check guard fails the checks as armed.
+func (cf *ComponentFaults) InjectCheckFault(name string) (components.CheckResult, bool) {
+	f, ok := cf.Get(name)
+	if !ok {
+		return nil, false
+	}
+
+	switch f.Type {
+	case ComponentFaultTypePanic:
+		panic(fmt.Sprintf("injected panic in component %q", name))
+	case ComponentFaultTypeTimeout:
+		time.Sleep(f.Timeout.Duration)
+	}
+	return &faultCheckResult{name: name, states: faultHealthStates(name, f)}, true
+}
+
+// ClearCheckFault implements components.CheckFaultInjector.
+func (cf *ComponentFaults) ClearCheckFault(name string) {
+	if cf == nil {
+		return
+	}
+	cf.Disarm(name)
+}
+
+// WrapInitFunc wraps the component initialization function, so that the
+// health states and the event reads of the component fail as armed
+// in the component faults. The checks fail via the check guard
+// (see components.CheckGuard.SetFaultInjector).
+func WrapInitFunc(initFunc components.InitFunc, faults *ComponentFaults) components.InitFunc {
+	return func(gpudInstance *components.GPUdInstance) (components.Component, error) {
+		c, err := initFunc(gpudInstance)
+		if err != nil {
+			return nil, err
+		}
+		fc := &faultyComponent{Component: c, faults: faults}
+		// only claims the health settable if the underlying component is
+		if _, ok := c.(components.HealthSettable); ok {
+			return &healthSettableFaultyComponent{faultyComponent: fc}, nil
+		}
+		return fc, nil
+	}
+}
+
+var (
+	_ components.Component      = &faultyComponent{}
+	_ components.Deregisterable = &faultyComponent{}
+	_ components.TypedPayloader = &faultyComponent{}
+	_ components.HealthSettable = &healthSettableFaultyComponent{}
+)
+
+// faultyComponent fails as armed in the component faults,
+// and delegates everything else to the underlying component.
+type faultyComponent struct {
+	components.Component
+	faults *ComponentFaults
+}
+
+func (c *faultyComponent) LastHealthStates() apiv1.HealthStates {
+	f, ok := c.faults.Get(c.Name())
+	if !ok {
+		return c.Component.LastHealthStates()
+	}
+	return faultHealthStates(c.Name(), f)
+}
+
+// LastHealthStatesWithPayload returns the fault health states without a payload,
+// if a fault is armed.
+func (c *faultyComponent) LastHealthStatesWithPayload() (apiv1.HealthStates, apiv2.Payload) {
+	f, ok := c.faults.Get(c.Name())
+	if !ok {
+		return components.LastHealthStatesWithPayload(c.Component)
+	}
+	return faultHealthStates(c.Name(), f), nil
+}
+
+func (c *faultyComponent) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	if f, ok := c.faults.Get(c.Name()); ok && f.Type == ComponentFaultTypeTimeout {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(f.Timeout.Duration):
+		}
+	}
+	return c.Component.Events(ctx, since)
+}
+
+// CanDeregister delegates to the underlying component, if deregisterable.
+func (c *faultyComponent) CanDeregister() bool {
+	d, ok := c.Component.(components.Deregisterable)
+	return ok && d.CanDeregister()
+}
+
+// healthSettableFaultyComponent is the faulty component
+// whose underlying component is health settable.
+type healthSettableFaultyComponent struct {
+	*faultyComponent
+}
+
+// SetHealthy disarms the fault, and delegates to the underlying component.
+func (c *healthSettableFaultyComponent) SetHealthy() error {
+	c.faults.Disarm(c.Name())
+	return c.Component.(components.HealthSettable).SetHealthy()
+}
+
+func faultHealthStates(name string, f ComponentFault) apiv1.HealthStates {
+	reason := "injected fault"
+	if f.Type == ComponentFaultTypeTimeout {
+		reason = fmt.Sprintf("injected fault: check timed out after %s", f.Timeout.Duration)
+	}
+	return apiv1.HealthStates{
+		{
+			Time:      metav1.NewTime(time.Now().UTC()),
+			Component: name,
+			Name:      name,
+			Health:    apiv1.HealthStateTypeUnhealthy,
+			Reason:    reason,
+			Error:     fmt.Sprintf("fault %q injected", f.Type),
+		},
+	}
+}
+
+var _ components.CheckResult = &faultCheckResult{}
+
+type faultCheckResult struct {
+	name   string
+	states apiv1.HealthStates
+}
+
+func (r *faultCheckResult) ComponentName() string { return r.name }
+func (r *faultCheckResult) String() string        { return r.states[0].Reason }
+func (r *faultCheckResult) Summary() string       { return r.states[0].Reason }
+func (r *faultCheckResult) HealthStateType() apiv1.HealthStateType {
+	return r.states[0].Health
+}
+func (r *faultCheckResult) HealthStates() apiv1.HealthStates { return r.states }
@@ -0,0 +1,146 @@
+package faultinjector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+)
+
+type mockComponent struct {
+	components.Component
+}
+
+func (c *mockComponent) Name() string { return "test" }
+
+func (c *mockComponent) Check() components.CheckResult {
+	return &faultCheckResult{name: "test", states: c.LastHealthStates()}
+}
+
+func (c *mockComponent) LastHealthStates() apiv1.HealthStates {
+	return apiv1.HealthStates{{Name: "test", Health: apiv1.HealthStateTypeHealthy, Reason: "ok"}}
+}
+
+func (c *mockComponent) Events(context.Context, time.Time) (apiv1.Events, error) {
+	return apiv1.Events{{Name: "ev"}}, nil
+}
+
+func TestComponentFaultValidate(t *testing.T) {
+	f := &ComponentFault{Type: ComponentFaultTypePanic}
+	assert.ErrorIs(t, f.Validate(), ErrComponentRequired)
+
+	f = &ComponentFault{Component: "test", Type: "unknown"}
+	assert.ErrorIs(t, f.Validate(), ErrInvalidComponentFaultType)
+
+	f = &ComponentFault{Component: "test", Type: ComponentFaultTypeTimeout}
+	require.NoError(t, f.Validate())
+	assert.Equal(t, DefaultComponentFaultDuration, f.Duration.Duration)
+	assert.Equal(t, DefaultComponentFaultTimeout, f.Timeout.Duration)
+
+	r := &Request{Component: &ComponentFault{Component: "test"}}
+	assert.ErrorIs(t, r.Validate(), ErrInvalidComponentFaultType)
+}
+
+func TestComponentFaults(t *testing.T) {
+	now := time.Now()
+	faults := NewComponentFaults()
+	faults.nowFunc = func() time.Time { return now }
+
+	guard := components.NewCheckGuard(0)
+	guard.SetFaultInjector(faults)
+
+	c, err := components.WrapInitFunc(WrapInitFunc(func(*components.GPUdInstance) (components.Component, error) {
+		return &mockComponent{}, nil
+	}, faults), guard)(&components.GPUdInstance{})
+	require.NoError(t, err)
+
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, c.LastHealthStates()[0].Health)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, c.Check().HealthStateType())
+
+	// unhealthy
+	until, err := faults.Arm(ComponentFault{Component: "test", Type: ComponentFaultTypeUnhealthy, Duration: metav1.Duration{Duration: time.Minute}})
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Minute), until)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, c.LastHealthStates()[0].Health)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, c.Check().HealthStateType())
+
+	// the component pollers check the unwrapped component via the guard
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, guard.Check(&mockComponent{}).HealthStateType())
+
+	// expired
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, c.Check().HealthStateType())
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, c.LastHealthStates()[0].Health)
+
+	// panic, recovered by the guard
+	_, err = faults.Arm(ComponentFault{Component: "test", Type: ComponentFaultTypePanic})
+	require.NoError(t, err)
+	rs := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, rs.HealthStateType())
+	assert.Contains(t, rs.Summary(), "injected panic")
+
+	// set healthy disarms
+	require.NoError(t, c.(components.HealthSettable).SetHealthy())
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, c.Check().HealthStateType())
+	assert.False(t, c.(components.Deregisterable).CanDeregister())
+
+	// timeout
+	_, err = faults.Arm(ComponentFault{Component: "test", Type: ComponentFaultTypeTimeout, Timeout: metav1.Duration{Duration: 50 * time.Millisecond}})
+	require.NoError(t, err)
+	start := time.Now()
+	rs = c.Check()
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.Contains(t, rs.Summary(), "timed out")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = c.Events(ctx, time.Time{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// nil-safe
+	var nilFaults *ComponentFaults
+	_, ok := nilFaults.Get("test")
+	assert.False(t, ok)
+}
+
+type mockHealthSettableComponent struct {
+	mockComponent
+	setHealthy int
+}
+
+func (c *mockHealthSettableComponent) SetHealthy() error {
+	c.setHealthy++
+	return nil
+}
+
+func TestWrapInitFuncHealthSettable(t *testing.T) {
+	faults := NewComponentFaults()
+
+	c, err := WrapInitFunc(func(*components.GPUdInstance) (components.Component, error) {
+		return &mockComponent{}, nil
+	}, faults)(&components.GPUdInstance{})
+	require.NoError(t, err)
+	_, ok := c.(components.HealthSettable)
+	assert.False(t, ok)
+
+	inner := &mockHealthSettableComponent{}
+	c, err = WrapInitFunc(func(*components.GPUdInstance) (components.Component, error) {
+		return inner, nil
+	}, faults)(&components.GPUdInstance{})
+	require.NoError(t, err)
+	hs, ok := c.(components.HealthSettable)
+	require.True(t, ok)
+
+	_, err = faults.Arm(ComponentFault{Component: "test", Type: ComponentFaultTypeUnhealthy})
+	require.NoError(t, err)
+	require.NoError(t, hs.SetHealthy())
+	assert.Equal(t, 1, inner.setHealthy)
+	_, armed := faults.Get("test")
+	assert.False(t, armed)
+}
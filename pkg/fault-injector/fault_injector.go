@@ -11,22 +11,32 @@ import (
 // Injector defines the interface for injecting failures into the system.
 type Injector interface {
 	KmsgWriter() pkgkmsgwriter.KmsgWriter
+	// ComponentFaults returns nil if the component fault injection is disabled.
+	ComponentFaults() *ComponentFaults
 }
 
-func NewInjector(kmsgWriter pkgkmsgwriter.KmsgWriter) Injector {
+// NewInjector creates a new injector.
+// The component faults are nil to disable the component fault injection.
+func NewInjector(kmsgWriter pkgkmsgwriter.KmsgWriter, componentFaults *ComponentFaults) Injector {
 	return &injector{
-		kmsgWriter: kmsgWriter,
+		kmsgWriter:      kmsgWriter,
+		componentFaults: componentFaults,
 	}
 }
 
 type injector struct {
-	kmsgWriter pkgkmsgwriter.KmsgWriter
+	kmsgWriter      pkgkmsgwriter.KmsgWriter
+	componentFaults *ComponentFaults
 }
 
 func (i *injector) KmsgWriter() pkgkmsgwriter.KmsgWriter {
 	return i.kmsgWriter
 }
 
+func (i *injector) ComponentFaults() *ComponentFaults {
+	return i.componentFaults
+}
+
 // Request is the request body for the inject-fault endpoint.
 type Request struct {
 	// XID is the XID to inject.
@@ -34,6 +44,9 @@ type Request struct {
 
 	// KernelMessage is the kernel message to inject.
 	KernelMessage *pkgkmsgwriter.KernelMessage `json:"kernel_message,omitempty"`
+	// Component is the fault to inject into a component
+	// (e.g., unhealthy, timeout, panic).
+	Component *ComponentFault `json:"component,omitempty"`
 }
 
 type XIDToInject struct {
@@ -62,6 +75,9 @@ func (r *Request) Validate() error {
 	case r.KernelMessage != nil:
 		return r.KernelMessage.Validate()
 
+	case r.Component != nil:
+		return r.Component.Validate()
+
 	default:
 		return ErrNoFaultFound
 	}
@@ -5,12 +5,54 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"sync"
 )
 
+var (
+	execPathsMu sync.RWMutex
+	execPaths   = make(map[string]string)
+)
+
+// SetExecutablePaths seeds the located executable paths
+// (e.g., from the probe cache of the previous runs),
+// to skip searching the PATH for the same executables.
+func SetExecutablePaths(paths map[string]string) {
+	execPathsMu.Lock()
+	defer execPathsMu.Unlock()
+	for bin, p := range paths {
+		execPaths[bin] = p
+	}
+}
+
+// ExecutablePaths returns the copy of the executable paths located so far.
+func ExecutablePaths() map[string]string {
+	execPathsMu.RLock()
+	defer execPathsMu.RUnlock()
+	paths := make(map[string]string, len(execPaths))
+	for bin, p := range execPaths {
+		paths[bin] = p
+	}
+	return paths
+}
+
+// LocateExecutable returns the path of the executable in the PATH.
+// The located paths are remembered, and re-located only if no longer executable.
 func LocateExecutable(bin string) (string, error) {
+	execPathsMu.RLock()
+	cached, ok := execPaths[bin]
+	execPathsMu.RUnlock()
+	if ok && CheckExecutable(cached) == nil {
+		return cached, nil
+	}
+
 	execPath, err := exec.LookPath(bin)
 	if err == nil {
-		return execPath, CheckExecutable(execPath)
+		if err = CheckExecutable(execPath); err == nil {
+			execPathsMu.Lock()
+			execPaths[bin] = execPath
+			execPathsMu.Unlock()
+		}
+		return execPath, err
 	}
 	return "", fmt.Errorf("executable %q not found in PATH: %w", bin, err)
 }
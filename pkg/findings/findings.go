@@ -0,0 +1,298 @@
+// Package findings tracks the unhealthy (and degraded) health states reported
+// to the control plane, until the control plane acknowledges them,
+// so that the unacknowledged critical findings can be re-escalated
+// rather than silently lost on the control plane side.
+package findings
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgdisruption "github.com/leptonai/gpud/pkg/disruption"
+	pkgescalation "github.com/leptonai/gpud/pkg/escalation"
+)
+
+// DefaultEscalateAfter is the default time after which the unacknowledged
+// unhealthy finding is re-escalated to the control plane.
+const DefaultEscalateAfter = 15 * time.Minute
+
+// ID returns the stable id of the finding for the health state of the component.
+// The reason is excluded, since it may change across the checks
+// (e.g., the counters) while the finding remains the same.
+func ID(component string, st apiv1.HealthState) string {
+	codes := make([]string, 0, len(st.FailureCodes))
+	for _, c := range st.FailureCodes {
+		codes = append(codes, string(c))
+	}
+	sort.Strings(codes)
+
+	h := sha256.New()
+	h.Write([]byte(component))
+	h.Write([]byte{0})
+	h.Write([]byte(st.Name))
+	h.Write([]byte{0})
+	h.Write([]byte(st.Health))
+	h.Write([]byte{0})
+	h.Write([]byte(strings.Join(codes, ",")))
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// Tracker tracks the reported findings and their acknowledgments.
+// The findings are kept in memory only, and reported again after the restart,
+// unless persisted (see LoadFindings).
+// A nil *Tracker is valid and tracks nothing.
+type Tracker struct {
+	escalateAfter time.Duration
+
+	// disruptions are the declared windows of the expected disruption,
+	// nil if no disruption is expected
+	disruptions *pkgdisruption.Windows
+	// escalation is the escalation ladders deciding when the findings
+	// of their components are critical, nil if not escalated
+	escalation *pkgescalation.Tracker
+
+	mu       sync.RWMutex
+	findings map[string]*apiv1.Finding
+	// dbRW persists the findings if not nil (see LoadFindings)
+	dbRW *sql.DB
+}
+
+// NewTracker creates a new tracker that re-escalates the unacknowledged
+// unhealthy findings after the duration.
+// Zero or negative duration uses the DefaultEscalateAfter.
+func NewTracker(escalateAfter time.Duration) *Tracker {
+	if escalateAfter <= 0 {
+		escalateAfter = DefaultEscalateAfter
+	}
+	return &Tracker{
+		escalateAfter: escalateAfter,
+		findings:      make(map[string]*apiv1.Finding),
+	}
+}
+
+// SetDisruptions sets the windows of the expected disruption, to tag the findings
+// inside the windows as expected and not to re-escalate them.
+func (t *Tracker) SetDisruptions(disruptions *pkgdisruption.Windows) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.disruptions = disruptions
+}
+
+// SetEscalation sets the escalation ladders, so that the findings of the
+// components escalated by the ladders are re-escalated only once the ladders
+// escalate them to the critical severity, rather than on a separate timer
+// regardless of the ladders. The findings of the other components are
+// re-escalated if unhealthy.
+func (t *Tracker) SetEscalation(escalation *pkgescalation.Tracker) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.escalation = escalation
+}
+
+// Observe records the health states reported to the control plane,
+// tracking the new findings and dropping the findings of the reported
+// components that recovered. Returns the unacknowledged findings
+// of the reported states, for the control plane to acknowledge.
+func (t *Tracker) Observe(now time.Time, states apiv1.GPUdComponentHealthStates) []apiv1.Finding {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	changed := false
+	reported := make(map[string]struct{})
+	current := make(map[string]struct{})
+	var pending []apiv1.Finding
+	for _, cs := range states {
+		reported[cs.Component] = struct{}{}
+		for _, st := range cs.States {
+			if st.Health != apiv1.HealthStateTypeUnhealthy && st.Health != apiv1.HealthStateTypeDegraded {
+				continue
+			}
+
+			id := ID(cs.Component, st)
+			current[id] = struct{}{}
+
+			f, ok := t.findings[id]
+			if !ok {
+				f = &apiv1.Finding{
+					ID:              id,
+					Component:       cs.Component,
+					Name:            st.Name,
+					Health:          st.Health,
+					FailureCodes:    st.FailureCodes,
+					FirstReportedAt: metav1.NewTime(now),
+				}
+				t.findings[id] = f
+				changed = true
+			}
+			f.Reason = st.Reason
+			f.Severity = st.Severity
+			f.EscalationLevel = st.EscalationLevel
+			f.LastReportedAt = metav1.NewTime(now)
+			// re-evaluated on every report, so that the finding persisting
+			// after the window ends is no longer expected
+			f.ExpectedDisruption = t.disruptions.Match(cs.Component, now)
+
+			if !f.Acknowledged() {
+				pending = append(pending, *f)
+			}
+		}
+	}
+
+	for id, f := range t.findings {
+		if _, ok := reported[f.Component]; !ok {
+			continue
+		}
+		if _, ok := current[id]; !ok {
+			delete(t.findings, id)
+			changed = true
+		}
+	}
+	if changed {
+		t.persistLocked()
+	}
+
+	sortFindings(pending)
+	return pending
+}
+
+// Acknowledge records the acknowledgments from the control plane,
+// and returns the ids of the unknown (e.g., already recovered) findings.
+func (t *Tracker) Acknowledge(now time.Time, acks []apiv1.FindingAck) []string {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var unknown []string
+	acked := 0
+	for _, ack := range acks {
+		f, ok := t.findings[ack.FindingID]
+		if !ok {
+			unknown = append(unknown, ack.FindingID)
+			continue
+		}
+		ackedAt := metav1.NewTime(now)
+		f.AckID = ack.AckID
+		f.AcknowledgedAt = &ackedAt
+		acked++
+	}
+	// persisted, not to report the acknowledged findings again after the restart
+	if acked > 0 {
+		t.persistLocked()
+	}
+	return unknown
+}
+
+// Escalate returns the unacknowledged critical findings that have not been
+// acknowledged (or re-escalated) within the escalation timeout,
+// and marks them as re-escalated.
+// The findings inside the windows of the expected disruption are not re-escalated.
+func (t *Tracker) Escalate(now time.Time) []apiv1.Finding {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var due []apiv1.Finding
+	for _, f := range t.findings {
+		if f.Acknowledged() || f.Expected() || !t.criticalLocked(f) {
+			continue
+		}
+
+		since := f.FirstReportedAt.Time
+		if f.LastEscalatedAt != nil {
+			since = f.LastEscalatedAt.Time
+		}
+		if now.Sub(since) < t.escalateAfter {
+			continue
+		}
+
+		escalatedAt := metav1.NewTime(now)
+		f.Escalations++
+		f.LastEscalatedAt = &escalatedAt
+		due = append(due, *f)
+	}
+	if len(due) > 0 {
+		t.persistLocked()
+	}
+
+	sortFindings(due)
+	return due
+}
+
+// criticalLocked returns true if the finding is critical, as escalated by the
+// ladder of its component, or unhealthy if not escalated by any ladder.
+// The caller must hold the lock.
+func (t *Tracker) criticalLocked(f *apiv1.Finding) bool {
+	if t.escalation.Escalates(f.Component) {
+		return f.Severity == apiv1.EventTypeCritical
+	}
+	return f.Health == apiv1.HealthStateTypeUnhealthy
+}
+
+// Pending returns the unacknowledged findings.
+func (t *Tracker) Pending() []apiv1.Finding {
+	var pending []apiv1.Finding
+	for _, f := range t.List() {
+		if !f.Acknowledged() {
+			pending = append(pending, f)
+		}
+	}
+	return pending
+}
+
+// List returns the copy of all the tracked findings,
+// sorted by the component and the health state name.
+func (t *Tracker) List() []apiv1.Finding {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	fs := make([]apiv1.Finding, 0, len(t.findings))
+	for _, f := range t.findings {
+		fs = append(fs, *f)
+	}
+	sortFindings(fs)
+	return fs
+}
+
+func sortFindings(fs []apiv1.Finding) {
+	sort.Slice(fs, func(i, j int) bool {
+		if fs[i].Component != fs[j].Component {
+			return fs[i].Component < fs[j].Component
+		}
+		if fs[i].Name != fs[j].Name {
+			return fs[i].Name < fs[j].Name
+		}
+		return fs[i].ID < fs[j].ID
+	})
+}
@@ -0,0 +1,179 @@
+package findings
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	pkgdisruption "github.com/leptonai/gpud/pkg/disruption"
+	pkgescalation "github.com/leptonai/gpud/pkg/escalation"
+)
+
+func TestID(t *testing.T) {
+	st := apiv1.HealthState{
+		Name:         "ib",
+		Health:       apiv1.HealthStateTypeUnhealthy,
+		Reason:       "1 port down",
+		FailureCodes: []apiv1.FailureCode{apiv1.FailureCodeIBPortRateDegraded, apiv1.FailureCodeIBPortDown},
+	}
+	id := ID("infiniband", st)
+	assert.Len(t, id, 16)
+
+	// the reason and the order of the failure codes do not change the finding
+	st2 := st
+	st2.Reason = "2 ports down"
+	st2.FailureCodes = []apiv1.FailureCode{apiv1.FailureCodeIBPortDown, apiv1.FailureCodeIBPortRateDegraded}
+	assert.Equal(t, id, ID("infiniband", st2))
+
+	st3 := st
+	st3.Health = apiv1.HealthStateTypeDegraded
+	assert.NotEqual(t, id, ID("infiniband", st3))
+	assert.NotEqual(t, id, ID("ecc", st))
+}
+
+func TestTracker(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tr := NewTracker(10 * time.Minute)
+
+	unhealthy := apiv1.HealthState{Name: "ib", Health: apiv1.HealthStateTypeUnhealthy, Reason: "port down"}
+	degraded := apiv1.HealthState{Name: "ecc", Health: apiv1.HealthStateTypeDegraded}
+	pending := tr.Observe(now, apiv1.GPUdComponentHealthStates{
+		{Component: "infiniband", States: apiv1.HealthStates{unhealthy}},
+		{Component: "ecc", States: apiv1.HealthStates{degraded}},
+		{Component: "cpu", States: apiv1.HealthStates{{Name: "cpu", Health: apiv1.HealthStateTypeHealthy}}},
+	})
+	require.Len(t, pending, 2)
+	assert.Equal(t, "ecc", pending[0].Component)
+	assert.Equal(t, "infiniband", pending[1].Component)
+	assert.Equal(t, "port down", pending[1].Reason)
+	ibID := pending[1].ID
+	eccID := pending[0].ID
+
+	// not escalated before the timeout
+	assert.Empty(t, tr.Escalate(now.Add(5*time.Minute)))
+
+	// acknowledge the degraded finding, and an unknown finding
+	unknown := tr.Acknowledge(now.Add(time.Minute), []apiv1.FindingAck{{FindingID: eccID, AckID: "INC-1"}, {FindingID: "unknown", AckID: "INC-2"}})
+	assert.Equal(t, []string{"unknown"}, unknown)
+	require.Len(t, tr.Pending(), 1)
+	assert.Equal(t, ibID, tr.Pending()[0].ID)
+
+	// only the unacknowledged unhealthy finding is escalated, once per timeout
+	due := tr.Escalate(now.Add(10 * time.Minute))
+	require.Len(t, due, 1)
+	assert.Equal(t, ibID, due[0].ID)
+	assert.Equal(t, 1, due[0].Escalations)
+	assert.Empty(t, tr.Escalate(now.Add(15*time.Minute)))
+	due = tr.Escalate(now.Add(20 * time.Minute))
+	require.Len(t, due, 1)
+	assert.Equal(t, 2, due[0].Escalations)
+
+	// the acknowledged finding is not reported again
+	pending = tr.Observe(now.Add(21*time.Minute), apiv1.GPUdComponentHealthStates{
+		{Component: "ecc", States: apiv1.HealthStates{degraded}},
+	})
+	assert.Empty(t, pending)
+
+	// the recovered finding is dropped, the other components are kept
+	pending = tr.Observe(now.Add(22*time.Minute), apiv1.GPUdComponentHealthStates{
+		{Component: "infiniband", States: apiv1.HealthStates{{Name: "ib", Health: apiv1.HealthStateTypeHealthy}}},
+	})
+	assert.Empty(t, pending)
+	all := tr.List()
+	require.Len(t, all, 1)
+	assert.Equal(t, eccID, all[0].ID)
+	assert.True(t, all[0].Acknowledged())
+	assert.Equal(t, "INC-1", all[0].AckID)
+}
+
+func TestTrackerExpectedDisruption(t *testing.T) {
+	now := time.Date(2025, 1, 1, 2, 0, 0, 0, time.UTC)
+	disruptions := pkgdisruption.New()
+	w, err := disruptions.Add(now, apiv1.DisruptionWindow{
+		Components: []string{"infiniband"},
+		Start:      metav1.NewTime(now),
+		End:        metav1.NewTime(now.Add(2 * time.Hour)),
+	}, nil)
+	require.NoError(t, err)
+
+	tr := NewTracker(10 * time.Minute)
+	tr.SetDisruptions(disruptions)
+
+	states := apiv1.GPUdComponentHealthStates{
+		{Component: "infiniband", States: apiv1.HealthStates{{Name: "ib", Health: apiv1.HealthStateTypeUnhealthy}}},
+		{Component: "ecc", States: apiv1.HealthStates{{Name: "ecc", Health: apiv1.HealthStateTypeUnhealthy}}},
+	}
+
+	// still reported, but tagged as expected
+	pending := tr.Observe(now, states)
+	require.Len(t, pending, 2)
+	assert.False(t, pending[0].Expected())
+	assert.True(t, pending[1].Expected())
+	assert.Equal(t, w.ID, pending[1].ExpectedDisruption)
+
+	// only the unexpected finding is re-escalated
+	due := tr.Escalate(now.Add(15 * time.Minute))
+	require.Len(t, due, 1)
+	assert.Equal(t, "ecc", due[0].Component)
+
+	// no longer expected once the window ends
+	end := now.Add(2 * time.Hour)
+	pending = tr.Observe(end, states)
+	require.Len(t, pending, 2)
+	assert.False(t, pending[1].Expected())
+	due = tr.Escalate(end)
+	require.Len(t, due, 2)
+}
+
+func TestNilTracker(t *testing.T) {
+	var tr *Tracker
+	assert.Nil(t, tr.Observe(time.Now(), apiv1.GPUdComponentHealthStates{{Component: "a", States: apiv1.HealthStates{{Health: apiv1.HealthStateTypeUnhealthy}}}}))
+	assert.Nil(t, tr.Acknowledge(time.Now(), []apiv1.FindingAck{{FindingID: "a"}}))
+	assert.Nil(t, tr.Escalate(time.Now()))
+	assert.Nil(t, tr.List())
+	assert.Nil(t, tr.Pending())
+}
+
+func TestTrackerEscalation(t *testing.T) {
+	registry := components.NewRegistry(&components.GPUdInstance{})
+	escalation, err := pkgescalation.NewTracker(registry, []pkgescalation.Ladder{{
+		Name:       "ib",
+		Components: []string{"infiniband"},
+		Steps:      []pkgescalation.Step{{After: metav1.Duration{Duration: 30 * time.Minute}, Severity: apiv1.EventTypeCritical}},
+	}})
+	require.NoError(t, err)
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tr := NewTracker(10 * time.Minute)
+	tr.SetEscalation(escalation)
+
+	ib := apiv1.HealthState{Name: "ib", Health: apiv1.HealthStateTypeUnhealthy, Severity: apiv1.EventTypeWarning}
+	states := apiv1.GPUdComponentHealthStates{
+		{Component: "infiniband", States: apiv1.HealthStates{ib}},
+		{Component: "ecc", States: apiv1.HealthStates{{Name: "ecc", Health: apiv1.HealthStateTypeUnhealthy}}},
+	}
+	tr.Observe(now, states)
+
+	// the finding of the component escalated by the ladder is not
+	// re-escalated until the ladder escalates it to critical
+	due := tr.Escalate(now.Add(15 * time.Minute))
+	require.Len(t, due, 1)
+	assert.Equal(t, "ecc", due[0].Component)
+
+	ib.Severity = apiv1.EventTypeCritical
+	ib.EscalationLevel = 1
+	states[0].States = apiv1.HealthStates{ib}
+	pending := tr.Observe(now.Add(30*time.Minute), states)
+	require.Len(t, pending, 2)
+	assert.Equal(t, apiv1.EventTypeCritical, pending[1].Severity)
+	assert.Equal(t, 1, pending[1].EscalationLevel)
+
+	due = tr.Escalate(now.Add(30 * time.Minute))
+	require.Len(t, due, 2)
+	assert.Equal(t, "infiniband", due[1].Component)
+}
@@ -0,0 +1,84 @@
+package findings
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+)
+
+// persistTimeout is the timeout to persist the findings.
+const persistTimeout = 10 * time.Second
+
+// LoadFindings loads the findings persisted in the metadata table,
+// and persists the findings from now on whenever they change, so that
+// the acknowledged findings are not reported again after the restart.
+// The loaded findings recovered after the restart are dropped on the next report.
+func (t *Tracker) LoadFindings(ctx context.Context, dbRW *sql.DB, dbRO *sql.DB) error {
+	if t == nil {
+		return nil
+	}
+
+	persisted, err := readFindings(ctx, dbRO)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := range persisted {
+		f := persisted[i]
+		if _, ok := t.findings[f.ID]; ok {
+			continue
+		}
+		t.findings[f.ID] = &f
+	}
+	t.dbRW = dbRW
+	return nil
+}
+
+// persistLocked persists the findings, if enabled.
+// The caller must hold the lock.
+func (t *Tracker) persistLocked() {
+	if t.dbRW == nil {
+		return
+	}
+
+	fs := make([]apiv1.Finding, 0, len(t.findings))
+	for _, f := range t.findings {
+		fs = append(fs, *f)
+	}
+	sortFindings(fs)
+	b, err := json.Marshal(fs)
+	if err != nil {
+		log.Logger.Warnw("failed to marshal findings", "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), persistTimeout)
+	defer cancel()
+	if err := pkgmetadata.SetMetadata(ctx, t.dbRW, pkgmetadata.MetadataKeyFindings, string(b)); err != nil {
+		log.Logger.Warnw("failed to persist findings", "error", err)
+	}
+}
+
+func readFindings(ctx context.Context, dbRO *sql.DB) ([]apiv1.Finding, error) {
+	raw, err := pkgmetadata.ReadMetadata(ctx, dbRO, pkgmetadata.MetadataKeyFindings)
+	if err != nil {
+		return nil, err
+	}
+	if raw == "" {
+		return nil, nil
+	}
+
+	var fs []apiv1.Finding
+	if err := json.Unmarshal([]byte(raw), &fs); err != nil {
+		return nil, fmt.Errorf("failed to parse findings: %w", err)
+	}
+	return fs, nil
+}
@@ -0,0 +1,59 @@
+package findings
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestLoadFindings(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	require.NoError(t, pkgmetadata.CreateTableMetadata(ctx, dbRW))
+
+	states := apiv1.GPUdComponentHealthStates{
+		{
+			Component: "infiniband",
+			States: apiv1.HealthStates{
+				{Name: "ib", Health: apiv1.HealthStateTypeUnhealthy, Reason: "1 port down"},
+			},
+		},
+	}
+	now := time.Now().UTC()
+
+	tr := NewTracker(time.Minute)
+	require.NoError(t, tr.LoadFindings(ctx, dbRW, dbRO))
+	pending := tr.Observe(now, states)
+	require.Len(t, pending, 1)
+	assert.Empty(t, tr.Acknowledge(now, []apiv1.FindingAck{{FindingID: pending[0].ID, AckID: "ack-1"}}))
+
+	// the acknowledgment survives the restart
+	restarted := NewTracker(time.Minute)
+	require.NoError(t, restarted.LoadFindings(ctx, dbRW, dbRO))
+	fs := restarted.List()
+	require.Len(t, fs, 1)
+	assert.Equal(t, "ack-1", fs[0].AckID)
+	assert.True(t, fs[0].Acknowledged())
+	assert.Empty(t, restarted.Observe(now.Add(time.Minute), states))
+
+	// the recovered finding is dropped, and no longer persisted
+	restarted.Observe(now.Add(2*time.Minute), apiv1.GPUdComponentHealthStates{{Component: "infiniband"}})
+	restarted = NewTracker(time.Minute)
+	require.NoError(t, restarted.LoadFindings(ctx, dbRW, dbRO))
+	assert.Empty(t, restarted.List())
+
+	// nil tracker is a no-op
+	var nilTracker *Tracker
+	assert.NoError(t, nilTracker.LoadFindings(ctx, dbRW, dbRO))
+}
@@ -0,0 +1,150 @@
+package systemd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"tailscale.com/atomicfile"
+)
+
+// DefaultUnitDropInFile is the systemd drop-in of the unit customizations,
+// so that the unit file itself is kept as embedded.
+const DefaultUnitDropInFile = "/etc/systemd/system/gpud.service.d/gpud-up.conf"
+
+// UnitOptions customizes the gpud systemd unit.
+// The zero values keep the defaults of the embedded unit file.
+type UnitOptions struct {
+	// CPUQuota limits the CPU time of the unit (e.g., "50%", "200%" for two cores).
+	CPUQuota string
+	// MemoryMax limits the memory of the unit (e.g., "2G", "512MiB").
+	MemoryMax string
+	// Nice sets the nice level of the unit, between -20 and 19.
+	// Zero keeps the default.
+	Nice int
+	// Environment is the list of the environment variables in the "KEY=VALUE" format.
+	Environment []string
+	// ExtraFlags are the extra flags passed to "gpud run",
+	// after the ones in the environment file.
+	ExtraFlags string
+}
+
+var envKeyRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Validate validates the unit options.
+func (o UnitOptions) Validate() error {
+	if o.CPUQuota != "" {
+		v, ok := strings.CutSuffix(o.CPUQuota, "%")
+		if !ok {
+			return fmt.Errorf("invalid cpu quota %q (must be a percentage, e.g., 50%%)", o.CPUQuota)
+		}
+		pct, err := strconv.ParseUint(v, 10, 32)
+		if err != nil || pct == 0 {
+			return fmt.Errorf("invalid cpu quota %q (must be a positive percentage, e.g., 50%%)", o.CPUQuota)
+		}
+	}
+	if o.MemoryMax != "" && o.MemoryMax != "infinity" {
+		b, err := humanize.ParseBytes(o.MemoryMax)
+		if err != nil {
+			return fmt.Errorf("invalid memory max %q: %w", o.MemoryMax, err)
+		}
+		if b == 0 {
+			return fmt.Errorf("invalid memory max %q (must be positive)", o.MemoryMax)
+		}
+	}
+	if o.Nice < -20 || o.Nice > 19 {
+		return fmt.Errorf("invalid nice level %d (must be between -20 and 19)", o.Nice)
+	}
+	for _, kv := range o.Environment {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || !envKeyRegex.MatchString(k) {
+			return fmt.Errorf("invalid environment variable %q (must be KEY=VALUE)", kv)
+		}
+		if strings.ContainsAny(v, "\"\n") {
+			return fmt.Errorf("invalid environment variable %q (value must not contain quotes or newlines)", kv)
+		}
+	}
+	if strings.ContainsAny(o.ExtraFlags, "\n\\") {
+		return fmt.Errorf("invalid extra flags %q (must not contain newlines or backslashes)", o.ExtraFlags)
+	}
+	return nil
+}
+
+// memoryMax returns the systemd memory limit, converting the human-readable units
+// (e.g., "2GB") to bytes since systemd only supports the K/M/G/T suffixes.
+func (o UnitOptions) memoryMax() string {
+	if o.MemoryMax == "infinity" {
+		return o.MemoryMax
+	}
+	b, _ := humanize.ParseBytes(o.MemoryMax)
+	return strconv.FormatUint(b, 10)
+}
+
+// escapeSpecifiers escapes the systemd specifiers (e.g., "%h"),
+// which are expanded in the "Environment=" and "ExecStart=" values.
+func escapeSpecifiers(s string) string {
+	return strings.ReplaceAll(s, "%", "%%")
+}
+
+// directives returns the "[Service]" directives of the options.
+func (o UnitOptions) directives() []string {
+	var lines []string
+	if o.CPUQuota != "" {
+		lines = append(lines, "CPUQuota="+o.CPUQuota)
+	}
+	if o.MemoryMax != "" {
+		lines = append(lines, "MemoryMax="+o.memoryMax())
+	}
+	if o.Nice != 0 {
+		lines = append(lines, "Nice="+strconv.Itoa(o.Nice))
+	}
+	for _, kv := range o.Environment {
+		lines = append(lines, fmt.Sprintf("Environment=%q", escapeSpecifiers(kv)))
+	}
+	if flags := strings.TrimSpace(o.ExtraFlags); flags != "" {
+		// the empty "ExecStart=" resets the one in the unit file
+		lines = append(lines, "ExecStart=", execStart(GPUdServiceUnitFileContents())+" "+escapeSpecifiers(flags))
+	}
+	return lines
+}
+
+// execStart returns the "ExecStart=" line of the unit file.
+func execStart(unit string) string {
+	for _, line := range strings.Split(unit, "\n") {
+		if strings.HasPrefix(line, "ExecStart=") {
+			return line
+		}
+	}
+	return "ExecStart=" + DefaultBinPath + " run $FLAGS"
+}
+
+// UnitDropInContents returns the systemd drop-in customizing the gpud unit
+// with the options, or empty if no option is set. The options must be validated.
+func UnitDropInContents(opts UnitOptions) string {
+	lines := opts.directives()
+	if len(lines) == 0 {
+		return ""
+	}
+	return "# customized by \"gpud up\"\n[Service]\n" + strings.Join(lines, "\n") + "\n"
+}
+
+// WriteUnitDropIn writes the systemd drop-in of the options,
+// or removes the existing one if no option is set.
+func WriteUnitDropIn(file string, opts UnitOptions) error {
+	contents := UnitDropInContents(opts)
+	if contents == "" {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(file, []byte(contents), 0644)
+}
@@ -0,0 +1,101 @@
+package systemd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    UnitOptions
+		wantErr bool
+	}{
+		{name: "empty", opts: UnitOptions{}},
+		{name: "valid", opts: UnitOptions{
+			CPUQuota:    "50%",
+			MemoryMax:   "2GB",
+			Nice:        10,
+			Environment: []string{"FOO=bar", "EMPTY="},
+			ExtraFlags:  "--web-ui",
+		}},
+		{name: "infinity memory", opts: UnitOptions{MemoryMax: "infinity"}},
+		{name: "cpu quota without percent", opts: UnitOptions{CPUQuota: "50"}, wantErr: true},
+		{name: "zero cpu quota", opts: UnitOptions{CPUQuota: "0%"}, wantErr: true},
+		{name: "negative cpu quota", opts: UnitOptions{CPUQuota: "-5%"}, wantErr: true},
+		{name: "invalid memory", opts: UnitOptions{MemoryMax: "lots"}, wantErr: true},
+		{name: "zero memory", opts: UnitOptions{MemoryMax: "0"}, wantErr: true},
+		{name: "nice too low", opts: UnitOptions{Nice: -21}, wantErr: true},
+		{name: "nice too high", opts: UnitOptions{Nice: 20}, wantErr: true},
+		{name: "env without value", opts: UnitOptions{Environment: []string{"FOO"}}, wantErr: true},
+		{name: "env invalid key", opts: UnitOptions{Environment: []string{"1FOO=bar"}}, wantErr: true},
+		{name: "env with quote", opts: UnitOptions{Environment: []string{`FOO=b"ar`}}, wantErr: true},
+		{name: "extra flags with newline", opts: UnitOptions{ExtraFlags: "--a\n--b"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUnitDropInContents(t *testing.T) {
+	t.Run("no options", func(t *testing.T) {
+		assert.Empty(t, UnitDropInContents(UnitOptions{}))
+	})
+
+	t.Run("with options", func(t *testing.T) {
+		opts := UnitOptions{
+			CPUQuota:    "50%",
+			MemoryMax:   "2GB",
+			Nice:        10,
+			Environment: []string{"FOO=bar baz", "PCT=100%h"},
+			ExtraFlags:  " --web-ui --log-format=%d ",
+		}
+		require.NoError(t, opts.Validate())
+
+		content := UnitDropInContents(opts)
+		assert.True(t, strings.HasPrefix(content, "# customized by \"gpud up\"\n[Service]\n"))
+		assert.Contains(t, content, "CPUQuota=50%\n")
+		assert.Contains(t, content, "MemoryMax=2000000000\n")
+		assert.Contains(t, content, "Nice=10\n")
+		assert.Contains(t, content, `Environment="FOO=bar baz"`+"\n")
+		assert.Contains(t, content, `Environment="PCT=100%%h"`+"\n")
+
+		// the empty ExecStart resets the one in the unit file
+		assert.Contains(t, content, "\nExecStart=\nExecStart=")
+		assert.Contains(t, content, " run $FLAGS --web-ui --log-format=%%d\n")
+	})
+
+	t.Run("infinity memory", func(t *testing.T) {
+		content := UnitDropInContents(UnitOptions{MemoryMax: "infinity"})
+		assert.Contains(t, content, "MemoryMax=infinity\n")
+	})
+}
+
+func TestWriteUnitDropIn(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "gpud.service.d", "gpud-up.conf")
+
+	require.NoError(t, WriteUnitDropIn(file, UnitOptions{Nice: 5}))
+	b, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "Nice=5\n")
+
+	// no option removes the previous customizations
+	require.NoError(t, WriteUnitDropIn(file, UnitOptions{}))
+	_, err = os.Stat(file)
+	assert.True(t, os.IsNotExist(err))
+
+	// no-op if already removed
+	require.NoError(t, WriteUnitDropIn(file, UnitOptions{}))
+}
@@ -0,0 +1,129 @@
+package hooks
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+var (
+	ErrHookNameRequired    = errors.New("hook name is required")
+	ErrHookCommandRequired = errors.New("hook command is required")
+	ErrInvalidHookHealth   = errors.New("invalid hook health state")
+	ErrInvalidHookTimeout  = errors.New("hook timeout must be non-negative")
+	ErrDuplicateHookName   = errors.New("duplicate hook name")
+	ErrInvalidComponentPat = errors.New("invalid hook component pattern")
+)
+
+// Hook is a local command run on the matching health transitions
+// (e.g., run "/etc/gpud/hooks/ib-down.sh" once the infiniband component is unhealthy).
+type Hook struct {
+	// Name is the unique name of the hook.
+	Name string `json:"name"`
+	// Components are the glob patterns of the component names to run the hook for
+	// (e.g., "accelerator-nvidia-infiniband"). Leave empty to match all the components.
+	Components []string `json:"components,omitempty"`
+	// To are the health states transitioned to (e.g., "Unhealthy").
+	// Leave empty to match any transition.
+	To []apiv1.HealthStateType `json:"to,omitempty"`
+	// From are the health states transitioned from (e.g., "Healthy").
+	// Leave empty to match any transition.
+	From []apiv1.HealthStateType `json:"from,omitempty"`
+	// Command is the command (run with bash), with the transitioned
+	// state in the environment variables (e.g., "GPUD_HOOK_COMPONENT").
+	Command string `json:"command"`
+	// Timeout is the time limit of the command, killed when exceeded.
+	// Zero to use the default.
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+}
+
+// Validate validates the hook.
+func (h Hook) Validate() error {
+	if h.Name == "" {
+		return ErrHookNameRequired
+	}
+	if h.Command == "" {
+		return fmt.Errorf("%w (hook %q)", ErrHookCommandRequired, h.Name)
+	}
+	for _, pat := range h.Components {
+		if _, err := path.Match(pat, ""); err != nil {
+			return fmt.Errorf("%w %q (hook %q)", ErrInvalidComponentPat, pat, h.Name)
+		}
+	}
+	for _, health := range append(append([]apiv1.HealthStateType{}, h.To...), h.From...) {
+		switch health {
+		case apiv1.HealthStateTypeHealthy, apiv1.HealthStateTypeUnhealthy, apiv1.HealthStateTypeDegraded, apiv1.HealthStateTypeInitializing:
+		default:
+			return fmt.Errorf("%w %q (hook %q)", ErrInvalidHookHealth, health, h.Name)
+		}
+	}
+	if h.Timeout.Duration < 0 {
+		return fmt.Errorf("%w, got %v (hook %q)", ErrInvalidHookTimeout, h.Timeout.Duration, h.Name)
+	}
+	return nil
+}
+
+// Matches returns true if the hook runs on the health transition of the component.
+// The previous health is empty for the first observed state.
+func (h Hook) Matches(component string, from apiv1.HealthStateType, to apiv1.HealthStateType) bool {
+	if len(h.Components) > 0 {
+		matched := false
+		for _, pat := range h.Components {
+			if ok, _ := path.Match(pat, component); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return matchesHealth(h.To, to) && matchesHealth(h.From, from)
+}
+
+func matchesHealth(healths []apiv1.HealthStateType, health apiv1.HealthStateType) bool {
+	if len(healths) == 0 {
+		return true
+	}
+	for _, h := range healths {
+		if h == health {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadHooks loads and validates the hooks from the YAML file.
+func LoadHooks(file string) ([]Hook, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParseHooks(b)
+}
+
+// ParseHooks parses and validates the hooks from the YAML content.
+func ParseHooks(b []byte) ([]Hook, error) {
+	var hooks []Hook
+	if err := yaml.Unmarshal(b, &hooks); err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]struct{}, len(hooks))
+	for _, h := range hooks {
+		if err := h.Validate(); err != nil {
+			return nil, err
+		}
+		if _, ok := names[h.Name]; ok {
+			return nil, fmt.Errorf("%w %q", ErrDuplicateHookName, h.Name)
+		}
+		names[h.Name] = struct{}{}
+	}
+	return hooks, nil
+}
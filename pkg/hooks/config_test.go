@@ -0,0 +1,92 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestHookValidate(t *testing.T) {
+	valid := Hook{Name: "ib-down", Command: "/etc/gpud/hooks/ib-down.sh"}
+	require.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name string
+		hook Hook
+		err  error
+	}{
+		{"no name", Hook{Command: "true"}, ErrHookNameRequired},
+		{"no command", Hook{Name: "a"}, ErrHookCommandRequired},
+		{"invalid pattern", Hook{Name: "a", Command: "true", Components: []string{"["}}, ErrInvalidComponentPat},
+		{"invalid to", Hook{Name: "a", Command: "true", To: []apiv1.HealthStateType{"Broken"}}, ErrInvalidHookHealth},
+		{"invalid from", Hook{Name: "a", Command: "true", From: []apiv1.HealthStateType{"Broken"}}, ErrInvalidHookHealth},
+		{"negative timeout", Hook{Name: "a", Command: "true", Timeout: metav1.Duration{Duration: -time.Second}}, ErrInvalidHookTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.hook.Validate(), tt.err)
+		})
+	}
+}
+
+func TestHookMatches(t *testing.T) {
+	h := Hook{
+		Name:       "ib-down",
+		Command:    "true",
+		Components: []string{"accelerator-nvidia-infiniband"},
+		To:         []apiv1.HealthStateType{apiv1.HealthStateTypeUnhealthy},
+	}
+	assert.True(t, h.Matches("accelerator-nvidia-infiniband", apiv1.HealthStateTypeHealthy, apiv1.HealthStateTypeUnhealthy))
+	assert.True(t, h.Matches("accelerator-nvidia-infiniband", "", apiv1.HealthStateTypeUnhealthy))
+	assert.False(t, h.Matches("accelerator-nvidia-infiniband", apiv1.HealthStateTypeUnhealthy, apiv1.HealthStateTypeHealthy))
+	assert.False(t, h.Matches("memory", apiv1.HealthStateTypeHealthy, apiv1.HealthStateTypeUnhealthy))
+
+	h.From = []apiv1.HealthStateType{apiv1.HealthStateTypeDegraded}
+	assert.False(t, h.Matches("accelerator-nvidia-infiniband", apiv1.HealthStateTypeHealthy, apiv1.HealthStateTypeUnhealthy))
+	assert.True(t, h.Matches("accelerator-nvidia-infiniband", apiv1.HealthStateTypeDegraded, apiv1.HealthStateTypeUnhealthy))
+
+	// no filter matches any transition
+	assert.True(t, Hook{Name: "any", Command: "true"}.Matches("memory", apiv1.HealthStateTypeUnhealthy, apiv1.HealthStateTypeHealthy))
+}
+
+func TestLoadHooks(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "hooks.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+- name: ib-down
+  components: ["accelerator-nvidia-infiniband"]
+  to: ["Unhealthy"]
+  command: /etc/gpud/hooks/ib-down.sh
+  timeout: 30s
+- name: recovered
+  from: ["Unhealthy", "Degraded"]
+  to: ["Healthy"]
+  command: echo recovered
+`), 0644))
+
+	hooks, err := LoadHooks(file)
+	require.NoError(t, err)
+	require.Len(t, hooks, 2)
+	assert.Equal(t, "ib-down", hooks[0].Name)
+	assert.Equal(t, 30*time.Second, hooks[0].Timeout.Duration)
+	assert.Equal(t, []apiv1.HealthStateType{apiv1.HealthStateTypeUnhealthy, apiv1.HealthStateTypeDegraded}, hooks[1].From)
+
+	require.NoError(t, os.WriteFile(file, []byte(`
+- name: a
+  command: "true"
+- name: a
+  command: "false"
+`), 0644))
+	_, err = LoadHooks(file)
+	assert.ErrorIs(t, err, ErrDuplicateHookName)
+
+	_, err = LoadHooks(filepath.Join(dir, "not-found.yaml"))
+	assert.Error(t, err)
+}
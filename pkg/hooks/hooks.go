@@ -0,0 +1,199 @@
+// Package hooks runs the operator-registered local commands on the health
+// transitions of the components (e.g., run "/etc/gpud/hooks/ib-down.sh" once
+// the infiniband component is unhealthy), with the transitioned state in the
+// environment variables, and records the command output as the component events.
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgdisruption "github.com/leptonai/gpud/pkg/disruption"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgtimeline "github.com/leptonai/gpud/pkg/timeline"
+	"github.com/leptonai/gpud/pkg/toolexec"
+)
+
+const (
+	// DefaultTimeout is the default time limit of the hook command.
+	DefaultTimeout = time.Minute
+
+	// EventNameHookExecuted is the name of the event recorded
+	// in the component bucket once the hook command ran.
+	EventNameHookExecuted = "hook_executed"
+
+	// maxEventOutputBytes is the cap of the command output kept in the event.
+	maxEventOutputBytes = 4096
+)
+
+// Runner runs the matching hooks on the health transitions of the components,
+// as recorded by the timeline recorder (see HandleTransition), so that the
+// transition already recorded before the restart is not hooked again.
+// The hooks run in the background, not to hold the recorder and the other hooks.
+type Runner struct {
+	hooks []Hook
+
+	nowFunc    func() time.Time
+	runFunc    func(ctx context.Context, command string, timeout time.Duration, envs []string) (*toolexec.Result, error)
+	insertFunc func(ctx context.Context, ev eventstore.Event) error
+
+	mu sync.Mutex
+	// disruptions is nil if no disruption is expected
+	disruptions *pkgdisruption.Windows
+
+	// wg tracks the running hooks
+	wg sync.WaitGroup
+}
+
+// NewRunner creates a new hook runner, recording the hook outputs in the
+// event store buckets of the transitioned components.
+// With the nil event store, the outputs are only logged.
+func NewRunner(store eventstore.Store, hooks []Hook) (*Runner, error) {
+	for _, h := range hooks {
+		if err := h.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return &Runner{
+		hooks:   hooks,
+		nowFunc: time.Now,
+		runFunc: runCommand,
+		insertFunc: func(ctx context.Context, ev eventstore.Event) error {
+			if store == nil {
+				return nil
+			}
+			bucket, err := store.Bucket(ev.Component)
+			if err != nil {
+				return err
+			}
+			defer bucket.Close()
+			return bucket.Insert(ctx, ev)
+		},
+	}, nil
+}
+
+// SetDisruptions skips the hooks on the transitions of the components inside
+// the windows of the expected disruption (e.g., the port flaps during the planned
+// fabric upgrade).
+func (r *Runner) SetDisruptions(ws *pkgdisruption.Windows) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.disruptions = ws
+}
+
+// HandleTransition runs the hooks matching the health transition in the background.
+// The transitions of the synthetic states injected by the simulation and the
+// transitions inside the windows of the expected disruption are not hooked.
+func (r *Runner) HandleTransition(ctx context.Context, t pkgtimeline.Transition) {
+	if t.State.ExtraInfo[apiv1.SyntheticExtraInfoKey] == "true" {
+		return
+	}
+
+	r.mu.Lock()
+	disruptions := r.disruptions
+	r.mu.Unlock()
+	if id := disruptions.Match(t.Component, r.nowFunc()); id != "" {
+		log.Logger.Infow("skipping health transition hooks in expected disruption window", "component", t.Component, "name", t.State.Name, "from", t.From, "to", t.State.Health, "window", id)
+		return
+	}
+
+	for _, h := range r.hooks {
+		if !h.Matches(t.Component, t.From, t.State.Health) {
+			continue
+		}
+		r.wg.Add(1)
+		go func(h Hook) {
+			defer r.wg.Done()
+			r.run(ctx, h, t)
+		}(h)
+	}
+}
+
+// Wait waits for the running hooks to finish.
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
+
+// run runs the hook command, and records its output as the component event.
+func (r *Runner) run(ctx context.Context, h Hook, t pkgtimeline.Transition) {
+	timeout := h.Timeout.Duration
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	log.Logger.Infow("running health transition hook", "hook", h.Name, "component", t.Component, "name", t.State.Name, "from", t.From, "to", t.State.Health)
+	res, err := r.runFunc(ctx, h.Command, timeout, envsOf(h, t))
+
+	exitCode := -1
+	var output string
+	if res != nil {
+		exitCode = res.ExitCode
+		output = strings.TrimSpace(string(res.Output))
+	}
+	if len(output) > maxEventOutputBytes {
+		output = output[:maxEventOutputBytes]
+	}
+
+	ev := eventstore.Event{
+		Component: t.Component,
+		Time:      r.nowFunc().UTC(),
+		Name:      EventNameHookExecuted,
+		Type:      string(apiv1.EventTypeInfo),
+		Message:   fmt.Sprintf("hook %q ran on %s %s -> %s, exited %d", h.Name, t.State.Name, describeHealth(t.From), t.State.Health, exitCode),
+		ExtraInfo: map[string]string{
+			"hook":      h.Name,
+			"exit_code": strconv.Itoa(exitCode),
+			"output":    output,
+		},
+	}
+	if err != nil {
+		log.Logger.Warnw("health transition hook failed", "hook", h.Name, "component", t.Component, "exitCode", exitCode, "error", err)
+		ev.Type = string(apiv1.EventTypeWarning)
+		ev.Message += ": " + err.Error()
+		ev.ExtraInfo["error"] = err.Error()
+	}
+
+	if err := r.insertFunc(ctx, ev); err != nil {
+		log.Logger.Warnw("failed to record health transition hook event", "hook", h.Name, "component", t.Component, "error", err)
+	}
+}
+
+// envsOf returns the environment variables carrying the transitioned state.
+func envsOf(h Hook, t pkgtimeline.Transition) []string {
+	codes := make([]string, 0, len(t.State.FailureCodes))
+	for _, c := range t.State.FailureCodes {
+		codes = append(codes, string(c))
+	}
+	state, _ := json.Marshal(t.State)
+
+	return []string{
+		"GPUD_HOOK_NAME=" + h.Name,
+		"GPUD_HOOK_COMPONENT=" + t.Component,
+		"GPUD_HOOK_STATE_NAME=" + t.State.Name,
+		"GPUD_HOOK_HEALTH=" + string(t.State.Health),
+		"GPUD_HOOK_PREVIOUS_HEALTH=" + string(t.From),
+		"GPUD_HOOK_REASON=" + t.State.Reason,
+		"GPUD_HOOK_ERROR=" + t.State.Error,
+		"GPUD_HOOK_FAILURE_CODES=" + strings.Join(codes, ","),
+		// the full health state in JSON (e.g., the suggested actions, the extra info)
+		"GPUD_HOOK_STATE=" + string(state),
+	}
+}
+
+func describeHealth(h apiv1.HealthStateType) string {
+	if h == "" {
+		return "(none)"
+	}
+	return string(h)
+}
+
+func runCommand(ctx context.Context, command string, timeout time.Duration, envs []string) (*toolexec.Result, error) {
+	return toolexec.Run(ctx, []string{"bash", "-c", command}, toolexec.WithTimeout(timeout), toolexec.WithEnvs(envs...))
+}
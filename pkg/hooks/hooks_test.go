@@ -0,0 +1,241 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgdisruption "github.com/leptonai/gpud/pkg/disruption"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	pkgtimeline "github.com/leptonai/gpud/pkg/timeline"
+	"github.com/leptonai/gpud/pkg/toolexec"
+)
+
+type ran struct {
+	command string
+	timeout time.Duration
+	envs    []string
+}
+
+// recorder records the hook runs and the events, run in the background.
+type recorder struct {
+	mu     sync.Mutex
+	runs   []ran
+	events []eventstore.Event
+}
+
+func (rec *recorder) sortedRuns() []ran {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	runs := append([]ran(nil), rec.runs...)
+	sort.Slice(runs, func(i, j int) bool { return runs[i].command < runs[j].command })
+	return runs
+}
+
+func transitionOf(component string, from apiv1.HealthStateType, to apiv1.HealthStateType, reason string) pkgtimeline.Transition {
+	return pkgtimeline.Transition{
+		Component: component,
+		From:      from,
+		State:     apiv1.HealthState{Name: component, Health: to, Reason: reason},
+	}
+}
+
+func TestRunner(t *testing.T) {
+	ctx := context.Background()
+
+	r, err := NewRunner(nil, []Hook{
+		{
+			Name:       "ib-down",
+			Components: []string{"accelerator-nvidia-*"},
+			To:         []apiv1.HealthStateType{apiv1.HealthStateTypeUnhealthy},
+			Command:    "/etc/gpud/hooks/ib-down.sh",
+		},
+		{
+			Name:    "recovered",
+			From:    []apiv1.HealthStateType{apiv1.HealthStateTypeUnhealthy},
+			To:      []apiv1.HealthStateType{apiv1.HealthStateTypeHealthy},
+			Command: "echo recovered",
+			Timeout: metav1.Duration{Duration: 5 * time.Second},
+		},
+	})
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 0).UTC()
+	r.nowFunc = func() time.Time { return now }
+
+	rec := &recorder{}
+	r.runFunc = func(_ context.Context, command string, timeout time.Duration, envs []string) (*toolexec.Result, error) {
+		rec.mu.Lock()
+		rec.runs = append(rec.runs, ran{command: command, timeout: timeout, envs: envs})
+		rec.mu.Unlock()
+		if strings.HasPrefix(command, "/etc") {
+			return &toolexec.Result{Output: []byte("draining\n"), ExitCode: 1}, errors.New("command exited with error: exit status 1")
+		}
+		return &toolexec.Result{Output: []byte("ok\n")}, nil
+	}
+	r.insertFunc = func(_ context.Context, ev eventstore.Event) error {
+		rec.mu.Lock()
+		rec.events = append(rec.events, ev)
+		rec.mu.Unlock()
+		return nil
+	}
+
+	// only the matching component runs the hook
+	r.HandleTransition(ctx, transitionOf("accelerator-nvidia-infiniband", apiv1.HealthStateTypeHealthy, apiv1.HealthStateTypeUnhealthy, "port down"))
+	r.HandleTransition(ctx, transitionOf("memory", apiv1.HealthStateTypeHealthy, apiv1.HealthStateTypeUnhealthy, "ecc errors"))
+	r.Wait()
+	runs := rec.sortedRuns()
+	require.Len(t, runs, 1)
+	assert.Equal(t, "/etc/gpud/hooks/ib-down.sh", runs[0].command)
+	assert.Equal(t, DefaultTimeout, runs[0].timeout)
+	assert.Contains(t, runs[0].envs, "GPUD_HOOK_COMPONENT=accelerator-nvidia-infiniband")
+	assert.Contains(t, runs[0].envs, "GPUD_HOOK_HEALTH=Unhealthy")
+	assert.Contains(t, runs[0].envs, "GPUD_HOOK_PREVIOUS_HEALTH=Healthy")
+	assert.Contains(t, runs[0].envs, "GPUD_HOOK_REASON=port down")
+
+	// the failed hook is recorded as the warning event with the output
+	require.Len(t, rec.events, 1)
+	ev := rec.events[0]
+	assert.Equal(t, "accelerator-nvidia-infiniband", ev.Component)
+	assert.Equal(t, EventNameHookExecuted, ev.Name)
+	assert.Equal(t, string(apiv1.EventTypeWarning), ev.Type)
+	assert.Equal(t, "draining", ev.ExtraInfo["output"])
+	assert.Equal(t, "1", ev.ExtraInfo["exit_code"])
+	assert.Contains(t, ev.Message, `hook "ib-down" ran on accelerator-nvidia-infiniband Healthy -> Unhealthy, exited 1`)
+
+	// both recover
+	r.HandleTransition(ctx, transitionOf("accelerator-nvidia-infiniband", apiv1.HealthStateTypeUnhealthy, apiv1.HealthStateTypeHealthy, "ok"))
+	r.HandleTransition(ctx, transitionOf("memory", apiv1.HealthStateTypeUnhealthy, apiv1.HealthStateTypeHealthy, "ok"))
+	r.Wait()
+	runs = rec.sortedRuns()
+	require.Len(t, runs, 3)
+	assert.Equal(t, "echo recovered", runs[1].command)
+	assert.Equal(t, 5*time.Second, runs[1].timeout)
+	require.Len(t, rec.events, 3)
+	for _, ev := range rec.events[1:] {
+		assert.Equal(t, string(apiv1.EventTypeInfo), ev.Type)
+		assert.Equal(t, "ok", ev.ExtraInfo["output"])
+	}
+}
+
+func TestRunnerFirstObservedUnhealthy(t *testing.T) {
+	r, err := NewRunner(nil, []Hook{{Name: "any", Command: "true"}})
+	require.NoError(t, err)
+
+	rec := &recorder{}
+	r.runFunc = func(_ context.Context, command string, timeout time.Duration, envs []string) (*toolexec.Result, error) {
+		rec.mu.Lock()
+		rec.runs = append(rec.runs, ran{command: command, envs: envs})
+		rec.mu.Unlock()
+		return &toolexec.Result{}, nil
+	}
+
+	r.HandleTransition(context.Background(), transitionOf("accelerator-nvidia-infiniband", "", apiv1.HealthStateTypeUnhealthy, "port down"))
+	r.Wait()
+	runs := rec.sortedRuns()
+	require.Len(t, runs, 1)
+	assert.Contains(t, runs[0].envs, "GPUD_HOOK_PREVIOUS_HEALTH=")
+}
+
+func TestRunnerAsync(t *testing.T) {
+	r, err := NewRunner(nil, []Hook{{Name: "slow", Command: "sleep"}, {Name: "fast", Command: "true"}})
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+	fast := make(chan struct{})
+	r.runFunc = func(_ context.Context, command string, _ time.Duration, _ []string) (*toolexec.Result, error) {
+		if command == "sleep" {
+			<-release
+		} else {
+			close(fast)
+		}
+		return &toolexec.Result{}, nil
+	}
+
+	// the slow hook holds neither the caller nor the other hooks
+	r.HandleTransition(context.Background(), transitionOf("memory", "", apiv1.HealthStateTypeUnhealthy, "oom"))
+	select {
+	case <-fast:
+	case <-time.After(10 * time.Second):
+		t.Fatal("fast hook did not run while the slow hook is running")
+	}
+	close(release)
+	r.Wait()
+}
+
+func TestRunnerSynthetic(t *testing.T) {
+	r, err := NewRunner(nil, []Hook{{Name: "any", Command: "true"}})
+	require.NoError(t, err)
+	runs := 0
+	r.runFunc = func(context.Context, string, time.Duration, []string) (*toolexec.Result, error) {
+		runs++
+		return &toolexec.Result{}, nil
+	}
+
+	tr := transitionOf("memory", "", apiv1.HealthStateTypeUnhealthy, "oom")
+	tr.State.ExtraInfo = map[string]string{apiv1.SyntheticExtraInfoKey: "true"}
+	r.HandleTransition(context.Background(), tr)
+	r.Wait()
+	assert.Zero(t, runs)
+}
+
+func TestRunnerExpectedDisruption(t *testing.T) {
+	r, err := NewRunner(nil, []Hook{{Name: "any", Command: "true"}})
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 0).UTC()
+	r.nowFunc = func() time.Time { return now }
+	var runs atomic.Int32
+	r.runFunc = func(context.Context, string, time.Duration, []string) (*toolexec.Result, error) {
+		runs.Add(1)
+		return &toolexec.Result{}, nil
+	}
+
+	ws := pkgdisruption.New()
+	_, err = ws.Add(now, apiv1.DisruptionWindow{
+		Components: []string{"accelerator-nvidia-infiniband"},
+		Start:      metav1.NewTime(now),
+		End:        metav1.NewTime(now.Add(time.Hour)),
+	}, nil)
+	require.NoError(t, err)
+	r.SetDisruptions(ws)
+
+	// the transitions inside the window are not hooked
+	r.HandleTransition(context.Background(), transitionOf("accelerator-nvidia-infiniband", apiv1.HealthStateTypeHealthy, apiv1.HealthStateTypeUnhealthy, "port down"))
+	r.Wait()
+	assert.Zero(t, runs.Load())
+
+	// hooked after the window
+	now = now.Add(2 * time.Hour)
+	r.HandleTransition(context.Background(), transitionOf("accelerator-nvidia-infiniband", apiv1.HealthStateTypeUnhealthy, apiv1.HealthStateTypeHealthy, "ok"))
+	r.Wait()
+	assert.Equal(t, int32(1), runs.Load())
+}
+
+func TestRunCommand(t *testing.T) {
+	res, err := runCommand(context.Background(), `echo "$GPUD_HOOK_COMPONENT $GPUD_HOOK_HEALTH"`, 10*time.Second, []string{"GPUD_HOOK_COMPONENT=memory", "GPUD_HOOK_HEALTH=Unhealthy"})
+	require.NoError(t, err)
+	assert.Equal(t, "memory Unhealthy\n", string(res.Output))
+
+	res, err = runCommand(context.Background(), "exit 3", 10*time.Second, nil)
+	require.Error(t, err)
+	assert.Equal(t, 3, res.ExitCode)
+
+	_, err = runCommand(context.Background(), "sleep 10", 100*time.Millisecond, nil)
+	assert.ErrorIs(t, err, toolexec.ErrTimeout)
+}
+
+func TestNewRunnerInvalidHook(t *testing.T) {
+	_, err := NewRunner(nil, []Hook{{Name: "a"}})
+	assert.ErrorIs(t, err, ErrHookCommandRequired)
+}
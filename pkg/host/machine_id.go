@@ -9,9 +9,9 @@ import (
 	"path"
 	"strings"
 
-	"github.com/leptonai/gpud/pkg/file"
 	"github.com/leptonai/gpud/pkg/log"
 	"github.com/leptonai/gpud/pkg/process"
+	"github.com/leptonai/gpud/pkg/toolpath"
 )
 
 // Returns the UUID of the machine host.
@@ -34,7 +34,7 @@ func GetMachineID(ctx context.Context) (string, error) {
 // ref.
 // UUID=$(dmidecode -t 1 | grep -i UUID | awk '{print $2}')
 func GetDmidecodeUUID(ctx context.Context) (string, error) {
-	dmidecodePath, err := file.LocateExecutable("dmidecode")
+	dmidecodePath, err := toolpath.Locate("dmidecode")
 	if err != nil {
 		return "", errors.New("dmidecode not found")
 	}
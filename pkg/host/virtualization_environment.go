@@ -5,9 +5,9 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/leptonai/gpud/pkg/file"
 	"github.com/leptonai/gpud/pkg/log"
 	"github.com/leptonai/gpud/pkg/process"
+	"github.com/leptonai/gpud/pkg/toolpath"
 )
 
 // VirtualizationEnvironment represents the virtualization environment of the host.
@@ -36,7 +36,7 @@ type VirtualizationEnvironment struct {
 
 // GetSystemdDetectVirt detects the virtualization type of the host, using "systemd-detect-virt".
 func GetSystemdDetectVirt(ctx context.Context) (VirtualizationEnvironment, error) {
-	detectExecPath, err := file.LocateExecutable("systemd-detect-virt")
+	detectExecPath, err := toolpath.Locate("systemd-detect-virt")
 	if err != nil {
 		return VirtualizationEnvironment{}, nil
 	}
@@ -106,7 +106,7 @@ func GetSystemdDetectVirt(ctx context.Context) (VirtualizationEnvironment, error
 
 // GetSystemManufacturer detects the system manufacturer, using "dmidecode".
 func GetSystemManufacturer(ctx context.Context) (string, error) {
-	dmidecodePath, err := file.LocateExecutable("dmidecode")
+	dmidecodePath, err := toolpath.Locate("dmidecode")
 	if err != nil {
 		return "", nil
 	}
@@ -4,8 +4,14 @@ const (
 	RequestHeaderContentType = "Content-Type"
 	RequestHeaderJSON        = "application/json"
 	RequestHeaderYAML        = "application/yaml"
+	RequestHeaderProtobuf    = "application/x-protobuf"
+	RequestHeaderCSV         = "text/csv"
 	RequestHeaderJSONIndent  = "json-indent"
 
+	// RequestHeaderSchemaVersion is the schema version of the health states
+	// and events the consumer expects (e.g., "v1"), defaults to the current one.
+	RequestHeaderSchemaVersion = "schema-version"
+
 	RequestHeaderAcceptEncoding = "Accept-Encoding"
 	RequestHeaderEncodingGzip   = "gzip"
 )
@@ -0,0 +1,132 @@
+// Package ipmi reads the sensors (e.g., the chassis fans, the inlet temperatures)
+// from the baseboard management controller via "ipmitool".
+package ipmi
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/leptonai/gpud/pkg/toolexec"
+	"github.com/leptonai/gpud/pkg/toolpath"
+)
+
+// DefaultIPMITool is the ipmitool binary to list the sensors
+// with their readings and thresholds (via "ipmitool sensor").
+const DefaultIPMITool = "ipmitool"
+
+const (
+	// UnitRPM is the unit of the fan sensors.
+	UnitRPM = "RPM"
+	// UnitDegreesC is the unit of the temperature sensors.
+	UnitDegreesC = "degrees C"
+)
+
+var ErrNoIPMITool = errors.New("ipmitool not found, cannot read ipmi sensors")
+
+// Sensor is the reading of an IPMI sensor.
+type Sensor struct {
+	// Name is the sensor name (e.g., "FAN1", "Inlet Temp").
+	Name string `json:"name"`
+	// Value is the current reading.
+	Value float64 `json:"value"`
+	// Unit is the unit of the reading (e.g., "RPM", "degrees C").
+	Unit string `json:"unit"`
+	// Status is the sensor status (e.g., "ok", "nc", "cr", "nr").
+	Status string `json:"status"`
+	// UpperNonCritical is the upper non-critical threshold,
+	// zero if the BMC does not report one.
+	UpperNonCritical float64 `json:"upper_non_critical,omitempty"`
+	// NoReading is true if the sensor reports no reading ("na"),
+	// e.g., the failed fan, or the fan slot not populated.
+	NoReading bool `json:"no_reading,omitempty"`
+}
+
+// criticalStatuses are the sensor statuses beyond the critical
+// or the non-recoverable thresholds, in both the short and the long forms.
+var criticalStatuses = map[string]struct{}{
+	"cr": {}, "nr": {},
+	"lcr": {}, "lnr": {},
+	"ucr": {}, "unr": {},
+}
+
+// Critical returns true if the reading is beyond the critical
+// or the non-recoverable thresholds (e.g., the fan below the lower critical RPM).
+func (s Sensor) Critical() bool {
+	_, ok := criticalStatuses[s.Status]
+	return ok
+}
+
+// GetSensors returns the readings of the IPMI sensors, including the threshold
+// sensors without a reading (see Sensor.NoReading).
+// The ipmitool is the binary name or path, resolved with the tool path overrides.
+func GetSensors(ctx context.Context, ipmitool string) ([]Sensor, error) {
+	if strings.TrimSpace(ipmitool) == "" {
+		ipmitool = DefaultIPMITool
+	}
+	binPath, err := toolpath.Locate(ipmitool)
+	if err != nil {
+		return nil, ErrNoIPMITool
+	}
+
+	res, err := toolexec.Run(ctx, []string{binPath, "sensor"})
+	if err != nil {
+		var out string
+		if res != nil {
+			out = strings.TrimSpace(string(res.Output))
+		}
+		return nil, fmt.Errorf("failed to run %q: %w (output %q)", binPath+" sensor", err, out)
+	}
+	return ParseSensors(res.Output), nil
+}
+
+// ParseSensors parses the "ipmitool sensor" output, in the columns of
+// the name, value, unit, status, and the lower non-recoverable, lower critical,
+// lower non-critical, upper non-critical, upper critical, upper non-recoverable thresholds:
+//
+//	FAN1             | 5400.000   | RPM        | ok    | na        | 300.000   | 500.000   | 25300.000 | 25400.000 | na
+//
+// The threshold sensors without a reading ("na") are returned with NoReading,
+// and the discrete sensors (without the unit of the reading) are skipped.
+func ParseSensors(b []byte) []Sensor {
+	var sensors []Sensor
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "|")
+		if len(fields) < 4 {
+			continue
+		}
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+
+		s := Sensor{
+			Name:   fields[0],
+			Unit:   fields[2],
+			Status: fields[3],
+		}
+		if s.Unit == "" || s.Unit == "discrete" {
+			continue
+		}
+		if fields[1] == "na" {
+			s.NoReading = true
+		} else {
+			v, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil {
+				continue
+			}
+			s.Value = v
+		}
+		if len(fields) > 7 {
+			if unc, err := strconv.ParseFloat(fields[7], 64); err == nil {
+				s.UpperNonCritical = unc
+			}
+		}
+		sensors = append(sensors, s)
+	}
+	return sensors
+}
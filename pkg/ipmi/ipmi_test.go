@@ -0,0 +1,51 @@
+package ipmi
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSensors(t *testing.T) {
+	out := `Inlet Temp       | 24.000     | degrees C  | ok    | na        | -7.000    | 3.000     | 38.000    | 42.000    | na
+FAN1             | 5400.000   | RPM        | ok    | na        | 300.000   | 500.000   | 25300.000 | 25400.000 | na
+FAN2             | 0.000      | RPM        | cr    | na        | 300.000   | 500.000   | na        | na        | na
+PS1 Status       | 0x1        | discrete   | 0x0100| na        | na        | na        | na        | na        | na
+FAN3             | na         | RPM        | na    | na        | na        | na        | na        | na        | na
+`
+	sensors := ParseSensors([]byte(out))
+	require.Len(t, sensors, 4)
+	assert.Equal(t, Sensor{Name: "Inlet Temp", Value: 24, Unit: UnitDegreesC, Status: "ok", UpperNonCritical: 38}, sensors[0])
+	assert.Equal(t, Sensor{Name: "FAN1", Value: 5400, Unit: UnitRPM, Status: "ok", UpperNonCritical: 25300}, sensors[1])
+	assert.Equal(t, Sensor{Name: "FAN2", Value: 0, Unit: UnitRPM, Status: "cr"}, sensors[2])
+	assert.Equal(t, Sensor{Name: "FAN3", Unit: UnitRPM, Status: "na", NoReading: true}, sensors[3])
+
+	assert.False(t, sensors[1].Critical())
+	assert.True(t, sensors[2].Critical())
+	assert.True(t, Sensor{Status: "lnr"}.Critical())
+	assert.False(t, sensors[3].Critical())
+
+	assert.Empty(t, ParseSensors([]byte("Could not open device at /dev/ipmi0\n")))
+}
+
+func TestGetSensors(t *testing.T) {
+	// the arguments are passed as is, not interpreted by the shell
+	ipmitool := filepath.Join(t.TempDir(), "ipmitool")
+	script := `#!/bin/sh
+[ "$#" -eq 1 ] && [ "$1" = "sensor" ] || exit 1
+echo "FAN1             | 5400.000   | RPM        | ok    | na        | 300.000   | 500.000   | 25300.000 | 25400.000 | na"
+`
+	require.NoError(t, os.WriteFile(ipmitool, []byte(script), 0755))
+
+	sensors, err := GetSensors(context.Background(), ipmitool)
+	require.NoError(t, err)
+	require.Len(t, sensors, 1)
+	assert.Equal(t, "FAN1", sensors[0].Name)
+
+	_, err = GetSensors(context.Background(), filepath.Join(t.TempDir(), "not-found"))
+	assert.ErrorIs(t, err, ErrNoIPMITool)
+}
@@ -0,0 +1,36 @@
+package kmsg
+
+// Op represents the options for the syncer.
+type Op struct {
+	component string
+	registry  *Registry
+}
+
+// OpOption applies an option to the syncer.
+type OpOption func(*Op)
+
+func (op *Op) applyOpts(opts []OpOption) {
+	for _, opt := range opts {
+		opt(op)
+	}
+	if op.registry == nil {
+		op.registry = DefaultRegistry
+	}
+}
+
+// WithComponent sets the owner component of the syncer, so that the kernel
+// messages not matched by the built-in match function are also matched
+// against the user-supplied rules of the component.
+func WithComponent(name string) OpOption {
+	return func(op *Op) {
+		op.component = name
+	}
+}
+
+// WithRegistry sets the registry of the user-supplied rules (useful for testing).
+// Defaults to the DefaultRegistry.
+func WithRegistry(r *Registry) OpOption {
+	return func(op *Op) {
+		op.registry = r
+	}
+}
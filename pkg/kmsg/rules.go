@@ -0,0 +1,212 @@
+package kmsg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/eventstore"
+)
+
+var (
+	ErrRuleNameRequired      = errors.New("kmsg rule name is required")
+	ErrRuleRegexRequired     = errors.New("kmsg rule regex is required")
+	ErrRuleComponentRequired = errors.New("kmsg rule component is required")
+	ErrInvalidRuleRegex      = errors.New("invalid kmsg rule regex")
+	ErrInvalidRuleEventType  = errors.New("invalid kmsg rule event type")
+	ErrInvalidRuleComponent  = errors.New("invalid kmsg rule component")
+	ErrInvalidRuleAction     = errors.New("invalid kmsg rule suggested action")
+	ErrDuplicateRuleName     = errors.New("duplicate kmsg rule name")
+)
+
+// Rule is a user-supplied kernel message matcher, which creates an event
+// in the owner component when a kernel message matches the regex
+// (e.g., for the site-specific kernel modules).
+type Rule struct {
+	// Name is the unique name of the rule, used as the event name.
+	Name string `json:"name"`
+	// Regex is the regular expression to match the kernel message with.
+	Regex string `json:"regex"`
+	// Component is the name of the owner component to create the event in
+	// (e.g., "os"). The owner component must be one of the RuleComponents.
+	Component string `json:"component"`
+	// EventType is the type of the event to create.
+	// Defaults to "Warning" if empty.
+	EventType apiv1.EventType `json:"event_type,omitempty"`
+	// Message is the event message. Defaults to the matched kernel message if empty.
+	Message string `json:"message,omitempty"`
+	// SuggestedAction is the repair action to suggest for the event, if any
+	// (e.g., "REBOOT_SYSTEM").
+	SuggestedAction apiv1.RepairActionType `json:"suggested_action,omitempty"`
+
+	compiled *regexp.Regexp
+}
+
+// RuleComponents are the components whose kernel message syncers
+// match the user-supplied rules. The xid and sxid components parse
+// the kernel messages on their own, and do not match the rules.
+var RuleComponents = []string{
+	"accelerator-nvidia-infiniband",
+	"accelerator-nvidia-nccl",
+	"accelerator-nvidia-peermem",
+	"cpu",
+	"disk",
+	"memory",
+	"os",
+}
+
+// Validate validates the rule and compiles the regex.
+func (r *Rule) Validate() error {
+	if r.Name == "" {
+		return ErrRuleNameRequired
+	}
+	if r.Regex == "" {
+		return fmt.Errorf("%w (rule %q)", ErrRuleRegexRequired, r.Name)
+	}
+	if r.Component == "" {
+		return fmt.Errorf("%w (rule %q)", ErrRuleComponentRequired, r.Name)
+	}
+	if !slices.Contains(RuleComponents, r.Component) {
+		return fmt.Errorf("%w %q (rule %q, must be one of %v)", ErrInvalidRuleComponent, r.Component, r.Name, RuleComponents)
+	}
+	switch r.EventType {
+	case "":
+		r.EventType = apiv1.EventTypeWarning
+	case apiv1.EventTypeInfo, apiv1.EventTypeWarning, apiv1.EventTypeCritical, apiv1.EventTypeFatal:
+	default:
+		return fmt.Errorf("%w %q (rule %q)", ErrInvalidRuleEventType, r.EventType, r.Name)
+	}
+	switch r.SuggestedAction {
+	case "",
+		apiv1.RepairActionTypeIgnoreNoActionRequired,
+		apiv1.RepairActionTypeRebootSystem,
+		apiv1.RepairActionTypeHardwareInspection,
+		apiv1.RepairActionTypeCheckUserAppAndGPU,
+		apiv1.RepairActionTypeCheckCabling:
+	default:
+		return fmt.Errorf("%w %q (rule %q)", ErrInvalidRuleAction, r.SuggestedAction, r.Name)
+	}
+
+	compiled, err := regexp.Compile(r.Regex)
+	if err != nil {
+		return fmt.Errorf("%w %q (rule %q): %v", ErrInvalidRuleRegex, r.Regex, r.Name, err)
+	}
+	r.compiled = compiled
+	return nil
+}
+
+// Match returns true if the kernel message matches the rule.
+func (r *Rule) Match(line string) bool {
+	if r.compiled == nil {
+		return false
+	}
+	return r.compiled.MatchString(line)
+}
+
+// EventKeySuggestedAction is the event extra info key of the suggested repair action,
+// surfaced as the suggested actions of the event.
+const EventKeySuggestedAction = eventstore.ExtraInfoKeySuggestedAction
+
+// LoadRules loads and validates the kernel message rules from the YAML file.
+func LoadRules(file string) ([]Rule, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRules(b)
+}
+
+// ParseRules parses and validates the kernel message rules from the YAML content.
+func ParseRules(b []byte) ([]Rule, error) {
+	var rules []Rule
+	if err := yaml.Unmarshal(b, &rules); err != nil {
+		return nil, err
+	}
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+// Registry is the set of the user-supplied kernel message rules,
+// consulted by the syncers after their built-in matchers.
+type Registry struct {
+	mu    sync.RWMutex
+	rules []Rule
+}
+
+// NewRegistry creates a new empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// DefaultRegistry is the registry used by the syncers by default.
+var DefaultRegistry = NewRegistry()
+
+// Register validates and adds the rules to the registry.
+// Returns an error without adding any rule if a rule is invalid
+// or its name is already registered.
+func (r *Registry) Register(rules ...Rule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make(map[string]struct{}, len(r.rules)+len(rules))
+	for _, rule := range r.rules {
+		names[rule.Name] = struct{}{}
+	}
+
+	validated := make([]Rule, 0, len(rules))
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+		if _, ok := names[rule.Name]; ok {
+			return fmt.Errorf("%w %q", ErrDuplicateRuleName, rule.Name)
+		}
+		names[rule.Name] = struct{}{}
+		validated = append(validated, rule)
+	}
+
+	r.rules = append(r.rules, validated...)
+	return nil
+}
+
+// Rules returns the registered rules in the registration order.
+func (r *Registry) Rules() []Rule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rules := make([]Rule, len(r.rules))
+	copy(rules, r.rules)
+	return rules
+}
+
+// Match returns the first rule of the component that matches the kernel message,
+// or nil if none matches. Empty component matches the rules of all the components.
+func (r *Registry) Match(component string, line string) *Rule {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := range r.rules {
+		if component != "" && r.rules[i].Component != component {
+			continue
+		}
+		if r.rules[i].Match(line) {
+			rule := r.rules[i]
+			return &rule
+		}
+	}
+	return nil
+}
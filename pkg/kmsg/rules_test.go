@@ -0,0 +1,147 @@
+package kmsg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestRuleValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		wantErr error
+	}{
+		{name: "valid", rule: Rule{Name: "a", Regex: "foo", Component: "os"}},
+		{name: "no name", rule: Rule{Regex: "foo", Component: "os"}, wantErr: ErrRuleNameRequired},
+		{name: "no regex", rule: Rule{Name: "a", Component: "os"}, wantErr: ErrRuleRegexRequired},
+		{name: "no component", rule: Rule{Name: "a", Regex: "foo"}, wantErr: ErrRuleComponentRequired},
+		{name: "invalid regex", rule: Rule{Name: "a", Regex: "(", Component: "os"}, wantErr: ErrInvalidRuleRegex},
+		{name: "invalid event type", rule: Rule{Name: "a", Regex: "foo", Component: "os", EventType: "Bad"}, wantErr: ErrInvalidRuleEventType},
+		{name: "valid suggested action", rule: Rule{Name: "a", Regex: "foo", Component: "os", SuggestedAction: apiv1.RepairActionTypeHardwareInspection}},
+		{name: "invalid suggested action", rule: Rule{Name: "a", Regex: "foo", Component: "os", SuggestedAction: "REBOOT"}, wantErr: ErrInvalidRuleAction},
+		{name: "xid component", rule: Rule{Name: "a", Regex: "foo", Component: "accelerator-nvidia-error-xid"}, wantErr: ErrInvalidRuleComponent},
+		{name: "sxid component", rule: Rule{Name: "a", Regex: "foo", Component: "accelerator-nvidia-error-sxid"}, wantErr: ErrInvalidRuleComponent},
+		{name: "unknown component", rule: Rule{Name: "a", Regex: "foo", Component: "unknown"}, wantErr: ErrInvalidRuleComponent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				assert.Equal(t, apiv1.EventTypeWarning, tt.rule.EventType)
+				return
+			}
+			assert.True(t, errors.Is(err, tt.wantErr), err)
+		})
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+- name: mydrv_fatal
+  regex: "mydrv: fatal error on port \\d+"
+  component: os
+  event_type: Critical
+  suggested_action: REBOOT_SYSTEM
+- name: mydrv_retry
+  regex: "mydrv: retrying"
+  component: os
+`), 0644))
+
+	rules, err := LoadRules(file)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, apiv1.EventTypeCritical, rules[0].EventType)
+	assert.Equal(t, apiv1.RepairActionTypeRebootSystem, rules[0].SuggestedAction)
+	assert.Equal(t, apiv1.EventTypeWarning, rules[1].EventType)
+	assert.True(t, rules[0].Match("mydrv: fatal error on port 3"))
+
+	_, err = LoadRules(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Register(
+		Rule{Name: "a", Regex: "mydrv: fatal", Component: "os"},
+		Rule{Name: "b", Regex: "mydrv: .*", Component: "memory"},
+	))
+	assert.Len(t, r.Rules(), 2)
+
+	err := r.Register(Rule{Name: "a", Regex: "x", Component: "os"})
+	assert.True(t, errors.Is(err, ErrDuplicateRuleName))
+	assert.Len(t, r.Rules(), 2)
+
+	rule := r.Match("os", "mydrv: fatal")
+	require.NotNil(t, rule)
+	assert.Equal(t, "a", rule.Name)
+
+	rule = r.Match("memory", "mydrv: fatal")
+	require.NotNil(t, rule)
+	assert.Equal(t, "b", rule.Name)
+
+	assert.Nil(t, r.Match("cpu", "mydrv: fatal"))
+	assert.NotNil(t, r.Match("", "mydrv: fatal"))
+
+	var nilRegistry *Registry
+	assert.Nil(t, nilRegistry.Match("os", "mydrv: fatal"))
+}
+
+func TestSyncerMatchRules(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Register(Rule{
+		Name:            "mydrv_fatal",
+		Regex:           "mydrv: fatal",
+		Component:       "os",
+		EventType:       apiv1.EventTypeCritical,
+		SuggestedAction: apiv1.RepairActionTypeRebootSystem,
+	}))
+
+	builtin := func(line string) (string, string) {
+		if line == "builtin" {
+			return "builtin_event", "builtin message"
+		}
+		return "", ""
+	}
+	now := metav1.Now()
+
+	w := &Syncer{matchFunc: builtin, component: "os", registry: r}
+	ev, ok := w.match(Message{Timestamp: now, Message: "builtin"})
+	require.True(t, ok)
+	assert.Equal(t, "builtin_event", ev.Name)
+	assert.Equal(t, string(apiv1.EventTypeWarning), ev.Type)
+
+	ev, ok = w.match(Message{Timestamp: now, Message: "mydrv: fatal error"})
+	require.True(t, ok)
+	assert.Equal(t, "mydrv_fatal", ev.Name)
+	assert.Equal(t, string(apiv1.EventTypeCritical), ev.Type)
+	assert.Equal(t, "mydrv: fatal error (suggested action: REBOOT_SYSTEM)", ev.Message)
+	assert.Equal(t, "REBOOT_SYSTEM", ev.ExtraInfo[EventKeySuggestedAction])
+
+	// surfaced as the suggested actions of the event
+	apiEv := ev.ToEvent()
+	require.NotNil(t, apiEv.SuggestedActions)
+	assert.Equal(t, []apiv1.RepairActionType{apiv1.RepairActionTypeRebootSystem}, apiEv.SuggestedActions.RepairActions)
+
+	_, ok = w.match(Message{Timestamp: now, Message: "unrelated"})
+	assert.False(t, ok)
+
+	// rules of the other components are not matched
+	w = &Syncer{matchFunc: builtin, component: "cpu", registry: r}
+	_, ok = w.match(Message{Timestamp: now, Message: "mydrv: fatal error"})
+	assert.False(t, ok)
+
+	// no owner component, no rules
+	w = &Syncer{matchFunc: builtin, registry: r}
+	_, ok = w.match(Message{Timestamp: now, Message: "mydrv: fatal error"})
+	assert.False(t, ok)
+}
@@ -2,6 +2,7 @@ package kmsg
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
@@ -15,15 +16,24 @@ type Syncer struct {
 	watcher     Watcher
 	matchFunc   MatchFunc
 	eventBucket eventstore.Bucket
+	// closes the buffer of the syncer, if the bucket is not already buffered
+	closeBuffer func()
+
+	// owner component of the user-supplied rules to match
+	component string
+	registry  *Registry
 }
 
 type MatchFunc func(line string) (eventName string, message string)
 
-func NewSyncer(ctx context.Context, matchFunc MatchFunc, eventBucket eventstore.Bucket) (*Syncer, error) {
-	return newSyncer(ctx, nil, matchFunc, eventBucket)
+func NewSyncer(ctx context.Context, matchFunc MatchFunc, eventBucket eventstore.Bucket, opts ...OpOption) (*Syncer, error) {
+	return newSyncer(ctx, nil, matchFunc, eventBucket, opts...)
 }
 
-func newSyncer(ctx context.Context, watcher Watcher, matchFunc MatchFunc, eventBucket eventstore.Bucket) (*Syncer, error) {
+func newSyncer(ctx context.Context, watcher Watcher, matchFunc MatchFunc, eventBucket eventstore.Bucket, opts ...OpOption) (*Syncer, error) {
+	op := &Op{}
+	op.applyOpts(opts)
+
 	if watcher == nil {
 		var err error
 		watcher, err = NewWatcher()
@@ -33,20 +43,34 @@ func newSyncer(ctx context.Context, watcher Watcher, matchFunc MatchFunc, eventB
 	}
 
 	w := &Syncer{
-		ctx:         ctx,
-		watcher:     watcher,
-		matchFunc:   matchFunc,
-		eventBucket: eventBucket,
+		ctx:       ctx,
+		watcher:   watcher,
+		matchFunc: matchFunc,
+		component: op.component,
+		registry:  op.registry,
 	}
 	ch, err := w.watcher.Watch()
 	if err != nil {
 		return nil, err
 	}
+
+	// buffers the inserts to write the message storms in batches,
+	// unless the component already buffers its bucket (see eventstore.WithBuffer)
+	w.eventBucket = eventBucket
+	if _, ok := eventBucket.(*eventstore.BufferedBucket); !ok {
+		buffered := eventstore.NewBufferedBucket(eventBucket, eventstore.DefaultBatchSize, eventstore.DefaultFlushInterval)
+		w.eventBucket = buffered
+		w.closeBuffer = buffered.Close
+	}
 	go w.sync(ch)
 	return w, nil
 }
 
 func (w *Syncer) sync(ch <-chan Message) {
+	if w.closeBuffer != nil {
+		defer w.closeBuffer()
+	}
+
 	for {
 		select {
 		case <-w.ctx.Done():
@@ -56,16 +80,10 @@ func (w *Syncer) sync(ch <-chan Message) {
 				return
 			}
 
-			name, message := w.matchFunc(kmsg.Message)
-			if name == "" {
+			event, ok := w.match(kmsg)
+			if !ok {
 				continue
 			}
-			event := eventstore.Event{
-				Time:    kmsg.Timestamp.UTC(),
-				Name:    name,
-				Message: message,
-				Type:    string(apiv1.EventTypeWarning),
-			}
 
 			// lookup to prevent duplicate event insertions
 			cctx, ccancel := context.WithTimeout(w.ctx, 15*time.Second)
@@ -85,12 +103,48 @@ func (w *Syncer) sync(ch <-chan Message) {
 			if err != nil {
 				log.Logger.Errorw("failed to insert event", "error", err)
 			} else {
-				log.Logger.Infow("successfully inserted event", "event", event.Name)
+				log.Logger.Infow("successfully buffered event", "event", event.Name)
 			}
 		}
 	}
 }
 
+// match returns the event for the kernel message matched by the built-in match function,
+// or by the user-supplied rules of the owner component.
+func (w *Syncer) match(kmsg Message) (eventstore.Event, bool) {
+	if name, message := w.matchFunc(kmsg.Message); name != "" {
+		return eventstore.Event{
+			Time:    kmsg.Timestamp.UTC(),
+			Name:    name,
+			Message: message,
+			Type:    string(apiv1.EventTypeWarning),
+		}, true
+	}
+
+	if w.component == "" {
+		return eventstore.Event{}, false
+	}
+	rule := w.registry.Match(w.component, kmsg.Message)
+	if rule == nil {
+		return eventstore.Event{}, false
+	}
+
+	event := eventstore.Event{
+		Time:    kmsg.Timestamp.UTC(),
+		Name:    rule.Name,
+		Message: rule.Message,
+		Type:    string(rule.EventType),
+	}
+	if event.Message == "" {
+		event.Message = kmsg.Message
+	}
+	if rule.SuggestedAction != "" {
+		event.Message = fmt.Sprintf("%s (suggested action: %s)", event.Message, rule.SuggestedAction)
+		event.ExtraInfo = map[string]string{EventKeySuggestedAction: string(rule.SuggestedAction)}
+	}
+	return event, true
+}
+
 func (w *Syncer) Close() {
 	_ = w.watcher.Close()
 }
@@ -0,0 +1,212 @@
+// Package labels manages the node labels (e.g., rack, cluster, tenant, hardware generation)
+// that are attached to every health state, event, and metric emitted by GPUd,
+// so that the downstream aggregation can slice the data by the labels.
+package labels
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+)
+
+// Labels holds the static labels (from the "--annotations" flag)
+// and the labels assigned by the control plane.
+// The assigned labels take precedence over the static labels
+// with the same key.
+// A nil *Labels is valid and attaches no label.
+type Labels struct {
+	mu       sync.RWMutex
+	static   map[string]string
+	assigned map[string]string
+}
+
+// New creates a new labels set with the static labels.
+func New(static map[string]string) *Labels {
+	return &Labels{static: copyMap(static)}
+}
+
+// SetAssigned replaces the labels assigned by the control plane.
+func (l *Labels) SetAssigned(assigned map[string]string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	l.assigned = copyMap(assigned)
+	l.mu.Unlock()
+}
+
+// Get returns the merged copy of the labels, or nil if no label is set.
+func (l *Labels) Get() map[string]string {
+	if l == nil {
+		return nil
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if len(l.static) == 0 && len(l.assigned) == 0 {
+		return nil
+	}
+	merged := make(map[string]string, len(l.static)+len(l.assigned))
+	for k, v := range l.static {
+		merged[k] = v
+	}
+	for k, v := range l.assigned {
+		merged[k] = v
+	}
+	return merged
+}
+
+// ApplyToHealthStates returns the copy of the health states with the labels attached.
+// The states are copied since they may be shared with the component cache.
+func (l *Labels) ApplyToHealthStates(states apiv1.HealthStates) apiv1.HealthStates {
+	merged := l.Get()
+	if len(merged) == 0 || len(states) == 0 {
+		return states
+	}
+	out := make(apiv1.HealthStates, len(states))
+	for i, st := range states {
+		st.Labels = mergeInto(st.Labels, merged)
+		out[i] = st
+	}
+	return out
+}
+
+// ApplyToEvents returns the copy of the events with the labels attached.
+func (l *Labels) ApplyToEvents(events apiv1.Events) apiv1.Events {
+	merged := l.Get()
+	if len(merged) == 0 || len(events) == 0 {
+		return events
+	}
+	out := make(apiv1.Events, len(events))
+	for i, ev := range events {
+		ev.Labels = mergeInto(ev.Labels, merged)
+		out[i] = ev
+	}
+	return out
+}
+
+// ApplyToMetrics returns the copy of the metrics with the labels attached.
+// The existing metric labels (e.g., "gpu_id") are not overwritten.
+func (l *Labels) ApplyToMetrics(metrics apiv1.Metrics) apiv1.Metrics {
+	merged := l.Get()
+	if len(merged) == 0 || len(metrics) == 0 {
+		return metrics
+	}
+	out := make(apiv1.Metrics, len(metrics))
+	for i, m := range metrics {
+		m.Labels = mergeInto(m.Labels, merged)
+		out[i] = m
+	}
+	return out
+}
+
+// mergeInto returns a new map with the labels, without overwriting
+// the existing keys in the destination map.
+func mergeInto(dst map[string]string, labels map[string]string) map[string]string {
+	out := make(map[string]string, len(dst)+len(labels))
+	for k, v := range labels {
+		out[k] = v
+	}
+	for k, v := range dst {
+		out[k] = v
+	}
+	return out
+}
+
+func copyMap(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// ErrInvalidLabel is returned when the label is not in the "key=value" format.
+var ErrInvalidLabel = errors.New("invalid label, expected key=value")
+
+// Parse parses the labels either in the JSON object format
+// (e.g., '{"rack":"r1","tenant":"a"}') or in the comma-separated
+// "key=value" format (e.g., "rack=r1,tenant=a").
+func Parse(s string) (map[string]string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(s, "{") {
+		var m map[string]string
+		if err := json.Unmarshal([]byte(s), &m); err != nil {
+			return nil, fmt.Errorf("failed to parse labels %q: %w", s, err)
+		}
+		return m, nil
+	}
+
+	m := make(map[string]string)
+	for _, kv := range strings.Split(s, ",") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(kv, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidLabel, kv)
+		}
+		m[k] = strings.TrimSpace(v)
+	}
+	return m, nil
+}
+
+// String returns the labels in the sorted "key=value" format.
+func String(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	kvs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		kvs = append(kvs, k+"="+m[k])
+	}
+	return strings.Join(kvs, ",")
+}
+
+// ReadAssigned reads the labels assigned by the control plane from the metadata table.
+// It returns nil if no label has been assigned.
+func ReadAssigned(ctx context.Context, dbRO *sql.DB) (map[string]string, error) {
+	raw, err := pkgmetadata.ReadMetadata(ctx, dbRO, pkgmetadata.MetadataKeyLabels)
+	if err != nil {
+		return nil, err
+	}
+	if raw == "" {
+		return nil, nil
+	}
+
+	var m map[string]string
+	if err := json.Unmarshal([]byte(raw), &m); err != nil {
+		return nil, fmt.Errorf("failed to parse assigned labels: %w", err)
+	}
+	return m, nil
+}
+
+// SaveAssigned persists the labels assigned by the control plane to the metadata table,
+// so that the labels survive the restarts.
+func SaveAssigned(ctx context.Context, dbRW *sql.DB, assigned map[string]string) error {
+	b, err := json.Marshal(assigned)
+	if err != nil {
+		return err
+	}
+	return pkgmetadata.SetMetadata(ctx, dbRW, pkgmetadata.MetadataKeyLabels, string(b))
+}
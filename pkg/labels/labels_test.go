@@ -0,0 +1,97 @@
+package labels
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "empty", input: "", want: nil},
+		{name: "key value", input: "rack=r1, tenant=a", want: map[string]string{"rack": "r1", "tenant": "a"}},
+		{name: "empty value", input: "rack=", want: map[string]string{"rack": ""}},
+		{name: "json", input: `{"rack":"r1"}`, want: map[string]string{"rack": "r1"}},
+		{name: "missing value", input: "rack", wantErr: true},
+		{name: "missing key", input: "=r1", wantErr: true},
+		{name: "invalid json", input: `{"rack":1}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLabelsGet(t *testing.T) {
+	var nilLabels *Labels
+	assert.Nil(t, nilLabels.Get())
+	nilLabels.SetAssigned(map[string]string{"a": "b"})
+
+	l := New(nil)
+	assert.Nil(t, l.Get())
+
+	l = New(map[string]string{"rack": "r1", "tenant": "a"})
+	l.SetAssigned(map[string]string{"tenant": "b", "cluster": "c1"})
+	assert.Equal(t, map[string]string{"rack": "r1", "tenant": "b", "cluster": "c1"}, l.Get())
+	assert.Equal(t, "cluster=c1,rack=r1,tenant=b", String(l.Get()))
+
+	l.SetAssigned(nil)
+	assert.Equal(t, map[string]string{"rack": "r1", "tenant": "a"}, l.Get())
+}
+
+func TestLabelsApply(t *testing.T) {
+	l := New(map[string]string{"rack": "r1", "gpu_id": "overwritten"})
+
+	states := apiv1.HealthStates{{Name: "a"}}
+	got := l.ApplyToHealthStates(states)
+	assert.Equal(t, map[string]string{"rack": "r1", "gpu_id": "overwritten"}, got[0].Labels)
+	assert.Nil(t, states[0].Labels, "the original states must not be modified")
+
+	events := l.ApplyToEvents(apiv1.Events{{Name: "a"}})
+	assert.Equal(t, "r1", events[0].Labels["rack"])
+
+	metrics := l.ApplyToMetrics(apiv1.Metrics{{Name: "a", Labels: map[string]string{"gpu_id": "GPU-0"}}})
+	assert.Equal(t, map[string]string{"rack": "r1", "gpu_id": "GPU-0"}, metrics[0].Labels)
+
+	var nilLabels *Labels
+	assert.Nil(t, nilLabels.ApplyToHealthStates(states)[0].Labels)
+	assert.Nil(t, nilLabels.ApplyToEvents(nil))
+}
+
+func TestReadSaveAssigned(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	require.NoError(t, pkgmetadata.CreateTableMetadata(ctx, dbRW))
+
+	assigned, err := ReadAssigned(ctx, dbRO)
+	require.NoError(t, err)
+	assert.Nil(t, assigned)
+
+	require.NoError(t, SaveAssigned(ctx, dbRW, map[string]string{"cluster": "c1"}))
+	assigned, err = ReadAssigned(ctx, dbRO)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"cluster": "c1"}, assigned)
+}
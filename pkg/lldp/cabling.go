@@ -0,0 +1,161 @@
+package lldp
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ExpectedLink is the expected switch port of a local interface, from the cabling map.
+type ExpectedLink struct {
+	// Host is the hostname of the node the link belongs to,
+	// so that a single cabling map can cover the whole cluster.
+	// Empty applies to every node.
+	Host string `json:"host,omitempty"`
+	// Interface is the local interface name (e.g., "eth0").
+	Interface string `json:"interface"`
+
+	// Switch is the expected switch, matched against the system name
+	// or the chassis ID of the neighbor. Empty matches any switch.
+	Switch string `json:"switch,omitempty"`
+	// Port is the expected switch port, matched against the port ID
+	// or the port description of the neighbor. Empty matches any port.
+	Port string `json:"port,omitempty"`
+}
+
+// CablingMap is the list of the expected links.
+type CablingMap []ExpectedLink
+
+var ErrInterfaceEmpty = errors.New("interface is empty")
+
+// Validate validates the cabling map.
+func (m CablingMap) Validate() error {
+	seen := make(map[string]struct{}, len(m))
+	for _, l := range m {
+		if l.Interface == "" {
+			return ErrInterfaceEmpty
+		}
+		key := l.Host + "/" + l.Interface
+		if _, ok := seen[key]; ok {
+			return fmt.Errorf("duplicate expected link for interface %q (host %q)", l.Interface, l.Host)
+		}
+		seen[key] = struct{}{}
+	}
+	return nil
+}
+
+// ForHost returns the expected links of the host.
+// The host-specific link takes precedence over the one without the host.
+func (m CablingMap) ForHost(host string) CablingMap {
+	byIface := make(map[string]ExpectedLink)
+	order := []string{}
+	for _, l := range m {
+		if l.Host != "" && !strings.EqualFold(l.Host, host) {
+			continue
+		}
+		prev, ok := byIface[l.Interface]
+		if !ok {
+			order = append(order, l.Interface)
+		}
+		if !ok || (prev.Host == "" && l.Host != "") {
+			byIface[l.Interface] = l
+		}
+	}
+
+	links := make(CablingMap, 0, len(order))
+	for _, iface := range order {
+		links = append(links, byIface[iface])
+	}
+	return links
+}
+
+// LoadCablingMap loads and validates the cabling map from the YAML (or JSON) file.
+func LoadCablingMap(file string) (CablingMap, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCablingMap(b)
+}
+
+// ParseCablingMap parses and validates the cabling map from the YAML (or JSON) content.
+func ParseCablingMap(b []byte) (CablingMap, error) {
+	var m CablingMap
+	if err := yaml.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Mismatch is the local interface not connected as the cabling map expects.
+type Mismatch struct {
+	Interface string `json:"interface"`
+
+	Expected ExpectedLink `json:"expected"`
+	// Actual is nil if no LLDP neighbor is found on the interface
+	// (e.g., unplugged cable, LLDP disabled on the switch port).
+	Actual *Neighbor `json:"actual,omitempty"`
+
+	Reason string `json:"reason"`
+}
+
+// FindMismatches returns the expected links not matching the discovered neighbors.
+func FindMismatches(expected CablingMap, neighbors []Neighbor) []Mismatch {
+	byIface := make(map[string]Neighbor, len(neighbors))
+	for _, n := range neighbors {
+		byIface[n.Interface] = n
+	}
+
+	var mismatches []Mismatch
+	for _, l := range expected {
+		n, ok := byIface[l.Interface]
+		if !ok {
+			mismatches = append(mismatches, Mismatch{
+				Interface: l.Interface,
+				Expected:  l,
+				Reason:    fmt.Sprintf("no lldp neighbor found on %s (expected %s)", l.Interface, l.describe()),
+			})
+			continue
+		}
+
+		switchMatched := l.Switch == "" || strings.EqualFold(l.Switch, n.SystemName) || strings.EqualFold(l.Switch, n.ChassisID)
+		portMatched := l.Port == "" || strings.EqualFold(l.Port, n.PortID) || strings.EqualFold(l.Port, n.PortDescription)
+		if switchMatched && portMatched {
+			continue
+		}
+
+		actual := n
+		mismatches = append(mismatches, Mismatch{
+			Interface: l.Interface,
+			Expected:  l,
+			Actual:    &actual,
+			Reason:    fmt.Sprintf("%s connected to %s (expected %s)", l.Interface, n.describe(), l.describe()),
+		})
+	}
+	return mismatches
+}
+
+func (l ExpectedLink) describe() string {
+	sw, port := l.Switch, l.Port
+	if sw == "" {
+		sw = "any switch"
+	}
+	if port == "" {
+		port = "any port"
+	}
+	return sw + " " + port
+}
+
+func (n Neighbor) describe() string {
+	sw := n.SystemName
+	if sw == "" {
+		sw = n.ChassisID
+	}
+	return sw + " " + n.PortID
+}
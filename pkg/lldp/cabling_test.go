@@ -0,0 +1,91 @@
+package lldp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCablingMapValidate(t *testing.T) {
+	assert.NoError(t, CablingMap{{Interface: "eth0"}, {Host: "node-a", Interface: "eth0"}}.Validate())
+	assert.ErrorIs(t, CablingMap{{Switch: "leaf-01"}}.Validate(), ErrInterfaceEmpty)
+	assert.ErrorContains(t, CablingMap{{Host: "node-a", Interface: "eth0"}, {Host: "node-a", Interface: "eth0"}}.Validate(), "duplicate")
+}
+
+func TestCablingMapForHost(t *testing.T) {
+	m := CablingMap{
+		{Interface: "eth0", Switch: "leaf-01"},
+		{Host: "node-a", Interface: "eth0", Switch: "leaf-02"},
+		{Host: "node-b", Interface: "eth1", Switch: "leaf-03"},
+		{Interface: "eth2", Switch: "leaf-04"},
+	}
+	assert.Equal(t, CablingMap{
+		{Host: "node-a", Interface: "eth0", Switch: "leaf-02"},
+		{Interface: "eth2", Switch: "leaf-04"},
+	}, m.ForHost("NODE-A"))
+	assert.Equal(t, CablingMap{
+		{Interface: "eth0", Switch: "leaf-01"},
+		{Host: "node-b", Interface: "eth1", Switch: "leaf-03"},
+		{Interface: "eth2", Switch: "leaf-04"},
+	}, m.ForHost("node-b"))
+}
+
+func TestLoadCablingMap(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "cabling.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+- host: node-a
+  interface: eth0
+  switch: leaf-01
+  port: swp11
+- interface: eth1
+  switch: leaf-02
+`), 0644))
+
+	m, err := LoadCablingMap(file)
+	require.NoError(t, err)
+	assert.Equal(t, CablingMap{
+		{Host: "node-a", Interface: "eth0", Switch: "leaf-01", Port: "swp11"},
+		{Interface: "eth1", Switch: "leaf-02"},
+	}, m)
+
+	require.NoError(t, os.WriteFile(file, []byte(`- switch: leaf-01`), 0644))
+	_, err = LoadCablingMap(file)
+	assert.ErrorIs(t, err, ErrInterfaceEmpty)
+
+	_, err = LoadCablingMap(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestFindMismatches(t *testing.T) {
+	neighbors := []Neighbor{
+		{Interface: "eth0", ChassisID: "0c:42:a1:00:00:01", SystemName: "leaf-01", PortID: "swp11"},
+		{Interface: "eth1", ChassisID: "0c:42:a1:00:00:02", SystemName: "leaf-02", PortID: "swp12"},
+	}
+
+	// matched by the system name, the chassis ID, or any
+	assert.Empty(t, FindMismatches(CablingMap{
+		{Interface: "eth0", Switch: "LEAF-01", Port: "swp11"},
+		{Interface: "eth1", Switch: "0c:42:a1:00:00:02"},
+	}, neighbors))
+
+	mismatches := FindMismatches(CablingMap{
+		{Interface: "eth0", Switch: "leaf-01", Port: "swp12"},
+		{Interface: "eth1", Switch: "leaf-01"},
+		{Interface: "eth2", Switch: "leaf-03", Port: "swp1"},
+	}, neighbors)
+	require.Len(t, mismatches, 3)
+
+	assert.Equal(t, "eth0", mismatches[0].Interface)
+	assert.Equal(t, "eth0 connected to leaf-01 swp11 (expected leaf-01 swp12)", mismatches[0].Reason)
+	require.NotNil(t, mismatches[0].Actual)
+	assert.Equal(t, "swp11", mismatches[0].Actual.PortID)
+
+	assert.Equal(t, "eth1 connected to leaf-02 swp12 (expected leaf-01 any port)", mismatches[1].Reason)
+
+	assert.Nil(t, mismatches[2].Actual)
+	assert.Equal(t, "no lldp neighbor found on eth2 (expected leaf-03 swp1)", mismatches[2].Reason)
+}
@@ -0,0 +1,134 @@
+// Package lldp discovers the Ethernet switch ports the local NICs are connected to,
+// from the LLDP neighbors reported by the lldpd daemon, and validates them
+// against the expected cabling map.
+package lldp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/leptonai/gpud/pkg/toolexec"
+	"github.com/leptonai/gpud/pkg/toolpath"
+)
+
+// DefaultLLDPCtl is the lldpd client to list the neighbors (via "lldpctl -f json0"),
+// in the "json0" format whose structure does not change with the number of the entries.
+const DefaultLLDPCtl = "lldpctl"
+
+var (
+	ErrNoLLDPCtlCommand = errors.New("lldpctl not found, cannot discover lldp neighbors")
+	ErrLLDPDNotRunning  = errors.New("lldpd not running, cannot discover lldp neighbors")
+)
+
+// Neighbor is the LLDP neighbor (e.g., the switch port) of a local interface.
+type Neighbor struct {
+	// Interface is the local interface name (e.g., "eth0").
+	Interface string `json:"interface"`
+
+	// ChassisID is the chassis ID of the neighbor (e.g., the switch MAC address).
+	ChassisID string `json:"chassis_id,omitempty"`
+	// SystemName is the system name of the neighbor (e.g., the switch hostname).
+	SystemName string `json:"system_name,omitempty"`
+	// MgmtIP is the management IP of the neighbor.
+	MgmtIP string `json:"mgmt_ip,omitempty"`
+
+	// PortID is the port ID of the neighbor (e.g., "Ethernet1/1").
+	PortID string `json:"port_id,omitempty"`
+	// PortDescription is the port description of the neighbor.
+	PortDescription string `json:"port_description,omitempty"`
+}
+
+// GetNeighbors returns the LLDP neighbors of the local interfaces, sorted by the interface.
+// The lldpctl is the binary name or path, resolved with the tool path overrides.
+func GetNeighbors(ctx context.Context, lldpctl string) ([]Neighbor, error) {
+	if strings.TrimSpace(lldpctl) == "" {
+		lldpctl = DefaultLLDPCtl
+	}
+	binPath, err := toolpath.Locate(lldpctl)
+	if err != nil {
+		return nil, ErrNoLLDPCtlCommand
+	}
+
+	res, err := toolexec.Run(ctx, []string{binPath, "-f", "json0"})
+	if err != nil {
+		var out string
+		if res != nil {
+			out = strings.TrimSpace(string(res.Output))
+		}
+		if isLLDPDNotRunning(out) {
+			return nil, fmt.Errorf("%w (output %q)", ErrLLDPDNotRunning, out)
+		}
+		return nil, fmt.Errorf("failed to run %q: %w (output %q)", binPath+" -f json0", err, out)
+	}
+	return ParseLLDPCtlJSON0(res.Output)
+}
+
+// isLLDPDNotRunning returns true if the lldpctl output shows that it cannot
+// connect to the lldpd daemon (e.g., "unable to connect to socket /run/lldpd.socket").
+func isLLDPDNotRunning(out string) bool {
+	return strings.Contains(out, "unable to connect to socket") || strings.Contains(out, "unable to connect to lldpd")
+}
+
+// "lldpctl -f json0" wraps every value in a list of objects.
+type lldpctlValue struct {
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value"`
+}
+
+type lldpctlOutput struct {
+	LLDP []struct {
+		Interface []struct {
+			Name    string `json:"name"`
+			Chassis []struct {
+				ID     []lldpctlValue `json:"id"`
+				Name   []lldpctlValue `json:"name"`
+				MgmtIP []lldpctlValue `json:"mgmt-ip"`
+			} `json:"chassis"`
+			Port []struct {
+				ID    []lldpctlValue `json:"id"`
+				Descr []lldpctlValue `json:"descr"`
+			} `json:"port"`
+		} `json:"interface"`
+	} `json:"lldp"`
+}
+
+func firstValue(vs []lldpctlValue) string {
+	if len(vs) == 0 {
+		return ""
+	}
+	return vs[0].Value
+}
+
+// ParseLLDPCtlJSON0 parses the "lldpctl -f json0" output.
+func ParseLLDPCtlJSON0(b []byte) ([]Neighbor, error) {
+	var out lldpctlOutput
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, fmt.Errorf("failed to parse lldpctl output: %w", err)
+	}
+
+	var neighbors []Neighbor
+	for _, l := range out.LLDP {
+		for _, iface := range l.Interface {
+			n := Neighbor{Interface: iface.Name}
+			if len(iface.Chassis) > 0 {
+				n.ChassisID = firstValue(iface.Chassis[0].ID)
+				n.SystemName = firstValue(iface.Chassis[0].Name)
+				n.MgmtIP = firstValue(iface.Chassis[0].MgmtIP)
+			}
+			if len(iface.Port) > 0 {
+				n.PortID = firstValue(iface.Port[0].ID)
+				n.PortDescription = firstValue(iface.Port[0].Descr)
+			}
+			neighbors = append(neighbors, n)
+		}
+	}
+
+	sort.Slice(neighbors, func(i, j int) bool {
+		return neighbors[i].Interface < neighbors[j].Interface
+	})
+	return neighbors, nil
+}
@@ -0,0 +1,73 @@
+package lldp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLLDPCtlJSON0(t *testing.T) {
+	b, err := os.ReadFile("testdata/lldpctl-json0.json")
+	require.NoError(t, err)
+
+	neighbors, err := ParseLLDPCtlJSON0(b)
+	require.NoError(t, err)
+	assert.Equal(t, []Neighbor{
+		{Interface: "eth0", ChassisID: "0c:42:a1:00:00:01", SystemName: "leaf-01", MgmtIP: "10.10.0.1", PortID: "swp11", PortDescription: "node-a eth0"},
+		{Interface: "eth1", ChassisID: "0c:42:a1:00:00:02", SystemName: "leaf-02", MgmtIP: "10.10.0.2", PortID: "swp12", PortDescription: "node-a eth1"},
+	}, neighbors)
+}
+
+func TestParseLLDPCtlJSON0Empty(t *testing.T) {
+	// no neighbor discovered yet
+	neighbors, err := ParseLLDPCtlJSON0([]byte(`{"lldp": [{}]}`))
+	require.NoError(t, err)
+	assert.Empty(t, neighbors)
+
+	// neighbor without the system name
+	neighbors, err = ParseLLDPCtlJSON0([]byte(`{"lldp": [{"interface": [{"name": "eth0", "chassis": [{"id": [{"type": "mac", "value": "aa:bb"}]}]}]}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, []Neighbor{{Interface: "eth0", ChassisID: "aa:bb"}}, neighbors)
+
+	_, err = ParseLLDPCtlJSON0([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestGetNeighborsNoCommand(t *testing.T) {
+	_, err := GetNeighbors(context.Background(), "lldpctl-does-not-exist")
+	assert.ErrorIs(t, err, ErrNoLLDPCtlCommand)
+}
+
+func TestGetNeighborsCustomLLDPCtl(t *testing.T) {
+	testdata, err := filepath.Abs("testdata/lldpctl-json0.json")
+	require.NoError(t, err)
+
+	// the arguments are passed as is, not interpreted by the shell
+	lldpctl := filepath.Join(t.TempDir(), "lldpctl")
+	script := `#!/bin/sh
+[ "$#" -eq 2 ] && [ "$1" = "-f" ] && [ "$2" = "json0" ] || exit 1
+cat ` + testdata + `
+`
+	require.NoError(t, os.WriteFile(lldpctl, []byte(script), 0755))
+
+	neighbors, err := GetNeighbors(context.Background(), lldpctl)
+	require.NoError(t, err)
+	require.Len(t, neighbors, 2)
+	assert.Equal(t, "leaf-01", neighbors[0].SystemName)
+}
+
+func TestGetNeighborsLLDPDNotRunning(t *testing.T) {
+	lldpctl := filepath.Join(t.TempDir(), "lldpctl")
+	script := `#!/bin/sh
+echo "lldpctl: unable to connect to socket /run/lldpd.socket" >&2
+exit 1
+`
+	require.NoError(t, os.WriteFile(lldpctl, []byte(script), 0755))
+
+	_, err := GetNeighbors(context.Background(), lldpctl)
+	assert.ErrorIs(t, err, ErrLLDPDNotRunning)
+}
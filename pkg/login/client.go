@@ -10,6 +10,7 @@ import (
 	"net/http"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/endpoints"
 	"github.com/leptonai/gpud/pkg/httputil"
 	"github.com/leptonai/gpud/pkg/log"
 )
@@ -26,6 +27,28 @@ func SendRequest(ctx context.Context, endpoint string, req apiv1.LoginRequest) (
 	return sendRequest(ctx, url, req)
 }
 
+// SendRequestWithFailover sends a login request to the endpoints in order,
+// failing over to the next endpoint only if the endpoint is unreachable.
+// Once an endpoint responds, its response is returned (e.g., invalid token)
+// without trying the other endpoints.
+func SendRequestWithFailover(ctx context.Context, eps []string, req apiv1.LoginRequest) (*apiv1.LoginResponse, error) {
+	var (
+		resp    *apiv1.LoginResponse
+		respErr error
+	)
+	_, err := endpoints.NewFailover(eps, 0).Do(ctx, func(ctx context.Context, ep string) error {
+		resp, respErr = SendRequest(ctx, ep, req)
+		if respErr != nil && resp == nil {
+			return respErr
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, respErr
+}
+
 func sendRequest(ctx context.Context, url string, req apiv1.LoginRequest) (*apiv1.LoginResponse, error) {
 	log.Logger.Debugw("sending login request", "url", url)
 
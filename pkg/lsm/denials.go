@@ -0,0 +1,294 @@
+package lsm
+
+import (
+	"bufio"
+	"io"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Feature is the gpud feature affected by the denial.
+type Feature string
+
+const (
+	FeatureModuleLoading Feature = "module-loading"
+	FeatureKmsg          Feature = "kmsg"
+	FeatureNVML          Feature = "nvml"
+	FeatureOther         Feature = "other"
+)
+
+// Denial is an access denied by the security module.
+type Denial struct {
+	Module  Module  `json:"module"`
+	Feature Feature `json:"feature"`
+
+	// Source is the SELinux source type (e.g., "unconfined_service_t").
+	Source string `json:"source,omitempty"`
+	// Target is the SELinux target type (e.g., "kmsg_device_t").
+	Target string `json:"target,omitempty"`
+	// Class is the SELinux target class (e.g., "chr_file").
+	Class string `json:"class,omitempty"`
+	// Permissions are the denied SELinux permissions (e.g., "read", "module_load").
+	Permissions []string `json:"permissions,omitempty"`
+
+	// Profile is the AppArmor profile.
+	Profile string `json:"profile,omitempty"`
+	// Capability is the denied AppArmor capability (e.g., "sys_module").
+	Capability string `json:"capability,omitempty"`
+	// Mask is the denied AppArmor file access mask (e.g., "r").
+	Mask string `json:"mask,omitempty"`
+
+	// Path is the path of the denied object, if any.
+	Path string `json:"path,omitempty"`
+}
+
+// String returns the human-readable description of the denial.
+func (d Denial) String() string {
+	switch d.Module {
+	case ModuleSELinux:
+		s := "selinux denied {" + strings.Join(d.Permissions, " ") + "} on " + d.Target + ":" + d.Class + " for " + d.Source
+		if d.Path != "" {
+			s += " (" + d.Path + ")"
+		}
+		return s
+	case ModuleAppArmor:
+		if d.Capability != "" {
+			return "apparmor denied capability " + d.Capability + " for profile " + d.Profile
+		}
+		return "apparmor denied " + d.Mask + " on " + d.Path + " for profile " + d.Profile
+	}
+	return ""
+}
+
+func (d Denial) key() string {
+	return strings.Join([]string{
+		string(d.Module), d.Source, d.Target, d.Class, strings.Join(d.Permissions, " "),
+		d.Profile, d.Capability, d.Mask, d.Path,
+	}, "|")
+}
+
+var (
+	fieldRegex       = regexp.MustCompile(`(\w+)=("[^"]*"|\S+)`)
+	permissionsRegex = regexp.MustCompile(`denied\s+\{\s*([^}]*)\}`)
+	// e.g., "audit(1700000000.123:456)"
+	auditTimeRegex = regexp.MustCompile(`audit\((\d+)\.(\d+):\d+\)`)
+)
+
+// Filter selects the denials of the gpud process.
+type Filter struct {
+	// Comm is the command name of the gpud process.
+	Comm string
+	// SELinuxDomains are the SELinux domains gpud runs in (e.g., "unconfined_service_t"),
+	// since the command name alone can be spoofed by any process.
+	SELinuxDomains []string
+	// Since is the time the denials older than are ignored,
+	// not to allow the accesses denied long ago by the unrelated runs.
+	Since time.Time
+}
+
+// FindDenials parses the SELinux AVC and AppArmor denial records
+// of the gpud process selected by the filter, in the audit or kernel log.
+// The SELinux denials must be in the gpud domains, and the AppArmor denials
+// must be in the profile of the gpud executable (e.g., "/usr/local/bin/gpud").
+// The records without the audit timestamp are ignored.
+func FindDenials(r io.Reader, f Filter) []Denial {
+	quotedComm := `comm="` + f.Comm + `"`
+
+	var denials []Denial
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.Contains(line, quotedComm) {
+			continue
+		}
+		at, ok := parseAuditTime(line)
+		if !ok || at.Before(f.Since) {
+			continue
+		}
+
+		var d Denial
+		switch {
+		case strings.Contains(line, "avc:") && strings.Contains(line, "denied"):
+			d, ok = parseSELinuxDenial(line)
+			ok = ok && slices.Contains(f.SELinuxDomains, d.Source)
+		case strings.Contains(line, `apparmor="DENIED"`):
+			d, ok = parseAppArmorDenial(line)
+			ok = ok && path.Base(d.Profile) == f.Comm
+		default:
+			ok = false
+		}
+		if ok {
+			d.Feature = classify(d)
+			denials = append(denials, d)
+		}
+	}
+	return denials
+}
+
+// parseAuditTime parses the time of the audit record.
+func parseAuditTime(line string) (time.Time, bool) {
+	m := auditTimeRegex.FindStringSubmatch(line)
+	if m == nil {
+		return time.Time{}, false
+	}
+	sec, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(sec, 0), true
+}
+
+func parseFields(line string) map[string]string {
+	fields := make(map[string]string)
+	for _, m := range fieldRegex.FindAllStringSubmatch(line, -1) {
+		fields[m[1]] = strings.Trim(m[2], `"`)
+	}
+	return fields
+}
+
+// contextType returns the type of the SELinux context
+// (e.g., "kmsg_device_t" in "system_u:object_r:kmsg_device_t:s0").
+func contextType(ctx string) string {
+	parts := strings.Split(ctx, ":")
+	if len(parts) < 3 {
+		return ""
+	}
+	return parts[2]
+}
+
+// e.g.,
+// type=AVC msg=audit(1700000000.123:456): avc:  denied  { read } for  pid=1234 comm="gpud" name="kmsg" dev="devtmpfs" ino=10 scontext=system_u:system_r:unconfined_service_t:s0 tcontext=system_u:object_r:kmsg_device_t:s0 tclass=chr_file permissive=0
+func parseSELinuxDenial(line string) (Denial, bool) {
+	m := permissionsRegex.FindStringSubmatch(line)
+	if m == nil {
+		return Denial{}, false
+	}
+	perms := strings.Fields(m[1])
+	sort.Strings(perms)
+
+	fields := parseFields(line)
+	d := Denial{
+		Module:      ModuleSELinux,
+		Source:      contextType(fields["scontext"]),
+		Target:      contextType(fields["tcontext"]),
+		Class:       fields["tclass"],
+		Permissions: perms,
+		Path:        fields["path"],
+	}
+	if d.Path == "" {
+		d.Path = fields["name"]
+	}
+	if d.Source == "" || d.Target == "" || d.Class == "" || len(perms) == 0 {
+		return Denial{}, false
+	}
+	return d, true
+}
+
+// e.g.,
+// audit: type=1400 audit(1700000000.123:789): apparmor="DENIED" operation="open" profile="gpud" name="/dev/kmsg" pid=1234 comm="gpud" requested_mask="r" denied_mask="r" fsuid=0 ouid=0
+// audit: type=1400 audit(1700000000.123:790): apparmor="DENIED" operation="capable" profile="gpud" pid=1234 comm="gpud" capability=16 capname="sys_module"
+func parseAppArmorDenial(line string) (Denial, bool) {
+	fields := parseFields(line)
+	d := Denial{
+		Module:     ModuleAppArmor,
+		Profile:    fields["profile"],
+		Capability: fields["capname"],
+		Mask:       fields["denied_mask"],
+		Path:       fields["name"],
+	}
+	if d.Profile == "" {
+		return Denial{}, false
+	}
+	if d.Capability == "" && (d.Path == "" || d.Mask == "") {
+		return Denial{}, false
+	}
+	return d, true
+}
+
+// selinuxAccess is the whitelisted SELinux access needed by the gpud feature.
+type selinuxAccess struct {
+	feature Feature
+	class   string
+	// target is the required target type, empty for any
+	target      string
+	permissions []string
+}
+
+// selinuxAccesses are the only SELinux accesses the policy is generated for.
+var selinuxAccesses = []selinuxAccess{
+	{feature: FeatureModuleLoading, class: "system", permissions: []string{"module_load", "module_request"}},
+	{feature: FeatureModuleLoading, class: "capability", permissions: []string{"sys_module"}},
+	{feature: FeatureKmsg, class: "system", permissions: []string{"syslog_read"}},
+	{feature: FeatureKmsg, class: "capability2", permissions: []string{"syslog"}},
+	{feature: FeatureKmsg, class: "chr_file", target: "kmsg_device_t", permissions: []string{"getattr", "ioctl", "open", "read"}},
+	{feature: FeatureNVML, class: "chr_file", target: "xserver_misc_device_t", permissions: []string{"getattr", "ioctl", "map", "open", "read", "write"}},
+}
+
+// apparmorAccess is the whitelisted AppArmor file access needed by the gpud feature.
+type apparmorAccess struct {
+	feature Feature
+	path    *regexp.Regexp
+	// mask is the allowed file access mask
+	mask string
+}
+
+// apparmorAccesses are the only AppArmor file accesses the policy is generated for.
+var apparmorAccesses = []apparmorAccess{
+	{feature: FeatureModuleLoading, path: regexp.MustCompile(`^(/usr)?/lib/modules/[^/]+/.+\.ko(\.xz|\.zst|\.gz)?$`), mask: "r"},
+	{feature: FeatureKmsg, path: regexp.MustCompile(`^/dev/kmsg$`), mask: "r"},
+	{feature: FeatureNVML, path: regexp.MustCompile(`^/dev/nvidia(ctl|-uvm|-uvm-tools|-modeset|[0-9]+)$`), mask: "rw"},
+	{feature: FeatureNVML, path: regexp.MustCompile(`^(/usr)?/lib(64)?/([^/]+-linux-gnu/)?libnvidia-ml\.so(\.[0-9.]+)?$`), mask: "mr"},
+}
+
+// apparmorCapabilities are the only AppArmor capabilities the policy is generated for.
+var apparmorCapabilities = map[string]Feature{
+	"sys_module": FeatureModuleLoading,
+	"syslog":     FeatureKmsg,
+}
+
+// classify returns the gpud feature of the denial only if all the denied accesses
+// are whitelisted for the feature, otherwise FeatureOther, not to widen the policy
+// for the accesses gpud does not need.
+func classify(d Denial) Feature {
+	switch d.Module {
+	case ModuleSELinux:
+		for _, a := range selinuxAccesses {
+			if a.class != d.Class || (a.target != "" && a.target != d.Target) {
+				continue
+			}
+			if len(d.Permissions) > 0 && allIn(d.Permissions, a.permissions) {
+				return a.feature
+			}
+		}
+
+	case ModuleAppArmor:
+		if d.Capability != "" {
+			if f, ok := apparmorCapabilities[d.Capability]; ok {
+				return f
+			}
+			return FeatureOther
+		}
+		for _, a := range apparmorAccesses {
+			if a.path.MatchString(d.Path) && d.Mask != "" && allIn(strings.Split(d.Mask, ""), strings.Split(a.mask, "")) {
+				return a.feature
+			}
+		}
+	}
+	return FeatureOther
+}
+
+// allIn returns true if all the elements are in the allowed ones.
+func allIn(elems []string, allowed []string) bool {
+	for _, e := range elems {
+		if !slices.Contains(allowed, e) {
+			return false
+		}
+	}
+	return true
+}
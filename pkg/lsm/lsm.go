@@ -0,0 +1,211 @@
+// Package lsm detects the Linux Security Module (SELinux and AppArmor) denials
+// affecting gpud (e.g., kernel module loading, /dev/kmsg, NVML), and generates
+// the policy snippets to allow them.
+package lsm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Module is the Linux Security Module that enforces the access control.
+type Module string
+
+const (
+	ModuleSELinux  Module = "selinux"
+	ModuleAppArmor Module = "apparmor"
+)
+
+const (
+	// DefaultSELinuxEnforceFile is "1" if SELinux is enforcing.
+	DefaultSELinuxEnforceFile = "/sys/fs/selinux/enforce"
+	// DefaultAppArmorEnabledFile is "Y" if AppArmor is enabled.
+	DefaultAppArmorEnabledFile = "/sys/module/apparmor/parameters/enabled"
+
+	// DefaultComm is the command name of the gpud process in the audit records.
+	DefaultComm = "gpud"
+
+	// DefaultMaxDenialAge is the age of the denials older than are ignored.
+	DefaultMaxDenialAge = 7 * 24 * time.Hour
+
+	// maxLogBytes is the maximum number of the bytes read from the end of each log file,
+	// not to scan the whole rotated-but-large audit logs.
+	maxLogBytes = 8 * 1024 * 1024
+)
+
+// DefaultSELinuxDomains are the SELinux domains the gpud service runs in:
+// the domain of the systemd services without the dedicated policy,
+// and the dedicated gpud domain if installed.
+var DefaultSELinuxDomains = []string{"unconfined_service_t", "gpud_t"}
+
+// DefaultLogFiles are the log files the denials are recorded in.
+// SELinux and AppArmor write to the audit log if auditd runs,
+// otherwise to the kernel log.
+var DefaultLogFiles = []string{
+	"/var/log/audit/audit.log",
+	"/var/log/kern.log",
+	"/var/log/messages",
+	"/var/log/syslog",
+}
+
+type Op struct {
+	selinuxEnforceFile  string
+	apparmorEnabledFile string
+	logFiles            []string
+	comm                string
+	selinuxDomains      []string
+	since               time.Time
+}
+
+type OpOption func(*Op)
+
+func (op *Op) applyOpts(opts []OpOption) {
+	for _, opt := range opts {
+		opt(op)
+	}
+
+	if op.selinuxEnforceFile == "" {
+		op.selinuxEnforceFile = DefaultSELinuxEnforceFile
+	}
+	if op.apparmorEnabledFile == "" {
+		op.apparmorEnabledFile = DefaultAppArmorEnabledFile
+	}
+	if len(op.logFiles) == 0 {
+		op.logFiles = DefaultLogFiles
+	}
+	if op.comm == "" {
+		op.comm = DefaultComm
+	}
+	if len(op.selinuxDomains) == 0 {
+		op.selinuxDomains = DefaultSELinuxDomains
+	}
+	if op.since.IsZero() {
+		op.since = time.Now().Add(-DefaultMaxDenialAge)
+	}
+}
+
+// WithSELinuxEnforceFile sets the SELinux enforce status file.
+func WithSELinuxEnforceFile(file string) OpOption {
+	return func(op *Op) {
+		op.selinuxEnforceFile = file
+	}
+}
+
+// WithAppArmorEnabledFile sets the AppArmor enabled status file.
+func WithAppArmorEnabledFile(file string) OpOption {
+	return func(op *Op) {
+		op.apparmorEnabledFile = file
+	}
+}
+
+// WithLogFiles sets the log files to scan the denials in.
+func WithLogFiles(files ...string) OpOption {
+	return func(op *Op) {
+		op.logFiles = files
+	}
+}
+
+// WithComm sets the command name of the process to find the denials of.
+func WithComm(comm string) OpOption {
+	return func(op *Op) {
+		op.comm = comm
+	}
+}
+
+// WithSELinuxDomains sets the SELinux domains of the process to find the denials of.
+func WithSELinuxDomains(domains ...string) OpOption {
+	return func(op *Op) {
+		op.selinuxDomains = domains
+	}
+}
+
+// WithSince sets the time the denials older than are ignored.
+func WithSince(since time.Time) OpOption {
+	return func(op *Op) {
+		op.since = since
+	}
+}
+
+// Report is the result of the scan.
+type Report struct {
+	// SELinuxEnforcing is true if SELinux is enabled and enforcing.
+	SELinuxEnforcing bool
+	// AppArmorEnabled is true if AppArmor is enabled.
+	AppArmorEnabled bool
+	// Denials are the deduplicated denials affecting gpud.
+	Denials []Denial
+}
+
+// Enforced returns true if any of the security modules is enforced.
+func (r Report) Enforced() bool {
+	return r.SELinuxEnforcing || r.AppArmorEnabled
+}
+
+// Scan detects the enforced security modules, and finds the denials
+// affecting gpud in the log files. The missing log files are skipped.
+func Scan(opts ...OpOption) (Report, error) {
+	op := &Op{}
+	op.applyOpts(opts)
+
+	rs := Report{
+		SELinuxEnforcing: readFlag(op.selinuxEnforceFile) == "1",
+		AppArmorEnabled:  readFlag(op.apparmorEnabledFile) == "Y",
+	}
+	if !rs.Enforced() {
+		return rs, nil
+	}
+
+	seen := make(map[string]struct{})
+	for _, file := range op.logFiles {
+		b, err := readTail(file, maxLogBytes)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return rs, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		for _, d := range FindDenials(bytes.NewReader(b), Filter{Comm: op.comm, SELinuxDomains: op.selinuxDomains, Since: op.since}) {
+			k := d.key()
+			if _, ok := seen[k]; ok {
+				continue
+			}
+			seen[k] = struct{}{}
+			rs.Denials = append(rs.Denials, d)
+		}
+	}
+	return rs, nil
+}
+
+func readFlag(file string) string {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// readTail reads up to the last n bytes of the file.
+func readTail(file string, n int64) ([]byte, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if st.Size() > n {
+		if _, err := f.Seek(-n, io.SeekEnd); err != nil {
+			return nil, err
+		}
+	}
+	return io.ReadAll(f)
+}
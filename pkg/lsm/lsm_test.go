@@ -0,0 +1,149 @@
+package lsm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSELinuxKmsg   = `type=AVC msg=audit(1700000000.123:456): avc:  denied  { read } for  pid=1234 comm="gpud" name="kmsg" dev="devtmpfs" ino=10 scontext=system_u:system_r:unconfined_service_t:s0 tcontext=system_u:object_r:kmsg_device_t:s0 tclass=chr_file permissive=0`
+	testSELinuxModule = `type=AVC msg=audit(1700000000.124:457): avc:  denied  { module_load } for  pid=1234 comm="gpud" path="/usr/lib/modules/5.15.0/nvidia_peermem.ko" dev="sda1" ino=11 scontext=system_u:system_r:unconfined_service_t:s0 tcontext=system_u:object_r:modules_object_t:s0 tclass=system permissive=0`
+	testSELinuxNVML   = `type=AVC msg=audit(1700000000.125:458): avc:  denied  { open write read } for  pid=1234 comm="gpud" path="/dev/nvidiactl" dev="devtmpfs" ino=12 scontext=system_u:system_r:unconfined_service_t:s0 tcontext=system_u:object_r:xserver_misc_device_t:s0 tclass=chr_file permissive=0`
+	testSELinuxOther  = `type=AVC msg=audit(1700000000.126:459): avc:  denied  { read } for  pid=4321 comm="sshd" name="kmsg" dev="devtmpfs" ino=10 scontext=system_u:system_r:sshd_t:s0 tcontext=system_u:object_r:kmsg_device_t:s0 tclass=chr_file permissive=0`
+
+	testAppArmorKmsg = `Nov 14 22:13:20 host kernel: audit: type=1400 audit(1700000000.123:789): apparmor="DENIED" operation="open" profile="/usr/local/bin/gpud" name="/dev/kmsg" pid=1234 comm="gpud" requested_mask="r" denied_mask="r" fsuid=0 ouid=0`
+	testAppArmorCap  = `Nov 14 22:13:21 host kernel: audit: type=1400 audit(1700000000.124:790): apparmor="DENIED" operation="capable" profile="/usr/local/bin/gpud" pid=1234 comm="gpud" capability=16 capname="sys_module"`
+	testAppArmorLib  = `Nov 14 22:13:22 host kernel: audit: type=1400 audit(1700000000.125:791): apparmor="DENIED" operation="file_mmap" profile="/usr/local/bin/gpud" name="/usr/lib/x86_64-linux-gnu/libnvidia-ml.so.550.54.15" pid=1234 comm="gpud" requested_mask="m" denied_mask="m" fsuid=0 ouid=0`
+)
+
+var testFilter = Filter{Comm: DefaultComm, SELinuxDomains: DefaultSELinuxDomains}
+
+func TestFindDenials(t *testing.T) {
+	input := strings.Join([]string{
+		testSELinuxKmsg, testSELinuxModule, testSELinuxNVML, testSELinuxOther,
+		testAppArmorKmsg, testAppArmorCap, testAppArmorLib,
+		"unrelated line",
+	}, "\n")
+
+	denials := FindDenials(strings.NewReader(input), testFilter)
+	require.Len(t, denials, 6)
+
+	assert.Equal(t, ModuleSELinux, denials[0].Module)
+	assert.Equal(t, FeatureKmsg, denials[0].Feature)
+	assert.Equal(t, "unconfined_service_t", denials[0].Source)
+	assert.Equal(t, "kmsg_device_t", denials[0].Target)
+	assert.Equal(t, "chr_file", denials[0].Class)
+	assert.Equal(t, []string{"read"}, denials[0].Permissions)
+
+	assert.Equal(t, FeatureModuleLoading, denials[1].Feature)
+	assert.Equal(t, FeatureNVML, denials[2].Feature)
+	assert.Equal(t, []string{"open", "read", "write"}, denials[2].Permissions)
+
+	assert.Equal(t, ModuleAppArmor, denials[3].Module)
+	assert.Equal(t, FeatureKmsg, denials[3].Feature)
+	assert.Equal(t, "/usr/local/bin/gpud", denials[3].Profile)
+	assert.Equal(t, "/dev/kmsg", denials[3].Path)
+	assert.Equal(t, "r", denials[3].Mask)
+
+	assert.Equal(t, FeatureModuleLoading, denials[4].Feature)
+	assert.Equal(t, "sys_module", denials[4].Capability)
+	assert.Equal(t, FeatureNVML, denials[5].Feature)
+
+	for _, d := range denials {
+		assert.NotEmpty(t, d.String())
+	}
+}
+
+func TestFindDenialsFiltered(t *testing.T) {
+	// the command name spoofed by the process in the other domain or profile
+	spoofedSELinux := `type=AVC msg=audit(1700000000.127:460): avc:  denied  { module_load } for  pid=4321 comm="gpud" path="/tmp/evil.ko" scontext=system_u:system_r:sshd_t:s0 tcontext=system_u:object_r:user_tmp_t:s0 tclass=system permissive=0`
+	spoofedAppArmor := `audit: type=1400 audit(1700000000.128:792): apparmor="DENIED" operation="open" profile="/usr/bin/evil" name="/dev/kmsg" pid=4321 comm="gpud" requested_mask="r" denied_mask="r" fsuid=0 ouid=0`
+	noTime := `avc:  denied  { read } for  pid=1234 comm="gpud" name="kmsg" scontext=system_u:system_r:unconfined_service_t:s0 tcontext=system_u:object_r:kmsg_device_t:s0 tclass=chr_file permissive=0`
+	input := strings.Join([]string{spoofedSELinux, spoofedAppArmor, noTime, testSELinuxKmsg}, "\n")
+
+	denials := FindDenials(strings.NewReader(input), testFilter)
+	require.Len(t, denials, 1)
+	assert.Equal(t, "kmsg_device_t", denials[0].Target)
+
+	// the denials older than the time are ignored
+	f := testFilter
+	f.Since = time.Unix(1700000001, 0)
+	assert.Empty(t, FindDenials(strings.NewReader(input), f))
+}
+
+func TestClassify(t *testing.T) {
+	for _, tc := range []struct {
+		d    Denial
+		want Feature
+	}{
+		{Denial{Module: ModuleSELinux, Class: "system", Target: "kernel_t", Permissions: []string{"module_load"}}, FeatureModuleLoading},
+		{Denial{Module: ModuleSELinux, Class: "chr_file", Target: "kmsg_device_t", Permissions: []string{"open", "read"}}, FeatureKmsg},
+		// writing the kernel log is not needed
+		{Denial{Module: ModuleSELinux, Class: "chr_file", Target: "kmsg_device_t", Permissions: []string{"read", "write"}}, FeatureOther},
+		{Denial{Module: ModuleSELinux, Class: "chr_file", Target: "xserver_misc_device_t", Permissions: []string{"ioctl"}}, FeatureNVML},
+		// not classified by the substring of the target or the path
+		{Denial{Module: ModuleSELinux, Class: "file", Target: "nvidia_evil_t", Path: "/tmp/nvidia", Permissions: []string{"read"}}, FeatureOther},
+		{Denial{Module: ModuleSELinux, Class: "file", Target: "shadow_t", Path: "/etc/shadow", Permissions: []string{"getattr"}}, FeatureOther},
+		{Denial{Module: ModuleAppArmor, Capability: "sys_module"}, FeatureModuleLoading},
+		{Denial{Module: ModuleAppArmor, Capability: "sys_admin"}, FeatureOther},
+		{Denial{Module: ModuleAppArmor, Path: "/dev/kmsg", Mask: "r"}, FeatureKmsg},
+		{Denial{Module: ModuleAppArmor, Path: "/dev/kmsg", Mask: "w"}, FeatureOther},
+		{Denial{Module: ModuleAppArmor, Path: "/tmp/kmsg", Mask: "r"}, FeatureOther},
+		{Denial{Module: ModuleAppArmor, Path: "/usr/lib/modules/5.15.0/kernel/nvidia_peermem.ko", Mask: "r"}, FeatureModuleLoading},
+		{Denial{Module: ModuleAppArmor, Path: "/tmp/evil.ko", Mask: "r"}, FeatureOther},
+		{Denial{Module: ModuleAppArmor, Path: "/dev/nvidia0", Mask: "rw"}, FeatureNVML},
+		{Denial{Module: ModuleAppArmor, Path: "/home/nvidia/.ssh/id_rsa", Mask: "r"}, FeatureOther},
+	} {
+		assert.Equal(t, tc.want, classify(tc.d), tc.d.String())
+	}
+}
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	enforce := filepath.Join(dir, "enforce")
+	enabled := filepath.Join(dir, "enabled")
+	auditLog := filepath.Join(dir, "audit.log")
+	kernLog := filepath.Join(dir, "kern.log")
+	opts := []OpOption{
+		WithSELinuxEnforceFile(enforce),
+		WithAppArmorEnabledFile(enabled),
+		WithLogFiles(auditLog, kernLog, filepath.Join(dir, "missing.log")),
+		WithSince(time.Unix(1600000000, 0)),
+	}
+
+	rs, err := Scan(opts...)
+	require.NoError(t, err)
+	assert.False(t, rs.Enforced())
+	assert.Empty(t, rs.Denials)
+
+	require.NoError(t, os.WriteFile(enforce, []byte("1\n"), 0644))
+	require.NoError(t, os.WriteFile(auditLog, []byte(testSELinuxKmsg+"\n"+testSELinuxKmsg+"\n"), 0644))
+	require.NoError(t, os.WriteFile(kernLog, []byte(testSELinuxKmsg+"\n"+testSELinuxModule+"\n"), 0644))
+
+	rs, err = Scan(opts...)
+	require.NoError(t, err)
+	assert.True(t, rs.SELinuxEnforcing)
+	assert.False(t, rs.AppArmorEnabled)
+	require.Len(t, rs.Denials, 2, "duplicate denials must be merged")
+	assert.Equal(t, FeatureKmsg, rs.Denials[0].Feature)
+	assert.Equal(t, FeatureModuleLoading, rs.Denials[1].Feature)
+}
+
+func TestReadTail(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "log")
+	require.NoError(t, os.WriteFile(f, []byte("0123456789"), 0644))
+
+	b, err := readTail(f, 4)
+	require.NoError(t, err)
+	assert.Equal(t, "6789", string(b))
+
+	b, err = readTail(f, 100)
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789", string(b))
+}
@@ -0,0 +1,236 @@
+package lsm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/leptonai/gpud/pkg/toolexec"
+)
+
+const (
+	// DefaultSELinuxPolicyDir is the directory the SELinux policy module is written to.
+	DefaultSELinuxPolicyDir = "/etc/gpud/selinux"
+	// DefaultAppArmorDir is the AppArmor profile directory.
+	DefaultAppArmorDir = "/etc/apparmor.d"
+
+	// SELinuxModuleName is the name of the SELinux policy module for gpud.
+	SELinuxModuleName = "gpud_local"
+
+	policyHeader = "# generated by \"gpud up --install-security-policy\" from the denials affecting gpud"
+)
+
+// SELinuxModule returns the SELinux policy module (type enforcement file)
+// that allows the SELinux denials, or empty if none.
+// The denials not whitelisted for any gpud feature (FeatureOther) are never allowed.
+func SELinuxModule(denials []Denial) string {
+	types := make(map[string]struct{})
+	classes := make(map[string]map[string]struct{})
+	rules := make(map[string]map[string]struct{})
+	for _, d := range denials {
+		if d.Module != ModuleSELinux || d.Feature == FeatureOther {
+			continue
+		}
+		types[d.Source] = struct{}{}
+		types[d.Target] = struct{}{}
+
+		if classes[d.Class] == nil {
+			classes[d.Class] = make(map[string]struct{})
+		}
+		k := fmt.Sprintf("%s %s:%s", d.Source, d.Target, d.Class)
+		if rules[k] == nil {
+			rules[k] = make(map[string]struct{})
+		}
+		for _, p := range d.Permissions {
+			classes[d.Class][p] = struct{}{}
+			rules[k][p] = struct{}{}
+		}
+	}
+	if len(rules) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(policyHeader + "\n")
+	fmt.Fprintf(&sb, "module %s 1.0;\n\nrequire {\n", SELinuxModuleName)
+	for _, t := range sortedKeys(types) {
+		fmt.Fprintf(&sb, "\ttype %s;\n", t)
+	}
+	for _, c := range sortedKeys(classes) {
+		fmt.Fprintf(&sb, "\tclass %s { %s };\n", c, strings.Join(sortedKeys(classes[c]), " "))
+	}
+	sb.WriteString("}\n\n")
+	for _, k := range sortedKeys(rules) {
+		fmt.Fprintf(&sb, "allow %s { %s };\n", k, strings.Join(sortedKeys(rules[k]), " "))
+	}
+	return sb.String()
+}
+
+// AppArmorRules returns the AppArmor rules per profile
+// that allow the AppArmor denials.
+// The denials not whitelisted for any gpud feature (FeatureOther) are never allowed.
+func AppArmorRules(denials []Denial) map[string][]string {
+	rules := make(map[string]map[string]struct{})
+	for _, d := range denials {
+		if d.Module != ModuleAppArmor || d.Feature == FeatureOther {
+			continue
+		}
+
+		var rule string
+		if d.Capability != "" {
+			rule = fmt.Sprintf("capability %s,", d.Capability)
+		} else if mask := appArmorFileMask(d.Mask); mask != "" {
+			rule = fmt.Sprintf("%s %s,", d.Path, mask)
+		}
+		if rule == "" {
+			continue
+		}
+
+		if rules[d.Profile] == nil {
+			rules[d.Profile] = make(map[string]struct{})
+		}
+		rules[d.Profile][rule] = struct{}{}
+	}
+
+	ret := make(map[string][]string, len(rules))
+	for profile, rs := range rules {
+		ret[profile] = sortedKeys(rs)
+	}
+	return ret
+}
+
+// appArmorFileMask converts the denied mask to the file rule permissions
+// (e.g., the create "c" and append "a" accesses are allowed by the write "w").
+func appArmorFileMask(denied string) string {
+	allowed := make(map[byte]bool)
+	for i := 0; i < len(denied); i++ {
+		switch c := denied[i]; c {
+		case 'c', 'd', 'a', 'w':
+			allowed['w'] = true
+		case 'r', 'm', 'k', 'l':
+			allowed[c] = true
+		}
+	}
+
+	var sb strings.Builder
+	for _, c := range []byte("rwmkl") {
+		if allowed[c] {
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// appArmorProfileFile returns the profile file name of the profile,
+// following the naming convention (e.g., "usr.local.bin.gpud" for "/usr/local/bin/gpud").
+func appArmorProfileFile(profile string) string {
+	return strings.ReplaceAll(strings.TrimPrefix(profile, "/"), "/", ".")
+}
+
+type policyOp struct {
+	selinuxPolicyDir string
+	apparmorDir      string
+	runCommand       func(ctx context.Context, name string, args ...string) error
+}
+
+// InstallPolicy installs the policy snippets that allow the denials,
+// and returns the written files.
+//
+// The SELinux policy module is compiled and loaded with "checkmodule",
+// "semodule_package", and "semodule". The AppArmor rules are written
+// to the local override of each profile, which must be included by the profile
+// (e.g., "include if exists <local/usr.local.bin.gpud>"), and the profile is reloaded
+// with "apparmor_parser".
+func InstallPolicy(ctx context.Context, denials []Denial) ([]string, error) {
+	return installPolicy(ctx, &policyOp{
+		selinuxPolicyDir: DefaultSELinuxPolicyDir,
+		apparmorDir:      DefaultAppArmorDir,
+		runCommand:       runCommand,
+	}, denials)
+}
+
+func installPolicy(ctx context.Context, op *policyOp, denials []Denial) ([]string, error) {
+	var written []string
+
+	if module := SELinuxModule(denials); module != "" {
+		if err := os.MkdirAll(op.selinuxPolicyDir, 0755); err != nil {
+			return written, err
+		}
+
+		te := filepath.Join(op.selinuxPolicyDir, SELinuxModuleName+".te")
+		if err := os.WriteFile(te, []byte(module), 0644); err != nil {
+			return written, err
+		}
+		written = append(written, te)
+
+		mod := filepath.Join(op.selinuxPolicyDir, SELinuxModuleName+".mod")
+		pp := filepath.Join(op.selinuxPolicyDir, SELinuxModuleName+".pp")
+		if err := op.runCommand(ctx, "checkmodule", "-M", "-m", "-o", mod, te); err != nil {
+			return written, err
+		}
+		if err := op.runCommand(ctx, "semodule_package", "-o", pp, "-m", mod); err != nil {
+			return written, err
+		}
+		if err := op.runCommand(ctx, "semodule", "-i", pp); err != nil {
+			return written, err
+		}
+	}
+
+	rules := AppArmorRules(denials)
+	for _, profile := range sortedKeys(rules) {
+		name := appArmorProfileFile(profile)
+		profileFile := filepath.Join(op.apparmorDir, name)
+		b, err := os.ReadFile(profileFile)
+		if err != nil {
+			return written, fmt.Errorf("failed to read apparmor profile %q: %w", profile, err)
+		}
+		if !strings.Contains(string(b), "local/"+name) {
+			return written, fmt.Errorf("apparmor profile %s does not include the local override (add 'include if exists <local/%s>' to the profile)", profileFile, name)
+		}
+
+		localDir := filepath.Join(op.apparmorDir, "local")
+		if err := os.MkdirAll(localDir, 0755); err != nil {
+			return written, err
+		}
+		local := filepath.Join(localDir, name)
+		contents := policyHeader + "\n" + strings.Join(rules[profile], "\n") + "\n"
+		if err := os.WriteFile(local, []byte(contents), 0644); err != nil {
+			return written, err
+		}
+		written = append(written, local)
+
+		if err := op.runCommand(ctx, "apparmor_parser", "-r", profileFile); err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
+
+func runCommand(ctx context.Context, name string, args ...string) error {
+	res, err := toolexec.Run(ctx, append([]string{name}, args...))
+	if errors.Is(err, toolexec.ErrNotFound) {
+		return fmt.Errorf("%s not found (install the policy tools of the security module)", name)
+	}
+	if err != nil {
+		var out []byte
+		if res != nil {
+			out = res.Output
+		}
+		return fmt.Errorf("%s failed: %w output: %s", name, err, out)
+	}
+	return nil
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,84 @@
+package lsm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSELinuxModule(t *testing.T) {
+	assert.Empty(t, SELinuxModule(nil))
+
+	denials := FindDenials(strings.NewReader(strings.Join([]string{testSELinuxKmsg, testSELinuxNVML, testAppArmorKmsg}, "\n")), testFilter)
+	module := SELinuxModule(denials)
+	assert.Contains(t, module, "module gpud_local 1.0;")
+	assert.Contains(t, module, "\ttype kmsg_device_t;\n")
+	assert.Contains(t, module, "\ttype unconfined_service_t;\n")
+	assert.Contains(t, module, "\tclass chr_file { open read write };\n")
+	assert.Contains(t, module, "allow unconfined_service_t kmsg_device_t:chr_file { read };\n")
+	assert.Contains(t, module, "allow unconfined_service_t xserver_misc_device_t:chr_file { open read write };\n")
+
+	// the denials not whitelisted are never allowed
+	assert.Empty(t, SELinuxModule([]Denial{{Module: ModuleSELinux, Feature: FeatureOther, Source: "unconfined_service_t", Target: "shadow_t", Class: "file", Permissions: []string{"read"}}}))
+}
+
+func TestAppArmorRules(t *testing.T) {
+	denials := FindDenials(strings.NewReader(strings.Join([]string{testAppArmorKmsg, testAppArmorCap, testAppArmorLib, testSELinuxKmsg}, "\n")), testFilter)
+	rules := AppArmorRules(denials)
+	assert.Equal(t, map[string][]string{
+		"/usr/local/bin/gpud": {
+			"/dev/kmsg r,",
+			"/usr/lib/x86_64-linux-gnu/libnvidia-ml.so.550.54.15 m,",
+			"capability sys_module,",
+		},
+	}, rules)
+
+	assert.Equal(t, "rw", appArmorFileMask("cr"))
+	assert.Equal(t, "", appArmorFileMask("x"))
+	assert.Equal(t, "usr.local.bin.gpud", appArmorProfileFile("/usr/local/bin/gpud"))
+}
+
+func TestInstallPolicy(t *testing.T) {
+	dir := t.TempDir()
+	var commands []string
+	op := &policyOp{
+		selinuxPolicyDir: filepath.Join(dir, "selinux"),
+		apparmorDir:      filepath.Join(dir, "apparmor.d"),
+		runCommand: func(ctx context.Context, name string, args ...string) error {
+			commands = append(commands, name)
+			return nil
+		},
+	}
+	denials := FindDenials(strings.NewReader(strings.Join([]string{testSELinuxKmsg, testAppArmorKmsg}, "\n")), testFilter)
+
+	// profile not found
+	_, err := installPolicy(context.Background(), op, denials)
+	require.Error(t, err)
+
+	// profile without the local override
+	require.NoError(t, os.MkdirAll(op.apparmorDir, 0755))
+	profileFile := filepath.Join(op.apparmorDir, "usr.local.bin.gpud")
+	require.NoError(t, os.WriteFile(profileFile, []byte("/usr/local/bin/gpud {\n}\n"), 0644))
+	_, err = installPolicy(context.Background(), op, denials)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "include if exists <local/usr.local.bin.gpud>")
+
+	require.NoError(t, os.WriteFile(profileFile, []byte("/usr/local/bin/gpud {\n  include if exists <local/usr.local.bin.gpud>\n}\n"), 0644))
+	commands = nil
+	written, err := installPolicy(context.Background(), op, denials)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(op.selinuxPolicyDir, "gpud_local.te"),
+		filepath.Join(op.apparmorDir, "local", "usr.local.bin.gpud"),
+	}, written)
+	assert.Equal(t, []string{"checkmodule", "semodule_package", "semodule", "apparmor_parser"}, commands)
+
+	b, err := os.ReadFile(written[1])
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "/dev/kmsg r,\n")
+}
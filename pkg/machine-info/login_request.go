@@ -82,15 +82,7 @@ func createLoginRequest(
 
 	// get the default values from the machine info
 	if req.MachineInfo != nil && req.MachineInfo.NICInfo != nil {
-		for _, iface := range req.MachineInfo.NICInfo.PrivateIPInterfaces {
-			if iface.IP == "" {
-				continue
-			}
-			if req.Network.PrivateIP == "" && iface.Addr.IsPrivate() && iface.Addr.Is4() {
-				req.Network.PrivateIP = iface.IP
-				break
-			}
-		}
+		req.Network.PrivateIP, req.Network.PrivateIPv6 = findPrivateIPs(req.MachineInfo.NICInfo.PrivateIPInterfaces)
 	}
 
 	// represents the CPU, in cores (500m = .5 cores).
@@ -122,3 +114,25 @@ func createLoginRequest(
 
 	return req, nil
 }
+
+// findPrivateIPs returns the first private IPv4 address and the first
+// unique local IPv6 address (the link-local addresses are not routable).
+// The IPv6-only machines use the IPv6 address as the private IP.
+func findPrivateIPs(ifaces []apiv1.MachineNetworkInterface) (string, string) {
+	privateIPv4, privateIPv6 := "", ""
+	for _, iface := range ifaces {
+		if iface.IP == "" || !iface.Addr.IsPrivate() {
+			continue
+		}
+		if iface.Addr.Is4() && privateIPv4 == "" {
+			privateIPv4 = iface.IP
+		}
+		if iface.Addr.Is6() && privateIPv6 == "" {
+			privateIPv6 = iface.IP
+		}
+	}
+	if privateIPv4 == "" {
+		return privateIPv6, privateIPv6
+	}
+	return privateIPv4, privateIPv6
+}
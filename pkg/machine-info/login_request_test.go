@@ -103,6 +103,10 @@ func (m *mockNvmlInstance) Architecture() string {
 	return ""
 }
 
+func (m *mockNvmlInstance) VirtualizationMode() nvidianvml.VirtualizationMode {
+	return nvidianvml.VirtualizationModeNone
+}
+
 // mockNetworkInterface creates a network interface with specified IP values
 func mockNetworkInterface(publicIP, privateIP string) apiv1.MachineNetworkInterface {
 	var addr netip.Addr
@@ -510,10 +514,11 @@ func TestCreateLoginRequest_NetworkBasics(t *testing.T) {
 // TestCreateLoginRequest_PrivateIPDetection tests private IP detection logic
 func TestCreateLoginRequest_PrivateIPDetection(t *testing.T) {
 	tests := []struct {
-		name        string
-		interfaces  []apiv1.MachineNetworkInterface
-		expectedIP  string
-		description string
+		name         string
+		interfaces   []apiv1.MachineNetworkInterface
+		expectedIP   string
+		expectedIPv6 string
+		description  string
 	}{
 		{
 			name: "private IPv4 detected",
@@ -560,6 +565,35 @@ func TestCreateLoginRequest_PrivateIPDetection(t *testing.T) {
 			expectedIP:  "192.168.1.1",
 			description: "Should select first private IPv4 address",
 		},
+		{
+			name: "dual-stack",
+			interfaces: []apiv1.MachineNetworkInterface{
+				{Interface: "eth0", IP: "fe80::1", Addr: netip.MustParseAddr("fe80::1")},
+				{Interface: "eth1", IP: "10.0.0.1", Addr: netip.MustParseAddr("10.0.0.1")},
+				{Interface: "eth1", IP: "fd00::1", Addr: netip.MustParseAddr("fd00::1")},
+			},
+			expectedIP:   "10.0.0.1",
+			expectedIPv6: "fd00::1",
+			description:  "Should detect both private IPv4 and unique local IPv6 addresses",
+		},
+		{
+			name: "IPv6 only",
+			interfaces: []apiv1.MachineNetworkInterface{
+				{Interface: "eth0", IP: "fd00::1", Addr: netip.MustParseAddr("fd00::1")},
+			},
+			expectedIP:   "fd00::1",
+			expectedIPv6: "fd00::1",
+			description:  "Should use the private IPv6 address as the private IP",
+		},
+		{
+			name: "IPv6 link-local only",
+			interfaces: []apiv1.MachineNetworkInterface{
+				{Interface: "eth0", IP: "fe80::1", Addr: netip.MustParseAddr("fe80::1")},
+			},
+			expectedIP:   "",
+			expectedIPv6: "",
+			description:  "Should not use the link-local address",
+		},
 	}
 
 	for _, tt := range tests {
@@ -595,6 +629,7 @@ func TestCreateLoginRequest_PrivateIPDetection(t *testing.T) {
 			assert.NoError(t, err, tt.description)
 			assert.NotNil(t, req, tt.description)
 			assert.Equal(t, tt.expectedIP, req.Network.PrivateIP, tt.description)
+			assert.Equal(t, tt.expectedIPv6, req.Network.PrivateIPv6, tt.description)
 		})
 	}
 }
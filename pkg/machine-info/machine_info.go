@@ -3,6 +3,7 @@ package machineinfo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"runtime"
@@ -26,13 +27,94 @@ import (
 	"github.com/leptonai/gpud/pkg/netutil"
 	pkgnetutillatencyedge "github.com/leptonai/gpud/pkg/netutil/latency/edge"
 	nvidiaquery "github.com/leptonai/gpud/pkg/nvidia-query"
+	"github.com/leptonai/gpud/pkg/nvidia-query/infiniband"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	pkgprobecache "github.com/leptonai/gpud/pkg/probecache"
 	"github.com/leptonai/gpud/pkg/providers"
 	pkgprovidersall "github.com/leptonai/gpud/pkg/providers/all"
 	"github.com/leptonai/gpud/version"
 )
 
 func GetMachineInfo(nvmlInstance nvidianvml.Instance) (*apiv1.MachineInfo, error) {
+	return getMachineInfo(nvmlInstance, GetMachineCPUInfo, GetMachineGPUInfo)
+}
+
+// gpuInfoMaxAge bounds the staleness of the cached GPU inventory
+// (e.g., the VBIOS updated with the GPU reset, without the reboot).
+const gpuInfoMaxAge = 10 * time.Minute
+
+// GetMachineInfoWithCache is the same as GetMachineInfo, but reads the static
+// CPU information from the probe cache if cached in the current boot.
+// The GPU inventory is only cached for the short period, and re-probed
+// if the GPUs visible to NVML differ from the cached ones (e.g., the GPU
+// fell off the bus before the restart). The active vGPUs are never cached.
+func GetMachineInfoWithCache(ctx context.Context, nvmlInstance nvidianvml.Instance, cache *pkgprobecache.Cache) (*apiv1.MachineInfo, error) {
+	getCPUInfo := func() *apiv1.MachineCPUInfo {
+		info, _ := pkgprobecache.Load(ctx, cache, pkgprobecache.KeyMachineCPUInfo, func() (*apiv1.MachineCPUInfo, error) {
+			return GetMachineCPUInfo(), nil
+		})
+		return info
+	}
+	getGPUInfo := func(nvmlInstance nvidianvml.Instance) (*apiv1.MachineGPUInfo, error) {
+		info, err := pkgprobecache.LoadWithMaxAge(ctx, cache, pkgprobecache.KeyMachineGPUInfo, gpuInfoMaxAge, func() (*apiv1.MachineGPUInfo, error) {
+			return GetMachineGPUInfo(nvmlInstance)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if sameGPUs(info, nvmlInstance) {
+			return info, refreshActiveVGPUs(info, nvmlInstance)
+		}
+
+		log.Logger.Infow("gpus changed since cached, re-probing gpu info")
+		info, err = GetMachineGPUInfo(nvmlInstance)
+		if err != nil {
+			return nil, err
+		}
+		if err := cache.Set(ctx, pkgprobecache.KeyMachineGPUInfo, info); err != nil {
+			log.Logger.Warnw("failed to write probe cache", "key", pkgprobecache.KeyMachineGPUInfo, "error", err)
+		}
+		return info, nil
+	}
+	return getMachineInfo(nvmlInstance, getCPUInfo, getGPUInfo)
+}
+
+// sameGPUs returns true if the GPU info has the same GPUs as visible to NVML.
+func sameGPUs(info *apiv1.MachineGPUInfo, nvmlInstance nvidianvml.Instance) bool {
+	devs := nvmlInstance.Devices()
+	if info == nil || len(info.GPUs) != len(devs) {
+		return false
+	}
+	for _, gpu := range info.GPUs {
+		if _, ok := devs[gpu.UUID]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// refreshActiveVGPUs updates the active vGPUs of the cached GPU info,
+// which change whenever the vGPU guests start or stop.
+func refreshActiveVGPUs(info *apiv1.MachineGPUInfo, nvmlInstance nvidianvml.Instance) error {
+	if nvmlInstance.VirtualizationMode() != nvidianvml.VirtualizationModeHostVGPU {
+		return nil
+	}
+	devs := nvmlInstance.Devices()
+	for i := range info.GPUs {
+		virt, err := nvidianvml.GetVirtualization(info.GPUs[i].UUID, devs[info.GPUs[i].UUID])
+		if err != nil {
+			return err
+		}
+		info.GPUs[i].ActiveVGPUs = virt.ActiveVGPUs
+	}
+	return nil
+}
+
+func getMachineInfo(
+	nvmlInstance nvidianvml.Instance,
+	getCPUInfo func() *apiv1.MachineCPUInfo,
+	getGPUInfo func(nvidianvml.Instance) (*apiv1.MachineGPUInfo, error),
+) (*apiv1.MachineInfo, error) {
 	hostname, _ := os.Hostname()
 	info := &apiv1.MachineInfo{
 		GPUdVersion: version.Version,
@@ -49,13 +131,13 @@ func GetMachineInfo(nvmlInstance nvidianvml.Instance) (*apiv1.MachineInfo, error
 		Hostname:                hostname,
 		Uptime:                  metav1.NewTime(time.Unix(int64(pkghost.BootTimeUnixSeconds()), 0)),
 
-		CPUInfo:    GetMachineCPUInfo(),
+		CPUInfo:    getCPUInfo(),
 		MemoryInfo: GetMachineMemoryInfo(),
 		NICInfo:    GetMachineNICInfo(),
 	}
 
 	var err error
-	info.GPUInfo, err = GetMachineGPUInfo(nvmlInstance)
+	info.GPUInfo, err = getGPUInfo(nvmlInstance)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get machine gpu info: %w", err)
 	}
@@ -177,7 +259,29 @@ func GetMachineNICInfo() *apiv1.MachineNICInfo {
 	})
 	return &apiv1.MachineNICInfo{
 		PrivateIPInterfaces: ifaces,
+		InfinibandDevices:   getMachineInfinibandDevices(infiniband.DefaultClassDir),
+	}
+}
+
+// getMachineInfinibandDevices returns the infiniband devices with their firmware versions,
+// to tell the firmware upgrades, or nil if the host has no infiniband device.
+func getMachineInfinibandDevices(classDir string) []apiv1.MachineInfinibandDevice {
+	cards, err := infiniband.ReadSysfsCards(classDir)
+	if err != nil {
+		if !errors.Is(err, infiniband.ErrNoSysfsDevice) {
+			log.Logger.Warnw("failed to read infiniband devices", "error", err)
+		}
+		return nil
+	}
+
+	devs := make([]apiv1.MachineInfinibandDevice, 0, len(cards))
+	for _, card := range cards {
+		devs = append(devs, apiv1.MachineInfinibandDevice{
+			Device:          card.Device,
+			FirmwareVersion: card.FirmwareVersion,
+		})
 	}
+	return devs
 }
 
 // GetProvider looks up the provider of the machine.
@@ -276,6 +380,8 @@ func GetMachineGPUInfo(nvmlInstance nvidianvml.Instance) (*apiv1.MachineGPUInfo,
 		Product:      nvmlInstance.ProductName(),
 		Manufacturer: nvmlInstance.Brand(),
 		Architecture: nvmlInstance.Architecture(),
+
+		VirtualizationMode: string(nvmlInstance.VirtualizationMode()),
 	}
 
 	for uuid, dev := range nvmlInstance.Devices() {
@@ -304,12 +410,27 @@ func GetMachineGPUInfo(nvmlInstance nvidianvml.Instance) (*apiv1.MachineGPUInfo,
 			return nil, err
 		}
 
-		info.GPUs = append(info.GPUs, apiv1.MachineGPUInstance{
-			UUID:    uuid,
-			SN:      serialID,
-			MinorID: strconv.Itoa(minorID),
-			BoardID: boardID,
-		})
+		// not supported on all the GPUs (e.g., the vGPU guests)
+		vbiosVersion, err := nvidianvml.GetVBIOSVersion(uuid, dev)
+		if err != nil {
+			log.Logger.Warnw("failed to get vbios version", "uuid", uuid, "error", err)
+		}
+
+		gpu := apiv1.MachineGPUInstance{
+			UUID:         uuid,
+			SN:           serialID,
+			MinorID:      strconv.Itoa(minorID),
+			BoardID:      boardID,
+			VBIOSVersion: vbiosVersion,
+		}
+		if nvmlInstance.VirtualizationMode() == nvidianvml.VirtualizationModeHostVGPU {
+			virt, err := nvidianvml.GetVirtualization(uuid, dev)
+			if err != nil {
+				return nil, err
+			}
+			gpu.ActiveVGPUs = virt.ActiveVGPUs
+		}
+		info.GPUs = append(info.GPUs, gpu)
 	}
 
 	return info, nil
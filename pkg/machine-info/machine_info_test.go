@@ -4,14 +4,18 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"testing"
 	"time"
 
+	"github.com/NVIDIA/go-nvlib/pkg/nvlib/device"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/api/resource"
 
+	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/pkg/log"
 	nvidiaquery "github.com/leptonai/gpud/pkg/nvidia-query"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
@@ -423,3 +427,36 @@ func TestGetSystemResourceRootVolumeTotal_Validation(t *testing.T) {
 
 	t.Logf("Root volume: %s (parsed: %d bytes)", volume, volQty.Value())
 }
+
+func TestGetMachineInfinibandDevices(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, getMachineInfinibandDevices(filepath.Join(dir, "nonexistent")))
+
+	for dev, fw := range map[string]string{"mlx5_1": "28.41.1000", "mlx5_0": "28.39.1002"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, dev, "ports", "1"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, dev, "fw_ver"), []byte(fw+"\n"), 0644))
+	}
+	assert.Equal(t, []apiv1.MachineInfinibandDevice{
+		{Device: "mlx5_0", FirmwareVersion: "28.39.1002"},
+		{Device: "mlx5_1", FirmwareVersion: "28.41.1000"},
+	}, getMachineInfinibandDevices(dir))
+}
+
+type mockDevicesInstance struct {
+	nvidianvml.Instance
+	devs map[string]device.Device
+}
+
+func (m *mockDevicesInstance) Devices() map[string]device.Device { return m.devs }
+
+func TestSameGPUs(t *testing.T) {
+	cached := &apiv1.MachineGPUInfo{GPUs: []apiv1.MachineGPUInstance{{UUID: "GPU-0"}, {UUID: "GPU-1"}}}
+
+	assert.True(t, sameGPUs(cached, &mockDevicesInstance{devs: map[string]device.Device{"GPU-0": nil, "GPU-1": nil}}))
+
+	// fell off the bus
+	assert.False(t, sameGPUs(cached, &mockDevicesInstance{devs: map[string]device.Device{"GPU-0": nil}}))
+	// replaced
+	assert.False(t, sameGPUs(cached, &mockDevicesInstance{devs: map[string]device.Device{"GPU-0": nil, "GPU-2": nil}}))
+	assert.False(t, sameGPUs(nil, &mockDevicesInstance{}))
+}
@@ -0,0 +1,196 @@
+// Package maintenance defers the disruptive maintenance (e.g., the automatic
+// remediations, the auto-updates) while the GPU jobs are running on the node,
+// up to the maximum deferral before forcing it, so that the node always busy
+// with the jobs still gets repaired and updated.
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// DefaultMaxDeferral is the default longest time the maintenance is deferred for
+// while the node is busy, before forcing it.
+const DefaultMaxDeferral = 4 * time.Hour
+
+// Policy configures the busy signals and the maximum deferral.
+type Policy struct {
+	// Kubelet is true to defer the maintenance while the pods requesting
+	// the GPUs are running on the node, listed from the authenticated kubelet API.
+	Kubelet bool `json:"kubelet,omitempty"`
+	// KubeletPort is the authenticated (HTTPS) kubelet port.
+	// Zero to use the default.
+	KubeletPort int `json:"kubelet_port,omitempty"`
+	// KubeletTokenFile is the bearer token file authorized to list the pods
+	// from the kubelet (e.g., the service account token with "nodes/proxy" get).
+	// Empty to use the default.
+	KubeletTokenFile string `json:"kubelet_token_file,omitempty"`
+	// KubeletCAFile is the CA file to verify the kubelet serving certificate.
+	// Empty to use the default (self-signed) kubelet serving certificate.
+	KubeletCAFile string `json:"kubelet_ca_file,omitempty"`
+	// BusyHook is the command (run with bash) that signals whether the node
+	// is busy with the jobs (e.g., queries the Slurm or the custom scheduler).
+	// The hook exits 0 if the node is idle, 1 if busy with its output as the reason,
+	// and any other exit code is the hook failure, treated as busy.
+	BusyHook string `json:"busy_hook,omitempty"`
+	// MaxDeferral is the longest time the maintenance is deferred for.
+	// Zero to use the default.
+	MaxDeferral metav1.Duration `json:"max_deferral,omitempty"`
+}
+
+// Validate validates the policy.
+func (p Policy) Validate() error {
+	if !p.Kubelet && p.BusyHook == "" {
+		return fmt.Errorf("no busy signal configured (kubelet or busy hook)")
+	}
+	if p.KubeletPort < 0 || p.KubeletPort > 65535 {
+		return fmt.Errorf("invalid kubelet port %d", p.KubeletPort)
+	}
+	if p.MaxDeferral.Duration < 0 {
+		return fmt.Errorf("max deferral must be non-negative, got %v", p.MaxDeferral)
+	}
+	return nil
+}
+
+func (p Policy) maxDeferral() time.Duration {
+	if p.MaxDeferral.Duration > 0 {
+		return p.MaxDeferral.Duration
+	}
+	return DefaultMaxDeferral
+}
+
+// Signal returns true with the reason if the node is busy with the jobs.
+type Signal func(ctx context.Context) (bool, string, error)
+
+// Decision is the outcome of the deferral for a maintenance.
+type Decision struct {
+	// Proceed is true if the maintenance may proceed.
+	Proceed bool `json:"proceed"`
+	// Forced is true if the maintenance proceeds on the busy node,
+	// since it has been deferred for the maximum deferral.
+	Forced bool `json:"forced,omitempty"`
+	// Reason is why the node is busy, empty if idle.
+	Reason string `json:"reason,omitempty"`
+	// DeferredSince is the time the maintenance was first deferred, if deferred.
+	DeferredSince *metav1.Time `json:"deferred_since,omitempty"`
+}
+
+// Deferrer defers the maintenance while any of the busy signals is on.
+// The nil deferrer never defers.
+type Deferrer struct {
+	maxDeferral time.Duration
+	signals     []Signal
+
+	nowFunc func() time.Time
+
+	mu sync.Mutex
+	// deferrals is the deferral of each maintenance, removed once it proceeds
+	// or expired once not decided again within the maximum deferral
+	deferrals map[string]deferral
+}
+
+type deferral struct {
+	// since is the time the maintenance was first deferred
+	since time.Time
+	// last is the time the maintenance was last decided
+	last time.Time
+}
+
+// New creates a new deferrer with the busy signals of the policy.
+func New(p Policy) *Deferrer {
+	var signals []Signal
+	if p.Kubelet {
+		cfg := KubeletConfig{
+			Port:      p.KubeletPort,
+			TokenFile: p.KubeletTokenFile,
+			CAFile:    p.KubeletCAFile,
+		}
+		signals = append(signals, KubeletSignal(cfg))
+	}
+	if p.BusyHook != "" {
+		signals = append(signals, HookSignal(p.BusyHook))
+	}
+	return NewDeferrer(p.maxDeferral(), signals...)
+}
+
+// NewDeferrer creates a new deferrer with the busy signals and the maximum deferral.
+func NewDeferrer(maxDeferral time.Duration, signals ...Signal) *Deferrer {
+	return &Deferrer{
+		maxDeferral: maxDeferral,
+		signals:     signals,
+		nowFunc:     time.Now,
+		deferrals:   make(map[string]deferral),
+	}
+}
+
+// Decide decides whether the maintenance (e.g., "update") may proceed now.
+// The maintenance is deferred while the node is busy, and forced once it
+// has been deferred for the maximum deferral since the first deferral.
+// The failed busy signal is treated as busy, to not disrupt the jobs
+// when the signal is unavailable (still bounded by the maximum deferral).
+// The deferral not decided again within the maximum deferral (e.g., the
+// remediation no longer needed) expires, so that the same maintenance
+// requested later is deferred anew rather than forced right away.
+func (d *Deferrer) Decide(ctx context.Context, maintenance string) Decision {
+	if d == nil {
+		return Decision{Proceed: true}
+	}
+
+	var reasons []string
+	for _, signal := range d.signals {
+		busy, reason, err := signal(ctx)
+		if err != nil {
+			reasons = append(reasons, fmt.Sprintf("busy signal failed: %v", err))
+			continue
+		}
+		if busy {
+			reasons = append(reasons, reason)
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.nowFunc()
+	for m, df := range d.deferrals {
+		if now.Sub(df.last) > d.maxDeferral {
+			log.Logger.Infow("expiring stale maintenance deferral", "maintenance", m, "deferredSince", df.since, "lastDecided", df.last)
+			delete(d.deferrals, m)
+		}
+	}
+
+	if len(reasons) == 0 {
+		delete(d.deferrals, maintenance)
+		return Decision{Proceed: true}
+	}
+
+	df, ok := d.deferrals[maintenance]
+	if !ok {
+		df.since = now
+	}
+	df.last = now
+	d.deferrals[maintenance] = df
+	since := df.since
+
+	dec := Decision{
+		Reason:        strings.Join(reasons, "; "),
+		DeferredSince: &metav1.Time{Time: since},
+	}
+	if now.Sub(since) >= d.maxDeferral {
+		delete(d.deferrals, maintenance)
+		dec.Proceed = true
+		dec.Forced = true
+		log.Logger.Warnw("forcing maintenance on busy node after max deferral", "maintenance", maintenance, "reason", dec.Reason, "deferredSince", since, "maxDeferral", d.maxDeferral)
+		return dec
+	}
+
+	log.Logger.Infow("deferring maintenance on busy node", "maintenance", maintenance, "reason", dec.Reason, "deferredSince", since)
+	return dec
+}
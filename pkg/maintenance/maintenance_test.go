@@ -0,0 +1,197 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPolicyValidate(t *testing.T) {
+	assert.NoError(t, Policy{Kubelet: true}.Validate())
+	assert.NoError(t, Policy{BusyHook: "squeue -h -w $(hostname)"}.Validate())
+	assert.Error(t, Policy{}.Validate())
+	assert.Error(t, Policy{Kubelet: true, KubeletPort: -1}.Validate())
+	assert.Error(t, Policy{Kubelet: true, MaxDeferral: metav1.Duration{Duration: -time.Hour}}.Validate())
+
+	assert.Equal(t, DefaultMaxDeferral, Policy{}.maxDeferral())
+	assert.Equal(t, time.Hour, Policy{MaxDeferral: metav1.Duration{Duration: time.Hour}}.maxDeferral())
+}
+
+func TestDeferrerDecide(t *testing.T) {
+	busy := true
+	var signalErr error
+	d := NewDeferrer(time.Hour, func(ctx context.Context) (bool, string, error) {
+		return busy, "2 pod(s) running on the GPUs", signalErr
+	})
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	d.nowFunc = func() time.Time { return now }
+
+	dec := d.Decide(context.Background(), "update")
+	assert.False(t, dec.Proceed)
+	assert.Equal(t, "2 pod(s) running on the GPUs", dec.Reason)
+	require.NotNil(t, dec.DeferredSince)
+	assert.Equal(t, now, dec.DeferredSince.Time)
+
+	// deferred independently per maintenance
+	now = now.Add(30 * time.Minute)
+	dec = d.Decide(context.Background(), "remediation/accelerator-nvidia-xid/xid")
+	assert.False(t, dec.Proceed)
+	assert.Equal(t, now, dec.DeferredSince.Time)
+
+	// forced after the max deferral
+	now = now.Add(30 * time.Minute)
+	dec = d.Decide(context.Background(), "update")
+	assert.True(t, dec.Proceed)
+	assert.True(t, dec.Forced)
+
+	// the failed signal is treated as busy
+	signalErr = errors.New("connection refused")
+	dec = d.Decide(context.Background(), "update")
+	assert.False(t, dec.Proceed)
+	assert.Equal(t, "busy signal failed: connection refused", dec.Reason)
+
+	// idle resets the deferral
+	busy, signalErr = false, nil
+	dec = d.Decide(context.Background(), "update")
+	assert.True(t, dec.Proceed)
+	assert.False(t, dec.Forced)
+	assert.Nil(t, dec.DeferredSince)
+
+	busy = true
+	now = now.Add(2 * time.Hour)
+	dec = d.Decide(context.Background(), "update")
+	assert.False(t, dec.Proceed)
+	assert.Equal(t, now, dec.DeferredSince.Time)
+}
+
+func TestDeferrerExpireStale(t *testing.T) {
+	d := NewDeferrer(time.Hour, func(ctx context.Context) (bool, string, error) {
+		return true, "busy", nil
+	})
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	d.nowFunc = func() time.Time { return now }
+
+	first := now
+	dec := d.Decide(context.Background(), "remediation/accelerator-nvidia-xid/xid")
+	assert.False(t, dec.Proceed)
+
+	// decided again within the max deferral, thus still deferred since the first
+	now = now.Add(50 * time.Minute)
+	dec = d.Decide(context.Background(), "remediation/accelerator-nvidia-xid/xid")
+	assert.False(t, dec.Proceed)
+	assert.Equal(t, first, dec.DeferredSince.Time)
+
+	// not decided again within the max deferral (e.g., no longer needed),
+	// thus expired, and the same maintenance requested later is deferred anew
+	// rather than forced right away
+	now = now.Add(2 * time.Hour)
+	d.Decide(context.Background(), "update")
+	d.mu.Lock()
+	_, ok := d.deferrals["remediation/accelerator-nvidia-xid/xid"]
+	d.mu.Unlock()
+	assert.False(t, ok)
+
+	dec = d.Decide(context.Background(), "remediation/accelerator-nvidia-xid/xid")
+	assert.False(t, dec.Proceed)
+	assert.False(t, dec.Forced)
+	assert.Equal(t, now, dec.DeferredSince.Time)
+}
+
+func TestNilDeferrer(t *testing.T) {
+	var d *Deferrer
+	assert.Equal(t, Decision{Proceed: true}, d.Decide(context.Background(), "update"))
+}
+
+func TestRunningGPUPods(t *testing.T) {
+	pods, err := runningGPUPods(strings.NewReader(`{
+  "kind": "PodList",
+  "items": [
+    {
+      "metadata": {"namespace": "default", "name": "train-0"},
+      "spec": {"containers": [{"name": "main", "resources": {"limits": {"nvidia.com/gpu": "8"}}}]},
+      "status": {"phase": "Running"}
+    },
+    {
+      "metadata": {"namespace": "default", "name": "train-1"},
+      "spec": {"containers": [{"name": "main", "resources": {"limits": {"nvidia.com/gpu": "8"}}}]},
+      "status": {"phase": "Succeeded"}
+    },
+    {
+      "metadata": {"namespace": "kube-system", "name": "kube-proxy"},
+      "spec": {"containers": [{"name": "kube-proxy", "resources": {"requests": {"cpu": "100m"}}}]},
+      "status": {"phase": "Running"}
+    }
+  ]
+}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default/train-0"}, pods)
+
+	_, err = runningGPUPods(strings.NewReader("{"))
+	assert.Error(t, err)
+}
+
+func TestKubeletSignal(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/pods", r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"items": [{"metadata": {"namespace": "default", "name": "train-0"}, "spec": {"containers": [{"name": "main", "resources": {"limits": {"nvidia.com/gpu": "1"}}}]}, "status": {"phase": "Running"}}]}`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.crt")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600))
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("test-token\n"), 0o600))
+
+	cfg := KubeletConfig{TokenFile: tokenFile, CAFile: caFile}.withDefaults()
+	assert.Equal(t, DefaultKubeletPort, cfg.Port)
+
+	busy, reason, err := kubeletSignal(cfg, srv.URL+"/pods")(context.Background())
+	require.NoError(t, err)
+	assert.True(t, busy)
+	assert.Equal(t, "1 pod(s) running on the GPUs (default/train-0)", reason)
+
+	// the unauthorized token fails the signal with the reason
+	require.NoError(t, os.WriteFile(tokenFile, []byte("other-token"), 0o600))
+	_, _, err = kubeletSignal(cfg, srv.URL+"/pods")(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "kubelet: token not authorized")
+
+	// the missing token fails the signal
+	_, _, err = kubeletSignal(KubeletConfig{TokenFile: filepath.Join(dir, "missing"), CAFile: caFile}, srv.URL+"/pods")(context.Background())
+	assert.ErrorContains(t, err, "kubelet: failed to read token")
+
+	// the invalid CA fails the signal
+	require.NoError(t, os.WriteFile(caFile, []byte("not a certificate"), 0o600))
+	_, _, err = kubeletSignal(cfg, srv.URL+"/pods")(context.Background())
+	assert.ErrorContains(t, err, "kubelet: no certificate found")
+}
+
+func TestHookSignal(t *testing.T) {
+	busy, _, err := HookSignal("exit 0")(context.Background())
+	require.NoError(t, err)
+	assert.False(t, busy)
+
+	busy, reason, err := HookSignal("echo 'job 1234 running'; exit 1")(context.Background())
+	require.NoError(t, err)
+	assert.True(t, busy)
+	assert.Equal(t, "job 1234 running", reason)
+
+	_, _, err = HookSignal("exit 2")(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,174 @@
+package maintenance
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/leptonai/gpud/pkg/toolexec"
+)
+
+const (
+	// DefaultKubeletPort is the default authenticated (HTTPS) kubelet port to list the pods.
+	// The kubelet read-only port is not used, as it is disabled by default
+	// and unauthenticated when enabled.
+	DefaultKubeletPort = 10250
+	// DefaultKubeletTokenFile is the default bearer token file to authenticate to the kubelet.
+	DefaultKubeletTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	// DefaultKubeletCAFile is the default self-signed kubelet serving certificate,
+	// valid for "localhost".
+	DefaultKubeletCAFile = "/var/lib/kubelet/pki/kubelet.crt"
+
+	// defaultHookTimeout is the timeout of the busy hook.
+	defaultHookTimeout = 30 * time.Second
+
+	// gpuResourceName is the extended resource of the NVIDIA device plugin.
+	gpuResourceName = corev1.ResourceName("nvidia.com/gpu")
+)
+
+// KubeletConfig configures the authenticated kubelet API to list the pods.
+type KubeletConfig struct {
+	// Port is the kubelet HTTPS port, zero to use the default.
+	Port int
+	// TokenFile is the bearer token file, empty to use the default.
+	// Read on every request, to pick up the rotated token.
+	TokenFile string
+	// CAFile is the CA file to verify the kubelet serving certificate,
+	// empty to use the default.
+	CAFile string
+}
+
+func (cfg KubeletConfig) withDefaults() KubeletConfig {
+	if cfg.Port == 0 {
+		cfg.Port = DefaultKubeletPort
+	}
+	if cfg.TokenFile == "" {
+		cfg.TokenFile = DefaultKubeletTokenFile
+	}
+	if cfg.CAFile == "" {
+		cfg.CAFile = DefaultKubeletCAFile
+	}
+	return cfg
+}
+
+// KubeletSignal returns the signal that the node is busy while the pods
+// requesting the GPUs are running, listed from the authenticated kubelet API.
+// The errors are prefixed with "kubelet", so that the busy reason of the failed
+// signal tells which signal failed (e.g., the token not authorized).
+func KubeletSignal(cfg KubeletConfig) Signal {
+	cfg = cfg.withDefaults()
+	return kubeletSignal(cfg, fmt.Sprintf("https://localhost:%d/pods", cfg.Port))
+}
+
+func kubeletSignal(cfg KubeletConfig, url string) Signal {
+	return func(ctx context.Context) (bool, string, error) {
+		pods, err := listRunningGPUPods(ctx, cfg, url)
+		if err != nil {
+			return false, "", fmt.Errorf("kubelet: %w", err)
+		}
+		if len(pods) == 0 {
+			return false, "", nil
+		}
+		return true, fmt.Sprintf("%d pod(s) running on the GPUs (%s)", len(pods), strings.Join(pods, ", ")), nil
+	}
+}
+
+func listRunningGPUPods(ctx context.Context, cfg KubeletConfig, url string) ([]string, error) {
+	token, err := os.ReadFile(cfg.TokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token: %w", err)
+	}
+	caPEM, err := os.ReadFile(cfg.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificate found in CA file %q", cfg.CAFile)
+	}
+	cli := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	resp, err := cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, fmt.Errorf("token not authorized to list pods (status code %d)", resp.StatusCode)
+	default:
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return runningGPUPods(resp.Body)
+}
+
+// runningGPUPods returns the "namespace/name" of the running pods requesting the GPUs.
+func runningGPUPods(r io.Reader) ([]string, error) {
+	var podList corev1.PodList
+	if err := json.NewDecoder(r).Decode(&podList); err != nil {
+		return nil, err
+	}
+
+	var pods []string
+	for _, pod := range podList.Items {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		for _, c := range pod.Spec.Containers {
+			if q, ok := c.Resources.Limits[gpuResourceName]; ok && !q.IsZero() {
+				pods = append(pods, pod.Namespace+"/"+pod.Name)
+				break
+			}
+			if q, ok := c.Resources.Requests[gpuResourceName]; ok && !q.IsZero() {
+				pods = append(pods, pod.Namespace+"/"+pod.Name)
+				break
+			}
+		}
+	}
+	return pods, nil
+}
+
+// HookSignal returns the signal of the busy hook command, run with bash.
+// The hook exits 0 if the node is idle, 1 if busy with its output as the reason,
+// and any other exit code is the hook failure.
+func HookSignal(hook string) Signal {
+	return func(ctx context.Context) (bool, string, error) {
+		res, err := toolexec.Run(ctx, []string{"bash", "-c", hook}, toolexec.WithTimeout(defaultHookTimeout))
+		if res != nil {
+			switch res.ExitCode {
+			case 0:
+				return false, "", nil
+			case 1:
+				reason := strings.TrimSpace(string(res.Output))
+				if reason == "" {
+					reason = "busy hook reported busy"
+				}
+				return true, reason, nil
+			}
+		}
+		if err == nil {
+			err = fmt.Errorf("unexpected exit code %d", res.ExitCode)
+		}
+		return false, "", fmt.Errorf("busy hook: %w", err)
+	}
+}
@@ -0,0 +1,143 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// DefaultWatchdogInterval is the default interval to check the memory usage of the process.
+	DefaultWatchdogInterval = 30 * time.Second
+
+	// DefaultWatchdogConsecutive is the default number of the consecutive checks
+	// over the ceiling before the watchdog fires, in order to ignore
+	// the transient spikes (e.g., a large response being marshaled).
+	DefaultWatchdogConsecutive = 3
+)
+
+// Usage is the memory usage of the current process.
+type Usage struct {
+	RSSBytes  uint64
+	HeapBytes uint64
+}
+
+// Watchdog monitors the memory usage (RSS and heap) of the current process
+// against the configured ceilings, and fires once when either is exceeded
+// for the consecutive checks, so that a leaking gpud can restart itself
+// before it hurts the workloads on the same machine.
+type Watchdog struct {
+	rssCeiling  uint64
+	heapCeiling uint64
+	consecutive int
+
+	getRSSFunc  func() (uint64, error)
+	getHeapFunc func() uint64
+
+	onExceeded func(usage Usage, reason string)
+
+	exceeded int
+	fired    bool
+}
+
+// NewWatchdog creates a new memory watchdog with the RSS and heap ceilings in bytes.
+// Zero ceiling disables the respective check.
+// The onExceeded function is called once, with the memory usage and the reason,
+// when the ceiling is exceeded (e.g., to record an event and exit the process).
+func NewWatchdog(rssCeiling uint64, heapCeiling uint64, onExceeded func(usage Usage, reason string)) *Watchdog {
+	return &Watchdog{
+		rssCeiling:  rssCeiling,
+		heapCeiling: heapCeiling,
+		consecutive: DefaultWatchdogConsecutive,
+		getRSSFunc:  GetCurrentProcessRSSInBytes,
+		getHeapFunc: getHeapInUseBytes,
+		onExceeded:  onExceeded,
+	}
+}
+
+func getHeapInUseBytes() uint64 {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return ms.HeapInuse
+}
+
+// Start starts the watchdog in the background until the context is canceled.
+func (w *Watchdog) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultWatchdogInterval
+	}
+
+	log.Logger.Infow("starting memory watchdog", "rssCeiling", humanize.IBytes(w.rssCeiling), "heapCeiling", humanize.IBytes(w.heapCeiling))
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if w.check() {
+				return
+			}
+		}
+	}()
+}
+
+// check checks the memory usage once, and returns true if the watchdog has fired.
+func (w *Watchdog) check() bool {
+	if w.fired {
+		return true
+	}
+
+	usage, reason := w.usage()
+	if reason == "" {
+		w.exceeded = 0
+		return false
+	}
+
+	w.exceeded++
+	log.Logger.Warnw("memory ceiling exceeded", "reason", reason, "consecutive", w.exceeded)
+	if w.exceeded < w.consecutive {
+		return false
+	}
+
+	w.fired = true
+	if w.onExceeded != nil {
+		w.onExceeded(usage, reason)
+	}
+	return true
+}
+
+// usage returns the current memory usage, and the non-empty reason
+// if any of the ceilings is exceeded.
+func (w *Watchdog) usage() (Usage, string) {
+	var usage Usage
+
+	if w.rssCeiling > 0 {
+		rss, err := w.getRSSFunc()
+		if err != nil {
+			log.Logger.Warnw("failed to get process rss", "error", err)
+		} else {
+			usage.RSSBytes = rss
+		}
+	}
+	if w.heapCeiling > 0 {
+		usage.HeapBytes = w.getHeapFunc()
+	}
+
+	if w.rssCeiling > 0 && usage.RSSBytes > w.rssCeiling {
+		return usage, fmt.Sprintf("rss %s exceeds the ceiling %s", humanize.IBytes(usage.RSSBytes), humanize.IBytes(w.rssCeiling))
+	}
+	if w.heapCeiling > 0 && usage.HeapBytes > w.heapCeiling {
+		return usage, fmt.Sprintf("heap %s exceeds the ceiling %s", humanize.IBytes(usage.HeapBytes), humanize.IBytes(w.heapCeiling))
+	}
+	return usage, ""
+}
@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchdog(t *testing.T) {
+	fired := 0
+	var firedReason string
+	w := NewWatchdog(100, 0, func(usage Usage, reason string) {
+		fired++
+		firedReason = reason
+	})
+
+	rss := uint64(50)
+	w.getRSSFunc = func() (uint64, error) { return rss, nil }
+	w.getHeapFunc = func() uint64 { t.Fatal("heap must not be read without the ceiling"); return 0 }
+
+	assert.False(t, w.check())
+
+	// transient spike is ignored
+	rss = 200
+	assert.False(t, w.check())
+	rss = 50
+	assert.False(t, w.check())
+	assert.Equal(t, 0, w.exceeded)
+
+	rss = 200
+	for i := 0; i < DefaultWatchdogConsecutive-1; i++ {
+		assert.False(t, w.check())
+	}
+	assert.True(t, w.check())
+	assert.Equal(t, 1, fired)
+	assert.Contains(t, firedReason, "rss")
+
+	// fires only once
+	assert.True(t, w.check())
+	assert.Equal(t, 1, fired)
+}
+
+func TestWatchdogHeap(t *testing.T) {
+	var firedUsage Usage
+	w := NewWatchdog(0, 100, func(usage Usage, reason string) {
+		firedUsage = usage
+	})
+	w.consecutive = 1
+	w.getRSSFunc = func() (uint64, error) { return 0, errors.New("rss must not be read without the ceiling") }
+	w.getHeapFunc = func() uint64 { return 101 }
+
+	assert.True(t, w.check())
+	assert.Equal(t, uint64(101), firedUsage.HeapBytes)
+}
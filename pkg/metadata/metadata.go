@@ -11,6 +11,7 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 
 	pkgmetricsrecorder "github.com/leptonai/gpud/pkg/metrics/recorder"
+	"github.com/leptonai/gpud/pkg/sqlite"
 )
 
 const (
@@ -20,7 +21,8 @@ const (
 )
 
 // CreateTableMetadata creates the table for the metadata.
-func CreateTableMetadata(ctx context.Context, dbRW *sql.DB) error {
+// The dbRW is either the read-write database or the transaction of the schema migration.
+func CreateTableMetadata(ctx context.Context, dbRW sqlite.Execer) error {
 	_, err := dbRW.ExecContext(ctx, fmt.Sprintf(`
 CREATE TABLE IF NOT EXISTS %s (
 	%s TEXT PRIMARY KEY,
@@ -40,9 +42,53 @@ const (
 	MetadataKeyRegion    = "region"
 	MetadataKeyExtraInfo = "extra_info"
 
+	// MetadataKeyPrivateIPv6 represents the private IPv6 address
+	// of the dual-stack machine.
+	MetadataKeyPrivateIPv6 = "private_ipv6"
+
+	// MetadataKeyLabels represents the node labels assigned by the control plane,
+	// encoded in JSON.
+	MetadataKeyLabels = "labels"
+
 	// MetadataKeyControlPlaneLoginSuccess represents the timestamp in unix seconds
 	// when the control plane login was successful.
 	MetadataKeyControlPlaneLoginSuccess = "control_plane_login_success"
+
+	// MetadataKeyLastDriverOperation represents the last NVIDIA driver
+	// install or upgrade operation, encoded in JSON.
+	MetadataKeyLastDriverOperation = "last_driver_operation"
+
+	// MetadataKeyComponentCapabilities represents the capability report
+	// of the components generated at the startup, encoded in JSON.
+	MetadataKeyComponentCapabilities = "component_capabilities"
+
+	// MetadataKeyThresholds represents the component thresholds
+	// updated via the API, encoded in JSON.
+	MetadataKeyThresholds = "thresholds"
+
+	// MetadataKeyBaseline represents the baseline learning state
+	// of the node, encoded in JSON.
+	MetadataKeyBaseline = "baseline"
+
+	// MetadataKeyDisruptionWindows represents the expected disruption windows
+	// declared by the external schedulers, encoded in JSON.
+	MetadataKeyDisruptionWindows = "disruption_windows"
+
+	// MetadataKeyEscalationStates represents how long each unhealthy state
+	// has been unhealthy and its escalation level, encoded in JSON.
+	MetadataKeyEscalationStates = "escalation_states"
+
+	// MetadataKeyGPUPCIBaseline represents the PCI bus IDs of the GPUs
+	// found on the node and the GPUs fallen off the bus, encoded in JSON.
+	MetadataKeyGPUPCIBaseline = "gpu_pci_baseline"
+
+	// MetadataKeyRemediationLastActions represents the last automatic
+	// repair action time per component and failure class, encoded in JSON.
+	MetadataKeyRemediationLastActions = "remediation_last_actions"
+
+	// MetadataKeyFindings represents the findings reported to the control plane
+	// and their acknowledgments, encoded in JSON.
+	MetadataKeyFindings = "findings"
 )
 
 // SetMetadata sets the value of a metadata entry.
@@ -0,0 +1,137 @@
+// Package archiver archives the metric data points that are older than the
+// retention period into the compressed parquet files, instead of deleting
+// them, preserving the long-term history cheaply.
+package archiver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	"github.com/leptonai/gpud/pkg/parquet"
+	"github.com/leptonai/gpud/pkg/upload"
+)
+
+// DefaultInterval is the default time interval that each archive file covers.
+const DefaultInterval = time.Hour
+
+const fileTimeFormat = "20060102T150405Z"
+
+var ErrEmptyDir = errors.New("archive directory is empty")
+
+var _ pkgmetrics.Archiver = &archiver{}
+
+type archiver struct {
+	dir      string
+	uploader upload.Uploader
+	interval time.Duration
+}
+
+// New creates a new archiver that writes the parquet files into the directory.
+// If the uploader is not nil, each file is uploaded and then removed from the directory.
+// The file is kept in the directory if the upload fails.
+func New(dir string, uploader upload.Uploader) (pkgmetrics.Archiver, error) {
+	if dir == "" {
+		return nil, ErrEmptyDir
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	return &archiver{
+		dir:      dir,
+		uploader: uploader,
+		interval: DefaultInterval,
+	}, nil
+}
+
+func (a *archiver) Interval() time.Duration {
+	return a.interval
+}
+
+// Archive writes the data points into a single parquet file named after
+// the time range of the data points, overwriting the existing file
+// with the same range (e.g., archived but not purged before restart).
+func (a *archiver) Archive(ctx context.Context, ms pkgmetrics.Metrics) error {
+	if len(ms) == 0 {
+		return nil
+	}
+
+	b, err := encode(ms)
+	if err != nil {
+		return err
+	}
+
+	first, last := ms[0].UnixMilliseconds, ms[0].UnixMilliseconds
+	for _, m := range ms[1:] {
+		first = min(first, m.UnixMilliseconds)
+		last = max(last, m.UnixMilliseconds)
+	}
+	name := fmt.Sprintf("metrics_%s_%s.parquet",
+		time.UnixMilli(first).UTC().Format(fileTimeFormat),
+		time.UnixMilli(last).UTC().Format(fileTimeFormat),
+	)
+	file := filepath.Join(a.dir, name)
+
+	// write to the temporary file first, to not leave the partial file
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return fmt.Errorf("failed to write archive file: %w", err)
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		return fmt.Errorf("failed to rename archive file: %w", err)
+	}
+	log.Logger.Infow("archived metrics", "file", file, "rows", len(ms), "size", len(b))
+
+	if a.uploader == nil {
+		return nil
+	}
+	// keep the local file on the upload failure, since the data points
+	// are purged once archived
+	if err := upload.UploadFile(ctx, a.uploader, file, name); err != nil {
+		log.Logger.Warnw("failed to upload archived metrics, keeping the local file", "file", file, "error", err)
+		return nil
+	}
+	log.Logger.Infow("uploaded archived metrics", "destination", a.uploader.Destination(), "key", name)
+	return os.Remove(file)
+}
+
+// encode encodes the data points in parquet, with the labels encoded in JSON.
+func encode(ms pkgmetrics.Metrics) ([]byte, error) {
+	ts := make([]int64, len(ms))
+	components := make([]string, len(ms))
+	names := make([]string, len(ms))
+	labels := make([]string, len(ms))
+	values := make([]float64, len(ms))
+	for i, m := range ms {
+		ts[i] = m.UnixMilliseconds
+		components[i] = m.Component
+		names[i] = m.Name
+		values[i] = m.Value
+		if len(m.Labels) > 0 {
+			lb, err := json.Marshal(m.Labels)
+			if err != nil {
+				return nil, err
+			}
+			labels[i] = string(lb)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := parquet.Write(buf,
+		parquet.TimestampMillisColumn("unix_milliseconds", ts),
+		parquet.StringColumn("component", components),
+		parquet.StringColumn("name", names),
+		parquet.StringColumn("labels", labels),
+		parquet.DoubleColumn("value", values),
+	); err != nil {
+		return nil, fmt.Errorf("failed to encode parquet: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,44 @@
+package archiver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+func TestArchive(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "archive")
+	a, err := New(dir, nil)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultInterval, a.Interval())
+
+	require.NoError(t, a.Archive(context.Background(), nil))
+
+	ts := time.Date(2025, 1, 2, 3, 0, 0, 0, time.UTC)
+	require.NoError(t, a.Archive(context.Background(), pkgmetrics.Metrics{
+		{UnixMilliseconds: ts.Add(time.Minute).UnixMilli(), Component: "cpu", Name: "usage", Value: 1},
+		{UnixMilliseconds: ts.UnixMilli(), Component: "gpu", Name: "temp", Value: 2, Labels: map[string]string{"gpu_id": "GPU-0"}},
+	}))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "metrics_20250102T030000Z_20250102T030100Z.parquet", entries[0].Name())
+
+	b, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	require.NoError(t, err)
+	assert.Equal(t, "PAR1", string(b[:4]))
+	assert.Equal(t, "PAR1", string(b[len(b)-4:]))
+}
+
+func TestNewEmptyDir(t *testing.T) {
+	_, err := New("", nil)
+	require.ErrorIs(t, err, ErrEmptyDir)
+}
@@ -0,0 +1,206 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// DefaultMaxSeriesPerMetric is the default maximum number of the series
+// (i.e., the unique label sets) per metric name.
+const DefaultMaxSeriesPerMetric = 1000
+
+var _ prometheus.Gatherer = &CardinalityGuard{}
+
+// CardinalityGuard is the gatherer that guards the gathered metrics against
+// the runaway label cardinality (e.g., the per-process metrics),
+// to keep the Prometheus endpoint and the metrics store scrape-safe.
+//
+// When a metric has more series than the limit, the label with the most
+// unique values is dropped and the series are aggregated (summed) over it,
+// repeating until the metric is within the limit.
+// The component label is never dropped.
+// The series that still cannot be aggregated (e.g., summaries) are truncated.
+type CardinalityGuard struct {
+	gatherer  prometheus.Gatherer
+	maxSeries int
+
+	mu sync.Mutex
+	// pruned is the last pruned labels per metric name, to only log the changes
+	pruned map[string]string
+}
+
+// NewCardinalityGuard creates a new cardinality guard for the gatherer.
+// Zero or negative max series uses the default.
+func NewCardinalityGuard(gatherer prometheus.Gatherer, maxSeries int) *CardinalityGuard {
+	if maxSeries <= 0 {
+		maxSeries = DefaultMaxSeriesPerMetric
+	}
+	return &CardinalityGuard{
+		gatherer:  gatherer,
+		maxSeries: maxSeries,
+		pruned:    make(map[string]string),
+	}
+}
+
+// Gather implements the prometheus.Gatherer interface.
+func (g *CardinalityGuard) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := g.gatherer.Gather()
+	if err != nil {
+		return mfs, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	seen := make(map[string]struct{}, len(mfs))
+	for _, mf := range mfs {
+		name := mf.GetName()
+		seen[name] = struct{}{}
+
+		before := len(mf.Metric)
+		if before <= g.maxSeries {
+			if _, ok := g.pruned[name]; ok {
+				log.Logger.Infow("metric cardinality back within limit", "metric", name, "series", before)
+				delete(g.pruned, name)
+			}
+			continue
+		}
+
+		dropped := g.prune(mf)
+		desc := strings.Join(dropped, ",")
+		if g.pruned[name] != desc {
+			log.Logger.Warnw("pruned metric with runaway cardinality",
+				"metric", name,
+				"droppedLabels", dropped,
+				"seriesBefore", before,
+				"seriesAfter", len(mf.Metric),
+				"maxSeries", g.maxSeries,
+			)
+			g.pruned[name] = desc
+		}
+	}
+	for name := range g.pruned {
+		if _, ok := seen[name]; !ok {
+			delete(g.pruned, name)
+		}
+	}
+
+	return mfs, nil
+}
+
+// prune prunes the metric family in place, and returns the dropped labels.
+// Returns "truncated" as the last element if the series are truncated.
+func (g *CardinalityGuard) prune(mf *dto.MetricFamily) []string {
+	var dropped []string
+	for len(mf.Metric) > g.maxSeries && mf.GetType() != dto.MetricType_SUMMARY {
+		label := highestCardinalityLabel(mf.Metric)
+		if label == "" {
+			break
+		}
+		mf.Metric = aggregateWithout(mf.GetType(), mf.Metric, label)
+		dropped = append(dropped, label)
+	}
+
+	if len(mf.Metric) > g.maxSeries {
+		mf.Metric = mf.Metric[:g.maxSeries]
+		dropped = append(dropped, "truncated")
+	}
+	return dropped
+}
+
+// highestCardinalityLabel returns the label with the most unique values,
+// or empty if there is no label to drop.
+func highestCardinalityLabel(ms []*dto.Metric) string {
+	values := make(map[string]map[string]struct{})
+	for _, m := range ms {
+		for _, lp := range m.GetLabel() {
+			if lp.GetName() == MetricComponentLabelKey {
+				continue
+			}
+			if _, ok := values[lp.GetName()]; !ok {
+				values[lp.GetName()] = make(map[string]struct{})
+			}
+			values[lp.GetName()][lp.GetValue()] = struct{}{}
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	highest, highestN := "", 0
+	for _, name := range names {
+		if n := len(values[name]); n > highestN {
+			highest, highestN = name, n
+		}
+	}
+	return highest
+}
+
+// aggregateWithout drops the label from the series, and sums the series
+// with the same remaining labels, preserving the first-seen order.
+func aggregateWithout(typ dto.MetricType, ms []*dto.Metric, label string) []*dto.Metric {
+	var keys []string
+	aggregated := make(map[string]*dto.Metric)
+	for _, m := range ms {
+		labels := make([]*dto.LabelPair, 0, len(m.GetLabel()))
+		var sb strings.Builder
+		for _, lp := range m.GetLabel() {
+			if lp.GetName() == label {
+				continue
+			}
+			labels = append(labels, lp)
+			sb.WriteString(lp.GetName())
+			sb.WriteByte('=')
+			sb.WriteString(lp.GetValue())
+			sb.WriteByte(0)
+		}
+		key := sb.String()
+
+		cur, ok := aggregated[key]
+		if !ok {
+			cur = proto.Clone(m).(*dto.Metric)
+			cur.Label = labels
+			aggregated[key] = cur
+			keys = append(keys, key)
+			continue
+		}
+		addMetric(typ, cur, m)
+	}
+
+	out := make([]*dto.Metric, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, aggregated[key])
+	}
+	return out
+}
+
+// addMetric adds the value of the metric to the aggregated metric.
+func addMetric(typ dto.MetricType, dst *dto.Metric, src *dto.Metric) {
+	switch typ {
+	case dto.MetricType_COUNTER:
+		dst.Counter.Value = proto.Float64(dst.GetCounter().GetValue() + src.GetCounter().GetValue())
+	case dto.MetricType_GAUGE:
+		dst.Gauge.Value = proto.Float64(dst.GetGauge().GetValue() + src.GetGauge().GetValue())
+	case dto.MetricType_UNTYPED:
+		dst.Untyped.Value = proto.Float64(dst.GetUntyped().GetValue() + src.GetUntyped().GetValue())
+	case dto.MetricType_HISTOGRAM:
+		dh, sh := dst.GetHistogram(), src.GetHistogram()
+		dh.SampleCount = proto.Uint64(dh.GetSampleCount() + sh.GetSampleCount())
+		dh.SampleSum = proto.Float64(dh.GetSampleSum() + sh.GetSampleSum())
+		for i, b := range dh.GetBucket() {
+			if i < len(sh.GetBucket()) && sh.GetBucket()[i].GetUpperBound() == b.GetUpperBound() {
+				b.CumulativeCount = proto.Uint64(b.GetCumulativeCount() + sh.GetBucket()[i].GetCumulativeCount())
+			}
+		}
+	}
+}
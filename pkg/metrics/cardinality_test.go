@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCardinalityGuard(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	perProcess := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "per_process_memory"}, []string{MetricComponentLabelKey, "gpu", "pid"})
+	small := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "small"}, []string{MetricComponentLabelKey, "gpu"})
+	reg.MustRegister(perProcess, small)
+
+	for gpu := 0; gpu < 2; gpu++ {
+		small.WithLabelValues("test", fmt.Sprint(gpu)).Add(1)
+		for pid := 0; pid < 10; pid++ {
+			perProcess.WithLabelValues("test", fmt.Sprint(gpu), fmt.Sprint(pid)).Set(1)
+		}
+	}
+
+	g := NewCardinalityGuard(reg, 5)
+	mfs, err := g.Gather()
+	require.NoError(t, err)
+	require.Len(t, mfs, 2)
+
+	for _, mf := range mfs {
+		switch mf.GetName() {
+		case "per_process_memory":
+			// "pid" dropped, summed per gpu
+			require.Len(t, mf.Metric, 2)
+			for _, m := range mf.Metric {
+				assert.Len(t, m.GetLabel(), 2)
+				assert.Equal(t, float64(10), m.GetGauge().GetValue())
+			}
+		case "small":
+			assert.Len(t, mf.Metric, 2)
+		}
+	}
+	assert.Equal(t, "pid", g.pruned["per_process_memory"])
+
+	// back within the limit
+	perProcess.Reset()
+	_, err = g.Gather()
+	require.NoError(t, err)
+	assert.Empty(t, g.pruned)
+}
+
+func TestCardinalityGuardTruncate(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	s := prometheus.NewSummaryVec(prometheus.SummaryOpts{Name: "latency"}, []string{"pid"})
+	reg.MustRegister(s)
+	for pid := 0; pid < 10; pid++ {
+		s.WithLabelValues(fmt.Sprint(pid)).Observe(1)
+	}
+
+	g := NewCardinalityGuard(reg, 3)
+	mfs, err := g.Gather()
+	require.NoError(t, err)
+	require.Len(t, mfs, 1)
+	assert.Len(t, mfs[0].Metric, 3)
+	assert.Equal(t, "truncated", g.pruned["latency"])
+}
+
+func TestCardinalityGuardHistogram(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	h := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "durations", Buckets: []float64{1, 10}}, []string{"pid"})
+	reg.MustRegister(h)
+	for pid := 0; pid < 4; pid++ {
+		h.WithLabelValues(fmt.Sprint(pid)).Observe(5)
+	}
+
+	mfs, err := NewCardinalityGuard(reg, 1).Gather()
+	require.NoError(t, err)
+	require.Len(t, mfs[0].Metric, 1)
+	hist := mfs[0].Metric[0].GetHistogram()
+	assert.Equal(t, uint64(4), hist.GetSampleCount())
+	assert.Equal(t, float64(20), hist.GetSampleSum())
+	assert.Equal(t, uint64(0), hist.GetBucket()[0].GetCumulativeCount())
+	assert.Equal(t, uint64(4), hist.GetBucket()[1].GetCumulativeCount())
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Downsample aggregates the data points of each series (component, name, and labels)
+// into the buckets of the step, by averaging the values in each bucket.
+// The timestamp of each downsampled data point is the start of its bucket.
+// The returned metrics are sorted by the timestamp, and returned as is
+// if the step is less than a millisecond.
+func Downsample(ms Metrics, step time.Duration) Metrics {
+	stepMs := step.Milliseconds()
+	if stepMs <= 0 || len(ms) == 0 {
+		return ms
+	}
+
+	type bucketKey struct {
+		series string
+		ts     int64
+	}
+	type bucket struct {
+		m     Metric
+		sum   float64
+		count int
+	}
+
+	buckets := make(map[bucketKey]*bucket)
+	for _, m := range ms {
+		k := bucketKey{
+			series: seriesKey(m),
+			ts:     m.UnixMilliseconds - m.UnixMilliseconds%stepMs,
+		}
+		b, ok := buckets[k]
+		if !ok {
+			b = &bucket{m: m}
+			b.m.UnixMilliseconds = k.ts
+			buckets[k] = b
+		}
+		b.sum += m.Value
+		b.count++
+	}
+
+	downsampled := make(Metrics, 0, len(buckets))
+	for _, b := range buckets {
+		b.m.Value = b.sum / float64(b.count)
+		downsampled = append(downsampled, b.m)
+	}
+	sort.Slice(downsampled, func(i, j int) bool {
+		if downsampled[i].UnixMilliseconds != downsampled[j].UnixMilliseconds {
+			return downsampled[i].UnixMilliseconds < downsampled[j].UnixMilliseconds
+		}
+		return seriesKey(downsampled[i]) < seriesKey(downsampled[j])
+	})
+	return downsampled
+}
+
+// seriesKey returns the unique key of the series, with the labels sorted by the keys.
+func seriesKey(m Metric) string {
+	keys := make([]string, 0, len(m.Labels))
+	for k := range m.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString(m.Component)
+	sb.WriteByte(0)
+	sb.WriteString(m.Name)
+	for _, k := range keys {
+		sb.WriteByte(0)
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(m.Labels[k])
+	}
+	return sb.String()
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownsample(t *testing.T) {
+	base := time.Date(2025, 1, 2, 3, 4, 0, 0, time.UTC).UnixMilli()
+	ms := Metrics{
+		{UnixMilliseconds: base, Component: "c", Name: "temp", Labels: map[string]string{"gpu": "0"}, Value: 10},
+		{UnixMilliseconds: base + 30_000, Component: "c", Name: "temp", Labels: map[string]string{"gpu": "0"}, Value: 20},
+		{UnixMilliseconds: base + 10_000, Component: "c", Name: "temp", Labels: map[string]string{"gpu": "1"}, Value: 50},
+		{UnixMilliseconds: base + 60_000, Component: "c", Name: "temp", Labels: map[string]string{"gpu": "0"}, Value: 30},
+	}
+
+	out := Downsample(ms, time.Minute)
+	require.Len(t, out, 3)
+
+	assert.Equal(t, base, out[0].UnixMilliseconds)
+	assert.Equal(t, "0", out[0].Labels["gpu"])
+	assert.Equal(t, 15.0, out[0].Value)
+
+	assert.Equal(t, base, out[1].UnixMilliseconds)
+	assert.Equal(t, "1", out[1].Labels["gpu"])
+	assert.Equal(t, 50.0, out[1].Value)
+
+	assert.Equal(t, base+60_000, out[2].UnixMilliseconds)
+	assert.Equal(t, 30.0, out[2].Value)
+
+	// no-op for the zero step
+	assert.Equal(t, ms, Downsample(ms, 0))
+	assert.Empty(t, Downsample(nil, time.Minute))
+}
@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ExportFormat is the format of the exported raw data points.
+type ExportFormat string
+
+const (
+	// ExportFormatCSV exports one data point per row,
+	// with the labels encoded in JSON.
+	ExportFormatCSV ExportFormat = "csv"
+	// ExportFormatJSONL exports one JSON-encoded data point per line.
+	ExportFormatJSONL ExportFormat = "jsonl"
+)
+
+// ExportCSVHeader is the header row of the CSV export.
+var ExportCSVHeader = []string{"unix_milliseconds", "component", "name", "value", "labels"}
+
+// ParseExportFormat parses the export format.
+func ParseExportFormat(s string) (ExportFormat, error) {
+	switch ExportFormat(s) {
+	case ExportFormatCSV, ExportFormatJSONL:
+		return ExportFormat(s), nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q (supported: csv, jsonl)", s)
+	}
+}
+
+// ExportWriter encodes the data points one by one in the export format.
+type ExportWriter struct {
+	format ExportFormat
+	csv    *csv.Writer
+	enc    *json.Encoder
+}
+
+// NewExportWriter creates a new export writer, and writes the header if any.
+func NewExportWriter(wr io.Writer, format ExportFormat) (*ExportWriter, error) {
+	w := &ExportWriter{format: format}
+	switch format {
+	case ExportFormatCSV:
+		w.csv = csv.NewWriter(wr)
+		if err := w.csv.Write(ExportCSVHeader); err != nil {
+			return nil, err
+		}
+	case ExportFormatJSONL:
+		w.enc = json.NewEncoder(wr)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+	return w, nil
+}
+
+// Write encodes the data point.
+func (w *ExportWriter) Write(m Metric) error {
+	if w.enc != nil {
+		return w.enc.Encode(m)
+	}
+
+	labels := ""
+	if len(m.Labels) > 0 {
+		lb, err := json.Marshal(m.Labels)
+		if err != nil {
+			return err
+		}
+		labels = string(lb)
+	}
+	return w.csv.Write([]string{
+		strconv.FormatInt(m.UnixMilliseconds, 10),
+		m.Component,
+		m.Name,
+		strconv.FormatFloat(m.Value, 'f', -1, 64),
+		labels,
+	})
+}
+
+// Flush flushes the buffered rows, if any, to the underlying writer.
+func (w *ExportWriter) Flush() error {
+	if w.csv == nil {
+		return nil
+	}
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+// ReadEach calls the function for each data point in the store, streaming them
+// if the store implements StreamReader, otherwise reading all of them first.
+func ReadEach(ctx context.Context, store Store, fn func(Metric) error, opts ...OpOption) error {
+	if sr, ok := store.(StreamReader); ok {
+		return sr.ReadEach(ctx, fn, opts...)
+	}
+
+	ms, err := store.Read(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	for _, m := range ms {
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
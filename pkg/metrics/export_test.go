@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExportFormat(t *testing.T) {
+	f, err := ParseExportFormat("csv")
+	require.NoError(t, err)
+	assert.Equal(t, ExportFormatCSV, f)
+
+	f, err = ParseExportFormat("jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, ExportFormatJSONL, f)
+
+	_, err = ParseExportFormat("parquet")
+	assert.Error(t, err)
+}
+
+func TestExportWriter(t *testing.T) {
+	ms := Metrics{
+		{UnixMilliseconds: 1700000000000, Component: "cpu", Name: "cpu_usage", Value: 12.5},
+		{UnixMilliseconds: 1700000001000, Component: "disk", Name: "disk_used", Value: 3, Labels: map[string]string{"mount_point": "/"}},
+	}
+
+	buf := new(bytes.Buffer)
+	w, err := NewExportWriter(buf, ExportFormatCSV)
+	require.NoError(t, err)
+	for _, m := range ms {
+		require.NoError(t, w.Write(m))
+	}
+	require.NoError(t, w.Flush())
+	assert.Equal(t, `unix_milliseconds,component,name,value,labels
+1700000000000,cpu,cpu_usage,12.5,
+1700000001000,disk,disk_used,3,"{""mount_point"":""/""}"
+`, buf.String())
+
+	buf.Reset()
+	w, err = NewExportWriter(buf, ExportFormatJSONL)
+	require.NoError(t, err)
+	for _, m := range ms {
+		require.NoError(t, w.Write(m))
+	}
+	require.NoError(t, w.Flush())
+	assert.Equal(t, `{"unix_milliseconds":1700000000000,"component":"cpu","name":"cpu_usage","value":12.5}
+{"unix_milliseconds":1700000001000,"component":"disk","name":"disk_used","value":3,"labels":{"mount_point":"/"}}
+`, buf.String())
+
+	_, err = NewExportWriter(buf, "xml")
+	assert.Error(t, err)
+}
+
+type testStore struct {
+	ms Metrics
+}
+
+func (s *testStore) Record(ctx context.Context, ms ...Metric) error { return nil }
+func (s *testStore) Read(ctx context.Context, opts ...OpOption) (Metrics, error) {
+	return s.ms, nil
+}
+func (s *testStore) Purge(ctx context.Context, before time.Time) (int, error) { return 0, nil }
+
+func TestReadEachFallback(t *testing.T) {
+	store := &testStore{ms: Metrics{{Name: "a"}, {Name: "b"}, {Name: "c"}}}
+
+	var names []string
+	require.NoError(t, ReadEach(context.Background(), store, func(m Metric) error {
+		names = append(names, m.Name)
+		return nil
+	}))
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+
+	errStop := errors.New("stop")
+	names = nil
+	err := ReadEach(context.Background(), store, func(m Metric) error {
+		names = append(names, m.Name)
+		return errStop
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, []string{"a"}, names)
+}
@@ -3,8 +3,10 @@ package metrics
 import "time"
 
 type Op struct {
-	Since              time.Time
-	SelectedComponents map[string]struct{}
+	Since               time.Time
+	Before              time.Time
+	SelectedComponents  map[string]struct{}
+	SelectedMetricNames map[string]struct{}
 }
 
 type OpOption func(*Op)
@@ -23,6 +25,13 @@ func WithSince(t time.Time) OpOption {
 	}
 }
 
+// WithBefore sets the exclusive upper bound of the timestamps to be read.
+func WithBefore(t time.Time) OpOption {
+	return func(op *Op) {
+		op.Before = t
+	}
+}
+
 // WithComponents sets the components to be scraped.
 // If no components are provided, all components will be scraped.
 func WithComponents(components ...string) OpOption {
@@ -37,3 +46,18 @@ func WithComponents(components ...string) OpOption {
 		}
 	}
 }
+
+// WithMetricNames sets the metric names to be read.
+// If no metric names are provided, all metrics will be read.
+func WithMetricNames(names ...string) OpOption {
+	return func(op *Op) {
+		if len(names) > 0 && op.SelectedMetricNames == nil {
+			op.SelectedMetricNames = make(map[string]struct{})
+		}
+		for _, name := range names {
+			if name != "" {
+				op.SelectedMetricNames[name] = struct{}{}
+			}
+		}
+	}
+}
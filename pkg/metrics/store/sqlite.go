@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/leptonai/gpud/pkg/log"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
 	pkgmetricsrecorder "github.com/leptonai/gpud/pkg/metrics/recorder"
+	"github.com/leptonai/gpud/pkg/sqlite"
 )
 
 const (
@@ -42,8 +44,6 @@ const (
 	columnMetricValue = "metric_value"
 )
 
-// TODO: drop the old table "gpud_metrics"
-
 // DefaultTableName is the default table name for the metrics.
 var DefaultTableName = fmt.Sprintf("gpud_metrics_%s", schemaVersion)
 
@@ -53,7 +53,10 @@ var (
 	ErrEmptyMetricName    = errors.New("metric name is empty")
 )
 
-var _ pkgmetrics.Store = &sqliteStore{}
+var (
+	_ pkgmetrics.Store        = &sqliteStore{}
+	_ pkgmetrics.StreamReader = &sqliteStore{}
+)
 
 type sqliteStore struct {
 	dbRW  *sql.DB
@@ -80,11 +83,15 @@ func (s *sqliteStore) Read(ctx context.Context, opts ...pkgmetrics.OpOption) (pk
 	return read(ctx, s.dbRO, s.table, opts...)
 }
 
+func (s *sqliteStore) ReadEach(ctx context.Context, fn func(pkgmetrics.Metric) error, opts ...pkgmetrics.OpOption) error {
+	return readEach(ctx, s.dbRO, s.table, fn, opts...)
+}
+
 func (s *sqliteStore) Purge(ctx context.Context, before time.Time) (int, error) {
 	return purge(ctx, s.dbRW, s.table, before)
 }
 
-func CreateTable(ctx context.Context, dbRW *sql.DB, table string) error {
+func CreateTable(ctx context.Context, dbRW sqlite.Execer, table string) error {
 	if table == "" {
 		return ErrEmptyTableName
 	}
@@ -167,25 +174,48 @@ func insert(ctx context.Context, dbRW *sql.DB, table string, ms ...pkgmetrics.Me
 // meaning the first element is the oldest event.
 // It returns nil if no record is found ("database/sql.ErrNoRows").
 func read(ctx context.Context, dbRO *sql.DB, table string, opts ...pkgmetrics.OpOption) (pkgmetrics.Metrics, error) {
+	rows := make(pkgmetrics.Metrics, 0)
+	if err := readEach(ctx, dbRO, table, func(m pkgmetrics.Metric) error {
+		rows = append(rows, m)
+		return nil
+	}, opts...); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// readEach calls the function for each metric data point in the ascending order
+// of unix seconds, without loading all the data points in memory.
+// It stops and returns the error if the function returns an error.
+func readEach(ctx context.Context, dbRO *sql.DB, table string, fn func(pkgmetrics.Metric) error, opts ...pkgmetrics.OpOption) error {
 	op := &pkgmetrics.Op{}
 	if err := op.ApplyOpts(opts); err != nil {
-		return nil, err
+		return err
 	}
 
 	if table == "" {
-		return nil, ErrEmptyTableName
+		return ErrEmptyTableName
 	}
 
 	params := []any{}
 	if !op.Since.IsZero() {
 		params = append(params, op.Since.UnixMilli())
 	}
+	if !op.Before.IsZero() {
+		params = append(params, op.Before.UnixMilli())
+	}
 
 	orderByStatement := fmt.Sprintf("ORDER BY %s ASC;", columnUnixMilliseconds)
 	whereStatement := ""
 	if !op.Since.IsZero() {
 		whereStatement = fmt.Sprintf("%s >= ?", columnUnixMilliseconds)
 	}
+	if !op.Before.IsZero() {
+		if whereStatement != "" {
+			whereStatement += " AND "
+		}
+		whereStatement += fmt.Sprintf("%s < ?", columnUnixMilliseconds)
+	}
 	if len(op.SelectedComponents) > 0 {
 		if whereStatement != "" {
 			whereStatement += " AND "
@@ -198,6 +228,24 @@ func read(ctx context.Context, dbRO *sql.DB, table string, opts ...pkgmetrics.Op
 		}
 		whereStatement += strings.Join(components, ", ") + ")"
 	}
+	if len(op.SelectedMetricNames) > 0 {
+		if whereStatement != "" {
+			whereStatement += " AND "
+		}
+
+		names := make([]string, 0, len(op.SelectedMetricNames))
+		for name := range op.SelectedMetricNames {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		placeholders := make([]string, 0, len(names))
+		for _, name := range names {
+			placeholders = append(placeholders, "?")
+			params = append(params, name)
+		}
+		whereStatement += fmt.Sprintf("%s IN (%s)", columnMetricName, strings.Join(placeholders, ", "))
+	}
 	if whereStatement != "" {
 		whereStatement = fmt.Sprintf("WHERE %s", whereStatement)
 	}
@@ -225,32 +273,30 @@ FROM %s
 	queryRows, err := dbRO.QueryContext(ctx, query, params...)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, nil
+			return nil
 		}
-		return nil, err
+		return err
 	}
 	defer queryRows.Close()
 
-	rows := make(pkgmetrics.Metrics, 0)
 	for queryRows.Next() {
 		m := pkgmetrics.Metric{}
 		var labels sql.NullString
 		if err := queryRows.Scan(&m.UnixMilliseconds, &m.Component, &m.Name, &labels, &m.Value); err != nil {
-			return nil, err
+			return err
 		}
 		if labels.Valid && labels.String != "" {
 			lm := make(map[string]string, 0)
 			if err := json.Unmarshal([]byte(labels.String), &lm); err != nil {
-				return nil, err
+				return err
 			}
 			m.Labels = lm
 		}
-		rows = append(rows, m)
-	}
-	if err := queryRows.Err(); err != nil {
-		return nil, err
+		if err := fn(m); err != nil {
+			return err
+		}
 	}
-	return rows, nil
+	return queryRows.Err()
 }
 
 // purge purges the data for the corresponding component that is older
@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -1056,3 +1057,100 @@ func TestSQLiteReadScanHandling(t *testing.T) {
 	assert.Equal(t, nullMetric.Name, results[0].Name)
 	assert.Empty(t, results[0].Labels)
 }
+
+func TestSQLiteStore_ReadMetricNames(t *testing.T) {
+	dbRW, dbRO, cleanup := pkgsqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	store, err := NewSQLiteStore(ctx, dbRW, dbRO, "test_metrics")
+	require.NoError(t, err)
+
+	now := time.Now()
+	require.NoError(t, store.Record(ctx,
+		pkgmetrics.Metric{UnixMilliseconds: now.Add(-2 * time.Minute).UnixMilli(), Component: "comp1", Name: "temp", Value: 1},
+		pkgmetrics.Metric{UnixMilliseconds: now.Add(-time.Minute).UnixMilli(), Component: "comp1", Name: "power", Value: 2},
+		pkgmetrics.Metric{UnixMilliseconds: now.UnixMilli(), Component: "comp2", Name: "temp", Value: 3},
+	))
+
+	ms, err := store.Read(ctx, pkgmetrics.WithMetricNames("temp"))
+	require.NoError(t, err)
+	require.Len(t, ms, 2)
+	assert.Equal(t, 1.0, ms[0].Value)
+	assert.Equal(t, 3.0, ms[1].Value)
+
+	ms, err = store.Read(ctx, pkgmetrics.WithSince(now.Add(-90*time.Second)), pkgmetrics.WithComponents("comp1"), pkgmetrics.WithMetricNames("temp", "power"))
+	require.NoError(t, err)
+	require.Len(t, ms, 1)
+	assert.Equal(t, "power", ms[0].Name)
+
+	ms, err = store.Read(ctx, pkgmetrics.WithMetricNames("nonexistent"))
+	require.NoError(t, err)
+	assert.Empty(t, ms)
+}
+
+func TestSQLiteStore_ReadBefore(t *testing.T) {
+	dbRW, dbRO, cleanup := pkgsqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	store, err := NewSQLiteStore(ctx, dbRW, dbRO, "test_metrics")
+	require.NoError(t, err)
+
+	now := time.Now()
+	require.NoError(t, store.Record(ctx,
+		pkgmetrics.Metric{UnixMilliseconds: now.Add(-2 * time.Minute).UnixMilli(), Component: "comp1", Name: "temp", Value: 1},
+		pkgmetrics.Metric{UnixMilliseconds: now.Add(-time.Minute).UnixMilli(), Component: "comp1", Name: "temp", Value: 2},
+		pkgmetrics.Metric{UnixMilliseconds: now.UnixMilli(), Component: "comp1", Name: "temp", Value: 3},
+	))
+
+	ms, err := store.Read(ctx, pkgmetrics.WithBefore(now.Add(-time.Minute)))
+	require.NoError(t, err)
+	require.Len(t, ms, 1)
+	assert.Equal(t, 1.0, ms[0].Value)
+
+	ms, err = store.Read(ctx, pkgmetrics.WithSince(now.Add(-90*time.Second)), pkgmetrics.WithBefore(now), pkgmetrics.WithMetricNames("temp"))
+	require.NoError(t, err)
+	require.Len(t, ms, 1)
+	assert.Equal(t, 2.0, ms[0].Value)
+}
+
+func TestSQLiteStore_ReadEach(t *testing.T) {
+	dbRW, dbRO, cleanup := pkgsqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	store, err := NewSQLiteStore(ctx, dbRW, dbRO, "test_metrics")
+	require.NoError(t, err)
+
+	now := time.Now()
+	for i := 3; i > 0; i-- {
+		require.NoError(t, store.Record(ctx, pkgmetrics.Metric{
+			UnixMilliseconds: now.Add(-time.Duration(i) * time.Minute).UnixMilli(),
+			Component:        "component1",
+			Name:             "metric1",
+			Value:            float64(i),
+			Labels:           map[string]string{"gpu_id": "0"},
+		}))
+	}
+
+	sr, ok := store.(pkgmetrics.StreamReader)
+	require.True(t, ok)
+
+	var values []float64
+	require.NoError(t, sr.ReadEach(ctx, func(m pkgmetrics.Metric) error {
+		assert.Equal(t, "0", m.Labels["gpu_id"])
+		values = append(values, m.Value)
+		return nil
+	}, pkgmetrics.WithSince(now.Add(-150*time.Second))))
+	assert.Equal(t, []float64{2, 1}, values)
+
+	errStop := errors.New("stop")
+	values = nil
+	err = sr.ReadEach(ctx, func(m pkgmetrics.Metric) error {
+		values = append(values, m.Value)
+		return errStop
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, []float64{3}, values)
+}
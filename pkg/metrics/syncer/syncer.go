@@ -17,6 +17,7 @@ type Syncer struct {
 	scrapeInterval time.Duration
 	purgeInterval  time.Duration
 	retainDuration time.Duration
+	archiver       pkgmetrics.Archiver
 }
 
 func NewSyncer(ctx context.Context, scraper pkgmetrics.Scraper, store pkgmetrics.Store, scrapeInterval time.Duration, purgeInterval time.Duration, retainDuration time.Duration) *Syncer {
@@ -33,6 +34,12 @@ func NewSyncer(ctx context.Context, scraper pkgmetrics.Scraper, store pkgmetrics
 	return s
 }
 
+// SetArchiver sets the archiver to archive the data points before they are purged.
+// Must be called before Start.
+func (s *Syncer) SetArchiver(archiver pkgmetrics.Archiver) {
+	s.archiver = archiver
+}
+
 func (s *Syncer) Start() {
 	go func() {
 		ticker := time.NewTicker(s.scrapeInterval)
@@ -64,6 +71,14 @@ func (s *Syncer) Start() {
 			}
 
 			before := time.Now().UTC().Add(-s.retainDuration)
+			if s.archiver != nil {
+				before = before.Truncate(s.archiver.Interval())
+				if err := s.archive(before); err != nil {
+					// do not purge the data points that are not archived
+					log.Logger.Errorw("failed to archive metrics", "error", err)
+					continue
+				}
+			}
 			if purged, err := s.store.Purge(s.ctx, before); err != nil {
 				log.Logger.Errorw("failed to purge metrics", "error", err)
 			} else {
@@ -81,6 +96,14 @@ func (s *Syncer) sync() error {
 	return s.store.Record(s.ctx, ms...)
 }
 
+func (s *Syncer) archive(before time.Time) error {
+	ms, err := s.store.Read(s.ctx, pkgmetrics.WithBefore(before))
+	if err != nil {
+		return err
+	}
+	return s.archiver.Archive(s.ctx, ms)
+}
+
 func (s *Syncer) Stop() {
 	log.Logger.Infow("stopping syncer")
 
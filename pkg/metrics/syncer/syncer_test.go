@@ -90,6 +90,9 @@ func (m *mockStore) Read(ctx context.Context, opts ...pkgmetrics.OpOption) (pkgm
 
 	result := make(pkgmetrics.Metrics, 0)
 	for _, metric := range m.records {
+		if !op.Before.IsZero() && metric.UnixMilliseconds >= op.Before.UnixMilli() {
+			continue
+		}
 		if metric.UnixMilliseconds >= op.Since.UnixMilli() {
 			result = append(result, metric)
 		}
@@ -273,3 +276,41 @@ func TestSyncerWithErrors(t *testing.T) {
 		s.Stop()
 	})
 }
+
+type mockArchiver struct {
+	archived pkgmetrics.Metrics
+	err      error
+}
+
+func (m *mockArchiver) Interval() time.Duration {
+	return time.Hour
+}
+
+func (m *mockArchiver) Archive(ctx context.Context, ms pkgmetrics.Metrics) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.archived = append(m.archived, ms...)
+	return nil
+}
+
+func TestSyncerArchive(t *testing.T) {
+	now := time.Now()
+	store := newMockStore(nil, nil, nil)
+	require.NoError(t, store.Record(context.Background(),
+		pkgmetrics.Metric{UnixMilliseconds: now.Add(-2 * time.Hour).UnixMilli(), Name: "old"},
+		pkgmetrics.Metric{UnixMilliseconds: now.UnixMilli(), Name: "new"},
+	))
+
+	s := NewSyncer(context.Background(), newMockScraper(nil, nil), store, time.Minute, time.Minute, time.Hour)
+	defer s.Stop()
+
+	archiver := &mockArchiver{}
+	s.SetArchiver(archiver)
+	require.NoError(t, s.archive(now.Add(-time.Hour)))
+	require.Len(t, archiver.archived, 1)
+	require.Equal(t, "old", archiver.archived[0].Name)
+
+	archiver.err = errors.New("archive error")
+	require.Error(t, s.archive(now.Add(-time.Hour)))
+}
@@ -49,3 +49,23 @@ type Store interface {
 	// Purge purges the metrics data points before the given time.
 	Purge(ctx context.Context, before time.Time) (int, error)
 }
+
+// StreamReader is optionally implemented by the stores that read
+// the data points one by one, without loading all of them in memory
+// (e.g., to export the raw data points in bulk).
+type StreamReader interface {
+	// ReadEach calls the function for each data point in the ascending order
+	// of the timestamps, and stops if the function returns an error.
+	ReadEach(ctx context.Context, fn func(Metric) error, opts ...OpOption) error
+}
+
+// Archiver defines the interface to archive the metrics data points
+// before they are purged from the store.
+type Archiver interface {
+	// Interval returns the time interval that each archive covers.
+	// The purge is aligned to the interval, so that each archive
+	// covers the same time range.
+	Interval() time.Duration
+	// Archive archives the data points.
+	Archive(ctx context.Context, ms Metrics) error
+}
@@ -0,0 +1,95 @@
+// Package migrations defines the versioned schema migrations of the gpud state file,
+// applied in order at startup.
+//
+// Only append to the list. Never edit or reorder the released migrations,
+// since the state file records the last applied version.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	pkgmetricsstore "github.com/leptonai/gpud/pkg/metrics/store"
+	pkgprobecache "github.com/leptonai/gpud/pkg/probecache"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+// deprecatedTablePatterns are the GLOB patterns of the tables
+// no longer used since the schema changes.
+var deprecatedTablePatterns = []string{
+	// events tables before "v0_5_0"
+	"components_*_events_v0_4_0",
+	// metrics table before "v0_5"
+	"gpud_metrics",
+}
+
+// Migrations is the list of the schema migrations in order.
+var Migrations = []sqlite.Migration{
+	{
+		Version:     1,
+		Description: "create metadata table",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			return pkgmetadata.CreateTableMetadata(ctx, tx)
+		},
+	},
+	{
+		Version:     2,
+		Description: "create metrics table",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			return pkgmetricsstore.CreateTable(ctx, tx, pkgmetricsstore.DefaultTableName)
+		},
+	},
+	{
+		Version:     3,
+		Description: "drop deprecated events and metrics tables",
+		Up:          dropDeprecatedTables,
+	},
+	{
+		Version:     4,
+		Description: "create probe cache table",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			return pkgprobecache.CreateTable(ctx, tx)
+		},
+	},
+}
+
+// LatestVersion returns the schema version after all the migrations are applied.
+func LatestVersion() int {
+	return sqlite.LatestSchemaVersion(Migrations)
+}
+
+// Apply applies the pending migrations to the state file,
+// and returns the schema versions before and after the migrations.
+func Apply(ctx context.Context, dbRW *sql.DB) (int, int, error) {
+	return sqlite.Migrate(ctx, dbRW, Migrations)
+}
+
+func dropDeprecatedTables(ctx context.Context, tx *sql.Tx) error {
+	tables := make([]string, 0)
+	for _, pattern := range deprecatedTablePatterns {
+		rows, err := tx.QueryContext(ctx, "SELECT name FROM sqlite_master WHERE type='table' AND name GLOB ?", pattern)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				_ = rows.Close()
+				return err
+			}
+			tables = append(tables, name)
+		}
+		if err := rows.Close(); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range tables {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %q;", name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
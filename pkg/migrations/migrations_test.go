@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgmetricsstore "github.com/leptonai/gpud/pkg/metrics/store"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestApply(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	for _, table := range []string{"components_cpu_events_v0_4_0", "components_cpu_events_v0_5_0", "gpud_metrics"} {
+		_, err := dbRW.ExecContext(ctx, "CREATE TABLE "+table+" (id INTEGER PRIMARY KEY)")
+		require.NoError(t, err)
+	}
+
+	from, to, err := Apply(ctx, dbRW)
+	require.NoError(t, err)
+	assert.Equal(t, 0, from)
+	assert.Equal(t, LatestVersion(), to)
+
+	for table, expected := range map[string]bool{
+		"gpud_metadata":                  true,
+		pkgmetricsstore.DefaultTableName: true,
+		"components_cpu_events_v0_5_0":   true,
+		"components_cpu_events_v0_4_0":   false,
+		"gpud_metrics":                   false,
+	} {
+		exists, err := sqlite.TableExists(ctx, dbRO, table)
+		require.NoError(t, err)
+		assert.Equal(t, expected, exists, table)
+	}
+
+	from, to, err = Apply(ctx, dbRW)
+	require.NoError(t, err)
+	assert.Equal(t, LatestVersion(), from)
+	assert.Equal(t, LatestVersion(), to)
+}
@@ -0,0 +1,118 @@
+package nccltest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	pkgpeermesh "github.com/leptonai/gpud/pkg/peer-mesh"
+	"github.com/leptonai/gpud/pkg/toolexec"
+	"github.com/leptonai/gpud/pkg/toolpath"
+)
+
+// ErrNoBusBandwidth is returned if the test output has no average bus bandwidth.
+var ErrNoBusBandwidth = errors.New("no average bus bandwidth in the nccl test output")
+
+// RunLink runs "all_reduce_perf" of the nccl-tests across the two nodes with "mpirun",
+// launched over ssh from the leader, with the same "all_reduce_perf" path on all the nodes.
+func RunLink(ctx context.Context, link Link, req Request) (float64, error) {
+	if !isValidMessageSize(req.MessageSize) {
+		return 0, fmt.Errorf("%w %q", ErrInvalidMessageSize, req.MessageSize)
+	}
+	for _, p := range []pkgpeermesh.Peer{link.A, link.B} {
+		if host := hostOf(p); !isValidHost(host) {
+			return 0, fmt.Errorf("%w %q of peer %q", ErrInvalidPeerHost, host, p.ID)
+		}
+	}
+	allReducePerf, err := toolpath.Locate("all_reduce_perf")
+	if err != nil {
+		return 0, fmt.Errorf("all_reduce_perf not found: %w", err)
+	}
+	mpirun, err := toolpath.Locate("mpirun")
+	if err != nil {
+		return 0, fmt.Errorf("mpirun not found: %w", err)
+	}
+
+	timeout := DefaultLinkTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	envs := mpirunEnvs(os.Environ())
+	res, err := toolexec.Run(ctx, mpirunCommand(mpirun, allReducePerf, link, req, envs), toolexec.WithTimeout(timeout), toolexec.WithEnvs(envs...))
+	if err != nil {
+		return 0, err
+	}
+	return parseBusBandwidth(string(res.Output))
+}
+
+// mpirunEnvs returns the environment variables of gpud passed to "mpirun",
+// which are scrubbed by the toolexec otherwise: the ssh agent socket to launch
+// the processes on the remote node, and the NCCL settings (e.g., "NCCL_IB_HCA").
+func mpirunEnvs(environ []string) []string {
+	var envs []string
+	for _, env := range environ {
+		k, _, _ := strings.Cut(env, "=")
+		if k == "SSH_AUTH_SOCK" || strings.HasPrefix(k, "NCCL_") {
+			envs = append(envs, env)
+		}
+	}
+	return envs
+}
+
+// mpirunCommand returns the "mpirun" command, forwarding the NCCL settings
+// of the environment variables to the processes on both nodes.
+func mpirunCommand(mpirun string, allReducePerf string, link Link, req Request, envs []string) []string {
+	cmd := []string{
+		mpirun,
+		"--allow-run-as-root",
+		"-np", strconv.Itoa(2 * req.GPUsPerNode),
+		"-H", fmt.Sprintf("%s:%d,%s:%d", hostOf(link.A), req.GPUsPerNode, hostOf(link.B), req.GPUsPerNode),
+		"--bind-to", "none",
+	}
+
+	ncclDebug := false
+	for _, env := range envs {
+		k, _, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(k, "NCCL_") {
+			continue
+		}
+		ncclDebug = ncclDebug || k == "NCCL_DEBUG"
+		cmd = append(cmd, "-x", k)
+	}
+	if !ncclDebug {
+		cmd = append(cmd, "-x", "NCCL_DEBUG=WARN")
+	}
+
+	return append(cmd,
+		allReducePerf,
+		"-b", req.MessageSize,
+		"-e", req.MessageSize,
+		"-g", "1",
+	)
+}
+
+// e.g., "1G", "256M", "8K", "1048576"
+var messageSizeRegex = regexp.MustCompile(`^[1-9][0-9]*[KkMmGg]?$`)
+
+// isValidMessageSize returns true if the message size is in the nccl-tests size syntax,
+// the number of bytes with the optional "K", "M", or "G" suffix.
+func isValidMessageSize(s string) bool {
+	return messageSizeRegex.MatchString(s)
+}
+
+// e.g., "# Avg bus bandwidth    : 187.351"
+var busBandwidthRegex = regexp.MustCompile(`#\s*Avg bus bandwidth\s*:\s*([0-9.]+)`)
+
+// parseBusBandwidth parses the average bus bandwidth in GB/s from the nccl-tests output.
+func parseBusBandwidth(out string) (float64, error) {
+	m := busBandwidthRegex.FindStringSubmatch(out)
+	if len(m) != 2 {
+		return 0, ErrNoBusBandwidth
+	}
+	return strconv.ParseFloat(m[1], 64)
+}
@@ -0,0 +1,412 @@
+// Package nccltest orchestrates the inter-node NCCL bandwidth tests from a leader
+// gpud over the peer nodes (pairwise or in a ring), and localizes the fabric issues
+// (e.g., a bad node or a bad link) that the single-node infiniband checks cannot see.
+package nccltest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/leptonai/gpud/pkg/log"
+	pkgpeermesh "github.com/leptonai/gpud/pkg/peer-mesh"
+)
+
+// Mode is the topology of the links to test.
+type Mode string
+
+const (
+	// ModePairwise tests every pair of the nodes, n*(n-1)/2 links in total.
+	ModePairwise Mode = "pairwise"
+	// ModeRing tests each node with the next node in the ring, n links in total.
+	ModeRing Mode = "ring"
+)
+
+const (
+	// DefaultGPUsPerNode is the default number of the GPUs to test per node.
+	DefaultGPUsPerNode = 8
+	// DefaultMessageSize is the default message size of the all-reduce.
+	DefaultMessageSize = "1G"
+	// DefaultLinkTimeout is the default timeout to test each link.
+	DefaultLinkTimeout = 5 * time.Minute
+)
+
+var (
+	ErrTooFewPeers    = errors.New("at least 2 peers are required")
+	ErrUnknownMode    = errors.New("unknown mode")
+	ErrAlreadyRunning = errors.New("nccl test already running")
+
+	ErrInvalidMessageSize = errors.New("invalid message size (expected e.g., \"1G\", \"256M\", \"8K\")")
+	ErrInvalidPeerHost    = errors.New("invalid peer host (expected an IPv4 address or a hostname)")
+	ErrPeerNotInMesh      = errors.New("peer not in the configured peer mesh")
+)
+
+// Request is the request to test the links between the peers.
+type Request struct {
+	// Mode is the topology of the links to test, defaults to the ring.
+	Mode Mode `json:"mode,omitempty"`
+	// Peers are the nodes to test, with the gpud address of each node
+	// whose host is used to launch the test processes.
+	Peers pkgpeermesh.Peers `json:"peers,omitempty"`
+	// GPUsPerNode is the number of the GPUs to test per node.
+	GPUsPerNode int `json:"gpus_per_node,omitempty"`
+	// MessageSize is the message size of the all-reduce (e.g., "1G").
+	MessageSize string `json:"message_size,omitempty"`
+	// MinBusBandwidthGBps is the bus bandwidth in GB/s below which the link is degraded.
+	// Zero to only consider the failed tests as degraded.
+	MinBusBandwidthGBps float64 `json:"min_bus_bandwidth_gbps,omitempty"`
+	// LinkTimeoutSeconds is the timeout to test each link.
+	LinkTimeoutSeconds int `json:"link_timeout_seconds,omitempty"`
+}
+
+// withDefaults returns the request with the defaults for the unset fields.
+func (r Request) withDefaults() Request {
+	if r.Mode == "" {
+		r.Mode = ModeRing
+	}
+	if r.GPUsPerNode == 0 {
+		r.GPUsPerNode = DefaultGPUsPerNode
+	}
+	if r.MessageSize == "" {
+		r.MessageSize = DefaultMessageSize
+	}
+	if r.LinkTimeoutSeconds == 0 {
+		r.LinkTimeoutSeconds = int(DefaultLinkTimeout.Seconds())
+	}
+	return r
+}
+
+// Validate validates the request.
+func (r Request) Validate() error {
+	switch r.Mode {
+	case "", ModePairwise, ModeRing:
+	default:
+		return fmt.Errorf("%w %q", ErrUnknownMode, r.Mode)
+	}
+	if len(r.Peers) < 2 {
+		return ErrTooFewPeers
+	}
+	if err := r.Peers.Validate(); err != nil {
+		return err
+	}
+	for _, p := range r.Peers {
+		// the host is passed to "mpirun -H" in the comma-separated host list
+		if host := hostOf(p); !isValidHost(host) {
+			return fmt.Errorf("%w %q of peer %q", ErrInvalidPeerHost, host, p.ID)
+		}
+	}
+	if r.GPUsPerNode < 0 {
+		return fmt.Errorf("invalid gpus per node %d", r.GPUsPerNode)
+	}
+	if r.MessageSize != "" && !isValidMessageSize(r.MessageSize) {
+		return fmt.Errorf("%w %q", ErrInvalidMessageSize, r.MessageSize)
+	}
+	if r.MinBusBandwidthGBps < 0 {
+		return fmt.Errorf("invalid min bus bandwidth %f", r.MinBusBandwidthGBps)
+	}
+	if r.LinkTimeoutSeconds < 0 {
+		return fmt.Errorf("invalid link timeout %d", r.LinkTimeoutSeconds)
+	}
+	return nil
+}
+
+// Link is the pair of the nodes to test.
+type Link struct {
+	A pkgpeermesh.Peer `json:"a"`
+	B pkgpeermesh.Peer `json:"b"`
+}
+
+func (l Link) String() string {
+	return l.A.ID + "<->" + l.B.ID
+}
+
+// Plan returns the links to test between the peers in the mode.
+func Plan(peers pkgpeermesh.Peers, mode Mode) ([]Link, error) {
+	if len(peers) < 2 {
+		return nil, ErrTooFewPeers
+	}
+
+	var links []Link
+	switch mode {
+	case ModePairwise:
+		for i := 0; i < len(peers); i++ {
+			for j := i + 1; j < len(peers); j++ {
+				links = append(links, Link{A: peers[i], B: peers[j]})
+			}
+		}
+
+	case ModeRing:
+		n := len(peers)
+		if n == 2 {
+			// only one link between two nodes
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			links = append(links, Link{A: peers[i], B: peers[(i+1)%len(peers)]})
+		}
+
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownMode, mode)
+	}
+	return links, nil
+}
+
+// LinkResult is the test result of a link.
+type LinkResult struct {
+	Link
+
+	// BusBandwidthGBps is the average bus bandwidth of the all-reduce in GB/s.
+	BusBandwidthGBps float64 `json:"bus_bandwidth_gbps"`
+	// Degraded is true if the test failed or the bandwidth is below the threshold.
+	Degraded bool `json:"degraded"`
+	// Error is the test error, if any.
+	Error string `json:"error,omitempty"`
+
+	Took metav1.Duration `json:"took"`
+}
+
+// Result is the test result of all the links.
+type Result struct {
+	Request Request `json:"request"`
+
+	StartedAt metav1.Time `json:"started_at"`
+	// FinishedAt is nil while the test is running.
+	FinishedAt *metav1.Time `json:"finished_at,omitempty"`
+
+	// Links are the links tested so far, in the planned order.
+	Links []LinkResult `json:"links,omitempty"`
+	// PendingLinks is the number of the links not tested yet.
+	PendingLinks int `json:"pending_links"`
+
+	// SuspectNodes are the nodes with all of their links degraded,
+	// which likely indicates the node-level issue (e.g., the NIC, the cable to the leaf switch).
+	SuspectNodes []string `json:"suspect_nodes,omitempty"`
+	// SuspectLinks are the degraded links not explained by the suspect nodes,
+	// which likely indicates the issue on the path between the two nodes.
+	SuspectLinks []string `json:"suspect_links,omitempty"`
+	// FabricWide is true if all the links are degraded,
+	// which likely indicates the fabric-wide issue rather than any node.
+	FabricWide bool `json:"fabric_wide"`
+}
+
+// Localize localizes the degraded links into the suspect nodes and links.
+// A node is suspected only if it has more than one link tested and all degraded,
+// since a single degraded link cannot tell which end is at fault.
+func Localize(links []LinkResult) (suspectNodes []string, suspectLinks []string, fabricWide bool) {
+	if len(links) == 0 {
+		return nil, nil, false
+	}
+
+	total := make(map[string]int)
+	degraded := make(map[string]int)
+	degradedLinks := 0
+	for _, l := range links {
+		total[l.A.ID]++
+		total[l.B.ID]++
+		if l.Degraded {
+			degraded[l.A.ID]++
+			degraded[l.B.ID]++
+			degradedLinks++
+		}
+	}
+	if degradedLinks == len(links) && len(links) > 1 {
+		return nil, nil, true
+	}
+
+	suspects := make(map[string]struct{})
+	for id, n := range total {
+		if n > 1 && degraded[id] == n {
+			suspects[id] = struct{}{}
+			suspectNodes = append(suspectNodes, id)
+		}
+	}
+	sort.Strings(suspectNodes)
+
+	for _, l := range links {
+		if !l.Degraded {
+			continue
+		}
+		_, a := suspects[l.A.ID]
+		_, b := suspects[l.B.ID]
+		if a || b {
+			continue
+		}
+		suspectLinks = append(suspectLinks, l.String())
+	}
+	sort.Strings(suspectLinks)
+
+	return suspectNodes, suspectLinks, false
+}
+
+// RunLinkFunc runs the bandwidth test between the two nodes,
+// and returns the average bus bandwidth in GB/s.
+type RunLinkFunc func(ctx context.Context, link Link, req Request) (float64, error)
+
+// Orchestrator runs one test at a time as the leader of the peers,
+// and keeps the result of the last test.
+type Orchestrator struct {
+	rootCtx context.Context
+	// meshPeersFunc returns the configured peer mesh peers, the only peers to test
+	meshPeersFunc func() pkgpeermesh.Peers
+	runLinkFunc   RunLinkFunc
+
+	mu      sync.RWMutex
+	running bool
+	last    *Result
+}
+
+// NewOrchestrator creates the orchestrator to test the links between the peers
+// of the configured peer mesh returned by the function, with the link test function,
+// or with "mpirun" and "all_reduce_perf" of the nccl-tests if nil.
+func NewOrchestrator(ctx context.Context, meshPeersFunc func() pkgpeermesh.Peers, runLinkFunc RunLinkFunc) *Orchestrator {
+	if runLinkFunc == nil {
+		runLinkFunc = RunLink
+	}
+	return &Orchestrator{
+		rootCtx:       ctx,
+		meshPeersFunc: meshPeersFunc,
+		runLinkFunc:   runLinkFunc,
+	}
+}
+
+// Start starts testing the links in the background, one link at a time
+// not to skew the bandwidth of the nodes in more than one link,
+// and returns ErrAlreadyRunning if the previous test has not finished.
+// The request without the peers tests the peer mesh peers, and the request
+// with any peer not in the peer mesh is rejected with ErrPeerNotInMesh,
+// not to launch the processes on the arbitrary hosts over ssh.
+func (o *Orchestrator) Start(req Request) error {
+	var mesh pkgpeermesh.Peers
+	if o.meshPeersFunc != nil {
+		mesh = o.meshPeersFunc()
+	}
+	if len(req.Peers) == 0 {
+		req.Peers = mesh
+	} else if err := inMesh(req.Peers, mesh); err != nil {
+		return err
+	}
+
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	req = req.withDefaults()
+
+	links, err := Plan(req.Peers, req.Mode)
+	if err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	if o.running {
+		o.mu.Unlock()
+		return ErrAlreadyRunning
+	}
+	o.running = true
+	o.last = &Result{
+		Request:      req,
+		StartedAt:    metav1.NewTime(time.Now().UTC()),
+		PendingLinks: len(links),
+	}
+	o.mu.Unlock()
+
+	log.Logger.Infow("starting nccl test", "mode", req.Mode, "peers", len(req.Peers), "links", len(links))
+	go o.run(req, links)
+	return nil
+}
+
+// inMesh returns ErrPeerNotInMesh if any of the peers is not in the mesh
+// with the same id and address.
+func inMesh(peers pkgpeermesh.Peers, mesh pkgpeermesh.Peers) error {
+	for _, p := range peers {
+		found := false
+		for _, m := range mesh {
+			if p.ID == m.ID && p.Address == m.Address {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: %q (%s)", ErrPeerNotInMesh, p.ID, p.Address)
+		}
+	}
+	return nil
+}
+
+func (o *Orchestrator) run(req Request, links []Link) {
+	timeout := time.Duration(req.LinkTimeoutSeconds) * time.Second
+	for _, link := range links {
+		start := time.Now()
+		cctx, cancel := context.WithTimeout(o.rootCtx, timeout)
+		bw, err := o.runLinkFunc(cctx, link, req)
+		cancel()
+
+		lr := LinkResult{
+			Link:             link,
+			BusBandwidthGBps: bw,
+			Took:             metav1.Duration{Duration: time.Since(start)},
+		}
+		if err != nil {
+			lr.Error = err.Error()
+			lr.Degraded = true
+		} else if req.MinBusBandwidthGBps > 0 && bw < req.MinBusBandwidthGBps {
+			lr.Degraded = true
+		}
+		log.Logger.Infow("tested nccl link", "link", link.String(), "bus_bandwidth_gbps", bw, "degraded", lr.Degraded, "error", err)
+
+		o.mu.Lock()
+		o.last.Links = append(o.last.Links, lr)
+		o.last.PendingLinks--
+		o.last.SuspectNodes, o.last.SuspectLinks, o.last.FabricWide = Localize(o.last.Links)
+		o.mu.Unlock()
+	}
+
+	now := metav1.NewTime(time.Now().UTC())
+	o.mu.Lock()
+	o.last.FinishedAt = &now
+	o.running = false
+	suspectNodes, suspectLinks := o.last.SuspectNodes, o.last.SuspectLinks
+	o.mu.Unlock()
+
+	log.Logger.Infow("finished nccl test", "links", len(links), "suspect_nodes", suspectNodes, "suspect_links", suspectLinks)
+}
+
+// Last returns the result of the last (or the running) test, nil if none started.
+func (o *Orchestrator) Last() *Result {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	if o.last == nil {
+		return nil
+	}
+	copied := *o.last
+	copied.Links = append([]LinkResult(nil), o.last.Links...)
+	return &copied
+}
+
+// hostOf returns the host of the peer address to launch the test processes on.
+func hostOf(p pkgpeermesh.Peer) string {
+	host, _, err := net.SplitHostPort(p.Address)
+	if err != nil {
+		return p.Address
+	}
+	return host
+}
+
+// e.g., "node-1", "node-1.cluster.local"
+var hostnameRegex = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?)*$`)
+
+// isValidHost returns true if the host is an IPv4 address or a hostname,
+// never with the separators of the "mpirun -H" host list (e.g., "," and ":").
+func isValidHost(host string) bool {
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.To4() != nil
+	}
+	return len(host) <= 253 && hostnameRegex.MatchString(host)
+}
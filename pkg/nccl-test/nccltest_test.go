@@ -0,0 +1,222 @@
+package nccltest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgpeermesh "github.com/leptonai/gpud/pkg/peer-mesh"
+)
+
+func testPeers(ids ...string) pkgpeermesh.Peers {
+	peers := make(pkgpeermesh.Peers, 0, len(ids))
+	for _, id := range ids {
+		peers = append(peers, pkgpeermesh.Peer{ID: id, Address: id + ":15132"})
+	}
+	return peers
+}
+
+func linkStrings(links []Link) []string {
+	ss := make([]string, 0, len(links))
+	for _, l := range links {
+		ss = append(ss, l.String())
+	}
+	return ss
+}
+
+func TestPlan(t *testing.T) {
+	links, err := Plan(testPeers("a", "b", "c"), ModePairwise)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a<->b", "a<->c", "b<->c"}, linkStrings(links))
+
+	links, err = Plan(testPeers("a", "b", "c"), ModeRing)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a<->b", "b<->c", "c<->a"}, linkStrings(links))
+
+	// only one link between two nodes
+	links, err = Plan(testPeers("a", "b"), ModeRing)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a<->b"}, linkStrings(links))
+
+	_, err = Plan(testPeers("a"), ModeRing)
+	assert.True(t, errors.Is(err, ErrTooFewPeers))
+
+	_, err = Plan(testPeers("a", "b"), Mode("star"))
+	assert.True(t, errors.Is(err, ErrUnknownMode))
+}
+
+func TestRequestValidate(t *testing.T) {
+	assert.NoError(t, Request{Peers: testPeers("a", "b")}.Validate())
+	assert.True(t, errors.Is(Request{Peers: testPeers("a")}.Validate(), ErrTooFewPeers))
+	assert.True(t, errors.Is(Request{Mode: "star", Peers: testPeers("a", "b")}.Validate(), ErrUnknownMode))
+	assert.Error(t, Request{Peers: testPeers("a", "a")}.Validate())
+	assert.Error(t, Request{Peers: testPeers("a", "b"), GPUsPerNode: -1}.Validate())
+	assert.Error(t, Request{Peers: testPeers("a", "b"), MinBusBandwidthGBps: -1}.Validate())
+	assert.NoError(t, Request{Peers: testPeers("a", "b"), MessageSize: "256M"}.Validate())
+	for _, size := range []string{"1G; reboot", "-1G", "0", "1T", "1 G", "--help"} {
+		assert.True(t, errors.Is(Request{Peers: testPeers("a", "b"), MessageSize: size}.Validate(), ErrInvalidMessageSize), size)
+	}
+
+	for _, host := range []string{"10.0.0.1", "node-1", "node-1.cluster.local"} {
+		peers := pkgpeermesh.Peers{{ID: "a", Address: host + ":15132"}, {ID: "b", Address: "10.0.0.2:15132"}}
+		assert.NoError(t, Request{Peers: peers}.Validate(), host)
+	}
+	for _, addr := range []string{"10.0.0.1,10.9.9.9:15132", "[fe80::1]:15132", "-oProxyCommand=x:15132", "node_1:15132", "a b:15132"} {
+		peers := pkgpeermesh.Peers{{ID: "a", Address: addr}, {ID: "b", Address: "10.0.0.2:15132"}}
+		assert.True(t, errors.Is(Request{Peers: peers}.Validate(), ErrInvalidPeerHost), addr)
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	peers := testPeers("a", "b", "c", "d")
+	links, err := Plan(peers, ModePairwise)
+	require.NoError(t, err)
+
+	results := func(degraded ...string) []LinkResult {
+		rs := make([]LinkResult, 0, len(links))
+		for _, l := range links {
+			r := LinkResult{Link: l}
+			for _, d := range degraded {
+				if l.String() == d {
+					r.Degraded = true
+				}
+			}
+			rs = append(rs, r)
+		}
+		return rs
+	}
+
+	nodes, ls, fabricWide := Localize(results())
+	assert.Empty(t, nodes)
+	assert.Empty(t, ls)
+	assert.False(t, fabricWide)
+
+	// all the links of "c" degraded
+	nodes, ls, fabricWide = Localize(results("a<->c", "b<->c", "c<->d"))
+	assert.Equal(t, []string{"c"}, nodes)
+	assert.Empty(t, ls)
+	assert.False(t, fabricWide)
+
+	// a single bad path
+	nodes, ls, fabricWide = Localize(results("a<->b"))
+	assert.Empty(t, nodes)
+	assert.Equal(t, []string{"a<->b"}, ls)
+	assert.False(t, fabricWide)
+
+	nodes, ls, fabricWide = Localize(results("a<->b", "a<->c", "a<->d", "b<->c", "b<->d", "c<->d"))
+	assert.Empty(t, nodes)
+	assert.Empty(t, ls)
+	assert.True(t, fabricWide)
+}
+
+func TestParseBusBandwidth(t *testing.T) {
+	out := `#                                                              out-of-place                       in-place
+#       size         count      type   redop    root     time   algbw   busbw #wrong     time   algbw   busbw #wrong
+  1073741824     268435456     float     sum      -1   9804.2  109.52  205.35      0   9801.6  109.55  205.40      0
+# Out of bounds values : 0 OK
+# Avg bus bandwidth    : 205.374
+#
+`
+	bw, err := parseBusBandwidth(out)
+	require.NoError(t, err)
+	assert.Equal(t, 205.374, bw)
+
+	_, err = parseBusBandwidth("mpirun was unable to launch the specified application")
+	assert.True(t, errors.Is(err, ErrNoBusBandwidth))
+}
+
+func TestMpirunCommand(t *testing.T) {
+	link := Link{A: pkgpeermesh.Peer{ID: "a", Address: "10.0.0.1:15132"}, B: pkgpeermesh.Peer{ID: "b", Address: "10.0.0.2:15132"}}
+	cmd := mpirunCommand("/usr/local/mpi/bin/mpirun", "/opt/nccl-tests/build/all_reduce_perf", link, Request{GPUsPerNode: 8, MessageSize: "1G"}, nil)
+	assert.Equal(t, "/usr/local/mpi/bin/mpirun", cmd[0])
+	assert.Contains(t, cmd, "16")
+	assert.Contains(t, cmd, "10.0.0.1:8,10.0.0.2:8")
+	assert.Contains(t, cmd, "/opt/nccl-tests/build/all_reduce_perf")
+	assert.Contains(t, cmd, "NCCL_DEBUG=WARN")
+
+	envs := mpirunEnvs([]string{"SSH_AUTH_SOCK=/tmp/agent.sock", "NCCL_IB_HCA=mlx5", "NCCL_DEBUG=INFO", "GPUD_TOKEN=secret"})
+	assert.Equal(t, []string{"SSH_AUTH_SOCK=/tmp/agent.sock", "NCCL_IB_HCA=mlx5", "NCCL_DEBUG=INFO"}, envs)
+
+	cmd = mpirunCommand("/usr/local/mpi/bin/mpirun", "/opt/nccl-tests/build/all_reduce_perf", link, Request{GPUsPerNode: 8, MessageSize: "1G"}, envs)
+	assert.Contains(t, strings.Join(cmd, " "), "-x NCCL_IB_HCA -x NCCL_DEBUG /opt/nccl-tests/build/all_reduce_perf")
+	assert.NotContains(t, cmd, "NCCL_DEBUG=WARN")
+	assert.NotContains(t, strings.Join(cmd, " "), "SSH_AUTH_SOCK")
+}
+
+func TestRunLinkInvalidMessageSize(t *testing.T) {
+	link := Link{A: pkgpeermesh.Peer{ID: "a", Address: "10.0.0.1:15132"}, B: pkgpeermesh.Peer{ID: "b", Address: "10.0.0.2:15132"}}
+	_, err := RunLink(context.Background(), link, Request{GPUsPerNode: 8, MessageSize: "1G -c 0"})
+	assert.True(t, errors.Is(err, ErrInvalidMessageSize))
+
+	// the comma injects the extra "mpirun -H" hosts
+	link.B.Address = "10.0.0.2,10.9.9.9:15132"
+	_, err = RunLink(context.Background(), link, Request{GPUsPerNode: 8, MessageSize: "1G"})
+	assert.True(t, errors.Is(err, ErrInvalidPeerHost))
+}
+
+func TestOrchestrator(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	release := make(chan struct{})
+	o := NewOrchestrator(ctx, func() pkgpeermesh.Peers { return testPeers("a", "b", "c", "d") }, func(ctx context.Context, link Link, req Request) (float64, error) {
+		<-release
+		if link.A.ID == "c" || link.B.ID == "c" {
+			return 10, nil
+		}
+		return 200, nil
+	})
+	assert.Nil(t, o.Last())
+
+	require.NoError(t, o.Start(Request{Peers: testPeers("a", "b", "c", "d"), MinBusBandwidthGBps: 100}))
+	assert.True(t, errors.Is(o.Start(Request{Peers: testPeers("a", "b")}), ErrAlreadyRunning))
+
+	last := o.Last()
+	require.NotNil(t, last)
+	assert.Nil(t, last.FinishedAt)
+	assert.Equal(t, ModeRing, last.Request.Mode)
+	assert.Equal(t, 4, last.PendingLinks)
+
+	close(release)
+	require.Eventually(t, func() bool {
+		return o.Last().FinishedAt != nil
+	}, 10*time.Second, 10*time.Millisecond)
+
+	last = o.Last()
+	require.Len(t, last.Links, 4)
+	assert.Equal(t, 0, last.PendingLinks)
+	assert.Equal(t, []string{"c"}, last.SuspectNodes)
+	assert.Empty(t, last.SuspectLinks)
+
+	// the next test may start once finished
+	require.NoError(t, o.Start(Request{Peers: testPeers("a", "b")}))
+}
+
+func TestOrchestratorPeerMesh(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	mesh := testPeers("a", "b", "c")
+	o := NewOrchestrator(ctx, func() pkgpeermesh.Peers { return mesh }, func(ctx context.Context, link Link, req Request) (float64, error) {
+		return 200, nil
+	})
+
+	// the peers not in the mesh, or with the other address
+	assert.True(t, errors.Is(o.Start(Request{Peers: testPeers("a", "x")}), ErrPeerNotInMesh))
+	assert.True(t, errors.Is(o.Start(Request{Peers: pkgpeermesh.Peers{mesh[0], {ID: "b", Address: "evil:15132"}}}), ErrPeerNotInMesh))
+	assert.Nil(t, o.Last())
+
+	// the mesh peers are tested if the request has no peers
+	require.NoError(t, o.Start(Request{}))
+	assert.Equal(t, mesh, o.Last().Request.Peers)
+
+	// no peer mesh configured
+	o = NewOrchestrator(ctx, nil, nil)
+	assert.True(t, errors.Is(o.Start(Request{Peers: testPeers("a", "b")}), ErrPeerNotInMesh))
+	assert.True(t, errors.Is(o.Start(Request{}), ErrTooFewPeers))
+}
@@ -0,0 +1,296 @@
+// Package target measures the latencies from local to the user-provided targets
+// (e.g., the control plane, storage endpoints, peer nodes), using the TCP connect time.
+package target
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/netutil/latency"
+)
+
+// ProviderTarget is the provider of the latencies measured against the user-provided targets.
+const ProviderTarget = "target"
+
+const (
+	// DefaultAttempts is the number of the connects to each target,
+	// with the smallest connect time taken as the latency.
+	DefaultAttempts = 3
+	// DefaultTimeout is the timeout of each connect.
+	DefaultTimeout = 5 * time.Second
+	// DefaultConcurrency is the number of the targets probed at the same time,
+	// so that the slow targets do not exhaust the measure deadline for the others.
+	DefaultConcurrency = 16
+)
+
+// Target is the user-provided endpoint to measure the latency against.
+type Target struct {
+	// Name is the display name of the target, defaults to the address.
+	Name string `json:"name"`
+	// Address is the "host:port" of the target.
+	Address string `json:"address"`
+	// RegionCode is the optional region code of the target (e.g., "us-east-1"),
+	// only the targets with the region code are used for the region detection.
+	RegionCode string `json:"region_code,omitempty"`
+}
+
+// ParseTarget parses the target in the format of "[<name>[@<region>]=]<address>",
+// where the address is either "host:port" or a URL with the "http" or "https" scheme
+// (e.g., "storage@us-east-1=https://s3.us-east-1.amazonaws.com").
+func ParseTarget(s string) (Target, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Target{}, errors.New("empty latency target")
+	}
+
+	t := Target{}
+	addr := s
+	if prefix, rest, ok := strings.Cut(s, "="); ok {
+		t.Name, t.RegionCode, _ = strings.Cut(prefix, "@")
+		addr = rest
+	}
+
+	hostPort, err := parseAddress(addr)
+	if err != nil {
+		return Target{}, fmt.Errorf("invalid latency target %q: %w", s, err)
+	}
+	t.Address = hostPort
+	if t.Name == "" {
+		t.Name = t.Address
+	}
+	return t, nil
+}
+
+// ParseTargets parses the comma-separated targets.
+func ParseTargets(s string) ([]Target, error) {
+	var targets []Target
+	for _, field := range strings.Split(s, ",") {
+		if strings.TrimSpace(field) == "" {
+			continue
+		}
+		t, err := ParseTarget(field)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// parseAddress returns the "host:port" of the address,
+// with the default port of the URL scheme.
+func parseAddress(addr string) (string, error) {
+	if strings.Contains(addr, "://") {
+		u, err := url.Parse(addr)
+		if err != nil {
+			return "", err
+		}
+		port := u.Port()
+		if port == "" {
+			switch u.Scheme {
+			case "https":
+				port = "443"
+			case "http":
+				port = "80"
+			default:
+				return "", fmt.Errorf("unsupported scheme %q without port", u.Scheme)
+			}
+		}
+		if u.Hostname() == "" {
+			return "", errors.New("missing host")
+		}
+		return net.JoinHostPort(u.Hostname(), port), nil
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+	if host == "" || port == "" {
+		return "", errors.New("missing host or port")
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+type Op struct {
+	attempts int
+	timeout  time.Duration
+	dialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+type OpOption func(*Op)
+
+func (op *Op) applyOpts(opts []OpOption) error {
+	for _, opt := range opts {
+		opt(op)
+	}
+
+	if op.attempts <= 0 {
+		op.attempts = DefaultAttempts
+	}
+	if op.timeout <= 0 {
+		op.timeout = DefaultTimeout
+	}
+	if op.dialFunc == nil {
+		op.dialFunc = (&net.Dialer{}).DialContext
+	}
+	return nil
+}
+
+// WithAttempts sets the number of the connects to each target.
+func WithAttempts(attempts int) OpOption {
+	return func(op *Op) {
+		op.attempts = attempts
+	}
+}
+
+// WithTimeout sets the timeout of each connect.
+func WithTimeout(timeout time.Duration) OpOption {
+	return func(op *Op) {
+		op.timeout = timeout
+	}
+}
+
+// Measure measures the latencies from local to the targets concurrently, sorted by the latency.
+// The unreachable targets are excluded from the latencies, and returned
+// as the joined error along with the latencies of the reachable targets.
+func Measure(ctx context.Context, targets []Target, opts ...OpOption) (latency.Latencies, error) {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		dur time.Duration
+		err error
+	}
+	results := make([]result, len(targets))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, DefaultConcurrency)
+	for i, t := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			dur, err := measure(ctx, op, t.Address)
+			results[i] = result{dur: dur, err: err}
+		}()
+	}
+	wg.Wait()
+
+	latencies := make(latency.Latencies, 0, len(targets))
+	var errs []error
+	for i, t := range targets {
+		dur, err := results[i].dur, results[i].err
+		if err != nil {
+			log.Logger.Warnw("failed to measure latency", "target", t.Name, "address", t.Address, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", t.Name, err))
+			continue
+		}
+
+		latencies = append(latencies, latency.Latency{
+			Provider: ProviderTarget,
+
+			RegionName: t.Name,
+			RegionCode: t.RegionCode,
+
+			Latency:             metav1.Duration{Duration: dur},
+			LatencyMilliseconds: dur.Milliseconds(),
+		})
+	}
+
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i].Latency.Duration < latencies[j].Latency.Duration
+	})
+	return latencies, errors.Join(errs...)
+}
+
+// ProbeResult is the result of the repeated connects to an address.
+type ProbeResult struct {
+	// Sent is the number of the connect attempts.
+	Sent int `json:"sent"`
+	// Received is the number of the successful connects.
+	Received int `json:"received"`
+
+	Min time.Duration `json:"min"`
+	Avg time.Duration `json:"avg"`
+	Max time.Duration `json:"max"`
+
+	// Err is the last connect error, nil if all the connects succeeded.
+	Err error `json:"-"`
+}
+
+// LossPercent returns the percentage of the failed connects.
+func (r ProbeResult) LossPercent() float64 {
+	if r.Sent == 0 {
+		return 0
+	}
+	return float64(r.Sent-r.Received) / float64(r.Sent) * 100
+}
+
+// Probe connects to the address repeatedly, and returns the connect times
+// and the number of the failed connects (e.g., to track the packet loss).
+func Probe(ctx context.Context, address string, opts ...OpOption) ProbeResult {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return ProbeResult{Err: err}
+	}
+	return probe(ctx, op, address)
+}
+
+func probe(ctx context.Context, op *Op, address string) ProbeResult {
+	r := ProbeResult{}
+	var total time.Duration
+	for i := 0; i < op.attempts; i++ {
+		cctx, ccancel := context.WithTimeout(ctx, op.timeout)
+		start := time.Now()
+		conn, err := op.dialFunc(cctx, "tcp", address)
+		took := time.Since(start)
+		ccancel()
+		r.Sent++
+		if err != nil {
+			r.Err = err
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		_ = conn.Close()
+
+		r.Received++
+		total += took
+		if r.Min == 0 || took < r.Min {
+			r.Min = took
+		}
+		if took > r.Max {
+			r.Max = took
+		}
+	}
+	if r.Received > 0 {
+		r.Avg = total / time.Duration(r.Received)
+	}
+	return r
+}
+
+// measure returns the smallest TCP connect time to the address over the attempts,
+// or the last error if none of the attempts succeeded.
+func measure(ctx context.Context, op *Op, address string) (time.Duration, error) {
+	r := probe(ctx, op, address)
+	if r.Received == 0 {
+		return 0, r.Err
+	}
+	return r.Min, nil
+}
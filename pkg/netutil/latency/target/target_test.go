@@ -0,0 +1,165 @@
+package target
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Target
+		wantErr bool
+	}{
+		{in: "10.0.0.1:443", want: Target{Name: "10.0.0.1:443", Address: "10.0.0.1:443"}},
+		{in: "cp=gpud-manager.example.com:443", want: Target{Name: "cp", Address: "gpud-manager.example.com:443"}},
+		{in: "storage@us-east-1=https://s3.us-east-1.amazonaws.com", want: Target{Name: "storage", Address: "s3.us-east-1.amazonaws.com:443", RegionCode: "us-east-1"}},
+		{in: "peer=http://[fd00::1]/healthz", want: Target{Name: "peer", Address: "[fd00::1]:80"}},
+		{in: " peer=http://node-1:8080 ", want: Target{Name: "peer", Address: "node-1:8080"}},
+		{in: "", wantErr: true},
+		{in: "no-port.example.com", wantErr: true},
+		{in: "ftp://example.com", wantErr: true},
+		{in: "x=:443", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseTarget(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseTargets(t *testing.T) {
+	targets, err := ParseTargets("cp=cp.example.com:443, ,peer@us-west-2=10.0.0.2:22")
+	require.NoError(t, err)
+	assert.Equal(t, []Target{
+		{Name: "cp", Address: "cp.example.com:443"},
+		{Name: "peer", Address: "10.0.0.2:22", RegionCode: "us-west-2"},
+	}, targets)
+
+	targets, err = ParseTargets("")
+	require.NoError(t, err)
+	assert.Empty(t, targets)
+
+	_, err = ParseTargets("cp.example.com:443,invalid")
+	assert.Error(t, err)
+}
+
+func TestMeasure(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	// reserve a port and close it, so that the connect is refused
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedAddr := closed.Addr().String()
+	require.NoError(t, closed.Close())
+
+	latencies, err := Measure(context.Background(), []Target{
+		{Name: "reachable", Address: ln.Addr().String(), RegionCode: "local"},
+		{Name: "unreachable", Address: closedAddr},
+	}, WithAttempts(2), WithTimeout(time.Second))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unreachable")
+	require.Len(t, latencies, 1)
+	assert.Equal(t, ProviderTarget, latencies[0].Provider)
+	assert.Equal(t, "reachable", latencies[0].RegionName)
+	assert.Equal(t, "local", latencies[0].RegionCode)
+	assert.Positive(t, latencies[0].Latency.Duration)
+}
+
+func TestMeasureConcurrently(t *testing.T) {
+	var targets []Target
+	for i := 0; i < 5; i++ {
+		targets = append(targets, Target{Name: fmt.Sprintf("t%d", i), Address: fmt.Sprintf("10.0.0.%d:443", i)})
+	}
+
+	dialFunc := func(ctx context.Context, network, address string) (net.Conn, error) {
+		select {
+		case <-time.After(200 * time.Millisecond):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		c1, c2 := net.Pipe()
+		_ = c2.Close()
+		return c1, nil
+	}
+
+	// 5 targets of 200ms each would exceed the deadline if probed sequentially
+	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Millisecond)
+	defer cancel()
+	latencies, err := Measure(ctx, targets, WithAttempts(1), func(op *Op) { op.dialFunc = dialFunc })
+	require.NoError(t, err)
+	assert.Len(t, latencies, 5)
+}
+
+func TestMeasureTakesSmallestAttempt(t *testing.T) {
+	delays := []time.Duration{30 * time.Millisecond, 10 * time.Millisecond, 20 * time.Millisecond}
+	calls := 0
+	op := &Op{attempts: len(delays), timeout: time.Second}
+	op.dialFunc = func(ctx context.Context, network, address string) (net.Conn, error) {
+		d := delays[calls]
+		calls++
+		time.Sleep(d)
+		c1, c2 := net.Pipe()
+		_ = c2.Close()
+		return c1, nil
+	}
+
+	dur, err := measure(context.Background(), op, "example.com:443")
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.GreaterOrEqual(t, dur, 10*time.Millisecond)
+	assert.Less(t, dur, 20*time.Millisecond)
+
+	op.dialFunc = func(ctx context.Context, network, address string) (net.Conn, error) {
+		return nil, errors.New("connection refused")
+	}
+	_, err = measure(context.Background(), op, "example.com:443")
+	assert.EqualError(t, err, "connection refused")
+}
+
+func TestProbe(t *testing.T) {
+	calls := 0
+	dialFunc := func(ctx context.Context, network, address string) (net.Conn, error) {
+		calls++
+		if calls%2 == 0 {
+			return nil, errors.New("connection timed out")
+		}
+		c1, c2 := net.Pipe()
+		_ = c2.Close()
+		return c1, nil
+	}
+
+	r := probe(context.Background(), &Op{attempts: 4, timeout: time.Second, dialFunc: dialFunc}, "example.com:443")
+	assert.Equal(t, 4, r.Sent)
+	assert.Equal(t, 2, r.Received)
+	assert.Equal(t, float64(50), r.LossPercent())
+	assert.EqualError(t, r.Err, "connection timed out")
+	assert.LessOrEqual(t, r.Min, r.Avg)
+	assert.LessOrEqual(t, r.Avg, r.Max)
+
+	assert.Equal(t, float64(0), ProbeResult{}.LossPercent())
+}
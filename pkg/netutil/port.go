@@ -1,8 +1,8 @@
 package netutil
 
 import (
-	"fmt"
 	"net"
+	"strconv"
 	"time"
 )
 
@@ -10,7 +10,9 @@ import (
 // It returns true if the port is open/used, otherwise false.
 func IsPortOpen(port int) bool {
 	// check if the TCP port is open/used
-	conn, err := net.DialTimeout("tcp", fmt.Sprintf("localhost:%d", port), 3*time.Second)
+	// "localhost" resolves to both "127.0.0.1" and "::1" on the dual-stack hosts,
+	// and the dialer tries each of them, so the IPv6-only listeners are found too
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("localhost", strconv.Itoa(port)), 3*time.Second)
 	if err != nil {
 		return false
 	}
@@ -32,6 +32,8 @@ type InterfaceAddr struct {
 
 // GetPrivateIPs finds private IP addresses using an optional interface filter.
 // It returns a slice of IP structs, each containing interface and address information.
+// Each interface returns at most one private IPv4 address and one private IPv6 address,
+// in that order, so the dual-stack interfaces are listed twice.
 func GetPrivateIPs(opts ...OpOption) (InterfaceAddrs, error) {
 	op := &Op{}
 	if err := op.applyOpts(opts); err != nil {
@@ -80,46 +82,59 @@ func GetPrivateIPs(opts ...OpOption) (InterfaceAddrs, error) {
 			continue
 		}
 
-		// first try to find a private IPv4 address
-		// matches: 10.*.*.*, 172.16-31.*.*, 192.168.*.*
-		for _, addr := range addrs {
-			ip, ok := convertNetAddr(addr)
-			if !ok || !ip.IsValid() || ip.IsLoopback() {
-				continue
-			}
-
-			if ip.Is4() && ip.IsPrivate() {
-				addresses = append(addresses, InterfaceAddr{
-					Iface: iface,
-					Addr:  ip,
-				})
-				break
-			}
+		// dual-stack interfaces report both the private IPv4 and IPv6 addresses
+		if ip, ok := findPrivateIPv4(addrs); ok {
+			addresses = append(addresses, InterfaceAddr{
+				Iface: iface,
+				Addr:  ip,
+			})
 		}
-
-		// if no IPv4 found, try to find a private/link-local IPv6 address
-		// matches: fd00::/8 (ULA) or fe80::/10 (Link-Local)
-		if len(addresses) == 0 || addresses[len(addresses)-1].Iface.Name != iface.Name {
-			for _, addr := range addrs {
-				ip, ok := convertNetAddr(addr)
-				if !ok || !ip.IsValid() || ip.IsLoopback() {
-					continue
-				}
-
-				if ip.Is6() && (ip.IsPrivate() || ip.IsLinkLocalUnicast()) {
-					addresses = append(addresses, InterfaceAddr{
-						Iface: iface,
-						Addr:  ip,
-					})
-					break
-				}
-			}
+		if ip, ok := findPrivateIPv6(addrs); ok {
+			addresses = append(addresses, InterfaceAddr{
+				Iface: iface,
+				Addr:  ip,
+			})
 		}
 	}
 
 	return addresses, nil
 }
 
+// findPrivateIPv4 returns the first private IPv4 address.
+// matches: 10.*.*.*, 172.16-31.*.*, 192.168.*.*
+func findPrivateIPv4(addrs []net.Addr) (netip.Addr, bool) {
+	for _, addr := range addrs {
+		ip, ok := convertNetAddr(addr)
+		if !ok || !ip.IsValid() || ip.IsLoopback() {
+			continue
+		}
+		if ip.Is4() && ip.IsPrivate() {
+			return ip, true
+		}
+	}
+	return netip.Addr{}, false
+}
+
+// findPrivateIPv6 returns the first unique local IPv6 address (fc00::/7),
+// or the first link-local IPv6 address (fe80::/10) if the interface
+// has no unique local address.
+func findPrivateIPv6(addrs []net.Addr) (netip.Addr, bool) {
+	var linkLocal netip.Addr
+	for _, addr := range addrs {
+		ip, ok := convertNetAddr(addr)
+		if !ok || !ip.IsValid() || ip.IsLoopback() || !ip.Is6() {
+			continue
+		}
+		if ip.IsPrivate() {
+			return ip, true
+		}
+		if ip.IsLinkLocalUnicast() && !linkLocal.IsValid() {
+			linkLocal = ip
+		}
+	}
+	return linkLocal, linkLocal.IsValid()
+}
+
 // convertNetAddr converts a standard net.Addr to netip.Addr.
 // Returns the IP and true if conversion was successful, or
 // an invalid IP and false otherwise.
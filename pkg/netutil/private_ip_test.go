@@ -157,3 +157,33 @@ func TestInterfaceAddrsRenderTable(t *testing.T) {
 	assert.Contains(t, output, "eth1")
 	assert.Contains(t, output, "fd00::1")
 }
+
+func TestFindPrivateIPs(t *testing.T) {
+	ipNet := func(s string) net.Addr {
+		return &net.IPNet{IP: net.ParseIP(s)}
+	}
+
+	dualStack := []net.Addr{
+		ipNet("fe80::1"),
+		ipNet("2001:db8::1"),
+		ipNet("203.0.113.1"),
+		ipNet("10.0.0.1"),
+		ipNet("fd00::1"),
+	}
+	ip, ok := findPrivateIPv4(dualStack)
+	assert.True(t, ok)
+	assert.Equal(t, netip.MustParseAddr("10.0.0.1"), ip)
+	ip, ok = findPrivateIPv6(dualStack)
+	assert.True(t, ok)
+	assert.Equal(t, netip.MustParseAddr("fd00::1"), ip, "unique local address should be preferred over link-local")
+
+	linkLocalOnly := []net.Addr{ipNet("2001:db8::1"), ipNet("fe80::1"), ipNet("::1")}
+	_, ok = findPrivateIPv4(linkLocalOnly)
+	assert.False(t, ok)
+	ip, ok = findPrivateIPv6(linkLocalOnly)
+	assert.True(t, ok)
+	assert.Equal(t, netip.MustParseAddr("fe80::1"), ip)
+
+	_, ok = findPrivateIPv6([]net.Addr{ipNet("10.0.0.1"), ipNet("2001:db8::1")})
+	assert.False(t, ok)
+}
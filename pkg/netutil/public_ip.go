@@ -12,15 +12,32 @@ import (
 	"github.com/leptonai/gpud/pkg/log"
 )
 
+// PublicIP discovers the public IPv4 address of the machine,
+// falling back to the public IPv6 address on the IPv6-only machines.
 func PublicIP() (string, error) {
+	ip, err := discoverPublicIPWithFallback(publicIPDiscoverURLs, "tcp4")
+	if err == nil {
+		return ip, nil
+	}
+
+	ip6, err6 := discoverPublicIPWithFallback(publicIPDiscoverURLs, "tcp6")
+	if err6 == nil {
+		return ip6, nil
+	}
+	return ip, err
+}
+
+// discoverPublicIPWithFallback tries the URLs in order, over the network family
+// ("tcp4" or "tcp6"), returning the first discovered public IP.
+func discoverPublicIPWithFallback(urls []string, network string) (string, error) {
 	var ip string
 	var err error
-	for _, url := range publicIPDiscoverURLs {
-		ip, err = discoverPublicIP(url)
+	for _, url := range urls {
+		ip, err = discoverPublicIP(url, network)
 		if err == nil {
 			break
 		}
-		log.Logger.Warnw("failed to discover public IP", "url", url, "error", err)
+		log.Logger.Warnw("failed to discover public IP", "url", url, "network", network, "error", err)
 	}
 	return ip, err
 }
@@ -31,15 +48,15 @@ var publicIPDiscoverURLs = []string{
 	// "https://ifconfig.io/ip",
 }
 
-func discoverPublicIP(url string) (string, error) {
-	// Create a transport that forces IPv4
+func discoverPublicIP(url string, network string) (string, error) {
+	// Create a transport that forces the network family
+	// so that the discovered IP is in the same family
 	transport := &http.Transport{
-		// Force IPv4 by using tcp4 network
-		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+		DialContext: func(ctx context.Context, _, addr string) (net.Conn, error) {
 			return (&net.Dialer{
 				Timeout:   5 * time.Second,
 				KeepAlive: 30 * time.Second,
-			}).DialContext(ctx, "tcp4", addr)
+			}).DialContext(ctx, network, addr)
 		},
 	}
 
@@ -131,7 +131,7 @@ func TestDiscoverPublicIPWithMockServer(t *testing.T) {
 			defer server.Close()
 
 			// Call the function under test
-			ip, err := discoverPublicIP(server.URL)
+			ip, err := discoverPublicIP(server.URL, "tcp4")
 
 			// Check the results
 			if tc.expectError {
@@ -169,7 +169,7 @@ func TestDiscoverPublicIPMalformedResponse(t *testing.T) {
 	defer server.Close()
 
 	// Call the function under test
-	_, err := discoverPublicIP(server.URL)
+	_, err := discoverPublicIP(server.URL, "tcp4")
 
 	// Should return an error
 	assert.Error(t, err, "Expected an error due to malformed response")
@@ -263,7 +263,7 @@ func TestDiscoverPublicIPInvalidURL(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			_, err := discoverPublicIP(tc.url)
+			_, err := discoverPublicIP(tc.url, "tcp4")
 			assert.Error(t, err, "Expected error for invalid URL: %s", tc.url)
 		})
 	}
@@ -288,7 +288,7 @@ func TestDiscoverPublicIPContextCancellation(t *testing.T) {
 
 	// This should timeout due to the 10-second client timeout
 	start := time.Now()
-	_, err := discoverPublicIP(server.URL)
+	_, err := discoverPublicIP(server.URL, "tcp4")
 	duration := time.Since(start)
 
 	assert.Error(t, err, "Expected timeout error")
@@ -312,7 +312,7 @@ func TestDiscoverPublicIPHTTPMethodAndHeaders(t *testing.T) {
 	}))
 	defer server.Close()
 
-	ip, err := discoverPublicIP(server.URL)
+	ip, err := discoverPublicIP(server.URL, "tcp4")
 	assert.NoError(t, err)
 	assert.Equal(t, "203.0.113.1", ip)
 }
@@ -329,7 +329,7 @@ func TestDiscoverPublicIPIPv4Enforcement(t *testing.T) {
 	}))
 	defer server.Close()
 
-	ip, err := discoverPublicIP(server.URL)
+	ip, err := discoverPublicIP(server.URL, "tcp4")
 	assert.NoError(t, err)
 	assert.Equal(t, "203.0.113.1", ip)
 
@@ -347,3 +347,33 @@ func TestPublicIPDiscoverURLsNotEmpty(t *testing.T) {
 		assert.Contains(t, url, "http", "URL at index %d should be a valid HTTP URL", i)
 	}
 }
+
+// TestPublicIPIPv6Fallback tests the IPv6 fallback on the IPv6-only machines
+func TestPublicIPIPv6Fallback(t *testing.T) {
+	originalURLs := publicIPDiscoverURLs
+	defer func() {
+		publicIPDiscoverURLs = originalURLs
+	}()
+
+	listener, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		t.Skipf("IPv6 loopback not available: %v", err)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("2001:db8::1"))
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	// the IPv6-only server is not reachable over IPv4
+	publicIPDiscoverURLs = []string{server.URL}
+
+	_, err = discoverPublicIP(server.URL, "tcp4")
+	assert.Error(t, err)
+
+	ip, err := PublicIP()
+	assert.NoError(t, err)
+	assert.Equal(t, "2001:db8::1", ip)
+}
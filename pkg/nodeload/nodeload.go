@@ -0,0 +1,138 @@
+// Package nodeload samples the CPU and GPU utilization of the node,
+// to defer the low priority component checks while the node is busy.
+package nodeload
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/log"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+)
+
+// DefaultCacheTTL is the default time the sampled load is reused for,
+// so that the checks in a burst do not sample the load each.
+const DefaultCacheTTL = 10 * time.Second
+
+// Sampler samples the utilization of the node.
+type Sampler struct {
+	ttl time.Duration
+
+	getCPUTimesFunc       func() (cpu.TimesStat, error)
+	getGPUUtilizationFunc func() float64
+
+	mu          sync.Mutex
+	prevTimes   *cpu.TimesStat
+	sampled     components.Load
+	lastSampled time.Time
+}
+
+// New creates a new sampler, with the GPU utilization of the NVML instance, if any.
+func New(nvmlInstance nvidianvml.Instance, ttl time.Duration) *Sampler {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &Sampler{
+		ttl:             ttl,
+		getCPUTimesFunc: getCPUTimes,
+		getGPUUtilizationFunc: func() float64 {
+			return maxGPUUtilization(nvmlInstance)
+		},
+	}
+}
+
+// Load returns the utilization of the node since the last sample,
+// cached for the TTL.
+func (s *Sampler) Load() components.Load {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if !s.lastSampled.IsZero() && now.Sub(s.lastSampled) < s.ttl {
+		return s.sampled
+	}
+
+	var l components.Load
+	times, err := s.getCPUTimesFunc()
+	if err != nil {
+		log.Logger.Warnw("failed to get cpu times", "error", err)
+	} else {
+		if s.prevTimes != nil {
+			l.CPUPercent = calculateBusy(*s.prevTimes, times)
+		}
+		s.prevTimes = &times
+	}
+	l.GPUPercent = s.getGPUUtilizationFunc()
+
+	s.sampled = l
+	s.lastSampled = now
+	return l
+}
+
+func getCPUTimes() (cpu.TimesStat, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	times, err := cpu.TimesWithContext(ctx, false)
+	if err != nil {
+		return cpu.TimesStat{}, err
+	}
+	if len(times) == 0 {
+		return cpu.TimesStat{}, nil
+	}
+	return times[0], nil
+}
+
+// maxGPUUtilization returns the highest utilization of the GPUs in percent,
+// since a job busy on any GPU is to be protected.
+func maxGPUUtilization(nvmlInstance nvidianvml.Instance) float64 {
+	if nvmlInstance == nil || !nvmlInstance.NVMLExists() {
+		return 0
+	}
+
+	var highest float64
+	for uuid, dev := range nvmlInstance.Devices() {
+		util, err := nvidianvml.GetUtilization(uuid, dev)
+		if err != nil {
+			log.Logger.Debugw("failed to get gpu utilization", "uuid", uuid, "error", err)
+			continue
+		}
+		if util.Supported {
+			highest = math.Max(highest, float64(util.GPUUsedPercent))
+		}
+	}
+	return highest
+}
+
+// ref. https://pkg.go.dev/github.com/shirou/gopsutil/v4/cpu#PercentWithContext
+func calculateBusy(t1, t2 cpu.TimesStat) float64 {
+	t1All, t1Busy := getAllBusy(t1)
+	t2All, t2Busy := getAllBusy(t2)
+
+	if t2Busy <= t1Busy {
+		return 0
+	}
+	if t2All <= t1All {
+		return 100
+	}
+	return math.Min(100, math.Max(0, (t2Busy-t1Busy)/(t2All-t1All)*100))
+}
+
+func getAllBusy(t cpu.TimesStat) (float64, float64) {
+	total := t.User + t.System + t.Idle + t.Nice + t.Iowait + t.Irq +
+		t.Softirq + t.Steal + t.Guest + t.GuestNice
+
+	if runtime.GOOS == "linux" {
+		total -= t.Guest
+		total -= t.GuestNice
+	}
+
+	busy := total - t.Idle - t.Iowait
+	return total, busy
+}
@@ -0,0 +1,46 @@
+package nodeload
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/leptonai/gpud/components"
+)
+
+func TestSamplerLoad(t *testing.T) {
+	times := []cpu.TimesStat{
+		{User: 10, Idle: 90},
+		{User: 90, Idle: 110},
+	}
+	var timesErr error
+	s := New(nil, time.Hour)
+	s.getCPUTimesFunc = func() (cpu.TimesStat, error) {
+		t := times[0]
+		if len(times) > 1 {
+			times = times[1:]
+		}
+		return t, timesErr
+	}
+	s.getGPUUtilizationFunc = func() float64 { return 70 }
+
+	// no cpu utilization until the second sample
+	assert.Equal(t, components.Load{GPUPercent: 70}, s.Load())
+
+	// cached
+	assert.Equal(t, components.Load{GPUPercent: 70}, s.Load())
+
+	s.lastSampled = time.Time{}
+	assert.Equal(t, components.Load{CPUPercent: 80, GPUPercent: 70}, s.Load())
+
+	s.lastSampled = time.Time{}
+	timesErr = errors.New("failed")
+	assert.Equal(t, components.Load{GPUPercent: 70}, s.Load())
+}
+
+func TestMaxGPUUtilizationWithoutNVML(t *testing.T) {
+	assert.Zero(t, maxGPUUtilization(nil))
+}
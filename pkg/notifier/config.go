@@ -0,0 +1,141 @@
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	"sigs.k8s.io/yaml"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+const (
+	// SinkTypeWebhook posts each routed event as JSON to the URL.
+	SinkTypeWebhook = "webhook"
+	// SinkTypeLog writes each routed event to the gpud log.
+	SinkTypeLog = "log"
+)
+
+var (
+	ErrSinkNameRequired    = errors.New("sink name is required")
+	ErrSinkURLRequired     = errors.New("sink url is required for the webhook sink")
+	ErrInvalidSinkType     = errors.New("invalid sink type")
+	ErrInvalidMinSeverity  = errors.New("invalid sink min severity")
+	ErrInvalidRateLimit    = errors.New("sink rate limit must be non-negative")
+	ErrDuplicateSinkName   = errors.New("duplicate sink name")
+	ErrInvalidComponentPat = errors.New("invalid sink component pattern")
+)
+
+// SinkConfig defines a notification sink and its routing rules.
+type SinkConfig struct {
+	// Name is the unique name of the sink.
+	Name string `json:"name"`
+	// Type is the sink type (e.g., "webhook", "log").
+	Type string `json:"type"`
+	// URL is the endpoint to post the events to, for the webhook sink.
+	URL string `json:"url,omitempty"`
+
+	// Components are the glob patterns of the component names to route
+	// (e.g., "accelerator-nvidia-*"). Leave empty to route all the components.
+	Components []string `json:"components,omitempty"`
+	// MinSeverity is the minimum event type to route
+	// (e.g., "Critical" routes the "Critical" and "Fatal" events).
+	// Leave empty to route all the events.
+	MinSeverity apiv1.EventType `json:"min_severity,omitempty"`
+	// RateLimit is the maximum number of the events sent per minute.
+	// The events over the limit are dropped. Zero means no limit.
+	RateLimit int `json:"rate_limit,omitempty"`
+}
+
+// Validate validates the sink config.
+func (cfg SinkConfig) Validate() error {
+	if cfg.Name == "" {
+		return ErrSinkNameRequired
+	}
+	switch cfg.Type {
+	case SinkTypeWebhook:
+		if cfg.URL == "" {
+			return fmt.Errorf("%w (sink %q)", ErrSinkURLRequired, cfg.Name)
+		}
+	case SinkTypeLog:
+	default:
+		return fmt.Errorf("%w %q (sink %q)", ErrInvalidSinkType, cfg.Type, cfg.Name)
+	}
+	for _, pat := range cfg.Components {
+		if _, err := path.Match(pat, ""); err != nil {
+			return fmt.Errorf("%w %q (sink %q)", ErrInvalidComponentPat, pat, cfg.Name)
+		}
+	}
+	if cfg.MinSeverity != "" && severity(cfg.MinSeverity) < 0 {
+		return fmt.Errorf("%w %q (sink %q)", ErrInvalidMinSeverity, cfg.MinSeverity, cfg.Name)
+	}
+	if cfg.RateLimit < 0 {
+		return fmt.Errorf("%w (sink %q)", ErrInvalidRateLimit, cfg.Name)
+	}
+	return nil
+}
+
+// Matches returns true if the event of the component should be routed to the sink.
+func (cfg SinkConfig) Matches(component string, eventType apiv1.EventType) bool {
+	if cfg.MinSeverity != "" && severity(eventType) < severity(cfg.MinSeverity) {
+		return false
+	}
+	if len(cfg.Components) == 0 {
+		return true
+	}
+	for _, pat := range cfg.Components {
+		if ok, _ := path.Match(pat, component); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadSinkConfigs loads and validates the sink configs from the YAML file.
+func LoadSinkConfigs(file string) ([]SinkConfig, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSinkConfigs(b)
+}
+
+// ParseSinkConfigs parses and validates the sink configs from the YAML content.
+func ParseSinkConfigs(b []byte) ([]SinkConfig, error) {
+	var cfgs []SinkConfig
+	if err := yaml.Unmarshal(b, &cfgs); err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]struct{}, len(cfgs))
+	for _, cfg := range cfgs {
+		if err := cfg.Validate(); err != nil {
+			return nil, err
+		}
+		if _, ok := names[cfg.Name]; ok {
+			return nil, fmt.Errorf("%w %q", ErrDuplicateSinkName, cfg.Name)
+		}
+		names[cfg.Name] = struct{}{}
+	}
+	return cfgs, nil
+}
+
+// severity returns the order of the event type, or -1 if unknown.
+func severity(t apiv1.EventType) int {
+	switch t {
+	case apiv1.EventTypeUnknown, "":
+		return 0
+	case apiv1.EventTypeInfo:
+		return 1
+	case apiv1.EventTypeWarning:
+		return 2
+	case apiv1.EventTypeCritical:
+		return 3
+	case apiv1.EventTypeFatal:
+		return 4
+	default:
+		return -1
+	}
+}
@@ -0,0 +1,94 @@
+package notifier
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestSinkConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     SinkConfig
+		wantErr error
+	}{
+		{name: "valid webhook", cfg: SinkConfig{Name: "pager", Type: SinkTypeWebhook, URL: "https://example.com", MinSeverity: apiv1.EventTypeCritical, RateLimit: 10}},
+		{name: "valid log", cfg: SinkConfig{Name: "log", Type: SinkTypeLog, Components: []string{"accelerator-nvidia-*"}}},
+		{name: "missing name", cfg: SinkConfig{Type: SinkTypeLog}, wantErr: ErrSinkNameRequired},
+		{name: "missing url", cfg: SinkConfig{Name: "pager", Type: SinkTypeWebhook}, wantErr: ErrSinkURLRequired},
+		{name: "invalid type", cfg: SinkConfig{Name: "x", Type: "email"}, wantErr: ErrInvalidSinkType},
+		{name: "invalid pattern", cfg: SinkConfig{Name: "x", Type: SinkTypeLog, Components: []string{"["}}, wantErr: ErrInvalidComponentPat},
+		{name: "invalid severity", cfg: SinkConfig{Name: "x", Type: SinkTypeLog, MinSeverity: "Urgent"}, wantErr: ErrInvalidMinSeverity},
+		{name: "negative rate limit", cfg: SinkConfig{Name: "x", Type: SinkTypeLog, RateLimit: -1}, wantErr: ErrInvalidRateLimit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSinkConfigMatches(t *testing.T) {
+	cfg := SinkConfig{
+		Name:        "pager",
+		Type:        SinkTypeLog,
+		Components:  []string{"accelerator-nvidia-*", "disk"},
+		MinSeverity: apiv1.EventTypeCritical,
+	}
+	assert.True(t, cfg.Matches("accelerator-nvidia-error-xid", apiv1.EventTypeCritical))
+	assert.True(t, cfg.Matches("disk", apiv1.EventTypeFatal))
+	assert.False(t, cfg.Matches("accelerator-nvidia-error-xid", apiv1.EventTypeWarning))
+	assert.False(t, cfg.Matches("memory", apiv1.EventTypeFatal))
+
+	all := SinkConfig{Name: "all", Type: SinkTypeLog}
+	assert.True(t, all.Matches("memory", ""))
+	assert.True(t, all.Matches("memory", apiv1.EventTypeInfo))
+}
+
+func TestLoadSinkConfigs(t *testing.T) {
+	dir := t.TempDir()
+
+	file := filepath.Join(dir, "sinks.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+- name: pager
+  type: webhook
+  url: https://example.com/hook
+  components:
+  - accelerator-nvidia-*
+  min_severity: Critical
+  rate_limit: 5
+- name: all
+  type: log
+`), 0644))
+
+	cfgs, err := LoadSinkConfigs(file)
+	require.NoError(t, err)
+	require.Len(t, cfgs, 2)
+	assert.Equal(t, "https://example.com/hook", cfgs[0].URL)
+	assert.Equal(t, []string{"accelerator-nvidia-*"}, cfgs[0].Components)
+	assert.Equal(t, apiv1.EventTypeCritical, cfgs[0].MinSeverity)
+	assert.Equal(t, 5, cfgs[0].RateLimit)
+	assert.Equal(t, SinkTypeLog, cfgs[1].Type)
+
+	require.NoError(t, os.WriteFile(file, []byte(`
+- name: a
+  type: log
+- name: a
+  type: log
+`), 0644))
+	_, err = LoadSinkConfigs(file)
+	assert.ErrorIs(t, err, ErrDuplicateSinkName)
+
+	_, err = LoadSinkConfigs(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,177 @@
+// Package notifier routes the component events to the configured sinks
+// (e.g., paging webhooks, logs), filtered per sink by the component and severity.
+// The events inside the windows of the expected disruption are not sent to
+// the webhook sinks, while still written to the log sinks.
+package notifier
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	pkgdisruption "github.com/leptonai/gpud/pkg/disruption"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// DefaultPollInterval is the default interval to poll the component events.
+const DefaultPollInterval = 10 * time.Second
+
+// Notifier polls the new events of all the components,
+// and routes each event to the matching sinks.
+type Notifier struct {
+	registry components.Registry
+	sinks    []*routedSink
+
+	// disruptions are the declared windows of the expected disruption,
+	// nil if no disruption is expected
+	disruptions *pkgdisruption.Windows
+
+	// latest routed event time per component, and the events routed
+	// at that second (the event store is in the unix seconds, thus
+	// the same second is polled again in the next poll)
+	last map[string]time.Time
+	seen map[string]map[eventKey]struct{}
+}
+
+type routedSink struct {
+	cfg     SinkConfig
+	sink    Sink
+	limiter *rateLimiter
+}
+
+type eventKey struct {
+	name    string
+	message string
+	unix    int64
+}
+
+// New creates a new notifier for the sink configs.
+func New(registry components.Registry, cfgs []SinkConfig) (*Notifier, error) {
+	n := &Notifier{
+		registry: registry,
+		last:     make(map[string]time.Time),
+		seen:     make(map[string]map[eventKey]struct{}),
+	}
+	for _, cfg := range cfgs {
+		if err := cfg.Validate(); err != nil {
+			return nil, err
+		}
+		n.sinks = append(n.sinks, &routedSink{
+			cfg:     cfg,
+			sink:    newSink(cfg),
+			limiter: &rateLimiter{limit: cfg.RateLimit},
+		})
+	}
+	return n, nil
+}
+
+// SetDisruptions sets the windows of the expected disruption,
+// not to page the events of the components inside the windows.
+// Must be called before Start.
+func (n *Notifier) SetDisruptions(disruptions *pkgdisruption.Windows) {
+	n.disruptions = disruptions
+}
+
+// Start polls the events in the background until the context is canceled.
+// Only the events after the start are routed.
+func (n *Notifier) Start(ctx context.Context, interval time.Duration) {
+	start := time.Now().UTC()
+	for _, comp := range n.registry.All() {
+		n.last[comp.Name()] = start
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			n.poll(ctx)
+		}
+	}()
+}
+
+func (n *Notifier) poll(ctx context.Context) {
+	for _, comp := range n.registry.All() {
+		name := comp.Name()
+		since, ok := n.last[name]
+		if !ok {
+			// newly registered component (e.g., custom plugin)
+			n.last[name] = time.Now().UTC()
+			continue
+		}
+
+		// the event store query excludes the events at the time, in unix seconds
+		evs, err := comp.Events(ctx, since.Add(-time.Second))
+		if err != nil {
+			log.Logger.Warnw("failed to get events for notification", "component", name, "error", err)
+			continue
+		}
+
+		lastSec := since.Unix()
+		seen := n.seen[name]
+		if seen == nil {
+			seen = make(map[eventKey]struct{})
+		}
+		sort.SliceStable(evs, func(i, j int) bool {
+			return evs[i].Time.Before(&evs[j].Time)
+		})
+		for _, ev := range evs { // oldest event first
+			sec := ev.Time.Unix()
+			if sec < lastSec {
+				continue
+			}
+
+			key := eventKey{name: ev.Name, message: ev.Message, unix: sec}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+
+			if ev.Component == "" {
+				ev.Component = name
+			}
+			n.Route(ctx, ev)
+
+			if sec > lastSec {
+				lastSec = sec
+				seen = make(map[eventKey]struct{})
+			}
+			seen[key] = struct{}{}
+		}
+
+		n.last[name] = time.Unix(lastSec, 0).UTC()
+		n.seen[name] = seen
+	}
+}
+
+// Route sends the event to all the sinks whose rules match the event.
+// The event inside the window of the expected disruption is not sent to the webhook sinks.
+func (n *Notifier) Route(ctx context.Context, ev apiv1.Event) {
+	expected := n.disruptions.Match(ev.Component, ev.Time.Time)
+	for _, s := range n.sinks {
+		if !s.cfg.Matches(ev.Component, ev.Type) {
+			continue
+		}
+		if expected != "" && s.cfg.Type == SinkTypeWebhook {
+			log.Logger.Infow("suppressing event notification inside expected disruption window", "sink", s.cfg.Name, "component", ev.Component, "name", ev.Name, "window", expected)
+			continue
+		}
+		if !s.limiter.allow(time.Now()) {
+			log.Logger.Warnw("dropping event notification over the rate limit", "sink", s.cfg.Name, "component", ev.Component, "name", ev.Name)
+			continue
+		}
+
+		cctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		err := s.sink.Send(cctx, ev)
+		cancel()
+		if err != nil {
+			log.Logger.Warnw("failed to send event notification", "sink", s.cfg.Name, "component", ev.Component, "name", ev.Name, "error", err)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	pkgdisruption "github.com/leptonai/gpud/pkg/disruption"
+)
+
+type mockComponent struct {
+	components.Component
+
+	name string
+
+	mu     sync.Mutex
+	events apiv1.Events
+}
+
+func (c *mockComponent) Name() string { return c.name }
+
+func (c *mockComponent) Events(_ context.Context, since time.Time) (apiv1.Events, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var evs apiv1.Events
+	for _, ev := range c.events {
+		if ev.Time.Time.After(since) {
+			evs = append(evs, ev)
+		}
+	}
+	return evs, nil
+}
+
+func (c *mockComponent) add(ev apiv1.Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events = append(apiv1.Events{ev}, c.events...)
+}
+
+type recordSink struct {
+	mu     sync.Mutex
+	events []apiv1.Event
+}
+
+func (s *recordSink) Send(_ context.Context, ev apiv1.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, ev)
+	return nil
+}
+
+func (s *recordSink) names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.events))
+	for _, ev := range s.events {
+		names = append(names, ev.Name)
+	}
+	return names
+}
+
+func TestNotifierPollRoutes(t *testing.T) {
+	ctx := context.Background()
+
+	gpu := &mockComponent{name: "accelerator-nvidia-error-xid"}
+	disk := &mockComponent{name: "disk"}
+	registry := components.NewRegistry(&components.GPUdInstance{})
+	for _, c := range []*mockComponent{gpu, disk} {
+		c := c
+		_, err := registry.Register(func(*components.GPUdInstance) (components.Component, error) { return c, nil })
+		require.NoError(t, err)
+	}
+
+	n, err := New(registry, []SinkConfig{
+		{Name: "pager", Type: SinkTypeLog, Components: []string{"accelerator-nvidia-*"}, MinSeverity: apiv1.EventTypeCritical},
+		{Name: "all", Type: SinkTypeLog},
+	})
+	require.NoError(t, err)
+	pager, all := &recordSink{}, &recordSink{}
+	n.sinks[0].sink = pager
+	n.sinks[1].sink = all
+
+	base := time.Now().UTC().Truncate(time.Second)
+	n.last[gpu.name] = base
+	n.last[disk.name] = base
+
+	// before the start, not routed
+	gpu.add(apiv1.Event{Time: metav1.NewTime(base.Add(-time.Minute)), Name: "old", Type: apiv1.EventTypeFatal})
+	gpu.add(apiv1.Event{Time: metav1.NewTime(base), Name: "xid-79", Type: apiv1.EventTypeFatal})
+	gpu.add(apiv1.Event{Time: metav1.NewTime(base), Name: "xid-31", Type: apiv1.EventTypeWarning})
+	disk.add(apiv1.Event{Time: metav1.NewTime(base.Add(time.Second)), Name: "disk-full", Type: apiv1.EventTypeCritical})
+
+	n.poll(ctx)
+	assert.Equal(t, []string{"xid-79"}, pager.names())
+	assert.ElementsMatch(t, []string{"xid-79", "xid-31", "disk-full"}, all.names())
+
+	// the same second is polled again, without re-routing
+	gpu.add(apiv1.Event{Time: metav1.NewTime(base), Name: "xid-48", Type: apiv1.EventTypeCritical})
+	n.poll(ctx)
+	assert.Equal(t, []string{"xid-79", "xid-48"}, pager.names())
+	assert.Len(t, all.names(), 4)
+
+	n.poll(ctx)
+	assert.Len(t, all.names(), 4)
+}
+
+func TestNotifierRateLimit(t *testing.T) {
+	n, err := New(components.NewRegistry(&components.GPUdInstance{}), []SinkConfig{
+		{Name: "pager", Type: SinkTypeLog, RateLimit: 2},
+	})
+	require.NoError(t, err)
+	rec := &recordSink{}
+	n.sinks[0].sink = rec
+
+	for i := 0; i < 5; i++ {
+		n.Route(context.Background(), apiv1.Event{Component: "disk", Name: "disk-full"})
+	}
+	assert.Len(t, rec.names(), 2)
+
+	n.sinks[0].limiter.windowStart = time.Now().Add(-time.Minute)
+	n.Route(context.Background(), apiv1.Event{Component: "disk", Name: "disk-full"})
+	assert.Len(t, rec.names(), 3)
+}
+
+func TestNotifierExpectedDisruption(t *testing.T) {
+	n, err := New(components.NewRegistry(&components.GPUdInstance{}), []SinkConfig{
+		{Name: "pager", Type: SinkTypeWebhook, URL: "http://localhost"},
+		{Name: "all", Type: SinkTypeLog},
+	})
+	require.NoError(t, err)
+	pager, all := &recordSink{}, &recordSink{}
+	n.sinks[0].sink = pager
+	n.sinks[1].sink = all
+
+	now := time.Now().UTC()
+	disruptions := pkgdisruption.New()
+	_, err = disruptions.Add(now, apiv1.DisruptionWindow{
+		Components: []string{"accelerator-nvidia-infiniband"},
+		Start:      metav1.NewTime(now.Add(-time.Minute)),
+		End:        metav1.NewTime(now.Add(time.Hour)),
+	}, nil)
+	require.NoError(t, err)
+	n.SetDisruptions(disruptions)
+
+	// not paged inside the window, but still logged
+	n.Route(context.Background(), apiv1.Event{Component: "accelerator-nvidia-infiniband", Time: metav1.NewTime(now), Name: "ib-port-down"})
+	assert.Empty(t, pager.names())
+	assert.Equal(t, []string{"ib-port-down"}, all.names())
+
+	// paged outside the window
+	n.Route(context.Background(), apiv1.Event{Component: "accelerator-nvidia-infiniband", Time: metav1.NewTime(now.Add(2 * time.Hour)), Name: "ib-port-flap"})
+	n.Route(context.Background(), apiv1.Event{Component: "disk", Time: metav1.NewTime(now), Name: "disk-full"})
+	assert.Equal(t, []string{"ib-port-flap", "disk-full"}, pager.names())
+	assert.Len(t, all.names(), 3)
+}
+
+func TestWebhookSink(t *testing.T) {
+	var got apiv1.Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		if got.Name == "fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	s := newSink(SinkConfig{Name: "pager", Type: SinkTypeWebhook, URL: srv.URL})
+	require.NoError(t, s.Send(context.Background(), apiv1.Event{Component: "disk", Name: "disk-full", Type: apiv1.EventTypeCritical}))
+	assert.Equal(t, "disk", got.Component)
+	assert.Equal(t, apiv1.EventTypeCritical, got.Type)
+
+	assert.Error(t, s.Send(context.Background(), apiv1.Event{Name: "fail"}))
+}
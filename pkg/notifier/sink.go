@@ -0,0 +1,106 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// Sink sends the routed events to the destination.
+type Sink interface {
+	Send(ctx context.Context, ev apiv1.Event) error
+}
+
+func newSink(cfg SinkConfig) Sink {
+	switch cfg.Type {
+	case SinkTypeWebhook:
+		return &webhookSink{
+			url: cfg.URL,
+			cli: &http.Client{Timeout: 10 * time.Second},
+		}
+	default:
+		return &logSink{name: cfg.Name}
+	}
+}
+
+type webhookSink struct {
+	url string
+	cli *http.Client
+}
+
+func (s *webhookSink) Send(ctx context.Context, ev apiv1.Event) error {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.cli.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook returned %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+type logSink struct {
+	name string
+}
+
+func (s *logSink) Send(_ context.Context, ev apiv1.Event) error {
+	log.Logger.Infow("event notification",
+		"sink", s.name,
+		"component", ev.Component,
+		"name", ev.Name,
+		"type", ev.Type,
+		"time", ev.Time.Time,
+		"message", ev.Message,
+	)
+	return nil
+}
+
+// rateLimiter allows up to the limit per minute, in the fixed windows.
+type rateLimiter struct {
+	limit int
+
+	mu          sync.Mutex
+	windowStart time.Time
+	count       int
+}
+
+func (r *rateLimiter) allow(now time.Time) bool {
+	if r.limit <= 0 {
+		return true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now.Sub(r.windowStart) >= time.Minute {
+		r.windowStart = now
+		r.count = 0
+	}
+	if r.count >= r.limit {
+		return false
+	}
+	r.count++
+	return true
+}
@@ -0,0 +1,181 @@
+package nvidiadriver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/process"
+)
+
+type InstallOp struct {
+	downloadDir    string
+	httpClient     *http.Client
+	runCommandFunc func(ctx context.Context, cmd string) error
+}
+
+type InstallOpOption func(*InstallOp)
+
+func (op *InstallOp) applyOpts(opts []InstallOpOption) {
+	for _, opt := range opts {
+		opt(op)
+	}
+	if op.downloadDir == "" {
+		op.downloadDir = os.TempDir()
+	}
+	if op.httpClient == nil {
+		op.httpClient = http.DefaultClient
+	}
+	if op.runCommandFunc == nil {
+		op.runCommandFunc = runCommand
+	}
+}
+
+// WithDownloadDir sets the directory to download the runfile to.
+func WithDownloadDir(dir string) InstallOpOption {
+	return func(op *InstallOp) {
+		op.downloadDir = dir
+	}
+}
+
+// WithHTTPClient sets the HTTP client to download the runfile with.
+func WithHTTPClient(c *http.Client) InstallOpOption {
+	return func(op *InstallOp) {
+		op.httpClient = c
+	}
+}
+
+// WithRunCommandFunc overrides the function to run the install commands (e.g., for testing).
+func WithRunCommandFunc(f func(ctx context.Context, cmd string) error) InstallOpOption {
+	return func(op *InstallOp) {
+		op.runCommandFunc = f
+	}
+}
+
+// Install runs the planned install commands in order, after downloading
+// and verifying the runfile for the runfile method.
+// The package managers verify the packages with the repository signing keys.
+func Install(ctx context.Context, plan *Plan, opts ...InstallOpOption) error {
+	op := &InstallOp{}
+	op.applyOpts(opts)
+
+	runfile := ""
+	if plan.Method == MethodRunfile {
+		runfile = filepath.Join(op.downloadDir, runfileBaseName(plan.RunfileURL))
+		if err := downloadAndVerify(ctx, op.httpClient, plan.RunfileURL, plan.RunfileSHA256, runfile); err != nil {
+			return err
+		}
+		defer func() {
+			if err := os.Remove(runfile); err != nil {
+				log.Logger.Warnw("failed to remove the downloaded runfile", "file", runfile, "error", err)
+			}
+		}()
+	}
+
+	for _, cmd := range plan.Commands {
+		if runfile != "" {
+			cmd = strings.ReplaceAll(cmd, RunfilePlaceholder, runfile)
+		}
+		log.Logger.Infow("running driver install command", "command", cmd)
+		if err := op.runCommandFunc(ctx, cmd); err != nil {
+			return fmt.Errorf("failed to run %q: %w", cmd, err)
+		}
+	}
+	return nil
+}
+
+func runfileBaseName(url string) string {
+	name := url
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.IndexAny(name, "?#"); i >= 0 {
+		name = name[:i]
+	}
+	if name == "" {
+		name = "nvidia-driver.run"
+	}
+	return name
+}
+
+// downloadAndVerify downloads the file and verifies its sha256 checksum,
+// removing the file on the checksum mismatch.
+func downloadAndVerify(ctx context.Context, client *http.Client, url string, expectedSHA256 string, dst string) error {
+	log.Logger.Infow("downloading driver runfile", "url", url, "file", dst)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download %q: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download %q: %s", url, resp.Status)
+	}
+
+	f, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0700)
+	if err != nil {
+		return err
+	}
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(f, h), resp.Body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(dst)
+		return fmt.Errorf("failed to write %q: %w", dst, err)
+	}
+
+	actual := hex.EncodeToString(h.Sum(nil))
+	if !strings.EqualFold(actual, strings.TrimSpace(expectedSHA256)) {
+		_ = os.Remove(dst)
+		return fmt.Errorf("sha256 checksum mismatch for %q: expected %s, got %s", url, expectedSHA256, actual)
+	}
+
+	log.Logger.Infow("verified driver runfile", "file", dst, "sha256", actual)
+	return nil
+}
+
+// runCommand runs the command as a bash script, streaming its output.
+func runCommand(ctx context.Context, cmd string) error {
+	p, err := process.New(
+		process.WithCommand(cmd),
+		process.WithRunAsBashScript(),
+	)
+	if err != nil {
+		return err
+	}
+	if err := p.Start(ctx); err != nil {
+		return err
+	}
+	defer func() {
+		if err := p.Close(ctx); err != nil {
+			log.Logger.Warnw("failed to abort command", "err", err)
+		}
+	}()
+
+	if err := process.Read(
+		ctx,
+		p,
+		process.WithReadStdout(),
+		process.WithReadStderr(),
+		process.WithProcessLine(func(line string) {
+			fmt.Println(line)
+		}),
+		process.WithWaitForCmd(),
+	); err != nil {
+		return err
+	}
+	return nil
+}
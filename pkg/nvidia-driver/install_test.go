@@ -0,0 +1,97 @@
+package nvidiadriver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallPackages(t *testing.T) {
+	p, err := NewPlan(OperationInstall, "550.90.07", "", MethodApt)
+	require.NoError(t, err)
+
+	var ran []string
+	err = Install(context.Background(), p, WithRunCommandFunc(func(_ context.Context, cmd string) error {
+		ran = append(ran, cmd)
+		return nil
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, p.Commands, ran)
+
+	ran = nil
+	err = Install(context.Background(), p, WithRunCommandFunc(func(_ context.Context, cmd string) error {
+		ran = append(ran, cmd)
+		return errors.New("exit status 100")
+	}))
+	assert.ErrorContains(t, err, "exit status 100")
+	assert.Len(t, ran, 1, "stops at the first failed command")
+}
+
+func TestInstallRunfile(t *testing.T) {
+	content := []byte("#!/bin/sh\necho installing\n")
+	sum := sha256.Sum256(content)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/550.90.07/NVIDIA-Linux-x86_64-550.90.07.run" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	url := srv.URL + "/550.90.07/NVIDIA-Linux-x86_64-550.90.07.run"
+
+	p, err := NewPlan(OperationInstall, "550.90.07", "", MethodRunfile, WithRunfileURL(url), WithRunfileSHA256(hex.EncodeToString(sum[:])))
+	require.NoError(t, err)
+
+	var ran []string
+	err = Install(context.Background(), p, WithDownloadDir(dir), WithRunCommandFunc(func(_ context.Context, cmd string) error {
+		ran = append(ran, cmd)
+
+		// the runfile is downloaded before running the commands
+		file := strings.Fields(cmd)[1]
+		b, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Equal(t, content, b)
+		return nil
+	}))
+	require.NoError(t, err)
+	require.Len(t, ran, 1)
+	assert.NotContains(t, ran[0], RunfilePlaceholder)
+
+	// removed after the install
+	_, err = os.Stat(strings.Fields(ran[0])[1])
+	assert.True(t, os.IsNotExist(err))
+
+	// checksum mismatch
+	p.RunfileSHA256 = strings.Repeat("0", 64)
+	err = Install(context.Background(), p, WithDownloadDir(dir), WithRunCommandFunc(func(context.Context, string) error {
+		t.Fatal("should not run the unverified runfile")
+		return nil
+	}))
+	assert.ErrorContains(t, err, "sha256 checksum mismatch")
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// not found
+	p.RunfileURL = srv.URL + "/missing.run"
+	err = Install(context.Background(), p, WithDownloadDir(dir))
+	assert.ErrorContains(t, err, "404")
+}
+
+func TestRunfileBaseName(t *testing.T) {
+	assert.Equal(t, "NVIDIA-Linux-x86_64-550.90.07.run", runfileBaseName("https://example.com/a/NVIDIA-Linux-x86_64-550.90.07.run?token=x"))
+	assert.Equal(t, "nvidia-driver.run", runfileBaseName("https://example.com/"))
+}
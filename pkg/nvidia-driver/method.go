@@ -0,0 +1,70 @@
+package nvidiadriver
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Method is the method to install the NVIDIA driver.
+type Method string
+
+const (
+	// MethodApt installs the driver packages from the apt repositories (e.g., Ubuntu, Debian).
+	MethodApt Method = "apt"
+	// MethodDnf installs the driver packages from the dnf repositories (e.g., RHEL, Rocky, Amazon Linux).
+	MethodDnf Method = "dnf"
+	// MethodRunfile installs the driver from the NVIDIA runfile installer,
+	// for the distros without the driver packages.
+	MethodRunfile Method = "runfile"
+)
+
+// DefaultOSReleaseFile is the file to detect the distro from.
+const DefaultOSReleaseFile = "/etc/os-release"
+
+// ParseMethod parses the install method, empty to detect from the distro.
+func ParseMethod(s string) (Method, error) {
+	switch m := Method(strings.ToLower(strings.TrimSpace(s))); m {
+	case "", MethodApt, MethodDnf, MethodRunfile:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unsupported driver install method %q (supported: %s, %s, %s)", s, MethodApt, MethodDnf, MethodRunfile)
+	}
+}
+
+// DetectMethod returns the distro-appropriate install method,
+// from the "ID" and "ID_LIKE" in the os-release file.
+// Falls back to the runfile installer for the unknown distros.
+func DetectMethod(osReleaseFile string) (Method, error) {
+	f, err := os.Open(osReleaseFile)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	ids := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		for _, key := range []string{"ID=", "ID_LIKE="} {
+			if strings.HasPrefix(line, key) {
+				v := strings.Trim(strings.TrimPrefix(line, key), "\"'")
+				ids = append(ids, strings.Fields(strings.ToLower(v))...)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	for _, id := range ids {
+		switch id {
+		case "ubuntu", "debian":
+			return MethodApt, nil
+		case "rhel", "centos", "rocky", "almalinux", "fedora", "amzn":
+			return MethodDnf, nil
+		}
+	}
+	return MethodRunfile, nil
+}
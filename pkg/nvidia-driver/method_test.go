@@ -0,0 +1,50 @@
+package nvidiadriver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectMethod(t *testing.T) {
+	tests := []struct {
+		osRelease string
+		want      Method
+	}{
+		{osRelease: "NAME=\"Ubuntu\"\nID=ubuntu\nID_LIKE=debian\n", want: MethodApt},
+		{osRelease: "ID=debian\n", want: MethodApt},
+		{osRelease: "ID=\"rocky\"\nID_LIKE=\"rhel centos fedora\"\n", want: MethodDnf},
+		{osRelease: "ID=\"amzn\"\nID_LIKE=\"fedora\"\n", want: MethodDnf},
+		{osRelease: "ID=pop\nID_LIKE=\"ubuntu debian\"\n", want: MethodApt},
+		{osRelease: "ID=arch\n", want: MethodRunfile},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.want), func(t *testing.T) {
+			f := filepath.Join(t.TempDir(), "os-release")
+			require.NoError(t, os.WriteFile(f, []byte(tt.osRelease), 0644))
+
+			m, err := DetectMethod(f)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, m)
+		})
+	}
+
+	_, err := DetectMethod(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+func TestParseMethod(t *testing.T) {
+	m, err := ParseMethod(" APT ")
+	require.NoError(t, err)
+	assert.Equal(t, MethodApt, m)
+
+	m, err = ParseMethod("")
+	require.NoError(t, err)
+	assert.Equal(t, Method(""), m)
+
+	_, err = ParseMethod("yum")
+	assert.Error(t, err)
+}
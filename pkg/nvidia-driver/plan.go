@@ -0,0 +1,200 @@
+// Package nvidiadriver plans and runs the NVIDIA driver installation and upgrade
+// with the distro-appropriate method, and records the operation.
+package nvidiadriver
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+)
+
+// Operation is the driver operation.
+type Operation string
+
+const (
+	// OperationInstall installs the driver on the node without the driver.
+	OperationInstall Operation = "install"
+	// OperationUpgrade upgrades the installed driver to the newer version.
+	OperationUpgrade Operation = "upgrade"
+)
+
+// DefaultRunfileURLPrefix is the URL prefix of the NVIDIA data center driver runfiles.
+const DefaultRunfileURLPrefix = "https://us.download.nvidia.com/tesla"
+
+var (
+	// ErrAlreadyInstalled is returned when the requested version is already installed.
+	ErrAlreadyInstalled = errors.New("driver version already installed")
+	// ErrNotInstalled is returned when upgrading the node without the driver.
+	ErrNotInstalled = errors.New("no driver installed, run install instead")
+	// ErrInstalledDifferentVersion is returned when installing over the different installed version.
+	ErrInstalledDifferentVersion = errors.New("different driver version already installed, run upgrade instead")
+	// ErrNotNewerVersion is returned when upgrading to the same or older version.
+	ErrNotNewerVersion = errors.New("driver version is not newer than the installed version")
+	// ErrRunfileChecksumRequired is returned when the runfile is installed without the checksum.
+	ErrRunfileChecksumRequired = errors.New("sha256 checksum is required to verify the runfile")
+)
+
+// Plan is the planned driver operation.
+type Plan struct {
+	Operation      Operation `json:"operation"`
+	Version        string    `json:"version"`
+	CurrentVersion string    `json:"current_version,omitempty"`
+	Method         Method    `json:"method"`
+
+	// RunfileURL is the URL to download the runfile installer from,
+	// only set for the runfile method.
+	RunfileURL string `json:"runfile_url,omitempty"`
+	// RunfileSHA256 is the expected sha256 checksum of the runfile.
+	RunfileSHA256 string `json:"runfile_sha256,omitempty"`
+
+	// Commands are the shell commands to install the driver, in order.
+	// For the runfile method, "{{RUNFILE}}" is replaced with the
+	// path to the downloaded and verified runfile.
+	Commands []string `json:"commands"`
+}
+
+// RunfilePlaceholder is replaced with the downloaded runfile path in the commands.
+const RunfilePlaceholder = "{{RUNFILE}}"
+
+type PlanOp struct {
+	runfileURLPrefix string
+	runfileURL       string
+	runfileSHA256    string
+}
+
+type PlanOpOption func(*PlanOp)
+
+func (op *PlanOp) applyOpts(opts []PlanOpOption) {
+	for _, opt := range opts {
+		opt(op)
+	}
+	if op.runfileURLPrefix == "" {
+		op.runfileURLPrefix = DefaultRunfileURLPrefix
+	}
+}
+
+// WithRunfileURL overrides the URL to download the runfile from
+// (e.g., the internal mirror).
+func WithRunfileURL(url string) PlanOpOption {
+	return func(op *PlanOp) {
+		op.runfileURL = url
+	}
+}
+
+// WithRunfileSHA256 sets the expected sha256 checksum of the runfile.
+func WithRunfileSHA256(sha256 string) PlanOpOption {
+	return func(op *PlanOp) {
+		op.runfileSHA256 = sha256
+	}
+}
+
+// NewPlan plans the driver operation to the version,
+// given the currently installed version (empty if none).
+func NewPlan(operation Operation, version string, currentVersion string, method Method, opts ...PlanOpOption) (*Plan, error) {
+	op := &PlanOp{}
+	op.applyOpts(opts)
+
+	major, _, _, err := nvidianvml.ParseDriverVersion(version)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operation {
+	case OperationInstall:
+		if currentVersion == version {
+			return nil, fmt.Errorf("%w: %s", ErrAlreadyInstalled, version)
+		}
+		if currentVersion != "" {
+			return nil, fmt.Errorf("%w: installed %s, requested %s", ErrInstalledDifferentVersion, currentVersion, version)
+		}
+
+	case OperationUpgrade:
+		if currentVersion == "" {
+			return nil, ErrNotInstalled
+		}
+		if currentVersion == version {
+			return nil, fmt.Errorf("%w: %s", ErrAlreadyInstalled, version)
+		}
+		newer, err := isNewer(version, currentVersion)
+		if err != nil {
+			return nil, err
+		}
+		if !newer {
+			return nil, fmt.Errorf("%w: installed %s, requested %s", ErrNotNewerVersion, currentVersion, version)
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported driver operation %q", operation)
+	}
+
+	p := &Plan{
+		Operation:      operation,
+		Version:        version,
+		CurrentVersion: currentVersion,
+		Method:         method,
+	}
+
+	switch method {
+	case MethodApt:
+		// e.g., "nvidia-driver-550-server=550.90.07-0ubuntu0.22.04.1"
+		pkg := fmt.Sprintf("nvidia-driver-%d-server", major)
+		p.Commands = []string{
+			"apt-get update",
+			fmt.Sprintf("DEBIAN_FRONTEND=noninteractive apt-get install -y --no-install-recommends --allow-downgrades %s=%s-*", pkg, version),
+		}
+
+	case MethodDnf:
+		p.Commands = []string{
+			fmt.Sprintf("dnf install -y nvidia-driver-%s nvidia-driver-cuda-%s", version, version),
+		}
+
+	case MethodRunfile:
+		if op.runfileSHA256 == "" {
+			return nil, ErrRunfileChecksumRequired
+		}
+		p.RunfileURL = op.runfileURL
+		if p.RunfileURL == "" {
+			p.RunfileURL = fmt.Sprintf("%s/%s/%s", op.runfileURLPrefix, version, runfileName(version, runtime.GOARCH))
+		}
+		p.RunfileSHA256 = op.runfileSHA256
+		p.Commands = []string{
+			fmt.Sprintf("sh %s --silent --dkms", RunfilePlaceholder),
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported driver install method %q", method)
+	}
+
+	return p, nil
+}
+
+// runfileName returns the runfile name of the version,
+// e.g., "NVIDIA-Linux-x86_64-550.90.07.run".
+func runfileName(version string, goarch string) string {
+	arch := "x86_64"
+	if goarch == "arm64" {
+		arch = "aarch64"
+	}
+	return fmt.Sprintf("NVIDIA-Linux-%s-%s.run", arch, version)
+}
+
+// isNewer returns true if the version a is newer than the version b.
+func isNewer(a, b string) (bool, error) {
+	aMajor, aMinor, aPatch, err := nvidianvml.ParseDriverVersion(a)
+	if err != nil {
+		return false, err
+	}
+	bMajor, bMinor, bPatch, err := nvidianvml.ParseDriverVersion(b)
+	if err != nil {
+		return false, err
+	}
+	if aMajor != bMajor {
+		return aMajor > bMajor, nil
+	}
+	if aMinor != bMinor {
+		return aMinor > bMinor, nil
+	}
+	return aPatch > bPatch, nil
+}
@@ -0,0 +1,121 @@
+package nvidiadriver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPlan(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation Operation
+		version   string
+		current   string
+		method    Method
+		opts      []PlanOpOption
+		wantErr   error
+		wantCmds  []string
+	}{
+		{
+			name:      "install with apt",
+			operation: OperationInstall,
+			version:   "550.90.07",
+			method:    MethodApt,
+			wantCmds: []string{
+				"apt-get update",
+				"DEBIAN_FRONTEND=noninteractive apt-get install -y --no-install-recommends --allow-downgrades nvidia-driver-550-server=550.90.07-*",
+			},
+		},
+		{
+			name:      "upgrade with dnf",
+			operation: OperationUpgrade,
+			version:   "570.86.15",
+			current:   "550.90.07",
+			method:    MethodDnf,
+			wantCmds:  []string{"dnf install -y nvidia-driver-570.86.15 nvidia-driver-cuda-570.86.15"},
+		},
+		{
+			name:      "install with runfile",
+			operation: OperationInstall,
+			version:   "550.90.07",
+			method:    MethodRunfile,
+			opts:      []PlanOpOption{WithRunfileSHA256("abc")},
+			wantCmds:  []string{"sh {{RUNFILE}} --silent --dkms"},
+		},
+		{
+			name:      "runfile without checksum",
+			operation: OperationInstall,
+			version:   "550.90.07",
+			method:    MethodRunfile,
+			wantErr:   ErrRunfileChecksumRequired,
+		},
+		{
+			name:      "install already installed",
+			operation: OperationInstall,
+			version:   "550.90.07",
+			current:   "550.90.07",
+			method:    MethodApt,
+			wantErr:   ErrAlreadyInstalled,
+		},
+		{
+			name:      "install over different version",
+			operation: OperationInstall,
+			version:   "570.86.15",
+			current:   "550.90.07",
+			method:    MethodApt,
+			wantErr:   ErrInstalledDifferentVersion,
+		},
+		{
+			name:      "upgrade without driver",
+			operation: OperationUpgrade,
+			version:   "570.86.15",
+			method:    MethodApt,
+			wantErr:   ErrNotInstalled,
+		},
+		{
+			name:      "upgrade to older version",
+			operation: OperationUpgrade,
+			version:   "550.54.15",
+			current:   "550.90.07",
+			method:    MethodApt,
+			wantErr:   ErrNotNewerVersion,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPlan(tt.operation, tt.version, tt.current, tt.method, tt.opts...)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.operation, p.Operation)
+			assert.Equal(t, tt.version, p.Version)
+			assert.Equal(t, tt.current, p.CurrentVersion)
+			assert.Equal(t, tt.wantCmds, p.Commands)
+		})
+	}
+
+	_, err := NewPlan(OperationInstall, "invalid", "", MethodApt)
+	assert.Error(t, err)
+	_, err = NewPlan(OperationInstall, "550.90.07", "", "yum")
+	assert.Error(t, err)
+	_, err = NewPlan("remove", "550.90.07", "", MethodApt)
+	assert.Error(t, err)
+}
+
+func TestNewPlanRunfileURL(t *testing.T) {
+	p, err := NewPlan(OperationInstall, "550.90.07", "", MethodRunfile, WithRunfileSHA256("abc"))
+	require.NoError(t, err)
+	assert.Contains(t, p.RunfileURL, DefaultRunfileURLPrefix+"/550.90.07/NVIDIA-Linux-")
+	assert.Equal(t, "abc", p.RunfileSHA256)
+
+	p, err = NewPlan(OperationInstall, "550.90.07", "", MethodRunfile, WithRunfileSHA256("abc"), WithRunfileURL("https://mirror.example.com/driver.run"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://mirror.example.com/driver.run", p.RunfileURL)
+
+	assert.Equal(t, "NVIDIA-Linux-x86_64-550.90.07.run", runfileName("550.90.07", "amd64"))
+	assert.Equal(t, "NVIDIA-Linux-aarch64-550.90.07.run", runfileName("550.90.07", "arm64"))
+}
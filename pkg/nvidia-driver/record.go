@@ -0,0 +1,57 @@
+package nvidiadriver
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+)
+
+// Record records the driver operation, whether succeeded or not.
+type Record struct {
+	Plan Plan `json:"plan"`
+
+	StartedAt  metav1.Time `json:"started_at"`
+	FinishedAt metav1.Time `json:"finished_at"`
+
+	// DrainedProcesses is the number of the GPU processes
+	// terminated before the operation.
+	DrainedProcesses int `json:"drained_processes,omitempty"`
+	// RebootRequested is true if the reboot was triggered
+	// to load the new driver.
+	RebootRequested bool `json:"reboot_requested,omitempty"`
+
+	// Error is the error of the operation, empty if succeeded.
+	Error string `json:"error,omitempty"`
+}
+
+// SaveRecord persists the record as the last driver operation.
+func SaveRecord(ctx context.Context, dbRW *sql.DB, rec Record) error {
+	if err := pkgmetadata.CreateTableMetadata(ctx, dbRW); err != nil {
+		return err
+	}
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return pkgmetadata.SetMetadata(ctx, dbRW, pkgmetadata.MetadataKeyLastDriverOperation, string(b))
+}
+
+// ReadLastRecord reads the last driver operation, nil if none recorded.
+func ReadLastRecord(ctx context.Context, dbRO *sql.DB) (*Record, error) {
+	v, err := pkgmetadata.ReadMetadata(ctx, dbRO, pkgmetadata.MetadataKeyLastDriverOperation)
+	if err != nil {
+		return nil, err
+	}
+	if v == "" {
+		return nil, nil
+	}
+	rec := &Record{}
+	if err := json.Unmarshal([]byte(v), rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
@@ -0,0 +1,48 @@
+package nvidiadriver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestRecord(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	rec, err := ReadLastRecord(ctx, dbRO)
+	assert.Error(t, err, "metadata table not created yet")
+	assert.Nil(t, rec)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	want := Record{
+		Plan: Plan{
+			Operation:      OperationUpgrade,
+			Version:        "570.86.15",
+			CurrentVersion: "550.90.07",
+			Method:         MethodApt,
+			Commands:       []string{"apt-get update"},
+		},
+		StartedAt:        metav1.NewTime(now),
+		FinishedAt:       metav1.NewTime(now.Add(time.Minute)),
+		DrainedProcesses: 2,
+		RebootRequested:  true,
+	}
+	require.NoError(t, SaveRecord(ctx, dbRW, want))
+
+	rec, err = ReadLastRecord(ctx, dbRO)
+	require.NoError(t, err)
+	require.NotNil(t, rec)
+	assert.Equal(t, want.Plan, rec.Plan)
+	assert.True(t, want.StartedAt.Equal(&rec.StartedAt))
+	assert.Equal(t, 2, rec.DrainedProcesses)
+	assert.True(t, rec.RebootRequested)
+}
@@ -5,9 +5,8 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/leptonai/gpud/pkg/file"
-	"github.com/leptonai/gpud/pkg/log"
-	"github.com/leptonai/gpud/pkg/process"
+	"github.com/leptonai/gpud/pkg/toolexec"
+	"github.com/leptonai/gpud/pkg/toolpath"
 )
 
 // DeviceVendorID defines the vendor ID of NVIDIA devices.
@@ -44,46 +43,30 @@ func isNVIDIANVSwitchPCI(line string) bool {
 }
 
 func listPCIs(ctx context.Context, command string, matchFunc func(line string) bool) ([]string, error) {
-	lspciPath, err := file.LocateExecutable(strings.Split(command, " ")[0])
+	args := strings.Fields(command)
+	lspciPath, err := toolpath.Locate(args[0])
 	if lspciPath == "" || err != nil {
 		return nil, fmt.Errorf("failed to locate lspci: %w", err)
 	}
 
-	p, err := process.New(
-		process.WithCommand(command),
-		process.WithRunAsBashScript(),
-	)
+	res, err := toolexec.Run(ctx, append([]string{lspciPath}, args[1:]...))
 	if err != nil {
-		return nil, err
-	}
-
-	if err := p.Start(ctx); err != nil {
-		return nil, err
-	}
-	defer func() {
-		if err := p.Close(ctx); err != nil {
-			log.Logger.Warnw("failed to abort command", "err", err)
+		var out string
+		if res != nil {
+			out = strings.TrimSpace(string(res.Output))
 		}
-	}()
+		return nil, fmt.Errorf("failed to read lspci output: %w\n\noutput:\n%s", err, out)
+	}
 
 	lines := make([]string, 0)
-	if err := process.Read(
-		ctx,
-		p,
-		process.WithReadStdout(),
-		process.WithReadStderr(),
-		process.WithProcessLine(func(line string) {
-			if !strings.Contains(strings.ToLower(line), DeviceVendorID) {
-				return
-			}
+	for _, line := range strings.Split(string(res.Output), "\n") {
+		if !strings.Contains(strings.ToLower(line), DeviceVendorID) {
+			continue
+		}
 
-			if matchFunc != nil && matchFunc(line) {
-				lines = append(lines, line)
-			}
-		}),
-		process.WithWaitForCmd(),
-	); err != nil {
-		return nil, fmt.Errorf("failed to read lspci output: %w\n\noutput:\n%s", err, strings.Join(lines, "\n"))
+		if matchFunc != nil && matchFunc(line) {
+			lines = append(lines, line)
+		}
 	}
 	return lines, nil
 }
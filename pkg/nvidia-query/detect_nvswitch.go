@@ -5,9 +5,8 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/leptonai/gpud/pkg/file"
-	"github.com/leptonai/gpud/pkg/log"
-	"github.com/leptonai/gpud/pkg/process"
+	"github.com/leptonai/gpud/pkg/toolexec"
+	"github.com/leptonai/gpud/pkg/toolpath"
 )
 
 func CountSMINVSwitches(ctx context.Context) ([]string, error) {
@@ -17,44 +16,28 @@ func CountSMINVSwitches(ctx context.Context) ([]string, error) {
 // e.g.,
 // "nvidia-smi nvlink --status"
 func countSMINVSwitches(ctx context.Context, command string) ([]string, error) {
-	lspciPath, err := file.LocateExecutable(strings.Split(command, " ")[0])
-	if lspciPath == "" || err != nil {
-		return nil, fmt.Errorf("failed to locate lspci: %w", err)
+	args := strings.Fields(command)
+	smiPath, err := toolpath.Locate(args[0])
+	if smiPath == "" || err != nil {
+		return nil, fmt.Errorf("failed to locate %s: %w", args[0], err)
 	}
 
-	p, err := process.New(
-		process.WithCommand(command),
-		process.WithRunAsBashScript(),
-	)
+	res, err := toolexec.Run(ctx, append([]string{smiPath}, args[1:]...))
 	if err != nil {
-		return nil, err
-	}
-
-	if err := p.Start(ctx); err != nil {
-		return nil, err
-	}
-	defer func() {
-		if err := p.Close(ctx); err != nil {
-			log.Logger.Warnw("failed to abort command", "err", err)
+		var out string
+		if res != nil {
+			out = strings.TrimSpace(string(res.Output))
 		}
-	}()
+		return nil, fmt.Errorf("failed to read nvidia-smi nvlink output: %w\n\noutput:\n%s", err, out)
+	}
 
 	lines := make([]string, 0)
-	if err := process.Read(
-		ctx,
-		p,
-		process.WithReadStdout(),
-		process.WithReadStderr(),
-		process.WithProcessLine(func(line string) {
-			// e.g.,
-			// GPU 7: NVIDIA A100-SXM4-80GB (UUID: GPU-754035b4-4708-efcd-b261-623aea38bcad)
-			if strings.Contains(line, "GPU ") && strings.Contains(line, "NVIDIA") && strings.Contains(line, "UUID") {
-				lines = append(lines, line)
-			}
-		}),
-		process.WithWaitForCmd(),
-	); err != nil {
-		return nil, fmt.Errorf("failed to read nvidia-smi nvlink output: %w\n\noutput:\n%s", err, strings.Join(lines, "\n"))
+	for _, line := range strings.Split(string(res.Output), "\n") {
+		// e.g.,
+		// GPU 7: NVIDIA A100-SXM4-80GB (UUID: GPU-754035b4-4708-efcd-b261-623aea38bcad)
+		if strings.Contains(line, "GPU ") && strings.Contains(line, "NVIDIA") && strings.Contains(line, "UUID") {
+			lines = append(lines, line)
+		}
 	}
 	return lines, nil
 }
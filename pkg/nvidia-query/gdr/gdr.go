@@ -0,0 +1,64 @@
+// Package gdr queries the GPUDirect RDMA prerequisites, the peer-direct
+// memory clients registered with the RDMA core, and the PCIe topology between
+// the GPUs and the RDMA devices (HCAs).
+package gdr
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// DefaultMemoryPeersDir is the sysfs directory of the peer-direct memory
+	// clients registered with the RDMA core (ib_core of MLNX_OFED/DOCA-OFED).
+	DefaultMemoryPeersDir = "/sys/kernel/mm/memory_peers"
+
+	// MemoryPeerNVIDIA is the name of the peer-direct memory client
+	// registered by both "nvidia_peermem" and "nv_peer_mem".
+	MemoryPeerNVIDIA = "nv_mem"
+)
+
+// MemoryPeer is a peer-direct memory client registered with the RDMA core.
+type MemoryPeer struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// GetMemoryPeers returns the peer-direct memory clients sorted by the name,
+// and false if the RDMA core does not support the peer-direct memory
+// (e.g., the inbox RDMA core without MLNX_OFED/DOCA-OFED).
+func GetMemoryPeers(memoryPeersDir string) ([]MemoryPeer, bool, error) {
+	entries, err := os.ReadDir(memoryPeersDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	peers := make([]MemoryPeer, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		peers = append(peers, MemoryPeer{
+			Name:    e.Name(),
+			Version: readValue(filepath.Join(memoryPeersDir, e.Name(), "version")),
+		})
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].Name < peers[j].Name
+	})
+	return peers, true, nil
+}
+
+// readValue reads the trimmed value of the sysfs file, empty if unreadable.
+func readValue(path string) string {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
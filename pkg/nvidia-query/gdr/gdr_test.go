@@ -0,0 +1,36 @@
+package gdr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestGetMemoryPeers(t *testing.T) {
+	peers, supported, err := GetMemoryPeers(filepath.Join(t.TempDir(), "memory_peers"))
+	require.NoError(t, err)
+	assert.False(t, supported)
+	assert.Empty(t, peers)
+
+	dir := t.TempDir()
+	peers, supported, err = GetMemoryPeers(dir)
+	require.NoError(t, err)
+	assert.True(t, supported)
+	assert.Empty(t, peers)
+
+	writeFile(t, filepath.Join(dir, "nv_mem", "version"), "1.0\n")
+	writeFile(t, filepath.Join(dir, "amdkfd", "version"), "")
+	peers, supported, err = GetMemoryPeers(dir)
+	require.NoError(t, err)
+	assert.True(t, supported)
+	assert.Equal(t, []MemoryPeer{{Name: "amdkfd"}, {Name: MemoryPeerNVIDIA, Version: "1.0"}}, peers)
+}
@@ -0,0 +1,206 @@
+package gdr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/leptonai/gpud/pkg/pci"
+)
+
+// Level is the PCIe topology level between two devices,
+// in the same terms as "nvidia-smi topo -m", from the nearest to the farthest.
+type Level string
+
+const (
+	// LevelPIX is the connection traversing at most a single PCIe bridge (switch).
+	LevelPIX Level = "PIX"
+	// LevelPXB is the connection traversing multiple PCIe bridges,
+	// without traversing the PCIe host bridge.
+	LevelPXB Level = "PXB"
+	// LevelPHB is the connection traversing the PCIe host bridge (the CPU).
+	LevelPHB Level = "PHB"
+	// LevelNODE is the connection traversing the interconnect between
+	// the PCIe host bridges within the same NUMA node.
+	// In a VM, the devices are commonly under the different emulated host bridges,
+	// not reflecting the physical topology.
+	LevelNODE Level = "NODE"
+	// LevelSYS is the connection traversing the interconnect between
+	// the NUMA nodes (e.g., QPI/UPI).
+	LevelSYS Level = "SYS"
+)
+
+var levelRanks = map[Level]int{
+	LevelPIX:  0,
+	LevelPXB:  1,
+	LevelPHB:  2,
+	LevelNODE: 3,
+	LevelSYS:  4,
+}
+
+// CrossesHostBridges returns true if the connection traverses multiple PCIe host bridges.
+func (l Level) CrossesHostBridges() bool {
+	return l == LevelNODE || l == LevelSYS
+}
+
+// FartherThan returns true if the level is farther than the other level.
+func (l Level) FartherThan(other Level) bool {
+	return levelRanks[l] > levelRanks[other]
+}
+
+// DefaultMaxLevel is the farthest level between the GPU and its nearest HCA
+// for GPUDirect RDMA, same as the NCCL default (NCCL_NET_GDR_LEVEL).
+const DefaultMaxLevel = LevelPXB
+
+// DefaultInfinibandClassDir is the sysfs directory of the RDMA devices.
+const DefaultInfinibandClassDir = "/sys/class/infiniband"
+
+// GPUTopology is the nearest HCA of the GPU.
+type GPUTopology struct {
+	// BusID is the PCI bus ID of the GPU.
+	BusID string `json:"bus_id"`
+	// UUID is the GPU UUID.
+	UUID string `json:"uuid,omitempty"`
+	// NearestHCA is the nearest RDMA device (e.g., "mlx5_0"), empty if none.
+	NearestHCA string `json:"nearest_hca,omitempty"`
+	// Level is the topology level between the GPU and the nearest HCA.
+	Level Level `json:"level,omitempty"`
+}
+
+// LinkLayerInfiniband is the link layer of the infiniband ports.
+const LinkLayerInfiniband = "InfiniBand"
+
+// GetHCADevicePaths returns the resolved sysfs device paths of the RDMA devices
+// used for the GPUDirect RDMA, keyed by the device name (e.g., "mlx5_0").
+// Only the devices with an infiniband port are returned, to exclude the ethernet
+// (e.g., the frontend or the management) NICs, unless the host has no infiniband
+// port at all (e.g., the RoCE fabric), in which case all the devices are returned.
+func GetHCADevicePaths(classDir string) (map[string]string, error) {
+	entries, err := os.ReadDir(classDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	paths := make(map[string]string, len(entries))
+	ibPaths := make(map[string]string, len(entries))
+	for _, e := range entries {
+		p, err := filepath.EvalSymlinks(filepath.Join(classDir, e.Name(), "device"))
+		if err != nil {
+			// e.g., the software RDMA device (rxe) without the PCI device
+			continue
+		}
+		paths[e.Name()] = p
+		if hasInfinibandPort(filepath.Join(classDir, e.Name(), "ports")) {
+			ibPaths[e.Name()] = p
+		}
+	}
+	if len(ibPaths) > 0 {
+		return ibPaths, nil
+	}
+	return paths, nil
+}
+
+// hasInfinibandPort returns true if any port of the RDMA device has the infiniband link layer.
+func hasInfinibandPort(portsDir string) bool {
+	ports, err := os.ReadDir(portsDir)
+	if err != nil {
+		return false
+	}
+	for _, port := range ports {
+		if readValue(filepath.Join(portsDir, port.Name(), "link_layer")) == LinkLayerInfiniband {
+			return true
+		}
+	}
+	return false
+}
+
+// GetGPUDevicePath returns the resolved sysfs device path of the GPU by its PCI bus ID.
+func GetGPUDevicePath(pciSysfsRoot string, busID string) (string, error) {
+	return filepath.EvalSymlinks(filepath.Join(pciSysfsRoot, "devices", pci.NormalizeBusID(busID)))
+}
+
+// GetTopology returns the nearest HCA of each GPU, sorted by the GPU bus ID.
+// The GPU device paths are keyed by the bus ID, with the UUIDs (if known) in the uuids.
+func GetTopology(gpuPaths map[string]string, uuids map[string]string, hcaPaths map[string]string) ([]GPUTopology, error) {
+	hcas := make([]string, 0, len(hcaPaths))
+	for name := range hcaPaths {
+		hcas = append(hcas, name)
+	}
+	sort.Strings(hcas)
+
+	topo := make([]GPUTopology, 0, len(gpuPaths))
+	for busID, gpuPath := range gpuPaths {
+		t := GPUTopology{BusID: busID, UUID: uuids[busID]}
+		for _, hca := range hcas {
+			level, err := GetLevel(gpuPath, hcaPaths[hca])
+			if err != nil {
+				return nil, fmt.Errorf("failed to get the topology level of GPU %s and %s: %w", busID, hca, err)
+			}
+			if t.NearestHCA == "" || t.Level.FartherThan(level) {
+				t.NearestHCA, t.Level = hca, level
+			}
+		}
+		topo = append(topo, t)
+	}
+	sort.Slice(topo, func(i, j int) bool {
+		return topo[i].BusID < topo[j].BusID
+	})
+	return topo, nil
+}
+
+// GetLevel returns the topology level between the two devices by their
+// resolved sysfs device paths (e.g., "/sys/devices/pci0000:00/0000:00:01.0/0000:01:00.0"),
+// approximating the "nvidia-smi topo -m" levels from the PCIe hierarchy.
+func GetLevel(pathA string, pathB string) (Level, error) {
+	rootA, chainA, err := splitPCIPath(pathA)
+	if err != nil {
+		return "", err
+	}
+	rootB, chainB, err := splitPCIPath(pathB)
+	if err != nil {
+		return "", err
+	}
+
+	if rootA != rootB {
+		nodeA := readValue(filepath.Join(pathA, "numa_node"))
+		nodeB := readValue(filepath.Join(pathB, "numa_node"))
+		if nodeA == nodeB {
+			return LevelNODE, nil
+		}
+		return LevelSYS, nil
+	}
+
+	// the bridges shared by both devices under the host bridge
+	common := 0
+	for common < len(chainA)-1 && common < len(chainB)-1 && chainA[common] == chainB[common] {
+		common++
+	}
+	if common == 0 {
+		return LevelPHB, nil
+	}
+
+	// the bridges below the shared bridges, excluding the devices themselves
+	belowA := len(chainA) - 1 - common
+	belowB := len(chainB) - 1 - common
+	if belowA <= 1 && belowB <= 1 {
+		return LevelPIX, nil
+	}
+	return LevelPXB, nil
+}
+
+// splitPCIPath splits the sysfs device path into the PCI host bridge (e.g., "pci0000:00")
+// and the chain of the bridges and the device under it.
+func splitPCIPath(p string) (string, []string, error) {
+	parts := strings.Split(filepath.Clean(p), string(filepath.Separator))
+	for i, part := range parts {
+		if strings.HasPrefix(part, "pci") && i+1 < len(parts) {
+			return part, parts[i+1:], nil
+		}
+	}
+	return "", nil, fmt.Errorf("no PCI host bridge in the device path %q", p)
+}
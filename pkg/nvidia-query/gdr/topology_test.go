@@ -0,0 +1,132 @@
+package gdr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelFartherThan(t *testing.T) {
+	assert.True(t, LevelSYS.FartherThan(LevelNODE))
+	assert.True(t, LevelPHB.FartherThan(DefaultMaxLevel))
+	assert.False(t, LevelPIX.FartherThan(DefaultMaxLevel))
+	assert.False(t, LevelPXB.FartherThan(LevelPXB))
+}
+
+func TestLevelCrossesHostBridges(t *testing.T) {
+	assert.False(t, LevelPHB.CrossesHostBridges())
+	assert.True(t, LevelNODE.CrossesHostBridges())
+	assert.True(t, LevelSYS.CrossesHostBridges())
+}
+
+func TestGetLevel(t *testing.T) {
+	root := t.TempDir()
+	dev := func(p string, numaNode string) string {
+		full := filepath.Join(root, "devices", p)
+		writeFile(t, filepath.Join(full, "numa_node"), numaNode+"\n")
+		return full
+	}
+
+	gpu0 := dev("pci0000:00/0000:00:01.0/0000:01:00.0/0000:02:00.0/0000:03:00.0", "0")
+	hca0 := dev("pci0000:00/0000:00:01.0/0000:01:00.0/0000:02:01.0/0000:04:00.0", "0")
+	hca1 := dev("pci0000:00/0000:00:01.0/0000:01:00.0/0000:02:02.0/0000:05:00.0/0000:06:00.0/0000:07:00.0", "0")
+	hca2 := dev("pci0000:00/0000:00:02.0/0000:08:00.0", "0")
+	hca3 := dev("pci0000:20/0000:20:01.0/0000:21:00.0", "0")
+	hca4 := dev("pci0000:80/0000:80:01.0/0000:81:00.0", "1")
+
+	tests := []struct {
+		name string
+		hca  string
+		want Level
+	}{
+		{name: "same switch", hca: hca0, want: LevelPIX},
+		{name: "multiple switches", hca: hca1, want: LevelPXB},
+		{name: "host bridge", hca: hca2, want: LevelPHB},
+		{name: "same numa node", hca: hca3, want: LevelNODE},
+		{name: "cross numa node", hca: hca4, want: LevelSYS},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level, err := GetLevel(gpu0, tt.hca)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, level)
+		})
+	}
+
+	_, err := GetLevel("/sys/devices/virtual/net/lo", hca0)
+	assert.Error(t, err)
+}
+
+func TestGetTopology(t *testing.T) {
+	root := t.TempDir()
+	devicesDir := filepath.Join(root, "sys", "devices")
+	classDir := filepath.Join(root, "sys", "class", "infiniband")
+	pciRoot := filepath.Join(root, "sys", "bus", "pci")
+
+	devices := map[string]string{
+		"gpu0":   "pci0000:00/0000:00:01.0/0000:01:00.0/0000:02:00.0/0000:03:00.0",
+		"gpu1":   "pci0000:80/0000:80:01.0/0000:81:00.0/0000:82:00.0/0000:83:00.0",
+		"mlx5_0": "pci0000:00/0000:00:01.0/0000:01:00.0/0000:02:01.0/0000:04:00.0",
+		"mlx5_1": "pci0000:80/0000:80:01.0/0000:81:00.0/0000:82:01.0/0000:84:00.0",
+	}
+	for name, p := range devices {
+		numaNode := "0"
+		if name == "gpu1" || name == "mlx5_1" {
+			numaNode = "1"
+		}
+		writeFile(t, filepath.Join(devicesDir, p, "numa_node"), numaNode)
+
+		if name == "mlx5_0" || name == "mlx5_1" {
+			require.NoError(t, os.MkdirAll(filepath.Join(classDir, name), 0755))
+			require.NoError(t, os.Symlink(filepath.Join(devicesDir, p), filepath.Join(classDir, name, "device")))
+		} else {
+			require.NoError(t, os.MkdirAll(filepath.Join(pciRoot, "devices"), 0755))
+			require.NoError(t, os.Symlink(filepath.Join(devicesDir, p), filepath.Join(pciRoot, "devices", filepath.Base(p))))
+		}
+	}
+	// the software RDMA device without the PCI device
+	require.NoError(t, os.MkdirAll(filepath.Join(classDir, "rxe0"), 0755))
+
+	// without the infiniband port (e.g., the RoCE fabric), all the devices are used
+	hcaPaths, err := GetHCADevicePaths(classDir)
+	require.NoError(t, err)
+	assert.Len(t, hcaPaths, 2)
+
+	// the ethernet NIC is excluded once the host has the infiniband ports
+	mgmtPath := filepath.Join(devicesDir, "pci0000:00/0000:00:03.0/0000:09:00.0")
+	writeFile(t, filepath.Join(mgmtPath, "numa_node"), "0")
+	require.NoError(t, os.MkdirAll(filepath.Join(classDir, "mlx5_2"), 0755))
+	require.NoError(t, os.Symlink(mgmtPath, filepath.Join(classDir, "mlx5_2", "device")))
+	writeFile(t, filepath.Join(classDir, "mlx5_2", "ports", "1", "link_layer"), "Ethernet\n")
+	hcaPaths, err = GetHCADevicePaths(classDir)
+	require.NoError(t, err)
+	assert.Len(t, hcaPaths, 3)
+
+	writeFile(t, filepath.Join(classDir, "mlx5_0", "ports", "1", "link_layer"), "InfiniBand\n")
+	writeFile(t, filepath.Join(classDir, "mlx5_1", "ports", "1", "link_layer"), "InfiniBand\n")
+	hcaPaths, err = GetHCADevicePaths(classDir)
+	require.NoError(t, err)
+	assert.Len(t, hcaPaths, 2)
+	assert.NotContains(t, hcaPaths, "mlx5_2")
+
+	gpuPaths := make(map[string]string)
+	for _, busID := range []string{"0000:03:00.0", "0000:83:00.0"} {
+		p, err := GetGPUDevicePath(pciRoot, busID)
+		require.NoError(t, err)
+		gpuPaths[busID] = p
+	}
+
+	topo, err := GetTopology(gpuPaths, map[string]string{"0000:03:00.0": "GPU-0"}, hcaPaths)
+	require.NoError(t, err)
+	assert.Equal(t, []GPUTopology{
+		{BusID: "0000:03:00.0", UUID: "GPU-0", NearestHCA: "mlx5_0", Level: LevelPIX},
+		{BusID: "0000:83:00.0", NearestHCA: "mlx5_1", Level: LevelPIX},
+	}, topo)
+
+	hcaPaths, err = GetHCADevicePaths(filepath.Join(root, "nonexistent"))
+	require.NoError(t, err)
+	assert.Empty(t, hcaPaths)
+}
@@ -0,0 +1,286 @@
+// Package gds queries the GPUDirect Storage (GDS) prerequisites,
+// the nvidia-fs kernel module, the cuFile configuration, and the filesystems
+// GDS can read from and write to directly.
+package gds
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultSysModuleDir is the sysfs directory of the loaded nvidia-fs module.
+	DefaultSysModuleDir = "/sys/module/nvidia_fs"
+	// DefaultCufileConfigPath is the default cuFile configuration path.
+	DefaultCufileConfigPath = "/etc/cufile.json"
+	// DefaultProcMountsPath is the list of the mounted filesystems.
+	DefaultProcMountsPath = "/proc/mounts"
+	// DefaultNvfsStatsPath is the nvidia-fs statistics, present while the module is loaded.
+	DefaultNvfsStatsPath = "/proc/driver/nvidia-fs/stats"
+
+	// envCufileConfigPath overrides the cuFile configuration path, same as the cuFile library.
+	envCufileConfigPath = "CUFILE_ENV_PATH_JSON"
+)
+
+var ErrNoCufileConfig = errors.New("cufile config not found, GDS not installed")
+
+// GetLoadedModuleVersion returns the version of the loaded nvidia-fs module,
+// and false if not loaded.
+func GetLoadedModuleVersion(moduleDir string) (string, bool, error) {
+	if _, err := os.Stat(moduleDir); err != nil {
+		if os.IsNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	b, err := os.ReadFile(filepath.Join(moduleDir, "version"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", true, nil
+		}
+		return "", true, err
+	}
+	return strings.TrimSpace(string(b)), true, nil
+}
+
+// CufileConfigPath returns the cuFile configuration path the cuFile library reads.
+func CufileConfigPath() string {
+	if p := os.Getenv(envCufileConfigPath); p != "" {
+		return p
+	}
+	return DefaultCufileConfigPath
+}
+
+// CufileConfig is the subset of the cuFile configuration relevant to the GDS readiness.
+type CufileConfig struct {
+	Path string `json:"path"`
+
+	// AllowCompatMode is true if cuFile silently falls back to the POSIX I/O
+	// (the compatibility mode) when GDS is not available, true by default.
+	AllowCompatMode bool `json:"allow_compat_mode"`
+}
+
+type rawCufileConfig struct {
+	Properties struct {
+		AllowCompatMode *bool `json:"allow_compat_mode"`
+	} `json:"properties"`
+}
+
+// ReadCufileConfig reads the cuFile configuration,
+// and returns ErrNoCufileConfig if it does not exist.
+func ReadCufileConfig(path string) (*CufileConfig, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoCufileConfig
+		}
+		return nil, err
+	}
+
+	var raw rawCufileConfig
+	if err := json.Unmarshal(stripComments(b), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse cufile config %q: %w", path, err)
+	}
+
+	cfg := &CufileConfig{Path: path, AllowCompatMode: true}
+	if raw.Properties.AllowCompatMode != nil {
+		cfg.AllowCompatMode = *raw.Properties.AllowCompatMode
+	}
+	return cfg, nil
+}
+
+// stripComments removes the "//" line comments of the cuFile configuration,
+// outside the string values.
+func stripComments(b []byte) []byte {
+	var out bytes.Buffer
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		inString, escaped := false, false
+		cut := len(line)
+		for i := 0; i < len(line); i++ {
+			switch {
+			case escaped:
+				escaped = false
+			case line[i] == '\\' && inString:
+				escaped = true
+			case line[i] == '"':
+				inString = !inString
+			case !inString && line[i] == '/' && i+1 < len(line) && line[i+1] == '/':
+				cut = i
+			}
+			if cut != len(line) {
+				break
+			}
+		}
+		out.Write(line[:cut])
+		out.WriteByte('\n')
+	}
+	return out.Bytes()
+}
+
+// Mount is the mounted filesystem GDS supports.
+type Mount struct {
+	Device     string `json:"device"`
+	MountPoint string `json:"mount_point"`
+	FsType     string `json:"fs_type"`
+}
+
+// localFsTypes are the local filesystems GDS supports on the NVMe devices.
+var localFsTypes = map[string]struct{}{
+	"ext4": {},
+	"xfs":  {},
+}
+
+// distributedFsTypes are the distributed filesystems GDS supports,
+// in addition to NFS over RDMA.
+var distributedFsTypes = map[string]struct{}{
+	"lustre": {},
+	"wekafs": {},
+	"gpfs":   {},
+	"beegfs": {},
+}
+
+// systemMountPoints are the OS mount points, never used for the GDS datasets
+// even though their filesystems are supported.
+var systemMountPoints = map[string]struct{}{
+	"/":         {},
+	"/boot":     {},
+	"/boot/efi": {},
+	"/usr":      {},
+	"/var":      {},
+	"/var/lib":  {},
+	"/var/log":  {},
+	"/home":     {},
+	"/tmp":      {},
+	"/snap":     {},
+}
+
+// compatible returns true if GDS supports the filesystem on the device with the mount options.
+func compatible(device string, mountPoint string, fsType string, options string) bool {
+	if _, ok := distributedFsTypes[fsType]; ok {
+		return true
+	}
+
+	opts := strings.Split(options, ",")
+	if fsType == "nfs" || fsType == "nfs4" {
+		for _, opt := range opts {
+			if opt == "proto=rdma" {
+				return true
+			}
+		}
+		return false
+	}
+
+	if _, ok := localFsTypes[fsType]; !ok {
+		return false
+	}
+	if _, ok := systemMountPoints[mountPoint]; ok {
+		return false
+	}
+	// e.g., "/etc/hosts" bind-mounted into the container
+	if strings.HasPrefix(mountPoint, "/etc/") {
+		return false
+	}
+	// GDS reads directly from the NVMe devices (or the software RAID across them),
+	// not from the SATA/SAS disks or the virtual block devices
+	if !strings.HasPrefix(device, "/dev/nvme") && !strings.HasPrefix(device, "/dev/md") {
+		return false
+	}
+	// only the ext4 ordered mode (the default) is supported
+	for _, opt := range opts {
+		if opt == "data=journal" || opt == "data=writeback" {
+			return false
+		}
+	}
+	return true
+}
+
+// GetCompatibleMounts returns the mounted filesystems GDS supports, sorted by the mount point.
+// The local filesystems only count if mounted from the NVMe devices outside the OS mount points
+// (e.g., the root filesystem is never counted).
+func GetCompatibleMounts(procMountsPath string) ([]Mount, error) {
+	f, err := os.Open(procMountsPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var mounts []Mount
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// e.g., "/dev/nvme0n1p1 /data ext4 rw,relatime 0 0"
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		if !compatible(fields[0], fields[1], fields[2], fields[3]) {
+			continue
+		}
+		mounts = append(mounts, Mount{Device: fields[0], MountPoint: fields[1], FsType: fields[2]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(mounts, func(i, j int) bool {
+		return mounts[i].MountPoint < mounts[j].MountPoint
+	})
+	return mounts, nil
+}
+
+// GetNvfsReadOps returns the number of the reads served by nvidia-fs
+// (i.e., the GPUDirect reads), and false if the statistics are not available.
+// The reads in the compatibility mode bypass nvidia-fs and never increase the count.
+func GetNvfsReadOps(statsPath string) (uint64, bool, error) {
+	b, err := os.ReadFile(statsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	n, ok := parseNvfsReadOps(string(b))
+	return n, ok, nil
+}
+
+// e.g.,
+// "Ops                             : Read=128 Write=0 BatchIO=0" (nvidia-fs 2.17+)
+// "Reads                           : n=128 ok=128 err=0 readMiB=1024 io_state_err=0" (older)
+func parseNvfsReadOps(stats string) (uint64, bool) {
+	for _, line := range strings.Split(stats, "\n") {
+		name, values, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		var key string
+		switch strings.TrimSpace(name) {
+		case "Ops":
+			key = "Read"
+		case "Reads":
+			key = "n"
+		default:
+			continue
+		}
+		for _, field := range strings.Fields(values) {
+			k, v, ok := strings.Cut(field, "=")
+			if !ok || k != key {
+				continue
+			}
+			n, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				continue
+			}
+			return n, true
+		}
+	}
+	return 0, false
+}
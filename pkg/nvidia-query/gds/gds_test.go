@@ -0,0 +1,146 @@
+package gds
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLoadedModuleVersion(t *testing.T) {
+	dir := t.TempDir()
+
+	_, loaded, err := GetLoadedModuleVersion(filepath.Join(dir, "nvidia_fs"))
+	require.NoError(t, err)
+	assert.False(t, loaded)
+
+	moduleDir := filepath.Join(dir, "nvidia_fs")
+	require.NoError(t, os.MkdirAll(moduleDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(moduleDir, "version"), []byte("2.20.5\n"), 0644))
+
+	version, loaded, err := GetLoadedModuleVersion(moduleDir)
+	require.NoError(t, err)
+	assert.True(t, loaded)
+	assert.Equal(t, "2.20.5", version)
+}
+
+func TestReadCufileConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := ReadCufileConfig(filepath.Join(dir, "cufile.json"))
+	assert.True(t, errors.Is(err, ErrNoCufileConfig))
+
+	p := filepath.Join(dir, "cufile.json")
+	require.NoError(t, os.WriteFile(p, []byte(`{
+    // NOTE : Application can override custom configuration via export CUFILE_ENV_PATH_JSON=<filepath>
+    "logging": {
+        "dir": "/var/log/cufile", // log directory
+        "level": "ERROR"
+    },
+    "properties": {
+        "max_direct_io_size_kb" : 16384,
+        // allow compat mode, this will enable use of cuFile posix read/writes
+        "allow_compat_mode": false
+    }
+}`), 0644))
+	cfg, err := ReadCufileConfig(p)
+	require.NoError(t, err)
+	assert.False(t, cfg.AllowCompatMode)
+
+	// compat mode is allowed by default
+	require.NoError(t, os.WriteFile(p, []byte(`{"properties": {}}`), 0644))
+	cfg, err = ReadCufileConfig(p)
+	require.NoError(t, err)
+	assert.True(t, cfg.AllowCompatMode)
+
+	require.NoError(t, os.WriteFile(p, []byte(`{"properties": `), 0644))
+	_, err = ReadCufileConfig(p)
+	assert.Error(t, err)
+}
+
+func TestCufileConfigPath(t *testing.T) {
+	t.Setenv(envCufileConfigPath, "")
+	assert.Equal(t, DefaultCufileConfigPath, CufileConfigPath())
+
+	t.Setenv(envCufileConfigPath, "/opt/cufile.json")
+	assert.Equal(t, "/opt/cufile.json", CufileConfigPath())
+}
+
+func TestGetCompatibleMounts(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "mounts")
+	require.NoError(t, os.WriteFile(p, []byte(`proc /proc proc rw,nosuid,nodev,noexec,relatime 0 0
+/dev/nvme0n1p1 / ext4 rw,relatime 0 0
+/dev/nvme0n1p15 /boot/efi vfat rw,relatime 0 0
+/dev/md0 /data xfs rw,relatime 0 0
+/dev/nvme1n1 /scratch ext4 rw,relatime 0 0
+/dev/nvme2n1 /journal ext4 rw,relatime,data=journal 0 0
+/dev/sda1 /mnt/disk ext4 rw,relatime 0 0
+/dev/vdb /mnt/vdisk xfs rw,relatime 0 0
+10.0.0.3@o2ib:/lustre /mnt/lustre lustre rw,flock 0 0
+10.0.0.1:/export /mnt/nfs nfs4 rw,relatime,vers=4.1,proto=tcp 0 0
+10.0.0.2:/export /mnt/nfs-rdma nfs4 rw,relatime,vers=4.1,proto=rdma,port=20049 0 0
+tmpfs /run tmpfs rw,nosuid,nodev 0 0
+`), 0644))
+
+	mounts, err := GetCompatibleMounts(p)
+	require.NoError(t, err)
+	assert.Equal(t, []Mount{
+		{Device: "/dev/md0", MountPoint: "/data", FsType: "xfs"},
+		{Device: "10.0.0.3@o2ib:/lustre", MountPoint: "/mnt/lustre", FsType: "lustre"},
+		{Device: "10.0.0.2:/export", MountPoint: "/mnt/nfs-rdma", FsType: "nfs4"},
+		{Device: "/dev/nvme1n1", MountPoint: "/scratch", FsType: "ext4"},
+	}, mounts)
+}
+
+func TestGetNvfsReadOps(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "stats")
+
+	_, ok, err := GetNvfsReadOps(p)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, os.WriteFile(p, []byte(`GDS Version: 1.9.0.20
+NVFS statistics(ver: 4.0)
+NVFS Driver(version: 2.17.5)
+Mellanox PeerDirect Supported: True
+IO stats: Enabled, peer IO stats: Disabled
+Logging level: info
+
+Active Shadow-Buffer (MiB): 0
+Active Process: 0
+Reads                           : err=0 io_state_err=0
+Sparse Reads                    : n=6 io=0 holes=0 pages=0
+Writes                          : err=0 io_state_err=0 pg-cache=0 pg-cache-fail=0 pg-cache-eio=0
+Ops                             : Read=4096 Write=0 BatchIO=0
+`), 0644))
+	n, ok, err := GetNvfsReadOps(p)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(4096), n)
+
+	n, ok = parseNvfsReadOps("Reads                           : n=128 ok=128 err=0 readMiB=1024 io_state_err=0\n")
+	assert.True(t, ok)
+	assert.Equal(t, uint64(128), n)
+
+	_, ok = parseNvfsReadOps("NVFS statistics(ver: 4.0)\n")
+	assert.False(t, ok)
+}
+
+func TestParseGdsioOutput(t *testing.T) {
+	r, err := parseGdsioOutput("IoType: READ XferType: GPUD Threads: 4 DataSetSize: 4194304/4194304(KiB) IOSize: 1024(KiB) Throughput: 3.245 GiB/sec, Avg_Latency: 1203.42 usecs ops: 4096 total_time 1.23 secs\n")
+	require.NoError(t, err)
+	assert.Equal(t, "GPUD", r.XferType)
+	assert.Equal(t, 3.245, r.ThroughputGiBps)
+
+	_, err = parseGdsioOutput("file register error: GPUDirect Storage not supported on current file")
+	assert.True(t, errors.Is(err, ErrNoThroughput))
+}
+
+func TestProbeConfigValidate(t *testing.T) {
+	assert.NoError(t, ProbeConfig{Dir: "/data"}.Validate())
+	assert.Error(t, ProbeConfig{Dir: "/data", GPUIndex: -1}.Validate())
+	assert.Error(t, ProbeConfig{Dir: "/data", MinThroughputGiBps: -1}.Validate())
+}
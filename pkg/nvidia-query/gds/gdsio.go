@@ -0,0 +1,107 @@
+package gds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/toolexec"
+)
+
+const (
+	// DefaultProbeSize is the default size of the file the throughput probe reads.
+	DefaultProbeSize = "1G"
+	// DefaultProbeTimeout is the default timeout of the throughput probe.
+	DefaultProbeTimeout = 2 * time.Minute
+)
+
+// XferTypeGPUDirect is the gdsio transfer type of the GPUDirect reads.
+const XferTypeGPUDirect = "GPUD"
+
+var ErrNoThroughput = errors.New("no throughput in the gdsio output")
+
+// ProbeConfig is the optional throughput probe with "gdsio".
+type ProbeConfig struct {
+	// Dir is the directory on the GDS compatible filesystem to read the probe file in.
+	// Empty to disable the probe.
+	Dir string `json:"dir"`
+	// GPUIndex is the GPU to read the probe file into.
+	GPUIndex int `json:"gpu_index"`
+	// MinThroughputGiBps is the read throughput in GiB/s below which the GDS is degraded
+	// (e.g., the slow storage fabric). Zero to only report the throughput.
+	// The compatibility mode fallback is detected regardless of the threshold.
+	MinThroughputGiBps float64 `json:"min_throughput_gibps,omitempty"`
+}
+
+// Validate validates the probe config.
+func (cfg ProbeConfig) Validate() error {
+	if cfg.GPUIndex < 0 {
+		return fmt.Errorf("invalid gds probe gpu index %d", cfg.GPUIndex)
+	}
+	if cfg.MinThroughputGiBps < 0 {
+		return fmt.Errorf("invalid gds probe min throughput %f", cfg.MinThroughputGiBps)
+	}
+	return nil
+}
+
+// ProbeResult is the result of the throughput probe.
+type ProbeResult struct {
+	// XferType is the transfer type reported by gdsio
+	// (e.g., "GPUD" for the GPUDirect, "CPUONLY" for the compatibility mode).
+	XferType string `json:"xfer_type"`
+	// ThroughputGiBps is the read throughput in GiB/s.
+	ThroughputGiBps float64 `json:"throughput_gibps"`
+}
+
+// RunProbe reads the probe file in the directory into the GPU with the GPUDirect transfer,
+// and returns the read throughput.
+func RunProbe(ctx context.Context, cfg ProbeConfig) (*ProbeResult, error) {
+	res, err := toolexec.Run(ctx, gdsioCommand(cfg), toolexec.WithTimeout(DefaultProbeTimeout))
+	if err != nil {
+		return nil, err
+	}
+	return parseGdsioOutput(string(res.Output))
+}
+
+func gdsioCommand(cfg ProbeConfig) []string {
+	return []string{
+		"gdsio",
+		"-D", cfg.Dir,
+		"-d", strconv.Itoa(cfg.GPUIndex),
+		"-w", "4",
+		"-s", DefaultProbeSize,
+		"-i", "1M",
+		// GPUDirect transfer
+		"-x", "0",
+		// sequential read
+		"-I", "0",
+		"-T", "10",
+	}
+}
+
+// e.g.,
+// "IoType: READ XferType: GPUD Threads: 4 DataSetSize: 4194304/4194304(KiB) IOSize: 1024(KiB) Throughput: 3.245 GiB/sec, Avg_Latency: 1203.42 usecs ops: 4096 total_time 1.23 secs"
+var (
+	xferTypeRegex   = regexp.MustCompile(`XferType:\s*(\S+)`)
+	throughputRegex = regexp.MustCompile(`Throughput:\s*([0-9.]+)\s*GiB/sec`)
+)
+
+func parseGdsioOutput(out string) (*ProbeResult, error) {
+	m := throughputRegex.FindStringSubmatch(out)
+	if len(m) != 2 {
+		return nil, ErrNoThroughput
+	}
+	throughput, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &ProbeResult{ThroughputGiBps: throughput}
+	if m := xferTypeRegex.FindStringSubmatch(out); len(m) == 2 {
+		r.XferType = m[1]
+	}
+	return r, nil
+}
@@ -2,7 +2,9 @@ package infiniband
 
 // IBPort is the port of the IB card.
 type IBPort struct {
-	Device        string
+	Device string
+	// Port is the port number of the device.
+	Port          int
 	State         string
 	PhysicalState string
 	Rate          int
@@ -0,0 +1,235 @@
+package infiniband
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultClassDir is the sysfs directory of the infiniband devices.
+const DefaultClassDir = "/sys/class/infiniband"
+
+const (
+	// CounterSymbolError is the number of the minor link errors detected on the physical lanes.
+	CounterSymbolError = "symbol_error"
+	// CounterLinkDowned is the number of times the link error recovery failed and the link went down.
+	CounterLinkDowned = "link_downed"
+	// CounterPortRcvErrors is the number of the packets received with the errors.
+	CounterPortRcvErrors = "port_rcv_errors"
+	// CounterExcessiveBufferOverrunErrors is the number of the consecutive flow control
+	// update periods with the buffer overruns.
+	CounterExcessiveBufferOverrunErrors = "excessive_buffer_overrun_errors"
+)
+
+// counterMaxes are the values at which the error counters saturate. The counters
+// are the PMA PortCounters attribute fields, which stop at the max instead of wrapping,
+// so the further increases are not visible until the counters are reset.
+var counterMaxes = map[string]uint64{
+	CounterSymbolError:                  1<<16 - 1,
+	CounterLinkDowned:                   1<<8 - 1,
+	CounterPortRcvErrors:                1<<16 - 1,
+	CounterExcessiveBufferOverrunErrors: 1<<4 - 1,
+}
+
+// PortCounters is the error counters of an infiniband port,
+// read from "/sys/class/infiniband/<device>/ports/<port>/counters".
+type PortCounters struct {
+	// Device is the port device name (e.g., "mlx5_0").
+	Device string `json:"device"`
+	// Port is the port number of the device.
+	Port int `json:"port"`
+
+	SymbolError                  uint64 `json:"symbol_error"`
+	LinkDowned                   uint64 `json:"link_downed"`
+	PortRcvErrors                uint64 `json:"port_rcv_errors"`
+	ExcessiveBufferOverrunErrors uint64 `json:"excessive_buffer_overrun_errors"`
+}
+
+// Key returns the unique key of the port (e.g., "mlx5_0/1").
+func (pc PortCounters) Key() string {
+	return fmt.Sprintf("%s/%d", pc.Device, pc.Port)
+}
+
+// GetPortCounters reads the error counters of all the infiniband ports,
+// sorted by the device and the port. Returns no counters if the
+// class directory does not exist (no infiniband device).
+// The counters missing in the port (e.g., the ethernet link layer) are zero.
+func GetPortCounters(classDir string) ([]PortCounters, error) {
+	devices, err := os.ReadDir(classDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var counters []PortCounters
+	for _, dev := range devices {
+		portsDir := filepath.Join(classDir, dev.Name(), "ports")
+		ports, err := os.ReadDir(portsDir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		for _, port := range ports {
+			portNum, err := strconv.Atoi(port.Name())
+			if err != nil {
+				continue
+			}
+
+			countersDir := filepath.Join(portsDir, port.Name(), "counters")
+			if _, err := os.Stat(countersDir); err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return nil, err
+			}
+
+			pc := PortCounters{Device: dev.Name(), Port: portNum}
+			for name, v := range map[string]*uint64{
+				CounterSymbolError:                  &pc.SymbolError,
+				CounterLinkDowned:                   &pc.LinkDowned,
+				CounterPortRcvErrors:                &pc.PortRcvErrors,
+				CounterExcessiveBufferOverrunErrors: &pc.ExcessiveBufferOverrunErrors,
+			} {
+				*v, err = readCounter(filepath.Join(countersDir, name))
+				if err != nil {
+					return nil, err
+				}
+			}
+			counters = append(counters, pc)
+		}
+	}
+
+	sort.Slice(counters, func(i, j int) bool {
+		if counters[i].Device != counters[j].Device {
+			return counters[i].Device < counters[j].Device
+		}
+		return counters[i].Port < counters[j].Port
+	})
+	return counters, nil
+}
+
+// readCounter reads the counter value, zero if the counter does not exist.
+func readCounter(path string) (uint64, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	v, err := strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse counter %q: %w", path, err)
+	}
+	return v, nil
+}
+
+// DefaultCounterDeltaThresholds are the default increases of the port error counters
+// within the counter window, at or beyond which the port is degraded.
+var DefaultCounterDeltaThresholds = CounterDeltaThresholds{
+	SymbolError:                  100,
+	LinkDowned:                   1,
+	PortRcvErrors:                100,
+	ExcessiveBufferOverrunErrors: 1,
+}
+
+// CounterDeltaThresholds are the increases of the port error counters
+// within the counter window, at or beyond which the port is degraded,
+// to flag the slowly degrading links before they drop.
+// Zero fields do not evaluate the counter.
+type CounterDeltaThresholds struct {
+	SymbolError                  uint64 `json:"symbol_error,omitempty"`
+	LinkDowned                   uint64 `json:"link_downed,omitempty"`
+	PortRcvErrors                uint64 `json:"port_rcv_errors,omitempty"`
+	ExcessiveBufferOverrunErrors uint64 `json:"excessive_buffer_overrun_errors,omitempty"`
+}
+
+// IsZero returns true if no counter is evaluated.
+func (t CounterDeltaThresholds) IsZero() bool {
+	return t == CounterDeltaThresholds{}
+}
+
+// CounterDelta is the increase of a port error counter at or beyond its threshold.
+type CounterDelta struct {
+	// Device is the port device name (e.g., "mlx5_0").
+	Device string `json:"device"`
+	// Port is the port number of the device.
+	Port int `json:"port"`
+	// Counter is the counter name (e.g., "symbol_error").
+	Counter string `json:"counter"`
+	// Delta is the increase of the counter within the counter window.
+	Delta uint64 `json:"delta"`
+	// Threshold is the increase at or beyond which the port is degraded.
+	Threshold uint64 `json:"threshold"`
+	// Saturated is true if the counter stopped at its max value,
+	// so the further increases are not visible until the counters are reset.
+	Saturated bool `json:"saturated,omitempty"`
+}
+
+func (d CounterDelta) String() string {
+	if d.Saturated {
+		return fmt.Sprintf("%s port %d %s saturated (increases no longer visible until the counters are reset)", d.Device, d.Port, d.Counter)
+	}
+	return fmt.Sprintf("%s port %d %s increased by %d (threshold %d)", d.Device, d.Port, d.Counter, d.Delta, d.Threshold)
+}
+
+// ExceededDeltas returns the counters of the port that increased at or beyond
+// the thresholds over the readings (oldest first), in the order of the counter names.
+// The increase is summed across the consecutive readings, so the counter resets
+// in between do not hide the increases before them. The saturated counters are
+// returned regardless of the increase, since their increases are no longer visible.
+func (t CounterDeltaThresholds) ExceededDeltas(readings ...PortCounters) []CounterDelta {
+	if len(readings) < 2 {
+		return nil
+	}
+	cur := readings[len(readings)-1]
+
+	var exceeded []CounterDelta
+	for _, c := range []struct {
+		name      string
+		value     func(PortCounters) uint64
+		threshold uint64
+	}{
+		{CounterSymbolError, func(pc PortCounters) uint64 { return pc.SymbolError }, t.SymbolError},
+		{CounterLinkDowned, func(pc PortCounters) uint64 { return pc.LinkDowned }, t.LinkDowned},
+		{CounterPortRcvErrors, func(pc PortCounters) uint64 { return pc.PortRcvErrors }, t.PortRcvErrors},
+		{CounterExcessiveBufferOverrunErrors, func(pc PortCounters) uint64 { return pc.ExcessiveBufferOverrunErrors }, t.ExcessiveBufferOverrunErrors},
+	} {
+		if c.threshold == 0 {
+			continue
+		}
+		var d uint64
+		for i := 1; i < len(readings); i++ {
+			d += counterDelta(c.value(readings[i-1]), c.value(readings[i]))
+		}
+		saturated := c.value(cur) >= counterMaxes[c.name]
+		if d >= c.threshold || saturated {
+			exceeded = append(exceeded, CounterDelta{
+				Device:    cur.Device,
+				Port:      cur.Port,
+				Counter:   c.name,
+				Delta:     d,
+				Threshold: c.threshold,
+				Saturated: saturated,
+			})
+		}
+	}
+	return exceeded
+}
+
+// counterDelta returns the increase of the counter,
+// or the current value if the counter has been reset (e.g., "perfquery -R").
+func counterDelta(prev uint64, cur uint64) uint64 {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}
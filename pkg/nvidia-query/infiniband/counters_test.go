@@ -0,0 +1,93 @@
+package infiniband
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestCounters(t *testing.T, classDir string, device string, port string, counters map[string]string) {
+	dir := filepath.Join(classDir, device, "ports", port, "counters")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	for name, v := range counters {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(v+"\n"), 0644))
+	}
+}
+
+func TestGetPortCounters(t *testing.T) {
+	classDir := filepath.Join(t.TempDir(), "infiniband")
+
+	counters, err := GetPortCounters(classDir)
+	require.NoError(t, err)
+	assert.Empty(t, counters)
+
+	writeTestCounters(t, classDir, "mlx5_1", "1", map[string]string{
+		CounterSymbolError:                  "12",
+		CounterLinkDowned:                   "1",
+		CounterPortRcvErrors:                "3",
+		CounterExcessiveBufferOverrunErrors: "0",
+	})
+	writeTestCounters(t, classDir, "mlx5_0", "1", map[string]string{
+		CounterSymbolError: "7",
+	})
+	// no counters directory
+	require.NoError(t, os.MkdirAll(filepath.Join(classDir, "mlx5_2", "ports", "1"), 0755))
+
+	counters, err = GetPortCounters(classDir)
+	require.NoError(t, err)
+	assert.Equal(t, []PortCounters{
+		{Device: "mlx5_0", Port: 1, SymbolError: 7},
+		{Device: "mlx5_1", Port: 1, SymbolError: 12, LinkDowned: 1, PortRcvErrors: 3},
+	}, counters)
+	assert.Equal(t, "mlx5_0/1", counters[0].Key())
+
+	writeTestCounters(t, classDir, "mlx5_0", "1", map[string]string{
+		CounterLinkDowned: "invalid",
+	})
+	_, err = GetPortCounters(classDir)
+	assert.Error(t, err)
+}
+
+func TestCounterDeltaThresholdsExceededDeltas(t *testing.T) {
+	assert.True(t, CounterDeltaThresholds{}.IsZero())
+
+	thresholds := CounterDeltaThresholds{SymbolError: 100, PortRcvErrors: 10, ExcessiveBufferOverrunErrors: 1}
+	assert.False(t, thresholds.IsZero())
+
+	prev := PortCounters{Device: "mlx5_0", Port: 1, SymbolError: 1000, LinkDowned: 1, PortRcvErrors: 50}
+	cur := PortCounters{Device: "mlx5_0", Port: 1, SymbolError: 1099, LinkDowned: 5, PortRcvErrors: 60, ExcessiveBufferOverrunErrors: 2}
+	assert.Equal(t, []CounterDelta{
+		{Device: "mlx5_0", Port: 1, Counter: CounterPortRcvErrors, Delta: 10, Threshold: 10},
+		{Device: "mlx5_0", Port: 1, Counter: CounterExcessiveBufferOverrunErrors, Delta: 2, Threshold: 1},
+	}, thresholds.ExceededDeltas(prev, cur))
+
+	// the counters reset
+	cur = PortCounters{Device: "mlx5_0", Port: 1, SymbolError: 120}
+	deltas := thresholds.ExceededDeltas(prev, cur)
+	require.Len(t, deltas, 1)
+	assert.Equal(t, uint64(120), deltas[0].Delta)
+	assert.Equal(t, "mlx5_0 port 1 symbol_error increased by 120 (threshold 100)", deltas[0].String())
+
+	// the increases are summed across the readings, including the ones before a reset
+	deltas = thresholds.ExceededDeltas(
+		PortCounters{Device: "mlx5_0", Port: 1, SymbolError: 1000},
+		PortCounters{Device: "mlx5_0", Port: 1, SymbolError: 1060},
+		PortCounters{Device: "mlx5_0", Port: 1, SymbolError: 50},
+	)
+	require.Len(t, deltas, 1)
+	assert.Equal(t, uint64(110), deltas[0].Delta)
+
+	// a single reading has no increase
+	assert.Empty(t, thresholds.ExceededDeltas(cur))
+
+	// the saturated counters are reported, since the increases are no longer visible
+	saturated := PortCounters{Device: "mlx5_0", Port: 1, SymbolError: 65535, LinkDowned: 255}
+	deltas = thresholds.ExceededDeltas(saturated, saturated)
+	require.Len(t, deltas, 1)
+	assert.True(t, deltas[0].Saturated)
+	assert.Equal(t, CounterSymbolError, deltas[0].Counter)
+	assert.Equal(t, "mlx5_0 port 1 symbol_error saturated (increases no longer visible until the counters are reset)", deltas[0].String())
+}
@@ -14,6 +14,12 @@ type ExpectedPortStates struct {
 	// The expected rate in Gb/sec.
 	// If not set, it defaults to 0.
 	AtLeastRate int `json:"at_least_rate"`
+
+	// The expected state of the ports of each device, keyed by the device name
+	// for all its ports (e.g., "mlx5_0"), or the device name and the port
+	// (e.g., "mlx5_0/2"), for the hosts with the heterogeneous adapters.
+	// Evaluated on top of the minimum number of ports and rate.
+	Devices map[string]ExpectedDeviceState `json:"devices,omitempty"`
 }
 
 // IsZero returns true if the expected port states are not set.
@@ -21,7 +27,7 @@ func (eps *ExpectedPortStates) IsZero() bool {
 	if eps == nil {
 		return true
 	}
-	return eps.AtLeastPorts <= 0 || eps.AtLeastRate <= 0
+	return (eps.AtLeastPorts <= 0 || eps.AtLeastRate <= 0) && len(eps.Devices) == 0
 }
 
 var gpuPortConfigs = map[string]ExpectedPortStates{
@@ -0,0 +1,129 @@
+package infiniband
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// DefaultDropDuration is the default duration a port stays down,
+	// at or beyond which the port is considered dropped (not a transient flap).
+	DefaultDropDuration = 4 * time.Minute
+	// DefaultFlapWindow is the default lookback window to count the port down transitions.
+	DefaultFlapWindow = 10 * time.Minute
+	// DefaultFlapMinTransitions is the default number of the down transitions
+	// within the flap window, at or beyond which the port is flapping.
+	DefaultFlapMinTransitions = 3
+	// DefaultCounterWindow is the default window to sum the port error counter increases,
+	// independent of the check interval.
+	DefaultCounterWindow = 10 * time.Minute
+)
+
+var ErrInvalidEvaluationConfig = errors.New("invalid infiniband evaluation config")
+
+// EvaluationConfig configures the port drop and flap detection over the port state history,
+// and the port error counter increases within a time window.
+// Zero fields default to the DefaultDropDuration, DefaultFlapWindow, DefaultFlapMinTransitions,
+// DefaultCounterWindow, and DefaultCounterDeltaThresholds.
+type EvaluationConfig struct {
+	// DropDuration is the duration a port stays down, at or beyond which the port is dropped.
+	DropDuration metav1.Duration `json:"drop_duration,omitempty"`
+	// FlapWindow is the lookback window to count the port down transitions.
+	FlapWindow metav1.Duration `json:"flap_window,omitempty"`
+	// FlapMinTransitions is the number of the down transitions within the flap window,
+	// at or beyond which the port is flapping.
+	FlapMinTransitions int `json:"flap_min_transitions,omitempty"`
+	// CounterWindow is the window to sum the port error counter increases.
+	CounterWindow metav1.Duration `json:"counter_window,omitempty"`
+	// CounterDeltas are the increases of the port error counters
+	// within the counter window, at or beyond which the port is degraded.
+	CounterDeltas CounterDeltaThresholds `json:"counter_deltas"`
+	// DisableCounterDeltas disables the port error counter evaluation.
+	DisableCounterDeltas bool `json:"disable_counter_deltas,omitempty"`
+}
+
+// Validate validates the evaluation config.
+func (cfg EvaluationConfig) Validate() error {
+	if cfg.DropDuration.Duration < 0 {
+		return fmt.Errorf("%w: negative drop_duration %s", ErrInvalidEvaluationConfig, cfg.DropDuration.Duration)
+	}
+	if cfg.FlapWindow.Duration < 0 {
+		return fmt.Errorf("%w: negative flap_window %s", ErrInvalidEvaluationConfig, cfg.FlapWindow.Duration)
+	}
+	if cfg.FlapMinTransitions < 0 {
+		return fmt.Errorf("%w: negative flap_min_transitions %d", ErrInvalidEvaluationConfig, cfg.FlapMinTransitions)
+	}
+	if cfg.CounterWindow.Duration < 0 {
+		return fmt.Errorf("%w: negative counter_window %s", ErrInvalidEvaluationConfig, cfg.CounterWindow.Duration)
+	}
+	return nil
+}
+
+// WithDefaults returns the config with the zero fields set to the defaults.
+func (cfg EvaluationConfig) WithDefaults() EvaluationConfig {
+	if cfg.DropDuration.Duration == 0 {
+		cfg.DropDuration.Duration = DefaultDropDuration
+	}
+	if cfg.FlapWindow.Duration == 0 {
+		cfg.FlapWindow.Duration = DefaultFlapWindow
+	}
+	if cfg.FlapMinTransitions == 0 {
+		cfg.FlapMinTransitions = DefaultFlapMinTransitions
+	}
+	if cfg.CounterWindow.Duration == 0 {
+		cfg.CounterWindow.Duration = DefaultCounterWindow
+	}
+	if cfg.DisableCounterDeltas {
+		cfg.CounterDeltas = CounterDeltaThresholds{}
+		return cfg
+	}
+	if cfg.CounterDeltas.SymbolError == 0 {
+		cfg.CounterDeltas.SymbolError = DefaultCounterDeltaThresholds.SymbolError
+	}
+	if cfg.CounterDeltas.LinkDowned == 0 {
+		cfg.CounterDeltas.LinkDowned = DefaultCounterDeltaThresholds.LinkDowned
+	}
+	if cfg.CounterDeltas.PortRcvErrors == 0 {
+		cfg.CounterDeltas.PortRcvErrors = DefaultCounterDeltaThresholds.PortRcvErrors
+	}
+	if cfg.CounterDeltas.ExcessiveBufferOverrunErrors == 0 {
+		cfg.CounterDeltas.ExcessiveBufferOverrunErrors = DefaultCounterDeltaThresholds.ExcessiveBufferOverrunErrors
+	}
+	return cfg
+}
+
+// LoadEvaluationConfig loads and validates the evaluation config from the YAML (or JSON) file.
+//
+// e.g.,
+//
+//	drop_duration: 10m
+//	flap_window: 30m
+//	flap_min_transitions: 5
+//	counter_window: 15m
+//	counter_deltas:
+//	  symbol_error: 100
+//	  link_downed: 1
+func LoadEvaluationConfig(file string) (EvaluationConfig, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return EvaluationConfig{}, err
+	}
+	return ParseEvaluationConfig(b)
+}
+
+// ParseEvaluationConfig parses and validates the evaluation config from the YAML (or JSON) content.
+func ParseEvaluationConfig(b []byte) (EvaluationConfig, error) {
+	var cfg EvaluationConfig
+	if err := yaml.UnmarshalStrict(b, &cfg); err != nil {
+		return EvaluationConfig{}, fmt.Errorf("%w: %v", ErrInvalidEvaluationConfig, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return EvaluationConfig{}, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,83 @@
+package infiniband
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEvaluationConfigWithDefaults(t *testing.T) {
+	cfg := EvaluationConfig{}.WithDefaults()
+	assert.Equal(t, DefaultDropDuration, cfg.DropDuration.Duration)
+	assert.Equal(t, DefaultFlapWindow, cfg.FlapWindow.Duration)
+	assert.Equal(t, DefaultFlapMinTransitions, cfg.FlapMinTransitions)
+	assert.Equal(t, DefaultCounterWindow, cfg.CounterWindow.Duration)
+	assert.Equal(t, DefaultCounterDeltaThresholds, cfg.CounterDeltas)
+
+	// the zero counter thresholds default per counter
+	cfg = EvaluationConfig{CounterDeltas: CounterDeltaThresholds{SymbolError: 500}}.WithDefaults()
+	assert.Equal(t, uint64(500), cfg.CounterDeltas.SymbolError)
+	assert.Equal(t, DefaultCounterDeltaThresholds.LinkDowned, cfg.CounterDeltas.LinkDowned)
+
+	cfg = EvaluationConfig{DisableCounterDeltas: true}.WithDefaults()
+	assert.True(t, cfg.CounterDeltas.IsZero())
+
+	cfg = EvaluationConfig{FlapMinTransitions: 5}.WithDefaults()
+	assert.Equal(t, 5, cfg.FlapMinTransitions)
+	assert.Equal(t, DefaultDropDuration, cfg.DropDuration.Duration)
+}
+
+func TestEvaluationConfigValidate(t *testing.T) {
+	assert.NoError(t, EvaluationConfig{}.Validate())
+	assert.NoError(t, EvaluationConfig{
+		DropDuration:       metav1.Duration{Duration: 10 * time.Minute},
+		FlapWindow:         metav1.Duration{Duration: 30 * time.Minute},
+		FlapMinTransitions: 5,
+	}.Validate())
+
+	assert.ErrorIs(t, EvaluationConfig{DropDuration: metav1.Duration{Duration: -time.Minute}}.Validate(), ErrInvalidEvaluationConfig)
+	assert.ErrorIs(t, EvaluationConfig{FlapWindow: metav1.Duration{Duration: -time.Minute}}.Validate(), ErrInvalidEvaluationConfig)
+	assert.ErrorIs(t, EvaluationConfig{FlapMinTransitions: -1}.Validate(), ErrInvalidEvaluationConfig)
+	assert.ErrorIs(t, EvaluationConfig{CounterWindow: metav1.Duration{Duration: -time.Minute}}.Validate(), ErrInvalidEvaluationConfig)
+}
+
+func TestLoadEvaluationConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	file := filepath.Join(dir, "evaluation.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+drop_duration: 10m
+flap_window: 30m
+flap_min_transitions: 5
+counter_window: 15m
+counter_deltas:
+  symbol_error: 100
+  link_downed: 1
+`), 0644))
+	cfg, err := LoadEvaluationConfig(file)
+	require.NoError(t, err)
+	assert.Equal(t, EvaluationConfig{
+		DropDuration:       metav1.Duration{Duration: 10 * time.Minute},
+		FlapWindow:         metav1.Duration{Duration: 30 * time.Minute},
+		FlapMinTransitions: 5,
+		CounterWindow:      metav1.Duration{Duration: 15 * time.Minute},
+		CounterDeltas:      CounterDeltaThresholds{SymbolError: 100, LinkDowned: 1},
+	}, cfg)
+
+	require.NoError(t, os.WriteFile(file, []byte(`flap_min_transitions: -1`), 0644))
+	_, err = LoadEvaluationConfig(file)
+	assert.ErrorIs(t, err, ErrInvalidEvaluationConfig)
+
+	// unknown fields are rejected to catch the typos
+	require.NoError(t, os.WriteFile(file, []byte(`drop_duraton: 10m`), 0644))
+	_, err = LoadEvaluationConfig(file)
+	assert.ErrorIs(t, err, ErrInvalidEvaluationConfig)
+
+	_, err = LoadEvaluationConfig(filepath.Join(dir, "not-found.yaml"))
+	assert.Error(t, err)
+}
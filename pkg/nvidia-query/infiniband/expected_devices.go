@@ -0,0 +1,142 @@
+package infiniband
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ExpectedDeviceState is the expected state of the port of an infiniband device.
+// Empty fields are not evaluated.
+type ExpectedDeviceState struct {
+	// State is the expected port state (e.g., "Active").
+	State string `json:"state,omitempty"`
+	// PhysicalState is the expected port physical state (e.g., "LinkUp").
+	PhysicalState string `json:"physical_state,omitempty"`
+	// Rate is the expected port rate in Gb/sec (e.g., 400).
+	Rate int `json:"rate,omitempty"`
+}
+
+var ErrInvalidExpectedPortStates = errors.New("invalid infiniband expected port states")
+
+// Validate validates the expected port states.
+func (eps ExpectedPortStates) Validate() error {
+	if eps.AtLeastPorts < 0 || eps.AtLeastRate < 0 {
+		return fmt.Errorf("%w: negative ports %d or rate %d", ErrInvalidExpectedPortStates, eps.AtLeastPorts, eps.AtLeastRate)
+	}
+	for dev, exp := range eps.Devices {
+		name, port := parseDeviceKey(dev)
+		if name == "" {
+			return fmt.Errorf("%w: empty device name", ErrInvalidExpectedPortStates)
+		}
+		if port < 0 {
+			return fmt.Errorf("%w: invalid port of device %q", ErrInvalidExpectedPortStates, dev)
+		}
+		if exp.Rate < 0 {
+			return fmt.Errorf("%w: negative rate %d of device %q", ErrInvalidExpectedPortStates, exp.Rate, dev)
+		}
+	}
+	return nil
+}
+
+const (
+	// MismatchFieldDevice is the expected device not found.
+	MismatchFieldDevice = "device"
+	// MismatchFieldState is the port state not as expected.
+	MismatchFieldState = "state"
+	// MismatchFieldPhysicalState is the port physical state not as expected.
+	MismatchFieldPhysicalState = "physical_state"
+	// MismatchFieldRate is the port rate not as expected.
+	MismatchFieldRate = "rate"
+)
+
+// parseDeviceKey parses the key of the expected device states, either the device
+// name for all its ports (e.g., "mlx5_0") or the device name and the port (e.g., "mlx5_0/2").
+// The port is zero for all the ports, and negative if invalid.
+func parseDeviceKey(key string) (string, int) {
+	idx := strings.LastIndex(key, "/")
+	if idx < 0 {
+		return key, 0
+	}
+	port, err := strconv.Atoi(key[idx+1:])
+	if err != nil || port <= 0 {
+		return key[:idx], -1
+	}
+	return key[:idx], port
+}
+
+// DeviceMismatch is the port of an infiniband device not in the expected state.
+type DeviceMismatch struct {
+	// Device is the port device name (e.g., "mlx5_0").
+	Device string `json:"device"`
+	// Port is the port number of the device,
+	// zero if no port of the device is found.
+	Port int `json:"port,omitempty"`
+	// Field is the mismatched field (e.g., "state"), or "device" if not found.
+	Field string `json:"field"`
+	// Expected is the expected value.
+	Expected string `json:"expected,omitempty"`
+	// Actual is the actual value, empty if the device is not found.
+	Actual string `json:"actual,omitempty"`
+}
+
+func (m DeviceMismatch) String() string {
+	name := m.Device
+	if m.Port > 0 {
+		name = fmt.Sprintf("%s port %d", m.Device, m.Port)
+	}
+	if m.Field == MismatchFieldDevice {
+		return fmt.Sprintf("%s not found", name)
+	}
+	return fmt.Sprintf("%s %s %s (expected %s)", name, m.Field, m.Actual, m.Expected)
+}
+
+// CheckDevices returns the ports of the expected devices not in the expected states,
+// sorted by the device name and the port. The device name expects all its ports
+// in the state, while the device name and the port (e.g., "mlx5_0/2") expects the port only.
+// The states are compared case-insensitively,
+// since "ibstatus" reports them in the upper case (e.g., "ACTIVE").
+func (eps ExpectedPortStates) CheckDevices(ports []IBPort) []DeviceMismatch {
+	if len(eps.Devices) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(eps.Devices))
+	for key := range eps.Devices {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var mismatches []DeviceMismatch
+	for _, key := range keys {
+		exp := eps.Devices[key]
+		dev, port := parseDeviceKey(key)
+
+		var matched []IBPort
+		for _, p := range ports {
+			if p.Device == dev && (port == 0 || p.Port == port) {
+				matched = append(matched, p)
+			}
+		}
+		if len(matched) == 0 {
+			mismatches = append(mismatches, DeviceMismatch{Device: dev, Port: max(port, 0), Field: MismatchFieldDevice})
+			continue
+		}
+		sort.Slice(matched, func(i, j int) bool { return matched[i].Port < matched[j].Port })
+
+		for _, p := range matched {
+			if exp.State != "" && !strings.EqualFold(exp.State, p.State) {
+				mismatches = append(mismatches, DeviceMismatch{Device: dev, Port: p.Port, Field: MismatchFieldState, Expected: exp.State, Actual: p.State})
+			}
+			if exp.PhysicalState != "" && !strings.EqualFold(exp.PhysicalState, p.PhysicalState) {
+				mismatches = append(mismatches, DeviceMismatch{Device: dev, Port: p.Port, Field: MismatchFieldPhysicalState, Expected: exp.PhysicalState, Actual: p.PhysicalState})
+			}
+			if exp.Rate > 0 && exp.Rate != p.Rate {
+				mismatches = append(mismatches, DeviceMismatch{Device: dev, Port: p.Port, Field: MismatchFieldRate, Expected: strconv.Itoa(exp.Rate), Actual: strconv.Itoa(p.Rate)})
+			}
+		}
+	}
+	return mismatches
+}
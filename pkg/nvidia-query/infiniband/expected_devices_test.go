@@ -0,0 +1,81 @@
+package infiniband
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpectedPortStatesValidate(t *testing.T) {
+	assert.NoError(t, ExpectedPortStates{}.Validate())
+	assert.NoError(t, ExpectedPortStates{AtLeastPorts: 8, AtLeastRate: 400, Devices: map[string]ExpectedDeviceState{"mlx5_0": {State: "Active", Rate: 400}}}.Validate())
+	assert.ErrorIs(t, ExpectedPortStates{AtLeastPorts: -1}.Validate(), ErrInvalidExpectedPortStates)
+	assert.ErrorIs(t, ExpectedPortStates{Devices: map[string]ExpectedDeviceState{"": {}}}.Validate(), ErrInvalidExpectedPortStates)
+	assert.ErrorIs(t, ExpectedPortStates{Devices: map[string]ExpectedDeviceState{"mlx5_0": {Rate: -1}}}.Validate(), ErrInvalidExpectedPortStates)
+	assert.NoError(t, ExpectedPortStates{Devices: map[string]ExpectedDeviceState{"mlx5_0/2": {State: "Active"}}}.Validate())
+	assert.ErrorIs(t, ExpectedPortStates{Devices: map[string]ExpectedDeviceState{"mlx5_0/x": {}}}.Validate(), ErrInvalidExpectedPortStates)
+	assert.ErrorIs(t, ExpectedPortStates{Devices: map[string]ExpectedDeviceState{"/1": {}}}.Validate(), ErrInvalidExpectedPortStates)
+}
+
+func TestExpectedPortStatesCheckDevicesPorts(t *testing.T) {
+	ports := []IBPort{
+		{Device: "mlx5_0", Port: 2, State: "Down", Rate: 400},
+		{Device: "mlx5_0", Port: 1, State: "Active", Rate: 400},
+		{Device: "mlx5_1", Port: 1, State: "Active", Rate: 400},
+	}
+
+	// the device name expects all its ports
+	mismatches := ExpectedPortStates{Devices: map[string]ExpectedDeviceState{"mlx5_0": {State: "Active"}}}.CheckDevices(ports)
+	require.Len(t, mismatches, 1)
+	assert.Equal(t, "mlx5_0 port 2 state Down (expected Active)", mismatches[0].String())
+
+	// the device name and the port expects the port only
+	assert.Empty(t, ExpectedPortStates{Devices: map[string]ExpectedDeviceState{"mlx5_0/1": {State: "Active"}}}.CheckDevices(ports))
+	mismatches = ExpectedPortStates{Devices: map[string]ExpectedDeviceState{"mlx5_1/2": {State: "Active"}}}.CheckDevices(ports)
+	require.Len(t, mismatches, 1)
+	assert.Equal(t, "mlx5_1 port 2 not found", mismatches[0].String())
+}
+
+func TestExpectedPortStatesCheckDevices(t *testing.T) {
+	eps := ExpectedPortStates{
+		Devices: map[string]ExpectedDeviceState{
+			"mlx5_0": {State: "Active", PhysicalState: "LinkUp", Rate: 400},
+			"mlx5_1": {State: "Active", PhysicalState: "LinkUp", Rate: 400},
+			"mlx5_2": {State: "Active", Rate: 200},
+			"mlx5_3": {PhysicalState: "LinkUp"},
+			"mlx5_9": {State: "Active"},
+		},
+	}
+	ports := []IBPort{
+		{Device: "mlx5_0", State: "Active", PhysicalState: "LinkUp", Rate: 400},
+		{Device: "mlx5_1", State: "Down", PhysicalState: "Disabled", Rate: 400},
+		// "ibstatus" reports the states in the upper case
+		{Device: "mlx5_2", State: "ACTIVE", PhysicalState: "LINKUP", Rate: 100},
+		{Device: "mlx5_3", State: "Active", PhysicalState: "LinkUp", Rate: 100},
+		// not expected, not evaluated
+		{Device: "mlx5_4", State: "Down", PhysicalState: "Polling"},
+	}
+
+	mismatches := eps.CheckDevices(ports)
+	require.Len(t, mismatches, 4)
+	assert.Equal(t, "mlx5_1 state Down (expected Active)", mismatches[0].String())
+	assert.Equal(t, "mlx5_1 physical_state Disabled (expected LinkUp)", mismatches[1].String())
+	assert.Equal(t, "mlx5_2 rate 100 (expected 200)", mismatches[2].String())
+	assert.Equal(t, "mlx5_9 not found", mismatches[3].String())
+	assert.Equal(t, MismatchFieldDevice, mismatches[3].Field)
+
+	assert.Nil(t, ExpectedPortStates{AtLeastPorts: 1, AtLeastRate: 400}.CheckDevices(ports))
+}
+
+func TestExpectedPortStatesDevicesJSON(t *testing.T) {
+	var eps ExpectedPortStates
+	require.NoError(t, json.Unmarshal([]byte(`{"at_least_ports":8,"at_least_rate":400,"devices":{"mlx5_0":{"state":"Active","rate":400,"physical_state":"LinkUp"}}}`), &eps))
+	assert.Equal(t, ExpectedDeviceState{State: "Active", PhysicalState: "LinkUp", Rate: 400}, eps.Devices["mlx5_0"])
+	assert.False(t, eps.IsZero())
+
+	// the per-device expectations alone are evaluated
+	devicesOnly := ExpectedPortStates{Devices: eps.Devices}
+	assert.False(t, devicesOnly.IsZero())
+}
@@ -6,14 +6,15 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"sigs.k8s.io/yaml"
 
-	pkgfile "github.com/leptonai/gpud/pkg/file"
 	"github.com/leptonai/gpud/pkg/log"
-	"github.com/leptonai/gpud/pkg/process"
+	"github.com/leptonai/gpud/pkg/toolexec"
+	"github.com/leptonai/gpud/pkg/toolpath"
 )
 
 var ErrNoIbstatCommand = errors.New("ibstat not found, cannot check ib state")
@@ -23,30 +24,22 @@ func GetIbstatOutput(ctx context.Context, ibstatCommands []string) (*IbstatOutpu
 	if len(ibstatCommands) == 0 || strings.TrimSpace(ibstatCommands[0]) == "" {
 		return nil, ErrNoIbstatCommand
 	}
-	if _, err := pkgfile.LocateExecutable(strings.Split(ibstatCommands[0], " ")[0]); err != nil {
+	if _, err := toolpath.Locate(strings.Split(ibstatCommands[0], " ")[0]); err != nil {
 		return nil, ErrNoIbstatCommand
 	}
 
-	cmdOpts := []process.OpOption{
-		process.WithCommand(ibstatCommands...),
-	}
+	var opts []toolexec.OpOption
 	if ibstatCommands[0] != "ibstat" {
 		// more complicated commands (like mocked ibstat custom commands)
-		cmdOpts = append(cmdOpts, process.WithRunAsBashScript())
+		opts = append(opts, toolexec.WithRunAsBashScript())
 	}
 
-	p, runErr := process.New(cmdOpts...)
-	if runErr != nil {
+	res, runErr := toolexec.Run(ctx, ibstatCommands, opts...)
+	if res == nil {
 		return nil, runErr
 	}
-	defer func() {
-		if err := p.Close(ctx); err != nil {
-			log.Logger.Warnw("failed to abort command", "err", err)
-		}
-	}()
-	b, runErr := p.StartAndWaitForCombinedOutput(ctx)
 	o := &IbstatOutput{
-		Raw: strings.TrimSpace(string(b)),
+		Raw: strings.TrimSpace(string(res.Output)),
 	}
 
 	var parseErr error
@@ -56,9 +49,9 @@ func GetIbstatOutput(ctx context.Context, ibstatCommands []string) (*IbstatOutpu
 	if len(o.Raw) > 0 {
 		o.Parsed, parseErr = ParseIBStat(o.Raw)
 		if parseErr != nil {
-			log.Logger.Warnw("failed to parse ibstat output", "exitCode", p.ExitCode(), "rawInputSize", len(o.Raw), "error", parseErr)
+			log.Logger.Warnw("failed to parse ibstat output", "exitCode", res.ExitCode, "rawInputSize", len(o.Raw), "error", parseErr)
 		} else {
-			log.Logger.Infow("ibstat parsed", "exitCode", p.ExitCode(), "rawInputSize", len(o.Raw))
+			log.Logger.Infow("ibstat parsed", "exitCode", res.ExitCode, "rawInputSize", len(o.Raw))
 		}
 	}
 
@@ -75,10 +68,26 @@ func GetIbstatOutput(ctx context.Context, ibstatCommands []string) (*IbstatOutpu
 	return o, nil
 }
 
+// GetPortStatesOutput reads the port states from sysfs, and only falls back to
+// the ibstat command if sysfs is unavailable (no device found) or the ibstat
+// command is overwritten (e.g., the mocked ibstat for testing).
+func GetPortStatesOutput(ctx context.Context, classDir string, ibstatCommand string) (*IbstatOutput, error) {
+	if ibstatCommand == "" || ibstatCommand == "ibstat" {
+		o, err := GetSysfsOutput(classDir)
+		if err == nil {
+			return o, nil
+		}
+		if !errors.Is(err, ErrNoSysfsDevice) {
+			log.Logger.Warnw("failed to read infiniband ports from sysfs -- falling back to ibstat", "error", err)
+		}
+	}
+	return GetIbstatOutput(ctx, []string{ibstatCommand})
+}
+
 // CheckInfiniband checks if the infiniband ports are up and running with the expected thresholds.
 func CheckInfiniband(ctx context.Context, ibstatCommand string, threshold ExpectedPortStates) error {
 	cctx, cancel := context.WithTimeout(ctx, 15*time.Second)
-	ibstat, err := GetIbstatOutput(cctx, []string{ibstatCommand})
+	ibstat, err := GetPortStatesOutput(cctx, DefaultClassDir, ibstatCommand)
 	cancel()
 
 	if err != nil {
@@ -133,6 +142,8 @@ type IBStatPort struct {
 	PhysicalState string `json:"Physical state"`
 	Rate          int    `json:"Rate"`
 	BaseLid       int    `json:"Base lid"`
+	SMLid         int    `json:"SM lid"`
+	PortGUID      string `json:"Port GUID"`
 	LinkLayer     string `json:"Link layer"`
 }
 
@@ -141,6 +152,7 @@ func (cards IBStatCards) IBPorts() []IBPort {
 	for _, card := range cards {
 		ibports = append(ibports, IBPort{
 			Device:        card.Device,
+			Port:          1,
 			PhysicalState: card.Port1.PhysicalState,
 			State:         card.Port1.State,
 			Rate:          card.Port1.Rate,
@@ -183,6 +195,17 @@ var (
 	ErrIbstatOutputNoCardFound = errors.New("parsed ibstat output does not contain any card")
 )
 
+// quoteYAMLValue quotes the value of the "key: value" line,
+// so that the hex GUIDs (e.g., "0xa088c20300e3142a") are not
+// decoded as the integers.
+func quoteYAMLValue(line string) string {
+	k, v, ok := strings.Cut(line, ":")
+	if !ok {
+		return line
+	}
+	return k + ": " + strconv.Quote(strings.TrimSpace(v))
+}
+
 // ParseIBStat parses ibstat output and returns YAML representation.
 // Returns ErrIbstatOutputEmpty if the input is empty.
 func ParseIBStat(input string) (IBStatCards, error) {
@@ -238,13 +261,13 @@ func ParseIBStat(input string) (IBStatCards, error) {
 
 		// Node GUID: 0xa088c20300e3142a
 		if strings.HasPrefix(strings.TrimSpace(line), "Node GUID:") {
-			lines = append(lines, "  "+strings.TrimSpace(line))
+			lines = append(lines, "  "+quoteYAMLValue(strings.TrimSpace(line)))
 			continue
 		}
 
 		// System image GUID: 0xa088c20300e3142a
 		if strings.HasPrefix(strings.TrimSpace(line), "System image GUID:") {
-			lines = append(lines, "  "+strings.TrimSpace(line))
+			lines = append(lines, "  "+quoteYAMLValue(strings.TrimSpace(line)))
 			continue
 		}
 
@@ -275,6 +298,27 @@ func ParseIBStat(input string) (IBStatCards, error) {
 			continue
 		}
 
+		// Port 1:
+		//    Base lid: ...
+		if strings.HasPrefix(strings.TrimSpace(line), "Base lid:") {
+			lines = append(lines, "    "+strings.TrimSpace(line))
+			continue
+		}
+
+		// Port 1:
+		//    SM lid: ...
+		if strings.HasPrefix(strings.TrimSpace(line), "SM lid:") {
+			lines = append(lines, "    "+strings.TrimSpace(line))
+			continue
+		}
+
+		// Port 1:
+		//    Port GUID: ...
+		if strings.HasPrefix(strings.TrimSpace(line), "Port GUID:") {
+			lines = append(lines, "    "+quoteYAMLValue(strings.TrimSpace(line)))
+			continue
+		}
+
 		// Port 1:
 		//    Link layer: ...
 		if strings.HasPrefix(strings.TrimSpace(line), "Link layer:") {
@@ -171,6 +171,9 @@ func TestParseIBStat(t *testing.T) {
 		assert.Equal(t, "LinkUp", card.Port1.PhysicalState)
 		assert.Equal(t, 400, card.Port1.Rate)
 		assert.Equal(t, "Ethernet", card.Port1.LinkLayer)
+		assert.Equal(t, "0xa088c20300e3142a", card.NodeGUID)
+		assert.Equal(t, "0xa288c2fffee3142a", card.Port1.PortGUID)
+		assert.Equal(t, 0, card.Port1.BaseLid)
 	}
 }
 
@@ -11,9 +11,9 @@ import (
 
 	"sigs.k8s.io/yaml"
 
-	pkgfile "github.com/leptonai/gpud/pkg/file"
 	"github.com/leptonai/gpud/pkg/log"
-	"github.com/leptonai/gpud/pkg/process"
+	"github.com/leptonai/gpud/pkg/toolexec"
+	"github.com/leptonai/gpud/pkg/toolpath"
 )
 
 type IbstatusOutput struct {
@@ -27,30 +27,22 @@ func GetIbstatusOutput(ctx context.Context, ibstatusCommands []string) (*Ibstatu
 	if len(ibstatusCommands) == 0 || strings.TrimSpace(ibstatusCommands[0]) == "" {
 		return nil, ErrNoIbstatusCommand
 	}
-	if _, err := pkgfile.LocateExecutable(strings.Split(ibstatusCommands[0], " ")[0]); err != nil {
+	if _, err := toolpath.Locate(strings.Split(ibstatusCommands[0], " ")[0]); err != nil {
 		return nil, ErrNoIbstatusCommand
 	}
 
-	cmdOpts := []process.OpOption{
-		process.WithCommand(ibstatusCommands...),
-	}
+	var opts []toolexec.OpOption
 	if ibstatusCommands[0] != "ibstatus" {
 		// more complicated commands (like mocked ibstat custom commands)
-		cmdOpts = append(cmdOpts, process.WithRunAsBashScript())
+		opts = append(opts, toolexec.WithRunAsBashScript())
 	}
 
-	p, runErr := process.New(cmdOpts...)
-	if runErr != nil {
+	res, runErr := toolexec.Run(ctx, ibstatusCommands, opts...)
+	if res == nil {
 		return nil, runErr
 	}
-	defer func() {
-		if err := p.Close(ctx); err != nil {
-			log.Logger.Warnw("failed to abort command", "err", err)
-		}
-	}()
-	b, runErr := p.StartAndWaitForCombinedOutput(ctx)
 	o := &IbstatusOutput{
-		Raw: strings.TrimSpace(string(b)),
+		Raw: strings.TrimSpace(string(res.Output)),
 	}
 
 	var parseErr error
@@ -60,9 +52,9 @@ func GetIbstatusOutput(ctx context.Context, ibstatusCommands []string) (*Ibstatu
 	if len(o.Raw) > 0 {
 		o.Parsed, parseErr = ParseIBStatus(o.Raw)
 		if parseErr != nil {
-			log.Logger.Warnw("failed to parse ibstatus output", "exitCode", p.ExitCode(), "rawInputSize", len(o.Raw), "error", parseErr)
+			log.Logger.Warnw("failed to parse ibstatus output", "exitCode", res.ExitCode, "rawInputSize", len(o.Raw), "error", parseErr)
 		} else {
-			log.Logger.Infow("ibstatus parsed", "exitCode", p.ExitCode(), "rawInputSize", len(o.Raw))
+			log.Logger.Infow("ibstatus parsed", "exitCode", res.ExitCode, "rawInputSize", len(o.Raw))
 		}
 	}
 
@@ -82,7 +74,9 @@ func GetIbstatusOutput(ctx context.Context, ibstatusCommands []string) (*Ibstatu
 type IBStatuses []IBStatus
 
 type IBStatus struct {
-	Device        string `json:"device"`
+	Device string `json:"device"`
+	// Port is the port number of the device.
+	Port          int    `json:"port,omitempty"`
 	DefaultGID    string `json:"default gid"`
 	DefaultLID    string `json:"default lid"`
 	SMLID         string `json:"sm lid"`
@@ -98,6 +92,7 @@ func (devs IBStatuses) IBPorts() []IBPort {
 	for _, dev := range devs {
 		ibports = append(ibports, IBPort{
 			Device:        dev.Device,
+			Port:          dev.Port,
 			State:         sanitizeIbstatusState(dev.State),
 			PhysicalState: sanitizeIbstatusPhysicalState(dev.PhysicalState),
 			Rate:          parseIbstatusRate(dev.Rate),
@@ -188,11 +183,12 @@ func ParseIBStatus(input string) (IBStatuses, error) {
 
 		// "Infiniband device 'mlx5_0' port 1 status:"
 		// becomes
-		// "mlx5_0:"
+		// "mlx5_0/1:"
 		if strings.HasPrefix(line, "Infiniband device '") {
 			line = strings.TrimSpace(line)
 			line = strings.TrimPrefix(line, "Infiniband device '")
-			line = strings.TrimSuffix(line, "' port 1 status:")
+			line = strings.TrimSuffix(line, " status:")
+			line = strings.Replace(line, "' port ", "/", 1)
 			line += ":"
 			lines = append(lines, line)
 			continue
@@ -226,11 +222,19 @@ func ParseIBStatus(input string) (IBStatuses, error) {
 
 	converted := IBStatuses{}
 	for k, v := range statuses {
-		v.Device = k
+		v.Device, v.Port = k, 1
+		if idx := strings.LastIndex(k, "/"); idx > 0 {
+			if port, err := strconv.Atoi(k[idx+1:]); err == nil {
+				v.Device, v.Port = k[:idx], port
+			}
+		}
 		converted = append(converted, v)
 	}
 	sort.Slice(converted, func(i, j int) bool {
-		return converted[i].Device < converted[j].Device
+		if converted[i].Device != converted[j].Device {
+			return converted[i].Device < converted[j].Device
+		}
+		return converted[i].Port < converted[j].Port
 	})
 
 	return converted, nil
@@ -118,6 +118,31 @@ Infiniband device 'mlx5_1' port 1 status:
 	require.Equal(t, "2: Disabled", parsed[1].PhysicalState)
 }
 
+func TestParseIBStatusMultiplePorts(t *testing.T) {
+	t.Parallel()
+
+	input := `Infiniband device 'mlx5_0' port 2 status:
+        state:           1: DOWN
+        phys state:      3: Disabled
+        rate:            400 Gb/sec (4X NDR)
+
+Infiniband device 'mlx5_0' port 1 status:
+        state:           4: ACTIVE
+        phys state:      5: LinkUp
+        rate:            400 Gb/sec (4X NDR)`
+
+	parsed, err := ParseIBStatus(input)
+	require.NoError(t, err)
+	require.Len(t, parsed, 2)
+	require.Equal(t, "mlx5_0", parsed[0].Device)
+	require.Equal(t, 1, parsed[0].Port)
+	require.Equal(t, "mlx5_0", parsed[1].Device)
+	require.Equal(t, 2, parsed[1].Port)
+
+	ports := parsed.IBPorts()
+	require.Equal(t, IBPort{Device: "mlx5_0", Port: 2, State: "DOWN", PhysicalState: "Disabled", Rate: 400}, ports[1])
+}
+
 // TestParseIBStatusIncompleteFields tests parsing output with some missing fields
 func TestParseIBStatusIncompleteFields(t *testing.T) {
 	t.Parallel()
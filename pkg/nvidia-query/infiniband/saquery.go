@@ -0,0 +1,170 @@
+package infiniband
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/leptonai/gpud/pkg/toolexec"
+	"github.com/leptonai/gpud/pkg/toolpath"
+)
+
+var ErrNoSaqueryCommand = errors.New("saquery not found, cannot query subnet administrator records")
+
+// IBPeer is the remote end (e.g., the switch port) of the local IB port,
+// as recorded by the subnet administrator, used to trace a down port
+// to the specific switch port or cable in the fabric.
+type IBPeer struct {
+	// Device is the local CA name (e.g., "mlx5_0").
+	Device string `json:"device"`
+	// LID is the base LID of the local port.
+	LID int `json:"lid"`
+	// PortGUID is the GUID of the local port.
+	PortGUID string `json:"port_guid,omitempty"`
+
+	// PeerLID is the LID of the remote node (e.g., the switch).
+	PeerLID int `json:"peer_lid"`
+	// PeerPort is the port number on the remote node.
+	PeerPort int `json:"peer_port"`
+	// PeerGUID is the node GUID of the remote node.
+	PeerGUID string `json:"peer_guid,omitempty"`
+	// PeerDescription is the node description of the remote node
+	// (e.g., "MF0;ib-switch-01:MQM8700/U1").
+	PeerDescription string `json:"peer_description,omitempty"`
+}
+
+// GetPeers queries the link and node records from the subnet administrator
+// with the "saquery" command, and returns the peer of each local IB port.
+// The ports without the LID (e.g., Ethernet link layer) or without the
+// link record (e.g., the link is down) are skipped.
+func GetPeers(ctx context.Context, saqueryCommand string, cards IBStatCards) ([]IBPeer, error) {
+	if strings.TrimSpace(saqueryCommand) == "" {
+		return nil, ErrNoSaqueryCommand
+	}
+	if _, err := toolpath.Locate(strings.Split(saqueryCommand, " ")[0]); err != nil {
+		return nil, ErrNoSaqueryCommand
+	}
+
+	linkOut, err := runSaquery(ctx, saqueryCommand, "LR")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query link records: %w", err)
+	}
+	nodeOut, err := runSaquery(ctx, saqueryCommand, "NR")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query node records: %w", err)
+	}
+
+	return FindPeers(cards, ParseSaqueryRecords(linkOut), ParseSaqueryRecords(nodeOut)), nil
+}
+
+func runSaquery(ctx context.Context, saqueryCommand string, queryType string) (string, error) {
+	var opts []toolexec.OpOption
+	if saqueryCommand != "saquery" {
+		// more complicated commands (like mocked saquery custom commands)
+		opts = append(opts, toolexec.WithRunAsBashScript())
+	}
+
+	res, err := toolexec.Run(ctx, []string{saqueryCommand, queryType}, opts...)
+	if err != nil {
+		return "", err
+	}
+	return string(res.Output), nil
+}
+
+// ParseSaqueryRecords parses the "saquery" record dumps
+// (e.g., "LinkRecord dump:" followed by the "FromLID.....1" lines)
+// into the key-value pairs of each record.
+func ParseSaqueryRecords(input string) []map[string]string {
+	records := make([]map[string]string, 0)
+
+	var cur map[string]string
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasSuffix(line, "Record dump:") {
+			cur = make(map[string]string)
+			records = append(records, cur)
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+
+		// e.g., "FromLID....................1"
+		idx := strings.Index(line, "..")
+		if idx <= 0 {
+			continue
+		}
+		cur[line[:idx]] = strings.TrimSpace(strings.TrimLeft(line[idx:], "."))
+	}
+
+	return records
+}
+
+// FindPeers matches the local IB ports to the link records by the base LID,
+// and the remote nodes to the node records by the LID.
+func FindPeers(cards IBStatCards, linkRecords []map[string]string, nodeRecords []map[string]string) []IBPeer {
+	nodes := make(map[int]map[string]string)
+	for _, rec := range nodeRecords {
+		lid, err := parseSaqueryInt(rec["lid"])
+		if err != nil {
+			continue
+		}
+		nodes[lid] = rec
+	}
+
+	// local port 1 of each CA, keyed by its base LID
+	links := make(map[int]map[string]string)
+	for _, rec := range linkRecords {
+		fromPort, err := parseSaqueryInt(rec["FromPort"])
+		if err != nil || fromPort != 1 {
+			continue
+		}
+		fromLID, err := parseSaqueryInt(rec["FromLID"])
+		if err != nil {
+			continue
+		}
+		links[fromLID] = rec
+	}
+
+	peers := make([]IBPeer, 0)
+	for _, card := range cards {
+		if card.Port1.BaseLid == 0 {
+			continue
+		}
+		rec, ok := links[card.Port1.BaseLid]
+		if !ok {
+			continue
+		}
+
+		peer := IBPeer{
+			Device:   card.Device,
+			LID:      card.Port1.BaseLid,
+			PortGUID: card.Port1.PortGUID,
+		}
+		peer.PeerLID, _ = parseSaqueryInt(rec["ToLID"])
+		peer.PeerPort, _ = parseSaqueryInt(rec["ToPort"])
+		if node, ok := nodes[peer.PeerLID]; ok {
+			peer.PeerGUID = node["node_guid"]
+			peer.PeerDescription = node["NodeDescription"]
+		}
+		peers = append(peers, peer)
+	}
+	return peers
+}
+
+// parseSaqueryInt parses the decimal (e.g., "17") or hex (e.g., "0x11") value.
+func parseSaqueryInt(s string) (int, error) {
+	v, err := strconv.ParseInt(strings.TrimSpace(s), 0, 64)
+	if err != nil {
+		return 0, err
+	}
+	return int(v), nil
+}
@@ -0,0 +1,69 @@
+package infiniband
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSaqueryRecords(t *testing.T) {
+	b, err := os.ReadFile("testdata/saquery.lr.0")
+	require.NoError(t, err)
+
+	records := ParseSaqueryRecords(string(b))
+	require.Len(t, records, 3)
+	assert.Equal(t, "1369", records[0]["FromLID"])
+	assert.Equal(t, "17", records[0]["ToPort"])
+	assert.Equal(t, "83", records[0]["ToLID"])
+
+	b, err = os.ReadFile("testdata/saquery.nr.0")
+	require.NoError(t, err)
+
+	records = ParseSaqueryRecords(string(b))
+	require.Len(t, records, 2)
+	assert.Equal(t, "0x53", records[0]["lid"])
+	assert.Equal(t, "0xfc6a1c0300a1b2c3", records[0]["node_guid"])
+	assert.Equal(t, "MF0;ib-leaf-01:MQM9700/U1", records[0]["NodeDescription"])
+	assert.Equal(t, "gpu-node-01 mlx5_1", records[1]["NodeDescription"])
+
+	assert.Empty(t, ParseSaqueryRecords(""))
+	assert.Empty(t, ParseSaqueryRecords("ibwarn: no SM found"))
+}
+
+func TestFindPeers(t *testing.T) {
+	b, err := os.ReadFile("testdata/ibstat.47.0.a100.all.active.0")
+	require.NoError(t, err)
+	cards, err := ParseIBStat(string(b))
+	require.NoError(t, err)
+
+	lr, err := os.ReadFile("testdata/saquery.lr.0")
+	require.NoError(t, err)
+	nr, err := os.ReadFile("testdata/saquery.nr.0")
+	require.NoError(t, err)
+
+	peers := FindPeers(cards, ParseSaqueryRecords(string(lr)), ParseSaqueryRecords(string(nr)))
+
+	// only mlx5_1 has the link record with the matching base LID
+	// (mlx5_0 is Ethernet with the zero LID)
+	require.Len(t, peers, 1)
+	assert.Equal(t, IBPeer{
+		Device:          "mlx5_1",
+		LID:             1369,
+		PortGUID:        "0x6b9d7f2c99902a63",
+		PeerLID:         83,
+		PeerPort:        17,
+		PeerGUID:        "0xfc6a1c0300a1b2c3",
+		PeerDescription: "MF0;ib-leaf-01:MQM9700/U1",
+	}, peers[0])
+}
+
+func TestGetPeersNoCommand(t *testing.T) {
+	_, err := GetPeers(context.Background(), "", nil)
+	assert.ErrorIs(t, err, ErrNoSaqueryCommand)
+
+	_, err = GetPeers(context.Background(), "saquery-does-not-exist", nil)
+	assert.ErrorIs(t, err, ErrNoSaqueryCommand)
+}
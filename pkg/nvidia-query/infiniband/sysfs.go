@@ -0,0 +1,259 @@
+package infiniband
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrNoSysfsDevice is returned when no infiniband device is found in sysfs
+// (e.g., the class directory does not exist), to fall back to "ibstat".
+var ErrNoSysfsDevice = errors.New("no infiniband device found in sysfs")
+
+// sysfsStates maps the port state numbers in sysfs (e.g., "4: ACTIVE")
+// to the state names printed by "ibstat".
+var sysfsStates = map[string]string{
+	"1": "Down",
+	"2": "Initializing",
+	"3": "Armed",
+	"4": "Active",
+}
+
+// GetSysfsOutput reads the port states of the infiniband devices directly
+// from the sysfs class directory (e.g., "/sys/class/infiniband"), in the same
+// format as the "ibstat" output, so that the ports are checked on the hosts
+// without the MOFED user-space tools installed.
+// Returns ErrNoSysfsDevice if no device is found, to fall back to "ibstat".
+func GetSysfsOutput(classDir string) (*IbstatOutput, error) {
+	cards, err := ReadSysfsCards(classDir)
+	if err != nil {
+		return nil, err
+	}
+	return &IbstatOutput{Parsed: cards, Raw: renderIbstat(cards)}, nil
+}
+
+// ReadSysfsCards reads the first port of each infiniband device from sysfs,
+// sorted by the device name, as the "ibstat" only reports the first port.
+func ReadSysfsCards(classDir string) (IBStatCards, error) {
+	devices, err := os.ReadDir(classDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoSysfsDevice
+		}
+		return nil, err
+	}
+
+	cards := make(IBStatCards, 0, len(devices))
+	for _, dev := range devices {
+		card, err := readSysfsCard(filepath.Join(classDir, dev.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read infiniband device %q: %w", dev.Name(), err)
+		}
+		if card == nil {
+			continue
+		}
+		cards = append(cards, *card)
+	}
+	if len(cards) == 0 {
+		return nil, ErrNoSysfsDevice
+	}
+
+	sort.Slice(cards, func(i, j int) bool {
+		return cards[i].Device < cards[j].Device
+	})
+	return cards, nil
+}
+
+// ReadSysfsPorts reads all the ports of each infiniband device from sysfs
+// (e.g., both ports of the dual-port HCAs), sorted by the device name and the port number.
+// Returns ErrNoSysfsDevice if no device is found.
+func ReadSysfsPorts(classDir string) ([]IBPort, error) {
+	devices, err := os.ReadDir(classDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoSysfsDevice
+		}
+		return nil, err
+	}
+
+	var ibports []IBPort
+	for _, dev := range devices {
+		devDir := filepath.Join(classDir, dev.Name())
+		ports, err := sysfsPorts(filepath.Join(devDir, "ports"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read infiniband device %q: %w", dev.Name(), err)
+		}
+		for _, port := range ports {
+			portDir := filepath.Join(devDir, "ports", strconv.Itoa(port))
+			ibports = append(ibports, IBPort{
+				Device:        dev.Name(),
+				Port:          port,
+				State:         parseSysfsState(readSysfsValue(filepath.Join(portDir, "state"))),
+				PhysicalState: parseSysfsPhysicalState(readSysfsValue(filepath.Join(portDir, "phys_state"))),
+				Rate:          parseSysfsRate(readSysfsValue(filepath.Join(portDir, "rate"))),
+			})
+		}
+	}
+	if len(ibports) == 0 {
+		return nil, ErrNoSysfsDevice
+	}
+
+	sort.Slice(ibports, func(i, j int) bool {
+		if ibports[i].Device != ibports[j].Device {
+			return ibports[i].Device < ibports[j].Device
+		}
+		return ibports[i].Port < ibports[j].Port
+	})
+	return ibports, nil
+}
+
+// readSysfsCard reads the device and its first port, nil if the device has no port.
+func readSysfsCard(devDir string) (*IBStatCard, error) {
+	ports, err := sysfsPorts(filepath.Join(devDir, "ports"))
+	if err != nil {
+		return nil, err
+	}
+	if len(ports) == 0 {
+		return nil, nil
+	}
+
+	card := &IBStatCard{
+		Device:          filepath.Base(devDir),
+		Type:            readSysfsValue(filepath.Join(devDir, "hca_type")),
+		NumPorts:        strconv.Itoa(len(ports)),
+		FirmwareVersion: readSysfsValue(filepath.Join(devDir, "fw_ver")),
+		HardwareVersion: parseSysfsHex(readSysfsValue(filepath.Join(devDir, "hw_rev"))),
+		NodeGUID:        parseSysfsGUID(readSysfsValue(filepath.Join(devDir, "node_guid"))),
+		SystemImageGUID: parseSysfsGUID(readSysfsValue(filepath.Join(devDir, "sys_image_guid"))),
+	}
+
+	portDir := filepath.Join(devDir, "ports", strconv.Itoa(ports[0]))
+	card.Port1 = IBStatPort{
+		State:         parseSysfsState(readSysfsValue(filepath.Join(portDir, "state"))),
+		PhysicalState: parseSysfsPhysicalState(readSysfsValue(filepath.Join(portDir, "phys_state"))),
+		Rate:          parseSysfsRate(readSysfsValue(filepath.Join(portDir, "rate"))),
+		LinkLayer:     readSysfsValue(filepath.Join(portDir, "link_layer")),
+		PortGUID:      parseSysfsGID(readSysfsValue(filepath.Join(portDir, "gids", "0"))),
+	}
+	card.Port1.BaseLid, _ = strconv.Atoi(parseSysfsHex(readSysfsValue(filepath.Join(portDir, "lid"))))
+	card.Port1.SMLid, _ = strconv.Atoi(parseSysfsHex(readSysfsValue(filepath.Join(portDir, "sm_lid"))))
+	return card, nil
+}
+
+// sysfsPorts returns the sorted port numbers of the device.
+func sysfsPorts(portsDir string) ([]int, error) {
+	entries, err := os.ReadDir(portsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var ports []int
+	for _, e := range entries {
+		n, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		ports = append(ports, n)
+	}
+	sort.Ints(ports)
+	return ports, nil
+}
+
+// readSysfsValue reads the trimmed value of the sysfs file, empty if unreadable
+// (e.g., the attribute not exposed by the driver).
+func readSysfsValue(path string) string {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// parseSysfsState parses the port state (e.g., "4: ACTIVE" to "Active").
+func parseSysfsState(s string) string {
+	num, name, ok := strings.Cut(s, ":")
+	if !ok {
+		return s
+	}
+	if state, ok := sysfsStates[strings.TrimSpace(num)]; ok {
+		return state
+	}
+	return strings.TrimSpace(name)
+}
+
+// parseSysfsPhysicalState parses the port physical state (e.g., "5: LinkUp" to "LinkUp").
+func parseSysfsPhysicalState(s string) string {
+	if _, name, ok := strings.Cut(s, ":"); ok {
+		return strings.TrimSpace(name)
+	}
+	return s
+}
+
+// parseSysfsRate parses the port rate in Gb/sec (e.g., "400 Gb/sec (4X NDR)" to 400).
+func parseSysfsRate(s string) int {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return 0
+	}
+	f, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0
+	}
+	return int(f)
+}
+
+// parseSysfsHex parses the hex value (e.g., "0x4b") to the decimal string (e.g., "75").
+func parseSysfsHex(s string) string {
+	v, err := strconv.ParseUint(strings.TrimPrefix(s, "0x"), 16, 64)
+	if err != nil {
+		return s
+	}
+	return strconv.FormatUint(v, 10)
+}
+
+// parseSysfsGUID parses the GUID (e.g., "946d:ae03:00cd:e72c" to "0x946dae0300cde72c").
+func parseSysfsGUID(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "0x" + strings.ReplaceAll(s, ":", "")
+}
+
+// parseSysfsGID parses the port GUID from the lower 64 bits of the GID
+// (e.g., "fe80:0000:0000:0000:946d:ae03:00cd:e72c" to "0x946dae0300cde72c").
+func parseSysfsGID(s string) string {
+	groups := strings.Split(s, ":")
+	if len(groups) != 8 {
+		return ""
+	}
+	return parseSysfsGUID(strings.Join(groups[4:], ":"))
+}
+
+// renderIbstat renders the cards in the "ibstat" output format.
+func renderIbstat(cards IBStatCards) string {
+	var sb strings.Builder
+	for _, card := range cards {
+		fmt.Fprintf(&sb, "CA '%s'\n", card.Device)
+		fmt.Fprintf(&sb, "\tCA type: %s\n", card.Type)
+		fmt.Fprintf(&sb, "\tNumber of ports: %s\n", card.NumPorts)
+		fmt.Fprintf(&sb, "\tFirmware version: %s\n", card.FirmwareVersion)
+		fmt.Fprintf(&sb, "\tHardware version: %s\n", card.HardwareVersion)
+		fmt.Fprintf(&sb, "\tNode GUID: %s\n", card.NodeGUID)
+		fmt.Fprintf(&sb, "\tSystem image GUID: %s\n", card.SystemImageGUID)
+		sb.WriteString("\tPort 1:\n")
+		fmt.Fprintf(&sb, "\t\tState: %s\n", card.Port1.State)
+		fmt.Fprintf(&sb, "\t\tPhysical state: %s\n", card.Port1.PhysicalState)
+		fmt.Fprintf(&sb, "\t\tRate: %d\n", card.Port1.Rate)
+		fmt.Fprintf(&sb, "\t\tBase lid: %d\n", card.Port1.BaseLid)
+		fmt.Fprintf(&sb, "\t\tSM lid: %d\n", card.Port1.SMLid)
+		fmt.Fprintf(&sb, "\t\tPort GUID: %s\n", card.Port1.PortGUID)
+		fmt.Fprintf(&sb, "\t\tLink layer: %s\n", card.Port1.LinkLayer)
+	}
+	return strings.TrimSpace(sb.String())
+}
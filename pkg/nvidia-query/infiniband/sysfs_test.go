@@ -0,0 +1,147 @@
+package infiniband
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSysfsFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, os.WriteFile(p, []byte(content+"\n"), 0644))
+	}
+}
+
+func TestReadSysfsCards(t *testing.T) {
+	dir := t.TempDir()
+	writeSysfsFiles(t, dir, map[string]string{
+		"mlx5_1/hca_type":           "MT4129",
+		"mlx5_1/fw_ver":             "28.39.1002",
+		"mlx5_1/hw_rev":             "0x0",
+		"mlx5_1/node_guid":          "946d:ae03:00cd:e72c",
+		"mlx5_1/sys_image_guid":     "946d:ae03:00cd:e72c",
+		"mlx5_1/ports/1/state":      "4: ACTIVE",
+		"mlx5_1/ports/1/phys_state": "5: LinkUp",
+		"mlx5_1/ports/1/rate":       "400 Gb/sec (4X NDR)",
+		"mlx5_1/ports/1/lid":        "0x4b",
+		"mlx5_1/ports/1/sm_lid":     "0x14e",
+		"mlx5_1/ports/1/link_layer": "InfiniBand",
+		"mlx5_1/ports/1/gids/0":     "fe80:0000:0000:0000:946d:ae03:00cd:e72c",
+		"mlx5_0/ports/1/state":      "1: DOWN",
+		"mlx5_0/ports/1/phys_state": "3: Disabled",
+		"mlx5_0/ports/1/rate":       "10 Gb/sec (4X SDR)",
+		"mlx5_0/ports/1/link_layer": "InfiniBand",
+	})
+	// the device without any port is skipped
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "mlx5_2"), 0755))
+
+	cards, err := ReadSysfsCards(dir)
+	require.NoError(t, err)
+	require.Len(t, cards, 2)
+
+	assert.Equal(t, "mlx5_0", cards[0].Device)
+	assert.Equal(t, IBStatPort{State: "Down", PhysicalState: "Disabled", Rate: 10, LinkLayer: "InfiniBand"}, cards[0].Port1)
+
+	assert.Equal(t, IBStatCard{
+		Device:          "mlx5_1",
+		Type:            "MT4129",
+		NumPorts:        "1",
+		FirmwareVersion: "28.39.1002",
+		HardwareVersion: "0",
+		NodeGUID:        "0x946dae0300cde72c",
+		SystemImageGUID: "0x946dae0300cde72c",
+		Port1: IBStatPort{
+			State:         "Active",
+			PhysicalState: "LinkUp",
+			Rate:          400,
+			BaseLid:       75,
+			SMLid:         334,
+			PortGUID:      "0x946dae0300cde72c",
+			LinkLayer:     "InfiniBand",
+		},
+	}, cards[1])
+
+	// the raw output is in the ibstat format
+	o, err := GetSysfsOutput(dir)
+	require.NoError(t, err)
+	parsed, err := ParseIBStat(o.Raw)
+	require.NoError(t, err)
+	assert.Equal(t, cards, parsed)
+	assert.ErrorIs(t, ValidateIbstatOutput(o.Raw), ErrIbstatOutputBrokenStateDown)
+}
+
+func TestReadSysfsPorts(t *testing.T) {
+	dir := t.TempDir()
+	writeSysfsFiles(t, dir, map[string]string{
+		"mlx5_1/ports/1/state":      "4: ACTIVE",
+		"mlx5_1/ports/1/phys_state": "5: LinkUp",
+		"mlx5_1/ports/1/rate":       "400 Gb/sec (4X NDR)",
+		"mlx5_1/ports/2/state":      "1: DOWN",
+		"mlx5_1/ports/2/phys_state": "3: Disabled",
+		"mlx5_1/ports/2/rate":       "10 Gb/sec (4X SDR)",
+		"mlx5_0/ports/1/state":      "4: ACTIVE",
+		"mlx5_0/ports/1/phys_state": "5: LinkUp",
+		"mlx5_0/ports/1/rate":       "400 Gb/sec (4X NDR)",
+	})
+
+	ports, err := ReadSysfsPorts(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []IBPort{
+		{Device: "mlx5_0", Port: 1, State: "Active", PhysicalState: "LinkUp", Rate: 400},
+		{Device: "mlx5_1", Port: 1, State: "Active", PhysicalState: "LinkUp", Rate: 400},
+		{Device: "mlx5_1", Port: 2, State: "Down", PhysicalState: "Disabled", Rate: 10},
+	}, ports)
+
+	_, err = ReadSysfsPorts(filepath.Join(dir, "nonexistent"))
+	assert.ErrorIs(t, err, ErrNoSysfsDevice)
+}
+
+func TestReadSysfsCardsNoDevice(t *testing.T) {
+	_, err := ReadSysfsCards(filepath.Join(t.TempDir(), "not-found"))
+	assert.ErrorIs(t, err, ErrNoSysfsDevice)
+
+	_, err = ReadSysfsCards(t.TempDir())
+	assert.ErrorIs(t, err, ErrNoSysfsDevice)
+}
+
+func TestGetPortStatesOutput(t *testing.T) {
+	dir := t.TempDir()
+	writeSysfsFiles(t, dir, map[string]string{
+		"mlx5_0/ports/1/state":      "4: ACTIVE",
+		"mlx5_0/ports/1/phys_state": "5: LinkUp",
+		"mlx5_0/ports/1/rate":       "400 Gb/sec (4X NDR)",
+	})
+
+	o, err := GetPortStatesOutput(context.Background(), dir, "ibstat")
+	require.NoError(t, err)
+	require.Len(t, o.Parsed, 1)
+	assert.Equal(t, "Active", o.Parsed[0].Port1.State)
+
+	// falls back to the ibstat command without the sysfs devices
+	_, err = GetPortStatesOutput(context.Background(), t.TempDir(), "")
+	assert.True(t, errors.Is(err, ErrNoIbstatCommand))
+
+	// the overwritten ibstat command is run even with the sysfs devices
+	o, err = GetPortStatesOutput(context.Background(), dir, "cat testdata/ibstat.47.0.h100.all.active.0")
+	require.NoError(t, err)
+	assert.Greater(t, len(o.Parsed), 1)
+}
+
+func TestParseSysfsValues(t *testing.T) {
+	assert.Equal(t, "Active", parseSysfsState("4: ACTIVE"))
+	assert.Equal(t, "Initializing", parseSysfsState("2: INIT"))
+	assert.Equal(t, "ACTIVE_DEFER", parseSysfsState("5: ACTIVE_DEFER"))
+	assert.Equal(t, "Polling", parseSysfsPhysicalState("2: Polling"))
+	assert.Equal(t, 200, parseSysfsRate("200 Gb/sec (2X NDR)"))
+	assert.Equal(t, 2, parseSysfsRate("2.5 Gb/sec (1X SDR)"))
+	assert.Equal(t, 0, parseSysfsRate(""))
+	assert.Equal(t, "", parseSysfsGID("0000:0000"))
+}
@@ -0,0 +1,129 @@
+package mofed
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+)
+
+// Compatibility is the validated range of the kernel and the NVIDIA driver
+// for a MOFED/DOCA release.
+type Compatibility struct {
+	// Release is the MOFED/DOCA release (e.g., "24.10").
+	Release string `json:"release"`
+
+	// MinKernel is the oldest supported kernel "<major>.<minor>" (e.g., "4.18").
+	MinKernel string `json:"min_kernel"`
+	// MaxKernel is the newest supported kernel "<major>.<minor>" (e.g., "6.11"),
+	// newer kernels are likely to fail the module build.
+	MaxKernel string `json:"max_kernel"`
+
+	// MinDriverMajor is the oldest NVIDIA driver major version validated
+	// with the release for the GPUDirect RDMA (e.g., 535).
+	MinDriverMajor int `json:"min_driver_major"`
+}
+
+// Matrix is the MOFED/DOCA compatibility matrix.
+type Matrix []Compatibility
+
+// DefaultMatrix is the compatibility matrix embedded from the release notes.
+// The releases not in the matrix are not validated.
+var DefaultMatrix Matrix
+
+//go:embed matrix.json
+var matrixRaw embed.FS
+
+func init() {
+	data, _ := matrixRaw.ReadFile("matrix.json")
+	if err := json.Unmarshal(data, &DefaultMatrix); err != nil {
+		panic(fmt.Errorf("failed to load mofed compatibility matrix: %v", err))
+	}
+}
+
+// Find returns the compatibility of the release, nil if not in the matrix.
+func (m Matrix) Find(release string) *Compatibility {
+	for i := range m {
+		if m[i].Release == release {
+			return &m[i]
+		}
+	}
+	return nil
+}
+
+// Validate returns the incompatibilities of the kernel version (e.g., "5.15.0-105-generic")
+// and the NVIDIA driver version (e.g., "550.90.07") with the release.
+// The empty driver version is not validated (e.g., NVML not loaded).
+func (c Compatibility) Validate(kernelVersion string, driverVersion string) ([]string, error) {
+	var issues []string
+
+	if kernelVersion != "" {
+		kernel, err := parseMajorMinor(kernelVersion)
+		if err != nil {
+			return nil, err
+		}
+		minKernel, err := parseMajorMinor(c.MinKernel)
+		if err != nil {
+			return nil, err
+		}
+		maxKernel, err := parseMajorMinor(c.MaxKernel)
+		if err != nil {
+			return nil, err
+		}
+		if kernel.less(minKernel) {
+			issues = append(issues, fmt.Sprintf("kernel %s is older than %s supported by MOFED/DOCA %s", kernelVersion, c.MinKernel, c.Release))
+		}
+		if maxKernel.less(kernel) {
+			issues = append(issues, fmt.Sprintf("kernel %s is newer than %s supported by MOFED/DOCA %s", kernelVersion, c.MaxKernel, c.Release))
+		}
+	}
+
+	if driverVersion != "" && c.MinDriverMajor > 0 {
+		major, _, _, err := nvidianvml.ParseDriverVersion(driverVersion)
+		if err != nil {
+			return nil, err
+		}
+		if major < c.MinDriverMajor {
+			issues = append(issues, fmt.Sprintf("nvidia driver %s is older than %d validated with MOFED/DOCA %s", driverVersion, c.MinDriverMajor, c.Release))
+		}
+	}
+
+	return issues, nil
+}
+
+type majorMinor struct {
+	major int
+	minor int
+}
+
+func (a majorMinor) less(b majorMinor) bool {
+	if a.major != b.major {
+		return a.major < b.major
+	}
+	return a.minor < b.minor
+}
+
+// parseMajorMinor parses the "<major>.<minor>" prefix of the version
+// (e.g., "5.15.0-105-generic" to 5 and 15).
+func parseMajorMinor(version string) (majorMinor, error) {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return majorMinor{}, fmt.Errorf("failed to parse version %q (expected <major>.<minor>)", version)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return majorMinor{}, fmt.Errorf("failed to parse version %q: %w", version, err)
+	}
+	minorStr := parts[1]
+	if i := strings.IndexFunc(minorStr, func(r rune) bool { return r < '0' || r > '9' }); i >= 0 {
+		minorStr = minorStr[:i]
+	}
+	minor, err := strconv.Atoi(minorStr)
+	if err != nil {
+		return majorMinor{}, fmt.Errorf("failed to parse version %q: %w", version, err)
+	}
+	return majorMinor{major: major, minor: minor}, nil
+}
@@ -0,0 +1,71 @@
+package mofed
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultMatrix(t *testing.T) {
+	require.NotEmpty(t, DefaultMatrix)
+	for _, c := range DefaultMatrix {
+		_, err := parseMajorMinor(c.MinKernel)
+		require.NoError(t, err, c.Release)
+		_, err = parseMajorMinor(c.MaxKernel)
+		require.NoError(t, err, c.Release)
+	}
+
+	c := DefaultMatrix.Find("24.10")
+	require.NotNil(t, c)
+	assert.Equal(t, "24.10", c.Release)
+	assert.Nil(t, DefaultMatrix.Find("1.0"))
+}
+
+func TestValidate(t *testing.T) {
+	c := Compatibility{Release: "24.10", MinKernel: "4.18", MaxKernel: "6.11", MinDriverMajor: 535}
+
+	tests := []struct {
+		name          string
+		kernelVersion string
+		driverVersion string
+		wantIssues    int
+	}{
+		{name: "compatible", kernelVersion: "5.15.0-105-generic", driverVersion: "550.90.07"},
+		{name: "max kernel", kernelVersion: "6.11.0-1007-aws", driverVersion: "535.183.01"},
+		{name: "unknown driver", kernelVersion: "6.8.0-45-generic"},
+		{name: "kernel too new", kernelVersion: "6.14.0-1-generic", driverVersion: "550.90.07", wantIssues: 1},
+		{name: "kernel too old", kernelVersion: "3.10.0-1160.el7.x86_64", driverVersion: "550.90.07", wantIssues: 1},
+		{name: "driver too old", kernelVersion: "5.15.0-105-generic", driverVersion: "525.147.05", wantIssues: 1},
+		{name: "both", kernelVersion: "6.14.0", driverVersion: "470.256.02", wantIssues: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues, err := c.Validate(tt.kernelVersion, tt.driverVersion)
+			require.NoError(t, err)
+			assert.Len(t, issues, tt.wantIssues, issues)
+		})
+	}
+
+	_, err := c.Validate("invalid", "")
+	assert.Error(t, err)
+	_, err = c.Validate("", "invalid")
+	assert.Error(t, err)
+}
+
+func TestParseMajorMinor(t *testing.T) {
+	v, err := parseMajorMinor("5.15.0-105-generic")
+	require.NoError(t, err)
+	assert.Equal(t, majorMinor{major: 5, minor: 15}, v)
+
+	v, err = parseMajorMinor("6.8")
+	require.NoError(t, err)
+	assert.Equal(t, majorMinor{major: 6, minor: 8}, v)
+
+	v, err = parseMajorMinor("6.14-rc1")
+	require.NoError(t, err)
+	assert.Equal(t, majorMinor{major: 6, minor: 14}, v)
+
+	_, err = parseMajorMinor("6")
+	assert.Error(t, err)
+}
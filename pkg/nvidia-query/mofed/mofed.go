@@ -0,0 +1,118 @@
+// Package mofed detects the installed MLNX_OFED or DOCA-OFED version and the
+// loaded mlx5_core module, and validates them against the embedded compatibility matrix.
+package mofed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/leptonai/gpud/pkg/toolexec"
+	"github.com/leptonai/gpud/pkg/toolpath"
+)
+
+// DefaultOFEDInfo prints the short version of the installed OFED stack (via "ofed_info -s").
+const DefaultOFEDInfo = "ofed_info"
+
+// DefaultSysModuleDir is the sysfs directory of the loaded mlx5_core module.
+const DefaultSysModuleDir = "/sys/module/mlx5_core"
+
+var ErrNoOFEDInfo = errors.New("ofed_info not found, MOFED/DOCA not installed")
+
+// Flavor is the distribution of the OFED stack.
+type Flavor string
+
+const (
+	// FlavorMLNXOFED is the legacy MLNX_OFED (e.g., "MLNX_OFED_LINUX-23.10-1.1.9.0").
+	FlavorMLNXOFED Flavor = "mlnx-ofed"
+	// FlavorDOCA is the DOCA-OFED shipped with the DOCA-Host (e.g., "OFED-internal-24.10-1.1.4").
+	FlavorDOCA Flavor = "doca"
+)
+
+// Version is the installed OFED stack version.
+type Version struct {
+	Flavor Flavor `json:"flavor"`
+	// Version is the full version (e.g., "24.10-1.1.4").
+	Version string `json:"version"`
+	// Release is the "<year>.<month>" release of the version (e.g., "24.10"),
+	// or "<major>.<minor>" for the older MLNX_OFED (e.g., "5.8").
+	Release string `json:"release"`
+}
+
+// e.g.,
+// "MLNX_OFED_LINUX-5.8-3.0.7.0:"
+// "OFED-internal-24.10-1.1.4:"
+var ofedInfoRegex = regexp.MustCompile(`^(MLNX_OFED_LINUX|OFED-internal)-(\d+\.\d+)-([\d.]+):?$`)
+
+// ParseOFEDInfo parses the "ofed_info -s" output.
+func ParseOFEDInfo(out string) (*Version, error) {
+	for _, line := range strings.Split(out, "\n") {
+		m := ofedInfoRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		v := &Version{
+			Flavor:  FlavorMLNXOFED,
+			Version: m[2] + "-" + m[3],
+			Release: m[2],
+		}
+		if m[1] == "OFED-internal" {
+			v.Flavor = FlavorDOCA
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("unexpected ofed_info output %q", strings.TrimSpace(out))
+}
+
+// GetVersion returns the installed OFED stack version, or ErrNoOFEDInfo if not installed.
+func GetVersion(ctx context.Context) (*Version, error) {
+	binPath, err := toolpath.Locate(DefaultOFEDInfo)
+	if err != nil {
+		return nil, ErrNoOFEDInfo
+	}
+
+	res, err := toolexec.Run(ctx, []string{binPath, "-s"})
+	if err != nil {
+		var out string
+		if res != nil {
+			out = strings.TrimSpace(string(res.Output))
+		}
+		return nil, fmt.Errorf("failed to run %q: %w (output %q)", binPath+" -s", err, out)
+	}
+	return ParseOFEDInfo(string(res.Output))
+}
+
+// GetLoadedModuleVersion returns the version of the loaded mlx5_core module
+// from the sysfs module directory, and false if the module is not loaded.
+// The version is empty for the inbox (kernel-provided) module,
+// which does not report its version.
+func GetLoadedModuleVersion(moduleDir string) (string, bool, error) {
+	if _, err := os.Stat(moduleDir); err != nil {
+		if os.IsNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	b, err := os.ReadFile(filepath.Join(moduleDir, "version"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", true, nil
+		}
+		return "", true, err
+	}
+	return strings.TrimSpace(string(b)), true, nil
+}
+
+// MatchesModule returns true if the loaded mlx5_core module version
+// (e.g., "24.10-1.1.4") is built from the installed version.
+func (v Version) MatchesModule(moduleVersion string) bool {
+	if moduleVersion == "" {
+		return false
+	}
+	return strings.HasPrefix(v.Version, moduleVersion) || strings.HasPrefix(moduleVersion, v.Version)
+}
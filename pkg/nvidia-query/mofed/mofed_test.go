@@ -0,0 +1,63 @@
+package mofed
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOFEDInfo(t *testing.T) {
+	tests := []struct {
+		out     string
+		want    *Version
+		wantErr bool
+	}{
+		{out: "MLNX_OFED_LINUX-5.8-3.0.7.0:\n", want: &Version{Flavor: FlavorMLNXOFED, Version: "5.8-3.0.7.0", Release: "5.8"}},
+		{out: "MLNX_OFED_LINUX-23.10-1.1.9.0", want: &Version{Flavor: FlavorMLNXOFED, Version: "23.10-1.1.9.0", Release: "23.10"}},
+		{out: "OFED-internal-24.10-1.1.4:\n", want: &Version{Flavor: FlavorDOCA, Version: "24.10-1.1.4", Release: "24.10"}},
+		{out: "", wantErr: true},
+		{out: "command not found", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.out, func(t *testing.T) {
+			v, err := ParseOFEDInfo(tt.out)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, v)
+		})
+	}
+}
+
+func TestGetLoadedModuleVersion(t *testing.T) {
+	dir := t.TempDir()
+
+	_, loaded, err := GetLoadedModuleVersion(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.False(t, loaded)
+
+	// inbox module without the version
+	v, loaded, err := GetLoadedModuleVersion(dir)
+	require.NoError(t, err)
+	assert.True(t, loaded)
+	assert.Empty(t, v)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "version"), []byte("24.10-1.1.4\n"), 0644))
+	v, loaded, err = GetLoadedModuleVersion(dir)
+	require.NoError(t, err)
+	assert.True(t, loaded)
+	assert.Equal(t, "24.10-1.1.4", v)
+}
+
+func TestMatchesModule(t *testing.T) {
+	v := Version{Flavor: FlavorMLNXOFED, Version: "5.8-3.0.7.0", Release: "5.8"}
+	assert.True(t, v.MatchesModule("5.8-3.0.7"))
+	assert.True(t, v.MatchesModule("5.8-3.0.7.0"))
+	assert.False(t, v.MatchesModule("5.8-1.0.1"))
+	assert.False(t, v.MatchesModule(""))
+}
@@ -0,0 +1,59 @@
+package nvml
+
+import (
+	"fmt"
+
+	"github.com/NVIDIA/go-nvlib/pkg/nvlib/device"
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+)
+
+// Fan represents the data from the nvmlDeviceGetFanSpeed_v2 API.
+// Passively cooled GPUs (e.g., most data center GPUs) have no fans.
+// ref. https://docs.nvidia.com/deploy/nvml-api/group__nvmlDeviceQueries.html
+type Fan struct {
+	// Represents the GPU UUID.
+	UUID string `json:"uuid"`
+
+	// SpeedPercents is the intended operating speed of each fan,
+	// as a percent of its maximum speed.
+	SpeedPercents []uint32 `json:"speed_percents,omitempty"`
+
+	// Supported is true if the fan speed is supported by the device.
+	Supported bool `json:"supported"`
+}
+
+func GetFan(uuid string, dev device.Device) (Fan, error) {
+	fan := Fan{
+		UUID:      uuid,
+		Supported: true,
+	}
+
+	n, ret := dev.GetNumFans()
+	if IsNotSupportError(ret) {
+		fan.Supported = false
+		return fan, nil
+	}
+	if IsGPULostError(ret) {
+		return fan, ErrGPULost
+	}
+	if ret != nvml.SUCCESS { // not a "not supported" error, not a success return, thus return an error here
+		return fan, fmt.Errorf("failed to get device number of fans: %v", nvml.ErrorString(ret))
+	}
+
+	for i := 0; i < n; i++ {
+		speed, ret := dev.GetFanSpeed_v2(i)
+		if IsNotSupportError(ret) {
+			fan.Supported = false
+			return fan, nil
+		}
+		if IsGPULostError(ret) {
+			return fan, ErrGPULost
+		}
+		if ret != nvml.SUCCESS {
+			return fan, fmt.Errorf("failed to get device fan %d speed: %v", i, nvml.ErrorString(ret))
+		}
+		fan.SpeedPercents = append(fan.SpeedPercents, speed)
+	}
+
+	return fan, nil
+}
@@ -0,0 +1,42 @@
+package nvml
+
+import (
+	"testing"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/nvidia-query/nvml/testutil"
+)
+
+func TestGetFan(t *testing.T) {
+	dev := &testutil.MockDevice{
+		Device: &mock.Device{
+			GetNumFansFunc: func() (int, nvml.Return) {
+				return 2, nvml.SUCCESS
+			},
+			GetFanSpeed_v2Func: func(i int) (uint32, nvml.Return) {
+				return uint32(30 + i), nvml.SUCCESS
+			},
+		},
+	}
+	fan, err := GetFan("GPU-1", dev)
+	require.NoError(t, err)
+	assert.True(t, fan.Supported)
+	assert.Equal(t, []uint32{30, 31}, fan.SpeedPercents)
+
+	dev.Device.GetFanSpeed_v2Func = func(i int) (uint32, nvml.Return) {
+		return 0, nvml.ERROR_UNKNOWN
+	}
+	_, err = GetFan("GPU-1", dev)
+	assert.Error(t, err)
+
+	dev.Device.GetNumFansFunc = func() (int, nvml.Return) {
+		return 0, nvml.ERROR_NOT_SUPPORTED
+	}
+	fan, err = GetFan("GPU-1", dev)
+	require.NoError(t, err)
+	assert.False(t, fan.Supported)
+}
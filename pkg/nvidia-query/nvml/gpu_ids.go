@@ -30,3 +30,12 @@ func GetBoardID(uuid string, dev device.Device) (uint32, error) {
 	}
 	return boardID, nil
 }
+
+// GetVBIOSVersion returns the VBIOS version of the GPU (e.g., "96.00.89.00.01").
+func GetVBIOSVersion(uuid string, dev device.Device) (string, error) {
+	version, ret := dev.GetVbiosVersion()
+	if ret != nvml.SUCCESS {
+		return "", fmt.Errorf("failed to get vbios version: %v", nvml.ErrorString(ret))
+	}
+	return version, nil
+}
@@ -84,6 +84,22 @@ func TestGetBoardID(t *testing.T) {
 }
 
 // mockErrorDevice implements device.Device and returns errors for the methods we're testing
+func TestGetVBIOSVersion(t *testing.T) {
+	mockDevice := &mock.Device{
+		GetVbiosVersionFunc: func() (string, nvml.Return) {
+			return "96.00.89.00.01", nvml.SUCCESS
+		},
+	}
+	version, err := GetVBIOSVersion("test-uuid", testutil.NewMockDevice(mockDevice, "test-arch", "test-brand", "test-cuda", "test-pci"))
+	assert.NoError(t, err)
+	assert.Equal(t, "96.00.89.00.01", version)
+
+	errorDevice := &mockErrorDevice{errorCode: nvml.ERROR_NOT_SUPPORTED}
+	version, err = GetVBIOSVersion("test-uuid", errorDevice)
+	assert.Error(t, err)
+	assert.Empty(t, version)
+}
+
 type mockErrorDevice struct {
 	device.Device
 	errorCode nvml.Return
@@ -100,3 +116,7 @@ func (d *mockErrorDevice) GetMinorNumber() (int, nvml.Return) {
 func (d *mockErrorDevice) GetBoardId() (uint32, nvml.Return) {
 	return 0, d.errorCode
 }
+
+func (d *mockErrorDevice) GetVbiosVersion() (string, nvml.Return) {
+	return "", d.errorCode
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/leptonai/gpud/pkg/log"
 	nvmllib "github.com/leptonai/gpud/pkg/nvidia-query/nvml/lib"
+	"github.com/leptonai/gpud/pkg/pci"
 )
 
 var _ Instance = &instance{}
@@ -56,6 +57,10 @@ type Instance interface {
 	// GetMemoryErrorManagementCapabilities returns the memory error management capabilities of the GPU.
 	GetMemoryErrorManagementCapabilities() MemoryErrorManagementCapabilities
 
+	// VirtualizationMode returns the virtualization mode of the GPU
+	// (e.g., "vgpu" in the guest VM with the virtual GPUs).
+	VirtualizationMode() VirtualizationMode
+
 	// Shutdown shuts down the NVML library.
 	Shutdown() error
 }
@@ -113,7 +118,16 @@ func newInstance(refreshCtx context.Context, refreshNVML func(context.Context))
 		}
 		return nil, err
 	}
+	return newInstanceWithLibrary(nvmlLib)
+}
 
+// NewWithLibrary creates a new instance with the already initialized NVML library
+// (e.g., the fake NVML library in the dev mode).
+func NewWithLibrary(nvmlLib nvmllib.Library) (Instance, error) {
+	return newInstanceWithLibrary(nvmlLib)
+}
+
+func newInstanceWithLibrary(nvmlLib nvmllib.Library) (Instance, error) {
 	log.Logger.Debugw("checking if nvml exists from info library")
 	nvmlExists, nvmlExistsMsg := nvmlLib.Info().HasNvml()
 	if !nvmlExists {
@@ -147,6 +161,7 @@ func newInstance(refreshCtx context.Context, refreshNVML func(context.Context))
 	productName := ""
 	archFamily := ""
 	brand := ""
+	virtMode := VirtualizationModeNone
 	dm := make(map[string]device.Device)
 	if len(devices) > 0 {
 		name, ret := devices[0].GetName()
@@ -167,6 +182,13 @@ func newInstance(refreshCtx context.Context, refreshNVML func(context.Context))
 			return nil, err
 		}
 
+		// all the GPUs in the system share the same virtualization mode
+		virtMode, err = getVirtualizationMode(devices[0], pci.DefaultSysfsRoot)
+		if err != nil {
+			log.Logger.Warnw("failed to get virtualization mode, assuming bare-metal", "error", err)
+			virtMode = VirtualizationModeNone
+		}
+
 		for _, dev := range devices {
 			uuid, ret := dev.GetUUID()
 			if ret != nvml.SUCCESS {
@@ -192,6 +214,7 @@ func newInstance(refreshCtx context.Context, refreshNVML func(context.Context))
 		brand:                brand,
 		fabricMgrSupported:   fmSupported,
 		memMgmtCaps:          memMgmtCaps,
+		virtMode:             virtMode,
 	}, nil
 }
 
@@ -215,6 +238,8 @@ type instance struct {
 
 	fabricMgrSupported bool
 	memMgmtCaps        MemoryErrorManagementCapabilities
+
+	virtMode VirtualizationMode
 }
 
 func (inst *instance) NVMLExists() bool {
@@ -261,6 +286,10 @@ func (inst *instance) GetMemoryErrorManagementCapabilities() MemoryErrorManageme
 	return inst.memMgmtCaps
 }
 
+func (inst *instance) VirtualizationMode() VirtualizationMode {
+	return inst.virtMode
+}
+
 func (inst *instance) Shutdown() error {
 	ret := inst.nvmlLib.Shutdown()
 	if ret != nvml.SUCCESS {
@@ -290,4 +319,5 @@ func (inst *noOpInstance) FabricManagerSupported() bool      { return false }
 func (inst *noOpInstance) GetMemoryErrorManagementCapabilities() MemoryErrorManagementCapabilities {
 	return MemoryErrorManagementCapabilities{}
 }
-func (inst *noOpInstance) Shutdown() error { return nil }
+func (inst *noOpInstance) VirtualizationMode() VirtualizationMode { return VirtualizationModeNone }
+func (inst *noOpInstance) Shutdown() error                        { return nil }
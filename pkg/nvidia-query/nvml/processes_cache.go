@@ -0,0 +1,51 @@
+package nvml
+
+import (
+	"sync"
+	"time"
+)
+
+// ProcessesCache caches the latest per-GPU processes, so that the other
+// components can look up the processes running on a GPU (e.g., when an Xid
+// error occurs) without querying NVML again.
+// All the methods are no-op for a nil cache.
+type ProcessesCache struct {
+	mu    sync.RWMutex
+	ts    time.Time
+	procs map[string]Processes
+}
+
+// NewProcessesCache creates a new empty processes cache.
+func NewProcessesCache() *ProcessesCache {
+	return &ProcessesCache{}
+}
+
+// Set replaces the cached processes with the ones queried at the time.
+func (c *ProcessesCache) Set(ts time.Time, procs []Processes) {
+	if c == nil {
+		return
+	}
+
+	m := make(map[string]Processes, len(procs))
+	for _, p := range procs {
+		m[p.UUID] = p
+	}
+
+	c.mu.Lock()
+	c.ts = ts
+	c.procs = m
+	c.mu.Unlock()
+}
+
+// Get returns the cached processes of the GPU, and the time they were queried.
+// It returns false if the GPU processes are not cached.
+func (c *ProcessesCache) Get(uuid string) (Processes, time.Time, bool) {
+	if c == nil {
+		return Processes{}, time.Time{}, false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	p, ok := c.procs[uuid]
+	return p, c.ts, ok
+}
@@ -0,0 +1,39 @@
+package nvml
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessesCache(t *testing.T) {
+	var nilCache *ProcessesCache
+	nilCache.Set(time.Now(), []Processes{{UUID: "GPU-0"}})
+	_, _, ok := nilCache.Get("GPU-0")
+	assert.False(t, ok)
+
+	c := NewProcessesCache()
+	_, _, ok = c.Get("GPU-0")
+	assert.False(t, ok)
+
+	ts := time.Now().UTC()
+	c.Set(ts, []Processes{
+		{UUID: "GPU-0", RunningProcesses: []Process{{PID: 100}}},
+		{UUID: "GPU-1"},
+	})
+	procs, cachedAt, ok := c.Get("GPU-0")
+	require.True(t, ok)
+	assert.Equal(t, ts, cachedAt)
+	require.Len(t, procs.RunningProcesses, 1)
+	assert.Equal(t, uint32(100), procs.RunningProcesses[0].PID)
+
+	// replaced, not merged
+	c.Set(ts.Add(time.Minute), []Processes{{UUID: "GPU-1"}})
+	_, _, ok = c.Get("GPU-0")
+	assert.False(t, ok)
+	_, cachedAt, ok = c.Get("GPU-1")
+	require.True(t, ok)
+	assert.Equal(t, ts.Add(time.Minute), cachedAt)
+}
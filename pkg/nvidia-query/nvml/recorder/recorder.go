@@ -0,0 +1,313 @@
+// Package recorder records the NVML calls and their results into the golden file,
+// and replays them as the NVML library, so that the field issues can be reproduced
+// and regression-tested on the machines without the GPUs.
+package recorder
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	nvmlmock "github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// Call is the recorded NVML call.
+type Call struct {
+	// Method is the NVML method name (e.g., "GetRemappedRows").
+	Method string `json:"method"`
+	// Args are the formatted call arguments, if any.
+	Args string `json:"args,omitempty"`
+	// Results are the JSON-encoded return values.
+	Results []json.RawMessage `json:"results"`
+}
+
+func (c Call) key() string {
+	return c.Method + "(" + c.Args + ")"
+}
+
+// Recording is the recorded NVML calls.
+type Recording struct {
+	// System are the calls on the library (e.g., "SystemGetDriverVersion").
+	System []Call `json:"system"`
+	// Devices are the calls on each device, in the device index order.
+	Devices [][]Call `json:"devices"`
+}
+
+// Recorder records the NVML calls made through the wrapped library.
+// Only the last result of the same call (the same method and arguments) is kept.
+type Recorder struct {
+	mu      sync.Mutex
+	system  map[string]Call
+	devices map[int]map[string]Call
+
+	wrapped map[int]nvml.Device
+}
+
+// New creates a new recorder.
+func New() *Recorder {
+	return &Recorder{
+		system:  make(map[string]Call),
+		devices: make(map[int]map[string]Call),
+		wrapped: make(map[int]nvml.Device),
+	}
+}
+
+// Recording returns the calls recorded so far, sorted by the method and arguments.
+func (r *Recorder) Recording() Recording {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec := Recording{System: sortCalls(r.system)}
+	maxIdx := -1
+	for idx := range r.devices {
+		if idx > maxIdx {
+			maxIdx = idx
+		}
+	}
+	rec.Devices = make([][]Call, maxIdx+1)
+	for idx, calls := range r.devices {
+		rec.Devices[idx] = sortCalls(calls)
+	}
+	return rec
+}
+
+func sortCalls(m map[string]Call) []Call {
+	calls := make([]Call, 0, len(m))
+	for _, c := range m {
+		calls = append(calls, c)
+	}
+	sort.Slice(calls, func(i, j int) bool { return calls[i].key() < calls[j].key() })
+	return calls
+}
+
+// record records the call results into the calls,
+// skipping the results that cannot be encoded (e.g., NaN).
+func (r *Recorder) record(calls map[string]Call, method string, args []reflect.Value, results []reflect.Value) {
+	c := Call{Method: method, Args: formatArgs(args)}
+	for _, res := range results {
+		b, err := json.Marshal(res.Interface())
+		if err != nil {
+			log.Logger.Warnw("failed to encode nvml call result, skipping", "method", method, "error", err)
+			return
+		}
+		c.Results = append(c.Results, b)
+	}
+
+	r.mu.Lock()
+	calls[c.key()] = c
+	r.mu.Unlock()
+}
+
+// Wrap wraps the NVML library to record the calls made through it.
+func (r *Recorder) Wrap(lib nvml.Interface) nvml.Interface {
+	m := &nvmlmock.Interface{}
+	libV := reflect.ValueOf(lib)
+
+	setFuncs(m, func(method string, ft reflect.Type) (reflect.Value, bool) {
+		fn := libV.MethodByName(method)
+		if !fn.IsValid() {
+			return reflect.Value{}, false
+		}
+
+		switch {
+		case method == "Extensions":
+			return reflect.ValueOf(func() nvml.ExtendedInterface {
+				return r.wrapExtensions(lib.Extensions())
+			}), true
+
+		case takesDevice(ft) || !recordableOuts(ft, true):
+			// the library dispatches the device calls to the wrapped devices,
+			// which records them
+			return reflect.MakeFunc(ft, func(args []reflect.Value) []reflect.Value {
+				return call(fn, ft, args)
+			}), true
+		}
+
+		return reflect.MakeFunc(ft, func(args []reflect.Value) []reflect.Value {
+			results := call(fn, ft, args)
+
+			recorded := make([]reflect.Value, len(results))
+			for i, res := range results {
+				recorded[i] = res
+				if ft.Out(i) != deviceType {
+					continue
+				}
+
+				// record the device by its index, and return the wrapped device
+				idx := -1
+				if !res.IsNil() {
+					dev := res.Interface().(nvml.Device)
+					if devIdx, ret := dev.GetIndex(); ret == nvml.SUCCESS {
+						idx = devIdx
+						results[i] = reflect.ValueOf(r.wrapDevice(idx, dev))
+					}
+				}
+				recorded[i] = reflect.ValueOf(idx)
+			}
+
+			r.record(r.system, method, args, recorded)
+			return results
+		}), true
+	})
+
+	return m
+}
+
+func (r *Recorder) wrapExtensions(ext nvml.ExtendedInterface) nvml.ExtendedInterface {
+	return &nvmlmock.ExtendedInterface{
+		LookupSymbolFunc: func(symbol string) error {
+			err := ext.LookupSymbol(symbol)
+			msg := ""
+			if err != nil {
+				msg = err.Error()
+			}
+			r.record(r.system, "Extensions.LookupSymbol", []reflect.Value{reflect.ValueOf(symbol)}, []reflect.Value{reflect.ValueOf(msg)})
+			return err
+		},
+	}
+}
+
+// recordingDevice records the calls on the device.
+// The real device is embedded at the deeper level than the mock,
+// so that the mock methods take precedence, while the NVML library
+// still resolves the real device handle (e.g., for the GPM samples).
+type recordingDevice struct {
+	realDevice
+	*nvmlmock.Device
+}
+
+type realDevice struct {
+	nvml.Device
+}
+
+func (r *Recorder) wrapDevice(idx int, dev nvml.Device) nvml.Device {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if wrapped, ok := r.wrapped[idx]; ok {
+		return wrapped
+	}
+	calls, ok := r.devices[idx]
+	if !ok {
+		calls = make(map[string]Call)
+		r.devices[idx] = calls
+	}
+
+	m := &nvmlmock.Device{}
+	devV := reflect.ValueOf(dev)
+	setFuncs(m, func(method string, ft reflect.Type) (reflect.Value, bool) {
+		fn := devV.MethodByName(method)
+		if !fn.IsValid() {
+			return reflect.Value{}, false
+		}
+		if !recordableOuts(ft, false) {
+			return reflect.MakeFunc(ft, func(args []reflect.Value) []reflect.Value {
+				return call(fn, ft, args)
+			}), true
+		}
+		return reflect.MakeFunc(ft, func(args []reflect.Value) []reflect.Value {
+			results := call(fn, ft, args)
+			r.record(calls, method, args, results)
+			return results
+		}), true
+	})
+
+	wrapped := &recordingDevice{realDevice: realDevice{Device: dev}, Device: m}
+	r.wrapped[idx] = wrapped
+	return wrapped
+}
+
+var (
+	deviceType = reflect.TypeOf((*nvml.Device)(nil)).Elem()
+	returnType = reflect.TypeOf(nvml.SUCCESS)
+)
+
+// setFuncs sets the function fields (e.g., "GetUUIDFunc") of the mock,
+// with the function built for the method (e.g., "GetUUID").
+func setFuncs(mock any, build func(method string, ft reflect.Type) (reflect.Value, bool)) {
+	v := reflect.ValueOf(mock).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || f.Type.Kind() != reflect.Func || !strings.HasSuffix(f.Name, "Func") {
+			continue
+		}
+		fn, ok := build(strings.TrimSuffix(f.Name, "Func"), f.Type)
+		if ok {
+			v.Field(i).Set(fn)
+		}
+	}
+}
+
+func call(fn reflect.Value, ft reflect.Type, args []reflect.Value) []reflect.Value {
+	if ft.IsVariadic() {
+		return fn.CallSlice(args)
+	}
+	return fn.Call(args)
+}
+
+// takesDevice returns true if the library method takes the device
+// (e.g., "DeviceGetUUID(device)"), which is dispatched to the device method.
+func takesDevice(ft reflect.Type) bool {
+	for i := 0; i < ft.NumIn(); i++ {
+		if ft.In(i) == deviceType {
+			return true
+		}
+	}
+	return false
+}
+
+// recordableOuts returns true if all the return values can be encoded
+// and decoded, allowing the devices if allowDevice is true.
+func recordableOuts(ft reflect.Type, allowDevice bool) bool {
+	for i := 0; i < ft.NumOut(); i++ {
+		if allowDevice && ft.Out(i) == deviceType {
+			continue
+		}
+		if !recordable(ft.Out(i), 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func recordable(t reflect.Type, depth int) bool {
+	if depth > 8 {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	case reflect.Array, reflect.Slice, reflect.Ptr:
+		return recordable(t.Elem(), depth+1)
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && recordable(t.Elem(), depth+1)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() && !recordable(t.Field(i).Type, depth+1) {
+				return false
+			}
+		}
+		return true
+	default:
+		// e.g., interfaces, functions, channels
+		return false
+	}
+}
+
+func formatArgs(args []reflect.Value) string {
+	ss := make([]string, 0, len(args))
+	for _, a := range args {
+		ss = append(ss, fmt.Sprintf("%v", a.Interface()))
+	}
+	return strings.Join(ss, ",")
+}
@@ -0,0 +1,156 @@
+package recorder
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/devmode"
+	devmodefake "github.com/leptonai/gpud/pkg/devmode/fake"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	nvmllib "github.com/leptonai/gpud/pkg/nvidia-query/nvml/lib"
+	nvmllibmock "github.com/leptonai/gpud/pkg/nvidia-query/nvml/lib/mock"
+)
+
+func newInstance(t *testing.T, lib nvml.Interface) nvidianvml.Instance {
+	l, err := nvmllib.New(
+		nvmllib.WithNVML(lib),
+		nvmllib.WithPropertyExtractor(nvmllibmock.HasNvmlPropertyExtractor),
+	)
+	require.NoError(t, err)
+	inst, err := nvidianvml.NewWithLibrary(l)
+	require.NoError(t, err)
+	return inst
+}
+
+type deviceReport struct {
+	Memory       nvidianvml.Memory
+	RemappedRows nvidianvml.RemappedRows
+	ECCErrors    nvidianvml.ECCErrors
+	HWSlowdown   []string
+	NVLinkStates int
+	Temperature  nvidianvml.Temperature
+	Power        nvidianvml.Power
+}
+
+func report(t *testing.T, inst nvidianvml.Instance) map[string]deviceReport {
+	reports := make(map[string]deviceReport)
+	for uuid, dev := range inst.Devices() {
+		var r deviceReport
+		var err error
+		r.Memory, err = nvidianvml.GetMemory(uuid, dev)
+		require.NoError(t, err)
+		r.RemappedRows, err = nvidianvml.GetRemappedRows(uuid, dev)
+		require.NoError(t, err)
+		r.ECCErrors, err = nvidianvml.GetECCErrors(uuid, dev, true)
+		require.NoError(t, err)
+		clockEvents, err := nvidianvml.GetClockEvents(uuid, dev)
+		require.NoError(t, err)
+		r.HWSlowdown = clockEvents.HWSlowdownReasons
+		nvlink, err := nvidianvml.GetNVLink(uuid, dev)
+		require.NoError(t, err)
+		r.NVLinkStates = len(nvlink.States)
+		r.Temperature, err = nvidianvml.GetTemperature(uuid, dev)
+		require.NoError(t, err)
+		r.Power, err = nvidianvml.GetPower(uuid, dev)
+		require.NoError(t, err)
+		reports[uuid] = r
+	}
+	return reports
+}
+
+func TestRecordReplay(t *testing.T) {
+	fake, err := devmodefake.NewNVMLInstance(devmode.Config{
+		GPUs:      2,
+		Scenarios: []devmode.Scenario{devmode.ScenarioRemappedRowsPending, devmode.ScenarioHWSlowdown, devmode.ScenarioECCUncorrectable},
+	})
+	require.NoError(t, err)
+
+	rec := New()
+	recorded := newInstance(t, rec.Wrap(fake.Library().NVML()))
+	want := report(t, recorded)
+	require.Len(t, want, 2)
+
+	// round trip the golden file
+	b, err := json.Marshal(rec.Recording())
+	require.NoError(t, err)
+	var golden Recording
+	require.NoError(t, json.Unmarshal(b, &golden))
+	require.Len(t, golden.Devices, 2)
+	require.NotEmpty(t, golden.System)
+
+	replayed := newInstance(t, Replay(golden))
+	assert.Equal(t, recorded.ProductName(), replayed.ProductName())
+	assert.Equal(t, want, report(t, replayed))
+
+	// the failures are replayed
+	var pending int
+	for _, r := range want {
+		if r.RemappedRows.RemappingPending {
+			pending++
+		}
+	}
+	assert.Equal(t, 1, pending)
+}
+
+func TestReplayNotRecorded(t *testing.T) {
+	lib := Replay(Recording{})
+
+	count, ret := lib.DeviceGetCount()
+	assert.Equal(t, nvml.ERROR_NOT_SUPPORTED, ret)
+	assert.Zero(t, count)
+
+	assert.Equal(t, nvml.SUCCESS, lib.Init())
+	assert.NoError(t, lib.Extensions().LookupSymbol("nvmlDeviceGetCurrentClocksEventReasons"))
+}
+
+func TestReplayLibraryNotFound(t *testing.T) {
+	lib := Replay(Recording{
+		System: []Call{
+			{Method: "Init", Results: []json.RawMessage{json.RawMessage(`12`)}},
+		},
+	})
+	assert.Equal(t, nvml.ERROR_LIBRARY_NOT_FOUND, lib.Init())
+
+	_, err := nvmllib.New(nvmllib.WithNVML(lib))
+	assert.ErrorIs(t, err, nvmllib.ErrNVMLNotFound)
+}
+
+func TestReplayDevice(t *testing.T) {
+	lib := Replay(Recording{
+		System: []Call{
+			{Method: "DeviceGetCount", Results: []json.RawMessage{json.RawMessage(`1`), json.RawMessage(`0`)}},
+			{Method: "DeviceGetHandleByIndex", Args: "0", Results: []json.RawMessage{json.RawMessage(`0`), json.RawMessage(`0`)}},
+		},
+		Devices: [][]Call{
+			{
+				{Method: "GetUUID", Results: []json.RawMessage{json.RawMessage(`"GPU-0"`), json.RawMessage(`0`)}},
+			},
+		},
+	})
+
+	dev, ret := lib.DeviceGetHandleByIndex(0)
+	require.Equal(t, nvml.SUCCESS, ret)
+
+	uuid, ret := dev.GetUUID()
+	assert.Equal(t, nvml.SUCCESS, ret)
+	assert.Equal(t, "GPU-0", uuid)
+
+	// dispatched to the device
+	uuid, ret = lib.DeviceGetUUID(dev)
+	assert.Equal(t, nvml.SUCCESS, ret)
+	assert.Equal(t, "GPU-0", uuid)
+
+	_, ret = dev.GetSerial()
+	assert.Equal(t, nvml.ERROR_NOT_SUPPORTED, ret)
+
+	support, ret := dev.GpmQueryDeviceSupport()
+	assert.Equal(t, nvml.SUCCESS, ret)
+	assert.Zero(t, support.IsSupportedDevice)
+
+	_, ret = lib.DeviceGetHandleByIndex(1)
+	assert.Equal(t, nvml.ERROR_NOT_SUPPORTED, ret)
+}
@@ -0,0 +1,187 @@
+package recorder
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	nvmlmock "github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// replayCalls looks up the recorded call results.
+type replayCalls struct {
+	exact map[string]Call
+	// byMethod is the fallback for the calls with the different arguments
+	// (e.g., the timestamps), nil to only look up the exact calls
+	byMethod map[string]Call
+}
+
+func newReplayCalls(calls []Call, fallback bool) replayCalls {
+	rc := replayCalls{
+		exact: make(map[string]Call, len(calls)),
+	}
+	if fallback {
+		rc.byMethod = make(map[string]Call, len(calls))
+	}
+	for _, c := range calls {
+		rc.exact[c.key()] = c
+		if fallback {
+			rc.byMethod[c.Method] = c
+		}
+	}
+	return rc
+}
+
+func (rc replayCalls) lookup(method string, args []reflect.Value) (Call, bool) {
+	if c, ok := rc.exact[Call{Method: method, Args: formatArgs(args)}.key()]; ok {
+		return c, true
+	}
+	c, ok := rc.byMethod[method]
+	return c, ok
+}
+
+// Replay returns the NVML library that returns the recorded results.
+// The calls not recorded return ERROR_NOT_SUPPORTED, and the GPM is
+// reported as unsupported, since the GPM samples are read from the real library.
+func Replay(rec Recording) nvml.Interface {
+	devs := make([]nvml.Device, len(rec.Devices))
+	for idx, calls := range rec.Devices {
+		devs[idx] = replayDevice(newReplayCalls(calls, true))
+	}
+	// the device handles must match the exact arguments (e.g., the index)
+	system := newReplayCalls(rec.System, false)
+
+	m := &nvmlmock.Interface{}
+	setFuncs(m, func(method string, ft reflect.Type) (reflect.Value, bool) {
+		switch method {
+		case "Init":
+			// replays the library not found, if not found when recorded
+			return reflect.ValueOf(func() nvml.Return {
+				c, ok := system.lookup(method, nil)
+				if !ok || len(c.Results) != 1 {
+					return nvml.SUCCESS
+				}
+				var ret nvml.Return
+				if err := json.Unmarshal(c.Results[0], &ret); err != nil {
+					return nvml.SUCCESS
+				}
+				return ret
+			}), true
+
+		case "Shutdown":
+			return reflect.ValueOf(func() nvml.Return { return nvml.SUCCESS }), true
+
+		case "Extensions":
+			return reflect.ValueOf(func() nvml.ExtendedInterface {
+				return &nvmlmock.ExtendedInterface{
+					LookupSymbolFunc: func(symbol string) error {
+						c, ok := system.lookup("Extensions.LookupSymbol", []reflect.Value{reflect.ValueOf(symbol)})
+						if !ok || len(c.Results) == 0 {
+							return nil
+						}
+						var msg string
+						if err := json.Unmarshal(c.Results[0], &msg); err != nil || msg == "" {
+							return nil
+						}
+						return errors.New(msg)
+					},
+				}
+			}), true
+		}
+
+		if takesDevice(ft) {
+			// dispatch to the device method (e.g., "DeviceGetUUID(device)" to "device.GetUUID()")
+			devMethod := strings.TrimPrefix(method, "Device")
+			return reflect.MakeFunc(ft, func(args []reflect.Value) []reflect.Value {
+				if len(args) > 0 && ft.In(0) == deviceType && !args[0].IsNil() {
+					if fn := args[0].MethodByName(devMethod); fn.IsValid() && fn.Type().NumIn() == len(args)-1 {
+						return call(fn, fn.Type(), args[1:])
+					}
+				}
+				return notSupported(ft)
+			}), true
+		}
+
+		return reflect.MakeFunc(ft, func(args []reflect.Value) []reflect.Value {
+			c, ok := system.lookup(method, args)
+			if !ok {
+				return notSupported(ft)
+			}
+			return decodeResults(ft, method, c, func(idx int) nvml.Device {
+				if idx < 0 || idx >= len(devs) {
+					return nil
+				}
+				return devs[idx]
+			})
+		}), true
+	})
+	return m
+}
+
+func replayDevice(calls replayCalls) nvml.Device {
+	m := &nvmlmock.Device{}
+	setFuncs(m, func(method string, ft reflect.Type) (reflect.Value, bool) {
+		if method == "GpmQueryDeviceSupport" {
+			return reflect.ValueOf(func() (nvml.GpmSupport, nvml.Return) {
+				return nvml.GpmSupport{IsSupportedDevice: 0}, nvml.SUCCESS
+			}), true
+		}
+		return reflect.MakeFunc(ft, func(args []reflect.Value) []reflect.Value {
+			c, ok := calls.lookup(method, args)
+			if !ok {
+				return notSupported(ft)
+			}
+			return decodeResults(ft, method, c, nil)
+		}), true
+	})
+	return m
+}
+
+// decodeResults decodes the recorded results into the return values,
+// resolving the recorded device indexes with the devices.
+func decodeResults(ft reflect.Type, method string, c Call, devices func(int) nvml.Device) []reflect.Value {
+	if len(c.Results) != ft.NumOut() {
+		log.Logger.Warnw("unexpected number of recorded nvml call results", "method", method, "expected", ft.NumOut(), "recorded", len(c.Results))
+		return notSupported(ft)
+	}
+
+	results := make([]reflect.Value, ft.NumOut())
+	for i := range results {
+		out := ft.Out(i)
+		if out == deviceType && devices != nil {
+			var idx int
+			if err := json.Unmarshal(c.Results[i], &idx); err != nil {
+				return notSupported(ft)
+			}
+			results[i] = reflect.Zero(out)
+			if dev := devices(idx); dev != nil {
+				results[i] = reflect.ValueOf(dev)
+			}
+			continue
+		}
+
+		v := reflect.New(out)
+		if err := json.Unmarshal(c.Results[i], v.Interface()); err != nil {
+			log.Logger.Warnw("failed to decode recorded nvml call result", "method", method, "error", err)
+			return notSupported(ft)
+		}
+		results[i] = v.Elem()
+	}
+	return results
+}
+
+// notSupported returns the zero values, with ERROR_NOT_SUPPORTED for the NVML return code.
+func notSupported(ft reflect.Type) []reflect.Value {
+	results := make([]reflect.Value, ft.NumOut())
+	for i := range results {
+		results[i] = reflect.Zero(ft.Out(i))
+		if ft.Out(i) == returnType {
+			results[i] = reflect.ValueOf(nvml.ERROR_NOT_SUPPORTED)
+		}
+	}
+	return results
+}
@@ -0,0 +1,163 @@
+package nvml
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/NVIDIA/go-nvlib/pkg/nvlib/device"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Snapshot is a comprehensive point-in-time document of a GPU,
+// equivalent in coverage to "nvidia-smi -q".
+type Snapshot struct {
+	// Time is the time the snapshot was taken.
+	Time metav1.Time `json:"time"`
+
+	// Represents the GPU UUID.
+	UUID        string `json:"uuid"`
+	ProductName string `json:"product_name,omitempty"`
+	Serial      string `json:"serial,omitempty"`
+	MinorID     int    `json:"minor_id"`
+	PCIBusID    string `json:"pci_bus_id,omitempty"`
+
+	Utilization     Utilization     `json:"utilization"`
+	Memory          Memory          `json:"memory"`
+	Temperature     Temperature     `json:"temperature"`
+	Power           Power           `json:"power"`
+	ClockSpeed      ClockSpeed      `json:"clock_speed"`
+	ClockEvents     *ClockEvents    `json:"clock_events,omitempty"`
+	Processes       Processes       `json:"processes"`
+	ECCMode         ECCMode         `json:"ecc_mode"`
+	ECCErrors       ECCErrors       `json:"ecc_errors"`
+	Fan             Fan             `json:"fan"`
+	PersistenceMode PersistenceMode `json:"persistence_mode"`
+
+	// Errors are the errors of the sections that failed to be read,
+	// keyed by the section name (e.g., "power").
+	// The other sections are still populated.
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// GetSnapshot reads all the sections of the GPU snapshot.
+// The clock events are only read if supported by the driver
+// (see ClockEventsSupportedVersion).
+// Returns ErrGPULost if the GPU has fallen off the bus.
+func GetSnapshot(uuid string, productName string, dev device.Device, clockEventsSupported bool) (Snapshot, error) {
+	snap := Snapshot{
+		Time:        metav1.Time{Time: time.Now().UTC()},
+		UUID:        uuid,
+		ProductName: productName,
+	}
+
+	gpuLost := false
+	record := func(section string, err error) {
+		if err == nil {
+			return
+		}
+		if errors.Is(err, ErrGPULost) {
+			gpuLost = true
+		}
+		if snap.Errors == nil {
+			snap.Errors = make(map[string]string)
+		}
+		snap.Errors[section] = err.Error()
+	}
+
+	var err error
+	snap.Serial, err = GetSerial(uuid, dev)
+	record("serial", err)
+	snap.MinorID, err = GetMinorID(uuid, dev)
+	record("minor_id", err)
+	snap.PCIBusID, err = dev.GetPCIBusID()
+	record("pci_bus_id", err)
+
+	snap.Utilization, err = GetUtilization(uuid, dev)
+	record("utilization", err)
+	snap.Memory, err = GetMemory(uuid, dev)
+	record("memory", err)
+	snap.Temperature, err = GetTemperature(uuid, dev)
+	record("temperature", err)
+	snap.Power, err = GetPower(uuid, dev)
+	record("power", err)
+	snap.ClockSpeed, err = GetClockSpeed(uuid, dev)
+	record("clock_speed", err)
+	if clockEventsSupported {
+		evs, err := GetClockEvents(uuid, dev)
+		record("clock_events", err)
+		snap.ClockEvents = &evs
+	}
+	snap.Processes, err = GetProcesses(uuid, dev)
+	record("processes", err)
+	snap.ECCMode, err = GetECCModeEnabled(uuid, dev)
+	record("ecc_mode", err)
+	snap.ECCErrors, err = GetECCErrors(uuid, dev, snap.ECCMode.EnabledCurrent)
+	record("ecc_errors", err)
+	snap.Fan, err = GetFan(uuid, dev)
+	record("fan", err)
+	snap.PersistenceMode, err = GetPersistenceMode(uuid, dev)
+	record("persistence_mode", err)
+
+	if gpuLost {
+		return snap, ErrGPULost
+	}
+	return snap, nil
+}
+
+// DefaultSnapshotCacheTTL is the default duration to serve the cached snapshots for.
+const DefaultSnapshotCacheTTL = 5 * time.Second
+
+// SnapshotCache caches the snapshots of all the GPUs, so that frequent
+// readers (e.g., other agents polling the snapshot endpoint) do not
+// hit the NVML for every request.
+type SnapshotCache struct {
+	instance Instance
+	ttl      time.Duration
+
+	mu        sync.Mutex
+	snapshots []Snapshot
+	takenAt   time.Time
+}
+
+// NewSnapshotCache creates a new snapshot cache for the GPUs of the NVML instance.
+func NewSnapshotCache(instance Instance, ttl time.Duration) *SnapshotCache {
+	if ttl <= 0 {
+		ttl = DefaultSnapshotCacheTTL
+	}
+	return &SnapshotCache{
+		instance: instance,
+		ttl:      ttl,
+	}
+}
+
+// Get returns the snapshots of all the GPUs sorted by the minor ID,
+// taking new snapshots if the cached ones are older than the TTL.
+// Concurrent callers wait for the same refresh.
+func (c *SnapshotCache) Get() []Snapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.snapshots != nil && time.Since(c.takenAt) < c.ttl {
+		return c.snapshots
+	}
+
+	clockEventsSupported := ClockEventsSupportedVersion(c.instance.DriverMajor())
+	snapshots := make([]Snapshot, 0, len(c.instance.Devices()))
+	for uuid, dev := range c.instance.Devices() {
+		// partial snapshot is still returned for the lost GPU, with the errors
+		snap, _ := GetSnapshot(uuid, c.instance.ProductName(), dev, clockEventsSupported)
+		snapshots = append(snapshots, snap)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		if snapshots[i].MinorID != snapshots[j].MinorID {
+			return snapshots[i].MinorID < snapshots[j].MinorID
+		}
+		return snapshots[i].UUID < snapshots[j].UUID
+	})
+
+	c.snapshots = snapshots
+	c.takenAt = time.Now()
+	return snapshots
+}
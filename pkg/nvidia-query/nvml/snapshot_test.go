@@ -0,0 +1,134 @@
+package nvml
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/go-nvlib/pkg/nvlib/device"
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/nvidia-query/nvml/testutil"
+)
+
+func newSnapshotMockDevice(powerRet nvml.Return) *testutil.MockDevice {
+	return testutil.NewMockDeviceWithIDs(&mock.Device{
+		GetUtilizationRatesFunc: func() (nvml.Utilization, nvml.Return) {
+			return nvml.Utilization{Gpu: 90, Memory: 40}, nvml.SUCCESS
+		},
+		GetMemoryInfo_v2Func: func() (nvml.Memory_v2, nvml.Return) {
+			return nvml.Memory_v2{Total: 100, Used: 25, Free: 75}, nvml.SUCCESS
+		},
+		GetTemperatureFunc: func(nvml.TemperatureSensors) (uint32, nvml.Return) {
+			return 60, nvml.SUCCESS
+		},
+		GetTemperatureThresholdFunc: func(nvml.TemperatureThresholds) (uint32, nvml.Return) {
+			return 90, nvml.SUCCESS
+		},
+		GetPowerUsageFunc: func() (uint32, nvml.Return) {
+			return 300000, powerRet
+		},
+		GetEnforcedPowerLimitFunc: func() (uint32, nvml.Return) {
+			return 700000, nvml.SUCCESS
+		},
+		GetPowerManagementLimitFunc: func() (uint32, nvml.Return) {
+			return 700000, nvml.SUCCESS
+		},
+		GetClockInfoFunc: func(nvml.ClockType) (uint32, nvml.Return) {
+			return 1980, nvml.SUCCESS
+		},
+		GetCurrentClocksEventReasonsFunc: func() (uint64, nvml.Return) {
+			return 0, nvml.SUCCESS
+		},
+		GetComputeRunningProcessesFunc: func() ([]nvml.ProcessInfo, nvml.Return) {
+			return nil, nvml.SUCCESS
+		},
+		GetProcessUtilizationFunc: func(uint64) ([]nvml.ProcessUtilizationSample, nvml.Return) {
+			return nil, nvml.ERROR_NOT_SUPPORTED
+		},
+		GetEccModeFunc: func() (nvml.EnableState, nvml.EnableState, nvml.Return) {
+			return nvml.FEATURE_ENABLED, nvml.FEATURE_ENABLED, nvml.SUCCESS
+		},
+		GetTotalEccErrorsFunc: func(nvml.MemoryErrorType, nvml.EccCounterType) (uint64, nvml.Return) {
+			return 0, nvml.SUCCESS
+		},
+		GetMemoryErrorCounterFunc: func(nvml.MemoryErrorType, nvml.EccCounterType, nvml.MemoryLocation) (uint64, nvml.Return) {
+			return 0, nvml.ERROR_NOT_SUPPORTED
+		},
+		GetNumFansFunc: func() (int, nvml.Return) {
+			return 0, nvml.ERROR_NOT_SUPPORTED
+		},
+		GetPersistenceModeFunc: func() (nvml.EnableState, nvml.Return) {
+			return nvml.FEATURE_ENABLED, nvml.SUCCESS
+		},
+	}, "hopper", "Nvidia", "9.0", "0000:01:00.0", "SERIAL-1", 3, 0)
+}
+
+func TestGetSnapshot(t *testing.T) {
+	snap, err := GetSnapshot("GPU-1", "H100", newSnapshotMockDevice(nvml.SUCCESS), true)
+	require.NoError(t, err)
+	assert.Empty(t, snap.Errors)
+	assert.Equal(t, "GPU-1", snap.UUID)
+	assert.Equal(t, "H100", snap.ProductName)
+	assert.Equal(t, "SERIAL-1", snap.Serial)
+	assert.Equal(t, 3, snap.MinorID)
+	assert.Equal(t, "0000:01:00.0", snap.PCIBusID)
+	assert.Equal(t, uint32(90), snap.Utilization.GPUUsedPercent)
+	assert.Equal(t, uint64(25), snap.Memory.UsedBytes)
+	assert.Equal(t, uint32(60), snap.Temperature.CurrentCelsiusGPUCore)
+	assert.Equal(t, uint32(300000), snap.Power.UsageMilliWatts)
+	assert.Equal(t, uint32(1980), snap.ClockSpeed.GraphicsMHz)
+	require.NotNil(t, snap.ClockEvents)
+	assert.True(t, snap.ECCMode.EnabledCurrent)
+	assert.False(t, snap.Fan.Supported)
+	assert.True(t, snap.PersistenceMode.Enabled)
+
+	snap, err = GetSnapshot("GPU-1", "H100", newSnapshotMockDevice(nvml.SUCCESS), false)
+	require.NoError(t, err)
+	assert.Nil(t, snap.ClockEvents)
+}
+
+func TestGetSnapshotPartial(t *testing.T) {
+	snap, err := GetSnapshot("GPU-1", "H100", newSnapshotMockDevice(nvml.ERROR_UNKNOWN), true)
+	require.NoError(t, err)
+	require.Len(t, snap.Errors, 1)
+	assert.Contains(t, snap.Errors, "power")
+	assert.Equal(t, uint32(90), snap.Utilization.GPUUsedPercent)
+
+	_, err = GetSnapshot("GPU-1", "H100", newSnapshotMockDevice(nvml.ERROR_GPU_IS_LOST), true)
+	assert.True(t, errors.Is(err, ErrGPULost))
+}
+
+func TestSnapshotCache(t *testing.T) {
+	calls := 0
+	dev := newSnapshotMockDevice(nvml.SUCCESS)
+	getUtilization := dev.Device.GetUtilizationRatesFunc
+	dev.Device.GetUtilizationRatesFunc = func() (nvml.Utilization, nvml.Return) {
+		calls++
+		return getUtilization()
+	}
+
+	inst := &instance{
+		nvmlExists:           true,
+		driverMajor:          535,
+		sanitizedProductName: "H100",
+		devices:              map[string]device.Device{"GPU-1": dev},
+	}
+
+	cache := NewSnapshotCache(inst, time.Hour)
+	snapshots := cache.Get()
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, "H100", snapshots[0].ProductName)
+	assert.NotNil(t, snapshots[0].ClockEvents)
+
+	// served from the cache
+	cache.Get()
+	assert.Equal(t, 1, calls)
+
+	cache.takenAt = time.Now().Add(-2 * time.Hour)
+	cache.Get()
+	assert.Equal(t, 2, calls)
+}
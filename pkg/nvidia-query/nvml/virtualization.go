@@ -0,0 +1,172 @@
+package nvml
+
+import (
+	"fmt"
+
+	"github.com/NVIDIA/go-nvlib/pkg/nvlib/device"
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/pci"
+)
+
+// VirtualizationMode is the virtualization mode of the GPU.
+type VirtualizationMode string
+
+const (
+	// VirtualizationModeNone is the bare-metal GPU.
+	VirtualizationModeNone VirtualizationMode = "none"
+	// VirtualizationModePassthrough is the whole GPU passed through to the guest VM.
+	VirtualizationModePassthrough VirtualizationMode = "passthrough"
+	// VirtualizationModeVGPU is the virtual GPU in the guest VM.
+	VirtualizationModeVGPU VirtualizationMode = "vgpu"
+	// VirtualizationModeHostVGPU is the physical GPU on the host, shared as virtual GPUs.
+	VirtualizationModeHostVGPU VirtualizationMode = "host-vgpu"
+	// VirtualizationModeHostVSGA is the physical GPU on the host, shared as VSGA.
+	VirtualizationModeHostVSGA VirtualizationMode = "host-vsga"
+	// VirtualizationModeSRIOVVF is the SR-IOV virtual function of the GPU,
+	// not reported as a virtual GPU by NVML (e.g., assigned to a container).
+	VirtualizationModeSRIOVVF VirtualizationMode = "sriov-vf"
+)
+
+// HostManaged returns true if the GPU is a slice of a physical GPU
+// managed by the host (e.g., the fabric manager, the row remapping),
+// so the checks that require the physical GPU do not apply.
+// The passed-through GPU is owned by the guest as a whole, thus not host managed.
+func (m VirtualizationMode) HostManaged() bool {
+	return m == VirtualizationModeVGPU || m == VirtualizationModeSRIOVVF
+}
+
+// Virtualization is the virtualization state of the device.
+type Virtualization struct {
+	UUID string             `json:"uuid"`
+	Mode VirtualizationMode `json:"mode"`
+
+	// ActiveVGPUs is the number of the virtual GPUs running on the physical GPU,
+	// only set in the host vGPU mode.
+	ActiveVGPUs int `json:"active_vgpus,omitempty"`
+}
+
+// GetVirtualization returns the virtualization state of the device.
+func GetVirtualization(uuid string, dev device.Device) (Virtualization, error) {
+	v := Virtualization{UUID: uuid}
+
+	mode, err := getVirtualizationMode(dev, pci.DefaultSysfsRoot)
+	if err != nil {
+		return v, err
+	}
+	v.Mode = mode
+
+	if mode == VirtualizationModeHostVGPU {
+		vgpus, ret := dev.GetActiveVgpus()
+		if IsGPULostError(ret) {
+			return v, ErrGPULost
+		}
+		if ret != nvml.SUCCESS && !IsNotSupportError(ret) {
+			return v, fmt.Errorf("failed to get active vgpus: %v", nvml.ErrorString(ret))
+		}
+		v.ActiveVGPUs = len(vgpus)
+	}
+
+	return v, nil
+}
+
+// VGPU is the virtual GPU running on the physical GPU in the host vGPU mode.
+type VGPU struct {
+	// GPUUUID is the UUID of the physical GPU.
+	GPUUUID string `json:"gpu_uuid"`
+	// UUID is the UUID of the virtual GPU.
+	UUID string `json:"uuid"`
+	// VMID is the ID of the guest VM running the virtual GPU (e.g., the domain UUID).
+	VMID string `json:"vm_id,omitempty"`
+	// FramebufferUsedBytes is the framebuffer memory used by the virtual GPU.
+	FramebufferUsedBytes uint64 `json:"framebuffer_used_bytes"`
+}
+
+// GetVGPUs returns the virtual GPUs running on the physical GPU,
+// or none if the GPU is not in the host vGPU mode.
+func GetVGPUs(uuid string, dev device.Device) ([]VGPU, error) {
+	vgpus, ret := dev.GetActiveVgpus()
+	if IsNotSupportError(ret) {
+		return nil, nil
+	}
+	if IsGPULostError(ret) {
+		return nil, ErrGPULost
+	}
+	if ret != nvml.SUCCESS {
+		return nil, fmt.Errorf("failed to get active vgpus: %v", nvml.ErrorString(ret))
+	}
+
+	vs := make([]VGPU, 0, len(vgpus))
+	for _, vgpu := range vgpus {
+		v := VGPU{GPUUUID: uuid}
+
+		v.UUID, ret = vgpu.GetUUID()
+		if ret != nvml.SUCCESS {
+			return nil, fmt.Errorf("failed to get vgpu uuid: %v", nvml.ErrorString(ret))
+		}
+
+		// best effort, the vm id is only for the reference
+		if vmID, _, ret := vgpu.GetVmID(); ret == nvml.SUCCESS {
+			v.VMID = vmID
+		}
+
+		v.FramebufferUsedBytes, ret = vgpu.GetFbUsage()
+		if ret != nvml.SUCCESS && !IsNotSupportError(ret) {
+			return nil, fmt.Errorf("failed to get vgpu %s framebuffer usage: %v", v.UUID, nvml.ErrorString(ret))
+		}
+
+		vs = append(vs, v)
+	}
+	return vs, nil
+}
+
+// getVirtualizationMode returns the virtualization mode of the device,
+// falling back to the SR-IOV virtual function detection from the sysfs
+// if NVML reports the bare-metal GPU.
+func getVirtualizationMode(dev device.Device, sysfsRoot string) (VirtualizationMode, error) {
+	nvmlMode, ret := dev.GetVirtualizationMode()
+	if IsNotSupportError(ret) {
+		nvmlMode = nvml.GPU_VIRTUALIZATION_MODE_NONE
+	} else if IsGPULostError(ret) {
+		return "", ErrGPULost
+	} else if ret != nvml.SUCCESS {
+		return "", fmt.Errorf("failed to get virtualization mode: %v", nvml.ErrorString(ret))
+	}
+
+	mode := convertVirtualizationMode(nvmlMode)
+	if mode != VirtualizationModeNone {
+		return mode, nil
+	}
+
+	busID, err := dev.GetPCIBusID()
+	if err != nil {
+		// best effort, the mode from NVML is still valid
+		log.Logger.Debugw("failed to get pci bus id for sr-iov detection", "error", err)
+		return mode, nil
+	}
+	isVF, err := pci.IsVirtualFunction(sysfsRoot, busID)
+	if err != nil {
+		log.Logger.Debugw("failed to check sr-iov virtual function", "busID", busID, "error", err)
+		return mode, nil
+	}
+	if isVF {
+		return VirtualizationModeSRIOVVF, nil
+	}
+	return mode, nil
+}
+
+func convertVirtualizationMode(mode nvml.GpuVirtualizationMode) VirtualizationMode {
+	switch mode {
+	case nvml.GPU_VIRTUALIZATION_MODE_PASSTHROUGH:
+		return VirtualizationModePassthrough
+	case nvml.GPU_VIRTUALIZATION_MODE_VGPU:
+		return VirtualizationModeVGPU
+	case nvml.GPU_VIRTUALIZATION_MODE_HOST_VGPU:
+		return VirtualizationModeHostVGPU
+	case nvml.GPU_VIRTUALIZATION_MODE_HOST_VSGA:
+		return VirtualizationModeHostVSGA
+	default:
+		return VirtualizationModeNone
+	}
+}
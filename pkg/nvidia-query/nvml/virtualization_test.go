@@ -0,0 +1,106 @@
+package nvml
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/nvidia-query/nvml/testutil"
+)
+
+func TestGetVirtualizationMode(t *testing.T) {
+	root := t.TempDir()
+	pf := filepath.Join(root, "devices", "0000:18:00.0")
+	vf := filepath.Join(root, "devices", "0000:18:00.4")
+	require.NoError(t, os.MkdirAll(pf, 0755))
+	require.NoError(t, os.MkdirAll(vf, 0755))
+	require.NoError(t, os.Symlink(pf, filepath.Join(vf, "physfn")))
+
+	testCases := []struct {
+		name        string
+		mode        nvml.GpuVirtualizationMode
+		ret         nvml.Return
+		busID       string
+		expected    VirtualizationMode
+		expectedErr error
+	}{
+		{name: "bare-metal", mode: nvml.GPU_VIRTUALIZATION_MODE_NONE, ret: nvml.SUCCESS, busID: "00000000:18:00.0", expected: VirtualizationModeNone},
+		{name: "passthrough", mode: nvml.GPU_VIRTUALIZATION_MODE_PASSTHROUGH, ret: nvml.SUCCESS, busID: "00000000:18:00.0", expected: VirtualizationModePassthrough},
+		{name: "vgpu guest", mode: nvml.GPU_VIRTUALIZATION_MODE_VGPU, ret: nvml.SUCCESS, busID: "00000000:18:00.4", expected: VirtualizationModeVGPU},
+		{name: "vgpu host", mode: nvml.GPU_VIRTUALIZATION_MODE_HOST_VGPU, ret: nvml.SUCCESS, busID: "00000000:18:00.0", expected: VirtualizationModeHostVGPU},
+		{name: "sr-iov virtual function", mode: nvml.GPU_VIRTUALIZATION_MODE_NONE, ret: nvml.SUCCESS, busID: "00000000:18:00.4", expected: VirtualizationModeSRIOVVF},
+		{name: "not supported", ret: nvml.ERROR_NOT_SUPPORTED, busID: "00000000:18:00.0", expected: VirtualizationModeNone},
+		{name: "gpu lost", ret: nvml.ERROR_GPU_IS_LOST, busID: "00000000:18:00.0", expectedErr: ErrGPULost},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dev := testutil.NewMockDevice(&mock.Device{
+				GetVirtualizationModeFunc: func() (nvml.GpuVirtualizationMode, nvml.Return) {
+					return tc.mode, tc.ret
+				},
+			}, "test-arch", "test-brand", "test-cuda", tc.busID)
+
+			mode, err := getVirtualizationMode(dev, root)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, mode)
+		})
+	}
+}
+
+func TestVirtualizationModeHostManaged(t *testing.T) {
+	assert.False(t, VirtualizationModeNone.HostManaged())
+	assert.False(t, VirtualizationModePassthrough.HostManaged())
+	assert.False(t, VirtualizationModeHostVGPU.HostManaged())
+	assert.True(t, VirtualizationModeVGPU.HostManaged())
+	assert.True(t, VirtualizationModeSRIOVVF.HostManaged())
+}
+
+func TestGetVGPUs(t *testing.T) {
+	vgpu := &mock.VgpuInstance{
+		GetUUIDFunc: func() (string, nvml.Return) {
+			return "vgpu-uuid-0", nvml.SUCCESS
+		},
+		GetVmIDFunc: func() (string, nvml.VgpuVmIdType, nvml.Return) {
+			return "vm-0", nvml.VGPU_VM_ID_UUID, nvml.SUCCESS
+		},
+		GetFbUsageFunc: func() (uint64, nvml.Return) {
+			return 4 * 1024 * 1024 * 1024, nvml.SUCCESS
+		},
+	}
+
+	dev := testutil.NewMockDevice(&mock.Device{
+		GetActiveVgpusFunc: func() ([]nvml.VgpuInstance, nvml.Return) {
+			return []nvml.VgpuInstance{vgpu}, nvml.SUCCESS
+		},
+	}, "test-arch", "test-brand", "test-cuda", "test-pci")
+	vgpus, err := GetVGPUs("gpu-uuid", dev)
+	require.NoError(t, err)
+	assert.Equal(t, []VGPU{{GPUUUID: "gpu-uuid", UUID: "vgpu-uuid-0", VMID: "vm-0", FramebufferUsedBytes: 4 * 1024 * 1024 * 1024}}, vgpus)
+
+	// not in the host vgpu mode
+	dev = testutil.NewMockDevice(&mock.Device{
+		GetActiveVgpusFunc: func() ([]nvml.VgpuInstance, nvml.Return) {
+			return nil, nvml.ERROR_NOT_SUPPORTED
+		},
+	}, "test-arch", "test-brand", "test-cuda", "test-pci")
+	vgpus, err = GetVGPUs("gpu-uuid", dev)
+	require.NoError(t, err)
+	assert.Empty(t, vgpus)
+
+	dev = testutil.NewMockDevice(&mock.Device{
+		GetActiveVgpusFunc: func() ([]nvml.VgpuInstance, nvml.Return) {
+			return nil, nvml.ERROR_GPU_IS_LOST
+		},
+	}, "test-arch", "test-brand", "test-cuda", "test-pci")
+	_, err = GetVGPUs("gpu-uuid", dev)
+	assert.ErrorIs(t, err, ErrGPULost)
+}
@@ -6,10 +6,8 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"time"
 
-	"github.com/leptonai/gpud/pkg/log"
-	"github.com/leptonai/gpud/pkg/process"
+	"github.com/leptonai/gpud/pkg/toolexec"
 )
 
 const peerMemModule = "nvidia_peermem"
@@ -19,49 +17,27 @@ func CheckLsmodPeermemModule(ctx context.Context) (*LsmodPeermemModuleOutput, er
 		return nil, errors.New("requires sudo/root access to check if ib_core is using nvidia_peermem")
 	}
 
-	proc, err := process.New(
-		process.WithCommand("sudo lsmod"),
-		process.WithRunAsBashScript(),
-		process.WithRestartConfig(
-			process.RestartConfig{
-				OnError:  true,
-				Limit:    10,
-				Interval: 5 * time.Second,
-			},
-		))
+	// e.g.,
+	// sudo lsmod | grep nvidia_peermem
+	res, err := toolexec.Run(ctx, []string{"sudo", "lsmod"})
 	if err != nil {
-		return nil, err
-	}
-	if err := proc.Start(ctx); err != nil {
-		return nil, err
-	}
-	defer func() {
-		if err := proc.Close(ctx); err != nil {
-			log.Logger.Warnw("failed to abort command", "err", err)
+		var out string
+		if res != nil {
+			out = strings.TrimSpace(string(res.Output))
 		}
-	}()
+		return nil, fmt.Errorf("failed to read lsmod output: %w\n\noutput:\n%s", err, out)
+	}
 
-	// e.g.,
-	// sudo lsmod | grep nvidia_peermem
 	lines := make([]string, 0, 10)
-	if err := process.Read(
-		ctx,
-		proc,
-		process.WithReadStdout(),
-		process.WithReadStderr(),
-		process.WithProcessLine(func(line string) {
-			s := strings.TrimSpace(line)
-			if s == "" {
-				return
-			}
-			if !strings.Contains(s, peerMemModule) {
-				return
-			}
-			lines = append(lines, s)
-		}),
-		process.WithWaitForCmd(),
-	); err != nil {
-		return nil, fmt.Errorf("failed to read lsmod output: %w\n\noutput:\n%s", err, strings.Join(lines, "\n"))
+	for _, line := range strings.Split(string(res.Output), "\n") {
+		s := strings.TrimSpace(line)
+		if s == "" {
+			continue
+		}
+		if !strings.Contains(s, peerMemModule) {
+			continue
+		}
+		lines = append(lines, s)
 	}
 
 	o := &LsmodPeermemModuleOutput{
@@ -0,0 +1,298 @@
+// Package openapi converts the Swagger 2.0 document generated by swag
+// into the OpenAPI 3 document, so that the clients in other languages
+// (e.g., Python, TypeScript) can be generated with the standard tooling.
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Version is the OpenAPI version of the converted document.
+const Version = "3.0.3"
+
+const (
+	swaggerRefPrefix = "#/definitions/"
+	openAPIRefPrefix = "#/components/schemas/"
+
+	defaultMediaType = "application/json"
+)
+
+// ErrNotSwagger2 is returned when the input document is not a Swagger 2.0 document.
+var ErrNotSwagger2 = errors.New("not a swagger 2.0 document")
+
+// skippedHeaderParams are the header parameters that are not allowed
+// to be defined as parameters in OpenAPI 3.
+// ref. https://spec.openapis.org/oas/v3.0.3#fixed-fields-10
+var skippedHeaderParams = map[string]struct{}{
+	"accept":        {},
+	"content-type":  {},
+	"authorization": {},
+}
+
+// Convert converts the Swagger 2.0 JSON document into the OpenAPI 3 JSON document.
+// The server URL is derived from the "host" and "basePath" fields, using "https"
+// unless the "schemes" field specifies otherwise.
+func Convert(swagger []byte) ([]byte, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(swagger, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse swagger document: %w", err)
+	}
+	if v, _ := doc["swagger"].(string); v != "2.0" {
+		return nil, ErrNotSwagger2
+	}
+
+	out := map[string]any{
+		"openapi": Version,
+		"info":    doc["info"],
+		"servers": convertServers(doc),
+		"paths":   convertPaths(doc),
+	}
+	if defs, ok := doc["definitions"].(map[string]any); ok && len(defs) > 0 {
+		out["components"] = map[string]any{
+			"schemas": rewriteRefs(defs),
+		}
+	}
+	if tags, ok := doc["tags"]; ok {
+		out["tags"] = tags
+	}
+
+	return json.Marshal(out)
+}
+
+func convertServers(doc map[string]any) []any {
+	host, _ := doc["host"].(string)
+	if host == "" {
+		host = "localhost"
+	}
+	basePath, _ := doc["basePath"].(string)
+	basePath = strings.TrimRight(basePath, "/")
+
+	scheme := "https"
+	if schemes, ok := doc["schemes"].([]any); ok && len(schemes) > 0 {
+		if s, ok := schemes[0].(string); ok && s != "" {
+			scheme = s
+		}
+	}
+	return []any{
+		map[string]any{"url": scheme + "://" + host + basePath},
+	}
+}
+
+func convertPaths(doc map[string]any) map[string]any {
+	paths, _ := doc["paths"].(map[string]any)
+
+	// document-level media types apply when the operation does not override them
+	consumes := toStrings(doc["consumes"])
+	produces := toStrings(doc["produces"])
+
+	out := make(map[string]any, len(paths))
+	for p, item := range paths {
+		ops, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		convertedOps := make(map[string]any, len(ops))
+		for method, v := range ops {
+			op, ok := v.(map[string]any)
+			if !ok {
+				continue
+			}
+			convertedOps[method] = convertOperation(op, consumes, produces)
+		}
+		out[p] = convertedOps
+	}
+	return out
+}
+
+func convertOperation(op map[string]any, consumes []string, produces []string) map[string]any {
+	if c := toStrings(op["consumes"]); len(c) > 0 {
+		consumes = c
+	}
+	if p := toStrings(op["produces"]); len(p) > 0 {
+		produces = p
+	}
+	if len(consumes) == 0 {
+		consumes = []string{defaultMediaType}
+	}
+	if len(produces) == 0 {
+		produces = []string{defaultMediaType}
+	}
+
+	out := make(map[string]any)
+	for _, k := range []string{"tags", "summary", "description", "operationId", "deprecated"} {
+		if v, ok := op[k]; ok {
+			out[k] = v
+		}
+	}
+
+	var params []any
+	if raw, ok := op["parameters"].([]any); ok {
+		for _, v := range raw {
+			param, ok := v.(map[string]any)
+			if !ok {
+				continue
+			}
+
+			in, _ := param["in"].(string)
+			name, _ := param["name"].(string)
+			switch in {
+			case "body":
+				out["requestBody"] = convertRequestBody(param, consumes)
+				continue
+			case "header":
+				if _, skip := skippedHeaderParams[strings.ToLower(name)]; skip {
+					continue
+				}
+			}
+			params = append(params, convertParameter(param))
+		}
+	}
+	if len(params) > 0 {
+		out["parameters"] = params
+	}
+
+	responses := make(map[string]any)
+	if raw, ok := op["responses"].(map[string]any); ok {
+		for code, v := range raw {
+			resp, ok := v.(map[string]any)
+			if !ok {
+				continue
+			}
+			responses[code] = convertResponse(resp, produces)
+		}
+	}
+	out["responses"] = responses
+
+	return out
+}
+
+// convertParameter moves the type fields of the non-body parameter into the "schema" field.
+func convertParameter(param map[string]any) map[string]any {
+	out := make(map[string]any)
+	schema := make(map[string]any)
+	for k, v := range param {
+		switch k {
+		case "name", "in", "description", "required":
+			out[k] = v
+		case "type", "format", "enum", "items", "default", "minimum", "maximum", "pattern":
+			schema[k] = rewriteRefs(v)
+		}
+	}
+	if len(schema) == 0 {
+		schema["type"] = "string"
+	}
+	out["schema"] = schema
+	if out["in"] == "path" {
+		out["required"] = true
+	}
+	return out
+}
+
+func convertRequestBody(param map[string]any, consumes []string) map[string]any {
+	schema := rewriteRefs(param["schema"])
+	content := make(map[string]any, len(consumes))
+	for _, mt := range consumes {
+		content[mt] = map[string]any{"schema": schema}
+	}
+
+	out := map[string]any{"content": content}
+	if d, ok := param["description"]; ok {
+		out["description"] = d
+	}
+	if r, ok := param["required"]; ok {
+		out["required"] = r
+	}
+	return out
+}
+
+func convertResponse(resp map[string]any, produces []string) map[string]any {
+	out := map[string]any{
+		"description": resp["description"],
+	}
+	if out["description"] == nil {
+		out["description"] = ""
+	}
+
+	if schema, ok := resp["schema"]; ok {
+		schema = rewriteRefs(schema)
+		content := make(map[string]any, len(produces))
+		for _, mt := range produces {
+			content[mt] = map[string]any{"schema": schema}
+		}
+		out["content"] = content
+	}
+
+	if headers, ok := resp["headers"].(map[string]any); ok && len(headers) > 0 {
+		converted := make(map[string]any, len(headers))
+		for name, v := range headers {
+			h, ok := v.(map[string]any)
+			if !ok {
+				continue
+			}
+			ch := make(map[string]any)
+			schema := make(map[string]any)
+			for k, hv := range h {
+				if k == "description" {
+					ch[k] = hv
+					continue
+				}
+				schema[k] = hv
+			}
+			ch["schema"] = schema
+			converted[name] = ch
+		}
+		out["headers"] = converted
+	}
+
+	return out
+}
+
+// rewriteRefs recursively rewrites the "$ref" values from the Swagger 2.0 definitions
+// to the OpenAPI 3 component schemas, and "x-nullable" to "nullable".
+func rewriteRefs(v any) any {
+	switch vv := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(vv))
+		for k, val := range vv {
+			switch k {
+			case "$ref":
+				if s, ok := val.(string); ok && strings.HasPrefix(s, swaggerRefPrefix) {
+					val = openAPIRefPrefix + strings.TrimPrefix(s, swaggerRefPrefix)
+				}
+				out[k] = val
+			case "x-nullable":
+				out["nullable"] = val
+			default:
+				out[k] = rewriteRefs(val)
+			}
+		}
+		return out
+
+	case []any:
+		out := make([]any, len(vv))
+		for i, val := range vv {
+			out[i] = rewriteRefs(val)
+		}
+		return out
+
+	default:
+		return v
+	}
+}
+
+func toStrings(v any) []string {
+	raw, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0, len(raw))
+	for _, s := range raw {
+		if str, ok := s.(string); ok {
+			out = append(out, str)
+		}
+	}
+	return out
+}
@@ -0,0 +1,102 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSwagger = `{
+  "swagger": "2.0",
+  "info": {"title": "GPUd API", "version": "1.0"},
+  "host": "localhost:15132",
+  "basePath": "/",
+  "paths": {
+    "/v1/states": {
+      "get": {
+        "produces": ["application/json"],
+        "operationId": "getHealthStates",
+        "parameters": [
+          {"type": "string", "name": "Accept", "in": "header", "enum": ["application/json", "application/yaml"]},
+          {"type": "string", "name": "components", "in": "query", "description": "components"},
+          {"type": "string", "name": "json-indent", "in": "header"}
+        ],
+        "responses": {
+          "200": {
+            "description": "ok",
+            "headers": {"Content-Type": {"type": "string", "description": "content type"}},
+            "schema": {"type": "array", "items": {"$ref": "#/definitions/v1.ComponentHealthStates"}}
+          }
+        }
+      }
+    },
+    "/inject-fault": {
+      "post": {
+        "consumes": ["application/json"],
+        "parameters": [
+          {"name": "request", "in": "body", "required": true, "schema": {"$ref": "#/definitions/Request"}}
+        ],
+        "responses": {"200": {"description": "ok"}}
+      }
+    }
+  },
+  "definitions": {
+    "v1.ComponentHealthStates": {
+      "type": "object",
+      "properties": {
+        "states": {"type": "array", "items": {"$ref": "#/definitions/v1.HealthState"}},
+        "error": {"type": "string", "x-nullable": true}
+      }
+    },
+    "v1.HealthState": {"type": "object"},
+    "Request": {"type": "object"}
+  }
+}`
+
+func TestConvert(t *testing.T) {
+	b, err := Convert([]byte(testSwagger))
+	require.NoError(t, err)
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(b, &doc))
+
+	assert.Equal(t, Version, doc["openapi"])
+	assert.Equal(t, "https://localhost:15132", doc["servers"].([]any)[0].(map[string]any)["url"])
+
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	chs := schemas["v1.ComponentHealthStates"].(map[string]any)["properties"].(map[string]any)
+	assert.Equal(t, "#/components/schemas/v1.HealthState", chs["states"].(map[string]any)["items"].(map[string]any)["$ref"])
+	assert.Equal(t, true, chs["error"].(map[string]any)["nullable"])
+
+	paths := doc["paths"].(map[string]any)
+	getStates := paths["/v1/states"].(map[string]any)["get"].(map[string]any)
+	params := getStates["parameters"].([]any)
+	require.Len(t, params, 2, "accept header must be dropped")
+	assert.Equal(t, "components", params[0].(map[string]any)["name"])
+	assert.Equal(t, map[string]any{"type": "string"}, params[0].(map[string]any)["schema"])
+
+	resp200 := getStates["responses"].(map[string]any)["200"].(map[string]any)
+	schema := resp200["content"].(map[string]any)["application/json"].(map[string]any)["schema"].(map[string]any)
+	assert.Equal(t, "#/components/schemas/v1.ComponentHealthStates", schema["items"].(map[string]any)["$ref"])
+	header := resp200["headers"].(map[string]any)["Content-Type"].(map[string]any)
+	assert.Equal(t, "content type", header["description"])
+	assert.Equal(t, map[string]any{"type": "string"}, header["schema"])
+
+	injectFault := paths["/inject-fault"].(map[string]any)["post"].(map[string]any)
+	assert.NotContains(t, injectFault, "parameters")
+	body := injectFault["requestBody"].(map[string]any)
+	assert.Equal(t, true, body["required"])
+	bodySchema := body["content"].(map[string]any)["application/json"].(map[string]any)["schema"].(map[string]any)
+	assert.Equal(t, "#/components/schemas/Request", bodySchema["$ref"])
+	assert.NotContains(t, injectFault["responses"].(map[string]any)["200"], "content")
+}
+
+func TestConvertErrors(t *testing.T) {
+	_, err := Convert([]byte("{"))
+	require.Error(t, err)
+
+	_, err = Convert([]byte(`{"openapi": "3.0.3"}`))
+	require.ErrorIs(t, err, ErrNotSwagger2)
+}
@@ -0,0 +1,196 @@
+// Package output renders the command outputs in the table, JSON, or YAML format,
+// so that every command supports the machine-readable output consistently.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli"
+	"sigs.k8s.io/yaml"
+)
+
+// Format is the output format.
+type Format string
+
+const (
+	// FormatTable renders the human-readable table.
+	FormatTable Format = "table"
+	// FormatWide renders the table with the extra columns.
+	FormatWide Format = "wide"
+	// FormatJSON renders the indented JSON.
+	FormatJSON Format = "json"
+	// FormatYAML renders the YAML.
+	FormatYAML Format = "yaml"
+)
+
+// DefaultFormat is the default output format.
+const DefaultFormat = FormatTable
+
+var supportedFormats = []Format{FormatTable, FormatWide, FormatJSON, FormatYAML}
+
+// ParseFormat parses the output format, and returns the default format if empty.
+func ParseFormat(s string) (Format, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return DefaultFormat, nil
+	}
+	for _, f := range supportedFormats {
+		if s == string(f) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported output format %q (supported: table, wide, json, yaml)", s)
+}
+
+// IsMachineReadable returns true if the format is for the scripts (JSON or YAML),
+// in which case the commands must only write the rendered output to the stdout.
+func (f Format) IsMachineReadable() bool {
+	return f == FormatJSON || f == FormatYAML
+}
+
+// FlagName is the name of the output format flag.
+const FlagName = "output"
+
+// Flag is the output format flag, registered globally and for each command
+// that supports the machine-readable output.
+var Flag = cli.StringFlag{
+	Name:  FlagName + ",o",
+	Usage: "set the output format [table, wide, json, yaml]",
+}
+
+// JSONFlagName is the name of the shorthand flag for the JSON output format.
+const JSONFlagName = "json"
+
+// JSONFlag is the shorthand flag for "--output json",
+// registered for the commands commonly consumed by the scripts.
+var JSONFlag = cli.BoolFlag{
+	Name:  JSONFlagName,
+	Usage: "set the output format to json (shorthand for --output json)",
+}
+
+// FormatFromContext parses the output format from the command flag,
+// falling back to the global flag (e.g., "gpud --output json status").
+// The JSON shorthand flag takes precedence, unless it conflicts with the command flag.
+func FormatFromContext(cliContext *cli.Context) (Format, error) {
+	return FormatFromContextOr(cliContext, DefaultFormat)
+}
+
+// FormatFromContextOr is FormatFromContext with the format used if no flag is set,
+// for the commands printing the machine-readable output by default
+// (e.g., "gpud nccl-test" printing the JSON result).
+func FormatFromContextOr(cliContext *cli.Context, defaultFormat Format) (Format, error) {
+	s := cliContext.String(FlagName)
+	if cliContext.Bool(JSONFlagName) {
+		if f, err := ParseFormat(s); s != "" && (err != nil || f != FormatJSON) {
+			return "", fmt.Errorf("--%s conflicts with --%s %s", JSONFlagName, FlagName, s)
+		}
+		return FormatJSON, nil
+	}
+	if s == "" {
+		s = cliContext.GlobalString(FlagName)
+	}
+	if strings.TrimSpace(s) == "" {
+		return defaultFormat, nil
+	}
+	return ParseFormat(s)
+}
+
+// RequireSupported returns an error if the global output format flag is set
+// (e.g., "gpud --output json down") for the command not registering the flag,
+// rather than the command silently ignoring the flag.
+// Set as the app "Before" function.
+func RequireSupported(cliContext *cli.Context) error {
+	if cliContext.String(FlagName) == "" || cliContext.NArg() == 0 {
+		return nil
+	}
+	cmd := cliContext.App.Command(cliContext.Args().First())
+	if cmd == nil {
+		return nil
+	}
+	for _, f := range cmd.Flags {
+		if f.GetName() == Flag.GetName() {
+			return nil
+		}
+	}
+	return fmt.Errorf("command %q does not support --%s", cmd.Name, FlagName)
+}
+
+// TableRenderer is implemented by the types that render themselves as a table.
+type TableRenderer interface {
+	RenderTable(wr io.Writer)
+}
+
+// Table is the tabular view of the output.
+type Table struct {
+	Header []string
+	Rows   [][]string
+	// Wide is the number of the trailing columns
+	// only rendered in the wide format.
+	Wide int
+}
+
+// AddRow appends the row to the table.
+func (t *Table) AddRow(row ...string) {
+	t.Rows = append(t.Rows, row)
+}
+
+// render renders the table, with the trailing wide columns if wide is true.
+func (t *Table) render(wr io.Writer, wide bool) {
+	n := len(t.Header)
+	if !wide {
+		n -= t.Wide
+	}
+
+	table := tablewriter.NewWriter(wr)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.SetAutoWrapText(false)
+	table.SetHeader(t.Header[:n])
+	for _, row := range t.Rows {
+		if len(row) > n {
+			row = row[:n]
+		}
+		table.Append(row)
+	}
+	table.Render()
+}
+
+// Render renders the data in the format.
+// The table formats render the table if not nil, otherwise the data
+// if it implements TableRenderer, and fall back to YAML if neither.
+func Render(wr io.Writer, format Format, data any, table *Table) error {
+	switch format {
+	case FormatJSON:
+		b, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal json: %w", err)
+		}
+		_, err = fmt.Fprintln(wr, string(b))
+		return err
+
+	case FormatYAML:
+		b, err := yaml.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("failed to marshal yaml: %w", err)
+		}
+		_, err = wr.Write(b)
+		return err
+
+	case FormatTable, FormatWide, "":
+		if table != nil {
+			table.render(wr, format == FormatWide)
+			return nil
+		}
+		if tr, ok := data.(TableRenderer); ok {
+			tr.RenderTable(wr)
+			return nil
+		}
+		return Render(wr, FormatYAML, data, nil)
+
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
@@ -0,0 +1,166 @@
+package output
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("")
+	require.NoError(t, err)
+	assert.Equal(t, FormatTable, f)
+
+	f, err = ParseFormat(" JSON ")
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSON, f)
+	assert.True(t, f.IsMachineReadable())
+
+	f, err = ParseFormat("wide")
+	require.NoError(t, err)
+	assert.False(t, f.IsMachineReadable())
+
+	_, err = ParseFormat("xml")
+	require.Error(t, err)
+}
+
+func TestFormatFromContext(t *testing.T) {
+	globalSet := flag.NewFlagSet("global", flag.ContinueOnError)
+	globalSet.String(FlagName, "yaml", "")
+	globalCtx := cli.NewContext(nil, globalSet, nil)
+
+	set := flag.NewFlagSet("command", flag.ContinueOnError)
+	set.String(FlagName, "", "")
+	ctx := cli.NewContext(nil, set, globalCtx)
+
+	// falls back to the global flag
+	f, err := FormatFromContext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, FormatYAML, f)
+
+	require.NoError(t, set.Set(FlagName, "json"))
+	f, err = FormatFromContext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSON, f)
+}
+
+func TestFormatFromContextJSONFlag(t *testing.T) {
+	globalSet := flag.NewFlagSet("global", flag.ContinueOnError)
+	globalSet.String(FlagName, "yaml", "")
+	globalCtx := cli.NewContext(nil, globalSet, nil)
+
+	set := flag.NewFlagSet("command", flag.ContinueOnError)
+	set.String(FlagName, "", "")
+	set.Bool(JSONFlagName, false, "")
+	ctx := cli.NewContext(nil, set, globalCtx)
+
+	// takes precedence over the global flag
+	require.NoError(t, set.Set(JSONFlagName, "true"))
+	f, err := FormatFromContext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSON, f)
+
+	require.NoError(t, set.Set(FlagName, "json"))
+	f, err = FormatFromContext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSON, f)
+
+	require.NoError(t, set.Set(FlagName, "yaml"))
+	_, err = FormatFromContext(ctx)
+	require.Error(t, err)
+}
+
+func TestFormatFromContextOr(t *testing.T) {
+	globalSet := flag.NewFlagSet("global", flag.ContinueOnError)
+	globalSet.String(FlagName, "", "")
+	globalCtx := cli.NewContext(nil, globalSet, nil)
+
+	set := flag.NewFlagSet("command", flag.ContinueOnError)
+	set.String(FlagName, "", "")
+	ctx := cli.NewContext(nil, set, globalCtx)
+
+	f, err := FormatFromContextOr(ctx, FormatJSON)
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSON, f)
+
+	require.NoError(t, globalSet.Set(FlagName, "yaml"))
+	f, err = FormatFromContextOr(ctx, FormatJSON)
+	require.NoError(t, err)
+	assert.Equal(t, FormatYAML, f)
+
+	require.NoError(t, set.Set(FlagName, "table"))
+	f, err = FormatFromContextOr(ctx, FormatJSON)
+	require.NoError(t, err)
+	assert.Equal(t, FormatTable, f)
+}
+
+func TestRequireSupported(t *testing.T) {
+	app := cli.NewApp()
+	app.Flags = []cli.Flag{Flag}
+	app.Before = RequireSupported
+	ran := ""
+	app.Commands = []cli.Command{
+		{Name: "status", Aliases: []string{"st"}, Flags: []cli.Flag{Flag}, Action: func(*cli.Context) error { ran = "status"; return nil }},
+		{Name: "down", Action: func(*cli.Context) error { ran = "down"; return nil }},
+	}
+
+	require.NoError(t, app.Run([]string{"gpud", "--output", "json", "st"}))
+	assert.Equal(t, "status", ran)
+
+	ran = ""
+	err := app.Run([]string{"gpud", "--output", "json", "down"})
+	require.ErrorContains(t, err, `command "down" does not support --output`)
+	assert.Empty(t, ran)
+
+	require.NoError(t, app.Run([]string{"gpud", "down"}))
+	assert.Equal(t, "down", ran)
+}
+
+type testData struct {
+	Name string `json:"name"`
+}
+
+func (d testData) RenderTable(wr io.Writer) {
+	_, _ = io.WriteString(wr, "rendered "+d.Name)
+}
+
+func TestRender(t *testing.T) {
+	data := []testData{{Name: "a"}}
+	table := &Table{Header: []string{"Name", "Extra"}, Wide: 1}
+	table.AddRow("a", "x")
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, Render(buf, FormatJSON, data, table))
+	assert.JSONEq(t, `[{"name":"a"}]`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, Render(buf, FormatYAML, data, table))
+	assert.Equal(t, "- name: a\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, Render(buf, FormatTable, data, table))
+	assert.Contains(t, buf.String(), "NAME")
+	assert.NotContains(t, buf.String(), "EXTRA")
+
+	buf.Reset()
+	require.NoError(t, Render(buf, FormatWide, data, table))
+	assert.Contains(t, buf.String(), "EXTRA")
+	assert.Contains(t, buf.String(), "x")
+
+	// renders the data itself without the table
+	buf.Reset()
+	require.NoError(t, Render(buf, FormatTable, testData{Name: "b"}, nil))
+	assert.Equal(t, "rendered b", buf.String())
+
+	// falls back to yaml
+	buf.Reset()
+	require.NoError(t, Render(buf, FormatTable, data, nil))
+	assert.Equal(t, "- name: a\n", buf.String())
+
+	require.Error(t, Render(buf, Format("xml"), data, nil))
+}
@@ -0,0 +1,265 @@
+// Package parquet implements a minimal Apache Parquet file writer for the
+// flat tables with the required columns, used to archive the data in the
+// columnar format that the data warehouses and the analytics tools read.
+// Each file has a single row group with a single GZIP-compressed data page
+// per column, using the PLAIN encoding.
+// ref. https://github.com/apache/parquet-format
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+const magic = "PAR1"
+
+// CreatedBy is written in the file metadata.
+const CreatedBy = "gpud"
+
+// Parquet physical types.
+const (
+	typeInt64     int32 = 2
+	typeDouble    int32 = 5
+	typeByteArray int32 = 6
+)
+
+// Parquet converted (logical) types.
+const (
+	convertedUTF8            int32 = 0
+	convertedTimestampMillis int32 = 9
+)
+
+const (
+	repetitionRequired int32 = 0
+
+	encodingPlain int32 = 0
+	encodingRLE   int32 = 3
+
+	codecGzip int32 = 2
+
+	pageTypeData int32 = 0
+)
+
+var (
+	ErrNoColumn             = errors.New("no column")
+	ErrColumnLengthMismatch = errors.New("column lengths do not match")
+)
+
+// Column is a named column with its values.
+type Column struct {
+	name      string
+	typ       int32
+	converted int32 // -1 if none
+	numValues int
+	plain     []byte
+}
+
+// Name returns the name of the column.
+func (c Column) Name() string {
+	return c.name
+}
+
+// Int64Column creates an INT64 column.
+func Int64Column(name string, vs []int64) Column {
+	b := make([]byte, 0, 8*len(vs))
+	for _, v := range vs {
+		b = binary.LittleEndian.AppendUint64(b, uint64(v))
+	}
+	return Column{name: name, typ: typeInt64, converted: -1, numValues: len(vs), plain: b}
+}
+
+// TimestampMillisColumn creates an INT64 column annotated as the timestamp in unix milliseconds.
+func TimestampMillisColumn(name string, vs []int64) Column {
+	c := Int64Column(name, vs)
+	c.converted = convertedTimestampMillis
+	return c
+}
+
+// DoubleColumn creates a DOUBLE column.
+func DoubleColumn(name string, vs []float64) Column {
+	b := make([]byte, 0, 8*len(vs))
+	for _, v := range vs {
+		b = binary.LittleEndian.AppendUint64(b, math.Float64bits(v))
+	}
+	return Column{name: name, typ: typeDouble, converted: -1, numValues: len(vs), plain: b}
+}
+
+// StringColumn creates a BYTE_ARRAY column annotated as the UTF-8 string.
+func StringColumn(name string, vs []string) Column {
+	size := 0
+	for _, v := range vs {
+		size += 4 + len(v)
+	}
+	b := make([]byte, 0, size)
+	for _, v := range vs {
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(v)))
+		b = append(b, v...)
+	}
+	return Column{name: name, typ: typeByteArray, converted: convertedUTF8, numValues: len(vs), plain: b}
+}
+
+type columnChunk struct {
+	col              Column
+	offset           int64
+	uncompressedSize int64
+	compressedSize   int64
+}
+
+// Write writes the columns as a parquet file.
+// All the columns must have the same number of values.
+func Write(w io.Writer, cols ...Column) error {
+	if len(cols) == 0 {
+		return ErrNoColumn
+	}
+	numRows := cols[0].numValues
+	for _, c := range cols[1:] {
+		if c.numValues != numRows {
+			return fmt.Errorf("%w: %q has %d values, expected %d", ErrColumnLengthMismatch, c.name, c.numValues, numRows)
+		}
+	}
+
+	cw := &countingWriter{w: w}
+	if _, err := io.WriteString(cw, magic); err != nil {
+		return err
+	}
+
+	chunks := make([]columnChunk, 0, len(cols))
+	for _, c := range cols {
+		chunk, err := writeColumnChunk(cw, c)
+		if err != nil {
+			return fmt.Errorf("failed to write column %q: %w", c.name, err)
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	footer := encodeFileMetaData(chunks, int64(numRows))
+	if _, err := cw.Write(footer); err != nil {
+		return err
+	}
+	if _, err := cw.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer)))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(cw, magic)
+	return err
+}
+
+func writeColumnChunk(cw *countingWriter, c Column) (columnChunk, error) {
+	compressed := new(bytes.Buffer)
+	gw := gzip.NewWriter(compressed)
+	if _, err := gw.Write(c.plain); err != nil {
+		return columnChunk{}, err
+	}
+	if err := gw.Close(); err != nil {
+		return columnChunk{}, err
+	}
+
+	header := encodePageHeader(c.numValues, len(c.plain), compressed.Len())
+
+	chunk := columnChunk{
+		col:              c,
+		offset:           cw.n,
+		uncompressedSize: int64(len(header) + len(c.plain)),
+		compressedSize:   int64(len(header) + compressed.Len()),
+	}
+	if _, err := cw.Write(header); err != nil {
+		return columnChunk{}, err
+	}
+	if _, err := cw.Write(compressed.Bytes()); err != nil {
+		return columnChunk{}, err
+	}
+	return chunk, nil
+}
+
+func encodePageHeader(numValues int, uncompressedSize int, compressedSize int) []byte {
+	tw := &thriftWriter{}
+	tw.structBegin()
+	tw.i32(1, pageTypeData)
+	tw.i32(2, int32(uncompressedSize))
+	tw.i32(3, int32(compressedSize))
+
+	tw.structField(5) // data_page_header
+	tw.i32(1, int32(numValues))
+	tw.i32(2, encodingPlain)
+	tw.i32(3, encodingRLE)
+	tw.i32(4, encodingRLE)
+	tw.structEnd()
+
+	tw.structEnd()
+	return tw.buf
+}
+
+func encodeFileMetaData(chunks []columnChunk, numRows int64) []byte {
+	tw := &thriftWriter{}
+	tw.structBegin()
+	tw.i32(1, 1) // version
+
+	// schema is the flattened tree with the root element first
+	tw.listField(2, thriftStruct, len(chunks)+1)
+	tw.structBegin()
+	tw.string(4, "schema")
+	tw.i32(5, int32(len(chunks)))
+	tw.structEnd()
+	for _, ch := range chunks {
+		tw.structBegin()
+		tw.i32(1, ch.col.typ)
+		tw.i32(3, repetitionRequired)
+		tw.string(4, ch.col.name)
+		if ch.col.converted >= 0 {
+			tw.i32(6, ch.col.converted)
+		}
+		tw.structEnd()
+	}
+
+	tw.i64(3, numRows)
+
+	var totalSize int64
+	for _, ch := range chunks {
+		totalSize += ch.uncompressedSize
+	}
+	tw.listField(4, thriftStruct, 1) // row_groups
+	tw.structBegin()
+	tw.listField(1, thriftStruct, len(chunks)) // columns
+	for _, ch := range chunks {
+		tw.structBegin()
+		tw.i64(2, ch.offset) // file_offset
+
+		tw.structField(3) // meta_data
+		tw.i32(1, ch.col.typ)
+		tw.listField(2, thriftI32, 2) // encodings
+		tw.listI32(encodingPlain)
+		tw.listI32(encodingRLE)
+		tw.listField(3, thriftBinary, 1) // path_in_schema
+		tw.listString(ch.col.name)
+		tw.i32(4, codecGzip)
+		tw.i64(5, int64(ch.col.numValues))
+		tw.i64(6, ch.uncompressedSize)
+		tw.i64(7, ch.compressedSize)
+		tw.i64(9, ch.offset) // data_page_offset
+		tw.structEnd()
+
+		tw.structEnd()
+	}
+	tw.i64(2, totalSize)
+	tw.i64(3, numRows)
+	tw.structEnd()
+
+	tw.string(6, CreatedBy)
+	tw.structEnd()
+	return tw.buf
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
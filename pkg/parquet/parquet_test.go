@@ -0,0 +1,246 @@
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	parquetgo "github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// thriftStructValue is the decoded thrift struct, keyed by the field id.
+type thriftStructValue map[int16]any
+
+// decodeThriftStruct decodes a thrift compact protocol struct,
+// returning the number of bytes read.
+func decodeThriftStruct(t *testing.T, b []byte) (thriftStructValue, int) {
+	out := make(thriftStructValue)
+	pos := 0
+	var last int16
+	for {
+		h := b[pos]
+		pos++
+		if h == 0 {
+			return out, pos
+		}
+		typ := h & 0x0f
+		var id int16
+		if delta := h >> 4; delta != 0 {
+			id = last + int16(delta)
+		} else {
+			v, n := binary.Uvarint(b[pos:])
+			pos += n
+			id = int16(unzigzag(v))
+		}
+		last = id
+
+		v, n := decodeThriftValue(t, b[pos:], typ)
+		pos += n
+		out[id] = v
+	}
+}
+
+func decodeThriftValue(t *testing.T, b []byte, typ byte) (any, int) {
+	switch typ {
+	case thriftI32, thriftI64:
+		v, n := binary.Uvarint(b)
+		return unzigzag(v), n
+	case thriftBinary:
+		l, n := binary.Uvarint(b)
+		return string(b[n : n+int(l)]), n + int(l)
+	case thriftStruct:
+		return decodeThriftStruct(t, b)
+	case thriftList:
+		size := int(b[0] >> 4)
+		elemType := b[0] & 0x0f
+		pos := 1
+		if size == 15 {
+			v, n := binary.Uvarint(b[pos:])
+			pos += n
+			size = int(v)
+		}
+		elems := make([]any, 0, size)
+		for i := 0; i < size; i++ {
+			v, n := decodeThriftValue(t, b[pos:], elemType)
+			pos += n
+			elems = append(elems, v)
+		}
+		return elems, pos
+	}
+	t.Fatalf("unexpected thrift type %d", typ)
+	return nil, 0
+}
+
+func unzigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func TestWrite(t *testing.T) {
+	ts := []int64{1700000000000, 1700000060000, 1700000120000}
+	names := []string{"a", "", "temperature"}
+	values := []float64{1.5, -2, math.MaxFloat64}
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, Write(buf,
+		TimestampMillisColumn("unix_milliseconds", ts),
+		StringColumn("name", names),
+		DoubleColumn("value", values),
+		Int64Column("count", []int64{1, 2, 3}),
+	))
+	b := buf.Bytes()
+
+	require.Equal(t, magic, string(b[:4]))
+	require.Equal(t, magic, string(b[len(b)-4:]))
+	footerLen := int(binary.LittleEndian.Uint32(b[len(b)-8:]))
+	footer := b[len(b)-8-footerLen : len(b)-8]
+
+	meta, n := decodeThriftStruct(t, footer)
+	require.Equal(t, footerLen, n)
+	assert.Equal(t, int64(1), meta[1])
+	assert.Equal(t, int64(3), meta[3])
+	assert.Equal(t, CreatedBy, meta[6])
+
+	schema := meta[2].([]any)
+	require.Len(t, schema, 5)
+	assert.Equal(t, int64(4), schema[0].(thriftStructValue)[5])
+	assert.Equal(t, "unix_milliseconds", schema[1].(thriftStructValue)[4])
+	assert.Equal(t, int64(convertedTimestampMillis), schema[1].(thriftStructValue)[6])
+	assert.Equal(t, int64(typeByteArray), schema[2].(thriftStructValue)[1])
+
+	rowGroups := meta[4].([]any)
+	require.Len(t, rowGroups, 1)
+	columns := rowGroups[0].(thriftStructValue)[1].([]any)
+	require.Len(t, columns, 4)
+
+	readPlain := func(i int) []byte {
+		cm := columns[i].(thriftStructValue)[3].(thriftStructValue)
+		assert.Equal(t, int64(codecGzip), cm[4])
+		assert.Equal(t, int64(3), cm[5])
+
+		offset := cm[9].(int64)
+		header, n := decodeThriftStruct(t, b[offset:])
+		assert.Equal(t, cm[7].(int64), int64(n)+header[3].(int64))
+		assert.Equal(t, int64(3), header[5].(thriftStructValue)[1])
+
+		start := offset + int64(n)
+		gr, err := gzip.NewReader(bytes.NewReader(b[start : start+header[3].(int64)]))
+		require.NoError(t, err)
+		plain, err := io.ReadAll(gr)
+		require.NoError(t, err)
+		assert.Equal(t, header[2].(int64), int64(len(plain)))
+		return plain
+	}
+
+	plain := readPlain(0)
+	for i, v := range ts {
+		assert.Equal(t, v, int64(binary.LittleEndian.Uint64(plain[i*8:])))
+	}
+
+	plain = readPlain(1)
+	pos := 0
+	for _, v := range names {
+		l := int(binary.LittleEndian.Uint32(plain[pos:]))
+		assert.Equal(t, v, string(plain[pos+4:pos+4+l]))
+		pos += 4 + l
+	}
+
+	plain = readPlain(2)
+	for i, v := range values {
+		assert.Equal(t, v, math.Float64frombits(binary.LittleEndian.Uint64(plain[i*8:])))
+	}
+}
+
+func TestWriteGolden(t *testing.T) {
+	golden, err := os.ReadFile(filepath.Join("testdata", "metrics.parquet"))
+	require.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, Write(buf, goldenColumns()...))
+	assert.Equal(t, golden, buf.Bytes())
+}
+
+// TestWriteReadBack reads the written file back with an independent parquet implementation,
+// to verify the file (not only the bytes of this writer) is valid parquet.
+func TestWriteReadBack(t *testing.T) {
+	buf := new(bytes.Buffer)
+	require.NoError(t, Write(buf, goldenColumns()...))
+
+	f, err := parquetgo.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), f.NumRows())
+	assert.Equal(t, CreatedBy, f.Metadata().CreatedBy)
+
+	fields := f.Schema().Fields()
+	require.Len(t, fields, 6)
+	assert.Equal(t, "unix_milliseconds", fields[0].Name())
+	assert.NotNil(t, fields[0].Type().LogicalType().Timestamp)
+	assert.NotNil(t, fields[1].Type().LogicalType().UTF8)
+	assert.Equal(t, parquetgo.DoubleType.Kind(), fields[4].Type().Kind())
+	for _, field := range fields {
+		assert.True(t, field.Required(), field.Name())
+	}
+
+	type row struct {
+		UnixMilliseconds int64   `parquet:"unix_milliseconds"`
+		Component        string  `parquet:"component"`
+		Name             string  `parquet:"name"`
+		Labels           string  `parquet:"labels"`
+		Value            float64 `parquet:"value"`
+		Count            int64   `parquet:"count"`
+	}
+	rows, err := parquetgo.Read[row](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	assert.Equal(t, []row{
+		{1700000000000, "accelerator-nvidia-temperature", "temperature_current_celsius", `{"gpu_id":"GPU-0"}`, 45.5, 1},
+		{1700000060000, "accelerator-nvidia-temperature", "temperature_current_celsius", `{"gpu_id":"GPU-1","note":"héllo ✓"}`, -1.25, -2},
+		{1700000120000, "disk", "used_bytes", "", 1.099511627776e+12, 1 << 40},
+	}, rows)
+
+	// the same for the golden file
+	golden, err := os.ReadFile(filepath.Join("testdata", "metrics.parquet"))
+	require.NoError(t, err)
+	goldenRows, err := parquetgo.Read[row](bytes.NewReader(golden), int64(len(golden)))
+	require.NoError(t, err)
+	assert.Equal(t, rows, goldenRows)
+}
+
+func TestWriteErrors(t *testing.T) {
+	require.ErrorIs(t, Write(io.Discard), ErrNoColumn)
+	require.ErrorIs(t, Write(io.Discard, Int64Column("a", []int64{1}), StringColumn("b", nil)), ErrColumnLengthMismatch)
+}
+
+func TestThriftLongList(t *testing.T) {
+	tw := &thriftWriter{}
+	tw.structBegin()
+	tw.listField(20, thriftI32, 20)
+	for i := 0; i < 20; i++ {
+		tw.listI32(int32(-i))
+	}
+	tw.structEnd()
+
+	v, n := decodeThriftStruct(t, tw.buf)
+	assert.Equal(t, len(tw.buf), n)
+	l := v[20].([]any)
+	require.Len(t, l, 20)
+	assert.Equal(t, int64(-19), l[19])
+}
+
+// goldenColumns are the columns of "testdata/metrics.parquet",
+// in the same schema as the archived metrics.
+func goldenColumns() []Column {
+	return []Column{
+		TimestampMillisColumn("unix_milliseconds", []int64{1700000000000, 1700000060000, 1700000120000}),
+		StringColumn("component", []string{"accelerator-nvidia-temperature", "accelerator-nvidia-temperature", "disk"}),
+		StringColumn("name", []string{"temperature_current_celsius", "temperature_current_celsius", "used_bytes"}),
+		StringColumn("labels", []string{`{"gpu_id":"GPU-0"}`, `{"gpu_id":"GPU-1","note":"héllo ✓"}`, ""}),
+		DoubleColumn("value", []float64{45.5, -1.25, 1.099511627776e+12}),
+		Int64Column("count", []int64{1, -2, 1 << 40}),
+	}
+}
@@ -0,0 +1,51 @@
+package pci
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultProcInterrupts is the file of the per-CPU interrupt counts.
+const DefaultProcInterrupts = "/proc/interrupts"
+
+// ReadInterruptCounts reads the interrupt counts, summed over all the CPUs,
+// keyed by the numbered interrupts (e.g., the MSI/MSI-X interrupts of the devices).
+// The named interrupts (e.g., "NMI") are skipped.
+func ReadInterruptCounts(file string) (map[int]uint64, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	counts := make(map[int]uint64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		irqField, rest, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			// the header line of the CPU names
+			continue
+		}
+		irq, err := strconv.Atoi(strings.TrimSpace(irqField))
+		if err != nil {
+			continue
+		}
+
+		var total uint64
+		for _, field := range strings.Fields(rest) {
+			n, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				// the interrupt chip and device names follow the counts
+				break
+			}
+			total += n
+		}
+		counts[irq] = total
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
@@ -0,0 +1,28 @@
+package pci
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadInterruptCounts(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "interrupts")
+	require.NoError(t, os.WriteFile(p, []byte(`           CPU0       CPU1
+  0:         35          0   IO-APIC   2-edge      timer
+ 98:       1200        300   IR-PCI-MSIX-0000:18:00.0    0-edge      nvidia
+ 99:          0          0   IR-PCI-MSIX-0000:2a:00.0    0-edge      nvidia
+NMI:          4          3   Non-maskable interrupts
+ERR:          0
+`), 0644))
+
+	counts, err := ReadInterruptCounts(p)
+	require.NoError(t, err)
+	assert.Equal(t, map[int]uint64{0: 35, 98: 1500, 99: 0}, counts)
+
+	_, err = ReadInterruptCounts(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
@@ -2,55 +2,34 @@ package pci
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"regexp"
 	"strings"
 
-	"github.com/leptonai/gpud/pkg/file"
-	"github.com/leptonai/gpud/pkg/log"
-	"github.com/leptonai/gpud/pkg/process"
+	"github.com/leptonai/gpud/pkg/toolexec"
+	"github.com/leptonai/gpud/pkg/toolpath"
 )
 
 // Lists all PCI devices.
 func List(ctx context.Context) (Devices, error) {
-	lspciPath, err := file.LocateExecutable("lspci")
+	lspciPath, err := toolpath.Locate("lspci")
 	if err != nil {
 		return nil, nil
 	}
 
-	p, err := process.New(
-		process.WithBashScriptContentsToRun(fmt.Sprintf("sudo %s -vvv", lspciPath)),
-		process.WithRunAsBashScript(),
-	)
+	res, err := toolexec.Run(ctx, []string{"sudo", lspciPath, "-vvv"})
 	if err != nil {
 		return nil, err
 	}
 
-	if err := p.Start(ctx); err != nil {
-		return nil, err
-	}
-	defer func() {
-		if err := p.Close(ctx); err != nil {
-			log.Logger.Warnw("failed to abort command", "err", err)
-		}
-	}()
-
-	scanner := bufio.NewScanner(p.StdoutReader())
+	scanner := bufio.NewScanner(bytes.NewReader(res.Output))
 	devs, err := parseLspciVVV(ctx, scanner, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	select {
-	case err := <-p.Wait():
-		if err != nil {
-			return nil, err
-		}
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	}
-
 	return devs, nil
 }
 
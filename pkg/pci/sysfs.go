@@ -0,0 +1,178 @@
+package pci
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultSysfsRoot is the sysfs directory of the PCI bus.
+const DefaultSysfsRoot = "/sys/bus/pci"
+
+// DeviceStatus is the status of the device in the PCI enumeration.
+type DeviceStatus string
+
+const (
+	// DeviceStatusPresent means the device is enumerated and its config space is readable.
+	DeviceStatusPresent DeviceStatus = "present"
+	// DeviceStatusMissing means the device is no longer enumerated on the bus.
+	DeviceStatusMissing DeviceStatus = "missing"
+	// DeviceStatusUnresponsive means the device is still enumerated but its
+	// config space reads all ones (e.g., "GPU has fallen off the bus").
+	DeviceStatusUnresponsive DeviceStatus = "unresponsive"
+)
+
+// NormalizeBusID converts the bus ID to the sysfs device name format.
+// e.g., "00000000:18:00.0" (NVML) to "0000:18:00.0".
+func NormalizeBusID(busID string) string {
+	busID = strings.ToLower(strings.TrimSpace(busID))
+	domain, rest, ok := strings.Cut(busID, ":")
+	if !ok {
+		return busID
+	}
+	if !strings.Contains(rest, ":") {
+		// no domain (e.g., "18:00.0" from lspci)
+		return "0000:" + busID
+	}
+	if len(domain) > 4 {
+		domain = domain[len(domain)-4:]
+	}
+	return domain + ":" + rest
+}
+
+// GetDeviceStatus returns the status of the device in the PCI enumeration.
+func GetDeviceStatus(sysfsRoot string, busID string) (DeviceStatus, error) {
+	devDir := filepath.Join(sysfsRoot, "devices", NormalizeBusID(busID))
+	if _, err := os.Stat(devDir); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return DeviceStatusMissing, nil
+		}
+		return "", err
+	}
+
+	f, err := os.Open(filepath.Join(devDir, "config"))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	// vendor ID is the first 2 bytes of the config space
+	// which reads 0xffff once the device stopped responding
+	vendor := make([]byte, 2)
+	if _, err := io.ReadFull(f, vendor); err != nil {
+		return DeviceStatusUnresponsive, nil
+	}
+	if vendor[0] == 0xff && vendor[1] == 0xff {
+		return DeviceStatusUnresponsive, nil
+	}
+	return DeviceStatusPresent, nil
+}
+
+// RemoveDevice removes the device from the PCI enumeration,
+// so that the following rescan re-enumerates it from scratch.
+func RemoveDevice(sysfsRoot string, busID string) error {
+	p := filepath.Join(sysfsRoot, "devices", NormalizeBusID(busID), "remove")
+	if err := os.WriteFile(p, []byte("1"), 0); err != nil {
+		return fmt.Errorf("failed to remove pci device %s: %w", busID, err)
+	}
+	return nil
+}
+
+// Rescan rescans the PCI bus for the devices.
+func Rescan(sysfsRoot string) error {
+	if err := os.WriteFile(filepath.Join(sysfsRoot, "rescan"), []byte("1"), 0); err != nil {
+		return fmt.Errorf("failed to rescan pci bus: %w", err)
+	}
+	return nil
+}
+
+// IsVirtualFunction returns true if the device is an SR-IOV virtual function,
+// which links to its physical function in the sysfs.
+func IsVirtualFunction(sysfsRoot string, busID string) (bool, error) {
+	p := filepath.Join(sysfsRoot, "devices", NormalizeBusID(busID), "physfn")
+	if _, err := os.Lstat(p); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// SysfsDevice is the PCI device read from the sysfs.
+type SysfsDevice struct {
+	BusID string `json:"bus_id"`
+	// Vendor is the vendor ID (e.g., "0x10de" for NVIDIA).
+	Vendor string `json:"vendor"`
+	// Class is the device class code (e.g., "0x030200" for the 3D controller).
+	Class string `json:"class"`
+	// Driver is the name of the bound kernel driver, empty if not bound.
+	Driver string `json:"driver,omitempty"`
+	// MSIIRQs are the MSI/MSI-X interrupt numbers allocated to the device.
+	MSIIRQs []int `json:"msi_irqs,omitempty"`
+}
+
+// ListSysfsDevices lists the PCI devices of the vendor (e.g., "0x10de") from the sysfs.
+func ListSysfsDevices(sysfsRoot string, vendor string) ([]SysfsDevice, error) {
+	devsDir := filepath.Join(sysfsRoot, "devices")
+	entries, err := os.ReadDir(devsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var devs []SysfsDevice
+	for _, entry := range entries {
+		devDir := filepath.Join(devsDir, entry.Name())
+		v, err := readSysfsValue(filepath.Join(devDir, "vendor"))
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(v, vendor) {
+			continue
+		}
+
+		dev := SysfsDevice{
+			BusID:  entry.Name(),
+			Vendor: v,
+		}
+		dev.Class, err = readSysfsValue(filepath.Join(devDir, "class"))
+		if err != nil {
+			return nil, err
+		}
+
+		if target, err := os.Readlink(filepath.Join(devDir, "driver")); err == nil {
+			dev.Driver = filepath.Base(target)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+
+		irqs, err := os.ReadDir(filepath.Join(devDir, "msi_irqs"))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		for _, irq := range irqs {
+			n, err := strconv.Atoi(irq.Name())
+			if err != nil {
+				continue
+			}
+			dev.MSIIRQs = append(dev.MSIIRQs, n)
+		}
+		sort.Ints(dev.MSIIRQs)
+
+		devs = append(devs, dev)
+	}
+	return devs, nil
+}
+
+func readSysfsValue(p string) (string, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
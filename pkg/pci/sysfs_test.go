@@ -0,0 +1,124 @@
+package pci
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeBusID(t *testing.T) {
+	assert.Equal(t, "0000:18:00.0", NormalizeBusID("00000000:18:00.0"))
+	assert.Equal(t, "0000:af:00.0", NormalizeBusID("00000000:AF:00.0"))
+	assert.Equal(t, "0000:18:00.0", NormalizeBusID("0000:18:00.0"))
+	assert.Equal(t, "0000:18:00.0", NormalizeBusID("18:00.0"))
+}
+
+func TestGetDeviceStatus(t *testing.T) {
+	root := t.TempDir()
+
+	writeConfig := func(busID string, b []byte) {
+		dir := filepath.Join(root, "devices", busID)
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "config"), b, 0644))
+	}
+	writeConfig("0000:18:00.0", []byte{0xde, 0x10, 0x33, 0x23})
+	writeConfig("0000:2a:00.0", []byte{0xff, 0xff, 0xff, 0xff})
+	writeConfig("0000:3a:00.0", nil)
+
+	st, err := GetDeviceStatus(root, "00000000:18:00.0")
+	require.NoError(t, err)
+	assert.Equal(t, DeviceStatusPresent, st)
+
+	st, err = GetDeviceStatus(root, "00000000:2A:00.0")
+	require.NoError(t, err)
+	assert.Equal(t, DeviceStatusUnresponsive, st)
+
+	st, err = GetDeviceStatus(root, "00000000:3a:00.0")
+	require.NoError(t, err)
+	assert.Equal(t, DeviceStatusUnresponsive, st)
+
+	st, err = GetDeviceStatus(root, "00000000:5d:00.0")
+	require.NoError(t, err)
+	assert.Equal(t, DeviceStatusMissing, st)
+}
+
+func TestRemoveDeviceAndRescan(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "devices", "0000:18:00.0")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "remove"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "rescan"), nil, 0644))
+
+	require.NoError(t, RemoveDevice(root, "00000000:18:00.0"))
+	b, err := os.ReadFile(filepath.Join(dir, "remove"))
+	require.NoError(t, err)
+	assert.Equal(t, "1", string(b))
+
+	require.NoError(t, Rescan(root))
+	b, err = os.ReadFile(filepath.Join(root, "rescan"))
+	require.NoError(t, err)
+	assert.Equal(t, "1", string(b))
+
+	assert.Error(t, RemoveDevice(root, "00000000:5d:00.0"))
+}
+
+func TestIsVirtualFunction(t *testing.T) {
+	root := t.TempDir()
+	pf := filepath.Join(root, "devices", "0000:18:00.0")
+	vf := filepath.Join(root, "devices", "0000:18:00.4")
+	require.NoError(t, os.MkdirAll(pf, 0755))
+	require.NoError(t, os.MkdirAll(vf, 0755))
+	require.NoError(t, os.Symlink(pf, filepath.Join(vf, "physfn")))
+
+	isVF, err := IsVirtualFunction(root, "00000000:18:00.0")
+	require.NoError(t, err)
+	assert.False(t, isVF)
+
+	isVF, err = IsVirtualFunction(root, "00000000:18:00.4")
+	require.NoError(t, err)
+	assert.True(t, isVF)
+
+	isVF, err = IsVirtualFunction(root, "00000000:5d:00.0")
+	require.NoError(t, err)
+	assert.False(t, isVF)
+}
+
+func TestListSysfsDevices(t *testing.T) {
+	root := t.TempDir()
+	writeDevice := func(busID, vendor, class, driver string, irqs int) {
+		dir := filepath.Join(root, "devices", busID)
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "vendor"), []byte(vendor+"\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "class"), []byte(class+"\n"), 0644))
+		if driver != "" {
+			drvDir := filepath.Join(root, "drivers", driver)
+			require.NoError(t, os.MkdirAll(drvDir, 0755))
+			require.NoError(t, os.Symlink(drvDir, filepath.Join(dir, "driver")))
+		}
+		if irqs > 0 {
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, "msi_irqs"), 0755))
+			for i := 0; i < irqs; i++ {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "msi_irqs", strconv.Itoa(100+i)), []byte("msix"), 0644))
+			}
+		}
+	}
+	writeDevice("0000:18:00.0", "0x10de", "0x030200", "nvidia", 2)
+	writeDevice("0000:2a:00.0", "0x10de", "0x030200", "vfio-pci", 0)
+	writeDevice("0000:3a:00.0", "0x10de", "0x030200", "", 0)
+	writeDevice("0000:00:1f.0", "0x8086", "0x060100", "lpc_ich", 0)
+
+	devs, err := ListSysfsDevices(root, "0x10de")
+	require.NoError(t, err)
+	assert.Equal(t, []SysfsDevice{
+		{BusID: "0000:18:00.0", Vendor: "0x10de", Class: "0x030200", Driver: "nvidia", MSIIRQs: []int{100, 101}},
+		{BusID: "0000:2a:00.0", Vendor: "0x10de", Class: "0x030200", Driver: "vfio-pci"},
+		{BusID: "0000:3a:00.0", Vendor: "0x10de", Class: "0x030200"},
+	}, devs)
+
+	_, err = ListSysfsDevices(filepath.Join(root, "missing"), "0x10de")
+	assert.Error(t, err)
+}
@@ -0,0 +1,171 @@
+package peermesh
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	latencytarget "github.com/leptonai/gpud/pkg/netutil/latency/target"
+)
+
+const (
+	// DefaultProbeCount is the number of the probes sent to each peer per check.
+	DefaultProbeCount = 10
+	// DefaultProbeTimeout is the timeout of each probe,
+	// the timed out probe is counted as lost.
+	// It is kept below the initial TCP SYN retransmission timeout
+	// (1 second in Linux), otherwise the kernel retransmits the lost SYN
+	// (or SYN-ACK) within the timeout, and the lost packet is only
+	// counted as a slower connect rather than the packet loss.
+	DefaultProbeTimeout = 900 * time.Millisecond
+)
+
+// PeerStatus is the probe result of a peer.
+type PeerStatus struct {
+	Peer
+
+	Sent     int `json:"sent"`
+	Received int `json:"received"`
+	// PacketLossPercent is the percentage of the lost probes.
+	PacketLossPercent float64 `json:"packet_loss_percent"`
+
+	// Latency is the average round-trip time of the received probes.
+	Latency             metav1.Duration `json:"latency"`
+	LatencyMilliseconds int64           `json:"latency_milliseconds"`
+
+	// Error is the last probe error, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// Reachable returns true if at least one probe to the peer was received.
+func (s PeerStatus) Reachable() bool {
+	return s.Received > 0
+}
+
+// ProbePeers probes all the peers concurrently, sorted by the rack and the peer ID.
+// The options override the default probe count and timeout.
+func ProbePeers(ctx context.Context, peers Peers, opts ...latencytarget.OpOption) []PeerStatus {
+	opts = append([]latencytarget.OpOption{
+		latencytarget.WithAttempts(DefaultProbeCount),
+		latencytarget.WithTimeout(DefaultProbeTimeout),
+	}, opts...)
+
+	statuses := make([]PeerStatus, len(peers))
+
+	var wg sync.WaitGroup
+	for i, p := range peers {
+		wg.Add(1)
+		go func(i int, p Peer) {
+			defer wg.Done()
+
+			r := latencytarget.Probe(ctx, p.Address, opts...)
+			st := PeerStatus{
+				Peer:                p,
+				Sent:                r.Sent,
+				Received:            r.Received,
+				PacketLossPercent:   r.LossPercent(),
+				Latency:             metav1.Duration{Duration: r.Avg},
+				LatencyMilliseconds: r.Avg.Milliseconds(),
+			}
+			if r.Err != nil {
+				st.Error = r.Err.Error()
+			}
+			statuses[i] = st
+		}(i, p)
+	}
+	wg.Wait()
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Rack != statuses[j].Rack {
+			return statuses[i].Rack < statuses[j].Rack
+		}
+		return statuses[i].ID < statuses[j].ID
+	})
+	return statuses
+}
+
+// Thresholds are the thresholds to consider a peer degraded.
+type Thresholds struct {
+	// PacketLossPercent is the packet loss percentage above which the peer is degraded.
+	PacketLossPercent float64 `json:"packet_loss_percent"`
+	// LatencyMilliseconds is the average latency above which the peer is degraded.
+	// Zero disables the latency threshold.
+	LatencyMilliseconds int64 `json:"latency_milliseconds"`
+}
+
+// Degraded returns true if the peer is unreachable or exceeds the thresholds.
+func (t Thresholds) Degraded(s PeerStatus) bool {
+	if !s.Reachable() {
+		return true
+	}
+	if s.PacketLossPercent > t.PacketLossPercent {
+		return true
+	}
+	return t.LatencyMilliseconds > 0 && s.LatencyMilliseconds > t.LatencyMilliseconds
+}
+
+// RackStatus is the summary of the peers in a rack.
+type RackStatus struct {
+	Rack string `json:"rack"`
+
+	Peers         int `json:"peers"`
+	DegradedPeers int `json:"degraded_peers"`
+
+	// PacketLossPercent is the packet loss percentage over all the probes to the rack.
+	PacketLossPercent float64 `json:"packet_loss_percent"`
+	// LatencyMilliseconds is the average latency of the reachable peers in the rack.
+	LatencyMilliseconds int64 `json:"latency_milliseconds"`
+}
+
+// AllDegraded returns true if all the peers in the rack are degraded,
+// which likely indicates the rack-level network issue (e.g., the top-of-rack switch).
+func (r RackStatus) AllDegraded() bool {
+	return r.Peers > 0 && r.DegradedPeers == r.Peers
+}
+
+// SummarizeRacks summarizes the peer statuses per rack, sorted by the rack.
+// The peers without the rack are grouped into the empty rack.
+func SummarizeRacks(statuses []PeerStatus, thresholds Thresholds) []RackStatus {
+	type agg struct {
+		RackStatus
+		sent, received int
+		latencySum     int64
+		reachable      int64
+	}
+	racks := make(map[string]*agg)
+	for _, s := range statuses {
+		a, ok := racks[s.Rack]
+		if !ok {
+			a = &agg{RackStatus: RackStatus{Rack: s.Rack}}
+			racks[s.Rack] = a
+		}
+		a.Peers++
+		if thresholds.Degraded(s) {
+			a.DegradedPeers++
+		}
+		a.sent += s.Sent
+		a.received += s.Received
+		if s.Reachable() {
+			a.latencySum += s.LatencyMilliseconds
+			a.reachable++
+		}
+	}
+
+	rss := make([]RackStatus, 0, len(racks))
+	for _, a := range racks {
+		if a.sent > 0 {
+			a.PacketLossPercent = float64(a.sent-a.received) / float64(a.sent) * 100
+		}
+		if a.reachable > 0 {
+			a.LatencyMilliseconds = a.latencySum / a.reachable
+		}
+		rss = append(rss, a.RackStatus)
+	}
+	sort.Slice(rss, func(i, j int) bool {
+		return rss[i].Rack < rss[j].Rack
+	})
+	return rss
+}
@@ -0,0 +1,82 @@
+package peermesh
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	latencytarget "github.com/leptonai/gpud/pkg/netutil/latency/target"
+)
+
+func TestProbePeers(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedAddr := closed.Addr().String()
+	require.NoError(t, closed.Close())
+
+	statuses := ProbePeers(context.Background(), Peers{
+		{ID: "node-3", Address: closedAddr, Rack: "rack-1"},
+		{ID: "node-2", Address: ln.Addr().String(), Rack: "rack-1"},
+	}, latencytarget.WithAttempts(2), latencytarget.WithTimeout(time.Second))
+	require.Len(t, statuses, 2)
+
+	assert.Equal(t, "node-2", statuses[0].ID)
+	assert.True(t, statuses[0].Reachable())
+	assert.Equal(t, 2, statuses[0].Sent)
+	assert.Equal(t, 2, statuses[0].Received)
+	assert.Equal(t, float64(0), statuses[0].PacketLossPercent)
+	assert.Empty(t, statuses[0].Error)
+
+	assert.Equal(t, "node-3", statuses[1].ID)
+	assert.False(t, statuses[1].Reachable())
+	assert.Equal(t, float64(100), statuses[1].PacketLossPercent)
+	assert.NotEmpty(t, statuses[1].Error)
+}
+
+func TestThresholdsDegraded(t *testing.T) {
+	th := Thresholds{PacketLossPercent: 20, LatencyMilliseconds: 100}
+
+	assert.False(t, th.Degraded(PeerStatus{Sent: 10, Received: 9, PacketLossPercent: 10, LatencyMilliseconds: 5}))
+	assert.True(t, th.Degraded(PeerStatus{Sent: 10, Received: 0, PacketLossPercent: 100}))
+	assert.True(t, th.Degraded(PeerStatus{Sent: 10, Received: 7, PacketLossPercent: 30, LatencyMilliseconds: 5}))
+	assert.True(t, th.Degraded(PeerStatus{Sent: 10, Received: 10, LatencyMilliseconds: 150}))
+
+	th.LatencyMilliseconds = 0
+	assert.False(t, th.Degraded(PeerStatus{Sent: 10, Received: 10, LatencyMilliseconds: 150}))
+}
+
+func TestSummarizeRacks(t *testing.T) {
+	th := Thresholds{PacketLossPercent: 20}
+	racks := SummarizeRacks([]PeerStatus{
+		{Peer: Peer{ID: "a", Rack: "rack-2"}, Sent: 10, Received: 0, PacketLossPercent: 100},
+		{Peer: Peer{ID: "b", Rack: "rack-2"}, Sent: 10, Received: 5, PacketLossPercent: 50, LatencyMilliseconds: 4},
+		{Peer: Peer{ID: "c", Rack: "rack-1"}, Sent: 10, Received: 10, LatencyMilliseconds: 2},
+		{Peer: Peer{ID: "d", Rack: "rack-1"}, Sent: 10, Received: 0, PacketLossPercent: 100},
+	}, th)
+	require.Len(t, racks, 2)
+
+	assert.Equal(t, RackStatus{Rack: "rack-1", Peers: 2, DegradedPeers: 1, PacketLossPercent: 50, LatencyMilliseconds: 2}, racks[0])
+	assert.False(t, racks[0].AllDegraded())
+
+	assert.Equal(t, RackStatus{Rack: "rack-2", Peers: 2, DegradedPeers: 2, PacketLossPercent: 75, LatencyMilliseconds: 4}, racks[1])
+	assert.True(t, racks[1].AllDegraded())
+
+	assert.False(t, RackStatus{}.AllDegraded())
+}
@@ -0,0 +1,73 @@
+// Package peermesh probes the peer gpud nodes, and summarizes the probe results
+// per rack to tell the rack-level network issues from the single-node ones.
+package peermesh
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	latencytarget "github.com/leptonai/gpud/pkg/netutil/latency/target"
+)
+
+// Peer is the peer node to probe.
+type Peer struct {
+	// ID is the unique ID of the peer (e.g., the machine ID).
+	ID string `json:"id"`
+	// Address is the "host:port" of the peer (e.g., the gpud endpoint of the peer).
+	Address string `json:"address"`
+	// Rack is the optional rack (or any failure domain) of the peer,
+	// to group the peers in the mesh view.
+	Rack string `json:"rack,omitempty"`
+}
+
+// Peers is the list of the peers.
+type Peers []Peer
+
+var (
+	ErrPeerIDEmpty      = errors.New("peer id is empty")
+	ErrPeerAddressEmpty = errors.New("peer address is empty")
+)
+
+// Validate validates the peers.
+func (peers Peers) Validate() error {
+	ids := make(map[string]struct{}, len(peers))
+	for _, p := range peers {
+		if p.ID == "" {
+			return ErrPeerIDEmpty
+		}
+		if p.Address == "" {
+			return ErrPeerAddressEmpty
+		}
+		if _, _, err := net.SplitHostPort(p.Address); err != nil {
+			return fmt.Errorf("invalid address %q of peer %q: %w", p.Address, p.ID, err)
+		}
+		if _, ok := ids[p.ID]; ok {
+			return fmt.Errorf("duplicate peer id %q", p.ID)
+		}
+		ids[p.ID] = struct{}{}
+	}
+	return nil
+}
+
+// ParsePeers parses the comma-separated peers in the format of
+// "[<id>[@<rack>]=]<host:port>", the same format as the latency targets
+// with the rack in place of the region (e.g., "node-2@rack-1=10.0.1.2:15132").
+func ParsePeers(s string) (Peers, error) {
+	targets, err := latencytarget.ParseTargets(s)
+	if err != nil {
+		return nil, err
+	}
+	peers := make(Peers, 0, len(targets))
+	for _, t := range targets {
+		peers = append(peers, Peer{
+			ID:      t.Name,
+			Address: t.Address,
+			Rack:    t.RegionCode,
+		})
+	}
+	if err := peers.Validate(); err != nil {
+		return nil, err
+	}
+	return peers, nil
+}
@@ -0,0 +1,49 @@
+package peermesh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePeers(t *testing.T) {
+	peers, err := ParsePeers("node-2@rack-1=10.0.1.2:15132,node-3=10.0.1.3:15132,10.0.2.4:15132")
+	require.NoError(t, err)
+	assert.Equal(t, Peers{
+		{ID: "node-2", Address: "10.0.1.2:15132", Rack: "rack-1"},
+		{ID: "node-3", Address: "10.0.1.3:15132"},
+		{ID: "10.0.2.4:15132", Address: "10.0.2.4:15132"},
+	}, peers)
+
+	_, err = ParsePeers("node-2=10.0.1.2")
+	assert.Error(t, err)
+
+	_, err = ParsePeers("node-2=10.0.1.2:15132,node-2=10.0.1.3:15132")
+	assert.ErrorContains(t, err, "duplicate peer id")
+}
+
+func TestPeersValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		peers   Peers
+		wantErr error
+	}{
+		{name: "empty", peers: nil},
+		{name: "valid", peers: Peers{{ID: "a", Address: "10.0.0.1:15132"}}},
+		{name: "empty id", peers: Peers{{Address: "10.0.0.1:15132"}}, wantErr: ErrPeerIDEmpty},
+		{name: "empty address", peers: Peers{{ID: "a"}}, wantErr: ErrPeerAddressEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.peers.Validate()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	assert.Error(t, Peers{{ID: "a", Address: "10.0.0.1"}}.Validate())
+}
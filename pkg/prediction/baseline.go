@@ -0,0 +1,84 @@
+package prediction
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+// metricLabelGPUUUID is the metric label key of the GPU UUID.
+const metricLabelGPUUUID = "uuid"
+
+// defaultEventWeights are the risk scores added per event type.
+var defaultEventWeights = map[apiv1.EventType]float64{
+	apiv1.EventTypeWarning:  0.1,
+	apiv1.EventTypeCritical: 0.5,
+	apiv1.EventTypeFatal:    1.0,
+}
+
+var _ Model = &baselineModel{}
+
+// baselineModel scores the failure risk by the number and the severity
+// of the recent events, as the reference for the trained models.
+// The risk of a target is "1 - exp(-score)", where the score is the sum
+// of the weights of the events, so that the risk saturates towards 1.
+// The events are attributed to a GPU if their messages mention the GPU UUID
+// (the GPUs are discovered from the "uuid" metric labels), and all the events
+// are attributed to the node.
+type baselineModel struct {
+	weights map[apiv1.EventType]float64
+}
+
+// NewBaselineModel creates the built-in baseline model.
+func NewBaselineModel() Model {
+	return &baselineModel{weights: defaultEventWeights}
+}
+
+func (m *baselineModel) Name() string {
+	return ModelBaseline
+}
+
+func (m *baselineModel) Predict(ctx context.Context, w Window) ([]Prediction, error) {
+	gpus := make(map[string]struct{})
+	for _, mt := range w.Metrics {
+		if uuid := mt.Labels[metricLabelGPUUUID]; uuid != "" {
+			gpus[uuid] = struct{}{}
+		}
+	}
+	targets := make([]string, 0, len(gpus))
+	for uuid := range gpus {
+		targets = append(targets, uuid)
+	}
+	sort.Strings(targets)
+
+	scores := make(map[string]float64, len(targets)+1)
+	counts := make(map[string]int, len(targets)+1)
+	for _, ev := range w.Events {
+		weight := m.weights[ev.Type]
+		if weight == 0 {
+			continue
+		}
+		scores[TargetNode] += weight
+		counts[TargetNode]++
+		for _, uuid := range targets {
+			if strings.Contains(ev.Message, uuid) {
+				scores[uuid] += weight
+				counts[uuid]++
+			}
+		}
+	}
+
+	preds := make([]Prediction, 0, len(targets)+1)
+	for _, target := range append([]string{TargetNode}, targets...) {
+		preds = append(preds, Prediction{
+			Target: target,
+			Risk:   clamp(1 - math.Exp(-scores[target])),
+			Reason: fmt.Sprintf("%d warning or worse event(s) in the window", counts[target]),
+		})
+	}
+	return preds, nil
+}
@@ -0,0 +1,46 @@
+package prediction
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/leptonai/gpud/pkg/toolexec"
+)
+
+var _ Model = &execModel{}
+
+type execModel struct {
+	path string
+}
+
+// NewExecModel creates the model that scores the window with the local command,
+// for the local model files (e.g., ONNX) without linking the model runtime into gpud.
+// The window is written in JSON to the stdin of the command,
+// and the command writes the Response in JSON to the stdout.
+func NewExecModel(path string) Model {
+	return &execModel{path: path}
+}
+
+func (m *execModel) Name() string {
+	return execPrefix + m.path
+}
+
+func (m *execModel) Predict(ctx context.Context, w Window) ([]Prediction, error) {
+	b, err := json.Marshal(w)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := toolexec.Run(ctx, []string{m.path}, toolexec.WithStdin(bytes.NewReader(b)), toolexec.WithSeparateStderr())
+	if err != nil {
+		var stderr string
+		if res != nil {
+			stderr = strings.TrimSpace(string(res.Stderr))
+		}
+		return nil, fmt.Errorf("failed to run model %q: %w (%s)", m.path, err, stderr)
+	}
+	return decodeResponse(res.Output)
+}
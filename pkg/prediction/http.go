@@ -0,0 +1,79 @@
+package prediction
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Response is the response of the HTTP scoring endpoint and the local command model.
+type Response struct {
+	Predictions []Prediction `json:"predictions"`
+}
+
+var _ Model = &httpModel{}
+
+type httpModel struct {
+	url string
+	cli *http.Client
+}
+
+// NewHTTPModel creates the model that scores the window with the HTTP endpoint.
+// The window is POSTed in JSON and the endpoint responds with the Response in JSON.
+// If the client is nil, the client with 30-second timeout is used.
+func NewHTTPModel(url string, cli *http.Client) Model {
+	if cli == nil {
+		cli = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &httpModel{url: url, cli: cli}
+}
+
+func (m *httpModel) Name() string {
+	return m.url
+}
+
+func (m *httpModel) Predict(ctx context.Context, w Window) ([]Prediction, error) {
+	b, err := json.Marshal(w)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.url, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("scoring endpoint returned %d: %s", resp.StatusCode, string(body))
+	}
+	return decodeResponse(body)
+}
+
+func decodeResponse(b []byte) ([]Prediction, error) {
+	var resp Response
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode predictions: %w", err)
+	}
+	for i := range resp.Predictions {
+		if resp.Predictions[i].Target == "" {
+			resp.Predictions[i].Target = TargetNode
+		}
+		resp.Predictions[i].Risk = clamp(resp.Predictions[i].Risk)
+	}
+	return resp.Predictions, nil
+}
@@ -0,0 +1,86 @@
+// Package prediction defines the pluggable failure prediction models,
+// which score the recent window of the events and the metrics into
+// the predicted failure risk per node or per GPU.
+package prediction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+// TargetNode is the prediction target for the node itself.
+// The other targets are the GPU UUIDs (or IDs).
+const TargetNode = "node"
+
+const (
+	// ModelBaseline is the model name of the built-in baseline model.
+	ModelBaseline = "baseline"
+
+	// execPrefix is the model spec prefix of the local command model.
+	execPrefix = "exec:"
+)
+
+var ErrUnknownModel = errors.New("unknown prediction model")
+
+// Window is the recent window of the events and the metrics fed to the model.
+type Window struct {
+	Start   time.Time          `json:"start"`
+	End     time.Time          `json:"end"`
+	Events  apiv1.Events       `json:"events,omitempty"`
+	Metrics pkgmetrics.Metrics `json:"metrics,omitempty"`
+}
+
+// Prediction is the predicted failure risk of a target.
+type Prediction struct {
+	// Target is either TargetNode or the GPU UUID.
+	Target string `json:"target"`
+	// Risk is the predicted failure risk in [0, 1].
+	Risk float64 `json:"risk"`
+	// Reason explains the prediction.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Model predicts the failure risks from the window.
+type Model interface {
+	// Name returns the name of the model.
+	Name() string
+	// Predict returns the predictions for the targets in the window.
+	Predict(ctx context.Context, w Window) ([]Prediction, error)
+}
+
+// New creates the model from the model spec:
+//
+//	"baseline"                   the built-in baseline model
+//	"http(s)://<scoring-endpoint>" the HTTP scoring endpoint
+//	"exec:<path>"                the local command (e.g., a script running an ONNX model)
+//
+// See NewHTTPModel and NewExecModel for the request and response formats.
+func New(spec string) (Model, error) {
+	switch {
+	case spec == ModelBaseline:
+		return NewBaselineModel(), nil
+	case strings.HasPrefix(spec, "http://"), strings.HasPrefix(spec, "https://"):
+		return NewHTTPModel(spec, nil), nil
+	case strings.HasPrefix(spec, execPrefix):
+		return NewExecModel(strings.TrimPrefix(spec, execPrefix)), nil
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownModel, spec)
+	}
+}
+
+// clamp clamps the risk into [0, 1].
+func clamp(risk float64) float64 {
+	if risk < 0 {
+		return 0
+	}
+	if risk > 1 {
+		return 1
+	}
+	return risk
+}
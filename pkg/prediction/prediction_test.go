@@ -0,0 +1,128 @@
+package prediction
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+func TestNew(t *testing.T) {
+	m, err := New("baseline")
+	require.NoError(t, err)
+	assert.Equal(t, ModelBaseline, m.Name())
+
+	m, err = New("https://example.com/score")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/score", m.Name())
+
+	m, err = New("exec:/usr/local/bin/score")
+	require.NoError(t, err)
+	assert.Equal(t, "exec:/usr/local/bin/score", m.Name())
+
+	_, err = New("model.onnx")
+	require.ErrorIs(t, err, ErrUnknownModel)
+}
+
+func TestBaselineModel(t *testing.T) {
+	now := metav1.Now()
+	w := Window{
+		Events: apiv1.Events{
+			{Time: now, Type: apiv1.EventTypeInfo, Message: "reboot"},
+			{Time: now, Type: apiv1.EventTypeCritical, Message: "xid 79 on GPU-a"},
+			{Time: now, Type: apiv1.EventTypeWarning, Message: "row remapping pending"},
+		},
+		Metrics: pkgmetrics.Metrics{
+			{Name: "gpu_util", Labels: map[string]string{"uuid": "GPU-b"}},
+			{Name: "gpu_util", Labels: map[string]string{"uuid": "GPU-a"}},
+			{Name: "cpu_usage"},
+		},
+	}
+
+	preds, err := NewBaselineModel().Predict(context.Background(), w)
+	require.NoError(t, err)
+	require.Len(t, preds, 3)
+
+	assert.Equal(t, TargetNode, preds[0].Target)
+	assert.InDelta(t, 0.451, preds[0].Risk, 0.001)
+	assert.Contains(t, preds[0].Reason, "2 warning or worse")
+
+	assert.Equal(t, "GPU-a", preds[1].Target)
+	assert.InDelta(t, 0.393, preds[1].Risk, 0.001)
+
+	assert.Equal(t, "GPU-b", preds[2].Target)
+	assert.Zero(t, preds[2].Risk)
+
+	// no event, no risk
+	preds, err = NewBaselineModel().Predict(context.Background(), Window{})
+	require.NoError(t, err)
+	require.Len(t, preds, 1)
+	assert.Zero(t, preds[0].Risk)
+}
+
+func TestHTTPModel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var win Window
+		if err := json.NewDecoder(r.Body).Decode(&win); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Response{
+			Predictions: []Prediction{
+				{Risk: float64(len(win.Events))},
+				{Target: "GPU-a", Risk: 0.2, Reason: "ok"},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	m := NewHTTPModel(srv.URL, nil)
+	preds, err := m.Predict(context.Background(), Window{
+		Start:  time.Now().Add(-time.Hour),
+		End:    time.Now(),
+		Events: apiv1.Events{{Type: apiv1.EventTypeFatal}, {Type: apiv1.EventTypeFatal}},
+	})
+	require.NoError(t, err)
+	require.Len(t, preds, 2)
+	assert.Equal(t, Prediction{Target: TargetNode, Risk: 1}, preds[0])
+	assert.Equal(t, Prediction{Target: "GPU-a", Risk: 0.2, Reason: "ok"}, preds[1])
+}
+
+func TestHTTPModelError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("model not loaded"))
+	}))
+	defer srv.Close()
+
+	_, err := NewHTTPModel(srv.URL, nil).Predict(context.Background(), Window{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "model not loaded")
+}
+
+func TestExecModel(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "score.sh")
+	require.NoError(t, os.WriteFile(script, []byte(`#!/bin/sh
+cat > /dev/null
+echo '{"predictions":[{"target":"node","risk":0.75,"reason":"test"}]}'
+`), 0755))
+
+	preds, err := NewExecModel(script).Predict(context.Background(), Window{})
+	require.NoError(t, err)
+	require.Len(t, preds, 1)
+	assert.Equal(t, Prediction{Target: TargetNode, Risk: 0.75, Reason: "test"}, preds[0])
+
+	_, err = NewExecModel(filepath.Join(t.TempDir(), "missing")).Predict(context.Background(), Window{})
+	require.Error(t, err)
+}
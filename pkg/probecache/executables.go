@@ -0,0 +1,33 @@
+package probecache
+
+import (
+	"context"
+
+	"github.com/leptonai/gpud/pkg/file"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// LoadExecutablePaths seeds the executable paths located
+// in the previous runs of the current boot (see file.LocateExecutable).
+func LoadExecutablePaths(ctx context.Context, c *Cache) {
+	paths := make(map[string]string)
+	ok, err := c.Get(ctx, KeyExecutablePaths, &paths)
+	if err != nil {
+		log.Logger.Warnw("failed to read cached executable paths", "error", err)
+		return
+	}
+	if ok {
+		file.SetExecutablePaths(paths)
+		log.Logger.Debugw("loaded cached executable paths", "paths", len(paths))
+	}
+}
+
+// SaveExecutablePaths caches the executable paths located so far,
+// for the next runs of the current boot.
+func SaveExecutablePaths(ctx context.Context, c *Cache) error {
+	paths := file.ExecutablePaths()
+	if len(paths) == 0 {
+		return nil
+	}
+	return c.Set(ctx, KeyExecutablePaths, paths)
+}
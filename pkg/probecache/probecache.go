@@ -0,0 +1,163 @@
+// Package probecache persists the results of the expensive static discoveries
+// (e.g., the GPU device inventory, the installed tool paths) in the state file,
+// so that the repeated "gpud scan" runs and the daemon restarts skip the
+// redundant probing. The entries are invalidated on the boot ID change,
+// since the hardware and the installed tools may change across reboots.
+// The entries that may change within the boot are loaded with the max age.
+package probecache
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetricsrecorder "github.com/leptonai/gpud/pkg/metrics/recorder"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+const (
+	tableName = "gpud_probe_cache"
+
+	columnKey       = "key"
+	columnBootID    = "boot_id"
+	columnValue     = "value"
+	columnUpdatedAt = "updated_at"
+)
+
+const (
+	// KeyMachineGPUInfo is the key for the GPU device inventory and topology.
+	KeyMachineGPUInfo = "machine_gpu_info"
+	// KeyMachineCPUInfo is the key for the CPU information.
+	KeyMachineCPUInfo = "machine_cpu_info"
+	// KeyExecutablePaths is the key for the located executable paths of the installed tools.
+	KeyExecutablePaths = "executable_paths"
+)
+
+// CreateTable creates the probe cache table.
+// The dbRW is either the read-write database or the transaction of the schema migration.
+func CreateTable(ctx context.Context, dbRW sqlite.Execer) error {
+	_, err := dbRW.ExecContext(ctx, fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %s (
+	%s TEXT PRIMARY KEY,
+	%s TEXT NOT NULL,
+	%s TEXT NOT NULL,
+	%s INTEGER NOT NULL
+) WITHOUT ROWID;`, tableName, columnKey, columnBootID, columnValue, columnUpdatedAt))
+	return err
+}
+
+// Cache is the probe cache of the current boot.
+// All the methods are safe to call on the nil cache,
+// in which case nothing is cached.
+type Cache struct {
+	dbRW   *sql.DB
+	dbRO   *sql.DB
+	bootID string
+}
+
+// New creates the probe cache for the current boot ID,
+// and purges the entries cached in the previous boots.
+func New(ctx context.Context, dbRW *sql.DB, dbRO *sql.DB, bootID string) (*Cache, error) {
+	start := time.Now()
+	res, err := dbRW.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE %s != ?`, tableName, columnBootID), bootID)
+	pkgmetricsrecorder.RecordSQLiteDelete(time.Since(start).Seconds())
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		log.Logger.Infow("boot id changed, invalidated probe cache", "entries", n)
+	}
+
+	return &Cache{
+		dbRW:   dbRW,
+		dbRO:   dbRO,
+		bootID: bootID,
+	}, nil
+}
+
+// Get reads the cached value of the key into v,
+// and returns false if not cached in the current boot.
+func (c *Cache) Get(ctx context.Context, key string, v any) (bool, error) {
+	return c.GetWithMaxAge(ctx, key, 0, v)
+}
+
+// GetWithMaxAge is the same as Get, but returns false if cached
+// longer than the max age ago. Zero max age means no age limit.
+func (c *Cache) GetWithMaxAge(ctx context.Context, key string, maxAge time.Duration, v any) (bool, error) {
+	if c == nil {
+		return false, nil
+	}
+
+	var since int64
+	if maxAge > 0 {
+		since = time.Now().UTC().Add(-maxAge).Unix()
+	}
+
+	var raw string
+	start := time.Now()
+	err := c.dbRO.QueryRowContext(ctx, fmt.Sprintf(`
+SELECT %s FROM %s WHERE %s = ? AND %s = ? AND %s >= ?`, columnValue, tableName, columnKey, columnBootID, columnUpdatedAt), key, c.bootID, since).Scan(&raw)
+	pkgmetricsrecorder.RecordSQLiteSelect(time.Since(start).Seconds())
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := json.Unmarshal([]byte(raw), v); err != nil {
+		return false, fmt.Errorf("failed to unmarshal cached %q: %w", key, err)
+	}
+	return true, nil
+}
+
+// Set caches the value of the key for the current boot.
+func (c *Cache) Set(ctx context.Context, key string, v any) error {
+	if c == nil {
+		return nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	_, err = c.dbRW.ExecContext(ctx, fmt.Sprintf(`
+INSERT OR REPLACE INTO %s (%s, %s, %s, %s) VALUES (?, ?, ?, ?)`, tableName, columnKey, columnBootID, columnValue, columnUpdatedAt),
+		key, c.bootID, string(b), time.Now().UTC().Unix())
+	pkgmetricsrecorder.RecordSQLiteInsertUpdate(time.Since(start).Seconds())
+	return err
+}
+
+// Load returns the cached value of the key, or probes and caches it if not cached.
+// The cache failures are logged and fall back to probing.
+func Load[T any](ctx context.Context, c *Cache, key string, probe func() (T, error)) (T, error) {
+	return LoadWithMaxAge(ctx, c, key, 0, probe)
+}
+
+// LoadWithMaxAge is the same as Load, but re-probes the value
+// cached longer than the max age ago. Zero max age means no age limit.
+func LoadWithMaxAge[T any](ctx context.Context, c *Cache, key string, maxAge time.Duration, probe func() (T, error)) (T, error) {
+	var v T
+	ok, err := c.GetWithMaxAge(ctx, key, maxAge, &v)
+	if err != nil {
+		log.Logger.Warnw("failed to read probe cache", "key", key, "error", err)
+	}
+	if ok {
+		log.Logger.Debugw("using cached probe", "key", key)
+		return v, nil
+	}
+
+	v, err = probe()
+	if err != nil {
+		return v, err
+	}
+	if err := c.Set(ctx, key, v); err != nil {
+		log.Logger.Warnw("failed to write probe cache", "key", key, "error", err)
+	}
+	return v, nil
+}
@@ -0,0 +1,121 @@
+package probecache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+type inventory struct {
+	GPUs []string `json:"gpus"`
+}
+
+func TestCache(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	require.NoError(t, CreateTable(ctx, dbRW))
+
+	c, err := New(ctx, dbRW, dbRO, "boot-1")
+	require.NoError(t, err)
+
+	probes := 0
+	probe := func() (inventory, error) {
+		probes++
+		return inventory{GPUs: []string{"GPU-0", "GPU-1"}}, nil
+	}
+
+	v, err := Load(ctx, c, KeyMachineGPUInfo, probe)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GPU-0", "GPU-1"}, v.GPUs)
+	assert.Equal(t, 1, probes)
+
+	// cached
+	v, err = Load(ctx, c, KeyMachineGPUInfo, probe)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GPU-0", "GPU-1"}, v.GPUs)
+	assert.Equal(t, 1, probes)
+
+	// same boot, new process
+	c, err = New(ctx, dbRW, dbRO, "boot-1")
+	require.NoError(t, err)
+	_, err = Load(ctx, c, KeyMachineGPUInfo, probe)
+	require.NoError(t, err)
+	assert.Equal(t, 1, probes)
+
+	// rebooted, invalidated
+	c, err = New(ctx, dbRW, dbRO, "boot-2")
+	require.NoError(t, err)
+	_, err = Load(ctx, c, KeyMachineGPUInfo, probe)
+	require.NoError(t, err)
+	assert.Equal(t, 2, probes)
+
+	// probe failure is not cached
+	_, err = Load(ctx, c, "failing", func() (inventory, error) { return inventory{}, errors.New("failed") })
+	require.Error(t, err)
+	ok, err := c.Get(ctx, "failing", &inventory{})
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestNilCache(t *testing.T) {
+	var c *Cache
+	probes := 0
+	for i := 0; i < 2; i++ {
+		_, err := Load(context.Background(), c, KeyMachineCPUInfo, func() (string, error) {
+			probes++
+			return "cpu", nil
+		})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, probes)
+	assert.NoError(t, SaveExecutablePaths(context.Background(), c))
+}
+
+func TestLoadWithMaxAge(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	require.NoError(t, CreateTable(ctx, dbRW))
+
+	c, err := New(ctx, dbRW, dbRO, "boot-1")
+	require.NoError(t, err)
+
+	probes := 0
+	probe := func() (string, error) {
+		probes++
+		return "v", nil
+	}
+
+	_, err = LoadWithMaxAge(ctx, c, KeyMachineGPUInfo, time.Hour, probe)
+	require.NoError(t, err)
+	_, err = LoadWithMaxAge(ctx, c, KeyMachineGPUInfo, time.Hour, probe)
+	require.NoError(t, err)
+	assert.Equal(t, 1, probes)
+
+	// cached two hours ago, expired
+	_, err = dbRW.ExecContext(ctx, "UPDATE "+tableName+" SET "+columnUpdatedAt+" = ?", time.Now().Add(-2*time.Hour).Unix())
+	require.NoError(t, err)
+	_, err = LoadWithMaxAge(ctx, c, KeyMachineGPUInfo, time.Hour, probe)
+	require.NoError(t, err)
+	assert.Equal(t, 2, probes)
+
+	// no age limit
+	_, err = dbRW.ExecContext(ctx, "UPDATE "+tableName+" SET "+columnUpdatedAt+" = ?", time.Now().Add(-2*time.Hour).Unix())
+	require.NoError(t, err)
+	_, err = Load(ctx, c, KeyMachineGPUInfo, probe)
+	require.NoError(t, err)
+	assert.Equal(t, 2, probes)
+}
@@ -0,0 +1,165 @@
+package distsign
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/crypto/blake2s"
+)
+
+// SignatureSuffix is the suffix of the detached signature file
+// next to the signed artifact (e.g., "plugins.yaml.sig").
+const SignatureSuffix = ".sig"
+
+// ErrInvalidArtifactSignature is returned when the artifact signature
+// is not valid for any of the artifact keys.
+var ErrInvalidArtifactSignature = errors.New("artifact signature not valid")
+
+const (
+	// The artifact keys are a separate role from the root and the package signing keys,
+	// so that a compromised artifact key cannot sign the packages, and vice versa.
+	pemTypeArtifactPrivate = "ARTIFACT PRIVATE KEY"
+	pemTypeArtifactPublic  = "ARTIFACT PUBLIC KEY"
+
+	// artifactSignaturePrefix separates the artifact signatures from the package
+	// signatures, followed by the artifact type and a NUL byte.
+	artifactSignaturePrefix = "gpud-artifact-v1\x00"
+)
+
+// ArtifactType is the type of the artifact distributed to the nodes,
+// covered by the signature so that the artifact signed as one type
+// is never accepted as another (e.g., the hooks file as the plugin specs).
+type ArtifactType string
+
+const (
+	ArtifactTypePluginSpecs          ArtifactType = "plugin-specs"
+	ArtifactTypeInfinibandEvaluation ArtifactType = "infiniband-evaluation"
+	ArtifactTypeKmsgMatchers         ArtifactType = "kmsg-matchers"
+	ArtifactTypeLLDPCablingMap       ArtifactType = "lldp-cabling-map"
+	ArtifactTypeErrorBudgets         ArtifactType = "error-budgets"
+	ArtifactTypeEventSinks           ArtifactType = "event-sinks"
+	ArtifactTypeEscalationLadders    ArtifactType = "escalation-ladders"
+	ArtifactTypeHooks                ArtifactType = "hooks"
+	ArtifactTypeRemediationPolicy    ArtifactType = "remediation-policy"
+)
+
+// ArtifactTypes are all the artifact types.
+var ArtifactTypes = []ArtifactType{
+	ArtifactTypePluginSpecs,
+	ArtifactTypeInfinibandEvaluation,
+	ArtifactTypeKmsgMatchers,
+	ArtifactTypeLLDPCablingMap,
+	ArtifactTypeErrorBudgets,
+	ArtifactTypeEventSinks,
+	ArtifactTypeEscalationLadders,
+	ArtifactTypeHooks,
+	ArtifactTypeRemediationPolicy,
+}
+
+// ParseArtifactType parses the artifact type, returning an error if unknown.
+func ParseArtifactType(s string) (ArtifactType, error) {
+	for _, t := range ArtifactTypes {
+		if string(t) == s {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unknown artifact type %q (must be one of %q)", s, ArtifactTypes)
+}
+
+// ArtifactKey is the private key to sign the artifacts with.
+type ArtifactKey struct {
+	k ed25519.PrivateKey
+}
+
+// GenerateArtifactKey generates a new artifact key pair and encodes it as PEM.
+func GenerateArtifactKey() (priv, pub []byte, err error) {
+	pub, priv, err = ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{
+			Type:  pemTypeArtifactPrivate,
+			Bytes: priv,
+		}), pem.EncodeToMemory(&pem.Block{
+			Type:  pemTypeArtifactPublic,
+			Bytes: pub,
+		}), nil
+}
+
+// ParseArtifactKey parses the PEM-encoded private artifact key. The key must be
+// in the same format as returned by GenerateArtifactKey.
+func ParseArtifactKey(privKey []byte) (*ArtifactKey, error) {
+	k, err := parsePrivateKey(privKey, pemTypeArtifactPrivate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse artifact key: %w", err)
+	}
+	return &ArtifactKey{k: k}, nil
+}
+
+// ParseArtifactKeyBundle parses the bundle of PEM-encoded public artifact keys.
+func ParseArtifactKeyBundle(bundle []byte) ([]ed25519.PublicKey, error) {
+	return parsePublicKeyBundle(bundle, pemTypeArtifactPublic)
+}
+
+// ParseArtifactKeyBundleAt parses the bundle of PEM-encoded public artifact keys,
+// excluding the keys retired at the time (see NotAfterHeader).
+func ParseArtifactKeyBundleAt(bundle []byte, now time.Time) ([]ed25519.PublicKey, error) {
+	return parseKeyBundleAt(bundle, pemTypeArtifactPublic, now)
+}
+
+// artifactMessage returns the signed message of the artifact: the domain separation
+// prefix with the artifact type, followed by the hash and the length of the artifact.
+func artifactMessage(typ ArtifactType, data []byte) []byte {
+	hash := blake2s.Sum256(data)
+	msg := append([]byte(artifactSignaturePrefix+string(typ)), 0)
+	msg = append(msg, hash[:]...)
+	return binary.LittleEndian.AppendUint64(msg, uint64(len(data)))
+}
+
+// SignArtifact signs the artifact of the type (e.g., the plugin specs,
+// the config files) with the artifact key.
+func SignArtifact(key *ArtifactKey, typ ArtifactType, data []byte) ([]byte, error) {
+	if typ == "" {
+		return nil, errors.New("artifact type is required")
+	}
+	if len(data) == 0 {
+		return nil, errors.New("artifact is empty")
+	}
+	return ed25519.Sign(key.k, artifactMessage(typ, data)), nil
+}
+
+// VerifyArtifact verifies the artifact signature of the type with any of the artifact keys.
+func VerifyArtifact(keys []ed25519.PublicKey, typ ArtifactType, data []byte, sig []byte) error {
+	if !VerifyAny(keys, artifactMessage(typ, data), sig) {
+		return ErrInvalidArtifactSignature
+	}
+	return nil
+}
+
+// VerifyArtifactFile verifies the artifact file of the type with its detached signature file,
+// and returns the verified content. Leave the signature path empty to use
+// the artifact path with the SignatureSuffix.
+func VerifyArtifactFile(keys []ed25519.PublicKey, typ ArtifactType, path string, sigPath string) ([]byte, error) {
+	if sigPath == "" {
+		sigPath = path + SignatureSuffix
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := os.ReadFile(sigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature of %q: %w", path, err)
+	}
+	if err := VerifyArtifact(keys, typ, data, sig); err != nil {
+		return nil, fmt.Errorf("%q: %w", path, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,108 @@
+package distsign
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2s"
+)
+
+func TestVerifyArtifactFile(t *testing.T) {
+	priv, pub, err := GenerateArtifactKey()
+	require.NoError(t, err)
+	key, err := ParseArtifactKey(priv)
+	require.NoError(t, err)
+	keys, err := ParseArtifactKeyBundle(pub)
+	require.NoError(t, err)
+
+	_, otherPub, err := GenerateArtifactKey()
+	require.NoError(t, err)
+	otherKeys, err := ParseArtifactKeyBundle(otherPub)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plugins.yaml")
+	data := []byte("- plugin_name: nv-peermem\n")
+	require.NoError(t, os.WriteFile(path, data, 0644))
+
+	sig, err := SignArtifact(key, ArtifactTypePluginSpecs, data)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path+SignatureSuffix, sig, 0644))
+
+	verified, err := VerifyArtifactFile(keys, ArtifactTypePluginSpecs, path, "")
+	require.NoError(t, err)
+	assert.Equal(t, data, verified)
+
+	// signed with the other key
+	_, err = VerifyArtifactFile(otherKeys, ArtifactTypePluginSpecs, path, "")
+	assert.ErrorIs(t, err, ErrInvalidArtifactSignature)
+
+	// signed as another artifact type
+	_, err = VerifyArtifactFile(keys, ArtifactTypeHooks, path, "")
+	assert.ErrorIs(t, err, ErrInvalidArtifactSignature)
+
+	// the explicit signature path
+	sigPath := filepath.Join(dir, "sig")
+	require.NoError(t, os.WriteFile(sigPath, sig, 0644))
+	_, err = VerifyArtifactFile(keys, ArtifactTypePluginSpecs, path, sigPath)
+	assert.NoError(t, err)
+
+	// tampered
+	require.NoError(t, os.WriteFile(path, []byte("- plugin_name: evil\n"), 0644))
+	_, err = VerifyArtifactFile(keys, ArtifactTypePluginSpecs, path, "")
+	assert.ErrorIs(t, err, ErrInvalidArtifactSignature)
+
+	// no signature
+	require.NoError(t, os.Remove(path+SignatureSuffix))
+	_, err = VerifyArtifactFile(keys, ArtifactTypePluginSpecs, path, "")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	// the empty artifact cannot be signed
+	_, err = SignArtifact(key, ArtifactTypePluginSpecs, nil)
+	assert.Error(t, err)
+	_, err = SignArtifact(key, "", data)
+	assert.Error(t, err)
+}
+
+func TestArtifactKeyRole(t *testing.T) {
+	signPriv, signPub, err := GenerateSigningKey()
+	require.NoError(t, err)
+
+	// the package signing keys are not artifact keys
+	_, err = ParseArtifactKey(signPriv)
+	assert.Error(t, err)
+	_, err = ParseArtifactKeyBundle(signPub)
+	assert.Error(t, err)
+
+	priv, pub, err := GenerateArtifactKey()
+	require.NoError(t, err)
+	_, err = ParseSigningKey(priv)
+	assert.Error(t, err)
+	_, err = ParseSigningKeyBundle(pub)
+	assert.Error(t, err)
+
+	// the package signature with the same key bytes is not an artifact signature
+	key, err := ParseArtifactKey(priv)
+	require.NoError(t, err)
+	keys, err := ParseArtifactKeyBundle(pub)
+	require.NoError(t, err)
+
+	data := []byte("- plugin_name: nv-peermem\n")
+	hash := blake2s.Sum256(data)
+	pkgSig, err := (&SigningKey{k: key.k}).SignPackageHash(hash[:], int64(len(data)))
+	require.NoError(t, err)
+	assert.ErrorIs(t, VerifyArtifact(keys, ArtifactTypePluginSpecs, data, pkgSig), ErrInvalidArtifactSignature)
+}
+
+func TestParseArtifactType(t *testing.T) {
+	for _, typ := range ArtifactTypes {
+		parsed, err := ParseArtifactType(string(typ))
+		require.NoError(t, err)
+		assert.Equal(t, typ, parsed)
+	}
+	_, err := ParseArtifactType("unknown")
+	assert.Error(t, err)
+}
@@ -35,6 +35,11 @@
 // The signing public keys are fetched by the client dynamically before every
 // download and can be rotated more readily, assuming that most deployed
 // clients trust the root keys used to issue fresh signing keys.
+//
+// To rotate the signing keys, RootKey.RotateSigningKeys issues a new signing key
+// and a transition bundle, where the current signing keys carry the Not-After
+// header. The clients accept both keys until the current keys retire, so the
+// packages signed with either key are downloaded during the overlap.
 package distsign
 
 import (
@@ -187,6 +192,8 @@ type Client struct {
 	logf     logger.Logf
 	roots    []ed25519.PublicKey
 	pkgsAddr *url.URL
+	// pin rejects the signing key bundles older than the latest seen, if set
+	pin *KeyBundlePin
 }
 
 // NewClient returns a new client for distribution server located at pkgsAddr,
@@ -202,6 +209,12 @@ func NewClient(logf logger.Logf, pkgsAddr string) (*Client, error) {
 	return &Client{logf: logf, roots: roots(), pkgsAddr: u}, nil
 }
 
+// SetKeyBundlePin sets the pin of the latest signing key bundle seen,
+// to reject the older bundles served afterwards (see KeyBundlePin).
+func (c *Client) SetKeyBundlePin(pin *KeyBundlePin) {
+	c.pin = pin
+}
+
 func (c *Client) url(path string) string {
 	return c.pkgsAddr.JoinPath(path).String()
 }
@@ -308,7 +321,12 @@ func (c *Client) signingKeys() ([]ed25519.PublicKey, error) {
 		return nil, fmt.Errorf("signature %q for key %q does not validate with any known root key; either you are under attack, or running a very old version of Tailscale with outdated root keys", sigURL, keyURL)
 	}
 
-	keys, err := ParseSigningKeyBundle(raw)
+	// the retiring keys in the transition bundle are only accepted until they retire
+	parse := ParseSigningKeyBundleAt
+	if c.pin != nil {
+		parse = c.pin.ParseSigningKeyBundleAt
+	}
+	keys, err := parse(raw, time.Now())
 	if err != nil {
 		return nil, fmt.Errorf("cannot parse signing key bundle from %q: %w", keyURL, err)
 	}
@@ -0,0 +1,246 @@
+package distsign
+
+import (
+	"crypto/ed25519"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// NotAfterHeader is the PEM header of the public signing key in the transition
+// bundle, after which the retiring key is no longer accepted by the clients.
+// The header is covered by the root key signature of the bundle.
+const NotAfterHeader = "Not-After"
+
+// IssuedAtHeader is the PEM header of the new public signing key in the transition
+// bundle, the time the bundle was issued. The clients pin the latest issue time seen
+// (see KeyBundlePin), and reject the older bundles served afterwards (e.g., the bundle
+// before the rotation, still accepting the retired keys without the NotAfterHeader).
+// The header is covered by the root key signature of the bundle.
+const IssuedAtHeader = "Issued-At"
+
+// ErrKeyBundleRolledBack is returned when the signing key bundle was issued before
+// the latest bundle seen by the client.
+var ErrKeyBundleRolledBack = errors.New("signing key bundle issued before the latest bundle seen")
+
+// DefaultRotationOverlap is the default window during which the clients accept
+// both the new and the retiring signing keys, long enough for the nodes updating
+// at their own pace to fetch the packages signed with either key.
+const DefaultRotationOverlap = 30 * 24 * time.Hour
+
+// RotateSigningKeys issues a new signing key to replace the current signing keys,
+// and returns the new private signing key, the transition bundle of the new public
+// signing key and the current public signing keys retiring after the overlap,
+// and the transition bundle signature with the root key.
+//
+// Publish the transition bundle and its signature as distsign.pub and
+// distsign.pub.sig, and sign the new packages with the new signing key.
+// The keys already retired at the time are dropped from the transition bundle,
+// and the keys retiring earlier keep their retirement time.
+func (r *RootKey) RotateSigningKeys(currentBundle []byte, now time.Time, overlap time.Duration) (priv, bundle, sig []byte, err error) {
+	if overlap <= 0 {
+		return nil, nil, nil, fmt.Errorf("overlap must be positive, got %v", overlap)
+	}
+	blocks, err := parseSigningKeyBlocks(currentBundle)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	priv, pub, err := GenerateSigningKey()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// the issue time is always after the issue time of the current bundle,
+	// so the clients that pinned the current bundle accept the new one
+	issuedAt := now.UTC().Truncate(time.Second)
+	if current := bundleIssuedAt(blocks); !issuedAt.After(current) {
+		issuedAt = current.Add(time.Second)
+	}
+	newBlock, _ := pem.Decode(pub)
+	newBlock.Headers = map[string]string{IssuedAtHeader: issuedAt.Format(time.RFC3339)}
+
+	notAfter := now.Add(overlap).UTC()
+	bundle = pem.EncodeToMemory(newBlock)
+	for _, b := range blocks {
+		retireAt, retiring := notAfterOf(b)
+		if retiring && !now.Before(retireAt) {
+			continue
+		}
+		// the retiring keys no longer carry their issue time,
+		// since the bundle issue time is that of the new key
+		if !retiring || notAfter.Before(retireAt) {
+			retireAt = notAfter
+		}
+		b.Headers = map[string]string{NotAfterHeader: retireAt.Format(time.RFC3339)}
+		bundle = append(bundle, pem.EncodeToMemory(b)...)
+	}
+
+	sig, err = r.SignSigningKeys(bundle)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return priv, bundle, sig, nil
+}
+
+// ParseSigningKeyBundleAt parses the bundle of PEM-encoded public signing keys,
+// excluding the keys retired at the time (see NotAfterHeader).
+func ParseSigningKeyBundleAt(bundle []byte, now time.Time) ([]ed25519.PublicKey, error) {
+	return parseKeyBundleAt(bundle, pemTypeSigningPublic, now)
+}
+
+func parseKeyBundleAt(bundle []byte, typeTag string, now time.Time) ([]ed25519.PublicKey, error) {
+	blocks, err := parseKeyBlocks(bundle, typeTag)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []ed25519.PublicKey
+	for _, b := range blocks {
+		if retireAt, retiring := notAfterOf(b); retiring && !now.Before(retireAt) {
+			continue
+		}
+		keys = append(keys, ed25519.PublicKey(b.Bytes))
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("all keys in the bundle have been retired")
+	}
+	return keys, nil
+}
+
+// parseSigningKeyBlocks parses the bundle of PEM-encoded public signing keys,
+// keeping the PEM headers.
+func parseSigningKeyBlocks(bundle []byte) ([]*pem.Block, error) {
+	return parseKeyBlocks(bundle, pemTypeSigningPublic)
+}
+
+// parseKeyBlocks parses the bundle of PEM-encoded public keys of the type,
+// keeping the PEM headers.
+func parseKeyBlocks(bundle []byte, typeTag string) ([]*pem.Block, error) {
+	var blocks []*pem.Block
+	for len(bundle) > 0 {
+		b, rest := pem.Decode(bundle)
+		if b == nil {
+			return nil, errors.New("failed to decode PEM data")
+		}
+		if b.Type != typeTag {
+			return nil, fmt.Errorf("PEM type is %q, want %q", b.Type, typeTag)
+		}
+		if len(b.Bytes) != ed25519.PublicKeySize {
+			return nil, errors.New("public key has incorrect length for an Ed25519 public key")
+		}
+		for _, h := range []string{NotAfterHeader, IssuedAtHeader} {
+			if v, ok := b.Headers[h]; ok {
+				if _, err := time.Parse(time.RFC3339, v); err != nil {
+					return nil, fmt.Errorf("invalid %s header %q: %w", h, v, err)
+				}
+			}
+		}
+		blocks = append(blocks, b)
+		bundle = rest
+	}
+	if len(blocks) == 0 {
+		return nil, errors.New("no signing keys found in the bundle")
+	}
+	return blocks, nil
+}
+
+// bundleIssuedAt returns the latest issue time of the keys in the bundle,
+// and the zero time for the bundles issued before the IssuedAtHeader.
+func bundleIssuedAt(blocks []*pem.Block) time.Time {
+	var latest time.Time
+	for _, b := range blocks {
+		v, ok := b.Headers[IssuedAtHeader]
+		if !ok {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			continue
+		}
+		if t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
+}
+
+// KeyBundlePin is the local file pinning the latest issue time of the signing
+// key bundles seen (see IssuedAtHeader), so that the older bundles are rejected
+// even though signed by the root keys, and the retired keys are not accepted
+// with the clock set back before the pinned issue time.
+type KeyBundlePin struct {
+	path string
+}
+
+// NewKeyBundlePin returns the pin persisted in the file.
+func NewKeyBundlePin(path string) *KeyBundlePin {
+	return &KeyBundlePin{path: path}
+}
+
+// Pinned returns the pinned issue time, and the zero time if none pinned yet.
+func (p *KeyBundlePin) Pinned() (time.Time, error) {
+	b, err := os.ReadFile(p.path)
+	if os.IsNotExist(err) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(string(b)))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid pinned issue time in %q: %w", p.path, err)
+	}
+	return t, nil
+}
+
+// ParseSigningKeyBundleAt parses the bundle of the PEM-encoded public signing keys
+// issued no earlier than the pinned issue time, and pins the issue time of the bundle
+// if later. The keys are excluded if retired at the time, or at the pinned issue time
+// if later (e.g., the clock set back).
+func (p *KeyBundlePin) ParseSigningKeyBundleAt(bundle []byte, now time.Time) ([]ed25519.PublicKey, error) {
+	blocks, err := parseSigningKeyBlocks(bundle)
+	if err != nil {
+		return nil, err
+	}
+	pinned, err := p.Pinned()
+	if err != nil {
+		return nil, err
+	}
+
+	issuedAt := bundleIssuedAt(blocks)
+	if issuedAt.Before(pinned) {
+		return nil, fmt.Errorf("%w (issued at %s, pinned %s)", ErrKeyBundleRolledBack, issuedAt.Format(time.RFC3339), pinned.Format(time.RFC3339))
+	}
+	if now.Before(pinned) {
+		now = pinned
+	}
+	keys, err := ParseSigningKeyBundleAt(bundle, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if issuedAt.After(pinned) {
+		if err := os.WriteFile(p.path, []byte(issuedAt.Format(time.RFC3339)+"\n"), 0644); err != nil {
+			return nil, fmt.Errorf("failed to pin signing key bundle issue time: %w", err)
+		}
+	}
+	return keys, nil
+}
+
+// notAfterOf returns the retirement time of the public signing key,
+// and false if the key is not retiring.
+func notAfterOf(b *pem.Block) (time.Time, bool) {
+	v, ok := b.Headers[NotAfterHeader]
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
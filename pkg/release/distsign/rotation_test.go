@@ -0,0 +1,191 @@
+package distsign
+
+import (
+	"context"
+	"encoding/pem"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2s"
+)
+
+func TestRotateSigningKeys(t *testing.T) {
+	srv := newTestServer(t)
+	c := srv.client(t)
+	ctx := context.Background()
+
+	srv.addSigned("hello", []byte("world"))
+	require.NoError(t, c.Download(ctx, "hello", filepath.Join(t.TempDir(), "hello")))
+
+	now := time.Now()
+	newPriv, bundle, sig, err := srv.roots[0].RotateSigningKeys(srv.files["distsign.pub"], now, time.Hour)
+	require.NoError(t, err)
+	newKey, err := ParseSigningKey(newPriv)
+	require.NoError(t, err)
+
+	keys, err := ParseSigningKeyBundle(bundle)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+
+	// the transition bundle accepts both keys during the overlap
+	srv.files["distsign.pub"] = bundle
+	srv.files["distsign.pub.sig"] = sig
+	require.NoError(t, c.Download(ctx, "hello", filepath.Join(t.TempDir(), "hello")))
+
+	hash := blake2s.Sum256([]byte("world"))
+	newSig, err := newKey.SignPackageHash(hash[:], int64(len("world")))
+	require.NoError(t, err)
+	srv.files["hello.sig"] = newSig
+	require.NoError(t, c.Download(ctx, "hello", filepath.Join(t.TempDir(), "hello")))
+
+	// the retiring key is no longer accepted after the overlap
+	_, bundle, sig, err = srv.roots[0].RotateSigningKeys(srv.files["distsign.pub"], now.Add(-2*time.Hour), time.Minute)
+	require.NoError(t, err)
+	keys, err = ParseSigningKeyBundleAt(bundle, now)
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+
+	srv.files["distsign.pub"] = bundle
+	srv.files["distsign.pub.sig"] = sig
+	assert.Error(t, c.Download(ctx, "hello", filepath.Join(t.TempDir(), "hello")))
+
+	_, _, _, err = srv.roots[0].RotateSigningKeys(srv.files["distsign.pub"], now, 0)
+	assert.Error(t, err)
+}
+
+func TestRotateSigningKeysKeepsEarlierRetirement(t *testing.T) {
+	root := newRootKeyPair(t)
+	current := newSigningKeyPair(t)
+
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	_, bundle, _, err := root.RotateSigningKeys(current.pubRaw, now, 24*time.Hour)
+	require.NoError(t, err)
+
+	// rotating again before the first overlap ends
+	_, bundle, _, err = root.RotateSigningKeys(bundle, now.Add(time.Hour), 48*time.Hour)
+	require.NoError(t, err)
+
+	blocks, err := parseSigningKeyBlocks(bundle)
+	require.NoError(t, err)
+	require.Len(t, blocks, 3)
+	assert.Equal(t, map[string]string{IssuedAtHeader: now.Add(time.Hour).Format(time.RFC3339)}, blocks[0].Headers)
+	assert.Equal(t, now.Add(49*time.Hour).Format(time.RFC3339), blocks[1].Headers[NotAfterHeader])
+	assert.Equal(t, now.Add(24*time.Hour).Format(time.RFC3339), blocks[2].Headers[NotAfterHeader])
+
+	// the retired keys are dropped
+	_, bundle, _, err = root.RotateSigningKeys(bundle, now.Add(30*time.Hour), time.Hour)
+	require.NoError(t, err)
+	blocks, err = parseSigningKeyBlocks(bundle)
+	require.NoError(t, err)
+	assert.Len(t, blocks, 3)
+}
+
+func TestParseSigningKeyBundleAt(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	active := newSigningKeyPair(t)
+	retired := newSigningKeyPair(t)
+
+	b, _ := pem.Decode(retired.pubRaw)
+	b.Headers = map[string]string{NotAfterHeader: now.Format(time.RFC3339)}
+	retiredRaw := pem.EncodeToMemory(b)
+
+	keys, err := ParseSigningKeyBundleAt(append(append([]byte{}, active.pubRaw...), retiredRaw...), now)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+
+	// still accepted before the retirement
+	keys, err = ParseSigningKeyBundleAt(append(append([]byte{}, active.pubRaw...), retiredRaw...), now.Add(-time.Second))
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+
+	_, err = ParseSigningKeyBundleAt(retiredRaw, now)
+	assert.Error(t, err)
+
+	b.Headers = map[string]string{NotAfterHeader: "tomorrow"}
+	_, err = ParseSigningKeyBundleAt(pem.EncodeToMemory(b), now)
+	assert.Error(t, err)
+}
+
+func TestRotateSigningKeysIssuedAt(t *testing.T) {
+	root := newRootKeyPair(t)
+	current := newSigningKeyPair(t)
+
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	_, bundle, _, err := root.RotateSigningKeys(current.pubRaw, now, 24*time.Hour)
+	require.NoError(t, err)
+	blocks, err := parseSigningKeyBlocks(bundle)
+	require.NoError(t, err)
+	assert.Equal(t, now, bundleIssuedAt(blocks))
+	assert.NotContains(t, blocks[1].Headers, IssuedAtHeader)
+
+	// rotating with the clock behind the current bundle still issues a later bundle
+	_, bundle, _, err = root.RotateSigningKeys(bundle, now.Add(-time.Hour), 48*time.Hour)
+	require.NoError(t, err)
+	blocks, err = parseSigningKeyBlocks(bundle)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Second), bundleIssuedAt(blocks))
+
+	// the legacy bundle without the issue time
+	blocks, err = parseSigningKeyBlocks(current.pubRaw)
+	require.NoError(t, err)
+	assert.True(t, bundleIssuedAt(blocks).IsZero())
+}
+
+func TestKeyBundlePin(t *testing.T) {
+	root := newRootKeyPair(t)
+	legacy := newSigningKeyPair(t)
+
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	_, first, _, err := root.RotateSigningKeys(legacy.pubRaw, now, time.Hour)
+	require.NoError(t, err)
+	_, second, _, err := root.RotateSigningKeys(first, now.Add(24*time.Hour), time.Hour)
+	require.NoError(t, err)
+
+	pin := NewKeyBundlePin(filepath.Join(t.TempDir(), "distsign.pub.pin"))
+	pinned, err := pin.Pinned()
+	require.NoError(t, err)
+	assert.True(t, pinned.IsZero())
+
+	// nothing pinned yet, the legacy bundle is accepted without pinning
+	keys, err := pin.ParseSigningKeyBundleAt(legacy.pubRaw, now)
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+	pinned, err = pin.Pinned()
+	require.NoError(t, err)
+	assert.True(t, pinned.IsZero())
+
+	keys, err = pin.ParseSigningKeyBundleAt(first, now)
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+	pinned, err = pin.Pinned()
+	require.NoError(t, err)
+	assert.Equal(t, now, pinned)
+
+	// the legacy bundle still accepting the retiring key is rejected once pinned
+	_, err = pin.ParseSigningKeyBundleAt(legacy.pubRaw, now)
+	assert.ErrorIs(t, err, ErrKeyBundleRolledBack)
+
+	keys, err = pin.ParseSigningKeyBundleAt(second, now.Add(24*time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+
+	// rolled back to the first bundle
+	_, err = pin.ParseSigningKeyBundleAt(first, now.Add(24*time.Hour))
+	assert.ErrorIs(t, err, ErrKeyBundleRolledBack)
+
+	// the clock set back before the pinned issue time does not revive the retired keys
+	_, third, _, err := root.RotateSigningKeys(second, now.Add(48*time.Hour), 24*time.Hour)
+	require.NoError(t, err)
+	keys, err = pin.ParseSigningKeyBundleAt(third, now.Add(48*time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+	keys, err = pin.ParseSigningKeyBundleAt(third, now)
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+	keys, err = pin.ParseSigningKeyBundleAt(third, now.Add(73*time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+}
@@ -0,0 +1,231 @@
+// Package remediation performs the suggested repair actions of the components
+// automatically, only as allowed by the local policy.
+package remediation
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	pkgdisruption "github.com/leptonai/gpud/pkg/disruption"
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/maintenance"
+)
+
+// DefaultEvaluateInterval is the default interval to evaluate the component health states.
+const DefaultEvaluateInterval = time.Minute
+
+// Executor performs the repair action for the unhealthy state of the component.
+type Executor func(ctx context.Context, component string, state apiv1.HealthState) error
+
+// DecisionType is the outcome of the policy evaluation for a suggested action.
+type DecisionType string
+
+const (
+	// DecisionTypeSuggested means the action is not allowed by the policy, thus only suggested.
+	DecisionTypeSuggested DecisionType = "suggested"
+	// DecisionTypeCooldown means the action is allowed but skipped within the cooldown.
+	DecisionTypeCooldown DecisionType = "cooldown"
+	// DecisionTypeNoExecutor means the action is allowed but gpud cannot perform it.
+	DecisionTypeNoExecutor DecisionType = "no-executor"
+	// DecisionTypeDeferred means the action is allowed but deferred while the jobs are running.
+	DecisionTypeDeferred DecisionType = "deferred"
+	// DecisionTypeExecuted means the action is performed (see the error for the result).
+	DecisionTypeExecuted DecisionType = "executed"
+	// DecisionTypeExpectedDisruption means the action is allowed but skipped,
+	// since the component is inside a window of the expected disruption.
+	DecisionTypeExpectedDisruption DecisionType = "expected-disruption"
+	// DecisionTypeNotPersisted means the action is allowed but skipped,
+	// since its cooldown cannot be persisted across the restarts.
+	DecisionTypeNotPersisted DecisionType = "not-persisted"
+)
+
+// Decision is the policy evaluation result for a suggested action.
+type Decision struct {
+	Component    string                 `json:"component"`
+	FailureClass string                 `json:"failure_class"`
+	Action       apiv1.RepairActionType `json:"action"`
+	Type         DecisionType           `json:"type"`
+	// Reason is why the action is deferred or forced on the busy node, if any.
+	Reason string `json:"reason,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Engine evaluates the suggested actions of the unhealthy components against
+// the policy, and performs the allowed ones with the registered executors.
+type Engine struct {
+	registry  components.Registry
+	policy    *Policy
+	executors map[apiv1.RepairActionType]Executor
+	// deferrer is nil if the actions are never deferred
+	deferrer *maintenance.Deferrer
+	// disruptions is nil if no disruption is expected
+	disruptions *pkgdisruption.Windows
+
+	mu sync.Mutex
+	// last automatic action time per component and failure class
+	last map[string]time.Time
+	// dbRW persists the last action times if not nil (see LoadLastActions)
+	dbRW *sql.DB
+
+	nowFunc func() time.Time
+}
+
+// NewEngine creates a new remediation engine.
+func NewEngine(registry components.Registry, policy *Policy) *Engine {
+	return &Engine{
+		registry:  registry,
+		policy:    policy,
+		executors: make(map[apiv1.RepairActionType]Executor),
+		last:      make(map[string]time.Time),
+		nowFunc:   time.Now,
+	}
+}
+
+// RegisterExecutor registers the executor of the repair action.
+func (e *Engine) RegisterExecutor(action apiv1.RepairActionType, exec Executor) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.executors[action] = exec
+}
+
+// SetDeferrer defers the actions while the jobs are running on the node,
+// up to the maximum deferral of the deferrer.
+func (e *Engine) SetDeferrer(d *maintenance.Deferrer) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.deferrer = d
+}
+
+// SetDisruptions skips the actions of the components inside the windows of
+// the expected disruption (e.g., the reboot during the planned fabric upgrade),
+// and performs them after the windows if the components are still unhealthy.
+func (e *Engine) SetDisruptions(ws *pkgdisruption.Windows) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.disruptions = ws
+}
+
+// Start evaluates the component health states in the background
+// until the context is canceled.
+func (e *Engine) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			for _, d := range e.Evaluate(ctx) {
+				if d.Type == DecisionTypeSuggested {
+					continue
+				}
+				log.Logger.Infow("remediation decision", "component", d.Component, "failureClass", d.FailureClass, "action", d.Action, "decision", d.Type, "reason", d.Reason, "error", d.Error)
+			}
+		}
+	}()
+}
+
+// Evaluate evaluates the suggested actions of all the components once,
+// and performs the ones allowed by the policy outside the cooldown.
+func (e *Engine) Evaluate(ctx context.Context) []Decision {
+	var decisions []Decision
+	for _, comp := range e.registry.All() {
+		for _, st := range comp.LastHealthStates() {
+			if st.Health == apiv1.HealthStateTypeHealthy || st.SuggestedActions == nil {
+				continue
+			}
+			// never act on the simulated failures
+			if st.ExtraInfo[apiv1.SyntheticExtraInfoKey] == "true" {
+				continue
+			}
+			for _, act := range st.SuggestedActions.RepairActions {
+				decisions = append(decisions, e.decide(ctx, comp.Name(), st, act))
+			}
+		}
+	}
+	return decisions
+}
+
+func (e *Engine) decide(ctx context.Context, component string, st apiv1.HealthState, action apiv1.RepairActionType) Decision {
+	d := Decision{
+		Component:    component,
+		FailureClass: st.Name,
+		Action:       action,
+	}
+
+	rule := e.policy.Find(component, st.Name, action)
+	if rule == nil {
+		d.Type = DecisionTypeSuggested
+		return d
+	}
+
+	e.mu.Lock()
+	exec, ok := e.executors[action]
+	key := component + "/" + st.Name
+	now := e.nowFunc()
+	if id := e.disruptions.Match(component, now); id != "" {
+		e.mu.Unlock()
+		d.Type = DecisionTypeExpectedDisruption
+		d.Reason = "expected disruption window " + id
+		return d
+	}
+	if last, seen := e.last[key]; seen && now.Sub(last) < rule.Cooldown.Duration {
+		e.mu.Unlock()
+		d.Type = DecisionTypeCooldown
+		return d
+	}
+	if !ok {
+		e.mu.Unlock()
+		d.Type = DecisionTypeNoExecutor
+		return d
+	}
+	deferrer := e.deferrer
+	e.mu.Unlock()
+
+	// the busy signals may take a while, thus checked outside the lock
+	md := deferrer.Decide(ctx, "remediation/"+key)
+	if !md.Proceed {
+		d.Type = DecisionTypeDeferred
+		d.Reason = md.Reason
+		return d
+	}
+	if md.Forced {
+		d.Reason = "forced after max deferral: " + md.Reason
+	}
+
+	// start the cooldown before executing, so that a failing action is not retried in a loop,
+	// and persist it, so that a rebooting action is not retried in a loop across the reboots
+	e.mu.Lock()
+	prev, seen := e.last[key]
+	e.last[key] = now
+	err := e.persistLastActionsLocked(ctx)
+	if err != nil {
+		if seen {
+			e.last[key] = prev
+		} else {
+			delete(e.last, key)
+		}
+	}
+	e.mu.Unlock()
+	if err != nil {
+		d.Type = DecisionTypeNotPersisted
+		d.Error = fmt.Sprintf("failed to persist the cooldown: %v", err)
+		return d
+	}
+
+	log.Logger.Warnw("performing repair action allowed by policy", "component", component, "failureClass", st.Name, "action", action, "reason", st.Reason)
+	d.Type = DecisionTypeExecuted
+	if err := exec(ctx, component, st); err != nil {
+		d.Error = fmt.Sprintf("failed to perform %s: %v", action, err)
+	}
+	return d
+}
@@ -0,0 +1,257 @@
+package remediation
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	pkgdisruption "github.com/leptonai/gpud/pkg/disruption"
+	"github.com/leptonai/gpud/pkg/maintenance"
+)
+
+type mockComponent struct {
+	components.Component
+
+	name   string
+	states apiv1.HealthStates
+}
+
+func (c *mockComponent) Name() string { return c.name }
+
+func (c *mockComponent) LastHealthStates() apiv1.HealthStates { return c.states }
+
+func TestLoadPolicy(t *testing.T) {
+	dir := t.TempDir()
+
+	file := filepath.Join(dir, "policy.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+rules:
+- component: accelerator-nvidia-fallen-off-bus
+  actions: [REBOOT_SYSTEM]
+  cooldown: 6h
+- component: disk
+  failure_class: disk
+  actions: [HARDWARE_INSPECTION]
+`), 0644))
+	p, err := LoadPolicy(file)
+	require.NoError(t, err)
+	require.Len(t, p.Rules, 2)
+	assert.Equal(t, 6*time.Hour, p.Rules[0].Cooldown.Duration)
+
+	assert.NotNil(t, p.Find("accelerator-nvidia-fallen-off-bus", "any", apiv1.RepairActionTypeRebootSystem))
+	assert.Nil(t, p.Find("accelerator-nvidia-fallen-off-bus", "any", apiv1.RepairActionTypeHardwareInspection))
+	assert.NotNil(t, p.Find("disk", "disk", apiv1.RepairActionTypeHardwareInspection))
+	assert.Nil(t, p.Find("disk", "other", apiv1.RepairActionTypeHardwareInspection))
+	assert.Nil(t, p.Find("memory", "memory", apiv1.RepairActionTypeRebootSystem))
+
+	var nilPolicy *Policy
+	assert.Nil(t, nilPolicy.Find("disk", "disk", apiv1.RepairActionTypeHardwareInspection))
+
+	invalid := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte(`
+rules:
+- component: disk
+  actions: [FORMAT_DISK]
+`), 0644))
+	_, err = LoadPolicy(invalid)
+	assert.ErrorIs(t, err, ErrInvalidRuleAction)
+
+	require.NoError(t, os.WriteFile(invalid, []byte(`
+rules:
+- component: disk
+`), 0644))
+	_, err = LoadPolicy(invalid)
+	assert.ErrorIs(t, err, ErrRuleActionsRequired)
+
+	_, err = LoadPolicy(filepath.Join(dir, "not-found.yaml"))
+	assert.Error(t, err)
+}
+
+func TestEngineEvaluate(t *testing.T) {
+	comp := &mockComponent{
+		name: "accelerator-nvidia-fallen-off-bus",
+		states: apiv1.HealthStates{
+			{
+				Name:   "accelerator-nvidia-fallen-off-bus",
+				Health: apiv1.HealthStateTypeUnhealthy,
+				Reason: "GPU lost",
+				SuggestedActions: &apiv1.SuggestedActions{
+					RepairActions: []apiv1.RepairActionType{apiv1.RepairActionTypeRebootSystem, apiv1.RepairActionTypeHardwareInspection},
+				},
+			},
+		},
+	}
+	healthy := &mockComponent{
+		name:   "disk",
+		states: apiv1.HealthStates{{Name: "disk", Health: apiv1.HealthStateTypeHealthy}},
+	}
+	registry := components.NewRegistry(&components.GPUdInstance{})
+	for _, c := range []*mockComponent{comp, healthy} {
+		c := c
+		_, err := registry.Register(func(*components.GPUdInstance) (components.Component, error) { return c, nil })
+		require.NoError(t, err)
+	}
+
+	now := time.Now()
+	e := NewEngine(registry, &Policy{Rules: []Rule{
+		{
+			Component: "accelerator-nvidia-fallen-off-bus",
+			Actions:   []apiv1.RepairActionType{apiv1.RepairActionTypeRebootSystem},
+			Cooldown:  metav1.Duration{Duration: time.Hour},
+		},
+	}})
+	e.nowFunc = func() time.Time { return now }
+
+	ctx := context.Background()
+
+	// allowed but no executor
+	ds := e.Evaluate(ctx)
+	require.Len(t, ds, 2)
+	assert.Equal(t, DecisionTypeNoExecutor, ds[0].Type)
+	assert.Equal(t, DecisionTypeSuggested, ds[1].Type)
+	assert.Equal(t, apiv1.RepairActionTypeHardwareInspection, ds[1].Action)
+
+	executed := 0
+	e.RegisterExecutor(apiv1.RepairActionTypeRebootSystem, func(_ context.Context, component string, state apiv1.HealthState) error {
+		executed++
+		assert.Equal(t, "accelerator-nvidia-fallen-off-bus", component)
+		assert.Equal(t, "GPU lost", state.Reason)
+		return errors.New("reboot failed")
+	})
+
+	ds = e.Evaluate(ctx)
+	require.Len(t, ds, 2)
+	assert.Equal(t, DecisionTypeExecuted, ds[0].Type)
+	assert.Contains(t, ds[0].Error, "reboot failed")
+	assert.Equal(t, 1, executed)
+
+	// within the cooldown, even if the previous action failed
+	now = now.Add(30 * time.Minute)
+	ds = e.Evaluate(ctx)
+	assert.Equal(t, DecisionTypeCooldown, ds[0].Type)
+	assert.Equal(t, 1, executed)
+
+	now = now.Add(time.Hour)
+	ds = e.Evaluate(ctx)
+	assert.Equal(t, DecisionTypeExecuted, ds[0].Type)
+	assert.Equal(t, 2, executed)
+
+	// recovered
+	comp.states = apiv1.HealthStates{{Name: "accelerator-nvidia-fallen-off-bus", Health: apiv1.HealthStateTypeHealthy}}
+	assert.Empty(t, e.Evaluate(ctx))
+}
+
+func TestEngineEvaluateDeferred(t *testing.T) {
+	comp := &mockComponent{
+		name: "accelerator-nvidia-fallen-off-bus",
+		states: apiv1.HealthStates{
+			{
+				Name:   "accelerator-nvidia-fallen-off-bus",
+				Health: apiv1.HealthStateTypeUnhealthy,
+				Reason: "GPU lost",
+				SuggestedActions: &apiv1.SuggestedActions{
+					RepairActions: []apiv1.RepairActionType{apiv1.RepairActionTypeRebootSystem},
+				},
+			},
+		},
+	}
+	registry := components.NewRegistry(&components.GPUdInstance{})
+	_, err := registry.Register(func(*components.GPUdInstance) (components.Component, error) { return comp, nil })
+	require.NoError(t, err)
+
+	e := NewEngine(registry, &Policy{Rules: []Rule{
+		{
+			Component: "accelerator-nvidia-fallen-off-bus",
+			Actions:   []apiv1.RepairActionType{apiv1.RepairActionTypeRebootSystem},
+		},
+	}})
+	executed := 0
+	e.RegisterExecutor(apiv1.RepairActionTypeRebootSystem, func(context.Context, string, apiv1.HealthState) error {
+		executed++
+		return nil
+	})
+
+	busy := true
+	e.SetDeferrer(maintenance.NewDeferrer(time.Hour, func(context.Context) (bool, string, error) {
+		return busy, "1 pod(s) running on the GPUs (default/train-0)", nil
+	}))
+
+	ctx := context.Background()
+	ds := e.Evaluate(ctx)
+	require.Len(t, ds, 1)
+	assert.Equal(t, DecisionTypeDeferred, ds[0].Type)
+	assert.Equal(t, "1 pod(s) running on the GPUs (default/train-0)", ds[0].Reason)
+	assert.Equal(t, 0, executed)
+
+	busy = false
+	ds = e.Evaluate(ctx)
+	require.Len(t, ds, 1)
+	assert.Equal(t, DecisionTypeExecuted, ds[0].Type)
+	assert.Empty(t, ds[0].Reason)
+	assert.Equal(t, 1, executed)
+}
+
+func TestEngineEvaluateExpectedDisruption(t *testing.T) {
+	comp := &mockComponent{
+		name: "accelerator-nvidia-infiniband",
+		states: apiv1.HealthStates{
+			{
+				Name:   "accelerator-nvidia-infiniband",
+				Health: apiv1.HealthStateTypeUnhealthy,
+				Reason: "port down",
+				SuggestedActions: &apiv1.SuggestedActions{
+					RepairActions: []apiv1.RepairActionType{apiv1.RepairActionTypeRebootSystem},
+				},
+			},
+		},
+	}
+	registry := components.NewRegistry(&components.GPUdInstance{})
+	_, err := registry.Register(func(*components.GPUdInstance) (components.Component, error) { return comp, nil })
+	require.NoError(t, err)
+
+	now := time.Now()
+	e := NewEngine(registry, &Policy{Rules: []Rule{
+		{
+			Component: "accelerator-nvidia-infiniband",
+			Actions:   []apiv1.RepairActionType{apiv1.RepairActionTypeRebootSystem},
+		},
+	}})
+	e.nowFunc = func() time.Time { return now }
+	executed := 0
+	e.RegisterExecutor(apiv1.RepairActionTypeRebootSystem, func(context.Context, string, apiv1.HealthState) error {
+		executed++
+		return nil
+	})
+
+	ws := pkgdisruption.New()
+	w, err := ws.Add(now, apiv1.DisruptionWindow{
+		Components: []string{"accelerator-nvidia-infiniband"},
+		Start:      metav1.NewTime(now.Add(-time.Minute)),
+		End:        metav1.NewTime(now.Add(time.Hour)),
+	}, nil)
+	require.NoError(t, err)
+	e.SetDisruptions(ws)
+
+	ctx := context.Background()
+	ds := e.Evaluate(ctx)
+	require.Len(t, ds, 1)
+	assert.Equal(t, DecisionTypeExpectedDisruption, ds[0].Type)
+	assert.Equal(t, "expected disruption window "+w.ID, ds[0].Reason)
+	assert.Equal(t, 0, executed)
+
+	// still unhealthy after the window
+	now = now.Add(2 * time.Hour)
+	ds = e.Evaluate(ctx)
+	require.Len(t, ds, 1)
+	assert.Equal(t, DecisionTypeExecuted, ds[0].Type)
+	assert.Equal(t, 1, executed)
+}
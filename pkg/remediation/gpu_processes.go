@@ -0,0 +1,366 @@
+package remediation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/cgroup"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/log"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+)
+
+const (
+	// DefaultTerminateGracePeriod is the default duration to wait for the processes
+	// to exit after SIGTERM, before sending SIGKILL.
+	DefaultTerminateGracePeriod = 10 * time.Second
+
+	// BucketName is the name of the event bucket of the remediation records
+	// (e.g., the processes terminated on the GPUs before the reboot).
+	BucketName = "remediation"
+
+	// EventNameGPUProcessesTerminated is the name of the event
+	// that records the processes terminated on a GPU.
+	EventNameGPUProcessesTerminated = "gpu_processes_terminated"
+	// EventKeyTerminationRecord is the event extra info key of the JSON-encoded termination record.
+	EventKeyTerminationRecord = "termination_record"
+)
+
+// DefaultProtectedProcesses are the names of the system processes
+// that hold the GPUs but must never be killed by the remediation.
+var DefaultProtectedProcesses = []string{
+	"gpud",
+	"nvidia-persistenced",
+	"nv-hostengine",
+	"nv-fabricmanager",
+	"nvidia-fabricmanager",
+	"dcgm-exporter",
+	"Xorg",
+	"Xwayland",
+	"gnome-shell",
+}
+
+// ErrProtectedProcesses is returned when the GPU has the protected processes,
+// in which case the GPU cannot be reset without the operator.
+var ErrProtectedProcesses = errors.New("gpu has protected processes that are not terminated")
+
+// GPUProcess is a process running on the GPU,
+// resolved to its container and pod from the cgroup.
+type GPUProcess struct {
+	PID  int32  `json:"pid"`
+	Name string `json:"name,omitempty"`
+
+	cgroup.Process
+
+	// Signal is the last signal sent to the process (e.g., "SIGTERM", "SIGKILL").
+	Signal string `json:"signal,omitempty"`
+	// Error is the error of terminating the process, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// TerminationRecord records what was terminated on the GPU.
+type TerminationRecord struct {
+	GPUUUID string      `json:"gpu_uuid"`
+	Time    metav1.Time `json:"time"`
+	Reason  string      `json:"reason,omitempty"`
+
+	// Terminated are the processes that exited after the signals.
+	Terminated []GPUProcess `json:"terminated,omitempty"`
+	// Refused are the protected processes not signaled.
+	Refused []GPUProcess `json:"refused,omitempty"`
+	// Failed are the processes that failed to terminate.
+	Failed []GPUProcess `json:"failed,omitempty"`
+}
+
+// GPUProcessTerminator terminates the processes on a GPU (e.g., before a GPU reset),
+// refusing to kill the protected system processes, and records exactly what was terminated.
+type GPUProcessTerminator struct {
+	bucket      eventstore.Bucket
+	protected   map[string]struct{}
+	gracePeriod time.Duration
+
+	listGPUs func() []string
+	listPIDs func(uuid string) ([]uint32, error)
+	procRoot string
+	selfPID  int
+	kill     func(pid int, sig syscall.Signal) error
+	nowFunc  func() time.Time
+}
+
+// NewGPUProcessTerminator creates a new GPU process terminator.
+// Nil protected uses DefaultProtectedProcesses.
+// The termination records are inserted to the bucket, if not nil.
+func NewGPUProcessTerminator(nvmlInstance nvidianvml.Instance, bucket eventstore.Bucket, protected []string) *GPUProcessTerminator {
+	if protected == nil {
+		protected = DefaultProtectedProcesses
+	}
+	t := &GPUProcessTerminator{
+		bucket:      bucket,
+		protected:   make(map[string]struct{}, len(protected)),
+		gracePeriod: DefaultTerminateGracePeriod,
+		listGPUs: func() []string {
+			return listGPUUUIDs(nvmlInstance)
+		},
+		listPIDs: func(uuid string) ([]uint32, error) {
+			return listGPUPIDs(nvmlInstance, uuid)
+		},
+		procRoot: cgroup.DefaultProcRoot,
+		selfPID:  os.Getpid(),
+		kill:     syscall.Kill,
+		nowFunc:  time.Now,
+	}
+	for _, name := range protected {
+		t.protected[name] = struct{}{}
+	}
+	return t
+}
+
+func listGPUUUIDs(nvmlInstance nvidianvml.Instance) []string {
+	if nvmlInstance == nil || !nvmlInstance.NVMLExists() {
+		return nil
+	}
+	uuids := make([]string, 0, len(nvmlInstance.Devices()))
+	for uuid := range nvmlInstance.Devices() {
+		uuids = append(uuids, uuid)
+	}
+	sort.Strings(uuids)
+	return uuids
+}
+
+func listGPUPIDs(nvmlInstance nvidianvml.Instance, uuid string) ([]uint32, error) {
+	if nvmlInstance == nil || !nvmlInstance.NVMLExists() {
+		return nil, errors.New("nvml not loaded")
+	}
+	dev, ok := nvmlInstance.Devices()[uuid]
+	if !ok {
+		return nil, fmt.Errorf("gpu %q not found", uuid)
+	}
+
+	procs, ret := dev.GetComputeRunningProcesses()
+	if nvidianvml.IsGPULostError(ret) {
+		return nil, nvidianvml.ErrGPULost
+	}
+	if ret != nvml.SUCCESS {
+		return nil, fmt.Errorf("failed to get device compute processes: %v", nvml.ErrorString(ret))
+	}
+
+	pids := make([]uint32, 0, len(procs))
+	for _, p := range procs {
+		pids = append(pids, p.Pid)
+	}
+	return pids, nil
+}
+
+// Terminate terminates the processes on the GPU with SIGTERM, and SIGKILL
+// the ones still running after the grace period. The protected processes
+// are never signaled, and ErrProtectedProcesses is returned if any,
+// so that the caller does not proceed to reset the GPU.
+func (t *GPUProcessTerminator) Terminate(ctx context.Context, uuid string, reason string) (*TerminationRecord, error) {
+	pids, err := t.listPIDs(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := &TerminationRecord{
+		GPUUUID: uuid,
+		Time:    metav1.Time{Time: t.nowFunc().UTC()},
+		Reason:  reason,
+	}
+
+	var targets []GPUProcess
+	for _, pid := range pids {
+		p := t.resolve(int32(pid))
+		if t.isProtected(p) {
+			log.Logger.Warnw("refusing to terminate protected gpu process", "gpu", uuid, "pid", p.PID, "name", p.Name)
+			rec.Refused = append(rec.Refused, p)
+			continue
+		}
+		targets = append(targets, p)
+	}
+
+	t.signal(targets, syscall.SIGTERM)
+	targets = t.waitExit(ctx, rec, targets)
+
+	t.signal(targets, syscall.SIGKILL)
+	targets = t.waitExit(ctx, rec, targets)
+
+	for _, p := range targets {
+		if p.Error == "" {
+			p.Error = "process still running after SIGKILL"
+		}
+		rec.Failed = append(rec.Failed, p)
+	}
+
+	log.Logger.Warnw("terminated gpu processes", "gpu", uuid, "reason", reason, "terminated", len(rec.Terminated), "refused", len(rec.Refused), "failed", len(rec.Failed))
+	if err := t.record(ctx, rec); err != nil {
+		log.Logger.Errorw("failed to record gpu process termination", "gpu", uuid, "error", err)
+	}
+
+	if len(rec.Refused) > 0 {
+		return rec, fmt.Errorf("%w (%d on gpu %s)", ErrProtectedProcesses, len(rec.Refused), uuid)
+	}
+	if len(rec.Failed) > 0 {
+		return rec, fmt.Errorf("failed to terminate %d process(es) on gpu %s", len(rec.Failed), uuid)
+	}
+	return rec, nil
+}
+
+// TerminateAll terminates the processes on all the GPUs (e.g., before the reboot
+// or the driver upgrade), and returns the termination records of the GPUs.
+// The errors of the GPUs are joined, while the other GPUs are still terminated.
+func (t *GPUProcessTerminator) TerminateAll(ctx context.Context, reason string) ([]*TerminationRecord, error) {
+	var (
+		recs []*TerminationRecord
+		errs []error
+	)
+	for _, uuid := range t.listGPUs() {
+		rec, err := t.Terminate(ctx, uuid, reason)
+		if rec != nil {
+			recs = append(recs, rec)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return recs, errors.Join(errs...)
+}
+
+func (t *GPUProcessTerminator) isProtected(p GPUProcess) bool {
+	// never kill gpud itself or init
+	if int(p.PID) == t.selfPID || p.PID <= 1 {
+		return true
+	}
+	_, ok := t.protected[p.Name]
+	return ok
+}
+
+// signal sends the signal to the processes, and records the errors.
+func (t *GPUProcessTerminator) signal(targets []GPUProcess, sig syscall.Signal) {
+	name := "SIGTERM"
+	if sig == syscall.SIGKILL {
+		name = "SIGKILL"
+	}
+	for i := range targets {
+		targets[i].Signal = name
+		if err := t.kill(int(targets[i].PID), sig); err != nil && !errors.Is(err, syscall.ESRCH) {
+			targets[i].Error = err.Error()
+		}
+	}
+}
+
+// waitExit waits up to the grace period for the processes to exit,
+// records the exited ones, and returns the ones still running.
+func (t *GPUProcessTerminator) waitExit(ctx context.Context, rec *TerminationRecord, targets []GPUProcess) []GPUProcess {
+	deadline := time.NewTimer(t.gracePeriod)
+	defer deadline.Stop()
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		running := targets[:0]
+		for _, p := range targets {
+			if t.exited(p.PID) {
+				p.Error = ""
+				rec.Terminated = append(rec.Terminated, p)
+				continue
+			}
+			running = append(running, p)
+		}
+		targets = running
+		if len(targets) == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return targets
+		case <-deadline.C:
+			return targets
+		case <-ticker.C:
+		}
+	}
+}
+
+// exited returns true if the process no longer exists, or is a zombie
+// (terminated but not yet reaped by its parent).
+func (t *GPUProcessTerminator) exited(pid int32) bool {
+	b, err := os.ReadFile(filepath.Join(t.procRoot, strconv.Itoa(int(pid)), "stat"))
+	if err != nil {
+		return errors.Is(err, os.ErrNotExist)
+	}
+	// e.g., "1234 (python) Z 1 ..."
+	s := string(b)
+	if i := strings.LastIndex(s, ")"); i >= 0 && i+2 < len(s) {
+		return s[i+2] == 'Z'
+	}
+	return false
+}
+
+func (t *GPUProcessTerminator) record(ctx context.Context, rec *TerminationRecord) error {
+	if t.bucket == nil {
+		return nil
+	}
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return t.bucket.Insert(ctx, eventstore.Event{
+		Time:    rec.Time.Time,
+		Name:    EventNameGPUProcessesTerminated,
+		Type:    string(apiv1.EventTypeWarning),
+		Message: fmt.Sprintf("terminated %d process(es) on gpu %s (refused %d, failed %d)", len(rec.Terminated), rec.GPUUUID, len(rec.Refused), len(rec.Failed)),
+		ExtraInfo: map[string]string{
+			EventKeyTerminationRecord: string(b),
+		},
+	})
+}
+
+// resolve reads the name and the cgroup of the process,
+// and resolves its container and pod.
+func (t *GPUProcessTerminator) resolve(pid int32) GPUProcess {
+	p := GPUProcess{
+		PID:  pid,
+		Name: readProcessName(filepath.Join(t.procRoot, strconv.Itoa(int(pid)))),
+	}
+	if cg, err := cgroup.ReadProcess(t.procRoot, pid); err == nil {
+		p.Process = cg
+	}
+	return p
+}
+
+// readProcessName returns the untruncated name of the process, from the
+// executable path or the argv[0], since the kernel truncates the "comm"
+// to 15 characters (e.g., "nvidia-persiste" for "nvidia-persistenced").
+// The "comm" is only used if neither is readable.
+func readProcessName(dir string) string {
+	if exe, err := os.Readlink(filepath.Join(dir, "exe")); err == nil {
+		// e.g., "/usr/bin/nvidia-persistenced (deleted)" after the package upgrade
+		exe = strings.TrimSuffix(exe, " (deleted)")
+		if name := filepath.Base(exe); name != "" && name != "." && name != "/" {
+			return name
+		}
+	}
+	if b, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+		argv0, _, _ := strings.Cut(string(b), "\x00")
+		if argv0 = strings.TrimSpace(argv0); argv0 != "" {
+			return filepath.Base(argv0)
+		}
+	}
+	if b, err := os.ReadFile(filepath.Join(dir, "comm")); err == nil {
+		return strings.TrimSpace(string(b))
+	}
+	return ""
+}
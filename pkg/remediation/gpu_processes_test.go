@@ -0,0 +1,220 @@
+package remediation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+const (
+	testContainerID = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	testPodUID      = "1b2c3d4e-5f60-7182-93a4-b5c6d7e8f901"
+)
+
+// writeTestProc writes the fake process, with the "comm" truncated
+// to 15 characters as the kernel does.
+func writeTestProc(t *testing.T, root string, pid int, name string, cgroup string) {
+	dir := filepath.Join(root, strconv.Itoa(pid))
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "comm"), []byte(truncateComm(name)+"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cmdline"), []byte("/usr/bin/"+name+"\x00--verbose\x00"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cgroup"), []byte(cgroup), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "stat"), []byte(strconv.Itoa(pid)+" ("+name+") S 1 1"), 0644))
+}
+
+func TestGPUProcessTerminator(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+	bucket, err := store.Bucket("remediation")
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	procRoot := t.TempDir()
+	writeTestProc(t, procRoot, 100, "python", "0::/kubepods/besteffort/pod"+testPodUID+"/"+testContainerID+"\n")
+	writeTestProc(t, procRoot, 101, "stubborn", "0::/user.slice\n")
+	writeTestProc(t, procRoot, 102, "nvidia-persistenced", "0::/system.slice/nvidia-persistenced.service\n")
+
+	var signals []string
+	term := NewGPUProcessTerminator(nil, bucket, nil)
+	term.procRoot = procRoot
+	term.gracePeriod = 200 * time.Millisecond
+	term.listPIDs = func(uuid string) ([]uint32, error) {
+		return []uint32{100, 101, 102}, nil
+	}
+	term.kill = func(pid int, sig syscall.Signal) error {
+		signals = append(signals, strconv.Itoa(pid)+":"+sig.String())
+		// "stubborn" ignores SIGTERM
+		if pid == 100 || sig == syscall.SIGKILL {
+			return os.RemoveAll(filepath.Join(procRoot, strconv.Itoa(pid)))
+		}
+		return nil
+	}
+
+	rec, err := term.Terminate(ctx, "GPU-0", "xid 79")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrProtectedProcesses))
+	require.NotNil(t, rec)
+
+	assert.Equal(t, []string{"100:terminated", "101:terminated", "101:killed"}, signals, "protected process must never be signaled")
+
+	require.Len(t, rec.Terminated, 2)
+	assert.Equal(t, int32(100), rec.Terminated[0].PID)
+	assert.Equal(t, "SIGTERM", rec.Terminated[0].Signal)
+	assert.Equal(t, testContainerID, rec.Terminated[0].ContainerID)
+	assert.Equal(t, testPodUID, rec.Terminated[0].PodUID)
+	assert.Equal(t, int32(101), rec.Terminated[1].PID)
+	assert.Equal(t, "SIGKILL", rec.Terminated[1].Signal)
+
+	require.Len(t, rec.Refused, 1)
+	assert.Equal(t, "nvidia-persistenced", rec.Refused[0].Name)
+	assert.Empty(t, rec.Failed)
+
+	evs, err := bucket.Get(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, evs, 1)
+	assert.Equal(t, EventNameGPUProcessesTerminated, evs[0].Name)
+
+	var stored TerminationRecord
+	require.NoError(t, json.Unmarshal([]byte(evs[0].ExtraInfo[EventKeyTerminationRecord]), &stored))
+	assert.Equal(t, "GPU-0", stored.GPUUUID)
+	assert.Len(t, stored.Terminated, 2)
+	assert.Len(t, stored.Refused, 1)
+}
+
+func truncateComm(name string) string {
+	if len(name) > 15 {
+		return name[:15]
+	}
+	return name
+}
+
+func TestGPUProcessTerminatorTruncatedComm(t *testing.T) {
+	procRoot := t.TempDir()
+	writeProc := func(pid int, comm string) string {
+		dir := filepath.Join(procRoot, strconv.Itoa(pid))
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "comm"), []byte(comm+"\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "stat"), []byte(strconv.Itoa(pid)+" ("+comm+") S 1 1"), 0644))
+		return dir
+	}
+
+	// the full name from the executable path
+	dir := writeProc(300, "nvidia-persiste")
+	require.NoError(t, os.Symlink("/usr/bin/nvidia-persistenced", filepath.Join(dir, "exe")))
+	// the full name from the argv[0], without the readable executable path
+	dir = writeProc(301, "nvidia-fabricma")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cmdline"), []byte("/usr/bin/nvidia-fabricmanager\x00-c\x00/usr/share/nvidia/nvswitch/fabricmanager.cfg\x00"), 0644))
+	// the replaced executable after the package upgrade
+	dir = writeProc(302, "nv-fabricmanage")
+	require.NoError(t, os.Symlink("/usr/bin/nv-fabricmanager (deleted)", filepath.Join(dir, "exe")))
+
+	var signaled []int
+	term := NewGPUProcessTerminator(nil, nil, nil)
+	term.procRoot = procRoot
+	term.gracePeriod = 50 * time.Millisecond
+	term.listPIDs = func(uuid string) ([]uint32, error) {
+		return []uint32{300, 301, 302}, nil
+	}
+	term.kill = func(pid int, sig syscall.Signal) error {
+		signaled = append(signaled, pid)
+		return nil
+	}
+
+	rec, err := term.Terminate(context.Background(), "GPU-2", "")
+	require.ErrorIs(t, err, ErrProtectedProcesses)
+	assert.Empty(t, signaled, "protected processes must never be signaled")
+	require.Len(t, rec.Refused, 3)
+	assert.Equal(t, "nvidia-persistenced", rec.Refused[0].Name)
+	assert.Equal(t, "nvidia-fabricmanager", rec.Refused[1].Name)
+	assert.Equal(t, "nv-fabricmanager", rec.Refused[2].Name)
+}
+
+func TestGPUProcessTerminatorFailed(t *testing.T) {
+	procRoot := t.TempDir()
+	writeTestProc(t, procRoot, 200, "python", "0::/\n")
+
+	term := NewGPUProcessTerminator(nil, nil, []string{})
+	term.procRoot = procRoot
+	term.gracePeriod = 50 * time.Millisecond
+	term.listPIDs = func(uuid string) ([]uint32, error) {
+		return []uint32{200, uint32(term.selfPID)}, nil
+	}
+	term.kill = func(pid int, sig syscall.Signal) error {
+		return syscall.EPERM
+	}
+
+	rec, err := term.Terminate(context.Background(), "GPU-1", "")
+	require.Error(t, err)
+	require.Len(t, rec.Failed, 1)
+	assert.Equal(t, int32(200), rec.Failed[0].PID)
+	assert.Equal(t, syscall.EPERM.Error(), rec.Failed[0].Error)
+	require.Len(t, rec.Refused, 1, "gpud itself must be refused")
+
+	// zombie is terminated
+	require.NoError(t, os.WriteFile(filepath.Join(procRoot, "200", "stat"), []byte("200 (py thon) Z 1 1"), 0644))
+	assert.True(t, term.exited(200))
+	assert.True(t, term.exited(999))
+}
+
+func TestGPUProcessTerminatorListError(t *testing.T) {
+	term := NewGPUProcessTerminator(nil, nil, nil)
+	_, err := term.Terminate(context.Background(), "GPU-0", "")
+	require.Error(t, err)
+}
+
+func TestGPUProcessTerminatorTerminateAll(t *testing.T) {
+	procRoot := t.TempDir()
+	writeTestProc(t, procRoot, 300, "python", "0::/\n")
+	writeTestProc(t, procRoot, 301, "nvidia-persistenced", "0::/\n")
+
+	term := NewGPUProcessTerminator(nil, nil, nil)
+	term.procRoot = procRoot
+	term.gracePeriod = 50 * time.Millisecond
+	term.listGPUs = func() []string {
+		return []string{"GPU-0", "GPU-1", "GPU-2"}
+	}
+	term.listPIDs = func(uuid string) ([]uint32, error) {
+		switch uuid {
+		case "GPU-0":
+			return []uint32{300}, nil
+		case "GPU-1":
+			return []uint32{301}, nil
+		default:
+			return nil, errors.New("gpu lost")
+		}
+	}
+	exited := make(map[int]bool)
+	term.kill = func(pid int, sig syscall.Signal) error {
+		exited[pid] = true
+		return os.WriteFile(filepath.Join(procRoot, strconv.Itoa(pid), "stat"), []byte(strconv.Itoa(pid)+" (python) Z 1 1"), 0644)
+	}
+
+	// the errors of the other gpus do not stop the termination
+	recs, err := term.TerminateAll(context.Background(), "reboot")
+	require.ErrorIs(t, err, ErrProtectedProcesses)
+	require.Len(t, recs, 2)
+	assert.Equal(t, "GPU-0", recs[0].GPUUUID)
+	require.Len(t, recs[0].Terminated, 1)
+	assert.Equal(t, int32(300), recs[0].Terminated[0].PID)
+	assert.Equal(t, "GPU-1", recs[1].GPUUUID)
+	require.Len(t, recs[1].Refused, 1)
+	assert.True(t, exited[300])
+	assert.False(t, exited[301])
+}
@@ -0,0 +1,60 @@
+package remediation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+)
+
+// LoadLastActions loads the last action times persisted in the metadata table,
+// and persists the last action times from now on before performing each action,
+// so that the cooldowns survive the restarts (e.g., the reboot actions).
+func (e *Engine) LoadLastActions(ctx context.Context, dbRW *sql.DB, dbRO *sql.DB) error {
+	last, err := readLastActions(ctx, dbRO)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for k, v := range last {
+		if v.After(e.last[k]) {
+			e.last[k] = v
+		}
+	}
+	e.dbRW = dbRW
+	return nil
+}
+
+// persistLastActionsLocked persists the last action times, if enabled.
+// The caller must hold the lock.
+func (e *Engine) persistLastActionsLocked(ctx context.Context) error {
+	if e.dbRW == nil {
+		return nil
+	}
+	b, err := json.Marshal(e.last)
+	if err != nil {
+		return err
+	}
+	return pkgmetadata.SetMetadata(ctx, e.dbRW, pkgmetadata.MetadataKeyRemediationLastActions, string(b))
+}
+
+func readLastActions(ctx context.Context, dbRO *sql.DB) (map[string]time.Time, error) {
+	raw, err := pkgmetadata.ReadMetadata(ctx, dbRO, pkgmetadata.MetadataKeyRemediationLastActions)
+	if err != nil {
+		return nil, err
+	}
+	if raw == "" {
+		return nil, nil
+	}
+
+	var last map[string]time.Time
+	if err := json.Unmarshal([]byte(raw), &last); err != nil {
+		return nil, fmt.Errorf("failed to parse remediation last actions: %w", err)
+	}
+	return last, nil
+}
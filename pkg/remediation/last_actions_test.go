@@ -0,0 +1,92 @@
+package remediation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestLoadLastActions(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	require.NoError(t, pkgmetadata.CreateTableMetadata(ctx, dbRW))
+
+	comp := &mockComponent{
+		name: "accelerator-nvidia-fallen-off-bus",
+		states: apiv1.HealthStates{
+			{
+				Name:   "accelerator-nvidia-fallen-off-bus",
+				Health: apiv1.HealthStateTypeUnhealthy,
+				SuggestedActions: &apiv1.SuggestedActions{
+					RepairActions: []apiv1.RepairActionType{apiv1.RepairActionTypeRebootSystem},
+				},
+			},
+		},
+	}
+	registry := components.NewRegistry(&components.GPUdInstance{})
+	_, err := registry.Register(func(*components.GPUdInstance) (components.Component, error) { return comp, nil })
+	require.NoError(t, err)
+	policy := &Policy{Rules: []Rule{
+		{
+			Component: "accelerator-nvidia-fallen-off-bus",
+			Actions:   []apiv1.RepairActionType{apiv1.RepairActionTypeRebootSystem},
+			Cooldown:  metav1.Duration{Duration: time.Hour},
+		},
+	}}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	newEngine := func() (*Engine, *int) {
+		e := NewEngine(registry, policy)
+		e.nowFunc = func() time.Time { return now }
+		require.NoError(t, e.LoadLastActions(ctx, dbRW, dbRO))
+		executed := 0
+		e.RegisterExecutor(apiv1.RepairActionTypeRebootSystem, func(context.Context, string, apiv1.HealthState) error {
+			executed++
+			return nil
+		})
+		return e, &executed
+	}
+
+	e, executed := newEngine()
+	ds := e.Evaluate(ctx)
+	require.Len(t, ds, 1)
+	assert.Equal(t, DecisionTypeExecuted, ds[0].Type)
+	assert.Equal(t, 1, *executed)
+
+	// restarted (e.g., rebooted by the action) within the cooldown
+	now = now.Add(10 * time.Minute)
+	e, executed = newEngine()
+	ds = e.Evaluate(ctx)
+	require.Len(t, ds, 1)
+	assert.Equal(t, DecisionTypeCooldown, ds[0].Type)
+	assert.Equal(t, 0, *executed)
+
+	now = now.Add(time.Hour)
+	e, executed = newEngine()
+	ds = e.Evaluate(ctx)
+	require.Len(t, ds, 1)
+	assert.Equal(t, DecisionTypeExecuted, ds[0].Type)
+	assert.Equal(t, 1, *executed)
+
+	// the action is not performed if the cooldown cannot be persisted
+	now = now.Add(2 * time.Hour)
+	require.NoError(t, dbRW.Close())
+	ds = e.Evaluate(ctx)
+	require.Len(t, ds, 1)
+	assert.Equal(t, DecisionTypeNotPersisted, ds[0].Type)
+	assert.NotEmpty(t, ds[0].Error)
+	assert.Equal(t, 1, *executed)
+}
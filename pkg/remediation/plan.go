@@ -0,0 +1,108 @@
+package remediation
+
+import (
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+// BuildActionPlan aggregates the suggested actions of the unhealthy (or degraded)
+// component states into a single node-level plan, where the same action suggested
+// by multiple components is merged into one step, and the steps are ordered from
+// the most invasive (e.g., hardware inspection, then reboot, then user app check).
+// The actions to ignore are excluded, since no action is required.
+func BuildActionPlan(states apiv1.GPUdComponentHealthStates) apiv1.ActionPlan {
+	steps := make(map[apiv1.RepairActionType]*apiv1.ActionPlanStep)
+	for _, cs := range states {
+		for _, st := range cs.States {
+			if st.Health == apiv1.HealthStateTypeHealthy || st.SuggestedActions == nil {
+				continue
+			}
+			for _, act := range st.SuggestedActions.RepairActions {
+				if act == "" || act == apiv1.RepairActionTypeIgnoreNoActionRequired {
+					continue
+				}
+
+				step, ok := steps[act]
+				if !ok {
+					step = &apiv1.ActionPlanStep{Action: act}
+					steps[act] = step
+				}
+				step.Components = appendUnique(step.Components, cs.Component)
+				if st.SuggestedActions.Description != "" {
+					step.Descriptions = appendUnique(step.Descriptions, st.SuggestedActions.Description)
+				}
+				for _, code := range st.FailureCodes {
+					step.FailureCodes = appendUnique(step.FailureCodes, code)
+				}
+			}
+		}
+	}
+
+	plan := apiv1.ActionPlan{}
+	for _, step := range steps {
+		sort.Strings(step.Components)
+		plan.Steps = append(plan.Steps, *step)
+	}
+	sort.Slice(plan.Steps, func(i, j int) bool {
+		ri := apiv1.RepairActionInvasiveness(plan.Steps[i].Action)
+		rj := apiv1.RepairActionInvasiveness(plan.Steps[j].Action)
+		if ri != rj {
+			return ri > rj
+		}
+		return plan.Steps[i].Action < plan.Steps[j].Action
+	})
+	if len(plan.Steps) > 0 {
+		plan.Action = plan.Steps[0].Action
+	}
+	return plan
+}
+
+// Summarize returns the node-level summary of the component health states,
+// with the worst health state and the prioritized action plan.
+func Summarize(now time.Time, states apiv1.GPUdComponentHealthStates) apiv1.NodeSummary {
+	summary := apiv1.NodeSummary{
+		Time:   metav1.NewTime(now.UTC()),
+		Health: apiv1.HealthStateTypeHealthy,
+	}
+
+	for _, cs := range states {
+		unhealthy, degraded := false, false
+		for _, st := range cs.States {
+			switch st.Health {
+			case apiv1.HealthStateTypeUnhealthy:
+				unhealthy = true
+			case apiv1.HealthStateTypeDegraded:
+				degraded = true
+			}
+		}
+		if unhealthy {
+			summary.UnhealthyComponents = append(summary.UnhealthyComponents, cs.Component)
+		} else if degraded {
+			summary.DegradedComponents = append(summary.DegradedComponents, cs.Component)
+		}
+	}
+	sort.Strings(summary.UnhealthyComponents)
+	sort.Strings(summary.DegradedComponents)
+
+	if len(summary.UnhealthyComponents) > 0 {
+		summary.Health = apiv1.HealthStateTypeUnhealthy
+	} else if len(summary.DegradedComponents) > 0 {
+		summary.Health = apiv1.HealthStateTypeDegraded
+	}
+
+	summary.ActionPlan = BuildActionPlan(states)
+	return summary
+}
+
+func appendUnique[T comparable](s []T, v T) []T {
+	for _, x := range s {
+		if x == v {
+			return s
+		}
+	}
+	return append(s, v)
+}
@@ -0,0 +1,134 @@
+package remediation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestBuildActionPlan(t *testing.T) {
+	states := apiv1.GPUdComponentHealthStates{
+		{
+			Component: "accelerator-nvidia-xid",
+			States: apiv1.HealthStates{{
+				Health:       apiv1.HealthStateTypeUnhealthy,
+				FailureCodes: []apiv1.FailureCode{apiv1.FailureCodeXIDUncorrectableECC},
+				SuggestedActions: &apiv1.SuggestedActions{
+					Description:   "reboot to reset the GPU",
+					RepairActions: []apiv1.RepairActionType{apiv1.RepairActionTypeRebootSystem},
+				},
+			}},
+		},
+		{
+			Component: "accelerator-nvidia-remapped-rows",
+			States: apiv1.HealthStates{{
+				Health:       apiv1.HealthStateTypeUnhealthy,
+				FailureCodes: []apiv1.FailureCode{apiv1.FailureCodeRowRemappingPending},
+				SuggestedActions: &apiv1.SuggestedActions{
+					Description:   "reboot to reset the GPU",
+					RepairActions: []apiv1.RepairActionType{apiv1.RepairActionTypeRebootSystem},
+				},
+			}},
+		},
+		{
+			Component: "accelerator-nvidia-infiniband",
+			States: apiv1.HealthStates{{
+				Health:       apiv1.HealthStateTypeUnhealthy,
+				FailureCodes: []apiv1.FailureCode{apiv1.FailureCodeIBPortDown},
+				SuggestedActions: &apiv1.SuggestedActions{
+					RepairActions: []apiv1.RepairActionType{apiv1.RepairActionTypeHardwareInspection},
+				},
+			}},
+		},
+		{
+			Component: "custom-plugin",
+			States: apiv1.HealthStates{{
+				Health: apiv1.HealthStateTypeDegraded,
+				SuggestedActions: &apiv1.SuggestedActions{
+					RepairActions: []apiv1.RepairActionType{apiv1.RepairActionTypeCheckUserAppAndGPU, apiv1.RepairActionTypeIgnoreNoActionRequired},
+				},
+			}},
+		},
+		{
+			// healthy states are not part of the plan
+			Component: "cpu",
+			States: apiv1.HealthStates{{
+				Health: apiv1.HealthStateTypeHealthy,
+				SuggestedActions: &apiv1.SuggestedActions{
+					RepairActions: []apiv1.RepairActionType{apiv1.RepairActionTypeRebootSystem},
+				},
+			}},
+		},
+	}
+
+	plan := BuildActionPlan(states)
+	assert.Equal(t, apiv1.RepairActionTypeHardwareInspection, plan.Action)
+	require.Len(t, plan.Steps, 3)
+
+	assert.Equal(t, apiv1.ActionPlanStep{
+		Action:       apiv1.RepairActionTypeHardwareInspection,
+		Components:   []string{"accelerator-nvidia-infiniband"},
+		FailureCodes: []apiv1.FailureCode{apiv1.FailureCodeIBPortDown},
+	}, plan.Steps[0])
+	assert.Equal(t, apiv1.ActionPlanStep{
+		Action:       apiv1.RepairActionTypeRebootSystem,
+		Components:   []string{"accelerator-nvidia-remapped-rows", "accelerator-nvidia-xid"},
+		Descriptions: []string{"reboot to reset the GPU"},
+		FailureCodes: []apiv1.FailureCode{apiv1.FailureCodeXIDUncorrectableECC, apiv1.FailureCodeRowRemappingPending},
+	}, plan.Steps[1])
+	assert.Equal(t, apiv1.RepairActionTypeCheckUserAppAndGPU, plan.Steps[2].Action)
+
+	assert.Equal(t, apiv1.ActionPlan{}, BuildActionPlan(nil))
+}
+
+func TestRepairActionInvasivenessOrder(t *testing.T) {
+	ordered := []apiv1.RepairActionType{
+		apiv1.RepairActionTypeHardwareInspection,
+		apiv1.RepairActionTypeRebootSystem,
+		apiv1.RepairActionTypeCheckCabling,
+		apiv1.RepairActionType("UNKNOWN_ACTION"),
+		apiv1.RepairActionTypeCheckUserAppAndGPU,
+		apiv1.RepairActionTypeIgnoreNoActionRequired,
+	}
+	for i := 1; i < len(ordered); i++ {
+		assert.Greater(t, apiv1.RepairActionInvasiveness(ordered[i-1]), apiv1.RepairActionInvasiveness(ordered[i]), "%s > %s", ordered[i-1], ordered[i])
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	summary := Summarize(now, apiv1.GPUdComponentHealthStates{
+		{Component: "cpu", States: apiv1.HealthStates{{Health: apiv1.HealthStateTypeHealthy}}},
+	})
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, summary.Health)
+	assert.True(t, now.Equal(summary.Time.Time))
+	assert.Empty(t, summary.UnhealthyComponents)
+	assert.Empty(t, summary.ActionPlan.Action)
+
+	summary = Summarize(now, apiv1.GPUdComponentHealthStates{
+		{Component: "disk", States: apiv1.HealthStates{{Health: apiv1.HealthStateTypeDegraded}}},
+		{Component: "cpu", States: apiv1.HealthStates{{Health: apiv1.HealthStateTypeHealthy}}},
+	})
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, summary.Health)
+	assert.Equal(t, []string{"disk"}, summary.DegradedComponents)
+
+	summary = Summarize(now, apiv1.GPUdComponentHealthStates{
+		{Component: "disk", States: apiv1.HealthStates{{Health: apiv1.HealthStateTypeDegraded}}},
+		{Component: "xid", States: apiv1.HealthStates{
+			{Health: apiv1.HealthStateTypeDegraded},
+			{
+				Health:           apiv1.HealthStateTypeUnhealthy,
+				SuggestedActions: &apiv1.SuggestedActions{RepairActions: []apiv1.RepairActionType{apiv1.RepairActionTypeRebootSystem}},
+			},
+		}},
+	})
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, summary.Health)
+	assert.Equal(t, []string{"xid"}, summary.UnhealthyComponents)
+	assert.Equal(t, []string{"disk"}, summary.DegradedComponents)
+	assert.Equal(t, apiv1.RepairActionTypeRebootSystem, summary.ActionPlan.Action)
+}
@@ -0,0 +1,133 @@
+package remediation
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+// FailureClassAny matches any failure class of the component.
+const FailureClassAny = "*"
+
+var (
+	ErrRuleComponentRequired = errors.New("remediation rule component is required")
+	ErrRuleActionsRequired   = errors.New("remediation rule actions are required")
+	ErrInvalidRuleAction     = errors.New("invalid remediation rule action")
+	ErrInvalidRuleCooldown   = errors.New("invalid remediation rule cooldown")
+)
+
+// Rule allows gpud to perform the repair actions automatically
+// for the failure class of the component, at most once per the cooldown.
+// The suggested actions not allowed by any rule are only suggested.
+type Rule struct {
+	// Component is the name of the component (e.g., "accelerator-nvidia-fallen-off-bus").
+	Component string `json:"component"`
+	// FailureClass is the name of the health state of the component
+	// that suggests the actions. Empty or "*" matches any failure class.
+	FailureClass string `json:"failure_class,omitempty"`
+	// Actions are the repair actions allowed to perform automatically
+	// (e.g., "REBOOT_SYSTEM").
+	Actions []apiv1.RepairActionType `json:"actions"`
+	// Cooldown is the minimum duration between the automatic actions
+	// for the same component and failure class.
+	Cooldown metav1.Duration `json:"cooldown,omitempty"`
+}
+
+// Validate validates the rule.
+func (r *Rule) Validate() error {
+	if r.Component == "" {
+		return ErrRuleComponentRequired
+	}
+	if len(r.Actions) == 0 {
+		return fmt.Errorf("%w (component %q)", ErrRuleActionsRequired, r.Component)
+	}
+	for _, act := range r.Actions {
+		switch act {
+		case apiv1.RepairActionTypeRebootSystem,
+			apiv1.RepairActionTypeHardwareInspection,
+			apiv1.RepairActionTypeCheckUserAppAndGPU,
+			apiv1.RepairActionTypeCheckCabling,
+			apiv1.RepairActionTypeIgnoreNoActionRequired:
+		default:
+			return fmt.Errorf("%w %q (component %q)", ErrInvalidRuleAction, act, r.Component)
+		}
+	}
+	if r.Cooldown.Duration < 0 {
+		return fmt.Errorf("%w %q (component %q)", ErrInvalidRuleCooldown, r.Cooldown.Duration, r.Component)
+	}
+	return nil
+}
+
+// matches returns true if the rule applies to the failure class of the component.
+func (r *Rule) matches(component string, failureClass string) bool {
+	if r.Component != component {
+		return false
+	}
+	return r.FailureClass == "" || r.FailureClass == FailureClassAny || r.FailureClass == failureClass
+}
+
+// allows returns true if the rule allows the action.
+func (r *Rule) allows(action apiv1.RepairActionType) bool {
+	for _, act := range r.Actions {
+		if act == action {
+			return true
+		}
+	}
+	return false
+}
+
+// Policy is the set of the rules that decide which repair actions
+// gpud may perform autonomously versus only suggest.
+type Policy struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Validate validates all the rules.
+func (p *Policy) Validate() error {
+	for i := range p.Rules {
+		if err := p.Rules[i].Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Find returns the first rule that allows the action for the failure class
+// of the component, or nil if the action should only be suggested.
+func (p *Policy) Find(component string, failureClass string, action apiv1.RepairActionType) *Rule {
+	if p == nil {
+		return nil
+	}
+	for i := range p.Rules {
+		if p.Rules[i].matches(component, failureClass) && p.Rules[i].allows(action) {
+			return &p.Rules[i]
+		}
+	}
+	return nil
+}
+
+// LoadPolicy loads and validates the policy from the YAML file.
+func LoadPolicy(file string) (*Policy, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePolicy(b)
+}
+
+// ParsePolicy parses and validates the policy from the YAML content.
+func ParsePolicy(b []byte) (*Policy, error) {
+	var p Policy
+	if err := yaml.Unmarshal(b, &p); err != nil {
+		return nil, err
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
@@ -0,0 +1,48 @@
+package sampling
+
+import (
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+// ring is a fixed-size ring buffer of the samples,
+// overwriting the oldest samples once full.
+type ring struct {
+	buf     []apiv1.GPUSample
+	start   int
+	size    int
+	dropped int
+}
+
+func newRing(capacity int) *ring {
+	return &ring{buf: make([]apiv1.GPUSample, capacity)}
+}
+
+func (r *ring) push(s apiv1.GPUSample) {
+	if len(r.buf) == 0 {
+		r.dropped++
+		return
+	}
+	if r.size < len(r.buf) {
+		r.buf[(r.start+r.size)%len(r.buf)] = s
+		r.size++
+		return
+	}
+	r.buf[r.start] = s
+	r.start = (r.start + 1) % len(r.buf)
+	r.dropped++
+}
+
+// list returns the samples in the order of insertion.
+func (r *ring) list() []apiv1.GPUSample {
+	out := make([]apiv1.GPUSample, 0, r.size)
+	for i := 0; i < r.size; i++ {
+		out = append(out, r.buf[(r.start+i)%len(r.buf)])
+	}
+	return out
+}
+
+func (r *ring) reset() {
+	r.start = 0
+	r.size = 0
+	r.dropped = 0
+}
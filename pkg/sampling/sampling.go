@@ -0,0 +1,236 @@
+// Package sampling implements the temporary high-frequency sampling of the GPU
+// utilization, power, and clocks (e.g., for the detailed profiling during
+// the benchmark runs), into an in-memory ring buffer.
+//
+// The sampling is time-boxed, and stops automatically once the duration elapses,
+// leaving the regular component checks at their normal cadence.
+package sampling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/log"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+)
+
+const (
+	MinInterval = 100 * time.Millisecond
+	MaxInterval = time.Second
+
+	DefaultDuration = 5 * time.Minute
+	MaxDuration     = 30 * time.Minute
+
+	// DefaultCapacity is the default number of the samples kept in the ring buffer
+	// (e.g., 10 minutes of 8 GPUs at 100ms).
+	DefaultCapacity = 50000
+)
+
+var ErrInvalidInterval = fmt.Errorf("sampling interval must be between %s and %s", MinInterval, MaxInterval)
+
+var ErrInvalidDuration = fmt.Errorf("sampling duration must be between 1s and %s", MaxDuration)
+
+// CollectFunc collects a sample from each GPU.
+type CollectFunc func(ctx context.Context) ([]apiv1.GPUSample, error)
+
+// Sampler samples the GPUs at the high frequency, while a session is active.
+type Sampler struct {
+	rootCtx context.Context
+	collect CollectFunc
+
+	mu        sync.RWMutex
+	samples   *ring
+	cancel    context.CancelFunc
+	active    bool
+	interval  time.Duration
+	startedAt time.Time
+	expiresAt time.Time
+}
+
+// New creates a new sampler that keeps up to the capacity samples.
+// The sessions are stopped when the root context is canceled.
+func New(rootCtx context.Context, collect CollectFunc, capacity int) *Sampler {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &Sampler{
+		rootCtx: rootCtx,
+		collect: collect,
+		samples: newRing(capacity),
+	}
+}
+
+// Start starts a new sampling session, replacing the current session if any.
+// The samples of the previous session are discarded.
+func (s *Sampler) Start(interval time.Duration, duration time.Duration) error {
+	if interval < MinInterval || interval > MaxInterval {
+		return ErrInvalidInterval
+	}
+	if duration == 0 {
+		duration = DefaultDuration
+	}
+	if duration < time.Second || duration > MaxDuration {
+		return ErrInvalidDuration
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	now := time.Now().UTC()
+	ctx, cancel := context.WithDeadline(s.rootCtx, now.Add(duration))
+	s.samples.reset()
+	s.cancel = cancel
+	s.active = true
+	s.interval = interval
+	s.startedAt = now
+	s.expiresAt = now.Add(duration)
+
+	log.Logger.Infow("starting high-frequency sampling", "interval", interval, "duration", duration)
+	go s.run(ctx, interval)
+
+	return nil
+}
+
+// Stop stops the current session, if any.
+// The collected samples are kept until the next session starts.
+func (s *Sampler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	if s.active {
+		s.active = false
+		s.expiresAt = time.Now().UTC()
+	}
+}
+
+// Status returns the status of the sampling, with the samples taken after the since time.
+// Set the since time to zero to return all the samples.
+func (s *Sampler) Status(since time.Time) apiv1.GPUSamplingStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st := apiv1.GPUSamplingStatus{
+		Active:  s.active,
+		Dropped: s.samples.dropped,
+	}
+	if !s.startedAt.IsZero() {
+		st.Interval = metav1.Duration{Duration: s.interval}
+		st.StartedAt = metav1.NewTime(s.startedAt)
+		st.ExpiresAt = metav1.NewTime(s.expiresAt)
+	}
+	for _, sample := range s.samples.list() {
+		if !since.IsZero() && !sample.Time.After(since) {
+			continue
+		}
+		st.Samples = append(st.Samples, sample)
+	}
+	return st
+}
+
+func (s *Sampler) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.finish(ctx)
+			return
+		case <-ticker.C:
+		}
+
+		samples, err := s.collect(ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				continue
+			}
+			log.Logger.Warnw("failed to collect high-frequency samples", "error", err)
+		}
+
+		s.mu.Lock()
+		if ctx.Err() == nil {
+			for _, sample := range samples {
+				// truncate to the serialized precision, so that the clients
+				// can page with the time of the last received sample
+				sample.Time = metav1.NewMicroTime(sample.Time.Truncate(time.Microsecond))
+				s.samples.push(sample)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// finish marks the session inactive once it expires,
+// unless it has been replaced by a new session.
+func (s *Sampler) finish(ctx context.Context) {
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.active && !time.Now().Before(s.expiresAt) {
+		log.Logger.Infow("high-frequency sampling expired, stopping")
+		s.active = false
+		s.cancel = nil
+	}
+}
+
+// NewNVMLCollectFunc returns the collect function that reads
+// the utilization, power, and clocks of all the GPUs via NVML.
+// The unsupported values are left as zero.
+func NewNVMLCollectFunc(nvmlInstance nvidianvml.Instance) CollectFunc {
+	return func(ctx context.Context) ([]apiv1.GPUSample, error) {
+		devs := nvmlInstance.Devices()
+		samples := make([]apiv1.GPUSample, 0, len(devs))
+
+		var errs []error
+		for uuid, dev := range devs {
+			sample := apiv1.GPUSample{
+				Time: metav1.NewMicroTime(time.Now().UTC()),
+				UUID: uuid,
+			}
+
+			util, err := nvidianvml.GetUtilization(uuid, dev)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to get utilization of %s: %w", uuid, err))
+				continue
+			}
+			sample.GPUUsedPercent = util.GPUUsedPercent
+			sample.MemoryUsedPercent = util.MemoryUsedPercent
+
+			power, err := nvidianvml.GetPower(uuid, dev)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to get power of %s: %w", uuid, err))
+				continue
+			}
+			sample.PowerUsageMilliWatts = power.UsageMilliWatts
+
+			clock, err := nvidianvml.GetClockSpeed(uuid, dev)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to get clock speed of %s: %w", uuid, err))
+				continue
+			}
+			sample.GraphicsClockMHz = clock.GraphicsMHz
+			sample.MemoryClockMHz = clock.MemoryMHz
+
+			samples = append(samples, sample)
+		}
+		return samples, errors.Join(errs...)
+	}
+}
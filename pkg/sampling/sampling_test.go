@@ -0,0 +1,102 @@
+package sampling
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestRing(t *testing.T) {
+	r := newRing(3)
+	for i := 0; i < 5; i++ {
+		r.push(apiv1.GPUSample{GPUUsedPercent: uint32(i)})
+	}
+	list := r.list()
+	require.Len(t, list, 3)
+	assert.Equal(t, uint32(2), list[0].GPUUsedPercent)
+	assert.Equal(t, uint32(4), list[2].GPUUsedPercent)
+	assert.Equal(t, 2, r.dropped)
+
+	r.reset()
+	assert.Empty(t, r.list())
+	assert.Equal(t, 0, r.dropped)
+}
+
+func countingCollect(calls *atomic.Int32) CollectFunc {
+	return func(ctx context.Context) ([]apiv1.GPUSample, error) {
+		n := calls.Add(1)
+		return []apiv1.GPUSample{{
+			Time:           metav1.NewMicroTime(time.Now().UTC()),
+			UUID:           "GPU-0",
+			GPUUsedPercent: uint32(n),
+		}}, nil
+	}
+}
+
+func TestSamplerValidation(t *testing.T) {
+	var calls atomic.Int32
+	s := New(context.Background(), countingCollect(&calls), 10)
+
+	assert.ErrorIs(t, s.Start(10*time.Millisecond, time.Minute), ErrInvalidInterval)
+	assert.ErrorIs(t, s.Start(2*time.Second, time.Minute), ErrInvalidInterval)
+	assert.ErrorIs(t, s.Start(MinInterval, time.Hour), ErrInvalidDuration)
+	assert.ErrorIs(t, s.Start(MinInterval, time.Millisecond), ErrInvalidDuration)
+
+	st := s.Status(time.Time{})
+	assert.False(t, st.Active)
+	assert.True(t, st.StartedAt.IsZero())
+}
+
+func TestSamplerExpires(t *testing.T) {
+	var calls atomic.Int32
+	s := New(context.Background(), countingCollect(&calls), 100)
+
+	require.NoError(t, s.Start(MinInterval, time.Second))
+	assert.True(t, s.Status(time.Time{}).Active)
+
+	require.Eventually(t, func() bool {
+		return !s.Status(time.Time{}).Active
+	}, 5*time.Second, 50*time.Millisecond)
+
+	st := s.Status(time.Time{})
+	assert.NotEmpty(t, st.Samples)
+	assert.Equal(t, metav1.Duration{Duration: MinInterval}, st.Interval)
+
+	// no more samples after the session expired
+	n := calls.Load()
+	time.Sleep(3 * MinInterval)
+	assert.Equal(t, n, calls.Load())
+
+	// samples after the since time only
+	last := st.Samples[len(st.Samples)-1]
+	assert.Empty(t, s.Status(last.Time.Time).Samples)
+}
+
+func TestSamplerStopAndRestart(t *testing.T) {
+	var calls atomic.Int32
+	s := New(context.Background(), countingCollect(&calls), 100)
+
+	require.NoError(t, s.Start(MinInterval, time.Minute))
+	require.Eventually(t, func() bool {
+		return len(s.Status(time.Time{}).Samples) > 0
+	}, 5*time.Second, 50*time.Millisecond)
+
+	s.Stop()
+	st := s.Status(time.Time{})
+	assert.False(t, st.Active)
+	assert.NotEmpty(t, st.Samples)
+
+	// restart discards the samples of the previous session
+	require.NoError(t, s.Start(MaxInterval, time.Minute))
+	st = s.Status(time.Time{})
+	assert.True(t, st.Active)
+	assert.Empty(t, st.Samples)
+	s.Stop()
+}
@@ -0,0 +1,108 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/toolexec"
+	"github.com/leptonai/gpud/pkg/toolpath"
+)
+
+// deepCheck is a diagnostic tool run only in the deep scan profile.
+type deepCheck struct {
+	name    string
+	command []string
+	// requiresGPU is true if the tool is only meaningful on the GPU hosts.
+	requiresGPU bool
+}
+
+var deepChecks = []deepCheck{
+	// DCGM medium diagnostics (e.g., PCIe bandwidth, memory, targeted stress)
+	// ref. https://docs.nvidia.com/datacenter/dcgm/latest/user-guide/dcgm-diagnostics.html
+	{name: "dcgm-diag", command: []string{"dcgmi", "diag", "-r", "2"}, requiresGPU: true},
+	// GPU memory bandwidth benchmark between the host and the devices, and between the devices
+	// ref. https://github.com/NVIDIA/nvbandwidth
+	{name: "nvbandwidth", command: []string{"nvbandwidth"}, requiresGPU: true},
+	// InfiniBand fabric diagnostics
+	{name: "ibdiagnet", command: []string{"ibdiagnet"}},
+}
+
+// maxDeepCheckOutputLines is the number of the last output lines to print.
+const maxDeepCheckOutputLines = 30
+
+// notRunReason returns the reason the deep check cannot run on the host,
+// empty if it can. The checks not run are never reported as healthy,
+// so that the node is not certified without them.
+func notRunReason(dc deepCheck, hasGPU bool) string {
+	if dc.requiresGPU && !hasGPU {
+		return "no NVIDIA GPU detected"
+	}
+	if _, err := toolpath.Locate(dc.command[0]); err != nil {
+		return fmt.Sprintf("%s not found", dc.command[0])
+	}
+	return ""
+}
+
+func runDeepCheck(ctx context.Context, dc deepCheck) components.CheckResult {
+	cr := &deepCheckResult{name: dc.name}
+
+	// the diagnostics are bounded by the deep profile budget,
+	// and by the resource limits of the check, if any
+	res, err := toolexec.Run(toolexec.WithComponent(ctx, dc.name), dc.command, toolexec.WithTimeout(ProfileDeep.Budget()))
+	if res == nil {
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = fmt.Sprintf("failed to run %s: %v", dc.name, err)
+		return cr
+	}
+	cr.output = tailLines(strings.TrimSpace(string(res.Output)), maxDeepCheckOutputLines)
+	if err != nil {
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = fmt.Sprintf("%s failed after %s: %v", dc.name, res.Took.Round(time.Second), err)
+		return cr
+	}
+
+	cr.health = apiv1.HealthStateTypeHealthy
+	cr.reason = fmt.Sprintf("%s passed in %s", dc.name, res.Took.Round(time.Second))
+	return cr
+}
+
+func tailLines(s string, n int) string {
+	lines := strings.Split(s, "\n")
+	if len(lines) <= n {
+		return s
+	}
+	return strings.Join(lines[len(lines)-n:], "\n")
+}
+
+var _ components.CheckResult = &deepCheckResult{}
+
+type deepCheckResult struct {
+	name   string
+	output string
+
+	health apiv1.HealthStateType
+	reason string
+}
+
+func (cr *deepCheckResult) ComponentName() string { return cr.name }
+
+func (cr *deepCheckResult) String() string { return cr.output }
+
+func (cr *deepCheckResult) Summary() string { return cr.reason }
+
+func (cr *deepCheckResult) HealthStateType() apiv1.HealthStateType { return cr.health }
+
+func (cr *deepCheckResult) HealthStates() apiv1.HealthStates {
+	return apiv1.HealthStates{
+		{
+			Component: cr.name,
+			Name:      cr.name,
+			Health:    cr.health,
+			Reason:    cr.reason,
+		},
+	}
+}
@@ -1,11 +1,25 @@
 package scan
 
+import (
+	"errors"
+
+	pkgoutput "github.com/leptonai/gpud/pkg/output"
+	pkgprobecache "github.com/leptonai/gpud/pkg/probecache"
+	"github.com/leptonai/gpud/pkg/upload"
+)
+
 type Op struct {
-	ibstatCommand   string
-	ibstatusCommand string
-	debug           bool
+	profile    Profile
+	debug      bool
+	uploader   upload.Uploader
+	probeCache *pkgprobecache.Cache
+	output     pkgoutput.Format
+	recordDir  string
+	replayDir  string
 }
 
+var ErrRecordAndReplay = errors.New("cannot record and replay the scan at the same time")
+
 type OpOption func(*Op)
 
 func (op *Op) applyOpts(opts []OpOption) error {
@@ -13,32 +27,71 @@ func (op *Op) applyOpts(opts []OpOption) error {
 		opt(op)
 	}
 
-	if op.ibstatCommand == "" {
-		op.ibstatCommand = "ibstat"
+	if op.profile == "" {
+		op.profile = DefaultProfile
+	}
+	if _, err := ParseProfile(string(op.profile)); err != nil {
+		return err
 	}
-	if op.ibstatusCommand == "" {
-		op.ibstatusCommand = "ibstatus"
+	if op.output == "" {
+		op.output = pkgoutput.DefaultFormat
+	}
+	if op.recordDir != "" && op.replayDir != "" {
+		return ErrRecordAndReplay
 	}
 
 	return nil
 }
 
-// Specifies the ibstat binary path to overwrite the default path.
-func WithIbstatCommand(p string) OpOption {
+// Specifies the scan profile (e.g., "quick", "deep").
+func WithProfile(p Profile) OpOption {
 	return func(op *Op) {
-		op.ibstatCommand = p
+		op.profile = p
 	}
 }
 
-// Specifies the ibstatus binary path to overwrite the default path.
-func WithIbstatusCommand(p string) OpOption {
+func WithDebug(b bool) OpOption {
 	return func(op *Op) {
-		op.ibstatusCommand = p
+		op.debug = b
 	}
 }
 
-func WithDebug(b bool) OpOption {
+// Specifies the uploader to upload the scan result in JSON to.
+func WithUploader(u upload.Uploader) OpOption {
 	return func(op *Op) {
-		op.debug = b
+		op.uploader = u
+	}
+}
+
+// Specifies the probe cache to skip the redundant static discoveries
+// (e.g., the GPU device inventory) on the repeated scans.
+func WithProbeCache(c *pkgprobecache.Cache) OpOption {
+	return func(op *Op) {
+		op.probeCache = c
+	}
+}
+
+// Specifies the output format. The machine-readable formats (e.g., "json")
+// only print the scan result once the scan completes.
+func WithOutput(f pkgoutput.Format) OpOption {
+	return func(op *Op) {
+		op.output = f
+	}
+}
+
+// Specifies the directory to record the raw inputs of the scan
+// (e.g., the NVML calls, the ibstat output) and the scan result into,
+// to replay the scan with.
+func WithRecordDir(dir string) OpOption {
+	return func(op *Op) {
+		op.recordDir = dir
+	}
+}
+
+// Specifies the recording directory to replay the scan from,
+// re-evaluating the recorded inputs and comparing with the recorded result.
+func WithReplayDir(dir string) OpOption {
+	return func(op *Op) {
+		op.replayDir = dir
 	}
 }
@@ -12,29 +12,30 @@ func TestApplyOpts(t *testing.T) {
 		err := op.applyOpts([]OpOption{})
 
 		assert.NoError(t, err)
-		assert.Equal(t, "ibstat", op.ibstatCommand)
-		assert.Equal(t, "ibstatus", op.ibstatusCommand)
+		assert.Equal(t, ProfileStandard, op.profile)
 		assert.False(t, op.debug)
 	})
 
-	t.Run("with ibstat command", func(t *testing.T) {
+	t.Run("with profile", func(t *testing.T) {
 		op := &Op{}
-		err := op.applyOpts([]OpOption{WithIbstatCommand("/custom/ibstat")})
+		assert.NoError(t, op.applyOpts([]OpOption{WithProfile(ProfileDeep)}))
+		assert.Equal(t, ProfileDeep, op.profile)
 
-		assert.NoError(t, err)
-		assert.Equal(t, "/custom/ibstat", op.ibstatCommand)
-		assert.Equal(t, "ibstatus", op.ibstatusCommand)
-		assert.False(t, op.debug)
+		op = &Op{}
+		assert.ErrorIs(t, op.applyOpts([]OpOption{WithProfile("fast")}), ErrUnknownProfile)
 	})
 
-	t.Run("with ibstatus command", func(t *testing.T) {
+	t.Run("with record or replay dir", func(t *testing.T) {
 		op := &Op{}
-		err := op.applyOpts([]OpOption{WithIbstatusCommand("/custom/ibstatus")})
+		assert.NoError(t, op.applyOpts([]OpOption{WithRecordDir("/tmp/rec")}))
+		assert.Equal(t, "/tmp/rec", op.recordDir)
 
-		assert.NoError(t, err)
-		assert.Equal(t, "ibstat", op.ibstatCommand)
-		assert.Equal(t, "/custom/ibstatus", op.ibstatusCommand)
-		assert.False(t, op.debug)
+		op = &Op{}
+		assert.NoError(t, op.applyOpts([]OpOption{WithReplayDir("/tmp/rec")}))
+		assert.Equal(t, "/tmp/rec", op.replayDir)
+
+		op = &Op{}
+		assert.ErrorIs(t, op.applyOpts([]OpOption{WithRecordDir("/tmp/a"), WithReplayDir("/tmp/b")}), ErrRecordAndReplay)
 	})
 
 	t.Run("with debug", func(t *testing.T) {
@@ -42,42 +43,22 @@ func TestApplyOpts(t *testing.T) {
 		err := op.applyOpts([]OpOption{WithDebug(true)})
 
 		assert.NoError(t, err)
-		assert.Equal(t, "ibstat", op.ibstatCommand)
-		assert.Equal(t, "ibstatus", op.ibstatusCommand)
 		assert.True(t, op.debug)
 	})
 
 	t.Run("with multiple options", func(t *testing.T) {
 		op := &Op{}
 		err := op.applyOpts([]OpOption{
-			WithIbstatCommand("/custom/ibstat"),
-			WithIbstatusCommand("/custom/ibstatus"),
+			WithProfile(ProfileQuick),
 			WithDebug(true),
 		})
 
 		assert.NoError(t, err)
-		assert.Equal(t, "/custom/ibstat", op.ibstatCommand)
-		assert.Equal(t, "/custom/ibstatus", op.ibstatusCommand)
+		assert.Equal(t, ProfileQuick, op.profile)
 		assert.True(t, op.debug)
 	})
 }
 
-func TestWithIbstatCommand(t *testing.T) {
-	opt := WithIbstatCommand("/test/ibstat")
-	op := &Op{}
-	opt(op)
-
-	assert.Equal(t, "/test/ibstat", op.ibstatCommand)
-}
-
-func TestWithIbstatusCommand(t *testing.T) {
-	opt := WithIbstatusCommand("/test/ibstatus")
-	op := &Op{}
-	opt(op)
-
-	assert.Equal(t, "/test/ibstatus", op.ibstatusCommand)
-}
-
 func TestWithDebug(t *testing.T) {
 	opt := WithDebug(true)
 	op := &Op{}
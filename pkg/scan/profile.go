@@ -0,0 +1,106 @@
+package scan
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	componentsacceleratornvidiabadenvs "github.com/leptonai/gpud/components/accelerator/nvidia/bad-envs"
+	componentsacceleratornvidiaclockspeed "github.com/leptonai/gpud/components/accelerator/nvidia/clock-speed"
+	componentsacceleratornvidiaecc "github.com/leptonai/gpud/components/accelerator/nvidia/ecc"
+	componentsacceleratornvidiafallenoffbus "github.com/leptonai/gpud/components/accelerator/nvidia/fallen-off-bus"
+	componentsacceleratornvidiagpm "github.com/leptonai/gpud/components/accelerator/nvidia/gpm"
+	componentsacceleratornvidiagspfirmwaremode "github.com/leptonai/gpud/components/accelerator/nvidia/gsp-firmware-mode"
+	componentsacceleratornvidiahwslowdown "github.com/leptonai/gpud/components/accelerator/nvidia/hw-slowdown"
+	componentsacceleratornvidiamemory "github.com/leptonai/gpud/components/accelerator/nvidia/memory"
+	componentsacceleratornvidianvlink "github.com/leptonai/gpud/components/accelerator/nvidia/nvlink"
+	componentsacceleratornvidiapersistencemode "github.com/leptonai/gpud/components/accelerator/nvidia/persistence-mode"
+	componentsacceleratornvidiapower "github.com/leptonai/gpud/components/accelerator/nvidia/power"
+	componentsacceleratornvidiaprocesses "github.com/leptonai/gpud/components/accelerator/nvidia/processes"
+	componentsacceleratornvidiaremappedrows "github.com/leptonai/gpud/components/accelerator/nvidia/remapped-rows"
+	componentsacceleratornvidiatemperature "github.com/leptonai/gpud/components/accelerator/nvidia/temperature"
+	componentsacceleratornvidiautilization "github.com/leptonai/gpud/components/accelerator/nvidia/utilization"
+	componentscpu "github.com/leptonai/gpud/components/cpu"
+	componentsmemory "github.com/leptonai/gpud/components/memory"
+	componentsos "github.com/leptonai/gpud/components/os"
+)
+
+// Profile selects which checks the scan runs, and how long it may take.
+type Profile string
+
+const (
+	// ProfileQuick only runs the checks that read the NVML or the
+	// in-memory/procfs data, without running any external command.
+	// Time budget is 30 seconds.
+	ProfileQuick Profile = "quick"
+	// ProfileStandard runs all the supported components.
+	// Time budget is 2 minutes.
+	ProfileStandard Profile = "standard"
+	// ProfileDeep runs all the supported components, and the deep
+	// diagnostics (DCGM diag, GPU bandwidth benchmark, ibdiagnet)
+	// if the tools are installed.
+	// Time budget is 15 minutes.
+	ProfileDeep Profile = "deep"
+)
+
+// DefaultProfile is the scan profile used when none is specified.
+const DefaultProfile = ProfileStandard
+
+var ErrUnknownProfile = errors.New("unknown scan profile")
+
+// ParseProfile parses the profile name, defaulting to the standard profile if empty.
+func ParseProfile(s string) (Profile, error) {
+	switch Profile(s) {
+	case "":
+		return DefaultProfile, nil
+	case ProfileQuick, ProfileStandard, ProfileDeep:
+		return Profile(s), nil
+	default:
+		return "", fmt.Errorf("%w %q (must be one of %q, %q, %q)", ErrUnknownProfile, s, ProfileQuick, ProfileStandard, ProfileDeep)
+	}
+}
+
+// Budget returns the maximum duration the scan of the profile may take.
+func (p Profile) Budget() time.Duration {
+	switch p {
+	case ProfileQuick:
+		return 30 * time.Second
+	case ProfileDeep:
+		return 15 * time.Minute
+	default:
+		return 2 * time.Minute
+	}
+}
+
+// IncludesComponent returns true if the profile runs the component.
+func (p Profile) IncludesComponent(name string) bool {
+	if p != ProfileQuick {
+		return true
+	}
+	_, ok := quickComponents[name]
+	return ok
+}
+
+// quickComponents are the components that only read the NVML
+// or the in-memory/procfs data.
+var quickComponents = map[string]struct{}{
+	componentscpu.Name:    {},
+	componentsmemory.Name: {},
+	componentsos.Name:     {},
+
+	componentsacceleratornvidiabadenvs.Name:         {},
+	componentsacceleratornvidiaclockspeed.Name:      {},
+	componentsacceleratornvidiaecc.Name:             {},
+	componentsacceleratornvidiafallenoffbus.Name:    {},
+	componentsacceleratornvidiagpm.Name:             {},
+	componentsacceleratornvidiagspfirmwaremode.Name: {},
+	componentsacceleratornvidiahwslowdown.Name:      {},
+	componentsacceleratornvidiamemory.Name:          {},
+	componentsacceleratornvidianvlink.Name:          {},
+	componentsacceleratornvidiapersistencemode.Name: {},
+	componentsacceleratornvidiapower.Name:           {},
+	componentsacceleratornvidiaprocesses.Name:       {},
+	componentsacceleratornvidiaremappedrows.Name:    {},
+	componentsacceleratornvidiatemperature.Name:     {},
+	componentsacceleratornvidiautilization.Name:     {},
+}
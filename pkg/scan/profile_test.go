@@ -0,0 +1,135 @@
+package scan
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	componentsacceleratornvidiamofed "github.com/leptonai/gpud/components/accelerator/nvidia/mofed"
+	"github.com/leptonai/gpud/components/all"
+	componentsdisk "github.com/leptonai/gpud/components/disk"
+	"github.com/leptonai/gpud/pkg/toolpath"
+)
+
+func TestParseProfile(t *testing.T) {
+	p, err := ParseProfile("")
+	require.NoError(t, err)
+	assert.Equal(t, ProfileStandard, p)
+
+	for _, s := range []string{"quick", "standard", "deep"} {
+		p, err = ParseProfile(s)
+		require.NoError(t, err)
+		assert.Equal(t, Profile(s), p)
+	}
+
+	_, err = ParseProfile("fast")
+	assert.ErrorIs(t, err, ErrUnknownProfile)
+}
+
+func TestProfileBudget(t *testing.T) {
+	assert.Equal(t, 30*time.Second, ProfileQuick.Budget())
+	assert.Equal(t, 2*time.Minute, ProfileStandard.Budget())
+	assert.Equal(t, 15*time.Minute, ProfileDeep.Budget())
+	assert.Less(t, ProfileQuick.Budget(), ProfileStandard.Budget())
+}
+
+func TestProfileIncludesComponent(t *testing.T) {
+	known := make(map[string]struct{})
+	for _, c := range all.All() {
+		known[c.Name] = struct{}{}
+	}
+	for name := range quickComponents {
+		_, ok := known[name]
+		assert.True(t, ok, "quick component %q is not registered", name)
+	}
+
+	assert.False(t, ProfileQuick.IncludesComponent(componentsdisk.Name))
+	assert.True(t, ProfileStandard.IncludesComponent(componentsdisk.Name))
+	assert.True(t, ProfileDeep.IncludesComponent(componentsdisk.Name))
+}
+
+func TestRunWithinBudget(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	result, err := runWithinBudget(ctx, ProfileQuick, func() components.CheckResult {
+		return &mockCheckResult{summary: "ok", healthStateType: apiv1.HealthStateTypeHealthy}
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", result.Summary())
+
+	_, err = runWithinBudget(ctx, ProfileQuick, func() components.CheckResult {
+		time.Sleep(time.Second)
+		return nil
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeded its time budget")
+
+	cctx, ccancel := context.WithCancel(context.Background())
+	ccancel()
+	_, err = runWithinBudget(cctx, ProfileQuick, func() components.CheckResult { return nil })
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestNotRunReason(t *testing.T) {
+	missing := deepCheck{name: "missing", command: []string{"gpud-deep-check-does-not-exist"}}
+	assert.Equal(t, "gpud-deep-check-does-not-exist not found", notRunReason(missing, true))
+
+	echo := deepCheck{name: "echo", command: []string{"echo"}, requiresGPU: true}
+	assert.Empty(t, notRunReason(echo, true))
+	assert.Equal(t, "no NVIDIA GPU detected", notRunReason(echo, false))
+
+	// the deep check tools are located via the tool path overrides
+	t.Cleanup(func() { require.NoError(t, toolpath.SetOverrides(nil)) })
+	bin := filepath.Join(t.TempDir(), "dcgmi")
+	require.NoError(t, os.WriteFile(bin, []byte("#!/bin/sh\n"), 0755))
+	require.NoError(t, toolpath.SetOverrides(map[string]string{"dcgmi": bin}))
+	assert.Empty(t, notRunReason(deepCheck{name: "dcgm-diag", command: []string{"dcgmi", "diag", "-r", "2"}}, true))
+}
+
+func TestRunDeepCheck(t *testing.T) {
+	cr := runDeepCheck(context.Background(), deepCheck{name: "missing", command: []string{"gpud-deep-check-does-not-exist"}})
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+
+	cr = runDeepCheck(context.Background(), deepCheck{name: "echo", command: []string{"echo", "hello"}})
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "hello", cr.String())
+
+	cr = runDeepCheck(context.Background(), deepCheck{name: "false", command: []string{"false"}})
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+}
+
+func TestTailLines(t *testing.T) {
+	assert.Equal(t, "a\nb", tailLines("a\nb", 3))
+	assert.Equal(t, "b\nc", tailLines("a\nb\nc", 2))
+}
+
+func TestRequiresGPU(t *testing.T) {
+	assert.False(t, requiresGPU(componentsdisk.Name))
+	assert.True(t, requiresGPU("accelerator-nvidia-ecc"))
+
+	// the MOFED stack is still checked without the GPUs (e.g., the storage nodes),
+	// while the other nvidia components are skipped
+	found := 0
+	for _, c := range all.All() {
+		switch c.Name {
+		case componentsacceleratornvidiamofed.Name:
+			found++
+			assert.False(t, c.RequiresGPU)
+			assert.False(t, requiresGPU(c.Name))
+		default:
+			if strings.Contains(c.Name, "nvidia") {
+				assert.True(t, requiresGPU(c.Name), c.Name)
+			}
+		}
+	}
+	assert.Equal(t, 1, found)
+}
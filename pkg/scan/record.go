@@ -0,0 +1,312 @@
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nvidiacommon "github.com/leptonai/gpud/pkg/config/common"
+	"github.com/leptonai/gpud/pkg/log"
+	nvidiainfiniband "github.com/leptonai/gpud/pkg/nvidia-query/infiniband"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	nvmllib "github.com/leptonai/gpud/pkg/nvidia-query/nvml/lib"
+	nvmlrecorder "github.com/leptonai/gpud/pkg/nvidia-query/nvml/recorder"
+	"github.com/leptonai/gpud/pkg/pci"
+	"github.com/leptonai/gpud/pkg/toolexec"
+	"github.com/leptonai/gpud/pkg/toolpath"
+	"github.com/leptonai/gpud/version"
+)
+
+// The files in the recording directory.
+const (
+	recordManifestFile  = "manifest.json"
+	recordNVMLFile      = "nvml.json"
+	recordResultFile    = "result.json"
+	recordIbstatFile    = "ibstat.txt"
+	recordIbstatusFile  = "ibstatus.txt"
+	recordNvidiaSMIFile = "nvidia-smi.txt"
+	recordSysfsDir      = "sysfs"
+	recordIBSysfsDir    = "sysfs-infiniband"
+	recordInterrupts    = "interrupts.txt"
+)
+
+// pciConfigHeaderBytes is the size of the PCI config space header to snapshot,
+// which is readable without the root privileges.
+const pciConfigHeaderBytes = 64
+
+// nvidiaVendorID is the PCI vendor ID of NVIDIA.
+const nvidiaVendorID = "0x10de"
+
+// pciDeviceAttributes are the PCI device attributes read by the components,
+// other than the config space header.
+var pciDeviceAttributes = []string{"vendor", "device", "class"}
+
+// ibDeviceAttributes and ibPortAttributes are the infiniband device and port
+// attributes read by the components, other than the port counters.
+var (
+	ibDeviceAttributes = []string{"hca_type", "fw_ver", "hw_rev", "node_guid", "sys_image_guid"}
+	ibPortAttributes   = []string{"state", "phys_state", "rate", "link_layer", "lid", "sm_lid", filepath.Join("gids", "0")}
+)
+
+// Manifest describes the recording.
+type Manifest struct {
+	// Version is the gpud version that recorded.
+	Version    string      `json:"version"`
+	RecordedAt metav1.Time `json:"recorded_at"`
+	Profile    Profile     `json:"profile"`
+	// Tools are the recorded outputs of the external tools,
+	// keyed by the tool name (e.g., "ibstat").
+	Tools map[string]RecordedTool `json:"tools,omitempty"`
+}
+
+// RecordedTool is the recorded output of the external tool.
+type RecordedTool struct {
+	Command string `json:"command"`
+	// File is the recorded output file in the recording directory.
+	// Empty if the tool is not found.
+	File string `json:"file,omitempty"`
+	// Error is the error of the tool, if failed.
+	Error string `json:"error,omitempty"`
+}
+
+// recordSession records the raw inputs of the scan (the NVML calls,
+// the external tool outputs, the sysfs snapshots) and the scan result
+// into the directory, to replay the scan with.
+type recordSession struct {
+	dir      string
+	recorder *nvmlrecorder.Recorder
+	manifest Manifest
+}
+
+func newRecordSession(dir string, profile Profile) (*recordSession, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &recordSession{
+		dir:      dir,
+		recorder: nvmlrecorder.New(),
+		manifest: Manifest{
+			Version:    version.Version,
+			RecordedAt: metav1.NewTime(time.Now().UTC()),
+			Profile:    profile,
+			Tools:      make(map[string]RecordedTool),
+		},
+	}, nil
+}
+
+// newNVMLInstance creates the NVML instance that records the NVML calls.
+func (rs *recordSession) newNVMLInstance() (nvidianvml.Instance, error) {
+	lib, err := nvmllib.New(nvmllib.WithNVML(rs.recorder.Wrap(nvml.New())))
+	if err != nil {
+		if errors.Is(err, nvmllib.ErrNVMLNotFound) {
+			return nvidianvml.NewNoOp(), nil
+		}
+		return nil, err
+	}
+	return nvidianvml.NewWithLibrary(lib)
+}
+
+// recordTools records the outputs of the external tools, and returns the
+// tool overwrites to evaluate the recorded outputs with, so that the scan
+// evaluates the same outputs as the replay.
+func (rs *recordSession) recordTools(ctx context.Context, overwrites nvidiacommon.ToolOverwrites) nvidiacommon.ToolOverwrites {
+	ibstat, err := nvidiainfiniband.GetIbstatOutput(ctx, []string{overwrites.IbstatCommand})
+	if ibstat != nil {
+		overwrites.IbstatCommand = rs.recordTool("ibstat", overwrites.IbstatCommand, recordIbstatFile, ibstat.Raw, err)
+	}
+	ibstatus, err := nvidiainfiniband.GetIbstatusOutput(ctx, []string{overwrites.IbstatusCommand})
+	if ibstatus != nil {
+		overwrites.IbstatusCommand = rs.recordTool("ibstatus", overwrites.IbstatusCommand, recordIbstatusFile, ibstatus.Raw, err)
+	}
+
+	// only for the humans to investigate, not evaluated by any component
+	if _, err := toolpath.Locate("nvidia-smi"); err == nil {
+		res, err := toolexec.Run(ctx, []string{"nvidia-smi", "-q"})
+		if res != nil {
+			rs.recordTool("nvidia-smi", "nvidia-smi -q", recordNvidiaSMIFile, strings.TrimSpace(string(res.Output)), err)
+		}
+	}
+
+	return overwrites
+}
+
+// recordTool writes the tool output, and returns the command to print the
+// recorded output with (failing if the tool failed).
+// Returns the original command if the output cannot be written.
+func (rs *recordSession) recordTool(name string, command string, file string, output string, toolErr error) string {
+	t := RecordedTool{Command: command, File: file}
+	if toolErr != nil {
+		t.Error = toolErr.Error()
+	}
+
+	path := filepath.Join(rs.dir, file)
+	if err := os.WriteFile(path, []byte(output), 0644); err != nil {
+		log.Logger.Warnw("failed to record tool output", "tool", name, "error", err)
+		return command
+	}
+	rs.manifest.Tools[name] = t
+
+	return replayToolCommand(path, t)
+}
+
+// recordSysfs snapshots the sysfs attributes read by the components:
+// the NVIDIA PCI devices (including the GPUs not visible to NVML, e.g., bound to vfio),
+// the interrupt counts, and the infiniband devices and ports.
+// The attributes failed to read are not recorded.
+func (rs *recordSession) recordSysfs() {
+	rs.recordPCISysfs(pci.DefaultSysfsRoot)
+	rs.recordFile(pci.DefaultProcInterrupts, filepath.Join(rs.dir, recordInterrupts))
+	rs.recordInfinibandSysfs(nvidiainfiniband.DefaultClassDir)
+}
+
+func (rs *recordSession) recordPCISysfs(sysfsRoot string) {
+	devs, err := pci.ListSysfsDevices(sysfsRoot, nvidiaVendorID)
+	if err != nil {
+		log.Logger.Warnw("failed to list pci devices, skipping sysfs snapshot", "error", err)
+		return
+	}
+
+	for _, dev := range devs {
+		srcDir := filepath.Join(sysfsRoot, "devices", dev.BusID)
+		devDir := filepath.Join(rs.dir, recordSysfsDir, "devices", dev.BusID)
+		if err := os.MkdirAll(filepath.Join(devDir, "msi_irqs"), 0755); err != nil {
+			log.Logger.Warnw("failed to record sysfs snapshot", "error", err)
+			continue
+		}
+
+		b, err := readHead(filepath.Join(srcDir, "config"), pciConfigHeaderBytes)
+		if err != nil {
+			log.Logger.Warnw("failed to read pci config space", "busID", dev.BusID, "error", err)
+		} else if err := os.WriteFile(filepath.Join(devDir, "config"), b, 0644); err != nil {
+			log.Logger.Warnw("failed to record sysfs snapshot", "error", err)
+		}
+		for _, attr := range pciDeviceAttributes {
+			rs.recordFile(filepath.Join(srcDir, attr), filepath.Join(devDir, attr))
+		}
+
+		// only the driver name of the link target is read
+		if dev.Driver != "" {
+			if err := os.Symlink(filepath.Join("..", "..", "drivers", dev.Driver), filepath.Join(devDir, "driver")); err != nil {
+				log.Logger.Warnw("failed to record sysfs snapshot", "error", err)
+			}
+		}
+		for _, irq := range dev.MSIIRQs {
+			if err := os.WriteFile(filepath.Join(devDir, "msi_irqs", strconv.Itoa(irq)), nil, 0644); err != nil {
+				log.Logger.Warnw("failed to record sysfs snapshot", "error", err)
+			}
+		}
+	}
+}
+
+func (rs *recordSession) recordInfinibandSysfs(classDir string) {
+	devices, err := os.ReadDir(classDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Logger.Warnw("failed to list infiniband devices, skipping sysfs snapshot", "error", err)
+		}
+		return
+	}
+
+	for _, dev := range devices {
+		srcDir := filepath.Join(classDir, dev.Name())
+		devDir := filepath.Join(rs.dir, recordIBSysfsDir, dev.Name())
+		for _, attr := range ibDeviceAttributes {
+			rs.recordFile(filepath.Join(srcDir, attr), filepath.Join(devDir, attr))
+		}
+
+		ports, err := os.ReadDir(filepath.Join(srcDir, "ports"))
+		if err != nil {
+			continue
+		}
+		for _, port := range ports {
+			srcPortDir := filepath.Join(srcDir, "ports", port.Name())
+			portDir := filepath.Join(devDir, "ports", port.Name())
+			for _, attr := range ibPortAttributes {
+				rs.recordFile(filepath.Join(srcPortDir, attr), filepath.Join(portDir, attr))
+			}
+
+			counters, err := os.ReadDir(filepath.Join(srcPortDir, "counters"))
+			if err != nil {
+				continue
+			}
+			for _, counter := range counters {
+				rs.recordFile(filepath.Join(srcPortDir, "counters", counter.Name()), filepath.Join(portDir, "counters", counter.Name()))
+			}
+		}
+	}
+}
+
+// recordFile copies the file (e.g., the sysfs attribute) into the recording,
+// skipping the file failed to read (e.g., the attribute not exposed by the driver).
+func (rs *recordSession) recordFile(src string, dst string) {
+	b, err := os.ReadFile(src)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		log.Logger.Warnw("failed to record file", "file", src, "error", err)
+		return
+	}
+	if err := os.WriteFile(dst, b, 0644); err != nil {
+		log.Logger.Warnw("failed to record file", "file", src, "error", err)
+	}
+}
+
+// finish writes the recorded NVML calls, the manifest, and the scan result as the golden file.
+func (rs *recordSession) finish(result *Result) error {
+	if err := writeJSON(filepath.Join(rs.dir, recordNVMLFile), rs.recorder.Recording()); err != nil {
+		return err
+	}
+	if err := writeJSON(filepath.Join(rs.dir, recordManifestFile), rs.manifest); err != nil {
+		return err
+	}
+	return writeJSON(filepath.Join(rs.dir, recordResultFile), result)
+}
+
+func writeJSON(path string, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0644)
+}
+
+func readHead(path string, n int) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	b := make([]byte, n)
+	read, err := io.ReadFull(f, b)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	return b[:read], nil
+}
+
+// replayToolCommand returns the command that prints the recorded output,
+// and fails if the tool failed when recorded.
+func replayToolCommand(path string, t RecordedTool) string {
+	cmd := "cat " + shellQuote(path)
+	if t.Error != "" {
+		cmd += "; exit 1"
+	}
+	return cmd
+}
+
+// shellQuote quotes the string as a single word for the shell,
+// in the single quotes that do not expand anything (e.g., "$", "`").
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,199 @@
+package scan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	componentsacceleratornvidiabadenvs "github.com/leptonai/gpud/components/accelerator/nvidia/bad-envs"
+	componentsacceleratornvidiainfiniband "github.com/leptonai/gpud/components/accelerator/nvidia/infiniband"
+	componentsacceleratornvidiapassthrough "github.com/leptonai/gpud/components/accelerator/nvidia/passthrough"
+	nvidiacommon "github.com/leptonai/gpud/pkg/config/common"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	nvmllib "github.com/leptonai/gpud/pkg/nvidia-query/nvml/lib"
+	nvmllibmock "github.com/leptonai/gpud/pkg/nvidia-query/nvml/lib/mock"
+	nvmlrecorder "github.com/leptonai/gpud/pkg/nvidia-query/nvml/recorder"
+)
+
+// ErrReplayMismatch is returned when the replayed health states differ from the recorded ones.
+var ErrReplayMismatch = errors.New("replayed health states differ from the recording")
+
+// ReplayDiff is the health state that differs between the recording and the replay.
+type ReplayDiff struct {
+	Component string `json:"component"`
+	Name      string `json:"name"`
+	// Recorded is the recorded health, empty if not recorded.
+	Recorded       apiv1.HealthStateType `json:"recorded"`
+	RecordedReason string                `json:"recorded_reason,omitempty"`
+	// Replayed is the replayed health, empty if not replayed.
+	Replayed       apiv1.HealthStateType `json:"replayed"`
+	ReplayedReason string                `json:"replayed_reason,omitempty"`
+}
+
+// replayComponents are the components whose inputs are fully recorded
+// (the NVML, the InfiniBand tools and sysfs, the PCI sysfs, the interrupt counts),
+// thus evaluated on replay. The other components read the host the replay runs on
+// (e.g., the kernel messages, the kernel modules, the services).
+var replayComponents = func() map[string]struct{} {
+	m := map[string]struct{}{
+		componentsacceleratornvidiainfiniband.Name:  {},
+		componentsacceleratornvidiapassthrough.Name: {},
+	}
+	for name := range quickComponents {
+		if requiresGPU(name) {
+			m[name] = struct{}{}
+		}
+	}
+	// reads the environment variables of the host
+	delete(m, componentsacceleratornvidiabadenvs.Name)
+	return m
+}()
+
+// replaySession replays the scan on the recorded inputs.
+type replaySession struct {
+	dir      string
+	manifest Manifest
+	nvml     nvmlrecorder.Recording
+	golden   Result
+}
+
+func loadReplaySession(dir string) (*replaySession, error) {
+	rp := &replaySession{dir: dir}
+	if err := readJSON(filepath.Join(dir, recordManifestFile), &rp.manifest); err != nil {
+		return nil, fmt.Errorf("failed to read recording manifest: %w", err)
+	}
+	if err := readJSON(filepath.Join(dir, recordNVMLFile), &rp.nvml); err != nil {
+		return nil, fmt.Errorf("failed to read recorded nvml calls: %w", err)
+	}
+	if err := readJSON(filepath.Join(dir, recordResultFile), &rp.golden); err != nil {
+		return nil, fmt.Errorf("failed to read recorded scan result: %w", err)
+	}
+	return rp, nil
+}
+
+// newNVMLInstance creates the NVML instance that returns the recorded results.
+func (rp *replaySession) newNVMLInstance() (nvidianvml.Instance, error) {
+	if len(rp.nvml.System) == 0 {
+		return nvidianvml.NewNoOp(), nil
+	}
+	lib, err := nvmllib.New(
+		nvmllib.WithNVML(nvmlrecorder.Replay(rp.nvml)),
+		nvmllib.WithPropertyExtractor(nvmllibmock.HasNvmlPropertyExtractor),
+	)
+	if err != nil {
+		// NVML was not found when recorded
+		if errors.Is(err, nvmllib.ErrNVMLNotFound) {
+			return nvidianvml.NewNoOp(), nil
+		}
+		return nil, err
+	}
+	return nvidianvml.NewWithLibrary(lib)
+}
+
+// toolOverwrites returns the commands that print the recorded tool outputs.
+// The tools not found when recorded are replayed as not found.
+func (rp *replaySession) toolOverwrites() nvidiacommon.ToolOverwrites {
+	var overwrites nvidiacommon.ToolOverwrites
+	if t, ok := rp.manifest.Tools["ibstat"]; ok {
+		overwrites.IbstatCommand = replayToolCommand(filepath.Join(rp.dir, t.File), t)
+	}
+	if t, ok := rp.manifest.Tools["ibstatus"]; ok {
+		overwrites.IbstatusCommand = replayToolCommand(filepath.Join(rp.dir, t.File), t)
+	}
+	return overwrites
+}
+
+// sysfsRoot returns the recorded sysfs directory of the PCI bus.
+func (rp *replaySession) sysfsRoot() string {
+	return filepath.Join(rp.dir, recordSysfsDir)
+}
+
+// interruptsFile returns the recorded interrupt counts.
+func (rp *replaySession) interruptsFile() string {
+	return filepath.Join(rp.dir, recordInterrupts)
+}
+
+// infinibandClassDir returns the recorded sysfs class directory of the infiniband devices.
+func (rp *replaySession) infinibandClassDir() string {
+	return filepath.Join(rp.dir, recordIBSysfsDir)
+}
+
+func (rp *replaySession) includesComponent(name string) bool {
+	_, ok := replayComponents[name]
+	return ok
+}
+
+// diff returns the health states of the replayed components
+// that differ from the recording, sorted by the component and the name.
+func (rp *replaySession) diff(replayed apiv1.GPUdComponentHealthStates) []ReplayDiff {
+	type key struct{ component, name string }
+	recordedStates := make(map[key]apiv1.HealthState)
+	for _, cs := range rp.golden.HealthStates {
+		if !rp.includesComponent(cs.Component) {
+			continue
+		}
+		for _, st := range cs.States {
+			recordedStates[key{cs.Component, st.Name}] = st
+		}
+	}
+	replayedStates := make(map[key]apiv1.HealthState)
+	for _, cs := range replayed {
+		for _, st := range cs.States {
+			replayedStates[key{cs.Component, st.Name}] = st
+		}
+	}
+
+	var diffs []ReplayDiff
+	for k, rec := range recordedStates {
+		rep, ok := replayedStates[k]
+		if ok && rep.Health == rec.Health {
+			continue
+		}
+		diffs = append(diffs, ReplayDiff{
+			Component:      k.component,
+			Name:           k.name,
+			Recorded:       rec.Health,
+			RecordedReason: rec.Reason,
+			Replayed:       rep.Health,
+			ReplayedReason: rep.Reason,
+		})
+	}
+	for k, rep := range replayedStates {
+		if _, ok := recordedStates[k]; ok {
+			continue
+		}
+		diffs = append(diffs, ReplayDiff{
+			Component:      k.component,
+			Name:           k.name,
+			Replayed:       rep.Health,
+			ReplayedReason: rep.Reason,
+		})
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].Component != diffs[j].Component {
+			return diffs[i].Component < diffs[j].Component
+		}
+		return diffs[i].Name < diffs[j].Name
+	})
+	return diffs
+}
+
+func printReplayDiffs(wr io.Writer, diffs []ReplayDiff) {
+	for _, d := range diffs {
+		fmt.Fprintf(wr, "  %s/%s: recorded %q (%s), replayed %q (%s)\n", d.Component, d.Name, d.Recorded, d.RecordedReason, d.Replayed, d.ReplayedReason)
+	}
+}
+
+func readJSON(path string, v any) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,218 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	componentsacceleratornvidiabadenvs "github.com/leptonai/gpud/components/accelerator/nvidia/bad-envs"
+	componentsacceleratornvidiainfiniband "github.com/leptonai/gpud/components/accelerator/nvidia/infiniband"
+	componentsacceleratornvidiapassthrough "github.com/leptonai/gpud/components/accelerator/nvidia/passthrough"
+	componentsacceleratornvidiaremappedrows "github.com/leptonai/gpud/components/accelerator/nvidia/remapped-rows"
+	componentsos "github.com/leptonai/gpud/components/os"
+	nvidiainfiniband "github.com/leptonai/gpud/pkg/nvidia-query/infiniband"
+	"github.com/leptonai/gpud/pkg/pci"
+	"github.com/leptonai/gpud/pkg/process"
+)
+
+func TestRecordReplaySession(t *testing.T) {
+	dir := t.TempDir()
+
+	rs, err := newRecordSession(dir, ProfileQuick)
+	require.NoError(t, err)
+
+	cmd := rs.recordTool("ibstat", "ibstat", recordIbstatFile, "CA 'mlx5_0'", nil)
+	assert.Equal(t, replayToolCommand(filepath.Join(dir, recordIbstatFile), RecordedTool{}), cmd)
+	cmd = rs.recordTool("ibstatus", "ibstatus", recordIbstatusFile, "", errors.New("exit status 1"))
+	assert.Contains(t, cmd, "exit 1")
+
+	golden := &Result{
+		HealthStates: apiv1.GPUdComponentHealthStates{
+			{
+				Component: componentsacceleratornvidiaremappedrows.Name,
+				States:    apiv1.HealthStates{{Name: componentsacceleratornvidiaremappedrows.Name, Health: apiv1.HealthStateTypeUnhealthy}},
+			},
+			{
+				// not replayed, thus not compared
+				Component: componentsos.Name,
+				States:    apiv1.HealthStates{{Name: componentsos.Name, Health: apiv1.HealthStateTypeUnhealthy}},
+			},
+		},
+	}
+	require.NoError(t, rs.finish(golden))
+
+	rp, err := loadReplaySession(dir)
+	require.NoError(t, err)
+	assert.Equal(t, ProfileQuick, rp.manifest.Profile)
+	assert.Equal(t, filepath.Join(dir, recordSysfsDir), rp.sysfsRoot())
+
+	overwrites := rp.toolOverwrites()
+	assert.Equal(t, rs.manifest.Tools["ibstat"], rp.manifest.Tools["ibstat"])
+	out := runShell(t, overwrites.IbstatCommand)
+	assert.Equal(t, "CA 'mlx5_0'", out)
+
+	// NVML was not recorded
+	nvmlInstance, err := rp.newNVMLInstance()
+	require.NoError(t, err)
+	assert.False(t, nvmlInstance.NVMLExists())
+
+	assert.Empty(t, rp.diff(apiv1.GPUdComponentHealthStates{
+		{
+			Component: componentsacceleratornvidiaremappedrows.Name,
+			States:    apiv1.HealthStates{{Name: componentsacceleratornvidiaremappedrows.Name, Health: apiv1.HealthStateTypeUnhealthy}},
+		},
+	}))
+
+	diffs := rp.diff(apiv1.GPUdComponentHealthStates{
+		{
+			Component: componentsacceleratornvidiaremappedrows.Name,
+			States:    apiv1.HealthStates{{Name: componentsacceleratornvidiaremappedrows.Name, Health: apiv1.HealthStateTypeHealthy}},
+		},
+		{
+			Component: componentsacceleratornvidiainfiniband.Name,
+			States:    apiv1.HealthStates{{Name: componentsacceleratornvidiainfiniband.Name, Health: apiv1.HealthStateTypeHealthy}},
+		},
+	})
+	require.Len(t, diffs, 2)
+	assert.Equal(t, componentsacceleratornvidiainfiniband.Name, diffs[0].Component)
+	assert.Empty(t, diffs[0].Recorded)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, diffs[0].Replayed)
+	assert.Equal(t, componentsacceleratornvidiaremappedrows.Name, diffs[1].Component)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, diffs[1].Recorded)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, diffs[1].Replayed)
+
+	buf := new(bytes.Buffer)
+	printReplayDiffs(buf, diffs)
+	assert.Contains(t, buf.String(), componentsacceleratornvidiaremappedrows.Name)
+}
+
+func TestLoadReplaySessionNotFound(t *testing.T) {
+	_, err := loadReplaySession(t.TempDir())
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestReplayComponents(t *testing.T) {
+	rp := &replaySession{}
+	assert.True(t, rp.includesComponent(componentsacceleratornvidiaremappedrows.Name))
+	assert.True(t, rp.includesComponent(componentsacceleratornvidiainfiniband.Name))
+	assert.True(t, rp.includesComponent(componentsacceleratornvidiapassthrough.Name))
+	assert.False(t, rp.includesComponent(componentsacceleratornvidiabadenvs.Name))
+	assert.False(t, rp.includesComponent(componentsos.Name))
+}
+
+func TestReplayToolCommandQuoted(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "it's $HOME `x`")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	path := filepath.Join(dir, recordIbstatFile)
+	require.NoError(t, os.WriteFile(path, []byte("CA 'mlx5_0'"), 0644))
+
+	assert.Equal(t, "CA 'mlx5_0'", runShell(t, replayToolCommand(path, RecordedTool{})))
+}
+
+func TestRecordPCISysfs(t *testing.T) {
+	sysfsRoot := t.TempDir()
+	writeSysfs := func(busID string, vendor string, driver string, irqs ...string) {
+		devDir := filepath.Join(sysfsRoot, "devices", busID)
+		require.NoError(t, os.MkdirAll(filepath.Join(devDir, "msi_irqs"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(devDir, "vendor"), []byte(vendor+"\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(devDir, "class"), []byte("0x030200\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(devDir, "config"), []byte{0xde, 0x10}, 0644))
+		if driver != "" {
+			require.NoError(t, os.Symlink(filepath.Join("..", "..", "..", "bus", "pci", "drivers", driver), filepath.Join(devDir, "driver")))
+		}
+		for _, irq := range irqs {
+			require.NoError(t, os.WriteFile(filepath.Join(devDir, "msi_irqs", irq), []byte("msix\n"), 0644))
+		}
+	}
+	writeSysfs("0000:01:00.0", nvidiaVendorID, "nvidia", "40", "41")
+	writeSysfs("0000:02:00.0", nvidiaVendorID, "vfio-pci")
+	writeSysfs("0000:03:00.0", "0x15b3", "mlx5_core")
+
+	rs, err := newRecordSession(t.TempDir(), ProfileStandard)
+	require.NoError(t, err)
+	rs.recordPCISysfs(sysfsRoot)
+
+	expected, err := pci.ListSysfsDevices(sysfsRoot, nvidiaVendorID)
+	require.NoError(t, err)
+	require.Len(t, expected, 2)
+	recorded, err := pci.ListSysfsDevices(filepath.Join(rs.dir, recordSysfsDir), nvidiaVendorID)
+	require.NoError(t, err)
+	assert.Equal(t, expected, recorded)
+
+	st, err := pci.GetDeviceStatus(filepath.Join(rs.dir, recordSysfsDir), "0000:01:00.0")
+	require.NoError(t, err)
+	assert.Equal(t, pci.DeviceStatusPresent, st)
+
+	// the other vendors are not recorded
+	_, err = os.Stat(filepath.Join(rs.dir, recordSysfsDir, "devices", "0000:03:00.0"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestRecordInfinibandSysfs(t *testing.T) {
+	classDir := t.TempDir()
+	portDir := filepath.Join(classDir, "mlx5_0", "ports", "1")
+	require.NoError(t, os.MkdirAll(filepath.Join(portDir, "counters"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(portDir, "gids"), 0755))
+	for file, value := range map[string]string{
+		filepath.Join(classDir, "mlx5_0", "fw_ver"):       "28.39.1002",
+		filepath.Join(classDir, "mlx5_0", "hca_type"):     "MT4129",
+		filepath.Join(portDir, "state"):                   "4: ACTIVE",
+		filepath.Join(portDir, "phys_state"):              "5: LinkUp",
+		filepath.Join(portDir, "rate"):                    "400 Gb/sec (4X NDR)",
+		filepath.Join(portDir, "link_layer"):              "InfiniBand",
+		filepath.Join(portDir, "counters", "link_downed"): "3",
+	} {
+		require.NoError(t, os.WriteFile(file, []byte(value+"\n"), 0644))
+	}
+
+	rs, err := newRecordSession(t.TempDir(), ProfileStandard)
+	require.NoError(t, err)
+	rs.recordInfinibandSysfs(classDir)
+	recordedDir := filepath.Join(rs.dir, recordIBSysfsDir)
+
+	expected, err := nvidiainfiniband.GetSysfsOutput(classDir)
+	require.NoError(t, err)
+	recorded, err := nvidiainfiniband.GetSysfsOutput(recordedDir)
+	require.NoError(t, err)
+	assert.Equal(t, expected, recorded)
+
+	expectedCounters, err := nvidiainfiniband.GetPortCounters(classDir)
+	require.NoError(t, err)
+	require.Len(t, expectedCounters, 1)
+	assert.Equal(t, uint64(3), expectedCounters[0].LinkDowned)
+	recordedCounters, err := nvidiainfiniband.GetPortCounters(recordedDir)
+	require.NoError(t, err)
+	assert.Equal(t, expectedCounters, recordedCounters)
+
+	// no infiniband device
+	rs.recordInfinibandSysfs(filepath.Join(t.TempDir(), "not-found"))
+}
+
+func TestReadHead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(path, []byte("0123456789"), 0644))
+
+	b, err := readHead(path, 4)
+	require.NoError(t, err)
+	assert.Equal(t, "0123", string(b))
+
+	b, err = readHead(path, 64)
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789", string(b))
+}
+
+func runShell(t *testing.T, command string) string {
+	p, err := process.New(process.WithCommand(command), process.WithRunAsBashScript())
+	require.NoError(t, err)
+	defer func() { _ = p.Close(context.Background()) }()
+	b, err := p.StartAndWaitForCombinedOutput(context.Background())
+	require.NoError(t, err)
+	return string(b)
+}
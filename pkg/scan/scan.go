@@ -2,20 +2,29 @@ package scan
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"runtime"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	cmdcommon "github.com/leptonai/gpud/cmd/common"
 	"github.com/leptonai/gpud/components"
+	componentsacceleratornvidiafallenoffbus "github.com/leptonai/gpud/components/accelerator/nvidia/fallen-off-bus"
 	componentsacceleratornvidiainfiniband "github.com/leptonai/gpud/components/accelerator/nvidia/infiniband"
+	componentsacceleratornvidiapassthrough "github.com/leptonai/gpud/components/accelerator/nvidia/passthrough"
 	"github.com/leptonai/gpud/components/all"
 	nvidiacommon "github.com/leptonai/gpud/pkg/config/common"
 	"github.com/leptonai/gpud/pkg/log"
 	pkgmachineinfo "github.com/leptonai/gpud/pkg/machine-info"
 	nvidiainfiniband "github.com/leptonai/gpud/pkg/nvidia-query/infiniband"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	pkgoutput "github.com/leptonai/gpud/pkg/output"
+	pkgprobecache "github.com/leptonai/gpud/pkg/probecache"
 )
 
 func printSummary(result components.CheckResult) {
@@ -28,26 +37,117 @@ func printSummary(result components.CheckResult) {
 	println()
 }
 
-// Runs the scan operations.
+// Result is the machine-readable result of the scan.
+type Result struct {
+	Profile     Profile            `json:"profile"`
+	MachineInfo *apiv1.MachineInfo `json:"machine_info"`
+	// HealthStates are the health states of the checked components,
+	// followed by the deep checks if any.
+	HealthStates apiv1.GPUdComponentHealthStates `json:"health_states"`
+	// SkippedAccelerators is the number of the accelerator components
+	// skipped for no NVIDIA GPU detected.
+	SkippedAccelerators int `json:"skipped_accelerators,omitempty"`
+	// NotRun are the deep checks not run on the host (e.g., the tool is not installed),
+	// in the "<check>: <reason>" format.
+	NotRun []string `json:"not_run,omitempty"`
+	// ReplayDiffs are the health states that differ from the recording,
+	// only set when replaying the recorded scan.
+	ReplayDiffs []ReplayDiff    `json:"replay_diffs,omitempty"`
+	Took        metav1.Duration `json:"took"`
+}
+
+func (r *Result) add(result components.CheckResult) {
+	r.HealthStates = append(r.HealthStates, apiv1.ComponentHealthStates{
+		Component: result.ComponentName(),
+		States:    result.HealthStates(),
+	})
+}
+
+// Runs the scan operations, within the time budget of the scan profile.
 func Scan(ctx context.Context, opts ...OpOption) error {
+	_, err := Run(ctx, opts...)
+	return err
+}
+
+// Run runs the scan operations, within the time budget of the scan profile,
+// and returns the scan result.
+// The result is returned along with the error if the replayed health states
+// differ from the recording, or the upload fails.
+func Run(ctx context.Context, opts ...OpOption) (*Result, error) {
 	op := &Op{}
 	if err := op.applyOpts(opts); err != nil {
-		return err
+		return nil, err
 	}
 
-	fmt.Printf("\n\n%s scanning the host (GOOS %s)\n\n", cmdcommon.InProgress, runtime.GOOS)
-
-	nvmlInstance, err := nvidianvml.New()
+	var (
+		recording *recordSession
+		replaying *replaySession
+		err       error
+	)
+	switch {
+	case op.recordDir != "":
+		recording, err = newRecordSession(op.recordDir, op.profile)
+	case op.replayDir != "":
+		replaying, err = loadReplaySession(op.replayDir)
+		if err == nil {
+			// replay the same checks as recorded
+			op.profile = replaying.manifest.Profile
+		}
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	mi, err := pkgmachineinfo.GetMachineInfo(nvmlInstance)
+	budget := op.profile.Budget()
+	ctx, cancel := context.WithTimeout(ctx, budget)
+	defer cancel()
+
+	// the machine-readable output only prints the result
+	machineReadable := op.output.IsMachineReadable()
+	var wr io.Writer = os.Stdout
+	if machineReadable {
+		wr = io.Discard
+	}
+	scanResult := &Result{Profile: op.profile}
+
+	start := time.Now()
+	fmt.Fprintf(wr, "\n\n%s scanning the host (GOOS %s, profile %s, time budget %s)\n\n", cmdcommon.InProgress, runtime.GOOS, op.profile, budget)
+
+	// single NVML existence probe shared by all the components
+	var nvmlInstance nvidianvml.Instance
+	switch {
+	case recording != nil:
+		nvmlInstance, err = recording.newNVMLInstance()
+	case replaying != nil:
+		nvmlInstance, err = replaying.newNVMLInstance()
+	default:
+		nvmlInstance, err = nvidianvml.New()
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
-	fmt.Printf("\n%s machine info\n", cmdcommon.CheckMark)
-	mi.RenderTable(os.Stdout)
+	hasGPU := nvmlInstance.NVMLExists() && nvmlInstance.ProductName() != ""
+
+	pkgprobecache.LoadExecutablePaths(ctx, op.probeCache)
+	defer func() {
+		if err := pkgprobecache.SaveExecutablePaths(context.Background(), op.probeCache); err != nil {
+			log.Logger.Warnw("failed to cache executable paths", "error", err)
+		}
+	}()
+
+	var mi *apiv1.MachineInfo
+	if replaying != nil && replaying.golden.MachineInfo != nil {
+		mi = replaying.golden.MachineInfo
+		fmt.Fprintf(wr, "%s replaying the scan recorded at %s (gpud %s)\n", cmdcommon.InProgress, replaying.manifest.RecordedAt.Format(time.RFC3339), replaying.manifest.Version)
+	} else {
+		mi, err = pkgmachineinfo.GetMachineInfoWithCache(ctx, nvmlInstance, op.probeCache)
+		if err != nil {
+			return nil, err
+		}
+	}
+	scanResult.MachineInfo = mi
+	fmt.Fprintf(wr, "\n%s machine info\n", cmdcommon.CheckMark)
+	mi.RenderTable(wr)
 
 	if mi.GPUInfo != nil && mi.GPUInfo.Product != "" {
 		threshold, err := nvidiainfiniband.SupportsInfinibandPortRate(mi.GPUInfo.Product)
@@ -57,16 +157,25 @@ func Scan(ctx context.Context, opts ...OpOption) error {
 		}
 	}
 
+	toolOverwrites := nvidiacommon.ToolOverwrites{}.WithDefaults()
+	switch {
+	case recording != nil:
+		toolOverwrites = recording.recordTools(ctx, toolOverwrites)
+		recording.recordSysfs()
+	case replaying != nil:
+		toolOverwrites = replaying.toolOverwrites()
+		componentsacceleratornvidiafallenoffbus.SetDefaultSysfsRoot(replaying.sysfsRoot())
+		componentsacceleratornvidiapassthrough.SetDefaultSysfs(replaying.sysfsRoot(), replaying.interruptsFile())
+		componentsacceleratornvidiainfiniband.SetDefaultClassDir(replaying.infinibandClassDir())
+	}
+
 	gpudInstance := &components.GPUdInstance{
 		RootCtx: ctx,
 
 		MachineID: mi.MachineID,
 
-		NVMLInstance: nvmlInstance,
-		NVIDIAToolOverwrites: nvidiacommon.ToolOverwrites{
-			IbstatCommand:   op.ibstatCommand,
-			IbstatusCommand: op.ibstatusCommand,
-		},
+		NVMLInstance:         nvmlInstance,
+		NVIDIAToolOverwrites: toolOverwrites,
 
 		EventStore:       nil,
 		RebootEventStore: nil,
@@ -75,17 +184,172 @@ func Scan(ctx context.Context, opts ...OpOption) error {
 		MountTargets: []string{"/var/lib/kubelet"},
 	}
 
-	for _, c := range all.All() {
+	include := func(name string) bool {
+		if !op.profile.IncludesComponent(name) {
+			return false
+		}
+		return replaying == nil || replaying.includesComponent(name)
+	}
+	skippedAccelerators, err := checkComponents(ctx, op.profile, all.All(), include, gpudInstance, hasGPU, func(result components.CheckResult) {
+		scanResult.add(result)
+		if !machineReadable {
+			printSummary(result)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	scanResult.SkippedAccelerators = skippedAccelerators
+	if skippedAccelerators > 0 {
+		fmt.Fprintf(wr, "%s no NVIDIA GPU detected, skipped %d accelerator component(s)\n\n", cmdcommon.CheckMark, skippedAccelerators)
+	}
+
+	// the deep diagnostics run the tools on the host, not recorded
+	if op.profile == ProfileDeep && replaying == nil {
+		for _, dc := range deepChecks {
+			if reason := notRunReason(dc, hasGPU); reason != "" {
+				scanResult.NotRun = append(scanResult.NotRun, fmt.Sprintf("%s: %s", dc.name, reason))
+				fmt.Fprintf(wr, "%s %s not run (%s)\n\n", cmdcommon.WarningSign, dc.name, reason)
+				continue
+			}
+			result, err := runWithinBudget(ctx, op.profile, func() components.CheckResult {
+				return runDeepCheck(ctx, dc)
+			})
+			if err != nil {
+				return nil, err
+			}
+			scanResult.add(result)
+			if !machineReadable {
+				printSummary(result)
+			}
+		}
+	}
+
+	took := time.Since(start).Round(time.Millisecond)
+	scanResult.Took = metav1.Duration{Duration: took}
+
+	if recording != nil {
+		if err := recording.finish(scanResult); err != nil {
+			return nil, fmt.Errorf("failed to write recording: %w", err)
+		}
+		fmt.Fprintf(wr, "%s recorded the scan to %s\n", cmdcommon.CheckMark, op.recordDir)
+	}
+
+	var replayErr error
+	if replaying != nil {
+		scanResult.ReplayDiffs = replaying.diff(scanResult.HealthStates)
+		if len(scanResult.ReplayDiffs) > 0 {
+			replayErr = fmt.Errorf("%w (%d health state(s))", ErrReplayMismatch, len(scanResult.ReplayDiffs))
+			fmt.Fprintf(wr, "%s %d health state(s) differ from the recording\n", cmdcommon.WarningSign, len(scanResult.ReplayDiffs))
+			printReplayDiffs(wr, scanResult.ReplayDiffs)
+		} else {
+			fmt.Fprintf(wr, "%s replayed health states match the recording\n", cmdcommon.CheckMark)
+		}
+	}
+
+	if machineReadable {
+		if err := pkgoutput.Render(os.Stdout, op.output, scanResult, nil); err != nil {
+			return nil, err
+		}
+	} else {
+		fmt.Printf("\n\n%s scan complete in %s\n\n", cmdcommon.CheckMark, took)
+	}
+
+	if op.uploader != nil {
+		// the scan context may have already timed out,
+		// and the replay mismatches are uploaded as well to compare with the recording
+		if err := uploadResult(context.Background(), op.uploader, scanResult); err != nil {
+			return scanResult, errors.Join(replayErr, err)
+		}
+	}
+	return scanResult, replayErr
+}
+
+// checkComponents runs the checks of the included components within the profile budget,
+// and returns the number of the components skipped without the GPUs.
+func checkComponents(
+	ctx context.Context,
+	profile Profile,
+	cs []all.Component,
+	include func(name string) bool,
+	gpudInstance *components.GPUdInstance,
+	hasGPU bool,
+	onResult func(components.CheckResult),
+) (int, error) {
+	skipped := 0
+	for _, c := range cs {
+		if !include(c.Name) {
+			continue
+		}
+
+		// no need to initialize the GPU components
+		// (e.g., on the CPU-only head/login nodes)
+		if !hasGPU && c.RequiresGPU {
+			skipped++
+			continue
+		}
+
 		c, err := c.InitFunc(gpudInstance)
 		if err != nil {
-			return err
+			return skipped, err
 		}
 		if !c.IsSupported() {
 			continue
 		}
-		printSummary(c.Check())
+
+		result, err := runWithinBudget(ctx, profile, c.Check)
+		if err != nil {
+			return skipped, err
+		}
+		onResult(result)
+	}
+	return skipped, nil
+}
+
+// runWithinBudget runs the check, and returns an error if the
+// time budget of the scan profile is exceeded before the check completes.
+func runWithinBudget(ctx context.Context, profile Profile, check func() components.CheckResult) (components.CheckResult, error) {
+	if ctx.Err() != nil {
+		return nil, budgetError(ctx, profile)
+	}
+
+	rc := make(chan components.CheckResult, 1)
+	go func() {
+		rc <- check()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, budgetError(ctx, profile)
+	case result := <-rc:
+		return result, nil
+	}
+}
+
+func budgetError(ctx context.Context, profile Profile) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("scan profile %q exceeded its time budget %s", profile, profile.Budget())
+	}
+	return ctx.Err()
+}
+
+// gpuComponents are the components that only check the GPUs
+// (e.g., "accelerator-nvidia-ecc"), which are not supported without the GPUs.
+var gpuComponents = func() map[string]struct{} {
+	m := make(map[string]struct{})
+	for _, c := range all.All() {
+		if c.RequiresGPU {
+			m[c.Name] = struct{}{}
+		}
 	}
+	return m
+}()
 
-	fmt.Printf("\n\n%s scan complete\n\n", cmdcommon.CheckMark)
-	return nil
+// requiresGPU returns true if the component only checks the GPUs.
+// The accelerator components checking the host NICs (e.g., infiniband)
+// still run without the GPUs.
+func requiresGPU(name string) bool {
+	_, ok := gpuComponents[name]
+	return ok
 }
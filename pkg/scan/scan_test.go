@@ -13,6 +13,10 @@ import (
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	cmdcommon "github.com/leptonai/gpud/cmd/common"
 	"github.com/leptonai/gpud/components"
+	componentsacceleratornvidiaecc "github.com/leptonai/gpud/components/accelerator/nvidia/ecc"
+	componentsacceleratornvidiainfiniband "github.com/leptonai/gpud/components/accelerator/nvidia/infiniband"
+	"github.com/leptonai/gpud/components/all"
+	componentsdisk "github.com/leptonai/gpud/components/disk"
 )
 
 // mockCheckResult implements the components.CheckResult interface for testing
@@ -152,3 +156,46 @@ func TestPrintSummary(t *testing.T) {
 		})
 	}
 }
+
+// mockComponent implements the components.Component interface for testing
+type mockComponent struct {
+	components.Component
+
+	name    string
+	checked *[]string
+}
+
+func (m *mockComponent) Name() string      { return m.name }
+func (m *mockComponent) IsSupported() bool { return true }
+func (m *mockComponent) Check() components.CheckResult {
+	*m.checked = append(*m.checked, m.name)
+	return &mockCheckResult{componentName: m.name, healthStateType: apiv1.HealthStateTypeHealthy}
+}
+
+func TestCheckComponentsWithoutGPU(t *testing.T) {
+	var checked []string
+	newComponent := func(name string, requiresGPU bool) all.Component {
+		return all.Component{
+			Name: name,
+			InitFunc: func(*components.GPUdInstance) (components.Component, error) {
+				return &mockComponent{name: name, checked: &checked}, nil
+			},
+			RequiresGPU: requiresGPU,
+		}
+	}
+	cs := []all.Component{
+		newComponent(componentsdisk.Name, false),
+		newComponent(componentsacceleratornvidiaecc.Name, true),
+		newComponent(componentsacceleratornvidiainfiniband.Name, false),
+	}
+	include := func(string) bool { return true }
+
+	var results []string
+	skipped, err := checkComponents(context.Background(), ProfileStandard, cs, include, &components.GPUdInstance{}, false, func(result components.CheckResult) {
+		results = append(results, result.ComponentName())
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, skipped)
+	assert.Equal(t, []string{componentsdisk.Name, componentsacceleratornvidiainfiniband.Name}, checked)
+	assert.Equal(t, checked, results)
+}
@@ -0,0 +1,36 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/upload"
+)
+
+// uploadTimeout is the time budget to upload the scan result,
+// not bounded by the time budget of the scan itself.
+const uploadTimeout = 5 * time.Minute
+
+// uploadResult uploads the scan result in JSON, keyed by the hostname and the time.
+func uploadResult(ctx context.Context, uploader upload.Uploader, result any) error {
+	b, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	hostname, _ := os.Hostname()
+	key := fmt.Sprintf("gpud-scan-%s-%s.json", hostname, time.Now().UTC().Format("20060102-150405"))
+
+	cctx, ccancel := context.WithTimeout(ctx, uploadTimeout)
+	defer ccancel()
+	if err := uploader.Upload(cctx, key, bytes.NewReader(b), int64(len(b))); err != nil {
+		return fmt.Errorf("failed to upload the scan result: %w", err)
+	}
+	log.Logger.Infow("uploaded scan result", "destination", uploader.Destination(), "key", key)
+	return nil
+}
@@ -0,0 +1,63 @@
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+type mockUploader struct {
+	key  string
+	body []byte
+	err  error
+}
+
+func (m *mockUploader) Destination() string {
+	return "s3://bucket/prefix"
+}
+
+func (m *mockUploader) Upload(ctx context.Context, key string, body io.ReadSeeker, size int64) error {
+	if m.err != nil {
+		return m.err
+	}
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	m.key = key
+	m.body = b
+	return nil
+}
+
+func TestUploadResult(t *testing.T) {
+	u := &mockUploader{}
+	result := &Result{
+		Profile:     ProfileQuick,
+		MachineInfo: &apiv1.MachineInfo{Hostname: "test-host"},
+		HealthStates: apiv1.GPUdComponentHealthStates{
+			{Component: "test", States: apiv1.HealthStates{{Health: apiv1.HealthStateTypeHealthy}}},
+		},
+	}
+	require.NoError(t, uploadResult(context.Background(), u, result))
+
+	assert.True(t, strings.HasPrefix(u.key, "gpud-scan-"))
+	assert.True(t, strings.HasSuffix(u.key, ".json"))
+
+	var got Result
+	require.NoError(t, json.Unmarshal(u.body, &got))
+	assert.Equal(t, "test-host", got.MachineInfo.Hostname)
+	assert.Len(t, got.HealthStates, 1)
+
+	u = &mockUploader{err: errors.New("access denied")}
+	err := uploadResult(context.Background(), u, result)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "access denied")
+}
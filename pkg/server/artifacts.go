@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	pkgcustomplugins "github.com/leptonai/gpud/pkg/custom-plugins"
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/release/distsign"
+)
+
+// errUnsignedPluginSpecs is returned when the plugin specs from the control plane
+// are rejected, since they do not carry the signature to verify.
+var errUnsignedPluginSpecs = errors.New("plugin specs update rejected (artifact signature verification enabled, update the signed plugin specs file instead)")
+
+// newArtifactReader returns the function to read the artifact files of the type (e.g., the plugin specs).
+// If the artifact public keys are set, every read verifies the file with its detached signature
+// (the file path with the ".sig" suffix) as the artifact type, and fails on the missing or invalid
+// signature. The artifact keys are re-read on every read, so the updated and retired keys
+// take effect without restarting (e.g., the infiniband evaluation config reloads).
+func newArtifactReader(signPubPath string) func(distsign.ArtifactType) func(string) ([]byte, error) {
+	if signPubPath == "" {
+		return func(distsign.ArtifactType) func(string) ([]byte, error) { return os.ReadFile }
+	}
+	return func(typ distsign.ArtifactType) func(string) ([]byte, error) {
+		return func(path string) ([]byte, error) {
+			signPubBundle, err := os.ReadFile(signPubPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read artifact public keys: %w", err)
+			}
+			signPubs, err := distsign.ParseArtifactKeyBundleAt(signPubBundle, time.Now())
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse artifact public keys %q: %w", signPubPath, err)
+			}
+
+			b, err := distsign.VerifyArtifactFile(signPubs, typ, path, "")
+			if err != nil {
+				return nil, fmt.Errorf("failed to verify %s artifact signature: %w", typ, err)
+			}
+			log.Logger.Infow("verified artifact signature", "type", typ, "path", path)
+			return b, nil
+		}
+	}
+}
+
+// loadArtifact reads the artifact file, and parses the read (and verified) content,
+// so the file is not re-read after the verification.
+func loadArtifact[T any](readArtifact func(string) ([]byte, error), path string, parse func([]byte) (T, error)) (T, error) {
+	b, err := readArtifact(path)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return parse(b)
+}
+
+// savePluginSpecs saves the plugin specs from the control plane,
+// unless the artifact signature verification is enabled.
+func (s *Server) savePluginSpecs(ctx context.Context, specs pkgcustomplugins.Specs) (bool, error) {
+	if s.artifactSignPubPath != "" {
+		log.Logger.Warnw("rejecting unsigned plugin specs from the control plane", "path", s.pluginSpecsFile)
+		return false, errUnsignedPluginSpecs
+	}
+	return pkgcustomplugins.SaveSpecs(s.pluginSpecsFile, specs)
+}
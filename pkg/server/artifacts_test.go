@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/release/distsign"
+)
+
+func TestArtifactReader(t *testing.T) {
+	priv, pub, err := distsign.GenerateArtifactKey()
+	require.NoError(t, err)
+	key, err := distsign.ParseArtifactKey(priv)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	signPubPath := filepath.Join(dir, "artifact.pub")
+	require.NoError(t, os.WriteFile(signPubPath, pub, 0644))
+
+	writeSigned := func(name string, typ distsign.ArtifactType, data []byte) string {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(p, data, 0644))
+		sig, err := distsign.SignArtifact(key, typ, data)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(p+distsign.SignatureSuffix, sig, 0644))
+		return p
+	}
+	sinksData := []byte("- name: log\n  type: log\n")
+	sinks := writeSigned("event-sinks.yaml", distsign.ArtifactTypeEventSinks, sinksData)
+
+	readArtifact := newArtifactReader(signPubPath)
+	b, err := readArtifact(distsign.ArtifactTypeEventSinks)(sinks)
+	require.NoError(t, err)
+	assert.Equal(t, sinksData, b)
+
+	// signed as another artifact type
+	_, err = readArtifact(distsign.ArtifactTypeHooks)(sinks)
+	assert.ErrorIs(t, err, distsign.ErrInvalidArtifactSignature)
+
+	// tampered after the previous read
+	require.NoError(t, os.WriteFile(sinks, []byte("- name: webhook\n"), 0644))
+	_, err = readArtifact(distsign.ArtifactTypeEventSinks)(sinks)
+	assert.ErrorIs(t, err, distsign.ErrInvalidArtifactSignature)
+
+	// unsigned
+	unsigned := filepath.Join(dir, "hooks.yaml")
+	require.NoError(t, os.WriteFile(unsigned, []byte("[]\n"), 0644))
+	_, err = readArtifact(distsign.ArtifactTypeHooks)(unsigned)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	// missing
+	_, err = readArtifact(distsign.ArtifactTypeHooks)(filepath.Join(dir, "missing.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	// retired artifact key
+	block, _ := pem.Decode(pub)
+	require.NotNil(t, block)
+	block.Headers = map[string]string{distsign.NotAfterHeader: time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)}
+	require.NoError(t, os.WriteFile(signPubPath, pem.EncodeToMemory(block), 0644))
+	sinks = writeSigned("event-sinks.yaml", distsign.ArtifactTypeEventSinks, sinksData)
+	_, err = readArtifact(distsign.ArtifactTypeEventSinks)(sinks)
+	assert.Error(t, err)
+
+	// the package signing keys are not accepted as the artifact keys
+	_, signingPub, err := distsign.GenerateSigningKey()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(signPubPath, signingPub, 0644))
+	sinks = writeSigned("event-sinks.yaml", distsign.ArtifactTypeEventSinks, sinksData)
+	_, err = readArtifact(distsign.ArtifactTypeEventSinks)(sinks)
+	assert.Error(t, err)
+
+	// no artifact public keys
+	_, err = newArtifactReader(filepath.Join(dir, "missing.pub"))(distsign.ArtifactTypeEventSinks)(sinks)
+	assert.Error(t, err)
+
+	// verification disabled
+	b, err = newArtifactReader("")(distsign.ArtifactTypeHooks)(unsigned)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("[]\n"), b)
+}
+
+func TestLoadArtifact(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "kmsg-matchers.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("abc"), 0644))
+
+	parse := func(b []byte) (int, error) { return len(b), nil }
+	n, err := loadArtifact(os.ReadFile, file, parse)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	_, err = loadArtifact(os.ReadFile, file+".missing", parse)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestSavePluginSpecsRejectedWithVerification(t *testing.T) {
+	s := &Server{
+		pluginSpecsFile:     filepath.Join(t.TempDir(), "plugins.yaml"),
+		artifactSignPubPath: "signing.pub",
+	}
+	updated, err := s.savePluginSpecs(context.Background(), nil)
+	assert.ErrorIs(t, err, errUnsignedPluginSpecs)
+	assert.False(t, updated)
+
+	_, err = os.Stat(s.pluginSpecsFile)
+	assert.True(t, os.IsNotExist(err))
+}
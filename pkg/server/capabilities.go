@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/components/all"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+)
+
+// buildCapabilityReport reports every component considered at the startup,
+// whether it was enabled, and why not.
+// The registered components not in the considered list (e.g., the custom plugins)
+// are reported as enabled if supported.
+// The skippedInDevMode is nil unless running in the dev mode.
+func buildCapabilityReport(
+	now time.Time,
+	considered []all.Component,
+	registry components.Registry,
+	nvmlExists bool,
+	runningAsRoot bool,
+	locateExecutable func(string) (string, error),
+	skippedInDevMode func(string) bool,
+) apiv1.ComponentCapabilityReport {
+	report := apiv1.ComponentCapabilityReport{
+		Time:          metav1.NewTime(now),
+		RunningAsRoot: runningAsRoot,
+		NVMLExists:    nvmlExists,
+	}
+
+	seen := make(map[string]struct{}, len(considered))
+	for _, c := range considered {
+		seen[c.Name] = struct{}{}
+
+		comp := registry.Get(c.Name)
+		if comp == nil && skippedInDevMode != nil && skippedInDevMode(c.Name) {
+			report.Components = append(report.Components, apiv1.ComponentCapability{
+				Component: c.Name,
+				Status:    apiv1.ComponentCapabilityStatusDisabledByDevMode,
+				Reason:    "disabled in the dev mode, checks the host hardware rather than the fake backends",
+			})
+			continue
+		}
+		if comp == nil {
+			report.Components = append(report.Components, apiv1.ComponentCapability{
+				Component: c.Name,
+				Status:    apiv1.ComponentCapabilityStatusDisabledByConfig,
+				Reason:    "disabled by the enable/disable components configuration",
+			})
+			continue
+		}
+
+		report.Components = append(report.Components, evaluateCapability(comp, c, nvmlExists, runningAsRoot, locateExecutable))
+	}
+
+	for _, comp := range registry.All() {
+		if _, ok := seen[comp.Name()]; ok {
+			continue
+		}
+		report.Components = append(report.Components, evaluateCapability(comp, all.Component{Name: comp.Name()}, nvmlExists, runningAsRoot, locateExecutable))
+	}
+
+	sort.Slice(report.Components, func(i, j int) bool {
+		return report.Components[i].Component < report.Components[j].Component
+	})
+	return report
+}
+
+func evaluateCapability(comp components.Component, c all.Component, nvmlExists bool, runningAsRoot bool, locateExecutable func(string) (string, error)) apiv1.ComponentCapability {
+	capability := apiv1.ComponentCapability{Component: c.Name}
+
+	if !comp.IsSupported() {
+		capability.Status = apiv1.ComponentCapabilityStatusNotSupported
+		capability.Reason = "not supported on this host"
+		for _, dep := range c.Dependencies {
+			if dep == components.DependencyNVML && !nvmlExists {
+				capability.Reason = "NVML not found (no NVIDIA driver or GPU)"
+				break
+			}
+		}
+		return capability
+	}
+
+	capability.Enabled = true
+	capability.Status = apiv1.ComponentCapabilityStatusEnabled
+
+	requiresTools := len(c.RequiredExecutables) > 0
+	if t, ok := comp.(components.ToolRequirer); ok && requiresTools {
+		requiresTools = t.RequiresTools()
+	}
+	if requiresTools {
+		found := false
+		for _, exec := range c.RequiredExecutables {
+			if _, err := locateExecutable(exec); err == nil {
+				found = true
+				break
+			}
+		}
+		if !found {
+			capability.Status = apiv1.ComponentCapabilityStatusMissingTool
+			capability.Reason = fmt.Sprintf("%s not found, checks are skipped", strings.Join(c.RequiredExecutables, ", "))
+			return capability
+		}
+	}
+
+	if !runningAsRoot && c.NonRootDegradation != "" {
+		capability.Status = apiv1.ComponentCapabilityStatusDegraded
+		capability.Reason = "not running as root, " + c.NonRootDegradation
+	}
+	return capability
+}
+
+// logCapabilityReport logs the components not fully enabled.
+func logCapabilityReport(report apiv1.ComponentCapabilityReport) {
+	enabled := 0
+	for _, c := range report.Components {
+		if c.Status == apiv1.ComponentCapabilityStatusEnabled {
+			enabled++
+			continue
+		}
+		log.Logger.Infow("component not fully enabled", "component", c.Component, "status", c.Status, "reason", c.Reason)
+	}
+	log.Logger.Infow("component capability report", "considered", len(report.Components), "enabled", enabled)
+}
+
+// saveCapabilityReport persists the report, to be read after the daemon exits.
+func saveCapabilityReport(ctx context.Context, dbRW *sql.DB, report apiv1.ComponentCapabilityReport) error {
+	b, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	return pkgmetadata.SetMetadata(ctx, dbRW, pkgmetadata.MetadataKeyComponentCapabilities, string(b))
+}
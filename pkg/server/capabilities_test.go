@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/components/all"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestBuildCapabilityReport(t *testing.T) {
+	considered := []all.Component{
+		{Name: "cpu", NonRootDegradation: "kernel message events are not watched"},
+		{Name: "disk"},
+		{Name: "accelerator-nvidia-ecc", Dependencies: []string{components.DependencyNVML}},
+		{Name: "accelerator-nvidia-infiniband", Dependencies: []string{components.DependencyNVML}, RequiredExecutables: []string{"ibstat"}},
+		{Name: "network-lldp", RequiredExecutables: []string{"lldpctl"}},
+		{Name: "kubelet"},
+	}
+
+	_, registry, _ := setupTestHandler([]components.Component{
+		&mockComponent{name: "cpu", isSupported: true},
+		&mockComponent{name: "accelerator-nvidia-ecc", isSupported: false},
+		&mockComponent{name: "accelerator-nvidia-infiniband", isSupported: true},
+		&mockComponent{name: "network-lldp", isSupported: true},
+		&mockComponent{name: "kubelet", isSupported: true},
+		&mockComponent{name: "my-plugin", isSupported: true},
+	})
+
+	locate := func(name string) (string, error) {
+		if name == "ibstat" {
+			return "/usr/sbin/ibstat", nil
+		}
+		return "", errors.New("not found")
+	}
+
+	now := time.Now().UTC()
+	report := buildCapabilityReport(now, considered, registry, false, false, locate, nil)
+	assert.Equal(t, now, report.Time.Time)
+	assert.False(t, report.RunningAsRoot)
+	assert.False(t, report.NVMLExists)
+
+	got := make(map[string]apiv1.ComponentCapability)
+	names := make([]string, 0, len(report.Components))
+	for _, c := range report.Components {
+		got[c.Component] = c
+		names = append(names, c.Component)
+	}
+	assert.IsIncreasing(t, names)
+	require.Len(t, got, 7)
+
+	assert.Equal(t, apiv1.ComponentCapabilityStatusDegraded, got["cpu"].Status)
+	assert.True(t, got["cpu"].Enabled)
+	assert.Contains(t, got["cpu"].Reason, "not running as root")
+
+	assert.Equal(t, apiv1.ComponentCapabilityStatusDisabledByConfig, got["disk"].Status)
+	assert.False(t, got["disk"].Enabled)
+
+	assert.Equal(t, apiv1.ComponentCapabilityStatusNotSupported, got["accelerator-nvidia-ecc"].Status)
+	assert.False(t, got["accelerator-nvidia-ecc"].Enabled)
+	assert.Contains(t, got["accelerator-nvidia-ecc"].Reason, "NVML not found")
+
+	assert.Equal(t, apiv1.ComponentCapabilityStatusEnabled, got["accelerator-nvidia-infiniband"].Status)
+	assert.Empty(t, got["accelerator-nvidia-infiniband"].Reason)
+
+	assert.Equal(t, apiv1.ComponentCapabilityStatusMissingTool, got["network-lldp"].Status)
+	assert.True(t, got["network-lldp"].Enabled)
+	assert.Contains(t, got["network-lldp"].Reason, "lldpctl not found")
+
+	assert.Equal(t, apiv1.ComponentCapabilityStatusEnabled, got["kubelet"].Status)
+	assert.Equal(t, apiv1.ComponentCapabilityStatusEnabled, got["my-plugin"].Status)
+
+	// running as root, no degradation
+	report = buildCapabilityReport(now, considered, registry, true, true, locate, nil)
+	for _, c := range report.Components {
+		if c.Component == "cpu" {
+			assert.Equal(t, apiv1.ComponentCapabilityStatusEnabled, c.Status)
+		}
+		if c.Component == "accelerator-nvidia-ecc" {
+			assert.Equal(t, "not supported on this host", c.Reason)
+		}
+	}
+}
+
+type toolRequirerComponent struct {
+	*mockComponent
+	requiresTools bool
+}
+
+func (c *toolRequirerComponent) RequiresTools() bool { return c.requiresTools }
+
+func TestBuildCapabilityReportToolsNotRequired(t *testing.T) {
+	considered := []all.Component{
+		{Name: "accelerator-nvidia-fabric-manager", RequiredExecutables: []string{"nv-fabricmanager"}},
+		{Name: "accelerator-nvidia-infiniband", RequiredExecutables: []string{"ibstat"}},
+	}
+	_, registry, _ := setupTestHandler([]components.Component{
+		// e.g., no NVSwitch
+		&toolRequirerComponent{mockComponent: &mockComponent{name: "accelerator-nvidia-fabric-manager", isSupported: true}},
+		&toolRequirerComponent{mockComponent: &mockComponent{name: "accelerator-nvidia-infiniband", isSupported: true}, requiresTools: true},
+	})
+	locate := func(string) (string, error) { return "", errors.New("not found") }
+
+	report := buildCapabilityReport(time.Now().UTC(), considered, registry, true, true, locate, nil)
+	require.Len(t, report.Components, 2)
+	assert.Equal(t, "accelerator-nvidia-fabric-manager", report.Components[0].Component)
+	assert.Equal(t, apiv1.ComponentCapabilityStatusEnabled, report.Components[0].Status)
+	assert.Equal(t, "accelerator-nvidia-infiniband", report.Components[1].Component)
+	assert.Equal(t, apiv1.ComponentCapabilityStatusMissingTool, report.Components[1].Status)
+}
+
+func TestBuildCapabilityReportDevMode(t *testing.T) {
+	considered := []all.Component{
+		{Name: "accelerator-nvidia-fallen-off-bus"},
+		{Name: "disk"},
+	}
+	_, registry, _ := setupTestHandler([]components.Component{})
+	locate := func(string) (string, error) { return "", errors.New("not found") }
+	skipped := func(name string) bool { return name == "accelerator-nvidia-fallen-off-bus" }
+
+	report := buildCapabilityReport(time.Now().UTC(), considered, registry, true, true, locate, skipped)
+	require.Len(t, report.Components, 2)
+	assert.Equal(t, apiv1.ComponentCapabilityStatusDisabledByDevMode, report.Components[0].Status)
+	assert.Contains(t, report.Components[0].Reason, "dev mode")
+	assert.Equal(t, apiv1.ComponentCapabilityStatusDisabledByConfig, report.Components[1].Status)
+}
+
+func TestSaveCapabilityReport(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	require.NoError(t, pkgmetadata.CreateTableMetadata(ctx, dbRW))
+
+	report := apiv1.ComponentCapabilityReport{
+		RunningAsRoot: true,
+		Components: []apiv1.ComponentCapability{
+			{Component: "cpu", Enabled: true, Status: apiv1.ComponentCapabilityStatusEnabled},
+		},
+	}
+	require.NoError(t, saveCapabilityReport(ctx, dbRW, report))
+
+	v, err := pkgmetadata.ReadMetadata(ctx, dbRO, pkgmetadata.MetadataKeyComponentCapabilities)
+	require.NoError(t, err)
+	var got apiv1.ComponentCapabilityReport
+	require.NoError(t, json.Unmarshal([]byte(v), &got))
+	assert.Equal(t, report.Components, got.Components)
+	assert.True(t, got.RunningAsRoot)
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+const (
+	// DefaultEventsQueryLimit is the default maximum number of events
+	// returned per component, if the limit is not specified.
+	DefaultEventsQueryLimit = 1000
+	// MaxEventsQueryLimit is the maximum number of events
+	// that can be requested per component.
+	MaxEventsQueryLimit = 10000
+
+	// MaxEventsQueryRange is the maximum time range of the events query,
+	// to keep the responses bounded on the nodes with long retention.
+	MaxEventsQueryRange = 7 * 24 * time.Hour
+)
+
+// eventsQuery is the server-side filter and pagination of the events query.
+type eventsQuery struct {
+	endTime    time.Time
+	eventTypes map[apiv1.EventType]struct{}
+	limit      int
+	offset     int
+}
+
+// parseEventsQuery parses the "eventTypes", "limit", and "offset" query parameters,
+// and validates the time range.
+func parseEventsQuery(c *gin.Context, startTime time.Time, endTime time.Time) (eventsQuery, error) {
+	q := eventsQuery{
+		endTime: endTime,
+		limit:   DefaultEventsQueryLimit,
+	}
+
+	if endTime.Before(startTime) {
+		return eventsQuery{}, fmt.Errorf("end time %s is before start time %s", endTime, startTime)
+	}
+	if endTime.Sub(startTime) > MaxEventsQueryRange {
+		return eventsQuery{}, fmt.Errorf("time range %s exceeds the maximum %s", endTime.Sub(startTime), MaxEventsQueryRange)
+	}
+
+	if raw := c.Query("eventTypes"); raw != "" {
+		q.eventTypes = make(map[apiv1.EventType]struct{})
+		for _, s := range strings.Split(raw, ",") {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+			et := apiv1.EventTypeFromString(s)
+			if et == apiv1.EventTypeUnknown && s != string(apiv1.EventTypeUnknown) {
+				return eventsQuery{}, fmt.Errorf("unknown event type %q", s)
+			}
+			q.eventTypes[et] = struct{}{}
+		}
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return eventsQuery{}, fmt.Errorf("failed to parse limit: %w", err)
+		}
+		if limit <= 0 || limit > MaxEventsQueryLimit {
+			return eventsQuery{}, fmt.Errorf("limit must be between 1 and %d, got %d", MaxEventsQueryLimit, limit)
+		}
+		q.limit = limit
+	}
+
+	if raw := c.Query("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil {
+			return eventsQuery{}, fmt.Errorf("failed to parse offset: %w", err)
+		}
+		if offset < 0 {
+			return eventsQuery{}, fmt.Errorf("offset must be non-negative, got %d", offset)
+		}
+		q.offset = offset
+	}
+
+	return q, nil
+}
+
+// apply filters the events by the end time and the event types,
+// sorts them by the time in the descending order, and returns the requested page
+// with the offset of the next page (zero if there is no more page).
+func (q eventsQuery) apply(events apiv1.Events) (apiv1.Events, int) {
+	filtered := make(apiv1.Events, 0, len(events))
+	for _, ev := range events {
+		// compare in seconds, since the time query parameters are in unix seconds
+		if ev.Time.Unix() > q.endTime.Unix() {
+			continue
+		}
+		if len(q.eventTypes) > 0 {
+			if _, ok := q.eventTypes[ev.Type]; !ok {
+				continue
+			}
+		}
+		filtered = append(filtered, ev)
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return filtered[i].Time.After(filtered[j].Time.Time)
+	})
+
+	if q.offset >= len(filtered) {
+		return nil, 0
+	}
+	filtered = filtered[q.offset:]
+
+	nextOffset := 0
+	if len(filtered) > q.limit {
+		filtered = filtered[:q.limit]
+		nextOffset = q.offset + q.limit
+	}
+	return filtered, nextOffset
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+)
+
+func TestParseEventsQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		query      string
+		startTime  time.Time
+		wantErr    bool
+		wantLimit  int
+		wantOffset int
+		wantTypes  int
+	}{
+		{name: "defaults", startTime: now, wantLimit: DefaultEventsQueryLimit},
+		{name: "limit and offset", query: "limit=5&offset=10", startTime: now, wantLimit: 5, wantOffset: 10},
+		{name: "event types", query: "eventTypes=Warning,%20Critical", startTime: now, wantLimit: DefaultEventsQueryLimit, wantTypes: 2},
+		{name: "unknown event type", query: "eventTypes=Bogus", startTime: now, wantErr: true},
+		{name: "zero limit", query: "limit=0", startTime: now, wantErr: true},
+		{name: "limit too large", query: fmt.Sprintf("limit=%d", MaxEventsQueryLimit+1), startTime: now, wantErr: true},
+		{name: "negative offset", query: "offset=-1", startTime: now, wantErr: true},
+		{name: "invalid limit", query: "limit=abc", startTime: now, wantErr: true},
+		{name: "range too large", startTime: now.Add(-MaxEventsQueryRange - time.Hour), wantErr: true},
+		{name: "end before start", startTime: now.Add(time.Hour), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/events?"+tt.query, nil)
+
+			q, err := parseEventsQuery(c, tt.startTime, now)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantLimit, q.limit)
+			assert.Equal(t, tt.wantOffset, q.offset)
+			assert.Len(t, q.eventTypes, tt.wantTypes)
+		})
+	}
+}
+
+func TestEventsQueryApply(t *testing.T) {
+	now := time.Now()
+	events := apiv1.Events{
+		{Time: metav1.NewTime(now.Add(-3 * time.Minute)), Name: "a", Type: apiv1.EventTypeInfo},
+		{Time: metav1.NewTime(now.Add(-1 * time.Minute)), Name: "b", Type: apiv1.EventTypeWarning},
+		{Time: metav1.NewTime(now.Add(-2 * time.Minute)), Name: "c", Type: apiv1.EventTypeCritical},
+		{Time: metav1.NewTime(now.Add(time.Hour)), Name: "future", Type: apiv1.EventTypeInfo},
+	}
+
+	q := eventsQuery{endTime: now, limit: 2}
+	page, next := q.apply(events)
+	require.Len(t, page, 2)
+	assert.Equal(t, "b", page[0].Name)
+	assert.Equal(t, "c", page[1].Name)
+	assert.Equal(t, 2, next)
+
+	q.offset = next
+	page, next = q.apply(events)
+	require.Len(t, page, 1)
+	assert.Equal(t, "a", page[0].Name)
+	assert.Equal(t, 0, next)
+
+	q.offset = 10
+	page, next = q.apply(events)
+	assert.Empty(t, page)
+	assert.Equal(t, 0, next)
+
+	q = eventsQuery{endTime: now, limit: 10, eventTypes: map[apiv1.EventType]struct{}{apiv1.EventTypeCritical: {}}}
+	page, _ = q.apply(events)
+	require.Len(t, page, 1)
+	assert.Equal(t, "c", page[0].Name)
+}
+
+func TestGetEventsPagination(t *testing.T) {
+	now := time.Now()
+	var events apiv1.Events
+	for i := 0; i < 5; i++ {
+		events = append(events, apiv1.Event{Time: metav1.NewTime(now.Add(-time.Duration(i) * time.Minute)), Name: fmt.Sprintf("e%d", i), Type: apiv1.EventTypeWarning})
+	}
+	comp := &mockComponent{name: "comp1", isSupported: true, events: events}
+	handler, _, _ := setupTestHandler([]components.Component{comp})
+
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/v1/events?startTime=%d&limit=2&offset=2", now.Add(-time.Hour).Unix()), nil)
+	handler.getEvents(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"nextOffset":4`)
+	assert.Contains(t, w.Body.String(), `"e2"`)
+	assert.NotContains(t, w.Body.String(), `"e1"`)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/v1/events?startTime=%d", now.Add(-MaxEventsQueryRange-time.Hour).Unix()), nil)
+	handler.getEvents(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"sort"
 	"strconv"
@@ -10,11 +12,25 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
+	pkgaccounting "github.com/leptonai/gpud/pkg/accounting"
+	pkgbaseline "github.com/leptonai/gpud/pkg/baseline"
 	gpudconfig "github.com/leptonai/gpud/pkg/config"
+	pkgdisruption "github.com/leptonai/gpud/pkg/disruption"
 	"github.com/leptonai/gpud/pkg/errdefs"
+	pkgescalation "github.com/leptonai/gpud/pkg/escalation"
 	pkgfaultinjector "github.com/leptonai/gpud/pkg/fault-injector"
+	pkgfindings "github.com/leptonai/gpud/pkg/findings"
+	pkgkmsg "github.com/leptonai/gpud/pkg/kmsg"
+	pkglabels "github.com/leptonai/gpud/pkg/labels"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	pkgnccltest "github.com/leptonai/gpud/pkg/nccl-test"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	pkgprobecache "github.com/leptonai/gpud/pkg/probecache"
+	pkgsampling "github.com/leptonai/gpud/pkg/sampling"
+	pkgsimulate "github.com/leptonai/gpud/pkg/simulate"
+	pkgtimeline "github.com/leptonai/gpud/pkg/timeline"
 )
 
 const (
@@ -35,9 +51,63 @@ type globalHandler struct {
 	gpudInstance *components.GPUdInstance
 
 	faultInjector pkgfaultinjector.Injector
+
+	// labels are attached to every health state, event, and metric in the responses
+	labels *pkglabels.Labels
+
+	// findings is nil if the findings reported to the control plane are not tracked
+	findings *pkgfindings.Tracker
+
+	// healthTransitions is nil if the health transitions are not recorded
+	healthTransitions *pkgtimeline.Recorder
+
+	// escalation is nil if the unhealthy states are not escalated
+	escalation *pkgescalation.Tracker
+
+	// gpuAccounting is nil if the gpu usage accounting is not enabled
+	gpuAccounting *pkgaccounting.Accountant
+
+	// gpuSampler is nil if NVML is not available
+	gpuSampler *pkgsampling.Sampler
+
+	// gpuSnapshots is nil if NVML is not available
+	gpuSnapshots *nvidianvml.SnapshotCache
+
+	// readKmsgFunc reads the kernel messages, defaults to reading "/dev/kmsg"
+	readKmsgFunc func(ctx context.Context) ([]pkgkmsg.Message, error)
+
+	// simulator is nil if the simulation is not set up
+	simulator *pkgsimulate.Simulator
+
+	// checkStats tracks the latency of the component checks, nil if not tracked
+	checkStats *components.CheckStats
+
+	// probeCache caches the static machine info, nil to probe every time
+	probeCache *pkgprobecache.Cache
+
+	// capabilities is the component capability report generated at the startup,
+	// nil if not generated
+	capabilities *apiv1.ComponentCapabilityReport
+
+	// dbRW persists the thresholds and the disruption windows updated via the API,
+	// nil to not persist
+	dbRW *sql.DB
+
+	// dbAPIRO is the read-only pool dedicated to the API readers, to read the component
+	// events without contending with the component checks, nil to read from the shared pool
+	dbAPIRO *sql.DB
+
+	// disruptions is nil if the expected disruption windows are not tracked
+	disruptions *pkgdisruption.Windows
+
+	// baselineLearner is nil if the baseline learning is disabled
+	baselineLearner *pkgbaseline.Learner
+
+	// ncclTester is nil if the inter-node NCCL tests are not supported
+	ncclTester *pkgnccltest.Orchestrator
 }
 
-func newGlobalHandler(cfg *gpudconfig.Config, componentsRegistry components.Registry, metricsStore pkgmetrics.Store, gpudInstance *components.GPUdInstance, faultInjector pkgfaultinjector.Injector) *globalHandler {
+func newGlobalHandler(cfg *gpudconfig.Config, componentsRegistry components.Registry, metricsStore pkgmetrics.Store, gpudInstance *components.GPUdInstance, faultInjector pkgfaultinjector.Injector, labels *pkglabels.Labels) *globalHandler {
 	var componentNames []string
 	for _, c := range componentsRegistry.All() {
 		componentNames = append(componentNames, c.Name())
@@ -51,6 +121,8 @@ func newGlobalHandler(cfg *gpudconfig.Config, componentsRegistry components.Regi
 		metricsStore:       metricsStore,
 		gpudInstance:       gpudInstance,
 		faultInjector:      faultInjector,
+		labels:             labels,
+		readKmsgFunc:       pkgkmsg.ReadAll,
 	}
 }
 
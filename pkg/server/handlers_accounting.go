@@ -0,0 +1,119 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"sigs.k8s.io/yaml"
+
+	pkgaccounting "github.com/leptonai/gpud/pkg/accounting"
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/httputil"
+)
+
+func (g *globalHandler) registerAccountingRoutes(r gin.IRoutes) {
+	r.GET(URLPathGPUAccounting, g.getGPUAccounting)
+}
+
+const (
+	// URLPathGPUAccounting is for getting the per-cgroup GPU usage records
+	URLPathGPUAccounting = "/accounting/gpu-usage"
+
+	// DefaultGPUAccountingQuerySince is the default time range of the GPU usage query.
+	DefaultGPUAccountingQuerySince = 24 * time.Hour
+)
+
+// getGPUAccounting godoc
+// @Summary Get GPU usage accounting records
+// @Description Returns the GPU-seconds and the energy attributed to each cgroup (container or pod) per GPU, aggregated per window, to bill the tenants and jobs by. Only available if the GPU accounting is enabled.
+// @ID getGPUAccounting
+// @Tags accounting
+// @Accept json
+// @Produce json
+// @Produce text/csv
+// @Header 200 {string} Content-Type "application/json, application/yaml, or text/csv"
+// @Param Accept header string false "Content type preference" Enums(application/json,application/yaml,text/csv)
+// @Param from query string false "Start of the time range, in unix seconds or RFC3339 (defaults to 24 hours before the end)"
+// @Param to query string false "End of the time range, in unix seconds or RFC3339 (defaults to current time)"
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {array} apiv1.GPUUsageRecord "GPU usage records of the windows ending within the time range"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid content type, time parsing error, or time range exceeding the maximum"
+// @Failure 404 {object} map[string]interface{} "GPU accounting not enabled"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/accounting/gpu-usage [get]
+func (g *globalHandler) getGPUAccounting(c *gin.Context) {
+	if g.gpuAccounting == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "gpu accounting not enabled"})
+		return
+	}
+
+	from, to, err := parseGPUAccountingQuery(c, time.Now().UTC())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid gpu accounting query: " + err.Error()})
+		return
+	}
+
+	records, err := g.gpuAccounting.Records(c, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to read gpu usage records: " + err.Error()})
+		return
+	}
+
+	switch c.GetHeader(httputil.RequestHeaderContentType) {
+	case httputil.RequestHeaderYAML:
+		yb, err := yaml.Marshal(records)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to marshal gpu usage records " + err.Error()})
+			return
+		}
+		c.String(http.StatusOK, string(yb))
+
+	case httputil.RequestHeaderCSV:
+		c.Header(httputil.RequestHeaderContentType, httputil.RequestHeaderCSV)
+		c.Status(http.StatusOK)
+		if err := pkgaccounting.WriteCSV(c.Writer, records); err != nil {
+			_ = c.Error(err)
+		}
+
+	case httputil.RequestHeaderJSON, "":
+		if c.GetHeader(httputil.RequestHeaderJSONIndent) == "true" {
+			c.IndentedJSON(http.StatusOK, records)
+			return
+		}
+		c.JSON(http.StatusOK, records)
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid content type"})
+	}
+}
+
+// parseGPUAccountingQuery parses the "from" and "to" query parameters,
+// and validates the time range.
+func parseGPUAccountingQuery(c *gin.Context, now time.Time) (time.Time, time.Time, error) {
+	to := now
+	if raw := c.Query("to"); raw != "" {
+		t, err := parseTimelineTime(raw)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("failed to parse to: %w", err)
+		}
+		to = t
+	}
+	from := to.Add(-DefaultGPUAccountingQuerySince)
+	if raw := c.Query("from"); raw != "" {
+		t, err := parseTimelineTime(raw)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("failed to parse from: %w", err)
+		}
+		from = t
+	}
+
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("to %s is before from %s", to, from)
+	}
+	if to.Sub(from) > MaxEventsQueryRange {
+		return time.Time{}, time.Time{}, fmt.Errorf("time range %s exceeds the maximum %s", to.Sub(from), MaxEventsQueryRange)
+	}
+	return from, to, nil
+}
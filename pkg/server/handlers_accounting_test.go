@@ -0,0 +1,76 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgaccounting "github.com/leptonai/gpud/pkg/accounting"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/httputil"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestGetGPUAccountingNotEnabled(t *testing.T) {
+	handler, _, _ := setupTestHandler(nil)
+
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/accounting/gpu-usage", nil)
+	handler.getGPUAccounting(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetGPUAccounting(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+	bucket, err := store.Bucket(pkgaccounting.BucketName, eventstore.WithDisablePurge())
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	handler, _, _ := setupTestHandler(nil)
+	handler.gpuAccounting = pkgaccounting.NewAccountant(nil, bucket, 0, 0)
+
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/accounting/gpu-usage", nil)
+	handler.getGPUAccounting(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var records []apiv1.GPUUsageRecord
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &records))
+	assert.Empty(t, records)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/accounting/gpu-usage", nil)
+	c.Request.Header.Set(httputil.RequestHeaderContentType, httputil.RequestHeaderCSV)
+	handler.getGPUAccounting(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, httputil.RequestHeaderCSV, w.Header().Get(httputil.RequestHeaderContentType))
+	assert.Equal(t, strings.Join(pkgaccounting.CSVHeader, ",")+"\n", w.Body.String())
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/accounting/gpu-usage", nil)
+	c.Request.Header.Set(httputil.RequestHeaderContentType, "text/html")
+	handler.getGPUAccounting(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	now := time.Now().UTC()
+	for _, query := range []string{
+		"from=invalid",
+		"to=invalid",
+		"from=" + now.Format(time.RFC3339) + "&to=" + now.Add(-time.Hour).Format(time.RFC3339),
+		"from=" + now.Add(-30*24*time.Hour).Format(time.RFC3339),
+	} {
+		_, c, w = setupTestRouter()
+		c.Request = httptest.NewRequest("GET", "/v1/accounting/gpu-usage?"+query, nil)
+		handler.getGPUAccounting(c)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"sigs.k8s.io/yaml"
+
+	pkgbaseline "github.com/leptonai/gpud/pkg/baseline"
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/httputil"
+	pkgthresholds "github.com/leptonai/gpud/pkg/thresholds"
+)
+
+func (g *globalHandler) registerBaselineRoutes(r gin.IRoutes) {
+	r.GET(URLPathBaseline, g.getBaseline)
+	r.POST(URLPathBaselineAccept, g.acceptBaseline)
+}
+
+const (
+	// URLPathBaseline is for getting the baseline learning state and the proposed baseline
+	URLPathBaseline = "/baseline"
+
+	// URLPathBaselineAccept is for accepting the proposed baseline as the thresholds
+	URLPathBaselineAccept = "/baseline/accept"
+)
+
+// getBaseline godoc
+// @Summary Get baseline learning state
+// @Description Returns the baseline learning state of the node, with the normal values (e.g., GPU count, infiniband ports and rate, nvlinks, mount points) observed since the node joined, proposed as the thresholds. Only available if the baseline learning is enabled.
+// @ID getBaseline
+// @Tags baseline
+// @Produce json
+// @Header 200 {string} Content-Type "application/json or application/yaml"
+// @Param Accept header string false "Content type preference" Enums(application/json,application/yaml)
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {object} pkgbaseline.State "Baseline learning state"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid content type"
+// @Failure 404 {object} map[string]interface{} "Baseline learning not enabled"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/baseline [get]
+func (g *globalHandler) getBaseline(c *gin.Context) {
+	if g.baselineLearner == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "baseline learning not enabled"})
+		return
+	}
+
+	st := g.baselineLearner.State()
+	switch c.GetHeader(httputil.RequestHeaderContentType) {
+	case httputil.RequestHeaderYAML:
+		yb, err := yaml.Marshal(st)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to marshal baseline " + err.Error()})
+			return
+		}
+		c.String(http.StatusOK, string(yb))
+
+	case httputil.RequestHeaderJSON, "":
+		if c.GetHeader(httputil.RequestHeaderJSONIndent) == "true" {
+			c.IndentedJSON(http.StatusOK, st)
+			return
+		}
+		c.JSON(http.StatusOK, st)
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid content type"})
+	}
+}
+
+// acceptBaseline godoc
+// @Summary Accept proposed baseline
+// @Description Applies the proposed baseline as the thresholds (e.g., the expected GPU count and infiniband port states), and persists them across the restarts. The proposal may be accepted before the learning period ends. Returns the thresholds of all the components after the update.
+// @ID acceptBaseline
+// @Tags baseline
+// @Produce json
+// @Success 200 {object} pkgthresholds.Thresholds "Thresholds of all the components after the update"
+// @Failure 404 {object} map[string]interface{} "Baseline learning not enabled"
+// @Failure 409 {object} map[string]interface{} "No observation to propose the baseline from yet"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/baseline/accept [post]
+func (g *globalHandler) acceptBaseline(c *gin.Context) {
+	if g.baselineLearner == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "baseline learning not enabled"})
+		return
+	}
+
+	updated, err := g.baselineLearner.Accept(c)
+	if err != nil {
+		switch {
+		case errors.Is(err, pkgbaseline.ErrNoObservation):
+			c.JSON(http.StatusConflict, gin.H{"code": errdefs.ErrFailedPrecondition, "message": err.Error()})
+		case errors.Is(err, pkgthresholds.ErrInvalidThresholds):
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"code": errdefs.ErrUnknown, "message": "failed to accept baseline: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
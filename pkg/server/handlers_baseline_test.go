@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgbaseline "github.com/leptonai/gpud/pkg/baseline"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	"github.com/leptonai/gpud/pkg/sqlite"
+	pkgthresholds "github.com/leptonai/gpud/pkg/thresholds"
+)
+
+func TestGetAcceptBaseline(t *testing.T) {
+	orig := pkgthresholds.Get()
+	t.Cleanup(orig.Apply)
+
+	handler, _, _ := setupTestHandler(nil)
+
+	// baseline learning not enabled
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/baseline", nil)
+	handler.getBaseline(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+	require.NoError(t, pkgmetadata.CreateTableMetadata(context.Background(), dbRW))
+	// the learning starts once the node joins
+	require.NoError(t, pkgmetadata.SetMetadata(context.Background(), dbRW, pkgmetadata.MetadataKeyControlPlaneLoginSuccess, fmt.Sprintf("%d", time.Now().Unix())))
+
+	observed := false
+	learner, err := pkgbaseline.New(context.Background(), dbRW, dbRO, time.Hour, func(ctx context.Context) pkgbaseline.Observation {
+		observed = true
+		return pkgbaseline.Observation{GPUs: 8}
+	})
+	require.NoError(t, err)
+	handler.baselineLearner = learner
+
+	// nothing observed yet
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("POST", "/v1/baseline/accept", nil)
+	handler.acceptBaseline(c)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	learner.Start(ctx, time.Hour)
+	require.Eventually(t, func() bool { return learner.State().Proposal != nil }, 10*time.Second, 10*time.Millisecond)
+	assert.True(t, observed)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/baseline", nil)
+	handler.getBaseline(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var st pkgbaseline.State
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &st))
+	assert.True(t, st.Learning)
+	require.NotNil(t, st.Proposal)
+	assert.Equal(t, 8, st.Proposal.GPUs)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("POST", "/v1/baseline/accept", nil)
+	handler.acceptBaseline(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var th pkgthresholds.Thresholds
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &th))
+	require.NotNil(t, th.GPUs)
+	assert.Equal(t, 8, th.GPUs.ExpectedGPUs)
+}
@@ -1,8 +1,10 @@
 package server
 
 import (
+	"fmt"
 	"net/http"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -11,6 +13,7 @@ import (
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
 	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/eventstore"
 	"github.com/leptonai/gpud/pkg/httputil"
 	"github.com/leptonai/gpud/pkg/log"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
@@ -19,14 +22,19 @@ import (
 func (g *globalHandler) registerComponentRoutes(r gin.IRoutes) {
 	r.GET(URLPathComponents, g.getComponents)
 	r.DELETE(URLPathComponents, g.deregisterComponent)
+	r.GET(URLPathComponentsLatency, g.getComponentsLatency)
+	r.GET(URLPathComponentsCapabilities, g.getComponentsCapabilities)
 
 	r.GET(URLPathComponentsTriggerCheck, g.triggerComponentCheck)
 	r.GET(URLPathComponentsTriggerTag, g.triggerComponentsByTag)
 
 	r.GET(URLPathStates, g.getHealthStates)
+	r.GET(URLPathSummary, g.getSummary)
+	r.GET(URLPathFindings, g.getFindings)
 	r.GET(URLPathEvents, g.getEvents)
 	r.GET(URLPathInfo, g.getInfo)
 	r.GET(URLPathMetrics, g.getMetrics)
+	r.GET(URLPathMetricsExport, g.exportMetrics)
 }
 
 // URLPathComponents is for getting the list of all gpud components
@@ -74,6 +82,116 @@ func (g *globalHandler) getComponents(c *gin.Context) {
 	}
 }
 
+// URLPathComponentsLatency is for getting the duration and the CPU time of the component checks
+const URLPathComponentsLatency = "/components/latency"
+
+// getComponentsLatency godoc
+// @Summary Get the latency of the component checks
+// @Description Returns the wall-clock duration and the CPU time of the checks of each component, with the budget each check is expected to complete within, to make the monitoring overhead visible. The components whose checks consistently exceed the budget are logged with a warning.
+// @ID getComponentsLatency
+// @Tags components
+// @Produce json
+// @Header 200 {string} Content-Type "application/json or application/yaml"
+// @Param Accept header string false "Content type preference" Enums(application/json,application/yaml)
+// @Param components query string false "Comma-separated list of component names to query (if not provided, returns all components)"
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {array} apiv1.ComponentCheckLatency "Check latencies sorted by the component name"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid content type"
+// @Failure 404 {object} map[string]interface{} "Component not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/components/latency [get]
+func (g *globalHandler) getComponentsLatency(c *gin.Context) {
+	if g.checkStats == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "check latency not tracked"})
+		return
+	}
+
+	components, err := g.getReqComponents(c)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "component not found: " + err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to parse components: " + err.Error()})
+		return
+	}
+	selected := make(map[string]struct{}, len(components))
+	for _, name := range components {
+		selected[name] = struct{}{}
+	}
+
+	latencies := make(apiv1.ComponentCheckLatencies, 0)
+	for _, l := range g.checkStats.Get() {
+		if _, ok := selected[l.Component]; ok {
+			latencies = append(latencies, l)
+		}
+	}
+
+	switch c.GetHeader(httputil.RequestHeaderContentType) {
+	case httputil.RequestHeaderYAML:
+		yb, err := yaml.Marshal(latencies)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to marshal latencies " + err.Error()})
+			return
+		}
+		c.String(http.StatusOK, string(yb))
+
+	case httputil.RequestHeaderJSON, "":
+		if c.GetHeader(httputil.RequestHeaderJSONIndent) == "true" {
+			c.IndentedJSON(http.StatusOK, latencies)
+			return
+		}
+		c.JSON(http.StatusOK, latencies)
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid content type"})
+	}
+}
+
+// URLPathComponentsCapabilities is for getting whether each component was enabled at the startup, and why
+const URLPathComponentsCapabilities = "/components/capabilities"
+
+// getComponentsCapabilities godoc
+// @Summary Get the component capability report
+// @Description Returns every component considered at the daemon startup, whether it was enabled, and why not (not supported on the host, missing tool, not running as root, or disabled by the configuration), to tell why a check is not running.
+// @ID getComponentsCapabilities
+// @Tags components
+// @Produce json
+// @Header 200 {string} Content-Type "application/json or application/yaml"
+// @Param Accept header string false "Content type preference" Enums(application/json,application/yaml)
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {object} apiv1.ComponentCapabilityReport "Capability report generated at the startup"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid content type"
+// @Failure 404 {object} map[string]interface{} "Capability report not generated"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/components/capabilities [get]
+func (g *globalHandler) getComponentsCapabilities(c *gin.Context) {
+	if g.capabilities == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "capability report not generated"})
+		return
+	}
+
+	switch c.GetHeader(httputil.RequestHeaderContentType) {
+	case httputil.RequestHeaderYAML:
+		yb, err := yaml.Marshal(g.capabilities)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to marshal capabilities " + err.Error()})
+			return
+		}
+		c.String(http.StatusOK, string(yb))
+
+	case httputil.RequestHeaderJSON, "":
+		if c.GetHeader(httputil.RequestHeaderJSONIndent) == "true" {
+			c.IndentedJSON(http.StatusOK, g.capabilities)
+			return
+		}
+		c.JSON(http.StatusOK, g.capabilities)
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid content type"})
+	}
+}
+
 // deregisterComponent godoc
 // @Summary Deregister a component
 // @Description Deregisters a component from the system if it supports deregistration. Only components that implement the Deregisterable interface can be deregistered.
@@ -245,10 +363,11 @@ const URLPathStates = "/states"
 // @Tags components
 // @Accept json
 // @Produce json
-// @Header 200 {string} Content-Type "application/json or application/yaml"
-// @Param Accept header string false "Content type preference" Enums(application/json,application/yaml)
+// @Header 200 {string} Content-Type "application/json, application/yaml, or application/x-protobuf"
+// @Param Accept header string false "Content type preference" Enums(application/json,application/yaml,application/x-protobuf)
 // @Param components query string false "Comma-separated list of component names to query (if empty, returns all components)"
 // @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Param schema-version header string false "Schema version of the health states and events for the older consumers (e.g., 'v1'), defaults to the current schema version"
 // @Success 200 {object} apiv1.GPUdComponentHealthStates "Component health states"
 // @Failure 400 {object} map[string]interface{} "Bad request - invalid content type or component parsing error"
 // @Failure 404 {object} map[string]interface{} "Component not found"
@@ -266,6 +385,11 @@ func (g *globalHandler) getHealthStates(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to parse components: " + err.Error()})
 		return
 	}
+	schemaVersion, err := getReqSchemaVersion(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": err.Error()})
+		return
+	}
 	for _, componentName := range components {
 		currState := apiv1.ComponentHealthStates{
 			Component: componentName,
@@ -290,7 +414,7 @@ func (g *globalHandler) getHealthStates(c *gin.Context) {
 		state := comp.LastHealthStates()
 
 		log.Logger.Debugw("successfully got states", "component", componentName)
-		currState.States = state
+		currState.States = g.labels.ApplyToHealthStates(versionHealthStates(comp, g.escalation.ApplyToHealthStates(componentName, state), schemaVersion))
 
 		states = append(states, currState)
 	}
@@ -304,6 +428,14 @@ func (g *globalHandler) getHealthStates(c *gin.Context) {
 		}
 		c.String(http.StatusOK, string(yb))
 
+	case httputil.RequestHeaderProtobuf:
+		pb, err := states.MarshalProto()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to marshal states " + err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, httputil.RequestHeaderProtobuf, pb)
+
 	case httputil.RequestHeaderJSON, "":
 		if c.GetHeader(httputil.RequestHeaderJSONIndent) == "true" {
 			c.IndentedJSON(http.StatusOK, states)
@@ -326,14 +458,18 @@ const URLPathEvents = "/events"
 // @Tags components
 // @Accept json
 // @Produce json
-// @Header 200 {string} Content-Type "application/json or application/yaml"
-// @Param Accept header string false "Content type preference" Enums(application/json,application/yaml)
+// @Header 200 {string} Content-Type "application/json, application/yaml, or application/x-protobuf"
+// @Param Accept header string false "Content type preference" Enums(application/json,application/yaml,application/x-protobuf)
 // @Param components query string false "Comma-separated list of component names to query (if empty, queries all components)"
 // @Param startTime query string false "Start time for event query (RFC3339 format, defaults to current time)"
 // @Param endTime query string false "End time for event query (RFC3339 format, defaults to current time)"
+// @Param eventTypes query string false "Comma-separated list of event types to return (e.g., 'Warning,Critical') - defaults to all types"
+// @Param limit query integer false "Maximum number of events to return per component (defaults to 1000, up to 10000)"
+// @Param offset query integer false "Number of events to skip per component, use 'nextOffset' of the previous response to fetch the next page"
 // @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Param schema-version header string false "Schema version of the health states and events for the older consumers (e.g., 'v1'), defaults to the current schema version"
 // @Success 200 {object} apiv1.GPUdComponentEvents "Component events within the specified time range"
-// @Failure 400 {object} map[string]interface{} "Bad request - invalid content type, component parsing error, or time parsing error"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid content type, component parsing error, time parsing error, time range exceeding the maximum, or invalid pagination"
 // @Failure 404 {object} map[string]interface{} "Component not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /v1/events [get]
@@ -349,11 +485,21 @@ func (g *globalHandler) getEvents(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to parse components: " + err.Error()})
 		return
 	}
+	schemaVersion, err := getReqSchemaVersion(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": err.Error()})
+		return
+	}
 	startTime, endTime, err := g.getReqTime(c)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to parse time: " + err.Error()})
 		return
 	}
+	query, err := parseEventsQuery(c, startTime, endTime)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid events query: " + err.Error()})
+		return
+	}
 	for _, componentName := range components {
 		currEvent := apiv1.ComponentEvents{
 			Component: componentName,
@@ -376,7 +522,7 @@ func (g *globalHandler) getEvents(c *gin.Context) {
 			continue
 		}
 
-		event, err := comp.Events(c, startTime)
+		event, err := comp.Events(eventstore.WithReadDBContext(c, g.dbAPIRO), startTime)
 		if err != nil {
 			log.Logger.Errorw("failed to invoke component events",
 				"operation", "GetEvents",
@@ -384,7 +530,8 @@ func (g *globalHandler) getEvents(c *gin.Context) {
 				"error", err,
 			)
 		} else if len(event) > 0 {
-			currEvent.Events = event
+			currEvent.Events, currEvent.NextOffset = query.apply(event)
+			currEvent.Events = g.labels.ApplyToEvents(versionEvents(comp, currEvent.Events, schemaVersion))
 		}
 		events = append(events, currEvent)
 	}
@@ -398,6 +545,14 @@ func (g *globalHandler) getEvents(c *gin.Context) {
 		}
 		c.String(http.StatusOK, string(yb))
 
+	case httputil.RequestHeaderProtobuf:
+		pb, err := events.MarshalProto()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to marshal events " + err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, httputil.RequestHeaderProtobuf, pb)
+
 	case httputil.RequestHeaderJSON, "":
 		if c.GetHeader(httputil.RequestHeaderJSONIndent) == "true" {
 			c.IndentedJSON(http.StatusOK, events)
@@ -429,6 +584,7 @@ const URLPathInfo = "/info"
 // @Param endTime query string false "End time for query (RFC3339 format, defaults to current time)"
 // @Param since query string false "Duration string for metrics query (e.g., '30m', '1h') - defaults to 30 minutes"
 // @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Param schema-version header string false "Schema version of the health states and events for the older consumers (e.g., 'v1'), defaults to the current schema version"
 // @Success 200 {object} apiv1.GPUdComponentInfos "Component information including events, states, and metrics"
 // @Failure 400 {object} map[string]interface{} "Bad request - invalid content type, component parsing error, time parsing error, or duration parsing error"
 // @Failure 404 {object} map[string]interface{} "Component not found"
@@ -446,6 +602,11 @@ func (g *globalHandler) getInfo(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to parse components: " + err.Error()})
 		return
 	}
+	schemaVersion, err := getReqSchemaVersion(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": err.Error()})
+		return
+	}
 	startTime, endTime, err := g.getReqTime(c)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to parse time: " + err.Error()})
@@ -509,7 +670,7 @@ func (g *globalHandler) getInfo(c *gin.Context) {
 			continue
 		}
 
-		events, err := comp.Events(c, startTime)
+		events, err := comp.Events(eventstore.WithReadDBContext(c, g.dbAPIRO), startTime)
 		if err != nil {
 			log.Logger.Errorw("failed to invoke component events",
 				"operation", "GetInfo",
@@ -517,13 +678,13 @@ func (g *globalHandler) getInfo(c *gin.Context) {
 				"error", err,
 			)
 		} else if len(events) > 0 {
-			currInfo.Info.Events = events
+			currInfo.Info.Events = g.labels.ApplyToEvents(versionEvents(comp, events, schemaVersion))
 		}
 
 		state := comp.LastHealthStates()
-		currInfo.Info.States = state
+		currInfo.Info.States = g.labels.ApplyToHealthStates(versionHealthStates(comp, g.escalation.ApplyToHealthStates(componentName, state), schemaVersion))
 
-		currInfo.Info.Metrics = componentsToMetrics[componentName]
+		currInfo.Info.Metrics = g.labels.ApplyToMetrics(componentsToMetrics[componentName])
 
 		infos = append(infos, currInfo)
 	}
@@ -552,20 +713,30 @@ func (g *globalHandler) getInfo(c *gin.Context) {
 // URLPathMetrics is for getting the metrics of all gpud components
 const URLPathMetrics = "/metrics"
 
+const (
+	// MinMetricsQueryStep is the minimum step of the downsampled metrics query.
+	MinMetricsQueryStep = time.Second
+	// MaxMetricsQueryPoints is the maximum number of the downsampled data points
+	// per series, to keep the responses bounded.
+	MaxMetricsQueryPoints = 10000
+)
+
 // getMetrics godoc
 // @Summary Get component metrics
-// @Description Returns metrics data for specified components within a time range. If no components specified, returns metrics for all components. Metrics are queried from the last 30 minutes by default.
+// @Description Returns metrics data for specified components within a time range. If no components specified, returns metrics for all components. Metrics are queried from the last 30 minutes by default, and can be filtered by the metric names and downsampled by the step to render the historical time series.
 // @ID getMetrics
 // @Tags components
 // @Accept json
 // @Produce json
-// @Header 200 {string} Content-Type "application/json or application/yaml"
-// @Param Accept header string false "Content type preference" Enums(application/json,application/yaml)
+// @Header 200 {string} Content-Type "application/json, application/yaml, or application/x-protobuf"
+// @Param Accept header string false "Content type preference" Enums(application/json,application/yaml,application/x-protobuf)
 // @Param components query string false "Comma-separated list of component names to query (if empty, queries all components)"
 // @Param since query string false "Duration string for metrics query (e.g., '30m', '1h') - defaults to 30 minutes"
+// @Param name query string false "Comma-separated list of metric names to query (if empty, queries all metrics)"
+// @Param step query string false "Duration string to downsample each series by averaging the data points in each step (e.g., '1m') - defaults to no downsampling"
 // @Param json-indent header string false "Set to 'true' for indented JSON output"
 // @Success 200 {object} apiv1.GPUdComponentMetrics "Component metrics data within the specified time range"
-// @Failure 400 {object} map[string]interface{} "Bad request - invalid content type, component parsing error, or duration parsing error"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid content type, component parsing error, duration parsing error, or too many data points"
 // @Failure 404 {object} map[string]interface{} "Component not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error - failed to read metrics"
 // @Router /v1/metrics [get]
@@ -592,13 +763,42 @@ func (g *globalHandler) getMetrics(c *gin.Context) {
 		metricsSince = now.Add(-dur)
 	}
 
-	metricsData, err := g.metricsStore.Read(c, pkgmetrics.WithSince(metricsSince), pkgmetrics.WithComponents(components...))
+	readOpts := []pkgmetrics.OpOption{pkgmetrics.WithSince(metricsSince), pkgmetrics.WithComponents(components...)}
+	if namesRaw := c.Query("name"); namesRaw != "" {
+		readOpts = append(readOpts, pkgmetrics.WithMetricNames(strings.Split(namesRaw, ",")...))
+	}
+
+	var step time.Duration
+	if stepRaw := c.Query("step"); stepRaw != "" {
+		step, err = time.ParseDuration(stepRaw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to parse step: " + err.Error()})
+			return
+		}
+		if step < MinMetricsQueryStep {
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": fmt.Sprintf("step must be at least %s", MinMetricsQueryStep)})
+			return
+		}
+		if points := int64(now.Sub(metricsSince) / step); points > MaxMetricsQueryPoints {
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": fmt.Sprintf("too many data points per series (%d > %d), increase the step", points, MaxMetricsQueryPoints)})
+			return
+		}
+	}
+
+	metricsData, err := g.metricsStore.Read(c, readOpts...)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to read metrics: " + err.Error()})
 		return
 	}
+	if step > 0 {
+		metricsData = pkgmetrics.Downsample(metricsData, step)
+	}
 
 	metrics := pkgmetrics.ConvertToLeptonMetrics(metricsData)
+	for i := range metrics {
+		metrics[i].Metrics = g.labels.ApplyToMetrics(metrics[i].Metrics)
+	}
+
 	switch c.GetHeader(httputil.RequestHeaderContentType) {
 	case httputil.RequestHeaderYAML:
 		yb, err := yaml.Marshal(metrics)
@@ -608,6 +808,14 @@ func (g *globalHandler) getMetrics(c *gin.Context) {
 		}
 		c.String(http.StatusOK, string(yb))
 
+	case httputil.RequestHeaderProtobuf:
+		pb, err := metrics.MarshalProto()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to marshal metrics " + err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, httputil.RequestHeaderProtobuf, pb)
+
 	case httputil.RequestHeaderJSON, "":
 		if c.GetHeader(httputil.RequestHeaderJSONIndent) == "true" {
 			c.IndentedJSON(http.StatusOK, metrics)
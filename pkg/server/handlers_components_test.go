@@ -20,7 +20,9 @@ import (
 	"github.com/leptonai/gpud/components"
 	"github.com/leptonai/gpud/pkg/config"
 	pkgcustomplugins "github.com/leptonai/gpud/pkg/custom-plugins"
+	pkgescalation "github.com/leptonai/gpud/pkg/escalation"
 	"github.com/leptonai/gpud/pkg/httputil"
+	pkglabels "github.com/leptonai/gpud/pkg/labels"
 	"github.com/leptonai/gpud/pkg/metrics"
 )
 
@@ -229,7 +231,7 @@ func TestGetComponents(t *testing.T) {
 	cfg := &config.Config{}
 	store := &mockMetricsStore{}
 
-	handler := newGlobalHandler(cfg, registry, store, nil, nil)
+	handler := newGlobalHandler(cfg, registry, store, nil, nil, nil)
 	_, c, w := setupTestRouter()
 
 	// Test with default JSON content type
@@ -259,7 +261,7 @@ func TestGetComponentsYAML(t *testing.T) {
 	cfg := &config.Config{}
 	store := &mockMetricsStore{}
 
-	handler := newGlobalHandler(cfg, registry, store, nil, nil)
+	handler := newGlobalHandler(cfg, registry, store, nil, nil, nil)
 	_, c, w := setupTestRouter()
 
 	// Set up a new request with YAML content type
@@ -281,6 +283,87 @@ func TestGetComponentsYAML(t *testing.T) {
 	assert.Len(t, components, 2)
 }
 
+func TestGetComponentsLatency(t *testing.T) {
+	handler, _, _ := setupTestHandler([]components.Component{
+		&mockComponent{name: "comp1", isSupported: true},
+		&mockComponent{name: "comp2", isSupported: true},
+	})
+
+	// not tracked
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/components/latency", nil)
+	handler.getComponentsLatency(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	handler.checkStats = components.NewCheckStats()
+	handler.checkStats.Record("comp1", 2*time.Second, time.Second)
+	handler.checkStats.Record("comp2", time.Second, 0)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/components/latency", nil)
+	handler.getComponentsLatency(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var latencies apiv1.ComponentCheckLatencies
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &latencies))
+	require.Len(t, latencies, 2)
+	assert.Equal(t, "comp1", latencies[0].Component)
+	assert.Equal(t, 2*time.Second, latencies[0].LastDuration.Duration)
+	assert.Equal(t, time.Second, latencies[0].LastCPUTime.Duration)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/components/latency?components=comp2", nil)
+	handler.getComponentsLatency(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &latencies))
+	require.Len(t, latencies, 1)
+	assert.Equal(t, "comp2", latencies[0].Component)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/components/latency?components=unknown", nil)
+	handler.getComponentsLatency(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetComponentsCapabilities(t *testing.T) {
+	handler, _, _ := setupTestHandler(nil)
+
+	// not generated
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/components/capabilities", nil)
+	handler.getComponentsCapabilities(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	handler.capabilities = &apiv1.ComponentCapabilityReport{
+		Components: []apiv1.ComponentCapability{
+			{Component: "comp1", Enabled: true, Status: apiv1.ComponentCapabilityStatusEnabled},
+			{Component: "comp2", Status: apiv1.ComponentCapabilityStatusDisabledByConfig, Reason: "disabled"},
+		},
+	}
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/components/capabilities", nil)
+	handler.getComponentsCapabilities(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var report apiv1.ComponentCapabilityReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, handler.capabilities.Components, report.Components)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/components/capabilities", nil)
+	c.Request.Header.Set(httputil.RequestHeaderContentType, httputil.RequestHeaderYAML)
+	handler.getComponentsCapabilities(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "DisabledByConfig")
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/components/capabilities", nil)
+	c.Request.Header.Set(httputil.RequestHeaderContentType, "invalid")
+	handler.getComponentsCapabilities(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
 func TestTriggerComponentCheck(t *testing.T) {
 	// Create a mock component with health states
 	healthStates := apiv1.HealthStates{
@@ -550,7 +633,7 @@ func TestGetInfo(t *testing.T) {
 	cfg := &config.Config{}
 	store := &mockMetricsStore{metrics: metricsData}
 
-	handler := newGlobalHandler(cfg, registry, store, nil, nil)
+	handler := newGlobalHandler(cfg, registry, store, nil, nil, nil)
 	_, c, w := setupTestRouter()
 
 	// Test getting info for a specific component
@@ -621,7 +704,7 @@ func TestGetMetrics(t *testing.T) {
 	cfg := &config.Config{}
 	store := &mockMetricsStore{metrics: metricsData}
 
-	handler := newGlobalHandler(cfg, registry, store, nil, nil)
+	handler := newGlobalHandler(cfg, registry, store, nil, nil, nil)
 	_, c, w := setupTestRouter()
 
 	// Test getting metrics for a specific component
@@ -660,6 +743,7 @@ func TestRegisterComponentRoutes(t *testing.T) {
 		path   string
 	}{
 		{"GET", "/v1/components"},
+		{"GET", "/v1/components/latency"},
 		{"GET", "/v1/components/trigger-check?componentName=test"},
 		{"GET", "/v1/states"},
 		{"GET", "/v1/events"},
@@ -691,7 +775,7 @@ func TestGetComponentsInvalidContentType(t *testing.T) {
 	cfg := &config.Config{}
 	store := &mockMetricsStore{}
 
-	handler := newGlobalHandler(cfg, registry, store, nil, nil)
+	handler := newGlobalHandler(cfg, registry, store, nil, nil, nil)
 	_, c, w := setupTestRouter()
 
 	// Test with invalid content type
@@ -714,7 +798,7 @@ func TestGetComponentsIndentedJSON(t *testing.T) {
 	cfg := &config.Config{}
 	store := &mockMetricsStore{}
 
-	handler := newGlobalHandler(cfg, registry, store, nil, nil)
+	handler := newGlobalHandler(cfg, registry, store, nil, nil, nil)
 	_, c, w := setupTestRouter()
 
 	// Test with JSON indent header
@@ -909,6 +993,49 @@ func TestGetHealthStatesYAML(t *testing.T) {
 	assert.Equal(t, "comp1", states[0].Component)
 }
 
+func TestGetHealthStatesSchemaVersion(t *testing.T) {
+	comp := &mockComponent{
+		name:         "comp1",
+		isSupported:  true,
+		healthStates: apiv1.HealthStates{{Health: apiv1.HealthStateTypeHealthy, Reason: "Component is healthy"}},
+	}
+	handler, _, _ := setupTestHandler([]components.Component{comp})
+
+	// current schema version by default
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/states", nil)
+	handler.getHealthStates(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var states apiv1.GPUdComponentHealthStates
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &states))
+	require.Len(t, states, 1)
+	require.Len(t, states[0].States, 1)
+	assert.Equal(t, components.VersionOf(comp), states[0].States[0].ComponentVersion)
+	assert.Equal(t, apiv1.CurrentSchemaVersion, states[0].States[0].SchemaVersion)
+
+	// the older consumers get the older schema
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/states", nil)
+	c.Request.Header.Set(httputil.RequestHeaderSchemaVersion, apiv1.SchemaVersion1)
+	handler.getHealthStates(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	states = nil
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &states))
+	require.Len(t, states, 1)
+	require.Len(t, states[0].States, 1)
+	assert.Empty(t, states[0].States[0].ComponentVersion)
+	assert.Empty(t, states[0].States[0].SchemaVersion)
+	assert.NotContains(t, w.Body.String(), "schema_version")
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/states", nil)
+	c.Request.Header.Set(httputil.RequestHeaderSchemaVersion, "v99")
+	handler.getHealthStates(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
 func TestGetHealthStatesInvalidContentType(t *testing.T) {
 	handler, _, _ := setupTestHandler([]components.Component{})
 	_, c, w := setupTestRouter()
@@ -1029,7 +1156,7 @@ func TestGetInfoYAML(t *testing.T) {
 	cfg := &config.Config{}
 	store := &mockMetricsStore{metrics: metricsData}
 
-	handler := newGlobalHandler(cfg, registry, store, nil, nil)
+	handler := newGlobalHandler(cfg, registry, store, nil, nil, nil)
 	_, c, w := setupTestRouter()
 
 	c.Request = httptest.NewRequest("GET", "/v1/info", nil)
@@ -1068,7 +1195,7 @@ func TestGetMetricsYAML(t *testing.T) {
 	cfg := &config.Config{}
 	store := &mockMetricsStore{metrics: metricsData}
 
-	handler := newGlobalHandler(cfg, registry, store, nil, nil)
+	handler := newGlobalHandler(cfg, registry, store, nil, nil, nil)
 	_, c, w := setupTestRouter()
 
 	c.Request = httptest.NewRequest("GET", "/v1/metrics", nil)
@@ -1103,7 +1230,7 @@ func TestGetMetricsWithSinceParam(t *testing.T) {
 	cfg := &config.Config{}
 	store := &mockMetricsStore{metrics: metricsData}
 
-	handler := newGlobalHandler(cfg, registry, store, nil, nil)
+	handler := newGlobalHandler(cfg, registry, store, nil, nil, nil)
 	_, c, w := setupTestRouter()
 
 	c.Request = httptest.NewRequest("GET", "/v1/metrics?since=1h", nil)
@@ -1142,7 +1269,7 @@ func TestGetMetricsStoreError(t *testing.T) {
 	cfg := &config.Config{}
 	store := &mockMetricsStore{err: errors.New("store error")}
 
-	handler := newGlobalHandler(cfg, registry, store, nil, nil)
+	handler := newGlobalHandler(cfg, registry, store, nil, nil, nil)
 	_, c, w := setupTestRouter()
 
 	c.Request = httptest.NewRequest("GET", "/v1/metrics", nil)
@@ -1155,3 +1282,137 @@ func TestGetMetricsStoreError(t *testing.T) {
 	require.NoError(t, err)
 	assert.Contains(t, response["message"], "failed to read metrics")
 }
+
+func TestGetProtobuf(t *testing.T) {
+	now := time.Now()
+	comp := &mockComponent{
+		name:         "comp1",
+		isSupported:  true,
+		healthStates: apiv1.HealthStates{{Health: apiv1.HealthStateTypeHealthy, Reason: "Component is healthy"}},
+		events:       apiv1.Events{{Time: metav1.NewTime(now), Name: "ev", Type: apiv1.EventTypeInfo}},
+	}
+	registry := newMockRegistry()
+	registry.AddMockComponent(comp)
+	store := &mockMetricsStore{metrics: []metrics.Metric{
+		{UnixMilliseconds: 1234567890000, Component: "comp1", Name: "test-metric", Value: 42.0},
+	}}
+	handler := newGlobalHandler(&config.Config{}, registry, store, nil, nil, nil)
+
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/states", nil)
+	c.Request.Header.Set(httputil.RequestHeaderContentType, httputil.RequestHeaderProtobuf)
+	handler.getHealthStates(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, httputil.RequestHeaderProtobuf, w.Header().Get("Content-Type"))
+	var states apiv1.GPUdComponentHealthStates
+	require.NoError(t, states.UnmarshalProto(w.Body.Bytes()))
+	require.Len(t, states, 1)
+	assert.Equal(t, "Component is healthy", states[0].States[0].Reason)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", fmt.Sprintf("/v1/events?startTime=%d", now.Add(-time.Hour).Unix()), nil)
+	c.Request.Header.Set(httputil.RequestHeaderContentType, httputil.RequestHeaderProtobuf)
+	handler.getEvents(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var events apiv1.GPUdComponentEvents
+	require.NoError(t, events.UnmarshalProto(w.Body.Bytes()))
+	require.Len(t, events, 1)
+	require.Len(t, events[0].Events, 1)
+	assert.Equal(t, "ev", events[0].Events[0].Name)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/metrics", nil)
+	c.Request.Header.Set(httputil.RequestHeaderContentType, httputil.RequestHeaderProtobuf)
+	handler.getMetrics(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var ms apiv1.GPUdComponentMetrics
+	require.NoError(t, ms.UnmarshalProto(w.Body.Bytes()))
+	require.Len(t, ms, 1)
+	assert.Equal(t, 42.0, ms[0].Metrics[0].Value)
+}
+
+func TestGetMetricsStep(t *testing.T) {
+	now := time.Now()
+	store := &mockMetricsStore{metrics: []metrics.Metric{
+		{UnixMilliseconds: now.Add(-2 * time.Minute).Truncate(time.Minute).UnixMilli(), Component: "comp1", Name: "m", Value: 1},
+		{UnixMilliseconds: now.Add(-2 * time.Minute).Truncate(time.Minute).Add(time.Second).UnixMilli(), Component: "comp1", Name: "m", Value: 3},
+	}}
+	registry := newMockRegistry()
+	registry.AddMockComponent(&mockComponent{name: "comp1", isSupported: true})
+	handler := newGlobalHandler(&config.Config{}, registry, store, nil, nil, nil)
+
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/metrics?name=m&step=1m", nil)
+	handler.getMetrics(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var ms apiv1.GPUdComponentMetrics
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &ms))
+	require.Len(t, ms, 1)
+	require.Len(t, ms[0].Metrics, 1)
+	assert.Equal(t, 2.0, ms[0].Metrics[0].Value)
+
+	for _, q := range []string{"step=abc", "step=1ms", "since=720h&step=1s"} {
+		_, c, w = setupTestRouter()
+		c.Request = httptest.NewRequest("GET", "/v1/metrics?"+q, nil)
+		handler.getMetrics(c)
+		assert.Equal(t, http.StatusBadRequest, w.Code, q)
+	}
+}
+
+func TestGetHealthStatesWithLabels(t *testing.T) {
+	comp := &mockComponent{
+		name:         "comp1",
+		isSupported:  true,
+		healthStates: apiv1.HealthStates{{Health: apiv1.HealthStateTypeHealthy}},
+	}
+
+	handler, _, _ := setupTestHandler([]components.Component{comp})
+	handler.labels = pkglabels.New(map[string]string{"rack": "r1"})
+	_, c, w := setupTestRouter()
+
+	c.Request = httptest.NewRequest("GET", "/v1/states", nil)
+	handler.getHealthStates(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var states apiv1.GPUdComponentHealthStates
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &states))
+	require.Len(t, states, 1)
+	require.Len(t, states[0].States, 1)
+	assert.Equal(t, map[string]string{"rack": "r1"}, states[0].States[0].Labels)
+	assert.Nil(t, comp.healthStates[0].Labels)
+}
+
+func TestGetHealthStatesWithEscalation(t *testing.T) {
+	comp := &mockComponent{
+		name:         "comp1",
+		isSupported:  true,
+		healthStates: apiv1.HealthStates{{Health: apiv1.HealthStateTypeUnhealthy, Reason: "down"}},
+	}
+
+	handler, registry, _ := setupTestHandler([]components.Component{comp})
+	tracker, err := pkgescalation.NewTracker(registry, nil)
+	require.NoError(t, err)
+	handler.escalation = tracker
+
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/states", nil)
+	handler.getHealthStates(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var states apiv1.GPUdComponentHealthStates
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &states))
+	require.Len(t, states, 1)
+	require.Len(t, states[0].States, 1)
+	assert.Equal(t, pkgescalation.BaseSeverity, states[0].States[0].Severity)
+	assert.NotNil(t, states[0].States[0].UnhealthySince)
+	assert.Empty(t, comp.healthStates[0].Severity)
+
+	// the older consumers do not get the severity
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/states", nil)
+	c.Request.Header.Set(httputil.RequestHeaderSchemaVersion, apiv1.SchemaVersion3)
+	handler.getHealthStates(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "severity")
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"sigs.k8s.io/yaml"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgdisruption "github.com/leptonai/gpud/pkg/disruption"
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/httputil"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+func (g *globalHandler) registerDisruptionRoutes(r gin.IRoutes) {
+	r.GET(URLPathDisruptions, g.getDisruptions)
+	r.POST(URLPathDisruptions, g.addDisruption)
+	r.DELETE(URLPathDisruptions+"/:id", g.deleteDisruption)
+}
+
+// URLPathDisruptions is for declaring the windows of the expected disruption of the components
+const URLPathDisruptions = "/disruptions"
+
+// getDisruptions godoc
+// @Summary Get expected disruption windows
+// @Description Returns the windows of the expected disruption declared by the external schedulers, not ended yet, in the order of the start time.
+// @ID getDisruptions
+// @Tags components
+// @Produce json
+// @Header 200 {string} Content-Type "application/json or application/yaml"
+// @Param Accept header string false "Content type preference" Enums(application/json,application/yaml)
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {array} apiv1.DisruptionWindow "Expected disruption windows"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid content type"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/disruptions [get]
+func (g *globalHandler) getDisruptions(c *gin.Context) {
+	windows := g.disruptions.List(time.Now().UTC())
+	if windows == nil {
+		windows = []apiv1.DisruptionWindow{}
+	}
+
+	switch c.GetHeader(httputil.RequestHeaderContentType) {
+	case httputil.RequestHeaderYAML:
+		yb, err := yaml.Marshal(windows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to marshal disruption windows " + err.Error()})
+			return
+		}
+		c.String(http.StatusOK, string(yb))
+
+	case httputil.RequestHeaderJSON, "":
+		if c.GetHeader(httputil.RequestHeaderJSONIndent) == "true" {
+			c.IndentedJSON(http.StatusOK, windows)
+			return
+		}
+		c.JSON(http.StatusOK, windows)
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid content type"})
+	}
+}
+
+// addDisruption godoc
+// @Summary Declare an expected disruption window
+// @Description Declares the window of the expected disruption of the components (e.g., the infiniband fabric upgrade from 2am to 4am), and persists it across the restarts. The findings of the components inside the window are tagged as expected and not re-escalated, their events are not sent to the webhook sinks, and the escalation ladders, the health transition hooks, and the automatic remediation actions are held, while still recorded. Declaring the window with the existing id replaces the window. Returns the declared window with its id.
+// @ID addDisruption
+// @Tags components
+// @Accept json
+// @Produce json
+// @Param request body apiv1.DisruptionWindow true "Expected disruption window"
+// @Success 200 {object} apiv1.DisruptionWindow "Declared window"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid request body or window"
+// @Failure 404 {object} map[string]interface{} "Expected disruption windows not available"
+// @Failure 500 {object} map[string]interface{} "Internal server error - failed to persist the windows, the window not declared"
+// @Router /v1/disruptions [post]
+func (g *globalHandler) addDisruption(c *gin.Context) {
+	if g.disruptions == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "expected disruption windows not available"})
+		return
+	}
+
+	var req apiv1.DisruptionWindow
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to decode request body: " + err.Error()})
+		return
+	}
+
+	now := time.Now().UTC()
+	w, err := g.disruptions.Add(now, req, g.persistDisruptions(c))
+	if err != nil {
+		if errors.Is(err, pkgdisruption.ErrInvalidWindow) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"code": errdefs.ErrUnknown, "message": err.Error()})
+		return
+	}
+	log.Logger.Infow("declared expected disruption window", "id", w.ID, "components", w.Components, "start", w.Start.Time, "end", w.End.Time, "reason", w.Reason, "requester", w.Requester)
+
+	c.JSON(http.StatusOK, w)
+}
+
+// deleteDisruption godoc
+// @Summary Delete an expected disruption window
+// @Description Deletes the window of the expected disruption (e.g., the maintenance finished early or canceled), so that the findings of the components are paged again.
+// @ID deleteDisruption
+// @Tags components
+// @Produce json
+// @Param id path string true "Window id"
+// @Success 200 {object} map[string]string "Window deleted"
+// @Failure 404 {object} map[string]interface{} "Window not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error - failed to persist the windows, the window not deleted"
+// @Router /v1/disruptions/{id} [delete]
+func (g *globalHandler) deleteDisruption(c *gin.Context) {
+	if g.disruptions == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "expected disruption windows not available"})
+		return
+	}
+
+	id := c.Param("id")
+	if err := g.disruptions.Delete(time.Now().UTC(), id, g.persistDisruptions(c)); err != nil {
+		if errors.Is(err, pkgdisruption.ErrWindowNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"code": errdefs.ErrUnknown, "message": err.Error()})
+		return
+	}
+	log.Logger.Infow("deleted expected disruption window", "id", id)
+
+	c.JSON(http.StatusOK, gin.H{"message": "disruption window deleted"})
+}
+
+// persistDisruptions returns the function to persist the windows before they are applied,
+// or nil if the database is not available.
+func (g *globalHandler) persistDisruptions(c *gin.Context) pkgdisruption.PersistFunc {
+	if g.dbRW == nil {
+		return nil
+	}
+	return func(windows []apiv1.DisruptionWindow) error {
+		return pkgdisruption.Save(c, g.dbRW, windows)
+	}
+}
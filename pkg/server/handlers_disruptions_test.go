@@ -0,0 +1,115 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	pkgdisruption "github.com/leptonai/gpud/pkg/disruption"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestDisruptions(t *testing.T) {
+	handler, _, _ := setupTestHandler([]components.Component{})
+
+	// not tracked
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/disruptions", nil)
+	handler.getDisruptions(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, "[]", w.Body.String())
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("POST", "/v1/disruptions", bytes.NewReader([]byte("{}")))
+	handler.addDisruption(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	handler.disruptions = pkgdisruption.New()
+
+	start := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	b, err := json.Marshal(apiv1.DisruptionWindow{
+		Components: []string{"accelerator-nvidia-infiniband"},
+		Start:      metav1.NewTime(start),
+		End:        metav1.NewTime(start.Add(2 * time.Hour)),
+		Reason:     "IB fabric upgrade",
+		Requester:  "slurm",
+	})
+	require.NoError(t, err)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("POST", "/v1/disruptions", bytes.NewReader(b))
+	handler.addDisruption(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var declared apiv1.DisruptionWindow
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &declared))
+	assert.NotEmpty(t, declared.ID)
+	assert.Equal(t, "IB fabric upgrade", declared.Reason)
+
+	// invalid window
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("POST", "/v1/disruptions", bytes.NewReader([]byte(`{"components":["disk"]}`)))
+	handler.addDisruption(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("POST", "/v1/disruptions", bytes.NewReader([]byte("invalid")))
+	handler.addDisruption(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/disruptions", nil)
+	handler.getDisruptions(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var windows []apiv1.DisruptionWindow
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &windows))
+	require.Len(t, windows, 1)
+	assert.Equal(t, declared.ID, windows[0].ID)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("DELETE", "/v1/disruptions/"+declared.ID, nil)
+	c.Params = gin.Params{{Key: "id", Value: declared.ID}}
+	handler.deleteDisruption(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("DELETE", "/v1/disruptions/"+declared.ID, nil)
+	c.Params = gin.Params{{Key: "id", Value: declared.ID}}
+	handler.deleteDisruption(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDisruptionsNotPersisted(t *testing.T) {
+	// no metadata table to persist the windows to
+	dbRW, _, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	handler, _, _ := setupTestHandler([]components.Component{})
+	handler.dbRW = dbRW
+	handler.disruptions = pkgdisruption.New()
+
+	start := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	b, err := json.Marshal(apiv1.DisruptionWindow{
+		Components: []string{"accelerator-nvidia-infiniband"},
+		Start:      metav1.NewTime(start),
+		End:        metav1.NewTime(start.Add(2 * time.Hour)),
+	})
+	require.NoError(t, err)
+
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("POST", "/v1/disruptions", bytes.NewReader(b))
+	handler.addDisruption(c)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	// the window not persisted is not declared
+	assert.Empty(t, handler.disruptions.List(time.Now().UTC()))
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"sigs.k8s.io/yaml"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/httputil"
+)
+
+// URLPathFindings is for getting the findings reported to the control plane and their acknowledgments
+const URLPathFindings = "/findings"
+
+// getFindings godoc
+// @Summary Get the reported findings
+// @Description Returns the unhealthy (or degraded) findings reported to the control plane, with whether the control plane acknowledged each finding, and how many times the unacknowledged finding was re-escalated.
+// @ID getFindings
+// @Tags components
+// @Produce json
+// @Param Accept header string false "Content type preference" Enums(application/json,application/yaml)
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {array} apiv1.Finding "Reported findings"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid content type"
+// @Router /v1/findings [get]
+func (g *globalHandler) getFindings(c *gin.Context) {
+	findings := g.findings.List()
+	if findings == nil {
+		findings = []apiv1.Finding{}
+	}
+
+	switch c.GetHeader(httputil.RequestHeaderContentType) {
+	case httputil.RequestHeaderYAML:
+		yb, err := yaml.Marshal(findings)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to marshal findings " + err.Error()})
+			return
+		}
+		c.String(http.StatusOK, string(yb))
+
+	case httputil.RequestHeaderJSON, "":
+		if c.GetHeader(httputil.RequestHeaderJSONIndent) == "true" {
+			c.IndentedJSON(http.StatusOK, findings)
+			return
+		}
+		c.JSON(http.StatusOK, findings)
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid content type"})
+	}
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	pkgfindings "github.com/leptonai/gpud/pkg/findings"
+	"github.com/leptonai/gpud/pkg/httputil"
+)
+
+func TestGetFindings(t *testing.T) {
+	handler, _, _ := setupTestHandler([]components.Component{})
+
+	// not tracked
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/findings", nil)
+	handler.getFindings(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, "[]", w.Body.String())
+
+	handler.findings = pkgfindings.NewTracker(time.Minute)
+	pending := handler.findings.Observe(time.Now(), apiv1.GPUdComponentHealthStates{
+		{Component: "comp1", States: apiv1.HealthStates{{Name: "comp1", Health: apiv1.HealthStateTypeUnhealthy}}},
+		{Component: "comp2", States: apiv1.HealthStates{{Name: "comp2", Health: apiv1.HealthStateTypeDegraded}}},
+	})
+	require.Len(t, pending, 2)
+	handler.findings.Acknowledge(time.Now(), []apiv1.FindingAck{{FindingID: pending[0].ID, AckID: "INC-1"}})
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/findings", nil)
+	handler.getFindings(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var findings []apiv1.Finding
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &findings))
+	require.Len(t, findings, 2)
+	assert.Equal(t, "INC-1", findings[0].AckID)
+	assert.False(t, findings[1].Acknowledged())
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/findings", nil)
+	c.Request.Header.Set(httputil.RequestHeaderContentType, "application/xml")
+	handler.getFindings(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
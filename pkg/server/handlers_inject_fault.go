@@ -14,7 +14,7 @@ const URLPathInjectFault = "/inject-fault"
 
 // injectFault godoc
 // @Summary Inject fault into the system
-// @Description Injects a fault (such as kernel messages) into the system for testing purposes
+// @Description Injects a fault (such as kernel messages, or component unhealthy results, timeouts, and panics if enabled) into the system for testing purposes
 // @ID injectFault
 // @Tags fault-injection
 // @Accept json
@@ -49,8 +49,23 @@ func (g *globalHandler) injectFault(c *gin.Context) {
 			return
 		}
 
+	case request.Component != nil:
+		faults := g.faultInjector.ComponentFaults()
+		if faults == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": pkgfaultinjector.ErrComponentFaultInjectionDisabled.Error()})
+			return
+		}
+		if g.componentsRegistry.Get(request.Component.Component) == nil {
+			c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "component not found"})
+			return
+		}
+		if _, err := faults.Arm(*request.Component); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to inject component fault: " + err.Error()})
+			return
+		}
+
 	default:
-		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "kernel message is required"})
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "kernel message or component fault is required"})
 		return
 	}
 
@@ -30,6 +30,8 @@ func (m *mockKmsgWriter) Write(msg *pkgkmsgwriter.KernelMessage) error {
 // Mock fault injector for testing
 type mockFaultInjector struct {
 	mock.Mock
+
+	componentFaults *pkgfaultinjector.ComponentFaults
 }
 
 func (m *mockFaultInjector) KmsgWriter() pkgkmsgwriter.KmsgWriter {
@@ -40,6 +42,10 @@ func (m *mockFaultInjector) KmsgWriter() pkgkmsgwriter.KmsgWriter {
 	return args.Get(0).(pkgkmsgwriter.KmsgWriter)
 }
 
+func (m *mockFaultInjector) ComponentFaults() *pkgfaultinjector.ComponentFaults {
+	return m.componentFaults
+}
+
 // Helper function to setup test context
 func setupInjectFaultTest() (*gin.Engine, *httptest.ResponseRecorder) {
 	gin.SetMode(gin.TestMode)
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	componentsnvidiainfiniband "github.com/leptonai/gpud/components/accelerator/nvidia/infiniband"
+	componentsnvidianccl "github.com/leptonai/gpud/components/accelerator/nvidia/nccl"
+	componentsnvidiapeermem "github.com/leptonai/gpud/components/accelerator/nvidia/peermem"
+	componentsnvidiasxid "github.com/leptonai/gpud/components/accelerator/nvidia/sxid"
+	componentsnvidiaxid "github.com/leptonai/gpud/components/accelerator/nvidia/xid"
+	componentscpu "github.com/leptonai/gpud/components/cpu"
+	componentsdisk "github.com/leptonai/gpud/components/disk"
+	componentsmemory "github.com/leptonai/gpud/components/memory"
+	componentsos "github.com/leptonai/gpud/components/os"
+	"github.com/leptonai/gpud/pkg/errdefs"
+	pkgkmsg "github.com/leptonai/gpud/pkg/kmsg"
+)
+
+func (g *globalHandler) registerKmsgRoutes(r gin.IRoutes) {
+	r.GET(URLPathKmsg, g.getKmsg)
+}
+
+// URLPathKmsg is for getting the recent kernel messages
+const URLPathKmsg = "/kmsg"
+
+// getKmsg godoc
+// @Summary Get recent kernel messages
+// @Description Returns the kernel messages (as in "dmesg") in the order of time, each annotated with the gpud matcher that classifies it (e.g., Xid, OOM kill, soft lockup), if any.
+// @ID getKmsg
+// @Tags kmsg
+// @Produce json
+// @Param since query string false "Only return the messages after the time, either in RFC3339 (e.g., 2025-01-02T15:04:05Z) or as the lookback duration (e.g., 30m)"
+// @Param grep query string false "Regular expression to filter the messages with"
+// @Param matched query bool false "Set to 'true' to only return the messages classified by a matcher"
+// @Success 200 {array} apiv1.KernelMessage "Kernel messages in the order of time"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid since or grep"
+// @Failure 500 {object} map[string]interface{} "Internal server error - failed to read kernel messages"
+// @Router /v1/kmsg [get]
+func (g *globalHandler) getKmsg(c *gin.Context) {
+	since, err := parseSince(c.Query("since"), time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid since: " + err.Error()})
+		return
+	}
+
+	var grep *regexp.Regexp
+	if s := c.Query("grep"); s != "" {
+		grep, err = regexp.Compile(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid grep: " + err.Error()})
+			return
+		}
+	}
+	matchedOnly := c.Query("matched") == "true"
+
+	msgs, err := g.readKmsgFunc(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": errdefs.ErrUnknown, "message": "failed to read kernel messages: " + err.Error()})
+		return
+	}
+
+	ret := make([]apiv1.KernelMessage, 0)
+	for _, msg := range msgs {
+		if !since.IsZero() && msg.Timestamp.Time.Before(since) {
+			continue
+		}
+		if grep != nil && !grep.MatchString(msg.Message) {
+			continue
+		}
+
+		km := apiv1.KernelMessage{
+			Time:     metav1.NewTime(msg.Timestamp.UTC()),
+			Priority: msg.Priority,
+			Message:  msg.Message,
+			Match:    classifyKmsg(msg.Message),
+		}
+		if matchedOnly && km.Match == nil {
+			continue
+		}
+		ret = append(ret, km)
+	}
+
+	c.JSON(http.StatusOK, ret)
+}
+
+// parseSince parses the time in RFC3339, or the lookback duration from now.
+// Returns the zero time if empty.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("negative duration %q", s)
+		}
+		return now.Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// kmsgMatcher is the built-in kernel message matcher of a component.
+type kmsgMatcher struct {
+	component string
+	match     func(line string) (eventName string, message string)
+}
+
+var kmsgMatchers = []kmsgMatcher{
+	{component: componentscpu.Name, match: componentscpu.Match},
+	{component: componentsdisk.Name, match: componentsdisk.Match},
+	{component: componentsmemory.Name, match: componentsmemory.Match},
+	{component: componentsos.Name, match: componentsos.Match},
+	{component: componentsnvidiainfiniband.Name, match: componentsnvidiainfiniband.Match},
+	{component: componentsnvidianccl.Name, match: componentsnvidianccl.Match},
+	{component: componentsnvidiapeermem.Name, match: componentsnvidiapeermem.Match},
+}
+
+// classifyKmsg returns the gpud matcher that classifies the kernel message:
+// the Xid and SXid matchers first, then the built-in matchers of the components,
+// and then the user-supplied rules. Returns nil if none matches.
+func classifyKmsg(line string) *apiv1.KernelMessageMatch {
+	if xidErr := componentsnvidiaxid.Match(line); xidErr != nil {
+		m := &apiv1.KernelMessageMatch{
+			Component: componentsnvidiaxid.Name,
+			EventName: componentsnvidiaxid.EventNameErrorXid,
+		}
+		if xidErr.Detail != nil {
+			m.EventType = xidErr.Detail.EventType
+			m.Description = fmt.Sprintf("Xid %d: %s", xidErr.Xid, xidErr.Detail.Name)
+		}
+		return m
+	}
+	if sxidErr := componentsnvidiasxid.Match(line); sxidErr != nil {
+		m := &apiv1.KernelMessageMatch{
+			Component: componentsnvidiasxid.Name,
+			EventName: componentsnvidiasxid.EventNameErrorSXid,
+		}
+		if sxidErr.Detail != nil {
+			m.EventType = sxidErr.Detail.EventType
+			m.Description = fmt.Sprintf("SXid %d: %s", sxidErr.SXid, sxidErr.Detail.Name)
+		}
+		return m
+	}
+
+	for _, m := range kmsgMatchers {
+		if name, msg := m.match(line); name != "" {
+			return &apiv1.KernelMessageMatch{
+				Component:   m.component,
+				EventName:   name,
+				EventType:   apiv1.EventTypeWarning,
+				Description: msg,
+			}
+		}
+	}
+
+	if rule := pkgkmsg.DefaultRegistry.Match("", line); rule != nil {
+		return &apiv1.KernelMessageMatch{
+			Component:   rule.Component,
+			EventName:   rule.Name,
+			EventType:   rule.EventType,
+			Description: rule.Message,
+		}
+	}
+	return nil
+}
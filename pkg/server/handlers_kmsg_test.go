@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	componentsnvidiaxid "github.com/leptonai/gpud/components/accelerator/nvidia/xid"
+	componentscpu "github.com/leptonai/gpud/components/cpu"
+	pkgkmsg "github.com/leptonai/gpud/pkg/kmsg"
+)
+
+func TestGetKmsg(t *testing.T) {
+	now := time.Now().UTC()
+	handler, _, _ := setupTestHandler(nil)
+	handler.readKmsgFunc = func(ctx context.Context) ([]pkgkmsg.Message, error) {
+		return []pkgkmsg.Message{
+			{Timestamp: metav1.NewTime(now.Add(-2 * time.Hour)), Priority: 6, Message: "old message"},
+			{Timestamp: metav1.NewTime(now.Add(-time.Minute)), Priority: 4, Message: "NVRM: Xid (PCI:0000:01:00): 79, pid=0, GPU has fallen off the bus."},
+			{Timestamp: metav1.NewTime(now.Add(-time.Minute)), Priority: 3, Message: "watchdog: BUG: soft lockup - CPU#18 stuck for 27s! [python3:2254956]"},
+			{Timestamp: metav1.NewTime(now), Priority: 6, Message: "eth0: link up"},
+		}, nil
+	}
+
+	get := func(query string) (int, []apiv1.KernelMessage) {
+		_, c, w := setupTestRouter()
+		c.Request = httptest.NewRequest("GET", "/v1/kmsg"+query, nil)
+		handler.getKmsg(c)
+
+		var msgs []apiv1.KernelMessage
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &msgs))
+		}
+		return w.Code, msgs
+	}
+
+	code, msgs := get("")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, msgs, 4)
+	assert.Nil(t, msgs[0].Match)
+	require.NotNil(t, msgs[1].Match)
+	assert.Equal(t, componentsnvidiaxid.Name, msgs[1].Match.Component)
+	assert.Equal(t, componentsnvidiaxid.EventNameErrorXid, msgs[1].Match.EventName)
+	assert.Contains(t, msgs[1].Match.Description, "Xid 79")
+	require.NotNil(t, msgs[2].Match)
+	assert.Equal(t, componentscpu.Name, msgs[2].Match.Component)
+
+	code, msgs = get("?since=1h")
+	require.Equal(t, http.StatusOK, code)
+	assert.Len(t, msgs, 3)
+
+	code, msgs = get("?since=" + now.Add(-30*time.Second).Format(time.RFC3339))
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, "eth0: link up", msgs[0].Message)
+
+	code, msgs = get("?grep=NVRM|lockup")
+	require.Equal(t, http.StatusOK, code)
+	assert.Len(t, msgs, 2)
+
+	code, msgs = get("?matched=true")
+	require.Equal(t, http.StatusOK, code)
+	assert.Len(t, msgs, 2)
+
+	code, _ = get("?since=invalid")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = get("?grep=(")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	handler.readKmsgFunc = func(ctx context.Context) ([]pkgkmsg.Message, error) {
+		return nil, errors.New("permission denied")
+	}
+	code, _ = get("")
+	assert.Equal(t, http.StatusInternalServerError, code)
+}
@@ -28,7 +28,7 @@ func (g *globalHandler) machineInfo(c *gin.Context) {
 		return
 	}
 
-	info, err := pkgmachineinfo.GetMachineInfo(g.gpudInstance.NVMLInstance)
+	info, err := pkgmachineinfo.GetMachineInfoWithCache(c, g.gpudInstance.NVMLInstance, g.probeCache)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"code": errdefs.ErrUnknown, "message": "failed to get machine info: " + err.Error()})
 		return
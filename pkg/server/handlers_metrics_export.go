@@ -0,0 +1,135 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/httputil"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+const (
+	// URLPathMetricsExport is for exporting the raw metrics data points in bulk
+	URLPathMetricsExport = "/metrics/export"
+
+	// metricsExportFlushRows is the number of the exported rows
+	// written before flushing the response to the client.
+	metricsExportFlushRows = 1000
+
+	// contentTypeJSONL is the content type of the newline-delimited JSON.
+	contentTypeJSONL = "application/x-ndjson"
+)
+
+// exportMetrics godoc
+// @Summary Export raw metrics data points
+// @Description Streams the raw metrics data points (without aggregation) in CSV or JSON lines, in the ascending order of the timestamps, for the bulk extraction of the node telemetry (e.g., into notebooks) without scraping. Exports all retained data points by default.
+// @ID exportMetrics
+// @Tags components
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Header 200 {string} Content-Type "text/csv or application/x-ndjson"
+// @Param format query string false "Export format - defaults to csv" Enums(csv,jsonl)
+// @Param since query string false "Start of the time range, as a duration before now (e.g., '6h'), unix seconds, or RFC3339 - defaults to all retained data points"
+// @Param components query string false "Comma-separated list of component names to export (if empty, exports all components)"
+// @Param name query string false "Comma-separated list of metric names to export (if empty, exports all metrics)"
+// @Success 200 {string} string "Data points, one per row (CSV with the header) or line (JSON lines)"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid format, component parsing error, or time parsing error"
+// @Failure 404 {object} map[string]interface{} "Component not found"
+// @Router /v1/metrics/export [get]
+func (g *globalHandler) exportMetrics(c *gin.Context) {
+	format := pkgmetrics.ExportFormatCSV
+	if raw := c.Query("format"); raw != "" {
+		f, err := pkgmetrics.ParseExportFormat(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": err.Error()})
+			return
+		}
+		format = f
+	}
+
+	components, err := g.getReqComponents(c)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "component not found: " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to parse components: " + err.Error()})
+		return
+	}
+
+	readOpts := []pkgmetrics.OpOption{pkgmetrics.WithComponents(components...)}
+	if raw := c.Query("since"); raw != "" {
+		since, err := parseMetricsExportSince(raw, time.Now().UTC())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to parse since: " + err.Error()})
+			return
+		}
+		readOpts = append(readOpts, pkgmetrics.WithSince(since))
+	}
+	if namesRaw := c.Query("name"); namesRaw != "" {
+		readOpts = append(readOpts, pkgmetrics.WithMetricNames(strings.Split(namesRaw, ",")...))
+	}
+
+	contentType := httputil.RequestHeaderCSV
+	if format == pkgmetrics.ExportFormatJSONL {
+		contentType = contentTypeJSONL
+	}
+	c.Header(httputil.RequestHeaderContentType, contentType)
+	c.Status(http.StatusOK)
+
+	ew, err := pkgmetrics.NewExportWriter(c.Writer, format)
+	if err != nil {
+		log.Logger.Errorw("failed to write metrics export header", "error", err)
+		return
+	}
+
+	// the response status is already sent, so the errors
+	// can only be logged and end the stream early
+	labels := g.labels.Get()
+	rows := 0
+	err = pkgmetrics.ReadEach(c, g.metricsStore, func(m pkgmetrics.Metric) error {
+		if len(labels) > 0 {
+			merged := make(map[string]string, len(labels)+len(m.Labels))
+			for k, v := range labels {
+				merged[k] = v
+			}
+			for k, v := range m.Labels {
+				merged[k] = v
+			}
+			m.Labels = merged
+		}
+		if err := ew.Write(m); err != nil {
+			return err
+		}
+
+		rows++
+		if rows%metricsExportFlushRows == 0 {
+			if err := ew.Flush(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+		return nil
+	}, readOpts...)
+	if ferr := ew.Flush(); err == nil {
+		err = ferr
+	}
+	if err != nil {
+		log.Logger.Errorw("failed to export metrics", "format", format, "rows", rows, "error", err)
+	}
+}
+
+// parseMetricsExportSince parses the start of the time range,
+// as a duration before now, unix seconds, or RFC3339.
+func parseMetricsExportSince(s string, now time.Time) (time.Time, error) {
+	if dur, err := time.ParseDuration(s); err == nil {
+		return now.Add(-dur), nil
+	}
+	return parseTimelineTime(s)
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/httputil"
+	pkglabels "github.com/leptonai/gpud/pkg/labels"
+	"github.com/leptonai/gpud/pkg/metrics"
+)
+
+func TestExportMetrics(t *testing.T) {
+	comp := &mockComponent{name: "comp1", isSupported: true}
+	handler, _, store := setupTestHandler([]components.Component{comp})
+	handler.labels = pkglabels.New(map[string]string{"rack": "r1"})
+	store.metrics = []metrics.Metric{
+		{UnixMilliseconds: 1700000000000, Component: "comp1", Name: "m1", Value: 1.5},
+		{UnixMilliseconds: 1700000001000, Component: "comp1", Name: "m2", Value: 2, Labels: map[string]string{"gpu_id": "0"}},
+	}
+
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/metrics/export", nil)
+	handler.exportMetrics(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, httputil.RequestHeaderCSV, w.Header().Get(httputil.RequestHeaderContentType))
+	assert.Equal(t, `unix_milliseconds,component,name,value,labels
+1700000000000,comp1,m1,1.5,"{""rack"":""r1""}"
+1700000001000,comp1,m2,2,"{""gpu_id"":""0"",""rack"":""r1""}"
+`, w.Body.String())
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/metrics/export?format=jsonl&since=1h", nil)
+	handler.exportMetrics(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, contentTypeJSONL, w.Header().Get(httputil.RequestHeaderContentType))
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, `{"unix_milliseconds":1700000000000,"component":"comp1","name":"m1","value":1.5,"labels":{"rack":"r1"}}`, lines[0])
+
+	for _, query := range []string{"format=parquet", "since=invalid", "components=unknown"} {
+		_, c, w = setupTestRouter()
+		c.Request = httptest.NewRequest("GET", "/v1/metrics/export?"+query, nil)
+		handler.exportMetrics(c)
+		assert.NotEqual(t, http.StatusOK, w.Code, query)
+	}
+}
+
+func TestParseMetricsExportSince(t *testing.T) {
+	now := time.Unix(1700000000, 0).UTC()
+
+	since, err := parseMetricsExportSince("1h", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-time.Hour), since)
+
+	since, err = parseMetricsExportSince("1699996400", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-time.Hour), since)
+
+	since, err = parseMetricsExportSince("2023-11-14T22:13:20Z", now)
+	require.NoError(t, err)
+	assert.True(t, now.Equal(since))
+
+	_, err = parseMetricsExportSince("yesterday", now)
+	assert.Error(t, err)
+}
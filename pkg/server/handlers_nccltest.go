@@ -0,0 +1,106 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"sigs.k8s.io/yaml"
+
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/httputil"
+	pkgnccltest "github.com/leptonai/gpud/pkg/nccl-test"
+)
+
+func (g *globalHandler) registerNCCLTestRoutes(r gin.IRoutes) {
+	r.GET(URLPathNCCLTest, g.getNCCLTest)
+	r.POST(URLPathNCCLTest, g.startNCCLTest)
+}
+
+// URLPathNCCLTest is for starting the inter-node NCCL bandwidth test and getting its result
+const URLPathNCCLTest = "/nccl-test"
+
+// getNCCLTest godoc
+// @Summary Get inter-node NCCL bandwidth test result
+// @Description Returns the result of the last (or the running) inter-node NCCL bandwidth test led by this node, with the bus bandwidth per link, and the suspect nodes and links localized from the degraded links.
+// @ID getNCCLTest
+// @Tags nccl-test
+// @Produce json
+// @Header 200 {string} Content-Type "application/json or application/yaml"
+// @Param Accept header string false "Content type preference" Enums(application/json,application/yaml)
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {object} pkgnccltest.Result "NCCL bandwidth test result"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid content type"
+// @Failure 404 {object} map[string]interface{} "No NCCL bandwidth test started"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/nccl-test [get]
+func (g *globalHandler) getNCCLTest(c *gin.Context) {
+	if g.ncclTester == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "nccl test not supported"})
+		return
+	}
+	result := g.ncclTester.Last()
+	if result == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "no nccl test started"})
+		return
+	}
+
+	switch c.GetHeader(httputil.RequestHeaderContentType) {
+	case httputil.RequestHeaderYAML:
+		yb, err := yaml.Marshal(result)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to marshal nccl test result " + err.Error()})
+			return
+		}
+		c.String(http.StatusOK, string(yb))
+
+	case httputil.RequestHeaderJSON, "":
+		if c.GetHeader(httputil.RequestHeaderJSONIndent) == "true" {
+			c.IndentedJSON(http.StatusOK, result)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid content type"})
+	}
+}
+
+// startNCCLTest godoc
+// @Summary Start inter-node NCCL bandwidth test
+// @Description Starts the NCCL bandwidth tests between the peer nodes (pairwise or in a ring) with this node as the leader, one link at a time in the background. The peer mesh peers are tested if the request has no peers, and the request with any peer not in the peer mesh is rejected. Poll the result with the GET method.
+// @ID startNCCLTest
+// @Tags nccl-test
+// @Accept json
+// @Produce json
+// @Param request body pkgnccltest.Request true "NCCL bandwidth test request"
+// @Success 202 {object} pkgnccltest.Result "NCCL bandwidth test started"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid request"
+// @Failure 404 {object} map[string]interface{} "NCCL bandwidth test not supported"
+// @Failure 409 {object} map[string]interface{} "NCCL bandwidth test already running"
+// @Router /v1/nccl-test [post]
+func (g *globalHandler) startNCCLTest(c *gin.Context) {
+	if g.ncclTester == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "nccl test not supported"})
+		return
+	}
+
+	var req pkgnccltest.Request
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": fmt.Sprintf("failed to decode request body: %v", err)})
+			return
+		}
+	}
+	if err := g.ncclTester.Start(req); err != nil {
+		if errors.Is(err, pkgnccltest.ErrAlreadyRunning) {
+			c.JSON(http.StatusConflict, gin.H{"code": errdefs.ErrFailedPrecondition, "message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, g.ncclTester.Last())
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgnccltest "github.com/leptonai/gpud/pkg/nccl-test"
+	pkgpeermesh "github.com/leptonai/gpud/pkg/peer-mesh"
+)
+
+func TestStartGetNCCLTest(t *testing.T) {
+	handler, _, _ := setupTestHandler(nil)
+
+	// nccl test not supported
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/nccl-test", nil)
+	handler.getNCCLTest(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	release := make(chan struct{})
+	mesh := pkgpeermesh.Peers{
+		{ID: "a", Address: "10.0.0.1:15132"},
+		{ID: "b", Address: "10.0.0.2:15132"},
+		{ID: "c", Address: "10.0.0.3:15132"},
+	}
+	handler.ncclTester = pkgnccltest.NewOrchestrator(ctx, func() pkgpeermesh.Peers { return mesh }, func(ctx context.Context, link pkgnccltest.Link, req pkgnccltest.Request) (float64, error) {
+		<-release
+		return 200, nil
+	})
+
+	// no nccl test started
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/nccl-test", nil)
+	handler.getNCCLTest(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// too few peers
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("POST", "/v1/nccl-test", bytes.NewBufferString(`{"peers":[{"id":"a","address":"10.0.0.1:15132"}]}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handler.startNCCLTest(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// peer not in the peer mesh
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("POST", "/v1/nccl-test", bytes.NewBufferString(`{"peers":[{"id":"a","address":"10.0.0.1:15132"},{"id":"x","address":"10.9.9.9:15132"}]}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handler.startNCCLTest(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	body := `{"mode":"pairwise","peers":[{"id":"a","address":"10.0.0.1:15132"},{"id":"b","address":"10.0.0.2:15132"},{"id":"c","address":"10.0.0.3:15132"}]}`
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("POST", "/v1/nccl-test", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handler.startNCCLTest(c)
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("POST", "/v1/nccl-test", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handler.startNCCLTest(c)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	close(release)
+	require.Eventually(t, func() bool { return handler.ncclTester.Last().FinishedAt != nil }, 10*time.Second, 10*time.Millisecond)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/nccl-test", nil)
+	handler.getNCCLTest(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var result pkgnccltest.Result
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, pkgnccltest.ModePairwise, result.Request.Mode)
+	assert.Len(t, result.Links, 3)
+	assert.Empty(t, result.SuspectNodes)
+}
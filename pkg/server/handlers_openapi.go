@@ -0,0 +1,43 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swaggo/swag/v2"
+
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/openapi"
+)
+
+// URLPathOpenAPI is for getting the OpenAPI 3 spec of the gpud API.
+const URLPathOpenAPI = "/openapi.json"
+
+func registerOpenAPIRoutes(r gin.IRoutes) {
+	r.GET(URLPathOpenAPI, getOpenAPI)
+}
+
+// getOpenAPI godoc
+// @Summary Get the OpenAPI spec
+// @Description Returns the OpenAPI 3 spec of the gpud API, converted from the generated Swagger 2.0 docs, to generate the clients in other languages
+// @ID getOpenAPI
+// @Tags docs
+// @Produce json
+// @Success 200 {object} map[string]interface{} "OpenAPI 3 spec"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/openapi.json [get]
+func getOpenAPI(c *gin.Context) {
+	doc, err := swag.ReadDoc()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": errdefs.ErrUnknown, "message": "failed to read swagger docs: " + err.Error()})
+		return
+	}
+
+	b, err := openapi.Convert([]byte(doc))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": errdefs.ErrUnknown, "message": "failed to convert swagger docs: " + err.Error()})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json", b)
+}
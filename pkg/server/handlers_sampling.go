@@ -0,0 +1,138 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"sigs.k8s.io/yaml"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/httputil"
+)
+
+func (g *globalHandler) registerGPUSamplingRoutes(r gin.IRoutes) {
+	r.GET(URLPathGPUSampling, g.getGPUSampling)
+	r.POST(URLPathGPUSampling, g.startGPUSampling)
+	r.DELETE(URLPathGPUSampling, g.stopGPUSampling)
+}
+
+// URLPathGPUSampling is for the temporary high-frequency GPU sampling
+const URLPathGPUSampling = "/gpu/sampling"
+
+// startGPUSampling godoc
+// @Summary Start high-frequency GPU sampling
+// @Description Starts sampling the GPU utilization, power, and clocks at the high frequency (100ms to 1s) into a ring buffer, for the specified duration (defaults to 5 minutes, up to 30 minutes). The sampling stops automatically once the duration elapses. Starting a new session replaces the current session and discards its samples.
+// @ID startGPUSampling
+// @Tags gpu
+// @Accept json
+// @Produce json
+// @Param request body apiv1.GPUSamplingRequest true "Sampling interval and duration"
+// @Success 200 {object} apiv1.GPUSamplingStatus "Sampling started"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid request body, interval, or duration"
+// @Failure 404 {object} map[string]interface{} "High-frequency sampling not available (e.g., no NVML)"
+// @Router /v1/gpu/sampling [post]
+func (g *globalHandler) startGPUSampling(c *gin.Context) {
+	if g.gpuSampler == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "high-frequency sampling not available"})
+		return
+	}
+
+	request := new(apiv1.GPUSamplingRequest)
+	if err := json.NewDecoder(c.Request.Body).Decode(request); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to decode request body: " + err.Error()})
+		return
+	}
+	if err := g.gpuSampler.Start(request.Interval.Duration, request.Duration.Duration); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to start sampling: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, g.gpuSampler.Status(time.Now()))
+}
+
+// stopGPUSampling godoc
+// @Summary Stop high-frequency GPU sampling
+// @Description Stops the current high-frequency sampling session, if any. The collected samples are kept until the next session starts.
+// @ID stopGPUSampling
+// @Tags gpu
+// @Produce json
+// @Success 200 {object} map[string]string "Sampling stopped"
+// @Failure 404 {object} map[string]interface{} "High-frequency sampling not available (e.g., no NVML)"
+// @Router /v1/gpu/sampling [delete]
+func (g *globalHandler) stopGPUSampling(c *gin.Context) {
+	if g.gpuSampler == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "high-frequency sampling not available"})
+		return
+	}
+
+	g.gpuSampler.Stop()
+	c.JSON(http.StatusOK, gin.H{"message": "sampling stopped"})
+}
+
+// getGPUSampling godoc
+// @Summary Get high-frequency GPU samples
+// @Description Returns the status of the high-frequency sampling with the samples in the ring buffer, in the order of time.
+// @ID getGPUSampling
+// @Tags gpu
+// @Produce json
+// @Header 200 {string} Content-Type "application/json or application/yaml"
+// @Param Accept header string false "Content type preference" Enums(application/json,application/yaml)
+// @Param since query string false "Only return the samples after this time, in unix milliseconds or RFC3339 (defaults to all samples)"
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {object} apiv1.GPUSamplingStatus "Sampling status and samples"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid content type or time parsing error"
+// @Failure 404 {object} map[string]interface{} "High-frequency sampling not available (e.g., no NVML)"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/gpu/sampling [get]
+func (g *globalHandler) getGPUSampling(c *gin.Context) {
+	if g.gpuSampler == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "high-frequency sampling not available"})
+		return
+	}
+
+	var since time.Time
+	if raw := c.Query("since"); raw != "" {
+		t, err := parseSamplingTime(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to parse since: " + err.Error()})
+			return
+		}
+		since = t
+	}
+
+	status := g.gpuSampler.Status(since)
+
+	switch c.GetHeader(httputil.RequestHeaderContentType) {
+	case httputil.RequestHeaderYAML:
+		yb, err := yaml.Marshal(status)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to marshal samples " + err.Error()})
+			return
+		}
+		c.String(http.StatusOK, string(yb))
+
+	case httputil.RequestHeaderJSON, "":
+		if c.GetHeader(httputil.RequestHeaderJSONIndent) == "true" {
+			c.IndentedJSON(http.StatusOK, status)
+			return
+		}
+		c.JSON(http.StatusOK, status)
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid content type"})
+	}
+}
+
+// parseSamplingTime parses the time in unix milliseconds or RFC3339 (with the sub-second precision).
+func parseSamplingTime(s string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.UnixMilli(ms).UTC(), nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
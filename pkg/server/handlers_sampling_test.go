@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgsampling "github.com/leptonai/gpud/pkg/sampling"
+)
+
+func TestGPUSamplingNotAvailable(t *testing.T) {
+	handler, _, _ := setupTestHandler(nil)
+
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/gpu/sampling", nil)
+	handler.getGPUSampling(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("POST", "/v1/gpu/sampling", strings.NewReader(`{"interval":"100ms"}`))
+	handler.startGPUSampling(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGPUSampling(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	handler, _, _ := setupTestHandler(nil)
+	handler.gpuSampler = pkgsampling.New(ctx, func(ctx context.Context) ([]apiv1.GPUSample, error) {
+		return []apiv1.GPUSample{{Time: metav1.NewMicroTime(time.Now().UTC()), UUID: "GPU-0", PowerUsageMilliWatts: 300000}}, nil
+	}, 100)
+
+	// invalid interval
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("POST", "/v1/gpu/sampling", strings.NewReader(`{"interval":"10ms"}`))
+	handler.startGPUSampling(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("POST", "/v1/gpu/sampling", strings.NewReader(`{"interval":"100ms","duration":"1m"}`))
+	handler.startGPUSampling(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var st apiv1.GPUSamplingStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &st))
+	assert.True(t, st.Active)
+	assert.Equal(t, 100*time.Millisecond, st.Interval.Duration)
+
+	require.Eventually(t, func() bool {
+		return len(handler.gpuSampler.Status(time.Time{}).Samples) >= 2
+	}, 5*time.Second, 50*time.Millisecond)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("DELETE", "/v1/gpu/sampling", nil)
+	handler.stopGPUSampling(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/gpu/sampling", nil)
+	handler.getGPUSampling(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	st = apiv1.GPUSamplingStatus{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &st))
+	assert.False(t, st.Active)
+	require.GreaterOrEqual(t, len(st.Samples), 2)
+	assert.Equal(t, uint32(300000), st.Samples[0].PowerUsageMilliWatts)
+
+	// samples after the last sample only
+	last := st.Samples[len(st.Samples)-1].Time.UTC().Format(time.RFC3339Nano)
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/gpu/sampling?since="+last, nil)
+	handler.getGPUSampling(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	st = apiv1.GPUSamplingStatus{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &st))
+	assert.Empty(t, st.Samples)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/gpu/sampling?since=invalid", nil)
+	handler.getGPUSampling(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/errdefs"
+	pkgsimulate "github.com/leptonai/gpud/pkg/simulate"
+)
+
+func (g *globalHandler) registerSimulateRoutes(r gin.IRoutes) {
+	r.POST(URLPathSimulate, g.simulate)
+}
+
+// URLPathSimulate is for injecting the synthetic events and health states
+const URLPathSimulate = "/simulate"
+
+// simulate godoc
+// @Summary Inject synthetic events and health states
+// @Description Injects the synthetic event and/or health state into a component (e.g., fake Xid 63, fake IB port down) through the normal pipeline (event store, notifications, control plane), for testing the alert pipelines end-to-end. The synthetic events and health states are marked with the "[SYNTHETIC] " prefix and the "synthetic" extra info.
+// @ID simulate
+// @Tags simulate
+// @Accept json
+// @Produce json
+// @Param request body apiv1.SimulationRequest true "Simulation request"
+// @Success 200 {object} apiv1.SimulationResponse "Injected synthetic event and health state"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid request body, unknown scenario, or nothing to inject"
+// @Failure 404 {object} map[string]interface{} "Simulator not set up or component not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/simulate [post]
+func (g *globalHandler) simulate(c *gin.Context) {
+	if g.simulator == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "simulator not set up"})
+		return
+	}
+
+	var req apiv1.SimulationRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to decode request body: " + err.Error()})
+		return
+	}
+
+	resp, err := g.simulator.Simulate(c, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, pkgsimulate.ErrComponentNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": err.Error()})
+		case errors.Is(err, pkgsimulate.ErrUnknownScenario),
+			errors.Is(err, pkgsimulate.ErrComponentRequired),
+			errors.Is(err, pkgsimulate.ErrNothingToInject):
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"code": errdefs.ErrUnknown, "message": "failed to simulate: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
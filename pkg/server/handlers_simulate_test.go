@@ -0,0 +1,55 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	pkgsimulate "github.com/leptonai/gpud/pkg/simulate"
+)
+
+func TestSimulate(t *testing.T) {
+	handler, registry, _ := setupTestHandler([]components.Component{
+		&mockComponent{name: "test", healthStates: apiv1.HealthStates{{Health: apiv1.HealthStateTypeHealthy}}},
+	})
+
+	// not set up
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("POST", "/v1/simulate", strings.NewReader(`{"component":"test"}`))
+	handler.simulate(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	handler.simulator = pkgsimulate.New(registry, nil)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("POST", "/v1/simulate", strings.NewReader(`{"scenario":"unknown"}`))
+	handler.simulate(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("POST", "/v1/simulate", strings.NewReader(`{"component":"unknown","health_state":{"health":"Unhealthy"}}`))
+	handler.simulate(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("POST", "/v1/simulate", strings.NewReader(`{"component":"test","health_state":{"health":"Unhealthy","reason":"fake"},"duration":"1m"}`))
+	handler.simulate(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp apiv1.SimulationResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotNil(t, resp.HealthState)
+	assert.Equal(t, "[SYNTHETIC] fake", resp.HealthState.Reason)
+	assert.NotNil(t, resp.ExpiresAt)
+
+	states := registry.Get("test").LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, states[0].Health)
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"sigs.k8s.io/yaml"
+
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/httputil"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+)
+
+func (g *globalHandler) registerGPUSnapshotRoutes(r gin.IRoutes) {
+	r.GET(URLPathGPUSnapshot, g.getGPUSnapshot)
+}
+
+// URLPathGPUSnapshot is for getting the comprehensive snapshot of each GPU
+const URLPathGPUSnapshot = "/gpu/snapshot"
+
+// getGPUSnapshot godoc
+// @Summary Get GPU snapshot
+// @Description Returns one comprehensive document per GPU (utilization, memory, temperature, power, clocks, processes, ECC, clock event reasons, fans), equivalent in coverage to "nvidia-smi -q" but structured. The snapshots are cached for a few seconds.
+// @ID getGPUSnapshot
+// @Tags gpu
+// @Produce json
+// @Header 200 {string} Content-Type "application/json or application/yaml"
+// @Param Accept header string false "Content type preference" Enums(application/json,application/yaml)
+// @Param uuid query string false "GPU UUID to return the snapshot of (if empty, returns all GPUs)"
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {array} nvidianvml.Snapshot "GPU snapshots sorted by the minor ID"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid content type"
+// @Failure 404 {object} map[string]interface{} "GPU not found, or NVML not available"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/gpu/snapshot [get]
+func (g *globalHandler) getGPUSnapshot(c *gin.Context) {
+	if g.gpuSnapshots == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "gpu snapshot not available"})
+		return
+	}
+
+	snapshots := g.gpuSnapshots.Get()
+	if uuid := c.Query("uuid"); uuid != "" {
+		var found []nvidianvml.Snapshot
+		for _, snap := range snapshots {
+			if snap.UUID == uuid {
+				found = append(found, snap)
+			}
+		}
+		if len(found) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "gpu not found: " + uuid})
+			return
+		}
+		snapshots = found
+	}
+
+	switch c.GetHeader(httputil.RequestHeaderContentType) {
+	case httputil.RequestHeaderYAML:
+		yb, err := yaml.Marshal(snapshots)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to marshal gpu snapshots " + err.Error()})
+			return
+		}
+		c.String(http.StatusOK, string(yb))
+
+	case httputil.RequestHeaderJSON, "":
+		if c.GetHeader(httputil.RequestHeaderJSONIndent) == "true" {
+			c.IndentedJSON(http.StatusOK, snapshots)
+			return
+		}
+		c.JSON(http.StatusOK, snapshots)
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid content type"})
+	}
+}
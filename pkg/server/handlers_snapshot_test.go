@@ -0,0 +1,44 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+)
+
+func TestGetGPUSnapshot(t *testing.T) {
+	handler, _, _ := setupTestHandler(nil)
+
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/gpu/snapshot", nil)
+	handler.getGPUSnapshot(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	handler.gpuSnapshots = nvidianvml.NewSnapshotCache(nvidianvml.NewNoOp(), 0)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/gpu/snapshot", nil)
+	handler.getGPUSnapshot(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var snapshots []nvidianvml.Snapshot
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &snapshots))
+	assert.Empty(t, snapshots)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/gpu/snapshot?uuid=GPU-unknown", nil)
+	handler.getGPUSnapshot(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/gpu/snapshot", nil)
+	c.Request.Header.Set("Content-Type", "application/invalid")
+	handler.getGPUSnapshot(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
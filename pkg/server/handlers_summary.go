@@ -0,0 +1,76 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"sigs.k8s.io/yaml"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/httputil"
+	pkgremediation "github.com/leptonai/gpud/pkg/remediation"
+)
+
+// URLPathSummary is for getting the node-level health summary with the prioritized action plan
+const URLPathSummary = "/summary"
+
+// getSummary godoc
+// @Summary Get the node health summary
+// @Description Returns the worst health state across the components, and a single action plan where the repair actions suggested by the components are deduplicated and ordered by the invasiveness (e.g., hardware inspection, then reboot).
+// @ID getSummary
+// @Tags components
+// @Produce json
+// @Param Accept header string false "Content type preference" Enums(application/json,application/yaml)
+// @Param components query string false "Comma-separated list of component names to summarize (if empty, summarizes all components)"
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {object} apiv1.NodeSummary "Node health summary"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid content type or component parsing error"
+// @Failure 404 {object} map[string]interface{} "Component not found"
+// @Router /v1/summary [get]
+func (g *globalHandler) getSummary(c *gin.Context) {
+	componentNames, err := g.getReqComponents(c)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "component not found: " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to parse components: " + err.Error()})
+		return
+	}
+
+	var states apiv1.GPUdComponentHealthStates
+	for _, componentName := range componentNames {
+		comp := g.componentsRegistry.Get(componentName)
+		if comp == nil || !comp.IsSupported() {
+			continue
+		}
+		states = append(states, apiv1.ComponentHealthStates{
+			Component: componentName,
+			States:    comp.LastHealthStates(),
+		})
+	}
+	summary := pkgremediation.Summarize(time.Now(), states)
+
+	switch c.GetHeader(httputil.RequestHeaderContentType) {
+	case httputil.RequestHeaderYAML:
+		yb, err := yaml.Marshal(summary)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to marshal summary " + err.Error()})
+			return
+		}
+		c.String(http.StatusOK, string(yb))
+
+	case httputil.RequestHeaderJSON, "":
+		if c.GetHeader(httputil.RequestHeaderJSONIndent) == "true" {
+			c.IndentedJSON(http.StatusOK, summary)
+			return
+		}
+		c.JSON(http.StatusOK, summary)
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid content type"})
+	}
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/httputil"
+)
+
+func TestGetSummary(t *testing.T) {
+	comp1 := &mockComponent{
+		name:        "comp1",
+		isSupported: true,
+		healthStates: apiv1.HealthStates{{
+			Health:           apiv1.HealthStateTypeUnhealthy,
+			SuggestedActions: &apiv1.SuggestedActions{RepairActions: []apiv1.RepairActionType{apiv1.RepairActionTypeRebootSystem}},
+		}},
+	}
+	comp2 := &mockComponent{
+		name:        "comp2",
+		isSupported: true,
+		healthStates: apiv1.HealthStates{{
+			Health:           apiv1.HealthStateTypeUnhealthy,
+			SuggestedActions: &apiv1.SuggestedActions{RepairActions: []apiv1.RepairActionType{apiv1.RepairActionTypeHardwareInspection, apiv1.RepairActionTypeRebootSystem}},
+		}},
+	}
+	unsupported := &mockComponent{
+		name:         "unsupported",
+		healthStates: apiv1.HealthStates{{Health: apiv1.HealthStateTypeUnhealthy}},
+	}
+
+	handler, _, _ := setupTestHandler([]components.Component{comp1, comp2, unsupported})
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/summary", nil)
+	handler.getSummary(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var summary apiv1.NodeSummary
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &summary))
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, summary.Health)
+	assert.Equal(t, []string{"comp1", "comp2"}, summary.UnhealthyComponents)
+	assert.Equal(t, apiv1.RepairActionTypeHardwareInspection, summary.ActionPlan.Action)
+	require.Len(t, summary.ActionPlan.Steps, 2)
+	assert.Equal(t, []string{"comp2"}, summary.ActionPlan.Steps[0].Components)
+	assert.Equal(t, apiv1.RepairActionTypeRebootSystem, summary.ActionPlan.Steps[1].Action)
+	assert.Equal(t, []string{"comp1", "comp2"}, summary.ActionPlan.Steps[1].Components)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/summary?components=nonexistent", nil)
+	handler.getSummary(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/summary", nil)
+	c.Request.Header.Set(httputil.RequestHeaderContentType, "application/xml")
+	handler.getSummary(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -17,7 +17,7 @@ func setupTestHandler(comps []components.Component) (*globalHandler, *mockRegist
 	cfg := &config.Config{}
 	store := &mockMetricsStore{}
 
-	handler := newGlobalHandler(cfg, registry, store, nil, nil)
+	handler := newGlobalHandler(cfg, registry, store, nil, nil, nil)
 	return handler, registry, store
 }
 
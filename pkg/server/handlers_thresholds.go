@@ -0,0 +1,100 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"sigs.k8s.io/yaml"
+
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/httputil"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgthresholds "github.com/leptonai/gpud/pkg/thresholds"
+)
+
+func (g *globalHandler) registerThresholdRoutes(r gin.IRoutes) {
+	r.GET(URLPathThresholds, g.getThresholds)
+	r.PUT(URLPathThresholds, g.putThresholds)
+}
+
+// URLPathThresholds is for getting and updating the thresholds of all the components
+const URLPathThresholds = "/thresholds"
+
+// getThresholds godoc
+// @Summary Get component thresholds
+// @Description Returns the thresholds of all the components (e.g., infiniband ports and rate, GPU temperature, ECC errors, disk usage) as one document.
+// @ID getThresholds
+// @Tags thresholds
+// @Produce json
+// @Header 200 {string} Content-Type "application/json or application/yaml"
+// @Param Accept header string false "Content type preference" Enums(application/json,application/yaml)
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {object} pkgthresholds.Thresholds "Thresholds of all the components"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid content type"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/thresholds [get]
+func (g *globalHandler) getThresholds(c *gin.Context) {
+	g.writeThresholds(c, pkgthresholds.Get())
+}
+
+// putThresholds godoc
+// @Summary Update component thresholds
+// @Description Validates and applies the thresholds of the components, and persists them across the restarts. The components omitted in the request are left unchanged. Returns the thresholds of all the components after the update.
+// @ID putThresholds
+// @Tags thresholds
+// @Accept json
+// @Produce json
+// @Param request body pkgthresholds.Thresholds true "Thresholds to update"
+// @Success 200 {object} pkgthresholds.Thresholds "Thresholds of all the components after the update"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid request body or thresholds"
+// @Failure 500 {object} map[string]interface{} "Internal server error - failed to persist the thresholds"
+// @Router /v1/thresholds [put]
+func (g *globalHandler) putThresholds(c *gin.Context) {
+	var req pkgthresholds.Thresholds
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to decode request body: " + err.Error()})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		if errors.Is(err, pkgthresholds.ErrInvalidThresholds) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"code": errdefs.ErrUnknown, "message": err.Error()})
+		return
+	}
+
+	// persisted before applied, so that the thresholds are not updated
+	// only until the restart
+	if err := pkgthresholds.Update(c, g.dbRW, req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": errdefs.ErrUnknown, "message": "failed to persist thresholds: " + err.Error()})
+		return
+	}
+	log.Logger.Infow("updated thresholds")
+
+	g.writeThresholds(c, pkgthresholds.Get())
+}
+
+func (g *globalHandler) writeThresholds(c *gin.Context, thresholds pkgthresholds.Thresholds) {
+	switch c.GetHeader(httputil.RequestHeaderContentType) {
+	case httputil.RequestHeaderYAML:
+		yb, err := yaml.Marshal(thresholds)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to marshal thresholds " + err.Error()})
+			return
+		}
+		c.String(http.StatusOK, string(yb))
+
+	case httputil.RequestHeaderJSON, "":
+		if c.GetHeader(httputil.RequestHeaderJSONIndent) == "true" {
+			c.IndentedJSON(http.StatusOK, thresholds)
+			return
+		}
+		c.JSON(http.StatusOK, thresholds)
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid content type"})
+	}
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	"github.com/leptonai/gpud/pkg/sqlite"
+	pkgthresholds "github.com/leptonai/gpud/pkg/thresholds"
+)
+
+func TestGetPutThresholds(t *testing.T) {
+	orig := pkgthresholds.Get()
+	t.Cleanup(orig.Apply)
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+	require.NoError(t, pkgmetadata.CreateTableMetadata(context.Background(), dbRW))
+
+	handler, _, _ := setupTestHandler(nil)
+	handler.dbRW = dbRW
+
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/thresholds", nil)
+	handler.getThresholds(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var got pkgthresholds.Thresholds
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, orig, got)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("PUT", "/v1/thresholds", strings.NewReader(`{"disk":{"max_used_percent":90},"temperature":{"gpu_core_max_celsius":85}}`))
+	handler.putThresholds(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, 90.0, got.Disk.MaxUsedPercent)
+	assert.Equal(t, uint32(85), got.Temperature.GPUCoreMaxCelsius)
+	// the omitted components are left unchanged
+	assert.Equal(t, orig.ECC, got.ECC)
+	assert.Equal(t, orig.Infiniband, got.Infiniband)
+
+	// only the updated thresholds are persisted
+	persisted, err := pkgthresholds.Read(context.Background(), dbRO)
+	require.NoError(t, err)
+	require.NotNil(t, persisted)
+	require.NotNil(t, persisted.Disk)
+	assert.Equal(t, 90.0, persisted.Disk.MaxUsedPercent)
+	require.NotNil(t, persisted.Temperature)
+	assert.Equal(t, uint32(85), persisted.Temperature.GPUCoreMaxCelsius)
+	assert.Nil(t, persisted.ECC)
+	assert.Nil(t, persisted.Infiniband)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("PUT", "/v1/thresholds", strings.NewReader(`{"disk":{"max_used_percent":80}}`))
+	handler.putThresholds(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	persisted, err = pkgthresholds.Read(context.Background(), dbRO)
+	require.NoError(t, err)
+	require.NotNil(t, persisted)
+	assert.Equal(t, 80.0, persisted.Disk.MaxUsedPercent)
+	require.NotNil(t, persisted.Temperature)
+	assert.Equal(t, uint32(85), persisted.Temperature.GPUCoreMaxCelsius)
+	assert.Nil(t, persisted.ECC)
+
+	// invalid thresholds are rejected without the update
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("PUT", "/v1/thresholds", strings.NewReader(`{"disk":{"max_used_percent":120}}`))
+	handler.putThresholds(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, 80.0, pkgthresholds.Get().Disk.MaxUsedPercent)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("PUT", "/v1/thresholds", strings.NewReader(`{`))
+	handler.putThresholds(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,184 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"sigs.k8s.io/yaml"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/httputil"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	pkgtimeline "github.com/leptonai/gpud/pkg/timeline"
+)
+
+func (g *globalHandler) registerTimelineRoutes(r gin.IRoutes) {
+	r.GET(URLPathTimeline, g.getTimeline)
+}
+
+// URLPathTimeline is for getting the cross-component timeline
+const URLPathTimeline = "/timeline"
+
+// getTimeline godoc
+// @Summary Get cross-component timeline
+// @Description Returns the events, the health transitions, and the key metric breaches from the specified components (or all components) in a single chronologically ordered view, to reconstruct an incident window. If there are more entries than the limit, only the latest entries are returned.
+// @ID getTimeline
+// @Tags components
+// @Accept json
+// @Produce json
+// @Header 200 {string} Content-Type "application/json or application/yaml"
+// @Param Accept header string false "Content type preference" Enums(application/json,application/yaml)
+// @Param components query string false "Comma-separated list of component names to query (if empty, queries all components)"
+// @Param from query string false "Start of the time range, in unix seconds or RFC3339 (defaults to 30 minutes before the end)"
+// @Param to query string false "End of the time range, in unix seconds or RFC3339 (defaults to current time)"
+// @Param limit query integer false "Maximum number of entries to return (defaults to 1000, up to 10000)"
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {object} apiv1.Timeline "Timeline entries within the specified time range"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid content type, component parsing error, time parsing error, time range exceeding the maximum, or invalid limit"
+// @Failure 404 {object} map[string]interface{} "Component not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/timeline [get]
+func (g *globalHandler) getTimeline(c *gin.Context) {
+	components, err := g.getReqComponents(c)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "component not found: " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to parse components: " + err.Error()})
+		return
+	}
+
+	from, to, limit, err := parseTimelineQuery(c, time.Now().UTC())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid timeline query: " + err.Error()})
+		return
+	}
+
+	selected := make(map[string]struct{}, len(components))
+	entries := make([][]apiv1.TimelineEntry, 0, len(components)+2)
+	for _, componentName := range components {
+		selected[componentName] = struct{}{}
+
+		comp := g.componentsRegistry.Get(componentName)
+		if comp == nil || !comp.IsSupported() {
+			continue
+		}
+		evs, err := comp.Events(eventstore.WithReadDBContext(c, g.dbAPIRO), from)
+		if err != nil {
+			log.Logger.Errorw("failed to invoke component events",
+				"operation", "GetTimeline",
+				"component", componentName,
+				"error", err,
+			)
+			continue
+		}
+		entries = append(entries, pkgtimeline.EventEntries(componentName, g.labels.ApplyToEvents(evs)))
+	}
+
+	transitions, err := g.healthTransitions.Transitions(c, from)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to read health transitions: " + err.Error()})
+		return
+	}
+	filtered := make([]apiv1.TimelineEntry, 0, len(transitions))
+	for _, e := range transitions {
+		if _, ok := selected[e.Component]; ok {
+			filtered = append(filtered, e)
+		}
+	}
+	entries = append(entries, filtered)
+
+	if g.metricsStore != nil {
+		ms, err := g.metricsStore.Read(c,
+			pkgmetrics.WithSince(from),
+			pkgmetrics.WithBefore(to.Add(time.Millisecond)),
+			pkgmetrics.WithComponents(components...),
+			pkgmetrics.WithMetricNames(pkgtimeline.MetricNames(pkgtimeline.DefaultBreachRules)...),
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to read metrics: " + err.Error()})
+			return
+		}
+		entries = append(entries, pkgtimeline.FindBreaches(ms, pkgtimeline.DefaultBreachRules))
+	}
+
+	timeline := pkgtimeline.Merge(from, to, limit, entries...)
+
+	switch c.GetHeader(httputil.RequestHeaderContentType) {
+	case httputil.RequestHeaderYAML:
+		yb, err := yaml.Marshal(timeline)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to marshal timeline " + err.Error()})
+			return
+		}
+		c.String(http.StatusOK, string(yb))
+
+	case httputil.RequestHeaderJSON, "":
+		if c.GetHeader(httputil.RequestHeaderJSONIndent) == "true" {
+			c.IndentedJSON(http.StatusOK, timeline)
+			return
+		}
+		c.JSON(http.StatusOK, timeline)
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid content type"})
+	}
+}
+
+// parseTimelineQuery parses the "from", "to", and "limit" query parameters,
+// and validates the time range.
+func parseTimelineQuery(c *gin.Context, now time.Time) (time.Time, time.Time, int, error) {
+	to := now
+	if raw := c.Query("to"); raw != "" {
+		t, err := parseTimelineTime(raw)
+		if err != nil {
+			return time.Time{}, time.Time{}, 0, fmt.Errorf("failed to parse to: %w", err)
+		}
+		to = t
+	}
+	from := to.Add(-DefaultQuerySince)
+	if raw := c.Query("from"); raw != "" {
+		t, err := parseTimelineTime(raw)
+		if err != nil {
+			return time.Time{}, time.Time{}, 0, fmt.Errorf("failed to parse from: %w", err)
+		}
+		from = t
+	}
+
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, 0, fmt.Errorf("to %s is before from %s", to, from)
+	}
+	if to.Sub(from) > MaxEventsQueryRange {
+		return time.Time{}, time.Time{}, 0, fmt.Errorf("time range %s exceeds the maximum %s", to.Sub(from), MaxEventsQueryRange)
+	}
+
+	limit := DefaultEventsQueryLimit
+	if raw := c.Query("limit"); raw != "" {
+		l, err := strconv.Atoi(raw)
+		if err != nil {
+			return time.Time{}, time.Time{}, 0, fmt.Errorf("failed to parse limit: %w", err)
+		}
+		if l <= 0 || l > MaxEventsQueryLimit {
+			return time.Time{}, time.Time{}, 0, fmt.Errorf("limit must be between 1 and %d, got %d", MaxEventsQueryLimit, l)
+		}
+		limit = l
+	}
+
+	return from, to, limit, nil
+}
+
+// parseTimelineTime parses the time in unix seconds or RFC3339.
+func parseTimelineTime(s string) (time.Time, error) {
+	if unix, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(unix, 0).UTC(), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
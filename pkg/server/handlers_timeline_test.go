@@ -0,0 +1,118 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/metrics"
+)
+
+func TestGetTimeline(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	comp1 := &mockComponent{
+		name:        "comp1",
+		isSupported: true,
+		events: apiv1.Events{
+			{Time: metav1.NewTime(now.Add(-10 * time.Minute)), Name: "xid", Type: apiv1.EventTypeCritical},
+			{Time: metav1.NewTime(now.Add(-2 * time.Hour)), Name: "too-old", Type: apiv1.EventTypeWarning},
+		},
+	}
+	comp2 := &mockComponent{
+		name:        "comp2",
+		isSupported: true,
+		events: apiv1.Events{
+			{Time: metav1.NewTime(now.Add(-time.Minute)), Name: "reboot", Type: apiv1.EventTypeInfo},
+		},
+	}
+
+	handler, _, store := setupTestHandler([]components.Component{comp1, comp2})
+	store.metrics = []metrics.Metric{
+		{UnixMilliseconds: now.Add(-5 * time.Minute).UnixMilli(), Component: "comp1", Name: "memory_used_percent", Value: 99},
+		{UnixMilliseconds: now.Add(-4 * time.Minute).UnixMilli(), Component: "comp1", Name: "memory_used_percent", Value: 99},
+		{UnixMilliseconds: now.Add(-5 * time.Minute).UnixMilli(), Component: "comp1", Name: "unrelated", Value: 99},
+	}
+
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/timeline", nil)
+	handler.getTimeline(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var tl apiv1.Timeline
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tl))
+	require.Len(t, tl.Entries, 3)
+	assert.False(t, tl.Truncated)
+
+	assert.Equal(t, "xid", tl.Entries[0].Name)
+	assert.Equal(t, apiv1.TimelineEntryKindEvent, tl.Entries[0].Kind)
+	assert.Equal(t, "comp1", tl.Entries[0].Component)
+
+	assert.Equal(t, "memory_used_percent", tl.Entries[1].Name)
+	assert.Equal(t, apiv1.TimelineEntryKindMetricBreach, tl.Entries[1].Kind)
+	assert.Equal(t, float64(99), tl.Entries[1].Value)
+
+	assert.Equal(t, "reboot", tl.Entries[2].Name)
+	assert.Equal(t, "comp2", tl.Entries[2].Component)
+}
+
+func TestGetTimelineQuery(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	comp := &mockComponent{
+		name:        "comp1",
+		isSupported: true,
+		events: apiv1.Events{
+			{Time: metav1.NewTime(now.Add(-3 * time.Hour)), Name: "first"},
+			{Time: metav1.NewTime(now.Add(-2 * time.Hour)), Name: "second"},
+			{Time: metav1.NewTime(now.Add(-time.Minute)), Name: "after"},
+		},
+	}
+	handler, _, _ := setupTestHandler([]components.Component{comp})
+
+	from := now.Add(-4 * time.Hour).Unix()
+	to := now.Add(-time.Hour).Format(time.RFC3339)
+
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("GET", fmt.Sprintf("/v1/timeline?components=comp1&from=%d&to=%s&limit=1", from, to), nil)
+	handler.getTimeline(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var tl apiv1.Timeline
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tl))
+	require.Len(t, tl.Entries, 1)
+	assert.Equal(t, "second", tl.Entries[0].Name)
+	assert.True(t, tl.Truncated)
+}
+
+func TestGetTimelineErrors(t *testing.T) {
+	handler, _, _ := setupTestHandler([]components.Component{&mockComponent{name: "comp1", isSupported: true}})
+
+	tests := []struct {
+		url  string
+		code int
+	}{
+		{url: "/v1/timeline?components=missing", code: http.StatusNotFound},
+		{url: "/v1/timeline?from=invalid", code: http.StatusBadRequest},
+		{url: "/v1/timeline?from=200&to=100", code: http.StatusBadRequest},
+		{url: "/v1/timeline?from=0&to=100000000", code: http.StatusBadRequest},
+		{url: "/v1/timeline?limit=0", code: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			_, c, w := setupTestRouter()
+			c.Request = httptest.NewRequest("GET", tt.url, nil)
+			handler.getTimeline(c)
+			assert.Equal(t, tt.code, w.Code)
+		})
+	}
+}
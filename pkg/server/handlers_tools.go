@@ -0,0 +1,56 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"sigs.k8s.io/yaml"
+
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/httputil"
+	pkgtoolpath "github.com/leptonai/gpud/pkg/toolpath"
+)
+
+func (g *globalHandler) registerToolRoutes(r gin.IRoutes) {
+	r.GET(URLPathTools, g.getTools)
+}
+
+// URLPathTools is for getting the resolved paths of the external tools
+const URLPathTools = "/tools"
+
+// getTools godoc
+// @Summary Get external tool paths
+// @Description Returns the external tools gpud runs (e.g., "ibstat", "lspci") with their candidate paths, the user overrides, and the resolved paths (or the reasons the tools are not found).
+// @ID getTools
+// @Tags tools
+// @Produce json
+// @Header 200 {string} Content-Type "application/json or application/yaml"
+// @Param Accept header string false "Content type preference" Enums(application/json,application/yaml)
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {array} pkgtoolpath.Status "External tools sorted by the name"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid content type"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/tools [get]
+func (g *globalHandler) getTools(c *gin.Context) {
+	tools := pkgtoolpath.List()
+
+	switch c.GetHeader(httputil.RequestHeaderContentType) {
+	case httputil.RequestHeaderYAML:
+		yb, err := yaml.Marshal(tools)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to marshal tools " + err.Error()})
+			return
+		}
+		c.String(http.StatusOK, string(yb))
+
+	case httputil.RequestHeaderJSON, "":
+		if c.GetHeader(httputil.RequestHeaderJSONIndent) == "true" {
+			c.IndentedJSON(http.StatusOK, tools)
+			return
+		}
+		c.JSON(http.StatusOK, tools)
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid content type"})
+	}
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgtoolpath "github.com/leptonai/gpud/pkg/toolpath"
+)
+
+func TestGetTools(t *testing.T) {
+	handler, _, _ := setupTestHandler(nil)
+
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/tools", nil)
+	handler.getTools(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var tools []pkgtoolpath.Status
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tools))
+	require.Len(t, tools, len(pkgtoolpath.DefaultTools))
+	for _, tool := range tools {
+		// either resolved or the reason it is not found
+		assert.True(t, (tool.Path == "") != (tool.Error == ""), tool.Name)
+	}
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/tools", nil)
+	c.Request.Header.Set("Content-Type", "application/invalid")
+	handler.getTools(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
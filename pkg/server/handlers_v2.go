@@ -0,0 +1,149 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"sigs.k8s.io/yaml"
+
+	apiv2 "github.com/leptonai/gpud/api/v2"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/httputil"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+func (g *globalHandler) registerV2Routes(r gin.IRoutes) {
+	r.GET(URLPathV2States, g.getHealthStatesV2)
+	r.GET(URLPathV2Schemas, g.getPayloadSchemas)
+	r.GET(URLPathV2Schemas+"/:type", g.getPayloadSchema)
+}
+
+// URLPathV2States is for getting the states of all gpud components with the typed payloads
+const URLPathV2States = "/states"
+
+// getHealthStatesV2 godoc
+// @Summary Get component health states with the typed payloads
+// @Description Returns the current health states of specified components or all components if none specified, with the component-specific data as the typed and versioned payload instead of the v1 extra info. The components without the typed payload return the states without the payload.
+// @ID getHealthStatesV2
+// @Tags components
+// @Produce json
+// @Header 200 {string} Content-Type "application/json or application/yaml"
+// @Param Accept header string false "Content type preference" Enums(application/json,application/yaml)
+// @Param components query string false "Comma-separated list of component names to query (if empty, returns all components)"
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {object} apiv2.GPUdComponentHealthStates "Component health states"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid content type or component parsing error"
+// @Failure 404 {object} map[string]interface{} "Component not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v2/states [get]
+func (g *globalHandler) getHealthStatesV2(c *gin.Context) {
+	componentNames, err := g.getReqComponents(c)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "component not found: " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to parse components: " + err.Error()})
+		return
+	}
+
+	states := make(apiv2.GPUdComponentHealthStates, 0, len(componentNames))
+	for _, componentName := range componentNames {
+		currState := apiv2.ComponentHealthStates{
+			Component: componentName,
+		}
+
+		comp := g.componentsRegistry.Get(componentName)
+		if comp == nil {
+			log.Logger.Errorw("failed to get component",
+				"operation", "GetStatesV2",
+				"component", componentName,
+				"error", errdefs.ErrNotFound,
+			)
+			states = append(states, currState)
+			continue
+		}
+		if !comp.IsSupported() {
+			log.Logger.Debugw("component not supported", "component", componentName)
+			continue
+		}
+
+		// read the states and the payload of the same check
+		lastStates, payload := components.LastHealthStatesWithPayload(comp)
+
+		v1States := g.labels.ApplyToHealthStates(versionHealthStates(comp, g.escalation.ApplyToHealthStates(componentName, lastStates), ""))
+		currState.States, err = apiv2.FromV1HealthStates(v1States, payload)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": errdefs.ErrUnknown, "message": "failed to encode payload " + err.Error()})
+			return
+		}
+
+		states = append(states, currState)
+	}
+
+	writeV2Response(c, states)
+}
+
+// URLPathV2Schemas is for getting the JSON schemas of the typed payloads
+const URLPathV2Schemas = "/schemas"
+
+// getPayloadSchemas godoc
+// @Summary Get the JSON schemas of the typed payloads
+// @Description Returns the JSON schemas of all the typed and versioned component payloads in the v2 health states.
+// @ID getPayloadSchemas
+// @Tags components
+// @Produce json
+// @Param Accept header string false "Content type preference" Enums(application/json,application/yaml)
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {array} apiv2.PayloadSchema "Payload JSON schemas"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid content type"
+// @Router /v2/schemas [get]
+func (g *globalHandler) getPayloadSchemas(c *gin.Context) {
+	writeV2Response(c, apiv2.PayloadSchemas())
+}
+
+// getPayloadSchema godoc
+// @Summary Get the JSON schema of a typed payload
+// @Description Returns the JSON schema of the typed component payload (e.g., "ib-ports", "gpu-ecc").
+// @ID getPayloadSchema
+// @Tags components
+// @Produce json
+// @Param type path string true "Payload type"
+// @Param Accept header string false "Content type preference" Enums(application/json,application/yaml)
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {object} apiv2.PayloadSchema "Payload JSON schema"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid content type"
+// @Failure 404 {object} map[string]interface{} "Payload type not found"
+// @Router /v2/schemas/{type} [get]
+func (g *globalHandler) getPayloadSchema(c *gin.Context) {
+	schema, ok := apiv2.GetPayloadSchema(c.Param("type"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "payload type not found: " + c.Param("type")})
+		return
+	}
+	writeV2Response(c, schema)
+}
+
+func writeV2Response(c *gin.Context, obj any) {
+	switch c.GetHeader(httputil.RequestHeaderContentType) {
+	case httputil.RequestHeaderYAML:
+		yb, err := yaml.Marshal(obj)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to marshal " + err.Error()})
+			return
+		}
+		c.String(http.StatusOK, string(yb))
+
+	case httputil.RequestHeaderJSON, "":
+		if c.GetHeader(httputil.RequestHeaderJSONIndent) == "true" {
+			c.IndentedJSON(http.StatusOK, obj)
+			return
+		}
+		c.JSON(http.StatusOK, obj)
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid content type"})
+	}
+}
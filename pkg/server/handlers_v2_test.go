@@ -0,0 +1,123 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	apiv2 "github.com/leptonai/gpud/api/v2"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/httputil"
+)
+
+// mockPayloadComponent is a mock component with the typed payload
+type mockPayloadComponent struct {
+	mockComponent
+	payload apiv2.Payload
+}
+
+func (m *mockPayloadComponent) LastHealthStatesWithPayload() (apiv1.HealthStates, apiv2.Payload) {
+	return m.healthStates, m.payload
+}
+
+func TestGetHealthStatesV2(t *testing.T) {
+	typed := &mockPayloadComponent{
+		mockComponent: mockComponent{
+			name:        "typed",
+			isSupported: true,
+			healthStates: apiv1.HealthStates{
+				{Health: apiv1.HealthStateTypeUnhealthy, Reason: "port down", ExtraInfo: map[string]string{"data": "{}"}},
+			},
+		},
+		payload: &apiv2.IBPortsPayload{Ports: []apiv2.IBPort{{Device: "mlx5_0", Port: 1, State: "Down"}}},
+	}
+	untyped := &mockComponent{
+		name:         "untyped",
+		isSupported:  true,
+		healthStates: apiv1.HealthStates{{Health: apiv1.HealthStateTypeHealthy, ExtraInfo: map[string]string{"data": "{}"}}},
+	}
+	unsupported := &mockComponent{name: "unsupported"}
+
+	handler, _, _ := setupTestHandler([]components.Component{typed, untyped, unsupported})
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v2/states", nil)
+	handler.getHealthStatesV2(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var states apiv2.GPUdComponentHealthStates
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &states))
+	require.Len(t, states, 2)
+
+	byName := map[string]apiv2.ComponentHealthStates{}
+	for _, s := range states {
+		byName[s.Component] = s
+	}
+
+	require.Len(t, byName["typed"].States, 1)
+	st := byName["typed"].States[0]
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, st.Health)
+	assert.Equal(t, components.VersionOf(typed), st.ComponentVersion)
+	require.NotNil(t, st.Payload)
+	assert.Equal(t, apiv2.PayloadTypeIBPorts, st.Payload.Type)
+	p := &apiv2.IBPortsPayload{}
+	require.NoError(t, st.Payload.Decode(p))
+	assert.Equal(t, typed.payload, p)
+
+	// the extra info of the component without a typed payload is carried as is
+	require.Len(t, byName["untyped"].States, 1)
+	require.NotNil(t, byName["untyped"].States[0].Payload)
+	assert.Equal(t, apiv2.PayloadTypeExtraInfo, byName["untyped"].States[0].Payload.Type)
+	extra := &apiv2.ExtraInfoPayload{}
+	require.NoError(t, byName["untyped"].States[0].Payload.Decode(extra))
+	assert.Equal(t, map[string]string{"data": "{}"}, extra.ExtraInfo)
+
+	// component not found
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v2/states?components=nonexistent", nil)
+	handler.getHealthStatesV2(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v2/states", nil)
+	c.Request.Header.Set(httputil.RequestHeaderContentType, "application/xml")
+	handler.getHealthStatesV2(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetPayloadSchemas(t *testing.T) {
+	handler, _, _ := setupTestHandler([]components.Component{})
+
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v2/schemas", nil)
+	handler.getPayloadSchemas(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var schemas []apiv2.PayloadSchema
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &schemas))
+	require.NotEmpty(t, schemas)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v2/schemas/gpu-ecc", nil)
+	c.Request.Header.Set(httputil.RequestHeaderContentType, httputil.RequestHeaderYAML)
+	c.AddParam("type", apiv2.PayloadTypeGPUECC)
+	handler.getPayloadSchema(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var schema apiv2.PayloadSchema
+	require.NoError(t, yaml.Unmarshal(w.Body.Bytes(), &schema))
+	assert.Equal(t, apiv2.PayloadTypeGPUECC, schema.Type)
+	assert.Equal(t, apiv2.PayloadVersionGPUECC, schema.Version)
+	assert.Equal(t, "object", schema.Schema["type"])
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v2/schemas/unknown", nil)
+	c.AddParam("type", "unknown")
+	handler.getPayloadSchema(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/nvidia-query/infiniband"
+)
+
+var healthStateTypes = []apiv1.HealthStateType{
+	apiv1.HealthStateTypeHealthy,
+	apiv1.HealthStateTypeDegraded,
+	apiv1.HealthStateTypeUnhealthy,
+	apiv1.HealthStateTypeInitializing,
+}
+
+var (
+	descComponentHealthState = prometheus.NewDesc(
+		"gpud_component_health_state",
+		"current health state of the component (1 for the current state, 0 for the others)",
+		[]string{"component", "name", "health"}, nil,
+	)
+	descComponentCheckDuration = prometheus.NewDesc(
+		"gpud_component_check_duration_seconds",
+		"wall-clock duration of the last component check in seconds",
+		[]string{"component"}, nil,
+	)
+	descComponentCheckAvgDuration = prometheus.NewDesc(
+		"gpud_component_check_avg_duration_seconds",
+		"average wall-clock duration of the component checks in seconds",
+		[]string{"component"}, nil,
+	)
+	descComponentChecksTotal = prometheus.NewDesc(
+		"gpud_component_checks_total",
+		"total number of the component checks",
+		[]string{"component"}, nil,
+	)
+	descInfinibandPortState = prometheus.NewDesc(
+		"gpud_infiniband_port_state",
+		"state of the infiniband port (always 1, the states are in the labels)",
+		[]string{"device", "port", "state", "physical_state"}, nil,
+	)
+	descInfinibandPortRate = prometheus.NewDesc(
+		"gpud_infiniband_port_rate_gbps",
+		"rate of the infiniband port in Gb/sec",
+		[]string{"device", "port"}, nil,
+	)
+)
+
+var _ prometheus.Collector = &prometheusExporter{}
+
+// prometheusExporter exports the component health states, the check durations,
+// and the infiniband port states on each scrape, read from the live sources
+// rather than recorded, so that they are not persisted in the metrics store.
+type prometheusExporter struct {
+	registry   components.Registry
+	checkStats *components.CheckStats
+	// readIBPortsFunc reads the states of all the infiniband ports,
+	// returns infiniband.ErrNoSysfsDevice if the host has no infiniband device
+	readIBPortsFunc func() ([]infiniband.IBPort, error)
+}
+
+func newPrometheusExporter(registry components.Registry, checkStats *components.CheckStats) *prometheusExporter {
+	return &prometheusExporter{
+		registry:   registry,
+		checkStats: checkStats,
+		readIBPortsFunc: func() ([]infiniband.IBPort, error) {
+			return infiniband.ReadSysfsPorts(infiniband.DefaultClassDir)
+		},
+	}
+}
+
+func (e *prometheusExporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descComponentHealthState
+	ch <- descComponentCheckDuration
+	ch <- descComponentCheckAvgDuration
+	ch <- descComponentChecksTotal
+	ch <- descInfinibandPortState
+	ch <- descInfinibandPortRate
+}
+
+func (e *prometheusExporter) Collect(ch chan<- prometheus.Metric) {
+	if e.registry != nil {
+		for _, c := range e.registry.All() {
+			// the states sharing the name would be the duplicate series,
+			// failing the whole scrape, so only the worst state of the name is exported
+			var names []string
+			healths := make(map[string]apiv1.HealthStateType)
+			for _, st := range c.LastHealthStates() {
+				name := st.Name
+				if name == "" {
+					name = c.Name()
+				}
+				prev, ok := healths[name]
+				if !ok {
+					names = append(names, name)
+				}
+				if !ok || healthSeverity(st.Health) > healthSeverity(prev) {
+					healths[name] = st.Health
+				}
+			}
+
+			for _, name := range names {
+				for _, h := range healthStateTypes {
+					v := 0.0
+					if healths[name] == h {
+						v = 1.0
+					}
+					ch <- prometheus.MustNewConstMetric(descComponentHealthState, prometheus.GaugeValue, v, c.Name(), name, string(h))
+				}
+			}
+		}
+	}
+
+	if e.checkStats != nil {
+		for _, l := range e.checkStats.Get() {
+			ch <- prometheus.MustNewConstMetric(descComponentCheckDuration, prometheus.GaugeValue, l.LastDuration.Seconds(), l.Component)
+			ch <- prometheus.MustNewConstMetric(descComponentCheckAvgDuration, prometheus.GaugeValue, l.AvgDuration.Seconds(), l.Component)
+			ch <- prometheus.MustNewConstMetric(descComponentChecksTotal, prometheus.CounterValue, float64(l.Checks), l.Component)
+		}
+	}
+
+	if e.readIBPortsFunc != nil {
+		ports, err := e.readIBPortsFunc()
+		if err != nil {
+			if !errors.Is(err, infiniband.ErrNoSysfsDevice) {
+				log.Logger.Warnw("failed to read infiniband port states", "error", err)
+			}
+			return
+		}
+		for _, p := range ports {
+			port := strconv.Itoa(p.Port)
+			ch <- prometheus.MustNewConstMetric(descInfinibandPortState, prometheus.GaugeValue, 1, p.Device, port, p.State, p.PhysicalState)
+			ch <- prometheus.MustNewConstMetric(descInfinibandPortRate, prometheus.GaugeValue, float64(p.Rate), p.Device, port)
+		}
+	}
+}
+
+// healthSeverity orders the health states from the healthy to the unhealthy.
+func healthSeverity(h apiv1.HealthStateType) int {
+	switch h {
+	case apiv1.HealthStateTypeUnhealthy:
+		return 3
+	case apiv1.HealthStateTypeDegraded:
+		return 2
+	case apiv1.HealthStateTypeInitializing:
+		return 1
+	default:
+		return 0
+	}
+}
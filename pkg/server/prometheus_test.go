@@ -0,0 +1,115 @@
+package server
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/nvidia-query/infiniband"
+)
+
+func TestPrometheusExporter(t *testing.T) {
+	registry := newMockRegistry()
+	registry.components["cpu"] = &mockComponent{
+		name: "cpu",
+		healthStates: apiv1.HealthStates{
+			{Name: "cpu", Health: apiv1.HealthStateTypeDegraded},
+		},
+	}
+
+	checkStats := components.NewCheckStats()
+	checkStats.Record("cpu", 2*time.Second, time.Second)
+
+	e := newPrometheusExporter(registry, checkStats)
+	e.readIBPortsFunc = func() ([]infiniband.IBPort, error) {
+		return []infiniband.IBPort{
+			{Device: "mlx5_0", Port: 1, State: "Active", PhysicalState: "LinkUp", Rate: 400},
+			{Device: "mlx5_0", Port: 2, State: "Down", PhysicalState: "Disabled", Rate: 10},
+		}, nil
+	}
+
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(e))
+
+	expected := `
+# HELP gpud_component_health_state current health state of the component (1 for the current state, 0 for the others)
+# TYPE gpud_component_health_state gauge
+gpud_component_health_state{component="cpu",health="Degraded",name="cpu"} 1
+gpud_component_health_state{component="cpu",health="Healthy",name="cpu"} 0
+gpud_component_health_state{component="cpu",health="Initializing",name="cpu"} 0
+gpud_component_health_state{component="cpu",health="Unhealthy",name="cpu"} 0
+# HELP gpud_component_check_duration_seconds wall-clock duration of the last component check in seconds
+# TYPE gpud_component_check_duration_seconds gauge
+gpud_component_check_duration_seconds{component="cpu"} 2
+# HELP gpud_component_checks_total total number of the component checks
+# TYPE gpud_component_checks_total counter
+gpud_component_checks_total{component="cpu"} 1
+# HELP gpud_infiniband_port_state state of the infiniband port (always 1, the states are in the labels)
+# TYPE gpud_infiniband_port_state gauge
+gpud_infiniband_port_state{device="mlx5_0",physical_state="LinkUp",port="1",state="Active"} 1
+gpud_infiniband_port_state{device="mlx5_0",physical_state="Disabled",port="2",state="Down"} 1
+# HELP gpud_infiniband_port_rate_gbps rate of the infiniband port in Gb/sec
+# TYPE gpud_infiniband_port_rate_gbps gauge
+gpud_infiniband_port_rate_gbps{device="mlx5_0",port="1"} 400
+gpud_infiniband_port_rate_gbps{device="mlx5_0",port="2"} 10
+`
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"gpud_component_health_state",
+		"gpud_component_check_duration_seconds",
+		"gpud_component_checks_total",
+		"gpud_infiniband_port_state",
+		"gpud_infiniband_port_rate_gbps",
+	))
+
+	// no infiniband device
+	e.readIBPortsFunc = func() ([]infiniband.IBPort, error) {
+		return nil, infiniband.ErrNoSysfsDevice
+	}
+	n, err := testutil.GatherAndCount(reg, "gpud_infiniband_port_state")
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	e.readIBPortsFunc = func() ([]infiniband.IBPort, error) {
+		return nil, errors.New("permission denied")
+	}
+	n, err = testutil.GatherAndCount(reg, "gpud_component_health_state")
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+}
+
+func TestPrometheusExporterDuplicateStateNames(t *testing.T) {
+	registry := newMockRegistry()
+	registry.components["disk"] = &mockComponent{
+		name: "disk",
+		healthStates: apiv1.HealthStates{
+			{Name: "disk", Health: apiv1.HealthStateTypeHealthy},
+			{Name: "disk", Health: apiv1.HealthStateTypeUnhealthy},
+			{Health: apiv1.HealthStateTypeDegraded},
+		},
+	}
+
+	e := newPrometheusExporter(registry, nil)
+	e.readIBPortsFunc = nil
+
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(e))
+
+	// the duplicate series would fail the whole scrape
+	expected := `
+# HELP gpud_component_health_state current health state of the component (1 for the current state, 0 for the others)
+# TYPE gpud_component_health_state gauge
+gpud_component_health_state{component="disk",health="Degraded",name="disk"} 0
+gpud_component_health_state{component="disk",health="Healthy",name="disk"} 0
+gpud_component_health_state{component="disk",health="Initializing",name="disk"} 0
+gpud_component_health_state{component="disk",health="Unhealthy",name="disk"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "gpud_component_health_state"))
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/httputil"
+)
+
+// getReqSchemaVersion returns the schema version of the health states and events
+// requested by the consumer, empty for the current schema version.
+func getReqSchemaVersion(c *gin.Context) (string, error) {
+	v := c.GetHeader(httputil.RequestHeaderSchemaVersion)
+	if v != "" && !apiv1.IsSupportedSchemaVersion(v) {
+		return "", fmt.Errorf("unsupported schema version %q", v)
+	}
+	return v, nil
+}
+
+// versionHealthStates stamps the component and schema versions to the health states,
+// and converts them to the schema version requested by the consumer.
+func versionHealthStates(comp components.Component, states apiv1.HealthStates, schemaVersion string) apiv1.HealthStates {
+	states = apiv1.StampHealthStates(states, components.VersionOf(comp))
+	converted, err := apiv1.ConvertHealthStates(states, schemaVersion)
+	if err != nil {
+		// the schema version is validated by the request parser
+		return states
+	}
+	return converted
+}
+
+// versionEvents stamps the component and schema versions to the events,
+// and converts them to the schema version requested by the consumer.
+func versionEvents(comp components.Component, events apiv1.Events, schemaVersion string) apiv1.Events {
+	events = apiv1.StampEvents(events, components.VersionOf(comp))
+	converted, err := apiv1.ConvertEvents(events, schemaVersion)
+	if err != nil {
+		return events
+	}
+	return converted
+}
@@ -12,7 +12,6 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
-	"io"
 	"math/big"
 	"net/http"
 	"net/http/pprof"
@@ -22,40 +21,94 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
 	"github.com/gin-contrib/gzip"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerfiles "github.com/swaggo/files"
 	ginswagger "github.com/swaggo/gin-swagger"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
+	componentsnvidiafallenoffbus "github.com/leptonai/gpud/components/accelerator/nvidia/fallen-off-bus"
+	componentsnvidiagds "github.com/leptonai/gpud/components/accelerator/nvidia/gds"
+	componentsnvidiainfiniband "github.com/leptonai/gpud/components/accelerator/nvidia/infiniband"
 	"github.com/leptonai/gpud/components/all"
+	componentserrorbudget "github.com/leptonai/gpud/components/error-budget"
+	componentsnetworklatency "github.com/leptonai/gpud/components/network/latency"
+	componentsnetworklldp "github.com/leptonai/gpud/components/network/lldp"
+	componentsnetworkpeermesh "github.com/leptonai/gpud/components/network/peer-mesh"
+	componentsos "github.com/leptonai/gpud/components/os"
+	componentsprediction "github.com/leptonai/gpud/components/prediction"
 	_ "github.com/leptonai/gpud/docs/apis"
+	pkgaccounting "github.com/leptonai/gpud/pkg/accounting"
+	pkgbaseline "github.com/leptonai/gpud/pkg/baseline"
 	lepconfig "github.com/leptonai/gpud/pkg/config"
+	nvidiacommon "github.com/leptonai/gpud/pkg/config/common"
 	pkgcustomplugins "github.com/leptonai/gpud/pkg/custom-plugins"
+	pkgdevmodefake "github.com/leptonai/gpud/pkg/devmode/fake"
+	pkgdisruption "github.com/leptonai/gpud/pkg/disruption"
+	pkgendpoints "github.com/leptonai/gpud/pkg/endpoints"
+	pkgerrorbudget "github.com/leptonai/gpud/pkg/errorbudget"
+	pkgescalation "github.com/leptonai/gpud/pkg/escalation"
+	"github.com/leptonai/gpud/pkg/eventbus"
 	"github.com/leptonai/gpud/pkg/eventstore"
 	pkgfaultinjector "github.com/leptonai/gpud/pkg/fault-injector"
+	pkgfile "github.com/leptonai/gpud/pkg/file"
+	pkgfindings "github.com/leptonai/gpud/pkg/findings"
 	gpudmanager "github.com/leptonai/gpud/pkg/gpud-manager"
+	pkghooks "github.com/leptonai/gpud/pkg/hooks"
 	pkghost "github.com/leptonai/gpud/pkg/host"
 	"github.com/leptonai/gpud/pkg/httputil"
+	pkgkmsg "github.com/leptonai/gpud/pkg/kmsg"
 	pkgkmsgwriter "github.com/leptonai/gpud/pkg/kmsg/writer"
+	pkglabels "github.com/leptonai/gpud/pkg/labels"
+	pkglldp "github.com/leptonai/gpud/pkg/lldp"
 	"github.com/leptonai/gpud/pkg/log"
+	pkgmaintenance "github.com/leptonai/gpud/pkg/maintenance"
+	pkgmemory "github.com/leptonai/gpud/pkg/memory"
 	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	pkgmetricsarchiver "github.com/leptonai/gpud/pkg/metrics/archiver"
 	pkgmetricsrecorder "github.com/leptonai/gpud/pkg/metrics/recorder"
 	pkgmetricsscraper "github.com/leptonai/gpud/pkg/metrics/scraper"
 	pkgmetricsstore "github.com/leptonai/gpud/pkg/metrics/store"
 	pkgmetricssyncer "github.com/leptonai/gpud/pkg/metrics/syncer"
+	pkgmigrations "github.com/leptonai/gpud/pkg/migrations"
+	pkgnccltest "github.com/leptonai/gpud/pkg/nccl-test"
+	pkgnodeload "github.com/leptonai/gpud/pkg/nodeload"
+	pkgnotifier "github.com/leptonai/gpud/pkg/notifier"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	pkgprediction "github.com/leptonai/gpud/pkg/prediction"
+	pkgprobecache "github.com/leptonai/gpud/pkg/probecache"
+	"github.com/leptonai/gpud/pkg/release/distsign"
+	pkgremediation "github.com/leptonai/gpud/pkg/remediation"
+	pkgsampling "github.com/leptonai/gpud/pkg/sampling"
+	"github.com/leptonai/gpud/pkg/server/webui"
 	"github.com/leptonai/gpud/pkg/session"
+	pkgsimulate "github.com/leptonai/gpud/pkg/simulate"
 	"github.com/leptonai/gpud/pkg/sqlite"
+	pkgtelemetry "github.com/leptonai/gpud/pkg/telemetry"
+	pkgthresholds "github.com/leptonai/gpud/pkg/thresholds"
+	pkgtimeline "github.com/leptonai/gpud/pkg/timeline"
+	pkgtoolexec "github.com/leptonai/gpud/pkg/toolexec"
+	pkgtoolpath "github.com/leptonai/gpud/pkg/toolpath"
+	"github.com/leptonai/gpud/pkg/upload"
 )
 
+// EventNameMemoryCeilingExceeded is the name of the event recorded
+// when gpud restarts itself for exceeding the memory ceiling.
+const EventNameMemoryCeilingExceeded = "gpud_memory_ceiling_exceeded"
+
 // Server is the gpud main daemon
 type Server struct {
 	dbRW *sql.DB
 	dbRO *sql.DB
+	// dbAPIRO is the read-only pool dedicated to the API readers,
+	// so that the heavy queries do not hold the connections
+	// used by the components
+	dbAPIRO *sql.DB
 
 	// initRegistry is the registry for init plugins
 	// that runs before the regular components
@@ -66,26 +119,58 @@ type Server struct {
 
 	// componentsRegistry is the registry for the regular components
 	componentsRegistry components.Registry
+	// componentDeps are the dependencies of the components,
+	// to close the components in the reverse order of their startup
+	componentDeps components.Dependencies
 
 	machineIDMu sync.RWMutex
 	machineID   string
 
+	// telemetryShutdown flushes and stops the OpenTelemetry exporters,
+	// nil if the export is disabled
+	telemetryShutdown pkgtelemetry.ShutdownFunc
+
 	// epLocalGPUdServer is the endpoint of the local GPUd server
 	epLocalGPUdServer string
-	// epControlPlane is the endpoint of the control plane
+	// epControlPlane is the endpoint of the (primary) control plane
 	epControlPlane string
+	// epControlPlaneFallbacks are the endpoints of the control plane to fail over to, in order
+	epControlPlaneFallbacks []string
 
 	fifoPath string
 	fifo     *stdos.File
 
+	// devModeDir has the outputs of the fake InfiniBand tools,
+	// empty if the dev mode is disabled
+	devModeDir string
+
 	gpudInstance *components.GPUdInstance
 	session      *session.Session
 
 	enableAutoUpdate   bool
 	autoUpdateExitCode int
+	// maintenanceDeferrer is nil if the remediations and the auto-updates are never deferred
+	maintenanceDeferrer *pkgmaintenance.Deferrer
 
 	pluginSpecsFile string
-	faultInjector   pkgfaultinjector.Injector
+	// artifactSignPubPath is the bundle of the signing public keys to verify
+	// the plugin specs and the config files, empty if not verified
+	artifactSignPubPath string
+	faultInjector       pkgfaultinjector.Injector
+
+	// ncclTester orchestrates the inter-node NCCL bandwidth tests as the leader
+	ncclTester *pkgnccltest.Orchestrator
+
+	// labels are attached to every health state, event, and metric emitted
+	labels *pkglabels.Labels
+
+	// findings tracks the findings reported to the control plane,
+	// kept across the session re-creations
+	findings *pkgfindings.Tracker
+
+	// escalation escalates the severity of the unhealthy states
+	// the longer they persist
+	escalation *pkgescalation.Tracker
 }
 
 type UserToken struct {
@@ -106,6 +191,12 @@ func New(ctx context.Context, config *lepconfig.Config, packageManager *gpudmana
 	if err := config.Validate(); err != nil {
 		return nil, fmt.Errorf("failed to validate config: %w", err)
 	}
+	if err := pkgtoolpath.SetOverrides(config.ToolPaths); err != nil {
+		return nil, fmt.Errorf("failed to set tool paths: %w", err)
+	}
+	if err := pkgtoolexec.SetResourceLimits(config.ToolResourceLimits); err != nil {
+		return nil, fmt.Errorf("failed to set tool resource limits: %w", err)
+	}
 
 	stateFile := ":memory:"
 	if config.State != "" {
@@ -120,10 +211,16 @@ func New(ctx context.Context, config *lepconfig.Config, packageManager *gpudmana
 	if err != nil {
 		return nil, fmt.Errorf("failed to open state file (for read-only): %w", err)
 	}
+	dbAPIRO, err := sqlite.Open(stateFile, sqlite.WithReadOnly(true), sqlite.WithMaxOpenConns(sqlite.DefaultMaxReadConns))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state file (for api read-only): %w", err)
+	}
 
-	if err := pkgmetadata.CreateTableMetadata(ctx, dbRW); err != nil {
-		return nil, fmt.Errorf("failed to create metadata table: %w", err)
+	fromVer, toVer, err := pkgmigrations.Apply(ctx, dbRW)
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate state file: %w", err)
 	}
+	log.Logger.Infow("state file schema", "previousVersion", fromVer, "version", toVer)
 
 	eventStore, err := eventstore.New(dbRW, dbRO, 0)
 	if err != nil {
@@ -132,6 +229,13 @@ func New(ctx context.Context, config *lepconfig.Config, packageManager *gpudmana
 
 	rebootEventStore := pkghost.NewRebootEventStore(eventStore)
 
+	// skips the redundant static discoveries on the restarts within the same boot
+	probeCache, err := pkgprobecache.New(ctx, dbRW, dbRO, pkghost.BootID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open probe cache: %w", err)
+	}
+	pkgprobecache.LoadExecutablePaths(ctx, probeCache)
+
 	// only record once when we create the server instance
 	cctx, ccancel := context.WithTimeout(ctx, time.Minute)
 	err = rebootEventStore.RecordReboot(cctx)
@@ -140,7 +244,10 @@ func New(ctx context.Context, config *lepconfig.Config, packageManager *gpudmana
 		log.Logger.Errorw("failed to record reboot", "error", err)
 	}
 
-	promScraper, err := pkgmetricsscraper.NewPrometheusScraper(pkgmetrics.DefaultGatherer())
+	// guards both the metrics store and the "/metrics" endpoint
+	// against the runaway label cardinality
+	promGatherer := pkgmetrics.NewCardinalityGuard(pkgmetrics.DefaultGatherer(), pkgmetrics.DefaultMaxSeriesPerMetric)
+	promScraper, err := pkgmetricsscraper.NewPrometheusScraper(promGatherer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create scraper: %w", err)
 	}
@@ -148,7 +255,25 @@ func New(ctx context.Context, config *lepconfig.Config, packageManager *gpudmana
 	if err != nil {
 		return nil, fmt.Errorf("failed to create metrics store: %w", err)
 	}
+	apiMetricsStore, err := pkgmetricsstore.NewSQLiteStore(ctx, dbRW, dbAPIRO, pkgmetricsstore.DefaultTableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metrics store (for api): %w", err)
+	}
 	syncer := pkgmetricssyncer.NewSyncer(ctx, promScraper, metricsSQLiteStore, time.Minute, time.Minute, 3*24*time.Hour)
+	if config.MetricsArchiveDir != "" {
+		var uploader upload.Uploader
+		if config.MetricsArchiveUploadDestination != "" {
+			uploader, err = upload.New(config.MetricsArchiveUploadDestination)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create metrics archive uploader: %w", err)
+			}
+		}
+		archiver, err := pkgmetricsarchiver.New(config.MetricsArchiveDir, uploader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create metrics archiver: %w", err)
+		}
+		syncer.SetArchiver(archiver)
+	}
 	syncer.Start()
 
 	promRecorder := pkgmetricsrecorder.NewPrometheusRecorder(ctx, 15*time.Minute, dbRO)
@@ -159,15 +284,17 @@ func New(ctx context.Context, config *lepconfig.Config, packageManager *gpudmana
 		return nil, fmt.Errorf("failed to get fifo path: %w", err)
 	}
 	s := &Server{
-		dbRW: dbRW,
-		dbRO: dbRO,
+		dbRW:    dbRW,
+		dbRO:    dbRO,
+		dbAPIRO: dbAPIRO,
 
 		fifoPath: fifoPath,
 
 		enableAutoUpdate:   config.EnableAutoUpdate,
 		autoUpdateExitCode: config.AutoUpdateExitCode,
 
-		pluginSpecsFile: config.PluginSpecsFile,
+		pluginSpecsFile:     config.PluginSpecsFile,
+		artifactSignPubPath: config.ArtifactSignPubPath,
 	}
 	defer func() {
 		if retErr != nil {
@@ -175,40 +302,203 @@ func New(ctx context.Context, config *lepconfig.Config, packageManager *gpudmana
 		}
 	}()
 
+	readArtifact := newArtifactReader(config.ArtifactSignPubPath)
+
 	s.machineID, err = pkgmetadata.ReadMachineIDWithFallback(ctx, dbRW, dbRO)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return nil, fmt.Errorf("failed to read machine uid: %w", err)
 	}
 
+	// must be set up before starting the components, to trace their first checks
+	if config.OTLPEndpoint != "" {
+		s.telemetryShutdown, err = pkgtelemetry.Setup(ctx, config.OTLPEndpoint, s.machineID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up opentelemetry export: %w", err)
+		}
+		log.Logger.Infow("enabled opentelemetry export", "endpoint", config.OTLPEndpoint)
+	}
+
+	s.labels = pkglabels.New(config.Annotations)
+	assignedLabels, err := pkglabels.ReadAssigned(ctx, dbRO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read assigned labels: %w", err)
+	}
+	s.labels.SetAssigned(assignedLabels)
+	s.findings = pkgfindings.NewTracker(pkgfindings.DefaultEscalateAfter)
+	// the acknowledged findings are not reported again after the restart
+	if err := s.findings.LoadFindings(ctx, dbRW, dbRO); err != nil {
+		return nil, fmt.Errorf("failed to load findings: %w", err)
+	}
+
+	// the windows declared ahead (e.g., the upgrade tonight) survive the restarts
+	disruptions := pkgdisruption.New()
+	persistedDisruptions, err := pkgdisruption.Read(ctx, dbRO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read disruption windows: %w", err)
+	}
+	disruptions.Set(time.Now().UTC(), persistedDisruptions)
+	s.findings.SetDisruptions(disruptions)
+	if labels := s.labels.Get(); len(labels) > 0 {
+		log.Logger.Infow("attaching labels to health states, events, and metrics", "labels", pkglabels.String(labels))
+	}
+
 	kmsgWriter := pkgkmsgwriter.NewWriter(pkgkmsgwriter.DefaultDevKmsg)
-	s.faultInjector = pkgfaultinjector.NewInjector(kmsgWriter)
+	var componentFaults *pkgfaultinjector.ComponentFaults
+	if config.EnableComponentFaultInjection {
+		log.Logger.Warnw("component fault injection enabled (NOT for production)")
+		componentFaults = pkgfaultinjector.NewComponentFaults()
+	}
+	s.faultInjector = pkgfaultinjector.NewInjector(kmsgWriter, componentFaults)
+	s.ncclTester = pkgnccltest.NewOrchestrator(ctx, componentsnetworkpeermesh.GetDefaultPeers, nil)
+	if config.MaintenanceDeferral != nil {
+		s.maintenanceDeferrer = pkgmaintenance.New(*config.MaintenanceDeferral)
+		log.Logger.Infow("deferring maintenance while the node is busy", "kubelet", config.MaintenanceDeferral.Kubelet, "busyHook", config.MaintenanceDeferral.BusyHook)
+	}
+
+	var nvmlInstance nvidianvml.Instance
+	var toolOverwrites nvidiacommon.ToolOverwrites
+	if config.DevMode != nil {
+		log.Logger.Warnw("dev mode enabled with the fake NVML backend (NOT for production)", "gpus", config.DevMode.GPUs, "productName", config.DevMode.ProductName, "scenarios", config.DevMode.Scenarios)
+		nvmlInstance, err = pkgdevmodefake.NewNVMLInstance(*config.DevMode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create NVML instance: %w", err)
+		}
+
+		// the fake InfiniBand tools print the synthetic outputs,
+		// removed on stop
+		s.devModeDir, err = stdos.MkdirTemp("", "gpud-dev-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create dev mode directory: %w", err)
+		}
+		toolOverwrites.IbstatCommand, toolOverwrites.IbstatusCommand, err = pkgdevmodefake.WriteInfinibandCommands(s.devModeDir, *config.DevMode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write dev mode infiniband outputs: %w", err)
+		}
 
-	nvmlInstance, err := nvidianvml.NewWithExitOnSuccessfulLoad(ctx)
+		// the control plane may still overwrite the expected port states
+		componentsnvidiainfiniband.SetDefaultExpectedPortStates(pkgdevmodefake.ExpectedInfinibandPortStates(*config.DevMode))
+	} else {
+		nvmlInstance, err = nvidianvml.NewWithExitOnSuccessfulLoad(ctx)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create NVML instance: %w", err)
 	}
 
+	if config.InfinibandEvaluationFile != "" {
+		if err := componentsnvidiainfiniband.LoadEvaluationConfigFile(config.InfinibandEvaluationFile, readArtifact(distsign.ArtifactTypeInfinibandEvaluation)); err != nil {
+			return nil, fmt.Errorf("failed to load infiniband evaluation config: %w", err)
+		}
+		componentsnvidiainfiniband.WatchEvaluationConfigFile(ctx, config.InfinibandEvaluationFile, readArtifact(distsign.ArtifactTypeInfinibandEvaluation), componentsnvidiainfiniband.DefaultEvaluationConfigReloadInterval)
+		log.Logger.Infow("loaded infiniband evaluation config", "file", config.InfinibandEvaluationFile)
+	}
+
+	// the thresholds updated via the API take precedence over the defaults
+	persistedThresholds, err := pkgthresholds.Read(ctx, dbRO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read thresholds: %w", err)
+	}
+	if persistedThresholds != nil {
+		persistedThresholds.Apply()
+	}
+
 	s.gpudInstance = &components.GPUdInstance{
 		RootCtx: ctx,
 
 		MachineID: s.machineID,
 
 		NVMLInstance:         nvmlInstance,
-		NVIDIAToolOverwrites: config.NvidiaToolOverwrites,
+		NVIDIAToolOverwrites: toolOverwrites,
+		ProcessesCache:       nvidianvml.NewProcessesCache(),
 
 		DBRO: dbRO,
+		DBRW: dbRW,
 
 		EventStore:       eventStore,
 		RebootEventStore: rebootEventStore,
 
+		TriggerBus: components.NewTriggerBus(),
+		EventBus:   eventbus.New(),
+
 		MountPoints:  []string{"/"},
 		MountTargets: []string{"/var/lib/kubelet"},
+
+		CheckIntervals: config.CheckIntervals(),
 	}
 	if s.gpudInstance.MachineID == "" {
 		s.gpudInstance.MachineID = pkghost.MachineID()
 		log.Logger.Infow("assigned machine id not found, using host level machine ID", "machineID", s.gpudInstance.MachineID)
 	}
 
+	if config.KmsgMatchersFile != "" {
+		rules, err := loadArtifact(readArtifact(distsign.ArtifactTypeKmsgMatchers), config.KmsgMatchersFile, pkgkmsg.ParseRules)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kmsg matchers: %w", err)
+		}
+		if err := pkgkmsg.DefaultRegistry.Register(rules...); err != nil {
+			return nil, fmt.Errorf("failed to register kmsg matchers: %w", err)
+		}
+		log.Logger.Infow("loaded kmsg matchers", "file", config.KmsgMatchersFile, "rules", len(rules))
+	}
+
+	if config.LLDPCablingMapFile != "" {
+		cablingMap, err := loadArtifact(readArtifact(distsign.ArtifactTypeLLDPCablingMap), config.LLDPCablingMapFile, pkglldp.ParseCablingMap)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load lldp cabling map: %w", err)
+		}
+		componentsnetworklldp.SetDefaultCablingMap(cablingMap)
+	}
+
+	if config.EnablePCIRescan {
+		componentsnvidiafallenoffbus.SetDefaultRescanEnabled(true)
+	}
+	if len(config.LatencyTargets) > 0 {
+		componentsnetworklatency.SetDefaultTargets(config.LatencyTargets)
+	}
+	if len(config.PeerMeshPeers) > 0 {
+		componentsnetworkpeermesh.SetDefaultPeers(config.PeerMeshPeers)
+	}
+	if config.GDSProbe != nil {
+		componentsnvidiagds.SetDefaultProbeConfig(*config.GDSProbe)
+	}
+
+	// the panics in the component checks are recovered, and the repeatedly
+	// panicking component is quarantined with an event in its own bucket
+	checkGuard := components.DefaultCheckGuard()
+	checkGuard.Stats().SetBudgets(config.CheckBudgets())
+	checkGuard.SetOnQuarantine(func(name string, err error) {
+		bucket, berr := eventStore.Bucket(name)
+		if berr != nil {
+			log.Logger.Errorw("failed to open event bucket for quarantined component", "component", name, "error", berr)
+			return
+		}
+		defer bucket.Close()
+
+		cctx, ccancel := context.WithTimeout(ctx, 10*time.Second)
+		berr = bucket.Insert(cctx, eventstore.Event{
+			Component: name,
+			Time:      time.Now().UTC(),
+			Name:      components.EventNameComponentQuarantined,
+			Type:      string(apiv1.EventTypeCritical),
+			Message:   fmt.Sprintf("component quarantined after repeated panics in check: %v", err),
+		})
+		ccancel()
+		if berr != nil {
+			log.Logger.Errorw("failed to insert component quarantine event", "component", name, "error", berr)
+		}
+	})
+
+	// the faults are injected at the guard, so that they apply to the checks
+	// of the component pollers and the plugins as well as the triggered checks
+	if componentFaults != nil {
+		checkGuard.SetFaultInjector(componentFaults)
+	}
+
+	// the low priority checks are deferred while the node is busy
+	if config.CheckLoadPolicy != nil {
+		log.Logger.Infow("deferring low priority checks under load", "policy", *config.CheckLoadPolicy)
+		checkGuard.SetLoadPolicy(*config.CheckLoadPolicy, pkgnodeload.New(nvmlInstance, pkgnodeload.DefaultCacheTTL).Load)
+	}
+
 	s.componentsRegistry = components.NewRegistry(s.gpudInstance)
 	for _, c := range all.All() {
 		name := c.Name
@@ -217,10 +507,38 @@ func New(ctx context.Context, config *lepconfig.Config, packageManager *gpudmana
 		if config.ShouldDisable(name) {
 			shouldEnable = false
 		}
+		if config.DevMode != nil && pkgdevmodefake.SkipComponent(name) {
+			log.Logger.Infow("skipping component unsupported in dev mode", "component", name)
+			shouldEnable = false
+		}
 
 		if shouldEnable {
-			s.componentsRegistry.MustRegister(c.InitFunc)
+			initFunc := c.InitFunc
+			if componentFaults != nil {
+				initFunc = pkgfaultinjector.WrapInitFunc(initFunc, componentFaults)
+			}
+			s.componentsRegistry.MustRegister(components.WrapInitFunc(initFunc, checkGuard))
+		}
+	}
+
+	// reads the events from all the other components in the registry
+	if config.PredictionModel != "" {
+		model, err := pkgprediction.New(config.PredictionModel)
+		if err != nil {
+			return nil, err
 		}
+		s.componentsRegistry.MustRegister(componentsprediction.New(model, s.componentsRegistry, metricsSQLiteStore))
+		log.Logger.Infow("enabled failure prediction", "model", model.Name())
+	}
+
+	// reads the counter history from the metrics store
+	if config.ErrorBudgetFile != "" {
+		budgets, err := loadArtifact(readArtifact(distsign.ArtifactTypeErrorBudgets), config.ErrorBudgetFile, pkgerrorbudget.ParseBudgets)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load error budgets: %w", err)
+		}
+		s.componentsRegistry.MustRegister(componentserrorbudget.New(budgets, metricsSQLiteStore))
+		log.Logger.Infow("enabled error budget tracking", "budgets", len(budgets))
 	}
 
 	// must be registered before starting the components
@@ -230,7 +548,7 @@ func New(ctx context.Context, config *lepconfig.Config, packageManager *gpudmana
 		exists := err == nil
 
 		if exists {
-			specs, err := pkgcustomplugins.LoadSpecs(config.PluginSpecsFile)
+			specs, err := loadArtifact(readArtifact(distsign.ArtifactTypePluginSpecs), config.PluginSpecsFile, pkgcustomplugins.ParseSpecs)
 			if err != nil {
 				return nil, fmt.Errorf("failed to load plugin specs: %w", err)
 			}
@@ -246,7 +564,10 @@ func New(ctx context.Context, config *lepconfig.Config, packageManager *gpudmana
 					s.initRegistry.MustRegister(initFunc)
 					log.Logger.Infow("loaded init plugin", "name", spec.ComponentName())
 				} else {
-					s.componentsRegistry.MustRegister(initFunc)
+					if componentFaults != nil {
+						initFunc = pkgfaultinjector.WrapInitFunc(initFunc, componentFaults)
+					}
+					s.componentsRegistry.MustRegister(components.WrapInitFunc(initFunc, checkGuard))
 					log.Logger.Infow("loaded component plugin", "name", spec.ComponentName())
 				}
 			}
@@ -270,14 +591,174 @@ func New(ctx context.Context, config *lepconfig.Config, packageManager *gpudmana
 		}
 	}
 
-	// component must be started after initialization
+	// the check intervals of the components disabled on this node are allowed,
+	// while the unknown component names (e.g., the typos) fail the startup
+	knownComponents := []string{componentsprediction.Name, componentserrorbudget.Name}
+	for _, c := range all.All() {
+		knownComponents = append(knownComponents, c.Name)
+	}
 	for _, c := range s.componentsRegistry.All() {
-		if err = c.Start(); err != nil {
-			return nil, fmt.Errorf("failed to start component %s: %w", c.Name(), err)
-		}
+		knownComponents = append(knownComponents, c.Name())
+	}
+	if err := s.gpudInstance.CheckIntervals.ValidateComponents(knownComponents); err != nil {
+		return nil, err
+	}
+	if err := config.CheckBudgets().ValidateComponents(knownComponents); err != nil {
+		return nil, err
+	}
+
+	// component must be started after initialization,
+	// in the order of the declared dependencies
+	deps := all.Dependencies()
+	deps[componentsprediction.Name] = []string{components.DependencyEventStore}
+	deps[componentserrorbudget.Name] = []string{components.DependencyNVML}
+	resources := map[string]components.ReadyFunc{
+		components.DependencyNVML: func(ctx context.Context) error {
+			return validateNVML(nvmlInstance)
+		},
+		components.DependencyEventStore: func(ctx context.Context) error {
+			return dbRO.PingContext(ctx)
+		},
+	}
+	s.componentDeps = deps
+	if err = components.StartInOrder(ctx, s.componentsRegistry, deps, resources, components.DefaultStartupStageTimeout); err != nil {
+		return nil, err
+	}
+	var skippedInDevMode func(string) bool
+	if config.DevMode != nil {
+		skippedInDevMode = pkgdevmodefake.SkipComponent
+	}
+	capabilities := buildCapabilityReport(time.Now().UTC(), all.All(), s.componentsRegistry, nvmlInstance.NVMLExists(), components.RunningAsRoot(), pkgfile.LocateExecutable, skippedInDevMode)
+	logCapabilityReport(capabilities)
+	if err := saveCapabilityReport(ctx, dbRW, capabilities); err != nil {
+		log.Logger.Warnw("failed to save component capability report", "error", err)
+	}
+	if err := pkgprobecache.SaveExecutablePaths(ctx, probeCache); err != nil {
+		log.Logger.Warnw("failed to cache executable paths", "error", err)
 	}
 	go doCompact(ctx, dbRW, config.CompactPeriod.Duration)
 
+	// the transitions are only read on startup and by the API
+	healthTransitionsBucket, err := eventStore.Bucket(pkgtimeline.BucketName, eventstore.WithDisablePurge(), eventstore.WithReadDB(dbAPIRO))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open health transitions bucket: %w", err)
+	}
+	healthTransitions := pkgtimeline.NewRecorder(s.componentsRegistry, healthTransitionsBucket, config.RetentionPeriod.Duration)
+	if err = healthTransitions.Start(ctx, pkgtimeline.DefaultRecordInterval); err != nil {
+		return nil, fmt.Errorf("failed to start health transitions recorder: %w", err)
+	}
+
+	var gpuAccounting *pkgaccounting.Accountant
+	if config.EnableGPUAccounting {
+		gpuAccountingBucket, err := eventStore.Bucket(pkgaccounting.BucketName, eventstore.WithDisablePurge(), eventstore.WithReadDB(dbAPIRO))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gpu accounting bucket: %w", err)
+		}
+		gpuAccounting = pkgaccounting.NewAccountant(nvmlInstance, gpuAccountingBucket, pkgaccounting.DefaultWindow, config.RetentionPeriod.Duration)
+		gpuAccounting.Start(ctx, pkgaccounting.DefaultSampleInterval)
+		log.Logger.Infow("started gpu accounting", "window", pkgaccounting.DefaultWindow)
+	}
+
+	var baselineLearner *pkgbaseline.Learner
+	if config.BaselineLearningPeriod.Duration > 0 {
+		baselineLearner, err = pkgbaseline.New(ctx, dbRW, dbRO, config.BaselineLearningPeriod.Duration, pkgbaseline.NewObserveFunc(nvmlInstance, toolOverwrites.WithDefaults().IbstatCommand))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create baseline learner: %w", err)
+		}
+		switch st := baselineLearner.State(); {
+		case st.Waiting():
+			baselineLearner.Start(ctx, pkgbaseline.DefaultObserveInterval)
+			log.Logger.Infow("baseline learning waits for the node to join", "period", config.BaselineLearningPeriod.Duration)
+		case st.Learning:
+			baselineLearner.Start(ctx, pkgbaseline.DefaultObserveInterval)
+			log.Logger.Infow("started baseline learning", "until", st.Until.Time)
+		}
+	}
+
+	if config.EventSinksFile != "" {
+		sinks, err := loadArtifact(readArtifact(distsign.ArtifactTypeEventSinks), config.EventSinksFile, pkgnotifier.ParseSinkConfigs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load event sinks: %w", err)
+		}
+		notifier, err := pkgnotifier.New(s.componentsRegistry, sinks)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create event notifier: %w", err)
+		}
+		notifier.SetDisruptions(disruptions)
+		notifier.Start(ctx, pkgnotifier.DefaultPollInterval)
+		log.Logger.Infow("started event notifier", "sinks", len(sinks))
+	}
+
+	var ladders []pkgescalation.Ladder
+	if config.EscalationLadderFile != "" {
+		ladders, err = loadArtifact(readArtifact(distsign.ArtifactTypeEscalationLadders), config.EscalationLadderFile, pkgescalation.ParseLadders)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load escalation ladders: %w", err)
+		}
+	}
+	s.escalation, err = pkgescalation.NewTracker(s.componentsRegistry, ladders)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create escalation tracker: %w", err)
+	}
+	if err := s.escalation.LoadStates(ctx, dbRW, dbRO); err != nil {
+		return nil, fmt.Errorf("failed to load escalation states: %w", err)
+	}
+	s.escalation.SetDisruptions(disruptions)
+	// the findings of the components escalated by the ladders
+	// are re-escalated only once the ladders escalate them to critical
+	s.findings.SetEscalation(s.escalation)
+	s.escalation.Start(ctx, pkgescalation.DefaultPollInterval)
+	log.Logger.Infow("started escalation tracker", "ladders", len(ladders))
+
+	if config.HooksFile != "" {
+		hooks, err := loadArtifact(readArtifact(distsign.ArtifactTypeHooks), config.HooksFile, pkghooks.ParseHooks)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load hooks: %w", err)
+		}
+		runner, err := pkghooks.NewRunner(eventStore, hooks)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create hook runner: %w", err)
+		}
+		runner.SetDisruptions(disruptions)
+		healthTransitions.OnTransition(runner.HandleTransition)
+		log.Logger.Infow("started hook runner", "hooks", len(hooks))
+	}
+
+	if config.RemediationPolicyFile != "" {
+		policy, err := loadArtifact(readArtifact(distsign.ArtifactTypeRemediationPolicy), config.RemediationPolicyFile, pkgremediation.ParsePolicy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load remediation policy: %w", err)
+		}
+		engine := pkgremediation.NewEngine(s.componentsRegistry, policy)
+		if err := engine.LoadLastActions(ctx, dbRW, dbRO); err != nil {
+			return nil, fmt.Errorf("failed to load remediation last actions: %w", err)
+		}
+		engine.SetDeferrer(s.maintenanceDeferrer)
+		engine.SetDisruptions(disruptions)
+		remediationBucket, err := eventStore.Bucket(pkgremediation.BucketName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open remediation event bucket: %w", err)
+		}
+		terminator := pkgremediation.NewGPUProcessTerminator(nvmlInstance, remediationBucket, nil)
+		engine.RegisterExecutor(apiv1.RepairActionTypeRebootSystem, func(ctx context.Context, component string, state apiv1.HealthState) error {
+			// the gpu processes are terminated gracefully and recorded before the reboot,
+			// while the protected ones are left to the reboot itself
+			if _, err := terminator.TerminateAll(ctx, fmt.Sprintf("reboot to repair %s", component)); err != nil {
+				log.Logger.Warnw("failed to terminate gpu processes before reboot", "component", component, "error", err)
+			}
+			return pkghost.Reboot(ctx, pkghost.WithDelaySeconds(10))
+		})
+		engine.Start(ctx, pkgremediation.DefaultEvaluateInterval)
+		log.Logger.Infow("started remediation engine", "rules", len(policy.Rules))
+	}
+
+	if config.MemoryCeilingBytes > 0 || config.HeapCeilingBytes > 0 {
+		watchdog := pkgmemory.NewWatchdog(config.MemoryCeilingBytes, config.HeapCeilingBytes, func(usage pkgmemory.Usage, reason string) {
+			s.restartOnMemoryCeiling(ctx, eventStore, usage, reason)
+		})
+		watchdog.Start(ctx, pkgmemory.DefaultWatchdogInterval)
+	}
+
 	cert, err := s.generateSelfSignedCert()
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate tls cert: %w", err)
@@ -287,7 +768,24 @@ func New(ctx context.Context, config *lepconfig.Config, packageManager *gpudmana
 	installRootGinMiddlewares(router)
 	installCommonGinMiddlewares(router, log.Logger.Desugar())
 
-	globalHandler := newGlobalHandler(config, s.componentsRegistry, metricsSQLiteStore, s.gpudInstance, s.faultInjector)
+	globalHandler := newGlobalHandler(config, s.componentsRegistry, apiMetricsStore, s.gpudInstance, s.faultInjector, s.labels)
+	globalHandler.healthTransitions = healthTransitions
+	globalHandler.escalation = s.escalation
+	globalHandler.findings = s.findings
+	globalHandler.disruptions = disruptions
+	globalHandler.gpuAccounting = gpuAccounting
+	globalHandler.probeCache = probeCache
+	globalHandler.checkStats = checkGuard.Stats()
+	globalHandler.capabilities = &capabilities
+	globalHandler.dbRW = dbRW
+	globalHandler.dbAPIRO = dbAPIRO
+	globalHandler.baselineLearner = baselineLearner
+	globalHandler.ncclTester = s.ncclTester
+	globalHandler.simulator = pkgsimulate.New(s.componentsRegistry, eventStore)
+	if nvmlInstance.NVMLExists() {
+		globalHandler.gpuSampler = pkgsampling.New(ctx, pkgsampling.NewNVMLCollectFunc(nvmlInstance), pkgsampling.DefaultCapacity)
+		globalHandler.gpuSnapshots = nvidianvml.NewSnapshotCache(nvmlInstance, nvidianvml.DefaultSnapshotCacheTTL)
+	}
 
 	// if the request header is set "Accept-Encoding: gzip",
 	// the middleware automatically gzip-compresses the response with the response header "Content-Encoding: gzip"
@@ -295,8 +793,38 @@ func New(ctx context.Context, config *lepconfig.Config, packageManager *gpudmana
 	v1Group.Use(gzip.Gzip(gzip.DefaultCompression, gzip.WithExcludedPaths([]string{"/update/"})))
 	globalHandler.registerComponentRoutes(v1Group)
 	globalHandler.registerPluginRoutes(v1Group)
-
-	promHandler := promhttp.HandlerFor(pkgmetrics.DefaultGatherer(), promhttp.HandlerOpts{})
+	globalHandler.registerTimelineRoutes(v1Group)
+	globalHandler.registerAccountingRoutes(v1Group)
+	globalHandler.registerGPUSamplingRoutes(v1Group)
+	globalHandler.registerGPUSnapshotRoutes(v1Group)
+	globalHandler.registerKmsgRoutes(v1Group)
+	globalHandler.registerSimulateRoutes(v1Group)
+	globalHandler.registerToolRoutes(v1Group)
+	globalHandler.registerThresholdRoutes(v1Group)
+	globalHandler.registerBaselineRoutes(v1Group)
+	globalHandler.registerNCCLTestRoutes(v1Group)
+	globalHandler.registerDisruptionRoutes(v1Group)
+	registerOpenAPIRoutes(v1Group)
+
+	v2Group := router.Group("/v2")
+	v2Group.Use(gzip.Gzip(gzip.DefaultCompression))
+	globalHandler.registerV2Routes(v2Group)
+
+	var metricsGatherer prometheus.Gatherer = promGatherer
+	if config.EnablePrometheus {
+		// separate registry to serve the exported metrics on "/metrics"
+		// without recording them in the metrics store
+		exporterRegistry := prometheus.NewRegistry()
+		exporterRegistry.MustRegister(newPrometheusExporter(s.componentsRegistry, checkGuard.Stats()))
+		metricsGatherer = prometheus.Gatherers{
+			promGatherer,
+			// the exported series are label-bounded by the live components and ports,
+			// but still guarded the same as the recorded metrics
+			pkgmetrics.NewCardinalityGuard(exporterRegistry, pkgmetrics.DefaultMaxSeriesPerMetric),
+		}
+		log.Logger.Infow("enabled prometheus exporter")
+	}
+	promHandler := promhttp.HandlerFor(metricsGatherer, promhttp.HandlerOpts{})
 	router.GET("/metrics", func(ctx *gin.Context) {
 		promHandler.ServeHTTP(ctx.Writer, ctx.Request)
 	})
@@ -310,6 +838,13 @@ func New(ctx context.Context, config *lepconfig.Config, packageManager *gpudmana
 	adminGroup.GET(urlPathConfig, handleAdminConfig(config))
 	adminGroup.GET(urlPathPackages, handleAdminPackagesStatus(packageManager))
 
+	if config.EnableWebUI {
+		log.Logger.Debugw("registering web ui handlers")
+		if err := webui.Register(router); err != nil {
+			return nil, fmt.Errorf("failed to register web ui: %w", err)
+		}
+	}
+
 	if config.Pprof {
 		log.Logger.Debugw("registering pprof handlers")
 		adminGroup.GET("/pprof/profile", gin.WrapH(http.HandlerFunc(pprof.Profile)))
@@ -321,7 +856,17 @@ func New(ctx context.Context, config *lepconfig.Config, packageManager *gpudmana
 	if err != nil {
 		return nil, fmt.Errorf("failed to read endpoint: %w", err)
 	}
-	s.epControlPlane = createURL(epControlPlane)
+	// comma-separated for the failover, the first being the primary
+	for i, ep := range pkgendpoints.Parse(epControlPlane) {
+		if i == 0 {
+			s.epControlPlane = createURL(ep)
+			continue
+		}
+		s.epControlPlaneFallbacks = append(s.epControlPlaneFallbacks, createURL(ep))
+	}
+	if s.epControlPlane == "" {
+		s.epControlPlane = createURL(epControlPlane)
+	}
 
 	s.epLocalGPUdServer, err = httputil.CreateURL("https", config.Address, "")
 	if err != nil {
@@ -340,15 +885,17 @@ func (s *Server) Stop() {
 		s.session.Stop()
 	}
 
+	if s.telemetryShutdown != nil {
+		cctx, ccancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := s.telemetryShutdown(cctx); err != nil {
+			log.Logger.Warnw("failed to flush opentelemetry export", "error", err)
+		}
+		ccancel()
+	}
+
 	if s.componentsRegistry != nil {
-		for _, component := range s.componentsRegistry.All() {
-			closer, ok := component.(io.Closer)
-			if !ok {
-				continue
-			}
-			if err := closer.Close(); err != nil {
-				log.Logger.Errorf("failed to close plugin %v: %v", component.Name(), err)
-			}
+		if err := components.CloseInOrder(s.componentsRegistry, s.componentDeps, components.DefaultStartupStageTimeout); err != nil {
+			log.Logger.Errorw("failed to close components", "error", err)
 		}
 	}
 
@@ -366,6 +913,13 @@ func (s *Server) Stop() {
 			log.Logger.Debugw("successfully closed read-only db")
 		}
 	}
+	if s.dbAPIRO != nil {
+		if cerr := s.dbAPIRO.Close(); cerr != nil {
+			log.Logger.Debugw("failed to close api read-only db", "error", cerr)
+		} else {
+			log.Logger.Debugw("successfully closed api read-only db")
+		}
+	}
 
 	if s.fifo != nil {
 		if err := s.fifo.Close(); err != nil {
@@ -377,6 +931,12 @@ func (s *Server) Stop() {
 			log.Logger.Errorf("failed to remove fifo: %s", err)
 		}
 	}
+
+	if s.devModeDir != "" {
+		if err := stdos.RemoveAll(s.devModeDir); err != nil {
+			log.Logger.Warnw("failed to remove dev mode directory", "dir", s.devModeDir, "error", err)
+		}
+	}
 }
 
 func (s *Server) generateSelfSignedCert() (tls.Certificate, error) {
@@ -485,16 +1045,24 @@ func (s *Server) updateToken(ctx context.Context, metricsStore pkgmetrics.Store,
 			s.epControlPlane,
 			userToken,
 			session.WithMachineID(machineID),
+			session.WithFallbackEndpoints(s.epControlPlaneFallbacks...),
 			session.WithPipeInterval(3*time.Second),
 			session.WithEnableAutoUpdate(s.enableAutoUpdate),
 			session.WithAutoUpdateExitCode(s.autoUpdateExitCode),
 			session.WithComponentsRegistry(s.componentsRegistry),
 			session.WithNvidiaInstance(s.gpudInstance.NVMLInstance),
 			session.WithMetricsStore(metricsStore),
-			session.WithSavePluginSpecsFunc(func(ctx context.Context, specs pkgcustomplugins.Specs) (bool, error) {
-				return pkgcustomplugins.SaveSpecs(s.pluginSpecsFile, specs)
-			}),
+			session.WithSavePluginSpecsFunc(s.savePluginSpecs),
 			session.WithFaultInjector(s.faultInjector),
+			session.WithNCCLTester(s.ncclTester),
+			session.WithMaintenanceDeferrer(s.maintenanceDeferrer),
+			session.WithEscalation(s.escalation),
+			session.WithLabels(s.labels),
+			session.WithSaveLabelsFunc(func(ctx context.Context, labels map[string]string) error {
+				return pkglabels.SaveAssigned(ctx, s.dbRW, labels)
+			}),
+			session.WithOutboxDB(s.dbRW),
+			session.WithFindingsTracker(s.findings),
 		)
 		if err != nil {
 			log.Logger.Errorw("error creating session", "error", err)
@@ -542,16 +1110,24 @@ func (s *Server) updateToken(ctx context.Context, metricsStore pkgmetrics.Store,
 				s.epControlPlane,
 				userToken,
 				session.WithMachineID(machineID),
+				session.WithFallbackEndpoints(s.epControlPlaneFallbacks...),
 				session.WithPipeInterval(3*time.Second),
 				session.WithEnableAutoUpdate(s.enableAutoUpdate),
 				session.WithAutoUpdateExitCode(s.autoUpdateExitCode),
 				session.WithComponentsRegistry(s.componentsRegistry),
 				session.WithNvidiaInstance(s.gpudInstance.NVMLInstance),
 				session.WithMetricsStore(metricsStore),
-				session.WithSavePluginSpecsFunc(func(ctx context.Context, specs pkgcustomplugins.Specs) (bool, error) {
-					return pkgcustomplugins.SaveSpecs(s.pluginSpecsFile, specs)
-				}),
+				session.WithSavePluginSpecsFunc(s.savePluginSpecs),
 				session.WithFaultInjector(s.faultInjector),
+				session.WithNCCLTester(s.ncclTester),
+				session.WithMaintenanceDeferrer(s.maintenanceDeferrer),
+				session.WithEscalation(s.escalation),
+				session.WithLabels(s.labels),
+				session.WithSaveLabelsFunc(func(ctx context.Context, labels map[string]string) error {
+					return pkglabels.SaveAssigned(ctx, s.dbRW, labels)
+				}),
+				session.WithOutboxDB(s.dbRW),
+				session.WithFindingsTracker(s.findings),
 			)
 			if err != nil {
 				log.Logger.Errorw("error creating session", "error", err)
@@ -619,6 +1195,52 @@ func doCompact(ctx context.Context, db *sql.DB, compactPeriod time.Duration) {
 	}
 }
 
+// validateNVML validates the NVML instance can query the GPUs,
+// before starting the NVML-based components.
+// No-op if the NVML library is not installed, as the components
+// report it themselves.
+func validateNVML(nvmlInstance nvidianvml.Instance) error {
+	if !nvmlInstance.NVMLExists() {
+		return nil
+	}
+	if _, ret := nvmlInstance.Library().NVML().DeviceGetCount(); ret != nvml.SUCCESS {
+		return fmt.Errorf("failed to get device count: %v", nvml.ErrorString(ret))
+	}
+	return nil
+}
+
+// restartOnMemoryCeiling records the memory ceiling event in the "os" component,
+// stops the server, and exits for the systemd to restart gpud (see "Restart=always").
+func (s *Server) restartOnMemoryCeiling(ctx context.Context, eventStore eventstore.Store, usage pkgmemory.Usage, reason string) {
+	log.Logger.Errorw("memory ceiling exceeded, restarting gpud", "reason", reason, "rssBytes", usage.RSSBytes, "heapBytes", usage.HeapBytes)
+
+	bucket, err := eventStore.Bucket(componentsos.Name)
+	if err != nil {
+		log.Logger.Errorw("failed to open event bucket", "error", err)
+	} else {
+		cctx, ccancel := context.WithTimeout(ctx, 10*time.Second)
+		err = bucket.Insert(cctx, eventstore.Event{
+			Component: componentsos.Name,
+			Time:      time.Now().UTC(),
+			Name:      EventNameMemoryCeilingExceeded,
+			Type:      string(apiv1.EventTypeWarning),
+			Message:   fmt.Sprintf("gpud restarted itself: %s", reason),
+			ExtraInfo: map[string]string{
+				"rss_bytes":  fmt.Sprintf("%d", usage.RSSBytes),
+				"heap_bytes": fmt.Sprintf("%d", usage.HeapBytes),
+			},
+		})
+		ccancel()
+		bucket.Close()
+		if err != nil {
+			log.Logger.Errorw("failed to insert memory ceiling event", "error", err)
+		}
+	}
+
+	s.Stop()
+	stdos.Exit(1)
+}
+
 func (s *Server) startListener(nvmlInstance nvidianvml.Instance, metricsSyncer *pkgmetricssyncer.Syncer, config *lepconfig.Config, router *gin.Engine, cert tls.Certificate) {
 	defer func() {
 		if nvmlInstance != nil {
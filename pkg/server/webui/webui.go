@@ -0,0 +1,32 @@
+// Package webui provides the built-in web dashboard of the gpud server,
+// which renders the component health states, recent events, and metrics
+// from the v1 APIs, without any external stack.
+package webui
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// URLPathUI is the path prefix of the web dashboard.
+const URLPathUI = "/ui"
+
+//go:embed static
+var staticFiles embed.FS
+
+// Register serves the web dashboard at "/ui/", and redirects the root path to it.
+func Register(r *gin.Engine) error {
+	sub, err := fs.Sub(staticFiles, "static")
+	if err != nil {
+		return err
+	}
+
+	r.GET("/", func(c *gin.Context) {
+		c.Redirect(http.StatusTemporaryRedirect, URLPathUI+"/")
+	})
+	r.StaticFS(URLPathUI, http.FS(sub))
+	return nil
+}
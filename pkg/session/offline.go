@@ -0,0 +1,150 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// DefaultOfflineRecordInterval is the default interval to record
+// the health state changes and the new events while the session is disconnected.
+const DefaultOfflineRecordInterval = time.Minute
+
+// offlineRecorder tracks what has already been reported (or recorded),
+// to only record the changes while the session is disconnected.
+type offlineRecorder struct {
+	// since is the time of the last record (or the last time the session was connected)
+	since time.Time
+	// healths are the last health states of each component
+	healths map[string]string
+}
+
+// recordOffline records the health state changes and the new events
+// into the outbox while the session is disconnected, so that they are
+// replayed in order on reconnect rather than lost.
+func (s *Session) recordOffline() {
+	ticker := time.NewTicker(s.offlineRecordInterval)
+	defer ticker.Stop()
+
+	r := &offlineRecorder{}
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.recordOfflineOnce(s.ctx, r, s.connected.Load(), time.Now().UTC()); err != nil {
+			log.Logger.Errorw("session offline: failed to record", "error", err)
+		}
+	}
+}
+
+func (s *Session) recordOfflineOnce(ctx context.Context, r *offlineRecorder, connected bool, now time.Time) error {
+	states := make(apiv1.GPUdComponentHealthStates, 0, len(s.components))
+	healths := make(map[string]string, len(s.components))
+	for _, name := range s.components {
+		st := s.getStatesFromComponent(name, nil)
+		states = append(states, st)
+		healths[name] = summarizeHealths(st.States)
+	}
+
+	// record less frequently while throttled
+	if !connected && r.healths != nil && s.throttler.throttled() && now.Sub(r.since) < throttledOfflineRecordFactor*s.offlineRecordInterval {
+		return nil
+	}
+
+	// the control plane reads the states and events while connected
+	if connected || r.healths == nil {
+		r.since = now
+		r.healths = healths
+		return nil
+	}
+
+	resp := &Response{Offline: true}
+	for _, st := range states {
+		if r.healths[st.Component] != healths[st.Component] {
+			resp.States = append(resp.States, st)
+		}
+	}
+	for _, name := range s.components {
+		evs := s.getEventsFromComponent(ctx, name, r.since, now)
+		if len(evs.Events) > 0 {
+			resp.Events = append(resp.Events, evs)
+		}
+	}
+
+	r.since = now
+	r.healths = healths
+
+	if len(resp.States) == 0 && len(resp.Events) == 0 {
+		return nil
+	}
+
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	log.Logger.Infow("session offline: recording changes", "states", len(resp.States), "events", len(resp.Events))
+	return s.outbox.push(ctx, Body{Data: b})
+}
+
+// summarizeHealths returns the comparable summary of the health states.
+func summarizeHealths(states apiv1.HealthStates) string {
+	ss := make([]string, 0, len(states))
+	for _, st := range states {
+		ss = append(ss, st.Name+"="+string(st.Health))
+	}
+	return strings.Join(ss, ",")
+}
+
+// replayOutbox writes the bodies buffered while the session was disconnected,
+// in order, before any new body. Returns an error if the write fails,
+// in which case the remaining bodies are kept for the next reconnect.
+func (s *Session) replayOutbox(writer *io.PipeWriter) error {
+	if s.outbox == nil {
+		return nil
+	}
+
+	replayed := 0
+	for {
+		ctx, cancel := context.WithTimeout(s.ctx, 10*time.Second)
+		id, body, ok, err := s.outbox.peek(ctx)
+		cancel()
+		if err != nil {
+			log.Logger.Errorw("session writer: failed to read outbox", "error", err)
+			if id == 0 {
+				return err
+			}
+			// drop the corrupted body so that it does not block the replay
+			ctx, cancel = context.WithTimeout(s.ctx, 10*time.Second)
+			_ = s.outbox.remove(ctx, id)
+			cancel()
+			continue
+		}
+		if !ok {
+			break
+		}
+
+		if err := s.writeBodyToPipe(writer, body); err != nil {
+			return err
+		}
+
+		ctx, cancel = context.WithTimeout(s.ctx, 10*time.Second)
+		err = s.outbox.remove(ctx, id)
+		cancel()
+		if err != nil {
+			return err
+		}
+		replayed++
+	}
+	if replayed > 0 {
+		log.Logger.Infow("session writer: replayed buffered bodies", "count", replayed)
+	}
+	return nil
+}
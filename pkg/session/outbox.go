@@ -0,0 +1,120 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetricsrecorder "github.com/leptonai/gpud/pkg/metrics/recorder"
+)
+
+const (
+	outboxTableName = "gpud_session_outbox"
+
+	// DefaultOutboxCapacity is the default maximum number of the bodies
+	// buffered while the session is disconnected.
+	// The oldest bodies are dropped once full.
+	DefaultOutboxCapacity = 10000
+)
+
+// outbox durably buffers the bodies to the control plane while the session
+// is disconnected, to replay them in order on reconnect.
+// All the methods are safe to call on the nil outbox, which drops the bodies.
+type outbox struct {
+	db       *sql.DB
+	capacity int
+}
+
+func newOutbox(ctx context.Context, db *sql.DB, capacity int) (*outbox, error) {
+	if db == nil {
+		return nil, nil
+	}
+	if capacity <= 0 {
+		capacity = DefaultOutboxCapacity
+	}
+
+	_, err := db.ExecContext(ctx, fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %s (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp INTEGER NOT NULL,
+	body TEXT NOT NULL
+);`, outboxTableName))
+	if err != nil {
+		return nil, err
+	}
+	return &outbox{db: db, capacity: capacity}, nil
+}
+
+// push appends the body to the outbox, dropping the oldest bodies over the capacity.
+func (o *outbox) push(ctx context.Context, body Body) error {
+	if o == nil {
+		return nil
+	}
+
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	_, err = o.db.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s (timestamp, body) VALUES (?, ?)`, outboxTableName), time.Now().UTC().Unix(), string(b))
+	pkgmetricsrecorder.RecordSQLiteInsertUpdate(time.Since(start).Seconds())
+	if err != nil {
+		return err
+	}
+
+	rs, err := o.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id <= (SELECT MAX(id) FROM %s) - ?`, outboxTableName, outboxTableName), o.capacity)
+	if err != nil {
+		return err
+	}
+	if dropped, err := rs.RowsAffected(); err == nil && dropped > 0 {
+		log.Logger.Warnw("session outbox full, dropped oldest bodies", "dropped", dropped, "capacity", o.capacity)
+	}
+	return nil
+}
+
+// peek returns the oldest body in the outbox, or false if empty.
+func (o *outbox) peek(ctx context.Context) (int64, Body, bool, error) {
+	if o == nil {
+		return 0, Body{}, false, nil
+	}
+
+	var id int64
+	var raw string
+	err := o.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT id, body FROM %s ORDER BY id ASC LIMIT 1`, outboxTableName)).Scan(&id, &raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, Body{}, false, nil
+	}
+	if err != nil {
+		return 0, Body{}, false, err
+	}
+
+	var body Body
+	if err := json.Unmarshal([]byte(raw), &body); err != nil {
+		return id, Body{}, false, err
+	}
+	return id, body, true, nil
+}
+
+// remove removes the body from the outbox, once written to the control plane.
+func (o *outbox) remove(ctx context.Context, id int64) error {
+	if o == nil {
+		return nil
+	}
+	_, err := o.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id = ?`, outboxTableName), id)
+	return err
+}
+
+// size returns the number of the bodies in the outbox.
+func (o *outbox) size(ctx context.Context) (int, error) {
+	if o == nil {
+		return 0, nil
+	}
+	var n int
+	err := o.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %s`, outboxTableName)).Scan(&n)
+	return n, err
+}
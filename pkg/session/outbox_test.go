@@ -0,0 +1,183 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestOutbox(t *testing.T) {
+	dbRW, _, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	ob, err := newOutbox(ctx, dbRW, 2)
+	require.NoError(t, err)
+
+	_, _, ok, err := ob.peek(ctx)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	for _, id := range []string{"a", "b", "c"} {
+		require.NoError(t, ob.push(ctx, Body{ReqID: id}))
+	}
+
+	// oldest dropped over the capacity
+	n, err := ob.size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	id, body, ok, err := ob.peek(ctx)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "b", body.ReqID)
+	require.NoError(t, ob.remove(ctx, id))
+
+	_, body, ok, err = ob.peek(ctx)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "c", body.ReqID)
+
+	// nil outbox drops
+	nilOb, err := newOutbox(ctx, nil, 0)
+	require.NoError(t, err)
+	assert.Nil(t, nilOb)
+	assert.NoError(t, nilOb.push(ctx, Body{}))
+	_, _, ok, err = nilOb.peek(ctx)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestRecordOfflineOnce(t *testing.T) {
+	dbRW, _, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	ob, err := newOutbox(ctx, dbRW, 0)
+	require.NoError(t, err)
+
+	registry := new(mockComponentRegistry)
+	comp := new(mockComponent)
+	registry.On("Get", "component1").Return(comp)
+	comp.On("LastHealthStates").Return(apiv1.HealthStates{{Name: "component1", Health: apiv1.HealthStateTypeHealthy}}).Once()
+
+	s := &Session{
+		ctx:                ctx,
+		componentsRegistry: registry,
+		components:         []string{"component1"},
+		outbox:             ob,
+	}
+	r := &offlineRecorder{}
+	now := time.Now().UTC()
+
+	// connected, only tracks the baseline
+	require.NoError(t, s.recordOfflineOnce(ctx, r, true, now))
+	n, err := ob.size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	// disconnected, no change
+	comp.On("LastHealthStates").Return(apiv1.HealthStates{{Name: "component1", Health: apiv1.HealthStateTypeHealthy}}).Once()
+	comp.On("Events", mock.Anything, now).Return(apiv1.Events{}, nil).Once()
+	require.NoError(t, s.recordOfflineOnce(ctx, r, false, now.Add(time.Minute)))
+	n, err = ob.size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	// disconnected, health changed with a new event
+	comp.On("LastHealthStates").Return(apiv1.HealthStates{{Name: "component1", Health: apiv1.HealthStateTypeUnhealthy}}).Once()
+	comp.On("Events", mock.Anything, now.Add(time.Minute)).Return(apiv1.Events{{Name: "ev1", Time: metav1.Now()}}, nil).Once()
+	require.NoError(t, s.recordOfflineOnce(ctx, r, false, now.Add(2*time.Minute)))
+
+	_, body, ok, err := ob.peek(ctx)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Empty(t, body.ReqID)
+
+	var resp Response
+	require.NoError(t, json.Unmarshal(body.Data, &resp))
+	assert.True(t, resp.Offline)
+	require.Len(t, resp.States, 1)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, resp.States[0].States[0].Health)
+	require.Len(t, resp.Events, 1)
+	assert.Equal(t, "ev1", resp.Events[0].Events[0].Name)
+}
+
+func TestHandleWriterPipeReplaysOutbox(t *testing.T) {
+	dbRW, _, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	ob, err := newOutbox(ctx, dbRW, 0)
+	require.NoError(t, err)
+	require.NoError(t, ob.push(ctx, Body{ReqID: "buffered-1"}))
+	require.NoError(t, ob.push(ctx, Body{ReqID: "buffered-2"}))
+
+	s := &Session{
+		ctx:    ctx,
+		writer: make(chan Body, 1),
+		closer: &closeOnce{closer: make(chan any)},
+		outbox: ob,
+	}
+	s.writer <- Body{ReqID: "live"}
+
+	reader, writer := io.Pipe()
+	closec := make(chan any)
+	finish := make(chan any)
+	go s.handleWriterPipe(writer, closec, finish)
+
+	decoder := json.NewDecoder(reader)
+	var got []string
+	for i := 0; i < 3; i++ {
+		var body Body
+		require.NoError(t, decoder.Decode(&body))
+		got = append(got, body.ReqID)
+	}
+	close(closec)
+	<-finish
+
+	// buffered bodies first, in order
+	assert.Equal(t, []string{"buffered-1", "buffered-2", "live"}, got)
+	n, err := ob.size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestHandleWriterPipeBuffersOnFailure(t *testing.T) {
+	dbRW, _, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	ob, err := newOutbox(ctx, dbRW, 0)
+	require.NoError(t, err)
+
+	s := &Session{
+		ctx:    ctx,
+		writer: make(chan Body, 1),
+		closer: &closeOnce{closer: make(chan any)},
+		outbox: ob,
+	}
+
+	reader, writer := io.Pipe()
+	require.NoError(t, reader.Close())
+
+	finish := make(chan any)
+	go s.handleWriterPipe(writer, make(chan any), finish)
+	s.writer <- Body{ReqID: "lost"}
+	<-finish
+
+	_, body, ok, err := ob.peek(ctx)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "lost", body.ReqID)
+}
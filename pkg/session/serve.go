@@ -26,6 +26,7 @@ import (
 	"github.com/leptonai/gpud/pkg/log"
 	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	pkgnccltest "github.com/leptonai/gpud/pkg/nccl-test"
 	"github.com/leptonai/gpud/pkg/sqlite"
 	"github.com/leptonai/gpud/pkg/systemd"
 	"github.com/leptonai/gpud/pkg/update"
@@ -60,6 +61,16 @@ type Request struct {
 
 	// CustomPluginSpecs is the specs for the custom plugins to register or overwrite.
 	CustomPluginSpecs pkgcustomplugins.Specs `json:"custom_plugin_specs,omitempty"`
+
+	// Labels is the node labels assigned by the control plane
+	// (e.g., rack, cluster, tenant), replacing the previously assigned labels.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Acks are the acknowledgments of the reported findings by the control plane.
+	Acks []apiv1.FindingAck `json:"acks,omitempty"`
+
+	// NCCLTestRequest is the inter-node NCCL bandwidth test to run with this node as the leader.
+	NCCLTestRequest *pkgnccltest.Request `json:"nccl_test_request,omitempty"`
 }
 
 // Response is the response from GPUd to the control plane.
@@ -84,6 +95,28 @@ type Response struct {
 
 	// CustomPluginSpecs lists the specs for the custom plugins.
 	CustomPluginSpecs pkgcustomplugins.Specs `json:"custom_plugin_specs,omitempty"`
+
+	// Offline is true if the response was not requested by the control plane,
+	// but recorded while the session was disconnected and replayed on reconnect,
+	// with the changed health states and the new events only (no request ID).
+	Offline bool `json:"offline,omitempty"`
+
+	// Throttled is true if the response carries the summaries only
+	// (e.g., no extra info in the health states, only the latest metric values),
+	// due to the constrained uplink or the backpressure from the control plane.
+	Throttled bool `json:"throttled,omitempty"`
+
+	// Findings are the unacknowledged unhealthy (or degraded) findings
+	// of the reported health states, for the control plane to acknowledge
+	// with the "ackFindings" request.
+	Findings []apiv1.Finding `json:"findings,omitempty"`
+
+	// Escalated is true if the response was not requested by the control plane,
+	// but re-escalates the unhealthy findings not acknowledged within the timeout.
+	Escalated bool `json:"escalated,omitempty"`
+
+	// NCCLTestResult is the result of the last (or the running) inter-node NCCL bandwidth test.
+	NCCLTestResult *pkgnccltest.Result `json:"nccl_test_result,omitempty"`
 }
 
 type BootstrapRequest struct {
@@ -135,6 +168,7 @@ func (s *Session) serve() {
 				response.Error = err.Error()
 			}
 			response.States = states
+			response.Findings = s.findings.Observe(time.Now().UTC(), states)
 
 		case "events":
 			events, err := s.getEvents(ctx, payload)
@@ -244,6 +278,14 @@ func (s *Session) serve() {
 					break
 				}
 
+				// the control plane retries the update, which is forced
+				// once deferred for the maximum deferral
+				if md := s.maintenanceDeferrer.Decide(ctx, "update"); !md.Proceed {
+					log.Logger.Warnw("node is busy with the jobs -- deferring update", "reason", md.Reason)
+					response.Error = "update deferred while the node is busy: " + md.Reason
+					break
+				}
+
 				if systemdManaged {
 					if uerr := pkdsystemd.CreateDefaultEnvFile(""); uerr != nil {
 						response.Error = uerr.Error()
@@ -268,6 +310,12 @@ func (s *Session) serve() {
 				}
 			}
 
+		case "ncclTest":
+			s.processNCCLTest(payload.NCCLTestRequest, response)
+
+		case "ncclTestResult":
+			s.processNCCLTestResult(response)
+
 		case "installAddon":
 			s.processInstallAddon(payload.InstallAddonRequest, response)
 
@@ -321,6 +369,17 @@ func (s *Session) serve() {
 						log.Logger.Infow("successfully injected kernel message", "message", payload.InjectFaultRequest.KernelMessage.Message)
 					}
 
+				case payload.InjectFaultRequest.Component != nil:
+					faults := s.faultInjector.ComponentFaults()
+					if faults == nil {
+						response.Error = pkgfaultinjector.ErrComponentFaultInjectionDisabled.Error()
+						break
+					}
+					if _, err := faults.Arm(*payload.InjectFaultRequest.Component); err != nil {
+						response.Error = err.Error()
+						log.Logger.Errorw("failed to inject component fault", "component", payload.InjectFaultRequest.Component.Component, "error", err)
+					}
+
 				default:
 					log.Logger.Warnw("fault inject request is nil or kernel message is nil")
 				}
@@ -367,6 +426,7 @@ func (s *Session) serve() {
 					States:    checkResult.HealthStates(),
 				})
 			}
+			response.Findings = s.findings.Observe(time.Now().UTC(), response.States)
 
 		case "deregisterComponent":
 			if payload.ComponentName != "" {
@@ -411,10 +471,20 @@ func (s *Session) serve() {
 
 		case "getPluginSpecs":
 			s.processGetPluginSpecs(response)
+
+		case "setLabels":
+			s.processSetLabels(ctx, payload.Labels, response)
+
+		case "ackFindings":
+			s.processAckFindings(payload.Acks, response)
 		}
 
 		cancel()
 
+		if (len(response.States) > 0 || len(response.Events) > 0 || len(response.Metrics) > 0) && s.throttler.throttled() {
+			summarizeResponse(response)
+		}
+
 		responseRaw, _ := json.Marshal(response)
 		s.writer <- Body{
 			Data:  responseRaw,
@@ -587,7 +657,7 @@ func (s *Session) getEventsFromComponent(ctx context.Context, componentName stri
 		)
 	} else if len(event) > 0 {
 		log.Logger.Debugw("successfully got events", "component", componentName)
-		currEvent.Events = event
+		currEvent.Events = s.labels.ApplyToEvents(apiv1.StampEvents(event, components.VersionOf(component)))
 	}
 	return currEvent
 }
@@ -625,6 +695,7 @@ func (s *Session) getMetricsFromComponent(ctx context.Context, componentName str
 			Value:       data.Value,
 		})
 	}
+	currMetrics.Metrics = s.labels.ApplyToMetrics(currMetrics.Metrics)
 	return currMetrics
 }
 
@@ -646,7 +717,7 @@ func (s *Session) getStatesFromComponent(componentName string, lastRebootTime *t
 	log.Logger.Debugw("getting states", "component", componentName)
 	state := component.LastHealthStates()
 	log.Logger.Debugw("successfully got states", "component", componentName)
-	currState.States = state
+	currState.States = s.labels.ApplyToHealthStates(apiv1.StampHealthStates(s.escalation.ApplyToHealthStates(componentName, state), components.VersionOf(component)))
 
 	for i, componentState := range currState.States {
 		if componentState.Health != apiv1.HealthStateTypeHealthy {
@@ -0,0 +1,100 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// findingsEscalationCheckInterval is the interval to check
+// the unacknowledged findings to re-escalate.
+const findingsEscalationCheckInterval = time.Minute
+
+// processAckFindings records the acknowledgments of the reported findings.
+func (s *Session) processAckFindings(acks []apiv1.FindingAck, resp *Response) {
+	log.Logger.Infow("processing ack findings request", "acks", len(acks))
+
+	if s.findings == nil {
+		resp.Error = "findings are not tracked"
+		return
+	}
+	if unknown := s.findings.Acknowledge(time.Now().UTC(), acks); len(unknown) > 0 {
+		// the finding may have recovered before the acknowledgment
+		log.Logger.Warnw("acknowledged unknown findings", "findings", unknown)
+	}
+}
+
+// escalateFindings re-escalates the unhealthy findings
+// not acknowledged by the control plane within the timeout.
+func (s *Session) escalateFindings() {
+	ticker := time.NewTicker(findingsEscalationCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.escalateFindingsOnce(s.ctx, time.Now().UTC()); err != nil {
+			log.Logger.Errorw("session findings: failed to re-escalate", "error", err)
+		}
+	}
+}
+
+func (s *Session) escalateFindingsOnce(ctx context.Context, now time.Time) error {
+	pending := s.findings.Pending()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	// re-read the states first, not to escalate the recovered findings
+	seen := make(map[string]struct{})
+	var states apiv1.GPUdComponentHealthStates
+	for _, f := range pending {
+		if _, ok := seen[f.Component]; ok {
+			continue
+		}
+		seen[f.Component] = struct{}{}
+		states = append(states, s.getStatesFromComponent(f.Component, nil))
+	}
+	s.findings.Observe(now, states)
+
+	due := s.findings.Escalate(now)
+	if len(due) == 0 {
+		return nil
+	}
+
+	escalated := make(map[string]struct{}, len(due))
+	for _, f := range due {
+		escalated[f.Component] = struct{}{}
+	}
+	resp := &Response{Escalated: true, Findings: due}
+	for _, st := range states {
+		if _, ok := escalated[st.Component]; ok {
+			resp.States = append(resp.States, st)
+		}
+	}
+
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	log.Logger.Warnw("session findings: re-escalating unacknowledged findings", "findings", len(due))
+
+	body := Body{Data: b}
+	if !s.connected.Load() {
+		s.bufferBody(body)
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case s.writer <- body:
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgfindings "github.com/leptonai/gpud/pkg/findings"
+)
+
+func TestProcessAckFindings(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	tracker := pkgfindings.NewTracker(time.Minute)
+	pending := tracker.Observe(now, apiv1.GPUdComponentHealthStates{
+		{Component: "comp", States: apiv1.HealthStates{{Name: "comp", Health: apiv1.HealthStateTypeUnhealthy}}},
+	})
+	require.Len(t, pending, 1)
+
+	s := &Session{findings: tracker}
+	resp := &Response{}
+	s.processAckFindings([]apiv1.FindingAck{{FindingID: pending[0].ID, AckID: "INC-1"}}, resp)
+	assert.Empty(t, resp.Error)
+	assert.Empty(t, tracker.Pending())
+
+	s = &Session{}
+	resp = &Response{}
+	s.processAckFindings([]apiv1.FindingAck{{FindingID: pending[0].ID, AckID: "INC-1"}}, resp)
+	assert.Equal(t, "findings are not tracked", resp.Error)
+}
+
+func TestEscalateFindingsOnce(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	registry := new(mockComponentRegistry)
+	comp := new(mockComponent)
+	registry.On("Get", "comp").Return(comp)
+	comp.On("LastHealthStates").Return(apiv1.HealthStates{{Name: "comp", Health: apiv1.HealthStateTypeUnhealthy}})
+
+	tracker := pkgfindings.NewTracker(10 * time.Minute)
+	s := &Session{
+		ctx:                ctx,
+		componentsRegistry: registry,
+		findings:           tracker,
+		writer:             make(chan Body, 1),
+	}
+	s.connected.Store(true)
+
+	// nothing reported yet
+	require.NoError(t, s.escalateFindingsOnce(ctx, now))
+	assert.Empty(t, s.writer)
+
+	reported := tracker.Observe(now, apiv1.GPUdComponentHealthStates{s.getStatesFromComponent("comp", nil)})
+	if len(reported) == 0 {
+		t.Skip("health states set to initializing after the recent reboot")
+	}
+
+	// not acknowledged within the timeout
+	require.NoError(t, s.escalateFindingsOnce(ctx, now.Add(5*time.Minute)))
+	assert.Empty(t, s.writer)
+
+	require.NoError(t, s.escalateFindingsOnce(ctx, now.Add(10*time.Minute)))
+	require.Len(t, s.writer, 1)
+	body := <-s.writer
+	var resp Response
+	require.NoError(t, json.Unmarshal(body.Data, &resp))
+	assert.True(t, resp.Escalated)
+	require.Len(t, resp.Findings, 1)
+	assert.Equal(t, reported[0].ID, resp.Findings[0].ID)
+	assert.Equal(t, 1, resp.Findings[0].Escalations)
+	require.Len(t, resp.States, 1)
+	assert.Equal(t, "comp", resp.States[0].Component)
+
+	// acknowledged, no longer escalated
+	tracker.Acknowledge(now, []apiv1.FindingAck{{FindingID: reported[0].ID, AckID: "INC-1"}})
+	require.NoError(t, s.escalateFindingsOnce(ctx, now.Add(30*time.Minute)))
+	assert.Empty(t, s.writer)
+}
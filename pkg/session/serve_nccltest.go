@@ -0,0 +1,51 @@
+package session
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/leptonai/gpud/pkg/log"
+	pkgnccltest "github.com/leptonai/gpud/pkg/nccl-test"
+)
+
+// processNCCLTest starts the inter-node NCCL bandwidth test with this node as the leader,
+// over the peer mesh peers if the request has no peers (see pkgnccltest.Orchestrator.Start).
+// The result is polled with the "ncclTestResult" request, since the test outlives the request.
+func (s *Session) processNCCLTest(req *pkgnccltest.Request, resp *Response) {
+	if s.ncclTester == nil {
+		resp.Error = "nccl test is not supported"
+		resp.ErrorCode = http.StatusNotImplemented
+		return
+	}
+
+	r := pkgnccltest.Request{}
+	if req != nil {
+		r = *req
+	}
+
+	if err := s.ncclTester.Start(r); err != nil {
+		log.Logger.Warnw("failed to start nccl test", "error", err)
+		resp.Error = err.Error()
+		if errors.Is(err, pkgnccltest.ErrAlreadyRunning) {
+			resp.ErrorCode = http.StatusConflict
+		} else {
+			resp.ErrorCode = http.StatusBadRequest
+		}
+		return
+	}
+	resp.NCCLTestResult = s.ncclTester.Last()
+}
+
+// processNCCLTestResult returns the result of the last (or the running) NCCL test.
+func (s *Session) processNCCLTestResult(resp *Response) {
+	if s.ncclTester == nil {
+		resp.Error = "nccl test is not supported"
+		resp.ErrorCode = http.StatusNotImplemented
+		return
+	}
+
+	resp.NCCLTestResult = s.ncclTester.Last()
+	if resp.NCCLTestResult == nil {
+		resp.ErrorCode = http.StatusNotFound
+	}
+}
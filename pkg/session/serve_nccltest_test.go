@@ -0,0 +1,69 @@
+package session
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgnccltest "github.com/leptonai/gpud/pkg/nccl-test"
+	pkgpeermesh "github.com/leptonai/gpud/pkg/peer-mesh"
+)
+
+func TestProcessNCCLTest(t *testing.T) {
+	t.Parallel()
+
+	// nccl test not supported
+	s := &Session{}
+	resp := &Response{}
+	s.processNCCLTest(&pkgnccltest.Request{}, resp)
+	assert.Equal(t, int32(http.StatusNotImplemented), resp.ErrorCode)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	release := make(chan struct{})
+	defer close(release)
+	s = &Session{
+		ncclTester: pkgnccltest.NewOrchestrator(ctx, func() pkgpeermesh.Peers {
+			return pkgpeermesh.Peers{
+				{ID: "a", Address: "10.0.0.1:15132"},
+				{ID: "b", Address: "10.0.0.2:15132"},
+			}
+		}, func(ctx context.Context, link pkgnccltest.Link, req pkgnccltest.Request) (float64, error) {
+			<-release
+			return 200, nil
+		}),
+	}
+
+	// the peers not in the peer mesh are rejected
+	resp = &Response{}
+	s.processNCCLTest(&pkgnccltest.Request{Peers: pkgpeermesh.Peers{
+		{ID: "a", Address: "10.0.0.1:15132"},
+		{ID: "x", Address: "10.9.9.9:15132"},
+	}}, resp)
+	assert.Equal(t, int32(http.StatusBadRequest), resp.ErrorCode)
+
+	resp = &Response{}
+	s.processNCCLTestResult(resp)
+	assert.Equal(t, int32(http.StatusNotFound), resp.ErrorCode)
+
+	// the peer mesh peers are tested if the request has no peers
+	resp = &Response{}
+	s.processNCCLTest(nil, resp)
+	assert.Empty(t, resp.Error)
+	require.NotNil(t, resp.NCCLTestResult)
+	assert.Len(t, resp.NCCLTestResult.Request.Peers, 2)
+
+	resp = &Response{}
+	s.processNCCLTest(nil, resp)
+	assert.Equal(t, int32(http.StatusConflict), resp.ErrorCode)
+
+	resp = &Response{}
+	s.processNCCLTestResult(resp)
+	assert.Empty(t, resp.Error)
+	require.NotNil(t, resp.NCCLTestResult)
+	assert.Equal(t, 1, resp.NCCLTestResult.PendingLinks)
+}
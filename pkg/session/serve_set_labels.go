@@ -0,0 +1,22 @@
+package session
+
+import (
+	"context"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// processSetLabels replaces the labels assigned by the control plane,
+// and persists them so that the labels survive the restarts.
+func (s *Session) processSetLabels(ctx context.Context, labels map[string]string, resp *Response) {
+	log.Logger.Infow("processing set labels request", "labels", labels)
+
+	if s.saveLabelsFunc != nil {
+		if err := s.saveLabelsFunc(ctx, labels); err != nil {
+			log.Logger.Warnw("failed to save labels", "error", err)
+			resp.Error = err.Error()
+			return
+		}
+	}
+	s.labels.SetAssigned(labels)
+}
@@ -0,0 +1,38 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pkglabels "github.com/leptonai/gpud/pkg/labels"
+)
+
+func TestProcessSetLabels(t *testing.T) {
+	t.Parallel()
+
+	var saved map[string]string
+	s := &Session{
+		labels: pkglabels.New(map[string]string{"rack": "r1"}),
+		saveLabelsFunc: func(ctx context.Context, labels map[string]string) error {
+			saved = labels
+			return nil
+		},
+	}
+
+	resp := &Response{}
+	s.processSetLabels(context.Background(), map[string]string{"tenant": "a"}, resp)
+	assert.Empty(t, resp.Error)
+	assert.Equal(t, map[string]string{"tenant": "a"}, saved)
+	assert.Equal(t, map[string]string{"rack": "r1", "tenant": "a"}, s.labels.Get())
+
+	s.saveLabelsFunc = func(ctx context.Context, labels map[string]string) error {
+		return errors.New("db error")
+	}
+	resp = &Response{}
+	s.processSetLabels(context.Background(), map[string]string{"tenant": "b"}, resp)
+	assert.Equal(t, "db error", resp.Error)
+	assert.Equal(t, "a", s.labels.Get()["tenant"])
+}
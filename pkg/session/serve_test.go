@@ -19,7 +19,9 @@ import (
 	"github.com/leptonai/gpud/components"
 	pkgfaultinjector "github.com/leptonai/gpud/pkg/fault-injector"
 	pkgkmsgwriter "github.com/leptonai/gpud/pkg/kmsg/writer"
+	pkgmaintenance "github.com/leptonai/gpud/pkg/maintenance"
 	"github.com/leptonai/gpud/pkg/metrics"
+	"github.com/leptonai/gpud/version"
 )
 
 // Mock implementations
@@ -172,6 +174,8 @@ func (m *mockProcessRunner) RunUntilCompletion(ctx context.Context, command stri
 
 type mockFaultInjector struct {
 	mock.Mock
+
+	componentFaults *pkgfaultinjector.ComponentFaults
 }
 
 func (m *mockFaultInjector) Write(kernelMessage *pkgkmsgwriter.KernelMessage) error {
@@ -187,6 +191,10 @@ func (m *mockFaultInjector) KmsgWriter() pkgkmsgwriter.KmsgWriter {
 	return args.Get(0).(pkgkmsgwriter.KmsgWriter)
 }
 
+func (m *mockFaultInjector) ComponentFaults() *pkgfaultinjector.ComponentFaults {
+	return m.componentFaults
+}
+
 type mockKmsgWriter struct {
 	mock.Mock
 }
@@ -275,7 +283,7 @@ func TestGetStatesFromComponent(t *testing.T) {
 		result := session.getStatesFromComponent("component1", lastRebootTime)
 
 		assert.Equal(t, "component1", result.Component)
-		assert.Equal(t, healthStates, result.States)
+		assert.Equal(t, apiv1.StampHealthStates(healthStates, version.Version), result.States)
 		registry.AssertExpectations(t)
 		comp.AssertExpectations(t)
 	})
@@ -313,7 +321,7 @@ func TestGetEventsFromComponent(t *testing.T) {
 		result := session.getEventsFromComponent(ctx, "component1", startTime, endTime)
 
 		assert.Equal(t, "component1", result.Component)
-		assert.Equal(t, events, result.Events)
+		assert.Equal(t, apiv1.StampEvents(events, version.Version), result.Events)
 		assert.Equal(t, startTime, result.StartTime)
 		assert.Equal(t, endTime, result.EndTime)
 		registry.AssertExpectations(t)
@@ -455,7 +463,7 @@ func TestGetHealthStates(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Len(t, states, 1)
 		assert.Equal(t, "component1", states[0].Component)
-		assert.Equal(t, healthStates, states[0].States)
+		assert.Equal(t, apiv1.StampHealthStates(healthStates, version.Version), states[0].States)
 
 		registry.AssertExpectations(t)
 		comp.AssertExpectations(t)
@@ -1639,6 +1647,40 @@ func TestHandleUpdateRequest(t *testing.T) {
 	assert.Equal(t, "auto update is disabled", response.Error)
 }
 
+// Test deferring the update request while the node is busy
+func TestHandleUpdateRequestDeferred(t *testing.T) {
+	session, _, _, _, reader, writer := setupTestSessionWithoutFaultInjector()
+
+	session.enableAutoUpdate = true
+	session.autoUpdateExitCode = 42
+	session.maintenanceDeferrer = pkgmaintenance.NewDeferrer(time.Hour, func(context.Context) (bool, string, error) {
+		return true, "1 pod(s) running on the GPUs (default/train-0)", nil
+	})
+
+	go session.serve()
+	defer close(reader)
+
+	reqData, _ := json.Marshal(Request{
+		Method:        "update",
+		UpdateVersion: "v1.2.3",
+	})
+	reader <- Body{
+		Data:  reqData,
+		ReqID: "test-update-deferred",
+	}
+
+	var resp Body
+	select {
+	case resp = <-writer:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for response")
+	}
+
+	var response Response
+	require.NoError(t, json.Unmarshal(resp.Data, &response))
+	assert.Equal(t, "update deferred while the node is busy: 1 pod(s) running on the GPUs (default/train-0)", response.Error)
+}
+
 // Test handling package request
 func TestHandlePackageRequest(t *testing.T) {
 	session, _, _, _, reader, writer := setupTestSessionWithoutFaultInjector()
@@ -4,12 +4,22 @@ import (
 	"encoding/json"
 
 	componentsnvidiainfiniband "github.com/leptonai/gpud/components/accelerator/nvidia/infiniband"
+	componentsnetworklldp "github.com/leptonai/gpud/components/network/lldp"
+	componentsnetworkpeermesh "github.com/leptonai/gpud/components/network/peer-mesh"
 	componentsnfs "github.com/leptonai/gpud/components/nfs"
+	pkglldp "github.com/leptonai/gpud/pkg/lldp"
 	"github.com/leptonai/gpud/pkg/log"
 	pkgnfschecker "github.com/leptonai/gpud/pkg/nfs-checker"
 	"github.com/leptonai/gpud/pkg/nvidia-query/infiniband"
+	pkgpeermesh "github.com/leptonai/gpud/pkg/peer-mesh"
 )
 
+// ibEvaluationUpdate is the port drop and flap detection config
+// in the infiniband update config, along with the expected port states.
+type ibEvaluationUpdate struct {
+	Evaluation *infiniband.EvaluationConfig `json:"evaluation,omitempty"`
+}
+
 func (s *Session) processUpdateConfig(configMap map[string]string, resp *Response) {
 	if len(configMap) == 0 {
 		return
@@ -26,9 +36,34 @@ func (s *Session) processUpdateConfig(configMap map[string]string, resp *Respons
 				resp.Error = err.Error()
 				return
 			}
+			if err := updateCfg.Validate(); err != nil {
+				log.Logger.Warnw("invalid infiniband config", "error", err)
+				resp.Error = err.Error()
+				return
+			}
+
+			// the port drop and flap detection config is only updated when specified,
+			// to not reset the per-node config with the port states only updates
+			var updateEvalCfg ibEvaluationUpdate
+			if err := json.Unmarshal([]byte(value), &updateEvalCfg); err != nil {
+				log.Logger.Warnw("failed to unmarshal infiniband evaluation config", "error", err)
+				resp.Error = err.Error()
+				return
+			}
+			if updateEvalCfg.Evaluation != nil {
+				if err := updateEvalCfg.Evaluation.Validate(); err != nil {
+					log.Logger.Warnw("invalid infiniband evaluation config", "error", err)
+					resp.Error = err.Error()
+					return
+				}
+			}
+
 			if s.setDefaultIbExpectedPortStatesFunc != nil {
 				s.setDefaultIbExpectedPortStatesFunc(updateCfg)
 			}
+			if updateEvalCfg.Evaluation != nil && s.setDefaultIbEvaluationConfigFunc != nil {
+				s.setDefaultIbEvaluationConfigFunc(*updateEvalCfg.Evaluation)
+			}
 
 		case componentsnfs.Name:
 			var updateCfgs pkgnfschecker.Configs
@@ -46,6 +81,38 @@ func (s *Session) processUpdateConfig(configMap map[string]string, resp *Respons
 				s.setDefaultNFSGroupConfigsFunc(updateCfgs)
 			}
 
+		case componentsnetworklldp.Name:
+			var updateMap pkglldp.CablingMap
+			if err := json.Unmarshal([]byte(value), &updateMap); err != nil {
+				log.Logger.Warnw("failed to unmarshal lldp cabling map", "error", err)
+				resp.Error = err.Error()
+				return
+			}
+			if err := updateMap.Validate(); err != nil {
+				log.Logger.Warnw("invalid lldp cabling map", "error", err)
+				resp.Error = err.Error()
+				return
+			}
+			if s.setDefaultLLDPCablingMapFunc != nil {
+				s.setDefaultLLDPCablingMapFunc(updateMap)
+			}
+
+		case componentsnetworkpeermesh.Name:
+			var updatePeers pkgpeermesh.Peers
+			if err := json.Unmarshal([]byte(value), &updatePeers); err != nil {
+				log.Logger.Warnw("failed to unmarshal peer mesh config", "error", err)
+				resp.Error = err.Error()
+				return
+			}
+			if err := updatePeers.Validate(); err != nil {
+				log.Logger.Warnw("invalid peer mesh config", "error", err)
+				resp.Error = err.Error()
+				return
+			}
+			if s.setDefaultPeerMeshPeersFunc != nil {
+				s.setDefaultPeerMeshPeersFunc(updatePeers)
+			}
+
 		default:
 			log.Logger.Warnw("unsupported component for updateConfig", "component", componentName)
 		}
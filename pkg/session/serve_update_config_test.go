@@ -8,8 +8,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	pkglldp "github.com/leptonai/gpud/pkg/lldp"
 	pkgnfschecker "github.com/leptonai/gpud/pkg/nfs-checker"
 	"github.com/leptonai/gpud/pkg/nvidia-query/infiniband"
+	pkgpeermesh "github.com/leptonai/gpud/pkg/peer-mesh"
 )
 
 func TestProcessUpdateConfig(t *testing.T) {
@@ -461,3 +463,133 @@ func TestProcessUpdateConfig_RealConfigStructures(t *testing.T) {
 		assert.Equal(t, expectedConfigs[1].NumExpectedFiles, actualConfigs[1].NumExpectedFiles)
 	})
 }
+
+func TestProcessUpdateConfig_InfinibandEvaluation(t *testing.T) {
+	t.Parallel()
+
+	t.Run("port states with evaluation config", func(t *testing.T) {
+		var actualStates infiniband.ExpectedPortStates
+		var actualCfg infiniband.EvaluationConfig
+		evalCallCount := 0
+		s := &Session{
+			setDefaultIbExpectedPortStatesFunc: func(states infiniband.ExpectedPortStates) {
+				actualStates = states
+			},
+			setDefaultIbEvaluationConfigFunc: func(cfg infiniband.EvaluationConfig) {
+				evalCallCount++
+				actualCfg = cfg
+			},
+		}
+
+		configMap := map[string]string{
+			"accelerator-nvidia-infiniband": `{"at_least_ports": 8, "at_least_rate": 400, "evaluation": {"drop_duration": "10m", "flap_window": "30m", "flap_min_transitions": 5}}`,
+		}
+
+		resp := &Response{}
+		s.processUpdateConfig(configMap, resp)
+
+		assert.Empty(t, resp.Error)
+		assert.Equal(t, 8, actualStates.AtLeastPorts)
+		assert.Equal(t, 400, actualStates.AtLeastRate)
+		assert.Equal(t, 1, evalCallCount)
+		assert.Equal(t, infiniband.EvaluationConfig{
+			DropDuration:       metav1.Duration{Duration: 10 * time.Minute},
+			FlapWindow:         metav1.Duration{Duration: 30 * time.Minute},
+			FlapMinTransitions: 5,
+		}, actualCfg)
+	})
+
+	t.Run("port states only", func(t *testing.T) {
+		ibCallCount := 0
+		s := &Session{
+			setDefaultIbExpectedPortStatesFunc: func(states infiniband.ExpectedPortStates) {
+				ibCallCount++
+			},
+			setDefaultIbEvaluationConfigFunc: func(cfg infiniband.EvaluationConfig) {
+				t.Error("setDefaultIbEvaluationConfigFunc should not be called without evaluation config")
+			},
+		}
+
+		configMap := map[string]string{
+			"accelerator-nvidia-infiniband": `{"at_least_ports": 8, "at_least_rate": 400}`,
+		}
+
+		resp := &Response{}
+		s.processUpdateConfig(configMap, resp)
+
+		assert.Empty(t, resp.Error)
+		assert.Equal(t, 1, ibCallCount)
+	})
+
+	t.Run("invalid evaluation config", func(t *testing.T) {
+		s := &Session{
+			setDefaultIbExpectedPortStatesFunc: func(states infiniband.ExpectedPortStates) {
+				t.Error("setDefaultIbExpectedPortStatesFunc should not be called for invalid config")
+			},
+			setDefaultIbEvaluationConfigFunc: func(cfg infiniband.EvaluationConfig) {
+				t.Error("setDefaultIbEvaluationConfigFunc should not be called for invalid config")
+			},
+		}
+
+		configMap := map[string]string{
+			"accelerator-nvidia-infiniband": `{"at_least_ports": 8, "evaluation": {"flap_min_transitions": -1}}`,
+		}
+
+		resp := &Response{}
+		s.processUpdateConfig(configMap, resp)
+
+		assert.Contains(t, resp.Error, "negative flap_min_transitions")
+	})
+}
+
+func TestProcessUpdateConfigPeerMesh(t *testing.T) {
+	t.Parallel()
+
+	var got pkgpeermesh.Peers
+	s := &Session{
+		setDefaultPeerMeshPeersFunc: func(peers pkgpeermesh.Peers) {
+			got = peers
+		},
+	}
+
+	resp := &Response{}
+	s.processUpdateConfig(map[string]string{
+		"network-peer-mesh": `[{"id": "node-2", "address": "10.0.1.2:15132", "rack": "rack-1"}]`,
+	}, resp)
+	assert.Empty(t, resp.Error)
+	assert.Equal(t, pkgpeermesh.Peers{{ID: "node-2", Address: "10.0.1.2:15132", Rack: "rack-1"}}, got)
+
+	got = nil
+	resp = &Response{}
+	s.processUpdateConfig(map[string]string{
+		"network-peer-mesh": `[{"id": "node-2", "address": "10.0.1.2"}]`,
+	}, resp)
+	assert.Contains(t, resp.Error, "invalid address")
+	assert.Nil(t, got)
+}
+
+func TestProcessUpdateConfigLLDP(t *testing.T) {
+	t.Parallel()
+
+	var got pkglldp.CablingMap
+	s := &Session{
+		setDefaultLLDPCablingMapFunc: func(m pkglldp.CablingMap) {
+			got = m
+		},
+	}
+
+	resp := &Response{}
+	s.processUpdateConfig(map[string]string{
+		"network-lldp": `[{"host": "node-a", "interface": "eth0", "switch": "leaf-01", "port": "swp11"}]`,
+	}, resp)
+	assert.Empty(t, resp.Error)
+	assert.Equal(t, pkglldp.CablingMap{{Host: "node-a", Interface: "eth0", Switch: "leaf-01", Port: "swp11"}}, got)
+
+	got = nil
+	resp = &Response{}
+	s.processUpdateConfig(map[string]string{
+		"network-lldp": `[{"switch": "leaf-01"}]`,
+	}, resp)
+	assert.Equal(t, pkglldp.ErrInterfaceEmpty.Error(), resp.Error)
+	assert.Nil(t, got)
+}
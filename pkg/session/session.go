@@ -2,6 +2,7 @@ package session
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,21 +11,33 @@ import (
 	"net/http"
 	"net/http/cookiejar"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
 	componentsnvidiainfiniband "github.com/leptonai/gpud/components/accelerator/nvidia/infiniband"
+	componentsnetworklldp "github.com/leptonai/gpud/components/network/lldp"
+	componentsnetworkpeermesh "github.com/leptonai/gpud/components/network/peer-mesh"
 	componentsnfs "github.com/leptonai/gpud/components/nfs"
 	pkgcustomplugins "github.com/leptonai/gpud/pkg/custom-plugins"
+	"github.com/leptonai/gpud/pkg/endpoints"
+	pkgescalation "github.com/leptonai/gpud/pkg/escalation"
 	pkgfaultinjector "github.com/leptonai/gpud/pkg/fault-injector"
+	pkgfindings "github.com/leptonai/gpud/pkg/findings"
+	pkglabels "github.com/leptonai/gpud/pkg/labels"
+	pkglldp "github.com/leptonai/gpud/pkg/lldp"
 	"github.com/leptonai/gpud/pkg/log"
 	pkgmachineinfo "github.com/leptonai/gpud/pkg/machine-info"
+	pkgmaintenance "github.com/leptonai/gpud/pkg/maintenance"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	pkgnccltest "github.com/leptonai/gpud/pkg/nccl-test"
 	pkgnfschecker "github.com/leptonai/gpud/pkg/nfs-checker"
 	"github.com/leptonai/gpud/pkg/nvidia-query/infiniband"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia-query/nvml"
+	pkgpeermesh "github.com/leptonai/gpud/pkg/peer-mesh"
 	"github.com/leptonai/gpud/pkg/process"
+	"github.com/leptonai/gpud/pkg/toolexec"
 )
 
 type Op struct {
@@ -37,6 +50,21 @@ type Op struct {
 	metricsStore        pkgmetrics.Store
 	savePluginSpecsFunc func(context.Context, pkgcustomplugins.Specs) (bool, error)
 	faultInjector       pkgfaultinjector.Injector
+	labels              *pkglabels.Labels
+	saveLabelsFunc      func(context.Context, map[string]string) error
+	findings            *pkgfindings.Tracker
+	ncclTester          *pkgnccltest.Orchestrator
+	maintenanceDeferrer *pkgmaintenance.Deferrer
+	escalation          *pkgescalation.Tracker
+
+	outboxDB              *sql.DB
+	outboxCapacity        int
+	offlineRecordInterval time.Duration
+
+	throttleRecoveryPeriod time.Duration
+
+	fallbackEndpoints      []string
+	primaryRecheckInterval time.Duration
 }
 
 type OpOption func(*Op)
@@ -50,6 +78,16 @@ func (op *Op) applyOpts(opts []OpOption) error {
 		opt(op)
 	}
 
+	if op.outboxCapacity <= 0 {
+		op.outboxCapacity = DefaultOutboxCapacity
+	}
+	if op.offlineRecordInterval <= 0 {
+		op.offlineRecordInterval = DefaultOfflineRecordInterval
+	}
+	if op.throttleRecoveryPeriod <= 0 {
+		op.throttleRecoveryPeriod = DefaultThrottleRecoveryPeriod
+	}
+
 	if !op.enableAutoUpdate && op.autoUpdateExitCode != -1 {
 		return ErrAutoUpdateDisabledButExitCodeSet
 	}
@@ -105,6 +143,104 @@ func WithFaultInjector(faultInjector pkgfaultinjector.Injector) OpOption {
 	}
 }
 
+// WithNCCLTester sets the orchestrator of the inter-node NCCL bandwidth tests,
+// to run the tests requested by the control plane with this node as the leader.
+func WithNCCLTester(ncclTester *pkgnccltest.Orchestrator) OpOption {
+	return func(op *Op) {
+		op.ncclTester = ncclTester
+	}
+}
+
+// WithMaintenanceDeferrer sets the deferrer of the auto-updates
+// while the jobs are running on the node.
+func WithMaintenanceDeferrer(d *pkgmaintenance.Deferrer) OpOption {
+	return func(op *Op) {
+		op.maintenanceDeferrer = d
+	}
+}
+
+// WithEscalation sets the tracker escalating the severity of the unhealthy states
+// sent to the control plane.
+func WithEscalation(t *pkgescalation.Tracker) OpOption {
+	return func(op *Op) {
+		op.escalation = t
+	}
+}
+
+// WithLabels sets the labels attached to every health state, event, and metric
+// sent to the control plane.
+func WithLabels(labels *pkglabels.Labels) OpOption {
+	return func(op *Op) {
+		op.labels = labels
+	}
+}
+
+// WithSaveLabelsFunc sets the function to persist the labels assigned by the control plane.
+func WithSaveLabelsFunc(saveLabelsFunc func(context.Context, map[string]string) error) OpOption {
+	return func(op *Op) {
+		op.saveLabelsFunc = saveLabelsFunc
+	}
+}
+
+// WithFindingsTracker sets the tracker of the reported findings,
+// to track the acknowledgments by the control plane and re-escalate
+// the unacknowledged unhealthy findings.
+// If not set, the findings are not tracked.
+func WithFindingsTracker(findings *pkgfindings.Tracker) OpOption {
+	return func(op *Op) {
+		op.findings = findings
+	}
+}
+
+// WithOutboxDB sets the database to durably buffer the health state changes
+// and the events while the session is disconnected, replayed in order on reconnect.
+// If not set, nothing is buffered while disconnected.
+func WithOutboxDB(db *sql.DB) OpOption {
+	return func(op *Op) {
+		op.outboxDB = db
+	}
+}
+
+// WithOutboxCapacity sets the maximum number of the buffered bodies
+// while the session is disconnected (the oldest are dropped once full).
+func WithOutboxCapacity(capacity int) OpOption {
+	return func(op *Op) {
+		op.outboxCapacity = capacity
+	}
+}
+
+// WithOfflineRecordInterval sets the interval to record the health state changes
+// and the new events while the session is disconnected.
+func WithOfflineRecordInterval(interval time.Duration) OpOption {
+	return func(op *Op) {
+		op.offlineRecordInterval = interval
+	}
+}
+
+// WithThrottleRecoveryPeriod sets the period without any backpressure from the
+// control plane or constrained uplink, after which the full fidelity reporting is restored.
+func WithThrottleRecoveryPeriod(period time.Duration) OpOption {
+	return func(op *Op) {
+		op.throttleRecoveryPeriod = period
+	}
+}
+
+// WithFallbackEndpoints sets the control plane endpoints to fail over to, in order,
+// when the primary endpoint is unreachable.
+func WithFallbackEndpoints(eps ...string) OpOption {
+	return func(op *Op) {
+		op.fallbackEndpoints = append(op.fallbackEndpoints, eps...)
+	}
+}
+
+// WithPrimaryRecheckInterval sets the interval to re-check the primary endpoint
+// while failed over to a fallback endpoint.
+func WithPrimaryRecheckInterval(interval time.Duration) OpOption {
+	return func(op *Op) {
+		op.primaryRecheckInterval = interval
+	}
+}
+
 // Triggers an auto update of GPUd itself by exiting the process with the given exit code.
 // Useful when the machine is managed by the Kubernetes daemonset and we want to
 // trigger an auto update when the daemonset restarts the machine.
@@ -124,15 +260,20 @@ type Session struct {
 
 	// epLocalGPUdServer is the endpoint of the local GPUd server
 	epLocalGPUdServer string
-	// epControlPlane is the endpoint of the control plane
+	// epControlPlane is the endpoint of the control plane currently in use
 	epControlPlane string
+	// failover selects the control plane endpoint among the primary and the fallbacks
+	failover *endpoints.Failover
 
 	token string
 
 	createGossipRequestFunc func(machineID string, nvmlInstance nvidianvml.Instance) (*apiv1.GossipRequest, error)
 
 	setDefaultIbExpectedPortStatesFunc func(states infiniband.ExpectedPortStates)
+	setDefaultIbEvaluationConfigFunc   func(cfg infiniband.EvaluationConfig)
 	setDefaultNFSGroupConfigsFunc      func(cfgs pkgnfschecker.Configs)
+	setDefaultPeerMeshPeersFunc        func(peers pkgpeermesh.Peers)
+	setDefaultLLDPCablingMapFunc       func(m pkglldp.CablingMap)
 
 	nvmlInstance       nvidianvml.Instance
 	metricsStore       pkgmetrics.Store
@@ -148,12 +289,35 @@ type Session struct {
 
 	enableAutoUpdate   bool
 	autoUpdateExitCode int
+	// maintenanceDeferrer is nil if the auto-updates are never deferred
+	maintenanceDeferrer *pkgmaintenance.Deferrer
 
 	savePluginSpecsFunc func(context.Context, pkgcustomplugins.Specs) (bool, error)
 	faultInjector       pkgfaultinjector.Injector
 
+	// ncclTester is nil if the inter-node NCCL tests are not supported
+	ncclTester *pkgnccltest.Orchestrator
+
+	labels         *pkglabels.Labels
+	saveLabelsFunc func(context.Context, map[string]string) error
+
+	// escalation is nil if the unhealthy states are not escalated
+	escalation *pkgescalation.Tracker
+
+	// findings is nil if the reported findings are not tracked
+	findings *pkgfindings.Tracker
+
 	lastPackageTimestampMu sync.RWMutex
 	lastPackageTimestamp   time.Time
+
+	// outbox is nil if nothing is buffered while disconnected
+	outbox                *outbox
+	offlineRecordInterval time.Duration
+	// connected is true while the control plane is reachable
+	connected atomic.Bool
+
+	// throttler tracks the backpressure and the constrained uplink
+	throttler *throttler
 }
 
 type closeOnce struct {
@@ -183,6 +347,13 @@ func NewSession(ctx context.Context, epLocalGPUdServer string, epControlPlane st
 		cps = append(cps, c.Name())
 	}
 
+	obCtx, obCancel := context.WithTimeout(ctx, 10*time.Second)
+	ob, err := newOutbox(obCtx, op.outboxDB, op.outboxCapacity)
+	obCancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session outbox: %w", err)
+	}
+
 	cctx, ccancel := context.WithCancel(ctx)
 	s := &Session{
 		ctx:    cctx,
@@ -192,6 +363,7 @@ func NewSession(ctx context.Context, epLocalGPUdServer string, epControlPlane st
 
 		epLocalGPUdServer: epLocalGPUdServer,
 		epControlPlane:    epControlPlane,
+		failover:          endpoints.NewFailover(append([]string{epControlPlane}, op.fallbackEndpoints...), op.primaryRecheckInterval),
 
 		machineID: op.machineID,
 		token:     token,
@@ -199,20 +371,38 @@ func NewSession(ctx context.Context, epLocalGPUdServer string, epControlPlane st
 		createGossipRequestFunc: pkgmachineinfo.CreateGossipRequest,
 
 		setDefaultIbExpectedPortStatesFunc: componentsnvidiainfiniband.SetDefaultExpectedPortStates,
-		setDefaultNFSGroupConfigsFunc:      componentsnfs.SetDefaultConfigs,
+		setDefaultIbEvaluationConfigFunc: func(cfg infiniband.EvaluationConfig) {
+			componentsnvidiainfiniband.SetDefaultEvaluationConfig(componentsnvidiainfiniband.EvaluationConfigSourceControlPlane, cfg)
+		},
+		setDefaultNFSGroupConfigsFunc: componentsnfs.SetDefaultConfigs,
+		setDefaultPeerMeshPeersFunc:   componentsnetworkpeermesh.SetDefaultPeers,
+		setDefaultLLDPCablingMapFunc:  componentsnetworklldp.SetDefaultCablingMap,
 
 		nvmlInstance:       op.nvmlInstance,
 		metricsStore:       op.metricsStore,
 		componentsRegistry: op.componentsRegistry,
-		processRunner:      process.NewExclusiveRunner(),
+		processRunner:      toolexec.NewExclusiveRunner(),
 
 		components: cps,
 
 		savePluginSpecsFunc: op.savePluginSpecsFunc,
 		faultInjector:       op.faultInjector,
+		ncclTester:          op.ncclTester,
+
+		labels:         op.labels,
+		escalation:     op.escalation,
+		saveLabelsFunc: op.saveLabelsFunc,
+
+		findings: op.findings,
+
+		enableAutoUpdate:    op.enableAutoUpdate,
+		autoUpdateExitCode:  op.autoUpdateExitCode,
+		maintenanceDeferrer: op.maintenanceDeferrer,
+
+		outbox:                ob,
+		offlineRecordInterval: op.offlineRecordInterval,
 
-		enableAutoUpdate:   op.enableAutoUpdate,
-		autoUpdateExitCode: op.autoUpdateExitCode,
+		throttler: newThrottler(op.throttleRecoveryPeriod),
 	}
 
 	s.reader = make(chan Body, 20)
@@ -220,6 +410,12 @@ func NewSession(ctx context.Context, epLocalGPUdServer string, epControlPlane st
 	s.closer = &closeOnce{closer: make(chan any)}
 	go s.keepAlive()
 	go s.serve()
+	if s.outbox != nil {
+		go s.recordOffline()
+	}
+	if s.findings != nil {
+		go s.escalateFindings()
+	}
 
 	return s, nil
 }
@@ -232,12 +428,23 @@ type Body struct {
 func (s *Session) keepAlive() {
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
+	var lastAttempt time.Time
+	failover := s.failover
+	if failover == nil {
+		failover = endpoints.NewFailover([]string{s.epControlPlane}, 0)
+	}
 	for {
 		select {
 		case <-s.ctx.Done():
 			log.Logger.Debug("session keep alive: closing keep alive")
 			return
 		case <-ticker.C:
+			// reconnect less frequently under the backpressure
+			if s.throttler.throttled() && time.Since(lastAttempt) < throttledReconnectInterval {
+				continue
+			}
+			lastAttempt = time.Now()
+
 			readerExit := make(chan any)
 			writerExit := make(chan any)
 			s.closer = &closeOnce{closer: make(chan any)}
@@ -247,11 +454,18 @@ func (s *Session) keepAlive() {
 
 			log.Logger.Infow("session keep alive: checking server health")
 			// DO NOT CHANGE OR REMOVE THIS SERVER HEALTH CHECK, DEPEND ON IT FOR STICKY SESSION
-			if err := s.checkServerHealth(ctx, jar); err != nil {
+			// fails over to the next healthy endpoint, if any
+			ep, err := failover.Do(ctx, func(ctx context.Context, ep string) error {
+				return s.checkServerHealth(ctx, ep, jar)
+			})
+			if err != nil {
 				log.Logger.Errorf("session keep alive: error checking server health: %v", err)
+				s.connected.Store(false)
 				cancel()
 				continue
 			}
+			s.epControlPlane = ep
+			s.connected.Store(true)
 
 			go s.startReader(ctx, readerExit, jar)
 			go s.startWriter(ctx, writerExit, jar)
@@ -260,6 +474,7 @@ func (s *Session) keepAlive() {
 			cancel()
 			<-writerExit
 			log.Logger.Debug("session writer: writer exited")
+			s.connected.Store(false)
 			cancel()
 		}
 	}
@@ -326,6 +541,13 @@ func (s *Session) handleWriterPipe(writer *io.PipeWriter, closec, finish chan an
 	defer close(finish)
 	defer writer.Close()
 	log.Logger.Debug("session writer: pipe handler started")
+
+	// replay the bodies buffered while disconnected first, to preserve the order
+	if err := s.replayOutbox(writer); err != nil {
+		log.Logger.Warnw("session writer: failed to replay buffered bodies", "error", err)
+		return
+	}
+
 	for {
 		select {
 		case <-s.closer.Done():
@@ -336,6 +558,8 @@ func (s *Session) handleWriterPipe(writer *io.PipeWriter, closec, finish chan an
 			return
 		case body := <-s.writer:
 			if err := s.writeBodyToPipe(writer, body); err != nil {
+				// buffer the body to replay on reconnect, instead of losing it
+				s.bufferBody(body)
 				if errors.Is(err, io.ErrClosedPipe) {
 					return
 				}
@@ -344,16 +568,29 @@ func (s *Session) handleWriterPipe(writer *io.PipeWriter, closec, finish chan an
 	}
 }
 
+func (s *Session) bufferBody(body Body) {
+	if s.outbox == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Second)
+	defer cancel()
+	if err := s.outbox.push(ctx, body); err != nil {
+		log.Logger.Errorw("session writer: failed to buffer body", "error", err)
+	}
+}
+
 func (s *Session) writeBodyToPipe(writer *io.PipeWriter, body Body) error {
 	bytes, err := json.Marshal(body)
 	if err != nil {
 		log.Logger.Errorf("session writer: failed to marshal body: %v", err)
 		return err
 	}
+	start := time.Now()
 	if _, err := writer.Write(bytes); err != nil {
 		log.Logger.Errorf("session writer: failed to write to pipe: %v", err)
 		return err
 	}
+	s.throttler.observeWrite(len(bytes), time.Since(start))
 	log.Logger.Debug("session writer: body written to pipe")
 	return nil
 }
@@ -383,6 +620,7 @@ func (s *Session) startReader(ctx context.Context, readerExit chan any, jar *coo
 		return
 	}
 	if resp.StatusCode != http.StatusOK {
+		s.throttler.observeResponse(resp)
 		log.Logger.Debugf("session reader: request resp not ok: %v %v, retrying", resp.StatusCode, resp.Status)
 		close(pipeFinishCh)
 		return
@@ -391,8 +629,8 @@ func (s *Session) startReader(ctx context.Context, readerExit chan any, jar *coo
 	s.processReaderResponse(resp, goroutineCloseCh, pipeFinishCh)
 }
 
-func (s *Session) checkServerHealth(ctx context.Context, jar *cookiejar.Jar) error {
-	req, err := http.NewRequestWithContext(ctx, "GET", s.epControlPlane+"/healthz", nil)
+func (s *Session) checkServerHealth(ctx context.Context, epControlPlane string, jar *cookiejar.Jar) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", epControlPlane+"/healthz", nil)
 	if err != nil {
 		return err
 	}
@@ -405,6 +643,7 @@ func (s *Session) checkServerHealth(ctx context.Context, jar *cookiejar.Jar) err
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
+		s.throttler.observeResponse(resp)
 		return fmt.Errorf("server health check failed: %s", resp.Status)
 	}
 	return nil
@@ -0,0 +1,220 @@
+package session
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// DefaultThrottleRecoveryPeriod is the default period without any backpressure
+	// or constrained uplink signal, after which the full fidelity reporting is restored.
+	DefaultThrottleRecoveryPeriod = 5 * time.Minute
+
+	// throttledReconnectInterval is the minimum interval between the reconnects while throttled.
+	throttledReconnectInterval = 30 * time.Second
+
+	// throttledOfflineRecordFactor multiplies the offline record interval while throttled.
+	throttledOfflineRecordFactor = 4
+
+	// minUplinkBytesPerSecond is the write throughput to the control plane
+	// below which the uplink is considered constrained.
+	minUplinkBytesPerSecond = 32 * 1024
+	// minUplinkCheckDuration is the minimum total write duration in the window
+	// to evaluate the throughput, to ignore the idle windows dominated by the latency.
+	minUplinkCheckDuration = time.Second
+	// uplinkWindow is the window over which the write throughput is measured,
+	// so that a single slow write does not throttle the reporting.
+	uplinkWindow = time.Minute
+)
+
+// throttler tracks whether the reporting to the control plane should be
+// throttled, when the uplink is constrained or the control plane signals backpressure.
+// While throttled, the responses carry the summaries only, and the session
+// reconnects and records offline changes less frequently.
+type throttler struct {
+	mu             sync.Mutex
+	recoveryPeriod time.Duration
+	until          time.Time
+	reason         string
+
+	// the writes observed in the current uplink window
+	windowStart   time.Time
+	windowBytes   int
+	windowElapsed time.Duration
+
+	nowFunc func() time.Time
+}
+
+func newThrottler(recoveryPeriod time.Duration) *throttler {
+	if recoveryPeriod <= 0 {
+		recoveryPeriod = DefaultThrottleRecoveryPeriod
+	}
+	return &throttler{
+		recoveryPeriod: recoveryPeriod,
+		nowFunc:        time.Now,
+	}
+}
+
+// signal throttles the reporting for the recovery period, or until the
+// retry-after duration requested by the control plane if longer.
+func (t *throttler) signal(reason string, retryAfter time.Duration) {
+	if t == nil {
+		return
+	}
+
+	d := t.recoveryPeriod
+	if retryAfter > d {
+		d = retryAfter
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.nowFunc()
+	if !now.Before(t.until) {
+		log.Logger.Warnw("session throttle: reducing report frequency and payload detail", "reason", reason, "duration", d)
+	}
+	if until := now.Add(d); until.After(t.until) {
+		t.until = until
+	}
+	t.reason = reason
+}
+
+// throttled returns true if the reporting should be throttled.
+func (t *throttler) throttled() bool {
+	if t == nil {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.until.IsZero() {
+		return false
+	}
+	if t.nowFunc().Before(t.until) {
+		return true
+	}
+
+	log.Logger.Infow("session throttle: restoring full fidelity reporting", "lastReason", t.reason)
+	t.until = time.Time{}
+	t.reason = ""
+	return false
+}
+
+// observeWrite records the write, and signals the constrained uplink if the
+// write throughput over the uplink window is too low.
+func (t *throttler) observeWrite(n int, elapsed time.Duration) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	now := t.nowFunc()
+	if t.windowStart.IsZero() {
+		t.windowStart = now
+	}
+	t.windowBytes += n
+	t.windowElapsed += elapsed
+	if now.Sub(t.windowStart) < uplinkWindow {
+		t.mu.Unlock()
+		return
+	}
+
+	bytes, total := t.windowBytes, t.windowElapsed
+	t.windowStart, t.windowBytes, t.windowElapsed = now, 0, 0
+	t.mu.Unlock()
+
+	if total < minUplinkCheckDuration {
+		return
+	}
+	if bps := float64(bytes) / total.Seconds(); bps < minUplinkBytesPerSecond {
+		t.signal(fmt.Sprintf("uplink constrained (%.0f bytes/s over %s)", bps, uplinkWindow), 0)
+	}
+}
+
+// observeResponse signals the backpressure if the control plane responds
+// with 429 or 503, honoring the "Retry-After" header (in seconds).
+// Returns true if the backpressure is signaled.
+func (t *throttler) observeResponse(resp *http.Response) bool {
+	if resp == nil {
+		return false
+	}
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return false
+	}
+
+	var retryAfter time.Duration
+	if v := strings.TrimSpace(resp.Header.Get("Retry-After")); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+			retryAfter = time.Duration(secs) * time.Second
+		}
+	}
+	t.signal(fmt.Sprintf("control plane backpressure (%s)", resp.Status), retryAfter)
+	return true
+}
+
+// summarizeResponse reduces the payload of the response to the summaries only:
+// no extra info in the health states, and only the latest value of each metric series.
+func summarizeResponse(resp *Response) {
+	resp.Throttled = true
+
+	for i := range resp.States {
+		states := make(apiv1.HealthStates, len(resp.States[i].States))
+		for j, st := range resp.States[i].States {
+			st.ExtraInfo = nil
+			states[j] = st
+		}
+		resp.States[i].States = states
+	}
+
+	for i := range resp.Metrics {
+		resp.Metrics[i].Metrics = latestMetrics(resp.Metrics[i].Metrics)
+	}
+}
+
+// latestMetrics returns the latest data point of each metric series (name and labels).
+func latestMetrics(ms apiv1.Metrics) apiv1.Metrics {
+	latest := make(map[string]apiv1.Metric)
+	for _, m := range ms {
+		key := metricSeriesKey(m)
+		if prev, ok := latest[key]; !ok || m.UnixSeconds > prev.UnixSeconds {
+			latest[key] = m
+		}
+	}
+
+	ret := make(apiv1.Metrics, 0, len(latest))
+	for _, m := range latest {
+		ret = append(ret, m)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return metricSeriesKey(ret[i]) < metricSeriesKey(ret[j])
+	})
+	return ret
+}
+
+func metricSeriesKey(m apiv1.Metric) string {
+	keys := make([]string, 0, len(m.Labels))
+	for k := range m.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString(m.Name)
+	for _, k := range keys {
+		sb.WriteString(",")
+		sb.WriteString(k)
+		sb.WriteString("=")
+		sb.WriteString(m.Labels[k])
+	}
+	return sb.String()
+}
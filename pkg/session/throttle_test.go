@@ -0,0 +1,133 @@
+package session
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestThrottler(t *testing.T) {
+	now := time.Now()
+	th := newThrottler(time.Minute)
+	th.nowFunc = func() time.Time { return now }
+
+	assert.False(t, th.throttled())
+
+	th.signal("test", 0)
+	assert.True(t, th.throttled())
+
+	now = now.Add(59 * time.Second)
+	assert.True(t, th.throttled())
+
+	now = now.Add(2 * time.Second)
+	assert.False(t, th.throttled())
+
+	// retry-after longer than the recovery period
+	th.signal("test", 10*time.Minute)
+	now = now.Add(5 * time.Minute)
+	assert.True(t, th.throttled())
+	now = now.Add(6 * time.Minute)
+	assert.False(t, th.throttled())
+
+	// nil-safe
+	var nilThrottler *throttler
+	nilThrottler.signal("test", 0)
+	assert.False(t, nilThrottler.throttled())
+	nilThrottler.observeWrite(1, time.Hour)
+}
+
+func TestThrottlerObserveWrite(t *testing.T) {
+	now := time.Now()
+	th := newThrottler(time.Minute)
+	th.nowFunc = func() time.Time { return now }
+
+	// a single slow write does not throttle before the window elapses
+	th.observeWrite(1024, 2*time.Second)
+	assert.False(t, th.throttled())
+
+	// the fast writes in the same window make up for it
+	now = now.Add(30 * time.Second)
+	th.observeWrite(10*minUplinkBytesPerSecond, 2*time.Second)
+	now = now.Add(30 * time.Second)
+	th.observeWrite(10*minUplinkBytesPerSecond, time.Second)
+	assert.False(t, th.throttled())
+
+	// the small writes in the window are too short to evaluate
+	now = now.Add(time.Minute)
+	th.observeWrite(1, 100*time.Millisecond)
+	assert.False(t, th.throttled())
+
+	// slow over the whole window
+	now = now.Add(30 * time.Second)
+	th.observeWrite(1024, 2*time.Second)
+	now = now.Add(30 * time.Second)
+	th.observeWrite(1024, 2*time.Second)
+	assert.True(t, th.throttled())
+}
+
+func TestThrottlerObserveResponse(t *testing.T) {
+	now := time.Now()
+	th := newThrottler(time.Minute)
+	th.nowFunc = func() time.Time { return now }
+
+	assert.False(t, th.observeResponse(nil))
+	assert.False(t, th.observeResponse(&http.Response{StatusCode: http.StatusInternalServerError}))
+	assert.False(t, th.throttled())
+
+	resp := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Status:     "429 Too Many Requests",
+		Header:     http.Header{"Retry-After": []string{"600"}},
+	}
+	assert.True(t, th.observeResponse(resp))
+	assert.True(t, th.throttled())
+
+	now = now.Add(5 * time.Minute)
+	assert.True(t, th.throttled())
+	now = now.Add(6 * time.Minute)
+	assert.False(t, th.throttled())
+
+	assert.True(t, th.observeResponse(&http.Response{StatusCode: http.StatusServiceUnavailable}))
+	assert.True(t, th.throttled())
+}
+
+func TestSummarizeResponse(t *testing.T) {
+	resp := &Response{
+		States: apiv1.GPUdComponentHealthStates{
+			{
+				Component: "comp",
+				States: apiv1.HealthStates{
+					{Name: "comp", Health: apiv1.HealthStateTypeHealthy, ExtraInfo: map[string]string{"data": "{}"}},
+				},
+			},
+		},
+		Metrics: apiv1.GPUdComponentMetrics{
+			{
+				Component: "comp",
+				Metrics: apiv1.Metrics{
+					{UnixSeconds: 1, Name: "a", Labels: map[string]string{"gpu": "0"}, Value: 1},
+					{UnixSeconds: 2, Name: "a", Labels: map[string]string{"gpu": "0"}, Value: 2},
+					{UnixSeconds: 1, Name: "a", Labels: map[string]string{"gpu": "1"}, Value: 3},
+					{UnixSeconds: 1, Name: "b", Value: 4},
+				},
+			},
+		},
+	}
+	summarizeResponse(resp)
+
+	assert.True(t, resp.Throttled)
+	require.Len(t, resp.States[0].States, 1)
+	assert.Nil(t, resp.States[0].States[0].ExtraInfo)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, resp.States[0].States[0].Health)
+
+	ms := resp.Metrics[0].Metrics
+	require.Len(t, ms, 3)
+	assert.Equal(t, float64(2), ms[0].Value)
+	assert.Equal(t, float64(3), ms[1].Value)
+	assert.Equal(t, float64(4), ms[2].Value)
+}
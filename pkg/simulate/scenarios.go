@@ -0,0 +1,69 @@
+package simulate
+
+import (
+	"fmt"
+	"sort"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	componentsnvidiainfiniband "github.com/leptonai/gpud/components/accelerator/nvidia/infiniband"
+	componentsnvidiaxid "github.com/leptonai/gpud/components/accelerator/nvidia/xid"
+	nvidiaxid "github.com/leptonai/gpud/pkg/nvidia-query/xid"
+)
+
+// scenarios are the built-in scenarios, keyed by the name.
+var scenarios = map[string]func() apiv1.SimulationRequest{
+	"xid-63": func() apiv1.SimulationRequest { return xidScenario(63) },
+	"xid-79": func() apiv1.SimulationRequest { return xidScenario(79) },
+	"ib-port-down": func() apiv1.SimulationRequest {
+		return apiv1.SimulationRequest{
+			Component: componentsnvidiainfiniband.Name,
+			HealthState: &apiv1.HealthState{
+				Health: apiv1.HealthStateTypeUnhealthy,
+				Reason: "only 7 port(s) are active and >=400 Gb/s, expect >=8 port(s) (mlx5_0 port 1 down)",
+				SuggestedActions: &apiv1.SuggestedActions{
+					RepairActions: []apiv1.RepairActionType{apiv1.RepairActionTypeHardwareInspection},
+				},
+			},
+		}
+	},
+}
+
+// Scenarios returns the names of the built-in scenarios.
+func Scenarios() []string {
+	names := make([]string, 0, len(scenarios))
+	for name := range scenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func xidScenario(id int) apiv1.SimulationRequest {
+	msg := componentsnvidiaxid.GetMessageToInject(id)
+
+	evType := apiv1.EventTypeCritical
+	health := apiv1.HealthStateTypeUnhealthy
+	var sa *apiv1.SuggestedActions
+	reason := fmt.Sprintf("XID %d detected", id)
+	if detail, ok := nvidiaxid.GetDetail(id); ok {
+		if detail.EventType != "" {
+			evType = detail.EventType
+		}
+		sa = detail.SuggestedActionsByGPUd
+		reason = fmt.Sprintf("XID %d(%s) detected", id, detail.Name)
+	}
+
+	return apiv1.SimulationRequest{
+		Component: componentsnvidiaxid.Name,
+		Event: &apiv1.Event{
+			Name:    componentsnvidiaxid.EventNameErrorXid,
+			Type:    evType,
+			Message: msg.Message,
+		},
+		HealthState: &apiv1.HealthState{
+			Health:           health,
+			Reason:           reason,
+			SuggestedActions: sa,
+		},
+	}
+}
@@ -0,0 +1,261 @@
+// Package simulate injects the synthetic events and health states into the components
+// through the normal pipeline (event store, notifications, control plane),
+// so that operators can test their paging and automation end-to-end.
+package simulate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// DefaultDuration is the default duration to report the synthetic health state for.
+const DefaultDuration = 5 * time.Minute
+
+var (
+	ErrUnknownScenario   = errors.New("unknown simulation scenario")
+	ErrComponentRequired = errors.New("simulation component is required")
+	ErrComponentNotFound = errors.New("simulation component not found")
+	ErrNothingToInject   = errors.New("simulation event or health state is required")
+)
+
+// IsSynthetic returns true if the health state is injected by the simulation.
+func IsSynthetic(st apiv1.HealthState) bool {
+	return st.ExtraInfo[apiv1.SyntheticExtraInfoKey] == "true"
+}
+
+// Resolve fills in the request from the scenario, if any,
+// with the fields set in the request taking precedence.
+func Resolve(req apiv1.SimulationRequest) (apiv1.SimulationRequest, error) {
+	if req.Scenario == "" {
+		return req, nil
+	}
+
+	f, ok := scenarios[req.Scenario]
+	if !ok {
+		return req, fmt.Errorf("%w %q (available: %s)", ErrUnknownScenario, req.Scenario, strings.Join(Scenarios(), ", "))
+	}
+	resolved := f()
+	resolved.Scenario = req.Scenario
+	if req.Component != "" {
+		resolved.Component = req.Component
+	}
+	if req.Event != nil {
+		resolved.Event = req.Event
+	}
+	if req.HealthState != nil {
+		resolved.HealthState = req.HealthState
+	}
+	resolved.Duration = req.Duration
+	return resolved, nil
+}
+
+// Simulator injects the synthetic events and health states into the registered components.
+type Simulator struct {
+	registry   components.Registry
+	eventStore eventstore.Store
+
+	mu sync.Mutex
+	// overlays of the components currently reporting the synthetic health states
+	overlays map[string]*overlayComponent
+
+	nowFunc func() time.Time
+}
+
+// New creates a new simulator.
+func New(registry components.Registry, eventStore eventstore.Store) *Simulator {
+	return &Simulator{
+		registry:   registry,
+		eventStore: eventStore,
+		overlays:   make(map[string]*overlayComponent),
+		nowFunc:    time.Now,
+	}
+}
+
+// Simulate injects the synthetic event into the event store of the component,
+// and/or reports the synthetic health state for the component for the duration.
+// Both are marked as synthetic (see SyntheticMessagePrefix and SyntheticExtraInfoKey).
+func (s *Simulator) Simulate(ctx context.Context, req apiv1.SimulationRequest) (*apiv1.SimulationResponse, error) {
+	req, err := Resolve(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.Component == "" {
+		return nil, ErrComponentRequired
+	}
+	if req.Event == nil && req.HealthState == nil {
+		return nil, ErrNothingToInject
+	}
+
+	comp := s.registry.Get(req.Component)
+	if comp == nil {
+		return nil, fmt.Errorf("%w %q", ErrComponentNotFound, req.Component)
+	}
+
+	now := s.nowFunc().UTC()
+	resp := &apiv1.SimulationResponse{Component: req.Component}
+
+	if req.Event != nil {
+		ev, err := s.insertEvent(ctx, req.Component, *req.Event, now)
+		if err != nil {
+			return nil, err
+		}
+		resp.Event = ev
+	}
+
+	if req.HealthState != nil {
+		d := req.Duration.Duration
+		if d <= 0 {
+			d = DefaultDuration
+		}
+		st, expiresAt := s.overlay(comp, *req.HealthState, now, d)
+		resp.HealthState = &st
+		resp.ExpiresAt = &metav1.Time{Time: expiresAt}
+	}
+
+	log.Logger.Warnw("injected synthetic event/health state", "scenario", req.Scenario, "component", req.Component)
+	return resp, nil
+}
+
+func (s *Simulator) insertEvent(ctx context.Context, component string, ev apiv1.Event, now time.Time) (*apiv1.Event, error) {
+	if s.eventStore == nil {
+		return nil, errors.New("event store not set up")
+	}
+
+	// same bucket as the component, so that the event goes through
+	// the component events (notifications, control plane)
+	bucket, err := s.eventStore.Bucket(component)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event bucket: %w", err)
+	}
+	defer bucket.Close()
+
+	if ev.Type == "" {
+		ev.Type = apiv1.EventTypeWarning
+	}
+	if ev.Name == "" {
+		ev.Name = "synthetic"
+	}
+	sev := eventstore.Event{
+		Component: component,
+		Time:      now,
+		Name:      ev.Name,
+		Type:      string(ev.Type),
+		Message:   apiv1.SyntheticMessagePrefix + ev.Message,
+		// only the synthetic marker, so that the component does not
+		// evaluate the event as a real one (e.g., the Xid data)
+		ExtraInfo: map[string]string{apiv1.SyntheticExtraInfoKey: "true"},
+	}
+	if err := bucket.Insert(ctx, sev); err != nil {
+		return nil, fmt.Errorf("failed to insert synthetic event: %w", err)
+	}
+
+	ret := sev.ToEvent()
+	return &ret, nil
+}
+
+// overlay reports the synthetic health state for the component until the expiry,
+// by replacing the component in the registry with the overlay.
+func (s *Simulator) overlay(comp components.Component, st apiv1.HealthState, now time.Time, d time.Duration) (apiv1.HealthState, time.Time) {
+	st.Time = metav1.Time{Time: now}
+	st.Component = comp.Name()
+	if st.Name == "" {
+		st.Name = comp.Name()
+	}
+	if st.Health == "" {
+		st.Health = apiv1.HealthStateTypeUnhealthy
+	}
+	st.Reason = apiv1.SyntheticMessagePrefix + st.Reason
+	extra := make(map[string]string, len(st.ExtraInfo)+1)
+	for k, v := range st.ExtraInfo {
+		extra[k] = v
+	}
+	extra[apiv1.SyntheticExtraInfoKey] = "true"
+	st.ExtraInfo = extra
+
+	expiresAt := now.Add(d)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ov, ok := s.overlays[comp.Name()]
+	if !ok {
+		ov = &overlayComponent{Component: comp, nowFunc: s.nowFunc}
+		s.overlays[comp.Name()] = ov
+		s.swap(comp.Name(), ov)
+	}
+	ov.set(apiv1.HealthStates{st}, expiresAt)
+
+	time.AfterFunc(d, func() {
+		s.restore(comp.Name())
+	})
+	return st, expiresAt
+}
+
+// restore puts the original component back in the registry, once the overlay expires.
+func (s *Simulator) restore(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ov, ok := s.overlays[name]
+	if !ok || ov.active() {
+		return
+	}
+	delete(s.overlays, name)
+	s.swap(name, ov.Component)
+	log.Logger.Infow("synthetic health state expired", "component", name)
+}
+
+func (s *Simulator) swap(name string, comp components.Component) {
+	s.registry.Deregister(name)
+	if _, err := s.registry.Register(func(*components.GPUdInstance) (components.Component, error) { return comp, nil }); err != nil {
+		log.Logger.Errorw("failed to swap component", "component", name, "error", err)
+	}
+}
+
+var _ components.Component = &overlayComponent{}
+
+// overlayComponent reports the synthetic health states until the expiry,
+// and delegates everything else to the underlying component.
+type overlayComponent struct {
+	components.Component
+
+	mu      sync.RWMutex
+	states  apiv1.HealthStates
+	until   time.Time
+	nowFunc func() time.Time
+}
+
+func (c *overlayComponent) set(states apiv1.HealthStates, until time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.states = states
+	c.until = until
+}
+
+func (c *overlayComponent) active() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.nowFunc().Before(c.until)
+}
+
+func (c *overlayComponent) LastHealthStates() apiv1.HealthStates {
+	c.mu.RLock()
+	states, until := c.states, c.until
+	c.mu.RUnlock()
+
+	if c.nowFunc().Before(until) {
+		return states
+	}
+	return c.Component.LastHealthStates()
+}
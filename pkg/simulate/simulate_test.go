@@ -0,0 +1,117 @@
+package simulate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	componentsnvidiaxid "github.com/leptonai/gpud/components/accelerator/nvidia/xid"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+type mockComponent struct {
+	components.Component
+
+	name   string
+	states apiv1.HealthStates
+}
+
+func (c *mockComponent) Name() string { return c.name }
+
+func (c *mockComponent) LastHealthStates() apiv1.HealthStates { return c.states }
+
+func TestResolve(t *testing.T) {
+	req, err := Resolve(apiv1.SimulationRequest{Scenario: "xid-63"})
+	require.NoError(t, err)
+	assert.Equal(t, componentsnvidiaxid.Name, req.Component)
+	require.NotNil(t, req.Event)
+	assert.Equal(t, componentsnvidiaxid.EventNameErrorXid, req.Event.Name)
+	assert.Contains(t, req.Event.Message, "63")
+	require.NotNil(t, req.HealthState)
+
+	// overrides the scenario
+	req, err = Resolve(apiv1.SimulationRequest{Scenario: "ib-port-down", Component: "custom"})
+	require.NoError(t, err)
+	assert.Equal(t, "custom", req.Component)
+	assert.Nil(t, req.Event)
+	require.NotNil(t, req.HealthState)
+
+	_, err = Resolve(apiv1.SimulationRequest{Scenario: "unknown"})
+	assert.ErrorIs(t, err, ErrUnknownScenario)
+
+	assert.Contains(t, Scenarios(), "xid-63")
+}
+
+func TestSimulate(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+	store, err := eventstore.New(dbRW, dbRO, 0)
+	require.NoError(t, err)
+
+	comp := &mockComponent{
+		name:   "test",
+		states: apiv1.HealthStates{{Name: "test", Health: apiv1.HealthStateTypeHealthy}},
+	}
+	registry := components.NewRegistry(&components.GPUdInstance{})
+	_, err = registry.Register(func(*components.GPUdInstance) (components.Component, error) { return comp, nil })
+	require.NoError(t, err)
+
+	now := time.Now()
+	s := New(registry, store)
+	s.nowFunc = func() time.Time { return now }
+
+	_, err = s.Simulate(ctx, apiv1.SimulationRequest{})
+	assert.ErrorIs(t, err, ErrComponentRequired)
+	_, err = s.Simulate(ctx, apiv1.SimulationRequest{Component: "test"})
+	assert.ErrorIs(t, err, ErrNothingToInject)
+	_, err = s.Simulate(ctx, apiv1.SimulationRequest{Component: "unknown", Event: &apiv1.Event{Name: "x"}})
+	assert.ErrorIs(t, err, ErrComponentNotFound)
+
+	resp, err := s.Simulate(ctx, apiv1.SimulationRequest{
+		Component:   "test",
+		Event:       &apiv1.Event{Name: "fake", Type: apiv1.EventTypeCritical, Message: "fake failure"},
+		HealthState: &apiv1.HealthState{Health: apiv1.HealthStateTypeUnhealthy, Reason: "fake failure"},
+		Duration:    metav1.Duration{Duration: time.Minute},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Event)
+	assert.Equal(t, "[SYNTHETIC] fake failure", resp.Event.Message)
+	require.NotNil(t, resp.HealthState)
+	assert.True(t, IsSynthetic(*resp.HealthState))
+	assert.Equal(t, now.Add(time.Minute).Unix(), resp.ExpiresAt.Unix())
+
+	// the event is in the component bucket
+	bucket, err := store.Bucket("test")
+	require.NoError(t, err)
+	defer bucket.Close()
+	evs, err := bucket.Get(ctx, now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Len(t, evs, 1)
+	assert.Equal(t, "fake", evs[0].Name)
+	assert.Equal(t, "true", evs[0].ExtraInfo[apiv1.SyntheticExtraInfoKey])
+
+	// the registry reports the synthetic health state
+	states := registry.Get("test").LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, states[0].Health)
+	assert.Equal(t, "[SYNTHETIC] fake failure", states[0].Reason)
+
+	// expired
+	now = now.Add(2 * time.Minute)
+	states = registry.Get("test").LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, states[0].Health)
+
+	s.restore("test")
+	assert.Same(t, comp, registry.Get("test"))
+}
@@ -0,0 +1,114 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// Execer is the subset of *sql.DB and *sql.Tx to execute the statements.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Migration is a forward schema migration.
+type Migration struct {
+	// Version is the schema version after the migration is applied.
+	// Versions must start at 1 and increase by one.
+	Version int
+	// Description describes the schema change.
+	Description string
+	// Up applies the schema change within the transaction.
+	Up func(ctx context.Context, tx *sql.Tx) error
+}
+
+var ErrInvalidMigrations = errors.New("invalid migrations")
+
+// ReadSchemaVersion returns the schema version of the database,
+// stored in the "user_version" header field.
+// Returns 0 for the database that has never been migrated.
+// ref. https://www.sqlite.org/pragma.html#pragma_user_version
+func ReadSchemaVersion(ctx context.Context, db *sql.DB) (int, error) {
+	var v int
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version;").Scan(&v); err != nil {
+		return 0, err
+	}
+	return v, nil
+}
+
+// LatestSchemaVersion returns the schema version after all the migrations are applied.
+func LatestSchemaVersion(migrations []Migration) int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+func validateMigrations(migrations []Migration) error {
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return fmt.Errorf("%w: migration %d has version %d (expected %d)", ErrInvalidMigrations, i, m.Version, i+1)
+		}
+		if m.Up == nil {
+			return fmt.Errorf("%w: migration %d has no up function", ErrInvalidMigrations, m.Version)
+		}
+	}
+	return nil
+}
+
+// Migrate applies the pending migrations in order, each in its own transaction
+// that also bumps the schema version, so a failed migration leaves the database
+// at the last successfully applied version.
+// It returns the schema versions before and after the migrations.
+//
+// The database migrated by a newer version (e.g., after a rollback) is left
+// as is, since the migrations are expected to be backward compatible.
+func Migrate(ctx context.Context, dbRW *sql.DB, migrations []Migration) (int, int, error) {
+	if err := validateMigrations(migrations); err != nil {
+		return 0, 0, err
+	}
+
+	from, err := ReadSchemaVersion(ctx, dbRW)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	latest := LatestSchemaVersion(migrations)
+	if from > latest {
+		log.Logger.Warnw("schema version is newer than the latest known version, skipping migrations", "version", from, "latest", latest)
+		return from, from, nil
+	}
+
+	cur := from
+	for _, m := range migrations[from:] {
+		log.Logger.Infow("applying schema migration", "version", m.Version, "description", m.Description)
+		if err := applyMigration(ctx, dbRW, m); err != nil {
+			return from, cur, fmt.Errorf("failed to apply schema migration %d (%s): %w", m.Version, m.Description, err)
+		}
+		cur = m.Version
+	}
+	return from, cur, nil
+}
+
+func applyMigration(ctx context.Context, dbRW *sql.DB, m Migration) error {
+	tx, err := dbRW.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := m.Up(ctx, tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	// "PRAGMA" does not support the bound parameters
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d;", m.Version)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,98 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTableMigration(version int, table string) Migration {
+	return Migration{
+		Version:     version,
+		Description: "create " + table,
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, "CREATE TABLE "+table+" (id INTEGER PRIMARY KEY)")
+			return err
+		},
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	dbRW, dbRO, cleanup := OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	v, err := ReadSchemaVersion(ctx, dbRW)
+	require.NoError(t, err)
+	assert.Equal(t, 0, v)
+
+	migrations := []Migration{
+		createTableMigration(1, "a"),
+		createTableMigration(2, "b"),
+	}
+	from, to, err := Migrate(ctx, dbRW, migrations)
+	require.NoError(t, err)
+	assert.Equal(t, 0, from)
+	assert.Equal(t, 2, to)
+
+	v, err = ReadSchemaVersion(ctx, dbRO)
+	require.NoError(t, err)
+	assert.Equal(t, 2, v)
+
+	// already applied migrations are not applied again
+	from, to, err = Migrate(ctx, dbRW, migrations)
+	require.NoError(t, err)
+	assert.Equal(t, 2, from)
+	assert.Equal(t, 2, to)
+
+	// failed migration is rolled back with its version
+	failing := Migration{
+		Version:     4,
+		Description: "fail",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, "CREATE TABLE d (id INTEGER PRIMARY KEY)"); err != nil {
+				return err
+			}
+			return errors.New("injected")
+		},
+	}
+	from, to, err = Migrate(ctx, dbRW, append(migrations, createTableMigration(3, "c"), failing))
+	require.Error(t, err)
+	assert.Equal(t, 2, from)
+	assert.Equal(t, 3, to)
+
+	v, err = ReadSchemaVersion(ctx, dbRO)
+	require.NoError(t, err)
+	assert.Equal(t, 3, v)
+
+	exists, err := TableExists(ctx, dbRO, "c")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = TableExists(ctx, dbRO, "d")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// database migrated by a newer version is left as is
+	from, to, err = Migrate(ctx, dbRW, migrations)
+	require.NoError(t, err)
+	assert.Equal(t, 3, from)
+	assert.Equal(t, 3, to)
+}
+
+func TestMigrateInvalid(t *testing.T) {
+	dbRW, _, cleanup := OpenTestDB(t)
+	defer cleanup()
+
+	_, _, err := Migrate(context.Background(), dbRW, []Migration{createTableMigration(2, "a")})
+	assert.ErrorIs(t, err, ErrInvalidMigrations)
+
+	_, _, err = Migrate(context.Background(), dbRW, []Migration{{Version: 1}})
+	assert.ErrorIs(t, err, ErrInvalidMigrations)
+
+	assert.Equal(t, 0, LatestSchemaVersion(nil))
+}
@@ -1,7 +1,8 @@
 package sqlite
 
 type Op struct {
-	readOnly bool
+	readOnly     bool
+	maxOpenConns int
 }
 
 type OpOption func(*Op)
@@ -20,3 +21,14 @@ func WithReadOnly(b bool) OpOption {
 		op.readOnly = b
 	}
 }
+
+// WithMaxOpenConns sets the maximum number of the connections
+// in the read-only pool, so that the concurrent readers share
+// the bounded number of the SQLite handles.
+// Zero or negative uses the default. Ignored for the read-write database,
+// which always uses a single connection.
+func WithMaxOpenConns(n int) OpOption {
+	return func(op *Op) {
+		op.maxOpenConns = n
+	}
+}
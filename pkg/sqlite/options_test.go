@@ -33,6 +33,16 @@ func TestWithReadOnly(t *testing.T) {
 	}
 }
 
+func TestWithMaxOpenConns(t *testing.T) {
+	op := &Op{}
+	if err := op.applyOpts([]OpOption{WithMaxOpenConns(8)}); err != nil {
+		t.Fatalf("applyOpts() unexpected error = %v", err)
+	}
+	if op.maxOpenConns != 8 {
+		t.Errorf("WithMaxOpenConns(8) = %v, want 8", op.maxOpenConns)
+	}
+}
+
 func TestOp_applyOpts(t *testing.T) {
 	t.Run("apply multiple options", func(t *testing.T) {
 		op := &Op{}
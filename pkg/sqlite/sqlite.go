@@ -13,6 +13,10 @@ import (
 	"github.com/leptonai/gpud/pkg/log"
 )
 
+// DefaultMaxReadConns is the default number of the connections
+// in the read-only pool.
+const DefaultMaxReadConns = 4
+
 // Helper function to open a SQLite3 database.
 func Open(file string, opts ...OpOption) (*sql.DB, error) {
 	op := &Op{}
@@ -53,6 +57,16 @@ func Open(file string, opts ...OpOption) (*sql.DB, error) {
 		// to not close
 		db.SetConnMaxLifetime(0)
 		db.SetConnMaxIdleTime(0)
+	} else {
+		// multiple connections for reading, which do not block
+		// the writer in the WAL mode, kept open to reuse
+		maxConns := op.maxOpenConns
+		if maxConns <= 0 {
+			maxConns = DefaultMaxReadConns
+		}
+		db.SetMaxOpenConns(maxConns)
+		db.SetMaxIdleConns(maxConns)
+		db.SetConnMaxLifetime(0)
 	}
 
 	return db, nil
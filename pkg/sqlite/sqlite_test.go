@@ -33,6 +33,23 @@ func TestOpen(t *testing.T) {
 				if _, err = dbRO.Exec("INSERT INTO test (id, name) VALUES (1, 'test')"); err == nil {
 					t.Fatal("expected error when inserting data in read-only mode, got nil")
 				}
+
+				if stats := dbRO.Stats(); stats.MaxOpenConnections != DefaultMaxReadConns {
+					t.Errorf("expected MaxOpenConnections=%d, got %d", DefaultMaxReadConns, stats.MaxOpenConnections)
+				}
+			})
+
+			// Test read-only pool size
+			t.Run("read-only pool size", func(t *testing.T) {
+				dbRO, err := Open(dbFile, WithReadOnly(true), WithMaxOpenConns(8))
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer dbRO.Close()
+
+				if stats := dbRO.Stats(); stats.MaxOpenConnections != 8 {
+					t.Errorf("expected MaxOpenConnections=8, got %d", stats.MaxOpenConnections)
+				}
 			})
 
 			// Test read-write mode
@@ -9,9 +9,9 @@ import (
 	"strings"
 	"time"
 
-	"github.com/leptonai/gpud/pkg/file"
 	"github.com/leptonai/gpud/pkg/log"
 	"github.com/leptonai/gpud/pkg/process"
+	"github.com/leptonai/gpud/pkg/toolpath"
 )
 
 func SystemdExists() bool {
@@ -41,7 +41,7 @@ func DaemonReload(ctx context.Context) ([]byte, error) {
 
 // GetVersion returns the systemd version by running `systemd --version`.
 func GetVersion() (string, []string, error) {
-	systemdPath, err := file.LocateExecutable("systemd")
+	systemdPath, err := toolpath.Locate("systemd")
 	if err != nil {
 		return "", nil, err
 	}
@@ -0,0 +1,125 @@
+// Package telemetry exports the gpud traces and metrics (e.g., the spans and the
+// durations of the component checks) to the OpenTelemetry collector over OTLP/HTTP,
+// to ship them to the existing observability stack of the operators.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+
+	"github.com/leptonai/gpud/version"
+)
+
+const (
+	// ServiceName is the OpenTelemetry service name of gpud.
+	ServiceName = "gpud"
+
+	// DefaultMetricInterval is the interval to export the metrics at.
+	DefaultMetricInterval = time.Minute
+
+	// AttributeMachineID is the resource attribute of the gpud machine ID.
+	AttributeMachineID = attribute.Key("gpud.machine_id")
+)
+
+var ErrInvalidEndpoint = errors.New("invalid otlp endpoint")
+
+// ShutdownFunc flushes the pending traces and metrics, and stops the exporters.
+type ShutdownFunc func(ctx context.Context) error
+
+// Setup sets the global OpenTelemetry tracer and meter providers to export
+// the traces and the metrics to the OTLP/HTTP endpoint, either the URL
+// (e.g., "http://otel-collector:4318") or the "host:port" for HTTPS,
+// with the machine ID as the resource attribute.
+func Setup(ctx context.Context, endpoint string, machineID string) (ShutdownFunc, error) {
+	traceOpts, metricOpts, err := endpointOptions(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(ServiceName),
+			semconv.ServiceVersion(version.Version),
+			AttributeMachineID.String(machineID),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	traceExporter, err := otlptracehttp.New(ctx, traceOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create otlp trace exporter: %w", err)
+	}
+	metricExporter, err := otlpmetrichttp.New(ctx, metricOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create otlp metric exporter: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(traceExporter),
+		sdktrace.WithResource(res),
+	)
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter, sdkmetric.WithInterval(DefaultMetricInterval))),
+		sdkmetric.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetMeterProvider(mp)
+
+	return func(ctx context.Context) error {
+		return errors.Join(tp.Shutdown(ctx), mp.Shutdown(ctx))
+	}, nil
+}
+
+// endpointOptions returns the exporter options of the endpoint,
+// either the URL or the "host:port".
+func endpointOptions(endpoint string) ([]otlptracehttp.Option, []otlpmetrichttp.Option, error) {
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		return nil, nil, fmt.Errorf("%w: empty", ErrInvalidEndpoint)
+	}
+
+	if !strings.Contains(endpoint, "://") {
+		return []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint)},
+			[]otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(endpoint)},
+			nil
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w %q: %v", ErrInvalidEndpoint, endpoint, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, nil, fmt.Errorf("%w %q: must be an http(s) URL or host:port", ErrInvalidEndpoint, endpoint)
+	}
+
+	// the signal paths ("/v1/traces", "/v1/metrics") are appended to the base path
+	// (e.g., the collector behind a reverse proxy prefix)
+	traceOpts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(u.Host)}
+	metricOpts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(u.Host)}
+	if u.Scheme == "http" {
+		traceOpts = append(traceOpts, otlptracehttp.WithInsecure())
+		metricOpts = append(metricOpts, otlpmetrichttp.WithInsecure())
+	}
+	if p := strings.TrimSuffix(u.Path, "/"); p != "" {
+		traceOpts = append(traceOpts, otlptracehttp.WithURLPath(p+"/v1/traces"))
+		metricOpts = append(metricOpts, otlpmetrichttp.WithURLPath(p+"/v1/metrics"))
+	}
+	return traceOpts, metricOpts, nil
+}
@@ -0,0 +1,55 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+)
+
+func TestEndpointOptions(t *testing.T) {
+	for _, endpoint := range []string{
+		"otel-collector:4318",
+		"http://otel-collector:4318",
+		"https://otel.example.com/otlp/",
+	} {
+		traceOpts, metricOpts, err := endpointOptions(endpoint)
+		require.NoError(t, err, endpoint)
+		assert.NotEmpty(t, traceOpts)
+		assert.NotEmpty(t, metricOpts)
+	}
+
+	traceOpts, _, err := endpointOptions("http://otel-collector:4318")
+	require.NoError(t, err)
+	assert.Len(t, traceOpts, 2) // with insecure
+	traceOpts, _, err = endpointOptions("https://otel.example.com/otlp/")
+	require.NoError(t, err)
+	assert.Len(t, traceOpts, 2) // with url path
+
+	for _, endpoint := range []string{"", " ", "grpc://otel-collector:4317", "http://", "http://[::1"} {
+		_, _, err := endpointOptions(endpoint)
+		assert.ErrorIs(t, err, ErrInvalidEndpoint, endpoint)
+	}
+}
+
+func TestSetup(t *testing.T) {
+	origTP, origMP := otel.GetTracerProvider(), otel.GetMeterProvider()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(origTP)
+		otel.SetMeterProvider(origMP)
+	})
+
+	_, err := Setup(context.Background(), "ftp://localhost", "machine-id")
+	assert.ErrorIs(t, err, ErrInvalidEndpoint)
+
+	// the exporters connect lazily
+	shutdown, err := Setup(context.Background(), "http://127.0.0.1:1", "machine-id")
+	require.NoError(t, err)
+	assert.NotEqual(t, origTP, otel.GetTracerProvider())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = shutdown(ctx)
+}
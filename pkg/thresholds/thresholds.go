@@ -0,0 +1,190 @@
+// Package thresholds aggregates the thresholds of the components
+// into one document, to read and update them at once.
+package thresholds
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	componentsecc "github.com/leptonai/gpud/components/accelerator/nvidia/ecc"
+	componentsfallenoffbus "github.com/leptonai/gpud/components/accelerator/nvidia/fallen-off-bus"
+	componentsinfiniband "github.com/leptonai/gpud/components/accelerator/nvidia/infiniband"
+	componentsnvlink "github.com/leptonai/gpud/components/accelerator/nvidia/nvlink"
+	componentstemperature "github.com/leptonai/gpud/components/accelerator/nvidia/temperature"
+	componentsdisk "github.com/leptonai/gpud/components/disk"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	"github.com/leptonai/gpud/pkg/nvidia-query/infiniband"
+)
+
+var ErrInvalidThresholds = errors.New("invalid thresholds")
+
+// Thresholds are the thresholds of all the components.
+// The nil fields are left unchanged on update.
+type Thresholds struct {
+	GPUs        *componentsfallenoffbus.Thresholds `json:"gpus,omitempty"`
+	NVLink      *componentsnvlink.Thresholds       `json:"nvlink,omitempty"`
+	Infiniband  *Infiniband                        `json:"infiniband,omitempty"`
+	Temperature *componentstemperature.Thresholds  `json:"temperature,omitempty"`
+	ECC         *componentsecc.Thresholds          `json:"ecc,omitempty"`
+	Disk        *componentsdisk.Thresholds         `json:"disk,omitempty"`
+}
+
+// Infiniband are the thresholds of the infiniband ports.
+type Infiniband struct {
+	ExpectedPortStates infiniband.ExpectedPortStates `json:"expected_port_states"`
+	Evaluation         infiniband.EvaluationConfig   `json:"evaluation"`
+}
+
+// Get returns the current thresholds of all the components.
+func Get() Thresholds {
+	gpus := componentsfallenoffbus.GetDefaultThresholds()
+	nvlink := componentsnvlink.GetDefaultThresholds()
+	temperature := componentstemperature.GetDefaultThresholds()
+	ecc := componentsecc.GetDefaultThresholds()
+	disk := componentsdisk.GetDefaultThresholds()
+	return Thresholds{
+		GPUs:   &gpus,
+		NVLink: &nvlink,
+		Infiniband: &Infiniband{
+			ExpectedPortStates: componentsinfiniband.GetDefaultExpectedPortStates(),
+			Evaluation:         componentsinfiniband.GetDefaultEvaluationConfig(),
+		},
+		Temperature: &temperature,
+		ECC:         &ecc,
+		Disk:        &disk,
+	}
+}
+
+// Validate validates the thresholds.
+func (t Thresholds) Validate() error {
+	if t.GPUs != nil && t.GPUs.ExpectedGPUs < 0 {
+		return fmt.Errorf("%w: negative expected gpus %d", ErrInvalidThresholds, t.GPUs.ExpectedGPUs)
+	}
+	if t.NVLink != nil && t.NVLink.AtLeastEnabledLinks < 0 {
+		return fmt.Errorf("%w: negative nvlink enabled links %d", ErrInvalidThresholds, t.NVLink.AtLeastEnabledLinks)
+	}
+	if t.Infiniband != nil {
+		if err := t.Infiniband.ExpectedPortStates.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidThresholds, err)
+		}
+		if err := t.Infiniband.Evaluation.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidThresholds, err)
+		}
+	}
+	if t.Disk != nil {
+		if err := t.Disk.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidThresholds, err)
+		}
+	}
+	return nil
+}
+
+// Apply sets the non-nil thresholds as the defaults of the components.
+func (t Thresholds) Apply() {
+	if t.GPUs != nil {
+		componentsfallenoffbus.SetDefaultThresholds(*t.GPUs)
+	}
+	if t.NVLink != nil {
+		componentsnvlink.SetDefaultThresholds(*t.NVLink)
+	}
+	if t.Infiniband != nil {
+		componentsinfiniband.SetDefaultExpectedPortStates(t.Infiniband.ExpectedPortStates)
+		componentsinfiniband.SetDefaultEvaluationConfig(componentsinfiniband.EvaluationConfigSourceThresholds, t.Infiniband.Evaluation)
+	}
+	if t.Temperature != nil {
+		componentstemperature.SetDefaultThresholds(*t.Temperature)
+	}
+	if t.ECC != nil {
+		componentsecc.SetDefaultThresholds(*t.ECC)
+	}
+	if t.Disk != nil {
+		componentsdisk.SetDefaultThresholds(*t.Disk)
+	}
+}
+
+// Merge returns the thresholds with the non-nil fields of the update
+// in place of the current ones.
+func (t Thresholds) Merge(update Thresholds) Thresholds {
+	if update.GPUs != nil {
+		t.GPUs = update.GPUs
+	}
+	if update.NVLink != nil {
+		t.NVLink = update.NVLink
+	}
+	if update.Infiniband != nil {
+		t.Infiniband = update.Infiniband
+	}
+	if update.Temperature != nil {
+		t.Temperature = update.Temperature
+	}
+	if update.ECC != nil {
+		t.ECC = update.ECC
+	}
+	if update.Disk != nil {
+		t.Disk = update.Disk
+	}
+	return t
+}
+
+// updateMu serializes the updates, so that the concurrent updates
+// do not overwrite each other's persisted thresholds.
+var updateMu sync.Mutex
+
+// Update persists the non-nil thresholds, merged into the ones persisted before,
+// and then applies them. Only the updated fields are persisted (not the whole
+// current thresholds), so that the other thresholds keep following the defaults
+// (e.g., the dev mode, the control plane) after the restart.
+// Nil database only applies the thresholds.
+func Update(ctx context.Context, dbRW *sql.DB, t Thresholds) error {
+	updateMu.Lock()
+	defer updateMu.Unlock()
+
+	if dbRW != nil {
+		persisted, err := Read(ctx, dbRW)
+		if err != nil {
+			return err
+		}
+		merged := t
+		if persisted != nil {
+			merged = persisted.Merge(t)
+		}
+		if err := Save(ctx, dbRW, merged); err != nil {
+			return err
+		}
+	}
+
+	t.Apply()
+	return nil
+}
+
+// Read reads the thresholds persisted in the metadata table.
+// It returns nil if no threshold has been persisted.
+func Read(ctx context.Context, dbRO *sql.DB) (*Thresholds, error) {
+	raw, err := pkgmetadata.ReadMetadata(ctx, dbRO, pkgmetadata.MetadataKeyThresholds)
+	if err != nil {
+		return nil, err
+	}
+	if raw == "" {
+		return nil, nil
+	}
+
+	var t Thresholds
+	if err := json.Unmarshal([]byte(raw), &t); err != nil {
+		return nil, fmt.Errorf("failed to parse thresholds: %w", err)
+	}
+	return &t, nil
+}
+
+// Save persists the thresholds to the metadata table,
+// so that the thresholds survive the restarts.
+func Save(ctx context.Context, dbRW *sql.DB, t Thresholds) error {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return pkgmetadata.SetMetadata(ctx, dbRW, pkgmetadata.MetadataKeyThresholds, string(b))
+}
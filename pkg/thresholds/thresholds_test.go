@@ -0,0 +1,104 @@
+package thresholds
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	componentsecc "github.com/leptonai/gpud/components/accelerator/nvidia/ecc"
+	componentsnvlink "github.com/leptonai/gpud/components/accelerator/nvidia/nvlink"
+	componentstemperature "github.com/leptonai/gpud/components/accelerator/nvidia/temperature"
+	componentsdisk "github.com/leptonai/gpud/components/disk"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	"github.com/leptonai/gpud/pkg/nvidia-query/infiniband"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Thresholds{}.Validate())
+	assert.NoError(t, Thresholds{
+		Infiniband: &Infiniband{ExpectedPortStates: infiniband.ExpectedPortStates{AtLeastPorts: 8, AtLeastRate: 400}},
+		Disk:       &componentsdisk.Thresholds{MaxUsedPercent: 90},
+	}.Validate())
+
+	for _, th := range []Thresholds{
+		{Infiniband: &Infiniband{ExpectedPortStates: infiniband.ExpectedPortStates{AtLeastPorts: -1}}},
+		{Infiniband: &Infiniband{Evaluation: infiniband.EvaluationConfig{DropDuration: metav1.Duration{Duration: -time.Minute}}}},
+		{Disk: &componentsdisk.Thresholds{MaxUsedPercent: 101}},
+	} {
+		assert.True(t, errors.Is(th.Validate(), ErrInvalidThresholds))
+	}
+}
+
+func TestApply(t *testing.T) {
+	orig := Get()
+	t.Cleanup(orig.Apply)
+
+	Thresholds{
+		Temperature: &componentstemperature.Thresholds{GPUCoreMaxCelsius: 85},
+		ECC:         &componentsecc.Thresholds{VolatileUncorrectedMax: 1},
+	}.Apply()
+
+	got := Get()
+	assert.Equal(t, uint32(85), got.Temperature.GPUCoreMaxCelsius)
+	assert.Equal(t, uint64(1), got.ECC.VolatileUncorrectedMax)
+	// the nil fields are left unchanged
+	assert.Equal(t, orig.Infiniband, got.Infiniband)
+	assert.Equal(t, orig.Disk, got.Disk)
+}
+
+func TestReadSave(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	require.NoError(t, pkgmetadata.CreateTableMetadata(ctx, dbRW))
+
+	th, err := Read(ctx, dbRO)
+	require.NoError(t, err)
+	assert.Nil(t, th)
+
+	saved := Thresholds{Disk: &componentsdisk.Thresholds{MaxUsedPercent: 90}}
+	require.NoError(t, Save(ctx, dbRW, saved))
+	th, err = Read(ctx, dbRO)
+	require.NoError(t, err)
+	require.NotNil(t, th)
+	assert.Equal(t, saved, *th)
+}
+
+func TestUpdate(t *testing.T) {
+	orig := Get()
+	t.Cleanup(orig.Apply)
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	require.NoError(t, pkgmetadata.CreateTableMetadata(ctx, dbRW))
+
+	disk := &componentsdisk.Thresholds{MaxUsedPercent: 90}
+	require.NoError(t, Update(ctx, dbRW, Thresholds{Disk: disk}))
+	assert.Equal(t, disk, Get().Disk)
+
+	// only the updated fields are persisted, merged into the ones persisted before
+	nvlink := &componentsnvlink.Thresholds{AtLeastEnabledLinks: 4}
+	require.NoError(t, Update(ctx, dbRW, Thresholds{NVLink: nvlink}))
+	th, err := Read(ctx, dbRO)
+	require.NoError(t, err)
+	require.NotNil(t, th)
+	assert.Equal(t, Thresholds{Disk: disk, NVLink: nvlink}, *th)
+
+	// nil database only applies
+	disk = &componentsdisk.Thresholds{MaxUsedPercent: 80}
+	require.NoError(t, Update(ctx, nil, Thresholds{Disk: disk}))
+	assert.Equal(t, disk, Get().Disk)
+}
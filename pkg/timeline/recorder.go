@@ -0,0 +1,204 @@
+package timeline
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// BucketName is the name of the event store bucket for the health transitions.
+const BucketName = "health-transitions"
+
+// DefaultRecordInterval is the default interval to poll the health states of the components.
+const DefaultRecordInterval = 10 * time.Second
+
+const (
+	// the event store does not persist the component name of the events
+	extraInfoKeyComponent      = "component"
+	extraInfoKeyPreviousHealth = "previous_health"
+)
+
+// Recorder polls the last health states of all the components
+// and persists their transitions, since the components only keep
+// the last health states in memory.
+type Recorder struct {
+	registry  components.Registry
+	bucket    eventstore.Bucket
+	retention time.Duration
+
+	mu sync.Mutex
+	// last health state per component and health state name
+	last map[transitionKey]apiv1.HealthStateType
+	// listeners are called with each transition once persisted
+	listeners []func(ctx context.Context, t Transition)
+}
+
+// Transition is the health transition of a health state of a component.
+type Transition struct {
+	Time      time.Time
+	Component string
+	// From is the previous health, empty if first observed (not healthy).
+	From  apiv1.HealthStateType
+	State apiv1.HealthState
+}
+
+type transitionKey struct {
+	component string
+	name      string
+}
+
+// NewRecorder creates a new health transition recorder that persists the
+// transitions in the bucket, purging the ones older than the retention.
+// Zero retention disables the purge.
+func NewRecorder(registry components.Registry, bucket eventstore.Bucket, retention time.Duration) *Recorder {
+	return &Recorder{
+		registry:  registry,
+		bucket:    bucket,
+		retention: retention,
+		last:      make(map[transitionKey]apiv1.HealthStateType),
+	}
+}
+
+// OnTransition registers the function called with each health transition once
+// persisted, so that the transition already persisted before the restart is not
+// notified again. The function is called with the lock held, thus must not block.
+func (r *Recorder) OnTransition(fn func(ctx context.Context, t Transition)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, fn)
+}
+
+// Start loads the last recorded transitions to resume from,
+// and starts polling the components in the background until the context is canceled.
+func (r *Recorder) Start(ctx context.Context, interval time.Duration) error {
+	if err := r.load(ctx); err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			now := time.Now().UTC()
+			if err := r.record(ctx, now); err != nil {
+				log.Logger.Warnw("failed to record health transitions", "error", err)
+			}
+			if r.retention > 0 {
+				if _, err := r.bucket.Purge(ctx, now.Add(-r.retention).Unix()); err != nil {
+					log.Logger.Warnw("failed to purge health transitions", "error", err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// load resumes from the latest transition per health state, in case of the restarts.
+func (r *Recorder) load(ctx context.Context) error {
+	evs, err := r.bucket.Get(ctx, time.Time{})
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// latest event first
+	for _, ev := range evs {
+		key := transitionKey{component: ev.ExtraInfo[extraInfoKeyComponent], name: ev.Name}
+		if _, ok := r.last[key]; !ok {
+			r.last[key] = apiv1.HealthStateType(ev.Type)
+		}
+	}
+	return nil
+}
+
+// record compares the last health states of all the components against the
+// previously observed ones, and persists the changes. The first observation of
+// a health state is only recorded if it is not healthy.
+func (r *Recorder) record(ctx context.Context, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, comp := range r.registry.All() {
+		for _, st := range comp.LastHealthStates() {
+			if st.Health == "" {
+				continue
+			}
+			component := st.Component
+			if component == "" {
+				component = comp.Name()
+			}
+			key := transitionKey{component: component, name: st.Name}
+
+			prev, ok := r.last[key]
+			if prev == st.Health || (!ok && st.Health == apiv1.HealthStateTypeHealthy) {
+				r.last[key] = st.Health
+				continue
+			}
+
+			ev := eventstore.Event{
+				Component: component,
+				Time:      now,
+				Name:      st.Name,
+				Type:      string(st.Health),
+				Message:   st.Reason,
+				ExtraInfo: map[string]string{
+					extraInfoKeyComponent:      component,
+					extraInfoKeyPreviousHealth: string(prev),
+				},
+			}
+			if err := r.bucket.Insert(ctx, ev); err != nil {
+				return err
+			}
+			r.last[key] = st.Health
+
+			for _, fn := range r.listeners {
+				fn(ctx, Transition{Time: now, Component: component, From: prev, State: st})
+			}
+		}
+	}
+	return nil
+}
+
+// Transitions returns the recorded health transitions since the time (inclusive),
+// in the descending order of the time.
+func (r *Recorder) Transitions(ctx context.Context, since time.Time) ([]apiv1.TimelineEntry, error) {
+	if r == nil {
+		return nil, nil
+	}
+
+	// the event store query excludes the events at the time, in unix seconds
+	evs, err := r.bucket.Get(ctx, since.Add(-time.Second))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]apiv1.TimelineEntry, 0, len(evs))
+	for _, ev := range evs {
+		entries = append(entries, apiv1.TimelineEntry{
+			Time:           metav1.NewTime(ev.Time),
+			Kind:           apiv1.TimelineEntryKindHealthTransition,
+			Component:      ev.ExtraInfo[extraInfoKeyComponent],
+			Name:           ev.Name,
+			Health:         apiv1.HealthStateType(ev.Type),
+			PreviousHealth: apiv1.HealthStateType(ev.ExtraInfo[extraInfoKeyPreviousHealth]),
+			Message:        ev.Message,
+		})
+	}
+	return entries, nil
+}
@@ -0,0 +1,157 @@
+// Package timeline merges the events, the health transitions, and the key metric
+// breaches from all the components into a single chronologically ordered view,
+// to reconstruct what happened in an incident window.
+package timeline
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+// BreachRule defines the threshold of a key metric,
+// which is breached when the value exceeds the threshold.
+type BreachRule struct {
+	MetricName string
+	Threshold  float64
+}
+
+// DefaultBreachRules are the key metrics that usually precede or indicate the incidents.
+var DefaultBreachRules = []BreachRule{
+	{MetricName: "accelerator_nvidia_temperature_slowdown_used_percent", Threshold: 95},
+	{MetricName: "accelerator_nvidia_clock_hw_slowdown", Threshold: 0},
+	{MetricName: "accelerator_nvidia_remapped_rows_remapping_failed", Threshold: 0},
+	{MetricName: "accelerator_nvidia_ecc_volatile_total_uncorrected", Threshold: 0},
+	{MetricName: "memory_used_percent", Threshold: 95},
+	{MetricName: "os_fd_allocated_file_handles_percent", Threshold: 95},
+}
+
+// MetricNames returns the metric names of the rules.
+func MetricNames(rules []BreachRule) []string {
+	names := make([]string, 0, len(rules))
+	for _, r := range rules {
+		names = append(names, r.MetricName)
+	}
+	return names
+}
+
+// FindBreaches returns an entry per series (the component, the metric name, and the labels)
+// each time the series starts exceeding its threshold, rather than per data point.
+func FindBreaches(ms pkgmetrics.Metrics, rules []BreachRule) []apiv1.TimelineEntry {
+	thresholds := make(map[string]float64, len(rules))
+	for _, r := range rules {
+		thresholds[r.MetricName] = r.Threshold
+	}
+
+	sorted := make(pkgmetrics.Metrics, 0, len(ms))
+	for _, m := range ms {
+		if _, ok := thresholds[m.Name]; ok {
+			sorted = append(sorted, m)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].UnixMilliseconds < sorted[j].UnixMilliseconds
+	})
+
+	var entries []apiv1.TimelineEntry
+	breaching := make(map[string]bool)
+	for _, m := range sorted {
+		threshold := thresholds[m.Name]
+		key := seriesKey(m)
+		if m.Value <= threshold {
+			breaching[key] = false
+			continue
+		}
+		if breaching[key] {
+			continue
+		}
+		breaching[key] = true
+
+		entries = append(entries, apiv1.TimelineEntry{
+			Time:      metav1.NewTime(time.UnixMilli(m.UnixMilliseconds).UTC()),
+			Kind:      apiv1.TimelineEntryKindMetricBreach,
+			Component: m.Component,
+			Name:      m.Name,
+			Value:     m.Value,
+			Threshold: threshold,
+			Message:   fmt.Sprintf("%s %g exceeded the threshold %g", m.Name, m.Value, threshold),
+			Labels:    m.Labels,
+		})
+	}
+	return entries
+}
+
+func seriesKey(m pkgmetrics.Metric) string {
+	keys := make([]string, 0, len(m.Labels))
+	for k := range m.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString(m.Component)
+	sb.WriteString("/")
+	sb.WriteString(m.Name)
+	for _, k := range keys {
+		sb.WriteString(",")
+		sb.WriteString(k)
+		sb.WriteString("=")
+		sb.WriteString(m.Labels[k])
+	}
+	return sb.String()
+}
+
+// EventEntries converts the events of the component into the timeline entries.
+func EventEntries(component string, evs apiv1.Events) []apiv1.TimelineEntry {
+	entries := make([]apiv1.TimelineEntry, 0, len(evs))
+	for _, ev := range evs {
+		if ev.Component != "" {
+			component = ev.Component
+		}
+		entries = append(entries, apiv1.TimelineEntry{
+			Time:      ev.Time,
+			Kind:      apiv1.TimelineEntryKindEvent,
+			Component: component,
+			Name:      ev.Name,
+			EventType: ev.Type,
+			Message:   ev.Message,
+			Labels:    ev.Labels,
+		})
+	}
+	return entries
+}
+
+// Merge merges the entries in the time range [from, to] in the ascending order of the time.
+// If the number of the entries exceeds the positive limit, only the latest entries are kept.
+func Merge(from time.Time, to time.Time, limit int, entries ...[]apiv1.TimelineEntry) apiv1.Timeline {
+	tl := apiv1.Timeline{
+		From:    from,
+		To:      to,
+		Entries: []apiv1.TimelineEntry{},
+	}
+	for _, es := range entries {
+		for _, e := range es {
+			if e.Time.Time.Before(from) || e.Time.Time.After(to) {
+				continue
+			}
+			tl.Entries = append(tl.Entries, e)
+		}
+	}
+
+	// stable to keep the order of the entries at the same time
+	sort.SliceStable(tl.Entries, func(i, j int) bool {
+		return tl.Entries[i].Time.Time.Before(tl.Entries[j].Time.Time)
+	})
+
+	if limit > 0 && len(tl.Entries) > limit {
+		tl.Entries = tl.Entries[len(tl.Entries)-limit:]
+		tl.Truncated = true
+	}
+	return tl
+}
@@ -0,0 +1,189 @@
+package timeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestFindBreaches(t *testing.T) {
+	base := time.Unix(1700000000, 0)
+	at := func(minutes int) int64 {
+		return base.Add(time.Duration(minutes) * time.Minute).UnixMilli()
+	}
+	gpu0 := map[string]string{"uuid": "GPU-0"}
+	gpu1 := map[string]string{"uuid": "GPU-1"}
+
+	ms := pkgmetrics.Metrics{
+		// out of order, to be sorted
+		{UnixMilliseconds: at(3), Name: "temp", Value: 99, Labels: gpu0},
+		{UnixMilliseconds: at(0), Name: "temp", Value: 50, Labels: gpu0},
+		{UnixMilliseconds: at(1), Name: "temp", Value: 96, Labels: gpu0},
+		{UnixMilliseconds: at(2), Name: "temp", Value: 80, Labels: gpu0},
+		{UnixMilliseconds: at(1), Name: "temp", Value: 97, Labels: gpu1},
+		{UnixMilliseconds: at(2), Name: "temp", Value: 98, Labels: gpu1},
+		{UnixMilliseconds: at(1), Name: "other", Value: 1000},
+	}
+
+	entries := FindBreaches(ms, []BreachRule{{MetricName: "temp", Threshold: 95}})
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, at(1), entries[0].Time.UnixMilli())
+	assert.Equal(t, "GPU-0", entries[0].Labels["uuid"])
+	assert.Equal(t, apiv1.TimelineEntryKindMetricBreach, entries[0].Kind)
+	assert.Equal(t, float64(95), entries[0].Threshold)
+
+	assert.Equal(t, at(1), entries[1].Time.UnixMilli())
+	assert.Equal(t, "GPU-1", entries[1].Labels["uuid"])
+
+	// breached again after recovering
+	assert.Equal(t, at(3), entries[2].Time.UnixMilli())
+	assert.Equal(t, "GPU-0", entries[2].Labels["uuid"])
+	assert.Equal(t, float64(99), entries[2].Value)
+}
+
+func TestMerge(t *testing.T) {
+	base := time.Unix(1700000000, 0).UTC()
+	entry := func(minutes int, name string) apiv1.TimelineEntry {
+		return apiv1.TimelineEntry{Time: metav1.NewTime(base.Add(time.Duration(minutes) * time.Minute)), Name: name}
+	}
+
+	tl := Merge(base, base.Add(10*time.Minute), 0,
+		[]apiv1.TimelineEntry{entry(5, "b"), entry(-1, "before"), entry(1, "a")},
+		[]apiv1.TimelineEntry{entry(11, "after"), entry(5, "c"), entry(10, "d")},
+	)
+	names := []string{}
+	for _, e := range tl.Entries {
+		names = append(names, e.Name)
+	}
+	assert.Equal(t, []string{"a", "b", "c", "d"}, names)
+	assert.False(t, tl.Truncated)
+
+	tl = Merge(base, base.Add(10*time.Minute), 2, []apiv1.TimelineEntry{entry(1, "a"), entry(2, "b"), entry(3, "c")})
+	require.Len(t, tl.Entries, 2)
+	assert.Equal(t, "b", tl.Entries[0].Name)
+	assert.True(t, tl.Truncated)
+
+	tl = Merge(base, base.Add(10*time.Minute), 0)
+	assert.NotNil(t, tl.Entries)
+	assert.Empty(t, tl.Entries)
+}
+
+func TestEventEntries(t *testing.T) {
+	entries := EventEntries("comp", apiv1.Events{
+		{Name: "a", Type: apiv1.EventTypeWarning, Message: "msg"},
+		{Component: "other", Name: "b"},
+	})
+	require.Len(t, entries, 2)
+	assert.Equal(t, "comp", entries[0].Component)
+	assert.Equal(t, apiv1.EventTypeWarning, entries[0].EventType)
+	assert.Equal(t, "msg", entries[0].Message)
+	assert.Equal(t, "other", entries[1].Component)
+}
+
+type mockComponent struct {
+	components.Component
+
+	name   string
+	states apiv1.HealthStates
+}
+
+func (c *mockComponent) Name() string { return c.name }
+
+func (c *mockComponent) LastHealthStates() apiv1.HealthStates { return c.states }
+
+func TestRecorder(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store, err := eventstore.New(dbRW, dbRO, 0)
+	require.NoError(t, err)
+	bucket, err := store.Bucket(BucketName, eventstore.WithDisablePurge())
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	comp := &mockComponent{name: "comp"}
+	registry := components.NewRegistry(&components.GPUdInstance{})
+	_, err = registry.Register(func(*components.GPUdInstance) (components.Component, error) { return comp, nil })
+	require.NoError(t, err)
+
+	r := NewRecorder(registry, bucket, 0)
+	require.NoError(t, r.load(ctx))
+	var notified []Transition
+	r.OnTransition(func(_ context.Context, tr Transition) {
+		notified = append(notified, tr)
+	})
+
+	base := time.Unix(1700000000, 0).UTC()
+
+	// first observation of a healthy state is not recorded
+	comp.states = apiv1.HealthStates{{Name: "a", Health: apiv1.HealthStateTypeHealthy}, {Name: "b", Health: apiv1.HealthStateTypeUnhealthy, Reason: "down"}}
+	require.NoError(t, r.record(ctx, base))
+
+	// unchanged
+	require.NoError(t, r.record(ctx, base.Add(time.Minute)))
+
+	comp.states = apiv1.HealthStates{{Name: "a", Health: apiv1.HealthStateTypeDegraded}, {Name: "b", Health: apiv1.HealthStateTypeHealthy}}
+	require.NoError(t, r.record(ctx, base.Add(2*time.Minute)))
+
+	entries, err := r.Transitions(ctx, base)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	// the listeners are notified of the persisted transitions
+	require.Len(t, notified, 3)
+	assert.Equal(t, "comp", notified[0].Component)
+	assert.Equal(t, "b", notified[0].State.Name)
+	assert.Empty(t, notified[0].From)
+	assert.Equal(t, base, notified[0].Time)
+
+	// latest first
+	byName := map[string][]apiv1.TimelineEntry{}
+	for _, e := range entries {
+		assert.Equal(t, "comp", e.Component)
+		assert.Equal(t, apiv1.TimelineEntryKindHealthTransition, e.Kind)
+		byName[e.Name] = append(byName[e.Name], e)
+	}
+	require.Len(t, byName["a"], 1)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, byName["a"][0].PreviousHealth)
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, byName["a"][0].Health)
+	require.Len(t, byName["b"], 2)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, byName["b"][0].Health)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, byName["b"][0].PreviousHealth)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, byName["b"][1].Health)
+	assert.Empty(t, byName["b"][1].PreviousHealth)
+	assert.Equal(t, "down", byName["b"][1].Message)
+
+	// resumes from the persisted transitions after restart,
+	// without notifying them again
+	r2 := NewRecorder(registry, bucket, 0)
+	require.NoError(t, r2.load(ctx))
+	notified = nil
+	r2.OnTransition(func(_ context.Context, tr Transition) {
+		notified = append(notified, tr)
+	})
+	require.NoError(t, r2.record(ctx, base.Add(3*time.Minute)))
+	entries, err = r2.Transitions(ctx, base)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+	assert.Empty(t, notified)
+
+	// nil recorder returns no transitions
+	var nilRecorder *Recorder
+	entries, err = nilRecorder.Transitions(ctx, base)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
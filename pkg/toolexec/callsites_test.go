@@ -0,0 +1,83 @@
+package toolexec
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// allowedProcessCallSites are the files running the processes directly with the "process" or
+// the "os/exec" package, rather than the external tools with Run (e.g., the long-running log streams,
+// the host reboot, the systemd units of gpud itself, the driver installation).
+// Do not add the tools run by the components here, use Run (or NewExclusiveRunner for the scripts) instead.
+var allowedProcessCallSites = map[string]bool{
+	"cmd/gpud/driver/command.go":                                  true,
+	"components/accelerator/nvidia/fabric-manager/log_watcher.go": true,
+	"pkg/gpud-manager/controllers/package_controller.go":          true,
+	"pkg/gpud-manager/informer/file_informer.go":                  true,
+	"pkg/host/machine_id.go":                                      true,
+	"pkg/host/reboot.go":                                          true,
+	"pkg/host/stop.go":                                            true,
+	"pkg/host/virtualization_environment.go":                      true,
+	"pkg/nvidia-driver/install.go":                                true,
+	"pkg/systemd/systemd.go":                                      true,
+	"pkg/update/system.go":                                        true,
+}
+
+// processCallPatterns are the calls starting the processes bypassing Run.
+var processCallPatterns = []string{
+	"process.New(",
+	"process.NewExclusiveRunner(",
+	"exec.Command(",
+	"exec.CommandContext(",
+}
+
+// TestNoNewProcessCallSites fails if the external tools are run with "process.New" or "os/exec",
+// bypassing the timeouts, the output caps, the environment scrubbing, the resource limits, and the metrics of Run.
+func TestNoNewProcessCallSites(t *testing.T) {
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	require.NoError(t, err)
+
+	var found []string
+	err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case ".git", "vendor", "testdata":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if strings.HasPrefix(rel, "pkg/process/") || strings.HasPrefix(rel, "pkg/toolexec/") || allowedProcessCallSites[rel] {
+			return nil
+		}
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, p := range processCallPatterns {
+			if strings.Contains(string(b), p) {
+				found = append(found, rel+" ("+strings.TrimSuffix(p, "(")+")")
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Empty(t, found, "run the external tools with toolexec.Run, not process.New or os/exec")
+}
@@ -0,0 +1,382 @@
+package toolexec
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/dustin/go-humanize"
+	"golang.org/x/sys/unix"
+
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/toolpath"
+)
+
+// ResourceLimits caps the CPU and the memory of the tool (and its children),
+// in the transient cgroup created for each execution.
+type ResourceLimits struct {
+	// CPUs is the number of the CPUs the tool may use (e.g., 0.5 for the half of a CPU).
+	// Zero for no CPU cap.
+	CPUs float64 `json:"cpus,omitempty"`
+	// MemoryMaxBytes is the memory the tool may use, beyond which the tool is OOM killed.
+	// Zero for no memory cap.
+	MemoryMaxBytes uint64 `json:"memory_max_bytes,omitempty"`
+}
+
+// AllComponents is the key of the resource limits of the components without their own limits.
+const AllComponents = "*"
+
+var (
+	ErrInvalidResourceLimits = errors.New("invalid resource limits")
+	ErrOOMKilled             = errors.New("command killed for exceeding the memory max")
+)
+
+func (l ResourceLimits) IsZero() bool {
+	return l.CPUs == 0 && l.MemoryMaxBytes == 0
+}
+
+func (l ResourceLimits) Validate() error {
+	if l.CPUs < 0 {
+		return fmt.Errorf("%w: negative cpus %v", ErrInvalidResourceLimits, l.CPUs)
+	}
+	return nil
+}
+
+// ParseResourceLimits parses the comma-separated resource limits per component,
+// in the format of "<component>=<cpus>:<memory max>" with either side optional
+// (e.g., "accelerator-nvidia-infiniband=0.5:512MiB,*=1:").
+func ParseResourceLimits(s string) (map[string]ResourceLimits, error) {
+	m := make(map[string]ResourceLimits)
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		component, v, ok := strings.Cut(field, "=")
+		component = strings.TrimSpace(component)
+		if !ok || component == "" {
+			return nil, fmt.Errorf("%w: %q (expected <component>=<cpus>:<memory max>)", ErrInvalidResourceLimits, field)
+		}
+		cpus, mem, _ := strings.Cut(v, ":")
+
+		var l ResourceLimits
+		if cpus = strings.TrimSpace(cpus); cpus != "" {
+			f, err := strconv.ParseFloat(cpus, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid cpus %q: %v", ErrInvalidResourceLimits, cpus, err)
+			}
+			l.CPUs = f
+		}
+		if mem = strings.TrimSpace(mem); mem != "" {
+			b, err := humanize.ParseBytes(mem)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid memory max %q: %v", ErrInvalidResourceLimits, mem, err)
+			}
+			l.MemoryMaxBytes = b
+		}
+		if err := l.Validate(); err != nil {
+			return nil, err
+		}
+		m[component] = l
+	}
+	return m, nil
+}
+
+var (
+	limitsMu sync.RWMutex
+	limits   map[string]ResourceLimits
+)
+
+// SetResourceLimits replaces the resource limits of the tools run by each component.
+// The tools run by the components without the limits (and without the AllComponents limits) are not capped.
+func SetResourceLimits(m map[string]ResourceLimits) error {
+	copied := make(map[string]ResourceLimits, len(m))
+	for component, l := range m {
+		if err := l.Validate(); err != nil {
+			return fmt.Errorf("component %q: %w", component, err)
+		}
+		if !l.IsZero() {
+			copied[component] = l
+		}
+	}
+
+	limitsMu.Lock()
+	limits = copied
+	limitsMu.Unlock()
+	return nil
+}
+
+func resourceLimitsOf(component string) (ResourceLimits, bool) {
+	limitsMu.RLock()
+	defer limitsMu.RUnlock()
+
+	if l, ok := limits[component]; ok && component != "" {
+		return l, true
+	}
+	l, ok := limits[AllComponents]
+	return l, ok
+}
+
+type componentKey struct{}
+
+// WithComponent returns the context of the component, so that the tools run with the context
+// are capped by the resource limits of the component.
+func WithComponent(ctx context.Context, component string) context.Context {
+	return context.WithValue(ctx, componentKey{}, component)
+}
+
+// ComponentFromContext returns the component of the context, or empty if not set.
+func ComponentFromContext(ctx context.Context) string {
+	component, _ := ctx.Value(componentKey{}).(string)
+	return component
+}
+
+const (
+	// DefaultCgroupRoot is the mount point of the cgroup v2 unified hierarchy.
+	DefaultCgroupRoot = "/sys/fs/cgroup"
+
+	// cgroupDaemonLeaf is the leaf cgroup gpud moves its own processes to, in its delegated cgroup,
+	// since the cgroup v2 does not allow the processes in the cgroups with the controllers
+	// enabled for the child cgroups.
+	cgroupDaemonLeaf = "daemon"
+	// cgroupParent is the parent of the transient cgroups, in the delegated cgroup of gpud.
+	cgroupParent = "tools"
+
+	// cgroupControllers are the controllers enabled for the transient cgroups.
+	cgroupControllers = "+cpu +memory"
+
+	// cpuPeriodMicros is the default CPU bandwidth period of the cgroup v2.
+	cpuPeriodMicros = 100000
+)
+
+// ErrCgroupNotDelegated is returned if the cgroup of gpud is not delegated to gpud
+// (e.g., not run by the systemd unit with "Delegate=yes"), in which case the transient cgroups
+// are not created in the cgroup tree managed by systemd.
+var ErrCgroupNotDelegated = errors.New("cgroup of gpud not delegated")
+
+var (
+	cgroupRoot     = DefaultCgroupRoot
+	procSelfCgroup = "/proc/self/cgroup"
+	cgroupSeq      atomic.Uint64
+
+	// isDelegatedFunc returns true if the cgroup directory is delegated to gpud.
+	isDelegatedFunc = isDelegated
+
+	invalidCgroupChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+	parentMu       sync.Mutex
+	parentResolved bool
+	parentPath     string
+	parentErr      error
+)
+
+// toolsCgroupParent returns the parent of the transient cgroups,
+// setting up the delegated cgroup of gpud on the first call.
+func toolsCgroupParent() (string, error) {
+	parentMu.Lock()
+	defer parentMu.Unlock()
+
+	if !parentResolved {
+		parentPath, parentErr = setupDelegatedCgroup(cgroupRoot, procSelfCgroup)
+		parentResolved = true
+		if parentErr != nil {
+			log.Logger.Warnw("not creating the transient cgroups of the tools in the cgroup of gpud", "error", parentErr)
+		} else {
+			log.Logger.Infow("creating the transient cgroups of the tools in the delegated cgroup of gpud", "parent", parentPath)
+		}
+	}
+	return parentPath, parentErr
+}
+
+// setupDelegatedCgroup moves the processes of the delegated cgroup of gpud to its leaf cgroup,
+// and creates the parent of the transient cgroups next to the leaf, with the controllers enabled.
+// e.g., "/sys/fs/cgroup/runtime.slice/gpud.service/{daemon,tools}".
+func setupDelegatedCgroup(root string, selfCgroupFile string) (string, error) {
+	own, err := readOwnCgroup(selfCgroupFile)
+	if err != nil {
+		return "", err
+	}
+	// already moved to the leaf
+	if filepath.Base(own) == cgroupDaemonLeaf {
+		own = filepath.Dir(own)
+	}
+	if own == "/" {
+		return "", fmt.Errorf("%w: gpud runs in the root cgroup", ErrCgroupNotDelegated)
+	}
+
+	ownDir := filepath.Join(root, own)
+	if !isDelegatedFunc(ownDir) {
+		return "", fmt.Errorf("%w: %s (set \"Delegate=yes\" in the gpud systemd unit)", ErrCgroupNotDelegated, ownDir)
+	}
+
+	leaf := filepath.Join(ownDir, cgroupDaemonLeaf)
+	if err := os.MkdirAll(leaf, 0755); err != nil {
+		return "", err
+	}
+	b, err := os.ReadFile(filepath.Join(ownDir, "cgroup.procs"))
+	if err != nil {
+		return "", err
+	}
+	for _, pid := range strings.Fields(string(b)) {
+		// the process may have exited
+		if err := os.WriteFile(filepath.Join(leaf, "cgroup.procs"), []byte(pid), 0644); err != nil && !errors.Is(err, syscall.ESRCH) {
+			return "", fmt.Errorf("failed to move process %s to %s: %w", pid, leaf, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(ownDir, "cgroup.subtree_control"), []byte(cgroupControllers), 0644); err != nil {
+		return "", fmt.Errorf("failed to enable the cgroup controllers: %w", err)
+	}
+
+	parent := filepath.Join(ownDir, cgroupParent)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(parent, "cgroup.subtree_control"), []byte(cgroupControllers), 0644); err != nil {
+		return "", fmt.Errorf("failed to enable the cgroup controllers: %w", err)
+	}
+	return parent, nil
+}
+
+// readOwnCgroup returns the cgroup v2 path of gpud from "/proc/self/cgroup"
+// (e.g., "0::/runtime.slice/gpud.service").
+func readOwnCgroup(selfCgroupFile string) (string, error) {
+	b, err := os.ReadFile(selfCgroupFile)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if p, ok := strings.CutPrefix(strings.TrimSpace(line), "0::"); ok && p != "" {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("%w: no cgroup v2 in %s", ErrCgroupNotDelegated, selfCgroupFile)
+}
+
+// isDelegated returns true if systemd marked the cgroup as delegated ("Delegate=yes").
+// ref. https://systemd.io/CGROUP_DELEGATION/
+func isDelegated(dir string) bool {
+	for _, attr := range []string{"trusted.delegate", "user.delegate"} {
+		buf := make([]byte, 8)
+		n, err := unix.Getxattr(dir, attr, buf)
+		if err == nil && string(buf[:n]) == "1" {
+			return true
+		}
+	}
+	return false
+}
+
+// transientCgroup is the cgroup the tool is started in, removed after the tool exits.
+type transientCgroup struct {
+	path string
+	dir  *os.File
+}
+
+// createCgroup creates the transient cgroup under the parent with the controllers enabled.
+func createCgroup(parent string, component string, l ResourceLimits) (*transientCgroup, error) {
+	if component == "" {
+		component = "unknown"
+	}
+	name := fmt.Sprintf("%s-%d-%d", invalidCgroupChars.ReplaceAllString(component, "_"), os.Getpid(), cgroupSeq.Add(1))
+	cg := &transientCgroup{path: filepath.Join(parent, name)}
+	if err := os.Mkdir(cg.path, 0755); err != nil {
+		return nil, err
+	}
+
+	if err := cg.setLimits(l); err != nil {
+		cg.remove()
+		return nil, err
+	}
+
+	dir, err := os.Open(cg.path)
+	if err != nil {
+		cg.remove()
+		return nil, err
+	}
+	cg.dir = dir
+	return cg, nil
+}
+
+func (cg *transientCgroup) setLimits(l ResourceLimits) error {
+	if l.CPUs > 0 {
+		quota := max(int64(l.CPUs*cpuPeriodMicros), 1000)
+		if err := os.WriteFile(filepath.Join(cg.path, "cpu.max"), []byte(fmt.Sprintf("%d %d", quota, cpuPeriodMicros)), 0644); err != nil {
+			return fmt.Errorf("failed to set cpu.max: %w", err)
+		}
+	}
+	if l.MemoryMaxBytes > 0 {
+		if err := os.WriteFile(filepath.Join(cg.path, "memory.max"), []byte(strconv.FormatUint(l.MemoryMaxBytes, 10)), 0644); err != nil {
+			return fmt.Errorf("failed to set memory.max: %w", err)
+		}
+	}
+	return nil
+}
+
+// oomKilled returns true if any process in the cgroup was OOM killed.
+func (cg *transientCgroup) oomKilled() bool {
+	f, err := os.Open(filepath.Join(cg.path, "memory.events"))
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		k, v, ok := strings.Cut(scanner.Text(), " ")
+		if ok && k == "oom_kill" {
+			n, err := strconv.ParseUint(strings.TrimSpace(v), 10, 64)
+			return err == nil && n > 0
+		}
+	}
+	return false
+}
+
+// remove removes the cgroup, which only succeeds once all the processes in the cgroup exited.
+func (cg *transientCgroup) remove() {
+	if cg.dir != nil {
+		_ = cg.dir.Close()
+	}
+	// rmdir, since the cgroup interface files cannot be removed
+	if err := os.Remove(cg.path); err != nil && !os.IsNotExist(err) {
+		log.Logger.Warnw("failed to remove the transient cgroup", "path", cg.path, "error", err)
+	}
+}
+
+// systemdRunScope returns the "systemd-run" command prefix that runs the tool in the transient scope
+// with the resource limits, for gpud not run in the delegated cgroup (e.g., "gpud scan" in the shell),
+// or false if systemd is not running or gpud is not root.
+func systemdRunScope(component string, l ResourceLimits) ([]string, bool) {
+	// only root creates the system scopes without the authentication
+	if os.Geteuid() != 0 {
+		return nil, false
+	}
+	if _, err := os.Stat("/run/systemd/system"); err != nil {
+		return nil, false
+	}
+	systemdRun, err := toolpath.Locate("systemd-run")
+	if err != nil {
+		return nil, false
+	}
+
+	if component == "" {
+		component = "unknown"
+	}
+	unit := fmt.Sprintf("gpud-tool-%s-%d-%d", invalidCgroupChars.ReplaceAllString(component, "_"), os.Getpid(), cgroupSeq.Add(1))
+	args := []string{systemdRun, "--scope", "--quiet", "--collect", "--unit=" + unit}
+	if l.CPUs > 0 {
+		args = append(args, "--property=CPUQuota="+strconv.FormatFloat(l.CPUs*100, 'f', -1, 64)+"%")
+	}
+	if l.MemoryMaxBytes > 0 {
+		args = append(args, "--property=MemoryMax="+strconv.FormatUint(l.MemoryMaxBytes, 10))
+	}
+	return append(args, "--"), true
+}
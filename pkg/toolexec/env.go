@@ -0,0 +1,37 @@
+package toolexec
+
+import "strings"
+
+// passthroughEnvs are the environment variables of gpud passed to the tools.
+// The others (e.g., the control plane tokens, the proxy credentials) are scrubbed.
+var passthroughEnvs = map[string]struct{}{
+	"PATH":            {},
+	"HOME":            {},
+	"USER":            {},
+	"LANG":            {},
+	"LC_ALL":          {},
+	"TMPDIR":          {},
+	"LD_LIBRARY_PATH": {},
+}
+
+// scrubEnv returns the passthrough environment variables of the environ,
+// with the extra environment variables overwriting them.
+func scrubEnv(environ []string, extra []string) []string {
+	envs := make([]string, 0, len(passthroughEnvs)+len(extra))
+	overwritten := make(map[string]struct{}, len(extra))
+	for _, env := range extra {
+		k, _, _ := strings.Cut(env, "=")
+		overwritten[k] = struct{}{}
+	}
+	for _, env := range environ {
+		k, _, _ := strings.Cut(env, "=")
+		if _, ok := passthroughEnvs[k]; !ok {
+			continue
+		}
+		if _, ok := overwritten[k]; ok {
+			continue
+		}
+		envs = append(envs, env)
+	}
+	return append(envs, extra...)
+}
@@ -0,0 +1,64 @@
+package toolexec
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+const SubSystem = "toolexec"
+
+var (
+	componentLabel = prometheus.Labels{
+		pkgmetrics.MetricComponentLabelKey: Name,
+	}
+
+	metricExecutions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "executions_total",
+			Help:      "total number of the external tool executions",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "tool", "result"}, // result is one of "success", "failed", "timeout", "oom_killed"
+	).MustCurryWith(componentLabel)
+
+	metricExecutionSeconds = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "execution_seconds_total",
+			Help:      "total seconds spent running the external tool",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "tool"},
+	).MustCurryWith(componentLabel)
+
+	metricOutputTruncated = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "output_truncated_total",
+			Help:      "total number of the external tool executions whose output exceeded the cap",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "tool"},
+	).MustCurryWith(componentLabel)
+
+	metricRunning = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "running",
+			Help:      "current number of the running external tools",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey},
+	).MustCurryWith(componentLabel)
+)
+
+func init() {
+	pkgmetrics.MustRegister(
+		metricExecutions,
+		metricExecutionSeconds,
+		metricOutputTruncated,
+		metricRunning,
+	)
+}
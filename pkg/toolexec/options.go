@@ -0,0 +1,98 @@
+package toolexec
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTimeout is the default time limit of each tool execution,
+	// in addition to the context deadline.
+	DefaultTimeout = time.Minute
+	// DefaultMaxOutputBytes is the default cap of the combined stdout and stderr,
+	// beyond which the output is discarded.
+	DefaultMaxOutputBytes = 4 * 1024 * 1024
+)
+
+type Op struct {
+	timeout         time.Duration
+	maxOutputBytes  int
+	envs            []string
+	runAsBashScript bool
+	stdin           io.Reader
+	separateStderr  bool
+}
+
+type OpOption func(*Op)
+
+var ErrNoCommand = errors.New("no command provided")
+
+func (op *Op) applyOpts(opts []OpOption) error {
+	for _, opt := range opts {
+		opt(op)
+	}
+
+	if op.timeout <= 0 {
+		op.timeout = DefaultTimeout
+	}
+	if op.maxOutputBytes <= 0 {
+		op.maxOutputBytes = DefaultMaxOutputBytes
+	}
+	for _, env := range op.envs {
+		if k, _, ok := strings.Cut(env, "="); !ok || k == "" {
+			return fmt.Errorf("invalid environment variable %q (expected KEY=VALUE)", env)
+		}
+	}
+
+	return nil
+}
+
+// Sets the time limit of the tool execution.
+// The tool (and its children) is killed when exceeded.
+func WithTimeout(timeout time.Duration) OpOption {
+	return func(op *Op) {
+		op.timeout = timeout
+	}
+}
+
+// Sets the cap of the combined stdout and stderr in bytes.
+func WithMaxOutputBytes(n int) OpOption {
+	return func(op *Op) {
+		op.maxOutputBytes = n
+	}
+}
+
+// Adds the environment variables in the format of `KEY=VALUE`,
+// on top of the scrubbed environment.
+func WithEnvs(envs ...string) OpOption {
+	return func(op *Op) {
+		op.envs = append(op.envs, envs...)
+	}
+}
+
+// Sets the stdin of the tool (e.g., the request to the local model command).
+func WithStdin(r io.Reader) OpOption {
+	return func(op *Op) {
+		op.stdin = r
+	}
+}
+
+// Returns the stderr in Result.Stderr, rather than in the combined output,
+// for the tools writing the structured output (e.g., JSON) to the stdout.
+// The stdout and the stderr are capped separately.
+func WithSeparateStderr() OpOption {
+	return func(op *Op) {
+		op.separateStderr = true
+	}
+}
+
+// Runs the command as the bash script, joining the arguments with spaces.
+// This is useful for the custom commands (e.g., the mocked "ibstat" for testing).
+func WithRunAsBashScript() OpOption {
+	return func(op *Op) {
+		op.runAsBashScript = true
+	}
+}
@@ -0,0 +1,58 @@
+package toolexec
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/process"
+)
+
+var _ process.Runner = &exclusiveRunner{}
+
+// NewExclusiveRunner returns the runner of the bash scripts (e.g., the custom plugin steps)
+// with Run, running a single script at a time.
+// The script is killed at the context deadline, or after DefaultTimeout if the context has none.
+func NewExclusiveRunner() process.Runner {
+	return &exclusiveRunner{}
+}
+
+type exclusiveRunner struct {
+	mu      sync.Mutex
+	running bool
+}
+
+// RunUntilCompletion runs the bash script, and returns the (partial) output and the exit code.
+// Returns process.ErrProcessAlreadyRunning if there is already a script running.
+func (er *exclusiveRunner) RunUntilCompletion(ctx context.Context, script string) ([]byte, int32, error) {
+	er.mu.Lock()
+	if er.running {
+		er.mu.Unlock()
+		return nil, 0, process.ErrProcessAlreadyRunning
+	}
+	er.running = true
+	er.mu.Unlock()
+
+	defer func() {
+		er.mu.Lock()
+		er.running = false
+		er.mu.Unlock()
+	}()
+
+	var opts []OpOption
+	if deadline, ok := ctx.Deadline(); ok {
+		opts = append(opts, WithTimeout(time.Until(deadline)))
+	}
+
+	// the script is run as is, as a complete script
+	res, err := Run(ctx, []string{"bash", "-c", script}, opts...)
+	if res == nil {
+		return nil, 0, err
+	}
+
+	out := res.Output
+	if len(out) == 0 {
+		out = nil
+	}
+	return out, int32(res.ExitCode), err
+}
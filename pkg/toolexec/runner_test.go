@@ -0,0 +1,49 @@
+package toolexec
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/process"
+)
+
+func TestExclusiveRunner(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	r := NewExclusiveRunner()
+
+	out, exitCode, err := r.RunUntilCompletion(ctx, "echo hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(out))
+	assert.Equal(t, int32(0), exitCode)
+
+	// partial output with the exit code
+	out, exitCode, err = r.RunUntilCompletion(ctx, "echo partial\nexit 3")
+	require.Error(t, err)
+	assert.Equal(t, "partial\n", string(out))
+	assert.Equal(t, int32(3), exitCode)
+
+	// one script at a time
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _, _ = r.RunUntilCompletion(ctx, "sleep 1")
+	}()
+	time.Sleep(200 * time.Millisecond)
+	_, _, err = r.RunUntilCompletion(ctx, "echo hello")
+	assert.ErrorIs(t, err, process.ErrProcessAlreadyRunning)
+	<-done
+
+	// killed at the context deadline
+	cctx, ccancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer ccancel()
+	start := time.Now()
+	_, _, err = r.RunUntilCompletion(cctx, "sleep 30")
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
@@ -0,0 +1,242 @@
+// Package toolexec runs the external tools (e.g., "ibstat", "dcgmi") that the
+// components shell out to, with the consistent time limits, output caps,
+// scrubbed environment, concurrency limits, and execution metrics.
+package toolexec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/toolpath"
+)
+
+// Name is the name of the tool execution metrics.
+const Name = "toolexec"
+
+// DefaultMaxConcurrency is the default number of the tools running at the same time.
+const DefaultMaxConcurrency = 8
+
+var (
+	ErrNotFound = errors.New("executable not found")
+	ErrTimeout  = errors.New("command timed out")
+)
+
+// Result is the result of the tool execution.
+type Result struct {
+	// Output is the combined stdout and stderr,
+	// including the partial output of the failed tool.
+	// Only the stdout if the stderr is separated (see WithSeparateStderr).
+	Output []byte
+	// Stderr is the stderr, only if separated with WithSeparateStderr.
+	Stderr []byte
+	// ExitCode is the exit code, or -1 if the tool did not exit on its own.
+	ExitCode int
+	// Truncated is true if the output exceeded the cap.
+	Truncated bool
+	Took      time.Duration
+}
+
+// Run runs the command, and returns the result with the partial output
+// even if the command failed.
+func Run(ctx context.Context, command []string, opts ...OpOption) (*Result, error) {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return nil, err
+	}
+	if len(command) == 0 || strings.TrimSpace(command[0]) == "" {
+		return nil, ErrNoCommand
+	}
+
+	bin := strings.Fields(command[0])[0]
+	binPath, err := toolpath.Locate(bin)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, bin)
+	}
+	tool := filepath.Base(bin)
+
+	release, err := acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	cctx, cancel := context.WithTimeout(ctx, op.timeout)
+	defer cancel()
+
+	// run the resolved path, in case the tool path is overridden
+	// or the tool is not in the PATH
+	args := append([]string{binPath}, command[1:]...)
+	if op.runAsBashScript {
+		args = []string{"bash", "-c", bashScriptHeader + strings.Join(command, " ")}
+	}
+
+	// cap the CPU and the memory of the tool in the transient cgroup in the delegated cgroup of gpud,
+	// or in the transient systemd scope, without failing the tool if neither is available
+	// (e.g., cgroup v1, not running as root)
+	component := ComponentFromContext(ctx)
+	var cg *transientCgroup
+	if l, ok := resourceLimitsOf(component); ok {
+		parent, perr := toolsCgroupParent()
+		if perr == nil {
+			cg, err = createCgroup(parent, component, l)
+			if err != nil {
+				log.Logger.Warnw("failed to create the cgroup, running the tool without the resource limits", "tool", tool, "component", component, "error", err)
+				cg = nil
+			} else {
+				defer cg.remove()
+			}
+		} else if prefix, ok := systemdRunScope(component, l); ok {
+			args = append(prefix, args...)
+		} else {
+			log.Logger.Warnw("no delegated cgroup nor systemd, running the tool without the resource limits", "tool", tool, "component", component)
+		}
+	}
+
+	cmd := exec.CommandContext(cctx, args[0], args[1:]...)
+	cmd.Env = scrubEnv(os.Environ(), op.envs)
+
+	// kill the whole process group, so that the children
+	// (e.g., of the bash script) do not outlive the time limit
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = time.Second
+	if cg != nil {
+		cmd.SysProcAttr.UseCgroupFD = true
+		cmd.SysProcAttr.CgroupFD = int(cg.dir.Fd())
+	}
+
+	out := &cappedBuffer{limit: op.maxOutputBytes}
+	cmd.Stdout = out
+	cmd.Stderr = out
+	var stderr *cappedBuffer
+	if op.separateStderr {
+		stderr = &cappedBuffer{limit: op.maxOutputBytes}
+		cmd.Stderr = stderr
+	}
+	cmd.Stdin = op.stdin
+
+	metricRunning.With(prometheus.Labels{}).Inc()
+	start := time.Now()
+	runErr := cmd.Run()
+	took := time.Since(start)
+	metricRunning.With(prometheus.Labels{}).Dec()
+
+	res := &Result{
+		Output:    out.Bytes(),
+		ExitCode:  -1,
+		Truncated: out.truncated,
+		Took:      took,
+	}
+	if stderr != nil {
+		res.Stderr = stderr.Bytes()
+		res.Truncated = res.Truncated || stderr.truncated
+	}
+	if cmd.ProcessState != nil {
+		res.ExitCode = cmd.ProcessState.ExitCode()
+	}
+
+	result := "success"
+	switch {
+	case runErr == nil:
+	case errors.Is(cctx.Err(), context.DeadlineExceeded) && ctx.Err() == nil:
+		result = "timeout"
+		runErr = fmt.Errorf("%w after %s", ErrTimeout, op.timeout)
+	case cmd.ProcessState == nil:
+		result = "failed"
+		runErr = fmt.Errorf("failed to start command: %w", runErr)
+	case cg != nil && cg.oomKilled():
+		result = "oom_killed"
+		runErr = fmt.Errorf("%w: %w", ErrOOMKilled, runErr)
+	default:
+		result = "failed"
+		runErr = fmt.Errorf("command exited with error: %w", runErr)
+	}
+
+	metricExecutions.With(prometheus.Labels{"tool": tool, "result": result}).Inc()
+	metricExecutionSeconds.With(prometheus.Labels{"tool": tool}).Add(took.Seconds())
+	if res.Truncated {
+		metricOutputTruncated.With(prometheus.Labels{"tool": tool}).Inc()
+		log.Logger.Warnw("tool output exceeded the cap, truncated", "tool", tool, "maxOutputBytes", op.maxOutputBytes)
+	}
+
+	return res, runErr
+}
+
+// bashScriptHeader fails the script on the first error, same as the "process" package.
+const bashScriptHeader = `set -o pipefail
+set -o nounset
+set -o errexit
+`
+
+// cappedBuffer discards the writes beyond the limit,
+// without failing the writer (the tool).
+type cappedBuffer struct {
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	remaining := b.limit - b.buf.Len()
+	if remaining < len(p) {
+		b.truncated = true
+		if remaining > 0 {
+			b.buf.Write(p[:remaining])
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *cappedBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Bytes()
+}
+
+var (
+	limiterMu sync.RWMutex
+	limiter   = make(chan struct{}, DefaultMaxConcurrency)
+)
+
+// SetMaxConcurrency sets the number of the tools running at the same time.
+// The other tools wait for the running ones, or their context.
+func SetMaxConcurrency(n int) {
+	if n <= 0 {
+		n = DefaultMaxConcurrency
+	}
+	limiterMu.Lock()
+	limiter = make(chan struct{}, n)
+	limiterMu.Unlock()
+}
+
+func acquire(ctx context.Context) (func(), error) {
+	limiterMu.RLock()
+	l := limiter
+	limiterMu.RUnlock()
+
+	select {
+	case l <- struct{}{}:
+		return func() { <-l }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,293 @@
+package toolexec
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	res, err := Run(ctx, []string{"echo", "hello"})
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(res.Output))
+	assert.Equal(t, 0, res.ExitCode)
+	assert.False(t, res.Truncated)
+}
+
+func TestRunErrors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := Run(ctx, nil)
+	assert.ErrorIs(t, err, ErrNoCommand)
+	_, err = Run(ctx, []string{" "})
+	assert.ErrorIs(t, err, ErrNoCommand)
+
+	_, err = Run(ctx, []string{"gpud-toolexec-does-not-exist"})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = Run(ctx, []string{"echo"}, WithEnvs("INVALID"))
+	assert.Error(t, err)
+
+	// partial output is returned with the exit code
+	res, err := Run(ctx, []string{"echo partial && exit 255"}, WithRunAsBashScript())
+	require.Error(t, err)
+	assert.Equal(t, "command exited with error: exit status 255", err.Error())
+	assert.Equal(t, 255, res.ExitCode)
+	assert.Equal(t, "partial\n", string(res.Output))
+}
+
+func TestRunStdinSeparateStderr(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	res, err := Run(ctx, []string{"cat && echo warning >&2"}, WithRunAsBashScript(), WithStdin(strings.NewReader(`{"a":1}`)), WithSeparateStderr())
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, string(res.Output))
+	assert.Equal(t, "warning\n", string(res.Stderr))
+
+	res, err = Run(ctx, []string{"echo out; echo err >&2"}, WithRunAsBashScript())
+	require.NoError(t, err)
+	assert.Equal(t, "out\nerr\n", string(res.Output))
+	assert.Empty(t, res.Stderr)
+}
+
+func TestRunTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// the child of the bash script is killed as well
+	start := time.Now()
+	res, err := Run(ctx, []string{"sleep 30 & wait"}, WithRunAsBashScript(), WithTimeout(200*time.Millisecond))
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Equal(t, -1, res.ExitCode)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestRunMaxOutputBytes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	res, err := Run(ctx, []string{"echo", "0123456789"}, WithMaxOutputBytes(4))
+	require.NoError(t, err)
+	assert.Equal(t, "0123", string(res.Output))
+	assert.True(t, res.Truncated)
+}
+
+func TestRunScrubsEnv(t *testing.T) {
+	t.Setenv("GPUD_TOOLEXEC_SECRET", "secret")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	res, err := Run(ctx, []string{"env"}, WithEnvs("GPUD_TOOLEXEC_EXTRA=extra"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(res.Output), "GPUD_TOOLEXEC_SECRET")
+	assert.Contains(t, string(res.Output), "GPUD_TOOLEXEC_EXTRA=extra")
+	assert.Contains(t, string(res.Output), "PATH=")
+}
+
+func TestScrubEnv(t *testing.T) {
+	envs := scrubEnv(
+		[]string{"PATH=/usr/bin", "TOKEN=secret", "LANG=C", "HOME=/root"},
+		[]string{"LANG=en_US.UTF-8"},
+	)
+	assert.Equal(t, []string{"PATH=/usr/bin", "HOME=/root", "LANG=en_US.UTF-8"}, envs)
+}
+
+func TestCappedBuffer(t *testing.T) {
+	b := &cappedBuffer{limit: 5}
+	n, err := b.Write([]byte("abc"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.False(t, b.truncated)
+
+	n, err = b.Write([]byte("defg"))
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+	assert.True(t, b.truncated)
+	assert.Equal(t, "abcde", string(b.Bytes()))
+}
+
+func TestSetMaxConcurrency(t *testing.T) {
+	SetMaxConcurrency(1)
+	defer SetMaxConcurrency(DefaultMaxConcurrency)
+
+	release, err := acquire(context.Background())
+	require.NoError(t, err)
+
+	// the second waits for the first
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	_, err = acquire(ctx)
+	cancel()
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	release()
+	release, err = acquire(context.Background())
+	require.NoError(t, err)
+	release()
+
+	var running, maxRunning int32
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := acquire(context.Background())
+			if err != nil {
+				return
+			}
+			defer release()
+			cur := atomic.AddInt32(&running, 1)
+			for {
+				prev := atomic.LoadInt32(&maxRunning)
+				if cur <= prev || atomic.CompareAndSwapInt32(&maxRunning, prev, cur) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), maxRunning)
+}
+
+func TestParseResourceLimits(t *testing.T) {
+	m, err := ParseResourceLimits("accelerator-nvidia-infiniband=0.5:512MiB, *=1:, disk=:1GB,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]ResourceLimits{
+		"accelerator-nvidia-infiniband": {CPUs: 0.5, MemoryMaxBytes: 512 * 1024 * 1024},
+		AllComponents:                   {CPUs: 1},
+		"disk":                          {MemoryMaxBytes: 1000 * 1000 * 1000},
+	}, m)
+
+	m, err = ParseResourceLimits("")
+	require.NoError(t, err)
+	assert.Empty(t, m)
+
+	for _, s := range []string{"0.5:512MiB", "=0.5", "a=x:", "a=:x", "a=-1:"} {
+		_, err = ParseResourceLimits(s)
+		assert.ErrorIs(t, err, ErrInvalidResourceLimits, s)
+	}
+}
+
+func TestResourceLimitsOf(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, SetResourceLimits(nil)) })
+
+	_, ok := resourceLimitsOf("a")
+	assert.False(t, ok)
+
+	assert.ErrorIs(t, SetResourceLimits(map[string]ResourceLimits{"a": {CPUs: -1}}), ErrInvalidResourceLimits)
+	require.NoError(t, SetResourceLimits(map[string]ResourceLimits{
+		"a":           {CPUs: 0.5},
+		"b":           {},
+		AllComponents: {MemoryMaxBytes: 1024},
+	}))
+
+	l, ok := resourceLimitsOf("a")
+	assert.True(t, ok)
+	assert.Equal(t, ResourceLimits{CPUs: 0.5}, l)
+
+	// the zero limits fall back to all components
+	l, ok = resourceLimitsOf("b")
+	assert.True(t, ok)
+	assert.Equal(t, ResourceLimits{MemoryMaxBytes: 1024}, l)
+
+	l, ok = resourceLimitsOf("")
+	assert.True(t, ok)
+	assert.Equal(t, ResourceLimits{MemoryMaxBytes: 1024}, l)
+}
+
+func TestWithComponent(t *testing.T) {
+	assert.Empty(t, ComponentFromContext(context.Background()))
+	assert.Equal(t, "disk", ComponentFromContext(WithComponent(context.Background(), "disk")))
+}
+
+func TestCreateCgroup(t *testing.T) {
+	parent := t.TempDir()
+
+	cg, err := createCgroup(parent, "accelerator/nvidia", ResourceLimits{CPUs: 0.5, MemoryMaxBytes: 1024})
+	require.NoError(t, err)
+	defer cg.dir.Close()
+
+	assert.Equal(t, parent, filepath.Dir(cg.path))
+	assert.True(t, strings.HasPrefix(filepath.Base(cg.path), "accelerator_nvidia-"))
+
+	b, err := os.ReadFile(filepath.Join(cg.path, "cpu.max"))
+	require.NoError(t, err)
+	assert.Equal(t, "50000 100000", string(b))
+	b, err = os.ReadFile(filepath.Join(cg.path, "memory.max"))
+	require.NoError(t, err)
+	assert.Equal(t, "1024", string(b))
+
+	assert.False(t, cg.oomKilled())
+	require.NoError(t, os.WriteFile(filepath.Join(cg.path, "memory.events"), []byte("low 0\nhigh 0\nmax 3\noom 1\noom_kill 0\n"), 0644))
+	assert.False(t, cg.oomKilled())
+	require.NoError(t, os.WriteFile(filepath.Join(cg.path, "memory.events"), []byte("low 0\nhigh 0\nmax 3\noom 1\noom_kill 1\n"), 0644))
+	assert.True(t, cg.oomKilled())
+}
+
+func TestSetupDelegatedCgroup(t *testing.T) {
+	origIsDelegated := isDelegatedFunc
+	t.Cleanup(func() { isDelegatedFunc = origIsDelegated })
+
+	root := t.TempDir()
+	ownDir := filepath.Join(root, "runtime.slice", "gpud.service")
+	require.NoError(t, os.MkdirAll(ownDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(ownDir, "cgroup.procs"), []byte("100\n200\n"), 0644))
+
+	selfCgroup := filepath.Join(t.TempDir(), "cgroup")
+	require.NoError(t, os.WriteFile(selfCgroup, []byte("0::/runtime.slice/gpud.service\n"), 0644))
+
+	// not delegated, never writes to the cgroup tree of systemd
+	isDelegatedFunc = func(string) bool { return false }
+	_, err := setupDelegatedCgroup(root, selfCgroup)
+	assert.ErrorIs(t, err, ErrCgroupNotDelegated)
+	_, err = os.Stat(filepath.Join(ownDir, cgroupParent))
+	assert.True(t, os.IsNotExist(err))
+
+	isDelegatedFunc = func(dir string) bool { return dir == ownDir }
+	parent, err := setupDelegatedCgroup(root, selfCgroup)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(ownDir, cgroupParent), parent)
+
+	// the processes are moved to the leaf (the fake cgroup.procs keeps the last write)
+	b, err := os.ReadFile(filepath.Join(ownDir, cgroupDaemonLeaf, "cgroup.procs"))
+	require.NoError(t, err)
+	assert.Equal(t, "200", string(b))
+	b, err = os.ReadFile(filepath.Join(ownDir, "cgroup.subtree_control"))
+	require.NoError(t, err)
+	assert.Equal(t, cgroupControllers, string(b))
+	b, err = os.ReadFile(filepath.Join(parent, "cgroup.subtree_control"))
+	require.NoError(t, err)
+	assert.Equal(t, cgroupControllers, string(b))
+
+	// already in the leaf (e.g., the second call)
+	require.NoError(t, os.WriteFile(selfCgroup, []byte("0::/runtime.slice/gpud.service/daemon\n"), 0644))
+	parent, err = setupDelegatedCgroup(root, selfCgroup)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(ownDir, cgroupParent), parent)
+
+	// never in the root cgroup
+	require.NoError(t, os.WriteFile(selfCgroup, []byte("0::/\n"), 0644))
+	_, err = setupDelegatedCgroup(root, selfCgroup)
+	assert.ErrorIs(t, err, ErrCgroupNotDelegated)
+
+	// cgroup v1
+	require.NoError(t, os.WriteFile(selfCgroup, []byte("12:memory:/user.slice\n"), 0644))
+	_, err = setupDelegatedCgroup(root, selfCgroup)
+	assert.ErrorIs(t, err, ErrCgroupNotDelegated)
+}
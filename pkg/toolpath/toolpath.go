@@ -0,0 +1,160 @@
+// Package toolpath resolves the paths of the external tools (e.g., "ibstat", "lspci")
+// that gpud shells out to, from the user overrides, the PATH,
+// and then the well-known candidate paths.
+package toolpath
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	pkgfile "github.com/leptonai/gpud/pkg/file"
+)
+
+// Tool is the external tool and its well-known install paths,
+// in case the tool is not in the PATH of gpud (e.g., the systemd unit).
+type Tool struct {
+	Name       string   `json:"name"`
+	Candidates []string `json:"candidates,omitempty"`
+}
+
+// DefaultTools are the external tools used by gpud.
+var DefaultTools = []Tool{
+	{Name: "all_reduce_perf", Candidates: []string{"/usr/local/bin/all_reduce_perf", "/opt/nccl-tests/build/all_reduce_perf"}},
+	{Name: "containerd", Candidates: []string{"/usr/bin/containerd", "/usr/local/bin/containerd"}},
+	{Name: "dcgmi", Candidates: []string{"/usr/bin/dcgmi", "/usr/local/dcgm/bin/dcgmi"}},
+	{Name: "dmidecode", Candidates: []string{"/usr/sbin/dmidecode"}},
+	{Name: "docker", Candidates: []string{"/usr/bin/docker", "/usr/local/bin/docker"}},
+	{Name: "findmnt", Candidates: []string{"/usr/bin/findmnt", "/bin/findmnt"}},
+	{Name: "gdsio", Candidates: []string{"/usr/local/cuda/gds/tools/gdsio"}},
+	{Name: "ibdiagnet", Candidates: []string{"/usr/bin/ibdiagnet", "/usr/sbin/ibdiagnet"}},
+	{Name: "ibstat", Candidates: []string{"/usr/sbin/ibstat", "/usr/bin/ibstat"}},
+	{Name: "ibstatus", Candidates: []string{"/usr/sbin/ibstatus", "/usr/bin/ibstatus"}},
+	{Name: "ipmitool", Candidates: []string{"/usr/bin/ipmitool", "/usr/sbin/ipmitool"}},
+	{Name: "kubelet", Candidates: []string{"/usr/bin/kubelet", "/usr/local/bin/kubelet"}},
+	{Name: "lldpctl", Candidates: []string{"/usr/sbin/lldpctl"}},
+	{Name: "lsblk", Candidates: []string{"/usr/bin/lsblk", "/bin/lsblk"}},
+	{Name: "lspci", Candidates: []string{"/usr/bin/lspci", "/usr/sbin/lspci", "/sbin/lspci"}},
+	{Name: "mpirun", Candidates: []string{"/usr/bin/mpirun", "/usr/local/mpi/bin/mpirun", "/opt/amazon/openmpi/bin/mpirun"}},
+	{Name: "nvbandwidth", Candidates: []string{"/usr/bin/nvbandwidth", "/usr/local/bin/nvbandwidth"}},
+	{Name: "nvidia-smi", Candidates: []string{"/usr/bin/nvidia-smi", "/usr/local/nvidia/bin/nvidia-smi"}},
+	{Name: "ofed_info", Candidates: []string{"/usr/bin/ofed_info"}},
+	{Name: "saquery", Candidates: []string{"/usr/sbin/saquery", "/usr/bin/saquery"}},
+	{Name: "smartctl", Candidates: []string{"/usr/sbin/smartctl", "/usr/bin/smartctl"}},
+	{Name: "systemd", Candidates: []string{"/usr/lib/systemd/systemd", "/lib/systemd/systemd"}},
+	{Name: "systemd-detect-virt", Candidates: []string{"/usr/bin/systemd-detect-virt"}},
+	{Name: "systemd-run", Candidates: []string{"/usr/bin/systemd-run", "/bin/systemd-run"}},
+	{Name: "tailscaled", Candidates: []string{"/usr/sbin/tailscaled", "/usr/local/bin/tailscaled"}},
+}
+
+var ErrUnknownTool = errors.New("unknown tool")
+
+var (
+	mu        sync.RWMutex
+	tools     = toMap(DefaultTools)
+	overrides = map[string]string{}
+)
+
+func toMap(ts []Tool) map[string]Tool {
+	m := make(map[string]Tool, len(ts))
+	for _, t := range ts {
+		m[t.Name] = t
+	}
+	return m
+}
+
+// Validate returns an error if the overrides have the unknown tools or the empty paths.
+func Validate(overrides map[string]string) error {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for name, p := range overrides {
+		if _, ok := tools[name]; !ok {
+			return fmt.Errorf("%w %q", ErrUnknownTool, name)
+		}
+		if p == "" {
+			return fmt.Errorf("empty path for tool %q", name)
+		}
+	}
+	return nil
+}
+
+// SetOverrides replaces the tool path overrides.
+// The overridden tools are resolved to the override paths only.
+func SetOverrides(m map[string]string) error {
+	if err := Validate(m); err != nil {
+		return err
+	}
+
+	copied := make(map[string]string, len(m))
+	for name, p := range m {
+		copied[name] = p
+	}
+
+	mu.Lock()
+	overrides = copied
+	mu.Unlock()
+	return nil
+}
+
+// Locate returns the path of the tool, from its override, the PATH,
+// and then the candidate paths in order.
+// The tools not in the registry (e.g., the custom commands) are looked up in the PATH only.
+func Locate(name string) (string, error) {
+	mu.RLock()
+	override, overridden := overrides[name]
+	tool := tools[name]
+	mu.RUnlock()
+
+	if overridden {
+		if err := pkgfile.CheckExecutable(override); err != nil {
+			return "", fmt.Errorf("override %q of tool %q is not executable: %w", override, name, err)
+		}
+		return override, nil
+	}
+
+	p, err := pkgfile.LocateExecutable(name)
+	if err == nil {
+		return p, nil
+	}
+	for _, c := range tool.Candidates {
+		if pkgfile.CheckExecutable(c) == nil {
+			return c, nil
+		}
+	}
+	return "", err
+}
+
+// Status is the resolved path of the tool.
+type Status struct {
+	Tool
+
+	// Override is the user-provided path, if any.
+	Override string `json:"override,omitempty"`
+	// Path is the resolved path, empty if the tool is not found.
+	Path string `json:"path,omitempty"`
+	// Error is the reason the tool is not found.
+	Error string `json:"error,omitempty"`
+}
+
+// List returns the resolved paths of all the registered tools, sorted by the name.
+func List() []Status {
+	mu.RLock()
+	ts := make([]Status, 0, len(tools))
+	for _, t := range tools {
+		ts = append(ts, Status{Tool: t, Override: overrides[t.Name]})
+	}
+	mu.RUnlock()
+
+	sort.Slice(ts, func(i, j int) bool { return ts[i].Name < ts[j].Name })
+	for i := range ts {
+		p, err := Locate(ts[i].Name)
+		if err != nil {
+			ts[i].Error = err.Error()
+			continue
+		}
+		ts[i].Path = p
+	}
+	return ts
+}
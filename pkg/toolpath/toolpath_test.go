@@ -0,0 +1,73 @@
+package toolpath
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(nil))
+	assert.NoError(t, Validate(map[string]string{"ibstat": "/opt/bin/ibstat"}))
+	assert.True(t, errors.Is(Validate(map[string]string{"unknown": "/opt/bin/unknown"}), ErrUnknownTool))
+	assert.Error(t, Validate(map[string]string{"ibstat": ""}))
+}
+
+func TestLocate(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, SetOverrides(nil)) })
+
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "ibstat")
+	require.NoError(t, os.WriteFile(bin, []byte("#!/bin/sh\n"), 0755))
+
+	require.NoError(t, SetOverrides(map[string]string{"ibstat": bin}))
+	p, err := Locate("ibstat")
+	require.NoError(t, err)
+	assert.Equal(t, bin, p)
+
+	// the override is not executable, without falling back to the PATH
+	require.NoError(t, SetOverrides(map[string]string{"ibstat": filepath.Join(dir, "missing")}))
+	_, err = Locate("ibstat")
+	assert.Error(t, err)
+
+	require.True(t, errors.Is(SetOverrides(map[string]string{"unknown": bin}), ErrUnknownTool))
+
+	// the tools not in the registry are looked up in the PATH
+	require.NoError(t, SetOverrides(nil))
+	p, err = Locate("sh")
+	require.NoError(t, err)
+	assert.NotEmpty(t, p)
+}
+
+func TestLocateCandidates(t *testing.T) {
+	mu.Lock()
+	orig := tools
+	mu.Unlock()
+	t.Cleanup(func() {
+		mu.Lock()
+		tools = orig
+		mu.Unlock()
+	})
+
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "gpud-test-tool")
+	require.NoError(t, os.WriteFile(bin, []byte("#!/bin/sh\n"), 0755))
+
+	mu.Lock()
+	tools = toMap([]Tool{{Name: "gpud-test-tool", Candidates: []string{filepath.Join(dir, "missing"), bin}}})
+	mu.Unlock()
+
+	p, err := Locate("gpud-test-tool")
+	require.NoError(t, err)
+	assert.Equal(t, bin, p)
+
+	ts := List()
+	require.Len(t, ts, 1)
+	assert.Equal(t, "gpud-test-tool", ts[0].Name)
+	assert.Equal(t, bin, ts[0].Path)
+	assert.Empty(t, ts[0].Error)
+}
@@ -13,6 +13,7 @@ import (
 	"path/filepath"
 	"runtime"
 
+	"github.com/leptonai/gpud/pkg/config"
 	"github.com/leptonai/gpud/pkg/log"
 	"github.com/leptonai/gpud/pkg/release/distsign"
 )
@@ -23,6 +24,11 @@ func downloadURLToFile(ctx context.Context, pathSrc, fileDst, pkgAddr string) (r
 	if err != nil {
 		return err
 	}
+	pinFile, err := config.DefaultKeyBundlePinFile()
+	if err != nil {
+		return err
+	}
+	c.SetKeyBundlePin(distsign.NewKeyBundlePin(pinFile))
 	return c.Download(ctx, pathSrc, fileDst)
 }
 
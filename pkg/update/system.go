@@ -62,6 +62,21 @@ func RestartGPUdSystemdUnit() error {
 	return nil
 }
 
+// StartGPUdSystemdUnit reloads the systemd units, and starts the gpud unit
+// if not running, without restarting the running daemon.
+func StartGPUdSystemdUnit() error {
+	if !pkdsystemd.SystemctlExists() {
+		return errors.ErrUnsupported
+	}
+	if out, err := exec.Command("systemctl", "daemon-reload").CombinedOutput(); err != nil {
+		return fmt.Errorf("systemctl daemon-reload failed: %w output: %s", err, out)
+	}
+	if out, err := exec.Command("systemctl", "start", "gpud.service").CombinedOutput(); err != nil {
+		return fmt.Errorf("systemctl start failed: %w output: %s", err, out)
+	}
+	return nil
+}
+
 func StopSystemdUnit() error {
 	if !pkdsystemd.SystemctlExists() {
 		return errors.ErrUnsupported
@@ -0,0 +1,119 @@
+package upload
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// ref. https://learn.microsoft.com/en-us/entra/identity/managed-identities-azure-resources/how-to-use-vm-token#get-a-token-using-http
+	azureIdentityTokenURL        = "http://169.254.169.254/metadata/identity/oauth2/token"
+	azureIdentityTokenAPIVersion = "2018-02-01"
+	azureStorageResource         = "https://storage.azure.com/"
+	azureIdentityTokenTimeout    = 5 * time.Second
+
+	// ref. https://learn.microsoft.com/en-us/rest/api/storageservices/versioning-for-the-azure-storage-services
+	azureStorageAPIVersion = "2021-08-06"
+)
+
+type azureBackend struct {
+	account   string
+	container string
+	endpoint  string
+	tokenURL  string
+	cli       *http.Client
+
+	getTokenFunc func(ctx context.Context) (string, error)
+}
+
+func newAzureBackend(account string, container string, op *Op) *azureBackend {
+	ep := fmt.Sprintf("https://%s.blob.core.windows.net", account)
+	if op.endpoint != "" {
+		ep = op.endpoint
+	}
+	b := &azureBackend{
+		account:   account,
+		container: container,
+		endpoint:  strings.TrimRight(ep, "/"),
+		tokenURL:  azureIdentityTokenURL,
+		cli:       op.httpClient,
+	}
+	b.getTokenFunc = b.getAccessToken
+	return b
+}
+
+// getAccessToken fetches the access token of the managed identity
+// assigned to the virtual machine for the Azure storage resource.
+func (b *azureBackend) getAccessToken(ctx context.Context) (string, error) {
+	q := url.Values{}
+	q.Set("api-version", azureIdentityTokenAPIVersion)
+	q.Set("resource", azureStorageResource)
+
+	// the upload client timeout is for the large objects,
+	// bound the metadata service request separately
+	cctx, ccancel := context.WithTimeout(ctx, azureIdentityTokenTimeout)
+	defer ccancel()
+
+	req, err := http.NewRequestWithContext(cctx, http.MethodGet, b.tokenURL+"?"+q.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata", "true")
+
+	resp, err := b.cli.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch managed identity token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch managed identity token: received status code %d", resp.StatusCode)
+	}
+
+	var tok struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", fmt.Errorf("failed to parse managed identity token: %w", err)
+	}
+	if tok.AccessToken == "" {
+		return "", errors.New("empty managed identity access token")
+	}
+	return tok.AccessToken, nil
+}
+
+// put uploads the object as a block blob in a single request.
+// ref. https://learn.microsoft.com/en-us/rest/api/storageservices/put-blob
+func (b *azureBackend) put(ctx context.Context, objectKey string, body io.Reader, size int64) error {
+	token, err := b.getTokenFunc(ctx)
+	if err != nil {
+		return err
+	}
+
+	reqURL := fmt.Sprintf("%s/%s/%s", b.endpoint, url.PathEscape(b.container), escapeObjectKey(objectKey))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, reqURL, io.NopCloser(body))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	req.Header.Set("x-ms-version", azureStorageAPIVersion)
+
+	resp, err := b.cli.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	rb, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return checkResponse(resp.StatusCode, rb)
+}
@@ -0,0 +1,88 @@
+package upload
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	gcpimds "github.com/leptonai/gpud/pkg/providers/gcp/imds"
+)
+
+const defaultGCSEndpoint = "https://storage.googleapis.com"
+
+type gcsBackend struct {
+	bucket   string
+	endpoint string
+	cli      *http.Client
+
+	getTokenFunc func(ctx context.Context) (string, error)
+}
+
+func newGCSBackend(bucket string, op *Op) *gcsBackend {
+	ep := defaultGCSEndpoint
+	if op.endpoint != "" {
+		ep = op.endpoint
+	}
+	return &gcsBackend{
+		bucket:       bucket,
+		endpoint:     strings.TrimRight(ep, "/"),
+		cli:          op.httpClient,
+		getTokenFunc: getGCPAccessToken,
+	}
+}
+
+// getGCPAccessToken fetches the OAuth2 access token of the default service account
+// attached to the instance.
+// ref. https://cloud.google.com/compute/docs/access/authenticate-workloads#applications
+func getGCPAccessToken(ctx context.Context) (string, error) {
+	raw, err := gcpimds.FetchMetadata(ctx, "instance/service-accounts/default/token")
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch service account token: %w", err)
+	}
+	var tok struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal([]byte(raw), &tok); err != nil {
+		return "", fmt.Errorf("failed to parse service account token: %w", err)
+	}
+	if tok.AccessToken == "" {
+		return "", errors.New("empty service account access token")
+	}
+	return tok.AccessToken, nil
+}
+
+// put uploads the object with the JSON API simple upload.
+// ref. https://cloud.google.com/storage/docs/uploading-objects#uploading-an-object
+func (b *gcsBackend) put(ctx context.Context, objectKey string, body io.Reader, size int64) error {
+	token, err := b.getTokenFunc(ctx)
+	if err != nil {
+		return err
+	}
+
+	q := url.Values{}
+	q.Set("uploadType", "media")
+	q.Set("name", objectKey)
+	reqURL := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?%s", b.endpoint, url.PathEscape(b.bucket), q.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, io.NopCloser(body))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := b.cli.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	rb, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return checkResponse(resp.StatusCode, rb)
+}
@@ -0,0 +1,101 @@
+package upload
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultMaxSize is the maximum object size for a single PUT request
+	// (S3 single PUT object is limited to 5 GiB).
+	DefaultMaxSize = int64(5 * 1024 * 1024 * 1024)
+
+	DefaultRetries       = 3
+	DefaultRetryInterval = 2 * time.Second
+)
+
+type Op struct {
+	maxSize       int64
+	retries       int
+	retryInterval time.Duration
+
+	// endpoint overrides the default storage service endpoint
+	// (e.g., S3-compatible storage, or testing).
+	endpoint string
+	region   string
+
+	httpClient *http.Client
+}
+
+type OpOption func(*Op)
+
+func (op *Op) applyOpts(opts []OpOption) error {
+	op.retries = -1
+
+	for _, opt := range opts {
+		opt(op)
+	}
+
+	if op.maxSize == 0 {
+		op.maxSize = DefaultMaxSize
+	}
+	if op.retries < 0 {
+		op.retries = DefaultRetries
+	}
+	if op.retryInterval == 0 {
+		op.retryInterval = DefaultRetryInterval
+	}
+	if op.httpClient == nil {
+		op.httpClient = &http.Client{
+			Timeout: 30 * time.Minute,
+		}
+	}
+
+	return nil
+}
+
+// WithMaxSize sets the maximum object size in bytes.
+// Set a negative value to disable the limit.
+func WithMaxSize(size int64) OpOption {
+	return func(op *Op) {
+		op.maxSize = size
+	}
+}
+
+// WithRetries sets the number of retries on the retryable failures
+// (e.g., network errors, 5xx, throttling).
+func WithRetries(retries int) OpOption {
+	return func(op *Op) {
+		op.retries = retries
+	}
+}
+
+// WithRetryInterval sets the base interval between retries,
+// which is linearly increased on each retry.
+func WithRetryInterval(d time.Duration) OpOption {
+	return func(op *Op) {
+		op.retryInterval = d
+	}
+}
+
+// WithEndpoint overrides the default storage service endpoint
+// (e.g., "https://minio.internal:9000" for S3-compatible storage).
+func WithEndpoint(ep string) OpOption {
+	return func(op *Op) {
+		op.endpoint = ep
+	}
+}
+
+// WithRegion sets the S3 region (leave empty to auto-detect).
+func WithRegion(region string) OpOption {
+	return func(op *Op) {
+		op.region = region
+	}
+}
+
+// WithHTTPClient sets the HTTP client for the upload requests.
+func WithHTTPClient(cli *http.Client) OpOption {
+	return func(op *Op) {
+		op.httpClient = cli
+	}
+}
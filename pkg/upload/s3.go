@@ -0,0 +1,229 @@
+package upload
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	awsimds "github.com/leptonai/gpud/pkg/providers/aws/imds"
+)
+
+const (
+	s3Service          = "s3"
+	s3SigningAlgorithm = "AWS4-HMAC-SHA256"
+	s3UnsignedPayload  = "UNSIGNED-PAYLOAD"
+
+	s3HeaderDate          = "X-Amz-Date"
+	s3HeaderContentSHA256 = "X-Amz-Content-Sha256"
+	s3HeaderSecurityToken = "X-Amz-Security-Token"
+)
+
+// awsCredentials is the AWS credentials for signing the S3 requests.
+type awsCredentials struct {
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	Token           string `json:"Token"`
+}
+
+type s3Backend struct {
+	bucket   string
+	endpoint string
+	region   string
+	cli      *http.Client
+
+	getCredentialsFunc func(ctx context.Context) (awsCredentials, error)
+	getRegionFunc      func(ctx context.Context) (string, error)
+	nowFunc            func() time.Time
+}
+
+func newS3Backend(bucket string, op *Op) *s3Backend {
+	return &s3Backend{
+		bucket:             bucket,
+		endpoint:           op.endpoint,
+		region:             op.region,
+		cli:                op.httpClient,
+		getCredentialsFunc: getAWSCredentials,
+		getRegionFunc:      getAWSRegion,
+		nowFunc:            time.Now,
+	}
+}
+
+// getAWSCredentials reads the credentials from the environment variables,
+// and falls back to the instance profile credentials via IMDSv2.
+// ref. https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/instance-metadata-security-credentials.html
+func getAWSCredentials(ctx context.Context) (awsCredentials, error) {
+	if id, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); id != "" && secret != "" {
+		return awsCredentials{
+			AccessKeyID:     id,
+			SecretAccessKey: secret,
+			Token:           os.Getenv("AWS_SESSION_TOKEN"),
+		}, nil
+	}
+
+	role, err := awsimds.FetchMetadata(ctx, "iam/security-credentials/")
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to fetch instance profile role: %w", err)
+	}
+	role = strings.TrimSpace(strings.Split(role, "\n")[0])
+	if role == "" {
+		return awsCredentials{}, errors.New("no instance profile role attached")
+	}
+
+	raw, err := awsimds.FetchMetadata(ctx, "iam/security-credentials/"+role)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to fetch instance profile credentials: %w", err)
+	}
+	var creds awsCredentials
+	if err := json.Unmarshal([]byte(raw), &creds); err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to parse instance profile credentials: %w", err)
+	}
+	return creds, nil
+}
+
+func getAWSRegion(ctx context.Context) (string, error) {
+	if r := os.Getenv("AWS_REGION"); r != "" {
+		return r, nil
+	}
+	if r := os.Getenv("AWS_DEFAULT_REGION"); r != "" {
+		return r, nil
+	}
+	return awsimds.FetchMetadata(ctx, "placement/region")
+}
+
+func (b *s3Backend) put(ctx context.Context, objectKey string, body io.Reader, size int64) error {
+	creds, err := b.getCredentialsFunc(ctx)
+	if err != nil {
+		return err
+	}
+
+	region := b.region
+	if region == "" {
+		region, err = b.getRegionFunc(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to detect aws region: %w", err)
+		}
+	}
+
+	// virtual-hosted-style by default, path-style for custom endpoints
+	reqURL := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", b.bucket, region, escapeObjectKey(objectKey))
+	if b.endpoint != "" {
+		reqURL = fmt.Sprintf("%s/%s/%s", strings.TrimRight(b.endpoint, "/"), b.bucket, escapeObjectKey(objectKey))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, reqURL, io.NopCloser(body))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	signS3Request(req, creds, region, b.nowFunc().UTC())
+
+	resp, err := b.cli.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	rb, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return checkResponse(resp.StatusCode, rb)
+}
+
+// signS3Request signs the request with AWS Signature Version 4.
+// The payload is not signed ("UNSIGNED-PAYLOAD"), which is allowed for S3 over TLS.
+// ref. https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+func signS3Request(req *http.Request, creds awsCredentials, region string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	shortDate := now.Format("20060102")
+
+	req.Header.Set(s3HeaderDate, amzDate)
+	req.Header.Set(s3HeaderContentSHA256, s3UnsignedPayload)
+	if creds.Token != "" {
+		req.Header.Set(s3HeaderSecurityToken, creds.Token)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, vs := range req.Header {
+		lk := strings.ToLower(k)
+		if lk == "content-type" || strings.HasPrefix(lk, "x-amz-") {
+			headers[lk] = strings.TrimSpace(strings.Join(vs, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	canonicalHeaders := ""
+	for _, k := range names {
+		canonicalHeaders += k + ":" + headers[k] + "\n"
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders,
+		signedHeaders,
+		s3UnsignedPayload,
+	}, "\n")
+
+	scope := strings.Join([]string{shortDate, region, s3Service, "aws4_request"}, "/")
+	crHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		s3SigningAlgorithm,
+		amzDate,
+		scope,
+		hex.EncodeToString(crHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), shortDate)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3SigningAlgorithm,
+		creds.AccessKeyID,
+		scope,
+		signedHeaders,
+		signature,
+	))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// escapeObjectKey URI-encodes each path segment of the object key
+// except the unreserved characters, while preserving the "/" separators.
+// ref. https://docs.aws.amazon.com/IAM/latest/UserGuide/create-signed-request.html#create-canonical-request
+func escapeObjectKey(key string) string {
+	var sb strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/':
+			sb.WriteByte(c)
+		default:
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,217 @@
+// Package upload provides the object storage sinks (S3, GCS, Azure Blob)
+// used to upload support bundles, scan outputs, and periodic reports
+// directly from the node with its instance credentials.
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	SchemeS3    = "s3"
+	SchemeGCS   = "gs"
+	SchemeAzure = "azblob"
+)
+
+var (
+	ErrUnsupportedScheme = errors.New("unsupported upload destination scheme")
+	ErrEmptyBucket       = errors.New("upload destination bucket is empty")
+	ErrEmptyKey          = errors.New("upload object key is empty")
+	ErrSizeLimitExceeded = errors.New("upload size limit exceeded")
+)
+
+// Uploader uploads objects to a remote object storage destination.
+type Uploader interface {
+	// Destination returns the destination URL (e.g., "s3://bucket/prefix").
+	Destination() string
+
+	// Upload uploads the object with the given key, relative to the destination prefix.
+	// The body is rewound to its beginning on each retry.
+	Upload(ctx context.Context, key string, body io.ReadSeeker, size int64) error
+}
+
+// backend is the storage specific implementation that uploads a single object
+// without retries or size checks.
+type backend interface {
+	put(ctx context.Context, objectKey string, body io.Reader, size int64) error
+}
+
+var _ Uploader = &uploader{}
+
+type uploader struct {
+	dest    *url.URL
+	prefix  string
+	backend backend
+
+	maxSize       int64
+	retries       int
+	retryInterval time.Duration
+}
+
+// New creates a new uploader for the destination URL.
+//
+// Supported destinations are:
+//
+//	s3://<bucket>/<prefix>
+//	gs://<bucket>/<prefix>
+//	azblob://<storage-account>/<container>/<prefix>
+func New(dest string, opts ...OpOption) (Uploader, error) {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(dest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse upload destination %q: %w", dest, err)
+	}
+	if u.Host == "" {
+		return nil, ErrEmptyBucket
+	}
+
+	prefix := strings.Trim(u.Path, "/")
+
+	var b backend
+	switch u.Scheme {
+	case SchemeS3:
+		b = newS3Backend(u.Host, op)
+
+	case SchemeGCS:
+		b = newGCSBackend(u.Host, op)
+
+	case SchemeAzure:
+		container, rest, _ := strings.Cut(prefix, "/")
+		if container == "" {
+			return nil, fmt.Errorf("azure blob container is empty: %w", ErrEmptyBucket)
+		}
+		prefix = rest
+		b = newAzureBackend(u.Host, container, op)
+
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedScheme, u.Scheme)
+	}
+
+	return &uploader{
+		dest:          u,
+		prefix:        prefix,
+		backend:       b,
+		maxSize:       op.maxSize,
+		retries:       op.retries,
+		retryInterval: op.retryInterval,
+	}, nil
+}
+
+func (u *uploader) Destination() string {
+	return u.dest.String()
+}
+
+func (u *uploader) Upload(ctx context.Context, key string, body io.ReadSeeker, size int64) error {
+	key = strings.TrimLeft(key, "/")
+	if key == "" {
+		return ErrEmptyKey
+	}
+	if u.maxSize > 0 && size > u.maxSize {
+		return fmt.Errorf("%w: %d bytes > %d bytes", ErrSizeLimitExceeded, size, u.maxSize)
+	}
+
+	objectKey := key
+	if u.prefix != "" {
+		objectKey = path.Join(u.prefix, key)
+	}
+
+	var err error
+	for attempt := 0; attempt <= u.retries; attempt++ {
+		if attempt > 0 {
+			log.Logger.Warnw("retrying upload", "destination", u.Destination(), "key", objectKey, "attempt", attempt, "error", err)
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(u.retryInterval * time.Duration(attempt)):
+			}
+
+			if _, serr := body.Seek(0, io.SeekStart); serr != nil {
+				return fmt.Errorf("failed to rewind upload body: %w", serr)
+			}
+		}
+
+		err = u.backend.put(ctx, objectKey, body, size)
+		if err == nil {
+			log.Logger.Infow("uploaded object", "destination", u.Destination(), "key", objectKey, "size", size)
+			return nil
+		}
+
+		var nre *nonRetryableError
+		if errors.As(err, &nre) {
+			return nre.err
+		}
+	}
+	return fmt.Errorf("failed to upload %q after %d attempt(s): %w", objectKey, u.retries+1, err)
+}
+
+// UploadFile uploads the local file to the destination with the given key.
+// If the key is empty, the base name of the file is used.
+func UploadFile(ctx context.Context, u Uploader, file string, key string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%q is a directory", file)
+	}
+
+	if key == "" {
+		key = path.Base(file)
+	}
+	return u.Upload(ctx, key, f, info.Size())
+}
+
+// nonRetryableError wraps the errors that should not be retried
+// (e.g., access denied, bad request).
+type nonRetryableError struct {
+	err error
+}
+
+func (e *nonRetryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *nonRetryableError) Unwrap() error {
+	return e.err
+}
+
+// checkResponse converts the HTTP status code to an error,
+// marking the client errors (except throttling) as non-retryable.
+func checkResponse(statusCode int, body []byte) error {
+	if statusCode >= 200 && statusCode < 300 {
+		return nil
+	}
+
+	msg := strings.TrimSpace(string(body))
+	if len(msg) > 512 {
+		msg = msg[:512]
+	}
+	err := fmt.Errorf("unexpected status code %d: %s", statusCode, msg)
+
+	// 408 request timeout and 429 too many requests are retryable
+	if statusCode >= 400 && statusCode < 500 && statusCode != 408 && statusCode != 429 {
+		return &nonRetryableError{err: err}
+	}
+	return err
+}
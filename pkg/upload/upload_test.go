@@ -0,0 +1,295 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name          string
+		dest          string
+		wantErr       error
+		wantPrefix    string
+		wantContainer string
+	}{
+		{name: "s3", dest: "s3://my-bucket/bundles/", wantPrefix: "bundles"},
+		{name: "gcs", dest: "gs://my-bucket/a/b", wantPrefix: "a/b"},
+		{name: "azure", dest: "azblob://account/container/reports", wantPrefix: "reports", wantContainer: "container"},
+		{name: "azure without prefix", dest: "azblob://account/container", wantContainer: "container"},
+		{name: "azure without container", dest: "azblob://account", wantErr: ErrEmptyBucket},
+		{name: "missing bucket", dest: "s3:///prefix", wantErr: ErrEmptyBucket},
+		{name: "unsupported scheme", dest: "ftp://host/path", wantErr: ErrUnsupportedScheme},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := New(tt.dest)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			up := u.(*uploader)
+			assert.Equal(t, tt.wantPrefix, up.prefix)
+			assert.Equal(t, tt.dest, u.Destination())
+			if tt.wantContainer != "" {
+				assert.Equal(t, tt.wantContainer, up.backend.(*azureBackend).container)
+			}
+		})
+	}
+}
+
+type mockBackend struct {
+	calls    atomic.Int32
+	errs     []error
+	lastKey  string
+	lastBody []byte
+}
+
+func (m *mockBackend) put(_ context.Context, objectKey string, body io.Reader, _ int64) error {
+	idx := int(m.calls.Add(1)) - 1
+	b, _ := io.ReadAll(body)
+	m.lastKey = objectKey
+	m.lastBody = b
+	if idx < len(m.errs) {
+		return m.errs[idx]
+	}
+	return nil
+}
+
+func newTestUploader(b backend, prefix string, maxSize int64, retries int) *uploader {
+	dest, _ := url.Parse("s3://test-bucket")
+	return &uploader{
+		dest:          dest,
+		prefix:        prefix,
+		backend:       b,
+		maxSize:       maxSize,
+		retries:       retries,
+		retryInterval: time.Millisecond,
+	}
+}
+
+func TestUploadRetry(t *testing.T) {
+	mb := &mockBackend{errs: []error{errors.New("connection reset"), errors.New("503")}}
+	u := newTestUploader(mb, "prefix", DefaultMaxSize, 3)
+
+	err := u.Upload(context.Background(), "/bundle.tar.gz", bytes.NewReader([]byte("hello")), 5)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), mb.calls.Load())
+	assert.Equal(t, "prefix/bundle.tar.gz", mb.lastKey)
+	assert.Equal(t, "hello", string(mb.lastBody), "body must be rewound on retry")
+}
+
+func TestUploadRetryExhausted(t *testing.T) {
+	mb := &mockBackend{errs: []error{errors.New("a"), errors.New("b"), errors.New("c")}}
+	u := newTestUploader(mb, "", DefaultMaxSize, 1)
+
+	err := u.Upload(context.Background(), "k", bytes.NewReader(nil), 0)
+	require.Error(t, err)
+	assert.Equal(t, int32(2), mb.calls.Load())
+}
+
+func TestUploadNonRetryable(t *testing.T) {
+	mb := &mockBackend{errs: []error{checkResponse(http.StatusForbidden, []byte("denied"))}}
+	u := newTestUploader(mb, "", DefaultMaxSize, 3)
+
+	err := u.Upload(context.Background(), "k", bytes.NewReader(nil), 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "403")
+	assert.Equal(t, int32(1), mb.calls.Load())
+}
+
+func TestUploadSizeLimit(t *testing.T) {
+	mb := &mockBackend{}
+	u := newTestUploader(mb, "", 4, 0)
+
+	err := u.Upload(context.Background(), "k", bytes.NewReader([]byte("hello")), 5)
+	require.ErrorIs(t, err, ErrSizeLimitExceeded)
+	assert.Equal(t, int32(0), mb.calls.Load())
+
+	// negative disables the limit
+	u.maxSize = -1
+	require.NoError(t, u.Upload(context.Background(), "k", bytes.NewReader([]byte("hello")), 5))
+}
+
+func TestUploadEmptyKey(t *testing.T) {
+	u := newTestUploader(&mockBackend{}, "", DefaultMaxSize, 0)
+	require.ErrorIs(t, u.Upload(context.Background(), "/", bytes.NewReader(nil), 0), ErrEmptyKey)
+}
+
+func TestCheckResponse(t *testing.T) {
+	assert.NoError(t, checkResponse(http.StatusOK, nil))
+	assert.NoError(t, checkResponse(http.StatusCreated, nil))
+
+	var nre *nonRetryableError
+	assert.ErrorAs(t, checkResponse(http.StatusBadRequest, nil), &nre)
+	assert.False(t, errors.As(checkResponse(http.StatusTooManyRequests, nil), &nre))
+	assert.False(t, errors.As(checkResponse(http.StatusInternalServerError, nil), &nre))
+}
+
+func TestUploadFile(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, os.WriteFile(f, []byte(`{"ok":true}`), 0644))
+
+	mb := &mockBackend{}
+	u := newTestUploader(mb, "reports", DefaultMaxSize, 0)
+	require.NoError(t, UploadFile(context.Background(), u, f, ""))
+	assert.Equal(t, "reports/report.json", mb.lastKey)
+	assert.Equal(t, `{"ok":true}`, string(mb.lastBody))
+
+	require.Error(t, UploadFile(context.Background(), u, filepath.Dir(f), ""))
+}
+
+func TestS3Backend(t *testing.T) {
+	var gotReq *http.Request
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotReq = r
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	b := newS3Backend("bucket", &Op{endpoint: srv.URL, region: "us-west-2", httpClient: srv.Client()})
+	b.getCredentialsFunc = func(context.Context) (awsCredentials, error) {
+		return awsCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret", Token: "session"}, nil
+	}
+	b.nowFunc = func() time.Time { return time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	require.NoError(t, b.put(context.Background(), "dir/file 1.txt", strings.NewReader("data"), 4))
+	require.NotNil(t, gotReq)
+	assert.Equal(t, http.MethodPut, gotReq.Method)
+	assert.Equal(t, "/bucket/dir/file%201.txt", gotReq.URL.EscapedPath())
+	assert.Equal(t, "data", string(gotBody))
+	assert.Equal(t, "20250102T030405Z", gotReq.Header.Get(s3HeaderDate))
+	assert.Equal(t, "session", gotReq.Header.Get(s3HeaderSecurityToken))
+
+	auth := gotReq.Header.Get("Authorization")
+	assert.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20250102/us-west-2/s3/aws4_request, SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date;x-amz-security-token, Signature="), auth)
+}
+
+func TestSignS3RequestDeterministic(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	creds := awsCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}
+
+	req1, _ := http.NewRequest(http.MethodPut, "https://bucket.s3.us-east-1.amazonaws.com/a/b", nil)
+	req2, _ := http.NewRequest(http.MethodPut, "https://bucket.s3.us-east-1.amazonaws.com/a/b", nil)
+	signS3Request(req1, creds, "us-east-1", now)
+	signS3Request(req2, creds, "us-east-1", now)
+	assert.Equal(t, req1.Header.Get("Authorization"), req2.Header.Get("Authorization"))
+
+	req3, _ := http.NewRequest(http.MethodPut, "https://bucket.s3.us-east-1.amazonaws.com/a/c", nil)
+	signS3Request(req3, creds, "us-east-1", now)
+	assert.NotEqual(t, req1.Header.Get("Authorization"), req3.Header.Get("Authorization"))
+}
+
+func TestEscapeObjectKey(t *testing.T) {
+	assert.Equal(t, "a/b/c.txt", escapeObjectKey("a/b/c.txt"))
+	assert.Equal(t, "a/2025-01-02T03%3A04%3A05Z.tar.gz", escapeObjectKey("a/2025-01-02T03:04:05Z.tar.gz"))
+	assert.Equal(t, "a%20b~_-", escapeObjectKey("a b~_-"))
+}
+
+func TestGCSBackend(t *testing.T) {
+	var gotReq *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotReq = r
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	b := newGCSBackend("bucket", &Op{endpoint: srv.URL, httpClient: srv.Client()})
+	b.getTokenFunc = func(context.Context) (string, error) { return "tok", nil }
+
+	require.NoError(t, b.put(context.Background(), "dir/scan.json", strings.NewReader("{}"), 2))
+	require.NotNil(t, gotReq)
+	assert.Equal(t, http.MethodPost, gotReq.Method)
+	assert.Equal(t, "/upload/storage/v1/b/bucket/o", gotReq.URL.Path)
+	assert.Equal(t, "media", gotReq.URL.Query().Get("uploadType"))
+	assert.Equal(t, "dir/scan.json", gotReq.URL.Query().Get("name"))
+	assert.Equal(t, "Bearer tok", gotReq.Header.Get("Authorization"))
+}
+
+func TestAzureBackend(t *testing.T) {
+	var gotReq *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotReq = r
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	b := newAzureBackend("account", "container", &Op{endpoint: srv.URL, httpClient: srv.Client()})
+	b.getTokenFunc = func(context.Context) (string, error) { return "tok", nil }
+
+	require.NoError(t, b.put(context.Background(), "dir/bundle.tar.gz", strings.NewReader("x"), 1))
+	require.NotNil(t, gotReq)
+	assert.Equal(t, http.MethodPut, gotReq.Method)
+	assert.Equal(t, "/container/dir/bundle.tar.gz", gotReq.URL.Path)
+	assert.Equal(t, "BlockBlob", gotReq.Header.Get("x-ms-blob-type"))
+	assert.Equal(t, "Bearer tok", gotReq.Header.Get("Authorization"))
+}
+
+func TestAzureAccessToken(t *testing.T) {
+	var gotReq *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotReq = r
+		_, _ = w.Write([]byte(`{"access_token":"tok"}`))
+	}))
+	defer srv.Close()
+
+	var requests atomic.Int32
+	cli := srv.Client()
+	transport := cli.Transport
+	cli.Transport = roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		requests.Add(1)
+		return transport.RoundTrip(r)
+	})
+
+	b := newAzureBackend("account", "container", &Op{httpClient: cli})
+	b.tokenURL = srv.URL
+
+	tok, err := b.getTokenFunc(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "tok", tok)
+	assert.Equal(t, int32(1), requests.Load())
+	require.NotNil(t, gotReq)
+	assert.Equal(t, "true", gotReq.Header.Get("Metadata"))
+	assert.Equal(t, azureStorageResource, gotReq.URL.Query().Get("resource"))
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestApplyOpts(t *testing.T) {
+	op := &Op{}
+	require.NoError(t, op.applyOpts(nil))
+	assert.Equal(t, DefaultMaxSize, op.maxSize)
+	assert.Equal(t, DefaultRetries, op.retries)
+	assert.Equal(t, DefaultRetryInterval, op.retryInterval)
+	assert.NotNil(t, op.httpClient)
+
+	op = &Op{}
+	require.NoError(t, op.applyOpts([]OpOption{WithRetries(0), WithMaxSize(10), WithRetryInterval(time.Second), WithRegion("r"), WithEndpoint("e")}))
+	assert.Equal(t, 0, op.retries)
+	assert.Equal(t, int64(10), op.maxSize)
+	assert.Equal(t, time.Second, op.retryInterval)
+	assert.Equal(t, "r", op.region)
+	assert.Equal(t, "e", op.endpoint)
+}
@@ -0,0 +1,45 @@
+#!/usr/bin/env bash
+set -xue
+
+# do not mask errors in a pipeline
+set -o pipefail
+
+# treat unset variables as an error
+set -o nounset
+
+# exit script whenever it errs
+set -o errexit
+
+# Convert the swagger 2.0 docs (see "scripts/swag-gen.sh") into the OpenAPI 3 spec,
+# which is the same spec served at "/v1/openapi.json"
+go run ./cmd/openapi-gen -input ./docs/apis/swagger.json -output ./docs/apis/openapi.json
+
+# Generate the clients only when requested, e.g.,
+#
+# CLIENTS="python typescript" ./scripts/openapi-gen.sh
+#
+# requires
+# npm install @openapitools/openapi-generator-cli -g
+CLIENTS=${CLIENTS:-}
+for client in ${CLIENTS}; do
+  case "${client}" in
+    python)
+      openapi-generator-cli generate \
+        -i ./docs/apis/openapi.json \
+        -g python \
+        -o ./clients/python \
+        --package-name gpud_client
+      ;;
+    typescript)
+      openapi-generator-cli generate \
+        -i ./docs/apis/openapi.json \
+        -g typescript-fetch \
+        -o ./clients/typescript \
+        --additional-properties=npmName=gpud-client
+      ;;
+    *)
+      echo "unsupported client: ${client}"
+      exit 1
+      ;;
+  esac
+done